@@ -0,0 +1,861 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/auto"
+)
+
+const (
+	// envConfigFileKey is the environment variable key containing the path to
+	// the agent configuration file.
+	envConfigFileKey = "OTEL_GO_AUTO_CONFIG_FILE"
+	// envTargetCmdlineKey is the environment variable key containing a
+	// substring of the command line of the target process.
+	envTargetCmdlineKey = "OTEL_GO_AUTO_TARGET_CMDLINE"
+	// envDisabledProbesKey is the environment variable key containing the
+	// comma-separated list of probes to disable.
+	envDisabledProbesKey = "OTEL_GO_AUTO_DISABLED_PROBES"
+	// envLogFormatKey is the environment variable key containing the log
+	// format.
+	envLogFormatKey = "OTEL_GO_AUTO_LOG_FORMAT"
+	// envAllGoProcessesKey is the environment variable key enabling the
+	// instrumentation of all the Go processes of the host.
+	envAllGoProcessesKey = "OTEL_GO_AUTO_ALL_GO_PROCESSES"
+)
+
+// source is where the value of a setting is resolved from.
+type source string
+
+const (
+	sourceFile source = "file"
+	sourceEnv  source = "env"
+	sourceFlag source = "flag"
+)
+
+// setting is a configuration setting of the agent.
+//
+// Settings are resolved from, in order of precedence, command line flags,
+// environment variables, and the configuration file.
+type setting struct {
+	// name is the flag name and the configuration file key.
+	name string
+	// env is the environment variable key.
+	env   string
+	usage string
+	// valid are the accepted values. Any value is accepted if empty.
+	valid []string
+	// fold is true if valid values are matched ignoring case and surrounding
+	// spaces.
+	fold bool
+	// check validates a value if not nil.
+	check func(string) error
+	// list is true if the setting accepts multiple values. Flags of these
+	// settings can be repeated, and their environment variables are
+	// comma-separated.
+	list bool
+	// group is the name of the settings resolved together: they all come from
+	// the source with the highest precedence setting any of them.
+	group string
+	// exported is true if the setting is passed to the trace handler using
+	// its environment variable.
+	exported bool
+	// secret is true if the value is redacted when printed.
+	secret bool
+	// boolean is true if the flag of the setting does not need a value.
+	boolean bool
+}
+
+// validate returns an error describing the valid values if v is not one.
+func (s setting) validate(v string) error {
+	match := func(valid string) bool { return valid == v }
+	if s.fold {
+		match = func(valid string) bool { return strings.EqualFold(valid, strings.TrimSpace(v)) }
+	}
+	if len(s.valid) > 0 && !slices.ContainsFunc(s.valid, match) {
+		return fmt.Errorf("valid values are %s", quoteAll(s.valid))
+	}
+	if s.check != nil {
+		return s.check(v)
+	}
+	return nil
+}
+
+func quoteAll(vals []string) string {
+	q := make([]string, len(vals))
+	for i, v := range vals {
+		q[i] = strconv.Quote(v)
+	}
+	return strings.Join(q, ", ")
+}
+
+// probeNames are the valid values of the disable-probe setting: the probe IDs
+// ("<package>/<span kind>") and the instrumented packages, disabling all the
+// probes of the package.
+//
+// Keep in sync with the probes of [auto.NewInstrumentation].
+var probeNames = []string{
+	"cloud.google.com/go/pubsub",
+	"cloud.google.com/go/pubsub/consumer",
+	"cloud.google.com/go/pubsub/producer",
+	"cloud.google.com/go/spanner",
+	"cloud.google.com/go/spanner/client",
+	"crypto/tls",
+	"crypto/tls/internal",
+	"database/sql",
+	"database/sql/client",
+	"github.com/99designs/gqlgen/graphql/handler",
+	"github.com/99designs/gqlgen/graphql/handler/internal",
+	"github.com/ClickHouse/clickhouse-go/v2",
+	"github.com/ClickHouse/clickhouse-go/v2/client",
+	"github.com/IBM/sarama",
+	"github.com/IBM/sarama/consumer",
+	"github.com/IBM/sarama/producer",
+	"github.com/Shopify/sarama",
+	"github.com/Shopify/sarama/consumer",
+	"github.com/Shopify/sarama/producer",
+	"github.com/aws/aws-sdk-go-v2",
+	"github.com/aws/aws-sdk-go-v2/client",
+	"github.com/bradfitz/gomemcache/memcache",
+	"github.com/bradfitz/gomemcache/memcache/client",
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka/consumer",
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka/producer",
+	"github.com/dgraph-io/badger/v4",
+	"github.com/dgraph-io/badger/v4/internal",
+	"github.com/eclipse/paho.golang/paho",
+	"github.com/eclipse/paho.golang/paho/producer",
+	"github.com/eclipse/paho.mqtt.golang",
+	"github.com/eclipse/paho.mqtt.golang/consumer",
+	"github.com/eclipse/paho.mqtt.golang/producer",
+	"github.com/elastic/go-elasticsearch/v7",
+	"github.com/elastic/go-elasticsearch/v7/client",
+	"github.com/elastic/go-elasticsearch/v8",
+	"github.com/elastic/go-elasticsearch/v8/client",
+	"github.com/gocql/gocql",
+	"github.com/gocql/gocql/client",
+	"github.com/gorilla/websocket",
+	"github.com/gorilla/websocket/internal",
+	"github.com/hashicorp/vault/api",
+	"github.com/hashicorp/vault/api/client",
+	"github.com/hibiken/asynq",
+	"github.com/hibiken/asynq/consumer",
+	"github.com/hibiken/asynq/producer",
+	"github.com/influxdata/influxdb-client-go/v2",
+	"github.com/influxdata/influxdb-client-go/v2/client",
+	"github.com/jackc/pgx",
+	"github.com/jackc/pgx/client",
+	"github.com/nats-io/nats.go",
+	"github.com/nats-io/nats.go/consumer",
+	"github.com/nats-io/nats.go/jetstream",
+	"github.com/nats-io/nats.go/jetstream/consumer",
+	"github.com/nats-io/nats.go/producer",
+	"github.com/rabbitmq/amqp091-go",
+	"github.com/rabbitmq/amqp091-go/consumer",
+	"github.com/rabbitmq/amqp091-go/producer",
+	"github.com/redis/go-redis/v9",
+	"github.com/redis/go-redis/v9/client",
+	"github.com/redis/rueidis",
+	"github.com/redis/rueidis/client",
+	"github.com/segmentio/kafka-go",
+	"github.com/segmentio/kafka-go/consumer",
+	"github.com/segmentio/kafka-go/producer",
+	"github.com/valkey-io/valkey-go",
+	"github.com/valkey-io/valkey-go/client",
+	"github.com/valyala/fasthttp",
+	"github.com/valyala/fasthttp/client",
+	"github.com/valyala/fasthttp/server",
+	"go.etcd.io/bbolt",
+	"go.etcd.io/bbolt/internal",
+	"go.etcd.io/etcd/client/v3",
+	"go.etcd.io/etcd/client/v3/client",
+	"go.mongodb.org/mongo-driver",
+	"go.mongodb.org/mongo-driver/client",
+	"go.mongodb.org/mongo-driver/v2",
+	"go.mongodb.org/mongo-driver/v2/client",
+	"go.opentelemetry.io/auto",
+	"go.opentelemetry.io/auto/client",
+	"go.opentelemetry.io/otel/internal/global",
+	"go.opentelemetry.io/otel/internal/global/client",
+	"go.opentelemetry.io/otel/trace",
+	"go.opentelemetry.io/otel/trace/client",
+	"go.temporal.io/sdk",
+	"go.temporal.io/sdk/client",
+	"go.uber.org/zap",
+	"go.uber.org/zap/internal",
+	"golang.org/x/crypto/ssh",
+	"golang.org/x/crypto/ssh/client",
+	"golang.org/x/net/http2",
+	"golang.org/x/net/http2/server",
+	"google.golang.org/grpc",
+	"google.golang.org/grpc/client",
+	"google.golang.org/grpc/server",
+	"k8s.io/client-go/rest",
+	"k8s.io/client-go/rest/internal",
+	"log/slog",
+	"log/slog/internal",
+	"net",
+	"net/client",
+	"net/internal",
+	"net/http",
+	"net/http/client",
+	"net/http/httputil",
+	"net/http/httputil/internal",
+	"net/http/server",
+	"net/rpc",
+	"net/rpc/client",
+	"net/rpc/server",
+	"net/smtp",
+	"net/smtp/client",
+	"runtime",
+	"runtime/internal",
+}
+
+const (
+	samplerAlwaysOn                = "always_on"
+	samplerAlwaysOff               = "always_off"
+	samplerTraceIDRatio            = "traceidratio"
+	samplerParentBasedAlwaysOn     = "parentbased_always_on"
+	samplerParentBasedAlwaysOff    = "parentbased_always_off"
+	samplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+var settings = []setting{
+	{
+		name:  "target-pid",
+		env:   envTargetPIDKey,
+		usage: "PID of the target process",
+		check: func(v string) error {
+			if pid, err := strconv.Atoi(v); err != nil || pid < 0 {
+				return errors.New("must be a non-negative integer")
+			}
+			return nil
+		},
+		group: "target",
+	},
+	{
+		name:  "target-exe",
+		env:   envTargetExeKey,
+		usage: "Executable path run by the target process",
+		group: "target",
+	},
+	{
+		name:  "target-cmdline",
+		env:   envTargetCmdlineKey,
+		usage: "Substring of the command line of the target process",
+		group: "target",
+	},
+	{
+		name:    "all-go-processes",
+		env:     envAllGoProcessesKey,
+		usage:   "Instrument all the selected Go processes of the host instead of a target process",
+		check:   checkBool,
+		boolean: true,
+	},
+	{
+		name:  "include-exe",
+		env:   "OTEL_GO_AUTO_INCLUDE_EXE",
+		usage: "Glob of the executable paths of the processes instrumented with -all-go-processes (repeatable)",
+		check: checkGlob,
+		list:  true,
+	},
+	{
+		name:  "exclude-exe",
+		env:   "OTEL_GO_AUTO_EXCLUDE_EXE",
+		usage: "Glob of the executable paths of the processes not instrumented with -all-go-processes (repeatable)",
+		check: checkGlob,
+		list:  true,
+	},
+	{
+		name:  "include-cgroup",
+		env:   "OTEL_GO_AUTO_INCLUDE_CGROUP",
+		usage: "Prefix of the cgroup paths of the processes instrumented with -all-go-processes (repeatable)",
+		list:  true,
+	},
+	{
+		name:  "exclude-cgroup",
+		env:   "OTEL_GO_AUTO_EXCLUDE_CGROUP",
+		usage: "Prefix of the cgroup paths of the processes not instrumented with -all-go-processes (repeatable)",
+		list:  true,
+	},
+	{
+		name:  "service-name",
+		env:   "OTEL_GO_AUTO_SERVICE_NAMES",
+		usage: "Service name of the processes instrumented with -all-go-processes whose executable path matches a glob, as <glob>=<name> (repeatable)",
+		check: func(v string) error {
+			_, err := parseServiceRule(v)
+			return err
+		},
+		list: true,
+	},
+	{
+		name:  "service-name-source",
+		env:   "OTEL_GO_AUTO_SERVICE_NAME_SOURCES",
+		usage: "Source of the service names of the processes instrumented with -all-go-processes, in order of precedence (repeatable, default env, label, module, executable)",
+		valid: serviceNameSources,
+		list:  true,
+	},
+	{
+		name:  "service-name-label",
+		env:   "OTEL_GO_AUTO_SERVICE_NAME_LABEL",
+		usage: "Container label of the label service name source (default " + defaultServiceNameLabel + ")",
+	},
+	{
+		name:  "min-uptime",
+		env:   "OTEL_GO_AUTO_MIN_UPTIME",
+		usage: "Minimum uptime of the processes instrumented with -all-go-processes (e.g. 30s)",
+		check: func(v string) error {
+			if d, err := time.ParseDuration(v); err != nil || d < 0 {
+				return errors.New("must be a non-negative duration (e.g. 30s, 5m)")
+			}
+			return nil
+		},
+	},
+	{
+		name:  "max-processes",
+		env:   "OTEL_GO_AUTO_MAX_PROCESSES",
+		usage: fmt.Sprintf("Maximum number of processes instrumented with -all-go-processes, the longest-running first (default %d)", defaultMaxProcesses),
+		check: func(v string) error {
+			if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+				return errors.New("must be a positive integer")
+			}
+			return nil
+		},
+	},
+	{
+		name:  "status-addr",
+		env:   "OTEL_GO_AUTO_STATUS_ADDR",
+		usage: "Loopback address or unix socket (unix:<path>) of the status API of -all-go-processes",
+	},
+	{
+		name:  "health-addr",
+		env:   "OTEL_GO_AUTO_HEALTH_ADDR",
+		usage: "Address of the /healthz and /readyz endpoints (e.g. :8081)",
+	},
+	{
+		name:    "ready-while-waiting",
+		env:     "OTEL_GO_AUTO_READY_WHILE_WAITING",
+		usage:   "Report the agent ready while it waits for the target process",
+		check:   checkBool,
+		boolean: true,
+	},
+	{
+		name:    "keep-privileges",
+		env:     "OTEL_GO_AUTO_KEEP_PRIVILEGES",
+		usage:   "Keep all the capabilities of the agent instead of dropping the ones not needed once the probes are loaded",
+		check:   checkBool,
+		boolean: true,
+	},
+	{
+		name:  "disable-probe",
+		env:   envDisabledProbesKey,
+		usage: "Probe to disable, a package or a package and span kind (repeatable)",
+		valid: probeNames,
+		list:  true,
+	},
+	{
+		name:  "allow-attribute",
+		env:   "OTEL_GO_AUTO_ALLOW_ATTRIBUTES",
+		usage: "Span attribute exported by a probe, as <probe ID>=<key glob>, the other attributes of the probe are removed (repeatable)",
+		check: checkAttributeRule,
+		list:  true,
+	},
+	{
+		name:  "deny-attribute",
+		env:   "OTEL_GO_AUTO_DENY_ATTRIBUTES",
+		usage: "Span attribute removed from a probe, as <probe ID>=<key glob>, takes precedence over -allow-attribute (repeatable)",
+		check: checkAttributeRule,
+		list:  true,
+	},
+	{
+		name:     "traces-exporter",
+		env:      "OTEL_TRACES_EXPORTER",
+		usage:    "Trace exporter (repeatable)",
+		valid:    []string{"otlp", "console", "none"},
+		list:     true,
+		exported: true,
+	},
+	{
+		name:     "exporter-endpoint",
+		env:      "OTEL_EXPORTER_OTLP_ENDPOINT",
+		usage:    "OTLP exporter endpoint URL",
+		exported: true,
+	},
+	{
+		name:     "exporter-protocol",
+		env:      "OTEL_EXPORTER_OTLP_PROTOCOL",
+		usage:    "OTLP exporter protocol",
+		valid:    []string{"grpc", "http/protobuf"},
+		exported: true,
+	},
+	{
+		name:     "exporter-headers",
+		env:      "OTEL_EXPORTER_OTLP_HEADERS",
+		usage:    "OTLP exporter headers, as comma-separated key=value pairs",
+		exported: true,
+		secret:   true,
+	},
+	{
+		name:  "sampler",
+		env:   "OTEL_TRACES_SAMPLER",
+		usage: "Trace sampler",
+		valid: []string{
+			samplerAlwaysOn,
+			samplerAlwaysOff,
+			samplerTraceIDRatio,
+			samplerParentBasedAlwaysOn,
+			samplerParentBasedAlwaysOff,
+			samplerParentBasedTraceIDRatio,
+		},
+		fold: true,
+	},
+	{
+		name:  "sampler-arg",
+		env:   "OTEL_TRACES_SAMPLER_ARG",
+		usage: "Sampling ratio of the traceidratio samplers",
+		check: func(v string) error {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil || f < 0 || f > 1 {
+				return errors.New("must be a number between 0 and 1")
+			}
+			return nil
+		},
+	},
+	{
+		name:  "log-level",
+		env:   envLogLevelKey,
+		usage: "Logging level",
+		valid: []string{"debug", "info", "warn", "error"},
+		fold:  true,
+	},
+	{
+		name:  "log-format",
+		env:   envLogFormatKey,
+		usage: "Logging format",
+		valid: []string{"json", "text"},
+	},
+}
+
+func checkBool(v string) error {
+	if _, err := strconv.ParseBool(v); err != nil {
+		return errors.New(`valid values are "true", "false"`)
+	}
+	return nil
+}
+
+func checkGlob(v string) error {
+	_, err := filepath.Match(v, "")
+	return err
+}
+
+// parseAttributeRule parses a "<probe ID>=<key glob>" attribute rule.
+func parseAttributeRule(v string) (probe, pattern string, err error) {
+	probe, pattern, ok := strings.Cut(v, "=")
+	if !ok || probe == "" || pattern == "" {
+		return "", "", errors.New("must be <probe ID>=<attribute key glob>")
+	}
+	i := strings.LastIndexByte(probe, '/')
+	if _, isKind := spanKinds[probe[i+1:]]; !isKind || !slices.Contains(probeNames, probe) {
+		return "", "", fmt.Errorf("unknown probe ID %q", probe)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", "", err
+	}
+	return probe, pattern, nil
+}
+
+func checkAttributeRule(v string) error {
+	_, _, err := parseAttributeRule(v)
+	return err
+}
+
+// value is the resolved value of a setting.
+type value struct {
+	vals []string
+	src  source
+}
+
+// config is the configuration of the agent.
+type config struct {
+	// file is the path of the configuration file, empty if none is used.
+	file        string
+	dryRun      bool
+	printConfig bool
+	version     bool
+
+	values map[string]value
+}
+
+// errUsage is returned by parseConfig if the command line arguments are
+// invalid. The error and the usage are already written to the output.
+var errUsage = errors.New("invalid command line arguments")
+
+// Used for testing.
+var (
+	lookupEnv = os.LookupEnv
+	setenv    = os.Setenv
+)
+
+// parseConfig parses the command line arguments, environment variables, and
+// configuration file of the agent.
+//
+// The flag.ErrHelp error is returned if the -help flag is passed, and an
+// errUsage error if the flags are invalid.
+func parseConfig(name string, args []string, output io.Writer) (*config, error) {
+	c := &config{values: make(map[string]value)}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() { usage(fs) }
+
+	flags := make(map[string]value)
+	for _, s := range settings {
+		define := fs.Func
+		if s.boolean {
+			define = fs.BoolFunc
+		}
+		define(s.name, flagUsage(s), func(v string) error {
+			if err := s.validate(v); err != nil {
+				return err
+			}
+			val := flags[s.name]
+			if !s.list {
+				val.vals = nil
+			}
+			val.vals, val.src = append(val.vals, v), sourceFlag
+			flags[s.name] = val
+			return nil
+		})
+	}
+	fs.StringVar(&c.file, "config", "", fmt.Sprintf("Path of the YAML configuration file (env %s)", envConfigFileKey))
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Validate the configuration and find the target process without instrumenting it")
+	fs.BoolVar(&c.printConfig, "print-config", false, "Print the effective configuration and exit")
+	fs.BoolVar(&c.version, "version", false, "Print the agent version and the supported library versions and exit")
+
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if c.file == "" {
+		c.file, _ = lookupEnv(envConfigFileKey)
+	}
+	if c.file != "" {
+		file, err := readConfigFile(c.file)
+		if err != nil {
+			return nil, err
+		}
+		c.merge(file)
+	}
+
+	env, err := envValues()
+	if err != nil {
+		return nil, err
+	}
+	c.merge(env)
+	c.merge(flags)
+	return c, nil
+}
+
+// merge sets the values of c to vals, overriding the ones already set.
+//
+// The values of the settings of a group override all the values of the group.
+func (c *config) merge(vals map[string]value) {
+	for _, s := range settings {
+		if s.group == "" {
+			continue
+		}
+		var set bool
+		for _, other := range settings {
+			if _, ok := vals[other.name]; ok && other.group == s.group {
+				set = true
+				break
+			}
+		}
+		if set {
+			delete(c.values, s.name)
+		}
+	}
+	for name, v := range vals {
+		c.values[name] = v
+	}
+}
+
+func envValues() (map[string]value, error) {
+	vals := make(map[string]value)
+	var err error
+	for _, s := range settings {
+		v, ok := lookupEnv(s.env)
+		if !ok || v == "" {
+			continue
+		}
+		var val value
+		if s.list {
+			for _, elem := range strings.Split(v, ",") {
+				val.vals = append(val.vals, strings.TrimSpace(elem))
+			}
+		} else {
+			val.vals = []string{v}
+		}
+		for _, elem := range val.vals {
+			if e := s.validate(elem); e != nil {
+				err = errors.Join(err, fmt.Errorf("%s: invalid value %q: %w", s.env, elem, e))
+			}
+		}
+		val.src = sourceEnv
+		vals[s.name] = val
+	}
+	return vals, err
+}
+
+func readConfigFile(path string) (map[string]value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read configuration file: %w", err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse configuration file %s: %w", path, err)
+	}
+
+	vals := make(map[string]value)
+	for key, v := range raw {
+		i := slices.IndexFunc(settings, func(s setting) bool { return s.name == key })
+		if i < 0 {
+			names := make([]string, len(settings))
+			for i, s := range settings {
+				names[i] = s.name
+			}
+			err = errors.Join(err, fmt.Errorf("%s: unknown key %q: valid keys are %s", path, key, quoteAll(names)))
+			continue
+		}
+		s := settings[i]
+
+		val := value{src: sourceFile}
+		switch v := v.(type) {
+		case []any:
+			if !s.list {
+				err = errors.Join(err, fmt.Errorf("%s: %s: a single value is expected", path, key))
+				continue
+			}
+			for _, elem := range v {
+				val.vals = append(val.vals, fmt.Sprint(elem))
+			}
+		case map[string]any, nil:
+			err = errors.Join(err, fmt.Errorf("%s: %s: invalid value", path, key))
+			continue
+		default:
+			val.vals = []string{fmt.Sprint(v)}
+		}
+		for _, elem := range val.vals {
+			if e := s.validate(elem); e != nil {
+				err = errors.Join(err, fmt.Errorf("%s: %s: invalid value %q: %w", path, key, elem, e))
+			}
+		}
+		vals[key] = val
+	}
+	return vals, err
+}
+
+// get returns the value of the setting name, or an empty string if it is not
+// set.
+func (c *config) get(name string) string {
+	vals := c.values[name].vals
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[len(vals)-1]
+}
+
+// enabled returns if the boolean setting name is set to true.
+func (c *config) enabled(name string) bool {
+	// Validated when parsed.
+	ok, _ := strconv.ParseBool(c.get(name))
+	return ok
+}
+
+// list returns the values of the list setting name.
+func (c *config) list(name string) []string {
+	return c.values[name].vals
+}
+
+// exportEnv sets the environment variables of the exported settings not
+// resolved from the environment. The trace handler, and the exporters it
+// creates, are configured with these environment variables.
+func (c *config) exportEnv() error {
+	for _, s := range settings {
+		v, ok := c.values[s.name]
+		if !s.exported || !ok || v.src == sourceEnv {
+			continue
+		}
+		if err := setenv(s.env, strings.Join(v.vals, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// print writes the effective configuration to w in the configuration file
+// format.
+func (c *config) print(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Effective configuration (precedence: flag, env, file).\n")
+	if c.file != "" {
+		fmt.Fprintf(&b, "# Configuration file: %s\n", c.file)
+	}
+	for _, s := range settings {
+		v, ok := c.values[s.name]
+		if !ok {
+			fmt.Fprintf(&b, "# %s: unset\n", s.name)
+			continue
+		}
+		vals := v.vals
+		if s.secret {
+			vals = []string{"<redacted>"}
+		}
+		if s.list {
+			fmt.Fprintf(&b, "%s: [%s] # %s\n", s.name, quoteAll(vals), v.src)
+		} else {
+			fmt.Fprintf(&b, "%s: %s # %s\n", s.name, strconv.Quote(vals[0]), v.src)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// target returns the target process selection.
+func (c *config) target() (pid int, exe, cmdline string) {
+	pid = -1
+	if v := c.get("target-pid"); v != "" {
+		// Validated when parsed.
+		pid, _ = strconv.Atoi(v)
+	}
+	return pid, c.get("target-exe"), c.get("target-cmdline")
+}
+
+// sampler returns the trace sampler, or nil if none is configured.
+func (c *config) sampler() auto.Sampler {
+	name := strings.ToLower(strings.TrimSpace(c.get("sampler")))
+	if name == "" {
+		return nil
+	}
+	ratio := 1.0
+	if arg := c.get("sampler-arg"); arg != "" {
+		// Validated when parsed.
+		ratio, _ = strconv.ParseFloat(strings.TrimSpace(arg), 64)
+	}
+
+	parentBased := auto.DefaultSampler().(auto.ParentBasedSampler)
+	switch name {
+	case samplerAlwaysOn:
+		return auto.AlwaysOnSampler{}
+	case samplerAlwaysOff:
+		return auto.AlwaysOffSampler{}
+	case samplerTraceIDRatio:
+		return auto.TraceIDRatioSampler{Fraction: ratio}
+	case samplerParentBasedAlwaysOn:
+		parentBased.Root = auto.AlwaysOnSampler{}
+	case samplerParentBasedAlwaysOff:
+		parentBased.Root = auto.AlwaysOffSampler{}
+	case samplerParentBasedTraceIDRatio:
+		parentBased.Root = auto.TraceIDRatioSampler{Fraction: ratio}
+	}
+	return parentBased
+}
+
+var spanKinds = map[string]trace.SpanKind{
+	trace.SpanKindServer.String():   trace.SpanKindServer,
+	trace.SpanKindClient.String():   trace.SpanKindClient,
+	trace.SpanKindProducer.String(): trace.SpanKindProducer,
+	trace.SpanKindConsumer.String(): trace.SpanKindConsumer,
+	trace.SpanKindInternal.String(): trace.SpanKindInternal,
+}
+
+// instrumentationConfig returns the instrumentation configuration disabling
+// the probes of the disable-probe setting.
+func (c *config) instrumentationConfig() auto.InstrumentationConfig {
+	ic := auto.InstrumentationConfig{Sampler: c.sampler()}
+	probes := c.list("disable-probe")
+	if len(probes) == 0 {
+		return ic
+	}
+
+	disabled := false
+	ic.InstrumentationLibraryConfigs = make(map[auto.InstrumentationLibraryID]auto.InstrumentationLibrary, len(probes))
+	for _, p := range probes {
+		id := auto.InstrumentationLibraryID{InstrumentedPkg: p}
+		if i := strings.LastIndexByte(p, '/'); i >= 0 {
+			if kind, ok := spanKinds[p[i+1:]]; ok {
+				id = auto.InstrumentationLibraryID{InstrumentedPkg: p[:i], SpanKind: kind}
+			}
+		}
+		ic.InstrumentationLibraryConfigs[id] = auto.InstrumentationLibrary{TracesEnabled: &disabled}
+	}
+	return ic
+}
+
+// attributeFilters returns the attribute filters of the allow-attribute and
+// deny-attribute settings, by probe ID.
+func (c *config) attributeFilters() map[string]auto.AttributeFilter {
+	filters := make(map[string]auto.AttributeFilter)
+	for _, v := range c.list("allow-attribute") {
+		// Validated when parsed.
+		probe, pattern, _ := parseAttributeRule(v)
+		f := filters[probe]
+		f.Allow = append(f.Allow, pattern)
+		filters[probe] = f
+	}
+	for _, v := range c.list("deny-attribute") {
+		probe, pattern, _ := parseAttributeRule(v)
+		f := filters[probe]
+		f.Deny = append(f.Deny, pattern)
+		filters[probe] = f
+	}
+	return filters
+}
+
+// instrumentationOptions returns the options configuring the sampler,
+// disabling the probes of the disable-probe setting, and filtering the span
+// attributes.
+func (c *config) instrumentationOptions() []auto.InstrumentationOption {
+	var opts []auto.InstrumentationOption
+	ic := c.instrumentationConfig()
+	if ic.InstrumentationLibraryConfigs != nil {
+		opts = append(opts, auto.WithConfigProvider(auto.NewStaticConfigProvider(ic)))
+	} else if ic.Sampler != nil {
+		opts = append(opts, auto.WithSampler(ic.Sampler))
+	}
+	filters := c.attributeFilters()
+	for _, probe := range slices.Sorted(maps.Keys(filters)) {
+		opts = append(opts, auto.WithAttributeFilter(probe, filters[probe]))
+	}
+	return opts
+}
+
+func flagUsage(s setting) string {
+	u := fmt.Sprintf("%s (env %s)", s.usage, s.env)
+	if len(s.valid) > 0 {
+		u += "\nValid values: " + strings.Join(s.valid, ", ")
+	}
+	return u
+}
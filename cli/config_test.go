@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto"
+)
+
+func writeConfigFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func TestParseConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+log-level: warn
+log-format: text
+sampler: always_off
+target-exe: /usr/bin/file
+disable-probe: [net/http/client]
+`)
+	t.Setenv(envConfigFileKey, path)
+	t.Setenv(envLogLevelKey, "error")
+	t.Setenv("OTEL_TRACES_SAMPLER", "always_on")
+	t.Setenv(envTargetPIDKey, "100")
+
+	c, err := parseConfig("test", []string{"-log-level=debug"}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, path, c.file)
+
+	assert.Equal(t, value{vals: []string{"debug"}, src: sourceFlag}, c.values["log-level"])
+	assert.Equal(t, value{vals: []string{"always_on"}, src: sourceEnv}, c.values["sampler"])
+	assert.Equal(t, value{vals: []string{"text"}, src: sourceFile}, c.values["log-format"])
+	assert.Equal(t, []string{"net/http/client"}, c.list("disable-probe"))
+
+	// The target of the environment overrides the one of the file.
+	pid, exe, cmdline := c.target()
+	assert.Equal(t, 100, pid)
+	assert.Empty(t, exe)
+	assert.Empty(t, cmdline)
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	var out bytes.Buffer
+	_, err := parseConfig("test", []string{"-log-format=xml"}, &out)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errUsage)
+	assert.Contains(t, out.String(), `invalid value "xml" for flag -log-format: valid values are "json", "text"`)
+
+	out.Reset()
+	_, err = parseConfig("test", []string{"-disable-probe=net/mail"}, &out)
+	require.Error(t, err)
+	assert.Contains(t, out.String(), `"net/http/server"`)
+
+	_, err = parseConfig("test", []string{"-sampler-arg=2"}, io.Discard)
+	assert.Error(t, err)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+	_, err = parseConfig("test", nil, io.Discard)
+	assert.ErrorContains(t, err, `OTEL_EXPORTER_OTLP_PROTOCOL: invalid value "http/json": valid values are "grpc", "http/protobuf"`)
+}
+
+func TestParseConfigFileInvalid(t *testing.T) {
+	path := writeConfigFile(t, "log-levle: debug\nsampler: [always_on]\n")
+	_, err := parseConfig("test", []string{"-config", path}, io.Discard)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `unknown key "log-levle": valid keys are "target-pid"`)
+	assert.ErrorContains(t, err, "sampler: a single value is expected")
+
+	_, err = parseConfig("test", []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, io.Discard)
+	assert.Error(t, err)
+}
+
+func TestParseConfigList(t *testing.T) {
+	t.Setenv(envDisabledProbesKey, "database/sql, net/http/server")
+	c, err := parseConfig("test", nil, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"database/sql", "net/http/server"}, c.list("disable-probe"))
+
+	c, err = parseConfig("test", []string{"-disable-probe=net/http", "-disable-probe=google.golang.org/grpc/client"}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"net/http", "google.golang.org/grpc/client"}, c.list("disable-probe"))
+}
+
+func TestConfigExportEnv(t *testing.T) {
+	orig := setenv
+	t.Cleanup(func() { setenv = orig })
+	got := make(map[string]string)
+	setenv = func(k, v string) error {
+		got[k] = v
+		return nil
+	}
+
+	path := writeConfigFile(t, "exporter-protocol: grpc\n")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://env:4318")
+	c, err := parseConfig("test", []string{
+		"-config", path,
+		"-traces-exporter=otlp", "-traces-exporter=console",
+		"-sampler=always_on",
+	}, io.Discard)
+	require.NoError(t, err)
+	require.NoError(t, c.exportEnv())
+	assert.Equal(t, map[string]string{
+		"OTEL_TRACES_EXPORTER":        "otlp,console",
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+	}, got)
+}
+
+func TestConfigPrint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=Bearer secret")
+	c, err := parseConfig("test", []string{"-target-exe=/usr/bin/app", "-disable-probe=database/sql"}, io.Discard)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, c.print(&out))
+	assert.Contains(t, out.String(), `target-exe: "/usr/bin/app" # flag`)
+	assert.Contains(t, out.String(), `disable-probe: ["database/sql"] # flag`)
+	assert.Contains(t, out.String(), `exporter-headers: "<redacted>" # env`)
+	assert.Contains(t, out.String(), "# sampler: unset")
+	assert.NotContains(t, out.String(), "secret")
+}
+
+func TestConfigSampler(t *testing.T) {
+	c, err := parseConfig("test", nil, io.Discard)
+	require.NoError(t, err)
+	assert.Nil(t, c.sampler())
+
+	c, err = parseConfig("test", []string{"-sampler=parentbased_traceidratio", "-sampler-arg=0.5"}, io.Discard)
+	require.NoError(t, err)
+	want := auto.DefaultSampler().(auto.ParentBasedSampler)
+	want.Root = auto.TraceIDRatioSampler{Fraction: 0.5}
+	assert.Equal(t, want, c.sampler())
+
+	c, err = parseConfig("test", []string{"-sampler=always_off"}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, auto.AlwaysOffSampler{}, c.sampler())
+}
+
+func TestConfigInstrumentationConfig(t *testing.T) {
+	c, err := parseConfig("test", []string{
+		"-disable-probe=database/sql",
+		"-disable-probe=net/http/server",
+		"-disable-probe=github.com/99designs/gqlgen/graphql/handler/internal",
+	}, io.Discard)
+	require.NoError(t, err)
+
+	ic := c.instrumentationConfig()
+	disabled := false
+	assert.Equal(t, map[auto.InstrumentationLibraryID]auto.InstrumentationLibrary{
+		{InstrumentedPkg: "database/sql"}:                             {TracesEnabled: &disabled},
+		{InstrumentedPkg: "net/http", SpanKind: trace.SpanKindServer}: {TracesEnabled: &disabled},
+		{
+			InstrumentedPkg: "github.com/99designs/gqlgen/graphql/handler",
+			SpanKind:        trace.SpanKindInternal,
+		}: {TracesEnabled: &disabled},
+	}, ic.InstrumentationLibraryConfigs)
+	assert.Nil(t, ic.Sampler)
+}
+
+func TestConfigAttributeFilters(t *testing.T) {
+	c, err := parseConfig("test", []string{
+		"-allow-attribute=net/http/server=http.*",
+		"-allow-attribute=net/http/server=url.path",
+		"-deny-attribute=net/http/server=http.request.header.*",
+		"-deny-attribute=database/sql/client=db.query.text",
+	}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]auto.AttributeFilter{
+		"net/http/server": {
+			Allow: []string{"http.*", "url.path"},
+			Deny:  []string{"http.request.header.*"},
+		},
+		"database/sql/client": {Deny: []string{"db.query.text"}},
+	}, c.attributeFilters())
+	assert.Len(t, c.instrumentationOptions(), 2)
+
+	for _, v := range []string{"db.query.text", "database/sql=db.query.text", "net/mail/client=*", "net/http/server=", "net/http/server=http.["} {
+		_, err = parseConfig("test", []string{"-deny-attribute=" + v}, io.Discard)
+		assert.Error(t, err, v)
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	var out bytes.Buffer
+	printVersion(&out)
+	assert.Contains(t, out.String(), auto.Version())
+	assert.Contains(t, out.String(), "  google.golang.org/grpc/server                                 google.golang.org/grpc                         v1.14.0 to v1.74.0\n")
+}
+
+func TestParseConfigFold(t *testing.T) {
+	t.Setenv(envLogLevelKey, "DEBUG")
+	t.Setenv("OTEL_TRACES_SAMPLER", " Always_Off ")
+	c, err := parseConfig("test", nil, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, auto.AlwaysOffSampler{}, c.sampler())
+	assert.Equal(t, "DEBUG", c.get("log-level"))
+}
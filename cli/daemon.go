@@ -0,0 +1,642 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"debug/buildinfo"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/zpages"
+	"go.opentelemetry.io/auto/pipeline"
+	"go.opentelemetry.io/auto/pipeline/otelsdk"
+)
+
+const (
+	// defaultMaxProcesses is the default maximum number of processes
+	// instrumented concurrently in daemon mode.
+	defaultMaxProcesses = 16
+	// clockTicks is the number of clock ticks per second (USER_HZ) of the
+	// process start times in /proc/<pid>/stat. It is 100 on all the
+	// architectures supported by the agent.
+	clockTicks = 100
+)
+
+// Overwritten in testing.
+var (
+	daemonScanInterval = 5 * time.Second
+	isGoExe            = func(pid int) bool {
+		_, err := buildinfo.ReadFile(procDir + "/" + strconv.Itoa(pid) + "/exe")
+		return err == nil
+	}
+)
+
+// proc is a process running on the host.
+type proc struct {
+	pid int
+	// start is the start time of the process in clock ticks since boot. It
+	// identifies the process with its PID, which can be reused.
+	start   uint64
+	exe     string
+	cgroups []string
+	uptime  time.Duration
+}
+
+// procKey identifies a process.
+type procKey struct {
+	pid   int
+	start uint64
+}
+
+func (p proc) key() procKey { return procKey{pid: p.pid, start: p.start} }
+
+// listProcs returns the processes running on the host, except the agent and
+// the kernel threads.
+func listProcs() ([]proc, error) {
+	data, err := osReadFile(procDir + "/uptime")
+	if err != nil {
+		return nil, err
+	}
+	f, _, _ := strings.Cut(string(data), " ")
+	sec, err := strconv.ParseFloat(f, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s/uptime: %w", procDir, err)
+	}
+	now := uint64(sec * clockTicks)
+
+	entries, err := osReadDir(procDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", procDir, err)
+	}
+
+	self := osGetpid()
+	var procs []proc
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() || pid == self {
+			continue
+		}
+		// Errors are expected for processes exiting while they are listed,
+		// and kernel threads have no executable.
+		dir := procDir + "/" + entry.Name()
+		exe, err := osReadlink(dir + "/exe")
+		if err != nil {
+			continue
+		}
+		stat, err := osReadFile(dir + "/stat")
+		if err != nil {
+			continue
+		}
+		start, ok := parseStartTime(stat)
+		if !ok {
+			continue
+		}
+		cgroups, _ := osReadFile(dir + "/cgroup")
+		procs = append(procs, proc{
+			pid:     pid,
+			start:   start,
+			exe:     exe,
+			cgroups: parseCgroups(cgroups),
+			uptime:  time.Duration(now-min(start, now)) * time.Second / clockTicks,
+		})
+	}
+	return procs, nil
+}
+
+// parseStartTime returns the start time of a process in clock ticks since
+// boot from the content of its /proc/<pid>/stat file.
+func parseStartTime(stat []byte) (uint64, bool) {
+	// The command name (2nd field) is in parentheses and can contain spaces
+	// and parentheses.
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, false
+	}
+	// The start time is the 22nd field, the first after the command name is
+	// the 3rd.
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return 0, false
+	}
+	start, err := strconv.ParseUint(fields[19], 10, 64)
+	return start, err == nil
+}
+
+// parseCgroups returns the cgroup paths of a process from the content of its
+// /proc/<pid>/cgroup file.
+func parseCgroups(data []byte) []string {
+	var out []string
+	for _, line := range strings.Split(string(data), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) == 3 && !slices.Contains(out, parts[2]) {
+			out = append(out, parts[2])
+		}
+	}
+	return out
+}
+
+// selector selects the processes instrumented in daemon mode.
+type selector struct {
+	// includeExe are the globs of the executable paths of the selected
+	// processes. All processes are selected if empty.
+	includeExe []string
+	excludeExe []string
+	// includeCgroup are the prefixes of the cgroup paths of the selected
+	// processes. All processes are selected if empty.
+	includeCgroup []string
+	excludeCgroup []string
+	minUptime     time.Duration
+}
+
+// matchExe returns if a process with the executable path exe is selected.
+func (s selector) matchExe(exe string) bool {
+	match := func(pattern string) bool {
+		ok, _ := filepath.Match(pattern, exe)
+		return ok
+	}
+	if len(s.includeExe) > 0 && !slices.ContainsFunc(s.includeExe, match) {
+		return false
+	}
+	return !slices.ContainsFunc(s.excludeExe, match)
+}
+
+// matchCgroups returns if a process in the cgroups is selected.
+func (s selector) matchCgroups(cgroups []string) bool {
+	anyPrefix := func(prefixes []string) bool {
+		return slices.ContainsFunc(cgroups, func(cg string) bool {
+			return slices.ContainsFunc(prefixes, func(p string) bool {
+				return strings.HasPrefix(cg, p)
+			})
+		})
+	}
+	if len(s.includeCgroup) > 0 && !anyPrefix(s.includeCgroup) {
+		return false
+	}
+	return !anyPrefix(s.excludeCgroup)
+}
+
+// Process states reported by the status API.
+const (
+	stateStarting = "starting"
+	stateRunning  = "running"
+	stateFailed   = "failed"
+	// stateDisabled is the state of the processes with instrumentation
+	// disabled by their OTEL_SDK_DISABLED environment variable.
+	stateDisabled = "disabled"
+)
+
+// target is a process instrumented in daemon mode.
+type target struct {
+	proc
+	service       string
+	serviceSource string
+	// instance is the service.instance.id of the process.
+	instance string
+	since    time.Time
+	stop     context.CancelFunc
+	done     chan struct{}
+	spans    atomic.Uint64
+
+	mu    sync.Mutex
+	state string
+	err   error
+	inst  *auto.Instrumentation
+}
+
+func (t *target) set(state string, inst *auto.Instrumentation, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state, t.err = state, err
+	if inst != nil {
+		t.inst = inst
+	}
+}
+
+func (t *target) failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state == stateFailed
+}
+
+func (t *target) disabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state == stateDisabled
+}
+
+// countingHandler is a [pipeline.TraceHandler] counting the spans of a target.
+type countingHandler struct {
+	next pipeline.TraceHandler
+	n    *atomic.Uint64
+}
+
+func (h countingHandler) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	h.n.Add(uint64(spans.Len()))
+	h.next.HandleTrace(scope, url, spans)
+}
+
+// daemon instruments all the selected Go processes of the host.
+type daemon struct {
+	logger *slog.Logger
+	sel    selector
+	max    int
+	// naming resolves the service names of the processes.
+	naming serviceNaming
+	// run instruments the target until ctx is done.
+	run func(ctx context.Context, t *target) error
+
+	mu      sync.Mutex
+	targets map[procKey]*target
+	// ignored are the processes that are never instrumented.
+	ignored map[procKey]struct{}
+	// capped is the number of selected processes not instrumented because
+	// max processes are.
+	capped int
+}
+
+func newDaemon(l *slog.Logger, sel selector, maxProcs int, run func(context.Context, *target) error) *daemon {
+	if maxProcs <= 0 {
+		maxProcs = defaultMaxProcesses
+	}
+	return &daemon{
+		logger:  l,
+		sel:     sel,
+		max:     maxProcs,
+		run:     run,
+		targets: make(map[procKey]*target),
+		ignored: make(map[procKey]struct{}),
+	}
+}
+
+// Run discovers and instruments processes until ctx is done.
+func (d *daemon) Run(ctx context.Context) {
+	ticker := time.NewTicker(daemonScanInterval)
+	defer ticker.Stop()
+
+	for {
+		if procs, err := listProcs(); err != nil {
+			d.logger.Error("failed to list processes", "error", err)
+		} else {
+			d.reconcile(ctx, procs)
+		}
+
+		select {
+		case <-ctx.Done():
+			d.stopAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile stops instrumenting the processes that exited, and starts
+// instrumenting the selected processes of procs, oldest first, up to the
+// maximum number of processes.
+func (d *daemon) reconcile(ctx context.Context, procs []proc) {
+	alive := make(map[procKey]struct{}, len(procs))
+	for _, p := range procs {
+		alive[p.key()] = struct{}{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for k, t := range d.targets {
+		if _, ok := alive[k]; !ok {
+			t.stop()
+			delete(d.targets, k)
+			if !t.disabled() {
+				d.logger.Info("instrumented process exited", "PID", t.pid, "executable", t.exe)
+			}
+		}
+	}
+	for k := range d.ignored {
+		if _, ok := alive[k]; !ok {
+			delete(d.ignored, k)
+		}
+	}
+
+	candidates := d.selectProcs(procs)
+
+	var running int
+	for _, t := range d.targets {
+		if !t.failed() && !t.disabled() {
+			running++
+		}
+	}
+	d.capped = 0
+	for i, p := range candidates {
+		if running >= d.max {
+			d.capped = len(candidates) - i
+			d.logger.Debug("maximum number of instrumented processes reached", "max", d.max, "skipped", d.capped)
+			break
+		}
+		d.targets[p.key()] = d.start(ctx, p)
+		running++
+	}
+}
+
+// selectProcs returns the selected processes of procs that are not
+// instrumented yet, the longest-running first. Selected processes with
+// instrumentation disabled by OTEL_SDK_DISABLED are added to the targets
+// instead. It must be called with d.mu held.
+func (d *daemon) selectProcs(procs []proc) []proc {
+	var candidates []proc
+	for _, p := range procs {
+		k := p.key()
+		if _, ok := d.targets[k]; ok {
+			continue
+		}
+		if _, ok := d.ignored[k]; ok {
+			continue
+		}
+		if !d.sel.matchExe(p.exe) || !d.sel.matchCgroups(p.cgroups) {
+			d.ignored[k] = struct{}{}
+			continue
+		}
+		if p.uptime < d.sel.minUptime {
+			// Selected once it has been running long enough.
+			continue
+		}
+		if !isGoExe(p.pid) {
+			d.ignored[k] = struct{}{}
+			continue
+		}
+		if sdkDisabled(environ(p.pid)) {
+			d.targets[k] = d.disable(p)
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+	slices.SortFunc(candidates, func(a, b proc) int {
+		return cmp.Or(cmp.Compare(a.start, b.start), cmp.Compare(a.pid, b.pid))
+	})
+	return candidates
+}
+
+func (d *daemon) start(ctx context.Context, p proc) *target {
+	service, source := d.naming.resolve(p)
+	t := &target{
+		proc:          p,
+		service:       service,
+		serviceSource: source,
+		// A new instance ID is used if the process is instrumented again.
+		instance: uuid.NewString(),
+		since:    time.Now(),
+		done:     make(chan struct{}),
+		state:    stateStarting,
+	}
+	ctx, t.stop = context.WithCancel(ctx)
+	d.logger.Info("instrumenting process", "PID", p.pid, "executable", p.exe, "service", t.service, "service_source", source, "service_instance_id", t.instance)
+
+	go func() {
+		defer close(t.done)
+		if err := d.run(ctx, t); err != nil {
+			t.set(stateFailed, nil, err)
+			d.logger.Error("failed to instrument process", "PID", p.pid, "executable", p.exe, "error", err)
+		}
+	}()
+	return t
+}
+
+// disable returns the target of p, not instrumented as its instrumentation is
+// disabled by OTEL_SDK_DISABLED. The target is reported by the status API
+// until the process exits.
+func (d *daemon) disable(p proc) *target {
+	service, source := d.naming.resolve(p)
+	t := &target{
+		proc:          p,
+		service:       service,
+		serviceSource: source,
+		since:         time.Now(),
+		stop:          func() {},
+		done:          make(chan struct{}),
+		state:         stateDisabled,
+	}
+	close(t.done)
+	d.logger.Info("instrumentation disabled by "+envSDKDisabledKey+", not instrumenting process", "PID", p.pid, "executable", p.exe, "service", service)
+	return t
+}
+
+func (d *daemon) stopAll() {
+	d.mu.Lock()
+	targets := make([]*target, 0, len(d.targets))
+	for k, t := range d.targets {
+		t.stop()
+		targets = append(targets, t)
+		delete(d.targets, k)
+	}
+	d.mu.Unlock()
+
+	for _, t := range targets {
+		<-t.done
+	}
+}
+
+// instrument returns a function instrumenting a target with the options
+// opts, exporting its telemetry with m.
+func instrument(m *otelsdk.Multiplexer, opts []auto.InstrumentationOption) func(context.Context, *target) error {
+	return func(ctx context.Context, t *target) error {
+		attrs := append([]attribute.KeyValue{
+			semconv.ServiceName(t.service),
+			semconv.ServiceInstanceID(t.instance),
+			semconv.ProcessPID(t.pid),
+			semconv.ProcessExecutablePath(t.exe),
+			semconv.ProcessExecutableName(filepath.Base(t.exe)),
+		}, k8sAttrs(t.pid)...)
+		h := m.HandlerWithAttributes(t.pid, attrs...)
+		h.TraceHandler = countingHandler{next: h.TraceHandler, n: &t.spans}
+
+		opts := append(slices.Clip(opts), auto.WithPID(t.pid), auto.WithHandler(h))
+		inst, err := auto.NewInstrumentation(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		t.set(stateStarting, inst, nil)
+		if err := inst.Load(ctx); err != nil {
+			return errors.Join(err, inst.Close())
+		}
+		t.set(stateRunning, nil, nil)
+		if err := inst.Run(ctx); ctx.Err() == nil {
+			return err
+		}
+		// Stopped by the daemon.
+		return nil
+	}
+}
+
+// processStatus is the status of an instrumented process reported by the
+// status API.
+type processStatus struct {
+	PID            int          `json:"pid"`
+	Executable     string       `json:"executable"`
+	ServiceName    string       `json:"service_name"`
+	ServiceSource  string       `json:"service_name_source"`
+	InstanceID     string       `json:"service_instance_id"`
+	Uptime         string       `json:"uptime"`
+	InstrumentedAt time.Time    `json:"instrumented_at"`
+	State          string       `json:"state"`
+	Error          string       `json:"error,omitempty"`
+	Spans          uint64       `json:"spans"`
+	Usage          *usageStatus `json:"usage,omitempty"`
+}
+
+// usageStatus is the resource usage of the instrumentation of a process.
+type usageStatus struct {
+	Probes     int    `json:"probes"`
+	Events     uint64 `json:"events"`
+	LostEvents uint64 `json:"lost_events"`
+	MapEntries uint64 `json:"map_entries"`
+}
+
+type daemonStatus struct {
+	MaxProcesses int             `json:"max_processes"`
+	Capped       int             `json:"capped"`
+	Processes    []processStatus `json:"processes"`
+}
+
+func (d *daemon) status() daemonStatus {
+	d.mu.Lock()
+	s := daemonStatus{MaxProcesses: d.max, Capped: d.capped}
+	targets := make([]*target, 0, len(d.targets))
+	for _, t := range d.targets {
+		targets = append(targets, t)
+	}
+	d.mu.Unlock()
+
+	for _, t := range targets {
+		t.mu.Lock()
+		ps := processStatus{
+			PID:            t.pid,
+			Executable:     t.exe,
+			ServiceName:    t.service,
+			ServiceSource:  t.serviceSource,
+			InstanceID:     t.instance,
+			Uptime:         (t.uptime + time.Since(t.since)).Truncate(time.Second).String(),
+			InstrumentedAt: t.since,
+			State:          t.state,
+			Spans:          t.spans.Load(),
+		}
+		if t.err != nil {
+			ps.Error = t.err.Error()
+		}
+		inst := t.inst
+		t.mu.Unlock()
+
+		if inst != nil && ps.State == stateRunning {
+			u := inst.Usage()
+			ps.Usage = &usageStatus{
+				Probes:     u.Probes,
+				Events:     u.Events,
+				LostEvents: u.LostEvents,
+				MapEntries: u.MapEntries,
+			}
+		}
+		s.Processes = append(s.Processes, ps)
+	}
+	slices.SortFunc(s.Processes, func(a, b processStatus) int { return cmp.Compare(a.PID, b.PID) })
+	return s
+}
+
+// ServeHTTP serves the status of the daemon as JSON.
+func (d *daemon) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(d.status())
+}
+
+// runDaemon instruments all the selected Go processes of the host until ctx
+// is done.
+func runDaemon(ctx context.Context, l *slog.Logger, c *config, opts []auto.InstrumentationOption) error {
+	m, err := otelsdk.NewMultiplexer(ctx, otelsdk.WithEnv(), otelsdk.WithLogger(l))
+	if err != nil {
+		return fmt.Errorf("failed to create OTel SDK multiplexer: %w", err)
+	}
+
+	sel := selector{
+		includeExe:    c.list("include-exe"),
+		excludeExe:    c.list("exclude-exe"),
+		includeCgroup: c.list("include-cgroup"),
+		excludeCgroup: c.list("exclude-cgroup"),
+	}
+	if v := c.get("min-uptime"); v != "" {
+		// Validated when parsed.
+		sel.minUptime, _ = time.ParseDuration(v)
+	}
+	maxProcs, _ := strconv.Atoi(c.get("max-processes"))
+
+	// The debugging servers of the processes would listen on the same
+	// address.
+	opts = append(slices.Clip(opts), auto.WithDebugServer("", 0), auto.WithDebugProfiling(""))
+	d := newDaemon(l, sel, maxProcs, instrument(m, opts))
+	for _, v := range c.list("service-name") {
+		// Validated when parsed.
+		r, _ := parseServiceRule(v)
+		d.naming.rules = append(d.naming.rules, r)
+	}
+	d.naming.sources = c.list("service-name-source")
+	d.naming.label = c.get("service-name-label")
+
+	if addr := c.get("status-addr"); addr != "" {
+		ln, err := zpages.Listen(addr)
+		if err != nil {
+			return errors.Join(fmt.Errorf("status API: %w", err), m.Shutdown(context.Background()))
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/status", d)
+		go func() {
+			if err := zpages.Serve(ctx, l, ln, mux); err != nil {
+				l.Error("status API failed", "error", err)
+			}
+		}()
+	}
+
+	if err := serveHealth(ctx, l, c, daemonState(d), m.ExportStatus); err != nil {
+		return errors.Join(err, m.Shutdown(context.Background()))
+	}
+
+	if c.dryRun {
+		procs, err := listProcs()
+		if err != nil {
+			return errors.Join(err, m.Shutdown(context.Background()))
+		}
+		d.mu.Lock()
+		selected := d.selectProcs(procs)
+		d.mu.Unlock()
+		for i, p := range selected {
+			service, source := d.naming.resolve(p)
+			l.Info("dry run: process selected", "PID", p.pid, "executable", p.exe, "service", service, "service_source", source, "capped", i >= d.max)
+		}
+		l.Info("dry run: configuration is valid, exiting without instrumenting", "selected", len(selected), "max_processes", d.max)
+		return m.Shutdown(context.Background())
+	}
+
+	l.Info("instrumenting all Go processes", "max_processes", d.max)
+	d.Run(ctx)
+
+	l.Info("shutting down")
+	return m.Shutdown(context.Background())
+}
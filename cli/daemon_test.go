@@ -0,0 +1,254 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStartTime(t *testing.T) {
+	stat := "1234 (my (odd) app) S 1 1234 1234 0 -1 4194560 1000 0 0 0 10 5 0 0 20 0 12 0 98765 1234567 890 18446744073709551615"
+	start, ok := parseStartTime([]byte(stat))
+	require.True(t, ok)
+	assert.Equal(t, uint64(98765), start)
+
+	_, ok = parseStartTime([]byte("1234 (app) S 1"))
+	assert.False(t, ok)
+}
+
+func TestParseCgroups(t *testing.T) {
+	// cgroup v2.
+	assert.Equal(t, []string{"/system.slice/app.service"}, parseCgroups([]byte("0::/system.slice/app.service\n")))
+	// cgroup v1.
+	v1 := "12:memory:/kubepods/pod1\n11:cpu,cpuacct:/kubepods/pod1\n1:name=systemd:/system.slice/docker.service\n"
+	assert.Equal(t, []string{"/kubepods/pod1", "/system.slice/docker.service"}, parseCgroups([]byte(v1)))
+}
+
+func TestSelector(t *testing.T) {
+	s := selector{
+		includeExe: []string{"/usr/local/bin/*", "/opt/*/bin/*"},
+		excludeExe: []string{"/usr/local/bin/debug-*"},
+	}
+	assert.True(t, s.matchExe("/usr/local/bin/app"))
+	assert.True(t, s.matchExe("/opt/svc/bin/app"))
+	assert.False(t, s.matchExe("/usr/local/bin/debug-app"))
+	assert.False(t, s.matchExe("/usr/bin/app"))
+
+	s = selector{
+		includeCgroup: []string{"/system.slice/"},
+		excludeCgroup: []string{"/system.slice/sshd.service"},
+	}
+	assert.True(t, s.matchCgroups([]string{"/system.slice/app.service"}))
+	assert.False(t, s.matchCgroups([]string{"/system.slice/sshd.service"}))
+	assert.False(t, s.matchCgroups([]string{"/user.slice/user-1000.slice"}))
+	assert.True(t, selector{}.matchCgroups(nil))
+}
+
+func TestListProcs(t *testing.T) {
+	t.Cleanup(mock())
+	origGetpid := osGetpid
+	t.Cleanup(func() { osGetpid = origGetpid })
+	osGetpid = func() int { return 100 }
+
+	readFile := osReadFile
+	osReadFile = func(name string) ([]byte, error) {
+		switch {
+		case name == procDir+"/uptime":
+			return []byte("1000.50 4000.00\n"), nil
+		case strings.HasSuffix(name, "/stat"):
+			// Started 10s after boot.
+			return []byte("1 (app) S" + strings.Repeat(" 0", 18) + " 1000 0"), nil
+		case strings.HasSuffix(name, "/cgroup"):
+			return []byte("0::/system.slice/app.service\n"), nil
+		}
+		return readFile(name)
+	}
+
+	procs, err := listProcs()
+	require.NoError(t, err)
+	var pids []int
+	for _, p := range procs {
+		pids = append(pids, p.pid)
+		assert.Equal(t, uint64(1000), p.start)
+		assert.Equal(t, 990*time.Second+500*time.Millisecond, p.uptime)
+		assert.Equal(t, []string{"/system.slice/app.service"}, p.cgroups)
+	}
+	// The agent (100), files, and processes without an executable (1001)
+	// are not listed.
+	assert.Equal(t, []int{0, appPathPID, 9000}, pids)
+}
+
+// fakeRunner records the instrumented targets.
+type fakeRunner struct {
+	mu      sync.Mutex
+	running map[int]bool
+	fail    map[int]bool
+}
+
+func (r *fakeRunner) run(ctx context.Context, t *target) error {
+	r.mu.Lock()
+	if r.fail[t.pid] {
+		r.mu.Unlock()
+		return errors.New("unsupported")
+	}
+	r.running[t.pid] = true
+	r.mu.Unlock()
+
+	t.set(stateRunning, nil, nil)
+	<-ctx.Done()
+
+	r.mu.Lock()
+	delete(r.running, t.pid)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *fakeRunner) pids() map[int]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[int]bool, len(r.running))
+	for pid := range r.running {
+		out[pid] = true
+	}
+	return out
+}
+
+func TestDaemonReconcile(t *testing.T) {
+	origIsGo := isGoExe
+	t.Cleanup(func() { isGoExe = origIsGo })
+	// Odd PIDs are Go processes.
+	isGoExe = func(pid int) bool { return pid%2 == 1 }
+
+	r := &fakeRunner{running: make(map[int]bool), fail: map[int]bool{7: true}}
+	d := newDaemon(discardLogger, selector{
+		excludeExe: []string{"/usr/bin/excluded"},
+		minUptime:  time.Minute,
+	}, 2, r.run)
+
+	procs := []proc{
+		{pid: 1, start: 500, exe: "/usr/bin/young", uptime: 2 * time.Minute},
+		{pid: 2, start: 100, exe: "/usr/bin/not-go", uptime: time.Hour},
+		{pid: 3, start: 300, exe: "/usr/bin/older", uptime: 10 * time.Minute},
+		{pid: 5, start: 200, exe: "/usr/bin/oldest", uptime: 20 * time.Minute},
+		{pid: 9, start: 100, exe: "/usr/bin/excluded", uptime: time.Hour},
+		{pid: 11, start: 900, exe: "/usr/bin/new", uptime: time.Second},
+	}
+	ctx := context.Background()
+	d.reconcile(ctx, procs)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[int]bool{3: true, 5: true}, r.pids())
+	}, time.Second, time.Millisecond, "longest-running processes not instrumented first")
+	assert.Equal(t, 1, d.status().Capped)
+
+	// The oldest instrumented process exits.
+	procs = append(procs[:3], procs[4:]...)
+	d.reconcile(ctx, procs)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[int]bool{1: true, 3: true}, r.pids())
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 0, d.status().Capped)
+
+	// A failed process does not count towards the maximum.
+	d.max = 3
+	procs = append(procs, proc{pid: 7, start: 50, exe: "/usr/bin/unsupported", uptime: time.Hour})
+	d.reconcile(ctx, procs)
+	assert.Eventually(t, func() bool {
+		for _, p := range d.status().Processes {
+			if p.PID == 7 {
+				return p.State == stateFailed && p.Error == "unsupported"
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond)
+	// Instrumented once the minimum uptime is reached.
+	procs[len(procs)-2].uptime = time.Hour
+	d.reconcile(ctx, procs)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[int]bool{1: true, 3: true, 11: true}, r.pids())
+	}, time.Second, time.Millisecond)
+
+	d.stopAll()
+	assert.Empty(t, r.pids())
+}
+
+func TestDaemonSDKDisabled(t *testing.T) {
+	origIsGo := isGoExe
+	t.Cleanup(func() { isGoExe = origIsGo })
+	isGoExe = func(int) bool { return true }
+	readFile := osReadFile
+	t.Cleanup(func() { osReadFile = readFile })
+	osReadFile = func(name string) ([]byte, error) {
+		if name == procDir+"/3/environ" {
+			return []byte("HOME=/\x00OTEL_SDK_DISABLED=TRUE\x00"), nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	r := &fakeRunner{running: make(map[int]bool)}
+	d := newDaemon(discardLogger, selector{}, 1, r.run)
+	t.Cleanup(d.stopAll)
+
+	procs := []proc{
+		{pid: 3, start: 1, exe: "/usr/bin/disabled"},
+		{pid: 5, start: 2, exe: "/usr/bin/app"},
+	}
+	d.reconcile(context.Background(), procs)
+	// A disabled process does not count towards the maximum.
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[int]bool{5: true}, r.pids())
+	}, time.Second, time.Millisecond)
+
+	s := d.status()
+	require.Len(t, s.Processes, 2)
+	assert.Equal(t, 3, s.Processes[0].PID)
+	assert.Equal(t, stateDisabled, s.Processes[0].State)
+	assert.Nil(t, s.Processes[0].Usage)
+
+	// Not instrumented when checked again.
+	d.reconcile(context.Background(), procs)
+	assert.Equal(t, map[int]bool{5: true}, r.pids())
+	assert.Equal(t, stateDisabled, d.status().Processes[0].State)
+
+	// Removed once exited.
+	d.reconcile(context.Background(), procs[1:])
+	require.Len(t, d.status().Processes, 1)
+	assert.Equal(t, 5, d.status().Processes[0].PID)
+}
+
+func TestDaemonStatus(t *testing.T) {
+	r := &fakeRunner{running: make(map[int]bool)}
+	d := newDaemon(discardLogger, selector{}, 0, r.run)
+	assert.Equal(t, defaultMaxProcesses, d.max)
+
+	origIsGo := isGoExe
+	t.Cleanup(func() { isGoExe = origIsGo })
+	isGoExe = func(int) bool { return true }
+
+	d.reconcile(context.Background(), []proc{{pid: 1, start: 1, exe: "/usr/bin/app", uptime: time.Hour}})
+	t.Cleanup(d.stopAll)
+	d.targets[procKey{pid: 1, start: 1}].spans.Add(3)
+
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var got daemonStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, defaultMaxProcesses, got.MaxProcesses)
+	require.Len(t, got.Processes, 1)
+	assert.Equal(t, 1, got.Processes[0].PID)
+	assert.Equal(t, "app", got.Processes[0].ServiceName)
+	assert.Len(t, got.Processes[0].InstanceID, 36)
+	assert.Equal(t, uint64(3), got.Processes[0].Spans)
+}
@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/auto/pipeline/otelsdk"
+)
+
+// stateWaiting is the state of the agent before the target process is found.
+const stateWaiting = "waiting"
+
+// Exporter states reported by the readiness endpoint.
+const (
+	exporterPending   = "pending"
+	exporterConnected = "connected"
+	exporterFailing   = "failing"
+)
+
+// healthCheckInterval is the interval the readiness is evaluated at to log
+// its transitions.
+var healthCheckInterval = 5 * time.Second
+
+// instState is the state of the instrumentation the readiness is derived
+// from.
+type instState struct {
+	state string
+	err   error
+	// probes is the number of probes attached.
+	probes int
+}
+
+// targetState returns the state of the instrumentation of t.
+func targetState(t *target) func() instState {
+	return func() instState {
+		t.mu.Lock()
+		state, err, inst := t.state, t.err, t.inst
+		t.mu.Unlock()
+
+		s := instState{state: state, err: err}
+		if inst != nil && state == stateRunning {
+			s.probes = inst.Usage().Probes
+		}
+		return s
+	}
+}
+
+// daemonState returns the state of the instrumentation of d: running if a
+// process is instrumented, waiting otherwise. Failed processes do not affect
+// the state.
+func daemonState(d *daemon) func() instState {
+	return func() instState {
+		s := instState{state: stateWaiting}
+		for _, p := range d.status().Processes {
+			switch p.State {
+			case stateRunning:
+				s.state = stateRunning
+				if p.Usage != nil {
+					s.probes += p.Usage.Probes
+				}
+			case stateStarting:
+				if s.state == stateWaiting {
+					s.state = stateStarting
+				}
+			}
+		}
+		return s
+	}
+}
+
+// readiness is the readiness of the agent.
+type readiness struct {
+	Ready    bool   `json:"ready"`
+	State    string `json:"state"`
+	Probes   int    `json:"probes"`
+	Exporter string `json:"exporter"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// health serves the liveness and readiness of the agent.
+//
+// The agent is ready once the target process is found, its probes are
+// attached, and the exporter is connected: the collector accepted an export
+// request, or no export was attempted yet. If readyWhileWaiting is true, the
+// agent is also ready while it waits for the target process.
+type health struct {
+	logger            *slog.Logger
+	readyWhileWaiting bool
+	state             func() instState
+	// export returns the export status, nil if unknown.
+	export func() otelsdk.ExportStatus
+
+	mu   sync.Mutex
+	last *readiness
+}
+
+func (h *health) check() readiness {
+	s := h.state()
+	r := readiness{State: s.state, Probes: s.probes, Exporter: exporterPending}
+	if h.export != nil {
+		switch status := h.export(); {
+		case !status.LastExportTime.IsZero():
+			r.Exporter = exporterConnected
+		case status.LastExportError != "":
+			r.Exporter = exporterFailing
+			r.Reason = "exporter never connected: " + status.LastExportError
+		}
+	}
+
+	switch s.state {
+	case stateWaiting:
+		r.Ready, r.Reason = h.readyWhileWaiting, "waiting for the target process"
+	case stateStarting:
+		r.Reason = "attaching probes"
+	case stateDisabled:
+		// Intentionally not instrumenting is not a failure.
+		r.Ready, r.Reason = true, "instrumentation disabled by "+envSDKDisabledKey
+	case stateFailed:
+		r.Reason = "instrumentation failed"
+		if s.err != nil {
+			r.Reason += ": " + s.err.Error()
+		}
+	case stateRunning:
+		if s.probes == 0 {
+			r.Reason = "no probe attached"
+		} else if r.Exporter != exporterFailing {
+			r.Ready, r.Reason = true, ""
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.last == nil || h.last.Ready != r.Ready || h.last.State != r.State || h.last.Exporter != r.Exporter {
+		lvl := slog.LevelInfo
+		if !r.Ready && h.last != nil && h.last.Ready {
+			lvl = slog.LevelWarn
+		}
+		h.logger.Log(context.Background(), lvl, "readiness changed",
+			"ready", r.Ready,
+			"state", r.State,
+			"probes", r.Probes,
+			"exporter", r.Exporter,
+			"reason", r.Reason,
+		)
+	}
+	h.last = &r
+	return r
+}
+
+// Handler returns an [http.Handler] serving:
+//
+//   - /healthz: the agent is up
+//   - /readyz: the readiness of the agent as JSON, with the 503 status code
+//     if it is not ready
+func (h *health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		r := h.check()
+		w.Header().Set("Content-Type", "application/json")
+		if !r.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(r)
+	})
+	return mux
+}
+
+// serveHealth serves the health endpoints on the health-addr address of c, if
+// set, until ctx is done.
+//
+// Unlike the debugging servers, the address is not restricted to loopback
+// addresses as the endpoints are probed by the kubelet.
+func serveHealth(ctx context.Context, l *slog.Logger, c *config, state func() instState, export func() otelsdk.ExportStatus) error {
+	addr := c.get("health-addr")
+	if addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("health endpoints: %w", err)
+	}
+	h := &health{
+		logger:            l,
+		readyWhileWaiting: c.enabled("ready-while-waiting"),
+		state:             state,
+		export:            export,
+	}
+	srv := &http.Server{Handler: h.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(shutdownCtx)
+				return
+			case <-ticker.C:
+				h.check()
+			}
+		}
+	}()
+	go func() {
+		l.Info("serving health endpoints", "address", ln.Addr().String())
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			l.Error("health endpoints failed", "error", err)
+		}
+	}()
+	return nil
+}
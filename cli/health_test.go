@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/auto/pipeline/otelsdk"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var (
+		state  instState
+		export otelsdk.ExportStatus
+		logs   bytes.Buffer
+	)
+	h := &health{
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		state:  func() instState { return state },
+		export: func() otelsdk.ExportStatus { return export },
+	}
+
+	state = instState{state: stateWaiting}
+	r := h.check()
+	assert.False(t, r.Ready)
+	assert.Equal(t, "waiting for the target process", r.Reason)
+	h.readyWhileWaiting = true
+	assert.Equal(t, readiness{
+		Ready:    true,
+		State:    stateWaiting,
+		Exporter: exporterPending,
+		Reason:   "waiting for the target process",
+	}, h.check())
+
+	state = instState{state: stateStarting}
+	assert.False(t, h.check().Ready)
+
+	state = instState{state: stateRunning}
+	r = h.check()
+	assert.False(t, r.Ready)
+	assert.Equal(t, "no probe attached", r.Reason)
+
+	// Ready before any export is attempted.
+	state.probes = 3
+	assert.Equal(t, readiness{Ready: true, State: stateRunning, Probes: 3, Exporter: exporterPending}, h.check())
+
+	export.LastExportError = "connection refused"
+	r = h.check()
+	assert.False(t, r.Ready)
+	assert.Equal(t, exporterFailing, r.Exporter)
+	assert.Equal(t, "exporter never connected: connection refused", r.Reason)
+
+	// Connected at least once.
+	export.LastExportTime = time.Now()
+	assert.Equal(t, readiness{Ready: true, State: stateRunning, Probes: 3, Exporter: exporterConnected}, h.check())
+
+	state = instState{state: stateFailed, err: errors.New("crashed")}
+	r = h.check()
+	assert.False(t, r.Ready)
+	assert.Equal(t, "instrumentation failed: crashed", r.Reason)
+
+	// Intentionally disabled.
+	state = instState{state: stateDisabled}
+	r = h.check()
+	assert.True(t, r.Ready)
+	assert.Equal(t, "instrumentation disabled by OTEL_SDK_DISABLED", r.Reason)
+
+	// Only transitions are logged.
+	h.check()
+	assert.Equal(t, 9, strings.Count(logs.String(), "readiness changed"))
+	assert.Equal(t, 3, strings.Count(logs.String(), "level=WARN"))
+}
+
+func TestHealthHandler(t *testing.T) {
+	state := instState{state: stateWaiting}
+	h := &health{logger: discardLogger, state: func() instState { return state }}
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	get := func() (int, readiness) {
+		resp, err := http.Get(srv.URL + "/readyz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var r readiness
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&r))
+		return resp.StatusCode, r
+	}
+
+	code, r := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, stateWaiting, r.State)
+
+	state = instState{state: stateRunning, probes: 1}
+	code, r = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, r.Ready)
+}
+
+func TestDaemonState(t *testing.T) {
+	r := &fakeRunner{running: make(map[int]bool), fail: map[int]bool{3: true}}
+	d := newDaemon(discardLogger, selector{}, 2, r.run)
+	state := daemonState(d)
+	assert.Equal(t, instState{state: stateWaiting}, state())
+
+	origIsGo := isGoExe
+	t.Cleanup(func() { isGoExe = origIsGo })
+	isGoExe = func(int) bool { return true }
+
+	// A failed process does not make the agent ready.
+	d.reconcile(context.Background(), []proc{{pid: 3, start: 1, exe: "/usr/bin/old"}})
+	t.Cleanup(d.stopAll)
+	assert.Eventually(t, func() bool {
+		return len(d.status().Processes) == 1 && d.status().Processes[0].State == stateFailed
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, instState{state: stateWaiting}, state())
+
+	d.reconcile(context.Background(), []proc{{pid: 3, start: 1, exe: "/usr/bin/old"}, {pid: 5, start: 2, exe: "/usr/bin/app"}})
+	assert.Eventually(t, func() bool { return state().state == stateRunning }, time.Second, time.Millisecond)
+}
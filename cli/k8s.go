@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+const (
+	// podInfoDir is the conventional mount path of the downward API volume.
+	podInfoDir = "/etc/podinfo"
+	// serviceAccountNamespace is the file of the mounted service account
+	// token volume containing the namespace of the pod.
+	serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// k8sField is a Kubernetes resource attribute read from the downward API.
+type k8sField struct {
+	key attribute.Key
+	// env are the environment variables the downward API conventionally
+	// exposes the value with, in order of precedence.
+	env []string
+	// file is the file name of the value in the downward API volume.
+	file string
+}
+
+var k8sFields = []k8sField{
+	{key: semconv.K8SPodNameKey, env: []string{"K8S_POD_NAME", "POD_NAME"}, file: "pod_name"},
+	{key: semconv.K8SNamespaceNameKey, env: []string{"K8S_NAMESPACE_NAME", "POD_NAMESPACE"}, file: "pod_namespace"},
+	{key: semconv.K8SNodeNameKey, env: []string{"K8S_NODE_NAME", "NODE_NAME"}, file: "node_name"},
+	{key: semconv.K8SContainerNameKey, env: []string{"K8S_CONTAINER_NAME", "CONTAINER_NAME"}, file: "container_name"},
+	{key: semconv.K8SPodUIDKey, env: []string{"K8S_POD_UID", "POD_UID"}, file: "pod_uid"},
+}
+
+// podInfo is the Kubernetes metadata of a process.
+type podInfo map[attribute.Key]string
+
+// readPodInfo returns the metadata exposed by the downward API in the
+// environment variables lookup returns and the files of the root filesystem.
+func readPodInfo(lookup func(string) (string, bool), root string) podInfo {
+	info := make(podInfo)
+	for _, f := range k8sFields {
+		for _, key := range f.env {
+			if v, ok := lookup(key); ok && v != "" {
+				info[f.key] = v
+				break
+			}
+		}
+		if info[f.key] != "" {
+			continue
+		}
+		if v := readValue(root + podInfoDir + "/" + f.file); v != "" {
+			info[f.key] = v
+		}
+	}
+	if info[semconv.K8SNamespaceNameKey] == "" {
+		if v := readValue(root + serviceAccountNamespace); v != "" {
+			info[semconv.K8SNamespaceNameKey] = v
+		}
+	}
+	return info
+}
+
+func readValue(path string) string {
+	data, err := osReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// environ returns a lookup function of the environment variables of the
+// process.
+func environ(pid int) func(string) (string, bool) {
+	data, _ := osReadFile(procDir + "/" + strconv.Itoa(pid) + "/environ")
+	return func(key string) (string, bool) {
+		for _, kv := range bytes.Split(data, []byte{0}) {
+			if v, ok := bytes.CutPrefix(kv, []byte(key+"=")); ok {
+				return string(v), true
+			}
+		}
+		return "", false
+	}
+}
+
+var (
+	// podUIDRe matches the pod UID of the cgroup paths of the kubelet with
+	// the cgroupfs ("pod<uid>") and systemd ("-pod<uid_with_underscores>.slice")
+	// cgroup drivers. Static pods have a UID without dashes.
+	podUIDRe = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12}|[0-9a-f]{32})(?:\.slice)?(?:/|$)`)
+	// containerIDRe matches the container ID of the last element of a cgroup
+	// path: "<id>" with the cgroupfs driver, "<runtime>-<id>.scope" with the
+	// systemd driver (cri-containerd, crio, docker).
+	containerIDRe = regexp.MustCompile(`^(?:[a-z-]+-)?([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// cgroupInfo is the pod and container a process belongs to according to its
+// cgroup.
+type cgroupInfo struct {
+	podUID      string
+	containerID string
+	// sibling is true if the cgroup is a sibling of the cgroup namespace root
+	// of the reader, i.e. a container of the same pod as the agent running
+	// in a private cgroup namespace.
+	sibling bool
+}
+
+// parseCgroupInfo returns the pod UID and container ID found in the cgroup
+// paths of a process, as returned by parseCgroups. Both cgroup v1 and v2
+// layouts are supported.
+func parseCgroupInfo(paths []string) cgroupInfo {
+	var info cgroupInfo
+	for _, p := range paths {
+		if m := podUIDRe.FindStringSubmatch(p); m != nil && info.podUID == "" {
+			info.podUID = strings.ReplaceAll(m[1], "_", "-")
+		}
+		elems := strings.Split(strings.TrimSuffix(p, "/"), "/")
+		last := elems[len(elems)-1]
+		// Ignore the conmon process of CRI-O containers.
+		if strings.HasPrefix(last, "crio-conmon-") {
+			continue
+		}
+		if m := containerIDRe.FindStringSubmatch(last); m != nil && info.containerID == "" {
+			info.containerID = m[1]
+			info.sibling = len(elems) == 3 && elems[0] == "" && elems[1] == ".."
+		}
+	}
+	return info
+}
+
+func readCgroupInfo(pid int) cgroupInfo {
+	data, err := osReadFile(procDir + "/" + strconv.Itoa(pid) + "/cgroup")
+	if err != nil {
+		return cgroupInfo{}
+	}
+	return parseCgroupInfo(parseCgroups(data))
+}
+
+// k8sAttrs returns the Kubernetes resource attributes of the process.
+//
+// The metadata is resolved from the downward API environment variables and
+// volume of the process, the pod UID and container ID of its cgroup, and the
+// downward API of the agent when the process runs in the same pod (sidecar)
+// or container. Only the node name of the agent is used for processes of
+// other pods (DaemonSet). Attributes defined in OTEL_RESOURCE_ATTRIBUTES are
+// not overridden.
+func k8sAttrs(pid int) []attribute.KeyValue {
+	root := procDir + "/" + strconv.Itoa(pid) + "/root"
+	env := environ(pid)
+	target := readPodInfo(env, root)
+	cg := readCgroupInfo(pid)
+	if target[semconv.K8SPodUIDKey] == "" {
+		target[semconv.K8SPodUIDKey] = cg.podUID
+	}
+
+	self := osGetpid()
+	agent := readPodInfo(lookupEnv, "")
+	agentCg := readCgroupInfo(self)
+	if agent[semconv.K8SPodUIDKey] == "" {
+		agent[semconv.K8SPodUIDKey] = agentCg.podUID
+	}
+
+	uid := target[semconv.K8SPodUIDKey]
+	var inherit []attribute.Key
+	switch {
+	case pid == self || (cg.containerID != "" && cg.containerID == agentCg.containerID):
+		inherit = []attribute.Key{
+			semconv.K8SPodNameKey, semconv.K8SNamespaceNameKey,
+			semconv.K8SNodeNameKey, semconv.K8SContainerNameKey,
+			semconv.K8SPodUIDKey,
+		}
+	case cg.sibling || (uid != "" && uid == agent[semconv.K8SPodUIDKey]):
+		inherit = []attribute.Key{
+			semconv.K8SPodNameKey, semconv.K8SNamespaceNameKey,
+			semconv.K8SNodeNameKey, semconv.K8SPodUIDKey,
+		}
+	default:
+		inherit = []attribute.Key{semconv.K8SNodeNameKey}
+	}
+	for _, key := range inherit {
+		if target[key] == "" {
+			target[key] = agent[key]
+		}
+	}
+
+	// The kubelet sets HOSTNAME to the pod name unless the pod defines a
+	// hostname.
+	if target[semconv.K8SPodNameKey] == "" && target[semconv.K8SPodUIDKey] != "" {
+		if v, ok := env("HOSTNAME"); ok {
+			target[semconv.K8SPodNameKey] = v
+		}
+	}
+
+	defined := resource.Environment().Set()
+	var attrs []attribute.KeyValue
+	for _, f := range k8sFields {
+		if v := target[f.key]; v != "" && !defined.HasValue(f.key) {
+			attrs = append(attrs, f.key.String(v))
+		}
+	}
+	if cg.containerID != "" && !defined.HasValue(semconv.ContainerIDKey) {
+		attrs = append(attrs, semconv.ContainerID(cg.containerID))
+	}
+	return attrs
+}
+
+// runtimeConfigs are the paths of the OCI runtime configuration of a
+// container with the containerd (Kubernetes and Docker namespaces) and CRI-O
+// runtimes. The placeholder is the container ID.
+var runtimeConfigs = []string{
+	"/run/containerd/io.containerd.runtime.v2.task/k8s.io/%s/config.json",
+	"/run/containerd/io.containerd.runtime.v2.task/moby/%s/config.json",
+	"/run/containers/storage/overlay-containers/%s/userdata/config.json",
+}
+
+// crioLabelsAnnotation is the annotation of the labels of a container in its
+// CRI-O runtime configuration.
+const crioLabelsAnnotation = "io.kubernetes.cri-o.Labels"
+
+// containerLabel returns the value of the label key of the container of the
+// process, or an empty string if it is not found.
+//
+// The label is read from the labels file of the downward API volume of the
+// container, then from the annotations of the runtime configuration of the
+// container found with its cgroup.
+func containerLabel(pid int, key string) string {
+	labels := readValue(procDir + "/" + strconv.Itoa(pid) + "/root" + podInfoDir + "/labels")
+	if v, ok := parseDownwardLabels(labels)[key]; ok {
+		return v
+	}
+
+	id := readCgroupInfo(pid).containerID
+	if id == "" {
+		return ""
+	}
+	for _, path := range runtimeConfigs {
+		data, err := osReadFile(fmt.Sprintf(path, id))
+		if err != nil {
+			continue
+		}
+		var spec struct {
+			Annotations map[string]string `json:"annotations"`
+		}
+		if json.Unmarshal(data, &spec) != nil {
+			continue
+		}
+		if v, ok := spec.Annotations[key]; ok {
+			return v
+		}
+		var crio map[string]string
+		if json.Unmarshal([]byte(spec.Annotations[crioLabelsAnnotation]), &crio) == nil {
+			return crio[key]
+		}
+		return ""
+	}
+	return ""
+}
+
+// parseDownwardLabels parses the labels file of a downward API volume:
+// key="value" lines.
+func parseDownwardLabels(data string) map[string]string {
+	labels := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.Unquote(value); err == nil {
+			labels[key] = v
+		}
+	}
+	return labels
+}
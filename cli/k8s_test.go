@@ -0,0 +1,196 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+const (
+	testPodUID      = "0c2f9b3f-6a2b-4c9e-9bd4-1f0e3a8d7f11"
+	testContainerID = "5e1c2b7d9a3f4e6b8c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b"
+	agentContainer  = "a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1"
+)
+
+func TestParseCgroupInfo(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cgroup string
+		want   cgroupInfo
+	}{
+		{
+			name: "containerd/v1/cgroupfs",
+			cgroup: "12:memory:/kubepods/burstable/pod" + testPodUID + "/" + testContainerID + "\n" +
+				"11:cpu,cpuacct:/kubepods/burstable/pod" + testPodUID + "/" + testContainerID + "\n" +
+				"1:name=systemd:/kubepods/burstable/pod" + testPodUID + "/" + testContainerID + "\n",
+			want: cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "containerd/v1/cgroupfs/guaranteed",
+			cgroup: "4:pids:/kubepods/pod" + testPodUID + "/" + testContainerID + "\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name: "containerd/v1/systemd",
+			cgroup: "10:devices:/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/cri-containerd-" + testContainerID + ".scope\n" +
+				"1:name=systemd:/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/cri-containerd-" + testContainerID + ".scope\n",
+			want: cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "containerd/v2/systemd",
+			cgroup: "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/cri-containerd-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "containerd/v2/cgroupfs",
+			cgroup: "0::/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID + "\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "crio/v1/systemd",
+			cgroup: "9:memory:/kubepods.slice/kubepods-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/crio-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "crio/v2/systemd",
+			cgroup: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/crio-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "crio/v2/cgroupfs",
+			cgroup: "0::/kubepods/burstable/pod" + testPodUID + "/crio-" + testContainerID + "\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "crio/conmon",
+			cgroup: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/crio-conmon-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{podUID: testPodUID},
+		},
+		{
+			name:   "static pod",
+			cgroup: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod9f8e7d6c5b4a39281706f5e4d3c2b1a0.slice/cri-containerd-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{podUID: "9f8e7d6c5b4a39281706f5e4d3c2b1a0", containerID: testContainerID},
+		},
+		{
+			name:   "docker",
+			cgroup: "0::/system.slice/docker-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{containerID: testContainerID},
+		},
+		{
+			name:   "sibling container",
+			cgroup: "0::/../cri-containerd-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{containerID: testContainerID, sibling: true},
+		},
+		{
+			name:   "other pod in cgroup namespace",
+			cgroup: "0::/../../kubepods-besteffort-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/cri-containerd-" + testContainerID + ".scope\n",
+			want:   cgroupInfo{podUID: testPodUID, containerID: testContainerID},
+		},
+		{
+			name:   "host",
+			cgroup: "0::/system.slice/app.service\n",
+		},
+		{
+			name:   "cgroup namespace root",
+			cgroup: "0::/\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseCgroupInfo(parseCgroups([]byte(tc.cgroup))))
+		})
+	}
+}
+
+// mockProcFiles replaces the files read by the agent with files.
+func mockProcFiles(t *testing.T, self int, files map[string]string) {
+	readFile, getpid := osReadFile, osGetpid
+	t.Cleanup(func() { osReadFile, osGetpid = readFile, getpid })
+	osReadFile = func(name string) ([]byte, error) {
+		if data, ok := files[name]; ok {
+			return []byte(data), nil
+		}
+		return nil, os.ErrNotExist
+	}
+	osGetpid = func() int { return self }
+}
+
+func TestK8sAttrsDaemonSet(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+	t.Setenv("NODE_NAME", "node-1")
+	t.Setenv("POD_NAME", "agent-xyz")
+	t.Setenv("POD_NAMESPACE", "monitoring")
+	mockProcFiles(t, 10, map[string]string{
+		"/proc/10/cgroup":  "0::/kubepods.slice/kubepods-pod11111111_2222_3333_4444_555555555555.slice/cri-containerd-" + agentContainer + ".scope\n",
+		"/proc/42/cgroup":  "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/cri-containerd-" + testContainerID + ".scope\n",
+		"/proc/42/environ": "PATH=/usr/bin\x00HOSTNAME=checkout-5d9f\x00",
+		"/proc/42/root/var/run/secrets/kubernetes.io/serviceaccount/namespace": "shop\n",
+	})
+
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.K8SPodName("checkout-5d9f"),
+		semconv.K8SNamespaceName("shop"),
+		semconv.K8SNodeName("node-1"),
+		semconv.K8SPodUID(testPodUID),
+		semconv.ContainerID(testContainerID),
+	}, k8sAttrs(42))
+}
+
+func TestK8sAttrsSidecar(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "k8s.namespace.name=override")
+	t.Setenv("K8S_POD_NAME", "checkout-5d9f")
+	t.Setenv("K8S_NAMESPACE_NAME", "shop")
+	t.Setenv("K8S_CONTAINER_NAME", "agent")
+	mockProcFiles(t, 10, map[string]string{
+		"/proc/10/cgroup":                  "0::/\n",
+		"/proc/42/cgroup":                  "0::/../cri-containerd-" + testContainerID + ".scope\n",
+		"/proc/42/environ":                 "CONTAINER_NAME=app\x00",
+		"/etc/podinfo/node_name":           "node-1\n",
+		"/etc/podinfo/pod_uid":             testPodUID + "\n",
+		"/proc/42/root/etc/podinfo/labels": "app=checkout\n",
+	})
+
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.K8SPodName("checkout-5d9f"),
+		semconv.K8SNodeName("node-1"),
+		semconv.K8SContainerName("app"),
+		semconv.K8SPodUID(testPodUID),
+		semconv.ContainerID(testContainerID),
+	}, k8sAttrs(42))
+}
+
+func TestK8sAttrsHost(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+	t.Setenv("NODE_NAME", "node-1")
+	t.Setenv("POD_NAME", "agent-xyz")
+	mockProcFiles(t, 10, map[string]string{
+		"/proc/10/cgroup":  "0::/../../kubepods-pod11111111_2222_3333_4444_555555555555.slice/cri-containerd-" + agentContainer + ".scope\n",
+		"/proc/42/cgroup":  "0::/../../../../system.slice/app.service\n",
+		"/proc/42/environ": "HOSTNAME=host-1\x00",
+	})
+
+	assert.Equal(t, []attribute.KeyValue{semconv.K8SNodeName("node-1")}, k8sAttrs(42))
+}
+
+func TestContainerLabel(t *testing.T) {
+	crioConfig := `{"ociVersion":"1.0.2","annotations":{"io.kubernetes.cri-o.Labels":"{\"app.kubernetes.io/name\":\"cart\",\"io.kubernetes.pod.name\":\"cart-7f9c\"}"}}`
+	mockProcFiles(t, 10, map[string]string{
+		"/proc/1/root/etc/podinfo/labels": "app.kubernetes.io/name=\"checkout\"\npod-template-hash=\"5d9f\"\n",
+		"/proc/2/cgroup":                  "0::/kubepods.slice/kubepods-pod0c2f9b3f_6a2b_4c9e_9bd4_1f0e3a8d7f11.slice/crio-" + testContainerID + ".scope\n",
+		"/run/containers/storage/overlay-containers/" + testContainerID + "/userdata/config.json": crioConfig,
+		"/proc/3/cgroup": "0::/system.slice/docker-" + agentContainer + ".scope\n",
+		"/run/containerd/io.containerd.runtime.v2.task/moby/" + agentContainer + "/config.json": `{"annotations":{"app.kubernetes.io/name":"inventory"}}`,
+	})
+
+	assert.Equal(t, "checkout", containerLabel(1, "app.kubernetes.io/name"))
+	assert.Equal(t, "5d9f", containerLabel(1, "pod-template-hash"))
+	assert.Equal(t, "cart", containerLabel(2, "app.kubernetes.io/name"))
+	assert.Equal(t, "", containerLabel(2, "team"))
+	assert.Equal(t, "inventory", containerLabel(3, "app.kubernetes.io/name"))
+	assert.Equal(t, "", containerLabel(4, "app.kubernetes.io/name"))
+}
@@ -8,6 +8,7 @@ package main
 import (
 	"context"
 	"debug/buildinfo"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -15,47 +16,44 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"syscall"
 
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 
 	"go.opentelemetry.io/auto"
-	"go.opentelemetry.io/auto/pipeline"
 	"go.opentelemetry.io/auto/pipeline/otelsdk"
 )
 
-const help = `Usage of %s:
-  -global-impl
-    	Record telemetry from the OpenTelemetry default global implementation
-  -target-pid int
-    	PID of target process
-  -target-exe string
-    	Executable path run by the target process
-  -log-level string
-    	Logging level ("debug", "info", "warn", "error")
-
+const help = `
 Runs the OpenTelemetry auto-instrumentation for Go applications using eBPF.
 
-If both -target-pid and -target-exe are provided -target-exe will be ignored
-and -target-pid used.
+Settings are resolved from, in order of precedence, the command line flags,
+the environment variables, and the configuration file. The configuration file
+is a YAML document whose keys are the flag names, e.g.:
 
-Environment variable configuration:
+	target-exe: /usr/local/bin/app
+	disable-probe: [database/sql, net/http/client]
+	sampler: parentbased_traceidratio
+	sampler-arg: 0.25
 
-	- OTEL_GO_AUTO_TARGET_PID: PID of the target process
-	- OTEL_GO_AUTO_TARGET_EXE: executable path run by the target process
-	- OTEL_LOG_LEVEL: log level (flag takes precedence)
-	- OTEL_SERVICE_NAME (or OTEL_RESOURCE_ATTRIBUTES): service name
-	- OTEL_TRACES_EXPORTER: trace exporter identifier
+The target process is selected with -target-pid, -target-exe, or
+-target-cmdline, used in this order of precedence. The target settings are
+resolved together: if any of them is set by a flag, the target environment
+variables and configuration file keys are ignored.
 
-If the OTEL_GO_AUTO_TARGET_PID is only resolved if -target-exe or -target-pid
-is not provided. If none of these are set, OTEL_GO_AUTO_TARGET_EXE will be
-resolved.
+Other environment variable configuration:
+
+	- OTEL_SERVICE_NAME (or OTEL_RESOURCE_ATTRIBUTES): service name
+	- OTEL_METRICS_EXPORTER: agent metric exporters (otlp, prometheus)
+	- OTEL_SDK_DISABLED: "true" disables the instrumentation, when set in the
+	  environment of the agent or of a target process
 
 The OTEL_TRACES_EXPORTER environment variable value is resolved using the
 autoexport (go.opentelemetry.io/contrib/exporters/autoexport) package. See that
-package's documentation for information on supported values and registration of
-custom exporters.
+package's documentation for information on supported values.
 `
 
 const (
@@ -68,22 +66,34 @@ const (
 	// envTargetExeKey is the environment variable key containing the path to
 	// target binary to instrument.
 	envTargetExeKey = "OTEL_GO_AUTO_TARGET_EXE"
+	// envSDKDisabledKey is the environment variable key disabling the
+	// instrumentation, in the environment of the agent or of a target.
+	envSDKDisabledKey = "OTEL_SDK_DISABLED"
 )
 
-func usage() {
-	program := filepath.Base(os.Args[0])
-	fmt.Fprintf(os.Stderr, help, program)
+// sdkDisabled returns if the OTEL_SDK_DISABLED environment variable of lookup
+// is "true", ignoring case.
+func sdkDisabled(lookup func(string) (string, bool)) bool {
+	v, _ := lookup(envSDKDisabledKey)
+	return strings.EqualFold(strings.TrimSpace(v), "true")
 }
 
-func newLogger(lvlStr string) *slog.Logger {
+func usage(fs *flag.FlagSet) {
+	fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+	fs.PrintDefaults()
+	fmt.Fprint(fs.Output(), help)
+}
+
+func newLogger(lvlStr, format string) *slog.Logger {
 	levelVar := new(slog.LevelVar) // Default value of info.
 	opts := &slog.HandlerOptions{AddSource: true, Level: levelVar}
-	h := slog.NewJSONHandler(os.Stderr, opts)
-	logger := slog.New(h)
-
-	if lvlStr == "" {
-		lvlStr = os.Getenv(envLogLevelKey)
+	var h slog.Handler
+	if format == "text" {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	} else {
+		h = slog.NewJSONHandler(os.Stderr, opts)
 	}
+	logger := slog.New(h)
 
 	if lvlStr == "" {
 		return logger
@@ -100,18 +110,35 @@ func newLogger(lvlStr string) *slog.Logger {
 }
 
 func main() {
-	var logLevel string
-	var targetPID int
-	var targetExe string
-
-	flag.StringVar(&logLevel, "log-level", "", `Logging level ("debug", "info", "warn", "error")`)
-	flag.IntVar(&targetPID, "target-pid", -1, `PID of target process`)
-	flag.StringVar(&targetExe, "target-exe", "", `Executable path run by the target process`)
+	c, err := parseConfig(filepath.Base(os.Args[0]), os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	flag.Usage = usage
-	flag.Parse()
+	switch {
+	case c.version:
+		printVersion(os.Stdout)
+		return
+	case c.printConfig:
+		if err := c.print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	logger := newLogger(logLevel)
+	logger := newLogger(c.get("log-level"), c.get("log-format"))
+	if err := c.exportEnv(); err != nil {
+		logger.Error("failed to configure exporter", "error", err)
+		return
+	}
 
 	// Trap Ctrl+C and SIGTERM and call cancel on the context.
 	ctx, cancel := context.WithCancel(context.Background())
@@ -129,12 +156,65 @@ func main() {
 		}
 	}()
 
-	pid, err := findPID(ctx, logger, targetPID, targetExe)
+	if sdkDisabled(lookupEnv) {
+		logger.Info("instrumentation disabled by " + envSDKDisabledKey + ", not instrumenting")
+		err := serveHealth(ctx, logger, c, func() instState { return instState{state: stateDisabled} }, nil)
+		if err != nil {
+			logger.Error("failed to serve health endpoints", "error", err)
+			return
+		}
+		if !c.dryRun {
+			// Exiting would restart the agent when run as a sidecar container.
+			<-ctx.Done()
+		}
+		return
+	}
+
+	if c.enabled("all-go-processes") {
+		opts := append([]auto.InstrumentationOption{
+			auto.WithEnv(),
+			auto.WithLogger(logger),
+		}, c.instrumentationOptions()...)
+		if !c.enabled("keep-privileges") {
+			// Other processes are discovered and instrumented afterwards.
+			opts = append(opts, auto.WithPrivilegeDrop(auto.PrivilegeDiscovery))
+		}
+		if err := runDaemon(ctx, logger, c, opts); err != nil {
+			logger.Error("daemon failed", "error", err)
+		}
+		return
+	}
+
+	// The handler is created once the target is found.
+	var handler atomic.Pointer[otelsdk.TraceHandler]
+	t := &target{state: stateWaiting}
+	err = serveHealth(ctx, logger, c, targetState(t), func() otelsdk.ExportStatus {
+		if h := handler.Load(); h != nil {
+			return h.ExportStatus()
+		}
+		return otelsdk.ExportStatus{}
+	})
+	if err != nil {
+		logger.Error("failed to serve health endpoints", "error", err)
+		return
+	}
+
+	pid, err := findPID(ctx, logger, c)
 	if err != nil {
 		logger.Error("failed to find target", "error", err)
 		return
 	}
 
+	if sdkDisabled(environ(pid)) {
+		t.set(stateDisabled, nil, nil)
+		logger.Info("instrumentation disabled by "+envSDKDisabledKey+" in the target environment, not instrumenting", "PID", pid)
+		if !c.dryRun {
+			// Changes are only detected when the target is restarted.
+			<-ctx.Done()
+		}
+		return
+	}
+
 	logger.Info(
 		"building OpenTelemetry Go instrumentation ...",
 		"version", newVersion(),
@@ -150,13 +230,18 @@ func main() {
 		logger.Error("failed to create OTel SDK handler", "error", err)
 		return
 	}
+	handler.Store(h)
 
 	instOptions := []auto.InstrumentationOption{
 		auto.WithEnv(),
 		auto.WithLogger(logger),
-		auto.WithHandler(&pipeline.Handler{TraceHandler: h}),
+		auto.WithHandler(h.PipelineHandler()),
 	}
+	instOptions = append(instOptions, c.instrumentationOptions()...)
 	instOptions = append(instOptions, auto.WithPID(pid))
+	if !c.enabled("keep-privileges") {
+		instOptions = append(instOptions, auto.WithPrivilegeDrop())
+	}
 
 	logger.Info(
 		"building OpenTelemetry Go instrumentation ...",
@@ -169,16 +254,27 @@ func main() {
 		logger.Error("failed to create instrumentation", "error", err)
 		return
 	}
+	t.set(stateStarting, inst, nil)
+
+	if c.dryRun {
+		logger.Info("dry run: configuration is valid, exiting without instrumenting", "PID", pid)
+		if err := h.Shutdown(context.Background()); err != nil {
+			logger.Error("failed to flush handler", "error", err)
+		}
+		return
+	}
 
 	err = inst.Load(ctx)
 	if err != nil {
 		logger.Error("failed to load instrumentation", "error", err)
 		return
 	}
+	t.set(stateRunning, nil, nil)
 
 	logger.Info("instrumentation loaded successfully, starting...")
 
-	if err = inst.Run(ctx); err != nil {
+	if err = inst.Run(ctx); err != nil && ctx.Err() == nil {
+		t.set(stateFailed, nil, err)
 		logger.Error("instrumentation crashed", "error", err)
 	}
 
@@ -194,45 +290,30 @@ func main() {
 }
 
 var errNoPID = fmt.Errorf(
-	"no target: -target-pid or -target-exe not provided and the env vars %s and %s are unset",
-	envTargetPIDKey, envTargetExeKey,
+	"no target: -target-pid, -target-exe, or -target-cmdline not provided and the env vars %s, %s, and %s are unset",
+	envTargetPIDKey, envTargetExeKey, envTargetCmdlineKey,
 )
 
-func findPID(ctx context.Context, l *slog.Logger, pid int, binPath string) (int, error) {
+func findPID(ctx context.Context, l *slog.Logger, c *config) (int, error) {
 	// Priority:
-	//  1. pid
-	//  2. binPath
-	//  3. OTEL_GO_AUTO_TARGET_PID
-	//  4. OTEL_GO_AUTO_TARGET_EXE
+	//  1. target-pid
+	//  2. target-exe
+	//  3. target-cmdline
+	pid, binPath, cmdline := c.target()
 
 	l.Debug(
 		"finding target PID",
 		"PID", pid,
 		"executable", binPath,
-		envTargetPIDKey, os.Getenv(envTargetPIDKey),
-		envTargetExeKey, os.Getenv(envTargetExeKey),
+		"cmdline", cmdline,
 	)
 
 	if pid >= 0 {
 		return pid, nil
 	}
 
-	if binPath != "" {
-		return findExeFn(ctx, l, binPath)
-	}
-
-	pidStr := os.Getenv(envTargetPIDKey)
-	if pidStr != "" {
-		pid, err := strconv.Atoi(pidStr)
-		if err != nil {
-			return 0, fmt.Errorf("invalid OTEL_GO_AUTO_TARGET_PID value: %s: %w", pidStr, err)
-		}
-		return pid, nil
-	}
-
-	binPath = os.Getenv(envTargetExeKey)
-	if binPath != "" {
-		return findExeFn(ctx, l, binPath)
+	if binPath != "" || cmdline != "" {
+		return findExeFn(ctx, l, ProcessPoller{BinPath: binPath, Cmdline: cmdline})
 	}
 
 	return -1, errNoPID
@@ -241,8 +322,8 @@ func findPID(ctx context.Context, l *slog.Logger, pid int, binPath string) (int,
 // Used for testing.
 var findExeFn = findExe
 
-func findExe(ctx context.Context, l *slog.Logger, exe string) (int, error) {
-	pp := ProcessPoller{Logger: l, BinPath: exe}
+func findExe(ctx context.Context, l *slog.Logger, pp ProcessPoller) (int, error) {
+	pp.Logger = l
 	return pp.Poll(ctx)
 }
 
@@ -250,10 +331,17 @@ func resourceAttrs(logger *slog.Logger, pid int) []attribute.KeyValue {
 	attrs := []attribute.KeyValue{
 		semconv.TelemetryDistroVersionKey.String(auto.Version()),
 	}
+	attrs = append(attrs, k8sAttrs(pid)...)
 
 	// Add additional process information for the target.
 	path := "/proc/" + strconv.Itoa(pid) + "/exe"
 	bi, err := buildinfo.ReadFile(path)
+	if !serviceNameDefined() {
+		exe, _ := osReadlink(path)
+		name, source := deriveServiceName(bi, exe)
+		logger.Info("service name not set, derived from the target", "service", name, "source", source)
+		attrs = append(attrs, semconv.ServiceName(name))
+	}
 	if err != nil {
 		logger.Error("failed to get Go proc build info", "error", err)
 		return attrs
@@ -6,11 +6,13 @@ package main
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -27,7 +29,11 @@ var pids = map[string]int{appPath: appPathPID, altPath: altPathPID}
 
 var errMissing = errors.New("missing")
 
-func fakeFindExe(_ context.Context, _ *slog.Logger, exe string) (int, error) {
+func fakeFindExe(_ context.Context, _ *slog.Logger, pp ProcessPoller) (int, error) {
+	exe := pp.BinPath
+	if exe == "" {
+		exe = pp.Cmdline
+	}
 	pid, ok := pids[exe]
 	if !ok {
 		return -1, errMissing
@@ -41,44 +47,60 @@ func TestFindPID(t *testing.T) {
 	t.Cleanup(func() { findExeFn = orig })
 
 	ctx := context.Background()
+	find := func(t *testing.T, args ...string) (int, error) {
+		t.Helper()
+		c, err := parseConfig("test", args, io.Discard)
+		require.NoError(t, err)
+		return findPID(ctx, discardLogger, c)
+	}
 
 	t.Run("Empty", func(t *testing.T) {
-		got, err := findPID(ctx, discardLogger, -1, "")
+		got, err := find(t)
 		assert.Equal(t, -1, got)
 		assert.ErrorIs(t, err, errNoPID)
 	})
 
 	t.Run("PID", func(t *testing.T) {
 		const pid = 4
-		got, err := findPID(ctx, discardLogger, pid, appPath)
+		got, err := find(t, "-target-pid=4", "-target-exe="+appPath)
 		assert.NoError(t, err)
 		assert.Equal(t, int(pid), got)
 	})
 
 	t.Run("BinPath", func(t *testing.T) {
-		got, err := findPID(ctx, discardLogger, -1, appPath)
+		got, err := find(t, "-target-exe="+appPath)
 		assert.NoError(t, err)
 		assert.Equal(t, appPathPID, got)
 
-		got, err = findPID(ctx, discardLogger, -1, missingPath)
+		got, err = find(t, "-target-exe="+missingPath)
 		assert.Equal(t, -1, got)
 		assert.ErrorIs(t, err, errMissing)
 	})
 
+	t.Run("Cmdline", func(t *testing.T) {
+		got, err := find(t, "-target-cmdline="+altPath)
+		assert.NoError(t, err)
+		assert.Equal(t, altPathPID, got)
+
+		got, err = find(t, "-target-exe="+appPath, "-target-cmdline="+altPath)
+		assert.NoError(t, err)
+		assert.Equal(t, appPathPID, got)
+	})
+
 	t.Run("OTEL_GO_AUTO_TARGET_PID", func(t *testing.T) {
 		t.Setenv(envTargetPIDKey, "2000")
-		got, err := findPID(ctx, discardLogger, -1, "")
+		got, err := find(t)
 		assert.NoError(t, err)
 		assert.Equal(t, int(2000), got)
 
 		t.Setenv(envTargetPIDKey, "invalid")
-		_, err = findPID(ctx, discardLogger, -1, "")
-		assert.Error(t, err)
+		_, err = parseConfig("test", nil, io.Discard)
+		assert.ErrorContains(t, err, envTargetPIDKey)
 	})
 
 	t.Run("OTEL_GO_AUTO_TARGET_EXE", func(t *testing.T) {
 		t.Setenv(envTargetExeKey, appPath)
-		got, err := findPID(ctx, discardLogger, -1, "")
+		got, err := find(t)
 		assert.NoError(t, err)
 		assert.Equal(t, appPathPID, got)
 	})
@@ -88,22 +110,39 @@ func TestFindPID(t *testing.T) {
 		t.Setenv(envTargetExeKey, altPath)
 
 		const pid = 4
-		got, err := findPID(ctx, discardLogger, pid, appPath)
+		got, err := find(t, "-target-pid=4", "-target-exe="+appPath)
 		assert.NoError(t, err)
 		assert.Equal(t, int(pid), got)
 
-		got, err = findPID(ctx, discardLogger, -1, appPath)
+		got, err = find(t, "-target-exe="+appPath)
 		assert.NoError(t, err)
 		assert.Equal(t, appPathPID, got)
 
-		got, err = findPID(ctx, discardLogger, -1, "")
+		got, err = find(t)
 		assert.NoError(t, err)
 		assert.Equal(t, 2000, got)
 
 		os.Unsetenv(envTargetPIDKey)
 
-		got, err = findPID(ctx, discardLogger, -1, "")
+		got, err = find(t)
 		assert.NoError(t, err)
 		assert.Equal(t, altPathPID, got)
 	})
 }
+
+func TestSDKDisabled(t *testing.T) {
+	lookup := func(v string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			if key != envSDKDisabledKey {
+				return "", false
+			}
+			return v, true
+		}
+	}
+	assert.True(t, sdkDisabled(lookup("true")))
+	assert.True(t, sdkDisabled(lookup(" TRUE ")))
+	assert.False(t, sdkDisabled(lookup("false")))
+	assert.False(t, sdkDisabled(lookup("1")))
+	assert.False(t, sdkDisabled(lookup("")))
+	assert.False(t, sdkDisabled(func(string) (string, bool) { return "", false }))
+}
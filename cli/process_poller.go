@@ -4,6 +4,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
@@ -25,6 +26,9 @@ type ProcessPoller struct {
 	Logger *slog.Logger
 	// BinPath is the path of the target process executable.
 	BinPath string
+	// Cmdline is a substring of the command line of the target process. It
+	// is only used if BinPath is empty.
+	Cmdline string
 	// Interval is time between successive polling attempts. If zero, a default
 	// of 2 seconds will be used.
 	Interval time.Duration
@@ -55,11 +59,17 @@ func (pp *ProcessPoller) logger() *slog.Logger {
 }
 
 // Poll polls the processes running on the system. The first process discovered
-// that is running with the configured BinPath will have its PID returned.
+// that is running with the configured BinPath, or whose command line contains
+// Cmdline if BinPath is empty, will have its PID returned.
 func (pp *ProcessPoller) Poll(ctx context.Context) (int, error) {
-	path, err := filepath.Abs(pp.BinPath)
-	if err != nil {
-		return 0, err
+	find, attr := pp.findCmdline, slog.String("cmdline", pp.Cmdline)
+	if pp.BinPath != "" || pp.Cmdline == "" {
+		path, err := filepath.Abs(pp.BinPath)
+		if err != nil {
+			return 0, err
+		}
+		find = func() (int, error) { return pp.find(path) }
+		attr = slog.String("binary", path)
 	}
 
 	interval := pp.interval()
@@ -68,7 +78,7 @@ func (pp *ProcessPoller) Poll(ctx context.Context) (int, error) {
 
 	pp.logger().Info(
 		"Polling for process",
-		"binary", path,
+		attr,
 		"interval", interval,
 	)
 	for {
@@ -76,17 +86,14 @@ func (pp *ProcessPoller) Poll(ctx context.Context) (int, error) {
 		case <-ctx.Done():
 			return 0, ctx.Err()
 		case <-ticker.C:
-			pid, err := pp.find(path)
+			pid, err := find()
 			if err != nil {
 				pp.logger().Error("failed to poll processes", "error", err)
 				continue
 			}
 
 			if pid < 0 {
-				pp.logger().Debug(
-					"process not found, continuing...",
-					"binary", path,
-				)
+				pp.logger().Debug("process not found, continuing...", attr)
 				continue
 			}
 			pp.logger().Info("process found", "PID", pid)
@@ -100,6 +107,7 @@ var (
 	osReadDir  = os.ReadDir
 	osReadlink = os.Readlink
 	osReadFile = os.ReadFile
+	osGetpid   = os.Getpid
 )
 
 func (pp *ProcessPoller) find(path string) (int, error) {
@@ -134,3 +142,34 @@ func (pp *ProcessPoller) find(path string) (int, error) {
 	}
 	return -1, nil
 }
+
+func (pp *ProcessPoller) findCmdline() (int, error) {
+	entries, err := osReadDir(procDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", procDir, err)
+	}
+
+	self := osGetpid()
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		pid, err := strconv.Atoi(name)
+		if err != nil || pid == self {
+			// The command line of the agent may contain Cmdline.
+			continue
+		}
+		cmdLine, err := osReadFile(procDir + "/" + name + "/cmdline")
+		if err != nil {
+			// The process may have exited.
+			continue
+		}
+		// Arguments are separated by NUL bytes.
+		cmdLine = bytes.ReplaceAll(bytes.TrimRight(cmdLine, "\x00"), []byte{0}, []byte{' '})
+		if strings.Contains(string(cmdLine), pp.Cmdline) {
+			return pid, nil
+		}
+	}
+	return -1, nil
+}
@@ -96,3 +96,23 @@ func TestProcessPollerPoll(t *testing.T) {
 	assert.ErrorIs(t, err, context.DeadlineExceeded)
 	assert.Zero(t, pid)
 }
+
+func TestProcessPollerPollCmdline(t *testing.T) {
+	t.Cleanup(mock())
+	origGetpid := osGetpid
+	t.Cleanup(func() { osGetpid = origGetpid })
+	osGetpid = func() int { return appPathPID }
+
+	ctx := context.Background()
+	pp := ProcessPoller{Cmdline: altPath + " args", Interval: time.Millisecond}
+	pid, err := pp.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, altPathPID, pid)
+
+	// The agent process is ignored.
+	pp.Cmdline = appPath
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = pp.Poll(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
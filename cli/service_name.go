@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"cmp"
+	"debug/buildinfo"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+// unknownServiceName is the service name of the specification used when none
+// can be derived.
+const unknownServiceName = "unknown_service:go"
+
+// Sources of a service name.
+const (
+	serviceNameFromRule       = "rule"
+	serviceNameFromEnv        = "env"
+	serviceNameFromLabel      = "label"
+	serviceNameFromModule     = "module"
+	serviceNameFromExecutable = "executable"
+	serviceNameFromDefault    = "default"
+)
+
+// serviceNameSources are the sources the service names of the processes
+// instrumented in daemon mode are resolved from by default, in order.
+var serviceNameSources = []string{
+	serviceNameFromEnv,
+	serviceNameFromLabel,
+	serviceNameFromModule,
+	serviceNameFromExecutable,
+}
+
+// defaultServiceNameLabel is the container label the service names are read
+// from by default.
+const defaultServiceNameLabel = "app.kubernetes.io/name"
+
+// majorVersionRe matches the major version suffix of a module path, e.g. the
+// "v2" of "example.com/app/v2" or "gopkg.in/app.v2".
+var majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+// deriveServiceName returns the default service name of a process running
+// exe, built as described by bi (nil if unknown), and the source it is derived
+// from: the last element of the main module path, the executable name, or the
+// unknown service name of the specification.
+func deriveServiceName(bi *buildinfo.BuildInfo, exe string) (name, source string) {
+	if bi != nil {
+		if name := moduleServiceName(bi.Main.Path); name != "" {
+			return name, serviceNameFromModule
+		}
+	}
+	if name := exeServiceName(exe); name != "" {
+		return name, serviceNameFromExecutable
+	}
+	return unknownServiceName, serviceNameFromDefault
+}
+
+// exeServiceName returns the name of the executable exe, or an empty string if
+// exe is not an executable path.
+func exeServiceName(exe string) string {
+	// The link of the executable of a process is suffixed if it was deleted.
+	exe = strings.TrimSuffix(exe, " (deleted)")
+	if base := filepath.Base(exe); exe != "" && base != "/" && base != "." {
+		return base
+	}
+	return ""
+}
+
+// moduleServiceName returns the last element of the module path without its
+// major version suffix, or an empty string if path is not a meaningful
+// module path, e.g. the "command-line-arguments" path of a program built from
+// files with go build or go run.
+func moduleServiceName(path string) string {
+	switch path {
+	case "", "command-line-arguments", "main":
+		return ""
+	}
+	elems := strings.Split(path, "/")
+	if n := len(elems); n > 1 && majorVersionRe.MatchString(elems[n-1]) {
+		elems = elems[:n-1]
+	}
+	name := elems[len(elems)-1]
+	if i := strings.LastIndex(name, "."); i > 0 && majorVersionRe.MatchString(name[i+1:]) {
+		name = name[:i]
+	}
+	return name
+}
+
+// serviceNameDefined returns true if the service name of the agent is
+// defined with OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES.
+func serviceNameDefined() bool {
+	if v, _ := lookupEnv("OTEL_SERVICE_NAME"); v != "" {
+		return true
+	}
+	return resource.Environment().Set().HasValue(semconv.ServiceNameKey)
+}
+
+// serviceRule sets the service name of the processes whose executable path
+// matches a glob pattern.
+type serviceRule struct {
+	pattern string
+	name    string
+}
+
+// parseServiceRule parses a "<glob>=<name>" service rule.
+func parseServiceRule(v string) (serviceRule, error) {
+	pattern, name, ok := strings.Cut(v, "=")
+	if !ok || pattern == "" || name == "" {
+		return serviceRule{}, errors.New("must be <executable glob>=<service name>")
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return serviceRule{}, err
+	}
+	return serviceRule{pattern: pattern, name: name}, nil
+}
+
+// serviceNaming resolves the service names of the processes instrumented in
+// daemon mode.
+type serviceNaming struct {
+	// rules take precedence over the sources.
+	rules []serviceRule
+	// sources are the sources the name is resolved from, in order. The
+	// serviceNameSources are used if empty.
+	sources []string
+	// label is the container label of the label source. The
+	// defaultServiceNameLabel is used if empty.
+	label string
+}
+
+// resolve returns the service name of a process, and its source: the name of
+// the first rule matching its executable path, or the first name found in
+// the sources, or the unknown service name of the specification.
+//
+// The environment and container of the process may not be readable by the
+// agent, their sources are then skipped.
+func (n serviceNaming) resolve(p proc) (name, source string) {
+	for _, r := range n.rules {
+		if ok, _ := filepath.Match(r.pattern, p.exe); ok {
+			return r.name, serviceNameFromRule
+		}
+	}
+
+	sources := n.sources
+	if len(sources) == 0 {
+		sources = serviceNameSources
+	}
+	for _, src := range sources {
+		var v string
+		switch src {
+		case serviceNameFromEnv:
+			v, _ = environ(p.pid)("OTEL_SERVICE_NAME")
+		case serviceNameFromLabel:
+			v = containerLabel(p.pid, cmp.Or(n.label, defaultServiceNameLabel))
+		case serviceNameFromModule:
+			if bi, err := buildinfo.ReadFile(procDir + "/" + strconv.Itoa(p.pid) + "/exe"); err == nil {
+				v = moduleServiceName(bi.Main.Path)
+			}
+		case serviceNameFromExecutable:
+			v = exeServiceName(p.exe)
+		}
+		if v != "" {
+			return v, src
+		}
+	}
+	return unknownServiceName, serviceNameFromDefault
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"debug/buildinfo"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleServiceName(t *testing.T) {
+	for path, want := range map[string]string{
+		"github.com/acme/checkout-service":    "checkout-service",
+		"github.com/acme/checkout-service/v2": "checkout-service",
+		"github.com/acme/v2":                  "acme",
+		"gopkg.in/inventory.v3":               "inventory",
+		"example.com/tools/v1.5":              "v1.5",
+		"app":                                 "app",
+		"command-line-arguments":              "",
+		"main":                                "",
+		"":                                    "",
+	} {
+		assert.Equal(t, want, moduleServiceName(path), path)
+	}
+}
+
+func TestDeriveServiceName(t *testing.T) {
+	bi := func(path string) *buildinfo.BuildInfo {
+		return &buildinfo.BuildInfo{Main: debug.Module{Path: path}}
+	}
+	for _, tc := range []struct {
+		bi     *buildinfo.BuildInfo
+		exe    string
+		name   string
+		source string
+	}{
+		{bi("github.com/acme/checkout-service/v2"), "/usr/local/bin/server", "checkout-service", serviceNameFromModule},
+		{bi("command-line-arguments"), "/tmp/go-build123/b001/exe/main", "main", serviceNameFromExecutable},
+		{nil, "/usr/local/bin/server (deleted)", "server", serviceNameFromExecutable},
+		{bi(""), "", unknownServiceName, serviceNameFromDefault},
+	} {
+		name, source := deriveServiceName(tc.bi, tc.exe)
+		assert.Equal(t, tc.name, name, tc.exe)
+		assert.Equal(t, tc.source, source, tc.exe)
+	}
+}
+
+func TestServiceNameDefined(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+	assert.False(t, serviceNameDefined())
+
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.name=checkout")
+	assert.True(t, serviceNameDefined())
+
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "")
+	t.Setenv("OTEL_SERVICE_NAME", "checkout")
+	assert.True(t, serviceNameDefined())
+}
+
+func TestParseServiceRule(t *testing.T) {
+	r, err := parseServiceRule("/opt/*/bin/server=checkout")
+	require.NoError(t, err)
+	assert.Equal(t, serviceRule{pattern: "/opt/*/bin/server", name: "checkout"}, r)
+
+	for _, v := range []string{"checkout", "=checkout", "/usr/bin/app=", "[=checkout"} {
+		_, err := parseServiceRule(v)
+		assert.Error(t, err, v)
+	}
+}
+
+func TestServiceNamingResolve(t *testing.T) {
+	mockProcFiles(t, 10, map[string]string{
+		"/proc/1/environ":                 "PATH=/usr/bin\x00OTEL_SERVICE_NAME=checkout\x00",
+		"/proc/1/root/etc/podinfo/labels": "app.kubernetes.io/name=\"checkout-label\"\n",
+		"/proc/2/root/etc/podinfo/labels": "app.kubernetes.io/name=\"cart\"\nteam=\"shop\"\n",
+		"/proc/3/root/etc/podinfo/labels": "team=\"shop\"\n",
+	})
+	resolve := func(n serviceNaming, p proc) [2]string {
+		name, source := n.resolve(p)
+		return [2]string{name, source}
+	}
+
+	var n serviceNaming
+	assert.Equal(t, [2]string{"checkout", serviceNameFromEnv}, resolve(n, proc{pid: 1, exe: "/usr/bin/app"}))
+	assert.Equal(t, [2]string{"cart", serviceNameFromLabel}, resolve(n, proc{pid: 2, exe: "/usr/bin/app"}))
+	// The environment of the process is not readable.
+	assert.Equal(t, [2]string{"app", serviceNameFromExecutable}, resolve(n, proc{pid: 3, exe: "/usr/bin/app"}))
+	assert.Equal(t, [2]string{unknownServiceName, serviceNameFromDefault}, resolve(n, proc{pid: 3}))
+
+	n = serviceNaming{
+		rules:   []serviceRule{{pattern: "/usr/bin/worker", name: "worker"}},
+		sources: []string{serviceNameFromLabel, serviceNameFromEnv},
+		label:   "team",
+	}
+	assert.Equal(t, [2]string{"worker", serviceNameFromRule}, resolve(n, proc{pid: 2, exe: "/usr/bin/worker"}), "rule does not take precedence")
+	assert.Equal(t, [2]string{"shop", serviceNameFromLabel}, resolve(n, proc{pid: 2, exe: "/usr/bin/app"}))
+	assert.Equal(t, [2]string{"checkout", serviceNameFromEnv}, resolve(n, proc{pid: 1, exe: "/usr/bin/app"}))
+	assert.Equal(t, [2]string{unknownServiceName, serviceNameFromDefault}, resolve(n, proc{pid: 4, exe: "/usr/bin/app"}))
+}
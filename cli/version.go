@@ -4,9 +4,12 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"runtime"
 	"runtime/debug"
 	"sync"
+	"text/tabwriter"
 
 	"go.opentelemetry.io/auto"
 )
@@ -64,3 +67,20 @@ func newGoInfo() goInfo {
 		Arch:    runtime.GOARCH,
 	}
 }
+
+// printVersion writes the agent version and the versions of the instrumented
+// libraries supported by each probe to w.
+func printVersion(w io.Writer) {
+	v := newVersion()
+	fmt.Fprintf(w, "OpenTelemetry Go Automatic Instrumentation %s\n", v.Release)
+	fmt.Fprintf(w, "  revision: %s\n", v.Revision)
+	fmt.Fprintf(w, "  go: %s %s/%s\n", v.Go.Version, v.Go.OS, v.Go.Arch)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Supported libraries:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  PROBE\tMODULE\tVERSIONS")
+	for _, l := range auto.ReadVersionInfo().Libraries {
+		fmt.Fprintf(tw, "  %s\t%s\t%s to %s\n", l.Probe, l.Module, l.Min, l.Max)
+	}
+	_ = tw.Flush()
+}
@@ -10,39 +10,98 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
-	dbSql "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
-	kafkaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/consumer"
-	kafkaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/producer"
-	autosdk "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/auto/sdk"
-	otelTrace "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/otel/trace"
-	otelTraceGlobal "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/otel/traceglobal"
-	grpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/client"
-	grpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/server"
-	httpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/client"
-	httpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/server"
-	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/privilege"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/zpages"
 	"go.opentelemetry.io/auto/internal/pkg/process"
 	"go.opentelemetry.io/auto/pipeline"
 	"go.opentelemetry.io/auto/pipeline/otelsdk"
 )
 
-// envLogLevelKey is the key for the environment variable value containing the log level.
-const envLogLevelKey = "OTEL_LOG_LEVEL"
+const (
+	// envLogLevelKey is the key for the environment variable value containing the log level.
+	envLogLevelKey = "OTEL_LOG_LEVEL"
+	// envProxyModeKey is the key for the environment variable value enabling proxy mode.
+	envProxyModeKey = "OTEL_GO_AUTO_PROXY_MODE"
+	// envDebugAddrKey is the key for the environment variable value containing
+	// the address of the debug pages server.
+	envDebugAddrKey = "OTEL_GO_AUTO_DEBUG_ADDR"
+	// envDebugSpansKey is the key for the environment variable value
+	// containing the number of recent spans shown by the debug pages.
+	envDebugSpansKey = "OTEL_GO_AUTO_DEBUG_SPANS"
+	// envDebugProfilingAddrKey is the key for the environment variable value
+	// containing the address of the profiling server.
+	envDebugProfilingAddrKey = "OTEL_GO_AUTO_DEBUG_PPROF_ADDR"
+	// envDropPrivilegesKey is the key for the environment variable value
+	// enabling the drop of privileges once the probes are loaded.
+	envDropPrivilegesKey = "OTEL_GO_AUTO_DROP_PRIVILEGES"
+	// envValidateOffsetsKey is the key for the environment variable value
+	// enabling the validation of the offsets of the probes.
+	envValidateOffsetsKey = "OTEL_GO_AUTO_VALIDATE_OFFSETS"
+	// envSemconvLintKey is the key for the environment variable value
+	// enabling the semantic convention linting of spans.
+	envSemconvLintKey = "OTEL_GO_AUTO_SEMCONV_LINT"
+	// envK8sWatchEventsKey is the key for the environment variable value
+	// enabling the spans of the events received by Kubernetes watches.
+	envK8sWatchEventsKey = "OTEL_GO_AUTO_K8S_WATCH_EVENTS"
+	// envBboltReadTxKey is the key for the environment variable value
+	// enabling the spans of the read-only transactions of bbolt databases.
+	envBboltReadTxKey = "OTEL_GO_AUTO_BBOLT_READ_TRANSACTIONS"
+	// envHTTPClientConnSpansKey is the key for the environment variable
+	// value enabling the spans of the connections of HTTP clients.
+	envHTTPClientConnSpansKey = "OTEL_GO_AUTO_HTTP_CLIENT_CONNECTION_SPANS"
+	// envGRPCServerMetadataKey is the key for the environment variable value
+	// containing the comma-separated gRPC request metadata keys recorded on
+	// the server spans.
+	envGRPCServerMetadataKey = "OTEL_GO_AUTO_GRPC_SERVER_METADATA"
+	// envEventDumpKey is the key for the environment variable value
+	// containing the path of the file the raw events of the probes are
+	// dumped to.
+	envEventDumpKey = "OTEL_GO_AUTO_EVENT_DUMP"
+)
+
+// ErrUnsupportedPlatform is returned by [NewInstrumentation] when the
+// instrumentation is not supported by the platform. It requires Linux, with a
+// kernel version 4.19 or higher with eBPF and uprobe support.
+var ErrUnsupportedPlatform = errors.New("unsupported platform: instrumentation requires Linux (kernel 4.19+ with eBPF and uprobe support)")
+
+// manager manages the probes attached to the target process.
+type manager interface {
+	Load(context.Context) error
+	Run(context.Context) error
+	Stop() error
+	DropPrivileges(privilege.Set) error
+	Usage() Usage
+}
 
 // Instrumentation manages and controls all OpenTelemetry Go
 // auto-instrumentation.
 type Instrumentation struct {
-	manager *instrumentation.Manager
+	manager manager
 	cleanup func()
 
+	// dropPrivileges is true if the privileges not in retain are dropped
+	// once the probes are loaded.
+	dropPrivileges bool
+	retain         privilege.Set
+
+	logger       *slog.Logger
+	debugServers []debugServer
+
 	stopMu  sync.Mutex
 	stop    context.CancelFunc
 	stopped chan struct{}
@@ -57,57 +116,111 @@ func NewInstrumentation(
 	ctx context.Context,
 	opts ...InstrumentationOption,
 ) (*Instrumentation, error) {
+	if err := checkPlatform(); err != nil {
+		return nil, err
+	}
+
 	c, err := newInstConfig(ctx, opts)
+	if err == nil {
+		err = c.validate()
+	}
 	if err != nil {
+		c.closeHandler()
 		return nil, err
 	}
-	if err := c.validate(); err != nil {
+
+	mngr, servers, err := newManager(ctx, c)
+	if err != nil {
+		c.closeHandler()
 		return nil, err
 	}
+	return &Instrumentation{
+		manager:        mngr,
+		cleanup:        c.handlerClose,
+		logger:         c.logger,
+		debugServers:   servers,
+		dropPrivileges: c.dropPrivileges,
+		retain:         c.retainPrivileges,
+	}, nil
+}
 
-	p := []probe.Probe{
-		grpcClient.New(c.logger, Version()),
-		grpcServer.New(c.logger, Version()),
-		httpServer.New(c.logger, Version()),
-		httpClient.New(c.logger, Version()),
-		dbSql.New(c.logger, Version()),
-		kafkaProducer.New(c.logger, Version()),
-		kafkaConsumer.New(c.logger, Version()),
-		autosdk.New(c.logger),
-		otelTrace.New(c.logger),
-		otelTraceGlobal.New(c.logger),
-	}
+// debugServer is a local debugging server of an Instrumentation. It is
+// served while the Instrumentation runs.
+type debugServer struct {
+	ln      net.Listener
+	handler http.Handler
+}
 
-	cp := convertConfigProvider(c.cp)
-	mngr, err := instrumentation.NewManager(c.logger, c.handler, c.pid, cp, p...)
+// newDebugServer returns a debugging server listening on addr serving h.
+func newDebugServer(addr string, h http.Handler) (debugServer, error) {
+	ln, err := zpages.Listen(addr)
 	if err != nil {
-		return nil, err
+		return debugServer{}, err
 	}
+	return debugServer{ln: ln, handler: h}, nil
+}
 
-	return &Instrumentation{manager: mngr, cleanup: c.handlerClose}, nil
+// closeDebugServers closes the listeners of debugging servers that were never
+// served.
+func closeDebugServers(servers []debugServer) {
+	for _, s := range servers {
+		_ = s.ln.Close()
+	}
 }
 
 // Load loads and attaches the relevant probes to the target process.
+//
+// If [WithPrivilegeDrop] is used, the capabilities of the process no longer
+// needed are dropped once the probes are loaded. The probes are unloaded and
+// an error is returned if they cannot be dropped.
 func (i *Instrumentation) Load(ctx context.Context) error {
-	return i.manager.Load(ctx)
+	if err := i.manager.Load(ctx); err != nil {
+		return err
+	}
+	if i.dropPrivileges {
+		if err := i.manager.DropPrivileges(i.retain); err != nil {
+			return errors.Join(err, i.manager.Stop())
+		}
+	}
+	return nil
 }
 
 // Run starts the instrumentation. It must be called after [Instrumentation.Load].
 //
 // This function will not return until either ctx is done, an unrecoverable
-// error is encountered, or Close is called.
+// error is encountered, or Close is called. Once ctx is done, the probes are
+// detached and unloaded, pending telemetry is flushed for up to 5 seconds,
+// and ctx.Err() is returned. Run returns nil if Close is called.
 func (i *Instrumentation) Run(ctx context.Context) error {
 	if i.cleanup != nil {
 		defer i.cleanup()
 	}
 
+	parent := ctx
 	ctx, err := i.newStop(ctx)
 	if err != nil {
 		return err
 	}
 
+	for _, s := range i.debugServers {
+		go func(s debugServer) {
+			if err := zpages.Serve(ctx, i.logger, s.ln, s.handler); err != nil {
+				i.logger.Error("debug server failed", "error", err)
+			}
+		}(s)
+	}
+	i.debugServers = nil
+
 	err = i.manager.Run(ctx)
 	close(i.stopped)
+	if e := parent.Err(); e != nil {
+		// The error of the manager wraps the cause of ctx, and the errors
+		// cleaning up the probes.
+		if !errors.Is(err, e) {
+			err = errors.Join(e, err)
+		}
+		return err
+	}
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return nil
 	}
@@ -135,6 +248,8 @@ func (i *Instrumentation) Close() error {
 	if i.stop == nil {
 		// if stop is not set, the instrumentation is not running
 		// stop the manager to clean up resources
+		closeDebugServers(i.debugServers)
+		i.debugServers = nil
 		return i.manager.Stop()
 	}
 
@@ -149,6 +264,28 @@ func (i *Instrumentation) Close() error {
 	return nil
 }
 
+// Usage is the resource usage of an [Instrumentation].
+type Usage struct {
+	// Probes is the number of probes attached to the target process.
+	Probes int
+	// Events is the number of events read from the attached probes.
+	Events uint64
+	// LostEvents is the number of events of the attached probes dropped
+	// because their perf buffer was full.
+	LostEvents uint64
+	// MapEntries is the number of entries in the eBPF maps of the attached
+	// probes.
+	MapEntries uint64
+}
+
+// Usage returns the resource usage of the Instrumentation.
+//
+// The eBPF map entries are counted by iterating the maps of the probes, this
+// is not intended to be called often.
+func (i *Instrumentation) Usage() Usage {
+	return i.manager.Usage()
+}
+
 // InstrumentationOption applies a configuration option to [Instrumentation].
 type InstrumentationOption interface {
 	apply(context.Context, instConfig) (instConfig, error)
@@ -161,6 +298,26 @@ type instConfig struct {
 	logger       *slog.Logger
 	sampler      Sampler
 	cp           ConfigProvider
+
+	maxSpanDuration  time.Duration
+	validationPolicy SpanValidationPolicy
+	proxyMode        bool
+	validateOffsets  bool
+	semconvLint      bool
+	k8sWatchEvents   bool
+	bboltReadTx      bool
+	httpConnSpans    bool
+	grpcMetadata     []string
+	eventDump        string
+	// attrFilters are the attribute filters by probe ID.
+	attrFilters map[string]instrumentation.AttributeFilter
+
+	debugAddr          string
+	debugSpans         int
+	debugProfilingAddr string
+
+	dropPrivileges   bool
+	retainPrivileges privilege.Set
 }
 
 func newInstConfig(ctx context.Context, opts []InstrumentationOption) (instConfig, error) {
@@ -211,11 +368,14 @@ func newInstConfig(ctx context.Context, opts []InstrumentationOption) (instConfi
 		err = errors.Join(err, e)
 
 		if th != nil {
-			c.handler = &pipeline.Handler{TraceHandler: th}
+			c.handler = th.PipelineHandler()
 
 			c.handlerClose = sync.OnceFunc(func() {
 				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 				defer stop()
+				// Do not wait for an unreachable collector.
+				ctx, cancel := context.WithTimeout(ctx, handlerShutdownTimeout)
+				defer cancel()
 
 				if err := th.Shutdown(ctx); err != nil {
 					c.logger.Error("failed cleanup", "error", err)
@@ -238,6 +398,17 @@ func newInstConfig(ctx context.Context, opts []InstrumentationOption) (instConfi
 	return c, err
 }
 
+// handlerShutdownTimeout is the maximum duration of the shutdown of the
+// default handler, flushing the pending telemetry.
+var handlerShutdownTimeout = 5 * time.Second
+
+// closeHandler closes the default handler of c, if created.
+func (c instConfig) closeHandler() {
+	if c.handlerClose != nil {
+		c.handlerClose()
+	}
+}
+
 func (c instConfig) validate() error {
 	return c.pid.Validate()
 }
@@ -276,6 +447,30 @@ var lookupEnv = os.LookupEnv
 //   - OTEL_LOG_LEVEL: sets the default logger's minimum logging level
 //   - OTEL_TRACES_SAMPLER: sets the trace sampler
 //   - OTEL_TRACES_SAMPLER_ARG: optionally sets the trace sampler argument
+//   - OTEL_GO_AUTO_PROXY_MODE: enables proxy mode (see [WithProxyMode])
+//   - OTEL_GO_AUTO_DEBUG_ADDR: enables the debug pages server listening on
+//     the loopback address (see [WithDebugServer])
+//   - OTEL_GO_AUTO_DEBUG_SPANS: sets the number of recent spans kept for
+//     each instrumentation scope by the debug pages server
+//   - OTEL_GO_AUTO_DEBUG_PPROF_ADDR: enables the profiling server listening
+//     on the loopback address or unix socket (see [WithDebugProfiling])
+//   - OTEL_GO_AUTO_DROP_PRIVILEGES: enables the drop of the capabilities of
+//     the process once the probes are loaded (see [WithPrivilegeDrop])
+//   - OTEL_GO_AUTO_VALIDATE_OFFSETS: enables the validation of the offsets
+//     of the probes (see [WithOffsetValidation])
+//   - OTEL_GO_AUTO_SEMCONV_LINT: enables the semantic convention linting of
+//     spans (see [WithSemconvLint])
+//   - OTEL_GO_AUTO_K8S_WATCH_EVENTS: enables the spans of the events received
+//     by Kubernetes watches (see [WithKubernetesWatchEvents])
+//   - OTEL_GO_AUTO_BBOLT_READ_TRANSACTIONS: enables the spans of the read-only
+//     transactions of bbolt databases (see [WithBboltReadTransactions])
+//   - OTEL_GO_AUTO_HTTP_CLIENT_CONNECTION_SPANS: enables the spans of the
+//     connections of HTTP clients (see [WithHTTPClientConnectionSpans])
+//   - OTEL_GO_AUTO_GRPC_SERVER_METADATA: sets the comma-separated gRPC
+//     request metadata keys recorded on the server spans (see
+//     [WithGRPCServerMetadata])
+//   - OTEL_GO_AUTO_EVENT_DUMP: enables the dump of the raw events of the
+//     probes to the file at the path value (see [WithEventDump])
 //
 // This option may conflict with [WithSampler] if their respective environment
 // variable is defined. If more than one of these options are used, the last
@@ -305,6 +500,87 @@ func WithEnv() InstrumentationOption {
 		} else {
 			c.sampler = s
 		}
+		if val, ok := lookupEnv(envProxyModeKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse proxy mode %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.proxyMode = enabled
+			}
+		}
+		if val, ok := lookupEnv(envDebugAddrKey); ok {
+			c.debugAddr = val
+		}
+		if val, ok := lookupEnv(envDebugProfilingAddrKey); ok {
+			c.debugProfilingAddr = val
+		}
+		if val, ok := lookupEnv(envDropPrivilegesKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse drop privileges %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.dropPrivileges = enabled
+			}
+		}
+		if val, ok := lookupEnv(envValidateOffsetsKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse offset validation %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.validateOffsets = enabled
+			}
+		}
+		if val, ok := lookupEnv(envSemconvLintKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse semconv lint %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.semconvLint = enabled
+			}
+		}
+		if val, ok := lookupEnv(envK8sWatchEventsKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse kubernetes watch events %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.k8sWatchEvents = enabled
+			}
+		}
+		if val, ok := lookupEnv(envBboltReadTxKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse bbolt read transactions %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.bboltReadTx = enabled
+			}
+		}
+		if val, ok := lookupEnv(envHTTPClientConnSpansKey); ok {
+			if enabled, e := strconv.ParseBool(val); e != nil {
+				e = fmt.Errorf("parse HTTP client connection spans %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.httpConnSpans = enabled
+			}
+		}
+		if val, ok := lookupEnv(envGRPCServerMetadataKey); ok {
+			if keys, e := grpcMetadataKeys(strings.Split(val, ",")); e != nil {
+				e = fmt.Errorf("parse gRPC server metadata %q: %w", val, e)
+				err = errors.Join(err, e)
+			} else {
+				c.grpcMetadata = keys
+			}
+		}
+		if val, ok := lookupEnv(envEventDumpKey); ok {
+			c.eventDump = val
+		}
+		if val, ok := lookupEnv(envDebugSpansKey); ok {
+			if n, e := strconv.Atoi(val); e != nil || n <= 0 {
+				e = fmt.Errorf("invalid %s value %q: must be a positive integer", envDebugSpansKey, val)
+				err = errors.Join(err, e)
+			} else {
+				c.debugSpans = n
+			}
+		}
 		return c, err
 	})
 }
@@ -347,6 +623,367 @@ func WithConfigProvider(cp ConfigProvider) InstrumentationOption {
 	})
 }
 
+// Privilege is a feature of a program using an [Instrumentation] that needs
+// elevated privileges after the probes are loaded.
+type Privilege int
+
+const (
+	// PrivilegeReattach is the loading of probes after [Instrumentation.Load]
+	// when configuration updates enable them.
+	PrivilegeReattach Privilege = iota + 1
+	// PrivilegeDiscovery is the discovery and instrumentation of other
+	// processes: reading their /proc entries and loading probes into them.
+	PrivilegeDiscovery
+)
+
+func (p Privilege) caps() privilege.Set {
+	switch p {
+	case PrivilegeReattach:
+		return privilege.Reattach
+	case PrivilegeDiscovery:
+		return privilege.Discovery
+	}
+	return 0
+}
+
+// WithPrivilegeDrop returns an [InstrumentationOption] that will configure an
+// [Instrumentation] to drop the Linux capabilities of the process once
+// [Instrumentation.Load] has loaded and attached the probes. The privileged
+// operations (eBPF program and map creation, uprobe attachment, BPF
+// file-system mount, and access to the target process) are all done by Load,
+// the probes are run and closed without privileges.
+//
+// The capabilities needed by the retain features are kept. The capabilities
+// needed to load probes ([PrivilegeReattach]) are also kept if the
+// [ConfigProvider] can update the configuration: a provider set with
+// [WithConfigProvider], unless it is returned by [NewStaticConfigProvider].
+//
+// Capabilities are dropped for the whole process and cannot be regained:
+// other [Instrumentation] of the process cannot load probes afterwards unless
+// [PrivilegeDiscovery] is retained. This is not supported by programs using
+// cgo, Load returns an error.
+//
+// This option is disabled by default. It is enabled without retained
+// features by OTEL_GO_AUTO_DROP_PRIVILEGES with [WithEnv].
+func WithPrivilegeDrop(retain ...Privilege) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.dropPrivileges, c.retainPrivileges = true, 0
+		for _, p := range retain {
+			caps := p.caps()
+			if caps == 0 {
+				return c, fmt.Errorf("unknown privilege: %d", p)
+			}
+			c.retainPrivileges |= caps
+		}
+		return c, nil
+	})
+}
+
+// WithMaxSpanDuration returns an [InstrumentationOption] that will configure
+// an [Instrumentation] to drop spans with a duration greater than d. Spans
+// with a negative duration are always dropped.
+//
+// If this option is not used or d is not positive, a maximum duration of one
+// hour is used.
+func WithMaxSpanDuration(d time.Duration) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.maxSpanDuration = d
+		return c, nil
+	})
+}
+
+// SpanValidationPolicy defines how spans that violate a structural invariant
+// are handled before they are exported.
+//
+// Spans are validated to have a valid trace and span ID, a non-empty name, an
+// end time that is not before their start time, and a name and string
+// attributes that are valid UTF-8 and do not contain NUL bytes. Each violation
+// is counted in the otel.auto.span.violations metric.
+type SpanValidationPolicy uint8
+
+const (
+	// SpanValidationDrop drops spans that violate any invariant. This is the
+	// default policy.
+	SpanValidationDrop = SpanValidationPolicy(instrumentation.ValidationPolicyDrop)
+	// SpanValidationRepair repairs spans that violate an invariant where
+	// possible. Empty names are replaced, end times before the start time are
+	// set to the start time, NUL bytes are removed from strings, and invalid
+	// UTF-8 is replaced. Spans with an invalid trace or span ID, or a duration
+	// greater than the maximum span duration, are still dropped.
+	SpanValidationRepair = SpanValidationPolicy(instrumentation.ValidationPolicyRepair)
+)
+
+// WithSpanValidationPolicy returns an [InstrumentationOption] that will
+// configure an [Instrumentation] to handle invalid spans using p.
+//
+// If this option is not used, [SpanValidationDrop] is used.
+func WithSpanValidationPolicy(p SpanValidationPolicy) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.validationPolicy = p
+		return c, nil
+	})
+}
+
+// WithProxyMode returns an [InstrumentationOption] that will configure an
+// [Instrumentation] to suppress the CLIENT spans a proxy makes on behalf of the
+// requests it serves.
+//
+// When enabled, a CLIENT span is not exported if it is the only CLIENT span
+// child of a SERVER span from the same process and it does not have an error
+// status. This halves the spans exported by a proxy that makes exactly one
+// upstream request for each request it serves. CLIENT spans are held for up
+// to 5 seconds, waiting for their parent SERVER span to end, to determine if
+// they are suppressed. Processes where outgoing requests are not 1:1 with
+// incoming requests have all their CLIENT spans exported, after the delay.
+//
+// This option is disabled by default.
+func WithProxyMode(enabled bool) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.proxyMode = enabled
+		return c, nil
+	})
+}
+
+// WithOffsetValidation returns an [InstrumentationOption] that will configure
+// an [Instrumentation] to validate the struct field offsets read by each probe
+// against the DWARF debugging data of the target process before it is
+// attached. Probes with offsets that are not valid are skipped, with the
+// reason reported as their status, instead of reporting corrupt data.
+//
+// The offsets of target processes built without DWARF data (e.g. with
+// -ldflags=-w) cannot be validated, their probes are attached.
+//
+// This option is disabled by default.
+func WithOffsetValidation(enabled bool) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.validateOffsets = enabled
+		return c, nil
+	})
+}
+
+// WithSemconvLint returns an [InstrumentationOption] that will configure an
+// [Instrumentation] to check the spans produced by the probes against the
+// OpenTelemetry semantic conventions before they are exported: the required
+// attributes of their kind must be present, with the expected type and, for
+// enumerations, a known value.
+//
+// Spans are exported unchanged. Each violation is counted by the
+// otel.auto.span.semconv_violations metric and logged as a warning, at most
+// once a minute for the same violation.
+//
+// This option is disabled by default.
+func WithSemconvLint(enabled bool) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.semconvLint = enabled
+		return c, nil
+	})
+}
+
+// WithKubernetesWatchEvents returns an [InstrumentationOption] that will
+// configure an [Instrumentation] to trace the watches of the Kubernetes API
+// made with k8s.io/client-go.
+//
+// Watches are long-lived, they are not traced unless this option is enabled.
+// A span is then produced for each event received by a watch, covering its
+// delivery to the receiver of the watch, as a child of the span of the
+// context the watch was started with.
+//
+// This option is disabled by default.
+func WithKubernetesWatchEvents(enabled bool) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.k8sWatchEvents = enabled
+		return c, nil
+	})
+}
+
+// WithBboltReadTransactions returns an [InstrumentationOption] that will
+// configure an [Instrumentation] to trace the read-only transactions of the
+// databases opened with go.etcd.io/bbolt.
+//
+// Read-only transactions, e.g. the ones of DB.View, are usually frequent and
+// short-lived, only writable transactions are traced unless this option is
+// enabled.
+//
+// This option is disabled by default.
+func WithBboltReadTransactions(enabled bool) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.bboltReadTx = enabled
+		return c, nil
+	})
+}
+
+// WithHTTPClientConnectionSpans returns an [InstrumentationOption] that will
+// configure an [Instrumentation] to trace the connections of the net/http
+// clients.
+//
+// The connections dialed with net.Dialer.DialContext and their TLS
+// handshakes, made with crypto/tls.Conn.HandshakeContext, are traced as
+// INTERNAL "connect" and "TLS handshake" child spans of the client span of
+// the request they are made for. The connections are reused by the requests
+// that follow: most client spans have none. The DNS lookups of the dials are
+// always traced, as "DNS lookup" child spans.
+//
+// This option is disabled by default.
+func WithHTTPClientConnectionSpans(enabled bool) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.httpConnSpans = enabled
+		return c, nil
+	})
+}
+
+// The limits of the gRPC metadata keys recorded on the server spans. They
+// need to be kept in sync with the google.golang.org/grpc server probe.
+const (
+	maxGRPCMetadataKeys   = 4
+	maxGRPCMetadataKeyLen = 32
+)
+
+// WithGRPCServerMetadata returns an [InstrumentationOption] that will
+// configure an [Instrumentation] to record the values of the request metadata
+// keys on the spans of the google.golang.org/grpc servers, as
+// rpc.grpc.request.metadata.<key> attributes.
+//
+// Keys are case-insensitive. Up to 4 keys of at most 32 bytes are supported,
+// an error is returned otherwise. The values are truncated to 64 bytes.
+//
+// No metadata is recorded by default.
+func WithGRPCServerMetadata(keys ...string) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		md, err := grpcMetadataKeys(keys)
+		if err != nil {
+			return c, fmt.Errorf("gRPC server metadata: %w", err)
+		}
+		c.grpcMetadata = md
+		return c, nil
+	})
+}
+
+// grpcMetadataKeys returns the lowercase and deduplicated keys, or an error
+// if they are not supported. Empty keys are ignored.
+func grpcMetadataKeys(keys []string) ([]string, error) {
+	var out []string
+	for _, k := range keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || slices.Contains(out, k) {
+			continue
+		}
+		if len(k) > maxGRPCMetadataKeyLen {
+			return nil, fmt.Errorf("key longer than %d bytes: %q", maxGRPCMetadataKeyLen, k)
+		}
+		out = append(out, k)
+	}
+	if len(out) > maxGRPCMetadataKeys {
+		return nil, fmt.Errorf("more than %d keys: %d", maxGRPCMetadataKeys, len(out))
+	}
+	return out, nil
+}
+
+// AttributeFilter filters the attributes of the spans of a probe.
+//
+// Attribute keys are matched against glob patterns with the syntax of
+// [path.Match] (e.g. "http.request.header.*").
+type AttributeFilter struct {
+	// Allow are the patterns of the keys of the attributes exported. All
+	// attributes are exported if empty.
+	Allow []string
+	// Deny are the patterns of the keys of the attributes not exported. It
+	// takes precedence over Allow.
+	Deny []string
+}
+
+// WithAttributeFilter returns an [InstrumentationOption] that will configure
+// an [Instrumentation] to remove the attributes of the spans produced by the
+// probe with the probe ID (e.g. "database/sql/client" or "net/http/server")
+// filtered by f, before they are exported. Removed attributes are counted by
+// the otel.auto.span.attributes_filtered metric.
+//
+// Attributes required by the semantic conventions of the spans of the probe
+// can be removed, a warning is logged for each of them when the
+// [Instrumentation] is created.
+//
+// If this option is used more than once for the same probe, the last filter
+// is used. An error is returned if a pattern of f is malformed.
+func WithAttributeFilter(probe string, f AttributeFilter) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		filter := instrumentation.AttributeFilter{Allow: f.Allow, Deny: f.Deny}
+		if err := filter.Validate(); err != nil {
+			return c, fmt.Errorf("attribute filter of %s: %w", probe, err)
+		}
+		// Do not modify the filters of the configuration c is copied from.
+		c.attrFilters = maps.Clone(c.attrFilters)
+		if c.attrFilters == nil {
+			c.attrFilters = make(map[string]instrumentation.AttributeFilter)
+		}
+		c.attrFilters[probe] = filter
+		return c, nil
+	})
+}
+
+// WithEventDump returns an [InstrumentationOption] that will configure an
+// [Instrumentation] to write the raw events read from the eBPF programs of
+// the probes to the file at path, before they are processed. Each line of
+// the file is a JSON object with the ID of the probe, the time the event was
+// read, and the base64 encoded event. The dump can be printed, or replayed
+// through the span processing, with the internal/cmd/eventdump command of
+// this repository.
+//
+// The file is truncated when the [Instrumentation] is created. It is rotated
+// when it reaches 16 MiB, and the 3 most recent rotated files are kept with
+// the suffixes .1 to .3.
+//
+// The events contain the data captured by the probes, e.g. URL paths, query
+// texts or message keys. Only use this option to troubleshoot probes, and
+// handle the dump files accordingly.
+//
+// This option is disabled by default, or if path is empty.
+func WithEventDump(path string) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.eventDump = path
+		return c, nil
+	})
+}
+
+// WithDebugServer returns an [InstrumentationOption] that will configure an
+// [Instrumentation] to serve local debugging pages on addr. The pages show the
+// most recent spans produced for each instrumentation scope, the status and
+// event counters of the probes, and the active configuration.
+//
+// The addr must be a loopback address (e.g. "localhost:7777"), the
+// [Instrumentation] fails to be created otherwise. If the host of addr is
+// empty (e.g. ":7777"), 127.0.0.1 is used. A unix domain socket, only
+// accessible by its owner, is used instead if addr has the "unix:" prefix
+// (e.g. "unix:/run/otel-go-auto/debug.sock"). The spans parameter is the
+// number of recent spans kept for each instrumentation scope. If it is not
+// positive, 32 is used.
+//
+// This option is disabled by default. If OTEL_GO_AUTO_DEBUG_ADDR or
+// OTEL_GO_AUTO_DEBUG_SPANS are defined, this option will conflict with
+// [WithEnv]. If both are used, the last one provided will be used.
+func WithDebugServer(addr string, spans int) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.debugAddr, c.debugSpans = addr, spans
+		return c, nil
+	})
+}
+
+// WithDebugProfiling returns an [InstrumentationOption] that will configure an
+// [Instrumentation] to serve the runtime profiles of the agent itself, and
+// its internals, on addr. The profiles are served at /debug/pprof/ in the
+// format expected by "go tool pprof". The goroutine count, memory statistics,
+// probe event counters, and eBPF map fill levels are served as JSON at
+// /debug/vars.
+//
+// The addr has the same requirements as the one of [WithDebugServer], it
+// must be a loopback address or a unix domain socket. The profiling server is
+// separate from the debugging pages server and is not created unless this
+// option, or OTEL_GO_AUTO_DEBUG_PPROF_ADDR with [WithEnv], is used.
+func WithDebugProfiling(addr string) InstrumentationOption {
+	return fnOpt(func(_ context.Context, c instConfig) (instConfig, error) {
+		c.debugProfilingAddr = addr
+		return c, nil
+	})
+}
+
 // WithHandler returns an [InstrumentationOption] that will configure an
 // [Instrumentation] to use h to handle generated telemetry.
 //
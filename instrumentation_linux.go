@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package auto
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/exception"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/zpages"
+)
+
+// probeManager is the manager of the eBPF probes attached to the target
+// process.
+type probeManager struct {
+	*instrumentation.Manager
+}
+
+// Usage returns the resource usage of the loaded and running probes.
+func (m probeManager) Usage() Usage {
+	var u Usage
+	for _, ps := range m.ProbeStatus() {
+		if ps.State != instrumentation.ProbeStateLoaded && ps.State != instrumentation.ProbeStateRunning {
+			continue
+		}
+		u.Probes++
+		u.Events += ps.Stats.Events
+		u.LostEvents += ps.Stats.Lost
+	}
+	for _, maps := range m.ProbeMapUsage() {
+		for _, mu := range maps {
+			u.MapEntries += uint64(mu.Entries)
+		}
+	}
+	return u
+}
+
+// newManager returns the manager of the probes of the target process of c,
+// and the debugging servers configured by c.
+func newManager(ctx context.Context, c instConfig) (manager, []debugServer, error) {
+	exc := exception.NewBuffer()
+	p := bpf.Probes(c.logger, Version(), bpf.Config{
+		KubernetesWatchEvents:     c.k8sWatchEvents,
+		BboltReadTransactions:     c.bboltReadTx,
+		HTTPClientConnectionSpans: c.httpConnSpans,
+		GRPCServerMetadata:        c.grpcMetadata,
+		Exceptions:                exc,
+	})
+
+	h := c.handler
+	if c.semconvLint {
+		// Lint the spans as exported, after they are validated.
+		h = instrumentation.WithSemconvLint(c.logger, h, instrumentation.LintConfig{})
+	}
+	h = instrumentation.WithValidation(c.logger, h, instrumentation.ValidationConfig{
+		MaxSpanDuration: c.maxSpanDuration,
+		Policy:          instrumentation.ValidationPolicy(c.validationPolicy),
+	})
+	var flushProxy func()
+	if c.proxyMode {
+		h, flushProxy = instrumentation.WithProxyMode(c.logger, h, instrumentation.ProxyConfig{})
+	}
+	var rec *zpages.Recorder
+	if c.debugAddr != "" {
+		// Record spans before they are validated or suppressed so the debug
+		// pages show all spans produced by the probes.
+		h, rec = zpages.WithRecorder(h, c.debugSpans)
+	}
+	// Add the panics raised in the spans to them before they are recorded.
+	h = exc.Wrap(h)
+	// Remove the filtered attributes first so they are not shown by the
+	// debug pages either.
+	h = instrumentation.WithAttributeFilter(c.logger, h, c.attrFilters)
+
+	cp := convertConfigProvider(c.cp)
+	// The target executable is analyzed without checking ctx, check it once
+	// done.
+	mngr, err := instrumentation.NewManager(c.logger, h, c.pid, cp, p...)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.validateOffsets {
+		mngr.EnableOffsetValidation()
+	}
+	if flushProxy != nil {
+		// Do not drop the spans held when the instrumentation stops.
+		mngr.FlushOnStop(flushProxy)
+	}
+
+	var exp zpages.ExporterSource
+	if c.handler != nil {
+		exp, _ = c.handler.TraceHandler.(zpages.ExporterSource)
+	}
+
+	var servers []debugServer
+	if c.debugAddr != "" {
+		s, err := newDebugServer(c.debugAddr, zpages.NewHandler(mngr, rec, exp, c.debugSettings()))
+		if err != nil {
+			return nil, nil, err
+		}
+		servers = append(servers, s)
+	}
+	if c.debugProfilingAddr != "" {
+		s, err := newDebugServer(c.debugProfilingAddr, zpages.NewProfilingHandler(mngr, rec, exp))
+		if err != nil {
+			closeDebugServers(servers)
+			return nil, nil, err
+		}
+		servers = append(servers, s)
+	}
+	if c.eventDump != "" {
+		w, err := eventdump.NewWriter(c.eventDump, eventdump.Config{})
+		if err != nil {
+			closeDebugServers(servers)
+			return nil, nil, err
+		}
+		c.logger.Warn("dumping raw probe events", "path", c.eventDump)
+		mngr.EnableEventDump(w)
+	}
+	return probeManager{Manager: mngr}, servers, nil
+}
+
+// debugSettings returns the settings of c shown by the debug pages.
+func (c instConfig) debugSettings() []zpages.Setting {
+	policy := "drop"
+	if c.validationPolicy == SpanValidationRepair {
+		policy = "repair"
+	}
+	maxDur := "default"
+	if c.maxSpanDuration > 0 {
+		maxDur = c.maxSpanDuration.String()
+	}
+	spans := c.debugSpans
+	if spans <= 0 {
+		spans = zpages.DefaultSpansPerScope
+	}
+	dump := "disabled"
+	if c.eventDump != "" {
+		dump = c.eventDump
+	}
+	metadata := "none"
+	if len(c.grpcMetadata) > 0 {
+		metadata = strings.Join(c.grpcMetadata, ", ")
+	}
+	filters := "none"
+	if len(c.attrFilters) > 0 {
+		filters = strings.Join(slices.Sorted(maps.Keys(c.attrFilters)), ", ")
+	}
+	return []zpages.Setting{
+		{Name: "target pid", Value: strconv.Itoa(int(c.pid))},
+		{Name: "proxy mode", Value: strconv.FormatBool(c.proxyMode)},
+		{Name: "offset validation", Value: strconv.FormatBool(c.validateOffsets)},
+		{Name: "semconv lint", Value: strconv.FormatBool(c.semconvLint)},
+		{Name: "kubernetes watch events", Value: strconv.FormatBool(c.k8sWatchEvents)},
+		{Name: "bbolt read transactions", Value: strconv.FormatBool(c.bboltReadTx)},
+		{Name: "HTTP client connection spans", Value: strconv.FormatBool(c.httpConnSpans)},
+		{Name: "gRPC server metadata", Value: metadata},
+		{Name: "event dump", Value: dump},
+		{Name: "attribute filters", Value: filters},
+		{Name: "span validation policy", Value: policy},
+		{Name: "max span duration", Value: maxDur},
+		{Name: "recent spans per scope", Value: strconv.Itoa(spans)},
+	}
+}
+
+// checkPlatform returns nil, the probes are supported on Linux.
+func checkPlatform() error { return nil }
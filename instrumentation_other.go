@@ -0,0 +1,22 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+
+package auto
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// checkPlatform returns an error wrapping ErrUnsupportedPlatform, the probes
+// are only supported on Linux.
+func checkPlatform() error {
+	return fmt.Errorf("%w: running on %s/%s", ErrUnsupportedPlatform, runtime.GOOS, runtime.GOARCH)
+}
+
+func newManager(context.Context, instConfig) (manager, []debugServer, error) {
+	return nil, nil, checkPlatform()
+}
@@ -0,0 +1,25 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+
+package auto
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInstrumentationUnsupportedPlatform(t *testing.T) {
+	_, err := NewInstrumentation(context.Background(), WithPID(os.Getpid()))
+	assert.ErrorIs(t, err, ErrUnsupportedPlatform)
+}
+
+func TestReadVersionInfoUnsupportedPlatform(t *testing.T) {
+	info := ReadVersionInfo()
+	assert.Equal(t, Version(), info.Version)
+	assert.Empty(t, info.Libraries)
+}
@@ -6,11 +6,15 @@ package auto
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/privilege"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/sampling"
 	"go.opentelemetry.io/auto/internal/pkg/process"
 )
@@ -169,6 +173,281 @@ func TestWithSampler(t *testing.T) {
 	})
 }
 
+func TestWithSpanValidation(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, SpanValidationDrop, c.validationPolicy)
+	assert.Zero(t, c.maxSpanDuration)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{
+		WithSpanValidationPolicy(SpanValidationRepair),
+		WithMaxSpanDuration(time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SpanValidationRepair, c.validationPolicy)
+	assert.Equal(t, time.Minute, c.maxSpanDuration)
+}
+
+func TestWithProxyMode(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.proxyMode)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithProxyMode(true)})
+	require.NoError(t, err)
+	assert.True(t, c.proxyMode)
+
+	mockEnv(t, map[string]string{envProxyModeKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.proxyMode)
+
+	mockEnv(t, map[string]string{envProxyModeKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse proxy mode "invalid"`)
+}
+
+func TestWithOffsetValidation(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.validateOffsets)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithOffsetValidation(true)})
+	require.NoError(t, err)
+	assert.True(t, c.validateOffsets)
+
+	mockEnv(t, map[string]string{envValidateOffsetsKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.validateOffsets)
+
+	mockEnv(t, map[string]string{envValidateOffsetsKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse offset validation "invalid"`)
+}
+
+func TestWithSemconvLint(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.semconvLint)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithSemconvLint(true)})
+	require.NoError(t, err)
+	assert.True(t, c.semconvLint)
+
+	mockEnv(t, map[string]string{envSemconvLintKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.semconvLint)
+
+	mockEnv(t, map[string]string{envSemconvLintKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse semconv lint "invalid"`)
+}
+
+func TestWithKubernetesWatchEvents(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.k8sWatchEvents)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithKubernetesWatchEvents(true)})
+	require.NoError(t, err)
+	assert.True(t, c.k8sWatchEvents)
+
+	mockEnv(t, map[string]string{envK8sWatchEventsKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.k8sWatchEvents)
+
+	mockEnv(t, map[string]string{envK8sWatchEventsKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse kubernetes watch events "invalid"`)
+}
+
+func TestWithBboltReadTransactions(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.bboltReadTx)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithBboltReadTransactions(true)})
+	require.NoError(t, err)
+	assert.True(t, c.bboltReadTx)
+
+	mockEnv(t, map[string]string{envBboltReadTxKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.bboltReadTx)
+
+	mockEnv(t, map[string]string{envBboltReadTxKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse bbolt read transactions "invalid"`)
+}
+
+func TestWithHTTPClientConnectionSpans(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.httpConnSpans)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithHTTPClientConnectionSpans(true)})
+	require.NoError(t, err)
+	assert.True(t, c.httpConnSpans)
+
+	mockEnv(t, map[string]string{envHTTPClientConnSpansKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.httpConnSpans)
+
+	mockEnv(t, map[string]string{envHTTPClientConnSpansKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse HTTP client connection spans "invalid"`)
+}
+
+func TestWithGRPCServerMetadata(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, c.grpcMetadata)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{
+		WithGRPCServerMetadata("X-Tenant-ID", " x-request-id", "x-tenant-id", ""),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x-tenant-id", "x-request-id"}, c.grpcMetadata)
+
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{
+		WithGRPCServerMetadata("a", "b", "c", "d", "e"),
+	})
+	assert.ErrorContains(t, err, "more than 4 keys")
+
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{
+		WithGRPCServerMetadata(strings.Repeat("k", 33)),
+	})
+	assert.ErrorContains(t, err, "key longer than 32 bytes")
+
+	mockEnv(t, map[string]string{envGRPCServerMetadataKey: "x-tenant-id,X-Request-Id"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x-tenant-id", "x-request-id"}, c.grpcMetadata)
+
+	mockEnv(t, map[string]string{envGRPCServerMetadataKey: "a,b,c,d,e"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse gRPC server metadata "a,b,c,d,e"`)
+}
+
+func TestWithAttributeFilter(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, c.attrFilters)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{
+		WithAttributeFilter("database/sql/client", AttributeFilter{Deny: []string{"db.query.text"}}),
+		WithAttributeFilter("net/http/server", AttributeFilter{Allow: []string{"http.*"}}),
+		WithAttributeFilter("net/http/server", AttributeFilter{Allow: []string{"http.route"}}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]instrumentation.AttributeFilter{
+		"database/sql/client": {Deny: []string{"db.query.text"}},
+		"net/http/server":     {Allow: []string{"http.route"}},
+	}, c.attrFilters)
+
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{
+		WithAttributeFilter("net/http/server", AttributeFilter{Deny: []string{"http.["}}),
+	})
+	assert.ErrorContains(t, err, `attribute filter of net/http/server: invalid attribute pattern "http.["`)
+}
+
+func TestWithEventDump(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, c.eventDump)
+
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEventDump("/tmp/events.jsonl")})
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/events.jsonl", c.eventDump)
+
+	mockEnv(t, map[string]string{envEventDumpKey: "/var/tmp/events.jsonl"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.Equal(t, "/var/tmp/events.jsonl", c.eventDump)
+}
+
+func TestWithDebugServer(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, c.debugAddr)
+
+	opts := []InstrumentationOption{WithDebugServer("localhost:7777", 8)}
+	c, err = newInstConfig(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:7777", c.debugAddr)
+	assert.Equal(t, 8, c.debugSpans)
+
+	mockEnv(t, map[string]string{
+		envDebugAddrKey:  "127.0.0.1:7777",
+		envDebugSpansKey: "16",
+	})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:7777", c.debugAddr)
+	assert.Equal(t, 16, c.debugSpans)
+
+	mockEnv(t, map[string]string{envDebugSpansKey: "0"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `invalid OTEL_GO_AUTO_DEBUG_SPANS value "0"`)
+}
+
+func TestWithDebugProfiling(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, c.debugProfilingAddr)
+
+	opts := []InstrumentationOption{WithDebugProfiling("unix:/tmp/debug.sock")}
+	c, err = newInstConfig(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "unix:/tmp/debug.sock", c.debugProfilingAddr)
+
+	mockEnv(t, map[string]string{envDebugProfilingAddrKey: ":6060"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.Equal(t, ":6060", c.debugProfilingAddr)
+}
+
+func TestWithPrivilegeDrop(t *testing.T) {
+	c, err := newInstConfig(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, c.dropPrivileges)
+
+	opts := []InstrumentationOption{WithPrivilegeDrop(PrivilegeDiscovery)}
+	c, err = newInstConfig(context.Background(), opts)
+	require.NoError(t, err)
+	assert.True(t, c.dropPrivileges)
+	assert.Equal(t, privilege.Discovery, c.retainPrivileges)
+
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithPrivilegeDrop(0)})
+	assert.ErrorContains(t, err, "unknown privilege: 0")
+
+	mockEnv(t, map[string]string{envDropPrivilegesKey: "true"})
+	c, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	require.NoError(t, err)
+	assert.True(t, c.dropPrivileges)
+	assert.Equal(t, privilege.Set(0), c.retainPrivileges)
+
+	mockEnv(t, map[string]string{envDropPrivilegesKey: "invalid"})
+	_, err = newInstConfig(context.Background(), []InstrumentationOption{WithEnv()})
+	assert.ErrorContains(t, err, `parse drop privileges "invalid"`)
+}
+
+func TestStaticConfigProvider(t *testing.T) {
+	ic := InstrumentationConfig{DefaultTracesDisabled: true}
+	cp := NewStaticConfigProvider(ic)
+	assert.Equal(t, ic, cp.InitialConfig(context.Background()))
+	_, ok := <-cp.Watch()
+	assert.False(t, ok, "configuration updated")
+
+	assert.True(t, convertConfigProvider(cp).(*converter).Static())
+	assert.True(t, convertConfigProvider(newNoopConfigProvider(nil)).(*converter).Static())
+	assert.False(t, convertConfigProvider(struct{ ConfigProvider }{cp}).(*converter).Static())
+}
+
 func mockEnv(t *testing.T, env map[string]string) {
 	orig := lookupEnv
 	t.Cleanup(func() { lookupEnv = orig })
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Eventdump prints and replays the raw probe events dumped by an
+// auto-instrumented process (see the OTEL_GO_AUTO_EVENT_DUMP environment
+// variable).
+//
+// Usage:
+//
+//	go run ./internal/cmd/eventdump [print] [-probe id] [file ...]
+//	go run ./internal/cmd/eventdump replay [flags] [file ...]
+//
+// The records of each file, or of the standard input if no file is given, are
+// read in order.
+//
+// The print command decodes each event with the event type of the probe that
+// read it and prints it one field per line.
+//
+// The replay command passes each event through the processing of the probe
+// that read it, and the span processors configured with its flags, and prints
+// the resulting spans and log records as OTLP JSON, one line per batch. With
+// the -otlp flag, they are exported with the OTLP exporters configured by the
+// OTEL_EXPORTER_OTLP_* and OTEL_LOGS_EXPORTER environment variables instead. Run
+// "eventdump replay -h" for the flags.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+func main() {
+	args := os.Args[1:]
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == "replay":
+		err = runReplay(os.Stdout, args[1:])
+	case len(args) > 0 && args[0] == "print":
+		err = runPrint(os.Stdout, args[1:])
+	default:
+		err = runPrint(os.Stdout, args)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// probes returns the probes, by probe ID, logging with l.
+func probes(l *slog.Logger) map[string]probe.Probe {
+	ps := bpf.Probes(l, "", bpf.Config{})
+	out := make(map[string]probe.Probe, len(ps))
+	for _, p := range ps {
+		out[p.Manifest().ID.String()] = p
+	}
+	return out
+}
+
+// readRecords calls fn with the records of the files, or of the standard
+// input if files is empty. Only the records of probeID are passed if it is
+// not empty.
+func readRecords(files []string, probeID string, fn func(eventdump.Record) error) error {
+	if len(files) == 0 {
+		return read(os.Stdin, probeID, fn)
+	}
+
+	var err error
+	for _, name := range files {
+		err = errors.Join(err, readFile(name, probeID, fn))
+	}
+	return err
+}
+
+func readFile(name, probeID string, fn func(eventdump.Record) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := read(f, probeID, fn); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func read(r io.Reader, probeID string, fn func(eventdump.Record) error) error {
+	dr := eventdump.NewReader(r)
+	for {
+		rec, err := dr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if probeID != "" && rec.Probe != probeID {
+			continue
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// decoder decodes the raw events of a probe.
+type decoder interface {
+	DecodeEvent([]byte) (any, error)
+}
+
+// runPrint runs the print command with args, writing to w.
+func runPrint(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("print", flag.ContinueOnError)
+	probeID := fs.String("probe", "", "only print the events of the probe with this ID (e.g. net/http/server)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ps := probes(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return readRecords(fs.Args(), *probeID, func(rec eventdump.Record) error {
+		printRecord(w, ps, rec)
+		return nil
+	})
+}
+
+// printRecord prints rec decoded with the event type of its probe.
+func printRecord(w io.Writer, ps map[string]probe.Probe, rec eventdump.Record) {
+	fmt.Fprintf(w, "%s %s", rec.Time.Format(time.RFC3339Nano), rec.Probe)
+	d, ok := ps[rec.Probe].(decoder)
+	if !ok {
+		fmt.Fprintf(w, " (unknown probe)\n  %x\n", rec.Payload)
+		return
+	}
+	event, err := d.DecodeEvent(rec.Payload)
+	if err != nil {
+		fmt.Fprintf(w, " (decode error: %s)\n  %x\n", err, rec.Payload)
+		return
+	}
+	fmt.Fprint(w, eventdump.Format(event))
+}
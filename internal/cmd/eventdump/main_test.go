@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+)
+
+func TestProbes(t *testing.T) {
+	ps := probes(nil)
+	assert.Len(t, ps, 64)
+	for id, p := range ps {
+		// All probes can print and replay their events.
+		assert.Implements(t, (*decoder)(nil), p, id)
+		assert.Implements(t, (*replayer)(nil), p, id)
+	}
+}
+
+// httpServerEvent returns a raw net/http/server probe event of a GET request.
+func httpServerEvent(t *testing.T) []byte {
+	t.Helper()
+
+	// Prefix of the event of the probe.
+	var e struct {
+		context.BaseSpanProperties
+		StatusCode uint64
+		Method     [8]byte
+		Path       [128]byte
+	}
+	e.StartTime, e.EndTime = 1000, 2000
+	e.SpanContext.TraceID = [16]byte{1}
+	e.SpanContext.SpanID = [8]byte{1}
+	e.StatusCode = 200
+	copy(e.Method[:], "GET")
+	copy(e.Path[:], "/")
+
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, e))
+	// Trailing bytes are ignored when decoding, the full event is larger.
+	return append(buf.Bytes(), make([]byte, 4096)...)
+}
+
+func writeDump(t *testing.T, records ...eventdump.Record) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := eventdump.NewWriter(path, eventdump.Config{})
+	require.NoError(t, err)
+	for _, r := range records {
+		require.NoError(t, w.Write(r.Probe, r.Payload))
+	}
+	require.NoError(t, w.Close())
+	return path
+}
+
+func TestPrint(t *testing.T) {
+	path := writeDump(t,
+		eventdump.Record{Probe: "net/http/server", Payload: httpServerEvent(t)},
+		eventdump.Record{Probe: "net/http/client", Payload: []byte{1}},
+		eventdump.Record{Probe: "unknown/probe", Payload: []byte{0xca, 0xfe}},
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, runPrint(&buf, []string{path}))
+	out := buf.String()
+	assert.Contains(t, out, " net/http/server\n")
+	assert.Contains(t, out, "  StatusCode: 200\n")
+	assert.Contains(t, out, "  Method: \"GET\"\n")
+	assert.Contains(t, out, " net/http/client (decode error: ")
+	assert.Contains(t, out, " unknown/probe (unknown probe)\n  cafe\n")
+
+	buf.Reset()
+	require.NoError(t, runPrint(&buf, []string{"-probe", "unknown/probe", path}))
+	assert.NotContains(t, buf.String(), "net/http")
+	assert.Contains(t, buf.String(), "unknown/probe")
+}
+
+func TestReplay(t *testing.T) {
+	path := writeDump(t,
+		eventdump.Record{Probe: "net/http/server", Payload: httpServerEvent(t)},
+		// Invalid events, and events of unknown probes, are skipped.
+		eventdump.Record{Probe: "net/http/client", Payload: []byte{1}},
+		eventdump.Record{Probe: "unknown/probe", Payload: []byte{1}},
+		// Spans without IDs are dropped by validation.
+		eventdump.Record{Probe: "net/http/server", Payload: make([]byte, 4096)},
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, runReplay(&buf, []string{"-semconv-lint", path}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"name":"go.opentelemetry.io/auto/net/http/server"`)
+	assert.Contains(t, lines[0], `"traceId":"01000000000000000000000000000000"`)
+	assert.Contains(t, lines[0], `"key":"http.request.method","value":{"stringValue":"GET"}`)
+
+	buf.Reset()
+	require.NoError(t, runReplay(&buf, []string{"-sample-ratio", "0", path}))
+	assert.Empty(t, buf.String())
+}
+
+func TestParseReplayConfig(t *testing.T) {
+	c, files, err := parseReplayConfig([]string{"-probe", "net/http/server", "-span-validation", "repair", "a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "net/http/server", c.probeID)
+	assert.Equal(t, "repair", c.validation)
+	assert.Equal(t, 1.0, c.sampleRatio)
+	assert.Equal(t, []string{"a", "b"}, files)
+
+	_, _, err = parseReplayConfig([]string{"-span-validation", "keep"})
+	assert.ErrorContains(t, err, `invalid span validation policy "keep"`)
+
+	_, _, err = parseReplayConfig([]string{"-sample-ratio", "2"})
+	assert.ErrorContains(t, err, "invalid sample ratio")
+}
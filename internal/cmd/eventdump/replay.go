@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+	"go.opentelemetry.io/auto/pipeline"
+	"go.opentelemetry.io/auto/pipeline/otelsdk"
+)
+
+// replayer replays the raw events of a probe.
+type replayer interface {
+	Replay([]byte, *pipeline.Handler) error
+}
+
+// replayConfig is the configuration of the replay command.
+type replayConfig struct {
+	probeID     string
+	sampleRatio float64
+	validation  string
+	lint        bool
+	otlp        bool
+	verbose     bool
+}
+
+func parseReplayConfig(args []string) (replayConfig, []string, error) {
+	var c replayConfig
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.StringVar(&c.probeID, "probe", "", "only replay the events of the probe with this ID (e.g. net/http/server)")
+	fs.Float64Var(&c.sampleRatio, "sample-ratio", 1, "ratio of the traces kept, based on their trace ID")
+	fs.StringVar(&c.validation, "span-validation", "drop", `policy applied to invalid spans: "drop" or "repair"`)
+	fs.BoolVar(&c.lint, "semconv-lint", false, "check the spans against the semantic conventions")
+	fs.BoolVar(&c.otlp, "otlp", false, "export the spans with the OTLP exporter configured by the OTEL_EXPORTER_OTLP_* environment variables instead of printing them")
+	fs.BoolVar(&c.verbose, "v", false, "log the processing of the spans")
+	if err := fs.Parse(args); err != nil {
+		return c, nil, err
+	}
+
+	if c.sampleRatio < 0 || c.sampleRatio > 1 {
+		return c, nil, fmt.Errorf("invalid sample ratio %v: must be in [0, 1]", c.sampleRatio)
+	}
+	switch c.validation {
+	case "drop", "repair":
+	default:
+		return c, nil, fmt.Errorf("invalid span validation policy %q", c.validation)
+	}
+	return c, fs.Args(), nil
+}
+
+// runReplay runs the replay command with args. Spans are printed to w.
+func runReplay(w io.Writer, args []string) error {
+	c, files, err := parseReplayConfig(args)
+	if err != nil {
+		return err
+	}
+
+	level := slog.LevelWarn
+	if c.verbose {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	var (
+		h        *pipeline.Handler
+		shutdown = func(context.Context) error { return nil }
+	)
+	if c.otlp {
+		th, err := otelsdk.NewTraceHandler(context.Background(), otelsdk.WithEnv(), otelsdk.WithLogger(logger))
+		if err != nil {
+			return err
+		}
+		h, shutdown = th.PipelineHandler(), th.Shutdown
+	} else {
+		p := &printer{w: w}
+		h = &pipeline.Handler{TraceHandler: p, LogHandler: p}
+	}
+	h = replayHandler(logger, h, c)
+
+	ps := probes(logger)
+	err = readRecords(files, c.probeID, func(rec eventdump.Record) error {
+		r, ok := ps[rec.Probe].(replayer)
+		if !ok {
+			logger.Warn("skipping event of unknown probe", "probe", rec.Probe)
+			return nil
+		}
+		if err := r.Replay(rec.Payload, h); err != nil {
+			logger.Warn("skipping invalid event", "probe", rec.Probe, "time", rec.Time, "error", err)
+		}
+		return nil
+	})
+	return errors.Join(err, shutdown(context.Background()))
+}
+
+// replayHandler returns h wrapped with the span processors configured by c,
+// in the order the instrumentation applies them.
+func replayHandler(l *slog.Logger, h *pipeline.Handler, c replayConfig) *pipeline.Handler {
+	if c.lint {
+		h = instrumentation.WithSemconvLint(l, h, instrumentation.LintConfig{})
+	}
+	vc := instrumentation.ValidationConfig{Policy: instrumentation.ValidationPolicyDrop}
+	if c.validation == "repair" {
+		vc.Policy = instrumentation.ValidationPolicyRepair
+	}
+	h = instrumentation.WithValidation(l, h, vc)
+	if c.sampleRatio < 1 {
+		h = &pipeline.Handler{
+			TraceHandler: &ratioSampler{
+				next:    h.TraceHandler,
+				sampler: sdk.TraceIDRatioBased(c.sampleRatio),
+			},
+			LogHandler: h.LogHandler,
+		}
+	}
+	return h
+}
+
+// ratioSampler is a [pipeline.TraceHandler] that drops the spans of the
+// traces not sampled by a trace ID ratio based sampler.
+type ratioSampler struct {
+	next    pipeline.TraceHandler
+	sampler sdk.Sampler
+}
+
+func (s *ratioSampler) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	spans.RemoveIf(func(span ptrace.Span) bool {
+		res := s.sampler.ShouldSample(sdk.SamplingParameters{TraceID: trace.TraceID(span.TraceID())})
+		return res.Decision == sdk.Drop
+	})
+	if spans.Len() > 0 {
+		s.next.HandleTrace(scope, url, spans)
+	}
+}
+
+// printer is a [pipeline.TraceHandler] and [pipeline.LogHandler] printing the
+// spans and log records as OTLP JSON, one line per batch.
+type printer struct {
+	mu           sync.Mutex
+	w            io.Writer
+	marshaler    ptrace.JSONMarshaler
+	logMarshaler plog.JSONMarshaler
+}
+
+func (p *printer) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	td := ptrace.NewTraces()
+	ss := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty()
+	scope.CopyTo(ss.Scope())
+	ss.SetSchemaUrl(url)
+	spans.CopyTo(ss.Spans())
+
+	b, err := p.marshaler.MarshalTraces(td)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to marshal spans:", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = p.w.Write(append(b, '\n'))
+}
+
+func (p *printer) HandleLog(scope pcommon.InstrumentationScope, url string, logs plog.LogRecordSlice) {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	scope.CopyTo(sl.Scope())
+	sl.SetSchemaUrl(url)
+	logs.CopyTo(sl.LogRecords())
+
+	b, err := p.logMarshaler.MarshalLogs(ld)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to marshal log records:", err)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = p.w.Write(append(b, '\n'))
+}
@@ -72,4 +72,14 @@ static __always_inline void *get_argument(struct pt_regs *ctx, int index)
     }
 }
 
+// Returns the address of the arguments passed on the stack (e.g. structs too
+// large to be assigned to registers) to the function the uprobe is attached
+// to. It must be called at the function entry: the first stack argument is
+// above the return address on amd64, and above the reserved link register
+// slot on arm64.
+static __always_inline void *get_stack_arguments(struct pt_regs *ctx)
+{
+    return (void *)(PT_REGS_SP(ctx) + 8);
+}
+
 #endif
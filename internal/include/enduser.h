@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#ifndef _ENDUSER_H_
+#define _ENDUSER_H_
+
+#include "bpf_helpers.h"
+#include "utils.h"
+
+// Must match the limits in the internal/pkg/instrumentation/enduser package.
+#define ENDUSER_HEADER_MAX_LEN 64
+// Maximum length of a captured identity value. Longer values are dropped, not
+// truncated, so a partial identity is never reported.
+#define ENDUSER_VALUE_MAX_LEN 1024
+
+struct enduser
+{
+    u64 len;
+    char value[ENDUSER_VALUE_MAX_LEN];
+};
+
+// Injected in init
+// A flag indicating whether the capture of the end user identity is enabled.
+volatile const bool enduser_enabled;
+// The lowercase name of the request header containing the end user identity.
+volatile const char enduser_header[ENDUSER_HEADER_MAX_LEN];
+volatile const u64 enduser_header_len;
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct enduser));
+    __uint(max_entries, 1);
+} enduser_storage_map SEC(".maps");
+
+// Returns the number of bytes of the size long event whose last field is a
+// struct enduser starting at offset to output. The end user identity is only
+// output if its capture is enabled.
+static __always_inline u64 enduser_event_size(u64 size, u64 offset) {
+    return enduser_enabled ? size : offset;
+}
+
+// Returns a zeroed per-CPU end user buffer, or NULL on failure.
+static __always_inline struct enduser *enduser_buffer() {
+    u32 zero = 0;
+    struct enduser *eu = bpf_map_lookup_elem(&enduser_storage_map, &zero);
+    if (eu == NULL) {
+        return NULL;
+    }
+    __builtin_memset(eu, 0, sizeof(struct enduser));
+    return eu;
+}
+
+// Returns true if the name_len long header name located at the user space
+// address name is the configured end user header. The header name is compared
+// case-insensitively.
+static __always_inline bool is_enduser_header(void *name, u64 name_len) {
+    if (!enduser_enabled || name_len != enduser_header_len || name_len == 0 || name_len > ENDUSER_HEADER_MAX_LEN) {
+        return false;
+    }
+    char current[ENDUSER_HEADER_MAX_LEN];
+    if (bpf_probe_read_user(current, name_len, name) < 0) {
+        return false;
+    }
+    return !bpf_memicmp(current, (const char *)enduser_header, name_len);
+}
+
+// Reads an end user identity value of len bytes located at the user space
+// address str into eu. Returns 0 on success, negative value otherwise. Values
+// longer than ENDUSER_VALUE_MAX_LEN are not read and eu is left empty.
+static __always_inline long read_enduser(void *str, u64 len, struct enduser *eu) {
+    eu->len = 0;
+    if (str == NULL || len == 0 || len > ENDUSER_VALUE_MAX_LEN) {
+        return -1;
+    }
+
+    long res = bpf_probe_read_user(eu->value, len, str);
+    if (res < 0) {
+        return res;
+    }
+    eu->len = len;
+    return 0;
+}
+
+#endif
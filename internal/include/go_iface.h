@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#ifndef _GO_IFACE_H_
+#define _GO_IFACE_H_
+
+#include "bpf_helpers.h"
+#include "go_types.h"
+
+// Helpers reading the values held by Go interfaces from their runtime type,
+// an abi.Type, and data. The layout of the runtime types is the same from
+// go1.19 to go1.24.
+
+// The reflect.Kind of a runtime type.
+#define GO_KIND_BOOL 1
+#define GO_KIND_INT 2
+#define GO_KIND_UINT8 8
+#define GO_KIND_PTR 22
+#define GO_KIND_STRING 24
+#define GO_KIND_STRUCT 25
+#define GO_KIND_MASK 0x1f
+
+// The offsets of the fields of an abi.Type.
+#define GO_TYPE_TFLAG_OFFSET 20
+#define GO_TYPE_KIND_OFFSET 23
+#define GO_TYPE_STR_OFFSET 40
+// The offset of the element type of an abi.PtrType.
+#define GO_PTR_TYPE_ELEM_OFFSET 48
+// The offset of the fields of an abi.StructType.
+#define GO_STRUCT_TYPE_FIELDS_OFFSET 56
+
+// The name of the type has an extra leading "*" (abi.TFlagExtraStar).
+#define GO_TFLAG_EXTRA_STAR 2
+
+// The layout of an abi.StructField.
+struct go_struct_field {
+    void *name;
+    void *type;
+    u64 offset;
+};
+
+// go_type_kind returns the reflect.Kind of type, 0 if type is NULL.
+static __always_inline u8 go_type_kind(void *type) {
+    u8 kind = 0;
+    if (type != NULL) {
+        bpf_probe_read_user(&kind, sizeof(kind), type + GO_TYPE_KIND_OFFSET);
+    }
+    return kind & GO_KIND_MASK;
+}
+
+// go_ptr_type_elem returns the element type of the pointer type type.
+static __always_inline void *go_ptr_type_elem(void *type) {
+    void *elem = NULL;
+    bpf_probe_read_user(&elem, sizeof(elem), type + GO_PTR_TYPE_ELEM_OFFSET);
+    return elem;
+}
+
+// go_type_name reads the name of type (e.g. "*errors.errorString") into buf.
+// The names of the runtime types are stored relative to the start of the
+// types of their module, types (the runtime.types symbol). Returns the size
+// of the name read, 0 if it is not read.
+static __always_inline u64 go_type_name(void *type, void *types, char *buf, u64 size) {
+    if (type == NULL || types == NULL) {
+        return 0;
+    }
+
+    u8 tflag = 0;
+    bpf_probe_read_user(&tflag, sizeof(tflag), type + GO_TYPE_TFLAG_OFFSET);
+    s32 str = 0;
+    bpf_probe_read_user(&str, sizeof(str), type + GO_TYPE_STR_OFFSET);
+    if (str <= 0) {
+        return 0;
+    }
+
+    // An abi.Name is a byte of flags followed by the varint encoded length
+    // of the name and its bytes.
+    u8 hdr[3] = {0};
+    void *name = types + str;
+    if (bpf_probe_read_user(hdr, sizeof(hdr), name) != 0) {
+        return 0;
+    }
+    u64 len = hdr[1] & 0x7f;
+    void *data = name + 2;
+    if (hdr[1] & 0x80) {
+        len |= (u64)hdr[2] << 7;
+        data++;
+    }
+    if ((tflag & GO_TFLAG_EXTRA_STAR) && len > 0) {
+        data++;
+        len--;
+    }
+
+    u64 n = len < size ? len : size;
+    if (n == 0 || bpf_probe_read_user(buf, n, data) != 0) {
+        return 0;
+    }
+    return n;
+}
+
+// go_read_string reads the Go string at str_ptr into buf. Returns true if the
+// string is longer than size and truncated.
+static __always_inline bool go_read_string(void *str_ptr, char *buf, u64 size) {
+    struct go_string str = {0};
+    if (bpf_probe_read_user(&str, sizeof(str), str_ptr) != 0 || str.len <= 0) {
+        return false;
+    }
+    u64 n = (u64)str.len < size ? str.len : size;
+    bpf_probe_read_user(buf, n, str.str);
+    return (u64)str.len > size;
+}
+
+// go_iface_string reads the string held by the interface value of type and
+// data into buf: a value of a string kind (e.g. a string, or a
+// runtime.errorString), or the first field of a value of a struct kind, or of
+// a pointer to one, if it is a string (e.g. an *errors.errorString). Returns
+// true if a string is read.
+static __always_inline bool go_iface_string(void *type, void *data, char *buf, u64 size) {
+    if (data == NULL) {
+        return false;
+    }
+
+    u8 kind = go_type_kind(type);
+    if (kind == GO_KIND_STRING) {
+        // The data of non-pointer values is a pointer to the value.
+        go_read_string(data, buf, size);
+        return true;
+    }
+
+    void *struct_type = type;
+    if (kind == GO_KIND_PTR) {
+        struct_type = go_ptr_type_elem(type);
+        kind = go_type_kind(struct_type);
+    }
+    if (kind != GO_KIND_STRUCT) {
+        return false;
+    }
+
+    struct go_slice fields = {0};
+    bpf_probe_read_user(&fields, sizeof(fields), struct_type + GO_STRUCT_TYPE_FIELDS_OFFSET);
+    if (fields.len <= 0 || fields.array == NULL) {
+        return false;
+    }
+    struct go_struct_field field = {0};
+    bpf_probe_read_user(&field, sizeof(field), fields.array);
+    if (go_type_kind(field.type) != GO_KIND_STRING) {
+        return false;
+    }
+    go_read_string(data + field.offset, buf, size);
+    return true;
+}
+
+#endif
@@ -7,9 +7,15 @@
 #include "common.h"
 #include "go_types.h"
 
+#define IP_ZONE_MAX_LEN 16 // IF_NAMESIZE
+
 typedef struct net_addr {
     u8 ip[16];
     u32 port;
+    // Number of bytes of ip that are set: 4 for IPv4, 16 for IPv6.
+    u8 ip_len;
+    // IPv6 scoped addressing zone, not NUL terminated if IP_ZONE_MAX_LEN long.
+    char zone[IP_ZONE_MAX_LEN];
 } net_addr_t;
 
 /*
@@ -21,6 +27,7 @@ type TCPAddr struct {
 */
 const volatile u64 TCPAddr_IP_offset;
 const volatile u64 TCPAddr_Port_offset;
+const volatile u64 TCPAddr_Zone_offset;
 
 static __always_inline long get_tcp_net_addr_from_tcp_addr(struct pt_regs *ctx, net_addr_t *addr, void* tcpAddr_ptr) {
     go_slice_t ip;
@@ -45,6 +52,10 @@ static __always_inline long get_tcp_net_addr_from_tcp_addr(struct pt_regs *ctx,
         bpf_printk("failed to read ip array");
         return res;
     }
+    addr->ip_len = ip_slice_len;
+
+    // The zone is optional, an empty or unreadable zone is not an error.
+    get_go_string_from_user_ptr((void *)(tcpAddr_ptr + TCPAddr_Zone_offset), addr->zone, sizeof(addr->zone));
 
     res = bpf_probe_read_user(&addr->port, sizeof(addr->port), (void *)(tcpAddr_ptr + TCPAddr_Port_offset));
     if (res != 0) {
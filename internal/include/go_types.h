@@ -159,17 +159,20 @@ static __always_inline void append_item_to_slice(void *new_item, u32 item_size,
     }
 }
 
-static __always_inline bool get_go_string_from_user_ptr(void *user_str_ptr, char *dst, u64 max_len)
+// Reads the Go string located at user_str_ptr into dst, truncated to max_len
+// bytes. The length of the Go string is returned, greater than max_len if it
+// was truncated, or 0 if it is empty or could not be read.
+static __always_inline u64 get_go_string_len_from_user_ptr(void *user_str_ptr, char *dst, u64 max_len)
 {
     if (user_str_ptr == NULL || max_len == 0) {
-        return false;
+        return 0;
     }
 
     struct go_string user_str = {0};
     long success = 0;
     success = bpf_probe_read_user(&user_str, sizeof(struct go_string), user_str_ptr);
     if (success != 0 || user_str.len < 1) {
-        return false;
+        return 0;
     }
 
     u64 size_to_read = user_str.len > max_len ? max_len : user_str.len;
@@ -177,8 +180,13 @@ static __always_inline bool get_go_string_from_user_ptr(void *user_str_ptr, char
     
     success = bpf_probe_read_user(dst, size_to_read, user_str.str);
     if (success != 0) {
-        return false;
+        return 0;
     }
-    return true;
+    return user_str.len;
+}
+
+static __always_inline bool get_go_string_from_user_ptr(void *user_str_ptr, char *dst, u64 max_len)
+{
+    return get_go_string_len_from_user_ptr(user_str_ptr, dst, max_len) > 0;
 }
 #endif
@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#ifndef _GOROUTINE_SPANS_H_
+#define _GOROUTINE_SPANS_H_
+
+#include "bpf_helpers.h"
+#include "trace/span_context.h"
+
+#define MAX_GOROUTINE_SPANS 1000
+
+// The span context of the SERVER spans of the goroutines handling requests
+// (e.g. with net/http or google.golang.org/grpc). Entries are set by the
+// server probes for the duration of their span. Probes of functions not
+// passed a context.Context (e.g. loggers) use it to find the span active in
+// the goroutine calling them.
+struct
+{
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *); // goroutine
+    __type(value, struct span_context);
+    __uint(max_entries, MAX_GOROUTINE_SPANS);
+    __uint(pinning, LIBBPF_PIN_BY_NAME);
+} goroutine_spans SEC(".maps");
+
+// Sets sc as the span active in goroutine.
+static __always_inline void set_goroutine_span(void *goroutine, struct span_context *sc) {
+    bpf_map_update_elem(&goroutine_spans, &goroutine, sc, BPF_ANY);
+}
+
+// Returns the span context of the span active in goroutine, or NULL if there
+// is none.
+static __always_inline struct span_context *get_goroutine_span(void *goroutine) {
+    return bpf_map_lookup_elem(&goroutine_spans, &goroutine);
+}
+
+static __always_inline void delete_goroutine_span(void *goroutine) {
+    bpf_map_delete_elem(&goroutine_spans, &goroutine);
+}
+
+#endif
@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#ifndef _HTTP_CLIENT_H_
+#define _HTTP_CLIENT_H_
+
+#include "bpf_helpers.h"
+#include "trace/span_context.h"
+
+#define MAX_HTTP_CLIENT_OWNERS 1000
+
+// The span context of the CLIENT spans of the goroutines sending the requests
+// of a client built on net/http (e.g. the Elasticsearch client). Entries are
+// set by the probes of these clients for the duration of their span. The
+// net/http client probe does not produce spans for the requests sent by these
+// goroutines, the span context of the owner is propagated instead.
+struct
+{
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *); // goroutine
+    __type(value, struct span_context);
+    __uint(max_entries, MAX_HTTP_CLIENT_OWNERS);
+    __uint(pinning, LIBBPF_PIN_BY_NAME);
+} http_client_owners SEC(".maps");
+
+// Sets sc as the owner of the requests sent with net/http by goroutine.
+static __always_inline void set_http_client_owner(void *goroutine, struct span_context *sc) {
+    bpf_map_update_elem(&http_client_owners, &goroutine, sc, BPF_ANY);
+}
+
+// Returns the span context of the owner of the requests sent with net/http by
+// goroutine, or NULL if they are not owned.
+static __always_inline struct span_context *get_http_client_owner(void *goroutine) {
+    return bpf_map_lookup_elem(&http_client_owners, &goroutine);
+}
+
+static __always_inline void delete_http_client_owner(void *goroutine) {
+    bpf_map_delete_elem(&http_client_owners, &goroutine);
+}
+
+#endif
@@ -25,6 +25,32 @@ struct span_context
     u8 padding[7];
 };
 
+// Returns true if the trace ID and span ID of sc are both non-zero.
+static __always_inline bool is_span_context_valid(struct span_context *sc)
+{
+    bool trace_id_valid = false;
+    for (int i = 0; i < TRACE_ID_SIZE; i++)
+    {
+        if (sc->TraceID[i] != 0)
+        {
+            trace_id_valid = true;
+            break;
+        }
+    }
+    if (!trace_id_valid)
+    {
+        return false;
+    }
+    for (int i = 0; i < SPAN_ID_SIZE; i++)
+    {
+        if (sc->SpanID[i] != 0)
+        {
+            return true;
+        }
+    }
+    return false;
+}
+
 // Fill the child span context based on the parent span context,
 // generating a new span id and copying the trace id and trace flags
 static __always_inline void get_span_context_from_parent(struct span_context *parent, struct span_context *child) {
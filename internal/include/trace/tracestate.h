@@ -0,0 +1,267 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#ifndef _TRACESTATE_H_
+#define _TRACESTATE_H_
+
+#include "bpf_helpers.h"
+#include "span_context.h"
+
+#define TRACESTATE_KEY_LENGTH 10 // length of the "tracestate" key
+// Maximum length of a propagated tracestate value. Longer values are dropped.
+#define TRACESTATE_MAX_LEN 512
+#define TRACESTATE_MAX_MEMBERS 32
+#define TRACESTATE_MAX_KEY_LEN 256
+#define TRACESTATE_MAX_TENANT_LEN 241
+#define TRACESTATE_MAX_SYSTEM_LEN 14
+#define TRACESTATE_MAX_VAL_LEN 256
+#define MAX_CONCURRENT_TRACESTATES 1000
+
+struct tracestate
+{
+    u64 len;
+    char value[TRACESTATE_MAX_LEN];
+};
+
+struct trace_id_key
+{
+    u8 TraceID[TRACE_ID_SIZE];
+};
+
+// The tracestate received from the remote parent of each trace active in the
+// process. Entries are set by server probes when a span is started from an
+// extracted remote context and read by client probes to propagate the
+// tracestate. Entries are evicted when the map is full.
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, struct trace_id_key);
+    __type(value, struct tracestate);
+    __uint(max_entries, MAX_CONCURRENT_TRACESTATES);
+    __uint(pinning, LIBBPF_PIN_BY_NAME);
+} tracestate_by_trace_id SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct tracestate));
+    __uint(max_entries, 1);
+} tracestate_storage_map SEC(".maps");
+
+// Returns a zeroed per-CPU tracestate buffer, or NULL on failure.
+static __always_inline struct tracestate *tracestate_buffer() {
+    u32 zero = 0;
+    struct tracestate *ts = bpf_map_lookup_elem(&tracestate_storage_map, &zero);
+    if (ts == NULL) {
+        return NULL;
+    }
+    __builtin_memset(ts, 0, sizeof(struct tracestate));
+    return ts;
+}
+
+static __always_inline bool is_lcalpha(char c) {
+    return c >= 'a' && c <= 'z';
+}
+
+static __always_inline bool is_digit(char c) {
+    return c >= '0' && c <= '9';
+}
+
+static __always_inline bool is_key_char(char c) {
+    return is_lcalpha(c) || is_digit(c) || c == '_' || c == '-' || c == '*' || c == '/';
+}
+
+static __always_inline bool is_ows(char c) {
+    return c == ' ' || c == '\t';
+}
+
+enum tracestate_parse_state {
+    TS_MEMBER_START,
+    TS_KEY,
+    TS_SYSTEM_ID_START,
+    TS_VALUE,
+    TS_MEMBER_END,
+};
+
+// Returns true if the len bytes of ts are a valid W3C tracestate header value
+// with at least one list-member.
+//
+// https://www.w3.org/TR/trace-context/#tracestate-header-field-values
+static __always_inline bool tracestate_valid(struct tracestate *ts) {
+    u64 len = ts->len;
+    if (len == 0 || len > TRACESTATE_MAX_LEN) {
+        return false;
+    }
+
+    u8 state = TS_MEMBER_START;
+    u32 members = 0;
+    u32 key_len = 0;
+    u32 val_len = 0;
+    bool first_digit = false;
+    bool in_system_id = false;
+    bool value_blank = true;
+
+    for (u32 i = 0; i < TRACESTATE_MAX_LEN; i++) {
+        if (i >= len) {
+            break;
+        }
+        char c = ts->value[i];
+
+        switch (state) {
+        case TS_MEMBER_START:
+            if (is_ows(c) || c == ',') {
+                // Empty and whitespace-only list-members are allowed.
+                continue;
+            }
+            if (!is_lcalpha(c) && !is_digit(c)) {
+                return false;
+            }
+            first_digit = is_digit(c);
+            in_system_id = false;
+            key_len = 1;
+            state = TS_KEY;
+            break;
+        case TS_KEY:
+            if (c == '=') {
+                // Only the tenant-id of a multi-tenant key can start with a digit.
+                if (first_digit && !in_system_id) {
+                    return false;
+                }
+                if (++members > TRACESTATE_MAX_MEMBERS) {
+                    return false;
+                }
+                val_len = 0;
+                value_blank = true;
+                state = TS_VALUE;
+                break;
+            }
+            if (c == '@') {
+                if (in_system_id || key_len > TRACESTATE_MAX_TENANT_LEN) {
+                    return false;
+                }
+                state = TS_SYSTEM_ID_START;
+                break;
+            }
+            if (!is_key_char(c)) {
+                return false;
+            }
+            key_len++;
+            if (in_system_id && key_len > TRACESTATE_MAX_SYSTEM_LEN) {
+                return false;
+            }
+            if (key_len > TRACESTATE_MAX_KEY_LEN) {
+                return false;
+            }
+            break;
+        case TS_SYSTEM_ID_START:
+            if (!is_lcalpha(c)) {
+                return false;
+            }
+            in_system_id = true;
+            key_len = 1;
+            state = TS_KEY;
+            break;
+        case TS_VALUE:
+            if (c == ',') {
+                if (value_blank) {
+                    return false;
+                }
+                state = TS_MEMBER_START;
+                break;
+            }
+            if (c == '\t') {
+                if (value_blank) {
+                    return false;
+                }
+                state = TS_MEMBER_END;
+                break;
+            }
+            if (c < 0x20 || c > 0x7e || c == '=') {
+                return false;
+            }
+            if (c != ' ') {
+                value_blank = false;
+            }
+            if (++val_len > TRACESTATE_MAX_VAL_LEN) {
+                return false;
+            }
+            break;
+        case TS_MEMBER_END:
+            if (c == ',') {
+                state = TS_MEMBER_START;
+                break;
+            }
+            if (!is_ows(c)) {
+                return false;
+            }
+            break;
+        default:
+            return false;
+        }
+    }
+
+    switch (state) {
+    case TS_MEMBER_START:
+    case TS_MEMBER_END:
+        return members > 0;
+    case TS_VALUE:
+        return !value_blank;
+    default:
+        return false;
+    }
+}
+
+// Reads a tracestate value of len bytes located at the user space address str
+// into ts. Returns 0 if a valid tracestate was read, negative value otherwise.
+// If the tracestate is malformed or longer than TRACESTATE_MAX_LEN, ts is left
+// empty.
+static __always_inline long read_tracestate(void *str, u64 len, struct tracestate *ts) {
+    ts->len = 0;
+    if (str == NULL || len == 0 || len > TRACESTATE_MAX_LEN) {
+        return -1;
+    }
+
+    long res = bpf_probe_read_user(ts->value, len, str);
+    if (res < 0) {
+        return res;
+    }
+    ts->len = len;
+
+    if (!tracestate_valid(ts)) {
+        ts->len = 0;
+        return -1;
+    }
+    return 0;
+}
+
+// Stores ts as the remote tracestate of the trace of sc. If ts is NULL or
+// empty, any tracestate stored for the trace is removed.
+static __always_inline void set_remote_tracestate(struct span_context *sc, struct tracestate *ts) {
+    struct trace_id_key key = {};
+    __builtin_memcpy(key.TraceID, sc->TraceID, TRACE_ID_SIZE);
+    if (ts == NULL || ts->len == 0) {
+        bpf_map_delete_elem(&tracestate_by_trace_id, &key);
+        return;
+    }
+    bpf_map_update_elem(&tracestate_by_trace_id, &key, ts, BPF_ANY);
+}
+
+// Returns the remote tracestate of the trace of sc, or NULL if none is known.
+static __always_inline struct tracestate *get_remote_tracestate(struct span_context *sc) {
+    struct trace_id_key key = {};
+    __builtin_memcpy(key.TraceID, sc->TraceID, TRACE_ID_SIZE);
+    return bpf_map_lookup_elem(&tracestate_by_trace_id, &key);
+}
+
+// Copies the remote tracestate of the trace of sc into ts, if known.
+static __always_inline void copy_remote_tracestate(struct span_context *sc, struct tracestate *ts) {
+    struct tracestate *remote = get_remote_tracestate(sc);
+    if (remote == NULL) {
+        ts->len = 0;
+        return;
+    }
+    __builtin_memcpy(ts, remote, sizeof(struct tracestate));
+}
+
+#endif
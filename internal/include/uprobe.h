@@ -10,6 +10,22 @@
 #include "go_types.h"
 #include "trace/span_output.h"
 
+// Injected in init: true if the kernel provides bpf_ktime_get_boot_ns.
+volatile const bool boot_clock_supported;
+
+// get_time_ns returns the current kernel time used for span timestamps.
+//
+// CLOCK_BOOTTIME (bpf_ktime_get_boot_ns) is used when supported by the
+// kernel as it continues to advance while the system is suspended. Otherwise,
+// CLOCK_MONOTONIC (bpf_ktime_get_ns) is used. The user space conversion of
+// these timestamps uses the same clock.
+static __always_inline u64 get_time_ns() {
+    if (boot_clock_supported) {
+        return bpf_ktime_get_boot_ns();
+    }
+    return bpf_ktime_get_ns();
+}
+
 #define BASE_SPAN_PROPERTIES \
     u64 start_time;          \
     u64 end_time;            \
@@ -32,7 +48,7 @@ int uprobe_##name##_Returns(struct pt_regs *ctx) {
         bpf_printk("event is NULL in ret probe");                                                                   \
         return 0;                                                                                                   \
     }                                                                                                               \
-    event->end_time = bpf_ktime_get_ns();                                                                           \
+    event->end_time = get_time_ns();                                                                                \
     output_span_event(ctx, event, sizeof(event_type), &event->sc);                                                  \
     stop_tracking_span(&event->sc, &event->psc);                                                                    \
     bpf_map_delete_elem(&uprobe_context_map, &key);                                                                 \
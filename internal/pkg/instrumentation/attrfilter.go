@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+// AttributeFilter filters the attributes of the spans of a probe.
+//
+// Keys are matched against glob patterns with the syntax of [path.Match]
+// (e.g. "http.*" or "db.query.text").
+type AttributeFilter struct {
+	// Allow are the patterns of the keys of the attributes kept. All
+	// attributes are kept if empty.
+	Allow []string
+	// Deny are the patterns of the keys of the attributes removed. It takes
+	// precedence over Allow.
+	Deny []string
+}
+
+// Validate returns an error if a pattern of f is malformed.
+func (f AttributeFilter) Validate() error {
+	for _, p := range slices.Concat(f.Allow, f.Deny) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid attribute pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// removes returns if f removes the attribute with key.
+func (f AttributeFilter) removes(key string) bool {
+	match := func(patterns []string) bool {
+		for _, p := range patterns {
+			// Patterns are validated.
+			if ok, _ := path.Match(p, key); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if match(f.Deny) {
+		return true
+	}
+	return len(f.Allow) > 0 && !match(f.Allow)
+}
+
+// WithAttributeFilter returns a copy of h that removes the span attributes
+// filtered by filters, by probe ID (e.g. "database/sql/client"), before
+// passing the spans to the TraceHandler of h. Each removed attribute is
+// counted.
+//
+// A warning is logged for each attribute required by the semantic
+// conventions of the spans of a probe that its filter removes. The attribute
+// is removed nonetheless.
+//
+// If h does not have a TraceHandler, or filters is empty, h is returned.
+func WithAttributeFilter(l *slog.Logger, h *pipeline.Handler, filters map[string]AttributeFilter) *pipeline.Handler {
+	if h == nil || h.TraceHandler == nil || len(filters) == 0 {
+		return h
+	}
+
+	byScope := make(map[string]AttributeFilter, len(filters))
+	for id, f := range filters {
+		scope := "go.opentelemetry.io/auto/" + id
+		byScope[scope] = f
+		for _, c := range semconvClasses {
+			if c.scope != scope {
+				continue
+			}
+			for _, a := range c.attrs {
+				if a.required && f.removes(a.key) {
+					l.Warn(
+						"attribute filter removes an attribute required by the semantic conventions",
+						"probe", id,
+						"key", a.key,
+						"class", c.name,
+					)
+				}
+			}
+		}
+	}
+
+	return &pipeline.Handler{
+		TraceHandler: &attrFilter{
+			next:    h.TraceHandler,
+			filters: byScope,
+			removed: newCounter(
+				h,
+				"otel.auto.span.attributes_filtered",
+				"{attribute}",
+				"Number of span attributes removed by attribute filters before export.",
+			),
+		},
+		MetricHandler: h.MetricHandler,
+		LogHandler:    h.LogHandler,
+	}
+}
+
+// attrFilter is a [pipeline.TraceHandler] that removes the filtered
+// attributes of spans before they are passed to the next handler.
+type attrFilter struct {
+	next pipeline.TraceHandler
+	// filters are the attribute filters by instrumentation scope name.
+	filters map[string]AttributeFilter
+	removed *counter
+}
+
+var _ pipeline.TraceHandler = (*attrFilter)(nil)
+
+func (a *attrFilter) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	if f, ok := a.filters[scope.Name()]; ok {
+		removed := make(map[string]int64)
+		for i := range spans.Len() {
+			spans.At(i).Attributes().RemoveIf(func(key string, _ pcommon.Value) bool {
+				if f.removes(key) {
+					removed[key]++
+					return true
+				}
+				return false
+			})
+		}
+
+		for key, n := range removed {
+			a.removed.Add(n, scopeNameKey.String(scope.Name()), attrKeyKey.String(key))
+		}
+	}
+	a.next.HandleTrace(scope, url, spans)
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+func TestAttributeFilterRemoves(t *testing.T) {
+	f := AttributeFilter{Allow: []string{"http.*", "url.path"}, Deny: []string{"http.request.header.*"}}
+	assert.False(t, f.removes("http.request.method"))
+	assert.False(t, f.removes("url.path"))
+	assert.True(t, f.removes("http.request.header.user-agent"))
+	assert.True(t, f.removes("server.address"))
+
+	f = AttributeFilter{Deny: []string{"db.query.text"}}
+	assert.True(t, f.removes("db.query.text"))
+	assert.False(t, f.removes("db.system.name"))
+
+	assert.False(t, AttributeFilter{}.removes("db.query.text"))
+}
+
+func TestAttributeFilterValidate(t *testing.T) {
+	assert.NoError(t, AttributeFilter{Allow: []string{"http.*"}, Deny: []string{"db.?"}}.Validate())
+	assert.ErrorContains(t, AttributeFilter{Deny: []string{"http.["}}.Validate(), `invalid attribute pattern "http.["`)
+}
+
+func TestWithAttributeFilter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	rec := new(recordingTraceHandler)
+	h := WithAttributeFilter(logger, &pipeline.Handler{TraceHandler: rec}, map[string]AttributeFilter{
+		"net/http/server":     {Allow: []string{"http.request.method", "http.route", "http.response.status_code"}},
+		"database/sql/client": {Deny: []string{"db.query.text"}},
+	})
+	// url.path is required for HTTP server spans.
+	assert.Equal(t, 1, strings.Count(buf.String(), "attribute filter removes an attribute required by the semantic conventions"))
+	assert.Contains(t, buf.String(), "key=url.path")
+
+	handle := func(scopeName string) pcommon.Map {
+		scope := pcommon.NewInstrumentationScope()
+		scope.SetName(scopeName)
+		spans := ptrace.NewSpanSlice()
+		s := newValidSpan(spans, "span")
+		s.Attributes().PutStr("http.request.method", "GET")
+		s.Attributes().PutStr("url.path", "/")
+		s.Attributes().PutStr("db.query.text", "SELECT 1")
+		h.TraceHandler.HandleTrace(scope, "", spans)
+		require.NotEmpty(t, rec.spans)
+		return rec.spans[len(rec.spans)-1].At(0).Attributes()
+	}
+
+	assert.Equal(t, map[string]any{
+		"http.request.method": "GET",
+	}, handle("go.opentelemetry.io/auto/net/http/server").AsRaw())
+	assert.Equal(t, map[string]any{
+		"http.request.method": "GET",
+		"url.path":            "/",
+	}, handle("go.opentelemetry.io/auto/database/sql/client").AsRaw())
+	// Spans of other probes are not filtered.
+	assert.Equal(t, 3, handle("go.opentelemetry.io/auto/net/http/client").Len())
+}
+
+func TestWithAttributeFilterNoFilter(t *testing.T) {
+	h := &pipeline.Handler{TraceHandler: new(recordingTraceHandler)}
+	assert.Same(t, h, WithAttributeFilter(slog.Default(), h, nil))
+}
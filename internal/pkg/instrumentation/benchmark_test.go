@@ -1,4 +1,4 @@
-//go:build !ebpf_test
+//go:build linux && !ebpf_test
 
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// Message IDs are numeric strings assigned by the server.
+#define MAX_MESSAGE_ID_SIZE 32
+// Ordering keys are truncated.
+#define MAX_ORDERING_KEY_SIZE 128
+// The attribute the traceparent is propagated in by the publishers.
+#define TRACEPARENT_ATTRIBUTE "googclient_traceparent"
+#define TRACEPARENT_ATTRIBUTE_LENGTH (sizeof(TRACEPARENT_ATTRIBUTE) - 1)
+// The max number of buckets of an attributes map searched for the traceparent
+// attribute, we must have a limit for the verifier. Overflow buckets are not
+// searched.
+#define MAX_BUCKETS 8
+// Top hash values of the empty, or evacuated, slots of a bucket.
+// https://github.com/golang/go/blob/go1.19/src/runtime/map.go#L91-L96
+#define MIN_TOP_HASH 5
+// The number of slots of a swiss map group.
+// https://github.com/golang/go/blob/go1.24.0/src/internal/runtime/maps/group.go
+#define SWISS_GROUP_SLOTS 8
+// The control byte of a slot holding an entry has its high bit clear.
+#define SWISS_CTRL_EMPTY_MASK 0x80
+// The offsets of the directory pointer, and length, of a swiss map.
+// https://github.com/golang/go/blob/go1.24.0/src/internal/runtime/maps/map.go#L194-L246
+#define SWISS_MAP_DIR_PTR_POS 16
+#define SWISS_MAP_DIR_LEN_POS 24
+
+struct pubsub_message_t {
+    BASE_SPAN_PROPERTIES
+    char message_id[MAX_MESSAGE_ID_SIZE];
+    char ordering_key[MAX_ORDERING_KEY_SIZE];
+    u64 body_size;
+};
+
+// The attributes of a message are a map[string]string.
+MAP_BUCKET_DEFINITION(go_string_t, go_string_t)
+
+struct swiss_slot_t {
+    go_string_t key;
+    go_string_t elem;
+};
+
+struct swiss_group_t {
+    u64 ctrl;
+    struct swiss_slot_t slots[SWISS_GROUP_SLOTS];
+};
+
+// Messages being dispatched, keyed by the goroutine dispatching them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct pubsub_message_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} pubsub_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct pubsub_message_t));
+    __uint(max_entries, 1);
+} pubsub_storage_map SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(MAP_BUCKET_TYPE(go_string_t, go_string_t)));
+    __uint(max_entries, 1);
+} golang_mapbucket_storage_map SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct swiss_group_t));
+    __uint(max_entries, 1);
+} swiss_group_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 message_id_pos;
+volatile const u64 message_data_pos;
+volatile const u64 message_attributes_pos;
+volatile const u64 message_ordering_key_pos;
+volatile const u64 buckets_ptr_pos;
+// A flag indicating whether the Go version is using swiss maps
+volatile const bool swiss_maps_used;
+
+// Parses the span context from the value of the traceparent attribute.
+static __always_inline long parse_traceparent_value(go_string_t *value, struct span_context *parent_span_context) {
+    if (value->len != W3C_VAL_LENGTH) {
+        return -1;
+    }
+    char val[W3C_VAL_LENGTH];
+    if (bpf_probe_read_user(val, sizeof(val), value->str)) {
+        return -1;
+    }
+    w3c_string_to_span_context(val, parent_span_context);
+    return 0;
+}
+
+static __always_inline bool is_traceparent_key(go_string_t *key) {
+    if (key->len != TRACEPARENT_ATTRIBUTE_LENGTH) {
+        return false;
+    }
+    char name[TRACEPARENT_ATTRIBUTE_LENGTH];
+    if (bpf_probe_read_user(name, sizeof(name), key->str)) {
+        return false;
+    }
+    char traceparent[TRACEPARENT_ATTRIBUTE_LENGTH] = TRACEPARENT_ATTRIBUTE;
+    return bpf_memcmp(name, traceparent, sizeof(traceparent));
+}
+
+/* Searches the buckets of a map, used by Go versions prior to 1.24, for the
+traceparent attribute. Only the first MAX_BUCKETS buckets are searched, the
+attribute is not found in larger maps, or in overflow buckets. */
+static __always_inline long extract_span_context_from_buckets(void *attributes, struct span_context *parent_span_context) {
+    unsigned char log_2_bucket_count;
+    if (bpf_probe_read_user(&log_2_bucket_count, sizeof(log_2_bucket_count), attributes + 9)) {
+        return -1;
+    }
+    u64 bucket_count = 1 << log_2_bucket_count;
+    void *buckets;
+    if (bpf_probe_read_user(&buckets, sizeof(buckets), (void *)(attributes + buckets_ptr_pos))) {
+        return -1;
+    }
+    u32 map_id = 0;
+    MAP_BUCKET_TYPE(go_string_t, go_string_t) *bucket = bpf_map_lookup_elem(&golang_mapbucket_storage_map, &map_id);
+    if (!bucket) {
+        return -1;
+    }
+
+    for (u64 j = 0; j < MAX_BUCKETS; j++) {
+        if (j >= bucket_count) {
+            break;
+        }
+        if (bpf_probe_read_user(bucket, sizeof(*bucket), buckets + (j * sizeof(*bucket)))) {
+            continue;
+        }
+        for (u64 i = 0; i < 8; i++) {
+            if (bucket->tophash[i] < MIN_TOP_HASH) {
+                continue;
+            }
+            if (is_traceparent_key(&bucket->keys[i])) {
+                return parse_traceparent_value(&bucket->values[i], parent_span_context);
+            }
+        }
+    }
+    return -1;
+}
+
+/* Searches a swiss map, used since Go 1.24, for the traceparent attribute.
+Only small maps, holding up to SWISS_GROUP_SLOTS entries in a single group,
+are searched. */
+static __always_inline long extract_span_context_from_swiss_map(void *attributes, struct span_context *parent_span_context) {
+    s64 dir_len = 0;
+    if (bpf_probe_read_user(&dir_len, sizeof(dir_len), attributes + SWISS_MAP_DIR_LEN_POS) || dir_len != 0) {
+        return -1;
+    }
+    void *group_ptr = NULL;
+    if (bpf_probe_read_user(&group_ptr, sizeof(group_ptr), attributes + SWISS_MAP_DIR_PTR_POS) || group_ptr == NULL) {
+        return -1;
+    }
+    u32 map_id = 0;
+    struct swiss_group_t *group = bpf_map_lookup_elem(&swiss_group_storage_map, &map_id);
+    if (!group) {
+        return -1;
+    }
+    if (bpf_probe_read_user(group, sizeof(*group), group_ptr)) {
+        return -1;
+    }
+
+    for (u64 i = 0; i < SWISS_GROUP_SLOTS; i++) {
+        u8 ctrl = group->ctrl >> (8 * i);
+        if (ctrl & SWISS_CTRL_EMPTY_MASK) {
+            continue;
+        }
+        if (is_traceparent_key(&group->slots[i].key)) {
+            return parse_traceparent_value(&group->slots[i].elem, parent_span_context);
+        }
+    }
+    return -1;
+}
+
+// Extracts the span context from the traceparent attribute of a message,
+// propagated by the publishers with tracing enabled.
+static __always_inline long extract_span_context_from_attributes(void *message, struct span_context *parent_span_context) {
+    void *attributes = NULL;
+    if (bpf_probe_read_user(&attributes, sizeof(attributes), (void *)(message + message_attributes_pos)) || attributes == NULL) {
+        return -1;
+    }
+    u64 count = 0;
+    if (bpf_probe_read_user(&count, sizeof(count), attributes) || count == 0) {
+        return -1;
+    }
+    if (swiss_maps_used) {
+        return extract_span_context_from_swiss_map(attributes, parent_span_context);
+    }
+    return extract_span_context_from_buckets(attributes, parent_span_context);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *ReceiveScheduler) Add(key string, item interface{}, handle func(item interface{})) error
+SEC("uprobe/ReceiveScheduler_Add")
+int uprobe_ReceiveScheduler_Add(struct pt_regs *ctx) {
+    /* Messages received by a subscription are dispatched to the callback
+    passed to Receive by the scheduler, it calls the callback from a worker
+    goroutine. The callback accepts a context.Context derived from the one
+    passed to Receive, the parent span is only the one of the publisher
+    propagated in the attributes of the message. */
+    void *key = (void *)GOROUTINE(ctx);
+    // The item is an interface{} holding a *Message.
+    void *message = get_argument(ctx, 5);
+    if (message == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct pubsub_message_t *pubsub_msg = bpf_map_lookup_elem(&pubsub_storage_map, &zero);
+    if (pubsub_msg == NULL) {
+        bpf_printk("uprobe/ReceiveScheduler_Add: pubsub_msg is NULL");
+        return 0;
+    }
+    __builtin_memset(pubsub_msg, 0, sizeof(struct pubsub_message_t));
+    pubsub_msg->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr((void *)(message + message_id_pos), pubsub_msg->message_id, sizeof(pubsub_msg->message_id));
+    get_go_string_from_user_ptr((void *)(message + message_ordering_key_pos), pubsub_msg->ordering_key, sizeof(pubsub_msg->ordering_key));
+    bpf_probe_read_user(&pubsub_msg->body_size, sizeof(pubsub_msg->body_size), (void *)(message + message_data_pos + offsetof(struct go_slice, len)));
+
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &pubsub_msg->psc,
+        .sc = &pubsub_msg->sc,
+        .get_parent_span_context_fn = extract_span_context_from_attributes,
+        .get_parent_span_context_arg = message,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&pubsub_events, &key, pubsub_msg, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *ReceiveScheduler) Add(key string, item interface{}, handle func(item interface{})) error
+SEC("uprobe/ReceiveScheduler_Add")
+int uprobe_ReceiveScheduler_Add_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct pubsub_message_t *pubsub_msg = bpf_map_lookup_elem(&pubsub_events, &key);
+    if (pubsub_msg == NULL) {
+        return 0;
+    }
+    pubsub_msg->end_time = end_time;
+
+    output_span_event(ctx, pubsub_msg, sizeof(*pubsub_msg), &pubsub_msg->sc);
+    bpf_map_delete_elem(&pubsub_events, &key);
+    return 0;
+}
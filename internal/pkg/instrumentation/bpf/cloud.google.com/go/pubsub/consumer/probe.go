@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consumer provides an instrumentation probe for Google Cloud Pub/Sub
+// subscribers using the [cloud.google.com/go/pubsub] package.
+package consumer
+
+import (
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "cloud.google.com/go/pubsub"
+	// internalMod is the module of the messages used by the package.
+	internalMod = "cloud.google.com/go"
+	// internalPkg is the package of the messages.
+	internalPkg = internalMod + "/internal/pubsub"
+)
+
+var (
+	// minVersion is the first version supported by the probe. It is the
+	// first version with messages defined in [internalPkg].
+	minVersion = semver.New(1, 9, 1, "", "")
+	// goMapsVersion is the first Go version using swiss maps.
+	goMapsVersion = semver.New(1, 24, 0, "", "")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	// The scheduler dispatching the messages is only known for versions
+	// matching the constraint. The uprobe is skipped for other versions.
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, field string) probe.Const {
+		return probe.StructFieldConst{
+			Key: key,
+			ID:  structfield.NewID(internalMod, internalPkg, "Message", field),
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("message_id_pos", "ID"),
+				fieldConst("message_data_pos", "Data"),
+				fieldConst("message_attributes_pos", "Attributes"),
+				fieldConst("message_ordering_key_pos", "OrderingKey"),
+				probe.StructFieldConstMaxVersion{
+					StructField: probe.StructFieldConst{
+						Key: "buckets_ptr_pos",
+						ID:  structfield.NewID("std", "runtime", "hmap", "buckets"),
+					},
+					MaxVersion: goMapsVersion,
+				},
+				swissMapsUsedConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + "/internal/scheduler.(*ReceiveScheduler).Add",
+					EntryProbe:         "uprobe_ReceiveScheduler_Add",
+					ReturnProbe:        "uprobe_ReceiveScheduler_Add_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+type swissMapsUsedConst struct{}
+
+func (c swissMapsUsedConst) InjectOption(info *process.Info) (inject.Option, error) {
+	isUsingGoSwissMaps := info.GoVersion.GreaterThanEqual(goMapsVersion)
+	return inject.WithKeyValue("swiss_maps_used", isUsingGoSwissMaps), nil
+}
+
+// event represents a message dispatched to the callback of a subscriber.
+type event struct {
+	context.BaseSpanProperties
+	MessageID   [32]byte
+	OrderingKey [128]byte
+	// BodySize is the size of the data of the message.
+	BodySize uint64
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemGCPPubsub,
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingOperationName("receive"),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max message size.
+	}
+	if id := unix.ByteSliceToString(e.MessageID[:]); id != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(id))
+	}
+	if key := unix.ByteSliceToString(e.OrderingKey[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingGCPPubsubMessageOrderingKey(key))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("receive")
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
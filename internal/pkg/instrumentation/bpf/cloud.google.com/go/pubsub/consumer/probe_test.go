@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(id, orderingKey string, bodySize uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           bodySize,
+		}
+		copy(e.MessageID[:], id)
+		copy(e.OrderingKey[:], orderingKey)
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "ordering key",
+			event: newEvent("4242", "customer-1", 42),
+			want: f.Spans(
+				"receive",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemGCPPubsub,
+				semconv.MessagingOperationTypeReceive,
+				semconv.MessagingOperationName("receive"),
+				semconv.MessagingMessageBodySize(42),
+				semconv.MessagingMessageID("4242"),
+				semconv.MessagingGCPPubsubMessageOrderingKey("customer-1"),
+			),
+		},
+		{
+			name:  "no ordering key",
+			event: newEvent("4242", "", 8),
+			want: f.Spans(
+				"receive",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemGCPPubsub,
+				semconv.MessagingOperationTypeReceive,
+				semconv.MessagingOperationName("receive"),
+				semconv.MessagingMessageBodySize(8),
+				semconv.MessagingMessageID("4242"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestSwissMapsUsedConst(t *testing.T) {
+	for _, tt := range []struct {
+		goVersion string
+		want      bool
+	}{
+		{goVersion: "1.23.8", want: false},
+		{goVersion: "1.24.0", want: true},
+		{goVersion: "1.25.1", want: true},
+	} {
+		t.Run(tt.goVersion, func(t *testing.T) {
+			info := &process.Info{GoVersion: semver.MustParse(tt.goVersion)}
+			opt, err := swissMapsUsedConst{}.InjectOption(info)
+			require.NoError(t, err)
+			assert.Equal(t, inject.WithKeyValue("swiss_maps_used", tt.want), opt)
+		})
+	}
+}
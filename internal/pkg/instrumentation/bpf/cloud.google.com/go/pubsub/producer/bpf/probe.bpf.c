@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// The max number of messages published and waiting for their bundle to be
+// sent. The least recently published are evicted.
+#define MAX_PENDING 1000
+// Topic names are "projects/<project>/topics/<topic>", topic IDs are at most
+// 255 bytes.
+#define MAX_TOPIC_SIZE 320
+// Ordering keys are truncated.
+#define MAX_ORDERING_KEY_SIZE 128
+// The max number of messages of a bundle resolved, we must have a limit for
+// the verifier. It is the default count threshold of the publish settings.
+#define MAX_BUNDLE_SIZE 100
+
+struct pubsub_message_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    char ordering_key[MAX_ORDERING_KEY_SIZE];
+    u64 body_size;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// A []*bundledMessage being sent.
+struct pubsub_bundle_t {
+    void *messages;
+    u64 len;
+};
+
+// Messages being published, keyed by the goroutine publishing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct pubsub_message_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} pubsub_events SEC(".maps");
+
+// Messages published and waiting for their bundle to be sent, keyed by their
+// *PublishResult.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct pubsub_message_t);
+    __uint(max_entries, MAX_PENDING);
+} pubsub_pending SEC(".maps");
+
+// Bundles being sent, keyed by the goroutine sending them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct pubsub_bundle_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} pubsub_bundles SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct pubsub_message_t));
+    __uint(max_entries, 1);
+} pubsub_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 topic_name_pos;
+volatile const u64 message_data_pos;
+volatile const u64 message_ordering_key_pos;
+volatile const u64 bundled_message_res_pos;
+volatile const u64 publish_result_err_pos;
+
+// Returns true if the error of the resolved PublishResult res is not nil.
+static __always_inline bool publish_failed(void *res) {
+    struct go_iface err = {0};
+    if (bpf_probe_read_user(&err, sizeof(err), res + publish_result_err_pos) != 0) {
+        return false;
+    }
+    return err.type != NULL;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (t *Topic) Publish(ctx context.Context, msg *Message) *PublishResult
+SEC("uprobe/Topic_Publish")
+int uprobe_Topic_Publish(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&pubsub_events, &key) != NULL) {
+        return 0;
+    }
+
+    void *topic = get_argument(ctx, 1);
+    void *msg = get_argument(ctx, 4);
+    if (topic == NULL || msg == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct pubsub_message_t *pubsub_msg = bpf_map_lookup_elem(&pubsub_storage_map, &zero);
+    if (pubsub_msg == NULL) {
+        bpf_printk("uprobe/Topic_Publish: pubsub_msg is NULL");
+        return 0;
+    }
+    __builtin_memset(pubsub_msg, 0, sizeof(struct pubsub_message_t));
+    pubsub_msg->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr(topic + topic_name_pos, pubsub_msg->topic, sizeof(pubsub_msg->topic));
+    get_go_string_from_user_ptr(msg + message_ordering_key_pos, pubsub_msg->ordering_key, sizeof(pubsub_msg->ordering_key));
+    bpf_probe_read_user(&pubsub_msg->body_size, sizeof(pubsub_msg->body_size), msg + message_data_pos + offsetof(struct go_slice, len));
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &pubsub_msg->psc,
+        .sc = &pubsub_msg->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&pubsub_events, &key, pubsub_msg, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (t *Topic) Publish(ctx context.Context, msg *Message) *PublishResult
+SEC("uprobe/Topic_Publish")
+int uprobe_Topic_Publish_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct pubsub_message_t *pubsub_msg = bpf_map_lookup_elem(&pubsub_events, &key);
+    if (pubsub_msg == NULL) {
+        return 0;
+    }
+
+    void *res = get_argument(ctx, 1);
+    if (res != NULL) {
+        if (publish_failed(res)) {
+            // The message was not added to a bundle, the result is resolved.
+            pubsub_msg->end_time = end_time;
+            pubsub_msg->has_error = 1;
+            output_span_event(ctx, pubsub_msg, sizeof(*pubsub_msg), &pubsub_msg->sc);
+        } else {
+            // The span ends when the bundle of the message is sent.
+            bpf_map_update_elem(&pubsub_pending, &res, pubsub_msg, BPF_ANY);
+        }
+    }
+
+    bpf_map_delete_elem(&pubsub_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (t *Topic) publishMessageBundle(ctx context.Context, bms []*bundledMessage)
+SEC("uprobe/Topic_publishMessageBundle")
+int uprobe_Topic_publishMessageBundle(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct pubsub_bundle_t bundle = {
+        .messages = get_argument(ctx, 4),
+        .len = (u64)get_argument(ctx, 5),
+    };
+    if (bundle.messages == NULL || bundle.len == 0) {
+        return 0;
+    }
+    bpf_map_update_elem(&pubsub_bundles, &key, &bundle, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (t *Topic) publishMessageBundle(ctx context.Context, bms []*bundledMessage)
+SEC("uprobe/Topic_publishMessageBundle")
+int uprobe_Topic_publishMessageBundle_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct pubsub_bundle_t *bundle = bpf_map_lookup_elem(&pubsub_bundles, &key);
+    if (bundle == NULL) {
+        return 0;
+    }
+
+    /* The results of the messages of the bundle are resolved when it is sent.
+    The messages are released before, they are found by their result. */
+    for (u64 i = 0; i < MAX_BUNDLE_SIZE; i++) {
+        if (i >= bundle->len) {
+            break;
+        }
+        void *bm = NULL;
+        if (bpf_probe_read_user(&bm, sizeof(bm), bundle->messages + (i * sizeof(bm))) != 0 || bm == NULL) {
+            continue;
+        }
+        void *res = NULL;
+        if (bpf_probe_read_user(&res, sizeof(res), bm + bundled_message_res_pos) != 0 || res == NULL) {
+            continue;
+        }
+        struct pubsub_message_t *pubsub_msg = bpf_map_lookup_elem(&pubsub_pending, &res);
+        if (pubsub_msg == NULL) {
+            continue;
+        }
+        pubsub_msg->end_time = end_time;
+        pubsub_msg->has_error = publish_failed(res);
+        output_span_event(ctx, pubsub_msg, sizeof(*pubsub_msg), &pubsub_msg->sc);
+        bpf_map_delete_elem(&pubsub_pending, &res);
+    }
+
+    bpf_map_delete_elem(&pubsub_bundles, &key);
+    return 0;
+}
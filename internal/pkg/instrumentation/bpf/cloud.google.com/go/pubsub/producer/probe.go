@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides an instrumentation probe for Google Cloud Pub/Sub
+// publishers using the [cloud.google.com/go/pubsub] package.
+package producer
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "cloud.google.com/go/pubsub"
+	// internalMod is the module of the messages and publish results used by
+	// the package.
+	internalMod = "cloud.google.com/go"
+	// internalPkg is the package of the messages and publish results.
+	internalPkg = internalMod + "/internal/pubsub"
+)
+
+// minVersion is the first version supported by the probe. It is the first
+// version with messages and publish results defined in [internalPkg].
+var minVersion = semver.New(1, 9, 1, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	// The layout of the bundles of messages is only known for versions
+	// matching the constraint. The uprobes are skipped for other versions.
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "topic_name_pos",
+						ID:  structfield.NewID(pkg, pkg, "Topic", "name"),
+					},
+					MinVersion: minVersion,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "bundled_message_res_pos",
+						ID:  structfield.NewID(pkg, pkg, "bundledMessage", "res"),
+					},
+					MinVersion: minVersion,
+				},
+				probe.StructFieldConst{
+					Key: "message_data_pos",
+					ID:  structfield.NewID(internalMod, internalPkg, "Message", "Data"),
+				},
+				probe.StructFieldConst{
+					Key: "message_ordering_key_pos",
+					ID:  structfield.NewID(internalMod, internalPkg, "Message", "OrderingKey"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_result_err_pos",
+					ID:  structfield.NewID(internalMod, internalPkg, "PublishResult", "err"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*Topic).Publish",
+					EntryProbe:         "uprobe_Topic_Publish",
+					ReturnProbe:        "uprobe_Topic_Publish_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*Topic).publishMessageBundle",
+					EntryProbe:         "uprobe_Topic_publishMessageBundle",
+					ReturnProbe:        "uprobe_Topic_publishMessageBundle_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a message published by the client. The span ends when the
+// bundle of the message is sent and its result resolved.
+type event struct {
+	context.BaseSpanProperties
+	Topic       [320]byte
+	OrderingKey [128]byte
+	// BodySize is the size of the data of the message.
+	BodySize uint64
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := topicID(unix.ByteSliceToString(e.Topic[:]))
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemGCPPubsub,
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max message size.
+	}
+	if key := unix.ByteSliceToString(e.OrderingKey[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingGCPPubsubMessageOrderingKey(key))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(topic))
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// topicID returns the ID of the topic of the fully qualified name, e.g.
+// "orders" for "projects/shop/topics/orders". The name is returned as is if it
+// is not fully qualified.
+func topicID(name string) string {
+	if i := strings.LastIndex(name, "/topics/"); i >= 0 {
+		return name[i+len("/topics/"):]
+	}
+	return name
+}
+
+// spanName returns the name of the span of a message published to topic, e.g.
+// "orders publish".
+func spanName(topic string) string {
+	if topic == "" {
+		return "publish"
+	}
+	return topic + " publish"
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindProducer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(topic, orderingKey string, bodySize uint64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           bodySize,
+		}
+		copy(e.Topic[:], topic)
+		copy(e.OrderingKey[:], orderingKey)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "ordering key",
+			event: newEvent("projects/shop/topics/orders", "customer-1", 42, false),
+			want: f.Spans(
+				"orders publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemGCPPubsub,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName("orders"),
+				semconv.MessagingMessageBodySize(42),
+				semconv.MessagingGCPPubsubMessageOrderingKey("customer-1"),
+			),
+		},
+		{
+			name:  "no ordering key",
+			event: newEvent("projects/shop/topics/events", "", 0, false),
+			want: f.Spans(
+				"events publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemGCPPubsub,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName("events"),
+				semconv.MessagingMessageBodySize(0),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("projects/shop/topics/orders", "", 8, true),
+			want: f.Spans(
+				"orders publish",
+				ptrace.StatusCodeError,
+				semconv.MessagingSystemGCPPubsub,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName("orders"),
+				semconv.MessagingMessageBodySize(8),
+			),
+		},
+		{
+			name:  "no topic",
+			event: newEvent("", "", 8, false),
+			want: f.Spans(
+				"publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemGCPPubsub,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName(""),
+				semconv.MessagingMessageBodySize(8),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestTopicID(t *testing.T) {
+	assert.Equal(t, "orders", topicID("projects/shop/topics/orders"))
+	assert.Equal(t, "orders", topicID("orders"))
+	assert.Equal(t, "", topicID(""))
+}
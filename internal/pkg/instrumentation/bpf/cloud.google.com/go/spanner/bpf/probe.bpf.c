@@ -0,0 +1,473 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "grpc_client.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_QUERY_SIZE 256
+#define MAX_DATABASE_SIZE 128
+#define MAX_CONCURRENT 50
+#define MAX_CLIENTS 1024
+#define MAX_TRANSACTIONS 1024
+#define MAX_ITERATORS 1024
+
+// The operations of the Spanner client, they need to be kept in sync with the
+// operations of the probe.
+#define OPERATION_QUERY 1
+#define OPERATION_READ_WRITE_TRANSACTION 2
+#define OPERATION_APPLY 3
+
+struct spanner_request_t {
+    BASE_SPAN_PROPERTIES
+    // The SQL text of a query, if configured to be included.
+    char query[MAX_QUERY_SIZE];
+    // The database of the client, e.g.
+    // "projects/p/instances/i/databases/d".
+    char database[MAX_DATABASE_SIZE];
+    // The number of mutations applied by Apply.
+    u64 mutations;
+    // The number of times the function of a transaction is run, more than
+    // once when the transaction is retried after being aborted.
+    u64 attempts;
+    u8 operation;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// The state of the operations being run, only the event is sent to user
+// space.
+struct spanner_request_state_t {
+    struct spanner_request_t event;
+    // The number of nested calls of the instrumented functions: Apply may
+    // call ReadWriteTransaction, Query may call QueryWithOptions.
+    u64 depth;
+};
+
+// The transactions and Apply calls being run, keyed by their goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct spanner_request_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} spanner_transactions SEC(".maps");
+
+// The queries being started, keyed by their goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct spanner_request_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} spanner_pending_queries SEC(".maps");
+
+// The queries started, keyed by their *RowIterator. Iterators that are never
+// stopped are evicted.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct spanner_request_t);
+    __uint(max_entries, MAX_ITERATORS);
+} spanner_queries SEC(".maps");
+
+// The *RowIterator of the queries being iterated, keyed by the goroutine
+// calling Next.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} spanner_iterators SEC(".maps");
+
+// The databases of the clients being created, keyed by their goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, char[MAX_DATABASE_SIZE]);
+    __uint(max_entries, MAX_CONCURRENT);
+} spanner_new_clients SEC(".maps");
+
+// The databases of the clients, keyed by their *Client.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, char[MAX_DATABASE_SIZE]);
+    __uint(max_entries, MAX_CLIENTS);
+} spanner_client_databases SEC(".maps");
+
+// The *Client of the read-only transactions being created, keyed by their
+// goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} spanner_new_read_only_transactions SEC(".maps");
+
+// The databases of the read-only transactions, keyed by their *txReadOnly.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, char[MAX_DATABASE_SIZE]);
+    __uint(max_entries, MAX_TRANSACTIONS);
+} spanner_transaction_databases SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct spanner_request_state_t));
+    __uint(max_entries, 1);
+} spanner_storage_map SEC(".maps");
+
+// Injected in init
+volatile const bool should_include_db_statement;
+volatile const u64 read_only_transaction_tx_read_only_pos;
+// The address of the google.golang.org/api/iterator.Done error, zero if it
+// is not known: the errors of the queries are then not recorded.
+volatile const u64 iterator_done_addr;
+
+// Returns a zeroed request state from the per-CPU storage, with the start
+// time and operation of its event set.
+static __always_inline struct spanner_request_state_t *new_request_state(u8 operation) {
+    u32 zero = 0;
+    struct spanner_request_state_t *state = bpf_map_lookup_elem(&spanner_storage_map, &zero);
+    if (state == NULL) {
+        return NULL;
+    }
+    __builtin_memset(state, 0, sizeof(struct spanner_request_state_t));
+    state->event.start_time = get_time_ns();
+    state->event.operation = operation;
+    return state;
+}
+
+// Starts the span of req, child of the span of the context in the
+// context_pos argument.
+static __always_inline void start_spanner_span(struct pt_regs *ctx, u64 context_pos, struct spanner_request_t *req) {
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, context_pos, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+}
+
+// Starts the span of the transaction of the operation run by the *Client in
+// the first argument, with the context in the second.
+static __always_inline int start_transaction(struct pt_regs *ctx, u8 operation, u64 mutations) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct spanner_request_state_t *tracked = bpf_map_lookup_elem(&spanner_transactions, &key);
+    if (tracked != NULL) {
+        tracked->depth++;
+        return 0;
+    }
+
+    struct spanner_request_state_t *state = new_request_state(operation);
+    if (state == NULL) {
+        bpf_printk("spanner: state is NULL");
+        return 0;
+    }
+    struct spanner_request_t *req = &state->event;
+    req->mutations = mutations;
+
+    void *client = get_argument(ctx, 1);
+    char *database = bpf_map_lookup_elem(&spanner_client_databases, &client);
+    if (database != NULL) {
+        __builtin_memcpy(req->database, database, sizeof(req->database));
+    }
+
+    start_spanner_span(ctx, 2, req);
+
+    bpf_map_update_elem(&spanner_transactions, &key, state, 0);
+    // The RPCs invoked by the client, e.g. to begin and commit the
+    // transaction, are part of this span.
+    set_grpc_client_owner(key, &req->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) ReadWriteTransaction(ctx context.Context, f func(context.Context, *ReadWriteTransaction) error) (commitTimestamp time.Time, err error)
+SEC("uprobe/Client_ReadWriteTransaction")
+int uprobe_Client_ReadWriteTransaction(struct pt_regs *ctx) {
+    return start_transaction(ctx, OPERATION_READ_WRITE_TRANSACTION, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption) (commitTimestamp time.Time, err error)
+SEC("uprobe/Client_Apply")
+int uprobe_Client_Apply(struct pt_regs *ctx) {
+    u64 mutations_len_pos = 5;
+    return start_transaction(ctx, OPERATION_APPLY, (u64)get_argument(ctx, mutations_len_pos));
+}
+
+// This instrumentation attaches uretprobe to the following functions:
+// func (c *Client) ReadWriteTransaction(ctx context.Context, f func(context.Context, *ReadWriteTransaction) error) (commitTimestamp time.Time, err error)
+// func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption) (commitTimestamp time.Time, err error)
+SEC("uprobe/Client_ReadWriteTransaction")
+int uprobe_Client_ReadWriteTransaction_Returns(struct pt_regs *ctx) {
+    // The returned time.Time is held by the 3 first registers.
+    u64 err_pos = 4;
+
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct spanner_request_state_t *state = bpf_map_lookup_elem(&spanner_transactions, &key);
+    if (state == NULL) {
+        return 0;
+    }
+    if (state->depth > 0) {
+        state->depth--;
+        return 0;
+    }
+    struct spanner_request_t *req = &state->event;
+    req->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, err_pos) != NULL) {
+        req->has_error = 1;
+    }
+
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    stop_tracking_span(&req->sc, &req->psc);
+    delete_grpc_client_owner(key);
+    bpf_map_delete_elem(&spanner_transactions, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (t *ReadWriteTransaction) runInTransaction(ctx context.Context, f func(context.Context, *ReadWriteTransaction) error) (CommitResponse, error)
+SEC("uprobe/ReadWriteTransaction_runInTransaction")
+int uprobe_ReadWriteTransaction_runInTransaction(struct pt_regs *ctx) {
+    // The function is run again each time the transaction is aborted.
+    void *key = (void *)GOROUTINE(ctx);
+    struct spanner_request_state_t *state = bpf_map_lookup_elem(&spanner_transactions, &key);
+    if (state != NULL) {
+        state->event.attempts++;
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (t *txReadOnly) Query(ctx context.Context, statement Statement) *RowIterator
+// func (t *txReadOnly) QueryWithOptions(ctx context.Context, statement Statement, opts QueryOptions) *RowIterator
+// func (t *txReadOnly) QueryWithStats(ctx context.Context, statement Statement) *RowIterator
+SEC("uprobe/txReadOnly_Query")
+int uprobe_txReadOnly_Query(struct pt_regs *ctx) {
+    u64 tx_pos = 1;
+    u64 context_pos = 2;
+    u64 sql_ptr_pos = 4;
+    u64 sql_len_pos = 5;
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct spanner_request_state_t *tracked = bpf_map_lookup_elem(&spanner_pending_queries, &key);
+    if (tracked != NULL) {
+        tracked->depth++;
+        return 0;
+    }
+
+    struct spanner_request_state_t *state = new_request_state(OPERATION_QUERY);
+    if (state == NULL) {
+        bpf_printk("uprobe/txReadOnly_Query: state is NULL");
+        return 0;
+    }
+    struct spanner_request_t *req = &state->event;
+
+    void *sql_ptr = get_argument(ctx, sql_ptr_pos);
+    u64 sql_len = (u64)get_argument(ctx, sql_len_pos);
+    if (should_include_db_statement && sql_ptr != NULL) {
+        u64 size = MAX_QUERY_SIZE < sql_len ? MAX_QUERY_SIZE : sql_len;
+        bpf_probe_read_user(req->query, size, sql_ptr);
+    }
+
+    // The read-only transactions are mapped to the database of their client
+    // when created, the read-write ones are run by the goroutine of their
+    // transaction.
+    void *tx = get_argument(ctx, tx_pos);
+    char *database = bpf_map_lookup_elem(&spanner_transaction_databases, &tx);
+    if (database != NULL) {
+        __builtin_memcpy(req->database, database, sizeof(req->database));
+    } else {
+        struct spanner_request_state_t *txn = bpf_map_lookup_elem(&spanner_transactions, &key);
+        if (txn != NULL) {
+            __builtin_memcpy(req->database, txn->event.database, sizeof(req->database));
+        }
+    }
+
+    start_spanner_span(ctx, context_pos, req);
+
+    bpf_map_update_elem(&spanner_pending_queries, &key, state, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following functions:
+// func (t *txReadOnly) Query(ctx context.Context, statement Statement) *RowIterator
+// func (t *txReadOnly) QueryWithOptions(ctx context.Context, statement Statement, opts QueryOptions) *RowIterator
+// func (t *txReadOnly) QueryWithStats(ctx context.Context, statement Statement) *RowIterator
+SEC("uprobe/txReadOnly_Query")
+int uprobe_txReadOnly_Query_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct spanner_request_state_t *state = bpf_map_lookup_elem(&spanner_pending_queries, &key);
+    if (state == NULL) {
+        return 0;
+    }
+    if (state->depth > 0) {
+        state->depth--;
+        return 0;
+    }
+
+    // The query is run lazily by the iterator: its span ends when the
+    // iterator is stopped.
+    void *iter = get_argument(ctx, 1);
+    if (iter != NULL) {
+        bpf_map_update_elem(&spanner_queries, &iter, &state->event, 0);
+    }
+    bpf_map_delete_elem(&spanner_pending_queries, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *RowIterator) Next() (*Row, error)
+SEC("uprobe/RowIterator_Next")
+int uprobe_RowIterator_Next(struct pt_regs *ctx) {
+    void *iter = get_argument(ctx, 1);
+    if (bpf_map_lookup_elem(&spanner_queries, &iter) == NULL) {
+        return 0;
+    }
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&spanner_iterators, &key, &iter, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (r *RowIterator) Next() (*Row, error)
+SEC("uprobe/RowIterator_Next")
+int uprobe_RowIterator_Next_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void **iter_ptr = bpf_map_lookup_elem(&spanner_iterators, &key);
+    if (iter_ptr == NULL) {
+        return 0;
+    }
+    void *iter = *iter_ptr;
+    bpf_map_delete_elem(&spanner_iterators, &key);
+
+    void *row = get_argument(ctx, 1);
+    void *err_type = get_argument(ctx, 2);
+    void *err_data = get_argument(ctx, 3);
+    if (row != NULL || err_type == NULL || iterator_done_addr == 0) {
+        return 0;
+    }
+
+    // The iterator returns iterator.Done once all the rows are read.
+    struct go_iface done = {0};
+    bpf_probe_read_user(&done, sizeof(done), (void *)iterator_done_addr);
+    if (done.data == err_data) {
+        return 0;
+    }
+    struct spanner_request_t *req = bpf_map_lookup_elem(&spanner_queries, &iter);
+    if (req != NULL) {
+        req->has_error = 1;
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *RowIterator) Stop()
+SEC("uprobe/RowIterator_Stop")
+int uprobe_RowIterator_Stop(struct pt_regs *ctx) {
+    void *iter = get_argument(ctx, 1);
+    struct spanner_request_t *req = bpf_map_lookup_elem(&spanner_queries, &iter);
+    if (req == NULL) {
+        return 0;
+    }
+    req->end_time = get_time_ns();
+
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    stop_tracking_span(&req->sc, &req->psc);
+    bpf_map_delete_elem(&spanner_queries, &iter);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func NewClientWithConfig(ctx context.Context, database string, config ClientConfig, opts ...option.ClientOption) (c *Client, err error)
+SEC("uprobe/NewClientWithConfig")
+int uprobe_NewClientWithConfig(struct pt_regs *ctx) {
+    u64 database_ptr_pos = 3;
+    u64 database_len_pos = 4;
+
+    void *database_ptr = get_argument(ctx, database_ptr_pos);
+    u64 database_len = (u64)get_argument(ctx, database_len_pos);
+    if (database_ptr == NULL || database_len == 0) {
+        return 0;
+    }
+
+    char database[MAX_DATABASE_SIZE] = {0};
+    u64 size = MAX_DATABASE_SIZE - 1 < database_len ? MAX_DATABASE_SIZE - 1 : database_len;
+    bpf_probe_read_user(database, size, database_ptr);
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&spanner_new_clients, &key, database, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func NewClientWithConfig(ctx context.Context, database string, config ClientConfig, opts ...option.ClientOption) (c *Client, err error)
+SEC("uprobe/NewClientWithConfig")
+int uprobe_NewClientWithConfig_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    char *database = bpf_map_lookup_elem(&spanner_new_clients, &key);
+    if (database == NULL) {
+        return 0;
+    }
+    void *client = get_argument(ctx, 1);
+    if (client != NULL) {
+        bpf_map_update_elem(&spanner_client_databases, &client, database, 0);
+    }
+    bpf_map_delete_elem(&spanner_new_clients, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (c *Client) Single() *ReadOnlyTransaction
+// func (c *Client) ReadOnlyTransaction() *ReadOnlyTransaction
+SEC("uprobe/Client_Single")
+int uprobe_Client_Single(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    if (bpf_map_lookup_elem(&spanner_client_databases, &client) == NULL) {
+        return 0;
+    }
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&spanner_new_read_only_transactions, &key, &client, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following functions:
+// func (c *Client) Single() *ReadOnlyTransaction
+// func (c *Client) ReadOnlyTransaction() *ReadOnlyTransaction
+SEC("uprobe/Client_Single")
+int uprobe_Client_Single_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void **client_ptr = bpf_map_lookup_elem(&spanner_new_read_only_transactions, &key);
+    if (client_ptr == NULL) {
+        return 0;
+    }
+    void *client = *client_ptr;
+    bpf_map_delete_elem(&spanner_new_read_only_transactions, &key);
+
+    void *ro_txn = get_argument(ctx, 1);
+    char *database = bpf_map_lookup_elem(&spanner_client_databases, &client);
+    if (ro_txn == NULL || database == NULL) {
+        return 0;
+    }
+    // The queries are run by the embedded txReadOnly of the transaction.
+    void *tx = ro_txn + read_only_transaction_tx_read_only_pos;
+    bpf_map_update_elem(&spanner_transaction_databases, &tx, database, 0);
+    return 0;
+}
@@ -0,0 +1,247 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package spanner provides an instrumentation probe for Google Cloud Spanner
+// clients using the [cloud.google.com/go/spanner] package.
+package spanner
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "cloud.google.com/go/spanner"
+
+const (
+	// mutationCountKey is the attribute key of the number of mutations
+	// applied by Apply.
+	mutationCountKey = attribute.Key("spanner.mutation.count")
+	// retryCountKey is the attribute key of the number of times a
+	// transaction is retried after being aborted.
+	retryCountKey = attribute.Key("spanner.transaction.retry_count")
+)
+
+// dbSystemNameSpanner is the db.system.name attribute of Cloud Spanner.
+var dbSystemNameSpanner = semconv.DBSystemNameKey.String("gcp.spanner")
+
+// minVersion is the first version supported by the probe.
+var minVersion = semver.New(1, 25, 0, "", "")
+
+// New returns a new [probe.Probe].
+//
+// Spans are produced for the queries run with the Query methods of the
+// transactions, e.g. Client.Single().Query, from the call until their
+// RowIterator is stopped, and for the ReadWriteTransaction and Apply calls of
+// the Client. The gRPC client spans of the RPCs invoked by the transactions
+// are not produced: they are part of the transaction spans. The SQL text of
+// the queries is only included if configured with
+// OTEL_GO_AUTO_INCLUDE_DB_STATEMENT, truncated to 256 bytes.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	uprobe := func(sym, entry, ret string) *probe.Uprobe {
+		return &probe.Uprobe{
+			Sym:                pkg + "." + sym,
+			EntryProbe:         entry,
+			ReturnProbe:        ret,
+			PackageConstraints: []probe.PackageConstraints{supported},
+			FailureMode:        probe.FailureModeIgnore,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{
+					Key: "should_include_db_statement",
+					Val: shouldIncludeDBStatement(),
+				},
+				probe.StructFieldConst{
+					Key: "read_only_transaction_tx_read_only_pos",
+					ID:  structfield.NewID(pkg, pkg, "ReadOnlyTransaction", "txReadOnly"),
+				},
+				probe.SymbolAddrConst{
+					Key:    "iterator_done_addr",
+					Symbol: "google.golang.org/api/iterator.Done",
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				uprobe("NewClientWithConfig", "uprobe_NewClientWithConfig", "uprobe_NewClientWithConfig_Returns"),
+				uprobe("(*Client).Single", "uprobe_Client_Single", "uprobe_Client_Single_Returns"),
+				uprobe("(*Client).ReadOnlyTransaction", "uprobe_Client_Single", "uprobe_Client_Single_Returns"),
+				uprobe("(*txReadOnly).Query", "uprobe_txReadOnly_Query", "uprobe_txReadOnly_Query_Returns"),
+				uprobe("(*txReadOnly).QueryWithOptions", "uprobe_txReadOnly_Query", "uprobe_txReadOnly_Query_Returns"),
+				uprobe("(*txReadOnly).QueryWithStats", "uprobe_txReadOnly_Query", "uprobe_txReadOnly_Query_Returns"),
+				uprobe("(*RowIterator).Next", "uprobe_RowIterator_Next", "uprobe_RowIterator_Next_Returns"),
+				uprobe("(*RowIterator).Stop", "uprobe_RowIterator_Stop", ""),
+				uprobe("(*Client).ReadWriteTransaction", "uprobe_Client_ReadWriteTransaction", "uprobe_Client_ReadWriteTransaction_Returns"),
+				uprobe("(*Client).Apply", "uprobe_Client_Apply", "uprobe_Client_ReadWriteTransaction_Returns"),
+				uprobe("(*ReadWriteTransaction).runInTransaction", "uprobe_ReadWriteTransaction_runInTransaction", ""),
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// operation is an operation of the Spanner client, it needs to be kept in
+// sync with the operations of the eBPF program.
+type operation uint8
+
+const (
+	operationQuery operation = iota + 1
+	operationReadWriteTransaction
+	operationApply
+)
+
+// name returns the name of the client method of o.
+func (o operation) name() string {
+	switch o {
+	case operationQuery:
+		return "Query"
+	case operationReadWriteTransaction:
+		return "ReadWriteTransaction"
+	case operationApply:
+		return "Apply"
+	default:
+		return ""
+	}
+}
+
+// event represents a query, read-write transaction, or Apply call, of a
+// Spanner client.
+type event struct {
+	context.BaseSpanProperties
+	// Query is the SQL text of a query, if configured to be included.
+	Query [256]byte
+	// Database is the database of the client, e.g.
+	// "projects/p/instances/i/databases/d".
+	Database [128]byte
+	// Mutations is the number of mutations applied by Apply.
+	Mutations uint64
+	// Attempts is the number of times the function of a transaction is run.
+	Attempts  uint64
+	Operation operation
+	HasError  uint8
+	_         [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{dbSystemNameSpanner}
+
+	name := e.Operation.name()
+	opName := name
+	query := unix.ByteSliceToString(e.Query[:])
+	if query != "" {
+		attrs = append(attrs, semconv.DBQueryText(query))
+
+		if shouldParseDBStatement() {
+			operation, target, err := sql.Parse(query)
+			if err == nil && operation != "" {
+				opName, name = operation, operation
+				if target != "" {
+					attrs = append(attrs, semconv.DBCollectionName(target))
+					name += " " + target
+				}
+			}
+		}
+	}
+	if opName != "" {
+		attrs = append(attrs, semconv.DBOperationName(opName))
+	} else {
+		name = dbSystemNameSpanner.Value.AsString()
+	}
+
+	if db := unix.ByteSliceToString(e.Database[:]); db != "" {
+		attrs = append(attrs, semconv.DBNamespace(db))
+	}
+
+	if e.Operation == operationApply {
+		attrs = append(attrs, mutationCountKey.Int64(int64(min(e.Mutations, math.MaxInt64)))) // nolint: gosec  // Bounded.
+	}
+	// Apply calls run with the ApplyAtLeastOnce option are not run in a
+	// transaction.
+	if e.Attempts > 0 {
+		attrs = append(attrs, retryCountKey.Int64(int64(min(e.Attempts-1, math.MaxInt64)))) // nolint: gosec  // Bounded.
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// shouldIncludeDBStatement returns if the user has configured SQL queries to
+// be included.
+func shouldIncludeDBStatement() bool {
+	return envBool(sql.IncludeDBStatementEnvVar)
+}
+
+// shouldParseDBStatement returns if the user has configured SQL queries to be
+// parsed for their operation and table.
+func shouldParseDBStatement() bool {
+	return envBool(sql.ParseDBStatementEnvVar)
+}
+
+func envBool(key string) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && val
+}
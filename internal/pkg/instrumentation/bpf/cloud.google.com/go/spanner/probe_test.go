@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	const db = "projects/p/instances/i/databases/d"
+
+	newEvent := func(op operation, query string, mutations, attempts uint64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Mutations:          mutations,
+			Attempts:           attempts,
+			Operation:          op,
+		}
+		copy(e.Query[:], query)
+		copy(e.Database[:], db)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		parse bool
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "query",
+			event: newEvent(operationQuery, "SELECT * FROM Singers", 0, 0, false),
+			want: f.Spans(
+				"Query",
+				ptrace.StatusCodeUnset,
+				dbSystemNameSpanner,
+				semconv.DBQueryText("SELECT * FROM Singers"),
+				semconv.DBOperationName("Query"),
+				semconv.DBNamespace(db),
+			),
+		},
+		{
+			name:  "parsed query",
+			parse: true,
+			event: newEvent(operationQuery, "SELECT * FROM Singers", 0, 0, true),
+			want: f.Spans(
+				"SELECT Singers",
+				ptrace.StatusCodeError,
+				dbSystemNameSpanner,
+				semconv.DBQueryText("SELECT * FROM Singers"),
+				semconv.DBCollectionName("Singers"),
+				semconv.DBOperationName("SELECT"),
+				semconv.DBNamespace(db),
+			),
+		},
+		{
+			name:  "query not included",
+			event: newEvent(operationQuery, "", 0, 0, false),
+			want: f.Spans(
+				"Query",
+				ptrace.StatusCodeUnset,
+				dbSystemNameSpanner,
+				semconv.DBOperationName("Query"),
+				semconv.DBNamespace(db),
+			),
+		},
+		{
+			name:  "read-write transaction",
+			event: newEvent(operationReadWriteTransaction, "", 0, 1, false),
+			want: f.Spans(
+				"ReadWriteTransaction",
+				ptrace.StatusCodeUnset,
+				dbSystemNameSpanner,
+				semconv.DBOperationName("ReadWriteTransaction"),
+				semconv.DBNamespace(db),
+				retryCountKey.Int64(0),
+			),
+		},
+		{
+			name:  "retried read-write transaction",
+			event: newEvent(operationReadWriteTransaction, "", 0, 3, true),
+			want: f.Spans(
+				"ReadWriteTransaction",
+				ptrace.StatusCodeError,
+				dbSystemNameSpanner,
+				semconv.DBOperationName("ReadWriteTransaction"),
+				semconv.DBNamespace(db),
+				retryCountKey.Int64(2),
+			),
+		},
+		{
+			name:  "apply",
+			event: newEvent(operationApply, "", 4, 2, false),
+			want: f.Spans(
+				"Apply",
+				ptrace.StatusCodeUnset,
+				dbSystemNameSpanner,
+				semconv.DBOperationName("Apply"),
+				semconv.DBNamespace(db),
+				mutationCountKey.Int64(4),
+				retryCountKey.Int64(1),
+			),
+		},
+		{
+			name:  "apply at least once",
+			event: newEvent(operationApply, "", 1, 0, false),
+			want: f.Spans(
+				"Apply",
+				ptrace.StatusCodeUnset,
+				dbSystemNameSpanner,
+				semconv.DBOperationName("Apply"),
+				semconv.DBNamespace(db),
+				mutationCountKey.Int64(1),
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent(0, "", 0, 0, false),
+			want: f.Spans(
+				"gcp.spanner",
+				ptrace.StatusCodeUnset,
+				dbSystemNameSpanner,
+				semconv.DBNamespace(db),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.parse {
+				t.Setenv(sql.ParseDBStatementEnvVar, "true")
+			}
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
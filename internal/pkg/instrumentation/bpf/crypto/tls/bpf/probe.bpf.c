@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_net.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_SERVER_NAME_SIZE 128
+
+struct tls_handshake_t {
+    BASE_SPAN_PROPERTIES
+    // The ServerName of the Config of the connection.
+    char server_name[MAX_SERVER_NAME_SIZE];
+    // The remote address of the connection, if it is a TCP one.
+    net_addr_t peer;
+    // The negotiated TLS version and cipher suite.
+    u16 version;
+    u16 cipher_suite;
+    u8 has_error;
+    u8 padding[3];
+};
+
+// A handshake in progress.
+struct handshake_t {
+    struct tls_handshake_t span;
+    // The *Conn handshaking.
+    void *conn;
+};
+
+// Handshakes in progress, keyed by the goroutine handshaking.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct handshake_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} handshakes SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct handshake_t));
+    __uint(max_entries, 1);
+} handshake_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 tls_conn_conn_pos;
+volatile const u64 tls_conn_vers_pos;
+volatile const u64 tls_conn_config_pos;
+volatile const u64 tls_conn_cipher_suite_pos;
+volatile const u64 tls_config_server_name_pos;
+volatile const u64 conn_fd_pos;
+volatile const u64 net_fd_raddr_pos;
+
+// Reads the remote address of the net.Conn of the *Conn conn_ptr into addr.
+// Only TCP connections are supported.
+static __always_inline void read_peer(struct pt_regs *ctx, void *conn_ptr, net_addr_t *addr) {
+    // The net.Conn is expected to be a *net.TCPConn, which embeds a net.conn
+    // holding the *net.netFD of the connection.
+    void *tcp_conn_ptr = NULL;
+    bpf_probe_read_user(&tcp_conn_ptr, sizeof(tcp_conn_ptr), get_go_interface_instance(conn_ptr + tls_conn_conn_pos));
+    if (tcp_conn_ptr == NULL) {
+        return;
+    }
+    void *fd_ptr = NULL;
+    bpf_probe_read_user(&fd_ptr, sizeof(fd_ptr), (void *)(tcp_conn_ptr + conn_fd_pos));
+    if (fd_ptr == NULL) {
+        return;
+    }
+    void *raddr_ptr = NULL;
+    bpf_probe_read_user(&raddr_ptr, sizeof(raddr_ptr), get_go_interface_instance(fd_ptr + net_fd_raddr_pos));
+    if (raddr_ptr == NULL) {
+        return;
+    }
+    get_tcp_net_addr_from_tcp_addr(ctx, addr, raddr_ptr);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) HandshakeContext(ctx context.Context) error
+SEC("uprobe/Conn_HandshakeContext")
+int uprobe_Conn_HandshakeContext(struct pt_regs *ctx) {
+    // Only the handshakes of a traced operation, e.g. of the connection of an
+    // HTTP client request, are traced.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    if (get_parent_span_context(&go_context) == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct handshake_t *handshake = bpf_map_lookup_elem(&handshake_storage_map, &zero);
+    if (handshake == NULL) {
+        bpf_printk("uprobe/Conn_HandshakeContext: handshake is NULL");
+        return 0;
+    }
+    __builtin_memset(handshake, 0, sizeof(struct handshake_t));
+    handshake->span.start_time = get_time_ns();
+    handshake->conn = get_argument(ctx, 1);
+
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &handshake->span.psc,
+        .sc = &handshake->span.sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&handshakes, &key, handshake, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (c *Conn) HandshakeContext(ctx context.Context) error
+SEC("uprobe/Conn_HandshakeContext")
+int uprobe_Conn_HandshakeContext_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct handshake_t *handshake = bpf_map_lookup_elem(&handshakes, &key);
+    if (handshake == NULL) {
+        return 0;
+    }
+    struct tls_handshake_t *span = &handshake->span;
+    span->end_time = end_time;
+    span->has_error = get_argument(ctx, 1) != NULL;
+
+    void *conn_ptr = handshake->conn;
+    void *config_ptr = NULL;
+    bpf_probe_read_user(&config_ptr, sizeof(config_ptr), (void *)(conn_ptr + tls_conn_config_pos));
+    if (config_ptr != NULL) {
+        get_go_string_from_user_ptr((void *)(config_ptr + tls_config_server_name_pos), span->server_name, sizeof(span->server_name));
+    }
+    read_peer(ctx, conn_ptr, &span->peer);
+    if (!span->has_error) {
+        bpf_probe_read_user(&span->version, sizeof(span->version), (void *)(conn_ptr + tls_conn_vers_pos));
+        bpf_probe_read_user(&span->cipher_suite, sizeof(span->cipher_suite), (void *)(conn_ptr + tls_conn_cipher_suite_pos));
+    }
+
+    output_span_event(ctx, span, sizeof(*span), &span->sc);
+
+    bpf_map_delete_elem(&handshakes, &key);
+    return 0;
+}
@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tls provides an instrumentation probe for the handshakes of the
+// [crypto/tls.Conn] connections.
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "crypto/tls"
+
+const (
+	// The attribute keys of the negotiated protocol and cipher suite.
+	tlsProtocolNameKey    = attribute.Key("tls.protocol.name")
+	tlsProtocolVersionKey = attribute.Key("tls.protocol.version")
+	tlsCipherKey          = attribute.Key("tls.cipher")
+)
+
+// New returns a new [probe.Probe]. It is only loaded if enabled is true.
+//
+// Spans are produced for the handshakes of [crypto/tls.Conn.HandshakeContext],
+// e.g. the ones of the connections of the [net/http.Transport], as the
+// children of the span of the context they are made with. The handshakes made
+// without a span, e.g. not for an HTTP client request, are not traced.
+func New(logger *slog.Logger, version string, enabled bool) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	return &tlsProbe{
+		SpanProducer: &probe.SpanProducer[bpfObjects, event]{
+			Base: probe.Base[bpfObjects, event]{
+				ID:     id,
+				Logger: logger,
+				Consts: []probe.Const{
+					probe.AllocationConst{},
+					probe.BootClockConst{},
+					probe.StructFieldConst{
+						Key: "tls_conn_conn_pos",
+						ID:  structfield.NewID("std", pkg, "Conn", "conn"),
+					},
+					probe.StructFieldConst{
+						Key: "tls_conn_vers_pos",
+						ID:  structfield.NewID("std", pkg, "Conn", "vers"),
+					},
+					probe.StructFieldConst{
+						Key: "tls_conn_config_pos",
+						ID:  structfield.NewID("std", pkg, "Conn", "config"),
+					},
+					probe.StructFieldConst{
+						Key: "tls_conn_cipher_suite_pos",
+						ID:  structfield.NewID("std", pkg, "Conn", "cipherSuite"),
+					},
+					probe.StructFieldConst{
+						Key: "tls_config_server_name_pos",
+						ID:  structfield.NewID("std", pkg, "Config", "ServerName"),
+					},
+					probe.StructFieldConst{
+						Key: "conn_fd_pos",
+						ID:  structfield.NewID("std", "net", "conn", "fd"),
+					},
+					probe.StructFieldConst{
+						Key: "net_fd_raddr_pos",
+						ID:  structfield.NewID("std", "net", "netFD", "raddr"),
+					},
+					probe.StructFieldConst{
+						Key: "TCPAddr_IP_offset",
+						ID:  structfield.NewID("std", "net", "TCPAddr", "IP"),
+					},
+					probe.StructFieldConst{
+						Key: "TCPAddr_Port_offset",
+						ID:  structfield.NewID("std", "net", "TCPAddr", "Port"),
+					},
+					probe.StructFieldConst{
+						Key: "TCPAddr_Zone_offset",
+						ID:  structfield.NewID("std", "net", "TCPAddr", "Zone"),
+					},
+				},
+				Uprobes: []*probe.Uprobe{
+					{
+						Sym:         pkg + ".(*Conn).HandshakeContext",
+						EntryProbe:  "uprobe_Conn_HandshakeContext",
+						ReturnProbe: "uprobe_Conn_HandshakeContext_Returns",
+					},
+				},
+				SpecFn: loadBpf,
+			},
+			Version:   version,
+			SchemaURL: semconv.SchemaURL,
+			ProcessFn: processFn,
+		},
+		enabled: enabled,
+	}
+}
+
+// tlsProbe is the probe of the handshakes, disabled by default.
+type tlsProbe struct {
+	*probe.SpanProducer[bpfObjects, event]
+
+	enabled bool
+}
+
+var _ probe.Optional = (*tlsProbe)(nil)
+
+// Enabled returns whether the handshakes are traced.
+func (p *tlsProbe) Enabled() bool { return p.enabled }
+
+// event represents a handshake.
+type event struct {
+	context.BaseSpanProperties
+	ServerName [128]byte
+	// Peer is the remote address of the connection, if it is a TCP one.
+	Peer netattr.NetAddr
+	// Version and CipherSuite are the negotiated ones, zero if the handshake
+	// failed.
+	Version     uint16
+	CipherSuite uint16
+	HasError    uint8
+	_           [3]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	server := netattr.Addr{Host: unix.ByteSliceToString(e.ServerName[:])}
+	attrs := netattr.Attributes(server, e.Peer.Addr())
+
+	if ver := protocolVersion(e.Version); ver != "" {
+		attrs = append(
+			attrs,
+			tlsProtocolNameKey.String("tls"),
+			tlsProtocolVersionKey.String(ver),
+		)
+	}
+	if e.CipherSuite != 0 {
+		attrs = append(attrs, tlsCipherKey.String(cryptotls.CipherSuiteName(e.CipherSuite)))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("TLS handshake")
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// protocolVersion returns the tls.protocol.version of the TLS version vers,
+// or an empty string if it is not known.
+func protocolVersion(vers uint16) string {
+	switch vers {
+	case cryptotls.VersionTLS10:
+		return "1.0"
+	case cryptotls.VersionTLS11:
+		return "1.1"
+	case cryptotls.VersionTLS12:
+		return "1.2"
+	case cryptotls.VersionTLS13:
+		return "1.3"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(version, cipherSuite uint16) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Version:            version,
+			CipherSuite:        cipherSuite,
+		}
+		copy(e.ServerName[:], "example.com")
+		e.Peer.IPLen = uint8(copy(e.Peer.IP[:], []byte{192, 0, 2, 1}))
+		e.Peer.Port = 443
+		return e
+	}
+
+	errEvent := newEvent(0, 0)
+	errEvent.HasError = 1
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "TLS 1.3",
+			event: newEvent(cryptotls.VersionTLS13, cryptotls.TLS_AES_128_GCM_SHA256),
+			want: f.Spans(
+				"TLS handshake",
+				ptrace.StatusCodeUnset,
+				semconv.NetworkPeerAddress("192.0.2.1"),
+				semconv.NetworkPeerPort(443),
+				semconv.ServerAddress("example.com"),
+				semconv.NetworkTransportTCP,
+				tlsProtocolNameKey.String("tls"),
+				tlsProtocolVersionKey.String("1.3"),
+				tlsCipherKey.String("TLS_AES_128_GCM_SHA256"),
+			),
+		},
+		{
+			name:  "TLS 1.2",
+			event: newEvent(cryptotls.VersionTLS12, cryptotls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256),
+			want: f.Spans(
+				"TLS handshake",
+				ptrace.StatusCodeUnset,
+				semconv.NetworkPeerAddress("192.0.2.1"),
+				semconv.NetworkPeerPort(443),
+				semconv.ServerAddress("example.com"),
+				semconv.NetworkTransportTCP,
+				tlsProtocolNameKey.String("tls"),
+				tlsProtocolVersionKey.String("1.2"),
+				tlsCipherKey.String("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"),
+			),
+		},
+		{
+			name:  "error",
+			event: errEvent,
+			want: f.Spans(
+				"TLS handshake",
+				ptrace.StatusCodeError,
+				semconv.NetworkPeerAddress("192.0.2.1"),
+				semconv.NetworkPeerPort(443),
+				semconv.ServerAddress("example.com"),
+				semconv.NetworkTransportTCP,
+				semconv.ErrorTypeOther,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestProtocolVersion(t *testing.T) {
+	tests := map[uint16]string{
+		cryptotls.VersionTLS10: "1.0",
+		cryptotls.VersionTLS11: "1.1",
+		cryptotls.VersionTLS12: "1.2",
+		cryptotls.VersionTLS13: "1.3",
+		0:                      "",
+		0x0300:                 "",
+	}
+	for vers, want := range tests {
+		assert.Equal(t, want, protocolVersion(vers), vers)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		p, ok := New(slog.Default(), "", enabled).(probe.Optional)
+		if assert.True(t, ok) {
+			assert.Equal(t, enabled, p.Enabled())
+		}
+	}
+}
@@ -12,10 +12,53 @@ char __license[] SEC("license") = "Dual MIT/GPL";
 
 #define MAX_QUERY_SIZE 256
 #define MAX_CONCURRENT 50
+#define MAX_TABLE_SIZE 64
+// The first clause of the operations of GORM is "INSERT", "SELECT", "UPDATE",
+// or "DELETE".
+#define MAX_CLAUSE_SIZE 8
+// The maximum depth of the nested GORM executions tracked, e.g. the ones
+// saving the associations of a model created.
+#define MAX_GORM_DEPTH 8
+// The number of open transactions tracked. The least recently used ones, e.g.
+// transactions never committed nor rolled back, are evicted once it is
+// reached.
+#define MAX_TRANSACTIONS 1024
+
+// The outcomes of a transaction, they need to be kept in sync with the ones of
+// the probe.
+#define OUTCOME_COMMIT 1
+#define OUTCOME_ROLLBACK 2
 
 struct sql_request_t {
     BASE_SPAN_PROPERTIES
     char query[MAX_QUERY_SIZE];
+    // The table and first clause of the GORM operation executing the query,
+    // and the rows it affected, if has_gorm is set.
+    char table[MAX_TABLE_SIZE];
+    char clause[MAX_CLAUSE_SIZE];
+    s64 rows_affected;
+    u8 has_gorm;
+    // Whether the request is a transaction, from the time it begins to the
+    // time it is committed or rolled back, its isolation level, and its
+    // outcome. Its query, and GORM operation, are not set.
+    u8 is_tx;
+    u8 isolation;
+    u8 outcome;
+    u8 has_error;
+    u8 padding[3];
+};
+
+struct gorm_execution_t {
+    // The first query of the execution, output once it returns.
+    struct sql_request_t request;
+    char clause[MAX_CLAUSE_SIZE];
+    u8 has_request;
+    u8 padding[7];
+};
+
+struct gorm_execution_key_t {
+    void *goroutine;
+    u64 depth;
 };
 
 struct {
@@ -25,70 +68,334 @@ struct {
 	__uint(max_entries, MAX_CONCURRENT);
 } sql_events SEC(".maps");
 
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct sql_request_t));
+    __uint(max_entries, 1);
+} sql_request_storage_map SEC(".maps");
+
+// Transactions beginning, keyed by the goroutine beginning them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct sql_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} sql_tx_begins SEC(".maps");
+
+// Open transactions, keyed by their Tx. A transaction can be committed, or
+// rolled back, by another goroutine than the one it began in.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct sql_request_t);
+    __uint(max_entries, MAX_TRANSACTIONS);
+} sql_txs SEC(".maps");
+
+// The span context of the open transactions, keyed by the driverConn they
+// hold. The queries of a transaction are executed on its connection.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct span_context);
+    __uint(max_entries, MAX_TRANSACTIONS);
+} sql_tx_conns SEC(".maps");
+
+// Transactions being committed, or rolled back, keyed by the goroutine ending
+// them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} sql_tx_ends SEC(".maps");
+
+// The depth of the GORM executions in progress, keyed by the goroutine
+// executing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, u64);
+    __uint(max_entries, MAX_CONCURRENT);
+} gorm_depths SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, struct gorm_execution_key_t);
+    __type(value, struct gorm_execution_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} gorm_executions SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct gorm_execution_t));
+    __uint(max_entries, 1);
+} gorm_execution_storage_map SEC(".maps");
+
 // Injected in init
 volatile const bool should_include_db_statement;
+volatile const u64 gorm_db_rows_affected_pos;
+volatile const u64 gorm_db_statement_pos;
+volatile const u64 gorm_statement_table_pos;
+volatile const u64 gorm_statement_sql_pos;
+volatile const u64 gorm_processor_clauses_pos;
+volatile const u64 tx_dc_pos;
+volatile const u64 tx_options_isolation_pos;
 
-// This instrumentation attaches uprobe to the following function:
-// func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any)
-SEC("uprobe/queryDC")
-int uprobe_queryDC(struct pt_regs *ctx) {
-    // argument positions
-    u64 context_ptr_pos = 3;
-    u64 query_str_ptr_pos = 8;
-    u64 query_str_len_pos = 9;
-
-    struct sql_request_t sql_request = {0};
-    sql_request.start_time = bpf_ktime_get_ns();
+static __always_inline struct sql_request_t *new_sql_request(struct pt_regs *ctx, u64 query_str_ptr_pos, u64 query_str_len_pos) {
+    u32 zero = 0;
+    struct sql_request_t *sql_request = bpf_map_lookup_elem(&sql_request_storage_map, &zero);
+    if (sql_request == NULL) {
+        return NULL;
+    }
+    __builtin_memset(sql_request, 0, sizeof(struct sql_request_t));
+    sql_request->start_time = get_time_ns();
 
     if (should_include_db_statement) {
         // Read Query string
         void *query_str_ptr = get_argument(ctx, query_str_ptr_pos);
         u64 query_str_len = (u64)get_argument(ctx, query_str_len_pos);
         u64 query_size = MAX_QUERY_SIZE < query_str_len ? MAX_QUERY_SIZE : query_str_len;
-        bpf_probe_read(sql_request.query, query_size, query_str_ptr);
+        bpf_probe_read(sql_request->query, query_size, query_str_ptr);
     }
+    return sql_request;
+}
 
+static __always_inline long get_tx_parent_span_context(void *tx_sc, struct span_context *psc) {
+    *psc = *(struct span_context *)tx_sc;
+    return 0;
+}
+
+// start_sql_request_span starts the span of a query executed on the
+// connection dc. It is the child of the span of the transaction holding the
+// connection, if any, or of the span of the context of the query otherwise.
+static __always_inline void start_sql_request_span(struct pt_regs *ctx, struct sql_request_t *sql_request, void *dc) {
     struct go_iface go_context = {0};
     get_Go_context(ctx, 2, 0, true, &go_context);
     start_span_params_t start_span_params = {
         .ctx = ctx,
         .go_context = &go_context,
-        .psc = &sql_request.psc,
-        .sc = &sql_request.sc,
+        .psc = &sql_request->psc,
+        .sc = &sql_request->sc,
         .get_parent_span_context_fn = NULL,
         .get_parent_span_context_arg = NULL,
     };
+    struct span_context *tx_sc = bpf_map_lookup_elem(&sql_tx_conns, &dc);
+    if (tx_sc != NULL) {
+        start_span_params.get_parent_span_context_fn = get_tx_parent_span_context;
+        start_span_params.get_parent_span_context_arg = tx_sc;
+    }
     start_span(&start_span_params);
+}
+
+// current_gorm_execution returns the innermost GORM execution in progress in
+// the goroutine, or NULL if there is none.
+static __always_inline struct gorm_execution_t *current_gorm_execution(void *goroutine) {
+    u64 *depth = bpf_map_lookup_elem(&gorm_depths, &goroutine);
+    if (depth == NULL) {
+        return NULL;
+    }
+    struct gorm_execution_key_t key = {.goroutine = goroutine, .depth = *depth};
+    return bpf_map_lookup_elem(&gorm_executions, &key);
+}
+
+static __always_inline int sql_request_returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct sql_request_t *sql_request = bpf_map_lookup_elem(&sql_events, &key);
+    if (sql_request == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+    sql_request->end_time = get_time_ns();
+
+    // The first query of a GORM execution is output once the execution
+    // returns, with the attributes of its operation. Other queries are output
+    // as is.
+    struct gorm_execution_t *execution = current_gorm_execution(key);
+    if (execution != NULL && !execution->has_request) {
+        execution->request = *sql_request;
+        execution->has_request = 1;
+    } else {
+        output_span_event(ctx, sql_request, sizeof(*sql_request), &sql_request->sc);
+    }
+
+    stop_tracking_span(&sql_request->sc, &sql_request->psc);
+    bpf_map_delete_elem(&sql_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any)
+SEC("uprobe/queryDC")
+int uprobe_queryDC(struct pt_regs *ctx) {
+    // argument positions
+    u64 query_str_ptr_pos = 8;
+    u64 query_str_len_pos = 9;
+    u64 dc_pos = 6;
+
+    struct sql_request_t *sql_request = new_sql_request(ctx, query_str_ptr_pos, query_str_len_pos);
+    if (sql_request == NULL) {
+        bpf_printk("uprobe/queryDC: sql_request is NULL");
+        return 0;
+    }
+
+    start_sql_request_span(ctx, sql_request, get_argument(ctx, dc_pos));
 
     // Get key
     void *key = (void *)GOROUTINE(ctx);
 
-    bpf_map_update_elem(&sql_events, &key, &sql_request, 0);
+    bpf_map_update_elem(&sql_events, &key, sql_request, 0);
     return 0;
 }
 
 // This instrumentation attaches uprobe to the following function:
 // func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any)
-UPROBE_RETURN(queryDC, struct sql_request_t, sql_events)
+SEC("uprobe/queryDC")
+int uprobe_queryDC_Returns(struct pt_regs *ctx) {
+    return sql_request_returns(ctx);
+}
 
 // This instrumentation attaches uprobe to the following function:
 // func (db *DB) execDC(ctx context.Context, dc *driverConn, release func(error), query string, args []any)
 SEC("uprobe/execDC")
 int uprobe_execDC(struct pt_regs *ctx) {
     // argument positions
-    u64 context_ptr_pos = 3;
     u64 query_str_ptr_pos = 6;
     u64 query_str_len_pos = 7;
+    u64 dc_pos = 4;
 
-    struct sql_request_t sql_request = {0};
-    sql_request.start_time = bpf_ktime_get_ns();
+    struct sql_request_t *sql_request = new_sql_request(ctx, query_str_ptr_pos, query_str_len_pos);
+    if (sql_request == NULL) {
+        bpf_printk("uprobe/execDC: sql_request is NULL");
+        return 0;
+    }
 
-    if (should_include_db_statement) {
-        // Read Query string
-        void *query_str_ptr = get_argument(ctx, query_str_ptr_pos);
-        u64 query_str_len = (u64)get_argument(ctx, query_str_len_pos);
-        u64 query_size = MAX_QUERY_SIZE < query_str_len ? MAX_QUERY_SIZE : query_str_len;
-        bpf_probe_read(sql_request.query, query_size, query_str_ptr);
+    start_sql_request_span(ctx, sql_request, get_argument(ctx, dc_pos));
+
+    // Get key
+    void *key = (void *)GOROUTINE(ctx);
+
+    bpf_map_update_elem(&sql_events, &key, sql_request, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) execDC(ctx context.Context, dc *driverConn, release func(error), query string, args []any)
+SEC("uprobe/execDC")
+int uprobe_execDC_Returns(struct pt_regs *ctx) {
+    return sql_request_returns(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *processor) Execute(db *DB) *DB
+SEC("uprobe/processor_Execute")
+int uprobe_processor_Execute(struct pt_regs *ctx) {
+    void *goroutine = (void *)GOROUTINE(ctx);
+    u64 depth = 0;
+    u64 *parent_depth = bpf_map_lookup_elem(&gorm_depths, &goroutine);
+    if (parent_depth != NULL) {
+        depth = *parent_depth + 1;
+    }
+    // The depth is tracked beyond MAX_GORM_DEPTH for the returns to match.
+    bpf_map_update_elem(&gorm_depths, &goroutine, &depth, 0);
+    if (depth >= MAX_GORM_DEPTH) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct gorm_execution_t *execution = bpf_map_lookup_elem(&gorm_execution_storage_map, &zero);
+    if (execution == NULL) {
+        bpf_printk("uprobe/processor_Execute: execution is NULL");
+        return 0;
+    }
+    __builtin_memset(execution, 0, sizeof(struct gorm_execution_t));
+
+    void *processor = get_argument(ctx, 1);
+    void *db = get_argument(ctx, 2);
+    void *statement = NULL;
+    bpf_probe_read_user(&statement, sizeof(statement), (void *)(db + gorm_db_statement_pos));
+
+    // The SQL of the Statement is only written before it is executed by Exec
+    // and Raw, the clauses of the processor are not the ones of the query
+    // then. The buf of a strings.Builder follows its addr.
+    u64 sql_len = 0;
+    bpf_probe_read_user(&sql_len, sizeof(sql_len), (void *)(statement + gorm_statement_sql_pos + 16));
+    if (sql_len == 0) {
+        struct go_slice clauses = {0};
+        bpf_probe_read_user(&clauses, sizeof(clauses), (void *)(processor + gorm_processor_clauses_pos));
+        if (clauses.len > 0) {
+            get_go_string_from_user_ptr(clauses.array, execution->clause, sizeof(execution->clause));
+        }
+    }
+
+    struct gorm_execution_key_t key = {.goroutine = goroutine, .depth = depth};
+    bpf_map_update_elem(&gorm_executions, &key, execution, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *processor) Execute(db *DB) *DB
+SEC("uprobe/processor_Execute")
+int uprobe_processor_Execute_Returns(struct pt_regs *ctx) {
+    void *goroutine = (void *)GOROUTINE(ctx);
+    u64 *depth_ptr = bpf_map_lookup_elem(&gorm_depths, &goroutine);
+    if (depth_ptr == NULL) {
+        return 0;
+    }
+    u64 depth = *depth_ptr;
+    if (depth == 0) {
+        bpf_map_delete_elem(&gorm_depths, &goroutine);
+    } else {
+        u64 parent_depth = depth - 1;
+        bpf_map_update_elem(&gorm_depths, &goroutine, &parent_depth, 0);
+    }
+
+    struct gorm_execution_key_t key = {.goroutine = goroutine, .depth = depth};
+    struct gorm_execution_t *execution = bpf_map_lookup_elem(&gorm_executions, &key);
+    if (execution == NULL) {
+        return 0;
+    }
+
+    if (execution->has_request) {
+        struct sql_request_t *sql_request = &execution->request;
+        sql_request->has_gorm = 1;
+        __builtin_memcpy(sql_request->clause, execution->clause, sizeof(sql_request->clause));
+
+        // The DB returned is the one executed, after its scopes are applied.
+        void *db = get_argument(ctx, 1);
+        bpf_probe_read_user(&sql_request->rows_affected, sizeof(sql_request->rows_affected), (void *)(db + gorm_db_rows_affected_pos));
+        void *statement = NULL;
+        bpf_probe_read_user(&statement, sizeof(statement), (void *)(db + gorm_db_statement_pos));
+        get_go_string_from_user_ptr((void *)(statement + gorm_statement_table_pos), sql_request->table, sizeof(sql_request->table));
+
+        output_span_event(ctx, sql_request, sizeof(*sql_request), &sql_request->sc);
+    }
+
+    bpf_map_delete_elem(&gorm_executions, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error)
+// func (c *Conn) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error)
+SEC("uprobe/BeginTx")
+int uprobe_BeginTx(struct pt_regs *ctx) {
+    u32 zero = 0;
+    struct sql_request_t *tx = bpf_map_lookup_elem(&sql_request_storage_map, &zero);
+    if (tx == NULL) {
+        bpf_printk("uprobe/BeginTx: tx is NULL");
+        return 0;
+    }
+    __builtin_memset(tx, 0, sizeof(struct sql_request_t));
+    tx->start_time = get_time_ns();
+    tx->is_tx = 1;
+
+    void *opts = get_argument(ctx, 4);
+    if (opts != NULL) {
+        s64 isolation = 0;
+        bpf_probe_read_user(&isolation, sizeof(isolation), (void *)(opts + tx_options_isolation_pos));
+        tx->isolation = (u8)isolation;
     }
 
     struct go_iface go_context = {0};
@@ -96,20 +403,115 @@ int uprobe_execDC(struct pt_regs *ctx) {
     start_span_params_t start_span_params = {
         .ctx = ctx,
         .go_context = &go_context,
-        .psc = &sql_request.psc,
-        .sc = &sql_request.sc,
+        .psc = &tx->psc,
+        .sc = &tx->sc,
         .get_parent_span_context_fn = NULL,
         .get_parent_span_context_arg = NULL,
     };
     start_span(&start_span_params);
 
-    // Get key
     void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&sql_tx_begins, &key, tx, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following functions:
+// func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error)
+// func (c *Conn) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error)
+SEC("uprobe/BeginTx")
+int uprobe_BeginTx_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct sql_request_t *tx = bpf_map_lookup_elem(&sql_tx_begins, &key);
+    if (tx == NULL) {
+        return 0;
+    }
+
+    void *tx_ptr = get_argument(ctx, 1);
+    if (tx_ptr == NULL) {
+        // The transaction failed to begin, it ends here.
+        tx->end_time = get_time_ns();
+        tx->has_error = 1;
+        output_span_event(ctx, tx, sizeof(*tx), &tx->sc);
+    } else {
+        void *dc = NULL;
+        bpf_probe_read_user(&dc, sizeof(dc), (void *)(tx_ptr + tx_dc_pos));
+        if (dc != NULL) {
+            bpf_map_update_elem(&sql_tx_conns, &dc, &tx->sc, 0);
+        }
+        bpf_map_update_elem(&sql_txs, &tx_ptr, tx, 0);
+    }
+
+    bpf_map_delete_elem(&sql_tx_begins, &key);
+    return 0;
+}
+
+static __always_inline int tx_end(struct pt_regs *ctx) {
+    void *tx_ptr = get_argument(ctx, 1);
+    if (bpf_map_lookup_elem(&sql_txs, &tx_ptr) == NULL) {
+        // Not tracked, or already committed or rolled back.
+        return 0;
+    }
+
+    // The queries executed on the connection from now on are not the ones of
+    // the transaction.
+    void *dc = NULL;
+    bpf_probe_read_user(&dc, sizeof(dc), (void *)(tx_ptr + tx_dc_pos));
+    bpf_map_delete_elem(&sql_tx_conns, &dc);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&sql_tx_ends, &key, &tx_ptr, 0);
+    return 0;
+}
+
+static __always_inline int tx_end_returns(struct pt_regs *ctx, u8 outcome) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    void **tx_ptr = bpf_map_lookup_elem(&sql_tx_ends, &key);
+    if (tx_ptr == NULL) {
+        return 0;
+    }
+    void *tx_key = *tx_ptr;
+    bpf_map_delete_elem(&sql_tx_ends, &key);
 
-    bpf_map_update_elem(&sql_events, &key, &sql_request, 0);
+    struct sql_request_t *tx = bpf_map_lookup_elem(&sql_txs, &tx_key);
+    if (tx == NULL) {
+        return 0;
+    }
+    tx->end_time = end_time;
+    tx->outcome = outcome;
+    if (get_argument(ctx, 1) != NULL) {
+        tx->has_error = 1;
+    }
+
+    output_span_event(ctx, tx, sizeof(*tx), &tx->sc);
+    bpf_map_delete_elem(&sql_txs, &tx_key);
     return 0;
 }
 
 // This instrumentation attaches uprobe to the following function:
-// func (db *DB) execDC(ctx context.Context, dc *driverConn, release func(error), query string, args []any)
-UPROBE_RETURN(execDC, struct sql_request_t, sql_events)
+// func (tx *Tx) Commit() error
+SEC("uprobe/Tx_Commit")
+int uprobe_Tx_Commit(struct pt_regs *ctx) {
+    return tx_end(ctx);
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (tx *Tx) Commit() error
+SEC("uprobe/Tx_Commit")
+int uprobe_Tx_Commit_Returns(struct pt_regs *ctx) {
+    return tx_end_returns(ctx, OUTCOME_COMMIT);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tx *Tx) rollback(discardConn bool) error
+SEC("uprobe/Tx_rollback")
+int uprobe_Tx_rollback(struct pt_regs *ctx) {
+    return tx_end(ctx);
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (tx *Tx) rollback(discardConn bool) error
+SEC("uprobe/Tx_rollback")
+int uprobe_Tx_rollback_Returns(struct pt_regs *ctx) {
+    return tx_end_returns(ctx, OUTCOME_ROLLBACK);
+}
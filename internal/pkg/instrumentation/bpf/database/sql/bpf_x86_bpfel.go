@@ -13,6 +13,20 @@ import (
 	"github.com/cilium/ebpf"
 )
 
+type bpfGormExecutionKeyT struct {
+	_         structs.HostLayout
+	Goroutine uint64
+	Depth     uint64
+}
+
+type bpfGormExecutionT struct {
+	_          structs.HostLayout
+	Request    bpfSqlRequestT
+	Clause     [8]int8
+	HasRequest uint8
+	Padding    [7]uint8
+}
+
 type bpfSliceArrayBuff struct {
 	_    structs.HostLayout
 	Buff [1024]uint8
@@ -27,12 +41,21 @@ type bpfSpanContext struct {
 }
 
 type bpfSqlRequestT struct {
-	_         structs.HostLayout
-	StartTime uint64
-	EndTime   uint64
-	Sc        bpfSpanContext
-	Psc       bpfSpanContext
-	Query     [256]int8
+	_            structs.HostLayout
+	StartTime    uint64
+	EndTime      uint64
+	Sc           bpfSpanContext
+	Psc          bpfSpanContext
+	Query        [256]int8
+	Table        [64]int8
+	Clause       [8]int8
+	RowsAffected int64
+	HasGorm      uint8
+	IsTx         uint8
+	Isolation    uint8
+	Outcome      uint8
+	HasError     uint8
+	Padding      [3]uint8
 }
 
 // loadBpf returns the embedded CollectionSpec for bpf.
@@ -77,35 +100,59 @@ type bpfSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfProgramSpecs struct {
-	UprobeExecDC          *ebpf.ProgramSpec `ebpf:"uprobe_execDC"`
-	UprobeExecDC_Returns  *ebpf.ProgramSpec `ebpf:"uprobe_execDC_Returns"`
-	UprobeQueryDC         *ebpf.ProgramSpec `ebpf:"uprobe_queryDC"`
-	UprobeQueryDC_Returns *ebpf.ProgramSpec `ebpf:"uprobe_queryDC_Returns"`
+	UprobeBeginTx                 *ebpf.ProgramSpec `ebpf:"uprobe_BeginTx"`
+	UprobeBeginTxReturns          *ebpf.ProgramSpec `ebpf:"uprobe_BeginTx_Returns"`
+	UprobeTxCommit                *ebpf.ProgramSpec `ebpf:"uprobe_Tx_Commit"`
+	UprobeTxCommitReturns         *ebpf.ProgramSpec `ebpf:"uprobe_Tx_Commit_Returns"`
+	UprobeTxRollback              *ebpf.ProgramSpec `ebpf:"uprobe_Tx_rollback"`
+	UprobeTxRollbackReturns       *ebpf.ProgramSpec `ebpf:"uprobe_Tx_rollback_Returns"`
+	UprobeExecDC                  *ebpf.ProgramSpec `ebpf:"uprobe_execDC"`
+	UprobeExecDC_Returns          *ebpf.ProgramSpec `ebpf:"uprobe_execDC_Returns"`
+	UprobeProcessorExecute        *ebpf.ProgramSpec `ebpf:"uprobe_processor_Execute"`
+	UprobeProcessorExecuteReturns *ebpf.ProgramSpec `ebpf:"uprobe_processor_Execute_Returns"`
+	UprobeQueryDC                 *ebpf.ProgramSpec `ebpf:"uprobe_queryDC"`
+	UprobeQueryDC_Returns         *ebpf.ProgramSpec `ebpf:"uprobe_queryDC_Returns"`
 }
 
 // bpfMapSpecs contains maps before they are loaded into the kernel.
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfMapSpecs struct {
-	AllocMap              *ebpf.MapSpec `ebpf:"alloc_map"`
-	Events                *ebpf.MapSpec `ebpf:"events"`
-	GoContextToSc         *ebpf.MapSpec `ebpf:"go_context_to_sc"`
-	ProbeActiveSamplerMap *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
-	SamplersConfigMap     *ebpf.MapSpec `ebpf:"samplers_config_map"`
-	SliceArrayBuffMap     *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
-	SqlEvents             *ebpf.MapSpec `ebpf:"sql_events"`
-	TrackedSpansBySc      *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
+	AllocMap                *ebpf.MapSpec `ebpf:"alloc_map"`
+	Events                  *ebpf.MapSpec `ebpf:"events"`
+	GoContextToSc           *ebpf.MapSpec `ebpf:"go_context_to_sc"`
+	GormDepths              *ebpf.MapSpec `ebpf:"gorm_depths"`
+	GormExecutionStorageMap *ebpf.MapSpec `ebpf:"gorm_execution_storage_map"`
+	GormExecutions          *ebpf.MapSpec `ebpf:"gorm_executions"`
+	ProbeActiveSamplerMap   *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
+	SamplersConfigMap       *ebpf.MapSpec `ebpf:"samplers_config_map"`
+	SliceArrayBuffMap       *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
+	SqlEvents               *ebpf.MapSpec `ebpf:"sql_events"`
+	SqlRequestStorageMap    *ebpf.MapSpec `ebpf:"sql_request_storage_map"`
+	SqlTxBegins             *ebpf.MapSpec `ebpf:"sql_tx_begins"`
+	SqlTxConns              *ebpf.MapSpec `ebpf:"sql_tx_conns"`
+	SqlTxEnds               *ebpf.MapSpec `ebpf:"sql_tx_ends"`
+	SqlTxs                  *ebpf.MapSpec `ebpf:"sql_txs"`
+	TrackedSpansBySc        *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
 }
 
 // bpfVariableSpecs contains global variables before they are loaded into the kernel.
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfVariableSpecs struct {
+	BootClockSupported       *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
 	EndAddr                  *ebpf.VariableSpec `ebpf:"end_addr"`
+	GormDbRowsAffectedPos    *ebpf.VariableSpec `ebpf:"gorm_db_rows_affected_pos"`
+	GormDbStatementPos       *ebpf.VariableSpec `ebpf:"gorm_db_statement_pos"`
+	GormProcessorClausesPos  *ebpf.VariableSpec `ebpf:"gorm_processor_clauses_pos"`
+	GormStatementSqlPos      *ebpf.VariableSpec `ebpf:"gorm_statement_sql_pos"`
+	GormStatementTablePos    *ebpf.VariableSpec `ebpf:"gorm_statement_table_pos"`
 	Hex                      *ebpf.VariableSpec `ebpf:"hex"`
 	ShouldIncludeDbStatement *ebpf.VariableSpec `ebpf:"should_include_db_statement"`
 	StartAddr                *ebpf.VariableSpec `ebpf:"start_addr"`
 	TotalCpus                *ebpf.VariableSpec `ebpf:"total_cpus"`
+	TxDcPos                  *ebpf.VariableSpec `ebpf:"tx_dc_pos"`
+	TxOptionsIsolationPos    *ebpf.VariableSpec `ebpf:"tx_options_isolation_pos"`
 }
 
 // bpfObjects contains all objects after they have been loaded into the kernel.
@@ -128,14 +175,22 @@ func (o *bpfObjects) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfMaps struct {
-	AllocMap              *ebpf.Map `ebpf:"alloc_map"`
-	Events                *ebpf.Map `ebpf:"events"`
-	GoContextToSc         *ebpf.Map `ebpf:"go_context_to_sc"`
-	ProbeActiveSamplerMap *ebpf.Map `ebpf:"probe_active_sampler_map"`
-	SamplersConfigMap     *ebpf.Map `ebpf:"samplers_config_map"`
-	SliceArrayBuffMap     *ebpf.Map `ebpf:"slice_array_buff_map"`
-	SqlEvents             *ebpf.Map `ebpf:"sql_events"`
-	TrackedSpansBySc      *ebpf.Map `ebpf:"tracked_spans_by_sc"`
+	AllocMap                *ebpf.Map `ebpf:"alloc_map"`
+	Events                  *ebpf.Map `ebpf:"events"`
+	GoContextToSc           *ebpf.Map `ebpf:"go_context_to_sc"`
+	GormDepths              *ebpf.Map `ebpf:"gorm_depths"`
+	GormExecutionStorageMap *ebpf.Map `ebpf:"gorm_execution_storage_map"`
+	GormExecutions          *ebpf.Map `ebpf:"gorm_executions"`
+	ProbeActiveSamplerMap   *ebpf.Map `ebpf:"probe_active_sampler_map"`
+	SamplersConfigMap       *ebpf.Map `ebpf:"samplers_config_map"`
+	SliceArrayBuffMap       *ebpf.Map `ebpf:"slice_array_buff_map"`
+	SqlEvents               *ebpf.Map `ebpf:"sql_events"`
+	SqlRequestStorageMap    *ebpf.Map `ebpf:"sql_request_storage_map"`
+	SqlTxBegins             *ebpf.Map `ebpf:"sql_tx_begins"`
+	SqlTxConns              *ebpf.Map `ebpf:"sql_tx_conns"`
+	SqlTxEnds               *ebpf.Map `ebpf:"sql_tx_ends"`
+	SqlTxs                  *ebpf.Map `ebpf:"sql_txs"`
+	TrackedSpansBySc        *ebpf.Map `ebpf:"tracked_spans_by_sc"`
 }
 
 func (m *bpfMaps) Close() error {
@@ -143,10 +198,18 @@ func (m *bpfMaps) Close() error {
 		m.AllocMap,
 		m.Events,
 		m.GoContextToSc,
+		m.GormDepths,
+		m.GormExecutionStorageMap,
+		m.GormExecutions,
 		m.ProbeActiveSamplerMap,
 		m.SamplersConfigMap,
 		m.SliceArrayBuffMap,
 		m.SqlEvents,
+		m.SqlRequestStorageMap,
+		m.SqlTxBegins,
+		m.SqlTxConns,
+		m.SqlTxEnds,
+		m.SqlTxs,
 		m.TrackedSpansBySc,
 	)
 }
@@ -155,27 +218,51 @@ func (m *bpfMaps) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfVariables struct {
+	BootClockSupported       *ebpf.Variable `ebpf:"boot_clock_supported"`
 	EndAddr                  *ebpf.Variable `ebpf:"end_addr"`
+	GormDbRowsAffectedPos    *ebpf.Variable `ebpf:"gorm_db_rows_affected_pos"`
+	GormDbStatementPos       *ebpf.Variable `ebpf:"gorm_db_statement_pos"`
+	GormProcessorClausesPos  *ebpf.Variable `ebpf:"gorm_processor_clauses_pos"`
+	GormStatementSqlPos      *ebpf.Variable `ebpf:"gorm_statement_sql_pos"`
+	GormStatementTablePos    *ebpf.Variable `ebpf:"gorm_statement_table_pos"`
 	Hex                      *ebpf.Variable `ebpf:"hex"`
 	ShouldIncludeDbStatement *ebpf.Variable `ebpf:"should_include_db_statement"`
 	StartAddr                *ebpf.Variable `ebpf:"start_addr"`
 	TotalCpus                *ebpf.Variable `ebpf:"total_cpus"`
+	TxDcPos                  *ebpf.Variable `ebpf:"tx_dc_pos"`
+	TxOptionsIsolationPos    *ebpf.Variable `ebpf:"tx_options_isolation_pos"`
 }
 
 // bpfPrograms contains all programs after they have been loaded into the kernel.
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfPrograms struct {
-	UprobeExecDC          *ebpf.Program `ebpf:"uprobe_execDC"`
-	UprobeExecDC_Returns  *ebpf.Program `ebpf:"uprobe_execDC_Returns"`
-	UprobeQueryDC         *ebpf.Program `ebpf:"uprobe_queryDC"`
-	UprobeQueryDC_Returns *ebpf.Program `ebpf:"uprobe_queryDC_Returns"`
+	UprobeBeginTx                 *ebpf.Program `ebpf:"uprobe_BeginTx"`
+	UprobeBeginTxReturns          *ebpf.Program `ebpf:"uprobe_BeginTx_Returns"`
+	UprobeTxCommit                *ebpf.Program `ebpf:"uprobe_Tx_Commit"`
+	UprobeTxCommitReturns         *ebpf.Program `ebpf:"uprobe_Tx_Commit_Returns"`
+	UprobeTxRollback              *ebpf.Program `ebpf:"uprobe_Tx_rollback"`
+	UprobeTxRollbackReturns       *ebpf.Program `ebpf:"uprobe_Tx_rollback_Returns"`
+	UprobeExecDC                  *ebpf.Program `ebpf:"uprobe_execDC"`
+	UprobeExecDC_Returns          *ebpf.Program `ebpf:"uprobe_execDC_Returns"`
+	UprobeProcessorExecute        *ebpf.Program `ebpf:"uprobe_processor_Execute"`
+	UprobeProcessorExecuteReturns *ebpf.Program `ebpf:"uprobe_processor_Execute_Returns"`
+	UprobeQueryDC                 *ebpf.Program `ebpf:"uprobe_queryDC"`
+	UprobeQueryDC_Returns         *ebpf.Program `ebpf:"uprobe_queryDC_Returns"`
 }
 
 func (p *bpfPrograms) Close() error {
 	return _BpfClose(
+		p.UprobeBeginTx,
+		p.UprobeBeginTxReturns,
+		p.UprobeTxCommit,
+		p.UprobeTxCommitReturns,
+		p.UprobeTxRollback,
+		p.UprobeTxRollbackReturns,
 		p.UprobeExecDC,
 		p.UprobeExecDC_Returns,
+		p.UprobeProcessorExecute,
+		p.UprobeProcessorExecuteReturns,
 		p.UprobeQueryDC,
 		p.UprobeQueryDC_Returns,
 	)
@@ -6,12 +6,14 @@
 package sql
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strconv"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/xwb1989/sqlparser"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -23,6 +25,7 @@ import (
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
 )
 
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
@@ -30,6 +33,9 @@ import (
 const (
 	// pkg is the package being instrumented.
 	pkg = "database/sql"
+	// gormPkg is the package of the GORM ORM. The operation, table, and rows
+	// affected of the query executed by its callbacks are read from it.
+	gormPkg = "gorm.io/gorm"
 
 	// IncludeDBStatementEnvVar is the environment variable to opt-in for sql query inclusion in the trace.
 	IncludeDBStatementEnvVar = "OTEL_GO_AUTO_INCLUDE_DB_STATEMENT"
@@ -38,22 +44,85 @@ const (
 	ParseDBStatementEnvVar = "OTEL_GO_AUTO_PARSE_DB_STATEMENT"
 )
 
+var (
+	// gormMinVersion is the first version of GORM the offsets of the
+	// Statement are known for.
+	gormMinVersion = semver.New(1, 25, 0, "", "")
+
+	gormSupported = probe.PackageConstraints{
+		Package: gormPkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + gormMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using GORM is expected, the queries are traced as is then.
+		FailureMode: probe.FailureModeIgnore,
+	}
+)
+
+// gormRowsAffectedKey is the attribute key of the number of rows affected by
+// the GORM operation executing a query.
+const gormRowsAffectedKey = "gorm.rows_affected"
+
+const (
+	// txIsolationLevelKey is the attribute key of the isolation level of a
+	// transaction.
+	txIsolationLevelKey = "db.transaction.isolation_level"
+	// txOutcomeKey is the attribute key of the outcome of a transaction:
+	// "commit" or "rollback".
+	txOutcomeKey = "db.transaction.outcome"
+)
+
+// The outcomes of a transaction, as reported by the eBPF program.
+const (
+	txOutcomeCommit   uint8 = 1
+	txOutcomeRollback uint8 = 2
+)
+
 // New returns a new [probe.Probe].
 func New(logger *slog.Logger, version string) probe.Probe {
 	id := probe.ID{
 		SpanKind:        trace.SpanKindClient,
 		InstrumentedPkg: pkg,
 	}
+
+	gormFieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstOptional{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(gormPkg, gormPkg, strct, field),
+			},
+			MinVersion: gormMinVersion,
+		}
+	}
+
 	return &probe.SpanProducer[bpfObjects, event]{
 		Base: probe.Base[bpfObjects, event]{
 			ID:     id,
 			Logger: logger,
 			Consts: []probe.Const{
 				probe.AllocationConst{},
+				probe.BootClockConst{},
 				probe.KeyValConst{
 					Key: "should_include_db_statement",
 					Val: shouldIncludeDBStatement(),
 				},
+				gormFieldConst("gorm_db_rows_affected_pos", "DB", "RowsAffected"),
+				gormFieldConst("gorm_db_statement_pos", "DB", "Statement"),
+				gormFieldConst("gorm_statement_table_pos", "Statement", "Table"),
+				gormFieldConst("gorm_statement_sql_pos", "Statement", "SQL"),
+				gormFieldConst("gorm_processor_clauses_pos", "processor", "Clauses"),
+				probe.StructFieldConst{
+					Key: "tx_dc_pos",
+					ID:  structfield.NewID("std", pkg, "Tx", "dc"),
+				},
+				probe.StructFieldConst{
+					Key: "tx_options_isolation_pos",
+					ID:  structfield.NewID("std", pkg, "TxOptions", "Isolation"),
+				},
 			},
 			Uprobes: []*probe.Uprobe{
 				{
@@ -68,6 +137,47 @@ func New(logger *slog.Logger, version string) probe.Probe {
 					ReturnProbe: "uprobe_execDC_Returns",
 					FailureMode: probe.FailureModeIgnore,
 				},
+				{
+					Sym:         gormPkg + ".(*processor).Execute",
+					EntryProbe:  "uprobe_processor_Execute",
+					ReturnProbe: "uprobe_processor_Execute_Returns",
+					PackageConstraints: []probe.PackageConstraints{
+						gormSupported,
+					},
+					DependsOn: []string{
+						"database/sql.(*DB).queryDC",
+						"database/sql.(*DB).execDC",
+					},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         "database/sql.(*DB).BeginTx",
+					EntryProbe:  "uprobe_BeginTx",
+					ReturnProbe: "uprobe_BeginTx_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         "database/sql.(*Conn).BeginTx",
+					EntryProbe:  "uprobe_BeginTx",
+					ReturnProbe: "uprobe_BeginTx_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         "database/sql.(*Tx).Commit",
+					EntryProbe:  "uprobe_Tx_Commit",
+					ReturnProbe: "uprobe_Tx_Commit_Returns",
+					DependsOn:   []string{"database/sql.(*DB).BeginTx", "database/sql.(*Conn).BeginTx"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// Rollback is inlined in its callers, rollback is called
+					// by it and when the context of the transaction is done.
+					Sym:         "database/sql.(*Tx).rollback",
+					EntryProbe:  "uprobe_Tx_rollback",
+					ReturnProbe: "uprobe_Tx_rollback_Returns",
+					DependsOn:   []string{"database/sql.(*DB).BeginTx", "database/sql.(*Conn).BeginTx"},
+					FailureMode: probe.FailureModeIgnore,
+				},
 			},
 
 			SpecFn: loadBpf,
@@ -83,9 +193,26 @@ func New(logger *slog.Logger, version string) probe.Probe {
 type event struct {
 	context.BaseSpanProperties
 	Query [256]byte
+	// Table, Clause, and RowsAffected are the ones of the GORM operation
+	// executing the query, if HasGorm is set.
+	Table        [64]byte
+	Clause       [8]byte
+	RowsAffected int64
+	HasGorm      uint8
+	// IsTx is set if the event is a transaction, with its Isolation level and
+	// Outcome. The query and the GORM operation are not set then.
+	IsTx      uint8
+	Isolation uint8
+	Outcome   uint8
+	HasError  uint8
+	_         [3]byte // padding
 }
 
 func processFn(e *event) ptrace.SpanSlice {
+	if e.IsTx != 0 {
+		return processTx(e)
+	}
+
 	spans := ptrace.NewSpanSlice()
 	span := spans.AppendEmpty()
 	span.SetName("DB")
@@ -130,9 +257,81 @@ func processFn(e *event) ptrace.SpanSlice {
 		}
 	}
 
+	if e.HasGorm != 0 {
+		// The operation of GORM is known, it takes precedence over the one
+		// parsed from the query.
+		operation := gormOperation(unix.ByteSliceToString(e.Clause[:]))
+		table := unix.ByteSliceToString(e.Table[:])
+		name := operation
+		if operation != "" {
+			span.Attributes().PutStr(string(semconv.DBOperationNameKey), operation)
+		}
+		if table != "" {
+			span.Attributes().PutStr(string(semconv.DBCollectionNameKey), table)
+			if name != "" {
+				name += " " + table
+			}
+		}
+		if name != "" {
+			span.SetName(name)
+		}
+		span.Attributes().PutInt(gormRowsAffectedKey, e.RowsAffected)
+	}
+
+	return spans
+}
+
+// processTx returns the span of the transaction e.
+func processTx(e *event) ptrace.SpanSlice {
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("transaction")
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	// The system of the database is the one of the driver, it is not known by
+	// database/sql.
+	span.Attributes().PutStr(string(semconv.DBSystemNameKey), semconv.DBSystemNameOtherSQL.Value.AsString())
+	span.Attributes().PutStr(txIsolationLevelKey, sql.IsolationLevel(e.Isolation).String())
+	switch e.Outcome {
+	case txOutcomeCommit:
+		span.Attributes().PutStr(txOutcomeKey, "commit")
+	case txOutcomeRollback:
+		span.Attributes().PutStr(txOutcomeKey, "rollback")
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
 	return spans
 }
 
+// gormOperation returns the GORM operation of the processor executed with the
+// first clause, or an empty string if it is not known, e.g. for raw SQL.
+func gormOperation(clause string) string {
+	switch clause {
+	case "INSERT":
+		return "create"
+	case "SELECT":
+		return "query"
+	case "UPDATE":
+		return "update"
+	case "DELETE":
+		return "delete"
+	default:
+		return ""
+	}
+}
+
 // shouldIncludeDBStatement returns if the user has configured SQL queries to be included.
 func shouldIncludeDBStatement() bool {
 	val := os.Getenv(IncludeDBStatementEnvVar)
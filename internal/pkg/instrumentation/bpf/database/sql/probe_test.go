@@ -4,6 +4,7 @@
 package sql
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -11,12 +12,14 @@ import (
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
 )
 
 func BenchmarkProcessFn(b *testing.B) {
@@ -115,3 +118,138 @@ func TestProbeConvertEvent(t *testing.T) {
 	}()
 	assert.Equal(t, want, got)
 }
+
+func TestProcessFnGorm(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+
+	newEvent := func(query, clause, table string, rows int64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			RowsAffected:       rows,
+			HasGorm:            1,
+		}
+		copy(e.Query[:], query)
+		copy(e.Clause[:], clause)
+		copy(e.Table[:], table)
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		parse bool
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "create",
+			event: newEvent("", "INSERT", "users", 1),
+			want: f.Spans(
+				"create users",
+				ptrace.StatusCodeUnset,
+				semconv.DBOperationName("create"),
+				semconv.DBCollectionName("users"),
+				attribute.Int64(gormRowsAffectedKey, 1),
+			),
+		},
+		{
+			name:  "parsed query",
+			parse: true,
+			event: newEvent("SELECT * FROM users", "SELECT", "users", 3),
+			want: f.Spans(
+				"query users",
+				ptrace.StatusCodeUnset,
+				semconv.DBQueryText("SELECT * FROM users"),
+				semconv.DBOperationName("query"),
+				semconv.DBCollectionName("users"),
+				attribute.Int64(gormRowsAffectedKey, 3),
+			),
+		},
+		{
+			name:  "raw",
+			event: newEvent("", "", "", 2),
+			want: f.Spans(
+				"DB",
+				ptrace.StatusCodeUnset,
+				attribute.Int64(gormRowsAffectedKey, 2),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ParseDBStatementEnvVar, strconv.FormatBool(tt.parse))
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestProcessFnTx(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(isolation, outcome, hasError uint8) *event {
+		return &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			IsTx:               1,
+			Isolation:          isolation,
+			Outcome:            outcome,
+			HasError:           hasError,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "commit",
+			event: newEvent(0, txOutcomeCommit, 0),
+			want: f.Spans(
+				"transaction",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameOtherSQL,
+				attribute.String(txIsolationLevelKey, "Default"),
+				attribute.String(txOutcomeKey, "commit"),
+			),
+		},
+		{
+			name:  "rollback",
+			event: newEvent(6, txOutcomeRollback, 0),
+			want: f.Spans(
+				"transaction",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameOtherSQL,
+				attribute.String(txIsolationLevelKey, "Serializable"),
+				attribute.String(txOutcomeKey, "rollback"),
+			),
+		},
+		{
+			name:  "commit error",
+			event: newEvent(2, txOutcomeCommit, 1),
+			want: f.Spans(
+				"transaction",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameOtherSQL,
+				attribute.String(txIsolationLevelKey, "Read Committed"),
+				attribute.String(txOutcomeKey, "commit"),
+			),
+		},
+		{
+			name:  "begin error",
+			event: newEvent(0, 0, 1),
+			want: f.Spans(
+				"transaction",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameOtherSQL,
+				attribute.String(txIsolationLevelKey, "Default"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
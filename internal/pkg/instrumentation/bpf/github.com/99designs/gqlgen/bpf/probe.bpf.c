@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_OPERATION_NAME_SIZE 128
+// The operation types are "query", "mutation", and "subscription".
+#define MAX_OPERATION_TYPE_SIZE 16
+// The size of the documents copied to be hashed, larger documents are not
+// hashed.
+#define MAX_DOCUMENT_SIZE 2048
+
+struct graphql_operation_t {
+    BASE_SPAN_PROPERTIES
+    char operation_name[MAX_OPERATION_NAME_SIZE];
+    char operation_type[MAX_OPERATION_TYPE_SIZE];
+    // The length of the document, it is only copied whole if it is not
+    // larger than MAX_DOCUMENT_SIZE.
+    u64 document_size;
+    char document[MAX_DOCUMENT_SIZE];
+    u8 has_operation;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// Operations being served, keyed by the goroutine serving them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct graphql_operation_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} graphql_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct graphql_operation_t));
+    __uint(max_entries, 1);
+} graphql_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 ctx_ptr_pos;
+volatile const u64 operation_context_raw_query_pos;
+volatile const u64 operation_context_operation_name_pos;
+volatile const u64 operation_context_operation_pos;
+volatile const u64 operation_definition_operation_pos;
+volatile const u64 operation_definition_name_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request)
+SEC("uprobe/Server_ServeHTTP")
+int uprobe_Server_ServeHTTP(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&graphql_events, &key) != NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct graphql_operation_t *operation = bpf_map_lookup_elem(&graphql_storage_map, &zero);
+    if (operation == NULL) {
+        bpf_printk("uprobe/Server_ServeHTTP: operation is NULL");
+        return 0;
+    }
+    __builtin_memset(operation, 0, sizeof(struct graphql_operation_t));
+    operation->start_time = get_time_ns();
+
+    // The span of the HTTP server serving the request, if any, is tracked
+    // with the context of the request.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 4, ctx_ptr_pos, false, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &operation->psc,
+        .sc = &operation->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&graphql_events, &key, operation, 0);
+    start_tracking_span(go_context.data, &operation->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request)
+//
+// The Server recovers from the panics of the operations, the function returns
+// once the response is written, including the response to a panic.
+SEC("uprobe/Server_ServeHTTP")
+int uprobe_Server_ServeHTTP_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct graphql_operation_t *operation = bpf_map_lookup_elem(&graphql_events, &key);
+    if (operation == NULL) {
+        return 0;
+    }
+    operation->end_time = end_time;
+
+    // Requests not creating an operation, e.g. with an unsupported transport,
+    // are not traced.
+    if (operation->has_operation) {
+        output_span_event(ctx, operation, sizeof(*operation), &operation->sc);
+    }
+
+    stop_tracking_span(&operation->sc, &operation->psc);
+    bpf_map_delete_elem(&graphql_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (e *Executor) CreateOperationContext(ctx context.Context, params *graphql.RawParams) (*graphql.OperationContext, gqlerror.List)
+SEC("uprobe/Executor_CreateOperationContext")
+int uprobe_Executor_CreateOperationContext_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct graphql_operation_t *operation = bpf_map_lookup_elem(&graphql_events, &key);
+    if (operation == NULL || operation->has_operation) {
+        return 0;
+    }
+
+    void *op_ctx = get_argument(ctx, 1);
+    if (op_ctx == NULL) {
+        return 0;
+    }
+    operation->has_operation = 1;
+
+    // The gqlerror.List of the parsing and validation errors.
+    u64 errors_len = (u64)get_argument(ctx, 3);
+    if (errors_len > 0) {
+        operation->has_error = 1;
+    }
+
+    struct go_string document = {0};
+    if (!bpf_probe_read_user(&document, sizeof(document), (void *)(op_ctx + operation_context_raw_query_pos))) {
+        operation->document_size = document.len;
+        if (document.len > 0 && document.len <= MAX_DOCUMENT_SIZE) {
+            bpf_probe_read_user(operation->document, document.len, document.str);
+        }
+    }
+
+    // The operation definition is not found if the document is invalid, the
+    // name requested is used instead.
+    void *definition = NULL;
+    bpf_probe_read_user(&definition, sizeof(definition), (void *)(op_ctx + operation_context_operation_pos));
+    if (definition == NULL) {
+        get_go_string_from_user_ptr((void *)(op_ctx + operation_context_operation_name_pos), operation->operation_name, sizeof(operation->operation_name));
+        return 0;
+    }
+    get_go_string_from_user_ptr((void *)(definition + operation_definition_operation_pos), operation->operation_type, sizeof(operation->operation_type));
+    get_go_string_from_user_ptr((void *)(definition + operation_definition_name_pos), operation->operation_name, sizeof(operation->operation_name));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (e *Executor) PresentRecoveredError(ctx context.Context, err any) error
+//
+// It is only called by the Server when it recovers from a panic.
+SEC("uprobe/Executor_PresentRecoveredError")
+int uprobe_Executor_PresentRecoveredError(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct graphql_operation_t *operation = bpf_map_lookup_elem(&graphql_events, &key);
+    if (operation == NULL) {
+        return 0;
+    }
+    operation->has_error = 1;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (t Websocket) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor)
+//
+// The operations of a websocket connection are all created by the goroutine
+// serving it, they are not traced.
+SEC("uprobe/Websocket_Do")
+int uprobe_Websocket_Do(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct graphql_operation_t *operation = bpf_map_lookup_elem(&graphql_events, &key);
+    if (operation == NULL) {
+        return 0;
+    }
+    stop_tracking_span(&operation->sc, &operation->psc);
+    bpf_map_delete_elem(&graphql_events, &key);
+    return 0;
+}
@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gqlgen provides an instrumentation probe for GraphQL servers using
+// the [github.com/99designs/gqlgen/graphql/handler] package.
+package gqlgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/99designs/gqlgen/graphql/handler"
+	// mod is the module of the package being instrumented.
+	mod = "github.com/99designs/gqlgen"
+	// parserMod is the module of the GraphQL parser used by gqlgen.
+	parserMod = "github.com/vektah/gqlparser/v2"
+)
+
+// documentHashKey is the attribute key of the SHA-256 hash of the document of
+// an operation, in hexadecimal. It is the hash of the automatic persisted
+// queries of gqlgen.
+const documentHashKey = attribute.Key("graphql.document.hash")
+
+// minVersion is the first version supported by the probe.
+var minVersion = semver.New(0, 17, 0, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: mod,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	opCtxConst := func(key, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(mod, mod+"/graphql", "OperationContext", field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "ctx_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "ctx"),
+				},
+				opCtxConst("operation_context_raw_query_pos", "RawQuery"),
+				opCtxConst("operation_context_operation_name_pos", "OperationName"),
+				opCtxConst("operation_context_operation_pos", "Operation"),
+				probe.StructFieldConst{
+					Key: "operation_definition_operation_pos",
+					ID:  structfield.NewID(parserMod, parserMod+"/ast", "OperationDefinition", "Operation"),
+				},
+				probe.StructFieldConst{
+					Key: "operation_definition_name_pos",
+					ID:  structfield.NewID(parserMod, parserMod+"/ast", "OperationDefinition", "Name"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*Server).ServeHTTP",
+					EntryProbe:         "uprobe_Server_ServeHTTP",
+					ReturnProbe:        "uprobe_Server_ServeHTTP_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                mod + "/graphql/executor.(*Executor).CreateOperationContext",
+					ReturnProbe:        "uprobe_Executor_CreateOperationContext_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                mod + "/graphql/executor.(*Executor).PresentRecoveredError",
+					EntryProbe:         "uprobe_Executor_PresentRecoveredError",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					// Not linked if the websocket transport is not used.
+					Sym:                mod + "/graphql/handler/transport.Websocket.Do",
+					EntryProbe:         "uprobe_Websocket_Do",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a GraphQL operation served by a gqlgen Server.
+type event struct {
+	context.BaseSpanProperties
+	OperationName [128]byte
+	OperationType [16]byte
+	// DocumentSize is the size of the document of the operation, Document
+	// only holds it if it is not larger.
+	DocumentSize uint64
+	Document     [2048]byte
+	HasOperation uint8
+	HasError     uint8
+	_            [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	var attrs []attribute.KeyValue
+
+	opType := unix.ByteSliceToString(e.OperationType[:])
+	if kv, ok := operationType(opType); ok {
+		attrs = append(attrs, kv)
+	} else {
+		opType = ""
+	}
+	opName := unix.ByteSliceToString(e.OperationName[:])
+	if opName != "" {
+		attrs = append(attrs, semconv.GraphqlOperationName(opName))
+	}
+	if e.DocumentSize > 0 && e.DocumentSize <= uint64(len(e.Document)) {
+		sum := sha256.Sum256(e.Document[:e.DocumentSize])
+		attrs = append(attrs, documentHashKey.String(hex.EncodeToString(sum[:])))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(opType, opName))
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// operationType returns the graphql.operation.type attribute of the operation
+// type t of a GraphQL document, and false if t is unknown.
+func operationType(t string) (attribute.KeyValue, bool) {
+	switch t {
+	case "query":
+		return semconv.GraphqlOperationTypeQuery, true
+	case "mutation":
+		return semconv.GraphqlOperationTypeMutation, true
+	case "subscription":
+		return semconv.GraphqlOperationTypeSubscription, true
+	default:
+		return attribute.KeyValue{}, false
+	}
+}
+
+// spanName returns the name of the span of an operation, e.g. "query GetUser".
+func spanName(opType, opName string) string {
+	switch {
+	case opType == "":
+		return "GraphQL Operation"
+	case opName == "":
+		return opType
+	default:
+		return opType + " " + opName
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gqlgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+
+	const document = "query GetUser { user(id: 1) { name } }"
+	// The SHA-256 hash of document.
+	const documentHash = "165eedf1b39a50b5057155306f1af5a2f4ad2490a64b4ec78c4d52f1f288c403"
+
+	newEvent := func(opType, opName, doc string, docSize uint64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			DocumentSize:       docSize,
+			HasOperation:       1,
+		}
+		copy(e.OperationType[:], opType)
+		copy(e.OperationName[:], opName)
+		copy(e.Document[:], doc)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "query",
+			event: newEvent("query", "GetUser", document, uint64(len(document)), false),
+			want: f.Spans(
+				"query GetUser",
+				ptrace.StatusCodeUnset,
+				semconv.GraphqlOperationTypeQuery,
+				semconv.GraphqlOperationName("GetUser"),
+				documentHashKey.String(documentHash),
+			),
+		},
+		{
+			name:  "anonymous mutation",
+			event: newEvent("mutation", "", "mutation { reset }", 0, false),
+			want: f.Spans(
+				"mutation",
+				ptrace.StatusCodeUnset,
+				semconv.GraphqlOperationTypeMutation,
+			),
+		},
+		{
+			name:  "large document",
+			event: newEvent("subscription", "OnUser", strings.Repeat(" ", 2048), 4096, false),
+			want: f.Spans(
+				"subscription OnUser",
+				ptrace.StatusCodeUnset,
+				semconv.GraphqlOperationTypeSubscription,
+				semconv.GraphqlOperationName("OnUser"),
+			),
+		},
+		{
+			name:  "invalid document",
+			event: newEvent("", "GetUser", "", 0, true),
+			want: f.Spans(
+				"GraphQL Operation",
+				ptrace.StatusCodeError,
+				semconv.GraphqlOperationName("GetUser"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_net.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_QUERY_SIZE 256
+#define MAX_DATABASE_SIZE 64
+#define MAX_CONCURRENT 50
+#define MAX_BATCHES 1024
+
+struct clickhouse_request_t {
+    BASE_SPAN_PROPERTIES
+    char query[MAX_QUERY_SIZE];
+    char database[MAX_DATABASE_SIZE];
+    net_addr_t server_addr;
+    // Number of rows appended to a batch since it was last sent.
+    u64 rows;
+    u8 is_batch;
+    u8 has_error;
+    u8 padding[6];
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct clickhouse_request_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} clickhouse_events SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(key_size, sizeof(u32));
+	__uint(value_size, sizeof(struct clickhouse_request_t));
+	__uint(max_entries, 1);
+} clickhouse_storage_map SEC(".maps");
+
+// Number of rows appended to the batches, by batch pointer. Batches that are
+// never sent are evicted.
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__type(key, void*);
+	__type(value, u64);
+	__uint(max_entries, MAX_BATCHES);
+} clickhouse_batch_rows SEC(".maps");
+
+// Injected in init
+volatile const bool should_include_db_statement;
+// True if the query method of the connect takes a release function argument
+// before the query (clickhouse-go >= 2.0.3).
+volatile const bool query_release_arg;
+
+volatile const u64 connect_opt_pos;
+volatile const u64 connect_conn_pos;
+volatile const u64 options_auth_pos;
+volatile const u64 auth_database_pos;
+volatile const u64 batch_ctx_pos;
+volatile const u64 batch_conn_pos;
+// Zero if the batch does not hold its query (clickhouse-go < 2.12.1).
+volatile const u64 batch_query_pos;
+volatile const u64 conn_fd_pos;
+volatile const u64 net_fd_raddr_pos;
+
+// Reads the remote address of the net.Conn of the connect pointed to by
+// connect_ptr into addr. Only TCP connections are supported.
+static __always_inline long read_server_addr(struct pt_regs *ctx, void *connect_ptr, net_addr_t *addr) {
+    // The net.Conn is expected to be a *net.TCPConn, which embeds a net.conn
+    // holding the *net.netFD of the connection.
+    void *conn_ptr = NULL;
+    long res = bpf_probe_read_user(&conn_ptr, sizeof(conn_ptr), get_go_interface_instance(connect_ptr + connect_conn_pos));
+    if (res != 0 || conn_ptr == NULL) {
+        return -1;
+    }
+
+    void *fd_ptr = NULL;
+    res = bpf_probe_read_user(&fd_ptr, sizeof(fd_ptr), (void *)(conn_ptr + conn_fd_pos));
+    if (res != 0 || fd_ptr == NULL) {
+        return -1;
+    }
+
+    void *raddr_ptr = NULL;
+    res = bpf_probe_read_user(&raddr_ptr, sizeof(raddr_ptr), get_go_interface_instance(fd_ptr + net_fd_raddr_pos));
+    if (res != 0 || raddr_ptr == NULL) {
+        return -1;
+    }
+
+    return get_tcp_net_addr_from_tcp_addr(ctx, addr, raddr_ptr);
+}
+
+// Reads the database and server address of the connect pointed to by
+// connect_ptr into req.
+static __always_inline void read_connect(struct pt_regs *ctx, void *connect_ptr, struct clickhouse_request_t *req) {
+    if (connect_ptr == NULL) {
+        return;
+    }
+
+    void *opt_ptr = NULL;
+    long res = bpf_probe_read_user(&opt_ptr, sizeof(opt_ptr), (void *)(connect_ptr + connect_opt_pos));
+    if (res == 0 && opt_ptr != NULL) {
+        get_go_string_from_user_ptr((void *)(opt_ptr + options_auth_pos + auth_database_pos), req->database, sizeof(req->database));
+    }
+
+    read_server_addr(ctx, connect_ptr, &req->server_addr);
+}
+
+// Reads the query string at query_ptr of length query_len into req, if
+// configured to be included.
+static __always_inline void read_query(void *query_ptr, u64 query_len, struct clickhouse_request_t *req) {
+    if (!should_include_db_statement || query_ptr == NULL) {
+        return;
+    }
+    u64 query_size = MAX_QUERY_SIZE < query_len ? MAX_QUERY_SIZE : query_len;
+    bpf_probe_read_user(req->query, query_size, query_ptr);
+}
+
+// Returns a zeroed request from the per-CPU storage, with its start time set.
+static __always_inline struct clickhouse_request_t *new_request() {
+    u32 map_id = 0;
+    struct clickhouse_request_t *req = bpf_map_lookup_elem(&clickhouse_storage_map, &map_id);
+    if (req == NULL) {
+        return NULL;
+    }
+    __builtin_memset(req, 0, sizeof(*req));
+    req->start_time = get_time_ns();
+    return req;
+}
+
+// Starts the span of req, child of the span of the go_context, and stores it
+// for the goroutine.
+static __always_inline void start_clickhouse_span(struct pt_regs *ctx, struct go_iface *go_context, struct clickhouse_request_t *req) {
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&clickhouse_events, &key, req, 0);
+}
+
+// Ends the span of the goroutine. The returned error is the interface in the
+// err_pos (type) and err_pos+1 (data) registers.
+static __always_inline int end_clickhouse_span(struct pt_regs *ctx, u64 err_pos) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct clickhouse_request_t *req = bpf_map_lookup_elem(&clickhouse_events, &key);
+    if (req == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, err_pos) != NULL) {
+        req->has_error = 1;
+    }
+
+    req->end_time = get_time_ns();
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    stop_tracking_span(&req->sc, &req->psc);
+    bpf_map_delete_elem(&clickhouse_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *connect) exec(ctx context.Context, query string, args ...any) error
+SEC("uprobe/connect_exec")
+int uprobe_connect_exec(struct pt_regs *ctx) {
+    u64 connect_ptr_pos = 1;
+    u64 query_str_ptr_pos = 4;
+    u64 query_str_len_pos = 5;
+
+    struct clickhouse_request_t *req = new_request();
+    if (req == NULL) {
+        return 0;
+    }
+    read_query(get_argument(ctx, query_str_ptr_pos), (u64)get_argument(ctx, query_str_len_pos), req);
+    read_connect(ctx, get_argument(ctx, connect_ptr_pos), req);
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_clickhouse_span(ctx, &go_context, req);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *connect) exec(ctx context.Context, query string, args ...any) error
+SEC("uprobe/connect_exec")
+int uprobe_connect_exec_Returns(struct pt_regs *ctx) {
+    return end_clickhouse_span(ctx, 1);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *connect) query(ctx context.Context, release nativeTransportRelease, query string, args ...any) (*rows, error)
+//
+// The release argument is missing before clickhouse-go 2.0.3.
+SEC("uprobe/connect_query")
+int uprobe_connect_query(struct pt_regs *ctx) {
+    u64 connect_ptr_pos = 1;
+    u64 query_str_ptr_pos = 4;
+    if (query_release_arg) {
+        query_str_ptr_pos = 5;
+    }
+
+    struct clickhouse_request_t *req = new_request();
+    if (req == NULL) {
+        return 0;
+    }
+    read_query(get_argument(ctx, query_str_ptr_pos), (u64)get_argument(ctx, query_str_ptr_pos + 1), req);
+    read_connect(ctx, get_argument(ctx, connect_ptr_pos), req);
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_clickhouse_span(ctx, &go_context, req);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *connect) query(ctx context.Context, release nativeTransportRelease, query string, args ...any) (*rows, error)
+//
+// The query returns once the first block of the result is received, the
+// *rows precede the error.
+SEC("uprobe/connect_query")
+int uprobe_connect_query_Returns(struct pt_regs *ctx) {
+    return end_clickhouse_span(ctx, 2);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (b *batch) Append(v ...any) error
+//
+// AppendStruct appends the values of its struct with Append.
+SEC("uprobe/batch_Append")
+int uprobe_batch_Append(struct pt_regs *ctx) {
+    u64 batch_ptr_pos = 1;
+    void *batch_ptr = get_argument(ctx, batch_ptr_pos);
+
+    u64 *rows = bpf_map_lookup_elem(&clickhouse_batch_rows, &batch_ptr);
+    if (rows != NULL) {
+        __sync_fetch_and_add(rows, 1);
+        return 0;
+    }
+    u64 one = 1;
+    bpf_map_update_elem(&clickhouse_batch_rows, &batch_ptr, &one, BPF_ANY);
+    return 0;
+}
+
+// Starts the span of the rows of the batch pointed to by the first argument
+// sent to the server. Both of the instrumented functions send the rows
+// appended since the batch was last sent.
+static __always_inline int start_batch_span(struct pt_regs *ctx) {
+    u64 batch_ptr_pos = 1;
+    void *batch_ptr = get_argument(ctx, batch_ptr_pos);
+    if (batch_ptr == NULL) {
+        return 0;
+    }
+
+    struct clickhouse_request_t *req = new_request();
+    if (req == NULL) {
+        return 0;
+    }
+    req->is_batch = 1;
+
+    u64 *rows = bpf_map_lookup_elem(&clickhouse_batch_rows, &batch_ptr);
+    if (rows != NULL) {
+        req->rows = *rows;
+        bpf_map_delete_elem(&clickhouse_batch_rows, &batch_ptr);
+    }
+
+    if (batch_query_pos != 0) {
+        struct go_string query = {0};
+        if (bpf_probe_read_user(&query, sizeof(query), (void *)(batch_ptr + batch_query_pos)) == 0) {
+            read_query(query.str, query.len, req);
+        }
+    }
+
+    void *connect_ptr = NULL;
+    bpf_probe_read_user(&connect_ptr, sizeof(connect_ptr), (void *)(batch_ptr + batch_conn_pos));
+    read_connect(ctx, connect_ptr, req);
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, batch_ptr_pos, batch_ctx_pos, false, &go_context);
+    start_clickhouse_span(ctx, &go_context, req);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (b *batch) Send() (err error)
+SEC("uprobe/batch_Send")
+int uprobe_batch_Send(struct pt_regs *ctx) {
+    return start_batch_span(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (b *batch) Send() (err error)
+SEC("uprobe/batch_Send")
+int uprobe_batch_Send_Returns(struct pt_regs *ctx) {
+    return end_clickhouse_span(ctx, 1);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (b *batch) Flush() error
+SEC("uprobe/batch_Flush")
+int uprobe_batch_Flush(struct pt_regs *ctx) {
+    return start_batch_span(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (b *batch) Flush() error
+SEC("uprobe/batch_Flush")
+int uprobe_batch_Flush_Returns(struct pt_regs *ctx) {
+    return end_clickhouse_span(ctx, 1);
+}
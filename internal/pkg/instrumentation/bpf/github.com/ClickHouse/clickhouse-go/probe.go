@@ -0,0 +1,265 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clickhouse provides an instrumentation probe for ClickHouse clients
+// using the native protocol of the [github.com/ClickHouse/clickhouse-go/v2]
+// package.
+package clickhouse
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/ClickHouse/clickhouse-go/v2"
+
+// batchRowsKey is the attribute key of the number of rows sent by a batch.
+const batchRowsKey = attribute.Key("clickhouse.batch.rows")
+
+var (
+	// minVersion is the first release of the github.com/ClickHouse/clickhouse-go/v2
+	// module.
+	minVersion = semver.New(2, 0, 1, "", "")
+	// releaseArgVersion is the version the query method of the connect
+	// started taking a release function argument.
+	releaseArgVersion = semver.New(2, 0, 3, "", "")
+	// flushVersion is the version the Flush method was added to batches.
+	flushVersion = semver.New(2, 3, 0, "", "")
+	// batchQueryVersion is the version the query was added to batches.
+	batchQueryVersion = semver.New(2, 12, 1, "", "")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	constraint := func(minVer *semver.Version) probe.PackageConstraints {
+		return probe.PackageConstraints{
+			Package: pkg,
+			Constraints: func() *semver.Constraints {
+				c, err := semver.NewConstraint(">= " + minVer.String())
+				if err != nil {
+					panic(err)
+				}
+				return c
+			}(),
+			FailureMode: probe.FailureModeIgnore,
+		}
+	}
+	supported := constraint(minVersion)
+
+	fieldConst := func(key, strct, field string, minVer *semver.Version) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, strct, field),
+			},
+			MinVersion: minVer,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{
+					Key: "should_include_db_statement",
+					Val: shouldIncludeDBStatement(),
+				},
+				fieldConst("connect_opt_pos", "connect", "opt", minVersion),
+				fieldConst("connect_conn_pos", "connect", "conn", minVersion),
+				fieldConst("options_auth_pos", "Options", "Auth", minVersion),
+				fieldConst("auth_database_pos", "Auth", "Database", minVersion),
+				fieldConst("batch_ctx_pos", "batch", "ctx", minVersion),
+				fieldConst("batch_conn_pos", "batch", "conn", minVersion),
+				fieldConst("batch_query_pos", "batch", "query", batchQueryVersion),
+				probe.StructFieldConst{
+					Key: "conn_fd_pos",
+					ID:  structfield.NewID("std", "net", "conn", "fd"),
+				},
+				probe.StructFieldConst{
+					Key: "net_fd_raddr_pos",
+					ID:  structfield.NewID("std", "net", "netFD", "raddr"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_IP_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "IP"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Port_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Port"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Zone_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Zone"),
+				},
+				releaseArgConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*connect).exec",
+					EntryProbe:         "uprobe_connect_exec",
+					ReturnProbe:        "uprobe_connect_exec_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*connect).query",
+					EntryProbe:         "uprobe_connect_query",
+					ReturnProbe:        "uprobe_connect_query_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*batch).Append",
+					EntryProbe:         "uprobe_batch_Append",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*batch).Send",
+					EntryProbe:         "uprobe_batch_Send",
+					ReturnProbe:        "uprobe_batch_Send_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*batch).Flush",
+					EntryProbe:         "uprobe_batch_Flush",
+					ReturnProbe:        "uprobe_batch_Flush_Returns",
+					PackageConstraints: []probe.PackageConstraints{constraint(flushVersion)},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// releaseArgConst is a Probe Const defining whether the query method of the
+// connect takes a release function argument.
+type releaseArgConst struct{}
+
+func (c releaseArgConst) InjectOption(info *process.Info) (inject.Option, error) {
+	ver, ok := info.Modules[pkg]
+	if !ok {
+		return nil, fmt.Errorf("unknown module version: %s", pkg)
+	}
+	return inject.WithKeyValue("query_release_arg", ver.GreaterThanEqual(releaseArgVersion)), nil
+}
+
+// event represents a query, or rows of a batch, sent by a connection.
+type event struct {
+	context.BaseSpanProperties
+	// Query is the query text, if configured to be included.
+	Query [256]byte
+	// Database is the database of the connection options.
+	Database [64]byte
+	// ServerAddr is the remote address of the connection.
+	ServerAddr netattr.NetAddr
+	// Rows is the number of rows appended to a batch since it was last sent.
+	Rows     uint64
+	IsBatch  uint8
+	HasError uint8
+	_        [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameClickhouse}
+
+	name := semconv.DBSystemNameClickhouse.Value.AsString()
+	query := unix.ByteSliceToString(e.Query[:])
+	if query != "" {
+		attrs = append(attrs, semconv.DBQueryText(query))
+
+		if shouldParseDBStatement() {
+			operation, target, err := sql.Parse(query)
+			if err == nil && operation != "" {
+				attrs = append(attrs, semconv.DBOperationName(operation))
+				name = operation
+				if target != "" {
+					attrs = append(attrs, semconv.DBCollectionName(target))
+					name += " " + target
+				}
+			}
+		}
+	}
+
+	if db := unix.ByteSliceToString(e.Database[:]); db != "" {
+		attrs = append(attrs, semconv.DBNamespace(db))
+	}
+
+	if e.IsBatch != 0 {
+		attrs = append(attrs, batchRowsKey.Int64(int64(min(e.Rows, math.MaxInt64)))) // nolint: gosec  // Bounded.
+	}
+
+	server := e.ServerAddr.Addr()
+	attrs = append(attrs, netattr.Attributes(server, server)...)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// shouldIncludeDBStatement returns if the user has configured SQL queries to
+// be included.
+func shouldIncludeDBStatement() bool {
+	return envBool(sql.IncludeDBStatementEnvVar)
+}
+
+// shouldParseDBStatement returns if the user has configured SQL queries to be
+// parsed for their operation and table.
+func shouldParseDBStatement() bool {
+	return envBool(sql.ParseDBStatementEnvVar)
+}
+
+func envBool(key string) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && val
+}
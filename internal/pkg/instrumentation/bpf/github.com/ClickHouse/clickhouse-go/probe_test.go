@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(query, database string, ip []byte, port int32) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.Query[:], query)
+		copy(e.Database[:], database)
+		copy(e.ServerAddr.IP[:], ip)
+		e.ServerAddr.IPLen = uint8(len(ip)) // nolint: gosec  // Bounded.
+		e.ServerAddr.Port = port
+		return e
+	}
+
+	newBatchEvent := func(query string, rows uint64, hasError bool) *event {
+		e := newEvent(query, "default", []byte{127, 0, 0, 1}, 9000)
+		e.IsBatch = 1
+		e.Rows = rows
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	localhost := []attribute.KeyValue{
+		semconv.NetworkPeerAddress("127.0.0.1"),
+		semconv.NetworkPeerPort(9000),
+		semconv.ServerAddress("127.0.0.1"),
+		semconv.ServerPort(9000),
+		semconv.NetworkTransportTCP,
+	}
+
+	tests := []struct {
+		name  string
+		parse bool
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "query",
+			event: newEvent("SELECT * FROM events", "default", []byte{127, 0, 0, 1}, 9000),
+			want: f.Spans(
+				"clickhouse",
+				ptrace.StatusCodeUnset,
+				append([]attribute.KeyValue{
+					semconv.DBSystemNameClickhouse,
+					semconv.DBQueryText("SELECT * FROM events"),
+					semconv.DBNamespace("default"),
+				}, localhost...)...,
+			),
+		},
+		{
+			name:  "parsed",
+			parse: true,
+			event: newEvent("SELECT * FROM events", "", []byte{10, 0, 0, 1}, 9440),
+			want: f.Spans(
+				"SELECT events",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameClickhouse,
+				semconv.DBQueryText("SELECT * FROM events"),
+				semconv.DBOperationName("SELECT"),
+				semconv.DBCollectionName("events"),
+				semconv.NetworkPeerAddress("10.0.0.1"),
+				semconv.NetworkPeerPort(9440),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(9440),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "batch",
+			event: newBatchEvent("INSERT INTO events", 3, false),
+			want: f.Spans(
+				"clickhouse",
+				ptrace.StatusCodeUnset,
+				append([]attribute.KeyValue{
+					semconv.DBSystemNameClickhouse,
+					semconv.DBQueryText("INSERT INTO events"),
+					semconv.DBNamespace("default"),
+					batchRowsKey.Int64(3),
+				}, localhost...)...,
+			),
+		},
+		{
+			name:  "batch error",
+			event: newBatchEvent("", 0, true),
+			want: f.Spans(
+				"clickhouse",
+				ptrace.StatusCodeError,
+				append([]attribute.KeyValue{
+					semconv.DBSystemNameClickhouse,
+					semconv.DBNamespace("default"),
+					batchRowsKey.Int64(0),
+				}, localhost...)...,
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", "", nil, 0),
+			want:  f.Spans("clickhouse", ptrace.StatusCodeUnset, semconv.DBSystemNameClickhouse),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.parse {
+				t.Setenv(sql.ParseDBStatementEnvVar, "true")
+			}
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// https://github.com/apache/kafka/blob/0.10.2/core/src/main/scala/kafka/common/Topic.scala#L30C3-L30C34
+#define MAX_TOPIC_SIZE 256
+// No constraint on the key size, but we must have a limit for the verifier
+#define MAX_KEY_SIZE 256
+// The number of messages of a fetch response a span is created for, we must
+// have a limit for the verifier.
+#define MAX_MESSAGES 10
+#define MAX_HEADERS 10
+
+struct kafka_request_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    char key[MAX_KEY_SIZE];
+    s64 offset;
+    s64 partition;
+};
+
+// Start time of the fetch responses being parsed, keyed by the goroutine.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, u64);
+	__uint(max_entries, MAX_CONCURRENT);
+} parse_start_times SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct kafka_request_t));
+    __uint(max_entries, 2);
+} kafka_request_storage_map SEC(".maps");
+
+// https://github.com/IBM/sarama/blob/v1.45.0/record.go#L15
+struct record_header_t {
+    struct go_slice key;
+    struct go_slice value;
+};
+
+// Injected in init
+volatile const u64 message_headers_pos;
+volatile const u64 message_key_pos;
+volatile const u64 message_topic_pos;
+volatile const u64 message_partition_pos;
+volatile const u64 message_offset_pos;
+
+static __always_inline long extract_span_context_from_headers(void *message, struct span_context *parent_span_context) {
+    // Read the headers slice descriptor, its items are *RecordHeader.
+    struct go_slice headers_slice = {0};
+    bpf_probe_read_user(&headers_slice, sizeof(headers_slice), (void *)(message + message_headers_pos));
+
+    char key[W3C_KEY_LENGTH] = "traceparent";
+    char current_key[W3C_KEY_LENGTH];
+
+    for (u64 i = 0; i < headers_slice.len; i++) {
+        if (i >= MAX_HEADERS) {
+            break;
+        }
+        void *header_ptr = NULL;
+        bpf_probe_read_user(&header_ptr, sizeof(header_ptr), headers_slice.array + (i * sizeof(header_ptr)));
+        if (header_ptr == NULL) {
+            continue;
+        }
+        struct record_header_t header = {0};
+        bpf_probe_read_user(&header, sizeof(header), header_ptr);
+        // Check if it is the traceparent header
+        if (header.key.len == W3C_KEY_LENGTH && header.value.len == W3C_VAL_LENGTH) {
+            bpf_probe_read_user(current_key, sizeof(current_key), header.key.array);
+            if (bpf_memcmp(key, current_key, sizeof(key))) {
+                // Found the traceparent header, extract the span context
+                char val[W3C_VAL_LENGTH];
+                bpf_probe_read_user(val, W3C_VAL_LENGTH, header.value.array);
+                w3c_string_to_span_context(val, parent_span_context);
+                return 0;
+            }
+        }
+    }
+
+    return -1;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (child *partitionConsumer) parseResponse(response *FetchResponse) ([]*ConsumerMessage, error)
+SEC("uprobe/partitionConsumer_parseResponse")
+int uprobe_partitionConsumer_parseResponse(struct pt_regs *ctx) {
+    void *goroutine = (void *)GOROUTINE(ctx);
+    u64 start_time = get_time_ns();
+    bpf_map_update_elem(&parse_start_times, &goroutine, &start_time, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (child *partitionConsumer) parseResponse(response *FetchResponse) ([]*ConsumerMessage, error)
+SEC("uprobe/partitionConsumer_parseResponse")
+int uprobe_partitionConsumer_parseResponse_Returns(struct pt_regs *ctx) {
+    /* The parsed messages are sent on the channel read by the
+    PartitionConsumer, or the ConsumerGroupClaim, of the user. A span is created
+    for each message received, it starts when the fetch response is parsed. The
+    consumer does not accept a context.Context, the parent span is only the one
+    of the producer propagated in the message headers. */
+    u64 end_time = get_time_ns();
+    void *goroutine = (void *)GOROUTINE(ctx);
+    u64 *start_time = bpf_map_lookup_elem(&parse_start_times, &goroutine);
+    if (start_time == NULL) {
+        return 0;
+    }
+    u64 start = *start_time;
+    bpf_map_delete_elem(&parse_start_times, &goroutine);
+
+    void *msgs_array = get_argument(ctx, 1);
+    u64 msgs_array_len = (u64)get_argument(ctx, 2);
+
+    u32 zero_id = 0;
+    struct kafka_request_t *zero_kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &zero_id);
+    if (zero_kafka_request == NULL) {
+        bpf_printk("uprobe/partitionConsumer_parseResponse: zero_kafka_request is NULL");
+        return 0;
+    }
+
+    u32 actual_id = 1;
+    struct go_iface go_context = {0};
+    for (u64 i = 0; i < MAX_MESSAGES; i++) {
+        if (i >= msgs_array_len) {
+            break;
+        }
+        void *message = NULL;
+        bpf_probe_read_user(&message, sizeof(message), msgs_array + (i * sizeof(message)));
+        if (message == NULL) {
+            continue;
+        }
+
+        // Zero the span we are about to build, eBPF doesn't support memset of large structs (more than 1024 bytes)
+        bpf_map_update_elem(&kafka_request_storage_map, &actual_id, zero_kafka_request, BPF_ANY);
+        struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &actual_id);
+        if (kafka_request == NULL) {
+            return 0;
+        }
+        kafka_request->start_time = start;
+        kafka_request->end_time = end_time;
+
+        // Get the parent span context from the message headers
+        start_span_params_t start_span_params = {
+            .ctx = ctx,
+            .sc = &kafka_request->sc,
+            .psc = &kafka_request->psc,
+            .go_context = &go_context,
+            .get_parent_span_context_fn = extract_span_context_from_headers,
+            .get_parent_span_context_arg = message,
+        };
+        start_span(&start_span_params);
+
+        get_go_string_from_user_ptr((void *)(message + message_topic_pos), kafka_request->topic, sizeof(kafka_request->topic));
+        // Key is a byte slice, it starts with the pointer to, and the length
+        // of, the key as a string does.
+        get_go_string_from_user_ptr((void *)(message + message_key_pos), kafka_request->key, sizeof(kafka_request->key));
+        s32 partition = 0;
+        bpf_probe_read_user(&partition, sizeof(partition), (void *)(message + message_partition_pos));
+        kafka_request->partition = partition;
+        bpf_probe_read_user(&kafka_request->offset, sizeof(kafka_request->offset), (void *)(message + message_offset_pos));
+
+        output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
+    }
+    return 0;
+}
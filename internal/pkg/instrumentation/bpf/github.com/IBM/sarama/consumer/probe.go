@@ -0,0 +1,159 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consumer provides instrumentation probes for Kafka consumers using
+// the [github.com/IBM/sarama] package, or the [github.com/Shopify/sarama]
+// package it was published as before v1.40.0.
+package consumer
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkgIBM is the package being instrumented by the probe returned by New.
+	pkgIBM = "github.com/IBM/sarama"
+	// pkgShopify is the package being instrumented by the probe returned by
+	// NewShopify.
+	pkgShopify = "github.com/Shopify/sarama"
+)
+
+var (
+	// minVersionIBM is the first release of the github.com/IBM/sarama
+	// module.
+	minVersionIBM = semver.New(1, 40, 0, "", "")
+	// minVersionShopify is the first version of the github.com/Shopify/sarama
+	// module with known offsets. Older versions are not instrumented.
+	minVersionShopify = semver.New(1, 21, 0, "", "")
+)
+
+// New returns a new [probe.Probe] for the github.com/IBM/sarama module.
+func New(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgIBM, minVersionIBM)
+}
+
+// NewShopify returns a new [probe.Probe] for the github.com/Shopify/sarama
+// module.
+func NewShopify(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgShopify, minVersionShopify)
+}
+
+func newProbe(logger *slog.Logger, version, pkg string, minVer *semver.Version) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVer.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	messageConst := func(key, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, "ConsumerMessage", field),
+			},
+			MinVersion: minVer,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				messageConst("message_headers_pos", "Headers"),
+				messageConst("message_key_pos", "Key"),
+				messageConst("message_topic_pos", "Topic"),
+				messageConst("message_partition_pos", "Partition"),
+				messageConst("message_offset_pos", "Offset"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*partitionConsumer).parseResponse",
+					EntryProbe:         "uprobe_partitionConsumer_parseResponse",
+					ReturnProbe:        "uprobe_partitionConsumer_parseResponse_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a kafka message received by the consumer.
+type event struct {
+	context.BaseSpanProperties
+	Topic     [256]byte
+	Key       [256]byte
+	Offset    int64
+	Partition int64
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingDestinationPartitionID(strconv.FormatInt(e.Partition, 10)),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingKafkaOffsetKey.Int64(e.Offset),
+	}
+	if key := unix.ByteSliceToString(e.Key[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(key))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(kafkaConsumerSpanName(topic))
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+func kafkaConsumerSpanName(topic string) string {
+	return topic + " receive"
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	e := &event{
+		BaseSpanProperties: f.BaseSpanProperties(),
+		Offset:             42,
+		Partition:          12,
+	}
+	copy(e.Topic[:], "topic1")
+	copy(e.Key[:], "key1")
+
+	want := f.Spans(
+		"topic1 receive",
+		ptrace.StatusCodeUnset,
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingDestinationPartitionID("12"),
+		semconv.MessagingDestinationName("topic1"),
+		semconv.MessagingKafkaOffset(42),
+		semconv.MessagingKafkaMessageKey("key1"),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// https://github.com/apache/kafka/blob/0.10.2/core/src/main/scala/kafka/common/Topic.scala#L30C3-L30C34
+#define MAX_TOPIC_SIZE 256
+// No constraint on the key size, but we must have a limit for the verifier
+#define MAX_KEY_SIZE 256
+
+struct kafka_request_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    char key[MAX_KEY_SIZE];
+    // Set to -1 if unknown.
+    s64 partition;
+    s64 offset;
+    // The message sent, only used by the eBPF program.
+    u64 message;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// Requests of messages being sent, keyed by the goroutine sending them.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct kafka_request_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} kafka_events SEC(".maps");
+
+// Span contexts of the messages sent by a SyncProducer, keyed by the message
+// address.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct span_context);
+	__uint(max_entries, MAX_CONCURRENT);
+} sync_messages SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct kafka_request_t));
+    __uint(max_entries, 2);
+} kafka_request_storage_map SEC(".maps");
+
+// https://github.com/IBM/sarama/blob/v1.45.0/record.go#L15
+struct record_header_t {
+    struct go_slice key;
+    struct go_slice value;
+};
+
+// Injected in init
+volatile const u64 message_topic_pos;
+volatile const u64 message_key_pos;
+volatile const u64 message_headers_pos;
+volatile const u64 message_partition_pos;
+
+static __always_inline struct kafka_request_t *new_kafka_request(struct pt_regs *ctx) {
+    u32 zero_id = 0;
+    struct kafka_request_t *zero_kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &zero_id);
+    if (zero_kafka_request == NULL) {
+        return NULL;
+    }
+
+    u32 actual_id = 1;
+    // Zero the span we are about to build, eBPF doesn't support memset of large structs (more than 1024 bytes)
+    bpf_map_update_elem(&kafka_request_storage_map, &actual_id, zero_kafka_request, BPF_ANY);
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &actual_id);
+    if (kafka_request == NULL) {
+        return NULL;
+    }
+
+    kafka_request->start_time = get_time_ns();
+    kafka_request->partition = -1;
+    kafka_request->offset = -1;
+
+    // sarama does not accept a context.Context, the span has no local parent.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &kafka_request->psc,
+        .sc = &kafka_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+    return kafka_request;
+}
+
+static __always_inline void collect_kafka_attributes(void *message, struct kafka_request_t *kafka_request) {
+    get_go_string_from_user_ptr((void *)(message + message_topic_pos), kafka_request->topic, sizeof(kafka_request->topic));
+
+    // Key is an Encoder. The StringEncoder and ByteEncoder values it holds
+    // both start with the pointer to, and the length of, the key.
+    struct go_iface key = {0};
+    bpf_probe_read_user(&key, sizeof(key), (void *)(message + message_key_pos));
+    if (key.data != NULL) {
+        get_go_string_from_user_ptr(key.data, kafka_request->key, sizeof(kafka_request->key));
+    }
+}
+
+#ifndef NO_HEADER_PROPAGATION
+static __always_inline int build_context_header(struct record_header_t *header, struct span_context *span_ctx) {
+    if (header == NULL || span_ctx == NULL) {
+        bpf_printk("build_context_header: Invalid arguments");
+        return -1;
+    }
+
+    char key[W3C_KEY_LENGTH] = "traceparent";
+    void *ptr = write_target_data(key, W3C_KEY_LENGTH);
+    if (ptr == NULL) {
+        bpf_printk("build_context_header: Failed to write key to user");
+        return -1;
+    }
+    header->key.array = ptr;
+    header->key.len = W3C_KEY_LENGTH;
+    header->key.cap = W3C_KEY_LENGTH;
+
+    char val[W3C_VAL_LENGTH];
+    span_context_to_w3c_string(span_ctx, val);
+    ptr = write_target_data(val, sizeof(val));
+    if (ptr == NULL) {
+        bpf_printk("build_context_header: Failed to write value to user");
+        return -1;
+    }
+    header->value.array = ptr;
+    header->value.len = W3C_VAL_LENGTH;
+    header->value.cap = W3C_VAL_LENGTH;
+    return 0;
+}
+
+static __always_inline void inject_kafka_header(void *message, struct span_context *span_ctx) {
+    struct record_header_t header = {0};
+    if (build_context_header(&header, span_ctx) != 0) {
+        return;
+    }
+    append_item_to_slice(&header, sizeof(header), (void *)(message + message_headers_pos));
+}
+#endif
+
+// This instrumentation attaches uprobe to the following function:
+// func (sp *syncProducer) SendMessage(msg *ProducerMessage) (partition int32, offset int64, err error)
+SEC("uprobe/syncProducer_SendMessage")
+int uprobe_syncProducer_SendMessage(struct pt_regs *ctx) {
+    void *message = get_argument(ctx, 2);
+    void *key = (void *)GOROUTINE(ctx);
+
+    if (bpf_map_lookup_elem(&kafka_events, &key) != NULL) {
+        bpf_printk("uprobe/syncProducer_SendMessage already tracked with the current goroutine");
+        return 0;
+    }
+
+    struct kafka_request_t *kafka_request = new_kafka_request(ctx);
+    if (kafka_request == NULL) {
+        bpf_printk("uprobe/syncProducer_SendMessage: Failed to get kafka_request");
+        return 0;
+    }
+    collect_kafka_attributes(message, kafka_request);
+    kafka_request->message = (u64)message;
+
+    bpf_map_update_elem(&kafka_events, &key, kafka_request, 0);
+    // The message is partitioned, and its headers written, by another
+    // goroutine, it is identified by its address there.
+    bpf_map_update_elem(&sync_messages, &message, &kafka_request->sc, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (sp *syncProducer) SendMessage(msg *ProducerMessage) (partition int32, offset int64, err error)
+SEC("uprobe/syncProducer_SendMessage")
+int uprobe_syncProducer_SendMessage_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_events, &key);
+    if (kafka_request == NULL) {
+        bpf_printk("kafka_request is null\n");
+        return 0;
+    }
+    kafka_request->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 3) != NULL) {
+        kafka_request->has_error = 1;
+    } else {
+        kafka_request->partition = (s32)(u64)get_argument(ctx, 1);
+        kafka_request->offset = (s64)get_argument(ctx, 2);
+    }
+
+    void *message = (void *)kafka_request->message;
+    bpf_map_delete_elem(&sync_messages, &message);
+
+    output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
+    bpf_map_delete_elem(&kafka_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tp *topicProducer) partitionMessage(msg *ProducerMessage) error
+SEC("uprobe/topicProducer_partitionMessage")
+int uprobe_topicProducer_partitionMessage(struct pt_regs *ctx) {
+    void *message = get_argument(ctx, 2);
+
+    struct span_context *sc = bpf_map_lookup_elem(&sync_messages, &message);
+    if (sc != NULL) {
+        // Sent by a SyncProducer, its span is ended by SendMessage.
+#ifndef NO_HEADER_PROPAGATION
+        inject_kafka_header(message, sc);
+#endif
+        return 0;
+    }
+
+    // Sent by an AsyncProducer, the span covers the partitioning of the
+    // message as its delivery is reported asynchronously.
+    void *key = (void *)GOROUTINE(ctx);
+    struct kafka_request_t *kafka_request = new_kafka_request(ctx);
+    if (kafka_request == NULL) {
+        bpf_printk("uprobe/topicProducer_partitionMessage: Failed to get kafka_request");
+        return 0;
+    }
+    collect_kafka_attributes(message, kafka_request);
+    kafka_request->message = (u64)message;
+
+#ifndef NO_HEADER_PROPAGATION
+    // Headers are added here, not when the message is sent to the producer,
+    // as the producer checks the Kafka version supports them before.
+    inject_kafka_header(message, &kafka_request->sc);
+#endif
+
+    bpf_map_update_elem(&kafka_events, &key, kafka_request, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tp *topicProducer) partitionMessage(msg *ProducerMessage) error
+SEC("uprobe/topicProducer_partitionMessage")
+int uprobe_topicProducer_partitionMessage_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_events, &key);
+    if (kafka_request == NULL) {
+        return 0;
+    }
+    kafka_request->end_time = end_time;
+
+    if (get_argument(ctx, 1) != NULL) {
+        kafka_request->has_error = 1;
+    } else {
+        s32 partition = 0;
+        bpf_probe_read_user(&partition, sizeof(partition), (void *)(kafka_request->message + message_partition_pos));
+        kafka_request->partition = partition;
+    }
+
+    output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
+    bpf_map_delete_elem(&kafka_events, &key);
+    return 0;
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides instrumentation probes for Kafka producers using
+// the [github.com/IBM/sarama] package, or the [github.com/Shopify/sarama]
+// package it was published as before v1.40.0.
+package producer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf_no_tp ./bpf/probe.bpf.c -- -DNO_HEADER_PROPAGATION
+
+const (
+	// pkgIBM is the package being instrumented by the probe returned by New.
+	pkgIBM = "github.com/IBM/sarama"
+	// pkgShopify is the package being instrumented by the probe returned by
+	// NewShopify.
+	pkgShopify = "github.com/Shopify/sarama"
+)
+
+var (
+	// minVersionIBM is the first release of the github.com/IBM/sarama
+	// module.
+	minVersionIBM = semver.New(1, 40, 0, "", "")
+	// minVersionShopify is the first version of the github.com/Shopify/sarama
+	// module with known offsets. Older versions are not instrumented.
+	minVersionShopify = semver.New(1, 21, 0, "", "")
+)
+
+// New returns a new [probe.Probe] for the github.com/IBM/sarama module.
+func New(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgIBM, minVersionIBM)
+}
+
+// NewShopify returns a new [probe.Probe] for the github.com/Shopify/sarama
+// module.
+func NewShopify(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgShopify, minVersionShopify)
+}
+
+func newProbe(logger *slog.Logger, version, pkg string, minVer *semver.Version) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVer.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	messageConst := func(key, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, "ProducerMessage", field),
+			},
+			MinVersion: minVer,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				messageConst("message_topic_pos", "Topic"),
+				messageConst("message_key_pos", "Key"),
+				messageConst("message_headers_pos", "Headers"),
+				messageConst("message_partition_pos", "Partition"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*syncProducer).SendMessage",
+					EntryProbe:         "uprobe_syncProducer_SendMessage",
+					ReturnProbe:        "uprobe_syncProducer_SendMessage_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*topicProducer).partitionMessage",
+					EntryProbe:         "uprobe_topicProducer_partitionMessage",
+					ReturnProbe:        "uprobe_topicProducer_partitionMessage_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: verifyAndLoadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
+	if !kernel.SupportsContextPropagation() {
+		fmt.Fprintf(
+			os.Stderr,
+			"the Linux Kernel doesn't support context propagation, please check if the kernel is in lockdown mode (/sys/kernel/security/lockdown)",
+		)
+		return loadBpf_no_tp()
+	}
+
+	return loadBpf()
+}
+
+// event represents a kafka message being sent.
+type event struct {
+	context.BaseSpanProperties
+	Topic [256]byte
+	Key   [256]byte
+	// Partition is the partition the message is sent to, or -1 if unknown.
+	Partition int64
+	// Offset is the offset of the message stored on the broker, or -1 if
+	// unknown. It is only known for messages sent by a SyncProducer.
+	Offset int64
+	// Message is the address of the message, only used by the eBPF program.
+	Message  uint64
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{semconv.MessagingSystemKafka, semconv.MessagingOperationTypeSend}
+	if topic != "" {
+		attrs = append(attrs, semconv.MessagingDestinationName(topic))
+	}
+	if key := unix.ByteSliceToString(e.Key[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(key))
+	}
+	if e.Partition >= 0 {
+		attrs = append(attrs, semconv.MessagingDestinationPartitionID(strconv.FormatInt(e.Partition, 10)))
+	}
+	if e.Offset >= 0 {
+		attrs = append(attrs, semconv.MessagingKafkaOffsetKey.Int64(e.Offset))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(kafkaProducerSpanName(topic))
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+func kafkaProducerSpanName(topic string) string {
+	return topic + " publish"
+}
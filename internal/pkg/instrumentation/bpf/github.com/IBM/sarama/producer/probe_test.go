@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindProducer)
+
+	newEvent := func(topic, key string, partition, offset int64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Partition:          partition,
+			Offset:             offset,
+		}
+		copy(e.Topic[:], topic)
+		copy(e.Key[:], key)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "sync",
+			event: newEvent("topic1", "key1", 3, 42, false),
+			want: f.Spans(
+				"topic1 publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemKafka,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("topic1"),
+				semconv.MessagingKafkaMessageKey("key1"),
+				semconv.MessagingDestinationPartitionID("3"),
+				semconv.MessagingKafkaOffset(42),
+			),
+		},
+		{
+			name:  "async",
+			event: newEvent("topic1", "", 0, -1, false),
+			want: f.Spans(
+				"topic1 publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemKafka,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("topic1"),
+				semconv.MessagingDestinationPartitionID("0"),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("topic1", "key1", -1, -1, true),
+			want: f.Spans(
+				"topic1 publish",
+				ptrace.StatusCodeError,
+				semconv.MessagingSystemKafka,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("topic1"),
+				semconv.MessagingKafkaMessageKey("key1"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
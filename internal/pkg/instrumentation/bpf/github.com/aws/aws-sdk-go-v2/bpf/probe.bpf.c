@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_SERVICE_SIZE 64
+#define MAX_OPERATION_SIZE 64
+#define MAX_REGION_SIZE 32
+#define MAX_CONCURRENT 50
+
+struct aws_request_t {
+    BASE_SPAN_PROPERTIES
+    char service[MAX_SERVICE_SIZE];
+    char operation[MAX_OPERATION_SIZE];
+    char region[MAX_REGION_SIZE];
+    // Status code of the HTTP response of the last attempt of the operation.
+    u64 status_code;
+    // Number of nested operations executed by the goroutine (e.g. the
+    // retrieval of credentials), they are part of this span.
+    u32 depth;
+    u8 has_error;
+    u8 padding[3];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct aws_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} aws_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct aws_request_t));
+    __uint(max_entries, 1);
+} aws_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 stack_id_pos;
+volatile const u64 register_service_metadata_service_id_pos;
+volatile const u64 register_service_metadata_region_pos;
+volatile const u64 smithy_response_response_pos;
+volatile const u64 http_response_status_code_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Stack) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (output interface{}, metadata Metadata, err error)
+SEC("uprobe/Stack_HandleMiddleware")
+int uprobe_Stack_HandleMiddleware(struct pt_regs *ctx) {
+    u64 stack_ptr_pos = 1;
+    u64 context_pos = 2;
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct aws_request_t *tracked = bpf_map_lookup_elem(&aws_events, &key);
+    if (tracked != NULL) {
+        tracked->depth++;
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct aws_request_t *aws_request = bpf_map_lookup_elem(&aws_storage_map, &zero);
+    if (aws_request == NULL) {
+        bpf_printk("uprobe/Stack_HandleMiddleware: aws_request is NULL");
+        return 0;
+    }
+    __builtin_memset(aws_request, 0, sizeof(struct aws_request_t));
+    aws_request->start_time = get_time_ns();
+
+    // The ID of the stack of an operation is the name of the operation.
+    void *stack_ptr = get_argument(ctx, stack_ptr_pos);
+    get_go_string_from_user_ptr((void *)(stack_ptr + stack_id_pos), aws_request->operation, sizeof(aws_request->operation));
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, context_pos, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &aws_request->psc,
+        .sc = &aws_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&aws_events, &key, aws_request, 0);
+    start_tracking_span(go_context.data, &aws_request->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Stack) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (output interface{}, metadata Metadata, err error)
+SEC("uprobe/Stack_HandleMiddleware")
+int uprobe_Stack_HandleMiddleware_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct aws_request_t *aws_request = bpf_map_lookup_elem(&aws_events, &key);
+    if (aws_request == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+
+    if (aws_request->depth > 0) {
+        aws_request->depth--;
+        return 0;
+    }
+
+    // The returned error is a non-nil interface on failure. The output is
+    // returned in the first two registers and the metadata in the third.
+    if (get_argument(ctx, 4) != NULL) {
+        aws_request->has_error = 1;
+    }
+
+    aws_request->end_time = get_time_ns();
+    output_span_event(ctx, aws_request, sizeof(*aws_request), &aws_request->sc);
+    stop_tracking_span(&aws_request->sc, &aws_request->psc);
+    bpf_map_delete_elem(&aws_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *RegisterServiceMetadata) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (out middleware.InitializeOutput, metadata middleware.Metadata, err error)
+SEC("uprobe/RegisterServiceMetadata_HandleInitialize")
+int uprobe_RegisterServiceMetadata_HandleInitialize(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct aws_request_t *aws_request = bpf_map_lookup_elem(&aws_events, &key);
+    if (aws_request == NULL || aws_request->depth > 0) {
+        return 0;
+    }
+
+    void *metadata_ptr = get_argument(ctx, 1);
+    get_go_string_from_user_ptr((void *)(metadata_ptr + register_service_metadata_service_id_pos), aws_request->service, sizeof(aws_request->service));
+    get_go_string_from_user_ptr((void *)(metadata_ptr + register_service_metadata_region_pos), aws_request->region, sizeof(aws_request->region));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c ClientHandler) Handle(ctx context.Context, input interface{}) (out interface{}, metadata middleware.Metadata, err error)
+SEC("uprobe/ClientHandler_Handle")
+int uprobe_ClientHandler_Handle_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct aws_request_t *aws_request = bpf_map_lookup_elem(&aws_events, &key);
+    if (aws_request == NULL || aws_request->depth > 0) {
+        return 0;
+    }
+
+    // The output is always a *smithyhttp.Response, holding an empty
+    // *http.Response if the request failed.
+    void *resp_ptr = get_argument(ctx, 2);
+    if (resp_ptr == NULL) {
+        return 0;
+    }
+    void *http_resp_ptr = NULL;
+    bpf_probe_read_user(&http_resp_ptr, sizeof(http_resp_ptr), (void *)(resp_ptr + smithy_response_response_pos));
+    if (http_resp_ptr == NULL) {
+        return 0;
+    }
+    s64 status_code = 0;
+    bpf_probe_read_user(&status_code, sizeof(status_code), (void *)(http_resp_ptr + http_response_status_code_pos));
+    aws_request->status_code = status_code;
+    return 0;
+}
@@ -0,0 +1,184 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package aws provides an instrumentation probe for the clients of AWS
+// services using the [github.com/aws/aws-sdk-go-v2] packages.
+package aws
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/aws/aws-sdk-go-v2"
+	// smithyPkg is the module of the middleware stacks the operations of the
+	// AWS service clients are executed with.
+	smithyPkg = "github.com/aws/smithy-go"
+)
+
+// rpcSystemAWS is the value of the rpc.system attribute of AWS API calls.
+const rpcSystemAWS = "aws-api"
+
+// awsRegionKey is the attribute key of the AWS region of the client.
+const awsRegionKey = attribute.Key("aws.region")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	middlewarePkg := smithyPkg + "/middleware"
+	smithyHTTPPkg := smithyPkg + "/transport/http"
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "stack_id_pos",
+					ID:  structfield.NewID(smithyPkg, middlewarePkg, "Stack", "id"),
+				},
+				probe.StructFieldConst{
+					Key: "smithy_response_response_pos",
+					ID:  structfield.NewID(smithyPkg, smithyHTTPPkg, "Response", "Response"),
+				},
+				probe.StructFieldConst{
+					Key: "http_response_status_code_pos",
+					ID:  structfield.NewID("std", "net/http", "Response", "StatusCode"),
+				},
+				probe.StructFieldConst{
+					Key: "register_service_metadata_service_id_pos",
+					ID:  structfield.NewID(pkg, pkg+"/aws/middleware", "RegisterServiceMetadata", "ServiceID"),
+				},
+				probe.StructFieldConst{
+					Key: "register_service_metadata_region_pos",
+					ID:  structfield.NewID(pkg, pkg+"/aws/middleware", "RegisterServiceMetadata", "Region"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         middlewarePkg + ".(*Stack).HandleMiddleware",
+					EntryProbe:  "uprobe_Stack_HandleMiddleware",
+					ReturnProbe: "uprobe_Stack_HandleMiddleware_Returns",
+				},
+				{
+					Sym:        pkg + "/aws/middleware.(*RegisterServiceMetadata).HandleInitialize",
+					EntryProbe: "uprobe_RegisterServiceMetadata_HandleInitialize",
+					DependsOn:  []string{middlewarePkg + ".(*Stack).HandleMiddleware"},
+					// The service metadata is only registered in the
+					// middleware stacks by the service clients generated
+					// before it was moved to the context of the operations.
+					// The function is not linked in programs only using
+					// newer clients.
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         smithyHTTPPkg + ".ClientHandler.Handle",
+					ReturnProbe: "uprobe_ClientHandler_Handle_Returns",
+					DependsOn:   []string{middlewarePkg + ".(*Stack).HandleMiddleware"},
+				},
+			},
+
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents an operation of an AWS service called by a client.
+type event struct {
+	context.BaseSpanProperties
+	// Service is the ID of the service, if registered in the middleware
+	// stack of the operation.
+	Service [64]byte
+	// Operation is the name of the operation.
+	Operation [64]byte
+	// Region is the region of the client, if registered with the service.
+	Region [32]byte
+	// StatusCode is the status code of the HTTP response of the last attempt
+	// of the operation, zero if none was received.
+	StatusCode uint64
+	// Depth is the number of nested operations being executed, only used by
+	// the eBPF program.
+	Depth    uint32
+	HasError uint8
+	_        [3]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	service := unix.ByteSliceToString(e.Service[:])
+	operation := unix.ByteSliceToString(e.Operation[:])
+	region := unix.ByteSliceToString(e.Region[:])
+
+	attrs := []attribute.KeyValue{semconv.RPCSystemKey.String(rpcSystemAWS)}
+	if service != "" {
+		attrs = append(attrs, semconv.RPCService(service))
+	}
+	if operation != "" {
+		attrs = append(attrs, semconv.RPCMethod(operation))
+	}
+	if region != "" {
+		attrs = append(attrs, awsRegionKey.String(region))
+	}
+	if e.StatusCode > 0 {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(int(e.StatusCode))) // nolint: gosec  // Status code is 3 digits.
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(service, operation))
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of a span following the semantic conventions:
+// "{rpc.service}/{rpc.method}", or the operation alone if the service is not
+// known.
+func spanName(service, operation string) string {
+	switch {
+	case operation == "":
+		return rpcSystemAWS
+	case service == "":
+		return operation
+	default:
+		return service + "/" + operation
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(service, operation, region string, statusCode uint64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			StatusCode:         statusCode,
+		}
+		copy(e.Service[:], service)
+		copy(e.Operation[:], operation)
+		copy(e.Region[:], region)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "service",
+			event: newEvent("DynamoDB", "GetItem", "us-west-2", 200, false),
+			want: f.Spans(
+				"DynamoDB/GetItem",
+				ptrace.StatusCodeUnset,
+				semconv.RPCSystemKey.String("aws-api"),
+				semconv.RPCService("DynamoDB"),
+				semconv.RPCMethod("GetItem"),
+				attribute.String("aws.region", "us-west-2"),
+				semconv.HTTPResponseStatusCode(200),
+			),
+		},
+		{
+			name:  "no service",
+			event: newEvent("", "PutObject", "", 200, false),
+			want: f.Spans(
+				"PutObject",
+				ptrace.StatusCodeUnset,
+				semconv.RPCSystemKey.String("aws-api"),
+				semconv.RPCMethod("PutObject"),
+				semconv.HTTPResponseStatusCode(200),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("SQS", "SendMessage", "eu-west-1", 400, true),
+			want: f.Spans(
+				"SQS/SendMessage",
+				ptrace.StatusCodeError,
+				semconv.RPCSystemKey.String("aws-api"),
+				semconv.RPCService("SQS"),
+				semconv.RPCMethod("SendMessage"),
+				attribute.String("aws.region", "eu-west-1"),
+				semconv.HTTPResponseStatusCode(400),
+			),
+		},
+		{
+			name:  "no response",
+			event: newEvent("SQS", "SendMessage", "eu-west-1", 0, true),
+			want: f.Spans(
+				"SQS/SendMessage",
+				ptrace.StatusCodeError,
+				semconv.RPCSystemKey.String("aws-api"),
+				semconv.RPCService("SQS"),
+				semconv.RPCMethod("SendMessage"),
+				attribute.String("aws.region", "eu-west-1"),
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", "", "", 0, false),
+			want:  f.Spans("aws-api", ptrace.StatusCodeUnset, semconv.RPCSystemKey.String("aws-api")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
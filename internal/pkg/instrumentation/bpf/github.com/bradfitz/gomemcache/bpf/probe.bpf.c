@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_ADDR_SIZE 128
+#define MAX_CONCURRENT 50
+
+// The operations instrumented, they need to be kept in sync with the
+// operation type of the probe.
+#define OPERATION_GET 1
+#define OPERATION_SET 2
+#define OPERATION_DELETE 3
+#define OPERATION_GET_MULTI 4
+
+// The message of memcache.ErrCacheMiss.
+static const char cache_miss_msg[] = "memcache: cache miss";
+#define CACHE_MISS_MSG_LEN (sizeof(cache_miss_msg) - 1)
+
+struct memcache_request_t {
+    BASE_SPAN_PROPERTIES
+    char addr[MAX_ADDR_SIZE];
+    u64 batch_size;
+    u8 operation;
+    u8 has_error;
+    u8 cache_miss;
+    u8 padding[5];
+};
+
+// The state of the operations being performed, only the event is sent to user
+// space.
+struct memcache_request_state_t {
+    struct memcache_request_t event;
+    // The position of the type of the error returned by the operation, its
+    // data is at the following position.
+    u64 error_pos;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct memcache_request_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} memcache_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct memcache_request_state_t));
+    __uint(max_entries, 1);
+} memcache_storage_map SEC(".maps");
+
+// Injected in init. The offset of the str field of memcache.staticAddr is zero
+// if it is not known, its first field is the network of the address.
+volatile const u64 static_addr_str_pos;
+
+static __always_inline void start_operation(struct pt_regs *ctx, u8 operation, u64 error_pos, u64 batch_size) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&memcache_events, &key) != NULL) {
+        bpf_printk("memcache operation already tracked with the current goroutine");
+        return;
+    }
+
+    u32 zero = 0;
+    struct memcache_request_state_t *state = bpf_map_lookup_elem(&memcache_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("memcache operation: state is NULL");
+        return;
+    }
+    __builtin_memset(state, 0, sizeof(struct memcache_request_state_t));
+    struct memcache_request_t *memcache_request = &state->event;
+    memcache_request->start_time = get_time_ns();
+    memcache_request->operation = operation;
+    memcache_request->batch_size = batch_size;
+    state->error_pos = error_pos;
+
+    // The client does not accept a context.Context, the span is always a
+    // root span.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &memcache_request->psc,
+        .sc = &memcache_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&memcache_events, &key, state, 0);
+}
+
+// Returns true if the error held by err is memcache.ErrCacheMiss.
+static __always_inline bool is_cache_miss(struct go_iface *err) {
+    // The error is expected to be the *errors.errorString returned by
+    // errors.New, its message is its first field. Other errors have another
+    // message, or no string, at this position.
+    struct go_string msg = {0};
+    long res = bpf_probe_read_user(&msg, sizeof(msg), err->data);
+    if (res != 0 || msg.len != CACHE_MISS_MSG_LEN) {
+        return false;
+    }
+
+    char buf[CACHE_MISS_MSG_LEN];
+    res = bpf_probe_read_user(buf, sizeof(buf), msg.str);
+    if (res != 0) {
+        return false;
+    }
+    for (int i = 0; i < CACHE_MISS_MSG_LEN; i++) {
+        if (buf[i] != cache_miss_msg[i]) {
+            return false;
+        }
+    }
+    return true;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Get(key string) (item *Item, err error)
+SEC("uprobe/Client_Get")
+int uprobe_Client_Get(struct pt_regs *ctx) {
+    start_operation(ctx, OPERATION_GET, 2, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Set(item *Item) error
+SEC("uprobe/Client_Set")
+int uprobe_Client_Set(struct pt_regs *ctx) {
+    start_operation(ctx, OPERATION_SET, 1, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Delete(key string) error
+SEC("uprobe/Client_Delete")
+int uprobe_Client_Delete(struct pt_regs *ctx) {
+    start_operation(ctx, OPERATION_DELETE, 1, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) GetMulti(keys []string) (map[string]*Item, error)
+SEC("uprobe/Client_GetMulti")
+int uprobe_Client_GetMulti(struct pt_regs *ctx) {
+    u64 keys_len_pos = 3;
+    u64 batch_size = (u64)get_argument(ctx, keys_len_pos);
+    start_operation(ctx, OPERATION_GET_MULTI, 2, batch_size);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) getConn(addr net.Addr) (*conn, error)
+//
+// The address is the one picked by the ServerSelector of the client. The
+// connections used by GetMulti are got by other goroutines, the address of
+// these operations is not recorded.
+SEC("uprobe/Client_getConn")
+int uprobe_Client_getConn(struct pt_regs *ctx) {
+    if (static_addr_str_pos == 0) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct memcache_request_state_t *state = bpf_map_lookup_elem(&memcache_events, &key);
+    if (state == NULL) {
+        return 0;
+    }
+
+    // The addresses of a ServerList are *memcache.staticAddr.
+    void *addr_ptr = get_argument(ctx, 3);
+    if (addr_ptr == NULL) {
+        return 0;
+    }
+    get_go_string_from_user_ptr((void *)(addr_ptr + static_addr_str_pos), state->event.addr, sizeof(state->event.addr));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the return of the Get, Set, Delete,
+// and GetMulti methods of the Client.
+SEC("uprobe/Client_Operation")
+int uprobe_Client_Operation_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct memcache_request_state_t *state = bpf_map_lookup_elem(&memcache_events, &key);
+    if (state == NULL) {
+        bpf_printk("uprobe/Client_Operation_Returns: state is NULL");
+        return 0;
+    }
+    struct memcache_request_t *memcache_request = &state->event;
+    memcache_request->end_time = end_time;
+
+    struct go_iface err = {0};
+    err.type = get_argument(ctx, state->error_pos);
+    err.data = get_argument(ctx, state->error_pos + 1);
+    if (err.type != NULL) {
+        memcache_request->has_error = 1;
+        if (is_cache_miss(&err)) {
+            memcache_request->cache_miss = 1;
+        }
+    }
+
+    output_span_event(ctx, memcache_request, sizeof(*memcache_request), &memcache_request->sc);
+    bpf_map_delete_elem(&memcache_events, &key);
+    return 0;
+}
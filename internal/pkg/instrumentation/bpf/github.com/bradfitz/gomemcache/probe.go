@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package memcache provides an instrumentation probe for memcached clients
+// using the [github.com/bradfitz/gomemcache/memcache] package.
+package memcache
+
+import (
+	"log/slog"
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/bradfitz/gomemcache/memcache"
+	// mod is the module of the package being instrumented.
+	mod = "github.com/bradfitz/gomemcache"
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				// The server address of the operations is not recorded if
+				// the offset is unknown.
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "static_addr_str_pos",
+						ID:  structfield.NewID(mod, pkg, "staticAddr", "str"),
+					},
+					FailureMode: probe.FailureModeIgnore,
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				newUprobe("Get", "uprobe_Client_Get"),
+				newUprobe("Set", "uprobe_Client_Set"),
+				newUprobe("Delete", "uprobe_Client_Delete"),
+				newUprobe("GetMulti", "uprobe_Client_GetMulti"),
+				{
+					Sym:         pkg + ".(*Client).getConn",
+					EntryProbe:  "uprobe_Client_getConn",
+					FailureMode: probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// newUprobe returns the [probe.Uprobe] of the method of the Client with the
+// entryProbe eBPF program.
+func newUprobe(method, entryProbe string) *probe.Uprobe {
+	return &probe.Uprobe{
+		Sym:         pkg + ".(*Client)." + method,
+		EntryProbe:  entryProbe,
+		ReturnProbe: "uprobe_Client_Operation_Returns",
+		FailureMode: probe.FailureModeIgnore,
+	}
+}
+
+// operation is an operation of the Client, it needs to be kept in sync with
+// the operations of the eBPF program.
+type operation uint8
+
+const (
+	operationGet operation = iota + 1
+	operationSet
+	operationDelete
+	operationGetMulti
+)
+
+// name returns the memcached command name of o.
+func (o operation) name() string {
+	switch o {
+	case operationGet, operationGetMulti:
+		return "get"
+	case operationSet:
+		return "set"
+	case operationDelete:
+		return "delete"
+	default:
+		return ""
+	}
+}
+
+// event represents an operation performed by a memcached client.
+type event struct {
+	context.BaseSpanProperties
+	// Addr is the address of the server of the operation, a "host:port" or
+	// a Unix domain socket path.
+	Addr [128]byte
+	// BatchSize is the number of keys of a GetMulti operation.
+	BatchSize uint64
+	Operation operation
+	HasError  uint8
+	// CacheMiss is set if the error of the operation is
+	// memcache.ErrCacheMiss.
+	CacheMiss uint8
+	_         [5]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameMemcached}
+
+	operation := e.Operation.name()
+	if e.BatchSize > 1 {
+		operation = "BATCH " + operation
+		attrs = append(
+			attrs,
+			semconv.DBOperationBatchSize(int(min(e.BatchSize, math.MaxInt))), // nolint: gosec  // Bounded.
+		)
+	}
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperationName(operation))
+	}
+
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Addr[:]))
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	name := operation
+	if name == "" {
+		name = semconv.DBSystemNameMemcached.Value.AsString()
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	// A cache miss is an expected outcome of Get and Delete, not an error.
+	if e.HasError != 0 && e.CacheMiss == 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
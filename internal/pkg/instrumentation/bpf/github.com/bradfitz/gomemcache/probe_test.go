@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package memcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+
+	newEvent := func(op operation, addr string, batchSize uint64, hasError, cacheMiss bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BatchSize:          batchSize,
+			Operation:          op,
+		}
+		copy(e.Addr[:], addr)
+		if hasError {
+			e.HasError = 1
+		}
+		if cacheMiss {
+			e.CacheMiss = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "get",
+			event: newEvent(operationGet, "10.0.0.1:11211", 0, false, false),
+			want: f.Spans(
+				"get",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMemcached,
+				semconv.DBOperationName("get"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(11211),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "cache miss",
+			event: newEvent(operationGet, "10.0.0.1:11211", 0, true, true),
+			want: f.Spans(
+				"get",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMemcached,
+				semconv.DBOperationName("get"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(11211),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "set error",
+			event: newEvent(operationSet, "10.0.0.1:11211", 0, true, false),
+			want: f.Spans(
+				"set",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameMemcached,
+				semconv.DBOperationName("set"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(11211),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "delete unix",
+			event: newEvent(operationDelete, "/var/run/memcached.sock", 0, false, false),
+			want: f.Spans(
+				"delete",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMemcached,
+				semconv.DBOperationName("delete"),
+				semconv.ServerAddress("/var/run/memcached.sock"),
+				semconv.NetworkTransportUnix,
+			),
+		},
+		{
+			name:  "get multi",
+			event: newEvent(operationGetMulti, "", 3, false, false),
+			want: f.Spans(
+				"BATCH get",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMemcached,
+				semconv.DBOperationBatchSize(3),
+				semconv.DBOperationName("BATCH get"),
+			),
+		},
+		{
+			name:  "get multi single key",
+			event: newEvent(operationGetMulti, "", 1, false, false),
+			want: f.Spans(
+				"get",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMemcached,
+				semconv.DBOperationName("get"),
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent(0, "", 0, false, false),
+			want:  f.Spans("memcached", ptrace.StatusCodeUnset, semconv.DBSystemNameMemcached),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// https://github.com/apache/kafka/blob/0.10.2/core/src/main/scala/kafka/common/Topic.scala#L30C3-L30C34
+#define MAX_TOPIC_SIZE 256
+// No constraint on the key size, but we must have a limit for the verifier
+#define MAX_KEY_SIZE 256
+#define MAX_HEADERS 10
+
+struct kafka_request_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    char key[MAX_KEY_SIZE];
+    s64 offset;
+    s64 partition;
+    u8 has_error;
+    u8 padding[7];
+};
+
+struct poll_t {
+    u64 start_time;
+    // The message received by the poll, if any.
+    void *message;
+};
+
+// Polls of the consumers in progress, keyed by the goroutine polling.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct poll_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} polls SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct kafka_request_t));
+    __uint(max_entries, 1);
+} kafka_request_storage_map SEC(".maps");
+
+// https://github.com/confluentinc/confluent-kafka-go/blob/v2.0.2/kafka/header.go#L42
+struct kafka_header_t {
+    struct go_string key;
+    struct go_slice value;
+};
+
+// Injected in init
+volatile const u64 message_key_pos;
+volatile const u64 message_headers_pos;
+// The TopicPartition is the first field of a Message, the offsets of its
+// fields are also the ones in the Message.
+volatile const u64 topic_partition_topic_pos;
+volatile const u64 topic_partition_partition_pos;
+volatile const u64 topic_partition_offset_pos;
+volatile const u64 topic_partition_error_pos;
+
+static __always_inline long extract_span_context_from_headers(void *message, struct span_context *parent_span_context) {
+    // Read the headers slice descriptor
+    struct go_slice headers_slice = {0};
+    bpf_probe_read_user(&headers_slice, sizeof(headers_slice), (void *)(message + message_headers_pos));
+
+    char key[W3C_KEY_LENGTH] = "traceparent";
+    char current_key[W3C_KEY_LENGTH];
+
+    for (u64 i = 0; i < headers_slice.len; i++) {
+        if (i >= MAX_HEADERS) {
+            break;
+        }
+        struct kafka_header_t header = {0};
+        bpf_probe_read_user(&header, sizeof(header), headers_slice.array + (i * sizeof(header)));
+        // Check if it is the traceparent header
+        if (header.key.len == W3C_KEY_LENGTH && header.value.len == W3C_VAL_LENGTH) {
+            bpf_probe_read_user(current_key, sizeof(current_key), header.key.str);
+            if (bpf_memcmp(key, current_key, sizeof(key))) {
+                // Found the traceparent header, extract the span context
+                char val[W3C_VAL_LENGTH];
+                bpf_probe_read_user(val, W3C_VAL_LENGTH, header.value.array);
+                w3c_string_to_span_context(val, parent_span_context);
+                return 0;
+            }
+        }
+    }
+
+    return -1;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (h *handle) eventPoll(channel chan Event, timeoutMs int, maxEvents int, termChan chan bool) (Event, bool)
+SEC("uprobe/handle_eventPoll")
+int uprobe_handle_eventPoll(struct pt_regs *ctx) {
+    /* Poll is usually inlined by the compiler, in ReadMessage as well. The
+    eventPoll it wraps is the last Go function called before librdkafka is
+    polled. It is also called with a channel by the goroutines of the
+    deprecated channel based consumers, and of the producers, those calls are
+    not traced. */
+    if (get_argument(ctx, 2) != NULL) {
+        return 0;
+    }
+
+    void *goroutine = (void *)GOROUTINE(ctx);
+    struct poll_t poll = {0};
+    poll.start_time = get_time_ns();
+    bpf_map_update_elem(&polls, &goroutine, &poll, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (h *handle) setupMessageFromC(msg *Message, cmsg *C.rd_kafka_message_t)
+SEC("uprobe/handle_setupMessageFromC")
+int uprobe_handle_setupMessageFromC(struct pt_regs *ctx) {
+    // The message is set up when it is fetched by a poll, and when its
+    // delivery is reported to a producer.
+    void *goroutine = (void *)GOROUTINE(ctx);
+    struct poll_t *poll = bpf_map_lookup_elem(&polls, &goroutine);
+    if (poll == NULL) {
+        return 0;
+    }
+    poll->message = get_argument(ctx, 2);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (h *handle) eventPoll(channel chan Event, timeoutMs int, maxEvents int, termChan chan bool) (Event, bool)
+SEC("uprobe/handle_eventPoll")
+int uprobe_handle_eventPoll_Returns(struct pt_regs *ctx) {
+    /* A span is created for the message received by the poll, it covers the
+    poll. The consumer does not accept a context.Context, the parent span is
+    only the one of the producer propagated in the message headers. */
+    u64 end_time = get_time_ns();
+    void *goroutine = (void *)GOROUTINE(ctx);
+    struct poll_t *poll_ptr = bpf_map_lookup_elem(&polls, &goroutine);
+    if (poll_ptr == NULL) {
+        return 0;
+    }
+    struct poll_t poll = *poll_ptr;
+    bpf_map_delete_elem(&polls, &goroutine);
+
+    void *message = poll.message;
+    if (message == NULL) {
+        // Timed out, or another event was received.
+        return 0;
+    }
+
+    u32 map_id = 0;
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &map_id);
+    if (kafka_request == NULL) {
+        bpf_printk("uprobe/handle_eventPoll: kafka_request is NULL");
+        return 0;
+    }
+    __builtin_memset(kafka_request, 0, sizeof(*kafka_request));
+    kafka_request->start_time = poll.start_time;
+    kafka_request->end_time = end_time;
+
+    // Get the parent span context from the message headers
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .sc = &kafka_request->sc,
+        .psc = &kafka_request->psc,
+        .go_context = &go_context,
+        .get_parent_span_context_fn = extract_span_context_from_headers,
+        .get_parent_span_context_arg = message,
+    };
+    start_span(&start_span_params);
+
+    // The topic is a *string.
+    void *topic_ptr = NULL;
+    bpf_probe_read_user(&topic_ptr, sizeof(topic_ptr), (void *)(message + topic_partition_topic_pos));
+    get_go_string_from_user_ptr(topic_ptr, kafka_request->topic, sizeof(kafka_request->topic));
+    // Key is a byte slice, it starts with the pointer to, and the length of,
+    // the key as a string does.
+    get_go_string_from_user_ptr((void *)(message + message_key_pos), kafka_request->key, sizeof(kafka_request->key));
+    s32 partition = 0;
+    bpf_probe_read_user(&partition, sizeof(partition), (void *)(message + topic_partition_partition_pos));
+    kafka_request->partition = partition;
+    bpf_probe_read_user(&kafka_request->offset, sizeof(kafka_request->offset), (void *)(message + topic_partition_offset_pos));
+
+    // The error is a non-nil interface for partition-specific errors.
+    void *err = NULL;
+    bpf_probe_read_user(&err, sizeof(err), (void *)(message + topic_partition_error_pos));
+    if (err != NULL) {
+        kafka_request->has_error = 1;
+    }
+
+    output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
+    return 0;
+}
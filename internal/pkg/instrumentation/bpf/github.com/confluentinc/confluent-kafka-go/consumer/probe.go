@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consumer provides an instrumentation probe for Kafka consumers using
+// the [github.com/confluentinc/confluent-kafka-go/v2/kafka] package.
+package consumer
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// mod is the module of the package being instrumented.
+	mod = "github.com/confluentinc/confluent-kafka-go/v2"
+	// pkg is the package being instrumented.
+	pkg = mod + "/kafka"
+)
+
+// minVersion is the first release of the module.
+var minVersion = semver.New(2, 0, 2, "", "")
+
+// New returns a new [probe.Probe].
+//
+// The package is a wrapper of librdkafka, it is only compiled with cgo. The
+// probe is not loaded for binaries built without it, they do not contain the
+// symbols of the package.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: mod,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(mod, pkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	const eventPoll = pkg + ".(*handle).eventPoll"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("message_key_pos", "Message", "Key"),
+				fieldConst("message_headers_pos", "Message", "Headers"),
+				fieldConst("topic_partition_topic_pos", "TopicPartition", "Topic"),
+				fieldConst("topic_partition_partition_pos", "TopicPartition", "Partition"),
+				fieldConst("topic_partition_offset_pos", "TopicPartition", "Offset"),
+				fieldConst("topic_partition_error_pos", "TopicPartition", "Error"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                eventPoll,
+					EntryProbe:         "uprobe_handle_eventPoll",
+					ReturnProbe:        "uprobe_handle_eventPoll_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					Sym:                pkg + ".(*handle).setupMessageFromC",
+					EntryProbe:         "uprobe_handle_setupMessageFromC",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{eventPoll},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a kafka message received by a poll of the consumer.
+type event struct {
+	context.BaseSpanProperties
+	Topic     [256]byte
+	Key       [256]byte
+	Offset    int64
+	Partition int64
+	HasError  uint8
+	_         [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingDestinationPartitionID(strconv.FormatInt(e.Partition, 10)),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingKafkaOffsetKey.Int64(e.Offset),
+	}
+	if key := unix.ByteSliceToString(e.Key[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(key))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(kafkaConsumerSpanName(topic))
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+func kafkaConsumerSpanName(topic string) string {
+	return topic + " receive"
+}
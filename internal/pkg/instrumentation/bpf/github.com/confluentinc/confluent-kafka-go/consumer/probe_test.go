@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(key string, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Offset:             42,
+			Partition:          12,
+		}
+		copy(e.Topic[:], "topic1")
+		copy(e.Key[:], key)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newSpans := func(code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		return f.Spans("topic1 receive", code, attrs...)
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "key",
+			event: newEvent("key1", false),
+			want: newSpans(
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemKafka,
+				semconv.MessagingOperationTypeReceive,
+				semconv.MessagingDestinationPartitionID("12"),
+				semconv.MessagingDestinationName("topic1"),
+				semconv.MessagingKafkaOffset(42),
+				semconv.MessagingKafkaMessageKey("key1"),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("", true),
+			want: newSpans(
+				ptrace.StatusCodeError,
+				semconv.MessagingSystemKafka,
+				semconv.MessagingOperationTypeReceive,
+				semconv.MessagingDestinationPartitionID("12"),
+				semconv.MessagingDestinationName("topic1"),
+				semconv.MessagingKafkaOffset(42),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// https://github.com/apache/kafka/blob/0.10.2/core/src/main/scala/kafka/common/Topic.scala#L30C3-L30C34
+#define MAX_TOPIC_SIZE 256
+// No constraint on the key size, but we must have a limit for the verifier
+#define MAX_KEY_SIZE 256
+
+struct kafka_request_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    char key[MAX_KEY_SIZE];
+    // Set to -1 if the partition is assigned by the partitioner.
+    s64 partition;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// Requests of messages being produced, keyed by the goroutine producing them.
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct kafka_request_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} kafka_events SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct kafka_request_t));
+    __uint(max_entries, 2);
+} kafka_request_storage_map SEC(".maps");
+
+// https://github.com/confluentinc/confluent-kafka-go/blob/v2.0.2/kafka/header.go#L42
+struct kafka_header_t {
+    struct go_string key;
+    struct go_slice value;
+};
+
+// Injected in init
+volatile const u64 message_key_pos;
+volatile const u64 message_headers_pos;
+// The TopicPartition is the first field of a Message, the offsets of its
+// fields are also the ones in the Message.
+volatile const u64 topic_partition_topic_pos;
+volatile const u64 topic_partition_partition_pos;
+
+#ifndef NO_HEADER_PROPAGATION
+static __always_inline int build_context_header(struct kafka_header_t *header, struct span_context *span_ctx) {
+    if (header == NULL || span_ctx == NULL) {
+        bpf_printk("build_context_header: Invalid arguments");
+        return -1;
+    }
+
+    char key[W3C_KEY_LENGTH] = "traceparent";
+    void *ptr = write_target_data(key, W3C_KEY_LENGTH);
+    if (ptr == NULL) {
+        bpf_printk("build_context_header: Failed to write key to user");
+        return -1;
+    }
+    header->key.str = ptr;
+    header->key.len = W3C_KEY_LENGTH;
+
+    char val[W3C_VAL_LENGTH];
+    span_context_to_w3c_string(span_ctx, val);
+    ptr = write_target_data(val, sizeof(val));
+    if (ptr == NULL) {
+        bpf_printk("build_context_header: Failed to write value to user");
+        return -1;
+    }
+    header->value.array = ptr;
+    header->value.len = W3C_VAL_LENGTH;
+    header->value.cap = W3C_VAL_LENGTH;
+    return 0;
+}
+
+static __always_inline void inject_kafka_header(void *message, struct span_context *span_ctx) {
+    struct kafka_header_t header = {0};
+    if (build_context_header(&header, span_ctx) != 0) {
+        return;
+    }
+    append_item_to_slice(&header, sizeof(header), (void *)(message + message_headers_pos));
+}
+#endif
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *Producer) produce(msg *Message, msgFlags int, deliveryChan chan Event) error
+SEC("uprobe/Producer_produce")
+int uprobe_Producer_produce(struct pt_regs *ctx) {
+    /* Produce is usually inlined by the compiler, the unexported produce it
+    wraps, also used by the channel based producer, is the last Go function
+    called before the message is handed to librdkafka. The message is copied
+    to librdkafka by this function, its headers must be written before. */
+    void *message = get_argument(ctx, 2);
+    void *key = (void *)GOROUTINE(ctx);
+
+    if (bpf_map_lookup_elem(&kafka_events, &key) != NULL) {
+        bpf_printk("uprobe/Producer_produce already tracked with the current goroutine");
+        return 0;
+    }
+
+    u32 zero_id = 0;
+    struct kafka_request_t *zero_kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &zero_id);
+    if (zero_kafka_request == NULL) {
+        bpf_printk("uprobe/Producer_produce: zero_kafka_request is NULL");
+        return 0;
+    }
+
+    u32 actual_id = 1;
+    // Zero the span we are about to build, eBPF doesn't support memset of large structs (more than 1024 bytes)
+    bpf_map_update_elem(&kafka_request_storage_map, &actual_id, zero_kafka_request, BPF_ANY);
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_request_storage_map, &actual_id);
+    if (kafka_request == NULL) {
+        bpf_printk("uprobe/Producer_produce: Failed to get kafka_request");
+        return 0;
+    }
+    kafka_request->start_time = get_time_ns();
+
+    // confluent-kafka-go does not accept a context.Context, the span has no
+    // local parent.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &kafka_request->psc,
+        .sc = &kafka_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    // The topic is a *string.
+    void *topic_ptr = NULL;
+    bpf_probe_read_user(&topic_ptr, sizeof(topic_ptr), (void *)(message + topic_partition_topic_pos));
+    get_go_string_from_user_ptr(topic_ptr, kafka_request->topic, sizeof(kafka_request->topic));
+    // Key is a byte slice, it starts with the pointer to, and the length of,
+    // the key as a string does.
+    get_go_string_from_user_ptr((void *)(message + message_key_pos), kafka_request->key, sizeof(kafka_request->key));
+    s32 partition = 0;
+    bpf_probe_read_user(&partition, sizeof(partition), (void *)(message + topic_partition_partition_pos));
+    kafka_request->partition = partition;
+
+#ifndef NO_HEADER_PROPAGATION
+    inject_kafka_header(message, &kafka_request->sc);
+#endif
+
+    bpf_map_update_elem(&kafka_events, &key, kafka_request, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *Producer) produce(msg *Message, msgFlags int, deliveryChan chan Event) error
+SEC("uprobe/Producer_produce")
+int uprobe_Producer_produce_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_events, &key);
+    if (kafka_request == NULL) {
+        return 0;
+    }
+    kafka_request->end_time = end_time;
+
+    // The returned error is a non-nil interface if the message could not be
+    // enqueued. Its delivery is reported asynchronously.
+    if (get_argument(ctx, 1) != NULL) {
+        kafka_request->has_error = 1;
+    }
+
+    output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
+    bpf_map_delete_elem(&kafka_events, &key);
+    return 0;
+}
@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides an instrumentation probe for Kafka producers using
+// the [github.com/confluentinc/confluent-kafka-go/v2/kafka] package.
+package producer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf_no_tp ./bpf/probe.bpf.c -- -DNO_HEADER_PROPAGATION
+
+const (
+	// mod is the module of the package being instrumented.
+	mod = "github.com/confluentinc/confluent-kafka-go/v2"
+	// pkg is the package being instrumented.
+	pkg = mod + "/kafka"
+)
+
+// minVersion is the first release of the module.
+var minVersion = semver.New(2, 0, 2, "", "")
+
+// New returns a new [probe.Probe].
+//
+// The package is a wrapper of librdkafka, it is only compiled with cgo. The
+// probe is not loaded for binaries built without it, they do not contain the
+// symbols of the package.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: mod,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(mod, pkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("message_key_pos", "Message", "Key"),
+				fieldConst("message_headers_pos", "Message", "Headers"),
+				fieldConst("topic_partition_topic_pos", "TopicPartition", "Topic"),
+				fieldConst("topic_partition_partition_pos", "TopicPartition", "Partition"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*Producer).produce",
+					EntryProbe:         "uprobe_Producer_produce",
+					ReturnProbe:        "uprobe_Producer_produce_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: verifyAndLoadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
+	if !kernel.SupportsContextPropagation() {
+		fmt.Fprintf(
+			os.Stderr,
+			"the Linux Kernel doesn't support context propagation, please check if the kernel is in lockdown mode (/sys/kernel/security/lockdown)",
+		)
+		return loadBpf_no_tp()
+	}
+
+	return loadBpf()
+}
+
+// event represents a kafka message being produced. The span ends when the
+// message is enqueued by librdkafka.
+type event struct {
+	context.BaseSpanProperties
+	Topic [256]byte
+	Key   [256]byte
+	// Partition is the partition the message is produced to, or -1 if it is
+	// assigned by the partitioner.
+	Partition int64
+	HasError  uint8
+	_         [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{semconv.MessagingSystemKafka, semconv.MessagingOperationTypeSend}
+	if topic != "" {
+		attrs = append(attrs, semconv.MessagingDestinationName(topic))
+	}
+	if key := unix.ByteSliceToString(e.Key[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(key))
+	}
+	if e.Partition >= 0 {
+		attrs = append(attrs, semconv.MessagingDestinationPartitionID(strconv.FormatInt(e.Partition, 10)))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(kafkaProducerSpanName(topic))
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+func kafkaProducerSpanName(topic string) string {
+	return topic + " publish"
+}
@@ -0,0 +1,314 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+// The number of operations of a transaction recorded. The operations past it
+// are counted as dropped.
+#define MAX_OPS 16
+#define MAX_CONCURRENT 50
+// The number of open transactions tracked. The least recently used ones, e.g.
+// transactions never committed nor discarded, are evicted once it is reached.
+#define MAX_TRANSACTIONS 1024
+
+// The operations of a transaction, they need to be kept in sync with the
+// operations of the probe.
+#define OP_GET 1
+#define OP_SET 2
+#define OP_DELETE 3
+
+// The bitDelete flag of the meta of an entry deleting its key.
+#define BIT_DELETE 1
+
+struct badger_op_t {
+    u64 time;
+    u64 key_size;
+    u64 value_size;
+    u8 op;
+    u8 padding[7];
+};
+
+struct badger_event_t {
+    BASE_SPAN_PROPERTIES
+    // The first operations of a transaction, in their order. Only the first
+    // ops_len are valid.
+    struct badger_op_t ops[MAX_OPS];
+    // The entries set or deleted by a transaction, and their estimated size.
+    u64 entries;
+    u64 bytes;
+    u32 ops_len;
+    u32 ops_dropped;
+    u32 files_rewritten;
+    u8 is_gc;
+    u8 has_error;
+    u8 padding[2];
+};
+
+// Transactions being created or committed, keyed by the goroutine doing it.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct badger_event_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} badger_events SEC(".maps");
+
+// Value log garbage collections running, keyed by the goroutine running them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct badger_event_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} badger_gcs SEC(".maps");
+
+// Open read-write transactions, keyed by the transaction.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct badger_event_t);
+    __uint(max_entries, MAX_TRANSACTIONS);
+} badger_txns SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct badger_event_t));
+    __uint(max_entries, 1);
+} badger_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 txn_size_pos;
+volatile const u64 txn_count_pos;
+volatile const u64 entry_key_pos;
+volatile const u64 entry_value_pos;
+volatile const u64 entry_meta_pos;
+
+// Returns a zeroed event from the per-CPU storage, with its start time set and
+// its span started. Transactions and garbage collections are not run with a
+// context, their spans are roots.
+static __always_inline struct badger_event_t *new_event(struct pt_regs *ctx) {
+    u32 zero = 0;
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_storage_map, &zero);
+    if (event == NULL) {
+        bpf_printk("badger: event is NULL");
+        return NULL;
+    }
+    __builtin_memset(event, 0, sizeof(struct badger_event_t));
+    event->start_time = get_time_ns();
+
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &event->psc,
+        .sc = &event->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+    return event;
+}
+
+// Records the operation of the instrumented method on the open transaction
+// receiving the call.
+static __always_inline void add_op(struct pt_regs *ctx, u8 op, u64 key_size, u64 value_size) {
+    void *txn = get_argument(ctx, 1);
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_txns, &txn);
+    if (event == NULL) {
+        return;
+    }
+
+    u32 n = event->ops_len;
+    if (n >= MAX_OPS) {
+        event->ops_dropped++;
+        return;
+    }
+    struct badger_op_t *o = &event->ops[n & (MAX_OPS - 1)];
+    o->time = get_time_ns();
+    o->key_size = key_size;
+    o->value_size = value_size;
+    o->op = op;
+    event->ops_len = n + 1;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) newTransaction(update, isManaged bool) *Txn
+SEC("uprobe/DB_newTransaction")
+int uprobe_DB_newTransaction(struct pt_regs *ctx) {
+    // Read-only transactions are not committed, they are not traced.
+    u8 update = (u64)get_argument(ctx, 2) & 0xff;
+    if (!update) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct badger_event_t *event = new_event(ctx);
+    if (event == NULL) {
+        return 0;
+    }
+    bpf_map_update_elem(&badger_events, &key, event, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) newTransaction(update, isManaged bool) *Txn
+SEC("uprobe/DB_newTransaction")
+int uprobe_DB_newTransaction_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_events, &key);
+    if (event == NULL) {
+        return 0;
+    }
+
+    void *txn = get_argument(ctx, 1);
+    if (txn != NULL) {
+        bpf_map_update_elem(&badger_txns, &txn, event, 0);
+    }
+    bpf_map_delete_elem(&badger_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (txn *Txn) modify(e *Entry) error
+SEC("uprobe/Txn_modify")
+int uprobe_Txn_modify(struct pt_regs *ctx) {
+    void *entry = get_argument(ctx, 2);
+    if (entry == NULL) {
+        return 0;
+    }
+
+    struct go_slice key = {0};
+    struct go_slice value = {0};
+    u8 meta = 0;
+    bpf_probe_read_user(&key, sizeof(key), entry + entry_key_pos);
+    bpf_probe_read_user(&value, sizeof(value), entry + entry_value_pos);
+    bpf_probe_read_user(&meta, sizeof(meta), entry + entry_meta_pos);
+
+    if (meta & BIT_DELETE) {
+        add_op(ctx, OP_DELETE, key.len, 0);
+    } else {
+        add_op(ctx, OP_SET, key.len, value.len);
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (txn *Txn) Get(key []byte) (item *Item, rerr error)
+SEC("uprobe/Txn_Get")
+int uprobe_Txn_Get(struct pt_regs *ctx) {
+    u64 key_len = (u64)get_argument(ctx, 3);
+    add_op(ctx, OP_GET, key_len, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (txn *Txn) Discard()
+SEC("uprobe/Txn_Discard")
+int uprobe_Txn_Discard(struct pt_regs *ctx) {
+    // Transactions committed are no longer open, the ones discarded without
+    // being committed are not traced.
+    void *txn = get_argument(ctx, 1);
+    bpf_map_delete_elem(&badger_txns, &txn);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (txn *Txn) Commit() error
+SEC("uprobe/Txn_Commit")
+int uprobe_Txn_Commit(struct pt_regs *ctx) {
+    void *txn = get_argument(ctx, 1);
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_txns, &txn);
+    if (event == NULL) {
+        return 0;
+    }
+
+    // The count includes the entry marking the end of the transaction.
+    s64 count = 0;
+    bpf_probe_read_user(&count, sizeof(count), txn + txn_count_pos);
+    if (count > 1) {
+        event->entries = count - 1;
+    }
+    bpf_probe_read_user(&event->bytes, sizeof(event->bytes), txn + txn_size_pos);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&badger_events, &key, event, 0);
+    bpf_map_delete_elem(&badger_txns, &txn);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (txn *Txn) Commit() error
+SEC("uprobe/Txn_Commit")
+int uprobe_Txn_Commit_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_events, &key);
+    if (event == NULL) {
+        return 0;
+    }
+
+    if (get_argument(ctx, 1) != NULL) {
+        event->has_error = 1;
+    }
+
+    event->end_time = get_time_ns();
+    output_span_event(ctx, event, sizeof(*event), &event->sc);
+    bpf_map_delete_elem(&badger_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) RunValueLogGC(discardRatio float64) error
+SEC("uprobe/DB_RunValueLogGC")
+int uprobe_DB_RunValueLogGC(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct badger_event_t *event = new_event(ctx);
+    if (event == NULL) {
+        return 0;
+    }
+    event->is_gc = 1;
+    bpf_map_update_elem(&badger_gcs, &key, event, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) RunValueLogGC(discardRatio float64) error
+SEC("uprobe/DB_RunValueLogGC")
+int uprobe_DB_RunValueLogGC_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_gcs, &key);
+    if (event == NULL) {
+        return 0;
+    }
+
+    event->end_time = get_time_ns();
+    output_span_event(ctx, event, sizeof(*event), &event->sc);
+    bpf_map_delete_elem(&badger_gcs, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (vlog *valueLog) rewrite(f *logFile) error
+SEC("uprobe/valueLog_rewrite")
+int uprobe_valueLog_rewrite_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct badger_event_t *event = bpf_map_lookup_elem(&badger_gcs, &key);
+    if (event == NULL) {
+        return 0;
+    }
+
+    // Garbage collections not rewriting any file return an error too, they
+    // only fail if the rewrite does.
+    if (get_argument(ctx, 1) != NULL) {
+        event->has_error = 1;
+    } else {
+        event->files_rewritten++;
+    }
+    return 0;
+}
@@ -0,0 +1,263 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package badger provides an instrumentation probe for the transactions and
+// value log garbage collections of embedded databases using the
+// [github.com/dgraph-io/badger/v4] package.
+package badger
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/dgraph-io/badger/v4"
+
+const (
+	// entriesKey is the attribute key of the number of entries set or
+	// deleted by a transaction.
+	entriesKey = attribute.Key("badger.txn.entries")
+	// bytesKey is the attribute key of the size, in bytes, of the entries
+	// written by a transaction, as estimated by badger.
+	bytesKey = attribute.Key("badger.txn.bytes")
+	// filesRewrittenKey is the attribute key of the number of value log files
+	// rewritten by a garbage collection.
+	filesRewrittenKey = attribute.Key("badger.gc.files_rewritten")
+	// keySizeKey is the attribute key of the size, in bytes, of the key of an
+	// operation of a transaction.
+	keySizeKey = attribute.Key("badger.key.size")
+	// valueSizeKey is the attribute key of the size, in bytes, of the value
+	// set by an operation of a transaction.
+	valueSizeKey = attribute.Key("badger.value.size")
+)
+
+// dbSystemNameBadger is the db.system.name attribute of badger. It is not
+// defined by the semantic conventions.
+var dbSystemNameBadger = semconv.DBSystemNameKey.String("badger")
+
+// minVersion is the first release of the github.com/dgraph-io/badger/v4
+// module.
+var minVersion = semver.New(4, 0, 1, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	const (
+		newTxn = pkg + ".(*DB).newTransaction"
+		gc     = pkg + ".(*DB).RunValueLogGC"
+	)
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("txn_size_pos", "Txn", "size"),
+				fieldConst("txn_count_pos", "Txn", "count"),
+				fieldConst("entry_key_pos", "Entry", "Key"),
+				fieldConst("entry_value_pos", "Entry", "Value"),
+				fieldConst("entry_meta_pos", "Entry", "meta"),
+			},
+			// The methods of DB are not linked if they are not used.
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                newTxn,
+					EntryProbe:         "uprobe_DB_newTransaction",
+					ReturnProbe:        "uprobe_DB_newTransaction_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					Sym:                pkg + ".(*Txn).Commit",
+					EntryProbe:         "uprobe_Txn_Commit",
+					ReturnProbe:        "uprobe_Txn_Commit_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{newTxn},
+				},
+				{
+					Sym:                pkg + ".(*Txn).Discard",
+					EntryProbe:         "uprobe_Txn_Discard",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{newTxn},
+				},
+				{
+					Sym:                pkg + ".(*Txn).modify",
+					EntryProbe:         "uprobe_Txn_modify",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{newTxn},
+				},
+				{
+					Sym:                pkg + ".(*Txn).Get",
+					EntryProbe:         "uprobe_Txn_Get",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{newTxn},
+				},
+				{
+					Sym:                gc,
+					EntryProbe:         "uprobe_DB_RunValueLogGC",
+					ReturnProbe:        "uprobe_DB_RunValueLogGC_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					Sym:                pkg + ".(*valueLog).rewrite",
+					ReturnProbe:        "uprobe_valueLog_rewrite_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{gc},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// The operations of a transaction. They need to be kept in sync with the
+// operations of the eBPF program.
+const (
+	opGet    = 1
+	opSet    = 2
+	opDelete = 3
+)
+
+// opNames are the names of the span events of the operations of a
+// transaction.
+var opNames = map[uint8]string{
+	opGet:    "get",
+	opSet:    "set",
+	opDelete: "delete",
+}
+
+// op is an operation of a transaction.
+type op struct {
+	Time      uint64
+	KeySize   uint64
+	ValueSize uint64
+	Op        uint8
+	_         [7]byte // padding
+}
+
+// event represents a transaction committed, or a value log garbage
+// collection.
+type event struct {
+	context.BaseSpanProperties
+	// Ops are the first operations of a transaction, in their order. Only the
+	// first OpsLen are valid.
+	Ops            [16]op
+	Entries        uint64
+	Bytes          uint64
+	OpsLen         uint32
+	OpsDropped     uint32
+	FilesRewritten uint32
+	IsGC           uint8
+	HasError       uint8
+	_              [2]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	var attrs []attribute.KeyValue
+	if e.IsGC != 0 {
+		span.SetName("gc")
+		attrs = []attribute.KeyValue{
+			dbSystemNameBadger,
+			semconv.DBOperationName("gc"),
+			filesRewrittenKey.Int(int(e.FilesRewritten)),
+		}
+	} else {
+		span.SetName("commit")
+		attrs = []attribute.KeyValue{
+			dbSystemNameBadger,
+			semconv.DBOperationName("commit"),
+			entriesKey.Int64(int64(min(e.Entries, math.MaxInt64))), // nolint: gosec  // Bounded.
+			bytesKey.Int64(int64(min(e.Bytes, math.MaxInt64))),     // nolint: gosec  // Bounded.
+		}
+
+		for _, o := range e.Ops[:min(int(e.OpsLen), len(e.Ops))] {
+			name, ok := opNames[o.Op]
+			if !ok {
+				continue
+			}
+			ev := span.Events().AppendEmpty()
+			ev.SetName(name)
+			ev.SetTimestamp(kernel.BootOffsetToTimestamp(o.Time))
+			opAttrs := []attribute.KeyValue{keySizeKey.Int64(int64(min(o.KeySize, math.MaxInt64)))} // nolint: gosec  // Bounded.
+			if o.Op == opSet {
+				opAttrs = append(opAttrs, valueSizeKey.Int64(int64(min(o.ValueSize, math.MaxInt64)))) // nolint: gosec  // Bounded.
+			}
+			pdataconv.Attributes(ev.Attributes(), opAttrs...)
+		}
+		span.SetDroppedEventsCount(e.OpsDropped)
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
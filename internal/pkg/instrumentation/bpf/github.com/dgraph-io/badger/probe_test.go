@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package badger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	opOffset := f.StartOffset + uint64(100*time.Millisecond)
+
+	newEvent := func(hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newCommit := func(hasError bool, ops ...op) *event {
+		e := newEvent(hasError)
+		e.Entries = 2
+		e.Bytes = 64
+		e.OpsLen = uint32(copy(e.Ops[:], ops)) // nolint: gosec  // Bounded.
+		return e
+	}
+
+	newGC := func(filesRewritten uint32, hasError bool) *event {
+		e := newEvent(hasError)
+		e.IsGC = 1
+		e.FilesRewritten = filesRewritten
+		return e
+	}
+
+	type spanEvent struct {
+		name  string
+		attrs []attribute.KeyValue
+	}
+
+	newSpans := func(name string, code ptrace.StatusCode, events []spanEvent, dropped uint32, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		spans := f.Spans(name, code, append([]attribute.KeyValue{dbSystemNameBadger}, attrs...)...)
+		span := spans.At(0)
+		for _, e := range events {
+			ev := span.Events().AppendEmpty()
+			ev.SetName(e.name)
+			ev.SetTimestamp(kernel.BootOffsetToTimestamp(opOffset))
+			pdataconv.Attributes(ev.Attributes(), e.attrs...)
+		}
+		span.SetDroppedEventsCount(dropped)
+		return spans
+	}
+
+	commit := []attribute.KeyValue{
+		semconv.DBOperationName("commit"),
+		entriesKey.Int64(2),
+		bytesKey.Int64(64),
+	}
+
+	ops := []op{
+		{Time: opOffset, KeySize: 3, Op: opGet},
+		{Time: opOffset, KeySize: 3, ValueSize: 5, Op: opSet},
+		{Time: opOffset, KeySize: 4, Op: opDelete},
+	}
+	events := []spanEvent{
+		{name: "get", attrs: []attribute.KeyValue{keySizeKey.Int64(3)}},
+		{name: "set", attrs: []attribute.KeyValue{keySizeKey.Int64(3), valueSizeKey.Int64(5)}},
+		{name: "delete", attrs: []attribute.KeyValue{keySizeKey.Int64(4)}},
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "commit",
+			event: newCommit(false, ops...),
+			want:  newSpans("commit", ptrace.StatusCodeUnset, events, 0, commit...),
+		},
+		{
+			name:  "commit error",
+			event: newCommit(true),
+			want:  newSpans("commit", ptrace.StatusCodeError, nil, 0, commit...),
+		},
+		{
+			name: "commit dropped ops",
+			event: func() *event {
+				e := newCommit(false, ops[0])
+				e.OpsDropped = 20
+				return e
+			}(),
+			want: newSpans("commit", ptrace.StatusCodeUnset, events[:1], 20, commit...),
+		},
+		{
+			name:  "gc",
+			event: newGC(1, false),
+			want: newSpans(
+				"gc",
+				ptrace.StatusCodeUnset,
+				nil,
+				0,
+				semconv.DBOperationName("gc"),
+				filesRewrittenKey.Int(1),
+			),
+		},
+		{
+			name:  "gc error",
+			event: newGC(0, true),
+			want: newSpans(
+				"gc",
+				ptrace.StatusCodeError,
+				nil,
+				0,
+				semconv.DBOperationName("gc"),
+				filesRewrittenKey.Int(0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
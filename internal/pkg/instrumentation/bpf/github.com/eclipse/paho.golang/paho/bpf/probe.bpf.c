@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_TOPIC_SIZE 256
+// The max size of the PublishProperties allocated for messages published
+// without properties.
+#define MAX_PROPERTIES_SIZE 256
+
+// The functions instrumented, they are used to end the span in the function
+// that started it.
+#define FUNC_PUBLISH 1
+#define FUNC_PUBLISH_WITH_OPTIONS 2
+
+struct mqtt_publish_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    u64 body_size;
+    u8 qos;
+    u8 retained;
+    u8 has_error;
+    u8 padding[5];
+};
+
+// The state of the messages being published, only the event is sent to user
+// space.
+struct mqtt_publish_state_t {
+    struct mqtt_publish_t event;
+    u8 func;
+};
+
+// https://github.com/eclipse/paho.golang/blob/v0.10.0/packets/properties.go#L75
+struct user_property_t {
+    struct go_string key;
+    struct go_string value;
+};
+
+struct publish_properties_t {
+    char buf[MAX_PROPERTIES_SIZE];
+};
+
+// Messages being published, keyed by the goroutine publishing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct mqtt_publish_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} mqtt_publishes SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct mqtt_publish_state_t));
+    __uint(max_entries, 1);
+} mqtt_storage_map SEC(".maps");
+
+#ifndef NO_HEADER_PROPAGATION
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct publish_properties_t));
+    __uint(max_entries, 1);
+} publish_properties_storage_map SEC(".maps");
+#endif
+
+// Injected in init
+volatile const u64 publish_qos_pos;
+volatile const u64 publish_retain_pos;
+volatile const u64 publish_topic_pos;
+volatile const u64 publish_properties_pos;
+volatile const u64 publish_payload_pos;
+volatile const u64 publish_properties_user_pos;
+
+#ifndef NO_HEADER_PROPAGATION
+// Returns the *PublishProperties of the message p, allocating them if the
+// message has none.
+static __always_inline void *get_publish_properties(void *p) {
+    void *properties = NULL;
+    bpf_probe_read_user(&properties, sizeof(properties), p + publish_properties_pos);
+    if (properties != NULL) {
+        return properties;
+    }
+
+    u64 size = publish_properties_user_pos + sizeof(struct go_slice);
+    if (size > MAX_PROPERTIES_SIZE) {
+        return NULL;
+    }
+    u32 zero = 0;
+    struct publish_properties_t *zero_properties = bpf_map_lookup_elem(&publish_properties_storage_map, &zero);
+    if (zero_properties == NULL) {
+        return NULL;
+    }
+    properties = write_target_data(zero_properties->buf, size);
+    if (properties == NULL) {
+        bpf_printk("get_publish_properties: failed to write properties");
+        return NULL;
+    }
+    long res = bpf_probe_write_user(p + publish_properties_pos, &properties, sizeof(properties));
+    if (res != 0) {
+        bpf_printk("get_publish_properties: failed to write properties pointer");
+        return NULL;
+    }
+    return properties;
+}
+
+// Appends the traceparent user property of the span context sc to the
+// properties of the message p.
+static __always_inline void inject_traceparent(void *p, struct span_context *sc) {
+    void *properties = get_publish_properties(p);
+    if (properties == NULL) {
+        return;
+    }
+
+    struct user_property_t property = {0};
+    char key[W3C_KEY_LENGTH] = "traceparent";
+    void *ptr = write_target_data(key, sizeof(key));
+    if (ptr == NULL) {
+        bpf_printk("inject_traceparent: failed to write key");
+        return;
+    }
+    property.key.str = ptr;
+    property.key.len = W3C_KEY_LENGTH;
+
+    char val[W3C_VAL_LENGTH];
+    span_context_to_w3c_string(sc, val);
+    ptr = write_target_data(val, sizeof(val));
+    if (ptr == NULL) {
+        bpf_printk("inject_traceparent: failed to write value");
+        return;
+    }
+    property.value.str = ptr;
+    property.value.len = W3C_VAL_LENGTH;
+
+    append_item_to_slice(&property, sizeof(property), properties + publish_properties_user_pos);
+}
+#endif
+
+static __always_inline void start_publish(struct pt_regs *ctx, u8 func) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&mqtt_publishes, &key) != NULL) {
+        // Publish calls PublishWithOptions since v0.20.0.
+        return;
+    }
+
+    u32 zero = 0;
+    struct mqtt_publish_state_t *state = bpf_map_lookup_elem(&mqtt_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("paho publish: state is NULL");
+        return;
+    }
+    __builtin_memset(state, 0, sizeof(struct mqtt_publish_state_t));
+    state->func = func;
+    struct mqtt_publish_t *publish = &state->event;
+    publish->start_time = get_time_ns();
+
+    void *p = get_argument(ctx, 4);
+    if (p == NULL) {
+        return;
+    }
+    bpf_probe_read_user(&publish->qos, sizeof(publish->qos), p + publish_qos_pos);
+    bpf_probe_read_user(&publish->retained, sizeof(publish->retained), p + publish_retain_pos);
+    get_go_string_from_user_ptr(p + publish_topic_pos, publish->topic, sizeof(publish->topic));
+    struct go_slice payload = {0};
+    bpf_probe_read_user(&payload, sizeof(payload), p + publish_payload_pos);
+    publish->body_size = payload.len;
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &publish->psc,
+        .sc = &publish->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+#ifndef NO_HEADER_PROPAGATION
+    inject_traceparent(p, &publish->sc);
+#endif
+
+    bpf_map_update_elem(&mqtt_publishes, &key, state, 0);
+}
+
+static __always_inline void end_publish(struct pt_regs *ctx, u8 func) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct mqtt_publish_state_t *state = bpf_map_lookup_elem(&mqtt_publishes, &key);
+    if (state == NULL || state->func != func) {
+        return;
+    }
+    struct mqtt_publish_t *publish = &state->event;
+    publish->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 2) != NULL) {
+        publish->has_error = 1;
+    }
+
+    output_span_event(ctx, publish, sizeof(*publish), &publish->sc);
+    bpf_map_delete_elem(&mqtt_publishes, &key);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Publish(ctx context.Context, p *Publish) (*PublishResponse, error)
+SEC("uprobe/Client_Publish")
+int uprobe_Client_Publish(struct pt_regs *ctx) {
+    start_publish(ctx, FUNC_PUBLISH);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Publish(ctx context.Context, p *Publish) (*PublishResponse, error)
+SEC("uprobe/Client_Publish")
+int uprobe_Client_Publish_Returns(struct pt_regs *ctx) {
+    end_publish(ctx, FUNC_PUBLISH);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) PublishWithOptions(ctx context.Context, p *Publish, o PublishOptions) (*PublishResponse, error)
+SEC("uprobe/Client_PublishWithOptions")
+int uprobe_Client_PublishWithOptions(struct pt_regs *ctx) {
+    start_publish(ctx, FUNC_PUBLISH_WITH_OPTIONS);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) PublishWithOptions(ctx context.Context, p *Publish, o PublishOptions) (*PublishResponse, error)
+SEC("uprobe/Client_PublishWithOptions")
+int uprobe_Client_PublishWithOptions_Returns(struct pt_regs *ctx) {
+    end_publish(ctx, FUNC_PUBLISH_WITH_OPTIONS);
+    return 0;
+}
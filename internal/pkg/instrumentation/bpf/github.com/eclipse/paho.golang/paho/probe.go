@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package paho provides an instrumentation probe for MQTT v5 publishers using
+// the [github.com/eclipse/paho.golang/paho] package.
+package paho
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf_no_tp ./bpf/probe.bpf.c -- -DNO_HEADER_PROPAGATION
+
+const (
+	// mod is the module of the package being instrumented.
+	mod = "github.com/eclipse/paho.golang"
+	// pkg is the package being instrumented.
+	pkg = mod + "/paho"
+)
+
+const (
+	// qosKey is the attribute key of the QoS level a message is published
+	// with.
+	qosKey = attribute.Key("messaging.mqtt.qos")
+	// retainedKey is the attribute key of the retain flag of a message.
+	retainedKey = attribute.Key("messaging.mqtt.retained")
+)
+
+// New returns a new [probe.Probe].
+//
+// A traceparent user property is added to the properties of the messages
+// published, the span ends when Publish returns, once the message is
+// acknowledged for a QoS of 1 or 2.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	const publish = pkg + ".(*Client).Publish"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "publish_qos_pos",
+					ID:  structfield.NewID(mod, pkg, "Publish", "QoS"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_retain_pos",
+					ID:  structfield.NewID(mod, pkg, "Publish", "Retain"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_topic_pos",
+					ID:  structfield.NewID(mod, pkg, "Publish", "Topic"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_properties_pos",
+					ID:  structfield.NewID(mod, pkg, "Publish", "Properties"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_payload_pos",
+					ID:  structfield.NewID(mod, pkg, "Publish", "Payload"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_properties_user_pos",
+					ID:  structfield.NewID(mod, pkg, "PublishProperties", "User"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         publish,
+					EntryProbe:  "uprobe_Client_Publish",
+					ReturnProbe: "uprobe_Client_Publish_Returns",
+				},
+				{
+					// Added in v0.12.0.
+					Sym:         pkg + ".(*Client).PublishWithOptions",
+					EntryProbe:  "uprobe_Client_PublishWithOptions",
+					ReturnProbe: "uprobe_Client_PublishWithOptions_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: verifyAndLoadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
+	if !kernel.SupportsContextPropagation() {
+		fmt.Fprintf(
+			os.Stderr,
+			"the Linux Kernel doesn't support context propagation, please check if the kernel is in lockdown mode (/sys/kernel/security/lockdown)",
+		)
+		return loadBpf_no_tp()
+	}
+
+	return loadBpf()
+}
+
+// event represents a message published by the client.
+type event struct {
+	context.BaseSpanProperties
+	Topic [256]byte
+	// BodySize is the size of the payload of the message.
+	BodySize uint64
+	QoS      uint8
+	Retained uint8
+	HasError uint8
+	_        [5]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("mqtt"),
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max packet size.
+		qosKey.Int(int(e.QoS)),
+		retainedKey.Bool(e.Retained != 0),
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(topic + " publish")
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
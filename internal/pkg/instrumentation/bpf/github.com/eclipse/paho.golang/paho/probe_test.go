@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package paho
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindProducer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	e := &event{
+		BaseSpanProperties: f.BaseSpanProperties(),
+		BodySize:           42,
+		QoS:                1,
+	}
+	copy(e.Topic[:], "sensors/temperature")
+
+	want := f.Spans(
+		"sensors/temperature publish",
+		ptrace.StatusCodeUnset,
+		semconv.MessagingSystemKey.String("mqtt"),
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName("sensors/temperature"),
+		semconv.MessagingMessageBodySize(42),
+		qosKey.Int(1),
+		retainedKey.Bool(false),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_PENDING 1024
+#define MAX_TOPIC_SIZE 256
+
+struct mqtt_receive_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    u64 body_size;
+    u8 qos;
+    u8 retained;
+    u8 padding[6];
+};
+
+// The span of a packet being decoded, only the event is sent to user space.
+struct mqtt_receive_state_t {
+    struct mqtt_receive_t event;
+    // The *packets.PublishPacket being decoded.
+    void *packet;
+};
+
+// Packets being decoded, keyed by the goroutine decoding them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct mqtt_receive_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} mqtt_receives SEC(".maps");
+
+// Messages decoded waiting for their handlers to be called, keyed by the data
+// pointer of their payload. The *message passed to the handlers shares its
+// payload with the *packets.PublishPacket it is created from.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct mqtt_receive_t);
+    __uint(max_entries, MAX_PENDING);
+} mqtt_dispatches SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct mqtt_receive_state_t));
+    __uint(max_entries, 1);
+} mqtt_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 publish_packet_fixed_header_pos;
+volatile const u64 publish_packet_topic_name_pos;
+volatile const u64 publish_packet_payload_pos;
+volatile const u64 fixed_header_qos_pos;
+volatile const u64 fixed_header_retain_pos;
+volatile const u64 message_payload_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *PublishPacket) Unpack(b io.Reader) error
+SEC("uprobe/PublishPacket_Unpack")
+int uprobe_PublishPacket_Unpack(struct pt_regs *ctx) {
+    u32 zero = 0;
+    struct mqtt_receive_state_t *state = bpf_map_lookup_elem(&mqtt_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("uprobe/PublishPacket_Unpack: state is NULL");
+        return 0;
+    }
+    __builtin_memset(state, 0, sizeof(struct mqtt_receive_state_t));
+    state->event.start_time = get_time_ns();
+    state->packet = get_argument(ctx, 1);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&mqtt_receives, &key, state, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *PublishPacket) Unpack(b io.Reader) error
+SEC("uprobe/PublishPacket_Unpack")
+int uprobe_PublishPacket_Unpack_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct mqtt_receive_state_t *state = bpf_map_lookup_elem(&mqtt_receives, &key);
+    if (state == NULL) {
+        return 0;
+    }
+
+    // The fields of packets failing to be decoded are not known.
+    if (get_argument(ctx, 1) != NULL) {
+        bpf_map_delete_elem(&mqtt_receives, &key);
+        return 0;
+    }
+
+    struct mqtt_receive_t *receive = &state->event;
+    // The end time is the one of the dispatch of messages with a payload.
+    receive->end_time = end_time;
+
+    void *packet = state->packet;
+    void *fixed_header = packet + publish_packet_fixed_header_pos;
+    bpf_probe_read_user(&receive->qos, sizeof(receive->qos), fixed_header + fixed_header_qos_pos);
+    bpf_probe_read_user(&receive->retained, sizeof(receive->retained), fixed_header + fixed_header_retain_pos);
+    get_go_string_from_user_ptr(packet + publish_packet_topic_name_pos, receive->topic, sizeof(receive->topic));
+    struct go_slice payload = {0};
+    bpf_probe_read_user(&payload, sizeof(payload), packet + publish_packet_payload_pos);
+    receive->body_size = payload.len;
+
+    // MQTT 3.1.1 messages have no properties to propagate a context with, the
+    // span is always a root span.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &receive->psc,
+        .sc = &receive->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    // Empty payloads all share the same data pointer, the dispatch of their
+    // messages cannot be told apart.
+    if (payload.len == 0 || payload.array == NULL) {
+        output_span_event(ctx, receive, sizeof(*receive), &receive->sc);
+    } else {
+        bpf_map_update_elem(&mqtt_dispatches, &payload.array, receive, 0);
+    }
+    bpf_map_delete_elem(&mqtt_receives, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (m *message) Ack()
+SEC("uprobe/message_Ack")
+int uprobe_message_Ack(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *m = get_argument(ctx, 1);
+    void *payload = NULL;
+    bpf_probe_read_user(&payload, sizeof(payload), m + message_payload_pos);
+    if (payload == NULL) {
+        return 0;
+    }
+
+    // The message is acknowledged once its handlers return, unless automatic
+    // acknowledgment is disabled. Only the first call is recorded.
+    struct mqtt_receive_t *receive = bpf_map_lookup_elem(&mqtt_dispatches, &payload);
+    if (receive == NULL) {
+        return 0;
+    }
+    receive->end_time = end_time;
+
+    output_span_event(ctx, receive, sizeof(*receive), &receive->sc);
+    bpf_map_delete_elem(&mqtt_dispatches, &payload);
+    return 0;
+}
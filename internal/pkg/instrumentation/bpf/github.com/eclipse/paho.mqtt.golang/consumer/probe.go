@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consumer provides an instrumentation probe for MQTT subscribers
+// using the [github.com/eclipse/paho.mqtt.golang] package.
+package consumer
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/eclipse/paho.mqtt.golang"
+	// packetsPkg is the package of the MQTT packets decoded by the client.
+	packetsPkg = pkg + "/packets"
+)
+
+// symPkg is pkg as it is named in the symbols of a binary.
+var symPkg = process.LinkerPkgPath(pkg)
+
+const (
+	// qosKey is the attribute key of the QoS level a message is published
+	// with.
+	qosKey = attribute.Key("messaging.mqtt.qos")
+	// retainedKey is the attribute key of the retain flag of a message.
+	retainedKey = attribute.Key("messaging.mqtt.retained")
+)
+
+// New returns a new [probe.Probe].
+//
+// The span of a message starts when it is received, and ends when the
+// router acknowledges it once the handlers it is dispatched to return, or when
+// the application acknowledges it if automatic acknowledgment is disabled.
+// Messages with an empty payload are not tracked through their dispatch, their
+// span ends when they are received. MQTT 3.1.1 messages have no properties to
+// propagate a context with, the spans are root spans.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	const unpack = packetsPkg + ".(*PublishPacket).Unpack"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "publish_packet_fixed_header_pos",
+					ID:  structfield.NewID(pkg, packetsPkg, "PublishPacket", "FixedHeader"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_packet_topic_name_pos",
+					ID:  structfield.NewID(pkg, packetsPkg, "PublishPacket", "TopicName"),
+				},
+				probe.StructFieldConst{
+					Key: "publish_packet_payload_pos",
+					ID:  structfield.NewID(pkg, packetsPkg, "PublishPacket", "Payload"),
+				},
+				probe.StructFieldConst{
+					Key: "fixed_header_qos_pos",
+					ID:  structfield.NewID(pkg, packetsPkg, "FixedHeader", "Qos"),
+				},
+				probe.StructFieldConst{
+					Key: "fixed_header_retain_pos",
+					ID:  structfield.NewID(pkg, packetsPkg, "FixedHeader", "Retain"),
+				},
+				probe.StructFieldConst{
+					Key: "message_payload_pos",
+					ID:  structfield.NewID(pkg, pkg, "message", "payload"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         unpack,
+					EntryProbe:  "uprobe_PublishPacket_Unpack",
+					ReturnProbe: "uprobe_PublishPacket_Unpack_Returns",
+				},
+				{
+					Sym:        symPkg + ".(*message).Ack",
+					EntryProbe: "uprobe_message_Ack",
+					DependsOn:  []string{unpack},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a message delivered to the client.
+type event struct {
+	context.BaseSpanProperties
+	Topic [256]byte
+	// BodySize is the size of the payload of the message.
+	BodySize uint64
+	QoS      uint8
+	Retained uint8
+	_        [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("mqtt"),
+		semconv.MessagingOperationTypeProcess,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max packet size.
+		qosKey.Int(int(e.QoS)),
+		retainedKey.Bool(e.Retained != 0),
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(topic + " process")
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
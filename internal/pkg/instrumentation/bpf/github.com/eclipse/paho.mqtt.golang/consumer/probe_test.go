@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+
+	e := &event{
+		BaseSpanProperties: f.BaseSpanProperties(),
+		BodySize:           42,
+		QoS:                2,
+	}
+	copy(e.Topic[:], "sensors/temperature")
+
+	want := f.Spans(
+		"sensors/temperature process",
+		ptrace.StatusCodeUnset,
+		semconv.MessagingSystemKey.String("mqtt"),
+		semconv.MessagingOperationTypeProcess,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName("sensors/temperature"),
+		semconv.MessagingMessageBodySize(42),
+		qosKey.Int(2),
+		retainedKey.Bool(false),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
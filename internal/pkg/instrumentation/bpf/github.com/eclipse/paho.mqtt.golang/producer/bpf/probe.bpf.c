@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_PENDING 1024
+// https://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html#_Toc398718106
+// No constraint on the topic size below 65535, but we must have a limit for
+// the verifier.
+#define MAX_TOPIC_SIZE 256
+
+struct mqtt_publish_t {
+    BASE_SPAN_PROPERTIES
+    char topic[MAX_TOPIC_SIZE];
+    u64 body_size;
+    u8 qos;
+    u8 retained;
+    u8 has_error;
+    u8 padding[5];
+};
+
+// The completion of a token that has no pending message.
+struct token_completion_t {
+    u64 end_time;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// Messages being published, keyed by the goroutine publishing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct mqtt_publish_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} mqtt_publishes SEC(".maps");
+
+// Messages published with a QoS of 1 or 2 waiting for their acknowledgment,
+// keyed by their *PublishToken.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct mqtt_publish_t);
+    __uint(max_entries, MAX_PENDING);
+} mqtt_pending SEC(".maps");
+
+// Tokens completed by the network goroutines, keyed by their *PublishToken.
+// The acknowledgment of a message can be received before the Publish call
+// returns its token.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct token_completion_t);
+    __uint(max_entries, MAX_PENDING);
+} mqtt_completed SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct mqtt_publish_t));
+    __uint(max_entries, 1);
+} mqtt_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 base_token_err_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *client) Publish(topic string, qos byte, retained bool, payload interface{}) Token
+SEC("uprobe/client_Publish")
+int uprobe_client_Publish(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&mqtt_publishes, &key) != NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct mqtt_publish_t *publish = bpf_map_lookup_elem(&mqtt_storage_map, &zero);
+    if (publish == NULL) {
+        bpf_printk("uprobe/client_Publish: publish is NULL");
+        return 0;
+    }
+    __builtin_memset(publish, 0, sizeof(struct mqtt_publish_t));
+    publish->start_time = get_time_ns();
+
+    void *topic_ptr = get_argument(ctx, 2);
+    u64 topic_len = (u64)get_argument(ctx, 3);
+    u64 topic_size = MAX_TOPIC_SIZE < topic_len ? MAX_TOPIC_SIZE : topic_len;
+    bpf_probe_read_user(publish->topic, topic_size, topic_ptr);
+    publish->qos = (u8)(u64)get_argument(ctx, 4);
+    publish->retained = (u8)(u64)get_argument(ctx, 5);
+
+    // The payload is a string, a []byte or a bytes.Buffer. Their length
+    // follows the pointer to their data in all cases.
+    void *payload = get_argument(ctx, 7);
+    if (payload != NULL) {
+        bpf_probe_read_user(&publish->body_size, sizeof(publish->body_size), payload + 8);
+    }
+
+    // The client does not accept a context.Context, the span is always a
+    // root span.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &publish->psc,
+        .sc = &publish->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&mqtt_publishes, &key, publish, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *client) Publish(topic string, qos byte, retained bool, payload interface{}) Token
+SEC("uprobe/client_Publish")
+int uprobe_client_Publish_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct mqtt_publish_t *publish = bpf_map_lookup_elem(&mqtt_publishes, &key);
+    if (publish == NULL) {
+        return 0;
+    }
+    publish->end_time = end_time;
+
+    // The returned Token holds a *PublishToken, its baseToken is its first
+    // field.
+    void *token = get_argument(ctx, 2);
+    struct go_iface err = {0};
+    bpf_probe_read_user(&err, sizeof(err), token + base_token_err_pos);
+    if (err.type != NULL) {
+        publish->has_error = 1;
+    } else if (publish->qos > 0) {
+        struct token_completion_t *completion = bpf_map_lookup_elem(&mqtt_completed, &token);
+        // Completions of the tokens of QoS 0 messages are never consumed, a
+        // completion recorded before the call is the one of a previous token
+        // allocated at the same address.
+        if (completion == NULL || completion->end_time < publish->start_time) {
+            // The span ends once the message is acknowledged.
+            bpf_map_update_elem(&mqtt_pending, &token, publish, 0);
+            bpf_map_delete_elem(&mqtt_publishes, &key);
+            return 0;
+        }
+        publish->end_time = completion->end_time;
+        publish->has_error = completion->has_error;
+        bpf_map_delete_elem(&mqtt_completed, &token);
+    }
+
+    output_span_event(ctx, publish, sizeof(*publish), &publish->sc);
+    bpf_map_delete_elem(&mqtt_publishes, &key);
+    return 0;
+}
+
+static __always_inline void complete_token(struct pt_regs *ctx, u8 has_error) {
+    u64 end_time = get_time_ns();
+    void *token = get_argument(ctx, 1);
+    struct mqtt_publish_t *publish = bpf_map_lookup_elem(&mqtt_pending, &token);
+    if (publish == NULL) {
+        // The token may be completed before Publish returns it.
+        struct token_completion_t completion = {
+            .end_time = end_time,
+            .has_error = has_error,
+        };
+        bpf_map_update_elem(&mqtt_completed, &token, &completion, 0);
+        return;
+    }
+    publish->end_time = end_time;
+    publish->has_error = has_error;
+
+    output_span_event(ctx, publish, sizeof(*publish), &publish->sc);
+    bpf_map_delete_elem(&mqtt_pending, &token);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *PublishToken) flowComplete()
+SEC("uprobe/PublishToken_flowComplete")
+int uprobe_PublishToken_flowComplete(struct pt_regs *ctx) {
+    complete_token(ctx, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *PublishToken) setError(e error)
+SEC("uprobe/PublishToken_setError")
+int uprobe_PublishToken_setError(struct pt_regs *ctx) {
+    complete_token(ctx, 1);
+    return 0;
+}
@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides an instrumentation probe for MQTT publishers using
+// the [github.com/eclipse/paho.mqtt.golang] package.
+package producer
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/eclipse/paho.mqtt.golang"
+
+// symPkg is pkg as it is named in the symbols of a binary.
+var symPkg = process.LinkerPkgPath(pkg)
+
+const (
+	// qosKey is the attribute key of the QoS level a message is published
+	// with.
+	qosKey = attribute.Key("messaging.mqtt.qos")
+	// retainedKey is the attribute key of the retain flag of a message.
+	retainedKey = attribute.Key("messaging.mqtt.retained")
+)
+
+// New returns a new [probe.Probe].
+//
+// The span of a message published with a QoS of 0 ends when Publish returns.
+// The span of a message published with a QoS of 1 or 2 ends when its token
+// completes, once the message is acknowledged by the broker or failed to be
+// delivered. MQTT 3.1.1 messages have no properties to propagate a context
+// with, and the client does not accept a context, the spans are root spans.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	const publish = ".(*client).Publish"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "base_token_err_pos",
+					ID:  structfield.NewID(pkg, pkg, "baseToken", "err"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         symPkg + publish,
+					EntryProbe:  "uprobe_client_Publish",
+					ReturnProbe: "uprobe_client_Publish_Returns",
+				},
+				{
+					Sym:        symPkg + ".(*PublishToken).flowComplete",
+					EntryProbe: "uprobe_PublishToken_flowComplete",
+					DependsOn:  []string{symPkg + publish},
+				},
+				{
+					Sym:        symPkg + ".(*PublishToken).setError",
+					EntryProbe: "uprobe_PublishToken_setError",
+					DependsOn:  []string{symPkg + publish},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a message published by the client.
+type event struct {
+	context.BaseSpanProperties
+	Topic [256]byte
+	// BodySize is the size of the payload of the message.
+	BodySize uint64
+	QoS      uint8
+	Retained uint8
+	HasError uint8
+	_        [5]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	topic := unix.ByteSliceToString(e.Topic[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("mqtt"),
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max packet size.
+		qosKey.Int(int(e.QoS)),
+		retainedKey.Bool(e.Retained != 0),
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(topic + " publish")
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "http_client.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_METHOD_SIZE 16
+#define MAX_PATH_SIZE 128
+#define MAX_HOSTNAME_SIZE 128
+#define MAX_CONCURRENT 50
+
+struct es_request_t {
+    BASE_SPAN_PROPERTIES
+    char method[MAX_METHOD_SIZE];
+    char path[MAX_PATH_SIZE];
+    char host[MAX_HOSTNAME_SIZE];
+    u64 status_code;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// The state of the requests being performed, only the event is sent to user
+// space.
+struct es_request_state_t {
+    struct es_request_t event;
+    // The *http.Request being performed.
+    void *req;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct es_request_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} es_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct es_request_state_t));
+    __uint(max_entries, 1);
+} es_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 method_ptr_pos;
+volatile const u64 url_ptr_pos;
+volatile const u64 path_ptr_pos;
+volatile const u64 url_host_pos;
+volatile const u64 ctx_ptr_pos;
+volatile const u64 status_code_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Perform(req *http.Request) (*http.Response, error)
+// or, since v8.4.0:
+// func (c *BaseClient) Perform(req *http.Request) (*http.Response, error)
+SEC("uprobe/Client_Perform")
+int uprobe_Client_Perform(struct pt_regs *ctx) {
+    u64 request_pos = 2;
+
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&es_events, &key) != NULL) {
+        bpf_printk("uprobe/Client_Perform already tracked with the current goroutine");
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct es_request_state_t *state = bpf_map_lookup_elem(&es_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("uprobe/Client_Perform: state is NULL");
+        return 0;
+    }
+    __builtin_memset(state, 0, sizeof(struct es_request_state_t));
+    struct es_request_t *es_request = &state->event;
+    es_request->start_time = get_time_ns();
+
+    void *req_ptr = get_argument(ctx, request_pos);
+    state->req = req_ptr;
+    get_go_string_from_user_ptr((void *)(req_ptr + method_ptr_pos), es_request->method, sizeof(es_request->method));
+
+    // The path is read before the transport prefixes it with the path of the
+    // URL of the node the request is sent to.
+    void *url_ptr = NULL;
+    bpf_probe_read_user(&url_ptr, sizeof(url_ptr), (void *)(req_ptr + url_ptr_pos));
+    if (url_ptr != NULL) {
+        get_go_string_from_user_ptr((void *)(url_ptr + path_ptr_pos), es_request->path, sizeof(es_request->path));
+    }
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, request_pos, ctx_ptr_pos, false, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &es_request->psc,
+        .sc = &es_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&es_events, &key, state, 0);
+    // The requests sent by the transport of the client are part of this span.
+    set_http_client_owner(key, &es_request->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Perform(req *http.Request) (*http.Response, error)
+// or, since v8.4.0:
+// func (c *BaseClient) Perform(req *http.Request) (*http.Response, error)
+SEC("uprobe/Client_Perform")
+int uprobe_Client_Perform_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct es_request_state_t *state = bpf_map_lookup_elem(&es_events, &key);
+    if (state == NULL) {
+        bpf_printk("uprobe/Client_Perform_Returns: state is NULL");
+        return 0;
+    }
+    struct es_request_t *es_request = &state->event;
+    es_request->end_time = end_time;
+
+    void *resp_ptr = get_argument(ctx, 1);
+    if (resp_ptr != NULL) {
+        s64 status_code = 0;
+        bpf_probe_read_user(&status_code, sizeof(status_code), (void *)(resp_ptr + status_code_pos));
+        es_request->status_code = status_code;
+    }
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 2) != NULL) {
+        es_request->has_error = 1;
+    }
+
+    // The transport sets the host of the URL of the request to the one of the
+    // node it was last sent to.
+    void *url_ptr = NULL;
+    bpf_probe_read_user(&url_ptr, sizeof(url_ptr), (void *)(state->req + url_ptr_pos));
+    if (url_ptr != NULL) {
+        get_go_string_from_user_ptr((void *)(url_ptr + url_host_pos), es_request->host, sizeof(es_request->host));
+    }
+
+    output_span_event(ctx, es_request, sizeof(*es_request), &es_request->sc);
+    delete_http_client_owner(key);
+    bpf_map_delete_elem(&es_events, &key);
+    return 0;
+}
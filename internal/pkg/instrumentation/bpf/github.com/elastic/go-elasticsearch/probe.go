@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package elasticsearch provides instrumentation probes for Elasticsearch
+// clients using the [github.com/elastic/go-elasticsearch/v7] and
+// [github.com/elastic/go-elasticsearch/v8] packages.
+package elasticsearch
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkgV7 is the package being instrumented by the probe returned by
+	// NewV7.
+	pkgV7 = "github.com/elastic/go-elasticsearch/v7"
+	// pkgV8 is the package being instrumented by the probe returned by
+	// NewV8.
+	pkgV8 = "github.com/elastic/go-elasticsearch/v8"
+)
+
+// NewV7 returns a new [probe.Probe] for the v7 client.
+func NewV7(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgV7, []*probe.Uprobe{
+		newUprobe(pkgV7, ".(*Client).Perform", ">= 7.0.0"),
+	})
+}
+
+// NewV8 returns a new [probe.Probe] for the v8 client.
+func NewV8(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgV8, []*probe.Uprobe{
+		newUprobe(pkgV8, ".(*Client).Perform", ">= 8.0.0, < 8.4.0"),
+		// The Perform method is implemented by the BaseClient embedded in
+		// the Client and the TypedClient since v8.4.0.
+		newUprobe(pkgV8, ".(*BaseClient).Perform", ">= 8.4.0"),
+	})
+}
+
+// newUprobe returns the [probe.Uprobe] of the Perform method of the client
+// of pkg, attached if the version of pkg matches constraint.
+func newUprobe(pkg, method, constraint string) *probe.Uprobe {
+	return &probe.Uprobe{
+		Sym:         pkg + method,
+		EntryProbe:  "uprobe_Client_Perform",
+		ReturnProbe: "uprobe_Client_Perform_Returns",
+		PackageConstraints: []probe.PackageConstraints{
+			{
+				Package: pkg,
+				Constraints: func() *semver.Constraints {
+					c, err := semver.NewConstraint(constraint)
+					if err != nil {
+						panic(err)
+					}
+					return c
+				}(),
+				FailureMode: probe.FailureModeIgnore,
+			},
+		},
+	}
+}
+
+func newProbe(logger *slog.Logger, version, pkg string, uprobes []*probe.Uprobe) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "method_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "Method"),
+				},
+				probe.StructFieldConst{
+					Key: "url_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "URL"),
+				},
+				probe.StructFieldConst{
+					Key: "path_ptr_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Path"),
+				},
+				probe.StructFieldConst{
+					Key: "url_host_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Host"),
+				},
+				probe.StructFieldConst{
+					Key: "ctx_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "ctx"),
+				},
+				probe.StructFieldConst{
+					Key: "status_code_pos",
+					ID:  structfield.NewID("std", "net/http", "Response", "StatusCode"),
+				},
+			},
+			Uprobes: uprobes,
+			SpecFn:  loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a request performed by an Elasticsearch client.
+type event struct {
+	context.BaseSpanProperties
+	// Method is the HTTP method of the request.
+	Method [16]byte
+	// Path is the path of the request, without the path prefix of the node
+	// it was sent to.
+	Path [128]byte
+	// Host is the host of the node the request was last sent to.
+	Host [128]byte
+	// StatusCode is the status code of the response, zero if none was
+	// received.
+	StatusCode uint64
+	HasError   uint8
+	_          [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	method := unix.ByteSliceToString(e.Method[:])
+	path := unix.ByteSliceToString(e.Path[:])
+	index := targetIndex(path)
+
+	attrs := []attribute.KeyValue{semconv.DBSystemNameElasticsearch}
+	if method != "" {
+		attrs = append(attrs, semconv.DBOperationName(method), semconv.HTTPRequestMethodKey.String(method))
+	}
+	if path != "" {
+		attrs = append(attrs, semconv.URLPath(path))
+	}
+	if index != "" {
+		attrs = append(attrs, semconv.DBCollectionName(index))
+	}
+	if e.StatusCode > 0 {
+		attrs = append(attrs, semconv.DBResponseStatusCode(strconv.FormatUint(e.StatusCode, 10)))
+	}
+
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:]))
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(method, index))
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	// Elasticsearch reports failed operations with error status codes, they
+	// are not returned as errors by the client.
+	if e.HasError != 0 || e.StatusCode >= 400 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// targetIndex returns the indices, or data streams, targeted by the request
+// with path, e.g. "my-index" for "/my-index/_search". An empty string is
+// returned for requests not targeting an index (e.g. "/_cluster/health").
+func targetIndex(path string) string {
+	target, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if strings.HasPrefix(target, "_") {
+		return ""
+	}
+	return target
+}
+
+// spanName returns the name of a span following the semantic conventions:
+// "{db.operation.name} {target}", where target is the index, if known.
+func spanName(method, index string) string {
+	switch {
+	case method == "":
+		return semconv.DBSystemNameElasticsearch.Value.AsString()
+	case index != "":
+		return method + " " + index
+	default:
+		return method
+	}
+}
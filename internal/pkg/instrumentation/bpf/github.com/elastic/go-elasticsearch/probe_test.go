@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestTargetIndex(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/my-index/_search", want: "my-index"},
+		{path: "/my-index/_doc/1", want: "my-index"},
+		{path: "/logs-1,logs-2/_search", want: "logs-1,logs-2"},
+		{path: "/my-index", want: "my-index"},
+		{path: "/_search"},
+		{path: "/_cluster/health"},
+		{path: "/"},
+		{path: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, targetIndex(tt.path))
+		})
+	}
+}
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(method, path, host string, statusCode uint64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			StatusCode:         statusCode,
+		}
+		copy(e.Method[:], method)
+		copy(e.Path[:], path)
+		copy(e.Host[:], host)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "index",
+			event: newEvent("POST", "/my-index/_search", "localhost:9200", 200, false),
+			want: f.Spans(
+				"POST my-index",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameElasticsearch,
+				semconv.DBOperationName("POST"),
+				semconv.HTTPRequestMethodKey.String("POST"),
+				semconv.URLPath("/my-index/_search"),
+				semconv.DBCollectionName("my-index"),
+				semconv.DBResponseStatusCode("200"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(9200),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "no index",
+			event: newEvent("GET", "/_cluster/health", "10.0.0.1:9200", 200, false),
+			want: f.Spans(
+				"GET",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameElasticsearch,
+				semconv.DBOperationName("GET"),
+				semconv.HTTPRequestMethodKey.String("GET"),
+				semconv.URLPath("/_cluster/health"),
+				semconv.DBResponseStatusCode("200"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(9200),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "error status code",
+			event: newEvent("PUT", "/my-index/_doc/1", "localhost:9200", 400, false),
+			want: f.Spans(
+				"PUT my-index",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameElasticsearch,
+				semconv.DBOperationName("PUT"),
+				semconv.HTTPRequestMethodKey.String("PUT"),
+				semconv.URLPath("/my-index/_doc/1"),
+				semconv.DBCollectionName("my-index"),
+				semconv.DBResponseStatusCode("400"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(9200),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("GET", "/my-index/_doc/1", "", 0, true),
+			want: f.Spans(
+				"GET my-index",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameElasticsearch,
+				semconv.DBOperationName("GET"),
+				semconv.HTTPRequestMethodKey.String("GET"),
+				semconv.URLPath("/my-index/_doc/1"),
+				semconv.DBCollectionName("my-index"),
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", "", "", 0, false),
+			want:  f.Spans("elasticsearch", ptrace.StatusCodeUnset, semconv.DBSystemNameElasticsearch),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_QUERY_SIZE 256
+// Keyspace names are at most 48 characters.
+#define MAX_KEYSPACE_SIZE 64
+#define MAX_ADDR_SIZE 128
+#define MAX_CONCURRENT 50
+
+struct gocql_request_t {
+    BASE_SPAN_PROPERTIES
+    char query[MAX_QUERY_SIZE];
+    char keyspace[MAX_KEYSPACE_SIZE];
+    // The address of the coordinator of the last attempt.
+    char addr[MAX_ADDR_SIZE];
+    // The number of statements of a batch.
+    u64 batch_size;
+    u32 attempts;
+    u8 is_batch;
+    u8 has_error;
+    u8 padding[2];
+};
+
+// Queries and batches being executed, keyed by the goroutine executing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct gocql_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} gocql_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct gocql_request_t));
+    __uint(max_entries, 1);
+} gocql_storage_map SEC(".maps");
+
+// Injected in init
+volatile const bool should_include_db_statement;
+
+volatile const u64 query_stmt_pos;
+volatile const u64 query_context_pos;
+volatile const u64 batch_entries_pos;
+volatile const u64 batch_context_pos;
+volatile const u64 batch_keyspace_pos;
+volatile const u64 session_cfg_pos;
+volatile const u64 cluster_config_keyspace_pos;
+volatile const u64 iter_err_pos;
+volatile const u64 conn_addr_pos;
+
+// Returns the zeroed request to start for the goroutine, or NULL if the
+// goroutine is already executing a request.
+static __always_inline struct gocql_request_t *new_request(void *key) {
+    if (bpf_map_lookup_elem(&gocql_events, &key) != NULL) {
+        return NULL;
+    }
+
+    u32 zero = 0;
+    struct gocql_request_t *req = bpf_map_lookup_elem(&gocql_storage_map, &zero);
+    if (req == NULL) {
+        bpf_printk("gocql: request is NULL");
+        return NULL;
+    }
+    __builtin_memset(req, 0, sizeof(struct gocql_request_t));
+    req->start_time = get_time_ns();
+    return req;
+}
+
+// Starts the span of a query, or batch, executed with the context.Context at
+// context_pos of the query.
+static __always_inline void start_request(struct pt_regs *ctx, void *key, struct gocql_request_t *req, const volatile u64 context_pos) {
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, context_pos, false, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&gocql_events, &key, req, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Session) executeQuery(qry *Query) (it *Iter)
+SEC("uprobe/Session_executeQuery")
+int uprobe_Session_executeQuery(struct pt_regs *ctx) {
+    void *session = get_argument(ctx, 1);
+    void *query = get_argument(ctx, 2);
+    if (session == NULL || query == NULL) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct gocql_request_t *req = new_request(key);
+    if (req == NULL) {
+        return 0;
+    }
+    if (should_include_db_statement) {
+        get_go_string_from_user_ptr((void *)(query + query_stmt_pos), req->query, sizeof(req->query));
+    }
+    get_go_string_from_user_ptr((void *)(session + session_cfg_pos + cluster_config_keyspace_pos), req->keyspace, sizeof(req->keyspace));
+
+    start_request(ctx, key, req, query_context_pos);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Session) executeBatch(batch *Batch) *Iter
+SEC("uprobe/Session_executeBatch")
+int uprobe_Session_executeBatch(struct pt_regs *ctx) {
+    void *batch = get_argument(ctx, 2);
+    if (batch == NULL) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct gocql_request_t *req = new_request(key);
+    if (req == NULL) {
+        return 0;
+    }
+    req->is_batch = 1;
+    bpf_probe_read_user(&req->batch_size, sizeof(req->batch_size), (void *)(batch + batch_entries_pos + offsetof(struct go_slice, len)));
+    get_go_string_from_user_ptr((void *)(batch + batch_keyspace_pos), req->keyspace, sizeof(req->keyspace));
+
+    start_request(ctx, key, req, batch_context_pos);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (q *queryExecutor) attemptQuery(ctx context.Context, qry ExecutableQuery, conn *Conn) *Iter
+//
+// With a speculative execution policy, the attempts of idempotent queries are
+// all made by other goroutines, they are not recorded.
+SEC("uprobe/queryExecutor_attemptQuery")
+int uprobe_queryExecutor_attemptQuery(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct gocql_request_t *req = bpf_map_lookup_elem(&gocql_events, &key);
+    if (req == NULL) {
+        return 0;
+    }
+
+    req->attempts++;
+    void *conn = get_argument(ctx, 6);
+    if (conn != NULL) {
+        get_go_string_from_user_ptr((void *)(conn + conn_addr_pos), req->addr, sizeof(req->addr));
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (s *Session) executeQuery(qry *Query) (it *Iter)
+// func (s *Session) executeBatch(batch *Batch) *Iter
+SEC("uprobe/Session_execute")
+int uprobe_Session_execute_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct gocql_request_t *req = bpf_map_lookup_elem(&gocql_events, &key);
+    if (req == NULL) {
+        return 0;
+    }
+    req->end_time = end_time;
+
+    void *iter = get_argument(ctx, 1);
+    if (iter != NULL) {
+        void *err_type = NULL;
+        bpf_probe_read_user(&err_type, sizeof(err_type), (void *)(iter + iter_err_pos));
+        req->has_error = err_type != NULL;
+    }
+
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    bpf_map_delete_elem(&gocql_events, &key);
+    return 0;
+}
@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gocql provides an instrumentation probe for Cassandra clients using
+// the [github.com/gocql/gocql] package.
+package gocql
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/gocql/gocql"
+
+// retryCountKey is the attribute key of the number of times a query, or
+// batch, was retried after its first attempt.
+const retryCountKey = attribute.Key("cassandra.retry.count")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	offset := func(key, typ, field string) probe.Const {
+		return probe.StructFieldConst{
+			Key: key,
+			ID:  structfield.NewID(pkg, pkg, typ, field),
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{
+					Key: "should_include_db_statement",
+					Val: shouldIncludeDBStatement(),
+				},
+				offset("query_stmt_pos", "Query", "stmt"),
+				offset("query_context_pos", "Query", "context"),
+				offset("batch_entries_pos", "Batch", "Entries"),
+				offset("batch_context_pos", "Batch", "context"),
+				offset("batch_keyspace_pos", "Batch", "keyspace"),
+				offset("session_cfg_pos", "Session", "cfg"),
+				offset("cluster_config_keyspace_pos", "ClusterConfig", "Keyspace"),
+				offset("iter_err_pos", "Iter", "err"),
+				offset("conn_addr_pos", "Conn", "addr"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         pkg + ".(*Session).executeQuery",
+					EntryProbe:  "uprobe_Session_executeQuery",
+					ReturnProbe: "uprobe_Session_execute_Returns",
+				},
+				{
+					Sym:         pkg + ".(*Session).executeBatch",
+					EntryProbe:  "uprobe_Session_executeBatch",
+					ReturnProbe: "uprobe_Session_execute_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// The retries are not recorded if the attempts are not
+					// instrumented.
+					Sym:         pkg + ".(*queryExecutor).attemptQuery",
+					EntryProbe:  "uprobe_queryExecutor_attemptQuery",
+					FailureMode: probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a query, or batch, executed by a gocql Session.
+type event struct {
+	context.BaseSpanProperties
+	// Query is the query text, if configured to be included. It is empty for
+	// batches.
+	Query    [256]byte
+	Keyspace [64]byte
+	// Addr is the "host:port" address of the coordinator of the last
+	// attempt.
+	Addr [128]byte
+	// BatchSize is the number of statements of a batch.
+	BatchSize uint64
+	// Attempts is the number of attempts made to execute the query.
+	Attempts uint32
+	IsBatch  uint8
+	HasError uint8
+	_        [2]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameCassandra}
+
+	name := semconv.DBSystemNameCassandra.Value.AsString()
+	keyspace := unix.ByteSliceToString(e.Keyspace[:])
+	if keyspace != "" {
+		attrs = append(attrs, semconv.DBNamespace(keyspace))
+		name = keyspace
+	}
+
+	if e.IsBatch != 0 {
+		name = "BATCH"
+		attrs = append(
+			attrs,
+			semconv.DBOperationName(name),
+			semconv.DBOperationBatchSize(int(min(e.BatchSize, math.MaxInt))), // nolint: gosec  // Bounded.
+		)
+	} else if query := unix.ByteSliceToString(e.Query[:]); query != "" {
+		attrs = append(attrs, semconv.DBQueryText(query))
+
+		// The CQL statements share their syntax with the SQL statements
+		// parsed.
+		if shouldParseDBStatement() {
+			operation, target, err := sql.Parse(query)
+			if err == nil && operation != "" {
+				attrs = append(attrs, semconv.DBOperationName(operation))
+				name = operation
+				if target != "" {
+					attrs = append(attrs, semconv.DBCollectionName(target))
+					name += " " + target
+				}
+			}
+		}
+	}
+
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Addr[:]))
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	if e.Attempts > 1 {
+		attrs = append(attrs, retryCountKey.Int(int(e.Attempts-1)))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// shouldIncludeDBStatement returns if the user has configured queries to be
+// included.
+func shouldIncludeDBStatement() bool {
+	return envBool(sql.IncludeDBStatementEnvVar)
+}
+
+// shouldParseDBStatement returns if the user has configured queries to be
+// parsed for their operation and table.
+func shouldParseDBStatement() bool {
+	return envBool(sql.ParseDBStatementEnvVar)
+}
+
+func envBool(key string) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && val
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gocql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(query, keyspace, addr string, attempts uint32, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Attempts:           attempts,
+		}
+		copy(e.Query[:], query)
+		copy(e.Keyspace[:], keyspace)
+		copy(e.Addr[:], addr)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newBatch := func(size uint64, keyspace, addr string) *event {
+		e := newEvent("", keyspace, addr, 1, false)
+		e.IsBatch = 1
+		e.BatchSize = size
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		parse bool
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "query",
+			event: newEvent("SELECT * FROM users", "app", "10.0.0.1:9042", 1, false),
+			want: f.Spans(
+				"app",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameCassandra,
+				semconv.DBNamespace("app"),
+				semconv.DBQueryText("SELECT * FROM users"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(9042),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "parsed",
+			parse: true,
+			event: newEvent("SELECT * FROM users", "app", "10.0.0.1:9042", 1, false),
+			want: f.Spans(
+				"SELECT users",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameCassandra,
+				semconv.DBNamespace("app"),
+				semconv.DBQueryText("SELECT * FROM users"),
+				semconv.DBOperationName("SELECT"),
+				semconv.DBCollectionName("users"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(9042),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "retried",
+			event: newEvent("", "", "10.0.0.2:9042", 3, true),
+			want: f.Spans(
+				"cassandra",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameCassandra,
+				semconv.ServerAddress("10.0.0.2"),
+				semconv.ServerPort(9042),
+				semconv.NetworkTransportTCP,
+				retryCountKey.Int(2),
+			),
+		},
+		{
+			name:  "batch",
+			event: newBatch(3, "app", "10.0.0.1:9042"),
+			want: f.Spans(
+				"BATCH",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameCassandra,
+				semconv.DBNamespace("app"),
+				semconv.DBOperationName("BATCH"),
+				semconv.DBOperationBatchSize(3),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(9042),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "not attempted",
+			event: newEvent("", "", "", 0, true),
+			want:  f.Spans("cassandra", ptrace.StatusCodeError, semconv.DBSystemNameCassandra),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.parse {
+				t.Setenv(sql.ParseDBStatementEnvVar, "true")
+			}
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// The default number of connections associated with the span of their
+// upgrade. The size is set by the probe when it is loaded.
+#define MAX_CONNECTIONS 1024
+
+#define DIRECTION_SEND 0
+#define DIRECTION_RECEIVE 1
+
+struct websocket_message_t {
+    BASE_SPAN_PROPERTIES
+    // The span of the HTTP request upgraded to the connection, if known.
+    struct span_context link;
+    u64 size;
+    s64 message_type;
+    u8 direction;
+    u8 has_link;
+    u8 has_error;
+    u8 padding[5];
+};
+
+// Messages being sent or received, keyed by the goroutine sending or
+// receiving them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct websocket_message_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} websocket_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct websocket_message_t));
+    __uint(max_entries, 1);
+} websocket_storage_map SEC(".maps");
+
+// The span of the HTTP request being upgraded, keyed by the goroutine
+// upgrading it.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct span_context);
+    __uint(max_entries, MAX_CONCURRENT);
+} upgrades SEC(".maps");
+
+// The span of the HTTP request upgraded to a connection, keyed by the
+// connection. Connections are removed when they are closed, the least
+// recently used ones are evicted if they are not.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct span_context);
+    __uint(max_entries, MAX_CONNECTIONS);
+} websocket_conns SEC(".maps");
+
+// Injected in init
+volatile const u64 ctx_ptr_pos;
+
+static __always_inline struct websocket_message_t *start_message(struct pt_regs *ctx, void *conn, u8 direction) {
+    u32 zero = 0;
+    struct websocket_message_t *message = bpf_map_lookup_elem(&websocket_storage_map, &zero);
+    if (message == NULL) {
+        return NULL;
+    }
+    __builtin_memset(message, 0, sizeof(struct websocket_message_t));
+    message->start_time = get_time_ns();
+    message->direction = direction;
+
+    struct span_context *link = bpf_map_lookup_elem(&websocket_conns, &conn);
+    if (link != NULL) {
+        message->link = *link;
+        message->has_link = 1;
+    }
+
+    // The connection outlives the HTTP request upgraded to it, the spans of
+    // its messages are the roots of their traces, linked to the span of the
+    // request.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &message->psc,
+        .sc = &message->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+    return message;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*Conn, error)
+SEC("uprobe/Upgrader_Upgrade")
+int uprobe_Upgrader_Upgrade(struct pt_regs *ctx) {
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 4, ctx_ptr_pos, false, &go_context);
+    struct span_context *sc = get_parent_span_context(&go_context);
+    if (sc == NULL) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&upgrades, &key, sc, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (*Conn, error)
+SEC("uprobe/Upgrader_Upgrade")
+int uprobe_Upgrader_Upgrade_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct span_context *sc = bpf_map_lookup_elem(&upgrades, &key);
+    if (sc == NULL) {
+        return 0;
+    }
+
+    void *conn = get_argument(ctx, 1);
+    if (conn != NULL) {
+        bpf_map_update_elem(&websocket_conns, &conn, sc, 0);
+    }
+    bpf_map_delete_elem(&upgrades, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Close() error
+SEC("uprobe/Conn_Close")
+int uprobe_Conn_Close(struct pt_regs *ctx) {
+    void *conn = get_argument(ctx, 1);
+    bpf_map_delete_elem(&websocket_conns, &conn);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) WriteMessage(messageType int, data []byte) error
+SEC("uprobe/Conn_WriteMessage")
+int uprobe_Conn_WriteMessage(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&websocket_events, &key) != NULL) {
+        return 0;
+    }
+
+    struct websocket_message_t *message = start_message(ctx, get_argument(ctx, 1), DIRECTION_SEND);
+    if (message == NULL) {
+        bpf_printk("uprobe/Conn_WriteMessage: message is NULL");
+        return 0;
+    }
+    message->message_type = (s64)get_argument(ctx, 2);
+    message->size = (u64)get_argument(ctx, 4);
+
+    bpf_map_update_elem(&websocket_events, &key, message, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) WriteMessage(messageType int, data []byte) error
+SEC("uprobe/Conn_WriteMessage")
+int uprobe_Conn_WriteMessage_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct websocket_message_t *message = bpf_map_lookup_elem(&websocket_events, &key);
+    if (message == NULL) {
+        return 0;
+    }
+    message->end_time = end_time;
+    if (get_argument(ctx, 1) != NULL) {
+        message->has_error = 1;
+    }
+
+    output_span_event(ctx, message, sizeof(*message), &message->sc);
+    bpf_map_delete_elem(&websocket_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) ReadMessage() (messageType int, p []byte, err error)
+SEC("uprobe/Conn_ReadMessage")
+int uprobe_Conn_ReadMessage(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&websocket_events, &key) != NULL) {
+        return 0;
+    }
+
+    struct websocket_message_t *message = start_message(ctx, get_argument(ctx, 1), DIRECTION_RECEIVE);
+    if (message == NULL) {
+        bpf_printk("uprobe/Conn_ReadMessage: message is NULL");
+        return 0;
+    }
+    bpf_map_update_elem(&websocket_events, &key, message, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) NextReader() (messageType int, r io.Reader, err error)
+//
+// ReadMessage waits for the next message with NextReader. The span of a
+// message received starts once its first frame is read, not while the
+// connection is idle.
+SEC("uprobe/Conn_NextReader")
+int uprobe_Conn_NextReader_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct websocket_message_t *message = bpf_map_lookup_elem(&websocket_events, &key);
+    if (message == NULL || message->direction != DIRECTION_RECEIVE) {
+        return 0;
+    }
+    message->start_time = get_time_ns();
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) ReadMessage() (messageType int, p []byte, err error)
+SEC("uprobe/Conn_ReadMessage")
+int uprobe_Conn_ReadMessage_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct websocket_message_t *message = bpf_map_lookup_elem(&websocket_events, &key);
+    if (message == NULL) {
+        return 0;
+    }
+
+    // No message is received if the connection fails or is closed.
+    s64 message_type = (s64)get_argument(ctx, 1);
+    if (message_type >= 0) {
+        message->end_time = end_time;
+        message->message_type = message_type;
+        message->size = (u64)get_argument(ctx, 3);
+        if (get_argument(ctx, 5) != NULL) {
+            message->has_error = 1;
+        }
+        output_span_event(ctx, message, sizeof(*message), &message->sc);
+    }
+
+    bpf_map_delete_elem(&websocket_events, &key);
+    return 0;
+}
@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package websocket provides an instrumentation probe for WebSocket
+// connections using the [github.com/gorilla/websocket] package.
+package websocket
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/gorilla/websocket"
+
+	// MaxConnectionsEnvVar is the environment variable used to configure the
+	// maximum number of connections associated with the span of the HTTP
+	// request upgraded to them. The least recently used connections are
+	// evicted once it is reached, their messages are no longer linked.
+	MaxConnectionsEnvVar = "OTEL_GO_AUTO_WEBSOCKET_MAX_CONNECTIONS"
+
+	// defaultMaxConnections is the maximum number of connections used if
+	// MaxConnectionsEnvVar is not set.
+	defaultMaxConnections = 1024
+)
+
+const (
+	// messageTypeKey is the attribute key of the type of a message.
+	messageTypeKey = attribute.Key("websocket.message.type")
+	// messageSizeKey is the attribute key of the size of the payload of a
+	// message, in bytes.
+	messageSizeKey = attribute.Key("websocket.message.size")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	maxConns, err := maxConnections(os.Getenv(MaxConnectionsEnvVar))
+	if err != nil {
+		logger.Error("invalid maximum number of connections, using default", "error", err, "default", maxConns)
+	}
+
+	const (
+		readMessage  = pkg + ".(*Conn).ReadMessage"
+		writeMessage = pkg + ".(*Conn).WriteMessage"
+	)
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "ctx_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "ctx"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					// Not linked if messages are only received.
+					Sym:         writeMessage,
+					EntryProbe:  "uprobe_Conn_WriteMessage",
+					ReturnProbe: "uprobe_Conn_WriteMessage_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// Not linked if messages are only sent.
+					Sym:         readMessage,
+					EntryProbe:  "uprobe_Conn_ReadMessage",
+					ReturnProbe: "uprobe_Conn_ReadMessage_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         pkg + ".(*Conn).NextReader",
+					ReturnProbe: "uprobe_Conn_NextReader_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{readMessage},
+				},
+				{
+					// Not linked if connections are only dialed.
+					Sym:         pkg + ".(*Upgrader).Upgrade",
+					EntryProbe:  "uprobe_Upgrader_Upgrade",
+					ReturnProbe: "uprobe_Upgrader_Upgrade_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{readMessage, writeMessage},
+				},
+				{
+					Sym:         pkg + ".(*Conn).Close",
+					EntryProbe:  "uprobe_Conn_Close",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{readMessage, writeMessage},
+				},
+			},
+			SpecFn: specFn(maxConns),
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// maxConnections returns the maximum number of connections parsed from val,
+// or defaultMaxConnections if val is empty or invalid.
+func maxConnections(val string) (uint32, error) {
+	if val == "" {
+		return defaultMaxConnections, nil
+	}
+	n, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		return defaultMaxConnections, err
+	}
+	if n == 0 {
+		return defaultMaxConnections, strconv.ErrRange
+	}
+	return uint32(n), nil
+}
+
+// specFn returns the function loading the CollectionSpec of the probe with
+// at most maxConns connections tracked.
+func specFn(maxConns uint32) func() (*ebpf.CollectionSpec, error) {
+	return func() (*ebpf.CollectionSpec, error) {
+		spec, err := loadBpf()
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := spec.Maps["websocket_conns"]; ok {
+			m.MaxEntries = maxConns
+		}
+		return spec, nil
+	}
+}
+
+// event represents a message sent or received on a WebSocket connection.
+type event struct {
+	context.BaseSpanProperties
+	// Link is the span context of the HTTP request upgraded to the
+	// connection, it is only valid if HasLink is set.
+	Link        context.EBPFSpanContext
+	Size        uint64
+	MessageType int64
+	Direction   uint8
+	HasLink     uint8
+	HasError    uint8
+	_           [5]byte // padding
+}
+
+// directionReceive is the Direction of a message received, messages sent
+// have a zero Direction.
+const directionReceive = 1
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{messageSizeKey.Int64(int64(e.Size))} // nolint: gosec  // Size of a Go slice.
+	if t, ok := messageType(e.MessageType); ok {
+		attrs = append(attrs, messageTypeKey.String(t))
+	}
+
+	name := "WebSocket send"
+	if e.Direction == directionReceive {
+		name = "WebSocket receive"
+		attrs = append(attrs, semconv.NetworkIoDirectionReceive)
+	} else {
+		attrs = append(attrs, semconv.NetworkIoDirectionTransmit)
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.HasLink != 0 {
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID(e.Link.TraceID))
+		link.SetSpanID(pcommon.SpanID(e.Link.SpanID))
+		link.SetFlags(uint32(e.Link.TraceFlags))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// messageType returns the name of the WebSocket message type t, and false if
+// t is unknown.
+//
+// https://datatracker.ietf.org/doc/html/rfc6455#section-11.8
+func messageType(t int64) (string, bool) {
+	switch t {
+	case 1:
+		return "text", true
+	case 2:
+		return "binary", true
+	case 8:
+		return "close", true
+	case 9:
+		return "ping", true
+	case 10:
+		return "pong", true
+	default:
+		return "", false
+	}
+}
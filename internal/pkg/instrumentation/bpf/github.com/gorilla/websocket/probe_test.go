@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	upgradeTraceID := trace.TraceID{2}
+	upgradeSpanID := trace.SpanID{2}
+
+	newEvent := func(direction uint8, msgType int64, linked, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Size:               42,
+			MessageType:        msgType,
+			Direction:          direction,
+		}
+		if linked {
+			e.Link = context.EBPFSpanContext{
+				TraceID:    upgradeTraceID,
+				SpanID:     upgradeSpanID,
+				TraceFlags: trace.FlagsSampled,
+			}
+			e.HasLink = 1
+		}
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newSpans := func(name string, linked bool, code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		spans := f.Spans(name, code, attrs...)
+		if linked {
+			link := spans.At(0).Links().AppendEmpty()
+			link.SetTraceID(pcommon.TraceID(upgradeTraceID))
+			link.SetSpanID(pcommon.SpanID(upgradeSpanID))
+			link.SetFlags(uint32(trace.FlagsSampled))
+		}
+		return spans
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "send",
+			event: newEvent(0, 1, true, false),
+			want: newSpans(
+				"WebSocket send",
+				true,
+				ptrace.StatusCodeUnset,
+				messageSizeKey.Int(42),
+				messageTypeKey.String("text"),
+				semconv.NetworkIoDirectionTransmit,
+			),
+		},
+		{
+			name:  "receive",
+			event: newEvent(directionReceive, 2, true, false),
+			want: newSpans(
+				"WebSocket receive",
+				true,
+				ptrace.StatusCodeUnset,
+				messageSizeKey.Int(42),
+				messageTypeKey.String("binary"),
+				semconv.NetworkIoDirectionReceive,
+			),
+		},
+		{
+			name:  "not linked",
+			event: newEvent(0, 9, false, false),
+			want: newSpans(
+				"WebSocket send",
+				false,
+				ptrace.StatusCodeUnset,
+				messageSizeKey.Int(42),
+				messageTypeKey.String("ping"),
+				semconv.NetworkIoDirectionTransmit,
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent(0, 3, true, true),
+			want: newSpans(
+				"WebSocket send",
+				true,
+				ptrace.StatusCodeError,
+				messageSizeKey.Int(42),
+				semconv.NetworkIoDirectionTransmit,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestMaxConnections(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    uint32
+		wantErr bool
+	}{
+		{val: "", want: defaultMaxConnections},
+		{val: "10", want: 10},
+		{val: "0", want: defaultMaxConnections, wantErr: true},
+		{val: "-1", want: defaultMaxConnections, wantErr: true},
+		{val: "many", want: defaultMaxConnections, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			got, err := maxConnections(tt.val)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "http_client.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_METHOD_SIZE 16
+#define MAX_PATH_SIZE 256
+#define MAX_NAMESPACE_SIZE 128
+#define MAX_HOSTNAME_SIZE 128
+#define MAX_CONCURRENT 50
+#define MAX_CLIENTS 1024
+
+struct vault_request_t {
+    BASE_SPAN_PROPERTIES
+    char method[MAX_METHOD_SIZE];
+    // The path of the URL of the request, e.g. "/v1/secret/data/app".
+    char path[MAX_PATH_SIZE];
+    // The namespace of the client sending the request, if any.
+    char namespace[MAX_NAMESPACE_SIZE];
+    char host[MAX_HOSTNAME_SIZE];
+    u64 status_code;
+    u8 has_error;
+    // Whether the request is sent by a LifetimeWatcher renewing a token or
+    // a lease in the background.
+    u8 renewal;
+    u8 padding[6];
+};
+
+// The state of the requests being sent, only the event is sent to user space.
+struct vault_request_state_t {
+    struct vault_request_t event;
+    // The number of nested calls of the raw request functions:
+    // RawRequestWithContext calls rawRequestWithContext.
+    u64 depth;
+};
+
+// The requests being sent, keyed by their goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct vault_request_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} vault_events SEC(".maps");
+
+// The namespaces set on the clients, keyed by their *Client.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, char[MAX_NAMESPACE_SIZE]);
+    __uint(max_entries, MAX_CLIENTS);
+} vault_namespaces SEC(".maps");
+
+// The goroutines running a LifetimeWatcher.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, u8);
+    __uint(max_entries, MAX_CONCURRENT);
+} vault_renewals SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct vault_request_state_t));
+    __uint(max_entries, 1);
+} vault_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 request_method_pos;
+volatile const u64 request_url_pos;
+volatile const u64 response_response_pos;
+volatile const u64 path_ptr_pos;
+volatile const u64 url_host_pos;
+volatile const u64 status_code_pos;
+
+// The renewals of a LifetimeWatcher are not part of the trace of the code
+// that started it: they are root spans.
+static __always_inline long get_no_parent_span_context(void *data, struct span_context *psc) {
+    return -1;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (c *Client) RawRequestWithContext(ctx context.Context, r *Request) (*Response, error)
+// func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Response, error)
+SEC("uprobe/Client_RawRequestWithContext")
+int uprobe_Client_RawRequestWithContext(struct pt_regs *ctx) {
+    u64 client_pos = 1;
+    u64 context_pos = 2;
+    u64 request_pos = 4;
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct vault_request_state_t *tracked = bpf_map_lookup_elem(&vault_events, &key);
+    if (tracked != NULL) {
+        tracked->depth++;
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct vault_request_state_t *state = bpf_map_lookup_elem(&vault_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("uprobe/Client_RawRequestWithContext: state is NULL");
+        return 0;
+    }
+    __builtin_memset(state, 0, sizeof(struct vault_request_state_t));
+    struct vault_request_t *req = &state->event;
+    req->start_time = get_time_ns();
+
+    void *req_ptr = get_argument(ctx, request_pos);
+    get_go_string_from_user_ptr((void *)(req_ptr + request_method_pos), req->method, sizeof(req->method));
+    void *url_ptr = NULL;
+    bpf_probe_read_user(&url_ptr, sizeof(url_ptr), (void *)(req_ptr + request_url_pos));
+    if (url_ptr != NULL) {
+        get_go_string_from_user_ptr((void *)(url_ptr + path_ptr_pos), req->path, sizeof(req->path));
+        get_go_string_from_user_ptr((void *)(url_ptr + url_host_pos), req->host, sizeof(req->host));
+    }
+
+    void *client = get_argument(ctx, client_pos);
+    char *namespace = bpf_map_lookup_elem(&vault_namespaces, &client);
+    if (namespace != NULL) {
+        __builtin_memcpy(req->namespace, namespace, sizeof(req->namespace));
+    }
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, context_pos, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    if (bpf_map_lookup_elem(&vault_renewals, &key) != NULL) {
+        req->renewal = 1;
+        start_span_params.get_parent_span_context_fn = get_no_parent_span_context;
+    }
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&vault_events, &key, state, 0);
+    // The request sent by the client with net/http is part of this span.
+    set_http_client_owner(key, &req->sc);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following functions:
+// func (c *Client) RawRequestWithContext(ctx context.Context, r *Request) (*Response, error)
+// func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Response, error)
+SEC("uprobe/Client_RawRequestWithContext")
+int uprobe_Client_RawRequestWithContext_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct vault_request_state_t *state = bpf_map_lookup_elem(&vault_events, &key);
+    if (state == NULL) {
+        return 0;
+    }
+    if (state->depth > 0) {
+        state->depth--;
+        return 0;
+    }
+    struct vault_request_t *req = &state->event;
+    req->end_time = end_time;
+
+    // The response is returned along with the error of the requests failing
+    // with an error status code.
+    void *resp_ptr = get_argument(ctx, 1);
+    if (resp_ptr != NULL) {
+        void *http_resp_ptr = NULL;
+        bpf_probe_read_user(&http_resp_ptr, sizeof(http_resp_ptr), (void *)(resp_ptr + response_response_pos));
+        if (http_resp_ptr != NULL) {
+            s64 status_code = 0;
+            bpf_probe_read_user(&status_code, sizeof(status_code), (void *)(http_resp_ptr + status_code_pos));
+            req->status_code = status_code;
+        }
+    }
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 2) != NULL) {
+        req->has_error = 1;
+    }
+
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    delete_http_client_owner(key);
+    bpf_map_delete_elem(&vault_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (c *Client) SetNamespace(namespace string)
+// func (c *Client) setNamespace(namespace string)
+SEC("uprobe/Client_SetNamespace")
+int uprobe_Client_SetNamespace(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    void *namespace_ptr = get_argument(ctx, 2);
+    u64 namespace_len = (u64)get_argument(ctx, 3);
+    if (client == NULL) {
+        return 0;
+    }
+    if (namespace_len == 0) {
+        bpf_map_delete_elem(&vault_namespaces, &client);
+        return 0;
+    }
+
+    char namespace[MAX_NAMESPACE_SIZE] = {0};
+    u64 size = MAX_NAMESPACE_SIZE - 1 < namespace_len ? MAX_NAMESPACE_SIZE - 1 : namespace_len;
+    bpf_probe_read_user(namespace, size, namespace_ptr);
+    bpf_map_update_elem(&vault_namespaces, &client, namespace, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) ClearNamespace()
+SEC("uprobe/Client_ClearNamespace")
+int uprobe_Client_ClearNamespace(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    bpf_map_delete_elem(&vault_namespaces, &client);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *LifetimeWatcher) Start()
+SEC("uprobe/LifetimeWatcher_Start")
+int uprobe_LifetimeWatcher_Start(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    u8 renewal = 1;
+    bpf_map_update_elem(&vault_renewals, &key, &renewal, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (r *LifetimeWatcher) Start()
+SEC("uprobe/LifetimeWatcher_Start")
+int uprobe_LifetimeWatcher_Start_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_delete_elem(&vault_renewals, &key);
+    return 0;
+}
@@ -0,0 +1,296 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vault provides an instrumentation probe for the clients of
+// HashiCorp Vault using the [github.com/hashicorp/vault/api] package.
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/hashicorp/vault/api"
+
+	// PathRedactionEnvVar is the environment variable to opt-in for the
+	// redaction of the last segment of the paths recorded, e.g. the name of
+	// a secret. The segment is replaced by its hash if set to "hash", and
+	// dropped if set to "drop".
+	PathRedactionEnvVar = "OTEL_GO_AUTO_VAULT_PATH_REDACTION"
+)
+
+const (
+	// pathKey is the attribute key of the path of a request, prefixed with
+	// the namespace of the client.
+	pathKey = attribute.Key("vault.path")
+	// operationKey is the attribute key of the operation of a request.
+	operationKey = attribute.Key("vault.operation")
+	// renewalKey is the attribute key set on the requests sent by a
+	// LifetimeWatcher renewing a token or a lease.
+	renewalKey = attribute.Key("vault.renewal")
+)
+
+// minVersion is the first version supported by the probe.
+var minVersion = semver.New(1, 1, 0, "", "")
+
+// New returns a new [probe.Probe].
+//
+// A span is produced for each request sent by a Client, e.g. by the Read and
+// Write methods of its Logical backend, which all send their requests with
+// RawRequestWithContext. The net/http client span of the request is not
+// produced, the span of the Client is propagated instead. The requests sent
+// by a LifetimeWatcher to renew a token or a lease in the background are root
+// spans.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	// The exported function only calls the unexported one in the recent
+	// versions, whose backends call the unexported one directly. The nested
+	// calls are traced once.
+	const rawRequest = pkg + ".(*Client).rawRequestWithContext"
+	const exportedRawRequest = pkg + ".(*Client).RawRequestWithContext"
+
+	uprobe := func(sym, entry, ret string) *probe.Uprobe {
+		return &probe.Uprobe{
+			Sym:                sym,
+			EntryProbe:         entry,
+			ReturnProbe:        ret,
+			PackageConstraints: []probe.PackageConstraints{supported},
+			FailureMode:        probe.FailureModeIgnore,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("request_method_pos", "Request", "Method"),
+				fieldConst("request_url_pos", "Request", "URL"),
+				fieldConst("response_response_pos", "Response", "Response"),
+				probe.StructFieldConst{
+					Key: "path_ptr_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Path"),
+				},
+				probe.StructFieldConst{
+					Key: "url_host_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Host"),
+				},
+				probe.StructFieldConst{
+					Key: "status_code_pos",
+					ID:  structfield.NewID("std", "net/http", "Response", "StatusCode"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				uprobe(rawRequest, "uprobe_Client_RawRequestWithContext", "uprobe_Client_RawRequestWithContext_Returns"),
+				uprobe(exportedRawRequest, "uprobe_Client_RawRequestWithContext", "uprobe_Client_RawRequestWithContext_Returns"),
+				// The namespace of the VAULT_NAMESPACE environment
+				// variable is set with setNamespace by NewClient.
+				uprobe(pkg+".(*Client).SetNamespace", "uprobe_Client_SetNamespace", ""),
+				uprobe(pkg+".(*Client).setNamespace", "uprobe_Client_SetNamespace", ""),
+				uprobe(pkg+".(*Client).ClearNamespace", "uprobe_Client_ClearNamespace", ""),
+				uprobe(pkg+".(*LifetimeWatcher).Start", "uprobe_LifetimeWatcher_Start", "uprobe_LifetimeWatcher_Start_Returns"),
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a request sent by a Vault client.
+type event struct {
+	context.BaseSpanProperties
+	Method [16]byte
+	// Path is the path of the URL of the request, e.g.
+	// "/v1/secret/data/app".
+	Path [256]byte
+	// Namespace is the namespace of the client, empty if none is set.
+	Namespace [128]byte
+	Host      [128]byte
+	// StatusCode is the status code of the response, zero if none was
+	// received.
+	StatusCode uint64
+	HasError   uint8
+	Renewal    uint8
+	_          [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	method := unix.ByteSliceToString(e.Method[:])
+	op := operation(method)
+
+	var attrs []attribute.KeyValue
+	if op != "" {
+		attrs = append(attrs, operationKey.String(op))
+	}
+	path := vaultPath(
+		unix.ByteSliceToString(e.Namespace[:]),
+		unix.ByteSliceToString(e.Path[:]),
+	)
+	if path != "" {
+		attrs = append(attrs, pathKey.String(redactPath(path, os.Getenv(PathRedactionEnvVar))))
+	}
+	if method != "" {
+		attrs = append(attrs, semconv.HTTPRequestMethodKey.String(method))
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc9110.html#name-status-codes
+	const maxStatus = 599
+	if e.StatusCode > 0 && e.StatusCode <= maxStatus {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(int(e.StatusCode))) // nolint: gosec  // Bound checked.
+	}
+
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:]))
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	if e.Renewal != 0 {
+		attrs = append(attrs, renewalKey.Bool(true))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(op))
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	// The requests failing with an error status code are returned as errors
+	// along with their response.
+	switch {
+	case e.StatusCode >= 400 && e.StatusCode <= maxStatus:
+		span.Status().SetCode(ptrace.StatusCodeError)
+		attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.FormatUint(e.StatusCode, 10)))
+	case e.HasError != 0:
+		span.Status().SetCode(ptrace.StatusCodeError)
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// operation returns the Vault operation of a request sent with the HTTP
+// method, or an empty string if it is not known.
+//
+// https://developer.hashicorp.com/vault/docs/concepts/policies#capabilities
+func operation(method string) string {
+	switch method {
+	case "GET", "HEAD":
+		return "read"
+	case "PUT", "POST":
+		return "write"
+	case "PATCH":
+		return "patch"
+	case "DELETE":
+		return "delete"
+	case "LIST":
+		return "list"
+	default:
+		return ""
+	}
+}
+
+// spanName returns the name of the span of a request of the operation op.
+func spanName(op string) string {
+	if op == "" {
+		return "Vault"
+	}
+	return "Vault " + op
+}
+
+// vaultPath returns the path of the request with the URL path urlPath, e.g.
+// "secret/data/app" for "/v1/secret/data/app", prefixed with the namespace of
+// the client, e.g. "ns1/secret/data/app".
+func vaultPath(namespace, urlPath string) string {
+	p := strings.TrimPrefix(strings.TrimPrefix(urlPath, "/"), "v1/")
+	if p == "" {
+		return ""
+	}
+	if ns := strings.Trim(namespace, "/"); ns != "" {
+		return ns + "/" + p
+	}
+	return p
+}
+
+// redactPath returns path with its last segment redacted according to mode:
+// replaced by the first 8 bytes of its hex encoded SHA-256 hash for "hash",
+// and dropped for "drop". The path is returned unchanged otherwise.
+func redactPath(path, mode string) string {
+	prefix, last := "", path
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		prefix, last = path[:i+1], path[i+1:]
+	}
+	if last == "" {
+		return path
+	}
+
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "hash":
+		sum := sha256.Sum256([]byte(last))
+		return prefix + hex.EncodeToString(sum[:8])
+	case "drop":
+		return prefix
+	default:
+		return path
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vault
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(method, path, namespace string, status uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			StatusCode:         status,
+		}
+		copy(e.Method[:], method)
+		copy(e.Path[:], path)
+		copy(e.Namespace[:], namespace)
+		copy(e.Host[:], "vault.example.com:8200")
+		return e
+	}
+
+	newSpans := func(name string, parent trace.SpanID, code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		fixture := f
+		fixture.ParentSpanID = parent
+		return fixture.Spans(name, code, attrs...)
+	}
+
+	server := []attribute.KeyValue{
+		semconv.ServerAddress("vault.example.com"),
+		semconv.ServerPort(8200),
+		semconv.NetworkTransportTCP,
+	}
+
+	renewal := newEvent("PUT", "/v1/auth/token/renew-self", "", 200)
+	renewal.ParentSpanContext = context.EBPFSpanContext{}
+	renewal.Renewal = 1
+
+	failed := newEvent("GET", "/v1/secret/data/app", "", 0)
+	failed.HasError = 1
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "Read",
+			event: newEvent("GET", "/v1/secret/data/app", "ns1/", 200),
+			want: newSpans("Vault read", f.ParentSpanID, ptrace.StatusCodeUnset, append([]attribute.KeyValue{
+				operationKey.String("read"),
+				pathKey.String("ns1/secret/data/app"),
+				semconv.HTTPRequestMethodKey.String("GET"),
+				semconv.HTTPResponseStatusCode(200),
+			}, server...)...),
+		},
+		{
+			name:  "WriteDenied",
+			event: newEvent("PUT", "/v1/secret/data/app", "", 403),
+			want: newSpans("Vault write", f.ParentSpanID, ptrace.StatusCodeError, append(append([]attribute.KeyValue{
+				operationKey.String("write"),
+				pathKey.String("secret/data/app"),
+				semconv.HTTPRequestMethodKey.String("PUT"),
+				semconv.HTTPResponseStatusCode(403),
+			}, server...), semconv.ErrorTypeKey.String("403"))...),
+		},
+		{
+			name:  "Unreachable",
+			event: failed,
+			want: newSpans("Vault read", f.ParentSpanID, ptrace.StatusCodeError, append(append([]attribute.KeyValue{
+				operationKey.String("read"),
+				pathKey.String("secret/data/app"),
+				semconv.HTTPRequestMethodKey.String("GET"),
+			}, server...), semconv.ErrorTypeOther)...),
+		},
+		{
+			name:  "Renewal",
+			event: renewal,
+			want: newSpans("Vault write", trace.SpanID{}, ptrace.StatusCodeUnset, append(append([]attribute.KeyValue{
+				operationKey.String("write"),
+				pathKey.String("auth/token/renew-self"),
+				semconv.HTTPRequestMethodKey.String("PUT"),
+				semconv.HTTPResponseStatusCode(200),
+			}, server...), renewalKey.Bool(true))...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestRedactPath(t *testing.T) {
+	tests := []struct {
+		path, mode, want string
+	}{
+		{path: "ns1/secret/data/app", mode: "", want: "ns1/secret/data/app"},
+		{path: "ns1/secret/data/app", mode: "hash", want: "ns1/secret/data/a172cedcae47474b"},
+		{path: "ns1/secret/data/app", mode: "drop", want: "ns1/secret/data/"},
+		{path: "ns1/secret/data/app", mode: "DROP", want: "ns1/secret/data/"},
+		{path: "ns1/secret/data/app", mode: "unknown", want: "ns1/secret/data/app"},
+		{path: "secret/metadata/", mode: "hash", want: "secret/metadata/"},
+		{path: "app", mode: "drop", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+"/"+tt.mode, func(t *testing.T) {
+			assert.Equal(t, tt.want, redactPath(tt.path, tt.mode))
+		})
+	}
+}
+
+func TestProcessFnPathRedaction(t *testing.T) {
+	t.Setenv(PathRedactionEnvVar, "hash")
+
+	e := &event{}
+	copy(e.Method[:], "GET")
+	copy(e.Path[:], "/v1/secret/data/app")
+
+	spans := processFn(e)
+	path, ok := spans.At(0).Attributes().Get(string(pathKey))
+	assert.True(t, ok)
+	assert.Equal(t, "secret/data/a172cedcae47474b", path.Str())
+}
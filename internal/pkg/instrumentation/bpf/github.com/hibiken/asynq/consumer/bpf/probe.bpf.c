@@ -0,0 +1,238 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 256
+#define MAX_MESSAGES 1024
+#define MAX_TYPE_SIZE 128
+#define MAX_ID_SIZE 128
+#define MAX_QUEUE_SIZE 128
+
+// The traceparent entry of the headers map of an encoded task message, see
+// the producer probe. It is the last field of the messages it is injected in.
+#define HEADERS_ENTRY_PREFIX_LEN 17
+#define HEADERS_ENTRY_LEN (HEADERS_ENTRY_PREFIX_LEN + W3C_VAL_LENGTH)
+
+struct asynq_task_t {
+    BASE_SPAN_PROPERTIES
+    char task_type[MAX_TYPE_SIZE];
+    char task_id[MAX_ID_SIZE];
+    char queue[MAX_QUEUE_SIZE];
+    u64 body_size;
+    // The max number of retries of the task, and the number of times it was
+    // retried, only valid if decoded is set.
+    s64 retry;
+    s64 retried;
+    u8 decoded;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// A task message decoded from the broker.
+struct asynq_message_t {
+    struct span_context psc;
+    s64 retry;
+    s64 retried;
+    u8 has_parent;
+    u8 padding[7];
+};
+
+// Tasks being processed, keyed by the goroutine processing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct asynq_task_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} asynq_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct asynq_task_t));
+    __uint(max_entries, 1);
+} asynq_storage_map SEC(".maps");
+
+// Task messages being decoded, keyed by the goroutine decoding them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct asynq_message_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} asynq_decoding SEC(".maps");
+
+// Decoded task messages, keyed by the address of their ID. The ID is shared
+// with the result writer of the task processed.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct asynq_message_t);
+    __uint(max_entries, MAX_MESSAGES);
+} asynq_messages SEC(".maps");
+
+// Injected in init
+volatile const u64 task_typename_pos;
+volatile const u64 task_payload_pos;
+volatile const u64 task_w_pos;
+volatile const u64 result_writer_id_pos;
+volatile const u64 result_writer_qname_pos;
+volatile const u64 task_message_id_pos;
+volatile const u64 task_message_retry_pos;
+volatile const u64 task_message_retried_pos;
+
+static __always_inline long extract_span_context_from_message(void *arg, struct span_context *parent_span_context) {
+    struct asynq_message_t *msg = arg;
+    if (msg == NULL || !msg->has_parent) {
+        return -1;
+    }
+    *parent_span_context = msg->psc;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func DecodeMessage(data []byte) (*TaskMessage, error)
+SEC("uprobe/DecodeMessage")
+int uprobe_DecodeMessage(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void *data_ptr = get_argument(ctx, 1);
+    s64 data_len = (s64)get_argument(ctx, 2);
+
+    struct asynq_message_t msg = {0};
+    if (data_len > HEADERS_ENTRY_LEN) {
+        char entry[HEADERS_ENTRY_LEN];
+        if (bpf_probe_read_user(entry, sizeof(entry), data_ptr + data_len - HEADERS_ENTRY_LEN) == 0) {
+            char prefix[HEADERS_ENTRY_PREFIX_LEN] = {
+                0x7a, HEADERS_ENTRY_LEN - 2,
+                0x0a, W3C_KEY_LENGTH, 't', 'r', 'a', 'c', 'e', 'p', 'a', 'r', 'e', 'n', 't',
+                0x12, W3C_VAL_LENGTH,
+            };
+            if (bpf_memcmp(entry, prefix, sizeof(prefix))) {
+                w3c_string_to_span_context(&entry[HEADERS_ENTRY_PREFIX_LEN], &msg.psc);
+                msg.has_parent = 1;
+            }
+        }
+    }
+
+    bpf_map_update_elem(&asynq_decoding, &key, &msg, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func DecodeMessage(data []byte) (*TaskMessage, error)
+SEC("uprobe/DecodeMessage")
+int uprobe_DecodeMessage_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct asynq_message_t *msg = bpf_map_lookup_elem(&asynq_decoding, &key);
+    if (msg == NULL) {
+        return 0;
+    }
+
+    void *task_msg = get_argument(ctx, 1);
+    if (task_msg != NULL) {
+        void *id = NULL;
+        bpf_probe_read_user(&id, sizeof(id), task_msg + task_message_id_pos);
+        bpf_probe_read_user(&msg->retry, sizeof(msg->retry), task_msg + task_message_retry_pos);
+        bpf_probe_read_user(&msg->retried, sizeof(msg->retried), task_msg + task_message_retried_pos);
+        if (id != NULL) {
+            bpf_map_update_elem(&asynq_messages, &id, msg, 0);
+        }
+    }
+
+    bpf_map_delete_elem(&asynq_decoding, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *processor) perform(ctx context.Context, task *Task) (err error)
+SEC("uprobe/processor_perform")
+int uprobe_processor_perform(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void *task = get_argument(ctx, 4);
+    if (task == NULL) {
+        return 0;
+    }
+    void *w = NULL;
+    bpf_probe_read_user(&w, sizeof(w), task + task_w_pos);
+    if (w == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct asynq_task_t *asynq_task = bpf_map_lookup_elem(&asynq_storage_map, &zero);
+    if (asynq_task == NULL) {
+        bpf_printk("uprobe/processor_perform: asynq_task is NULL");
+        return 0;
+    }
+    __builtin_memset(asynq_task, 0, sizeof(struct asynq_task_t));
+    asynq_task->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr(task + task_typename_pos, asynq_task->task_type, sizeof(asynq_task->task_type));
+    bpf_probe_read_user(&asynq_task->body_size, sizeof(asynq_task->body_size), task + task_payload_pos + offsetof(struct go_slice, len));
+    get_go_string_from_user_ptr(w + result_writer_id_pos, asynq_task->task_id, sizeof(asynq_task->task_id));
+    get_go_string_from_user_ptr(w + result_writer_qname_pos, asynq_task->queue, sizeof(asynq_task->queue));
+
+    void *id = NULL;
+    bpf_probe_read_user(&id, sizeof(id), w + result_writer_id_pos);
+    struct asynq_message_t *msg = NULL;
+    if (id != NULL) {
+        msg = bpf_map_lookup_elem(&asynq_messages, &id);
+    }
+    if (msg != NULL) {
+        asynq_task->retry = msg->retry;
+        asynq_task->retried = msg->retried;
+        asynq_task->decoded = 1;
+    }
+
+    // The parent span is the one of the producer propagated in the task
+    // message.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &asynq_task->psc,
+        .sc = &asynq_task->sc,
+        .get_parent_span_context_fn = extract_span_context_from_message,
+        .get_parent_span_context_arg = msg,
+    };
+    start_span(&start_span_params);
+    if (id != NULL) {
+        bpf_map_delete_elem(&asynq_messages, &id);
+    }
+
+    bpf_map_update_elem(&asynq_events, &key, asynq_task, 0);
+    start_tracking_span(go_context.data, &asynq_task->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *processor) perform(ctx context.Context, task *Task) (err error)
+SEC("uprobe/processor_perform")
+int uprobe_processor_perform_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct asynq_task_t *asynq_task = bpf_map_lookup_elem(&asynq_events, &key);
+    if (asynq_task == NULL) {
+        return 0;
+    }
+    asynq_task->end_time = end_time;
+
+    // The returned error is a non-nil interface when the handler fails, the
+    // task is then retried.
+    if (get_argument(ctx, 1) != NULL) {
+        asynq_task->has_error = 1;
+    }
+
+    output_span_event(ctx, asynq_task, sizeof(*asynq_task), &asynq_task->sc);
+    stop_tracking_span(&asynq_task->sc, &asynq_task->psc);
+    bpf_map_delete_elem(&asynq_events, &key);
+    return 0;
+}
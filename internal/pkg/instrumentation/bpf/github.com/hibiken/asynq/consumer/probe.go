@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consumer provides an instrumentation probe for the servers
+// processing Asynq tasks using the [github.com/hibiken/asynq] package.
+package consumer
+
+import (
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/hibiken/asynq"
+	// basePkg is the package of the task messages stored in Redis.
+	basePkg = pkg + "/internal/base"
+)
+
+const (
+	// retryCountKey is the attribute key of the number of times a task was
+	// retried.
+	retryCountKey = attribute.Key("messaging.asynq.task.retry_count")
+	// maxRetryKey is the attribute key of the max number of times a task is
+	// retried.
+	maxRetryKey = attribute.Key("messaging.asynq.task.max_retry")
+)
+
+// minVersion is the first version supported by the probe.
+var minVersion = semver.New(0, 24, 0, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, p, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, p, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("task_typename_pos", pkg, "Task", "typename"),
+				fieldConst("task_payload_pos", pkg, "Task", "payload"),
+				fieldConst("task_w_pos", pkg, "Task", "w"),
+				fieldConst("result_writer_id_pos", pkg, "ResultWriter", "id"),
+				fieldConst("result_writer_qname_pos", pkg, "ResultWriter", "qname"),
+				fieldConst("task_message_id_pos", basePkg, "TaskMessage", "ID"),
+				fieldConst("task_message_retry_pos", basePkg, "TaskMessage", "Retry"),
+				fieldConst("task_message_retried_pos", basePkg, "TaskMessage", "Retried"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                basePkg + ".DecodeMessage",
+					EntryProbe:         "uprobe_DecodeMessage",
+					ReturnProbe:        "uprobe_DecodeMessage_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*processor).perform",
+					EntryProbe:         "uprobe_processor_perform",
+					ReturnProbe:        "uprobe_processor_perform_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a task processed by a server.
+type event struct {
+	context.BaseSpanProperties
+	TaskType [128]byte
+	TaskID   [128]byte
+	Queue    [128]byte
+	// BodySize is the size of the payload of the task.
+	BodySize uint64
+	// Retry is the max number of retries of the task, and Retried the number
+	// of times it was retried. They are only valid if Decoded is set.
+	Retry    int64
+	Retried  int64
+	Decoded  uint8
+	HasError uint8
+	_        [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	taskType := unix.ByteSliceToString(e.TaskType[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("redis"),
+		semconv.MessagingOperationTypeProcess,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName(unix.ByteSliceToString(e.Queue[:])),
+		semconv.MessagingMessageID(unix.ByteSliceToString(e.TaskID[:])),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max payload.
+	}
+	if e.Decoded != 0 {
+		attrs = append(attrs,
+			retryCountKey.Int64(e.Retried),
+			maxRetryKey.Int64(e.Retry),
+		)
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(taskType))
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	// The handler failed, the task is retried if it has retries left.
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of the span of a task of taskType processed, e.g.
+// "email:deliver process".
+func spanName(taskType string) string {
+	if taskType == "" {
+		return "process"
+	}
+	return taskType + " process"
+}
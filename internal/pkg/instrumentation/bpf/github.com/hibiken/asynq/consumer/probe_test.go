@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(taskType string, decoded, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           2,
+			Retry:              25,
+			Retried:            3,
+		}
+		copy(e.TaskType[:], taskType)
+		copy(e.TaskID[:], "42")
+		copy(e.Queue[:], "critical")
+		if decoded {
+			e.Decoded = 1
+		}
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newSpans := func(name string, code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		return f.Spans(name, code, append([]attribute.KeyValue{
+			semconv.MessagingSystemKey.String("redis"),
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingOperationName("process"),
+			semconv.MessagingDestinationName("critical"),
+			semconv.MessagingMessageID("42"),
+			semconv.MessagingMessageBodySize(2),
+		}, attrs...)...)
+	}
+
+	retries := []attribute.KeyValue{
+		retryCountKey.Int64(3),
+		maxRetryKey.Int64(25),
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "process",
+			event: newEvent("email:deliver", true, false),
+			want:  newSpans("email:deliver process", ptrace.StatusCodeUnset, retries...),
+		},
+		{
+			name:  "error",
+			event: newEvent("email:deliver", true, true),
+			want:  newSpans("email:deliver process", ptrace.StatusCodeError, retries...),
+		},
+		{
+			name:  "not decoded",
+			event: newEvent("", false, false),
+			want:  newSpans("process", ptrace.StatusCodeUnset),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
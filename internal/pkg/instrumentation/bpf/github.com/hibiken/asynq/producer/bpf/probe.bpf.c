@@ -0,0 +1,240 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_TYPE_SIZE 128
+#define MAX_KEY_SIZE 256
+#define ENCODED_MASK (MAX_BUFFER_SIZE - 1)
+
+// The traceparent entry of the headers map of an encoded task message: the
+// tag of the headers field (15, length-delimited) and the length of the entry,
+// followed by the key and value fields of the entry.
+// https://github.com/hibiken/asynq/blob/v0.26.0/internal/proto/asynq.proto#L23
+#define HEADERS_ENTRY_PREFIX_LEN 17
+#define HEADERS_ENTRY_LEN (HEADERS_ENTRY_PREFIX_LEN + W3C_VAL_LENGTH)
+
+struct asynq_enqueue_t {
+    BASE_SPAN_PROPERTIES
+    char task_type[MAX_TYPE_SIZE];
+    // The key of the task message written, "asynq:{<queue>}:t:<id>".
+    char task_key[MAX_KEY_SIZE];
+    u64 body_size;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// The state of the tasks being enqueued, only the event is sent to user
+// space.
+struct asynq_enqueue_state_t {
+    struct asynq_enqueue_t event;
+    // Set once the task message is written.
+    u8 written;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct asynq_enqueue_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} asynq_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct asynq_enqueue_state_t));
+    __uint(max_entries, 1);
+} asynq_storage_map SEC(".maps");
+
+// The encoded task message a traceparent header is injected in. It is
+// written to the target with write_target_data.
+struct encoded_message_t {
+    char buf[MAX_BUFFER_SIZE];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct encoded_message_t));
+    __uint(max_entries, 1);
+} encoded_message_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 task_typename_pos;
+volatile const u64 task_payload_pos;
+
+/* The encoded task message is replaced with a copy followed by a traceparent
+entry of the headers map, the last field of the message. It is decoded in the
+headers of the task since v0.26.0, and skipped as an unknown field before. */
+static __always_inline void inject_traceparent(struct asynq_enqueue_state_t *state, void *encoded_ptr) {
+    struct go_slice encoded = {0};
+    if (bpf_probe_read_user(&encoded, sizeof(encoded), encoded_ptr) != 0) {
+        return;
+    }
+    if (encoded.len < 1 || encoded.len > MAX_BUFFER_SIZE - HEADERS_ENTRY_LEN) {
+        return;
+    }
+
+    u32 zero = 0;
+    struct encoded_message_t *msg = bpf_map_lookup_elem(&encoded_message_storage_map, &zero);
+    if (msg == NULL) {
+        return;
+    }
+    char *buf = msg->buf;
+
+    u32 off = encoded.len & ENCODED_MASK;
+    if (bpf_probe_read_user(buf, off, encoded.array) != 0) {
+        return;
+    }
+
+    char prefix[HEADERS_ENTRY_PREFIX_LEN] = {
+        0x7a, HEADERS_ENTRY_LEN - 2,
+        0x0a, W3C_KEY_LENGTH, 't', 'r', 'a', 'c', 'e', 'p', 'a', 'r', 'e', 'n', 't',
+        0x12, W3C_VAL_LENGTH,
+    };
+    for (int i = 0; i < HEADERS_ENTRY_PREFIX_LEN; i++) {
+        buf[(off + i) & ENCODED_MASK] = prefix[i];
+    }
+    off += HEADERS_ENTRY_PREFIX_LEN;
+    char val[W3C_VAL_LENGTH];
+    span_context_to_w3c_string(&state->event.sc, val);
+    for (int i = 0; i < W3C_VAL_LENGTH; i++) {
+        buf[(off + i) & ENCODED_MASK] = val[i];
+    }
+    off += W3C_VAL_LENGTH;
+
+    void *ptr = write_target_data(buf, off);
+    if (ptr == NULL) {
+        bpf_printk("inject_traceparent: failed to write task message");
+        return;
+    }
+
+    encoded.array = ptr;
+    encoded.len = off;
+    encoded.cap = off;
+    long res = bpf_probe_write_user(encoded_ptr, &encoded, sizeof(encoded));
+    if (res != 0) {
+        bpf_printk("inject_traceparent: failed to write task message slice");
+    }
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) EnqueueContext(ctx context.Context, task *Task, opts ...Option) (*TaskInfo, error)
+SEC("uprobe/Client_EnqueueContext")
+int uprobe_Client_EnqueueContext(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&asynq_events, &key) != NULL) {
+        return 0;
+    }
+
+    void *task = get_argument(ctx, 4);
+    if (task == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct asynq_enqueue_state_t *state = bpf_map_lookup_elem(&asynq_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("uprobe/Client_EnqueueContext: state is NULL");
+        return 0;
+    }
+    __builtin_memset(state, 0, sizeof(struct asynq_enqueue_state_t));
+    struct asynq_enqueue_t *enqueue = &state->event;
+    enqueue->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr(task + task_typename_pos, enqueue->task_type, sizeof(enqueue->task_type));
+    bpf_probe_read_user(&enqueue->body_size, sizeof(enqueue->body_size), task + task_payload_pos + offsetof(struct go_slice, len));
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &enqueue->psc,
+        .sc = &enqueue->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&asynq_events, &key, state, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) EnqueueContext(ctx context.Context, task *Task, opts ...Option) (*TaskInfo, error)
+SEC("uprobe/Client_EnqueueContext")
+int uprobe_Client_EnqueueContext_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct asynq_enqueue_state_t *state = bpf_map_lookup_elem(&asynq_events, &key);
+    if (state == NULL) {
+        return 0;
+    }
+    struct asynq_enqueue_t *enqueue = &state->event;
+    enqueue->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 2) != NULL) {
+        enqueue->has_error = 1;
+    }
+
+    output_span_event(ctx, enqueue, sizeof(*enqueue), &enqueue->sc);
+    bpf_map_delete_elem(&asynq_events, &key);
+    return 0;
+}
+
+// Reads the args of runScriptWithErrorCode. The arguments preceding them fill
+// the 9 integer argument registers of amd64, they are passed on the stack.
+// They are passed in registers on arm64, which has 16 of them.
+static __always_inline long get_script_args(struct pt_regs *ctx, struct go_slice *args) {
+#if defined(bpf_target_x86)
+    return bpf_probe_read_user(args, sizeof(*args), get_stack_arguments(ctx));
+#elif defined(bpf_target_arm64)
+    args->array = (void *)__PT_REGS_CAST(ctx)->regs[9];
+    args->len = (s64)__PT_REGS_CAST(ctx)->regs[10];
+    args->cap = (s64)__PT_REGS_CAST(ctx)->regs[11];
+    return 0;
+#endif
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *RDB) runScriptWithErrorCode(ctx context.Context, op errors.Op, script *redis.Script, keys []string, args ...interface{}) (int64, error)
+SEC("uprobe/RDB_runScriptWithErrorCode")
+int uprobe_RDB_runScriptWithErrorCode(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct asynq_enqueue_state_t *state = bpf_map_lookup_elem(&asynq_events, &key);
+    if (state == NULL || state->written) {
+        return 0;
+    }
+
+    // The task message is written with a script whose first key is the one of
+    // the message, and whose first argument is the encoded message.
+    void *keys_ptr = get_argument(ctx, 7);
+    u64 keys_len = (u64)get_argument(ctx, 8);
+    if (keys_ptr == NULL || keys_len < 1) {
+        return 0;
+    }
+    struct go_slice args = {0};
+    if (get_script_args(ctx, &args) != 0 || args.array == NULL || args.len < 1) {
+        return 0;
+    }
+    state->written = 1;
+    get_go_string_from_user_ptr(keys_ptr, state->event.task_key, sizeof(state->event.task_key));
+
+    struct go_iface encoded = {0};
+    if (bpf_probe_read_user(&encoded, sizeof(encoded), args.array) != 0 || encoded.data == NULL) {
+        return 0;
+    }
+    inject_traceparent(state, encoded.data);
+    return 0;
+}
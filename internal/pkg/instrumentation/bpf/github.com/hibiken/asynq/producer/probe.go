@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides an instrumentation probe for the clients of
+// Asynq task queues using the [github.com/hibiken/asynq] package.
+package producer
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/hibiken/asynq"
+
+// minVersion is the first version supported by the probe.
+var minVersion = semver.New(0, 24, 0, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("task_typename_pos", "Task", "typename"),
+				fieldConst("task_payload_pos", "Task", "payload"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*Client).EnqueueContext",
+					EntryProbe:         "uprobe_Client_EnqueueContext",
+					ReturnProbe:        "uprobe_Client_EnqueueContext_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + "/internal/rdb.(*RDB).runScriptWithErrorCode",
+					EntryProbe:         "uprobe_RDB_runScriptWithErrorCode",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a task enqueued by the client.
+type event struct {
+	context.BaseSpanProperties
+	TaskType [128]byte
+	// TaskKey is the key of the task message written to Redis.
+	TaskKey [256]byte
+	// BodySize is the size of the payload of the task.
+	BodySize uint64
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	taskType := unix.ByteSliceToString(e.TaskType[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("redis"),
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("enqueue"),
+	}
+	if queue, id, ok := parseTaskKey(unix.ByteSliceToString(e.TaskKey[:])); ok {
+		attrs = append(attrs,
+			semconv.MessagingDestinationName(queue),
+			semconv.MessagingMessageID(id),
+		)
+	}
+	attrs = append(attrs, semconv.MessagingMessageBodySize(int(e.BodySize))) // nolint: gosec  // Bounded by the max payload.
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(taskType))
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// parseTaskKey returns the queue and the ID of the task of the key of its
+// message, e.g. "critical" and "42" for "asynq:{critical}:t:42".
+func parseTaskKey(key string) (queue, id string, ok bool) {
+	rest, ok := strings.CutPrefix(key, "asynq:{")
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, "}:t:")
+}
+
+// spanName returns the name of the span of a task of taskType enqueued, e.g.
+// "email:deliver enqueue".
+func spanName(taskType string) string {
+	if taskType == "" {
+		return "enqueue"
+	}
+	return taskType + " enqueue"
+}
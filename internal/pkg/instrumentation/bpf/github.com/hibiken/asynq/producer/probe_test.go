@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindProducer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(taskType, key string, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           2,
+		}
+		copy(e.TaskType[:], taskType)
+		copy(e.TaskKey[:], key)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newSpans := func(name string, code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		return f.Spans(name, code, append([]attribute.KeyValue{
+			semconv.MessagingSystemKey.String("redis"),
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingOperationName("enqueue"),
+		}, attrs...)...)
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "enqueue",
+			event: newEvent("email:deliver", "asynq:{critical}:t:42", false),
+			want: newSpans(
+				"email:deliver enqueue",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingDestinationName("critical"),
+				semconv.MessagingMessageID("42"),
+				semconv.MessagingMessageBodySize(2),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("email:deliver", "", true),
+			want: newSpans(
+				"email:deliver enqueue",
+				ptrace.StatusCodeError,
+				semconv.MessagingMessageBodySize(2),
+			),
+		},
+		{
+			name:  "no type",
+			event: newEvent("", "", true),
+			want: newSpans(
+				"enqueue",
+				ptrace.StatusCodeError,
+				semconv.MessagingMessageBodySize(2),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestParseTaskKey(t *testing.T) {
+	queue, id, ok := parseTaskKey("asynq:{default}:t:a1b2")
+	assert.True(t, ok)
+	assert.Equal(t, "default", queue)
+	assert.Equal(t, "a1b2", id)
+
+	_, _, ok = parseTaskKey("asynq:{default}:pending")
+	assert.False(t, ok)
+
+	_, _, ok = parseTaskKey("other")
+	assert.False(t, ok)
+}
@@ -0,0 +1,356 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "utils.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_ORG_SIZE 64
+#define MAX_BUCKET_SIZE 64
+#define MAX_QUERY_SIZE 1024
+// The number of spans a batch of points is linked to. The spans of the writes
+// of points past it are not linked.
+#define MAX_LINKS 8
+#define MAX_CONCURRENT 50
+// The number of writers, and of the batches they send, tracked. The least
+// recently used ones are evicted once it is reached.
+#define MAX_BATCHES 1024
+
+struct influxdb_request_t {
+    BASE_SPAN_PROPERTIES
+    char org[MAX_ORG_SIZE];
+    char bucket[MAX_BUCKET_SIZE];
+    char query[MAX_QUERY_SIZE];
+    // The spans of the writes of the points of a batch, other than its
+    // parent.
+    struct span_context links[MAX_LINKS];
+    // The number of points of a batch, only valid if has_points is set.
+    u64 points;
+    u8 links_len;
+    u8 is_query;
+    u8 has_points;
+    u8 has_error;
+    u8 padding[4];
+};
+
+// The points written, and not yet sent, by a writer, or sent by a batch.
+struct influxdb_batch_t {
+    struct span_context links[MAX_LINKS];
+    u64 points;
+    u8 links_len;
+    u8 padding[7];
+};
+
+// Requests being sent, keyed by the goroutine sending them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct influxdb_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} influxdb_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct influxdb_request_t));
+    __uint(max_entries, 1);
+} influxdb_storage_map SEC(".maps");
+
+// The points pending in a writer, keyed by the writer.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct influxdb_batch_t);
+    __uint(max_entries, MAX_BATCHES);
+} influxdb_pending SEC(".maps");
+
+// The writer whose pending points are batched next, keyed by the goroutine
+// batching them.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_BATCHES);
+} influxdb_batch_writers SEC(".maps");
+
+// The points of the batches created, keyed by the batch. They are kept until
+// evicted as a batch is sent again when retried.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct influxdb_batch_t);
+    __uint(max_entries, MAX_BATCHES);
+} influxdb_batches SEC(".maps");
+
+// Injected in init
+volatile const u64 query_max_size;
+
+volatile const u64 service_org_pos;
+volatile const u64 service_bucket_pos;
+volatile const u64 write_api_write_buffer_pos;
+volatile const u64 query_api_org_pos;
+
+// Returns a zeroed request from the per-CPU storage, with its start time set.
+static __always_inline struct influxdb_request_t *new_request() {
+    u32 map_id = 0;
+    struct influxdb_request_t *req = bpf_map_lookup_elem(&influxdb_storage_map, &map_id);
+    if (req == NULL) {
+        return NULL;
+    }
+    __builtin_memset(req, 0, sizeof(*req));
+    req->start_time = get_time_ns();
+    return req;
+}
+
+// Starts the span of req, child of the span of the context argument of the
+// instrumented method.
+static __always_inline void start_influxdb_span(struct pt_regs *ctx, struct influxdb_request_t *req) {
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+}
+
+// Ends the span of the goroutine, failed if the err_pos register is not zero.
+static __always_inline int end_influxdb_span(struct pt_regs *ctx, u64 err_pos) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct influxdb_request_t *req = bpf_map_lookup_elem(&influxdb_events, &key);
+    if (req == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+
+    if (get_argument(ctx, err_pos) != NULL) {
+        req->has_error = 1;
+    }
+
+    req->end_time = get_time_ns();
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    stop_tracking_span(&req->sc, &req->psc);
+    bpf_map_delete_elem(&influxdb_events, &key);
+    return 0;
+}
+
+// Returns the points pending in the writer, tracking them if none are.
+static __always_inline struct influxdb_batch_t *get_pending(void *writer) {
+    struct influxdb_batch_t *pending = bpf_map_lookup_elem(&influxdb_pending, &writer);
+    if (pending != NULL) {
+        return pending;
+    }
+    struct influxdb_batch_t empty = {0};
+    bpf_map_update_elem(&influxdb_pending, &writer, &empty, BPF_NOEXIST);
+    return bpf_map_lookup_elem(&influxdb_pending, &writer);
+}
+
+// Adds the count points written by the blocking writer, with the context
+// argument of the instrumented method, to the points pending in it. The span
+// of the context is linked to the batch sending them, once for consecutive
+// writes in the same span. The points are batched next by the goroutine.
+static __always_inline void add_pending(struct pt_regs *ctx, void *writer, u64 count) {
+    struct influxdb_batch_t *pending = get_pending(writer);
+    if (pending == NULL) {
+        return;
+    }
+    // The points of a concurrent write can be counted in a batch sent before
+    // they are added to it.
+    __sync_fetch_and_add(&pending->points, count);
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    struct span_context *sc = get_parent_span_context(&go_context);
+    u8 n = pending->links_len;
+    if (sc != NULL && n < MAX_LINKS) {
+        if (n == 0 || !bpf_memcmp((char *)pending->links[(n - 1) & (MAX_LINKS - 1)].SpanID, (char *)sc->SpanID, SPAN_ID_SIZE)) {
+            pending->links[n & (MAX_LINKS - 1)] = *sc;
+            pending->links_len = n + 1;
+        }
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&influxdb_batch_writers, &key, &writer, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *writeAPIBlocking) WritePoint(ctx context.Context, point ...*write.Point) error
+SEC("uprobe/writeAPIBlocking_WritePoint")
+int uprobe_writeAPIBlocking_WritePoint(struct pt_regs *ctx) {
+    void *writer = get_argument(ctx, 1);
+    u64 points_len = (u64)get_argument(ctx, 5);
+    add_pending(ctx, writer, points_len);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *writeAPIBlocking) WriteRecord(ctx context.Context, line ...string) error
+SEC("uprobe/writeAPIBlocking_WriteRecord")
+int uprobe_writeAPIBlocking_WriteRecord(struct pt_regs *ctx) {
+    void *writer = get_argument(ctx, 1);
+    u64 lines_len = (u64)get_argument(ctx, 5);
+    add_pending(ctx, writer, lines_len);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *writeAPIBlocking) Flush(ctx context.Context) error
+SEC("uprobe/writeAPIBlocking_Flush")
+int uprobe_writeAPIBlocking_Flush(struct pt_regs *ctx) {
+    void *writer = get_argument(ctx, 1);
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&influxdb_batch_writers, &key, &writer, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *WriteAPIImpl) flushBuffer()
+SEC("uprobe/WriteAPIImpl_flushBuffer")
+int uprobe_WriteAPIImpl_flushBuffer(struct pt_regs *ctx) {
+    void *writer = get_argument(ctx, 1);
+    if (writer == NULL) {
+        return 0;
+    }
+
+    // The points of the non-blocking writer are written without a context,
+    // the batch is not linked to the spans writing them.
+    struct influxdb_batch_t pending = {0};
+    bpf_probe_read_user(&pending.points, sizeof(pending.points), writer + write_api_write_buffer_pos + offsetof(struct go_slice, len));
+    bpf_map_update_elem(&influxdb_pending, &writer, &pending, 0);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&influxdb_batch_writers, &key, &writer, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func NewBatch(data string, expireDelayMs uint) *Batch
+SEC("uprobe/NewBatch")
+int uprobe_NewBatch_Returns(struct pt_regs *ctx) {
+    void *batch = get_argument(ctx, 1);
+    if (batch == NULL) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    void **writer = bpf_map_lookup_elem(&influxdb_batch_writers, &key);
+    if (writer == NULL) {
+        // The batch reuses the memory of an evicted one.
+        bpf_map_delete_elem(&influxdb_batches, &batch);
+        return 0;
+    }
+    void *writer_ptr = *writer;
+    bpf_map_delete_elem(&influxdb_batch_writers, &key);
+
+    struct influxdb_batch_t *pending = bpf_map_lookup_elem(&influxdb_pending, &writer_ptr);
+    if (pending == NULL) {
+        bpf_map_delete_elem(&influxdb_batches, &batch);
+        return 0;
+    }
+    bpf_map_update_elem(&influxdb_batches, &batch, pending, 0);
+    bpf_map_delete_elem(&influxdb_pending, &writer_ptr);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *Service) WriteBatch(ctx context.Context, batch *Batch) *http2.Error
+SEC("uprobe/Service_WriteBatch")
+int uprobe_Service_WriteBatch(struct pt_regs *ctx) {
+    void *service = get_argument(ctx, 1);
+    if (service == NULL) {
+        return 0;
+    }
+
+    struct influxdb_request_t *req = new_request();
+    if (req == NULL) {
+        return 0;
+    }
+    get_go_string_from_user_ptr(service + service_org_pos, req->org, sizeof(req->org));
+    get_go_string_from_user_ptr(service + service_bucket_pos, req->bucket, sizeof(req->bucket));
+
+    start_influxdb_span(ctx, req);
+
+    void *batch_ptr = get_argument(ctx, 4);
+    struct influxdb_batch_t *batch = bpf_map_lookup_elem(&influxdb_batches, &batch_ptr);
+    if (batch != NULL) {
+        req->points = batch->points;
+        req->has_points = 1;
+
+        // The span of writes sending their own points is the parent of the
+        // batch, it is not linked.
+        u8 n = 0;
+        for (u8 i = 0; i < MAX_LINKS; i++) {
+            if (i >= batch->links_len) {
+                break;
+            }
+            if (bpf_memcmp((char *)batch->links[i].SpanID, (char *)req->psc.SpanID, SPAN_ID_SIZE)) {
+                continue;
+            }
+            req->links[n & (MAX_LINKS - 1)] = batch->links[i];
+            n++;
+        }
+        req->links_len = n;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&influxdb_events, &key, req, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *Service) WriteBatch(ctx context.Context, batch *Batch) *http2.Error
+SEC("uprobe/Service_WriteBatch")
+int uprobe_Service_WriteBatch_Returns(struct pt_regs *ctx) {
+    return end_influxdb_span(ctx, 1);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (q *queryAPI) Query(ctx context.Context, query string) (*QueryTableResult, error)
+SEC("uprobe/queryAPI_Query")
+int uprobe_queryAPI_Query(struct pt_regs *ctx) {
+    void *query_api = get_argument(ctx, 1);
+    if (query_api == NULL) {
+        return 0;
+    }
+
+    struct influxdb_request_t *req = new_request();
+    if (req == NULL) {
+        return 0;
+    }
+    req->is_query = 1;
+    get_go_string_from_user_ptr(query_api + query_api_org_pos, req->org, sizeof(req->org));
+
+    void *query_ptr = get_argument(ctx, 4);
+    u64 query_len = (u64)get_argument(ctx, 5);
+    u64 query_size = query_max_size < query_len ? query_max_size : query_len;
+    if (query_size > MAX_QUERY_SIZE) {
+        query_size = MAX_QUERY_SIZE;
+    }
+    if (query_ptr != NULL && query_size > 0) {
+        bpf_probe_read_user(req->query, query_size, query_ptr);
+    }
+
+    start_influxdb_span(ctx, req);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&influxdb_events, &key, req, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (q *queryAPI) Query(ctx context.Context, query string) (*QueryTableResult, error)
+SEC("uprobe/queryAPI_Query")
+int uprobe_queryAPI_Query_Returns(struct pt_regs *ctx) {
+    return end_influxdb_span(ctx, 2);
+}
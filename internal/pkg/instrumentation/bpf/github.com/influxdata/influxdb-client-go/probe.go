@@ -0,0 +1,262 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package influxdb provides an instrumentation probe for InfluxDB clients
+// using the [github.com/influxdata/influxdb-client-go/v2] package.
+package influxdb
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/influxdata/influxdb-client-go/v2"
+	// apiPkg is the package implementing the write and query APIs.
+	apiPkg = pkg + "/api"
+	// writePkg is the package implementing the sending of batches of points.
+	writePkg = pkg + "/internal/write"
+
+	// QueryMaxLengthEnvVar is the environment variable used to configure the
+	// maximum length, in bytes, of the Flux queries recorded. Longer queries
+	// are truncated, and queries are not recorded if it is zero.
+	QueryMaxLengthEnvVar = "OTEL_GO_AUTO_INFLUXDB_QUERY_MAX_LENGTH"
+
+	// maxQueryLength is the maximum length of the Flux queries recorded, and
+	// the one used if QueryMaxLengthEnvVar is not set. It is the size of the
+	// query buffer of the eBPF program.
+	maxQueryLength = 1024
+)
+
+const (
+	// orgKey is the attribute key of the organization of a request.
+	orgKey = attribute.Key("influxdb.org")
+	// bucketKey is the attribute key of the bucket points are written to.
+	bucketKey = attribute.Key("influxdb.bucket")
+	// pointsKey is the attribute key of the number of points written by a
+	// batch.
+	pointsKey = attribute.Key("influxdb.points")
+)
+
+// minVersion is the first release of the
+// github.com/influxdata/influxdb-client-go/v2 module.
+var minVersion = semver.New(2, 0, 1, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	queryMaxLen, err := queryMaxLength(os.Getenv(QueryMaxLengthEnvVar))
+	if err != nil {
+		logger.Error("invalid maximum query length, using default", "error", err, "default", queryMaxLen)
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, pkgPath, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkgPath, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	const writeBatch = writePkg + ".(*Service).WriteBatch"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{Key: "query_max_size", Val: queryMaxLen},
+				fieldConst("service_org_pos", writePkg, "Service", "org"),
+				fieldConst("service_bucket_pos", writePkg, "Service", "bucket"),
+				fieldConst("write_api_write_buffer_pos", apiPkg, "WriteAPIImpl", "writeBuffer"),
+				fieldConst("query_api_org_pos", apiPkg, "queryAPI", "org"),
+			},
+			// The methods of APIs are not linked if they are not used.
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                writeBatch,
+					EntryProbe:         "uprobe_Service_WriteBatch",
+					ReturnProbe:        "uprobe_Service_WriteBatch_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					Sym:                writePkg + ".NewBatch",
+					ReturnProbe:        "uprobe_NewBatch_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{writeBatch},
+				},
+				{
+					Sym:                apiPkg + ".(*writeAPIBlocking).WritePoint",
+					EntryProbe:         "uprobe_writeAPIBlocking_WritePoint",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{writeBatch},
+				},
+				{
+					Sym:                apiPkg + ".(*writeAPIBlocking).WriteRecord",
+					EntryProbe:         "uprobe_writeAPIBlocking_WriteRecord",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{writeBatch},
+				},
+				{
+					// Added in v2.10.0.
+					Sym:                apiPkg + ".(*writeAPIBlocking).Flush",
+					EntryProbe:         "uprobe_writeAPIBlocking_Flush",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{writeBatch},
+				},
+				{
+					Sym:                apiPkg + ".(*WriteAPIImpl).flushBuffer",
+					EntryProbe:         "uprobe_WriteAPIImpl_flushBuffer",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{writeBatch},
+				},
+				{
+					Sym:                apiPkg + ".(*queryAPI).Query",
+					EntryProbe:         "uprobe_queryAPI_Query",
+					ReturnProbe:        "uprobe_queryAPI_Query_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// queryMaxLength returns the maximum length of the queries recorded parsed
+// from val, or maxQueryLength if val is empty or invalid. Lengths greater
+// than maxQueryLength are reduced to it.
+func queryMaxLength(val string) (uint64, error) {
+	if val == "" {
+		return maxQueryLength, nil
+	}
+	n, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return maxQueryLength, err
+	}
+	return min(n, maxQueryLength), nil
+}
+
+// event represents a batch of points written, or a query sent, by a client.
+type event struct {
+	context.BaseSpanProperties
+	Org    [64]byte
+	Bucket [64]byte
+	// Query is the Flux query text, truncated to the configured length.
+	Query [maxQueryLength]byte
+	// Links are the span contexts of the writes of the points of a batch,
+	// other than the one of its parent. Only the first LinksLen are valid.
+	Links [8]context.EBPFSpanContext
+	// Points is the number of points of a batch, only valid if HasPoints is
+	// set.
+	Points    uint64
+	LinksLen  uint8
+	IsQuery   uint8
+	HasPoints uint8
+	HasError  uint8
+	_         [4]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameInfluxdb}
+
+	if org := unix.ByteSliceToString(e.Org[:]); org != "" {
+		attrs = append(attrs, orgKey.String(org))
+	}
+
+	var name string
+	if e.IsQuery != 0 {
+		name = "query"
+		attrs = append(attrs, semconv.DBOperationName(name))
+		if query := unix.ByteSliceToString(e.Query[:]); query != "" {
+			attrs = append(attrs, semconv.DBQueryText(query))
+		}
+	} else {
+		name = "write"
+		attrs = append(attrs, semconv.DBOperationName(name))
+		if bucket := unix.ByteSliceToString(e.Bucket[:]); bucket != "" {
+			attrs = append(attrs, bucketKey.String(bucket))
+			name += " " + bucket
+		}
+		if e.HasPoints != 0 {
+			attrs = append(attrs, pointsKey.Int64(int64(min(e.Points, math.MaxInt64)))) // nolint: gosec  // Bounded.
+		}
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	for _, l := range e.Links[:min(int(e.LinksLen), len(e.Links))] {
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID(l.TraceID))
+		link.SetSpanID(pcommon.SpanID(l.SpanID))
+		link.SetFlags(uint32(l.TraceFlags))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+	link := context.EBPFSpanContext{
+		TraceID:    trace.TraceID{3},
+		SpanID:     trace.SpanID{3},
+		TraceFlags: trace.FlagsSampled,
+	}
+
+	newEvent := func(parent trace.SpanID, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: context.BaseSpanProperties{
+				StartTime:         f.StartOffset,
+				EndTime:           f.EndOffset,
+				SpanContext:       context.EBPFSpanContext{TraceID: f.TraceID, SpanID: f.SpanID},
+				ParentSpanContext: context.EBPFSpanContext{TraceID: f.TraceID, SpanID: parent},
+			},
+		}
+		copy(e.Org[:], "acme")
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newWrite := func(parent trace.SpanID, hasError bool, links ...context.EBPFSpanContext) *event {
+		e := newEvent(parent, hasError)
+		copy(e.Bucket[:], "metrics")
+		e.Points = 3
+		e.HasPoints = 1
+		e.LinksLen = uint8(copy(e.Links[:], links)) // nolint: gosec  // Bounded.
+		return e
+	}
+
+	newQuery := func(query string, hasError bool) *event {
+		e := newEvent(f.ParentSpanID, hasError)
+		e.IsQuery = 1
+		copy(e.Query[:], query)
+		return e
+	}
+
+	newSpans := func(name string, parent trace.SpanID, code ptrace.StatusCode, links []context.EBPFSpanContext, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		fixture := f
+		fixture.ParentSpanID = parent
+		spans := fixture.Spans(name, code, append([]attribute.KeyValue{
+			semconv.DBSystemNameInfluxdb,
+			orgKey.String("acme"),
+		}, attrs...)...)
+		for _, l := range links {
+			link := spans.At(0).Links().AppendEmpty()
+			link.SetTraceID(pcommon.TraceID(l.TraceID))
+			link.SetSpanID(pcommon.SpanID(l.SpanID))
+			link.SetFlags(uint32(l.TraceFlags))
+		}
+		return spans
+	}
+
+	write := []attribute.KeyValue{
+		semconv.DBOperationName("write"),
+		bucketKey.String("metrics"),
+		pointsKey.Int64(3),
+	}
+
+	const query = `from(bucket: "metrics") |> range(start: -1h)`
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "write",
+			event: newWrite(f.ParentSpanID, false),
+			want:  newSpans("write metrics", f.ParentSpanID, ptrace.StatusCodeUnset, nil, write...),
+		},
+		{
+			name:  "write error",
+			event: newWrite(f.ParentSpanID, true),
+			want:  newSpans("write metrics", f.ParentSpanID, ptrace.StatusCodeError, nil, write...),
+		},
+		{
+			name:  "batch",
+			event: newWrite(trace.SpanID{}, false, link),
+			want:  newSpans("write metrics", trace.SpanID{}, ptrace.StatusCodeUnset, []context.EBPFSpanContext{link}, write...),
+		},
+		{
+			name:  "query",
+			event: newQuery(query, false),
+			want: newSpans(
+				"query",
+				f.ParentSpanID,
+				ptrace.StatusCodeUnset,
+				nil,
+				semconv.DBOperationName("query"),
+				semconv.DBQueryText(query),
+			),
+		},
+		{
+			name:  "query not recorded",
+			event: newQuery("", true),
+			want: newSpans(
+				"query",
+				f.ParentSpanID,
+				ptrace.StatusCodeError,
+				nil,
+				semconv.DBOperationName("query"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestQueryMaxLength(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    uint64
+		wantErr bool
+	}{
+		{val: "", want: maxQueryLength},
+		{val: "0", want: 0},
+		{val: "64", want: 64},
+		{val: strconv.Itoa(maxQueryLength + 1), want: maxQueryLength},
+		{val: "-1", want: maxQueryLength, wantErr: true},
+		{val: "long", want: maxQueryLength, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			got, err := queryMaxLength(tt.val)
+			assert.Equal(t, tt.want, got)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_QUERY_SIZE 256
+#define MAX_HOST_SIZE 128
+#define MAX_CONCURRENT 50
+
+struct pgx_request_t {
+    BASE_SPAN_PROPERTIES
+    char query[MAX_QUERY_SIZE];
+    char host[MAX_HOST_SIZE];
+    u16 port;
+    u8 has_error;
+    u8 padding[5];
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct pgx_request_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} pgx_events SEC(".maps");
+
+// Injected in init
+volatile const bool should_include_db_statement;
+
+// github.com/jackc/pgx/v5 offsets.
+volatile const u64 conn_pg_conn_pos;
+volatile const u64 pg_conn_config_pos;
+volatile const u64 config_host_pos;
+volatile const u64 config_port_pos;
+
+// github.com/jackc/pgx/v4 offsets, its pgconn package is the
+// github.com/jackc/pgconn module.
+volatile const u64 conn_pg_conn_pos_v4;
+volatile const u64 pg_conn_config_pos_v4;
+volatile const u64 config_host_pos_v4;
+volatile const u64 config_port_pos_v4;
+
+struct pgx_offsets_t {
+    u64 conn_pg_conn_pos;
+    u64 pg_conn_config_pos;
+    u64 config_host_pos;
+    u64 config_port_pos;
+};
+
+// Reads the host and port of the pgconn.Config of the Conn pointed to by
+// conn_ptr into pgx_request.
+static __always_inline void read_server(void *conn_ptr, struct pgx_offsets_t *offsets, struct pgx_request_t *pgx_request) {
+    void *pg_conn_ptr = NULL;
+    long res = bpf_probe_read_user(&pg_conn_ptr, sizeof(pg_conn_ptr), (void *)(conn_ptr + offsets->conn_pg_conn_pos));
+    if (res != 0 || pg_conn_ptr == NULL) {
+        return;
+    }
+
+    void *config_ptr = NULL;
+    res = bpf_probe_read_user(&config_ptr, sizeof(config_ptr), (void *)(pg_conn_ptr + offsets->pg_conn_config_pos));
+    if (res != 0 || config_ptr == NULL) {
+        return;
+    }
+
+    get_go_string_from_user_ptr((void *)(config_ptr + offsets->config_host_pos), pgx_request->host, sizeof(pgx_request->host));
+    bpf_probe_read_user(&pgx_request->port, sizeof(pgx_request->port), (void *)(config_ptr + offsets->config_port_pos));
+}
+
+// Starts the span of the query sent by the Conn. Both of the instrumented
+// functions have the same leading arguments:
+// func (c *Conn) F(ctx context.Context, sql string, ...)
+static __always_inline int start_pgx_span(struct pt_regs *ctx, struct pgx_offsets_t *offsets) {
+    // argument positions
+    u64 conn_ptr_pos = 1;
+    u64 query_str_ptr_pos = 4;
+    u64 query_str_len_pos = 5;
+
+    struct pgx_request_t pgx_request = {0};
+    pgx_request.start_time = get_time_ns();
+
+    if (should_include_db_statement) {
+        // Read Query string
+        void *query_str_ptr = get_argument(ctx, query_str_ptr_pos);
+        u64 query_str_len = (u64)get_argument(ctx, query_str_len_pos);
+        u64 query_size = MAX_QUERY_SIZE < query_str_len ? MAX_QUERY_SIZE : query_str_len;
+        bpf_probe_read(pgx_request.query, query_size, query_str_ptr);
+    }
+
+    read_server(get_argument(ctx, conn_ptr_pos), offsets, &pgx_request);
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &pgx_request.psc,
+        .sc = &pgx_request.sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    // Get key
+    void *key = (void *)GOROUTINE(ctx);
+
+    bpf_map_update_elem(&pgx_events, &key, &pgx_request, 0);
+    return 0;
+}
+
+// Ends the span of the query sent by the Conn. The returned error is the
+// interface in the err_pos (type) and err_pos+1 (data) registers.
+static __always_inline int end_pgx_span(struct pt_regs *ctx, u64 err_pos) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct pgx_request_t *pgx_request = bpf_map_lookup_elem(&pgx_events, &key);
+    if (pgx_request == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, err_pos) != NULL) {
+        pgx_request->has_error = 1;
+    }
+
+    pgx_request->end_time = get_time_ns();
+    output_span_event(ctx, pgx_request, sizeof(*pgx_request), &pgx_request->sc);
+    stop_tracking_span(&pgx_request->sc, &pgx_request->psc);
+    bpf_map_delete_elem(&pgx_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
+SEC("uprobe/Conn_Query")
+int uprobe_Conn_Query(struct pt_regs *ctx) {
+    struct pgx_offsets_t offsets = {
+        .conn_pg_conn_pos = conn_pg_conn_pos,
+        .pg_conn_config_pos = pg_conn_config_pos,
+        .config_host_pos = config_host_pos,
+        .config_port_pos = config_port_pos,
+    };
+    return start_pgx_span(ctx, &offsets);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
+//
+// It is used for both v4 and v5, the Rows interface precedes the error.
+SEC("uprobe/Conn_Query")
+int uprobe_Conn_Query_Returns(struct pt_regs *ctx) {
+    return end_pgx_span(ctx, 3);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+SEC("uprobe/Conn_Exec")
+int uprobe_Conn_Exec(struct pt_regs *ctx) {
+    struct pgx_offsets_t offsets = {
+        .conn_pg_conn_pos = conn_pg_conn_pos,
+        .pg_conn_config_pos = pg_conn_config_pos,
+        .config_host_pos = config_host_pos,
+        .config_port_pos = config_port_pos,
+    };
+    return start_pgx_span(ctx, &offsets);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+//
+// The v5 CommandTag is a struct holding a string.
+SEC("uprobe/Conn_Exec")
+int uprobe_Conn_Exec_Returns(struct pt_regs *ctx) {
+    return end_pgx_span(ctx, 3);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Query(ctx context.Context, sql string, args ...interface{}) (Rows, error)
+SEC("uprobe/Conn_Query_v4")
+int uprobe_Conn_Query_v4(struct pt_regs *ctx) {
+    struct pgx_offsets_t offsets = {
+        .conn_pg_conn_pos = conn_pg_conn_pos_v4,
+        .pg_conn_config_pos = pg_conn_config_pos_v4,
+        .config_host_pos = config_host_pos_v4,
+        .config_port_pos = config_port_pos_v4,
+    };
+    return start_pgx_span(ctx, &offsets);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+SEC("uprobe/Conn_Exec_v4")
+int uprobe_Conn_Exec_v4(struct pt_regs *ctx) {
+    struct pgx_offsets_t offsets = {
+        .conn_pg_conn_pos = conn_pg_conn_pos_v4,
+        .pg_conn_config_pos = pg_conn_config_pos_v4,
+        .config_host_pos = config_host_pos_v4,
+        .config_port_pos = config_port_pos_v4,
+    };
+    return start_pgx_span(ctx, &offsets);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Conn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+//
+// The v4 CommandTag is a byte slice.
+SEC("uprobe/Conn_Exec_v4")
+int uprobe_Conn_Exec_v4_Returns(struct pt_regs *ctx) {
+    return end_pgx_span(ctx, 4);
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pgx provides an instrumentation probe for PostgreSQL clients using
+// the [github.com/jackc/pgx/v5] or [github.com/jackc/pgx/v4] packages.
+package pgx
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented, in all of its major versions.
+	pkg = "github.com/jackc/pgx"
+
+	// pkgV5 is the v5 package being instrumented.
+	pkgV5 = "github.com/jackc/pgx/v5"
+	// pkgV4 is the v4 package being instrumented.
+	pkgV4 = "github.com/jackc/pgx/v4"
+	// pgconnV4 is the module of the pgconn package used by v4. It is part of
+	// the pgx module since v5.
+	pgconnV4 = "github.com/jackc/pgconn"
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	// Only one of the major versions is expected to be used by the target
+	// process. The uprobes and offsets of the others are skipped.
+	v5 := probe.PackageConstraints{
+		Package:     pkgV5,
+		Constraints: constraint(">= 5.0.0"),
+		FailureMode: probe.FailureModeIgnore,
+	}
+	v4 := probe.PackageConstraints{
+		Package:     pkgV4,
+		Constraints: constraint(">= 4.0.0, < 5.0.0"),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	offset := func(key, mod, pkgPath, typ, field string) probe.Const {
+		return probe.StructFieldConstOptional{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(mod, pkgPath, typ, field),
+			},
+		}
+	}
+	pgconnV5 := pkgV5 + "/pgconn"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{
+					Key: "should_include_db_statement",
+					Val: shouldIncludeDBStatement(),
+				},
+				offset("conn_pg_conn_pos", pkgV5, pkgV5, "Conn", "pgConn"),
+				offset("pg_conn_config_pos", pkgV5, pgconnV5, "PgConn", "config"),
+				offset("config_host_pos", pkgV5, pgconnV5, "Config", "Host"),
+				offset("config_port_pos", pkgV5, pgconnV5, "Config", "Port"),
+				offset("conn_pg_conn_pos_v4", pkgV4, pkgV4, "Conn", "pgConn"),
+				offset("pg_conn_config_pos_v4", pgconnV4, pgconnV4, "PgConn", "config"),
+				offset("config_host_pos_v4", pgconnV4, pgconnV4, "Config", "Host"),
+				offset("config_port_pos_v4", pgconnV4, pgconnV4, "Config", "Port"),
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkgV5 + ".(*Conn).Query",
+					EntryProbe:         "uprobe_Conn_Query",
+					ReturnProbe:        "uprobe_Conn_Query_Returns",
+					PackageConstraints: []probe.PackageConstraints{v5},
+				},
+				{
+					Sym:                pkgV5 + ".(*Conn).Exec",
+					EntryProbe:         "uprobe_Conn_Exec",
+					ReturnProbe:        "uprobe_Conn_Exec_Returns",
+					PackageConstraints: []probe.PackageConstraints{v5},
+				},
+				{
+					Sym:                pkgV4 + ".(*Conn).Query",
+					EntryProbe:         "uprobe_Conn_Query_v4",
+					ReturnProbe:        "uprobe_Conn_Query_Returns",
+					PackageConstraints: []probe.PackageConstraints{v4},
+				},
+				{
+					Sym:                pkgV4 + ".(*Conn).Exec",
+					EntryProbe:         "uprobe_Conn_Exec_v4",
+					ReturnProbe:        "uprobe_Conn_Exec_v4_Returns",
+					PackageConstraints: []probe.PackageConstraints{v4},
+				},
+			},
+
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func constraint(c string) *semver.Constraints {
+	out, err := semver.NewConstraint(c)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// event represents a query sent by a pgx connection.
+type event struct {
+	context.BaseSpanProperties
+	// Query is the query text, if configured to be included.
+	Query [256]byte
+	// Host and Port are the address of the server from the connection
+	// configuration. Host is a directory for Unix domain sockets.
+	Host     [128]byte
+	Port     uint16
+	HasError uint8
+	_        [5]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNamePostgreSQL}
+
+	name := semconv.DBSystemNamePostgreSQL.Value.AsString()
+	query := unix.ByteSliceToString(e.Query[:])
+	if query != "" {
+		attrs = append(attrs, semconv.DBQueryText(query))
+
+		if shouldParseDBStatement() {
+			operation, target, err := sql.Parse(query)
+			if err == nil && operation != "" {
+				attrs = append(attrs, semconv.DBOperationName(operation))
+				name = operation
+				if target != "" {
+					attrs = append(attrs, semconv.DBCollectionName(target))
+					name += " " + target
+				}
+			}
+		}
+	}
+
+	attrs = append(attrs, netattr.Attributes(server(e), netattr.Addr{})...)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// server returns the address of the server the query was sent to.
+func server(e *event) netattr.Addr {
+	addr := netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:]))
+	switch addr.Transport {
+	case netattr.TransportTCP:
+		addr.Port = int(e.Port)
+	case netattr.TransportUnix:
+		// The host is the directory of the socket, its name is derived from
+		// the port the same way libpq does.
+		addr.Host = path.Join(addr.Host, ".s.PGSQL."+strconv.Itoa(int(e.Port)))
+	}
+	return addr
+}
+
+// shouldIncludeDBStatement returns if the user has configured SQL queries to
+// be included.
+func shouldIncludeDBStatement() bool {
+	return envBool(sql.IncludeDBStatementEnvVar)
+}
+
+// shouldParseDBStatement returns if the user has configured SQL queries to be
+// parsed for their operation and table.
+func shouldParseDBStatement() bool {
+	return envBool(sql.ParseDBStatementEnvVar)
+}
+
+func envBool(key string) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && val
+}
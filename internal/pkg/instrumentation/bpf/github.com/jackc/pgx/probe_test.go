@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package pgx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(query, host string, port uint16, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Port:               port,
+		}
+		copy(e.Query[:], query)
+		copy(e.Host[:], host)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		parse bool
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "query",
+			event: newEvent("SELECT * FROM users", "localhost", 5432, false),
+			want: f.Spans(
+				"postgresql",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBQueryText("SELECT * FROM users"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(5432),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "parsed",
+			parse: true,
+			event: newEvent("SELECT * FROM users", "10.0.0.1", 5433, false),
+			want: f.Spans(
+				"SELECT users",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBQueryText("SELECT * FROM users"),
+				semconv.DBOperationName("SELECT"),
+				semconv.DBCollectionName("users"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(5433),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "unparsable",
+			parse: true,
+			event: newEvent("LISTEN channel", "::1", 5432, false),
+			want: f.Spans(
+				"postgresql",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBQueryText("LISTEN channel"),
+				semconv.ServerAddress("::1"),
+				semconv.ServerPort(5432),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "unix socket",
+			event: newEvent("", "/var/run/postgresql", 5432, true),
+			want: f.Spans(
+				"postgresql",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNamePostgreSQL,
+				semconv.ServerAddress("/var/run/postgresql/.s.PGSQL.5432"),
+				semconv.NetworkTransportUnix,
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", "", 0, false),
+			want:  f.Spans("postgresql", ptrace.StatusCodeUnset, semconv.DBSystemNamePostgreSQL),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.parse {
+				t.Setenv(sql.ParseDBStatementEnvVar, "true")
+			}
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_SUBJECT_SIZE 256
+// The size of the headers searched for a traceparent header, we must have a
+// limit for the verifier.
+#define MAX_HEADERS_SIZE 256
+
+// https://github.com/nats-io/nats.go/blob/v1.11.0/nats.go#L3068
+#define HDR_LINE_LEN 10
+// The length of the "traceparent: <value>\r\n" header line.
+#define TRACEPARENT_LINE_LEN (W3C_KEY_LENGTH + 2 + W3C_VAL_LENGTH + 2)
+
+struct nats_message_t {
+    BASE_SPAN_PROPERTIES
+    char subject[MAX_SUBJECT_SIZE];
+    u64 body_size;
+};
+
+// Messages being processed, keyed by the goroutine reading them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct nats_message_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} nats_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct nats_message_t));
+    __uint(max_entries, 1);
+} nats_storage_map SEC(".maps");
+
+struct headers_buf_t {
+    char buf[MAX_HEADERS_SIZE];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct headers_buf_t));
+    __uint(max_entries, 1);
+} headers_storage_map SEC(".maps");
+
+// The headers of a message, at the start of its data.
+struct message_headers_t {
+    void *ptr;
+    s64 len;
+};
+
+// Injected in init
+volatile const u64 conn_ps_pos;
+volatile const u64 parse_state_ma_pos;
+volatile const u64 msg_arg_subject_pos;
+volatile const u64 msg_arg_hdr_pos;
+
+static __always_inline long extract_span_context_from_headers(void *arg, struct span_context *parent_span_context) {
+    struct message_headers_t *headers = arg;
+    if (headers->len <= HDR_LINE_LEN) {
+        return -1;
+    }
+
+    u32 zero = 0;
+    struct headers_buf_t *headers_buf = bpf_map_lookup_elem(&headers_storage_map, &zero);
+    if (headers_buf == NULL) {
+        return -1;
+    }
+    u64 size = headers->len;
+    if (size > MAX_HEADERS_SIZE) {
+        size = MAX_HEADERS_SIZE;
+    }
+    if (bpf_probe_read_user(headers_buf->buf, size, headers->ptr) != 0) {
+        return -1;
+    }
+
+    // Header lines follow the "NATS/1.0\r\n" version line, their keys are
+    // case insensitive.
+    char key[W3C_KEY_LENGTH + 2] = "traceparent: ";
+    for (u32 i = HDR_LINE_LEN; i < MAX_HEADERS_SIZE - TRACEPARENT_LINE_LEN; i++) {
+        if (i + TRACEPARENT_LINE_LEN > size) {
+            break;
+        }
+        if (headers_buf->buf[i - 1] != '\n') {
+            continue;
+        }
+        if (bpf_memicmp(&headers_buf->buf[i], key, sizeof(key)) == 0) {
+            w3c_string_to_span_context(&headers_buf->buf[i + sizeof(key)], parent_span_context);
+            return 0;
+        }
+    }
+    return -1;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (nc *Conn) processMsg(data []byte)
+SEC("uprobe/Conn_processMsg")
+int uprobe_Conn_processMsg(struct pt_regs *ctx) {
+    /* Messages are processed by the goroutine reading them from the
+    connection, they are then delivered to the channel, or the handler, of the
+    subscription. The consumer does not accept a context.Context, the parent
+    span is only the one of the producer propagated in the message headers. */
+    void *key = (void *)GOROUTINE(ctx);
+    void *conn = get_argument(ctx, 1);
+    void *data_ptr = get_argument(ctx, 2);
+    s64 data_len = (s64)get_argument(ctx, 3);
+
+    u32 zero = 0;
+    struct nats_message_t *nats_message = bpf_map_lookup_elem(&nats_storage_map, &zero);
+    if (nats_message == NULL) {
+        bpf_printk("uprobe/Conn_processMsg: nats_message is NULL");
+        return 0;
+    }
+    __builtin_memset(nats_message, 0, sizeof(struct nats_message_t));
+    nats_message->start_time = get_time_ns();
+
+    // The arguments of the message are parsed in the parse state of the
+    // connection.
+    void *ps = NULL;
+    bpf_probe_read_user(&ps, sizeof(ps), (void *)(conn + conn_ps_pos));
+    if (ps == NULL) {
+        return 0;
+    }
+    void *ma = ps + parse_state_ma_pos;
+    // The subject is a byte slice, it starts with the pointer to, and the
+    // length of, the subject as a string does.
+    get_go_string_from_user_ptr((void *)(ma + msg_arg_subject_pos), nats_message->subject, sizeof(nats_message->subject));
+
+    // The headers size is -1, or 0, for messages without headers.
+    s64 hdr = 0;
+    bpf_probe_read_user(&hdr, sizeof(hdr), (void *)(ma + msg_arg_hdr_pos));
+    if (hdr < 0 || hdr > data_len) {
+        hdr = 0;
+    }
+    nats_message->body_size = data_len - hdr;
+
+    struct message_headers_t headers = {
+        .ptr = data_ptr,
+        .len = hdr,
+    };
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &nats_message->psc,
+        .sc = &nats_message->sc,
+        .get_parent_span_context_fn = extract_span_context_from_headers,
+        .get_parent_span_context_arg = &headers,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&nats_events, &key, nats_message, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (nc *Conn) processMsg(data []byte)
+SEC("uprobe/Conn_processMsg")
+int uprobe_Conn_processMsg_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct nats_message_t *nats_message = bpf_map_lookup_elem(&nats_events, &key);
+    if (nats_message == NULL) {
+        return 0;
+    }
+    nats_message->end_time = end_time;
+
+    output_span_event(ctx, nats_message, sizeof(*nats_message), &nats_message->sc);
+    bpf_map_delete_elem(&nats_events, &key);
+    return 0;
+}
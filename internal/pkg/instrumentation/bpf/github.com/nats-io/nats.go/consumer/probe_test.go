@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	e := &event{
+		BaseSpanProperties: f.BaseSpanProperties(),
+		BodySize:           42,
+	}
+	copy(e.Subject[:], "orders.new")
+
+	want := f.Spans(
+		"orders.new receive",
+		ptrace.StatusCodeUnset,
+		semconv.MessagingSystemKey.String("nats"),
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingOperationName("receive"),
+		semconv.MessagingDestinationName("orders.new"),
+		semconv.MessagingMessageBodySize(42),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
@@ -0,0 +1,442 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// The max number of messages delivered and not yet acknowledged.
+#define MAX_IN_FLIGHT 1024
+#define MAX_SUBJECT_SIZE 256
+// The reply subject of a message holds its JetStream metadata.
+#define MAX_REPLY_SIZE 256
+// The prefix of the reply subject of the messages of a JetStream consumer.
+#define ACK_PREFIX "$JS.ACK."
+#define ACK_PREFIX_LENGTH (sizeof(ACK_PREFIX) - 1)
+// The number of messages tracked for the expiry of their acknowledgment, and
+// the number of them checked each time a message is delivered.
+#define EXPIRY_SLOTS 64
+#define EXPIRY_SWEEP 8
+// The max number of buckets of a headers map searched for the traceparent
+// header, we must have a limit for the verifier. Overflow buckets are not
+// searched.
+#define MAX_BUCKETS 8
+// Top hash values of the empty, or evacuated, slots of a bucket.
+// https://github.com/golang/go/blob/go1.19/src/runtime/map.go#L91-L96
+#define MIN_TOP_HASH 5
+// The number of slots of a swiss map group.
+// https://github.com/golang/go/blob/go1.24.0/src/internal/runtime/maps/group.go
+#define SWISS_GROUP_SLOTS 8
+// The control byte of a slot holding an entry has its high bit clear.
+#define SWISS_CTRL_EMPTY_MASK 0x80
+// The offsets of the directory pointer, and length, of a swiss map.
+// https://github.com/golang/go/blob/go1.24.0/src/internal/runtime/maps/map.go#L194-L246
+#define SWISS_MAP_DIR_PTR_POS 16
+#define SWISS_MAP_DIR_LEN_POS 24
+
+// The outcomes of the processing of a message, they need to be kept in sync
+// with the outcomes of the probe.
+#define OUTCOME_ACK 1
+#define OUTCOME_NAK 2
+#define OUTCOME_TERM 3
+#define OUTCOME_EXPIRED 4
+
+struct jetstream_message_t {
+    BASE_SPAN_PROPERTIES
+    char subject[MAX_SUBJECT_SIZE];
+    char reply[MAX_REPLY_SIZE];
+    u64 body_size;
+    u8 outcome;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// The headers of a message are a nats.Header, a map[string][]string.
+MAP_BUCKET_DEFINITION(go_string_t, go_slice_t)
+
+struct swiss_slot_t {
+    go_string_t key;
+    go_slice_t elem;
+};
+
+struct swiss_group_t {
+    u64 ctrl;
+    struct swiss_slot_t slots[SWISS_GROUP_SLOTS];
+};
+
+// The cursors of the expiry slots.
+struct expiry_cursor_t {
+    u32 next;
+    u32 sweep;
+};
+
+// Messages delivered and not yet acknowledged, keyed by their *nats.Msg.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct jetstream_message_t);
+    __uint(max_entries, MAX_IN_FLIGHT);
+} jetstream_events SEC(".maps");
+
+// Messages being acknowledged, keyed by the goroutine acknowledging them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} jetstream_acks SEC(".maps");
+
+// The *nats.Msg of the messages checked for the expiry of their
+// acknowledgment, used as a ring.
+struct {
+    __uint(type, BPF_MAP_TYPE_ARRAY);
+    __type(key, u32);
+    __type(value, u64);
+    __uint(max_entries, EXPIRY_SLOTS);
+} jetstream_expiry_slots SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_ARRAY);
+    __type(key, u32);
+    __type(value, struct expiry_cursor_t);
+    __uint(max_entries, 1);
+} jetstream_expiry_cursor SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct jetstream_message_t));
+    __uint(max_entries, 1);
+} jetstream_storage_map SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(MAP_BUCKET_TYPE(go_string_t, go_slice_t)));
+    __uint(max_entries, 1);
+} golang_mapbucket_storage_map SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct swiss_group_t));
+    __uint(max_entries, 1);
+} swiss_group_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 ack_wait_ns;
+volatile const u64 msg_subject_pos;
+volatile const u64 msg_reply_pos;
+volatile const u64 msg_header_pos;
+volatile const u64 msg_data_pos;
+volatile const u64 jetstream_msg_msg_pos;
+volatile const u64 buckets_ptr_pos;
+// A flag indicating whether the Go version is using swiss maps
+volatile const bool swiss_maps_used;
+
+// Parses the span context from the first value of the traceparent header.
+static __always_inline long parse_traceparent_value(go_slice_t *values, struct span_context *parent_span_context) {
+    if (values->len < 1) {
+        return -1;
+    }
+    go_string_t value = {0};
+    if (bpf_probe_read_user(&value, sizeof(value), values->array)) {
+        return -1;
+    }
+    if (value.len != W3C_VAL_LENGTH) {
+        return -1;
+    }
+    char val[W3C_VAL_LENGTH];
+    if (bpf_probe_read_user(val, sizeof(val), value.str)) {
+        return -1;
+    }
+    w3c_string_to_span_context(val, parent_span_context);
+    return 0;
+}
+
+// Returns if the key is the traceparent header. The keys of the headers are
+// canonicalized by the versions decoding them with net/textproto.
+static __always_inline bool is_traceparent_key(go_string_t *key) {
+    if (key->len != W3C_KEY_LENGTH) {
+        return false;
+    }
+    char name[W3C_KEY_LENGTH];
+    if (bpf_probe_read_user(name, sizeof(name), key->str)) {
+        return false;
+    }
+    return bpf_memicmp(name, "traceparent", W3C_KEY_LENGTH) == 0;
+}
+
+/* Searches the buckets of a map, used by Go versions prior to 1.24, for the
+traceparent header. Only the first MAX_BUCKETS buckets are searched, the
+header is not found in larger maps, or in overflow buckets. */
+static __always_inline long extract_span_context_from_buckets(void *headers, struct span_context *parent_span_context) {
+    unsigned char log_2_bucket_count;
+    if (bpf_probe_read_user(&log_2_bucket_count, sizeof(log_2_bucket_count), headers + 9)) {
+        return -1;
+    }
+    u64 bucket_count = 1 << log_2_bucket_count;
+    void *buckets;
+    if (bpf_probe_read_user(&buckets, sizeof(buckets), (void *)(headers + buckets_ptr_pos))) {
+        return -1;
+    }
+    u32 map_id = 0;
+    MAP_BUCKET_TYPE(go_string_t, go_slice_t) *bucket = bpf_map_lookup_elem(&golang_mapbucket_storage_map, &map_id);
+    if (!bucket) {
+        return -1;
+    }
+
+    for (u64 j = 0; j < MAX_BUCKETS; j++) {
+        if (j >= bucket_count) {
+            break;
+        }
+        if (bpf_probe_read_user(bucket, sizeof(*bucket), buckets + (j * sizeof(*bucket)))) {
+            continue;
+        }
+        for (u64 i = 0; i < 8; i++) {
+            if (bucket->tophash[i] < MIN_TOP_HASH) {
+                continue;
+            }
+            if (is_traceparent_key(&bucket->keys[i])) {
+                return parse_traceparent_value(&bucket->values[i], parent_span_context);
+            }
+        }
+    }
+    return -1;
+}
+
+/* Searches a swiss map, used since Go 1.24, for the traceparent header. Only
+small maps, holding up to SWISS_GROUP_SLOTS entries in a single group, are
+searched. */
+static __always_inline long extract_span_context_from_swiss_map(void *headers, struct span_context *parent_span_context) {
+    s64 dir_len = 0;
+    if (bpf_probe_read_user(&dir_len, sizeof(dir_len), headers + SWISS_MAP_DIR_LEN_POS) || dir_len != 0) {
+        return -1;
+    }
+    void *group_ptr = NULL;
+    if (bpf_probe_read_user(&group_ptr, sizeof(group_ptr), headers + SWISS_MAP_DIR_PTR_POS) || group_ptr == NULL) {
+        return -1;
+    }
+    u32 map_id = 0;
+    struct swiss_group_t *group = bpf_map_lookup_elem(&swiss_group_storage_map, &map_id);
+    if (!group) {
+        return -1;
+    }
+    if (bpf_probe_read_user(group, sizeof(*group), group_ptr)) {
+        return -1;
+    }
+
+    for (u64 i = 0; i < SWISS_GROUP_SLOTS; i++) {
+        u8 ctrl = group->ctrl >> (8 * i);
+        if (ctrl & SWISS_CTRL_EMPTY_MASK) {
+            continue;
+        }
+        if (is_traceparent_key(&group->slots[i].key)) {
+            return parse_traceparent_value(&group->slots[i].elem, parent_span_context);
+        }
+    }
+    return -1;
+}
+
+// Extracts the span context from the traceparent header of a message, added
+// by the publishers with tracing enabled.
+static __always_inline long extract_span_context_from_headers(void *msg, struct span_context *parent_span_context) {
+    void *headers = NULL;
+    if (bpf_probe_read_user(&headers, sizeof(headers), (void *)(msg + msg_header_pos)) || headers == NULL) {
+        return -1;
+    }
+    u64 count = 0;
+    if (bpf_probe_read_user(&count, sizeof(count), headers) || count == 0) {
+        return -1;
+    }
+    if (swiss_maps_used) {
+        return extract_span_context_from_swiss_map(headers, parent_span_context);
+    }
+    return extract_span_context_from_buckets(headers, parent_span_context);
+}
+
+// Ends the spans of the messages whose acknowledgment expired. Only
+// EXPIRY_SWEEP of the tracked messages are checked on each call.
+static __always_inline void end_expired_spans(struct pt_regs *ctx, u64 now) {
+    u32 zero = 0;
+    struct expiry_cursor_t *cursor = bpf_map_lookup_elem(&jetstream_expiry_cursor, &zero);
+    if (cursor == NULL) {
+        return;
+    }
+    u32 sweep = __sync_fetch_and_add(&cursor->sweep, EXPIRY_SWEEP);
+
+    for (u32 i = 0; i < EXPIRY_SWEEP; i++) {
+        u32 slot = (sweep + i) % EXPIRY_SLOTS;
+        u64 *key = bpf_map_lookup_elem(&jetstream_expiry_slots, &slot);
+        if (key == NULL || *key == 0) {
+            continue;
+        }
+        struct jetstream_message_t *message = bpf_map_lookup_elem(&jetstream_events, key);
+        if (message == NULL) {
+            // Acknowledged, or evicted.
+            *key = 0;
+            continue;
+        }
+        if (now - message->start_time < ack_wait_ns) {
+            continue;
+        }
+        message->end_time = message->start_time + ack_wait_ns;
+        message->outcome = OUTCOME_EXPIRED;
+        output_span_event(ctx, message, sizeof(*message), &message->sc);
+        bpf_map_delete_elem(&jetstream_events, key);
+        *key = 0;
+    }
+}
+
+// Tracks the message for the expiry of its acknowledgment. The message
+// previously tracked in its slot is no longer checked.
+static __always_inline void track_expiry(void *msg) {
+    u32 zero = 0;
+    struct expiry_cursor_t *cursor = bpf_map_lookup_elem(&jetstream_expiry_cursor, &zero);
+    if (cursor == NULL) {
+        return;
+    }
+    u32 slot = __sync_fetch_and_add(&cursor->next, 1) % EXPIRY_SLOTS;
+    u64 key = (u64)msg;
+    bpf_map_update_elem(&jetstream_expiry_slots, &slot, &key, 0);
+}
+
+// This instrumentation attaches uprobe to the message handler of
+// (*pullConsumer).Consume, the first function literal of the method:
+// func(msg *nats.Msg)
+SEC("uprobe/pullConsumer_Consume_func1")
+int uprobe_pullConsumer_Consume_func1(struct pt_regs *ctx) {
+    /* The handler passes the messages of the consumer to the callback, the
+    span of a message ends once it is acknowledged. The callback does not
+    accept a context.Context, the parent span is only the one of the producer
+    propagated in the message headers. */
+    void *msg = get_argument(ctx, 1);
+    if (msg == NULL) {
+        return 0;
+    }
+    u64 now = get_time_ns();
+    end_expired_spans(ctx, now);
+
+    // Status messages, like heartbeats, are not passed to the callback. Only
+    // the messages of the stream have a reply subject to acknowledge them.
+    struct go_string reply = {0};
+    if (bpf_probe_read_user(&reply, sizeof(reply), (void *)(msg + msg_reply_pos)) || reply.len <= ACK_PREFIX_LENGTH) {
+        return 0;
+    }
+    char prefix[ACK_PREFIX_LENGTH];
+    if (bpf_probe_read_user(prefix, sizeof(prefix), reply.str)) {
+        return 0;
+    }
+    char ack_prefix[ACK_PREFIX_LENGTH] = ACK_PREFIX;
+    if (!bpf_memcmp(prefix, ack_prefix, sizeof(ack_prefix))) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct jetstream_message_t *message = bpf_map_lookup_elem(&jetstream_storage_map, &zero);
+    if (message == NULL) {
+        bpf_printk("uprobe/pullConsumer_Consume_func1: message is NULL");
+        return 0;
+    }
+    __builtin_memset(message, 0, sizeof(struct jetstream_message_t));
+    message->start_time = now;
+
+    get_go_string_from_user_ptr((void *)(msg + msg_subject_pos), message->subject, sizeof(message->subject));
+    get_go_string_from_user_ptr((void *)(msg + msg_reply_pos), message->reply, sizeof(message->reply));
+    bpf_probe_read_user(&message->body_size, sizeof(message->body_size), (void *)(msg + msg_data_pos + offsetof(struct go_slice, len)));
+
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &message->psc,
+        .sc = &message->sc,
+        .get_parent_span_context_fn = extract_span_context_from_headers,
+        .get_parent_span_context_arg = msg,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&jetstream_events, &msg, message, 0);
+    track_expiry(msg);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (m *jetStreamMsg) ackReply(ctx context.Context, ackType ackType, sync bool, opts ackOpts) error
+SEC("uprobe/jetStreamMsg_ackReply")
+int uprobe_jetStreamMsg_ackReply(struct pt_regs *ctx) {
+    void *jetstream_msg = get_argument(ctx, 1);
+    void *msg = NULL;
+    if (bpf_probe_read_user(&msg, sizeof(msg), (void *)(jetstream_msg + jetstream_msg_msg_pos)) || msg == NULL) {
+        return 0;
+    }
+    struct jetstream_message_t *message = bpf_map_lookup_elem(&jetstream_events, &msg);
+    if (message == NULL) {
+        return 0;
+    }
+
+    // The ack type is a byte slice: "+ACK", "-NAK", "+WPI", or "+TERM".
+    void *ack_type_ptr = get_argument(ctx, 4);
+    u64 ack_type_len = (u64)get_argument(ctx, 5);
+    if (ack_type_len < 2) {
+        return 0;
+    }
+    char ack_type[2];
+    if (bpf_probe_read_user(ack_type, sizeof(ack_type), ack_type_ptr)) {
+        return 0;
+    }
+    switch (ack_type[1]) {
+    case 'A':
+        message->outcome = OUTCOME_ACK;
+        break;
+    case 'N':
+        message->outcome = OUTCOME_NAK;
+        break;
+    case 'T':
+        message->outcome = OUTCOME_TERM;
+        break;
+    default:
+        // The message is still being processed, its acknowledgment is
+        // delayed.
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&jetstream_acks, &key, &msg, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (m *jetStreamMsg) ackReply(ctx context.Context, ackType ackType, sync bool, opts ackOpts) error
+SEC("uprobe/jetStreamMsg_ackReply")
+int uprobe_jetStreamMsg_ackReply_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    void **msg = bpf_map_lookup_elem(&jetstream_acks, &key);
+    if (msg == NULL) {
+        return 0;
+    }
+    void *msg_ptr = *msg;
+    bpf_map_delete_elem(&jetstream_acks, &key);
+
+    struct jetstream_message_t *message = bpf_map_lookup_elem(&jetstream_events, &msg_ptr);
+    if (message == NULL) {
+        return 0;
+    }
+    message->end_time = end_time;
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 1) != NULL) {
+        message->has_error = 1;
+    }
+
+    output_span_event(ctx, message, sizeof(*message), &message->sc);
+    bpf_map_delete_elem(&jetstream_events, &msg_ptr);
+    return 0;
+}
@@ -0,0 +1,321 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jetstream provides an instrumentation probe for NATS JetStream
+// consumers using the [github.com/nats-io/nats.go/jetstream] package.
+package jetstream
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// mod is the module of the package being instrumented.
+	mod = "github.com/nats-io/nats.go"
+	// pkg is the package being instrumented.
+	pkg = mod + "/jetstream"
+
+	// AckWaitEnvVar is the environment variable used to configure the time a
+	// message is waited to be acknowledged for before its span is ended with
+	// an error status. It should match the AckWait of the consumers.
+	AckWaitEnvVar = "OTEL_GO_AUTO_NATS_ACK_WAIT"
+
+	// defaultAckWait is the time waited for acknowledgments if AckWaitEnvVar
+	// is not set, the default AckWait of the consumers.
+	defaultAckWait = 30 * time.Second
+)
+
+const (
+	// streamNameKey is the attribute key of the name of the stream of a
+	// message.
+	streamNameKey = attribute.Key("messaging.nats.stream.name")
+	// streamSequenceKey is the attribute key of the sequence number of a
+	// message in its stream.
+	streamSequenceKey = attribute.Key("messaging.nats.stream.sequence")
+	// consumerSequenceKey is the attribute key of the sequence number of a
+	// message in the deliveries of its consumer.
+	consumerSequenceKey = attribute.Key("messaging.nats.consumer.sequence")
+	// deliveryCountKey is the attribute key of the number of times a message
+	// was delivered.
+	deliveryCountKey = attribute.Key("messaging.nats.delivery.count")
+	// ackOutcomeKey is the attribute key of the acknowledgment of a message.
+	ackOutcomeKey = attribute.Key("messaging.nats.ack.outcome")
+)
+
+var (
+	// symPkg is pkg as it is named in the symbols of a binary.
+	symPkg = process.LinkerPkgPath(pkg)
+	// minVersion is the first version of the module with the jetstream
+	// package.
+	minVersion = semver.New(1, 26, 0, "", "")
+	// goMapsVersion is the first Go version using swiss maps.
+	goMapsVersion = semver.New(1, 24, 0, "", "")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	ackWait, err := parseAckWait(os.Getenv(AckWaitEnvVar))
+	if err != nil {
+		logger.Error("invalid ack wait, using default", "error", err, "default", ackWait)
+	}
+
+	supported := probe.PackageConstraints{
+		Package: mod,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, p, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(mod, p, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	// The message handler of Consume is its first function literal in all
+	// the versions supported.
+	consume := symPkg + ".(*pullConsumer).Consume.func1"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{
+					Key: "ack_wait_ns",
+					Val: uint64(ackWait.Nanoseconds()), // nolint: gosec  // Positive.
+				},
+				fieldConst("msg_subject_pos", mod, "Msg", "Subject"),
+				fieldConst("msg_reply_pos", mod, "Msg", "Reply"),
+				fieldConst("msg_header_pos", mod, "Msg", "Header"),
+				fieldConst("msg_data_pos", mod, "Msg", "Data"),
+				fieldConst("jetstream_msg_msg_pos", pkg, "jetStreamMsg", "msg"),
+				probe.StructFieldConstMaxVersion{
+					StructField: probe.StructFieldConst{
+						Key: "buckets_ptr_pos",
+						ID:  structfield.NewID("std", "runtime", "hmap", "buckets"),
+					},
+					MaxVersion: goMapsVersion,
+				},
+				swissMapsUsedConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                consume,
+					EntryProbe:         "uprobe_pullConsumer_Consume_func1",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					// Not linked if messages are never acknowledged.
+					Sym:                symPkg + ".(*jetStreamMsg).ackReply",
+					EntryProbe:         "uprobe_jetStreamMsg_ackReply",
+					ReturnProbe:        "uprobe_jetStreamMsg_ackReply_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{consume},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// parseAckWait returns the ack wait parsed from val, or defaultAckWait if val
+// is empty or invalid.
+func parseAckWait(val string) (time.Duration, error) {
+	if val == "" {
+		return defaultAckWait, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return defaultAckWait, err
+	}
+	if d <= 0 {
+		return defaultAckWait, errors.New("non-positive duration")
+	}
+	return d, nil
+}
+
+type swissMapsUsedConst struct{}
+
+func (c swissMapsUsedConst) InjectOption(info *process.Info) (inject.Option, error) {
+	isUsingGoSwissMaps := info.GoVersion.GreaterThanEqual(goMapsVersion)
+	return inject.WithKeyValue("swiss_maps_used", isUsingGoSwissMaps), nil
+}
+
+// outcome is the acknowledgment of a message. It needs to be kept in sync
+// with the outcomes of the eBPF program.
+type outcome uint8
+
+const (
+	outcomeUnknown outcome = iota
+	outcomeAck
+	outcomeNak
+	outcomeTerm
+	outcomeExpired
+)
+
+func (o outcome) String() string {
+	switch o {
+	case outcomeAck:
+		return "ack"
+	case outcomeNak:
+		return "nak"
+	case outcomeTerm:
+		return "term"
+	case outcomeExpired:
+		return "expired"
+	default:
+		return ""
+	}
+}
+
+// event represents a message delivered to the callback of a consumer, and
+// acknowledged.
+type event struct {
+	context.BaseSpanProperties
+	Subject [256]byte
+	// Reply is the subject the message is acknowledged to, it holds the
+	// metadata of the message.
+	Reply [256]byte
+	// BodySize is the size of the payload of the message.
+	BodySize uint64
+	Outcome  outcome
+	HasError uint8
+	_        [6]byte // padding
+}
+
+// metadata is the JetStream metadata of a message.
+type metadata struct {
+	stream           string
+	consumer         string
+	delivered        uint64
+	streamSequence   uint64
+	consumerSequence uint64
+}
+
+// parseMetadata parses the metadata of a message from its reply subject:
+//
+//	$JS.ACK.<stream>.<consumer>.<delivered>.<sseq>.<cseq>.<tm>.<pending>
+//
+// Or, for newer servers:
+//
+//	$JS.ACK.<domain>.<account hash>.<stream>.<consumer>.<delivered>.<sseq>.<cseq>.<tm>.<pending>.<token>
+func parseMetadata(reply string) (metadata, bool) {
+	tokens := strings.Split(reply, ".")
+	if len(tokens) < 9 || tokens[0] != "$JS" || tokens[1] != "ACK" {
+		return metadata{}, false
+	}
+	switch {
+	case len(tokens) == 9:
+		// No domain, and account hash, tokens.
+	case len(tokens) >= 11:
+		tokens = tokens[2:]
+	default:
+		return metadata{}, false
+	}
+
+	num := func(s string) uint64 {
+		n, _ := strconv.ParseUint(s, 10, 64)
+		return n
+	}
+	return metadata{
+		stream:           tokens[2],
+		consumer:         tokens[3],
+		delivered:        num(tokens[4]),
+		streamSequence:   num(tokens[5]),
+		consumerSequence: num(tokens[6]),
+	}, true
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	subject := unix.ByteSliceToString(e.Subject[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("nats"),
+		semconv.MessagingOperationTypeProcess,
+		semconv.MessagingOperationName("process"),
+		semconv.MessagingDestinationName(subject),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max payload.
+	}
+
+	if md, ok := parseMetadata(unix.ByteSliceToString(e.Reply[:])); ok {
+		attrs = append(
+			attrs,
+			semconv.MessagingConsumerGroupName(md.consumer),
+			streamNameKey.String(md.stream),
+			streamSequenceKey.Int64(int64(md.streamSequence)),     // nolint: gosec  // Sequence.
+			consumerSequenceKey.Int64(int64(md.consumerSequence)), // nolint: gosec  // Sequence.
+			deliveryCountKey.Int64(int64(md.delivered)),           // nolint: gosec  // Count.
+		)
+	}
+
+	if o := e.Outcome.String(); o != "" {
+		attrs = append(attrs, ackOutcomeKey.String(o))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(subject + " process")
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	// A message not acknowledged in time is redelivered.
+	if e.Outcome == outcomeExpired || e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
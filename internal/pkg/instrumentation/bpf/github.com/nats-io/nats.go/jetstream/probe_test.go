@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package jetstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(reply string, o outcome, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           42,
+			Outcome:            o,
+		}
+		copy(e.Subject[:], "orders.new")
+		copy(e.Reply[:], reply)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newSpans := func(code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		return f.Spans("orders.new process", code, append([]attribute.KeyValue{
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingOperationName("process"),
+			semconv.MessagingDestinationName("orders.new"),
+			semconv.MessagingMessageBodySize(42),
+		}, attrs...)...)
+	}
+
+	metadata := []attribute.KeyValue{
+		semconv.MessagingConsumerGroupName("worker"),
+		streamNameKey.String("ORDERS"),
+		streamSequenceKey.Int64(7),
+		consumerSequenceKey.Int64(3),
+		deliveryCountKey.Int64(2),
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "ack",
+			event: newEvent("$JS.ACK.ORDERS.worker.2.7.3.1700000000000000000.0", outcomeAck, false),
+			want:  newSpans(ptrace.StatusCodeUnset, append(metadata, ackOutcomeKey.String("ack"))...),
+		},
+		{
+			name:  "nak with domain",
+			event: newEvent("$JS.ACK.hub.ACC.ORDERS.worker.2.7.3.1700000000000000000.0.abc", outcomeNak, false),
+			want:  newSpans(ptrace.StatusCodeUnset, append(metadata, ackOutcomeKey.String("nak"))...),
+		},
+		{
+			name:  "term",
+			event: newEvent("$JS.ACK.ORDERS.worker.2.7.3.1700000000000000000.0", outcomeTerm, false),
+			want:  newSpans(ptrace.StatusCodeUnset, append(metadata, ackOutcomeKey.String("term"))...),
+		},
+		{
+			name:  "expired",
+			event: newEvent("$JS.ACK.ORDERS.worker.2.7.3.1700000000000000000.0", outcomeExpired, false),
+			want:  newSpans(ptrace.StatusCodeError, append(metadata, ackOutcomeKey.String("expired"))...),
+		},
+		{
+			name:  "ack error",
+			event: newEvent("$JS.ACK.ORDERS.worker.2.7.3.1700000000000000000.0", outcomeAck, true),
+			want:  newSpans(ptrace.StatusCodeError, append(metadata, ackOutcomeKey.String("ack"))...),
+		},
+		{
+			name:  "invalid reply",
+			event: newEvent("_INBOX.abc", outcomeUnknown, false),
+			want:  newSpans(ptrace.StatusCodeUnset),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestParseAckWait(t *testing.T) {
+	d, err := parseAckWait("")
+	require.NoError(t, err)
+	assert.Equal(t, defaultAckWait, d)
+
+	d, err = parseAckWait("1m")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, d)
+
+	d, err = parseAckWait("invalid")
+	assert.Error(t, err)
+	assert.Equal(t, defaultAckWait, d)
+
+	d, err = parseAckWait("0s")
+	assert.Error(t, err)
+	assert.Equal(t, defaultAckWait, d)
+}
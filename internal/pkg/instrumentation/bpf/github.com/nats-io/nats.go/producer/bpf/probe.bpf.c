@@ -0,0 +1,345 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_SUBJECT_SIZE 256
+// The max size of the subject, and reply subject, of the control line of the
+// messages a traceparent header is injected in. Keep a power of 2 to help
+// with masks.
+#define MAX_SUBJECTS_SIZE 256
+#define CONTROL_LINE_MASK (MAX_BUFFER_SIZE - 1)
+
+// The operations instrumented, they need to be kept in sync with the
+// operation type of the probe.
+#define OPERATION_PUBLISH 1
+#define OPERATION_REQUEST 2
+
+// https://github.com/nats-io/nats.go/blob/v1.11.0/nats.go#L3068
+#define HDR_LINE "NATS/1.0\r\n"
+#define HDR_LINE_LEN 10
+// The length of the "traceparent: <value>\r\n" header line.
+#define TRACEPARENT_LINE_LEN (W3C_KEY_LENGTH + 2 + W3C_VAL_LENGTH + 2)
+
+struct nats_request_t {
+    BASE_SPAN_PROPERTIES
+    char subject[MAX_SUBJECT_SIZE];
+    u64 body_size;
+    u8 operation;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// The state of the operations being performed, only the event is sent to user
+// space.
+struct nats_request_state_t {
+    struct nats_request_t event;
+    // The *Conn the message is published with.
+    void *conn;
+    // Set once the message is written to the buffers of the connection.
+    u8 written;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct nats_request_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} nats_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct nats_request_state_t));
+    __uint(max_entries, 1);
+} nats_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 conn_info_pos;
+volatile const u64 server_info_headers_pos;
+
+static __always_inline void start_operation(struct pt_regs *ctx, u8 operation) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&nats_events, &key) != NULL) {
+        // The message published by a request is part of the span of the
+        // request.
+        return;
+    }
+
+    u32 zero = 0;
+    struct nats_request_state_t *state = bpf_map_lookup_elem(&nats_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("nats operation: state is NULL");
+        return;
+    }
+    __builtin_memset(state, 0, sizeof(struct nats_request_state_t));
+    struct nats_request_t *nats_request = &state->event;
+    nats_request->start_time = get_time_ns();
+    nats_request->operation = operation;
+    state->conn = get_argument(ctx, 1);
+
+    void *subj_ptr = get_argument(ctx, 2);
+    u64 subj_len = (u64)get_argument(ctx, 3);
+    u64 subj_size = MAX_SUBJECT_SIZE < subj_len ? MAX_SUBJECT_SIZE : subj_len;
+    bpf_probe_read_user(nats_request->subject, subj_size, subj_ptr);
+
+    // The client does not accept a context.Context, the span is always a
+    // root span.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &nats_request->psc,
+        .sc = &nats_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&nats_events, &key, state, 0);
+}
+
+static __always_inline void end_operation(struct pt_regs *ctx, u8 operation, u64 error_pos) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct nats_request_state_t *state = bpf_map_lookup_elem(&nats_events, &key);
+    if (state == NULL || state->event.operation != operation) {
+        return;
+    }
+    struct nats_request_t *nats_request = &state->event;
+    nats_request->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, error_pos) != NULL) {
+        nats_request->has_error = 1;
+    }
+
+    output_span_event(ctx, nats_request, sizeof(*nats_request), &nats_request->sc);
+    bpf_map_delete_elem(&nats_events, &key);
+}
+
+#ifndef NO_HEADER_PROPAGATION
+// The control line, and headers, of a message the traceparent header is
+// injected in. It is written to the target with write_target_data.
+struct control_line_t {
+    char buf[MAX_BUFFER_SIZE];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct control_line_t));
+    __uint(max_entries, 1);
+} control_line_storage_map SEC(".maps");
+
+// Returns the number of decimal digits of n.
+static __always_inline u32 count_digits(u64 n) {
+    u32 count = 1;
+    for (int i = 0; i < 20; i++) {
+        n /= 10;
+        if (n == 0) {
+            break;
+        }
+        count++;
+    }
+    return count;
+}
+
+// Writes the decimal representation of n to buf at off, and returns the offset
+// following it.
+static __always_inline u32 write_digits(char *buf, u32 off, u64 n) {
+    u32 count = count_digits(n);
+    for (int i = 0; i < 20; i++) {
+        if (i >= count) {
+            break;
+        }
+        buf[(off + count - 1 - i) & CONTROL_LINE_MASK] = '0' + (n % 10);
+        n /= 10;
+    }
+    return off + count;
+}
+
+// Writes the size bytes of src to buf at off, and returns the offset following
+// them.
+static __always_inline u32 write_bytes(char *buf, u32 off, char *src, u32 size) {
+    for (int i = 0; i < size; i++) {
+        buf[(off + i) & CONTROL_LINE_MASK] = src[i];
+    }
+    return off + size;
+}
+
+/* Messages are written with the buffers of the control line, the headers, the
+payload and a CRLF. The control line is "PUB <subject> [reply] <size>\r\n", or
+"HPUB <subject> [reply] <headers size> <total size>\r\n" for messages with
+headers. The control line buffer is replaced with the one of a message with
+headers, followed by the version line and the traceparent header. The version
+line of the existing headers is skipped. */
+static __always_inline void inject_traceparent(struct nats_request_state_t *state, void *bufs_ptr, struct go_slice *bufs) {
+    // Headers are only accepted by servers supporting them.
+    bool headers = false;
+    bpf_probe_read_user(&headers, sizeof(headers), (void *)(state->conn + conn_info_pos + server_info_headers_pos));
+    if (!headers) {
+        return;
+    }
+
+    struct go_slice *mh = &bufs[0];
+    struct go_slice *hdr = &bufs[1];
+    s64 data_len = bufs[2].len;
+    if (mh->len < 1 || hdr->len < 0 || data_len < 0) {
+        return;
+    }
+
+    char op = 0;
+    bpf_probe_read_user(&op, sizeof(op), mh->array);
+    s64 prefix_len = 4;
+    s64 suffix_len = count_digits(hdr->len + data_len) + 2;
+    if (op == 'H') {
+        prefix_len = 5;
+        suffix_len += count_digits(hdr->len) + 1;
+    }
+    s64 subjects_len = mh->len - prefix_len - suffix_len;
+    if (subjects_len < 1 || subjects_len >= MAX_SUBJECTS_SIZE) {
+        return;
+    }
+
+    char hdr_line[HDR_LINE_LEN] = HDR_LINE;
+    // The headers of the message are terminated by an empty line.
+    s64 hdr_len = HDR_LINE_LEN + 2;
+    if (hdr->len > 0) {
+        // Headers starting with a status line are not modified.
+        char version[HDR_LINE_LEN];
+        if (hdr->len < HDR_LINE_LEN || bpf_probe_read_user(version, sizeof(version), hdr->array) != 0) {
+            return;
+        }
+        if (!bpf_memcmp(version, hdr_line, HDR_LINE_LEN)) {
+            return;
+        }
+        hdr_len = hdr->len;
+    }
+    hdr_len += TRACEPARENT_LINE_LEN;
+
+    u32 zero = 0;
+    struct control_line_t *control_line = bpf_map_lookup_elem(&control_line_storage_map, &zero);
+    if (control_line == NULL) {
+        return;
+    }
+    char *buf = control_line->buf;
+
+    char hpub[5] = "HPUB ";
+    u32 off = write_bytes(buf, 0, hpub, sizeof(hpub));
+    bpf_probe_read_user(&buf[off], subjects_len & (MAX_SUBJECTS_SIZE - 1), mh->array + prefix_len);
+    off += subjects_len;
+    off = write_digits(buf, off, hdr_len);
+    buf[off & CONTROL_LINE_MASK] = ' ';
+    off++;
+    off = write_digits(buf, off, hdr_len + data_len);
+    char crlf[2] = "\r\n";
+    off = write_bytes(buf, off, crlf, sizeof(crlf));
+    off = write_bytes(buf, off, hdr_line, sizeof(hdr_line));
+    char key[W3C_KEY_LENGTH + 2] = "traceparent: ";
+    off = write_bytes(buf, off, key, sizeof(key));
+    char val[W3C_VAL_LENGTH];
+    span_context_to_w3c_string(&state->event.sc, val);
+    off = write_bytes(buf, off, val, sizeof(val));
+    off = write_bytes(buf, off, crlf, sizeof(crlf));
+    if (hdr->len == 0) {
+        off = write_bytes(buf, off, crlf, sizeof(crlf));
+    }
+
+    void *ptr = write_target_data(buf, off);
+    if (ptr == NULL) {
+        bpf_printk("inject_traceparent: failed to write control line");
+        return;
+    }
+
+    struct go_slice new_bufs[2] = {0};
+    new_bufs[0].array = ptr;
+    new_bufs[0].len = off;
+    new_bufs[0].cap = off;
+    new_bufs[1] = *hdr;
+    if (hdr->len > 0) {
+        new_bufs[1].array += HDR_LINE_LEN;
+        new_bufs[1].len -= HDR_LINE_LEN;
+        new_bufs[1].cap -= HDR_LINE_LEN;
+    }
+    long res = bpf_probe_write_user(bufs_ptr, new_bufs, sizeof(new_bufs));
+    if (res != 0) {
+        bpf_printk("inject_traceparent: failed to write buffers");
+    }
+}
+#endif
+
+// This instrumentation attaches uprobe to the following function:
+// func (nc *Conn) publish(subj, reply string, hdr, data []byte) error
+// or, since v1.48.0:
+// func (nc *Conn) publish(subj, reply string, validateReply bool, hdr, data []byte) error
+SEC("uprobe/Conn_publish")
+int uprobe_Conn_publish(struct pt_regs *ctx) {
+    start_operation(ctx, OPERATION_PUBLISH);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (nc *Conn) publish(subj, reply string, hdr, data []byte) error
+// or, since v1.48.0:
+// func (nc *Conn) publish(subj, reply string, validateReply bool, hdr, data []byte) error
+SEC("uprobe/Conn_publish")
+int uprobe_Conn_publish_Returns(struct pt_regs *ctx) {
+    end_operation(ctx, OPERATION_PUBLISH, 1);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (nc *Conn) request(subj string, hdr, data []byte, timeout time.Duration) (*Msg, error)
+SEC("uprobe/Conn_request")
+int uprobe_Conn_request(struct pt_regs *ctx) {
+    start_operation(ctx, OPERATION_REQUEST);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (nc *Conn) request(subj string, hdr, data []byte, timeout time.Duration) (*Msg, error)
+SEC("uprobe/Conn_request")
+int uprobe_Conn_request_Returns(struct pt_regs *ctx) {
+    end_operation(ctx, OPERATION_REQUEST, 2);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *natsWriter) appendBufs(bufs ...[]byte) error
+SEC("uprobe/natsWriter_appendBufs")
+int uprobe_natsWriter_appendBufs(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct nats_request_state_t *state = bpf_map_lookup_elem(&nats_events, &key);
+    if (state == NULL || state->written) {
+        return 0;
+    }
+
+    // The buffers of a message: the control line, the headers, the payload
+    // and a CRLF. Other protocol messages are written with a single buffer.
+    void *bufs_ptr = get_argument(ctx, 2);
+    u64 bufs_len = (u64)get_argument(ctx, 3);
+    if (bufs_len != 4) {
+        return 0;
+    }
+    struct go_slice bufs[4] = {0};
+    if (bpf_probe_read_user(bufs, sizeof(bufs), bufs_ptr) != 0) {
+        return 0;
+    }
+    state->written = 1;
+    state->event.body_size = bufs[2].len;
+
+#ifndef NO_HEADER_PROPAGATION
+    inject_traceparent(state, bufs_ptr, bufs);
+#endif
+    return 0;
+}
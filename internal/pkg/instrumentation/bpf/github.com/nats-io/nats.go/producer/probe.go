@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides an instrumentation probe for NATS publishers using
+// the [github.com/nats-io/nats.go] package.
+package producer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf_no_tp ./bpf/probe.bpf.c -- -DNO_HEADER_PROPAGATION
+
+// pkg is the package being instrumented.
+const pkg = "github.com/nats-io/nats.go"
+
+var (
+	// symPkg is pkg as it is named in the symbols of a binary.
+	symPkg = process.LinkerPkgPath(pkg)
+	// minVersion is the first version supported by the probe.
+	minVersion = semver.New(1, 11, 0, "", "")
+	// exportedServerInfoVersion is the version the serverInfo type was
+	// exported as ServerInfo in.
+	exportedServerInfoVersion = semver.New(1, 49, 0, "", "")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "conn_info_pos",
+						ID:  structfield.NewID(pkg, pkg, "Conn", "info"),
+					},
+					MinVersion: minVersion,
+				},
+				probe.StructFieldConstMaxVersion{
+					StructField: probe.StructFieldConst{
+						Key: "server_info_headers_pos",
+						ID:  structfield.NewID(pkg, pkg, "serverInfo", "Headers"),
+					},
+					MaxVersion: exportedServerInfoVersion,
+					MinVersion: minVersion,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "server_info_headers_pos",
+						ID:  structfield.NewID(pkg, pkg, "ServerInfo", "Headers"),
+					},
+					MinVersion: exportedServerInfoVersion,
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                symPkg + ".(*Conn).publish",
+					EntryProbe:         "uprobe_Conn_publish",
+					ReturnProbe:        "uprobe_Conn_publish_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                symPkg + ".(*Conn).request",
+					EntryProbe:         "uprobe_Conn_request",
+					ReturnProbe:        "uprobe_Conn_request_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                symPkg + ".(*natsWriter).appendBufs",
+					EntryProbe:         "uprobe_natsWriter_appendBufs",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: verifyAndLoadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
+	if !kernel.SupportsContextPropagation() {
+		fmt.Fprintf(
+			os.Stderr,
+			"the Linux Kernel doesn't support context propagation, please check if the kernel is in lockdown mode (/sys/kernel/security/lockdown)",
+		)
+		return loadBpf_no_tp()
+	}
+
+	return loadBpf()
+}
+
+// operation is the type of operation performed by the client. It needs to be
+// kept in sync with the operations of the eBPF program.
+type operation uint8
+
+const (
+	operationPublish operation = iota + 1
+	operationRequest
+)
+
+// name returns the name of the operation, it is empty if unknown.
+func (o operation) name() string {
+	switch o {
+	case operationPublish:
+		return "publish"
+	case operationRequest:
+		return "request"
+	default:
+		return ""
+	}
+}
+
+// event represents a message published by the client.
+type event struct {
+	context.BaseSpanProperties
+	Subject [256]byte
+	// BodySize is the size of the payload of the message.
+	BodySize  uint64
+	Operation operation
+	HasError  uint8
+	_         [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	subject := unix.ByteSliceToString(e.Subject[:])
+	op := e.Operation.name()
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("nats"),
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingDestinationName(subject),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max payload.
+	}
+	if op != "" {
+		attrs = append(attrs, semconv.MessagingOperationName(op))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(subject, op))
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of the span of the operation op on subject, e.g.
+// "orders publish".
+func spanName(subject, op string) string {
+	if op == "" {
+		op = "publish"
+	}
+	return subject + " " + op
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindProducer)
+
+	newEvent := func(subject string, bodySize uint64, op operation, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           bodySize,
+			Operation:          op,
+		}
+		copy(e.Subject[:], subject)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "publish",
+			event: newEvent("orders.new", 42, operationPublish, false),
+			want: f.Spans(
+				"orders.new publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemKey.String("nats"),
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("orders.new"),
+				semconv.MessagingMessageBodySize(42),
+				semconv.MessagingOperationName("publish"),
+			),
+		},
+		{
+			name:  "request",
+			event: newEvent("orders.get", 8, operationRequest, false),
+			want: f.Spans(
+				"orders.get request",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemKey.String("nats"),
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("orders.get"),
+				semconv.MessagingMessageBodySize(8),
+				semconv.MessagingOperationName("request"),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("orders.get", 8, operationRequest, true),
+			want: f.Spans(
+				"orders.get request",
+				ptrace.StatusCodeError,
+				semconv.MessagingSystemKey.String("nats"),
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("orders.get"),
+				semconv.MessagingMessageBodySize(8),
+				semconv.MessagingOperationName("request"),
+			),
+		},
+		{
+			name:  "unknown operation",
+			event: newEvent("orders.new", 0, 0, false),
+			want: f.Spans(
+				"orders.new publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemKey.String("nats"),
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingDestinationName("orders.new"),
+				semconv.MessagingMessageBodySize(0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// Exchange names and routing keys are short strings of at most 255 bytes.
+#define MAX_NAME_SIZE 256
+// The max number of buckets of a headers table searched for the traceparent
+// field, we must have a limit for the verifier. Overflow buckets are not
+// searched.
+#define MAX_BUCKETS 8
+// Top hash values of the empty, or evacuated, slots of a bucket.
+// https://github.com/golang/go/blob/go1.19/src/runtime/map.go#L91-L96
+#define MIN_TOP_HASH 5
+// The number of slots of a swiss map group.
+// https://github.com/golang/go/blob/go1.24.0/src/internal/runtime/maps/group.go
+#define SWISS_GROUP_SLOTS 8
+// The control byte of a slot holding an entry has its high bit clear.
+#define SWISS_CTRL_EMPTY_MASK 0x80
+// The offsets of the directory pointer, and length, of a swiss map.
+// https://github.com/golang/go/blob/go1.24.0/src/internal/runtime/maps/map.go#L194-L246
+#define SWISS_MAP_DIR_PTR_POS 16
+#define SWISS_MAP_DIR_LEN_POS 24
+
+struct amqp_delivery_t {
+    BASE_SPAN_PROPERTIES
+    char exchange[MAX_NAME_SIZE];
+    char routing_key[MAX_NAME_SIZE];
+    u64 delivery_tag;
+    u64 body_size;
+};
+
+// A Table is a map[string]interface{}.
+MAP_BUCKET_DEFINITION(go_string_t, go_iface_t)
+
+struct swiss_slot_t {
+    go_string_t key;
+    go_iface_t elem;
+};
+
+struct swiss_group_t {
+    u64 ctrl;
+    struct swiss_slot_t slots[SWISS_GROUP_SLOTS];
+};
+
+// Deliveries being dispatched, keyed by the goroutine dispatching them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct amqp_delivery_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} amqp_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct amqp_delivery_t));
+    __uint(max_entries, 1);
+} amqp_storage_map SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(MAP_BUCKET_TYPE(go_string_t, go_iface_t)));
+    __uint(max_entries, 1);
+} golang_mapbucket_storage_map SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct swiss_group_t));
+    __uint(max_entries, 1);
+} swiss_group_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 delivery_headers_pos;
+volatile const u64 delivery_delivery_tag_pos;
+volatile const u64 delivery_exchange_pos;
+volatile const u64 delivery_routing_key_pos;
+volatile const u64 delivery_body_pos;
+volatile const u64 buckets_ptr_pos;
+// A flag indicating whether the Go version is using swiss maps
+volatile const bool swiss_maps_used;
+
+// Parses the span context from the value of the traceparent field of a table.
+// The value is an interface holding a string, or a byte slice which starts
+// with the pointer to, and the length of, its data as a string does.
+static __always_inline long parse_traceparent_value(go_iface_t *value, struct span_context *parent_span_context) {
+    struct go_string str = {0};
+    if (value->data == NULL || bpf_probe_read_user(&str, sizeof(str), value->data)) {
+        return -1;
+    }
+    if (str.len != W3C_VAL_LENGTH) {
+        return -1;
+    }
+    char val[W3C_VAL_LENGTH];
+    if (bpf_probe_read_user(val, sizeof(val), str.str)) {
+        return -1;
+    }
+    w3c_string_to_span_context(val, parent_span_context);
+    return 0;
+}
+
+static __always_inline bool is_traceparent_key(go_string_t *key) {
+    if (key->len != W3C_KEY_LENGTH) {
+        return false;
+    }
+    char name[W3C_KEY_LENGTH];
+    if (bpf_probe_read_user(name, sizeof(name), key->str)) {
+        return false;
+    }
+    char traceparent[W3C_KEY_LENGTH] = "traceparent";
+    return bpf_memcmp(name, traceparent, sizeof(traceparent));
+}
+
+/* Searches the buckets of a map, used by Go versions prior to 1.24, for the
+traceparent field. Only the first MAX_BUCKETS buckets are searched, the field
+is not found in larger tables, or in overflow buckets. */
+static __always_inline long extract_span_context_from_buckets(void *table, struct span_context *parent_span_context) {
+    unsigned char log_2_bucket_count;
+    if (bpf_probe_read_user(&log_2_bucket_count, sizeof(log_2_bucket_count), table + 9)) {
+        return -1;
+    }
+    u64 bucket_count = 1 << log_2_bucket_count;
+    void *buckets;
+    if (bpf_probe_read_user(&buckets, sizeof(buckets), (void *)(table + buckets_ptr_pos))) {
+        return -1;
+    }
+    u32 map_id = 0;
+    MAP_BUCKET_TYPE(go_string_t, go_iface_t) *bucket = bpf_map_lookup_elem(&golang_mapbucket_storage_map, &map_id);
+    if (!bucket) {
+        return -1;
+    }
+
+    for (u64 j = 0; j < MAX_BUCKETS; j++) {
+        if (j >= bucket_count) {
+            break;
+        }
+        if (bpf_probe_read_user(bucket, sizeof(*bucket), buckets + (j * sizeof(*bucket)))) {
+            continue;
+        }
+        for (u64 i = 0; i < 8; i++) {
+            if (bucket->tophash[i] < MIN_TOP_HASH) {
+                continue;
+            }
+            if (is_traceparent_key(&bucket->keys[i])) {
+                return parse_traceparent_value(&bucket->values[i], parent_span_context);
+            }
+        }
+    }
+    return -1;
+}
+
+/* Searches a swiss map, used since Go 1.24, for the traceparent field. Only
+small maps, holding up to SWISS_GROUP_SLOTS entries in a single group, are
+searched. The groups of larger maps are found in a directory of tables, the
+field is not found in them. */
+static __always_inline long extract_span_context_from_swiss_map(void *table, struct span_context *parent_span_context) {
+    s64 dir_len = 0;
+    if (bpf_probe_read_user(&dir_len, sizeof(dir_len), table + SWISS_MAP_DIR_LEN_POS) || dir_len != 0) {
+        return -1;
+    }
+    void *group_ptr = NULL;
+    if (bpf_probe_read_user(&group_ptr, sizeof(group_ptr), table + SWISS_MAP_DIR_PTR_POS) || group_ptr == NULL) {
+        return -1;
+    }
+    u32 map_id = 0;
+    struct swiss_group_t *group = bpf_map_lookup_elem(&swiss_group_storage_map, &map_id);
+    if (!group) {
+        return -1;
+    }
+    if (bpf_probe_read_user(group, sizeof(*group), group_ptr)) {
+        return -1;
+    }
+
+    for (u64 i = 0; i < SWISS_GROUP_SLOTS; i++) {
+        u8 ctrl = group->ctrl >> (8 * i);
+        if (ctrl & SWISS_CTRL_EMPTY_MASK) {
+            continue;
+        }
+        if (is_traceparent_key(&group->slots[i].key)) {
+            return parse_traceparent_value(&group->slots[i].elem, parent_span_context);
+        }
+    }
+    return -1;
+}
+
+// Extracts the span context from the traceparent field of the headers table of
+// a delivery, its keys are case sensitive.
+static __always_inline long extract_span_context_from_headers(void *delivery, struct span_context *parent_span_context) {
+    void *table = NULL;
+    if (bpf_probe_read_user(&table, sizeof(table), (void *)(delivery + delivery_headers_pos)) || table == NULL) {
+        return -1;
+    }
+    u64 count = 0;
+    if (bpf_probe_read_user(&count, sizeof(count), table) || count == 0) {
+        return -1;
+    }
+    if (swiss_maps_used) {
+        return extract_span_context_from_swiss_map(table, parent_span_context);
+    }
+    return extract_span_context_from_buckets(table, parent_span_context);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (subs *consumers) send(tag string, msg *Delivery) bool
+SEC("uprobe/consumers_send")
+int uprobe_consumers_send(struct pt_regs *ctx) {
+    /* Deliveries are dispatched by the goroutine reading the frames of the
+    connection to the consumer they are delivered to, they are then received
+    from the channel returned by Consume. The consumer does not accept a
+    context.Context, the parent span is only the one of the producer
+    propagated in the headers of the delivery. */
+    void *key = (void *)GOROUTINE(ctx);
+    void *delivery = get_argument(ctx, 4);
+    if (delivery == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct amqp_delivery_t *amqp_delivery = bpf_map_lookup_elem(&amqp_storage_map, &zero);
+    if (amqp_delivery == NULL) {
+        bpf_printk("uprobe/consumers_send: amqp_delivery is NULL");
+        return 0;
+    }
+    __builtin_memset(amqp_delivery, 0, sizeof(struct amqp_delivery_t));
+    amqp_delivery->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr((void *)(delivery + delivery_exchange_pos), amqp_delivery->exchange, sizeof(amqp_delivery->exchange));
+    get_go_string_from_user_ptr((void *)(delivery + delivery_routing_key_pos), amqp_delivery->routing_key, sizeof(amqp_delivery->routing_key));
+    bpf_probe_read_user(&amqp_delivery->delivery_tag, sizeof(amqp_delivery->delivery_tag), (void *)(delivery + delivery_delivery_tag_pos));
+    bpf_probe_read_user(&amqp_delivery->body_size, sizeof(amqp_delivery->body_size), (void *)(delivery + delivery_body_pos + offsetof(struct go_slice, len)));
+
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &amqp_delivery->psc,
+        .sc = &amqp_delivery->sc,
+        .get_parent_span_context_fn = extract_span_context_from_headers,
+        .get_parent_span_context_arg = delivery,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&amqp_events, &key, amqp_delivery, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (subs *consumers) send(tag string, msg *Delivery) bool
+SEC("uprobe/consumers_send")
+int uprobe_consumers_send_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct amqp_delivery_t *amqp_delivery = bpf_map_lookup_elem(&amqp_events, &key);
+    if (amqp_delivery == NULL) {
+        return 0;
+    }
+    amqp_delivery->end_time = end_time;
+
+    output_span_event(ctx, amqp_delivery, sizeof(*amqp_delivery), &amqp_delivery->sc);
+    bpf_map_delete_elem(&amqp_events, &key);
+    return 0;
+}
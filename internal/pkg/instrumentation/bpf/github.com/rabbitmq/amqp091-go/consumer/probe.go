@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package consumer provides an instrumentation probe for RabbitMQ consumers
+// using the [github.com/rabbitmq/amqp091-go] package.
+package consumer
+
+import (
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/rabbitmq/amqp091-go"
+
+var (
+	// minVersion is the first version supported by the probe.
+	minVersion = semver.New(1, 4, 0, "", "")
+	// goMapsVersion is the first Go version using swiss maps.
+	goMapsVersion = semver.New(1, 24, 0, "", "")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindConsumer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, pkg, "Delivery", field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("delivery_headers_pos", "Headers"),
+				fieldConst("delivery_delivery_tag_pos", "DeliveryTag"),
+				fieldConst("delivery_exchange_pos", "Exchange"),
+				fieldConst("delivery_routing_key_pos", "RoutingKey"),
+				fieldConst("delivery_body_pos", "Body"),
+				probe.StructFieldConstMaxVersion{
+					StructField: probe.StructFieldConst{
+						Key: "buckets_ptr_pos",
+						ID:  structfield.NewID("std", "runtime", "hmap", "buckets"),
+					},
+					MaxVersion: goMapsVersion,
+				},
+				swissMapsUsedConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*consumers).send",
+					EntryProbe:         "uprobe_consumers_send",
+					ReturnProbe:        "uprobe_consumers_send_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+type swissMapsUsedConst struct{}
+
+func (c swissMapsUsedConst) InjectOption(info *process.Info) (inject.Option, error) {
+	isUsingGoSwissMaps := info.GoVersion.GreaterThanEqual(goMapsVersion)
+	return inject.WithKeyValue("swiss_maps_used", isUsingGoSwissMaps), nil
+}
+
+// event represents a message delivered to a consumer.
+type event struct {
+	context.BaseSpanProperties
+	Exchange    [256]byte
+	RoutingKey  [256]byte
+	DeliveryTag uint64
+	// BodySize is the size of the body of the message.
+	BodySize uint64
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	exchange := unix.ByteSliceToString(e.Exchange[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingOperationName("receive"),
+		semconv.MessagingDestinationName(exchange),
+		semconv.MessagingMessageBodySize(int(e.BodySize)),               // nolint: gosec  // Bounded by the max message size.
+		semconv.MessagingRabbitmqMessageDeliveryTag(int(e.DeliveryTag)), // nolint: gosec  // Bounded by the messages of a channel.
+	}
+	if key := unix.ByteSliceToString(e.RoutingKey[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingRabbitmqDestinationRoutingKey(key))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(exchange))
+	span.SetKind(ptrace.SpanKindConsumer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of the span of a message delivered from exchange,
+// e.g. "orders receive". The name of the default exchange is empty.
+func spanName(exchange string) string {
+	if exchange == "" {
+		return "receive"
+	}
+	return exchange + " receive"
+}
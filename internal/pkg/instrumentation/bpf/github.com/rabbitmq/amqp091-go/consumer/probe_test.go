@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindConsumer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	e := &event{
+		BaseSpanProperties: f.BaseSpanProperties(),
+		DeliveryTag:        7,
+		BodySize:           42,
+	}
+	copy(e.Exchange[:], "orders")
+	copy(e.RoutingKey[:], "orders.new")
+
+	want := f.Spans(
+		"orders receive",
+		ptrace.StatusCodeUnset,
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingOperationTypeReceive,
+		semconv.MessagingOperationName("receive"),
+		semconv.MessagingDestinationName("orders"),
+		semconv.MessagingMessageBodySize(42),
+		semconv.MessagingRabbitmqMessageDeliveryTag(7),
+		semconv.MessagingRabbitmqDestinationRoutingKey("orders.new"),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
+
+func TestSpanName(t *testing.T) {
+	assert.Equal(t, "orders receive", spanName("orders"))
+	assert.Equal(t, "receive", spanName(""))
+}
+
+func TestSwissMapsUsedConst(t *testing.T) {
+	for _, tt := range []struct {
+		goVersion string
+		want      bool
+	}{
+		{goVersion: "1.23.8", want: false},
+		{goVersion: "1.24.0", want: true},
+		{goVersion: "1.25.1", want: true},
+	} {
+		t.Run(tt.goVersion, func(t *testing.T) {
+			info := &process.Info{GoVersion: semver.MustParse(tt.goVersion)}
+			opt, err := swissMapsUsedConst{}.InjectOption(info)
+			require.NoError(t, err)
+			assert.Equal(t, inject.WithKeyValue("swiss_maps_used", tt.want), opt)
+		})
+	}
+}
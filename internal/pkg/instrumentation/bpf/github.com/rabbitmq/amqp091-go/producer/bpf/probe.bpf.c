@@ -0,0 +1,395 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// Exchange names and routing keys are short strings of at most 255 bytes.
+#define MAX_NAME_SIZE 256
+
+// The publishing methods instrumented, they are used to end the span in the
+// method that started it.
+#define METHOD_PUBLISH_WITH_CONTEXT 1
+#define METHOD_PUBLISH_WITH_DEFERRED_CONFIRM_WITH_CONTEXT 2
+#define METHOD_PUBLISH_WITH_DEFERRED_CONFIRM 3
+
+// https://github.com/rabbitmq/amqp091-go/blob/v1.4.0/spec091.go
+// A frame is written as its type, channel, payload size, payload and a
+// frame-end octet.
+#define FRAME_HEADER 2
+#define FRAME_HEADER_SIZE 7
+#define FRAME_END_SIZE 1
+// The payload of a header frame starts with the class, weight, body size and
+// property flags, the properties follow.
+#define PROPERTY_FLAGS_OFFSET 12
+#define PROPERTIES_OFFSET 14
+#define FLAG_CONTENT_TYPE 0x8000
+#define FLAG_CONTENT_ENCODING 0x4000
+#define FLAG_HEADERS 0x2000
+// The traceparent field of a table: a short string key, the 'S' long string
+// type and the long string value.
+#define TRACEPARENT_FIELD_SIZE (1 + W3C_KEY_LENGTH + 1 + 4 + W3C_VAL_LENGTH)
+// The size of a table only holding the traceparent field.
+#define TRACEPARENT_TABLE_SIZE (4 + TRACEPARENT_FIELD_SIZE)
+// The max size of the properties following the headers table, and of the
+// frame-end octet, moved to insert the traceparent field. Keep a power of 2 to
+// help with masks.
+#define MAX_TAIL_SIZE 2048
+
+struct amqp_publish_t {
+    BASE_SPAN_PROPERTIES
+    char exchange[MAX_NAME_SIZE];
+    char routing_key[MAX_NAME_SIZE];
+    u64 body_size;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// The state of the messages being published, only the event is sent to user
+// space.
+struct amqp_publish_state_t {
+    struct amqp_publish_t event;
+    // The *bufio.Writer the header frame of the message is written to.
+    void *writer;
+    // The number of bytes buffered by the writer before the header frame.
+    s64 frame_start;
+    // The publishing method the span was started in.
+    u8 method;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct amqp_publish_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} amqp_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct amqp_publish_state_t));
+    __uint(max_entries, 1);
+} amqp_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 header_frame_size_pos;
+volatile const u64 io_writer_buf_ptr_pos;
+volatile const u64 io_writer_n_pos;
+
+static __always_inline void read_name(struct pt_regs *ctx, int pos, char *dst) {
+    void *ptr = get_argument(ctx, pos);
+    u64 len = (u64)get_argument(ctx, pos + 1);
+    u64 size = MAX_NAME_SIZE < len ? MAX_NAME_SIZE : len;
+    bpf_probe_read_user(dst, size, ptr);
+}
+
+static __always_inline void start_publish(struct pt_regs *ctx, u8 method, int exchange_pos, bool with_context) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&amqp_events, &key) != NULL) {
+        // The methods accepting a context.Context publish the message with
+        // PublishWithDeferredConfirm, it is part of their span.
+        return;
+    }
+
+    u32 zero = 0;
+    struct amqp_publish_state_t *state = bpf_map_lookup_elem(&amqp_storage_map, &zero);
+    if (state == NULL) {
+        bpf_printk("amqp publish: state is NULL");
+        return;
+    }
+    __builtin_memset(state, 0, sizeof(struct amqp_publish_state_t));
+    struct amqp_publish_t *amqp_publish = &state->event;
+    amqp_publish->start_time = get_time_ns();
+    state->method = method;
+
+    read_name(ctx, exchange_pos, amqp_publish->exchange);
+    read_name(ctx, exchange_pos + 2, amqp_publish->routing_key);
+
+    struct go_iface go_context = {0};
+    if (with_context) {
+        get_Go_context(ctx, 2, 0, true, &go_context);
+    }
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &amqp_publish->psc,
+        .sc = &amqp_publish->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&amqp_events, &key, state, 0);
+}
+
+static __always_inline void end_publish(struct pt_regs *ctx, u8 method, int error_pos) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct amqp_publish_state_t *state = bpf_map_lookup_elem(&amqp_events, &key);
+    if (state == NULL || state->method != method) {
+        return;
+    }
+    struct amqp_publish_t *amqp_publish = &state->event;
+    amqp_publish->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, error_pos) != NULL) {
+        amqp_publish->has_error = 1;
+    }
+
+    output_span_event(ctx, amqp_publish, sizeof(*amqp_publish), &amqp_publish->sc);
+    bpf_map_delete_elem(&amqp_events, &key);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (ch *Channel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg Publishing) error
+SEC("uprobe/Channel_PublishWithContext")
+int uprobe_Channel_PublishWithContext(struct pt_regs *ctx) {
+    start_publish(ctx, METHOD_PUBLISH_WITH_CONTEXT, 4, true);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (ch *Channel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg Publishing) error
+SEC("uprobe/Channel_PublishWithContext")
+int uprobe_Channel_PublishWithContext_Returns(struct pt_regs *ctx) {
+    end_publish(ctx, METHOD_PUBLISH_WITH_CONTEXT, 1);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (ch *Channel) PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg Publishing) (*DeferredConfirmation, error)
+SEC("uprobe/Channel_PublishWithDeferredConfirmWithContext")
+int uprobe_Channel_PublishWithDeferredConfirmWithContext(struct pt_regs *ctx) {
+    start_publish(ctx, METHOD_PUBLISH_WITH_DEFERRED_CONFIRM_WITH_CONTEXT, 4, true);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (ch *Channel) PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg Publishing) (*DeferredConfirmation, error)
+SEC("uprobe/Channel_PublishWithDeferredConfirmWithContext")
+int uprobe_Channel_PublishWithDeferredConfirmWithContext_Returns(struct pt_regs *ctx) {
+    end_publish(ctx, METHOD_PUBLISH_WITH_DEFERRED_CONFIRM_WITH_CONTEXT, 2);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (ch *Channel) PublishWithDeferredConfirm(exchange, key string, mandatory, immediate bool, msg Publishing) (*DeferredConfirmation, error)
+//
+// Publish, which may be inlined in its callers, publishes the message with it.
+SEC("uprobe/Channel_PublishWithDeferredConfirm")
+int uprobe_Channel_PublishWithDeferredConfirm(struct pt_regs *ctx) {
+    start_publish(ctx, METHOD_PUBLISH_WITH_DEFERRED_CONFIRM, 2, false);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (ch *Channel) PublishWithDeferredConfirm(exchange, key string, mandatory, immediate bool, msg Publishing) (*DeferredConfirmation, error)
+SEC("uprobe/Channel_PublishWithDeferredConfirm")
+int uprobe_Channel_PublishWithDeferredConfirm_Returns(struct pt_regs *ctx) {
+    end_publish(ctx, METHOD_PUBLISH_WITH_DEFERRED_CONFIRM, 2);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (f *headerFrame) write(w io.Writer) (err error)
+//
+// The header frame holds the properties of the message, including its headers
+// table. It is written to the *bufio.Writer of the connection.
+SEC("uprobe/headerFrame_write")
+int uprobe_headerFrame_write(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct amqp_publish_state_t *state = bpf_map_lookup_elem(&amqp_events, &key);
+    if (state == NULL) {
+        return 0;
+    }
+
+    void *frame = get_argument(ctx, 1);
+    bpf_probe_read_user(&state->event.body_size, sizeof(state->event.body_size), (void *)(frame + header_frame_size_pos));
+
+#ifndef NO_HEADER_PROPAGATION
+    void *writer = get_argument(ctx, 3);
+    s64 n = 0;
+    if (writer == NULL || bpf_probe_read_user(&n, sizeof(n), (void *)(writer + io_writer_n_pos))) {
+        return 0;
+    }
+    state->writer = writer;
+    state->frame_start = n;
+#endif
+    return 0;
+}
+
+#ifndef NO_HEADER_PROPAGATION
+struct frame_tail_t {
+    char buf[TRACEPARENT_TABLE_SIZE + MAX_TAIL_SIZE];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct frame_tail_t));
+    __uint(max_entries, 1);
+} frame_tail_storage_map SEC(".maps");
+
+static __always_inline u32 read_be32(u8 *b) {
+    return ((u32)b[0] << 24) | ((u32)b[1] << 16) | ((u32)b[2] << 8) | (u32)b[3];
+}
+
+static __always_inline void write_be32(u8 *b, u32 v) {
+    b[0] = v >> 24;
+    b[1] = v >> 16;
+    b[2] = v >> 8;
+    b[3] = v;
+}
+
+/* The traceparent field is inserted in the headers table of the header frame
+buffered by the writer since frame_start. The field is appended to the table of
+a message with headers, otherwise a table only holding the field is inserted
+where the table is expected and the headers flag is set. The properties
+following the table, and the frame-end octet, are moved to make room for it,
+and the sizes of the table and of the frame are updated. */
+static __always_inline void inject_traceparent(struct amqp_publish_state_t *state, void *writer, s64 frame_start) {
+    void *buf_ptr = NULL;
+    bpf_probe_read_user(&buf_ptr, sizeof(buf_ptr), (void *)(writer + io_writer_buf_ptr_pos));
+    if (buf_ptr == NULL) {
+        bpf_printk("amqp:headerFrame_write_Returns: failed to get buf from io writer");
+        return;
+    }
+    s64 size = 0;
+    if (bpf_probe_read_user(&size, sizeof(size), (void *)(writer + io_writer_buf_ptr_pos + offsetof(struct go_slice, cap)))) {
+        return;
+    }
+    s64 n = 0;
+    if (bpf_probe_read_user(&n, sizeof(n), (void *)(writer + io_writer_n_pos))) {
+        return;
+    }
+
+    // The frame is only modified if it is entirely buffered. It is not when it
+    // was larger than the available buffer and written to the connection.
+    u8 prefix[FRAME_HEADER_SIZE + PROPERTIES_OFFSET];
+    if (frame_start < 0 || n - frame_start < (s64)(sizeof(prefix) + FRAME_END_SIZE)) {
+        return;
+    }
+    void *frame = buf_ptr + frame_start;
+    if (bpf_probe_read_user(prefix, sizeof(prefix), frame)) {
+        return;
+    }
+    u32 payload_size = read_be32(&prefix[3]);
+    if (prefix[0] != FRAME_HEADER || frame_start + FRAME_HEADER_SIZE + payload_size + FRAME_END_SIZE != n) {
+        return;
+    }
+    void *payload = frame + FRAME_HEADER_SIZE;
+    u16 flags = ((u16)prefix[FRAME_HEADER_SIZE + PROPERTY_FLAGS_OFFSET] << 8) | prefix[FRAME_HEADER_SIZE + PROPERTY_FLAGS_OFFSET + 1];
+
+    // The content type and encoding short strings precede the table.
+    u64 off = PROPERTIES_OFFSET;
+    u8 str_len = 0;
+    if (flags & FLAG_CONTENT_TYPE) {
+        bpf_probe_read_user(&str_len, sizeof(str_len), payload + off);
+        off += 1 + str_len;
+    }
+    if (flags & FLAG_CONTENT_ENCODING) {
+        bpf_probe_read_user(&str_len, sizeof(str_len), payload + off);
+        off += 1 + str_len;
+    }
+
+    u32 zero = 0;
+    struct frame_tail_t *tail = bpf_map_lookup_elem(&frame_tail_storage_map, &zero);
+    if (tail == NULL) {
+        return;
+    }
+
+    u8 table_size_be[4];
+    u32 table_size = 0;
+    u64 insert_at = off;
+    u32 inserted = TRACEPARENT_FIELD_SIZE;
+    if (flags & FLAG_HEADERS) {
+        if (bpf_probe_read_user(table_size_be, sizeof(table_size_be), payload + off)) {
+            return;
+        }
+        table_size = read_be32(table_size_be);
+        insert_at = off + sizeof(table_size_be) + table_size;
+    } else {
+        write_be32((u8 *)tail->buf, TRACEPARENT_FIELD_SIZE);
+        inserted = TRACEPARENT_TABLE_SIZE;
+    }
+    if (insert_at > payload_size) {
+        return;
+    }
+    u64 tail_size = payload_size - insert_at + FRAME_END_SIZE;
+    if (tail_size > MAX_TAIL_SIZE || n + inserted > size) {
+        return;
+    }
+
+    char *field = &tail->buf[inserted - TRACEPARENT_FIELD_SIZE];
+    char key[W3C_KEY_LENGTH] = "traceparent";
+    field[0] = W3C_KEY_LENGTH;
+    __builtin_memcpy(&field[1], key, sizeof(key));
+    field[1 + W3C_KEY_LENGTH] = 'S';
+    write_be32((u8 *)&field[2 + W3C_KEY_LENGTH], W3C_VAL_LENGTH);
+    span_context_to_w3c_string(&state->event.sc, &field[6 + W3C_KEY_LENGTH]);
+
+    // The tail size is at least 1, the frame-end octet.
+    tail_size = ((tail_size - 1) & (MAX_TAIL_SIZE - 1)) + 1;
+    if (bpf_probe_read_user(&tail->buf[inserted], tail_size, payload + insert_at)) {
+        return;
+    }
+    if (bpf_probe_write_user(payload + insert_at, tail->buf, inserted + tail_size)) {
+        bpf_printk("amqp:headerFrame_write_Returns: failed to write traceparent field");
+        return;
+    }
+
+    if (flags & FLAG_HEADERS) {
+        write_be32(table_size_be, table_size + TRACEPARENT_FIELD_SIZE);
+        bpf_probe_write_user(payload + off, table_size_be, sizeof(table_size_be));
+    } else {
+        flags |= FLAG_HEADERS;
+        u8 flags_be[2] = {flags >> 8, flags & 0xff};
+        bpf_probe_write_user(payload + PROPERTY_FLAGS_OFFSET, flags_be, sizeof(flags_be));
+    }
+    u8 payload_size_be[4];
+    write_be32(payload_size_be, payload_size + inserted);
+    bpf_probe_write_user(frame + 3, payload_size_be, sizeof(payload_size_be));
+
+    n += inserted;
+    if (bpf_probe_write_user((void *)(writer + io_writer_n_pos), &n, sizeof(n))) {
+        bpf_printk("amqp:headerFrame_write_Returns: failed to change io writer n");
+    }
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (f *headerFrame) write(w io.Writer) (err error)
+SEC("uprobe/headerFrame_write")
+int uprobe_headerFrame_write_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct amqp_publish_state_t *state = bpf_map_lookup_elem(&amqp_events, &key);
+    if (state == NULL || state->writer == NULL) {
+        return 0;
+    }
+    void *writer = state->writer;
+    state->writer = NULL;
+
+    // The frame is not buffered if it failed to be written.
+    if (get_argument(ctx, 1) != NULL) {
+        return 0;
+    }
+    inject_traceparent(state, writer, state->frame_start);
+    return 0;
+}
+#else
+// Not used at all, empty stub needed to ensure both versions of the bpf program are
+// able to compile with bpf2go. The userspace code will avoid loading the probe if
+// context propagation is not enabled.
+SEC("uprobe/headerFrame_write")
+int uprobe_headerFrame_write_Returns(struct pt_regs *ctx) {
+    return 0;
+}
+#endif
@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package producer provides an instrumentation probe for RabbitMQ publishers
+// using the [github.com/rabbitmq/amqp091-go] package.
+package producer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf_no_tp ./bpf/probe.bpf.c -- -DNO_HEADER_PROPAGATION
+
+// pkg is the package being instrumented.
+const pkg = "github.com/rabbitmq/amqp091-go"
+
+// minVersion is the first version supported by the probe. It is the first
+// version with the publishing methods accepting a context.Context.
+var minVersion = semver.New(1, 4, 0, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindProducer,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "header_frame_size_pos",
+						ID:  structfield.NewID(pkg, pkg, "headerFrame", "Size"),
+					},
+					MinVersion: minVersion,
+				},
+				probe.StructFieldConst{
+					Key: "io_writer_buf_ptr_pos",
+					ID:  structfield.NewID("std", "bufio", "Writer", "buf"),
+				},
+				probe.StructFieldConst{
+					Key: "io_writer_n_pos",
+					ID:  structfield.NewID("std", "bufio", "Writer", "n"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                pkg + ".(*Channel).PublishWithContext",
+					EntryProbe:         "uprobe_Channel_PublishWithContext",
+					ReturnProbe:        "uprobe_Channel_PublishWithContext_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*Channel).PublishWithDeferredConfirmWithContext",
+					EntryProbe:         "uprobe_Channel_PublishWithDeferredConfirmWithContext",
+					ReturnProbe:        "uprobe_Channel_PublishWithDeferredConfirmWithContext_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*Channel).PublishWithDeferredConfirm",
+					EntryProbe:         "uprobe_Channel_PublishWithDeferredConfirm",
+					ReturnProbe:        "uprobe_Channel_PublishWithDeferredConfirm_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                pkg + ".(*headerFrame).write",
+					EntryProbe:         "uprobe_headerFrame_write",
+					ReturnProbe:        "uprobe_headerFrame_write_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: verifyAndLoadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
+	if !kernel.SupportsContextPropagation() {
+		fmt.Fprintf(
+			os.Stderr,
+			"the Linux Kernel doesn't support context propagation, please check if the kernel is in lockdown mode (/sys/kernel/security/lockdown)",
+		)
+		return loadBpf_no_tp()
+	}
+
+	return loadBpf()
+}
+
+// event represents a message published by the client.
+type event struct {
+	context.BaseSpanProperties
+	Exchange   [256]byte
+	RoutingKey [256]byte
+	// BodySize is the size of the body of the message.
+	BodySize uint64
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	exchange := unix.ByteSliceToString(e.Exchange[:])
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("publish"),
+		semconv.MessagingDestinationName(exchange),
+		semconv.MessagingMessageBodySize(int(e.BodySize)), // nolint: gosec  // Bounded by the max frame size.
+	}
+	if key := unix.ByteSliceToString(e.RoutingKey[:]); key != "" {
+		attrs = append(attrs, semconv.MessagingRabbitmqDestinationRoutingKey(key))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(exchange))
+	span.SetKind(ptrace.SpanKindProducer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of the span of a message published to exchange,
+// e.g. "orders publish". The name of the default exchange is empty.
+func spanName(exchange string) string {
+	if exchange == "" {
+		return "publish"
+	}
+	return exchange + " publish"
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindProducer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(exchange, routingKey string, bodySize uint64, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BodySize:           bodySize,
+		}
+		copy(e.Exchange[:], exchange)
+		copy(e.RoutingKey[:], routingKey)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "exchange",
+			event: newEvent("orders", "orders.new", 42, false),
+			want: f.Spans(
+				"orders publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemRabbitmq,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName("orders"),
+				semconv.MessagingMessageBodySize(42),
+				semconv.MessagingRabbitmqDestinationRoutingKey("orders.new"),
+			),
+		},
+		{
+			name:  "default exchange",
+			event: newEvent("", "queue", 8, false),
+			want: f.Spans(
+				"publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemRabbitmq,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName(""),
+				semconv.MessagingMessageBodySize(8),
+				semconv.MessagingRabbitmqDestinationRoutingKey("queue"),
+			),
+		},
+		{
+			name:  "no routing key",
+			event: newEvent("events", "", 0, false),
+			want: f.Spans(
+				"events publish",
+				ptrace.StatusCodeUnset,
+				semconv.MessagingSystemRabbitmq,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName("events"),
+				semconv.MessagingMessageBodySize(0),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("orders", "orders.new", 42, true),
+			want: f.Spans(
+				"orders publish",
+				ptrace.StatusCodeError,
+				semconv.MessagingSystemRabbitmq,
+				semconv.MessagingOperationTypeSend,
+				semconv.MessagingOperationName("publish"),
+				semconv.MessagingDestinationName("orders"),
+				semconv.MessagingMessageBodySize(42),
+				semconv.MessagingRabbitmqDestinationRoutingKey("orders.new"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
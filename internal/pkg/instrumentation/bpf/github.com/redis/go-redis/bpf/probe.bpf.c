@@ -0,0 +1,141 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_OPERATION_SIZE 32
+#define MAX_ADDR_SIZE 128
+#define MAX_CONCURRENT 50
+
+struct redis_request_t {
+    BASE_SPAN_PROPERTIES
+    char operation[MAX_OPERATION_SIZE];
+    char addr[MAX_ADDR_SIZE];
+    u64 batch_size;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct redis_request_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} redis_events SEC(".maps");
+
+// Injected in init
+volatile const u64 base_client_opt_pos;
+volatile const u64 options_addr_pos;
+volatile const u64 base_cmd_args_pos;
+
+// Reads the name of the command pointed to by cmd_ptr, the first of its
+// arguments, into dst.
+static __always_inline void read_cmd_name(void *cmd_ptr, char *dst) {
+    if (cmd_ptr == NULL) {
+        return;
+    }
+
+    // All the Cmder implementations embed baseCmd as their first field.
+    struct go_slice args = {0};
+    long res = bpf_probe_read_user(&args, sizeof(args), (void *)(cmd_ptr + base_cmd_args_pos));
+    if (res != 0 || args.len < 1) {
+        return;
+    }
+
+    // The name is a string held by an interface{}, its data points to the
+    // string header.
+    struct go_iface name = {0};
+    res = bpf_probe_read_user(&name, sizeof(name), args.array);
+    if (res != 0) {
+        return;
+    }
+    get_go_string_from_user_ptr(name.data, dst, MAX_OPERATION_SIZE);
+}
+
+// Reads the Options.Addr of the baseClient pointed to by client_ptr into dst.
+static __always_inline void read_addr(void *client_ptr, char *dst) {
+    void *opt_ptr = NULL;
+    long res = bpf_probe_read_user(&opt_ptr, sizeof(opt_ptr), (void *)(client_ptr + base_client_opt_pos));
+    if (res != 0 || opt_ptr == NULL) {
+        return;
+    }
+    get_go_string_from_user_ptr((void *)(opt_ptr + options_addr_pos), dst, MAX_ADDR_SIZE);
+}
+
+static __always_inline void start_redis_span(struct pt_regs *ctx, struct redis_request_t *redis_request) {
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &redis_request->psc,
+        .sc = &redis_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    // Get key
+    void *key = (void *)GOROUTINE(ctx);
+
+    bpf_map_update_elem(&redis_events, &key, redis_request, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *baseClient) process(ctx context.Context, cmd Cmder) error
+SEC("uprobe/baseClient_process")
+int uprobe_baseClient_process(struct pt_regs *ctx) {
+    // argument positions
+    u64 client_ptr_pos = 1;
+    u64 cmd_ptr_pos = 5;
+
+    struct redis_request_t redis_request = {0};
+    redis_request.start_time = get_time_ns();
+
+    read_addr(get_argument(ctx, client_ptr_pos), redis_request.addr);
+    read_cmd_name(get_argument(ctx, cmd_ptr_pos), redis_request.operation);
+
+    start_redis_span(ctx, &redis_request);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *baseClient) process(ctx context.Context, cmd Cmder) error
+UPROBE_RETURN(baseClient_process, struct redis_request_t, redis_events)
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *baseClient) processPipeline(ctx context.Context, cmds []Cmder) error
+SEC("uprobe/baseClient_processPipeline")
+int uprobe_baseClient_processPipeline(struct pt_regs *ctx) {
+    // argument positions
+    u64 client_ptr_pos = 1;
+    u64 cmds_ptr_pos = 4;
+    u64 cmds_len_pos = 5;
+
+    struct redis_request_t redis_request = {0};
+    redis_request.start_time = get_time_ns();
+
+    read_addr(get_argument(ctx, client_ptr_pos), redis_request.addr);
+
+    redis_request.batch_size = (u64)get_argument(ctx, cmds_len_pos);
+    if (redis_request.batch_size > 0) {
+        // Name of the first command, used if it is the only one.
+        struct go_iface cmd = {0};
+        void *cmds_ptr = get_argument(ctx, cmds_ptr_pos);
+        if (bpf_probe_read_user(&cmd, sizeof(cmd), cmds_ptr) == 0) {
+            read_cmd_name(cmd.data, redis_request.operation);
+        }
+    }
+
+    start_redis_span(ctx, &redis_request);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *baseClient) processPipeline(ctx context.Context, cmds []Cmder) error
+UPROBE_RETURN(baseClient_processPipeline, struct redis_request_t, redis_events)
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis provides an instrumentation probe for Redis clients using the
+// [github.com/redis/go-redis/v9] package.
+package redis
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "github.com/redis/go-redis/v9"
+
+	// pipelineOperation is the operation name of pipelines of more than one
+	// command.
+	pipelineOperation = "PIPELINE"
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "base_client_opt_pos",
+					ID:  structfield.NewID(pkg, pkg, "baseClient", "opt"),
+				},
+				probe.StructFieldConst{
+					Key: "options_addr_pos",
+					ID:  structfield.NewID(pkg, pkg, "Options", "Addr"),
+				},
+				probe.StructFieldConst{
+					Key: "base_cmd_args_pos",
+					ID:  structfield.NewID(pkg, pkg, "baseCmd", "args"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         "github.com/redis/go-redis/v9.(*baseClient).process",
+					EntryProbe:  "uprobe_baseClient_process",
+					ReturnProbe: "uprobe_baseClient_process_Returns",
+				},
+				{
+					Sym:         "github.com/redis/go-redis/v9.(*baseClient).processPipeline",
+					EntryProbe:  "uprobe_baseClient_processPipeline",
+					ReturnProbe: "uprobe_baseClient_processPipeline_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+			},
+
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a Redis command, or pipeline of commands, sent by a
+// client.
+type event struct {
+	context.BaseSpanProperties
+	// Operation is the name of the command, or of the first command of a
+	// pipeline.
+	Operation [32]byte
+	// Addr is the address of the Redis server from the client options.
+	Addr [128]byte
+	// BatchSize is the number of commands of a pipeline, zero for a single
+	// command.
+	BatchSize uint64
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameRedis}
+
+	// Command names are sent as passed to the client (e.g. "get").
+	operation := strings.ToUpper(unix.ByteSliceToString(e.Operation[:]))
+	if e.BatchSize > 1 {
+		operation = pipelineOperation
+		attrs = append(
+			attrs,
+			semconv.DBOperationBatchSize(int(min(e.BatchSize, math.MaxInt))), // nolint: gosec  // Bounded.
+		)
+	}
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperationName(operation))
+	}
+
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Addr[:]))
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	name := operation
+	if name == "" {
+		name = semconv.DBSystemNameRedis.Value.AsString()
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
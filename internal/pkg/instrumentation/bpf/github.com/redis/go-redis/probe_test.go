@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(operation, addr string, batchSize uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BatchSize:          batchSize,
+		}
+		copy(e.Operation[:], operation)
+		copy(e.Addr[:], addr)
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "command",
+			event: newEvent("get", "localhost:6379", 0),
+			want: f.Spans(
+				"GET",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("GET"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(6379),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "pipeline",
+			event: newEvent("set", "10.0.0.1:6380", 3),
+			want: f.Spans(
+				"PIPELINE",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationBatchSize(3),
+				semconv.DBOperationName("PIPELINE"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(6380),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "single command pipeline",
+			event: newEvent("incr", "localhost:6379", 1),
+			want: f.Spans(
+				"INCR",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("INCR"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(6379),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "unix socket",
+			event: newEvent("ping", "/var/run/redis.sock", 0),
+			want: f.Spans(
+				"PING",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("PING"),
+				semconv.ServerAddress("/var/run/redis.sock"),
+				semconv.NetworkTransportUnix,
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", "", 0),
+			want:  f.Spans("redis", ptrace.StatusCodeUnset, semconv.DBSystemNameRedis),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
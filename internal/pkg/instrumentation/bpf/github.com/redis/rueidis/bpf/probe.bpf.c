@@ -0,0 +1,259 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_net.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_OPERATION_SIZE 32
+#define MAX_CONCURRENT 50
+
+struct rueidis_request_t {
+    BASE_SPAN_PROPERTIES
+    char operation[MAX_OPERATION_SIZE];
+    net_addr_t server_addr;
+    u64 batch_size;
+};
+
+// A call of a pipe method in progress in a goroutine. The methods of the
+// client-side cache call the other methods for the commands missing from the
+// cache: only the outermost call produces a span.
+struct rueidis_call_t {
+    struct rueidis_request_t request;
+    // Number of pipe method calls in progress.
+    u32 depth;
+    // True if the commands were sent to the server, false if they were all
+    // read from the client-side cache.
+    bool sent;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct rueidis_call_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} rueidis_events SEC(".maps");
+
+// Injected in init
+volatile const u64 pipe_conn_pos;
+volatile const u64 command_slice_s_pos;
+volatile const u64 conn_fd_pos;
+volatile const u64 net_fd_raddr_pos;
+
+// Reads the name of the command of the CommandSlice pointed to by cs_ptr, its
+// first element, into dst.
+static __always_inline void read_cmd_name(void *cs_ptr, char *dst) {
+    if (cs_ptr == NULL) {
+        return;
+    }
+
+    struct go_slice s = {0};
+    long res = bpf_probe_read_user(&s, sizeof(s), (void *)(cs_ptr + command_slice_s_pos));
+    if (res != 0 || s.len < 1) {
+        return;
+    }
+    get_go_string_from_user_ptr(s.array, dst, MAX_OPERATION_SIZE);
+}
+
+// Reads the name of the first command of the slice of commands at multi_ptr.
+// The commands are a Completed, or a CacheableTTL, both starting with the
+// pointer to their CommandSlice.
+static __always_inline void read_first_cmd_name(void *multi_ptr, u64 len, char *dst) {
+    if (multi_ptr == NULL || len < 1) {
+        return;
+    }
+
+    void *cs_ptr = NULL;
+    if (bpf_probe_read_user(&cs_ptr, sizeof(cs_ptr), multi_ptr) != 0) {
+        return;
+    }
+    read_cmd_name(cs_ptr, dst);
+}
+
+// Reads the remote address of the connection of the pipe pointed to by
+// pipe_ptr into addr. Only TCP connections are supported.
+static __always_inline long read_server_addr(struct pt_regs *ctx, void *pipe_ptr, net_addr_t *addr) {
+    // The net.Conn of the pipe is expected to be a *net.TCPConn, which embeds
+    // a net.conn holding the *net.netFD of the connection.
+    void *conn_ptr = NULL;
+    long res = bpf_probe_read_user(&conn_ptr, sizeof(conn_ptr), get_go_interface_instance(pipe_ptr + pipe_conn_pos));
+    if (res != 0 || conn_ptr == NULL) {
+        return -1;
+    }
+
+    void *fd_ptr = NULL;
+    res = bpf_probe_read_user(&fd_ptr, sizeof(fd_ptr), (void *)(conn_ptr + conn_fd_pos));
+    if (res != 0 || fd_ptr == NULL) {
+        return -1;
+    }
+
+    void *raddr_ptr = NULL;
+    res = bpf_probe_read_user(&raddr_ptr, sizeof(raddr_ptr), get_go_interface_instance(fd_ptr + net_fd_raddr_pos));
+    if (res != 0 || raddr_ptr == NULL) {
+        return -1;
+    }
+
+    return get_tcp_net_addr_from_tcp_addr(ctx, addr, raddr_ptr);
+}
+
+// Starts the span of a pipe method call, or joins the span of the call in
+// progress in the goroutine. If sent is true, the commands of the call are
+// sent to the server.
+//
+// The name of the command, and number of commands, of the call are read by
+// the caller once a new span is started, the returned pointer is NULL
+// otherwise.
+static __always_inline struct rueidis_call_t *start_call(struct pt_regs *ctx, bool sent) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct rueidis_call_t *call = bpf_map_lookup_elem(&rueidis_events, &key);
+    if (call != NULL) {
+        call->depth++;
+        if (sent) {
+            call->sent = true;
+        }
+        return NULL;
+    }
+
+    struct rueidis_call_t new_call = {0};
+    new_call.request.start_time = get_time_ns();
+    new_call.depth = 1;
+    new_call.sent = sent;
+
+    u64 pipe_ptr_pos = 1;
+    read_server_addr(ctx, get_argument(ctx, pipe_ptr_pos), &new_call.request.server_addr);
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &new_call.request.psc,
+        .sc = &new_call.request.sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&rueidis_events, &key, &new_call, 0);
+    return bpf_map_lookup_elem(&rueidis_events, &key);
+}
+
+// Ends the pipe method call in progress in the goroutine. The span is output
+// once the outermost call returns, unless all the commands were read from the
+// client-side cache.
+static __always_inline int end_call(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct rueidis_call_t *call = bpf_map_lookup_elem(&rueidis_events, &key);
+    if (call == NULL) {
+        return 0;
+    }
+    if (call->depth > 1) {
+        call->depth--;
+        return 0;
+    }
+
+    if (call->sent) {
+        call->request.end_time = get_time_ns();
+        output_span_event(ctx, &call->request, sizeof(call->request), &call->request.sc);
+    }
+    bpf_map_delete_elem(&rueidis_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) Do(ctx context.Context, cmd Completed) (resp RedisResult)
+SEC("uprobe/pipe_Do")
+int uprobe_pipe_Do(struct pt_regs *ctx) {
+    u64 cmd_cs_ptr_pos = 4;
+
+    struct rueidis_call_t *call = start_call(ctx, true);
+    if (call == NULL) {
+        return 0;
+    }
+    read_cmd_name(get_argument(ctx, cmd_cs_ptr_pos), call->request.operation);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) Do(ctx context.Context, cmd Completed) (resp RedisResult)
+SEC("uprobe/pipe_Do")
+int uprobe_pipe_Do_Returns(struct pt_regs *ctx) {
+    return end_call(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *redisresults
+SEC("uprobe/pipe_DoMulti")
+int uprobe_pipe_DoMulti(struct pt_regs *ctx) {
+    u64 multi_ptr_pos = 4;
+    u64 multi_len_pos = 5;
+
+    struct rueidis_call_t *call = start_call(ctx, true);
+    if (call == NULL) {
+        return 0;
+    }
+    call->request.batch_size = (u64)get_argument(ctx, multi_len_pos);
+    read_first_cmd_name(get_argument(ctx, multi_ptr_pos), call->request.batch_size, call->request.operation);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) DoMulti(ctx context.Context, multi ...Completed) *redisresults
+SEC("uprobe/pipe_DoMulti")
+int uprobe_pipe_DoMulti_Returns(struct pt_regs *ctx) {
+    return end_call(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) RedisResult
+SEC("uprobe/pipe_DoCache")
+int uprobe_pipe_DoCache(struct pt_regs *ctx) {
+    u64 cmd_cs_ptr_pos = 4;
+
+    // The command is only sent to the server by the Do or DoMulti call made
+    // if it is missing from the cache.
+    struct rueidis_call_t *call = start_call(ctx, false);
+    if (call == NULL) {
+        return 0;
+    }
+    read_cmd_name(get_argument(ctx, cmd_cs_ptr_pos), call->request.operation);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) DoCache(ctx context.Context, cmd Cacheable, ttl time.Duration) RedisResult
+SEC("uprobe/pipe_DoCache")
+int uprobe_pipe_DoCache_Returns(struct pt_regs *ctx) {
+    return end_call(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) DoMultiCache(ctx context.Context, multi ...CacheableTTL) *redisresults
+SEC("uprobe/pipe_DoMultiCache")
+int uprobe_pipe_DoMultiCache(struct pt_regs *ctx) {
+    u64 multi_ptr_pos = 4;
+    u64 multi_len_pos = 5;
+
+    // The commands are only sent to the server by the DoMulti call made if
+    // some are missing from the cache.
+    struct rueidis_call_t *call = start_call(ctx, false);
+    if (call == NULL) {
+        return 0;
+    }
+    call->request.batch_size = (u64)get_argument(ctx, multi_len_pos);
+    read_first_cmd_name(get_argument(ctx, multi_ptr_pos), call->request.batch_size, call->request.operation);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *pipe) DoMultiCache(ctx context.Context, multi ...CacheableTTL) *redisresults
+SEC("uprobe/pipe_DoMultiCache")
+int uprobe_pipe_DoMultiCache_Returns(struct pt_regs *ctx) {
+    return end_call(ctx);
+}
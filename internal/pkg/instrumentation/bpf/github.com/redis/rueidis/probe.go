@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rueidis provides instrumentation probes for Redis clients using the
+// [github.com/redis/rueidis] package, or the [github.com/valkey-io/valkey-go]
+// package forked from it.
+package rueidis
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkgRueidis is the package being instrumented by the probe returned by
+	// New.
+	pkgRueidis = "github.com/redis/rueidis"
+	// pkgValkey is the package being instrumented by the probe returned by
+	// NewValkey.
+	pkgValkey = "github.com/valkey-io/valkey-go"
+
+	// pipelineOperation is the operation name of pipelines of more than one
+	// command.
+	pipelineOperation = "PIPELINE"
+)
+
+var (
+	// minVersionRueidis is the first stable release of the
+	// github.com/redis/rueidis module.
+	minVersionRueidis = semver.New(1, 0, 0, "", "")
+	// minVersionValkey is the first release of the
+	// github.com/valkey-io/valkey-go module.
+	minVersionValkey = semver.New(1, 0, 35, "", "")
+)
+
+// New returns a new [probe.Probe] for the github.com/redis/rueidis module.
+func New(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgRueidis, minVersionRueidis)
+}
+
+// NewValkey returns a new [probe.Probe] for the
+// github.com/valkey-io/valkey-go module.
+func NewValkey(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgValkey, minVersionValkey)
+}
+
+func newProbe(logger *slog.Logger, version, pkg string, minVer *semver.Version) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVer.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	uprobe := func(method string) *probe.Uprobe {
+		return &probe.Uprobe{
+			Sym:                pkg + ".(*pipe)." + method,
+			EntryProbe:         "uprobe_pipe_" + method,
+			ReturnProbe:        "uprobe_pipe_" + method + "_Returns",
+			PackageConstraints: []probe.PackageConstraints{supported},
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "pipe_conn_pos",
+						ID:  structfield.NewID(pkg, pkg, "pipe", "conn"),
+					},
+					MinVersion: minVer,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "command_slice_s_pos",
+						ID:  structfield.NewID(pkg, pkg+"/internal/cmds", "CommandSlice", "s"),
+					},
+					MinVersion: minVer,
+				},
+				probe.StructFieldConst{
+					Key: "conn_fd_pos",
+					ID:  structfield.NewID("std", "net", "conn", "fd"),
+				},
+				probe.StructFieldConst{
+					Key: "net_fd_raddr_pos",
+					ID:  structfield.NewID("std", "net", "netFD", "raddr"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_IP_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "IP"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Port_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Port"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Zone_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Zone"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				uprobe("Do"),
+				uprobe("DoMulti"),
+				uprobe("DoCache"),
+				uprobe("DoMultiCache"),
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a Redis command, or pipeline of commands, sent by a
+// client. Commands read from the client-side cache are not sent, no event is
+// produced for them.
+type event struct {
+	context.BaseSpanProperties
+	// Operation is the name of the command, or of the first command of a
+	// pipeline.
+	Operation [32]byte
+	// ServerAddr is the remote address of the connection the commands are
+	// sent on.
+	ServerAddr netattr.NetAddr
+	// BatchSize is the number of commands of a pipeline, zero for a single
+	// command.
+	BatchSize uint64
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{semconv.DBSystemNameRedis}
+
+	operation := strings.ToUpper(unix.ByteSliceToString(e.Operation[:]))
+	if e.BatchSize > 1 {
+		operation = pipelineOperation
+		attrs = append(
+			attrs,
+			semconv.DBOperationBatchSize(int(min(e.BatchSize, math.MaxInt))), // nolint: gosec  // Bounded.
+		)
+	}
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperationName(operation))
+	}
+
+	// The remote end of the connection is the only address of the server
+	// known.
+	server := e.ServerAddr.Addr()
+	attrs = append(attrs, netattr.Attributes(server, server)...)
+
+	name := operation
+	if name == "" {
+		name = semconv.DBSystemNameRedis.Value.AsString()
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
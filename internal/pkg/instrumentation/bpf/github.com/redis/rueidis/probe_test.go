@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rueidis
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(operation string, ip []byte, port int32, batchSize uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			BatchSize:          batchSize,
+		}
+		copy(e.Operation[:], operation)
+		copy(e.ServerAddr.IP[:], ip)
+		e.ServerAddr.IPLen = uint8(len(ip)) // nolint: gosec  // Bounded.
+		e.ServerAddr.Port = port
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "command",
+			event: newEvent("GET", []byte{127, 0, 0, 1}, 6379, 0),
+			want: f.Spans(
+				"GET",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("GET"),
+				semconv.NetworkPeerAddress("127.0.0.1"),
+				semconv.NetworkPeerPort(6379),
+				semconv.ServerAddress("127.0.0.1"),
+				semconv.ServerPort(6379),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "pipeline",
+			event: newEvent("SET", []byte{10, 0, 0, 1}, 6380, 3),
+			want: f.Spans(
+				"PIPELINE",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationBatchSize(3),
+				semconv.DBOperationName("PIPELINE"),
+				semconv.NetworkPeerAddress("10.0.0.1"),
+				semconv.NetworkPeerPort(6380),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(6380),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "single command pipeline",
+			event: newEvent("INCR", []byte{127, 0, 0, 1}, 6379, 1),
+			want: f.Spans(
+				"INCR",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("INCR"),
+				semconv.NetworkPeerAddress("127.0.0.1"),
+				semconv.NetworkPeerPort(6379),
+				semconv.ServerAddress("127.0.0.1"),
+				semconv.ServerPort(6379),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "IPv6",
+			event: newEvent("ping", net.ParseIP("::1"), 6379, 0),
+			want: f.Spans(
+				"PING",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("PING"),
+				semconv.NetworkPeerAddress("::1"),
+				semconv.NetworkPeerPort(6379),
+				semconv.ServerAddress("::1"),
+				semconv.ServerPort(6379),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", nil, 0, 0),
+			want:  f.Spans("redis", ptrace.StatusCodeUnset, semconv.DBSystemNameRedis),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -134,7 +134,7 @@ int uprobe_FetchMessage(struct pt_regs *ctx) {
     }
 
     get_go_string_from_user_ptr((void *)(reader + reader_config_pos + reader_config_group_id_pos), kafka_request->consumer_group, sizeof(kafka_request->consumer_group));
-    kafka_request->end_time = bpf_ktime_get_ns();
+    kafka_request->end_time = get_time_ns();
 
     output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
     stop_tracking_span(&kafka_request->sc, &kafka_request->psc);
@@ -162,7 +162,7 @@ int uprobe_FetchMessage_Returns(struct pt_regs *ctx) {
         bpf_printk("uuprobe/sendMessage: kafka_request is NULL");
         return 0;
     }
-    kafka_request->start_time = bpf_ktime_get_ns();
+    kafka_request->start_time = get_time_ns();
     // The message returned on the stack since it returned as a struct and not a pointer
     void *message = (void *)(PT_REGS_SP(ctx) + 8);
 
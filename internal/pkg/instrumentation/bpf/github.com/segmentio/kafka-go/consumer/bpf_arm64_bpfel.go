@@ -106,6 +106,7 @@ type bpfMapSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfVariableSpecs struct {
+	BootClockSupported     *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
 	EndAddr                *ebpf.VariableSpec `ebpf:"end_addr"`
 	Hex                    *ebpf.VariableSpec `ebpf:"hex"`
 	MessageHeadersPos      *ebpf.VariableSpec `ebpf:"message_headers_pos"`
@@ -172,6 +173,7 @@ func (m *bpfMaps) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfVariables struct {
+	BootClockSupported     *ebpf.Variable `ebpf:"boot_clock_supported"`
 	EndAddr                *ebpf.Variable `ebpf:"end_addr"`
 	Hex                    *ebpf.Variable `ebpf:"hex"`
 	MessageHeadersPos      *ebpf.Variable `ebpf:"message_headers_pos"`
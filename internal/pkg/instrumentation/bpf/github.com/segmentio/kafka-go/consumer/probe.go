@@ -41,6 +41,7 @@ func New(logger *slog.Logger, version string) probe.Probe {
 			Logger: logger,
 			Consts: []probe.Const{
 				probe.AllocationConst{},
+				probe.BootClockConst{},
 				probe.StructFieldConst{
 					Key: "message_headers_pos",
 					ID: structfield.NewID(
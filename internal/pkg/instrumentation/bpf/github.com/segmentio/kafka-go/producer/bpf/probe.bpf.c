@@ -17,7 +17,8 @@ char __license[] SEC("license") = "Dual MIT/GPL";
 // limitation on map entry size: https://github.com/iovisor/bcc/issues/2519#issuecomment-534359316
 // the default value is 100, but it can be changed by the user
 // we must specify a limit for the verifier
-#define MAX_BATCH_SIZE 10
+// Only the first MAX_BATCH_SIZE messages of a batch are traced.
+#define MAX_BATCH_SIZE 32
 // https://github.com/apache/kafka/blob/0.10.2/core/src/main/scala/kafka/common/Topic.scala#L30C3-L30C34
 #define MAX_TOPIC_SIZE 256
 // No constraint on the key size, but we must have a limit for the verifier
@@ -27,17 +28,21 @@ struct message_attributes_t {
     struct span_context sc;
     char topic[MAX_TOPIC_SIZE];
     char key[MAX_KEY_SIZE];
+    // The partition the message is written to, -1 if it is not known.
+    s64 partition;
 };
 
 struct kafka_request_t {
-    // common attributes to all the produced messages
-    u64 start_time;
-    u64 end_time;
-    struct span_context psc;
+    // common attributes to all the produced messages, the span context is the
+    // one of the batch
+    BASE_SPAN_PROPERTIES
     // attributes per message
     struct message_attributes_t msgs[MAX_BATCH_SIZE];
     char global_topic[MAX_TOPIC_SIZE];
     u64 valid_messages;
+    // Number of messages in the batch, more than valid_messages if the batch
+    // is truncated
+    u64 total_messages;
 }__attribute__((packed));
 
 struct {
@@ -68,6 +73,8 @@ volatile const u64 message_headers_pos;
 volatile const u64 message_time_pos;
 
 volatile const u64 writer_topic_pos;
+volatile const u64 partition_writer_meta_pos;
+volatile const u64 topic_partition_partition_pos;
 
 #ifndef NO_HEADER_PROPAGATION
 static __always_inline int build_contxet_header(struct kafka_header_t *header, struct span_context *span_ctx) {
@@ -164,13 +171,13 @@ int uprobe_WriteMessages(struct pt_regs *ctx) {
         return 0;
     }
 
-    kafka_request->start_time = bpf_ktime_get_ns();
+    kafka_request->start_time = get_time_ns();
 
     start_span_params_t start_span_params = {
         .ctx = ctx,
         .go_context = &go_context,
         .psc = &kafka_request->psc,
-        .sc = &kafka_request->msgs[0].sc,
+        .sc = &kafka_request->sc,
         .get_parent_span_context_fn = NULL,
         .get_parent_span_context_arg = NULL,
     };
@@ -197,14 +204,14 @@ int uprobe_WriteMessages(struct pt_regs *ctx) {
         }
         // Optionally collect the topic, and always collect key
         collect_kafka_attributes(msg_ptr, &kafka_request->msgs[i], !global_topic);
+        // The partition is only known once the message is assigned to one
+        kafka_request->msgs[i].partition = -1;
         // Generate span id for each message
-        if (i > 0) {
-            generate_random_bytes(kafka_request->msgs[i].sc.SpanID, SPAN_ID_SIZE);
-            // Copy the trace id and trace flags from the first message. This means the sampling decision is done on the first message,
-            // and all the messages in the batch will have the same trace id and trace flags.
-            kafka_request->msgs[i].sc.TraceFlags = kafka_request->msgs[0].sc.TraceFlags;
-            __builtin_memcpy(kafka_request->msgs[i].sc.TraceID, kafka_request->msgs[0].sc.TraceID, TRACE_ID_SIZE);
-        }
+        generate_random_bytes(kafka_request->msgs[i].sc.SpanID, SPAN_ID_SIZE);
+        // Copy the trace id and trace flags from the batch. This means the sampling decision is done on the batch,
+        // and all the messages in the batch will have the same trace id and trace flags.
+        kafka_request->msgs[i].sc.TraceFlags = kafka_request->sc.TraceFlags;
+        __builtin_memcpy(kafka_request->msgs[i].sc.TraceID, kafka_request->sc.TraceID, TRACE_ID_SIZE);
 
 #ifndef NO_HEADER_PROPAGATION
         // Build the header
@@ -218,6 +225,7 @@ int uprobe_WriteMessages(struct pt_regs *ctx) {
         kafka_request->valid_messages++;
         msg_ptr = msg_ptr + msg_size;
     }
+    kafka_request->total_messages = msgs_array_len;
 
 
     bpf_map_update_elem(&kafka_events, &key, kafka_request, 0);
@@ -229,7 +237,7 @@ int uprobe_WriteMessages(struct pt_regs *ctx) {
 // func (w *Writer) WriteMessages(ctx context.Context, msgs ...Message) error
 SEC("uprobe/WriteMessages")
 int uprobe_WriteMessages_Returns(struct pt_regs *ctx) {
-    u64 end_time = bpf_ktime_get_ns();
+    u64 end_time = get_time_ns();
     void *key = (void *)GOROUTINE(ctx);
 
     struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_events, &key);
@@ -239,8 +247,44 @@ int uprobe_WriteMessages_Returns(struct pt_regs *ctx) {
     }
     kafka_request->end_time = end_time;
 
-    output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->msgs[0].sc);
+    output_span_event(ctx, kafka_request, sizeof(*kafka_request), &kafka_request->sc);
     bpf_map_delete_elem(&kafka_events, &key);
     // don't need to stop tracking the span, as we don't have a context to propagate locally
     return 0;
 }
+
+// This instrumentation attaches uprobe to the following function:
+// func (ptw *partitionWriter) writeMessages(msgs []Message, indexes []int32) map[*writeBatch][]int32
+SEC("uprobe/partitionWriter_writeMessages")
+int uprobe_partitionWriter_writeMessages(struct pt_regs *ctx) {
+    // The messages of the batch are assigned to the writers of their
+    // partition by WriteMessages, in the goroutine calling it.
+    void *key = (void *)GOROUTINE(ctx);
+    struct kafka_request_t *kafka_request = bpf_map_lookup_elem(&kafka_events, &key);
+    if (kafka_request == NULL) {
+        return 0;
+    }
+
+    void *ptw = get_argument(ctx, 1);
+    s32 *indexes = get_argument(ctx, 5);
+    u64 indexes_len = (u64)get_argument(ctx, 6);
+    s32 partition = -1;
+    if (ptw == NULL || bpf_probe_read_user(&partition, sizeof(partition), (void *)(ptw + partition_writer_meta_pos + topic_partition_partition_pos)) != 0) {
+        return 0;
+    }
+
+    // The indexes of the messages are in ascending order, the traced ones
+    // are the first.
+    for (u64 i = 0; i < MAX_BATCH_SIZE; i++) {
+        if (i >= indexes_len) {
+            break;
+        }
+        s32 index = -1;
+        bpf_probe_read_user(&index, sizeof(index), (void *)(indexes + i));
+        if (index < 0 || index >= MAX_BATCH_SIZE) {
+            break;
+        }
+        kafka_request->msgs[index].partition = partition;
+    }
+    return 0;
+}
@@ -17,15 +17,18 @@ type bpfKafkaRequestT struct {
 	_         structs.HostLayout
 	StartTime uint64
 	EndTime   uint64
+	Sc        bpfSpanContext
 	Psc       bpfSpanContext
-	Msgs      [10]struct {
-		_     structs.HostLayout
-		Sc    bpfSpanContext
-		Topic [256]int8
-		Key   [256]int8
+	Msgs      [32]struct {
+		_         structs.HostLayout
+		Sc        bpfSpanContext
+		Topic     [256]int8
+		Key       [256]int8
+		Partition int64
 	}
 	GlobalTopic   [256]int8
 	ValidMessages uint64
+	TotalMessages uint64
 }
 
 type bpfSliceArrayBuff struct {
@@ -83,8 +86,9 @@ type bpfSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfProgramSpecs struct {
-	UprobeWriteMessages        *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages"`
-	UprobeWriteMessagesReturns *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobeWriteMessages                *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages"`
+	UprobeWriteMessagesReturns         *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobePartitionWriterWriteMessages *ebpf.ProgramSpec `ebpf:"uprobe_partitionWriter_writeMessages"`
 }
 
 // bpfMapSpecs contains maps before they are loaded into the kernel.
@@ -106,15 +110,18 @@ type bpfMapSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfVariableSpecs struct {
-	EndAddr           *ebpf.VariableSpec `ebpf:"end_addr"`
-	Hex               *ebpf.VariableSpec `ebpf:"hex"`
-	MessageHeadersPos *ebpf.VariableSpec `ebpf:"message_headers_pos"`
-	MessageKeyPos     *ebpf.VariableSpec `ebpf:"message_key_pos"`
-	MessageTimePos    *ebpf.VariableSpec `ebpf:"message_time_pos"`
-	MessageTopicPos   *ebpf.VariableSpec `ebpf:"message_topic_pos"`
-	StartAddr         *ebpf.VariableSpec `ebpf:"start_addr"`
-	TotalCpus         *ebpf.VariableSpec `ebpf:"total_cpus"`
-	WriterTopicPos    *ebpf.VariableSpec `ebpf:"writer_topic_pos"`
+	BootClockSupported         *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
+	EndAddr                    *ebpf.VariableSpec `ebpf:"end_addr"`
+	Hex                        *ebpf.VariableSpec `ebpf:"hex"`
+	MessageHeadersPos          *ebpf.VariableSpec `ebpf:"message_headers_pos"`
+	MessageKeyPos              *ebpf.VariableSpec `ebpf:"message_key_pos"`
+	MessageTimePos             *ebpf.VariableSpec `ebpf:"message_time_pos"`
+	MessageTopicPos            *ebpf.VariableSpec `ebpf:"message_topic_pos"`
+	PartitionWriterMetaPos     *ebpf.VariableSpec `ebpf:"partition_writer_meta_pos"`
+	StartAddr                  *ebpf.VariableSpec `ebpf:"start_addr"`
+	TopicPartitionPartitionPos *ebpf.VariableSpec `ebpf:"topic_partition_partition_pos"`
+	TotalCpus                  *ebpf.VariableSpec `ebpf:"total_cpus"`
+	WriterTopicPos             *ebpf.VariableSpec `ebpf:"writer_topic_pos"`
 }
 
 // bpfObjects contains all objects after they have been loaded into the kernel.
@@ -166,29 +173,34 @@ func (m *bpfMaps) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfVariables struct {
-	EndAddr           *ebpf.Variable `ebpf:"end_addr"`
-	Hex               *ebpf.Variable `ebpf:"hex"`
-	MessageHeadersPos *ebpf.Variable `ebpf:"message_headers_pos"`
-	MessageKeyPos     *ebpf.Variable `ebpf:"message_key_pos"`
-	MessageTimePos    *ebpf.Variable `ebpf:"message_time_pos"`
-	MessageTopicPos   *ebpf.Variable `ebpf:"message_topic_pos"`
-	StartAddr         *ebpf.Variable `ebpf:"start_addr"`
-	TotalCpus         *ebpf.Variable `ebpf:"total_cpus"`
-	WriterTopicPos    *ebpf.Variable `ebpf:"writer_topic_pos"`
+	BootClockSupported         *ebpf.Variable `ebpf:"boot_clock_supported"`
+	EndAddr                    *ebpf.Variable `ebpf:"end_addr"`
+	Hex                        *ebpf.Variable `ebpf:"hex"`
+	MessageHeadersPos          *ebpf.Variable `ebpf:"message_headers_pos"`
+	MessageKeyPos              *ebpf.Variable `ebpf:"message_key_pos"`
+	MessageTimePos             *ebpf.Variable `ebpf:"message_time_pos"`
+	MessageTopicPos            *ebpf.Variable `ebpf:"message_topic_pos"`
+	PartitionWriterMetaPos     *ebpf.Variable `ebpf:"partition_writer_meta_pos"`
+	StartAddr                  *ebpf.Variable `ebpf:"start_addr"`
+	TopicPartitionPartitionPos *ebpf.Variable `ebpf:"topic_partition_partition_pos"`
+	TotalCpus                  *ebpf.Variable `ebpf:"total_cpus"`
+	WriterTopicPos             *ebpf.Variable `ebpf:"writer_topic_pos"`
 }
 
 // bpfPrograms contains all programs after they have been loaded into the kernel.
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfPrograms struct {
-	UprobeWriteMessages        *ebpf.Program `ebpf:"uprobe_WriteMessages"`
-	UprobeWriteMessagesReturns *ebpf.Program `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobeWriteMessages                *ebpf.Program `ebpf:"uprobe_WriteMessages"`
+	UprobeWriteMessagesReturns         *ebpf.Program `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobePartitionWriterWriteMessages *ebpf.Program `ebpf:"uprobe_partitionWriter_writeMessages"`
 }
 
 func (p *bpfPrograms) Close() error {
 	return _BpfClose(
 		p.UprobeWriteMessages,
 		p.UprobeWriteMessagesReturns,
+		p.UprobePartitionWriterWriteMessages,
 	)
 }
 
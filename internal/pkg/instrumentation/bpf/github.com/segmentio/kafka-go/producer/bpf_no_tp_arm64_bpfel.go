@@ -17,15 +17,18 @@ type bpf_no_tpKafkaRequestT struct {
 	_         structs.HostLayout
 	StartTime uint64
 	EndTime   uint64
+	Sc        bpf_no_tpSpanContext
 	Psc       bpf_no_tpSpanContext
-	Msgs      [10]struct {
-		_     structs.HostLayout
-		Sc    bpf_no_tpSpanContext
-		Topic [256]int8
-		Key   [256]int8
+	Msgs      [32]struct {
+		_         structs.HostLayout
+		Sc        bpf_no_tpSpanContext
+		Topic     [256]int8
+		Key       [256]int8
+		Partition int64
 	}
 	GlobalTopic   [256]int8
 	ValidMessages uint64
+	TotalMessages uint64
 }
 
 type bpf_no_tpSliceArrayBuff struct {
@@ -83,8 +86,9 @@ type bpf_no_tpSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpf_no_tpProgramSpecs struct {
-	UprobeWriteMessages        *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages"`
-	UprobeWriteMessagesReturns *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobeWriteMessages                *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages"`
+	UprobeWriteMessagesReturns         *ebpf.ProgramSpec `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobePartitionWriterWriteMessages *ebpf.ProgramSpec `ebpf:"uprobe_partitionWriter_writeMessages"`
 }
 
 // bpf_no_tpMapSpecs contains maps before they are loaded into the kernel.
@@ -106,15 +110,18 @@ type bpf_no_tpMapSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpf_no_tpVariableSpecs struct {
-	EndAddr           *ebpf.VariableSpec `ebpf:"end_addr"`
-	Hex               *ebpf.VariableSpec `ebpf:"hex"`
-	MessageHeadersPos *ebpf.VariableSpec `ebpf:"message_headers_pos"`
-	MessageKeyPos     *ebpf.VariableSpec `ebpf:"message_key_pos"`
-	MessageTimePos    *ebpf.VariableSpec `ebpf:"message_time_pos"`
-	MessageTopicPos   *ebpf.VariableSpec `ebpf:"message_topic_pos"`
-	StartAddr         *ebpf.VariableSpec `ebpf:"start_addr"`
-	TotalCpus         *ebpf.VariableSpec `ebpf:"total_cpus"`
-	WriterTopicPos    *ebpf.VariableSpec `ebpf:"writer_topic_pos"`
+	BootClockSupported         *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
+	EndAddr                    *ebpf.VariableSpec `ebpf:"end_addr"`
+	Hex                        *ebpf.VariableSpec `ebpf:"hex"`
+	MessageHeadersPos          *ebpf.VariableSpec `ebpf:"message_headers_pos"`
+	MessageKeyPos              *ebpf.VariableSpec `ebpf:"message_key_pos"`
+	MessageTimePos             *ebpf.VariableSpec `ebpf:"message_time_pos"`
+	MessageTopicPos            *ebpf.VariableSpec `ebpf:"message_topic_pos"`
+	PartitionWriterMetaPos     *ebpf.VariableSpec `ebpf:"partition_writer_meta_pos"`
+	StartAddr                  *ebpf.VariableSpec `ebpf:"start_addr"`
+	TopicPartitionPartitionPos *ebpf.VariableSpec `ebpf:"topic_partition_partition_pos"`
+	TotalCpus                  *ebpf.VariableSpec `ebpf:"total_cpus"`
+	WriterTopicPos             *ebpf.VariableSpec `ebpf:"writer_topic_pos"`
 }
 
 // bpf_no_tpObjects contains all objects after they have been loaded into the kernel.
@@ -166,29 +173,34 @@ func (m *bpf_no_tpMaps) Close() error {
 //
 // It can be passed to loadBpf_no_tpObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpf_no_tpVariables struct {
-	EndAddr           *ebpf.Variable `ebpf:"end_addr"`
-	Hex               *ebpf.Variable `ebpf:"hex"`
-	MessageHeadersPos *ebpf.Variable `ebpf:"message_headers_pos"`
-	MessageKeyPos     *ebpf.Variable `ebpf:"message_key_pos"`
-	MessageTimePos    *ebpf.Variable `ebpf:"message_time_pos"`
-	MessageTopicPos   *ebpf.Variable `ebpf:"message_topic_pos"`
-	StartAddr         *ebpf.Variable `ebpf:"start_addr"`
-	TotalCpus         *ebpf.Variable `ebpf:"total_cpus"`
-	WriterTopicPos    *ebpf.Variable `ebpf:"writer_topic_pos"`
+	BootClockSupported         *ebpf.Variable `ebpf:"boot_clock_supported"`
+	EndAddr                    *ebpf.Variable `ebpf:"end_addr"`
+	Hex                        *ebpf.Variable `ebpf:"hex"`
+	MessageHeadersPos          *ebpf.Variable `ebpf:"message_headers_pos"`
+	MessageKeyPos              *ebpf.Variable `ebpf:"message_key_pos"`
+	MessageTimePos             *ebpf.Variable `ebpf:"message_time_pos"`
+	MessageTopicPos            *ebpf.Variable `ebpf:"message_topic_pos"`
+	PartitionWriterMetaPos     *ebpf.Variable `ebpf:"partition_writer_meta_pos"`
+	StartAddr                  *ebpf.Variable `ebpf:"start_addr"`
+	TopicPartitionPartitionPos *ebpf.Variable `ebpf:"topic_partition_partition_pos"`
+	TotalCpus                  *ebpf.Variable `ebpf:"total_cpus"`
+	WriterTopicPos             *ebpf.Variable `ebpf:"writer_topic_pos"`
 }
 
 // bpf_no_tpPrograms contains all programs after they have been loaded into the kernel.
 //
 // It can be passed to loadBpf_no_tpObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpf_no_tpPrograms struct {
-	UprobeWriteMessages        *ebpf.Program `ebpf:"uprobe_WriteMessages"`
-	UprobeWriteMessagesReturns *ebpf.Program `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobeWriteMessages                *ebpf.Program `ebpf:"uprobe_WriteMessages"`
+	UprobeWriteMessagesReturns         *ebpf.Program `ebpf:"uprobe_WriteMessages_Returns"`
+	UprobePartitionWriterWriteMessages *ebpf.Program `ebpf:"uprobe_partitionWriter_writeMessages"`
 }
 
 func (p *bpf_no_tpPrograms) Close() error {
 	return _Bpf_no_tpClose(
 		p.UprobeWriteMessages,
 		p.UprobeWriteMessagesReturns,
+		p.UprobePartitionWriterWriteMessages,
 	)
 }
 
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"math"
 	"os"
+	"strconv"
 
 	"github.com/cilium/ebpf"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -34,7 +35,16 @@ const (
 	pkg = "github.com/segmentio/kafka-go"
 )
 
+// batchTruncatedKey is the attribute key set on the spans of the batches with
+// more messages than traced.
+const batchTruncatedKey = attribute.Key("messaging.kafka.batch.truncated")
+
 // New returns a new [probe.Probe].
+//
+// The batches of messages sent by WriteMessages are traced as a PRODUCER
+// "publish" span linked to a PRODUCER "create" span for each of their first 32
+// messages, children of the same parent. The context of the span of a message
+// is propagated in its traceparent header.
 func New(logger *slog.Logger, version string) probe.Probe {
 	id := probe.ID{
 		SpanKind:        trace.SpanKindProducer,
@@ -46,6 +56,7 @@ func New(logger *slog.Logger, version string) probe.Probe {
 			Logger: logger,
 			Consts: []probe.Const{
 				probe.AllocationConst{},
+				probe.BootClockConst{},
 				probe.StructFieldConst{
 					Key: "writer_topic_pos",
 					ID: structfield.NewID(
@@ -82,6 +93,24 @@ func New(logger *slog.Logger, version string) probe.Probe {
 						"Time",
 					),
 				},
+				probe.StructFieldConst{
+					Key: "partition_writer_meta_pos",
+					ID: structfield.NewID(
+						"github.com/segmentio/kafka-go",
+						"github.com/segmentio/kafka-go",
+						"partitionWriter",
+						"meta",
+					),
+				},
+				probe.StructFieldConst{
+					Key: "topic_partition_partition_pos",
+					ID: structfield.NewID(
+						"github.com/segmentio/kafka-go",
+						"github.com/segmentio/kafka-go",
+						"topicPartition",
+						"partition",
+					),
+				},
 			},
 			Uprobes: []*probe.Uprobe{
 				{
@@ -89,6 +118,11 @@ func New(logger *slog.Logger, version string) probe.Probe {
 					EntryProbe:  "uprobe_WriteMessages",
 					ReturnProbe: "uprobe_WriteMessages_Returns",
 				},
+				{
+					Sym:         "github.com/segmentio/kafka-go.(*partitionWriter).writeMessages",
+					EntryProbe:  "uprobe_partitionWriter_writeMessages",
+					FailureMode: probe.FailureModeIgnore,
+				},
 			},
 			SpecFn: verifyAndLoadBpf,
 		},
@@ -110,47 +144,44 @@ func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
 	return loadBpf()
 }
 
+// maxBatchSize is the maximum number of messages of a batch traced.
+const maxBatchSize = 32
+
 type messageAttributes struct {
 	SpanContext context.EBPFSpanContext
 	Topic       [256]byte
 	Key         [256]byte
+	// Partition is the partition the message is written to, -1 if it is not
+	// known.
+	Partition int64
 }
 
 // event represents a batch of kafka messages being sent.
 type event struct {
-	StartTime         uint64
-	EndTime           uint64
-	ParentSpanContext context.EBPFSpanContext
+	// The span context is the one of the batch.
+	context.BaseSpanProperties
 	// Message specific attributes
-	Messages [10]messageAttributes
+	Messages [maxBatchSize]messageAttributes
 	// Global topic for the batch
 	GlobalTopic [256]byte
 	// Number of valid messages in the batch
 	ValidMessages uint64
+	// Number of messages in the batch, more than ValidMessages if the batch
+	// is truncated.
+	TotalMessages uint64
 }
 
 func processFn(e *event) ptrace.SpanSlice {
 	globalTopic := unix.ByteSliceToString(e.GlobalTopic[:])
-
-	attrs := []attribute.KeyValue{semconv.MessagingSystemKafka, semconv.MessagingOperationTypeSend}
-	if len(globalTopic) > 0 {
-		attrs = append(attrs, semconv.MessagingDestinationName(globalTopic))
-	}
-
-	if e.ValidMessages > 0 {
-		e.ValidMessages = min(e.ValidMessages, math.MaxInt)
-		attrs = append(
-			attrs,
-			semconv.MessagingBatchMessageCount(int(e.ValidMessages)), // nolint: gosec  // Bounded.
-		)
-	}
-
-	traceID := pcommon.TraceID(e.Messages[0].SpanContext.TraceID)
+	traceID := pcommon.TraceID(e.SpanContext.TraceID)
+	validMessages := min(e.ValidMessages, uint64(len(e.Messages)))
 
 	spans := ptrace.NewSpanSlice()
+	batch := spans.AppendEmpty()
 
-	var msgTopic string
-	for i := uint64(0); i < e.ValidMessages; i++ {
+	// The batch has a destination if all its messages have the same topic.
+	batchTopic := globalTopic
+	for i := uint64(0); i < validMessages; i++ {
 		key := unix.ByteSliceToString(e.Messages[i].Key[:])
 		var msgAttrs []attribute.KeyValue
 		if len(key) > 0 {
@@ -158,17 +189,24 @@ func processFn(e *event) ptrace.SpanSlice {
 		}
 
 		// Topic is either the global topic or the message specific topic
+		msgTopic := globalTopic
 		if len(globalTopic) == 0 {
 			msgTopic = unix.ByteSliceToString(e.Messages[i].Topic[:])
-		} else {
-			msgTopic = globalTopic
+			if i == 0 {
+				batchTopic = msgTopic
+			} else if msgTopic != batchTopic {
+				batchTopic = ""
+			}
 		}
 
 		msgAttrs = append(msgAttrs, semconv.MessagingDestinationName(msgTopic))
-		msgAttrs = append(msgAttrs, attrs...)
+		if p := e.Messages[i].Partition; p >= 0 {
+			msgAttrs = append(msgAttrs, semconv.MessagingDestinationPartitionID(strconv.FormatInt(p, 10)))
+		}
+		msgAttrs = append(msgAttrs, semconv.MessagingSystemKafka, semconv.MessagingOperationTypeCreate)
 
 		span := spans.AppendEmpty()
-		span.SetName(kafkaProducerSpanName(msgTopic))
+		span.SetName(kafkaCreateSpanName(msgTopic))
 		span.SetKind(ptrace.SpanKindProducer)
 		span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
 		span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
@@ -181,11 +219,62 @@ func processFn(e *event) ptrace.SpanSlice {
 		}
 
 		pdataconv.Attributes(span.Attributes(), msgAttrs...)
+
+		link := batch.Links().AppendEmpty()
+		link.SetTraceID(traceID)
+		link.SetSpanID(pcommon.SpanID(e.Messages[i].SpanContext.SpanID))
+		link.SetFlags(uint32(e.Messages[i].SpanContext.TraceFlags))
+	}
+
+	truncated := e.TotalMessages > validMessages
+	if truncated && len(globalTopic) == 0 {
+		// The topics of the messages not traced are not known.
+		batchTopic = ""
+	}
+
+	attrs := []attribute.KeyValue{semconv.MessagingSystemKafka, semconv.MessagingOperationTypeSend}
+	if len(batchTopic) > 0 {
+		attrs = append(attrs, semconv.MessagingDestinationName(batchTopic))
+	}
+	if total := max(e.TotalMessages, validMessages); total > 0 {
+		total = min(total, math.MaxInt)
+		attrs = append(
+			attrs,
+			semconv.MessagingBatchMessageCount(int(total)), // nolint: gosec  // Bounded.
+		)
 	}
+	if truncated {
+		attrs = append(attrs, batchTruncatedKey.Bool(true))
+	}
+
+	batch.SetName(kafkaProducerSpanName(batchTopic))
+	batch.SetKind(ptrace.SpanKindProducer)
+	batch.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	batch.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	batch.SetTraceID(traceID)
+	batch.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	batch.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		batch.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(batch.Attributes(), attrs...)
 
 	return spans
 }
 
+// kafkaProducerSpanName returns the name of the span of a batch sent to
+// topic, or to multiple topics if topic is empty.
 func kafkaProducerSpanName(topic string) string {
+	if topic == "" {
+		return "publish"
+	}
 	return topic + " publish"
 }
+
+// kafkaCreateSpanName returns the name of the span of a message of a batch
+// sent to topic.
+func kafkaCreateSpanName(topic string) string {
+	return topic + " create"
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -27,67 +28,103 @@ func TestProbeConvertEvent(t *testing.T) {
 	endOffset := kernel.TimeToBootOffset(end)
 
 	traceID := trace.TraceID{1}
+	parentSpanID := trace.SpanID{3}
 
-	got := processFn(&event{
-		StartTime: startOffset,
-		EndTime:   endOffset,
-		Messages: [10]messageAttributes{
-			{
-				// topic1
-				Topic: [256]byte{0x74, 0x6f, 0x70, 0x69, 0x63, 0x31},
-				// key1
-				Key: [256]byte{0x6b, 0x65, 0x79, 0x31},
-				SpanContext: context.EBPFSpanContext{
-					TraceID: traceID,
-					SpanID:  trace.SpanID{1},
-				},
-			},
-			{
-				// topic2
-				Topic: [256]byte{0x74, 0x6f, 0x70, 0x69, 0x63, 0x32},
-				// key2
-				Key: [256]byte{0x6b, 0x65, 0x79, 0x32},
-				SpanContext: context.EBPFSpanContext{
-					TraceID: traceID,
-					SpanID:  trace.SpanID{2},
-				},
-			},
+	e := &event{
+		BaseSpanProperties: context.BaseSpanProperties{
+			StartTime:         startOffset,
+			EndTime:           endOffset,
+			SpanContext:       context.EBPFSpanContext{TraceID: traceID, SpanID: trace.SpanID{4}},
+			ParentSpanContext: context.EBPFSpanContext{TraceID: traceID, SpanID: parentSpanID},
 		},
 		ValidMessages: 2,
-	})
+		TotalMessages: 2,
+	}
+	e.Messages[0] = messageAttributes{
+		// topic1
+		Topic: [256]byte{0x74, 0x6f, 0x70, 0x69, 0x63, 0x31},
+		// key1
+		Key: [256]byte{0x6b, 0x65, 0x79, 0x31},
+		SpanContext: context.EBPFSpanContext{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+		},
+		Partition: 0,
+	}
+	e.Messages[1] = messageAttributes{
+		// topic2
+		Topic: [256]byte{0x74, 0x6f, 0x70, 0x69, 0x63, 0x32},
+		// key2
+		Key: [256]byte{0x6b, 0x65, 0x79, 0x32},
+		SpanContext: context.EBPFSpanContext{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{2},
+			TraceFlags: trace.FlagsSampled,
+		},
+		Partition: -1,
+	}
+	got := processFn(e)
 
 	want := func() ptrace.SpanSlice {
 		spans := ptrace.NewSpanSlice()
+		batch := spans.AppendEmpty()
+
 		span := spans.AppendEmpty()
-		span.SetName(kafkaProducerSpanName("topic1"))
+		span.SetName(kafkaCreateSpanName("topic1"))
 		span.SetKind(ptrace.SpanKindProducer)
 		span.SetStartTimestamp(kernel.BootOffsetToTimestamp(startOffset))
 		span.SetEndTimestamp(kernel.BootOffsetToTimestamp(endOffset))
 		span.SetTraceID(pcommon.TraceID(traceID))
 		span.SetSpanID(pcommon.SpanID{1})
+		span.SetParentSpanID(pcommon.SpanID(parentSpanID))
 		span.SetFlags(uint32(trace.FlagsSampled))
 		pdataconv.Attributes(
 			span.Attributes(),
 			semconv.MessagingKafkaMessageKey("key1"),
 			semconv.MessagingDestinationName("topic1"),
+			semconv.MessagingDestinationPartitionID("0"),
 			semconv.MessagingSystemKafka,
-			semconv.MessagingOperationTypeSend,
-			semconv.MessagingBatchMessageCount(2),
+			semconv.MessagingOperationTypeCreate,
 		)
+		link := batch.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID(traceID))
+		link.SetSpanID(pcommon.SpanID{1})
+		link.SetFlags(uint32(trace.FlagsSampled))
 
 		span = spans.AppendEmpty()
-		span.SetName(kafkaProducerSpanName("topic2"))
+		span.SetName(kafkaCreateSpanName("topic2"))
 		span.SetKind(ptrace.SpanKindProducer)
 		span.SetStartTimestamp(kernel.BootOffsetToTimestamp(startOffset))
 		span.SetEndTimestamp(kernel.BootOffsetToTimestamp(endOffset))
 		span.SetTraceID(pcommon.TraceID(traceID))
 		span.SetSpanID(pcommon.SpanID{2})
+		span.SetParentSpanID(pcommon.SpanID(parentSpanID))
 		span.SetFlags(uint32(trace.FlagsSampled))
 		pdataconv.Attributes(
 			span.Attributes(),
 			semconv.MessagingKafkaMessageKey("key2"),
 			semconv.MessagingDestinationName("topic2"),
 			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeCreate,
+		)
+		link = batch.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID(traceID))
+		link.SetSpanID(pcommon.SpanID{2})
+		link.SetFlags(uint32(trace.FlagsSampled))
+
+		// The messages have different topics, the batch has none.
+		batch.SetName(kafkaProducerSpanName(""))
+		batch.SetKind(ptrace.SpanKindProducer)
+		batch.SetStartTimestamp(kernel.BootOffsetToTimestamp(startOffset))
+		batch.SetEndTimestamp(kernel.BootOffsetToTimestamp(endOffset))
+		batch.SetTraceID(pcommon.TraceID(traceID))
+		batch.SetSpanID(pcommon.SpanID{4})
+		batch.SetParentSpanID(pcommon.SpanID(parentSpanID))
+		batch.SetFlags(uint32(trace.FlagsSampled))
+		pdataconv.Attributes(
+			batch.Attributes(),
+			semconv.MessagingSystemKafka,
 			semconv.MessagingOperationTypeSend,
 			semconv.MessagingBatchMessageCount(2),
 		)
@@ -96,3 +133,42 @@ func TestProbeConvertEvent(t *testing.T) {
 	}()
 	assert.Equal(t, want, got)
 }
+
+func TestProbeConvertTruncatedEvent(t *testing.T) {
+	e := &event{
+		BaseSpanProperties: context.BaseSpanProperties{
+			SpanContext: context.EBPFSpanContext{SpanID: trace.SpanID{1}},
+		},
+		ValidMessages: maxBatchSize,
+		TotalMessages: 100,
+	}
+	copy(e.GlobalTopic[:], "orders")
+	for i := range e.Messages {
+		e.Messages[i].SpanContext.SpanID = trace.SpanID{byte(i + 2)}
+		e.Messages[i].Partition = int64(i % 3)
+	}
+
+	spans := processFn(e)
+	require.Equal(t, maxBatchSize+1, spans.Len())
+
+	batch := spans.At(0)
+	assert.Equal(t, "orders publish", batch.Name())
+	assert.Equal(t, maxBatchSize, batch.Links().Len())
+	assert.Equal(t, map[string]any{
+		"messaging.system":                "kafka",
+		"messaging.operation.type":        "send",
+		"messaging.destination.name":      "orders",
+		"messaging.batch.message_count":   int64(100),
+		"messaging.kafka.batch.truncated": true,
+	}, batch.Attributes().AsRaw())
+
+	msg := spans.At(maxBatchSize)
+	assert.Equal(t, "orders create", msg.Name())
+	assert.Equal(t, pcommon.SpanID{maxBatchSize + 1}, msg.SpanID())
+	assert.Equal(t, map[string]any{
+		"messaging.destination.name":         "orders",
+		"messaging.destination.partition.id": "1",
+		"messaging.system":                   "kafka",
+		"messaging.operation.type":           "create",
+	}, msg.Attributes().AsRaw())
+}
@@ -0,0 +1,256 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "trace/span_context.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+#include "uprobe.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define METHOD_MAX_LEN 8
+#define PATH_MAX_LEN 128
+#define HOST_MAX_LEN 128
+#define MAX_CONCURRENT 50
+
+struct fasthttp_client_span_t
+{
+    BASE_SPAN_PROPERTIES
+    u64 status_code;
+    char method[METHOD_MAX_LEN];
+    // The request URI, it does not contain the scheme and host.
+    char path[PATH_MAX_LEN];
+    char host[HOST_MAX_LEN];
+    bool is_tls;
+    bool has_error;
+    u8 padding[6];
+};
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct fasthttp_client_span_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} fasthttp_client_events SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct fasthttp_client_span_t));
+    __uint(max_entries, 1);
+} fasthttp_client_storage_map SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);   // the goroutine
+    __type(value, void *); // the *Response of the request
+    __uint(max_entries, MAX_CONCURRENT);
+} fasthttp_client_responses SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);   // the goroutine
+    __type(value, void *); // the *bufio.Writer the request header is written to
+    __uint(max_entries, MAX_CONCURRENT);
+} fasthttp_client_writers SEC(".maps");
+
+// Injected in init
+volatile const u64 host_client_is_tls_pos;
+volatile const u64 response_header_pos;
+volatile const u64 request_header_method_pos;
+volatile const u64 request_header_request_uri_pos;
+volatile const u64 request_header_host_pos;
+volatile const u64 response_header_status_code_pos;
+volatile const u64 io_writer_buf_ptr_pos;
+volatile const u64 io_writer_n_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *HostClient) Do(req *Request, resp *Response) error
+//
+// The fasthttp.Client dispatches all its requests to a HostClient.
+SEC("uprobe/HostClient_Do")
+int uprobe_HostClient_Do(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void *span_ptr = bpf_map_lookup_elem(&fasthttp_client_events, &key);
+    if (span_ptr != NULL) {
+        bpf_printk("fasthttp:client:Do: already tracked with the current goroutine");
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct fasthttp_client_span_t *span = bpf_map_lookup_elem(&fasthttp_client_storage_map, &zero);
+    if (span == NULL) {
+        bpf_printk("fasthttp:client:Do: failed to get span storage");
+        return 0;
+    }
+    __builtin_memset(span, 0, sizeof(*span));
+    span->start_time = get_time_ns();
+
+    // fasthttp requests do not hold a context.Context, the span is always a
+    // root span.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &span->psc,
+        .sc = &span->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    void *client_ptr = get_argument(ctx, 1);
+    bpf_probe_read_user(&span->is_tls, sizeof(span->is_tls), (void *)(client_ptr + host_client_is_tls_pos));
+
+    void *resp_ptr = get_argument(ctx, 3);
+    bpf_map_update_elem(&fasthttp_client_responses, &key, &resp_ptr, 0);
+    bpf_map_update_elem(&fasthttp_client_events, &key, span, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (c *HostClient) Do(req *Request, resp *Response) error
+SEC("uprobe/HostClient_Do")
+int uprobe_HostClient_Do_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct fasthttp_client_span_t *span = bpf_map_lookup_elem(&fasthttp_client_events, &key);
+    if (span == NULL) {
+        return 0;
+    }
+    span->end_time = get_time_ns();
+
+    // The type of the returned error interface, NULL for a nil error.
+    void *err_type = get_argument(ctx, 1);
+    span->has_error = err_type != NULL;
+
+    void **resp_ptr = bpf_map_lookup_elem(&fasthttp_client_responses, &key);
+    if (resp_ptr != NULL && *resp_ptr != NULL && !span->has_error) {
+        void *resp_header_ptr = *resp_ptr + response_header_pos;
+        bpf_probe_read_user(&span->status_code, sizeof(span->status_code), (void *)(resp_header_ptr + response_header_status_code_pos));
+    }
+
+    output_span_event(ctx, span, sizeof(*span), &span->sc);
+    bpf_map_delete_elem(&fasthttp_client_responses, &key);
+    bpf_map_delete_elem(&fasthttp_client_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (h *RequestHeader) Write(w *bufio.Writer) error
+//
+// The header is written once the request URI and host were resolved by
+// Request.Write.
+SEC("uprobe/RequestHeader_Write")
+int uprobe_RequestHeader_Write(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct fasthttp_client_span_t *span = bpf_map_lookup_elem(&fasthttp_client_events, &key);
+    if (span == NULL) {
+        return 0;
+    }
+
+    void *req_header_ptr = get_argument(ctx, 1);
+    if (!get_go_string_from_user_ptr(req_header_ptr + request_header_method_pos, span->method, sizeof(span->method))) {
+        // fasthttp leaves the method unset for GET requests.
+        __builtin_memset(span->method, 0, sizeof(span->method));
+    }
+    if (!get_go_string_from_user_ptr(req_header_ptr + request_header_request_uri_pos, span->path, sizeof(span->path))) {
+        bpf_printk("fasthttp:client:RequestHeader_Write: failed to read request URI");
+    }
+    if (!get_go_string_from_user_ptr(req_header_ptr + request_header_host_pos, span->host, sizeof(span->host))) {
+        bpf_printk("fasthttp:client:RequestHeader_Write: failed to read host");
+    }
+
+#ifndef NO_HEADER_PROPAGATION
+    void *writer_ptr = get_argument(ctx, 2);
+    bpf_map_update_elem(&fasthttp_client_writers, &key, &writer_ptr, 0);
+#endif
+    return 0;
+}
+
+#ifndef NO_HEADER_PROPAGATION
+// This instrumentation attaches uretprobe to the following function:
+// func (h *RequestHeader) Write(w *bufio.Writer) error
+//
+// The traceparent header is inserted before the blank line ending the header
+// that was just written to the buffer of the writer.
+SEC("uprobe/RequestHeader_Write")
+int uprobe_RequestHeader_Write_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void **writer_ptr_ptr = bpf_map_lookup_elem(&fasthttp_client_writers, &key);
+    if (writer_ptr_ptr == NULL) {
+        return 0;
+    }
+    void *io_writer_ptr = *writer_ptr_ptr;
+    bpf_map_delete_elem(&fasthttp_client_writers, &key);
+
+    struct fasthttp_client_span_t *span = bpf_map_lookup_elem(&fasthttp_client_events, &key);
+    if (span == NULL || io_writer_ptr == NULL) {
+        return 0;
+    }
+
+    void *buf_ptr = 0;
+    bpf_probe_read_user(&buf_ptr, sizeof(buf_ptr), (void *)(io_writer_ptr + io_writer_buf_ptr_pos)); // grab buf ptr
+    if (!buf_ptr) {
+        bpf_printk("fasthttp:client:RequestHeader_Write_Returns: failed to get buf from io writer");
+        return 0;
+    }
+
+    s64 size = 0;
+    if (bpf_probe_read_user(&size, sizeof(s64), (void *)(io_writer_ptr + io_writer_buf_ptr_pos + offsetof(struct go_slice, cap)))) { // grab capacity
+        bpf_printk("fasthttp:client:RequestHeader_Write_Returns: failed to get size from io writer");
+        return 0;
+    }
+
+    s64 len = 0;
+    if (bpf_probe_read_user(&len, sizeof(s64), (void *)(io_writer_ptr + io_writer_n_pos))) { // grab len
+        bpf_printk("fasthttp:client:RequestHeader_Write_Returns: failed to get len from io writer");
+        return 0;
+    }
+
+    // The header is only modified if its end is still buffered. It is not
+    // when it was larger than the buffer and written to the connection.
+    char end[4];
+    if (len < 4 || bpf_probe_read_user(end, sizeof(end), buf_ptr + ((len - 4) & 0x0ffff))) {
+        return 0;
+    }
+    if (end[0] != '\r' || end[1] != '\n' || end[2] != '\r' || end[3] != '\n') {
+        return 0;
+    }
+
+    // Overwrite the blank line with "Traceparent: <value>\r\n\r\n".
+    len -= 2;
+    if (len < (size - W3C_VAL_LENGTH - W3C_KEY_LENGTH - 6)) { // 6 = strlen(":_") + 2 * strlen("\r\n")
+        char tp_str[W3C_KEY_LENGTH + 2 + W3C_VAL_LENGTH + 4] = "Traceparent: ";
+        char crlf[4] = "\r\n\r\n";
+        char tp[W3C_VAL_LENGTH];
+        span_context_to_w3c_string(&span->sc, tp);
+        __builtin_memcpy(&tp_str[W3C_KEY_LENGTH + 2], tp, sizeof(tp));
+        __builtin_memcpy(&tp_str[W3C_KEY_LENGTH + 2 + W3C_VAL_LENGTH], crlf, sizeof(crlf));
+        if (bpf_probe_write_user(buf_ptr + (len & 0x0ffff), tp_str, sizeof(tp_str))) {
+            bpf_printk("fasthttp:client:RequestHeader_Write_Returns: failed to write trace parent in buffer");
+            return 0;
+        }
+        len += sizeof(tp_str);
+
+        if (bpf_probe_write_user((void *)(io_writer_ptr + io_writer_n_pos), &len, sizeof(len))) {
+            bpf_printk("fasthttp:client:RequestHeader_Write_Returns: failed to change io writer n");
+        }
+    }
+    return 0;
+}
+#else
+// Not used at all, empty stub needed to ensure both versions of the bpf program are
+// able to compile with bpf2go. The userspace code will avoid loading the probe if
+// context propagation is not enabled.
+SEC("uprobe/RequestHeader_Write")
+int uprobe_RequestHeader_Write_Returns(struct pt_regs *ctx) {
+    return 0;
+}
+#endif
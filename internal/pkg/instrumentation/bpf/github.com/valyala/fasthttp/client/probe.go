@@ -0,0 +1,203 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package client provides an instrumentation probe for
+// [github.com/valyala/fasthttp] clients.
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/cilium/ebpf"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf_no_tp ./bpf/probe.bpf.c -- -DNO_HEADER_PROPAGATION
+
+// pkg is the package being instrumented.
+const pkg = "github.com/valyala/fasthttp"
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	writeProbe := &probe.Uprobe{
+		Sym:        pkg + ".(*RequestHeader).Write",
+		EntryProbe: "uprobe_RequestHeader_Write",
+		// We mark this probe as dependent on HostClient.Do, so it is not
+		// enabled for executables writing request headers without a client.
+		DependsOn: []string{pkg + ".(*HostClient).Do"},
+	}
+	// If the kernel supports context propagation, we enable the
+	// probe which writes the data in the outgoing buffer.
+	if kernel.SupportsContextPropagation() {
+		writeProbe.ReturnProbe = "uprobe_RequestHeader_Write_Returns"
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "host_client_is_tls_pos",
+					ID:  structfield.NewID(pkg, pkg, "HostClient", "IsTLS"),
+				},
+				probe.StructFieldConst{
+					Key: "response_header_pos",
+					ID:  structfield.NewID(pkg, pkg, "Response", "Header"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_method_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestHeader", "method"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_request_uri_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestHeader", "requestURI"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_host_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestHeader", "host"),
+				},
+				probe.StructFieldConst{
+					Key: "response_header_status_code_pos",
+					ID:  structfield.NewID(pkg, pkg, "ResponseHeader", "statusCode"),
+				},
+				probe.StructFieldConst{
+					Key: "io_writer_buf_ptr_pos",
+					ID:  structfield.NewID("std", "bufio", "Writer", "buf"),
+				},
+				probe.StructFieldConst{
+					Key: "io_writer_n_pos",
+					ID:  structfield.NewID("std", "bufio", "Writer", "n"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         pkg + ".(*HostClient).Do",
+					EntryProbe:  "uprobe_HostClient_Do",
+					ReturnProbe: "uprobe_HostClient_Do_Returns",
+				},
+				writeProbe,
+			},
+			SpecFn: verifyAndLoadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+func verifyAndLoadBpf() (*ebpf.CollectionSpec, error) {
+	if !kernel.SupportsContextPropagation() {
+		fmt.Fprintf(
+			os.Stderr,
+			"the Linux Kernel doesn't support context propagation, please check if the kernel is in lockdown mode (/sys/kernel/security/lockdown)",
+		)
+		return loadBpf_no_tp()
+	}
+
+	return loadBpf()
+}
+
+// event represents an event in a fasthttp client during an HTTP
+// request-response.
+type event struct {
+	context.BaseSpanProperties
+	StatusCode uint64
+	Method     [8]byte
+	Path       [128]byte
+	Host       [128]byte
+	IsTLS      uint8
+	HasError   uint8
+	_          [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	method := unix.ByteSliceToString(e.Method[:])
+	if method == "" {
+		// fasthttp leaves the method unset for GET requests.
+		method = "GET"
+	}
+	path := unix.ByteSliceToString(e.Path[:])
+	if path == "" {
+		path = "/"
+	}
+	host := unix.ByteSliceToString(e.Host[:])
+
+	scheme, defaultPort := "http", 80
+	if e.IsTLS != 0 {
+		scheme, defaultPort = "https", 443
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc9110.html#name-status-codes
+	const maxStatus = 599
+	if e.StatusCode > maxStatus {
+		e.StatusCode = 0
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		// The request URI holds the path and query of the request.
+		semconv.URLFull(scheme + "://" + host + path),
+	}
+	if e.StatusCode > 0 {
+		attrs = append(attrs, semconv.HTTPResponseStatusCodeKey.Int(
+			int(e.StatusCode),
+		)) // nolint: gosec  // Bound checked.
+	}
+
+	server := netattr.ParseHostPort(host)
+	if server.Host != "" && server.Port == 0 {
+		// The port is omitted from the Host header for the scheme default.
+		server.Port = defaultPort
+	}
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(method)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	switch {
+	case e.HasError != 0:
+		span.Status().SetCode(ptrace.StatusCodeError)
+	case e.StatusCode >= 400:
+		// https://opentelemetry.io/docs/specs/semconv/http/http-spans/#status
+		attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.FormatUint(e.StatusCode, 10)))
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
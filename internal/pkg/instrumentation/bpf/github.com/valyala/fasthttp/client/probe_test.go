@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProbeConvertEvent(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+
+	newEvent := func(method, path, host string, status uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			StatusCode:         status,
+		}
+		copy(e.Method[:], method)
+		copy(e.Path[:], path)
+		copy(e.Host[:], host)
+		return e
+	}
+
+	testCases := []struct {
+		name     string
+		event    *event
+		expected ptrace.SpanSlice
+	}{
+		{
+			name:  "basic client test",
+			event: newEvent("POST", "/foo/bar?baz=1", "localhost:8080", 201),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("POST", ptrace.StatusCodeUnset)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("POST"),
+					semconv.URLFull("http://localhost:8080/foo/bar?baz=1"),
+					semconv.HTTPResponseStatusCodeKey.Int(201),
+					semconv.ServerAddress("localhost"),
+					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
+				)
+				return spans
+			}(),
+		},
+		{
+			// fasthttp leaves the method unset for GET requests.
+			name: "defaults",
+			event: func() *event {
+				e := newEvent("", "", "example.com", 200)
+				e.IsTLS = 1
+				return e
+			}(),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("GET", ptrace.StatusCodeUnset)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLFull("https://example.com/"),
+					semconv.HTTPResponseStatusCodeKey.Int(200),
+					semconv.ServerAddress("example.com"),
+					semconv.ServerPort(443),
+					semconv.NetworkTransportTCP,
+				)
+				return spans
+			}(),
+		},
+		{
+			name:  "client statuscode 400 sets span.Status",
+			event: newEvent("GET", "/", "localhost", 400),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("GET", ptrace.StatusCodeError)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLFull("http://localhost/"),
+					semconv.HTTPResponseStatusCodeKey.Int(400),
+					semconv.ServerAddress("localhost"),
+					semconv.ServerPort(80),
+					semconv.NetworkTransportTCP,
+					semconv.ErrorTypeKey.String("400"),
+				)
+				return spans
+			}(),
+		},
+		{
+			name: "client error sets span.Status",
+			event: func() *event {
+				e := newEvent("GET", "/", "localhost:8080", 0)
+				e.HasError = 1
+				return e
+			}(),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("GET", ptrace.StatusCodeError)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLFull("http://localhost:8080/"),
+					semconv.ServerAddress("localhost"),
+					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
+				)
+				return spans
+			}(),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := processFn(tt.event)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
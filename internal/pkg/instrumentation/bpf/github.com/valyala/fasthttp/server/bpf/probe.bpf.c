@@ -0,0 +1,260 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "go_context.h"
+#include "go_net.h"
+#include "go_types.h"
+#include "trace/span_context.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+#include "trace/tracestate.h"
+#include "uprobe.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define METHOD_MAX_LEN 8
+#define PATH_MAX_LEN 128
+#define HOST_MAX_LEN 128
+#define MAX_CONCURRENT 50
+#define MAX_HEADERS 32
+
+struct fasthttp_server_span_t
+{
+    BASE_SPAN_PROPERTIES
+    u64 status_code;
+    char method[METHOD_MAX_LEN];
+    // The request URI, the query is removed in user space.
+    char path[PATH_MAX_LEN];
+    char host[HOST_MAX_LEN];
+    net_addr_t peer_addr;
+    struct tracestate tracestate;
+};
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct fasthttp_server_span_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} fasthttp_server_events SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct fasthttp_server_span_t));
+    __uint(max_entries, 1);
+} fasthttp_server_storage_map SEC(".maps");
+
+// A header of the request as stored by fasthttp.
+//
+// type argsKV struct {
+//     key     []byte
+//     value   []byte
+//     noValue bool
+// }
+struct args_kv
+{
+    struct go_slice key;
+    struct go_slice value;
+    bool no_value;
+};
+
+// Injected in init
+volatile const u64 request_ctx_request_pos;
+volatile const u64 request_ctx_response_pos;
+volatile const u64 request_ctx_conn_pos;
+volatile const u64 request_header_pos;
+volatile const u64 response_header_pos;
+volatile const u64 request_header_method_pos;
+volatile const u64 request_header_request_uri_pos;
+volatile const u64 request_header_host_pos;
+volatile const u64 request_header_h_pos;
+volatile const u64 request_header_header_pos;
+volatile const u64 header_h_pos;
+volatile const u64 response_header_status_code_pos;
+volatile const u64 conn_fd_pos;
+volatile const u64 net_fd_raddr_pos;
+
+// A flag indicating whether the common fields of the request and response
+// headers are in an embedded header struct (fasthttp >= 1.64.0).
+volatile const bool header_embedded;
+
+// Returns the address of the headers slice of the RequestHeader.
+static __always_inline void *request_headers_ptr(void *req_header_ptr) {
+    if (header_embedded) {
+        return req_header_ptr + request_header_header_pos + header_h_pos;
+    }
+    return req_header_ptr + request_header_h_pos;
+}
+
+// Reads the traceparent and tracestate headers of the request into the
+// parent span context and tracestate of the span.
+// Returns 0 if a valid traceparent header was found, -1 otherwise.
+static __always_inline long extract_context_from_req_headers(void *req_header_ptr, struct fasthttp_server_span_t *span) {
+    struct go_slice headers = {0};
+    long res = bpf_probe_read_user(&headers, sizeof(headers), request_headers_ptr(req_header_ptr));
+    if (res != 0) {
+        return -1;
+    }
+
+    bool found_traceparent = false;
+    for (s32 i = 0; i < MAX_HEADERS; i++) {
+        if (i >= headers.len) {
+            break;
+        }
+        struct args_kv kv = {0};
+        res = bpf_probe_read_user(&kv, sizeof(kv), (void *)(headers.array + (i * sizeof(kv))));
+        if (res != 0) {
+            break;
+        }
+        if (!found_traceparent && kv.key.len == W3C_KEY_LENGTH && kv.value.len == W3C_VAL_LENGTH) {
+            char key[W3C_KEY_LENGTH];
+            bpf_probe_read_user(key, sizeof(key), kv.key.array);
+            if (!bpf_memicmp(key, "traceparent", W3C_KEY_LENGTH)) {
+                char val[W3C_VAL_LENGTH];
+                bpf_probe_read_user(val, sizeof(val), kv.value.array);
+                w3c_string_to_span_context(val, &span->psc);
+                found_traceparent = true;
+                continue;
+            }
+        }
+        if (span->tracestate.len == 0 && kv.key.len == TRACESTATE_KEY_LENGTH) {
+            char key[TRACESTATE_KEY_LENGTH];
+            bpf_probe_read_user(key, sizeof(key), kv.key.array);
+            if (!bpf_memicmp(key, "tracestate", TRACESTATE_KEY_LENGTH)) {
+                // Malformed values are left empty and not propagated.
+                read_tracestate(kv.value.array, kv.value.len, &span->tracestate);
+            }
+        }
+    }
+
+    return found_traceparent ? 0 : -1;
+}
+
+// The parent span context is extracted from the headers before the span is
+// started. It is only used if it was found there.
+static __always_inline long get_parent_span_context(void *arg, struct span_context *parent_span_context) {
+    return is_span_context_valid(parent_span_context) ? 0 : -1;
+}
+
+// Reads the remote address of the connection serving the request into addr.
+// Only TCP connections are supported.
+static __always_inline long read_peer_addr(struct pt_regs *ctx, void *req_ctx_ptr, net_addr_t *addr) {
+    // The net.Conn of the request is expected to be a *net.TCPConn, which
+    // embeds a net.conn holding the *net.netFD of the connection.
+    void *conn_ptr = NULL;
+    long res = bpf_probe_read_user(&conn_ptr, sizeof(conn_ptr), get_go_interface_instance(req_ctx_ptr + request_ctx_conn_pos));
+    if (res != 0 || conn_ptr == NULL) {
+        return -1;
+    }
+
+    void *fd_ptr = NULL;
+    res = bpf_probe_read_user(&fd_ptr, sizeof(fd_ptr), (void *)(conn_ptr + conn_fd_pos));
+    if (res != 0 || fd_ptr == NULL) {
+        return -1;
+    }
+
+    void *raddr_ptr = NULL;
+    res = bpf_probe_read_user(&raddr_ptr, sizeof(raddr_ptr), get_go_interface_instance(fd_ptr + net_fd_raddr_pos));
+    if (res != 0 || raddr_ptr == NULL) {
+        return -1;
+    }
+
+    return get_tcp_net_addr_from_tcp_addr(ctx, addr, raddr_ptr);
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (req *Request) readLimitBody(r *bufio.Reader, maxBodySize int, getOnly, preParseMultipartForm bool) error
+// func (req *Request) readBodyStream(r *bufio.Reader, maxBodySize int, getOnly, preParseMultipartForm bool) error
+//
+// The server calls one of them once the request headers are read.
+SEC("uprobe/Request_readBody")
+int uprobe_Request_readBody(struct pt_regs *ctx) {
+    void *req_ptr = get_argument(ctx, 1);
+    if (req_ptr == NULL) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct fasthttp_server_span_t *stale = bpf_map_lookup_elem(&fasthttp_server_events, &key);
+    if (stale != NULL) {
+        // The previous request of the connection did not write a response
+        // (e.g. the connection was hijacked).
+        stop_tracking_span(&stale->sc, &stale->psc);
+        bpf_map_delete_elem(&fasthttp_server_events, &key);
+    }
+
+    u32 zero = 0;
+    struct fasthttp_server_span_t *span = bpf_map_lookup_elem(&fasthttp_server_storage_map, &zero);
+    if (span == NULL) {
+        bpf_printk("fasthttp:server:readBody: failed to get span storage");
+        return 0;
+    }
+    __builtin_memset(span, 0, sizeof(*span));
+    span->start_time = get_time_ns();
+
+    // The request is embedded in the RequestCtx, which is the context.Context
+    // passed to the request handler.
+    void *req_ctx_ptr = req_ptr - request_ctx_request_pos;
+    struct go_iface go_context = {0};
+    go_context.data = req_ctx_ptr;
+
+    extract_context_from_req_headers(req_ptr + request_header_pos, span);
+
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &span->psc,
+        .sc = &span->sc,
+        .get_parent_span_context_fn = get_parent_span_context,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    // The tracestate is only propagated along with a remote parent.
+    if (is_span_context_valid(&span->psc)) {
+        set_remote_tracestate(&span->sc, &span->tracestate);
+    } else {
+        span->tracestate.len = 0;
+    }
+
+    bpf_map_update_elem(&fasthttp_server_events, &key, span, 0);
+    start_tracking_span(req_ctx_ptr, &span->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func writeResponse(ctx *RequestCtx, w *bufio.Writer) error
+SEC("uprobe/writeResponse")
+int uprobe_writeResponse(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct fasthttp_server_span_t *span = bpf_map_lookup_elem(&fasthttp_server_events, &key);
+    if (span == NULL) {
+        return 0;
+    }
+    span->end_time = get_time_ns();
+
+    void *req_ctx_ptr = get_argument(ctx, 1);
+    if (req_ctx_ptr != NULL) {
+        void *req_header_ptr = req_ctx_ptr + request_ctx_request_pos + request_header_pos;
+        if (!get_go_string_from_user_ptr(req_header_ptr + request_header_method_pos, span->method, sizeof(span->method))) {
+            bpf_printk("fasthttp:server:writeResponse: failed to read method");
+        }
+        if (!get_go_string_from_user_ptr(req_header_ptr + request_header_request_uri_pos, span->path, sizeof(span->path))) {
+            bpf_printk("fasthttp:server:writeResponse: failed to read request URI");
+        }
+        get_go_string_from_user_ptr(req_header_ptr + request_header_host_pos, span->host, sizeof(span->host));
+
+        void *resp_header_ptr = req_ctx_ptr + request_ctx_response_pos + response_header_pos;
+        bpf_probe_read_user(&span->status_code, sizeof(span->status_code), (void *)(resp_header_ptr + response_header_status_code_pos));
+
+        read_peer_addr(ctx, req_ctx_ptr, &span->peer_addr);
+    }
+
+    output_span_event(ctx, span, sizeof(*span), &span->sc);
+    stop_tracking_span(&span->sc, &span->psc);
+    bpf_map_delete_elem(&fasthttp_server_events, &key);
+    return 0;
+}
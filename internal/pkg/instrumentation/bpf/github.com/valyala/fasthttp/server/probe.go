@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package server provides an instrumentation probe for
+// [github.com/valyala/fasthttp] servers.
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "github.com/valyala/fasthttp"
+
+// headerEmbeddedVersion is the version the fields common to the request and
+// response headers were moved to a header struct embedded in both.
+var headerEmbeddedVersion = semver.New(1, 64, 0, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindServer,
+		InstrumentedPkg: pkg,
+	}
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "request_ctx_request_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestCtx", "Request"),
+				},
+				probe.StructFieldConst{
+					Key: "request_ctx_response_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestCtx", "Response"),
+				},
+				probe.StructFieldConst{
+					Key: "request_ctx_conn_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestCtx", "c"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_pos",
+					ID:  structfield.NewID(pkg, pkg, "Request", "Header"),
+				},
+				probe.StructFieldConst{
+					Key: "response_header_pos",
+					ID:  structfield.NewID(pkg, pkg, "Response", "Header"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_method_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestHeader", "method"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_request_uri_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestHeader", "requestURI"),
+				},
+				probe.StructFieldConst{
+					Key: "request_header_host_pos",
+					ID:  structfield.NewID(pkg, pkg, "RequestHeader", "host"),
+				},
+				probe.StructFieldConstMaxVersion{
+					StructField: probe.StructFieldConst{
+						Key: "request_header_h_pos",
+						ID:  structfield.NewID(pkg, pkg, "RequestHeader", "h"),
+					},
+					MaxVersion: headerEmbeddedVersion,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "request_header_header_pos",
+						ID:  structfield.NewID(pkg, pkg, "RequestHeader", "header"),
+					},
+					MinVersion: headerEmbeddedVersion,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "header_h_pos",
+						ID:  structfield.NewID(pkg, pkg, "header", "h"),
+					},
+					MinVersion: headerEmbeddedVersion,
+				},
+				probe.StructFieldConst{
+					Key: "response_header_status_code_pos",
+					ID:  structfield.NewID(pkg, pkg, "ResponseHeader", "statusCode"),
+				},
+				probe.StructFieldConst{
+					Key: "conn_fd_pos",
+					ID:  structfield.NewID("std", "net", "conn", "fd"),
+				},
+				probe.StructFieldConst{
+					Key: "net_fd_raddr_pos",
+					ID:  structfield.NewID("std", "net", "netFD", "raddr"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_IP_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "IP"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Port_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Port"),
+				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Zone_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Zone"),
+				},
+				headerEmbeddedConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:        pkg + ".(*Request).readLimitBody",
+					EntryProbe: "uprobe_Request_readBody",
+				},
+				{
+					// Used instead of readLimitBody when the server streams
+					// request bodies.
+					Sym:         pkg + ".(*Request).readBodyStream",
+					EntryProbe:  "uprobe_Request_readBody",
+					DependsOn:   []string{pkg + ".(*Request).readLimitBody"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:        pkg + ".writeResponse",
+					EntryProbe: "uprobe_writeResponse",
+					DependsOn:  []string{pkg + ".(*Request).readLimitBody"},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// headerEmbeddedConst is a Probe Const defining whether the headers of a
+// request are stored in the header struct embedded in the RequestHeader.
+type headerEmbeddedConst struct{}
+
+func (c headerEmbeddedConst) InjectOption(info *process.Info) (inject.Option, error) {
+	ver, ok := info.Modules[pkg]
+	if !ok {
+		return nil, fmt.Errorf("unknown module version: %s", pkg)
+	}
+	return inject.WithKeyValue("header_embedded", ver.GreaterThanEqual(headerEmbeddedVersion)), nil
+}
+
+// event represents an event in a fasthttp server during an HTTP
+// request-response.
+type event struct {
+	context.BaseSpanProperties
+	StatusCode uint64
+	Method     [8]byte
+	Path       [128]byte
+	Host       [128]byte
+	PeerAddr   netattr.NetAddr
+	TraceState context.TraceState
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	method := unix.ByteSliceToString(e.Method[:])
+	if method == "" {
+		// fasthttp leaves the method unset for GET requests.
+		method = "GET"
+	}
+	// The request URI is read, not the parsed path.
+	path, _, _ := strings.Cut(unix.ByteSliceToString(e.Path[:]), "?")
+
+	// https://www.rfc-editor.org/rfc/rfc9110.html#name-status-codes
+	const maxStatus = 599
+	switch {
+	case e.StatusCode == 0:
+		// fasthttp leaves the status code unset when the handler does not
+		// set one, it is then written as 200.
+		e.StatusCode = 200
+	case e.StatusCode > maxStatus:
+		e.StatusCode = 0
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLPath(path),
+		semconv.HTTPResponseStatusCodeKey.Int(
+			int(e.StatusCode),
+		), // nolint: gosec  // Bound checked.
+	}
+
+	peer := e.PeerAddr.Addr()
+	if peer.Host != "" {
+		// Forwarding headers are not read, the client is the peer.
+		attrs = append(attrs, semconv.ClientAddress(peer.Host))
+		if peer.Port > 0 {
+			attrs = append(attrs, semconv.ClientPort(peer.Port))
+		}
+	}
+	attrs = append(attrs, netattr.Attributes(
+		netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:])),
+		peer,
+	)...)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(method)
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+	span.TraceState().FromRaw(e.TraceState.String())
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	if e.StatusCode >= 500 && e.StatusCode < 600 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	return spans
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+func TestProbeConvertEvent(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindServer)
+
+	newEvent := func(method, path, host string, status uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			StatusCode:         status,
+			PeerAddr: netattr.NetAddr{
+				IP:    [16]uint8{127, 0, 0, 1},
+				IPLen: 4,
+				Port:  54321,
+			},
+		}
+		copy(e.Method[:], method)
+		copy(e.Path[:], path)
+		copy(e.Host[:], host)
+		return e
+	}
+
+	testCases := []struct {
+		name     string
+		event    *event
+		expected ptrace.SpanSlice
+	}{
+		{
+			name:  "basic server test",
+			event: newEvent("POST", "/foo/bar?baz=1", "localhost:8080", 201),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("POST", ptrace.StatusCodeUnset)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("POST"),
+					semconv.URLPath("/foo/bar"),
+					semconv.HTTPResponseStatusCodeKey.Int(201),
+					semconv.ClientAddress("127.0.0.1"),
+					semconv.ClientPort(54321),
+					semconv.NetworkPeerAddress("127.0.0.1"),
+					semconv.NetworkPeerPort(54321),
+					semconv.ServerAddress("localhost"),
+					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
+				)
+				return spans
+			}(),
+		},
+		{
+			// fasthttp leaves the method and status code unset for defaults.
+			name:  "defaults",
+			event: newEvent("", "/", "", 0),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("GET", ptrace.StatusCodeUnset)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLPath("/"),
+					semconv.HTTPResponseStatusCodeKey.Int(200),
+					semconv.ClientAddress("127.0.0.1"),
+					semconv.ClientPort(54321),
+					semconv.NetworkPeerAddress("127.0.0.1"),
+					semconv.NetworkPeerPort(54321),
+					semconv.NetworkTransportTCP,
+				)
+				return spans
+			}(),
+		},
+		{
+			name: "server statuscode 400 doesn't set span.Status",
+			event: func() *event {
+				e := newEvent("GET", "/", "", 400)
+				// Unknown peer address.
+				e.PeerAddr = netattr.NetAddr{}
+				return e
+			}(),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("GET", ptrace.StatusCodeUnset)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLPath("/"),
+					semconv.HTTPResponseStatusCodeKey.Int(400),
+				)
+				return spans
+			}(),
+		},
+		{
+			name:  "server statuscode 500 sets span.Status",
+			event: newEvent("GET", "/", "", 500),
+			expected: func() ptrace.SpanSlice {
+				spans := f.Spans("GET", ptrace.StatusCodeError)
+				pdataconv.Attributes(
+					spans.At(0).Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLPath("/"),
+					semconv.HTTPResponseStatusCodeKey.Int(500),
+					semconv.ClientAddress("127.0.0.1"),
+					semconv.ClientPort(54321),
+					semconv.NetworkPeerAddress("127.0.0.1"),
+					semconv.NetworkPeerPort(54321),
+					semconv.NetworkTransportTCP,
+				)
+				return spans
+			}(),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := processFn(tt.event)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestHeaderOffsets(t *testing.T) {
+	// The headers moved to the embedded header struct in 1.64.0.
+	for _, v := range []string{"1.20.0", "1.63.0"} {
+		ver := semver.MustParse(v)
+		id := structfield.NewID(pkg, pkg, "RequestHeader", "h")
+		off, ok := inject.GetOffset(id, ver)
+		if assert.True(t, ok, "offset of %s not known for %s", id, v) {
+			assert.True(t, off.Valid, "invalid offset of %s for %s", id, v)
+		}
+	}
+	for _, v := range []string{"1.64.0", "1.74.0"} {
+		ver := semver.MustParse(v)
+		for _, id := range []structfield.ID{
+			structfield.NewID(pkg, pkg, "RequestHeader", "header"),
+			structfield.NewID(pkg, pkg, "header", "h"),
+		} {
+			off, ok := inject.GetOffset(id, ver)
+			if assert.True(t, ok, "offset of %s not known for %s", id, v) {
+				assert.True(t, off.Valid, "invalid offset of %s for %s", id, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_PATH_SIZE 256
+#define MAX_CONCURRENT 50
+// The number of open transactions tracked. The least recently used ones, e.g.
+// transactions never closed, are evicted once it is reached.
+#define MAX_TRANSACTIONS 1024
+
+struct bbolt_tx_t {
+    BASE_SPAN_PROPERTIES
+    char path[MAX_PATH_SIZE];
+    // The time spent writing the dirty pages and the meta page of a committed
+    // transaction, syncing included, only valid if has_write is set.
+    u64 write_ns;
+    // The time spent syncing the database file, only valid if has_sync is set.
+    u64 sync_ns;
+    u8 writable;
+    u8 committed;
+    u8 has_write;
+    u8 has_sync;
+    u8 has_error;
+    u8 padding[3];
+};
+
+// Transactions being begun, committed, or rolled back, keyed by the goroutine
+// doing it.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct bbolt_tx_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} bbolt_events SEC(".maps");
+
+// Open transactions, keyed by the transaction.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct bbolt_tx_t);
+    __uint(max_entries, MAX_TRANSACTIONS);
+} bbolt_txs SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct bbolt_tx_t));
+    __uint(max_entries, 1);
+} bbolt_storage_map SEC(".maps");
+
+// Injected in init
+volatile const bool include_read_tx;
+
+volatile const u64 db_path_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) Begin(writable bool) (*Tx, error)
+SEC("uprobe/DB_Begin")
+int uprobe_DB_Begin(struct pt_regs *ctx) {
+    void *db = get_argument(ctx, 1);
+    u8 writable = (u64)get_argument(ctx, 2) & 0xff;
+    if (db == NULL || (!writable && !include_read_tx)) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&bbolt_events, &key) != NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_storage_map, &zero);
+    if (tx == NULL) {
+        bpf_printk("bbolt: transaction is NULL");
+        return 0;
+    }
+    __builtin_memset(tx, 0, sizeof(struct bbolt_tx_t));
+    tx->start_time = get_time_ns();
+    tx->writable = writable;
+    get_go_string_from_user_ptr(db + db_path_pos, tx->path, sizeof(tx->path));
+
+    // Transactions are not begun with a context, their spans are roots.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &tx->psc,
+        .sc = &tx->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&bbolt_events, &key, tx, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (db *DB) Begin(writable bool) (*Tx, error)
+SEC("uprobe/DB_Begin")
+int uprobe_DB_Begin_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_events, &key);
+    if (tx == NULL) {
+        return 0;
+    }
+
+    // No span is produced for transactions failing to begin.
+    void *tx_ptr = get_argument(ctx, 1);
+    if (tx_ptr != NULL) {
+        bpf_map_update_elem(&bbolt_txs, &tx_ptr, tx, 0);
+    }
+    bpf_map_delete_elem(&bbolt_events, &key);
+    return 0;
+}
+
+// Moves the open transaction receiving the call of the instrumented method to
+// the transactions being closed by the goroutine.
+static __always_inline int close_tx(struct pt_regs *ctx, u8 committed) {
+    void *tx_ptr = get_argument(ctx, 1);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_txs, &tx_ptr);
+    if (tx == NULL) {
+        return 0;
+    }
+    tx->committed = committed;
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&bbolt_events, &key, tx, 0);
+    bpf_map_delete_elem(&bbolt_txs, &tx_ptr);
+    return 0;
+}
+
+// Ends the span of the transaction closed by the goroutine, failed if the
+// instrumented method returned an error.
+static __always_inline int end_tx(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_events, &key);
+    if (tx == NULL) {
+        return 0;
+    }
+
+    if (get_argument(ctx, 1) != NULL) {
+        tx->has_error = 1;
+    }
+
+    tx->end_time = get_time_ns();
+    output_span_event(ctx, tx, sizeof(*tx), &tx->sc);
+    bpf_map_delete_elem(&bbolt_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tx *Tx) Commit() error
+SEC("uprobe/Tx_Commit")
+int uprobe_Tx_Commit(struct pt_regs *ctx) {
+    return close_tx(ctx, 1);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tx *Tx) Commit() error
+SEC("uprobe/Tx_Commit")
+int uprobe_Tx_Commit_Returns(struct pt_regs *ctx) {
+    return end_tx(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tx *Tx) Rollback() error
+SEC("uprobe/Tx_Rollback")
+int uprobe_Tx_Rollback(struct pt_regs *ctx) {
+    return close_tx(ctx, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (tx *Tx) Rollback() error
+SEC("uprobe/Tx_Rollback")
+int uprobe_Tx_Rollback_Returns(struct pt_regs *ctx) {
+    return end_tx(ctx);
+}
+
+// The durations of the phases of a commit are accumulated: the time a phase
+// is entered is subtracted from them, and the time it returns is added.
+
+// This instrumentation attaches uprobe to the following functions:
+// func (tx *Tx) write() error
+// func (tx *Tx) writeMeta() error
+SEC("uprobe/Tx_write")
+int uprobe_Tx_write(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_events, &key);
+    if (tx == NULL || !tx->committed) {
+        return 0;
+    }
+    tx->write_ns -= get_time_ns();
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (tx *Tx) write() error
+// func (tx *Tx) writeMeta() error
+SEC("uprobe/Tx_write")
+int uprobe_Tx_write_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_events, &key);
+    if (tx == NULL || !tx->committed) {
+        return 0;
+    }
+    tx->write_ns += get_time_ns();
+    tx->has_write = 1;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func fdatasync(db *DB) error
+SEC("uprobe/fdatasync")
+int uprobe_fdatasync(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_events, &key);
+    if (tx == NULL || !tx->committed) {
+        return 0;
+    }
+    tx->sync_ns -= get_time_ns();
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func fdatasync(db *DB) error
+SEC("uprobe/fdatasync")
+int uprobe_fdatasync_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct bbolt_tx_t *tx = bpf_map_lookup_elem(&bbolt_events, &key);
+    if (tx == NULL || !tx->committed) {
+        return 0;
+    }
+    tx->sync_ns += get_time_ns();
+    tx->has_sync = 1;
+    return 0;
+}
@@ -0,0 +1,221 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bbolt provides an instrumentation probe for the transactions of
+// embedded databases using the [go.etcd.io/bbolt] package.
+package bbolt
+
+import (
+	"log/slog"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "go.etcd.io/bbolt"
+
+const (
+	// pathKey is the attribute key of the path of the database file.
+	pathKey = attribute.Key("bbolt.path")
+	// writableKey is the attribute key of whether a transaction is writable.
+	writableKey = attribute.Key("bbolt.tx.writable")
+	// writeDurationKey is the attribute key of the time, in seconds, a commit
+	// spent writing the dirty pages and the meta page to the database file,
+	// syncing included.
+	writeDurationKey = attribute.Key("bbolt.commit.write.duration")
+	// syncDurationKey is the attribute key of the time, in seconds, a commit
+	// spent syncing the database file.
+	syncDurationKey = attribute.Key("bbolt.commit.sync.duration")
+)
+
+// dbSystemNameBbolt is the db.system.name attribute of bbolt. It is not
+// defined by the semantic conventions.
+var dbSystemNameBbolt = semconv.DBSystemNameKey.String("bbolt")
+
+// minVersion is the first release of the go.etcd.io/bbolt module.
+var minVersion = semver.New(1, 3, 0, "", "")
+
+// New returns a new [probe.Probe].
+//
+// Read-only transactions, e.g. the ones of DB.View, are frequent and
+// short-lived, they are not traced unless readTx is true.
+func New(logger *slog.Logger, version string, readTx bool) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	const begin = pkg + ".(*DB).Begin"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.KeyValConst{Key: "include_read_tx", Val: readTx},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "db_path_pos",
+						ID:  structfield.NewID(pkg, pkg, "DB", "path"),
+					},
+					MinVersion: minVersion,
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                begin,
+					EntryProbe:         "uprobe_DB_Begin",
+					ReturnProbe:        "uprobe_DB_Begin_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					// Not linked if only read-only transactions are used.
+					Sym:                pkg + ".(*Tx).Commit",
+					EntryProbe:         "uprobe_Tx_Commit",
+					ReturnProbe:        "uprobe_Tx_Commit_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{begin},
+				},
+				{
+					Sym:                pkg + ".(*Tx).Rollback",
+					EntryProbe:         "uprobe_Tx_Rollback",
+					ReturnProbe:        "uprobe_Tx_Rollback_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					DependsOn:          []string{begin},
+				},
+				// The phases of commits are only timed if they are not
+				// inlined.
+				{
+					Sym:                pkg + ".(*Tx).write",
+					EntryProbe:         "uprobe_Tx_write",
+					ReturnProbe:        "uprobe_Tx_write_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{begin},
+				},
+				{
+					Sym:                pkg + ".(*Tx).writeMeta",
+					EntryProbe:         "uprobe_Tx_write",
+					ReturnProbe:        "uprobe_Tx_write_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{begin},
+				},
+				{
+					Sym:                pkg + ".fdatasync",
+					EntryProbe:         "uprobe_fdatasync",
+					ReturnProbe:        "uprobe_fdatasync_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+					FailureMode:        probe.FailureModeIgnore,
+					DependsOn:          []string{begin},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a transaction committed or rolled back.
+type event struct {
+	context.BaseSpanProperties
+	Path [256]byte
+	// WriteNs is the time, in nanoseconds, spent writing to the database file
+	// by a commit, only valid if HasWrite is set.
+	WriteNs uint64
+	// SyncNs is the time, in nanoseconds, spent syncing the database file by
+	// a commit, only valid if HasSync is set.
+	SyncNs    uint64
+	Writable  uint8
+	Committed uint8
+	HasWrite  uint8
+	HasSync   uint8
+	HasError  uint8
+	_         [3]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	name := "rollback"
+	if e.Committed != 0 {
+		name = "commit"
+	}
+
+	attrs := []attribute.KeyValue{
+		dbSystemNameBbolt,
+		semconv.DBOperationName(name),
+		writableKey.Bool(e.Writable != 0),
+	}
+
+	if path := unix.ByteSliceToString(e.Path[:]); path != "" {
+		attrs = append(attrs, pathKey.String(path))
+		name += " " + filepath.Base(path)
+	}
+
+	if e.HasWrite != 0 {
+		attrs = append(attrs, writeDurationKey.Float64(seconds(e.WriteNs)))
+	}
+	if e.HasSync != 0 {
+		attrs = append(attrs, syncDurationKey.Float64(seconds(e.SyncNs)))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// seconds returns the duration of ns nanoseconds in seconds.
+func seconds(ns uint64) float64 {
+	return time.Duration(min(ns, math.MaxInt64)).Seconds() // nolint: gosec  // Bounded.
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bbolt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+
+	const path = "/var/lib/app/app.db"
+
+	newEvent := func(writable, committed, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.Path[:], path)
+		if writable {
+			e.Writable = 1
+		}
+		if committed {
+			e.Committed = 1
+		}
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	newCommit := func(hasError bool) *event {
+		e := newEvent(true, true, hasError)
+		e.WriteNs = uint64(300 * time.Millisecond)
+		e.SyncNs = uint64(200 * time.Millisecond)
+		e.HasWrite = 1
+		e.HasSync = 1
+		return e
+	}
+
+	newSpans := func(name string, code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+		return f.Spans(name, code, append([]attribute.KeyValue{dbSystemNameBbolt}, attrs...)...)
+	}
+
+	commit := []attribute.KeyValue{
+		semconv.DBOperationName("commit"),
+		writableKey.Bool(true),
+		pathKey.String(path),
+		writeDurationKey.Float64(0.3),
+		syncDurationKey.Float64(0.2),
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "commit",
+			event: newCommit(false),
+			want:  newSpans("commit app.db", ptrace.StatusCodeUnset, commit...),
+		},
+		{
+			name:  "commit error",
+			event: newCommit(true),
+			want:  newSpans("commit app.db", ptrace.StatusCodeError, commit...),
+		},
+		{
+			name:  "rollback",
+			event: newEvent(true, false, false),
+			want: newSpans(
+				"rollback app.db",
+				ptrace.StatusCodeUnset,
+				semconv.DBOperationName("rollback"),
+				writableKey.Bool(true),
+				pathKey.String(path),
+			),
+		},
+		{
+			name:  "read-only",
+			event: newEvent(false, false, false),
+			want: newSpans(
+				"rollback app.db",
+				ptrace.StatusCodeUnset,
+				semconv.DBOperationName("rollback"),
+				writableKey.Bool(false),
+				pathKey.String(path),
+			),
+		},
+		{
+			name: "no path",
+			event: func() *event {
+				e := newEvent(true, true, false)
+				e.Path = [256]byte{}
+				return e
+			}(),
+			want: newSpans(
+				"commit",
+				ptrace.StatusCodeUnset,
+				semconv.DBOperationName("commit"),
+				writableKey.Bool(true),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "grpc_client.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_KEY_SIZE 128
+#define MAX_CONCURRENT 50
+
+// The operations of the etcd KV service, they need to be kept in sync with
+// the operations of the probe.
+#define OPERATION_RANGE 1
+#define OPERATION_PUT 2
+#define OPERATION_DELETE_RANGE 3
+#define OPERATION_TXN 4
+
+struct etcd_request_t {
+    BASE_SPAN_PROPERTIES
+    // The keys are binary, their sizes are recorded. They are truncated to
+    // MAX_KEY_SIZE bytes.
+    char key[MAX_KEY_SIZE];
+    char range_end[MAX_KEY_SIZE];
+    u64 key_size;
+    u64 range_end_size;
+    u8 operation;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// Requests being sent, keyed by the goroutine sending them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct etcd_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} etcd_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct etcd_request_t));
+    __uint(max_entries, 1);
+} etcd_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 range_request_key_pos;
+volatile const u64 range_request_range_end_pos;
+volatile const u64 put_request_key_pos;
+volatile const u64 delete_range_request_key_pos;
+volatile const u64 delete_range_request_range_end_pos;
+
+// Reads the []byte at ptr into buf, truncated to MAX_KEY_SIZE bytes. Returns
+// the number of bytes read.
+static __always_inline u64 read_key(void *ptr, char *buf) {
+    struct go_slice key = {0};
+    if (bpf_probe_read_user(&key, sizeof(key), ptr) != 0 || key.array == NULL || key.len <= 0) {
+        return 0;
+    }
+    u64 size = key.len;
+    if (size > MAX_KEY_SIZE) {
+        size = MAX_KEY_SIZE;
+    }
+    if (bpf_probe_read_user(buf, size, key.array) != 0) {
+        return 0;
+    }
+    return size;
+}
+
+// Starts the span of the request of the operation to the KV service. The
+// key_pos and range_end_pos are the offsets of the key and range end in the
+// request, if it has_key and has_range_end.
+static __always_inline int start_request(struct pt_regs *ctx, u8 operation, bool has_key, u64 key_pos, bool has_range_end, u64 range_end_pos) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&etcd_events, &key) != NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct etcd_request_t *req = bpf_map_lookup_elem(&etcd_storage_map, &zero);
+    if (req == NULL) {
+        bpf_printk("etcd: request is NULL");
+        return 0;
+    }
+    __builtin_memset(req, 0, sizeof(struct etcd_request_t));
+    req->start_time = get_time_ns();
+    req->operation = operation;
+
+    void *in = get_argument(ctx, 4);
+    if (in != NULL && has_key) {
+        req->key_size = read_key(in + key_pos, req->key);
+    }
+    if (in != NULL && has_range_end) {
+        req->range_end_size = read_key(in + range_end_pos, req->range_end);
+    }
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&etcd_events, &key, req, 0);
+    // The RPC invoked by the client is part of this span.
+    set_grpc_client_owner(key, &req->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (rkv *retryKVClient) Range(ctx context.Context, in *pb.RangeRequest, opts ...grpc.CallOption) (resp *pb.RangeResponse, err error)
+SEC("uprobe/retryKVClient_Range")
+int uprobe_retryKVClient_Range(struct pt_regs *ctx) {
+    return start_request(ctx, OPERATION_RANGE, true, range_request_key_pos, true, range_request_range_end_pos);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (rkv *retryKVClient) Put(ctx context.Context, in *pb.PutRequest, opts ...grpc.CallOption) (resp *pb.PutResponse, err error)
+SEC("uprobe/retryKVClient_Put")
+int uprobe_retryKVClient_Put(struct pt_regs *ctx) {
+    return start_request(ctx, OPERATION_PUT, true, put_request_key_pos, false, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (rkv *retryKVClient) DeleteRange(ctx context.Context, in *pb.DeleteRangeRequest, opts ...grpc.CallOption) (resp *pb.DeleteRangeResponse, err error)
+SEC("uprobe/retryKVClient_DeleteRange")
+int uprobe_retryKVClient_DeleteRange(struct pt_regs *ctx) {
+    return start_request(ctx, OPERATION_DELETE_RANGE, true, delete_range_request_key_pos, true, delete_range_request_range_end_pos);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (rkv *retryKVClient) Txn(ctx context.Context, in *pb.TxnRequest, opts ...grpc.CallOption) (resp *pb.TxnResponse, err error)
+SEC("uprobe/retryKVClient_Txn")
+int uprobe_retryKVClient_Txn(struct pt_regs *ctx) {
+    return start_request(ctx, OPERATION_TXN, false, 0, false, 0);
+}
+
+// This instrumentation attaches uprobe to the Range, Put, DeleteRange, and Txn
+// methods of the retryKVClient.
+SEC("uprobe/retryKVClient")
+int uprobe_retryKVClient_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct etcd_request_t *req = bpf_map_lookup_elem(&etcd_events, &key);
+    if (req == NULL) {
+        return 0;
+    }
+    req->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 2) != NULL) {
+        req->has_error = 1;
+    }
+
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    delete_grpc_client_owner(key);
+    bpf_map_delete_elem(&etcd_events, &key);
+    return 0;
+}
@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package etcd provides an instrumentation probe for etcd clients using the
+// [go.etcd.io/etcd/client/v3] package.
+package etcd
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "go.etcd.io/etcd/client/v3"
+	// apiMod is the module of the protocol buffers of the etcd API.
+	apiMod = "go.etcd.io/etcd/api/v3"
+	// pbPkg is the package of the requests of the KV service.
+	pbPkg = apiMod + "/etcdserverpb"
+)
+
+const (
+	// keyKey is the attribute key of the key of a request.
+	keyKey = attribute.Key("etcd.key")
+	// rangeEndKey is the attribute key of the end of the key range of a
+	// request, exclusive.
+	rangeEndKey = attribute.Key("etcd.range_end")
+)
+
+// dbSystemNameEtcd is the db.system.name attribute of etcd. It is not defined
+// by the semantic conventions.
+var dbSystemNameEtcd = semconv.DBSystemNameKey.String("etcd")
+
+// minVersion is the first version supported by the probe.
+var minVersion = semver.New(3, 5, 0, "", "")
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	offset := func(key, typ, field string) probe.Const {
+		return probe.StructFieldConst{
+			Key: key,
+			ID:  structfield.NewID(apiMod, pbPkg, typ, field),
+		}
+	}
+
+	uprobe := func(method string) *probe.Uprobe {
+		return &probe.Uprobe{
+			Sym:                pkg + ".(*retryKVClient)." + method,
+			EntryProbe:         "uprobe_retryKVClient_" + method,
+			ReturnProbe:        "uprobe_retryKVClient_Returns",
+			PackageConstraints: []probe.PackageConstraints{supported},
+			FailureMode:        probe.FailureModeIgnore,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				offset("range_request_key_pos", "RangeRequest", "Key"),
+				offset("range_request_range_end_pos", "RangeRequest", "RangeEnd"),
+				offset("put_request_key_pos", "PutRequest", "Key"),
+				offset("delete_range_request_key_pos", "DeleteRangeRequest", "Key"),
+				offset("delete_range_request_range_end_pos", "DeleteRangeRequest", "RangeEnd"),
+			},
+			Uprobes: []*probe.Uprobe{
+				uprobe("Range"),
+				uprobe("Put"),
+				uprobe("DeleteRange"),
+				uprobe("Txn"),
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// operation is an operation of the KV service, it needs to be kept in sync
+// with the operations of the eBPF program.
+type operation uint8
+
+const (
+	operationRange operation = iota + 1
+	operationPut
+	operationDeleteRange
+	operationTxn
+)
+
+// name returns the name of the RPC of o.
+func (o operation) name() string {
+	switch o {
+	case operationRange:
+		return "Range"
+	case operationPut:
+		return "Put"
+	case operationDeleteRange:
+		return "DeleteRange"
+	case operationTxn:
+		return "Txn"
+	default:
+		return ""
+	}
+}
+
+// event represents a request sent to the KV service by an etcd client.
+type event struct {
+	context.BaseSpanProperties
+	// Key and RangeEnd hold the first KeySize and RangeEndSize bytes of the
+	// key and range end of the request.
+	Key          [128]byte
+	RangeEnd     [128]byte
+	KeySize      uint64
+	RangeEndSize uint64
+	Operation    operation
+	HasError     uint8
+	_            [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{dbSystemNameEtcd}
+
+	name := e.Operation.name()
+	if name != "" {
+		attrs = append(attrs, semconv.DBOperationName(name))
+	} else {
+		name = dbSystemNameEtcd.Value.AsString()
+	}
+
+	if k := key(e.Key[:], e.KeySize); k != "" {
+		attrs = append(attrs, keyKey.String(k))
+	}
+	if end := key(e.RangeEnd[:], e.RangeEndSize); end != "" {
+		attrs = append(attrs, rangeEndKey.String(end))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// key returns the first size bytes of buf as a string. The keys are binary,
+// invalid UTF-8 sequences are replaced.
+func key(buf []byte, size uint64) string {
+	size = min(size, uint64(len(buf)))
+	return strings.ToValidUTF8(string(buf[:size]), "�")
+}
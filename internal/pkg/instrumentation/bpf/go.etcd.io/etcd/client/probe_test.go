@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package etcd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(op operation, key, rangeEnd string, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Operation:          op,
+		}
+		e.KeySize = uint64(copy(e.Key[:], key))
+		e.RangeEndSize = uint64(copy(e.RangeEnd[:], rangeEnd))
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "get",
+			event: newEvent(operationRange, "/config/app", "", false),
+			want: f.Spans(
+				"Range",
+				ptrace.StatusCodeUnset,
+				dbSystemNameEtcd,
+				semconv.DBOperationName("Range"),
+				keyKey.String("/config/app"),
+			),
+		},
+		{
+			name:  "get prefix",
+			event: newEvent(operationRange, "/config/", "/config0", false),
+			want: f.Spans(
+				"Range",
+				ptrace.StatusCodeUnset,
+				dbSystemNameEtcd,
+				semconv.DBOperationName("Range"),
+				keyKey.String("/config/"),
+				rangeEndKey.String("/config0"),
+			),
+		},
+		{
+			name:  "put",
+			event: newEvent(operationPut, "/config/app", "", true),
+			want: f.Spans(
+				"Put",
+				ptrace.StatusCodeError,
+				dbSystemNameEtcd,
+				semconv.DBOperationName("Put"),
+				keyKey.String("/config/app"),
+			),
+		},
+		{
+			name:  "delete binary key",
+			event: newEvent(operationDeleteRange, "lock\x00\xff", "", false),
+			want: f.Spans(
+				"DeleteRange",
+				ptrace.StatusCodeUnset,
+				dbSystemNameEtcd,
+				semconv.DBOperationName("DeleteRange"),
+				keyKey.String("lock\x00�"),
+			),
+		},
+		{
+			name:  "truncated key",
+			event: newEvent(operationRange, strings.Repeat("k", 200), "", false),
+			want: f.Spans(
+				"Range",
+				ptrace.StatusCodeUnset,
+				dbSystemNameEtcd,
+				semconv.DBOperationName("Range"),
+				keyKey.String(strings.Repeat("k", 128)),
+			),
+		},
+		{
+			name:  "txn",
+			event: newEvent(operationTxn, "", "", false),
+			want: f.Spans(
+				"Txn",
+				ptrace.StatusCodeUnset,
+				dbSystemNameEtcd,
+				semconv.DBOperationName("Txn"),
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent(0, "", "", false),
+			want:  f.Spans("etcd", ptrace.StatusCodeUnset, dbSystemNameEtcd),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_DATABASE_SIZE 64
+#define MAX_OPERATION_SIZE 32
+#define MAX_ADDR_SIZE 128
+#define MAX_COMMAND_SIZE 256
+#define MAX_CONCURRENT 50
+
+struct mongo_request_t {
+    BASE_SPAN_PROPERTIES
+    char database[MAX_DATABASE_SIZE];
+    char operation[MAX_OPERATION_SIZE];
+    char addr[MAX_ADDR_SIZE];
+    // Prefix of the first wire message sent for the operation.
+    u8 command[MAX_COMMAND_SIZE];
+    u64 command_len;
+    // Number of nested operations executed by the goroutine (e.g. the
+    // handshake of a new connection), they are part of this span.
+    u32 depth;
+    u8 has_error;
+    u8 padding[3];
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, void*);
+	__type(value, struct mongo_request_t);
+	__uint(max_entries, MAX_CONCURRENT);
+} mongo_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct mongo_request_t));
+    __uint(max_entries, 1);
+} mongo_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 operation_database_pos;
+volatile const u64 operation_name_pos;
+volatile const u64 connection_addr_pos;
+
+volatile const bool operation_name_supported;
+
+// This instrumentation attaches uprobe to the following function:
+// func (op Operation) Execute(ctx context.Context) error
+SEC("uprobe/Operation_Execute")
+int uprobe_Operation_Execute(struct pt_regs *ctx) {
+    // The Operation receiver is too large to be assigned to registers, it is
+    // passed on the stack and ctx is the first register argument.
+    u64 context_pos = 1;
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct mongo_request_t *tracked = bpf_map_lookup_elem(&mongo_events, &key);
+    if (tracked != NULL) {
+        tracked->depth++;
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct mongo_request_t *mongo_request = bpf_map_lookup_elem(&mongo_storage_map, &zero);
+    if (mongo_request == NULL) {
+        bpf_printk("uprobe/Operation_Execute: mongo_request is NULL");
+        return 0;
+    }
+    __builtin_memset(mongo_request, 0, sizeof(struct mongo_request_t));
+    mongo_request->start_time = get_time_ns();
+
+    void *op_ptr = get_stack_arguments(ctx);
+    get_go_string_from_user_ptr((void *)(op_ptr + operation_database_pos), mongo_request->database, sizeof(mongo_request->database));
+    if (operation_name_supported) {
+        get_go_string_from_user_ptr((void *)(op_ptr + operation_name_pos), mongo_request->operation, sizeof(mongo_request->operation));
+    }
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, context_pos, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &mongo_request->psc,
+        .sc = &mongo_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&mongo_events, &key, mongo_request, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (op Operation) Execute(ctx context.Context) error
+SEC("uprobe/Operation_Execute")
+int uprobe_Operation_Execute_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct mongo_request_t *mongo_request = bpf_map_lookup_elem(&mongo_events, &key);
+    if (mongo_request == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+
+    if (mongo_request->depth > 0) {
+        mongo_request->depth--;
+        return 0;
+    }
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 1) != NULL) {
+        mongo_request->has_error = 1;
+    }
+
+    mongo_request->end_time = get_time_ns();
+    output_span_event(ctx, mongo_request, sizeof(*mongo_request), &mongo_request->sc);
+    stop_tracking_span(&mongo_request->sc, &mongo_request->psc);
+    bpf_map_delete_elem(&mongo_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *connection) writeWireMessage(ctx context.Context, wm []byte) error
+SEC("uprobe/connection_writeWireMessage")
+int uprobe_connection_writeWireMessage(struct pt_regs *ctx) {
+    // argument positions
+    u64 connection_ptr_pos = 1;
+    u64 wm_ptr_pos = 4;
+    u64 wm_len_pos = 5;
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct mongo_request_t *mongo_request = bpf_map_lookup_elem(&mongo_events, &key);
+    if (mongo_request == NULL) {
+        return 0;
+    }
+
+    // Only the first command of the operation itself is recorded, not the
+    // ones of nested operations or of retries.
+    if (mongo_request->depth > 0 || mongo_request->command_len > 0) {
+        return 0;
+    }
+
+    void *connection_ptr = get_argument(ctx, connection_ptr_pos);
+    get_go_string_from_user_ptr((void *)(connection_ptr + connection_addr_pos), mongo_request->addr, sizeof(mongo_request->addr));
+
+    u64 wm_len = (u64)get_argument(ctx, wm_len_pos);
+    if (wm_len > MAX_COMMAND_SIZE) {
+        wm_len = MAX_COMMAND_SIZE;
+    }
+    if (wm_len == 0) {
+        return 0;
+    }
+    void *wm_ptr = get_argument(ctx, wm_ptr_pos);
+    if (bpf_probe_read_user(mongo_request->command, wm_len, wm_ptr) == 0) {
+        mongo_request->command_len = wm_len;
+    }
+    return 0;
+}
@@ -0,0 +1,290 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mongo provides instrumentation probes for MongoDB clients using the
+// [go.mongodb.org/mongo-driver] and [go.mongodb.org/mongo-driver/v2] packages.
+package mongo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkgV1 is the package being instrumented by the probe returned by New.
+	pkgV1 = "go.mongodb.org/mongo-driver"
+	// pkgV2 is the package being instrumented by the probe returned by NewV2.
+	pkgV2 = "go.mongodb.org/mongo-driver/v2"
+)
+
+var (
+	// minVersionV1 is the first version of the v1 driver with known offsets.
+	// Older versions are not instrumented.
+	minVersionV1 = semver.New(1, 11, 0, "", "")
+	// minVersionV2 is the first release of the v2 driver.
+	minVersionV2 = semver.New(2, 0, 0, "", "")
+
+	// operationNameMinVersionV1 is the first version of the v1 driver with
+	// the Operation.Name field. It is present in all v2 versions.
+	operationNameMinVersionV1 = semver.New(1, 13, 0, "", "")
+)
+
+// New returns a new [probe.Probe] for the v1 driver.
+func New(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgV1, minVersionV1, operationNameMinVersionV1)
+}
+
+// NewV2 returns a new [probe.Probe] for the v2 driver.
+func NewV2(logger *slog.Logger, version string) probe.Probe {
+	return newProbe(logger, version, pkgV2, minVersionV2, minVersionV2)
+}
+
+func newProbe(logger *slog.Logger, version, pkg string, minVer, nameMinVer *semver.Version) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	// The argument positions of the instrumented functions, like the
+	// offsets, are only known for versions matching the constraint.
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVer.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	driverPkg := pkg + "/x/mongo/driver"
+	topologyPkg := driverPkg + "/topology"
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "operation_database_pos",
+						ID:  structfield.NewID(pkg, driverPkg, "Operation", "Database"),
+					},
+					MinVersion: minVer,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "operation_name_pos",
+						ID:  structfield.NewID(pkg, driverPkg, "Operation", "Name"),
+					},
+					MinVersion: nameMinVer,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "connection_addr_pos",
+						ID:  structfield.NewID(pkg, topologyPkg, "connection", "addr"),
+					},
+					MinVersion: minVer,
+				},
+				operationNameSupportedConst{pkg: pkg, minVersion: nameMinVer},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                driverPkg + ".Operation.Execute",
+					EntryProbe:         "uprobe_Operation_Execute",
+					ReturnProbe:        "uprobe_Operation_Execute_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                topologyPkg + ".(*connection).writeWireMessage",
+					EntryProbe:         "uprobe_connection_writeWireMessage",
+					DependsOn:          []string{driverPkg + ".Operation.Execute"},
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// operationNameSupportedConst injects if the Operation.Name field is present
+// in the instrumented version of the driver.
+type operationNameSupportedConst struct {
+	pkg        string
+	minVersion *semver.Version
+}
+
+func (c operationNameSupportedConst) InjectOption(info *process.Info) (inject.Option, error) {
+	ver, ok := info.Modules[c.pkg]
+	if !ok {
+		return nil, fmt.Errorf("unknown module version: %s", c.pkg)
+	}
+	return inject.WithKeyValue("operation_name_supported", ver.GreaterThanEqual(c.minVersion)), nil
+}
+
+// event represents a MongoDB operation executed by a client.
+type event struct {
+	context.BaseSpanProperties
+	// Database is the name of the database of the operation.
+	Database [64]byte
+	// Operation is the name of the operation, if known by the driver
+	// version.
+	Operation [32]byte
+	// Addr is the address of the server the command was sent to.
+	Addr [128]byte
+	// Command is the prefix, of CommandLen bytes, of the wire message of the
+	// first command sent for the operation.
+	Command    [256]byte
+	CommandLen uint64
+	// Depth is the number of nested operations being executed, only used by
+	// the eBPF program.
+	Depth    uint32
+	HasError uint8
+	_        [3]byte // padding
+}
+
+const (
+	// opMsg is the op code of OP_MSG wire messages.
+	opMsg = 2013
+	// bsonString is the BSON type of UTF-8 strings.
+	bsonString = 0x02
+)
+
+// command returns the name of the command and of the collection it applies
+// to from the OP_MSG wire message msg. Empty strings are returned for values
+// that cannot be read, e.g. if the message is compressed or truncated.
+//
+// The command is the first element of the document of the message body, the
+// collection is its value if it is a string (e.g. {"find": "users", ...}).
+func command(msg []byte) (name, collection string) {
+	// Header (length, request ID, response to, op code), flag bits, and the
+	// kind of the body section.
+	const bodyOffset = 4*4 + 4 + 1
+	// The document length precedes its first element.
+	const elemOffset = bodyOffset + 4
+
+	if len(msg) <= elemOffset || binary.LittleEndian.Uint32(msg[12:16]) != opMsg || msg[bodyOffset-1] != 0 {
+		return "", ""
+	}
+
+	elem := msg[elemOffset:]
+	typ := elem[0]
+	end := bytes.IndexByte(elem[1:], 0)
+	if end < 0 {
+		return "", ""
+	}
+	name = string(elem[1 : 1+end])
+
+	value := elem[1+end+1:]
+	if typ != bsonString || len(value) < 4 {
+		return name, ""
+	}
+	// The string length includes its trailing null byte.
+	n := int(binary.LittleEndian.Uint32(value[:4]))
+	if n < 1 || len(value) < 4+n {
+		return name, ""
+	}
+	return name, string(value[4 : 4+n-1])
+}
+
+// internalCommands are the commands sent by the driver to establish
+// connections and monitor the servers, they are not traced.
+var internalCommands = map[string]struct{}{
+	"hello":        {},
+	"isMaster":     {},
+	"ismaster":     {},
+	"saslStart":    {},
+	"saslContinue": {},
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	spans := ptrace.NewSpanSlice()
+
+	operation, collection := command(e.Command[:min(e.CommandLen, uint64(len(e.Command)))])
+	if _, ok := internalCommands[operation]; ok {
+		return spans
+	}
+	if operation == "" {
+		operation = unix.ByteSliceToString(e.Operation[:])
+	}
+	namespace := unix.ByteSliceToString(e.Database[:])
+
+	attrs := []attribute.KeyValue{semconv.DBSystemNameMongoDB}
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperationName(operation))
+	}
+	if collection != "" {
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+	}
+	if namespace != "" {
+		attrs = append(attrs, semconv.DBNamespace(namespace))
+	}
+
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Addr[:]))
+	attrs = append(attrs, netattr.Attributes(server, netattr.Addr{})...)
+
+	span := spans.AppendEmpty()
+	span.SetName(spanName(operation, collection, namespace))
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of a span following the semantic conventions:
+// "{db.operation.name} {target}", where target is the collection, or the
+// database if unknown.
+func spanName(operation, collection, namespace string) string {
+	if operation == "" {
+		return semconv.DBSystemNameMongoDB.Value.AsString()
+	}
+	if collection != "" {
+		return operation + " " + collection
+	}
+	if namespace != "" {
+		return operation + " " + namespace
+	}
+	return operation
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mongo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+// opMsgWithString returns an OP_MSG wire message with a body document whose
+// first element is the string value of key.
+func opMsgWithString(key, value string) []byte {
+	elem := []byte{bsonString}
+	elem = append(elem, key...)
+	elem = append(elem, 0)
+	elem = binary.LittleEndian.AppendUint32(elem, uint32(len(value)+1)) // nolint: gosec  // Test value.
+	elem = append(elem, value...)
+	elem = append(elem, 0)
+	return newOpMsg(elem)
+}
+
+// opMsgWithInt returns an OP_MSG wire message with a body document whose
+// first element is the int32 value 1 of key.
+func opMsgWithInt(key string) []byte {
+	elem := []byte{0x10}
+	elem = append(elem, key...)
+	elem = append(elem, 0)
+	elem = binary.LittleEndian.AppendUint32(elem, 1)
+	return newOpMsg(elem)
+}
+
+// newOpMsg returns an OP_MSG wire message with a body document of elem.
+func newOpMsg(elem []byte) []byte {
+	doc := binary.LittleEndian.AppendUint32(nil, uint32(4+len(elem)+1)) // nolint: gosec  // Test value.
+	doc = append(doc, elem...)
+	doc = append(doc, 0)
+
+	msg := make([]byte, 16, 16+4+1+len(doc))
+	binary.LittleEndian.PutUint32(msg[0:], uint32(cap(msg))) // nolint: gosec  // Test value.
+	binary.LittleEndian.PutUint32(msg[12:], opMsg)
+	msg = append(msg, 0, 0, 0, 0) // Flag bits.
+	msg = append(msg, 0)          // Body section.
+	return append(msg, doc...)
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		msg        []byte
+		command    string
+		collection string
+	}{
+		{
+			name:       "collection",
+			msg:        opMsgWithString("find", "users"),
+			command:    "find",
+			collection: "users",
+		},
+		{
+			name:    "database",
+			msg:     opMsgWithInt("ping"),
+			command: "ping",
+		},
+		{
+			name:    "truncated collection",
+			msg:     opMsgWithString("insert", "users")[:35],
+			command: "insert",
+		},
+		{
+			name: "truncated command",
+			msg:  opMsgWithString("insert", "users")[:30],
+		},
+		{
+			name: "compressed",
+			msg: func() []byte {
+				msg := opMsgWithString("find", "users")
+				binary.LittleEndian.PutUint32(msg[12:], 2012)
+				return msg
+			}(),
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, collection := command(tt.msg)
+			assert.Equal(t, tt.command, command, "command")
+			assert.Equal(t, tt.collection, collection, "collection")
+		})
+	}
+}
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(database, operation, addr string, msg []byte, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		e.CommandLen = uint64(copy(e.Command[:], msg)) // nolint: gosec  // Bounded.
+		copy(e.Database[:], database)
+		copy(e.Operation[:], operation)
+		copy(e.Addr[:], addr)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "collection",
+			event: newEvent("app", "find", "localhost:27017", opMsgWithString("find", "users"), false),
+			want: f.Spans(
+				"find users",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMongoDB,
+				semconv.DBOperationName("find"),
+				semconv.DBCollectionName("users"),
+				semconv.DBNamespace("app"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(27017),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "database",
+			event: newEvent("admin", "", "10.0.0.1:27018", opMsgWithInt("ping"), false),
+			want: f.Spans(
+				"ping admin",
+				ptrace.StatusCodeUnset,
+				semconv.DBSystemNameMongoDB,
+				semconv.DBOperationName("ping"),
+				semconv.DBNamespace("admin"),
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(27018),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "operation name",
+			event: newEvent("app", "insert", "localhost:27017", nil, true),
+			want: f.Spans(
+				"insert app",
+				ptrace.StatusCodeError,
+				semconv.DBSystemNameMongoDB,
+				semconv.DBOperationName("insert"),
+				semconv.DBNamespace("app"),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(27017),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "unknown",
+			event: newEvent("", "", "", nil, false),
+			want:  f.Spans("mongodb", ptrace.StatusCodeUnset, semconv.DBSystemNameMongoDB),
+		},
+		{
+			name:  "internal",
+			event: newEvent("admin", "hello", "localhost:27017", opMsgWithInt("hello"), false),
+			want:  ptrace.NewSpanSlice(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
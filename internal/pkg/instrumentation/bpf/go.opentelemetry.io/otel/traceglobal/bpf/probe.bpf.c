@@ -501,7 +501,7 @@ int uprobe_Start_Returns(struct pt_regs *ctx) {
         goto done;
     }
 
-    otel_span->start_time = bpf_ktime_get_ns();
+    otel_span->start_time = get_time_ns();
     otel_span->span_name = *span_name;
     otel_span->tracer_id = *tracer_id;
 
@@ -621,7 +621,7 @@ int uprobe_End(struct pt_regs *ctx) {
     if (span == NULL) {
         return 0;
     }
-    span->end_time = bpf_ktime_get_ns();
+    span->end_time = get_time_ns();
     span->kind = 0;
     stop_tracking_span(&span->sc, &span->psc);
 
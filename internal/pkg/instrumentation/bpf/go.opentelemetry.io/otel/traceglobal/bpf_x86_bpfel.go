@@ -154,6 +154,7 @@ type bpfVariableSpecs struct {
 	AttrTypeInvalid                 *ebpf.VariableSpec `ebpf:"attr_type_invalid"`
 	AttrTypeString                  *ebpf.VariableSpec `ebpf:"attr_type_string"`
 	AttrTypeStringslice             *ebpf.VariableSpec `ebpf:"attr_type_stringslice"`
+	BootClockSupported              *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
 	BucketsPtrPos                   *ebpf.VariableSpec `ebpf:"buckets_ptr_pos"`
 	EndAddr                         *ebpf.VariableSpec `ebpf:"end_addr"`
 	Hex                             *ebpf.VariableSpec `ebpf:"hex"`
@@ -236,6 +237,7 @@ type bpfVariables struct {
 	AttrTypeInvalid                 *ebpf.Variable `ebpf:"attr_type_invalid"`
 	AttrTypeString                  *ebpf.Variable `ebpf:"attr_type_string"`
 	AttrTypeStringslice             *ebpf.Variable `ebpf:"attr_type_stringslice"`
+	BootClockSupported              *ebpf.Variable `ebpf:"boot_clock_supported"`
 	BucketsPtrPos                   *ebpf.Variable `ebpf:"buckets_ptr_pos"`
 	EndAddr                         *ebpf.Variable `ebpf:"end_addr"`
 	Hex                             *ebpf.Variable `ebpf:"hex"`
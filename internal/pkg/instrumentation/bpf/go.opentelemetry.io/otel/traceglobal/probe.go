@@ -96,6 +96,7 @@ func New(logger *slog.Logger) probe.Probe {
 			Logger: logger,
 			Consts: []probe.Const{
 				probe.AllocationConst{},
+				probe.BootClockConst{},
 				probe.KeyValConst{
 					Key: "attr_type_invalid",
 					Val: uint64(attribute.INVALID),
@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_WORKFLOW_TYPE_SIZE 128
+#define MAX_WORKFLOW_ID_SIZE 128
+#define MAX_RUN_ID_SIZE 64
+#define MAX_TASK_QUEUE_SIZE 128
+#define MAX_SIGNAL_NAME_SIZE 128
+#define MAX_CONCURRENT 50
+
+// The operations of the client traced, see the operation type in the probe.
+#define OPERATION_START_WORKFLOW 1
+#define OPERATION_SIGNAL_WORKFLOW 2
+#define OPERATION_SIGNAL_WITH_START_WORKFLOW 3
+
+struct temporal_request_t {
+    BASE_SPAN_PROPERTIES
+    char workflow_type[MAX_WORKFLOW_TYPE_SIZE];
+    char workflow_id[MAX_WORKFLOW_ID_SIZE];
+    char run_id[MAX_RUN_ID_SIZE];
+    char task_queue[MAX_TASK_QUEUE_SIZE];
+    char signal_name[MAX_SIGNAL_NAME_SIZE];
+    u8 operation;
+    u8 has_error;
+    u8 padding[6];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct temporal_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} temporal_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct temporal_request_t));
+    __uint(max_entries, 1);
+} temporal_storage_map SEC(".maps");
+
+// Injected in init
+// True if the current run ID of a workflow run is returned by a closure
+// capturing it (go.temporal.io/sdk >= 1.48.0).
+volatile const bool run_id_closure;
+
+volatile const u64 execute_input_options_pos;
+volatile const u64 execute_input_workflow_type_pos;
+volatile const u64 signal_input_workflow_id_pos;
+volatile const u64 signal_input_run_id_pos;
+volatile const u64 signal_input_signal_name_pos;
+volatile const u64 signal_with_start_input_signal_name_pos;
+volatile const u64 signal_with_start_input_options_pos;
+volatile const u64 signal_with_start_input_workflow_type_pos;
+volatile const u64 start_options_id_pos;
+volatile const u64 start_options_task_queue_pos;
+volatile const u64 workflow_run_workflow_id_pos;
+// Zero if the run ID is returned by a closure.
+volatile const u64 workflow_run_first_run_id_pos;
+// Zero if the run ID is held by the workflow run.
+volatile const u64 workflow_run_current_run_id_pos;
+
+// Returns a zeroed request from the per-CPU storage, with its start time and
+// operation set.
+static __always_inline struct temporal_request_t *new_request(u8 operation) {
+    u32 map_id = 0;
+    struct temporal_request_t *req = bpf_map_lookup_elem(&temporal_storage_map, &map_id);
+    if (req == NULL) {
+        return NULL;
+    }
+    __builtin_memset(req, 0, sizeof(*req));
+    req->start_time = get_time_ns();
+    req->operation = operation;
+    return req;
+}
+
+// Reads the workflow ID and task queue of the *StartWorkflowOptions at
+// options_ptr into req. The workflow ID is generated by the client if none is
+// set, it is then read from the returned workflow run.
+static __always_inline void read_start_options(void *options_ptr, struct temporal_request_t *req) {
+    if (options_ptr == NULL) {
+        return;
+    }
+    get_go_string_from_user_ptr(options_ptr + start_options_id_pos, req->workflow_id, sizeof(req->workflow_id));
+    get_go_string_from_user_ptr(options_ptr + start_options_task_queue_pos, req->task_queue, sizeof(req->task_queue));
+}
+
+// Starts the span of req, child of the span of the context argument of the
+// instrumented method, and stores it for the goroutine.
+static __always_inline void start_temporal_span(struct pt_regs *ctx, struct temporal_request_t *req) {
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&temporal_events, &key, req, 0);
+}
+
+// Ends the span of the goroutine. The returned error is the interface in the
+// err_pos (type) and err_pos+1 (data) registers. If run_pos is not zero, the
+// returned WorkflowRun is the interface in the run_pos (type) and run_pos+1
+// (data) registers.
+static __always_inline int end_temporal_span(struct pt_regs *ctx, u64 run_pos, u64 err_pos) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct temporal_request_t *req = bpf_map_lookup_elem(&temporal_events, &key);
+    if (req == NULL) {
+        bpf_printk("event is NULL in ret probe");
+        return 0;
+    }
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, err_pos) != NULL) {
+        req->has_error = 1;
+    }
+
+    // The workflow run is always a *workflowRunImpl.
+    void *run_ptr = NULL;
+    if (run_pos != 0) {
+        run_ptr = get_argument(ctx, run_pos + 1);
+    }
+    if (run_ptr != NULL) {
+        get_go_string_from_user_ptr(run_ptr + workflow_run_workflow_id_pos, req->workflow_id, sizeof(req->workflow_id));
+        if (run_id_closure) {
+            // The closure captures the run ID by value, it follows the
+            // function pointer.
+            void *closure_ptr = NULL;
+            bpf_probe_read_user(&closure_ptr, sizeof(closure_ptr), run_ptr + workflow_run_current_run_id_pos);
+            if (closure_ptr != NULL) {
+                get_go_string_from_user_ptr(closure_ptr + sizeof(void *), req->run_id, sizeof(req->run_id));
+            }
+        } else {
+            get_go_string_from_user_ptr(run_ptr + workflow_run_first_run_id_pos, req->run_id, sizeof(req->run_id));
+        }
+    }
+
+    req->end_time = get_time_ns();
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    stop_tracking_span(&req->sc, &req->psc);
+    bpf_map_delete_elem(&temporal_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *workflowClientInterceptor) ExecuteWorkflow(ctx context.Context, in *ClientExecuteWorkflowInput) (WorkflowRun, error)
+SEC("uprobe/workflowClientInterceptor_ExecuteWorkflow")
+int uprobe_workflowClientInterceptor_ExecuteWorkflow(struct pt_regs *ctx) {
+    u64 in_ptr_pos = 4;
+    void *in_ptr = get_argument(ctx, in_ptr_pos);
+    if (in_ptr == NULL) {
+        return 0;
+    }
+
+    struct temporal_request_t *req = new_request(OPERATION_START_WORKFLOW);
+    if (req == NULL) {
+        return 0;
+    }
+    get_go_string_from_user_ptr(in_ptr + execute_input_workflow_type_pos, req->workflow_type, sizeof(req->workflow_type));
+
+    void *options_ptr = NULL;
+    bpf_probe_read_user(&options_ptr, sizeof(options_ptr), in_ptr + execute_input_options_pos);
+    read_start_options(options_ptr, req);
+
+    start_temporal_span(ctx, req);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *workflowClientInterceptor) ExecuteWorkflow(ctx context.Context, in *ClientExecuteWorkflowInput) (WorkflowRun, error)
+SEC("uprobe/workflowClientInterceptor_ExecuteWorkflow")
+int uprobe_workflowClientInterceptor_ExecuteWorkflow_Returns(struct pt_regs *ctx) {
+    return end_temporal_span(ctx, 1, 3);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *workflowClientInterceptor) SignalWorkflow(ctx context.Context, in *ClientSignalWorkflowInput) error
+SEC("uprobe/workflowClientInterceptor_SignalWorkflow")
+int uprobe_workflowClientInterceptor_SignalWorkflow(struct pt_regs *ctx) {
+    u64 in_ptr_pos = 4;
+    void *in_ptr = get_argument(ctx, in_ptr_pos);
+    if (in_ptr == NULL) {
+        return 0;
+    }
+
+    struct temporal_request_t *req = new_request(OPERATION_SIGNAL_WORKFLOW);
+    if (req == NULL) {
+        return 0;
+    }
+    get_go_string_from_user_ptr(in_ptr + signal_input_workflow_id_pos, req->workflow_id, sizeof(req->workflow_id));
+    get_go_string_from_user_ptr(in_ptr + signal_input_run_id_pos, req->run_id, sizeof(req->run_id));
+    get_go_string_from_user_ptr(in_ptr + signal_input_signal_name_pos, req->signal_name, sizeof(req->signal_name));
+
+    start_temporal_span(ctx, req);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *workflowClientInterceptor) SignalWorkflow(ctx context.Context, in *ClientSignalWorkflowInput) error
+SEC("uprobe/workflowClientInterceptor_SignalWorkflow")
+int uprobe_workflowClientInterceptor_SignalWorkflow_Returns(struct pt_regs *ctx) {
+    return end_temporal_span(ctx, 0, 1);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *workflowClientInterceptor) SignalWithStartWorkflow(ctx context.Context, in *ClientSignalWithStartWorkflowInput) (WorkflowRun, error)
+SEC("uprobe/workflowClientInterceptor_SignalWithStartWorkflow")
+int uprobe_workflowClientInterceptor_SignalWithStartWorkflow(struct pt_regs *ctx) {
+    u64 in_ptr_pos = 4;
+    void *in_ptr = get_argument(ctx, in_ptr_pos);
+    if (in_ptr == NULL) {
+        return 0;
+    }
+
+    struct temporal_request_t *req = new_request(OPERATION_SIGNAL_WITH_START_WORKFLOW);
+    if (req == NULL) {
+        return 0;
+    }
+    get_go_string_from_user_ptr(in_ptr + signal_with_start_input_signal_name_pos, req->signal_name, sizeof(req->signal_name));
+    get_go_string_from_user_ptr(in_ptr + signal_with_start_input_workflow_type_pos, req->workflow_type, sizeof(req->workflow_type));
+
+    void *options_ptr = NULL;
+    bpf_probe_read_user(&options_ptr, sizeof(options_ptr), in_ptr + signal_with_start_input_options_pos);
+    read_start_options(options_ptr, req);
+
+    start_temporal_span(ctx, req);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (w *workflowClientInterceptor) SignalWithStartWorkflow(ctx context.Context, in *ClientSignalWithStartWorkflowInput) (WorkflowRun, error)
+SEC("uprobe/workflowClientInterceptor_SignalWithStartWorkflow")
+int uprobe_workflowClientInterceptor_SignalWithStartWorkflow_Returns(struct pt_regs *ctx) {
+    return end_temporal_span(ctx, 1, 3);
+}
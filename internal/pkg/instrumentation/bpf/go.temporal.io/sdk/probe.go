@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package temporal provides an instrumentation probe for the clients of
+// Temporal services using the [go.temporal.io/sdk] package.
+package temporal
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "go.temporal.io/sdk"
+	// internalPkg is the package implementing the client.
+	internalPkg = pkg + "/internal"
+)
+
+const (
+	// workflowTypeKey is the attribute key of the type of a workflow.
+	workflowTypeKey = attribute.Key("temporal.workflow.type")
+	// workflowIDKey is the attribute key of the ID of a workflow.
+	workflowIDKey = attribute.Key("temporal.workflow.id")
+	// runIDKey is the attribute key of the ID of a run of a workflow.
+	runIDKey = attribute.Key("temporal.workflow.run_id")
+	// taskQueueKey is the attribute key of the task queue of a workflow.
+	taskQueueKey = attribute.Key("temporal.task_queue")
+	// signalNameKey is the attribute key of the name of a signal sent to a
+	// workflow.
+	signalNameKey = attribute.Key("temporal.signal.name")
+)
+
+var (
+	// minVersion is the first version supported by the probe.
+	minVersion = semver.New(1, 20, 0, "", "")
+	// runIDClosureVersion is the version the current run ID of a workflow
+	// run started being returned by a closure.
+	runIDClosureVersion = semver.New(1, 48, 0, "", "")
+)
+
+// New returns a new [probe.Probe].
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	supported := probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(pkg, internalPkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("execute_input_options_pos", "ClientExecuteWorkflowInput", "Options"),
+				fieldConst("execute_input_workflow_type_pos", "ClientExecuteWorkflowInput", "WorkflowType"),
+				fieldConst("signal_input_workflow_id_pos", "ClientSignalWorkflowInput", "WorkflowID"),
+				fieldConst("signal_input_run_id_pos", "ClientSignalWorkflowInput", "RunID"),
+				fieldConst("signal_input_signal_name_pos", "ClientSignalWorkflowInput", "SignalName"),
+				fieldConst("signal_with_start_input_signal_name_pos", "ClientSignalWithStartWorkflowInput", "SignalName"),
+				fieldConst("signal_with_start_input_options_pos", "ClientSignalWithStartWorkflowInput", "Options"),
+				fieldConst("signal_with_start_input_workflow_type_pos", "ClientSignalWithStartWorkflowInput", "WorkflowType"),
+				fieldConst("start_options_id_pos", "StartWorkflowOptions", "ID"),
+				fieldConst("start_options_task_queue_pos", "StartWorkflowOptions", "TaskQueue"),
+				fieldConst("workflow_run_workflow_id_pos", "workflowRunImpl", "workflowID"),
+				probe.StructFieldConstMaxVersion{
+					StructField: probe.StructFieldConst{
+						Key: "workflow_run_first_run_id_pos",
+						ID:  structfield.NewID(pkg, internalPkg, "workflowRunImpl", "firstRunID"),
+					},
+					MinVersion: minVersion,
+					MaxVersion: runIDClosureVersion,
+				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "workflow_run_current_run_id_pos",
+						ID:  structfield.NewID(pkg, internalPkg, "workflowRunImpl", "currentRunID"),
+					},
+					MinVersion: runIDClosureVersion,
+				},
+				runIDClosureConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                internalPkg + ".(*workflowClientInterceptor).ExecuteWorkflow",
+					EntryProbe:         "uprobe_workflowClientInterceptor_ExecuteWorkflow",
+					ReturnProbe:        "uprobe_workflowClientInterceptor_ExecuteWorkflow_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                internalPkg + ".(*workflowClientInterceptor).SignalWorkflow",
+					EntryProbe:         "uprobe_workflowClientInterceptor_SignalWorkflow",
+					ReturnProbe:        "uprobe_workflowClientInterceptor_SignalWorkflow_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+				{
+					Sym:                internalPkg + ".(*workflowClientInterceptor).SignalWithStartWorkflow",
+					EntryProbe:         "uprobe_workflowClientInterceptor_SignalWithStartWorkflow",
+					ReturnProbe:        "uprobe_workflowClientInterceptor_SignalWithStartWorkflow_Returns",
+					PackageConstraints: []probe.PackageConstraints{supported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// runIDClosureConst is a Probe Const defining whether the current run ID of a
+// workflow run is returned by a closure.
+type runIDClosureConst struct{}
+
+func (c runIDClosureConst) InjectOption(info *process.Info) (inject.Option, error) {
+	ver, ok := info.Modules[pkg]
+	if !ok {
+		return nil, fmt.Errorf("unknown module version: %s", pkg)
+	}
+	return inject.WithKeyValue("run_id_closure", ver.GreaterThanEqual(runIDClosureVersion)), nil
+}
+
+// operation is an operation of a client on a workflow.
+type operation uint8
+
+const (
+	operationStartWorkflow operation = iota + 1
+	operationSignalWorkflow
+	operationSignalWithStartWorkflow
+)
+
+// event represents an operation of a client on a workflow.
+type event struct {
+	context.BaseSpanProperties
+	WorkflowType [128]byte
+	// WorkflowID is the ID of the workflow, generated by the client when
+	// starting a workflow without one.
+	WorkflowID [128]byte
+	// RunID is the ID of the run started, or signaled if set.
+	RunID      [64]byte
+	TaskQueue  [128]byte
+	SignalName [128]byte
+	Operation  operation
+	HasError   uint8
+	_          [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	var attrs []attribute.KeyValue
+	add := func(key attribute.Key, b []byte) {
+		if v := unix.ByteSliceToString(b); v != "" {
+			attrs = append(attrs, key.String(v))
+		}
+	}
+	add(workflowTypeKey, e.WorkflowType[:])
+	add(workflowIDKey, e.WorkflowID[:])
+	add(runIDKey, e.RunID[:])
+	add(taskQueueKey, e.TaskQueue[:])
+	add(signalNameKey, e.SignalName[:])
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(e))
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of the span of the operation of e, named as the
+// spans of the Temporal OpenTelemetry interceptor (e.g.
+// "StartWorkflow:ProcessOrder" or "SignalWorkflow:cancel").
+func spanName(e *event) string {
+	var name, suffix string
+	switch e.Operation {
+	case operationStartWorkflow:
+		name, suffix = "StartWorkflow", unix.ByteSliceToString(e.WorkflowType[:])
+	case operationSignalWorkflow:
+		name, suffix = "SignalWorkflow", unix.ByteSliceToString(e.SignalName[:])
+	case operationSignalWithStartWorkflow:
+		name, suffix = "SignalWithStartWorkflow", unix.ByteSliceToString(e.SignalName[:])
+	default:
+		return "temporal"
+	}
+	if suffix == "" {
+		return name
+	}
+	return name + ":" + suffix
+}
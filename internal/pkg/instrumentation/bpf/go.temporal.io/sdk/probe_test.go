@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package temporal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(op operation, workflowType, signalName, runID string, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			Operation:          op,
+		}
+		copy(e.WorkflowType[:], workflowType)
+		copy(e.WorkflowID[:], "order-42")
+		copy(e.RunID[:], runID)
+		copy(e.SignalName[:], signalName)
+		if workflowType != "" {
+			copy(e.TaskQueue[:], "orders")
+		}
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	const runID = "0190c5a4-7f3b-7b4e-9d0a-3c6f1e2d4b5a"
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "start",
+			event: newEvent(operationStartWorkflow, "ProcessOrder", "", runID, false),
+			want: f.Spans(
+				"StartWorkflow:ProcessOrder",
+				ptrace.StatusCodeUnset,
+				workflowTypeKey.String("ProcessOrder"),
+				workflowIDKey.String("order-42"),
+				runIDKey.String(runID),
+				taskQueueKey.String("orders"),
+			),
+		},
+		{
+			name:  "start error",
+			event: newEvent(operationStartWorkflow, "ProcessOrder", "", "", true),
+			want: f.Spans(
+				"StartWorkflow:ProcessOrder",
+				ptrace.StatusCodeError,
+				workflowTypeKey.String("ProcessOrder"),
+				workflowIDKey.String("order-42"),
+				taskQueueKey.String("orders"),
+			),
+		},
+		{
+			name:  "signal",
+			event: newEvent(operationSignalWorkflow, "", "cancel", "", false),
+			want: f.Spans(
+				"SignalWorkflow:cancel",
+				ptrace.StatusCodeUnset,
+				workflowIDKey.String("order-42"),
+				signalNameKey.String("cancel"),
+			),
+		},
+		{
+			name:  "signal with start",
+			event: newEvent(operationSignalWithStartWorkflow, "ProcessOrder", "cancel", runID, false),
+			want: f.Spans(
+				"SignalWithStartWorkflow:cancel",
+				ptrace.StatusCodeUnset,
+				workflowTypeKey.String("ProcessOrder"),
+				workflowIDKey.String("order-42"),
+				runIDKey.String(runID),
+				taskQueueKey.String("orders"),
+				signalNameKey.String("cancel"),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_types.h"
+#include "goroutine_spans.h"
+#include "uprobe.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_MESSAGE_SIZE 256
+#define MAX_LOGGER_NAME_SIZE 128
+#define MAX_CALLER_FILE_SIZE 256
+#define MAX_CALLER_FUNCTION_SIZE 256
+
+struct log_entry_t {
+    u64 time;
+    // The span context of the span active in the goroutine writing the entry.
+    struct span_context sc;
+    s64 caller_line;
+    char message[MAX_MESSAGE_SIZE];
+    char logger_name[MAX_LOGGER_NAME_SIZE];
+    char caller_file[MAX_CALLER_FILE_SIZE];
+    char caller_function[MAX_CALLER_FUNCTION_SIZE];
+    s8 level;
+    // Set if the message is longer than MAX_MESSAGE_SIZE.
+    u8 message_truncated;
+    // Set if the caller of the entry is defined (the logger is built with
+    // zap.AddCaller).
+    u8 caller_defined;
+    u8 padding[5];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct log_entry_t));
+    __uint(max_entries, 1);
+} zap_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 entry_level_pos;
+volatile const u64 entry_logger_name_pos;
+volatile const u64 entry_message_pos;
+volatile const u64 entry_caller_pos;
+volatile const u64 caller_defined_pos;
+volatile const u64 caller_file_pos;
+volatile const u64 caller_line_pos;
+volatile const u64 caller_function_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (ce *CheckedEntry) Write(fields ...Field)
+SEC("uprobe/CheckedEntry_Write")
+int uprobe_CheckedEntry_Write(struct pt_regs *ctx) {
+    // The Entry is embedded at the start of the CheckedEntry.
+    void *entry = get_argument(ctx, 1);
+    if (entry == NULL) {
+        // Not enabled for the level of the entry.
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct log_entry_t *rec = bpf_map_lookup_elem(&zap_storage_map, &zero);
+    if (rec == NULL) {
+        bpf_printk("uprobe/CheckedEntry_Write: rec is NULL");
+        return 0;
+    }
+    __builtin_memset(rec, 0, sizeof(struct log_entry_t));
+    rec->time = get_time_ns();
+
+    void *key = (void *)GOROUTINE(ctx);
+    struct span_context *sc = get_goroutine_span(key);
+    if (sc != NULL) {
+        rec->sc = *sc;
+    }
+
+    bpf_probe_read_user(&rec->level, sizeof(rec->level), entry + entry_level_pos);
+
+    struct go_string msg = {0};
+    bpf_probe_read_user(&msg, sizeof(msg), entry + entry_message_pos);
+    rec->message_truncated = (u64)msg.len > MAX_MESSAGE_SIZE;
+    u64 size = (u64)msg.len < MAX_MESSAGE_SIZE ? msg.len : MAX_MESSAGE_SIZE;
+    bpf_probe_read_user(rec->message, size, msg.str);
+
+    get_go_string_from_user_ptr(entry + entry_logger_name_pos, rec->logger_name, sizeof(rec->logger_name));
+
+    void *caller = entry + entry_caller_pos;
+    bpf_probe_read_user(&rec->caller_defined, sizeof(rec->caller_defined), caller + caller_defined_pos);
+    if (rec->caller_defined) {
+        bpf_probe_read_user(&rec->caller_line, sizeof(rec->caller_line), caller + caller_line_pos);
+        get_go_string_from_user_ptr(caller + caller_file_pos, rec->caller_file, sizeof(rec->caller_file));
+        get_go_string_from_user_ptr(caller + caller_function_pos, rec->caller_function, sizeof(rec->caller_function));
+    }
+
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, rec, sizeof(*rec));
+    return 0;
+}
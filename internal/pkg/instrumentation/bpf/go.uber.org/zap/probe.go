@@ -0,0 +1,257 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package zap provides an instrumentation probe producing log records from
+// the entries logged with the [go.uber.org/zap] package.
+package zap
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the module being instrumented.
+	pkg = "go.uber.org/zap"
+	// zapcorePkg is the package of the entries instrumented.
+	zapcorePkg = pkg + "/zapcore"
+)
+
+const (
+	// loggerNameKey is the attribute key of the name of the logger an entry
+	// is logged with.
+	loggerNameKey = attribute.Key("zap.logger.name")
+	// truncatedKey is the attribute key set on the log records with a
+	// message truncated.
+	truncatedKey = attribute.Key("zap.truncated")
+
+	// The attribute keys of the caller of an entry.
+	codeFilePathKey     = attribute.Key("code.file.path")
+	codeLineNumberKey   = attribute.Key("code.line.number")
+	codeFunctionNameKey = attribute.Key("code.function.name")
+)
+
+var (
+	// minVersion is the first version of go.uber.org/zap instrumented. The
+	// layout of the Entry is stable from it.
+	minVersion = semver.New(1, 21, 0, "", "")
+
+	zapSupported = probe.PackageConstraints{
+		Package: pkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + minVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		FailureMode: probe.FailureModeWarn,
+	}
+)
+
+// New returns a new [probe.Probe].
+//
+// A log record is produced for each entry written by a Logger, or a
+// SugaredLogger, enabled for its level. The entries are not logged with a
+// context: the log records have the trace context of the span active in the
+// goroutine writing them, the span of the net/http or gRPC server request
+// handled, if any. The fields of the entries are not recorded, only their
+// level, message, logger name, and caller. The message is truncated to 256
+// bytes.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	return &probe.LogProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "entry_level_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "Entry", "Level"),
+				},
+				probe.StructFieldConst{
+					Key: "entry_logger_name_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "Entry", "LoggerName"),
+				},
+				probe.StructFieldConst{
+					Key: "entry_message_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "Entry", "Message"),
+				},
+				probe.StructFieldConst{
+					Key: "entry_caller_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "Entry", "Caller"),
+				},
+				probe.StructFieldConst{
+					Key: "caller_defined_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "EntryCaller", "Defined"),
+				},
+				probe.StructFieldConst{
+					Key: "caller_file_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "EntryCaller", "File"),
+				},
+				probe.StructFieldConst{
+					Key: "caller_line_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "EntryCaller", "Line"),
+				},
+				probe.StructFieldConst{
+					Key: "caller_function_pos",
+					ID:  structfield.NewID(pkg, zapcorePkg, "EntryCaller", "Function"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:                zapcorePkg + ".(*CheckedEntry).Write",
+					EntryProbe:         "uprobe_CheckedEntry_Write",
+					PackageConstraints: []probe.PackageConstraints{zapSupported},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+const (
+	// maxMessageSize is the maximum size of the message read.
+	maxMessageSize = 256
+	// maxLoggerNameSize is the maximum size of the logger name read.
+	maxLoggerNameSize = 128
+	// maxCallerSize is the maximum size of the file and function of the
+	// caller read.
+	maxCallerSize = 256
+)
+
+// event represents an entry written by a Logger.
+type event struct {
+	Time           uint64
+	SpanContext    context.EBPFSpanContext
+	CallerLine     int64
+	Message        [maxMessageSize]byte
+	LoggerName     [maxLoggerNameSize]byte
+	CallerFile     [maxCallerSize]byte
+	CallerFunction [maxCallerSize]byte
+	Level          int8
+	// MessageTruncated is set if the message is longer than maxMessageSize.
+	MessageTruncated uint8
+	CallerDefined    uint8
+	_                [5]byte // padding
+}
+
+func processFn(e *event) plog.LogRecordSlice {
+	logs := plog.NewLogRecordSlice()
+	lr := logs.AppendEmpty()
+
+	ts := kernel.BootOffsetToTimestamp(e.Time)
+	lr.SetTimestamp(ts)
+	lr.SetObservedTimestamp(ts)
+
+	lr.SetSeverityNumber(severity(e.Level))
+	lr.SetSeverityText(levelText(e.Level))
+	lr.Body().SetStr(unix.ByteSliceToString(e.Message[:]))
+
+	if e.SpanContext.SpanID.IsValid() {
+		lr.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+		lr.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+		lr.SetFlags(plog.LogRecordFlags(e.SpanContext.TraceFlags))
+	}
+
+	var attrs []attribute.KeyValue
+	if name := unix.ByteSliceToString(e.LoggerName[:]); name != "" {
+		attrs = append(attrs, loggerNameKey.String(name))
+	}
+	if e.CallerDefined != 0 {
+		attrs = append(
+			attrs,
+			codeFilePathKey.String(unix.ByteSliceToString(e.CallerFile[:])),
+			codeLineNumberKey.Int64(e.CallerLine),
+		)
+		if fn := unix.ByteSliceToString(e.CallerFunction[:]); fn != "" {
+			attrs = append(attrs, codeFunctionNameKey.String(fn))
+		}
+	}
+	if e.MessageTruncated != 0 {
+		attrs = append(attrs, truncatedKey.Bool(true))
+	}
+	pdataconv.Attributes(lr.Attributes(), attrs...)
+
+	return logs
+}
+
+// The zapcore.Level of the entries.
+const (
+	levelDebug int8 = iota - 1
+	levelInfo
+	levelWarn
+	levelError
+	levelDPanic
+	levelPanic
+	levelFatal
+)
+
+// severity returns the OpenTelemetry severity of level. The zap levels are
+// mapped the same way as by the OpenTelemetry zap bridge.
+func severity(level int8) plog.SeverityNumber {
+	switch level {
+	case levelDebug:
+		return plog.SeverityNumberDebug
+	case levelInfo:
+		return plog.SeverityNumberInfo
+	case levelWarn:
+		return plog.SeverityNumberWarn
+	case levelError:
+		return plog.SeverityNumberError
+	case levelDPanic:
+		return plog.SeverityNumberFatal
+	case levelPanic:
+		return plog.SeverityNumberFatal2
+	case levelFatal:
+		return plog.SeverityNumberFatal3
+	default:
+		return plog.SeverityNumberUnspecified
+	}
+}
+
+// levelText returns the text of level, as returned by zapcore.Level.String.
+func levelText(level int8) string {
+	switch level {
+	case levelDebug:
+		return "debug"
+	case levelInfo:
+		return "info"
+	case levelWarn:
+		return "warn"
+	case levelError:
+		return "error"
+	case levelDPanic:
+		return "dpanic"
+	case levelPanic:
+		return "panic"
+	case levelFatal:
+		return "fatal"
+	default:
+		return fmt.Sprintf("Level(%d)", level)
+	}
+}
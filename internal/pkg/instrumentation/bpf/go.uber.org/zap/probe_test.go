@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+)
+
+func TestProcessFn(t *testing.T) {
+	now := time.Unix(0, time.Now().UnixNano()) // No wall clock.
+	offset := kernel.TimeToBootOffset(now)
+
+	traceID := trace.TraceID{1}
+	spanID := trace.SpanID{1}
+
+	e := &event{
+		Time: offset,
+		SpanContext: context.EBPFSpanContext{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		},
+		CallerLine:       42,
+		Level:            levelWarn,
+		MessageTruncated: 1,
+		CallerDefined:    1,
+	}
+	copy(e.Message[:], "hello")
+	copy(e.LoggerName[:], "app.http")
+	copy(e.CallerFile[:], "/src/app/main.go")
+	copy(e.CallerFunction[:], "main.handle")
+
+	want := plog.NewLogRecordSlice()
+	lr := want.AppendEmpty()
+	lr.SetTimestamp(kernel.BootOffsetToTimestamp(offset))
+	lr.SetObservedTimestamp(kernel.BootOffsetToTimestamp(offset))
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.SetSeverityText("warn")
+	lr.Body().SetStr("hello")
+	lr.SetTraceID(pcommon.TraceID(traceID))
+	lr.SetSpanID(pcommon.SpanID(spanID))
+	lr.SetFlags(plog.LogRecordFlags(trace.FlagsSampled))
+	pdataconv.Attributes(
+		lr.Attributes(),
+		loggerNameKey.String("app.http"),
+		attribute.String("code.file.path", "/src/app/main.go"),
+		attribute.Int64("code.line.number", 42),
+		attribute.String("code.function.name", "main.handle"),
+		truncatedKey.Bool(true),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
+
+func TestProcessFnNoSpan(t *testing.T) {
+	e := &event{Level: levelInfo}
+	copy(e.Message[:], "hello")
+
+	got := processFn(e)
+	assert.Equal(t, 1, got.Len())
+	lr := got.At(0)
+	assert.Equal(t, "hello", lr.Body().Str())
+	assert.Equal(t, plog.SeverityNumberInfo, lr.SeverityNumber())
+	assert.Equal(t, "info", lr.SeverityText())
+	assert.True(t, lr.TraceID().IsEmpty())
+	assert.True(t, lr.SpanID().IsEmpty())
+	// The caller is not recorded if not defined.
+	assert.Equal(t, 0, lr.Attributes().Len())
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level    int8
+		want     plog.SeverityNumber
+		wantText string
+	}{
+		{levelDebug, plog.SeverityNumberDebug, "debug"},
+		{levelInfo, plog.SeverityNumberInfo, "info"},
+		{levelWarn, plog.SeverityNumberWarn, "warn"},
+		{levelError, plog.SeverityNumberError, "error"},
+		{levelDPanic, plog.SeverityNumberFatal, "dpanic"},
+		{levelPanic, plog.SeverityNumberFatal2, "panic"},
+		{levelFatal, plog.SeverityNumberFatal3, "fatal"},
+		{10, plog.SeverityNumberUnspecified, "Level(10)"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, severity(tt.level), tt.level)
+		assert.Equal(t, tt.wantText, levelText(tt.level), tt.level)
+	}
+}
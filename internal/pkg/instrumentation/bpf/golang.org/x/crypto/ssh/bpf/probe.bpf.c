@@ -0,0 +1,402 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_CONNECTIONS 1024
+#define MAX_ADDR_SIZE 128
+#define MAX_USER_SIZE 64
+#define MAX_COMMAND_SIZE 256
+#define MAX_ERROR_SIZE 128
+
+struct ssh_event_t {
+    BASE_SPAN_PROPERTIES
+    // The address of the server, as passed to Dial or NewClientConn.
+    char addr[MAX_ADDR_SIZE];
+    char user[MAX_USER_SIZE];
+    // The command started by a session, empty for the connection spans.
+    char command[MAX_COMMAND_SIZE];
+    // The message of the error of a failed handshake.
+    char error[MAX_ERROR_SIZE];
+    u8 is_session;
+    u8 has_error;
+    u8 padding[6];
+};
+
+struct ssh_connect_t {
+    struct ssh_event_t event;
+    // Whether the connection is established by Dial: the span is ended when
+    // Dial returns, not NewClientConn.
+    u8 from_dial;
+};
+
+// The server and user of an established connection, and the span context of
+// the span of its handshake. Sessions opened on the connection are children
+// of the span.
+struct ssh_conn_t {
+    char addr[MAX_ADDR_SIZE];
+    char user[MAX_USER_SIZE];
+    struct span_context sc;
+};
+
+// Handshakes in progress, keyed by the goroutine establishing the connection.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct ssh_connect_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} ssh_connects SEC(".maps");
+
+// Established connections, keyed by their *ssh.connection.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct ssh_conn_t);
+    __uint(max_entries, MAX_CONNECTIONS);
+} ssh_conns SEC(".maps");
+
+// The connection of the sessions being opened, keyed by the goroutine opening
+// them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} ssh_new_sessions SEC(".maps");
+
+// The connection of the opened sessions, keyed by their *ssh.Session.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONNECTIONS);
+} ssh_session_conns SEC(".maps");
+
+// Started sessions, keyed by their *ssh.Session. Sessions never waited for
+// are evicted.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct ssh_event_t);
+    __uint(max_entries, MAX_CONNECTIONS);
+} ssh_sessions SEC(".maps");
+
+// The session of the calls to Session.Start and Session.Wait in progress,
+// keyed by the goroutine calling them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} ssh_session_calls SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct ssh_connect_t));
+    __uint(max_entries, 1);
+} ssh_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 client_config_user_pos;
+
+static __always_inline void start_connect(struct pt_regs *ctx, u8 from_dial) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&ssh_connects, &key) != NULL) {
+        // NewClientConn called by Dial.
+        return;
+    }
+
+    u32 zero = 0;
+    struct ssh_connect_t *connect = bpf_map_lookup_elem(&ssh_storage_map, &zero);
+    if (connect == NULL) {
+        bpf_printk("ssh connect: connect is NULL");
+        return;
+    }
+    __builtin_memset(connect, 0, sizeof(struct ssh_connect_t));
+    connect->from_dial = from_dial;
+    struct ssh_event_t *event = &connect->event;
+    event->start_time = get_time_ns();
+
+    void *addr_ptr = get_argument(ctx, 3);
+    u64 addr_len = (u64)get_argument(ctx, 4);
+    u64 size = MAX_ADDR_SIZE - 1 < addr_len ? MAX_ADDR_SIZE - 1 : addr_len;
+    bpf_probe_read_user(event->addr, size, addr_ptr);
+
+    void *config = get_argument(ctx, 5);
+    if (config != NULL) {
+        get_go_string_from_user_ptr((void *)(config + client_config_user_pos), event->user, MAX_USER_SIZE - 1);
+    }
+
+    // The client does not accept a context.Context, the span is always a
+    // root span.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &event->psc,
+        .sc = &event->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&ssh_connects, &key, connect, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func Dial(network, addr string, config *ClientConfig) (*Client, error)
+SEC("uprobe/Dial")
+int uprobe_Dial(struct pt_regs *ctx) {
+    start_connect(ctx, 1);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func Dial(network, addr string, config *ClientConfig) (*Client, error)
+SEC("uprobe/Dial")
+int uprobe_Dial_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct ssh_connect_t *connect = bpf_map_lookup_elem(&ssh_connects, &key);
+    if (connect == NULL) {
+        return 0;
+    }
+    struct ssh_event_t *event = &connect->event;
+    event->end_time = end_time;
+
+    // The error of the handshake is read by NewClientConn. Errors dialing the
+    // server are of various types, their message is not read.
+    if (get_argument(ctx, 2) != NULL) {
+        event->has_error = 1;
+    }
+
+    output_span_event(ctx, event, sizeof(*event), &event->sc);
+    bpf_map_delete_elem(&ssh_connects, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func NewClientConn(c net.Conn, addr string, config *ClientConfig) (Conn, <-chan NewChannel, <-chan *Request, error)
+SEC("uprobe/NewClientConn")
+int uprobe_NewClientConn(struct pt_regs *ctx) {
+    start_connect(ctx, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func NewClientConn(c net.Conn, addr string, config *ClientConfig) (Conn, <-chan NewChannel, <-chan *Request, error)
+SEC("uprobe/NewClientConn")
+int uprobe_NewClientConn_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct ssh_connect_t *connect = bpf_map_lookup_elem(&ssh_connects, &key);
+    if (connect == NULL) {
+        return 0;
+    }
+    struct ssh_event_t *event = &connect->event;
+
+    void *err_type = get_argument(ctx, 5);
+    void *err_ptr = get_argument(ctx, 6);
+    if (err_type != NULL) {
+        event->has_error = 1;
+        // The errors returned are all created by errors.New or fmt.Errorf:
+        // an *errors.errorString, *fmt.wrapError, or *fmt.wrapErrors. Their
+        // message is their first field.
+        if (err_ptr != NULL) {
+            get_go_string_from_user_ptr(err_ptr, event->error, MAX_ERROR_SIZE - 1);
+        }
+    } else {
+        // The Conn returned is a *connection.
+        void *conn = get_argument(ctx, 2);
+        if (conn != NULL) {
+            struct ssh_conn_t info = {0};
+            __builtin_memcpy(info.addr, event->addr, sizeof(info.addr));
+            __builtin_memcpy(info.user, event->user, sizeof(info.user));
+            info.sc = event->sc;
+            bpf_map_update_elem(&ssh_conns, &conn, &info, 0);
+        }
+    }
+
+    if (connect->from_dial) {
+        return 0;
+    }
+
+    event->end_time = end_time;
+    output_span_event(ctx, event, sizeof(*event), &event->sc);
+    bpf_map_delete_elem(&ssh_connects, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) NewSession() (*Session, error)
+SEC("uprobe/Client_NewSession")
+int uprobe_Client_NewSession(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    if (client == NULL) {
+        return 0;
+    }
+
+    // The Conn interface embedded in Client is its first field.
+    void *conn = NULL;
+    bpf_probe_read_user(&conn, sizeof(conn), (void *)(client + 8));
+    if (conn == NULL) {
+        return 0;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&ssh_new_sessions, &key, &conn, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (c *Client) NewSession() (*Session, error)
+SEC("uprobe/Client_NewSession")
+int uprobe_Client_NewSession_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    void **conn = bpf_map_lookup_elem(&ssh_new_sessions, &key);
+    if (conn == NULL) {
+        return 0;
+    }
+
+    void *session = get_argument(ctx, 1);
+    if (session != NULL) {
+        bpf_map_update_elem(&ssh_session_conns, &session, conn, 0);
+    }
+
+    bpf_map_delete_elem(&ssh_new_sessions, &key);
+    return 0;
+}
+
+// The span of a session is the child of the span of the handshake of its
+// connection, if it is known.
+static __always_inline long get_session_parent_span_context(void *data, struct span_context *psc) {
+    struct ssh_conn_t *info = data;
+    if (info == NULL) {
+        return -1;
+    }
+    *psc = info->sc;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Session) Start(cmd string) error
+SEC("uprobe/Session_Start")
+int uprobe_Session_Start(struct pt_regs *ctx) {
+    void *session = get_argument(ctx, 1);
+    if (session == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct ssh_connect_t *storage = bpf_map_lookup_elem(&ssh_storage_map, &zero);
+    if (storage == NULL) {
+        bpf_printk("uprobe/Session_Start: storage is NULL");
+        return 0;
+    }
+    struct ssh_event_t *event = &storage->event;
+    __builtin_memset(event, 0, sizeof(struct ssh_event_t));
+    event->start_time = get_time_ns();
+    event->is_session = 1;
+
+    void *cmd_ptr = get_argument(ctx, 2);
+    u64 cmd_len = (u64)get_argument(ctx, 3);
+    u64 size = MAX_COMMAND_SIZE - 1 < cmd_len ? MAX_COMMAND_SIZE - 1 : cmd_len;
+    bpf_probe_read_user(event->command, size, cmd_ptr);
+
+    struct ssh_conn_t *info = NULL;
+    void **conn = bpf_map_lookup_elem(&ssh_session_conns, &session);
+    if (conn != NULL) {
+        info = bpf_map_lookup_elem(&ssh_conns, conn);
+    }
+    if (info != NULL) {
+        __builtin_memcpy(event->addr, info->addr, sizeof(event->addr));
+        __builtin_memcpy(event->user, info->user, sizeof(event->user));
+    }
+
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = NULL,
+        .psc = &event->psc,
+        .sc = &event->sc,
+        .get_parent_span_context_fn = get_session_parent_span_context,
+        .get_parent_span_context_arg = info,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&ssh_sessions, &session, event, 0);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&ssh_session_calls, &key, &session, 0);
+    return 0;
+}
+
+// Ends the span of the session of the call returning, if err is not NULL or
+// the call is Session.Wait.
+static __always_inline void end_session(struct pt_regs *ctx, bool wait) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    void **session = bpf_map_lookup_elem(&ssh_session_calls, &key);
+    if (session == NULL) {
+        return;
+    }
+    void *session_ptr = *session;
+    bpf_map_delete_elem(&ssh_session_calls, &key);
+
+    struct ssh_event_t *event = bpf_map_lookup_elem(&ssh_sessions, &session_ptr);
+    if (event == NULL) {
+        return;
+    }
+
+    // The error is the only result of both calls. The errors of the command
+    // are of various types, their message is not read.
+    if (get_argument(ctx, 1) != NULL) {
+        event->has_error = 1;
+    } else if (!wait) {
+        // Started, the span is ended by Session.Wait.
+        return;
+    }
+
+    event->end_time = end_time;
+    output_span_event(ctx, event, sizeof(*event), &event->sc);
+    bpf_map_delete_elem(&ssh_sessions, &session_ptr);
+    bpf_map_delete_elem(&ssh_session_conns, &session_ptr);
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (s *Session) Start(cmd string) error
+SEC("uprobe/Session_Start")
+int uprobe_Session_Start_Returns(struct pt_regs *ctx) {
+    end_session(ctx, false);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Session) Wait() error
+SEC("uprobe/Session_Wait")
+int uprobe_Session_Wait(struct pt_regs *ctx) {
+    void *session = get_argument(ctx, 1);
+    if (session == NULL) {
+        return 0;
+    }
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&ssh_session_calls, &key, &session, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (s *Session) Wait() error
+SEC("uprobe/Session_Wait")
+int uprobe_Session_Wait_Returns(struct pt_regs *ctx) {
+    end_session(ctx, true);
+    return 0;
+}
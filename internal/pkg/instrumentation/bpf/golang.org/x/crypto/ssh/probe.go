@@ -0,0 +1,198 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ssh provides an instrumentation probe for the clients of the
+// [golang.org/x/crypto/ssh] package.
+package ssh
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "golang.org/x/crypto/ssh"
+	// mod is the module of the instrumented package.
+	mod = "golang.org/x/crypto"
+
+	// RedactCommandEnvVar is the environment variable to opt-in for the
+	// redaction of the arguments of the commands run by sessions. Only the
+	// name of the program is recorded if set.
+	RedactCommandEnvVar = "OTEL_GO_AUTO_SSH_REDACT_COMMAND"
+)
+
+const (
+	// userKey is the attribute key of the user authenticating to the server.
+	userKey = attribute.Key("ssh.user")
+	// commandKey is the attribute key of the command run by a session.
+	commandKey = attribute.Key("ssh.command")
+)
+
+// New returns a new [probe.Probe].
+//
+// A span is produced for the handshake of each connection established by
+// Dial or NewClientConn, and for each command run by a session, from
+// Session.Start to Session.Wait. The clients do not accept a context, the
+// handshake spans are root spans and the session spans are their children.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	const newClientConn = pkg + ".NewClientConn"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "client_config_user_pos",
+					ID:  structfield.NewID(mod, pkg, "ClientConfig", "User"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         newClientConn,
+					EntryProbe:  "uprobe_NewClientConn",
+					ReturnProbe: "uprobe_NewClientConn_Returns",
+				},
+				{
+					// Not linked if connections are only established with
+					// NewClientConn.
+					Sym:         pkg + ".Dial",
+					EntryProbe:  "uprobe_Dial",
+					ReturnProbe: "uprobe_Dial_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{newClientConn},
+				},
+				{
+					Sym:         pkg + ".(*Client).NewSession",
+					EntryProbe:  "uprobe_Client_NewSession",
+					ReturnProbe: "uprobe_Client_NewSession_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         pkg + ".(*Session).Start",
+					EntryProbe:  "uprobe_Session_Start",
+					ReturnProbe: "uprobe_Session_Start_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         pkg + ".(*Session).Wait",
+					EntryProbe:  "uprobe_Session_Wait",
+					ReturnProbe: "uprobe_Session_Wait_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{pkg + ".(*Session).Start"},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents the handshake of a connection, or a command run by a
+// session if IsSession is set.
+type event struct {
+	context.BaseSpanProperties
+	Addr    [128]byte
+	User    [64]byte
+	Command [256]byte
+	// Error is the message of the error of a failed handshake. It is empty
+	// if the error is not returned by NewClientConn.
+	Error     [128]byte
+	IsSession uint8
+	HasError  uint8
+	_         [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	var attrs []attribute.KeyValue
+	if host, port, err := net.SplitHostPort(unix.ByteSliceToString(e.Addr[:])); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(p))
+		}
+	}
+	if user := unix.ByteSliceToString(e.User[:]); user != "" {
+		attrs = append(attrs, userKey.String(user))
+	}
+
+	name := "SSH connect"
+	if e.IsSession != 0 {
+		name = "SSH session"
+		cmd := unix.ByteSliceToString(e.Command[:])
+		if shouldRedactCommand() {
+			cmd, _, _ = strings.Cut(strings.TrimSpace(cmd), " ")
+		}
+		if cmd != "" {
+			attrs = append(attrs, commandKey.String(cmd))
+		}
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		if msg := unix.ByteSliceToString(e.Error[:]); utf8.ValidString(msg) {
+			span.Status().SetMessage(msg)
+		}
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// shouldRedactCommand returns if the user has configured the arguments of
+// the commands to be redacted.
+func shouldRedactCommand() bool {
+	val := os.Getenv(RedactCommandEnvVar)
+	if val != "" {
+		boolVal, err := strconv.ParseBool(val)
+		if err == nil {
+			return boolVal
+		}
+	}
+
+	return false
+}
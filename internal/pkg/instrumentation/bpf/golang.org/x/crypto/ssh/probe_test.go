@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ssh
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	// Sessions are children of their connection span.
+	session := f
+	session.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(cmd, errMsg string, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.Addr[:], "example.com:22")
+		copy(e.User[:], "deploy")
+		if cmd != "" {
+			e.IsSession = 1
+			e.BaseSpanProperties = session.BaseSpanProperties()
+			copy(e.Command[:], cmd)
+		}
+		copy(e.Error[:], errMsg)
+		if hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name   string
+		redact bool
+		event  *event
+		want   ptrace.SpanSlice
+	}{
+		{
+			name:  "connect",
+			event: newEvent("", "", false),
+			want: f.Spans(
+				"SSH connect",
+				ptrace.StatusCodeUnset,
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(22),
+				userKey.String("deploy"),
+			),
+		},
+		{
+			name:  "handshake failed",
+			event: newEvent("", "ssh: handshake failed: EOF", true),
+			want: f.ErrorSpans(
+				"SSH connect",
+				"ssh: handshake failed: EOF",
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(22),
+				userKey.String("deploy"),
+				semconv.ErrorTypeOther,
+			),
+		},
+		{
+			name:  "dial failed",
+			event: newEvent("", "", true),
+			want: f.Spans(
+				"SSH connect",
+				ptrace.StatusCodeError,
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(22),
+				userKey.String("deploy"),
+				semconv.ErrorTypeOther,
+			),
+		},
+		{
+			name:  "session",
+			event: newEvent("cat /etc/hostname", "", false),
+			want: session.Spans(
+				"SSH session",
+				ptrace.StatusCodeUnset,
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(22),
+				userKey.String("deploy"),
+				commandKey.String("cat /etc/hostname"),
+			),
+		},
+		{
+			name:   "redacted session",
+			redact: true,
+			event:  newEvent("mysql -psecret", "", true),
+			want: session.Spans(
+				"SSH session",
+				ptrace.StatusCodeError,
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(22),
+				userKey.String("deploy"),
+				commandKey.String("mysql"),
+				semconv.ErrorTypeOther,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RedactCommandEnvVar, strconv.FormatBool(tt.redact))
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "goroutine_spans.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_HEADERS 20
+#define METHOD_MAX_LEN 16
+#define PATH_MAX_LEN 128
+#define HOST_MAX_LEN 256
+#define REMOTE_ADDR_MAX_LEN 256
+#define CONTENT_TYPE_KEY_LENGTH 12
+#define GRPC_CONTENT_TYPE_LENGTH 16
+
+struct http2_server_span_t {
+    BASE_SPAN_PROPERTIES
+    u64 status_code;
+    u32 stream_id;
+    u8 padding[4];
+    char method[METHOD_MAX_LEN];
+    char path[PATH_MAX_LEN];
+    char host[HOST_MAX_LEN];
+    char remote_addr[REMOTE_ADDR_MAX_LEN];
+};
+
+// The fields of the HEADERS frame of a stream read by processHeaders.
+struct http2_headers_t {
+    // The span context of the traceparent header, if any.
+    struct span_context psc;
+    u32 stream_id;
+    // The stream is a gRPC call, not traced.
+    u8 grpc;
+    u8 padding[3];
+};
+
+struct http2_server_t {
+    struct http2_server_span_t span;
+    // The request is served by the net/http server, which traces it: the
+    // span is dropped.
+    u8 delegated;
+    u8 padding[7];
+};
+
+struct hpack_header_field {
+    struct go_string name;
+    struct go_string value;
+    bool sensitive;
+};
+
+// The HEADERS frames being processed, keyed by the goroutine serving their
+// connection.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct http2_headers_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} http2_headers SEC(".maps");
+
+// The HEADERS frames of the requests not handled yet, keyed by their
+// *http.Request.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct http2_headers_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} http2_requests SEC(".maps");
+
+// The requests being handled, keyed by the goroutine running their handler.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct http2_server_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} http2_server_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct http2_server_t));
+    __uint(max_entries, 1);
+} http2_server_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 frame_fields_pos;
+volatile const u64 frame_stream_id_pos;
+volatile const u64 method_ptr_pos;
+volatile const u64 url_ptr_pos;
+volatile const u64 path_ptr_pos;
+volatile const u64 ctx_ptr_pos;
+volatile const u64 host_pos;
+volatile const u64 remote_addr_pos;
+
+// Whether the google.golang.org/grpc module is linked in the process: the
+// gRPC calls served by the http2 server are then not traced.
+volatile const bool skip_grpc;
+
+// This instrumentation attaches uprobe to the following function:
+// func (sc *serverConn) processHeaders(f *MetaHeadersFrame) error
+SEC("uprobe/serverConn_processHeaders")
+int uprobe_serverConn_processHeaders(struct pt_regs *ctx) {
+    void *frame_ptr = get_argument(ctx, 2);
+    if (frame_ptr == NULL) {
+        return 0;
+    }
+
+    struct http2_headers_t headers = {0};
+
+    // The stream ID is the one of the FrameHeader of the *HeadersFrame
+    // embedded first in the MetaHeadersFrame.
+    void *headers_frame = NULL;
+    bpf_probe_read_user(&headers_frame, sizeof(headers_frame), frame_ptr);
+    if (headers_frame != NULL) {
+        bpf_probe_read_user(&headers.stream_id, sizeof(headers.stream_id), (void *)(headers_frame + frame_stream_id_pos));
+    }
+
+    struct go_slice header_fields = {0};
+    bpf_probe_read_user(&header_fields, sizeof(header_fields), (void *)(frame_ptr + frame_fields_pos));
+
+    char tp_key[W3C_KEY_LENGTH] = "traceparent";
+    char ct_key[CONTENT_TYPE_KEY_LENGTH] = "content-type";
+    char grpc_ct[GRPC_CONTENT_TYPE_LENGTH] = "application/grpc";
+    for (s32 i = 0; i < MAX_HEADERS; i++) {
+        if (i >= header_fields.len) {
+            break;
+        }
+        struct hpack_header_field hf = {0};
+        bpf_probe_read_user(&hf, sizeof(hf), (void *)(header_fields.array + (i * sizeof(hf))));
+        if (hf.name.len == W3C_KEY_LENGTH && hf.value.len == W3C_VAL_LENGTH) {
+            char name[W3C_KEY_LENGTH];
+            bpf_probe_read_user(name, sizeof(name), hf.name.str);
+            if (bpf_memcmp(tp_key, name, sizeof(tp_key))) {
+                char val[W3C_VAL_LENGTH];
+                bpf_probe_read_user(val, sizeof(val), hf.value.str);
+                w3c_string_to_span_context(val, &headers.psc);
+                continue;
+            }
+        }
+        // The content type of gRPC calls is "application/grpc", or
+        // "application/grpc+<codec>".
+        if (skip_grpc && hf.name.len == CONTENT_TYPE_KEY_LENGTH && hf.value.len >= GRPC_CONTENT_TYPE_LENGTH) {
+            char name[CONTENT_TYPE_KEY_LENGTH];
+            bpf_probe_read_user(name, sizeof(name), hf.name.str);
+            char val[GRPC_CONTENT_TYPE_LENGTH];
+            bpf_probe_read_user(val, sizeof(val), hf.value.str);
+            if (bpf_memcmp(ct_key, name, sizeof(ct_key)) && bpf_memcmp(grpc_ct, val, sizeof(grpc_ct))) {
+                headers.grpc = 1;
+            }
+        }
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&http2_headers, &key, &headers, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (sc *serverConn) processHeaders(f *MetaHeadersFrame) error
+SEC("uprobe/serverConn_processHeaders")
+int uprobe_serverConn_processHeaders_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_delete_elem(&http2_headers, &key);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (sc *serverConn) newWriterAndRequest(st *stream, f *MetaHeadersFrame) (*responseWriter, *http.Request, error)
+SEC("uprobe/serverConn_newWriterAndRequest")
+int uprobe_serverConn_newWriterAndRequest_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct http2_headers_t *headers = bpf_map_lookup_elem(&http2_headers, &key);
+    if (headers == NULL || headers->grpc) {
+        return 0;
+    }
+
+    // The request is handled by another goroutine, its headers are found by
+    // the handler with the request.
+    void *req = get_argument(ctx, 2);
+    if (req != NULL) {
+        bpf_map_update_elem(&http2_requests, &req, headers, 0);
+    }
+    return 0;
+}
+
+// The parent span context is the one of the traceparent header, it is only
+// used if one was found.
+static __always_inline long get_headers_parent_span_context(void *data, struct span_context *psc) {
+    struct http2_headers_t *headers = data;
+    if (headers == NULL || !is_span_context_valid(&headers->psc)) {
+        return -1;
+    }
+    *psc = headers->psc;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (sc *serverConn) runHandler(rw *responseWriter, req *http.Request, handler func(http.ResponseWriter, *http.Request))
+SEC("uprobe/serverConn_runHandler")
+int uprobe_serverConn_runHandler(struct pt_regs *ctx) {
+    void *req = get_argument(ctx, 3);
+    struct http2_headers_t *headers = bpf_map_lookup_elem(&http2_requests, &req);
+    if (headers == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct http2_server_t *server = bpf_map_lookup_elem(&http2_server_storage_map, &zero);
+    if (server == NULL) {
+        bpf_printk("uprobe/serverConn_runHandler: server is NULL");
+        return 0;
+    }
+    __builtin_memset(server, 0, sizeof(struct http2_server_t));
+    struct http2_server_span_t *span = &server->span;
+    span->start_time = get_time_ns();
+    span->stream_id = headers->stream_id;
+
+    get_go_string_from_user_ptr((void *)(req + method_ptr_pos), span->method, sizeof(span->method));
+    void *url_ptr = NULL;
+    bpf_probe_read_user(&url_ptr, sizeof(url_ptr), (void *)(req + url_ptr_pos));
+    if (url_ptr != NULL) {
+        get_go_string_from_user_ptr((void *)(url_ptr + path_ptr_pos), span->path, sizeof(span->path));
+    }
+    get_go_string_from_user_ptr((void *)(req + host_pos), span->host, sizeof(span->host));
+    get_go_string_from_user_ptr((void *)(req + remote_addr_pos), span->remote_addr, sizeof(span->remote_addr));
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 3, ctx_ptr_pos, false, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &span->psc,
+        .sc = &span->sc,
+        .get_parent_span_context_fn = get_headers_parent_span_context,
+        .get_parent_span_context_arg = headers,
+    };
+    start_span(&start_span_params);
+    bpf_map_delete_elem(&http2_requests, &req);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&http2_server_events, &key, server, 0);
+    start_tracking_span(go_context.data, &span->sc);
+    set_goroutine_span(key, &span->sc);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (sc *serverConn) runHandler(rw *responseWriter, req *http.Request, handler func(http.ResponseWriter, *http.Request))
+SEC("uprobe/serverConn_runHandler")
+int uprobe_serverConn_runHandler_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct http2_server_t *server = bpf_map_lookup_elem(&http2_server_events, &key);
+    if (server == NULL) {
+        return 0;
+    }
+    struct http2_server_span_t *span = &server->span;
+
+    if (server->delegated) {
+        // The span of the net/http server replaced this one in the context
+        // and the goroutine, and stopped being tracked when it ended.
+        bpf_map_delete_elem(&tracked_spans_by_sc, &span->sc);
+    } else {
+        span->end_time = end_time;
+        output_span_event(ctx, span, sizeof(*span), &span->sc);
+        stop_tracking_span(&span->sc, &span->psc);
+        delete_goroutine_span(key);
+    }
+
+    bpf_map_delete_elem(&http2_server_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (rws *responseWriterState) writeHeader(code int)
+//
+// It is called with the status of the response by responseWriter.WriteHeader,
+// and with 200 when the response is written or flushed without one.
+SEC("uprobe/responseWriterState_writeHeader")
+int uprobe_responseWriterState_writeHeader(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct http2_server_t *server = bpf_map_lookup_elem(&http2_server_events, &key);
+    if (server == NULL || server->span.status_code != 0) {
+        return 0;
+    }
+
+    // Informational responses (1xx) are sent before the final one.
+    u64 code = (u64)get_argument(ctx, 2);
+    if (code >= 200) {
+        server->span.status_code = code;
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (sh serverHandler) ServeHTTP(rw ResponseWriter, req *Request)
+//
+// The servers configured with ConfigureServer serve the HTTP/2 requests of a
+// net/http Server with the handler of the Server, they are traced by the
+// net/http server probe.
+SEC("uprobe/serverHandler_ServeHTTP")
+int uprobe_serverHandler_ServeHTTP(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct http2_server_t *server = bpf_map_lookup_elem(&http2_server_events, &key);
+    if (server != NULL) {
+        server->delegated = 1;
+    }
+    return 0;
+}
@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package http2 provides an instrumentation probe for the servers of the
+// [golang.org/x/net/http2] package.
+package http2
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// pkg is the package being instrumented.
+	pkg = "golang.org/x/net/http2"
+	// mod is the module of the instrumented package.
+	mod = "golang.org/x/net"
+	// grpcMod is the module of the gRPC calls not traced.
+	grpcMod = "google.golang.org/grpc"
+)
+
+// streamIDKey is the attribute key of the ID of the HTTP/2 stream of the
+// request.
+const streamIDKey = attribute.Key("http2.stream.id")
+
+// New returns a new [probe.Probe].
+//
+// A SERVER span is produced for each request served by a Server with
+// ServeConn, from the time its handler is run to the time it returns. The
+// requests of a net/http Server configured with ConfigureServer are traced by
+// the net/http server probe, and the gRPC calls are not traced if the
+// google.golang.org/grpc module is linked: the gRPC server probe traces them.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindServer,
+		InstrumentedPkg: pkg,
+	}
+
+	const runHandler = pkg + ".(*serverConn).runHandler"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				grpcConst{},
+				probe.StructFieldConst{
+					Key: "frame_fields_pos",
+					ID:  structfield.NewID(mod, pkg, "MetaHeadersFrame", "Fields"),
+				},
+				probe.StructFieldConst{
+					Key: "frame_stream_id_pos",
+					ID:  structfield.NewID(mod, pkg, "FrameHeader", "StreamID"),
+				},
+				probe.StructFieldConst{
+					Key: "method_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "Method"),
+				},
+				probe.StructFieldConst{
+					Key: "url_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "URL"),
+				},
+				probe.StructFieldConst{
+					Key: "path_ptr_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Path"),
+				},
+				probe.StructFieldConst{
+					Key: "ctx_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "ctx"),
+				},
+				probe.StructFieldConst{
+					Key: "host_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "Host"),
+				},
+				probe.StructFieldConst{
+					Key: "remote_addr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "RemoteAddr"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         runHandler,
+					EntryProbe:  "uprobe_serverConn_runHandler",
+					ReturnProbe: "uprobe_serverConn_runHandler_Returns",
+				},
+				{
+					Sym:         pkg + ".(*serverConn).processHeaders",
+					EntryProbe:  "uprobe_serverConn_processHeaders",
+					ReturnProbe: "uprobe_serverConn_processHeaders_Returns",
+					DependsOn:   []string{runHandler},
+				},
+				{
+					Sym:         pkg + ".(*serverConn).newWriterAndRequest",
+					ReturnProbe: "uprobe_serverConn_newWriterAndRequest_Returns",
+					DependsOn:   []string{runHandler},
+				},
+				{
+					Sym:         pkg + ".(*responseWriterState).writeHeader",
+					EntryProbe:  "uprobe_responseWriterState_writeHeader",
+					FailureMode: probe.FailureModeWarn,
+					DependsOn:   []string{runHandler},
+				},
+				{
+					// Only called by the servers configured with
+					// ConfigureServer, the requests served with the
+					// handler of the net/http Server are not traced.
+					Sym:         "net/http.serverHandler.ServeHTTP",
+					EntryProbe:  "uprobe_serverHandler_ServeHTTP",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{runHandler},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// grpcConst is a [probe.Const] injecting whether the gRPC calls are skipped:
+// if the google.golang.org/grpc module is linked in the process.
+type grpcConst struct{}
+
+// InjectOption returns the [inject.Option] of the const.
+func (grpcConst) InjectOption(info *process.Info) (inject.Option, error) {
+	_, ok := info.Modules[grpcMod]
+	return inject.WithKeyValue("skip_grpc", ok), nil
+}
+
+// event represents a request served.
+type event struct {
+	context.BaseSpanProperties
+	StatusCode uint64
+	StreamID   uint32
+	_          [4]byte // padding
+	Method     [16]byte
+	Path       [128]byte
+	Host       [256]byte
+	RemoteAddr [256]byte
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	method := unix.ByteSliceToString(e.Method[:])
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLPath(unix.ByteSliceToString(e.Path[:])),
+	}
+
+	// https://www.rfc-editor.org/rfc/rfc9110.html#name-status-codes
+	const maxStatus = 599
+	if e.StatusCode > 0 && e.StatusCode <= maxStatus {
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(int(e.StatusCode))) // nolint: gosec  // Bound checked.
+	}
+
+	attrs = append(attrs, netattr.Attributes(
+		netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:])),
+		netattr.ParseHostPort(unix.ByteSliceToString(e.RemoteAddr[:])),
+	)...)
+	attrs = append(attrs,
+		semconv.NetworkProtocolVersion("2"),
+		streamIDKey.Int64(int64(e.StreamID)),
+	)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(method)
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	if e.StatusCode >= 500 && e.StatusCode <= maxStatus {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	return spans
+}
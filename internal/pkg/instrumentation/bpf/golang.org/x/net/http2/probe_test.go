@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package http2
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindServer)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(method, path string, status uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			StatusCode:         status,
+			StreamID:           3,
+		}
+		copy(e.Method[:], method)
+		copy(e.Path[:], path)
+		copy(e.Host[:], "example.com:8443")
+		copy(e.RemoteAddr[:], "10.0.0.1:54321")
+		return e
+	}
+
+	netAttrs := []attribute.KeyValue{
+		semconv.NetworkPeerAddress("10.0.0.1"),
+		semconv.NetworkPeerPort(54321),
+		semconv.ServerAddress("example.com"),
+		semconv.ServerPort(8443),
+		semconv.NetworkTransportTCP,
+		semconv.NetworkProtocolVersion("2"),
+		streamIDKey.Int64(3),
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "OK",
+			event: newEvent("GET", "/foo/bar", 200),
+			want: f.Spans("GET", ptrace.StatusCodeUnset, append([]attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String("GET"),
+				semconv.URLPath("/foo/bar"),
+				semconv.HTTPResponseStatusCode(200),
+			}, netAttrs...)...),
+		},
+		{
+			name:  "ServerError",
+			event: newEvent("POST", "/upload", 503),
+			want: f.Spans("POST", ptrace.StatusCodeError, append([]attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String("POST"),
+				semconv.URLPath("/upload"),
+				semconv.HTTPResponseStatusCode(503),
+			}, netAttrs...)...),
+		},
+		{
+			name:  "NoStatus",
+			event: newEvent("GET", "/", 0),
+			want: f.Spans("GET", ptrace.StatusCodeUnset, append([]attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String("GET"),
+				semconv.URLPath("/"),
+			}, netAttrs...)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestGRPCConst(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		modules map[string]*semver.Version
+		want    bool
+	}{
+		{
+			name:    "NoGRPC",
+			modules: map[string]*semver.Version{mod: semver.MustParse("0.42.0")},
+			want:    false,
+		},
+		{
+			name: "GRPC",
+			modules: map[string]*semver.Version{
+				mod:     semver.MustParse("0.42.0"),
+				grpcMod: semver.MustParse("1.73.0"),
+			},
+			want: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &process.Info{Modules: tt.modules}
+			opt, err := grpcConst{}.InjectOption(info)
+			require.NoError(t, err)
+			assert.Equal(t, inject.WithKeyValue("skip_grpc", tt.want), opt)
+		})
+	}
+}
@@ -5,14 +5,20 @@
 #include "go_types.h"
 #include "trace/span_context.h"
 #include "go_context.h"
+#include "grpc_client.h"
 #include "uprobe.h"
 #include "trace/start_span.h"
+#include "trace/tracestate.h"
 
 char __license[] SEC("license") = "Dual MIT/GPL";
 
 #define MAX_SIZE 50
 #define MAX_CONCURRENT 50
 #define MAX_ERROR_LEN 128
+#define MAX_HEADERS 20
+#define GRPC_STATUS_KEY_LEN 11
+#define GRPC_MESSAGE_KEY_LEN 12
+#define MAX_GRPC_STATUS_LEN 2
 
 struct grpc_request_t
 {
@@ -21,6 +27,10 @@ struct grpc_request_t
     char method[MAX_SIZE];
     char target[MAX_SIZE];
     u32 status_code;
+    struct tracestate tracestate;
+    // The status and message are the ones of the trailers of the response,
+    // the message is percent-encoded.
+    u8 status_from_trailers;
 };
 
 struct hpack_header_field
@@ -46,6 +56,24 @@ struct
     __uint(max_entries, MAX_CONCURRENT);
 } streamid_to_span_contexts SEC(".maps");
 
+// The goroutines invoking the RPCs of the client streams. The streams
+// without trailers are evicted.
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, u32);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} streamid_to_goroutines SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct grpc_request_t));
+    __uint(max_entries, 1);
+} grpc_storage_map SEC(".maps");
+
 // Injected in init
 volatile const u64 clientconn_target_ptr_pos;
 volatile const u64 httpclient_nextid_pos;
@@ -55,6 +83,8 @@ volatile const u64 error_status_pos;
 volatile const u64 status_s_pos;
 volatile const u64 status_message_pos;
 volatile const u64 status_code_pos;
+volatile const u64 frame_fields_pos;
+volatile const u64 frame_stream_id_pos;
 
 volatile const bool write_status_supported;
 
@@ -80,37 +110,52 @@ int uprobe_ClientConn_Invoke(struct pt_regs *ctx)
         return 0;
     }
 
-    struct grpc_request_t grpcReq = {};
-    grpcReq.start_time = bpf_ktime_get_ns();
+    u32 zero = 0;
+    struct grpc_request_t *grpcReq = bpf_map_lookup_elem(&grpc_storage_map, &zero);
+    if (grpcReq == NULL)
+    {
+        bpf_printk("uprobe/ClientConn_Invoke: failed to get grpcReq");
+        return 0;
+    }
+    __builtin_memset(grpcReq, 0, sizeof(struct grpc_request_t));
+    grpcReq->start_time = get_time_ns();
 
     // Read Method
     void *method_ptr = get_argument(ctx, method_ptr_pos);
     u64 method_len = (u64)get_argument(ctx, method_len_pos);
-    u64 method_size = sizeof(grpcReq.method);
+    u64 method_size = sizeof(grpcReq->method);
     method_size = method_size < method_len ? method_size : method_len;
-    bpf_probe_read(&grpcReq.method, method_size, method_ptr);
+    bpf_probe_read(&grpcReq->method, method_size, method_ptr);
 
     // Read ClientConn.Target
     void *clientconn_ptr = get_argument(ctx, clientconn_pos);
-    if (!get_go_string_from_user_ptr((void*)(clientconn_ptr + clientconn_target_ptr_pos), grpcReq.target, sizeof(grpcReq.target)))
+    if (!get_go_string_from_user_ptr((void*)(clientconn_ptr + clientconn_target_ptr_pos), grpcReq->target, sizeof(grpcReq->target)))
     {
         bpf_printk("target write failed, aborting ebpf probe");
         return 0;
     }
 
-    start_span_params_t start_span_params = {
-        .ctx = ctx,
-        .go_context = &go_context,
-        .psc = &grpcReq.psc,
-        .sc = &grpcReq.sc,
-        .get_parent_span_context_fn = NULL,
-        .get_parent_span_context_arg = NULL,
-    };
-    start_span(&start_span_params);
+    struct span_context *owner_sc = get_grpc_client_owner(key);
+    if (owner_sc != NULL) {
+        // The RPC is invoked for the span of another client probe, its span
+        // context is propagated and no span is produced for the RPC.
+        grpcReq->sc = *owner_sc;
+    } else {
+        start_span_params_t start_span_params = {
+            .ctx = ctx,
+            .go_context = &go_context,
+            .psc = &grpcReq->psc,
+            .sc = &grpcReq->sc,
+            .get_parent_span_context_fn = NULL,
+            .get_parent_span_context_arg = NULL,
+        };
+        start_span(&start_span_params);
+    }
+    copy_remote_tracestate(&grpcReq->sc, &grpcReq->tracestate);
 
     // Write event
-    bpf_map_update_elem(&grpc_events, &key, &grpcReq, 0);
-    start_tracking_span(go_context.data, &grpcReq.sc);
+    bpf_map_update_elem(&grpc_events, &key, grpcReq, 0);
+    start_tracking_span(go_context.data, &grpcReq->sc);
     return 0;
 }
 
@@ -128,6 +173,8 @@ int uprobe_ClientConn_Invoke_Returns(struct pt_regs *ctx) {
     if(!write_status_supported) {
         goto done;
     }
+    // The status of the returned error is used instead of the one of the
+    // trailers.
     // Getting the returned response (error)
     // The status code is embedded 3 layers deep:
     // Invoke() error
@@ -159,16 +206,56 @@ int uprobe_ClientConn_Invoke_Returns(struct pt_regs *ctx) {
     // Get status code from Status.s pointer
     bpf_probe_read_user(&grpc_span->status_code, sizeof(grpc_span->status_code), (void *)(s_ptr + status_code_pos));
     get_go_string_from_user_ptr((void *)(s_ptr + status_message_pos), grpc_span->err_msg, sizeof(grpc_span->err_msg));
+    grpc_span->status_from_trailers = 0;
 
 done:
-    grpc_span->end_time = bpf_ktime_get_ns();
-    output_span_event(ctx, grpc_span, sizeof(*grpc_span), &grpc_span->sc);
+    grpc_span->end_time = get_time_ns();
+    if (get_grpc_client_owner(key) == NULL) {
+        output_span_event(ctx, grpc_span, sizeof(*grpc_span), &grpc_span->sc);
+    }
     stop_tracking_span(&grpc_span->sc, &grpc_span->psc);
     bpf_map_delete_elem(&grpc_events, &key);
     return 0;
 }
 
+// Returns true if the header fields of the slice located at hf_ptr include a
+// traceparent, e.g. one set by the application in the outgoing metadata.
+static __always_inline bool has_traceparent(void *hf_ptr) {
+    struct go_slice fields = {0};
+    if (bpf_probe_read_user(&fields, sizeof(fields), hf_ptr) != 0) {
+        return false;
+    }
+
+    char tp_key[W3C_KEY_LENGTH] = "traceparent";
+    for (s32 i = 0; i < MAX_HEADERS; i++) {
+        if (i >= fields.len) {
+            break;
+        }
+        struct hpack_header_field hf = {};
+        if (bpf_probe_read_user(&hf, sizeof(hf), (void *)(fields.array + (i * sizeof(hf)))) != 0) {
+            break;
+        }
+        if (hf.name.len != W3C_KEY_LENGTH) {
+            continue;
+        }
+        char name[W3C_KEY_LENGTH];
+        if (bpf_probe_read_user(name, sizeof(name), hf.name.str) != 0) {
+            continue;
+        }
+        // The metadata keys are lowercase.
+        if (bpf_memcmp(tp_key, name, sizeof(tp_key))) {
+            return true;
+        }
+    }
+    return false;
+}
+
 // func (l *loopyWriter) headerHandler(h *headerFrame) error
+//
+// Appends the traceparent of the span of the RPC, and the tracestate of its
+// remote parent, to the headers of the stream. The sampled flag of the
+// traceparent is the sampling decision of the span. Nothing is appended if
+// the headers already have a traceparent.
 SEC("uprobe/loopyWriter_headerHandler")
 int uprobe_LoopyWriter_HeaderHandler(struct pt_regs *ctx)
 {
@@ -181,6 +268,11 @@ int uprobe_LoopyWriter_HeaderHandler(struct pt_regs *ctx)
         return 0;
     }
 
+    // The context propagated by the application is not overridden.
+    if (has_traceparent((void *)(headerFrame_ptr + (headerFrame_hf_pos)))) {
+        goto done;
+    }
+
     struct span_context current_span_context = {};
     bpf_probe_read(&current_span_context, sizeof(current_span_context), sc_ptr);
 
@@ -203,6 +295,27 @@ int uprobe_LoopyWriter_HeaderHandler(struct pt_regs *ctx)
     hf.name = key_str;
     hf.value = val_str;
     append_item_to_slice(&hf, sizeof(hf), (void *)(headerFrame_ptr + (headerFrame_hf_pos)));
+
+    // Propagate the tracestate of the remote parent, if any.
+    struct tracestate *ts = get_remote_tracestate(&current_span_context);
+    if (ts == NULL || ts->len == 0 || ts->len > TRACESTATE_MAX_LEN) {
+        goto done;
+    }
+    char ts_key[TRACESTATE_KEY_LENGTH] = "tracestate";
+    struct go_string ts_key_str = write_user_go_string(ts_key, sizeof(ts_key));
+    if (ts_key_str.len == 0) {
+        bpf_printk("tracestate key write failed");
+        goto done;
+    }
+    struct go_string ts_val_str = write_user_go_string(ts->value, ts->len);
+    if (ts_val_str.len == 0) {
+        bpf_printk("tracestate val write failed");
+        goto done;
+    }
+    struct hpack_header_field ts_hf = {};
+    ts_hf.name = ts_key_str;
+    ts_hf.value = ts_val_str;
+    append_item_to_slice(&ts_hf, sizeof(ts_hf), (void *)(headerFrame_ptr + (headerFrame_hf_pos)));
 done:
     bpf_map_delete_elem(&streamid_to_span_contexts, &stream_id);
 
@@ -225,5 +338,102 @@ int uprobe_http2Client_NewStream(struct pt_regs *ctx)
         bpf_map_update_elem(&streamid_to_span_contexts, &nextid, current_span_context, 0);
     }
 
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&grpc_events, &key) != NULL) {
+        bpf_map_update_elem(&streamid_to_goroutines, &nextid, &key, 0);
+    }
+
+    return 0;
+}
+
+// Parses the decimal grpc-status header value of len bytes at str.
+static __always_inline bool parse_grpc_status(void *str, u64 len, u32 *code) {
+    if (len == 0 || len > MAX_GRPC_STATUS_LEN) {
+        return false;
+    }
+    char digits[MAX_GRPC_STATUS_LEN] = {0};
+    u64 size = len < MAX_GRPC_STATUS_LEN ? len : MAX_GRPC_STATUS_LEN;
+    if (bpf_probe_read_user(digits, size, str) != 0) {
+        return false;
+    }
+    u32 value = 0;
+    for (u64 i = 0; i < MAX_GRPC_STATUS_LEN; i++) {
+        if (i >= len) {
+            break;
+        }
+        if (digits[i] < '0' || digits[i] > '9') {
+            return false;
+        }
+        value = value * 10 + (digits[i] - '0');
+    }
+    *code = value;
+    return true;
+}
+
+// func (t *http2Client) operateHeaders(frame *http2.MetaHeadersFrame)
+//
+// Records the status of the trailers of the responses to the RPCs.
+SEC("uprobe/http2Client_operateHeaders")
+int uprobe_http2Client_operateHeaders(struct pt_regs *ctx)
+{
+    void *frame_ptr = get_argument(ctx, 2);
+    void *headers_frame = NULL;
+    bpf_probe_read_user(&headers_frame, sizeof(headers_frame), frame_ptr);
+    u32 stream_id = 0;
+    bpf_probe_read_user(&stream_id, sizeof(stream_id), (void *)(headers_frame + frame_stream_id_pos));
+
+    void **key = bpf_map_lookup_elem(&streamid_to_goroutines, &stream_id);
+    if (key == NULL) {
+        return 0;
+    }
+    void *goroutine = *key;
+    struct grpc_request_t *grpc_span = bpf_map_lookup_elem(&grpc_events, &goroutine);
+    if (grpc_span == NULL) {
+        bpf_map_delete_elem(&streamid_to_goroutines, &stream_id);
+        return 0;
+    }
+
+    struct go_slice header_fields = {};
+    bpf_probe_read_user(&header_fields, sizeof(header_fields), (void *)(frame_ptr + frame_fields_pos));
+
+    char status_key[GRPC_STATUS_KEY_LEN] = "grpc-status";
+    char message_key[GRPC_MESSAGE_KEY_LEN] = "grpc-message";
+    bool found_status = false;
+    for (s32 i = 0; i < MAX_HEADERS; i++)
+    {
+        if (i >= header_fields.len)
+        {
+            break;
+        }
+        struct hpack_header_field hf = {};
+        bpf_probe_read_user(&hf, sizeof(hf), (void *)(header_fields.array + (i * sizeof(hf))));
+        if (hf.name.len == GRPC_STATUS_KEY_LEN)
+        {
+            char current_key[GRPC_STATUS_KEY_LEN];
+            bpf_probe_read_user(current_key, sizeof(current_key), hf.name.str);
+            if (bpf_memcmp(status_key, current_key, sizeof(status_key)))
+            {
+                found_status = parse_grpc_status(hf.value.str, hf.value.len, &grpc_span->status_code);
+                continue;
+            }
+        }
+        if (hf.name.len == GRPC_MESSAGE_KEY_LEN)
+        {
+            char current_key[GRPC_MESSAGE_KEY_LEN];
+            bpf_probe_read_user(current_key, sizeof(current_key), hf.name.str);
+            if (bpf_memcmp(message_key, current_key, sizeof(message_key)))
+            {
+                u64 size = hf.value.len < MAX_ERROR_LEN ? hf.value.len : MAX_ERROR_LEN;
+                __builtin_memset(grpc_span->err_msg, 0, sizeof(grpc_span->err_msg));
+                bpf_probe_read_user(grpc_span->err_msg, size, hf.value.str);
+            }
+        }
+    }
+
+    if (found_status) {
+        // Trailers end the stream.
+        grpc_span->status_from_trailers = 1;
+        bpf_map_delete_elem(&streamid_to_goroutines, &stream_id);
+    }
     return 0;
 }
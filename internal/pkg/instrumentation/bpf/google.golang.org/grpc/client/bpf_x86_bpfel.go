@@ -14,15 +14,18 @@ import (
 )
 
 type bpfGrpcRequestT struct {
-	_          structs.HostLayout
-	StartTime  uint64
-	EndTime    uint64
-	Sc         bpfSpanContext
-	Psc        bpfSpanContext
-	ErrMsg     [128]int8
-	Method     [50]int8
-	Target     [50]int8
-	StatusCode uint32
+	_                  structs.HostLayout
+	StartTime          uint64
+	EndTime            uint64
+	Sc                 bpfSpanContext
+	Psc                bpfSpanContext
+	ErrMsg             [128]int8
+	Method             [50]int8
+	Target             [50]int8
+	StatusCode         uint32
+	Tracestate         bpfTracestate
+	StatusFromTrailers uint8
+	_                  [7]byte
 }
 
 type bpfSliceArrayBuff struct {
@@ -38,6 +41,17 @@ type bpfSpanContext struct {
 	Padding    [7]uint8
 }
 
+type bpfTraceIdKey struct {
+	_       structs.HostLayout
+	TraceID [16]uint8
+}
+
+type bpfTracestate struct {
+	_     structs.HostLayout
+	Len   uint64
+	Value [512]int8
+}
+
 // loadBpf returns the embedded CollectionSpec for bpf.
 func loadBpf() (*ebpf.CollectionSpec, error) {
 	reader := bytes.NewReader(_BpfBytes)
@@ -80,10 +94,11 @@ type bpfSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfProgramSpecs struct {
-	UprobeClientConnInvoke         *ebpf.ProgramSpec `ebpf:"uprobe_ClientConn_Invoke"`
-	UprobeClientConnInvokeReturns  *ebpf.ProgramSpec `ebpf:"uprobe_ClientConn_Invoke_Returns"`
-	UprobeLoopyWriterHeaderHandler *ebpf.ProgramSpec `ebpf:"uprobe_LoopyWriter_HeaderHandler"`
-	UprobeHttp2ClientNewStream     *ebpf.ProgramSpec `ebpf:"uprobe_http2Client_NewStream"`
+	UprobeClientConnInvoke          *ebpf.ProgramSpec `ebpf:"uprobe_ClientConn_Invoke"`
+	UprobeClientConnInvokeReturns   *ebpf.ProgramSpec `ebpf:"uprobe_ClientConn_Invoke_Returns"`
+	UprobeLoopyWriterHeaderHandler  *ebpf.ProgramSpec `ebpf:"uprobe_LoopyWriter_HeaderHandler"`
+	UprobeHttp2ClientNewStream      *ebpf.ProgramSpec `ebpf:"uprobe_http2Client_NewStream"`
+	UprobeHttp2ClientOperateHeaders *ebpf.ProgramSpec `ebpf:"uprobe_http2Client_operateHeaders"`
 }
 
 // bpfMapSpecs contains maps before they are loaded into the kernel.
@@ -93,11 +108,16 @@ type bpfMapSpecs struct {
 	AllocMap               *ebpf.MapSpec `ebpf:"alloc_map"`
 	Events                 *ebpf.MapSpec `ebpf:"events"`
 	GoContextToSc          *ebpf.MapSpec `ebpf:"go_context_to_sc"`
+	GrpcClientOwners       *ebpf.MapSpec `ebpf:"grpc_client_owners"`
 	GrpcEvents             *ebpf.MapSpec `ebpf:"grpc_events"`
+	GrpcStorageMap         *ebpf.MapSpec `ebpf:"grpc_storage_map"`
 	ProbeActiveSamplerMap  *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
 	SamplersConfigMap      *ebpf.MapSpec `ebpf:"samplers_config_map"`
 	SliceArrayBuffMap      *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
+	StreamidToGoroutines   *ebpf.MapSpec `ebpf:"streamid_to_goroutines"`
 	StreamidToSpanContexts *ebpf.MapSpec `ebpf:"streamid_to_span_contexts"`
+	TracestateByTraceId    *ebpf.MapSpec `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap   *ebpf.MapSpec `ebpf:"tracestate_storage_map"`
 	TrackedSpansBySc       *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
 }
 
@@ -105,9 +125,12 @@ type bpfMapSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfVariableSpecs struct {
+	BootClockSupported     *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
 	ClientconnTargetPtrPos *ebpf.VariableSpec `ebpf:"clientconn_target_ptr_pos"`
 	EndAddr                *ebpf.VariableSpec `ebpf:"end_addr"`
 	ErrorStatusPos         *ebpf.VariableSpec `ebpf:"error_status_pos"`
+	FrameFieldsPos         *ebpf.VariableSpec `ebpf:"frame_fields_pos"`
+	FrameStreamIdPos       *ebpf.VariableSpec `ebpf:"frame_stream_id_pos"`
 	HeaderFrameHfPos       *ebpf.VariableSpec `ebpf:"headerFrame_hf_pos"`
 	HeaderFrameStreamidPos *ebpf.VariableSpec `ebpf:"headerFrame_streamid_pos"`
 	Hex                    *ebpf.VariableSpec `ebpf:"hex"`
@@ -143,11 +166,16 @@ type bpfMaps struct {
 	AllocMap               *ebpf.Map `ebpf:"alloc_map"`
 	Events                 *ebpf.Map `ebpf:"events"`
 	GoContextToSc          *ebpf.Map `ebpf:"go_context_to_sc"`
+	GrpcClientOwners       *ebpf.Map `ebpf:"grpc_client_owners"`
 	GrpcEvents             *ebpf.Map `ebpf:"grpc_events"`
+	GrpcStorageMap         *ebpf.Map `ebpf:"grpc_storage_map"`
 	ProbeActiveSamplerMap  *ebpf.Map `ebpf:"probe_active_sampler_map"`
 	SamplersConfigMap      *ebpf.Map `ebpf:"samplers_config_map"`
 	SliceArrayBuffMap      *ebpf.Map `ebpf:"slice_array_buff_map"`
+	StreamidToGoroutines   *ebpf.Map `ebpf:"streamid_to_goroutines"`
 	StreamidToSpanContexts *ebpf.Map `ebpf:"streamid_to_span_contexts"`
+	TracestateByTraceId    *ebpf.Map `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap   *ebpf.Map `ebpf:"tracestate_storage_map"`
 	TrackedSpansBySc       *ebpf.Map `ebpf:"tracked_spans_by_sc"`
 }
 
@@ -156,11 +184,16 @@ func (m *bpfMaps) Close() error {
 		m.AllocMap,
 		m.Events,
 		m.GoContextToSc,
+		m.GrpcClientOwners,
 		m.GrpcEvents,
+		m.GrpcStorageMap,
 		m.ProbeActiveSamplerMap,
 		m.SamplersConfigMap,
 		m.SliceArrayBuffMap,
+		m.StreamidToGoroutines,
 		m.StreamidToSpanContexts,
+		m.TracestateByTraceId,
+		m.TracestateStorageMap,
 		m.TrackedSpansBySc,
 	)
 }
@@ -169,9 +202,12 @@ func (m *bpfMaps) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfVariables struct {
+	BootClockSupported     *ebpf.Variable `ebpf:"boot_clock_supported"`
 	ClientconnTargetPtrPos *ebpf.Variable `ebpf:"clientconn_target_ptr_pos"`
 	EndAddr                *ebpf.Variable `ebpf:"end_addr"`
 	ErrorStatusPos         *ebpf.Variable `ebpf:"error_status_pos"`
+	FrameFieldsPos         *ebpf.Variable `ebpf:"frame_fields_pos"`
+	FrameStreamIdPos       *ebpf.Variable `ebpf:"frame_stream_id_pos"`
 	HeaderFrameHfPos       *ebpf.Variable `ebpf:"headerFrame_hf_pos"`
 	HeaderFrameStreamidPos *ebpf.Variable `ebpf:"headerFrame_streamid_pos"`
 	Hex                    *ebpf.Variable `ebpf:"hex"`
@@ -188,10 +224,11 @@ type bpfVariables struct {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfPrograms struct {
-	UprobeClientConnInvoke         *ebpf.Program `ebpf:"uprobe_ClientConn_Invoke"`
-	UprobeClientConnInvokeReturns  *ebpf.Program `ebpf:"uprobe_ClientConn_Invoke_Returns"`
-	UprobeLoopyWriterHeaderHandler *ebpf.Program `ebpf:"uprobe_LoopyWriter_HeaderHandler"`
-	UprobeHttp2ClientNewStream     *ebpf.Program `ebpf:"uprobe_http2Client_NewStream"`
+	UprobeClientConnInvoke          *ebpf.Program `ebpf:"uprobe_ClientConn_Invoke"`
+	UprobeClientConnInvokeReturns   *ebpf.Program `ebpf:"uprobe_ClientConn_Invoke_Returns"`
+	UprobeLoopyWriterHeaderHandler  *ebpf.Program `ebpf:"uprobe_LoopyWriter_HeaderHandler"`
+	UprobeHttp2ClientNewStream      *ebpf.Program `ebpf:"uprobe_http2Client_NewStream"`
+	UprobeHttp2ClientOperateHeaders *ebpf.Program `ebpf:"uprobe_http2Client_operateHeaders"`
 }
 
 func (p *bpfPrograms) Close() error {
@@ -200,6 +237,7 @@ func (p *bpfPrograms) Close() error {
 		p.UprobeClientConnInvokeReturns,
 		p.UprobeLoopyWriterHeaderHandler,
 		p.UprobeHttp2ClientNewStream,
+		p.UprobeHttp2ClientOperateHeaders,
 	)
 }
 
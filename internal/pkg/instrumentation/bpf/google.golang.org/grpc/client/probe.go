@@ -9,8 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"strconv"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cilium/ebpf"
@@ -24,6 +24,7 @@ import (
 	"go.opentelemetry.io/auto/internal/pkg/inject"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
 	"go.opentelemetry.io/auto/internal/pkg/process"
@@ -37,11 +38,10 @@ const (
 	pkg = "google.golang.org/grpc"
 )
 
-var (
-	writeStatus           = false
-	writeStatusMinVersion = semver.New(1, 40, 0, "", "")
-)
+var writeStatusMinVersion = semver.New(1, 40, 0, "", "")
 
+// writeStatusConst is a Probe Const defining if the statuses of the errors
+// returned by the RPCs are read.
 type writeStatusConst struct{}
 
 func (w writeStatusConst) InjectOption(info *process.Info) (inject.Option, error) {
@@ -49,13 +49,19 @@ func (w writeStatusConst) InjectOption(info *process.Info) (inject.Option, error
 	if !ok {
 		return nil, fmt.Errorf("unknown module version: %s", pkg)
 	}
-	if ver.GreaterThanEqual(writeStatusMinVersion) {
-		writeStatus = true
-	}
-	return inject.WithKeyValue("write_status_supported", writeStatus), nil
+	supported := ver.GreaterThanEqual(writeStatusMinVersion)
+	return inject.WithKeyValue("write_status_supported", supported), nil
 }
 
 // New returns a new [probe.Probe].
+//
+// The status of the RPCs is the one of the errors they return, or the one of
+// the trailers of their response for the versions the status of the errors is
+// not read. All the statuses but OK are recorded as errors.
+//
+// The span context of the RPCs is propagated in the traceparent metadata of
+// their requests, along with the tracestate of their remote parent, unless a
+// traceparent is already set in the metadata by the application.
 func New(logger *slog.Logger, version string) probe.Probe {
 	id := probe.ID{
 		SpanKind:        trace.SpanKindClient,
@@ -67,6 +73,7 @@ func New(logger *slog.Logger, version string) probe.Probe {
 			Logger: logger,
 			Consts: []probe.Const{
 				probe.AllocationConst{},
+				probe.BootClockConst{},
 				writeStatusConst{},
 				probe.StructFieldConst{
 					Key: "clientconn_target_ptr_pos",
@@ -152,6 +159,24 @@ func New(logger *slog.Logger, version string) probe.Probe {
 					},
 					MinVersion: writeStatusMinVersion,
 				},
+				probe.StructFieldConst{
+					Key: "frame_fields_pos",
+					ID: structfield.NewID(
+						"golang.org/x/net",
+						"golang.org/x/net/http2",
+						"MetaHeadersFrame",
+						"Fields",
+					),
+				},
+				probe.StructFieldConst{
+					Key: "frame_stream_id_pos",
+					ID: structfield.NewID(
+						"golang.org/x/net",
+						"golang.org/x/net/http2",
+						"FrameHeader",
+						"StreamID",
+					),
+				},
 			},
 			Uprobes: []*probe.Uprobe{
 				{
@@ -167,6 +192,11 @@ func New(logger *slog.Logger, version string) probe.Probe {
 					Sym:        "google.golang.org/grpc/internal/transport.(*loopyWriter).headerHandler",
 					EntryProbe: "uprobe_LoopyWriter_HeaderHandler",
 				},
+				{
+					Sym:         "google.golang.org/grpc/internal/transport.(*http2Client).operateHeaders",
+					EntryProbe:  "uprobe_http2Client_operateHeaders",
+					FailureMode: probe.FailureModeIgnore,
+				},
 			},
 			SpecFn: verifyAndLoadBpf,
 		},
@@ -193,31 +223,23 @@ type event struct {
 	Method     [50]byte
 	Target     [50]byte
 	StatusCode int32
+	TraceState context.TraceState
+	// StatusFromTrailers is not zero if the status is the one of the trailers
+	// of the response, its ErrMsg is percent-encoded.
+	StatusFromTrailers uint8
+	_                  [7]byte // padding
 }
 
 func processFn(e *event) ptrace.SpanSlice {
 	method := unix.ByteSliceToString(e.Method[:])
-	address := unix.ByteSliceToString(e.Target[:])
-
-	var port int
-	host, portStr, err := net.SplitHostPort(address)
-	if err == nil {
-		port, _ = strconv.Atoi(portStr)
-	} else {
-		host = address
-	}
+	target := unix.ByteSliceToString(e.Target[:])
 
 	attrs := []attribute.KeyValue{
 		semconv.RPCSystemKey.String("grpc"),
 		semconv.RPCServiceKey.String(method),
-		semconv.ServerAddress(host),
 		semconv.RPCGRPCStatusCodeKey.Int(int(e.StatusCode)),
 	}
-
-	if port > 0 {
-		attrs = append(attrs, semconv.NetworkPeerPort(port))
-		attrs = append(attrs, semconv.ServerPort(port))
-	}
+	attrs = append(attrs, netattr.Attributes(netattr.ParseTarget(target), netattr.Addr{})...)
 
 	spans := ptrace.NewSpanSlice()
 	span := spans.AppendEmpty()
@@ -232,12 +254,16 @@ func processFn(e *event) ptrace.SpanSlice {
 	if e.ParentSpanContext.SpanID.IsValid() {
 		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
 	}
+	span.TraceState().FromRaw(e.TraceState.String())
 
 	pdataconv.Attributes(span.Attributes(), attrs...)
 
-	if writeStatus && e.StatusCode > 0 {
+	if e.StatusCode > 0 {
 		span.Status().SetCode(ptrace.StatusCodeError)
 		errMsg := unix.ByteSliceToString(e.ErrMsg[:])
+		if e.StatusFromTrailers != 0 {
+			errMsg = decodeMessage(errMsg)
+		}
 		if errMsg != "" {
 			span.Status().SetMessage(errMsg)
 		}
@@ -245,3 +271,24 @@ func processFn(e *event) ptrace.SpanSlice {
 
 	return spans
 }
+
+// decodeMessage returns the percent-decoded grpc-message msg. Invalid
+// escapes are kept as they are, the same as grpc does.
+func decodeMessage(msg string) string {
+	if !strings.Contains(msg, "%") {
+		return msg
+	}
+	var b strings.Builder
+	b.Grow(len(msg))
+	for i := 0; i < len(msg); i++ {
+		if msg[i] == '%' && i+2 < len(msg) {
+			if v, err := strconv.ParseUint(msg[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(msg[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/grpc/codes"
+)
+
+func TestProcessFnStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     codes.Code
+		msg      string
+		trailers bool
+		wantCode ptrace.StatusCode
+		wantMsg  string
+	}{
+		{
+			name:     "OK",
+			code:     codes.OK,
+			wantCode: ptrace.StatusCodeUnset,
+		},
+		{
+			name:     "Error",
+			code:     codes.NotFound,
+			msg:      "100% not found",
+			wantCode: ptrace.StatusCodeError,
+			wantMsg:  "100% not found",
+		},
+		{
+			name:     "Trailers",
+			code:     codes.InvalidArgument,
+			msg:      "bad %22name%22",
+			trailers: true,
+			wantCode: ptrace.StatusCodeError,
+			wantMsg:  `bad "name"`,
+		},
+		{
+			name:     "TrailersInvalidEscape",
+			code:     codes.Unavailable,
+			msg:      "100%zz%2",
+			trailers: true,
+			wantCode: ptrace.StatusCodeError,
+			wantMsg:  "100%zz%2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &event{StatusCode: int32(tt.code)} // nolint: gosec  // Bounded.
+			copy(e.Method[:], "/helloworld.Greeter/SayHello")
+			copy(e.ErrMsg[:], tt.msg)
+			if tt.trailers {
+				e.StatusFromTrailers = 1
+			}
+
+			spans := processFn(e)
+			require.Equal(t, 1, spans.Len())
+			span := spans.At(0)
+			assert.Equal(t, tt.wantCode, span.Status().Code())
+			assert.Equal(t, tt.wantMsg, span.Status().Message())
+			assert.Equal(t, int64(tt.code), span.Attributes().AsRaw()["rpc.grpc.status_code"])
+		})
+	}
+}
@@ -4,25 +4,90 @@
 #include "arguments.h"
 #include "go_types.h"
 #include "go_net.h"
+#include "goroutine_spans.h"
 #include "trace/span_context.h"
 #include "go_context.h"
 #include "uprobe.h"
 #include "trace/start_span.h"
+#include "trace/tracestate.h"
+#include "enduser.h"
 
 char __license[] SEC("license") = "Dual MIT/GPL";
 
-#define MAX_SIZE 100
+#define MAX_SIZE 256
 #define MAX_CONCURRENT 50
 #define MAX_HEADERS 20
 #define MAX_HEADER_STRING 50
+#define MAX_STATUS_MESSAGE_SIZE 128
+#define MAX_UNIX_PATH_LEN 108
+#define MAX_AUTHORITY_LEN 128
+#define AUTHORITY_KEY_LEN 10
+// Must match the limits of the metadata capture in probe.go.
+#define MAX_METADATA_KEYS 4
+#define MAX_METADATA_KEY_LEN 32
+#define MAX_METADATA_VALUE_LEN 64
+
+// The value of an allowlisted metadata key, truncated to
+// MAX_METADATA_VALUE_LEN bytes.
+struct metadata_value
+{
+    u32 len;
+    char value[MAX_METADATA_VALUE_LEN];
+};
 
 struct grpc_request_t
 {
     BASE_SPAN_PROPERTIES
     char method[MAX_SIZE];
     u32 status_code;
+    char status_message[MAX_STATUS_MESSAGE_SIZE];
+    // The local and remote addresses of a TCP connection, or the paths of a
+    // Unix domain socket one.
     net_addr_t local_addr;
+    net_addr_t remote_addr;
+    char remote_unix_path[MAX_UNIX_PATH_LEN];
+    char local_unix_path[MAX_UNIX_PATH_LEN];
+    // The :authority pseudo-header of the request, only read if the addresses
+    // of the connection are not supported.
+    char authority[MAX_AUTHORITY_LEN];
     u8 has_status;
+    u8 status_message_truncated;
+    u8 method_truncated;
+    struct tracestate tracestate;
+    // The values of the allowlisted metadata keys, in the order of the keys.
+    struct metadata_value metadata[MAX_METADATA_KEYS];
+    // The number of messages received and sent by the server.
+    u64 received_messages;
+    u64 sent_messages;
+    // The number of messages already flushed in partial events, and the time
+    // of the last flush.
+    u64 flushed_received_messages;
+    u64 flushed_sent_messages;
+    u64 flush_time;
+    // The transport stream of the request.
+    u64 stream;
+    // A partial event reports the messages of a stream since the last flush.
+    u8 partial;
+    // Must be the last field, it is only output if enabled.
+    struct enduser enduser;
+};
+
+// The duration of an unsampled request, output instead of its event when the
+// durations are recorded.
+struct grpc_duration_t
+{
+    u64 start_time;
+    u64 end_time;
+    u32 status_code;
+    u8 method_truncated;
+    char method[MAX_SIZE];
+};
+
+// A SendMsg or RecvMsg call of a grpc.serverStream.
+struct message_call_t
+{
+    void *server_stream;
+    u8 sent;
 };
 
 struct
@@ -49,6 +114,31 @@ struct
     __uint(max_entries, 1);
 } grpc_storage_map SEC(".maps");
 
+struct
+{
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct grpc_duration_t));
+    __uint(max_entries, 1);
+} grpc_duration_storage_map SEC(".maps");
+
+// The goroutines handling the requests of the transport streams.
+struct
+{
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, u64);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} stream_goroutines SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct message_call_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} message_calls SEC(".maps");
+
 struct hpack_header_field
 {
     struct go_string name;
@@ -66,12 +156,103 @@ volatile const u64 server_stream_stream_pos;
 volatile const bool is_new_frame_pos;
 volatile const u64 status_s_pos;
 volatile const u64 status_code_pos;
+volatile const u64 status_message_pos;
 volatile const u64 http2server_peer_pos;
 volatile const u64 peer_local_addr_pos;
+volatile const u64 peer_addr_pos;
+volatile const u64 UnixAddr_Name_offset;
+// The addresses of the itabs of the *net.TCPAddr and *net.UnixAddr
+// implementations of net.Addr, 0 if not known.
+volatile const u64 tcp_addr_itab_addr;
+volatile const u64 unix_addr_itab_addr;
 
 volatile const bool server_addr_supported;
+volatile const bool is_server_stream;
+volatile const u64 server_stream_s_pos;
+// The interval of the flushes of the message counts of long-lived streams.
+volatile const u64 message_flush_interval;
+// Whether the durations of the requests are recorded, the unsampled requests
+// are only output if they are.
+volatile const bool record_durations;
+
+// The lowercase metadata keys whose values are captured, and their lengths.
+// The keys are first, the unused ones have a zero length.
+volatile const char metadata_keys[MAX_METADATA_KEYS][MAX_METADATA_KEY_LEN];
+volatile const u64 metadata_key_lens[MAX_METADATA_KEYS];
+
+// Returns the index of the allowlisted metadata key of the name_len long
+// header name located at the user space address name, or -1 if it is not
+// allowlisted. HTTP/2 header names are lowercase.
+static __always_inline s32 metadata_key_index(void *name, u64 name_len) {
+    if (metadata_key_lens[0] == 0 || name_len == 0 || name_len > MAX_METADATA_KEY_LEN) {
+        return -1;
+    }
+    char current[MAX_METADATA_KEY_LEN];
+    if (bpf_probe_read_user(current, name_len, name) < 0) {
+        return -1;
+    }
+    for (s32 i = 0; i < MAX_METADATA_KEYS; i++) {
+        if (metadata_key_lens[i] != name_len) {
+            continue;
+        }
+        if (bpf_memcmp(current, (char *)metadata_keys[i], name_len)) {
+            return i;
+        }
+    }
+    return -1;
+}
+
+// Reads the metadata value of len bytes located at the user space address str
+// into val, truncated to MAX_METADATA_VALUE_LEN bytes.
+static __always_inline void read_metadata_value(void *str, u64 len, struct metadata_value *val) {
+    if (str == NULL || len == 0) {
+        return;
+    }
+    u64 size = len < MAX_METADATA_VALUE_LEN ? len : MAX_METADATA_VALUE_LEN;
+    if (bpf_probe_read_user(val->value, size, str) == 0) {
+        val->len = size;
+    }
+}
+
+// Reads the net.Addr interface value located at iface_ptr into addr if it is a
+// *net.TCPAddr, or its path into unix_path if it is a *net.UnixAddr. Other
+// types are not read. If tcp_fallback is true, the value is read as a
+// *net.TCPAddr when the itab of *net.TCPAddr is not known.
+static __always_inline void read_net_addr(struct pt_regs *ctx, void *iface_ptr, net_addr_t *addr, char *unix_path, u64 unix_path_size, bool tcp_fallback) {
+    void *itab = NULL;
+    void *data = NULL;
+    bpf_probe_read_user(&itab, sizeof(itab), iface_ptr);
+    bpf_probe_read_user(&data, sizeof(data), get_go_interface_instance(iface_ptr));
+    if (itab == NULL || data == NULL) {
+        return;
+    }
 
-static __always_inline long dummy_extract_span_context_from_headers(void *stream_id, struct span_context *parent_span_context) {
+    if (unix_addr_itab_addr != 0 && (u64)itab == unix_addr_itab_addr) {
+        get_go_string_from_user_ptr(data + UnixAddr_Name_offset, unix_path, unix_path_size);
+    } else if (tcp_addr_itab_addr != 0 ? (u64)itab == tcp_addr_itab_addr : tcp_fallback) {
+        get_tcp_net_addr_from_tcp_addr(ctx, addr, data);
+    }
+}
+
+// The parent span context is extracted from the headers by the
+// operateHeader probe. It is only used if it was found there, or if go_context
+// is not NULL, it is the span tracked in the context of the stream.
+//
+// The headers of the requests served with the ServeHTTP method of the Server
+// are not read by operateHeader: the net/http server probe tracks the parent
+// of the HTTP request in its context instead.
+static __always_inline long extract_span_context_from_headers(void *go_context, struct span_context *parent_span_context) {
+    if (is_span_context_valid(parent_span_context)) {
+        return 0;
+    }
+    if (go_context == NULL) {
+        return -1;
+    }
+    struct span_context *local_psc = get_parent_span_context((struct go_iface *)go_context);
+    if (local_psc == NULL) {
+        return -1;
+    }
+    *parent_span_context = *local_psc;
     return 0;
 }
 
@@ -109,7 +290,14 @@ static __always_inline int handleStream(struct pt_regs *ctx, void *stream_ptr, s
         return -2;
     }
 
-    struct grpc_request_t *grpcReq = bpf_map_lookup_elem(&streamid_to_grpc_events, &stream_id);
+    // The streams of the requests served with the ServeHTTP method of the
+    // Server (a serverHandlerTransport) are not HTTP/2 streams of the
+    // transport, their ID is 0.
+    bool handler_transport = stream_id == 0;
+    struct grpc_request_t *grpcReq = NULL;
+    if (!handler_transport) {
+        grpcReq = bpf_map_lookup_elem(&streamid_to_grpc_events, &stream_id);
+    }
     if (grpcReq == NULL) {
         // No parent span context, generate new span context
         u32 zero = 0;
@@ -118,9 +306,10 @@ static __always_inline int handleStream(struct pt_regs *ctx, void *stream_ptr, s
             bpf_printk("grpc:server:handleStream: failed to get grpcReq");
             return 0;
         }
+        __builtin_memset(grpcReq, 0, sizeof(struct grpc_request_t));
     }
 
-    grpcReq->start_time = bpf_ktime_get_ns();
+    grpcReq->start_time = get_time_ns();
 
     start_span_params_t start_span_params = {
         .ctx = ctx,
@@ -128,39 +317,62 @@ static __always_inline int handleStream(struct pt_regs *ctx, void *stream_ptr, s
         .psc = &grpcReq->psc,
         .go_context = go_context,
         // The parent span context is set by operateHeader probe
-        .get_parent_span_context_fn = dummy_extract_span_context_from_headers,
-        .get_parent_span_context_arg = NULL,
+        .get_parent_span_context_fn = extract_span_context_from_headers,
+        .get_parent_span_context_arg = handler_transport ? go_context : NULL,
     };
     start_span(&start_span_params);
 
+    // The tracestate is only propagated along with a remote parent.
+    if (handler_transport) {
+        // The remote tracestate of the trace is set by the net/http server
+        // probe.
+        struct tracestate *ts = get_remote_tracestate(&grpcReq->sc);
+        if (ts != NULL) {
+            bpf_probe_read_kernel(&grpcReq->tracestate, sizeof(grpcReq->tracestate), ts);
+        }
+    } else if (is_span_context_valid(&grpcReq->psc)) {
+        set_remote_tracestate(&grpcReq->sc, &grpcReq->tracestate);
+    } else {
+        grpcReq->tracestate.len = 0;
+    }
+
     // Set attributes
     void *method_ptr = stream_ptr + stream_method_ptr_pos;
-    bool parsed_method = get_go_string_from_user_ptr(method_ptr, grpcReq->method, sizeof(grpcReq->method));
-    if (!parsed_method) {
+    u64 method_len = get_go_string_len_from_user_ptr(method_ptr, grpcReq->method, sizeof(grpcReq->method));
+    grpcReq->method_truncated = method_len > sizeof(grpcReq->method);
+    if (method_len == 0) {
         bpf_printk("grpc:server:handleStream: failed to read gRPC method from stream");
         bpf_map_delete_elem(&streamid_to_grpc_events, &stream_id);
         return -3;
     }
 
-    if (server_addr_supported) {
+    if (server_addr_supported && !handler_transport) {
         void *http2server = get_argument(ctx, 3);
         if (http2server != NULL) {
-            void *local_addr_ptr = 0;
+            // The local address of the listeners of the executables where the
+            // itabs are not known is assumed to be a TCP one.
             void *local_addr_pos = http2server + http2server_peer_pos + peer_local_addr_pos;
-            bpf_probe_read_user(&local_addr_ptr, sizeof(local_addr_ptr), get_go_interface_instance(local_addr_pos));
-            get_tcp_net_addr_from_tcp_addr(ctx, &grpcReq->local_addr, (void *)(local_addr_ptr));
+            read_net_addr(ctx, local_addr_pos, &grpcReq->local_addr, grpcReq->local_unix_path, sizeof(grpcReq->local_unix_path), true);
+
+            void *addr_pos = http2server + http2server_peer_pos + peer_addr_pos;
+            read_net_addr(ctx, addr_pos, &grpcReq->remote_addr, grpcReq->remote_unix_path, sizeof(grpcReq->remote_unix_path), false);
         } else {
             bpf_printk("grpc:server:handleStream: failed to get http2server arg");
         }
     }
 
+    grpcReq->stream = (u64)stream_ptr;
+
     // Write event
     rc = bpf_map_update_elem(&grpc_events, &key, grpcReq, 0);
     if (rc != 0) {
         bpf_printk("grpc:server:handleStream: failed to update event");
         return -4;
     }
+    u64 stream = (u64)stream_ptr;
+    bpf_map_update_elem(&stream_goroutines, &stream, &key, 0);
     start_tracking_span(go_context->data, &grpcReq->sc);
+    set_goroutine_span(key, &grpcReq->sc);
 
     return 0;
 }
@@ -201,9 +413,40 @@ static __always_inline int writeStatus(struct pt_regs *ctx, void *status_ptr) {
     }
     req_ptr->has_status = true;
 
+    // Get status message from Status.s pointer
+    void *message_ptr = s_ptr + status_message_pos;
+    struct go_string message = {0};
+    bpf_probe_read_user(&message, sizeof(message), message_ptr);
+    req_ptr->status_message_truncated = message.len > MAX_STATUS_MESSAGE_SIZE;
+    get_go_string_from_user_ptr(message_ptr, req_ptr->status_message, sizeof(req_ptr->status_message));
+
     return 0;
 }
 
+// Output the event of a request to the perf buffer. Only the duration of an
+// unsampled request is output, if the durations are recorded.
+static __always_inline long output_request_event(void *ctx, struct grpc_request_t *event) {
+    if (is_sampled(&event->sc)) {
+        return bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, event, enduser_event_size(sizeof(*event), offsetof(struct grpc_request_t, enduser)));
+    }
+    if (!record_durations) {
+        return 0;
+    }
+
+    u32 map_id = 0;
+    struct grpc_duration_t *duration = bpf_map_lookup_elem(&grpc_duration_storage_map, &map_id);
+    if (duration == NULL) {
+        bpf_printk("grpc:server:output_request_event: failed to get duration storage");
+        return -1;
+    }
+    duration->start_time = event->start_time;
+    duration->end_time = event->end_time;
+    duration->status_code = event->status_code;
+    duration->method_truncated = event->method_truncated;
+    __builtin_memcpy(duration->method, event->method, sizeof(duration->method));
+    return bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, duration, sizeof(*duration));
+}
+
 // This instrumentation attaches uprobe to the following function:
 // func (s *Server) handleStream(t transport.ServerTransport, stream *transport.Stream, trInfo *traceInfo)
 //
@@ -219,7 +462,26 @@ int uprobe_server_handleStream(struct pt_regs *ctx) {
     return handleStream(ctx, stream_ptr, &go_context);
 }
 
-UPROBE_RETURN(server_handleStream, struct grpc_request_t, grpc_events)
+// This instrumentation attaches a return uprobe to the following function:
+// func (s *Server) handleStream(t transport.ServerTransport, stream *transport.Stream, trInfo *traceInfo)
+//
+// This is only compatible with versions < 1.69.0 of the Server.
+SEC("uprobe/server_handleStream")
+int uprobe_server_handleStream_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct grpc_request_t *event = bpf_map_lookup_elem(&grpc_events, &key);
+    if (event == NULL) {
+        bpf_printk("grpc:server:uprobe/server_handleStreamReturn: event is NULL");
+        return 0;
+    }
+    event->end_time = get_time_ns();
+    bpf_map_delete_elem(&stream_goroutines, &event->stream);
+    output_request_event(ctx, event);
+    stop_tracking_span(&event->sc, &event->psc);
+    delete_goroutine_span(key);
+    bpf_map_delete_elem(&grpc_events, &key);
+    return 0;
+}
 
 // This instrumentation attaches uprobe to the following function:
 // func (s *Server) handleStream(t transport.ServerTransport, stream *transport.ServerStream)
@@ -288,9 +550,11 @@ lookup:
         bpf_printk("grpc:server:uprobe/server_handleStream2Return: event is NULL");
         return -5;
     }
-    event->end_time = bpf_ktime_get_ns();
-    output_span_event(ctx, event, sizeof(struct grpc_request_t), &event->sc);
+    event->end_time = get_time_ns();
+    bpf_map_delete_elem(&stream_goroutines, &event->stream);
+    output_request_event(ctx, event);
     stop_tracking_span(&event->sc, &event->psc);
+    delete_goroutine_span(key);
     bpf_map_delete_elem(&grpc_events, &key);
     return 0;
 }
@@ -306,7 +570,21 @@ int uprobe_http2Server_operateHeader(struct pt_regs *ctx)
     void *frame_ptr = is_new_frame_pos ? arg4 : arg2;
     struct go_slice header_fields = {};
     bpf_probe_read(&header_fields, sizeof(header_fields), (void *)(frame_ptr + frame_fields_pos));
+
+    u32 zero = 0;
+    struct grpc_request_t *grpcReq = bpf_map_lookup_elem(&grpc_storage_map, &zero);
+    if (grpcReq == NULL) {
+        bpf_printk("grpc:server:operateHeader: failed to get grpcReq");
+        return 0;
+    }
+    __builtin_memset(grpcReq, 0, sizeof(struct grpc_request_t));
+
+    bool found_traceparent = false;
+    bool found_metadata = false;
+    bool found_authority = false;
     char key[W3C_KEY_LENGTH] = "traceparent";
+    char ts_key[TRACESTATE_KEY_LENGTH] = "tracestate";
+    char authority_key[AUTHORITY_KEY_LEN] = ":authority";
     for (s32 i = 0; i < MAX_HEADERS; i++)
     {
         if (i >= header_fields.len)
@@ -323,24 +601,63 @@ int uprobe_http2Server_operateHeader(struct pt_regs *ctx)
             {
                 char val[W3C_VAL_LENGTH];
                 bpf_probe_read(val, W3C_VAL_LENGTH, hf.value.str);
-
-                // Get stream id
-                void *headers_frame = NULL;
-                bpf_probe_read(&headers_frame, sizeof(headers_frame), frame_ptr);
-                u32 stream_id = 0;
-                bpf_probe_read(&stream_id, sizeof(stream_id), (void *)(headers_frame + frame_stream_id_pod));
-                struct grpc_request_t grpcReq = {};
-                w3c_string_to_span_context(val, &grpcReq.psc);
-                bpf_map_update_elem(&streamid_to_grpc_events, &stream_id, &grpcReq, 0);
+                w3c_string_to_span_context(val, &grpcReq->psc);
+                found_traceparent = true;
+                continue;
+            }
+        }
+        if (hf.name.len == TRACESTATE_KEY_LENGTH && grpcReq->tracestate.len == 0)
+        {
+            char current_key[TRACESTATE_KEY_LENGTH];
+            bpf_probe_read(current_key, sizeof(current_key), hf.name.str);
+            if (bpf_memcmp(ts_key, current_key, sizeof(ts_key)))
+            {
+                // Malformed values are left empty and not propagated.
+                read_tracestate(hf.value.str, hf.value.len, &grpcReq->tracestate);
+                continue;
             }
         }
+        if (!server_addr_supported && !found_authority && hf.name.len == AUTHORITY_KEY_LEN)
+        {
+            char current_key[AUTHORITY_KEY_LEN];
+            bpf_probe_read(current_key, sizeof(current_key), hf.name.str);
+            if (bpf_memcmp(authority_key, current_key, sizeof(authority_key)))
+            {
+                u64 size = hf.value.len < MAX_AUTHORITY_LEN ? hf.value.len : MAX_AUTHORITY_LEN;
+                found_authority = size > 0 && bpf_probe_read_user(grpcReq->authority, size, hf.value.str) == 0;
+                continue;
+            }
+        }
+        if (grpcReq->enduser.len == 0 && is_enduser_header(hf.name.str, hf.name.len))
+        {
+            read_enduser(hf.value.str, hf.value.len, &grpcReq->enduser);
+        }
+        s32 md_idx = metadata_key_index(hf.name.str, hf.name.len);
+        if (md_idx >= 0 && md_idx < MAX_METADATA_KEYS && grpcReq->metadata[md_idx].len == 0)
+        {
+            read_metadata_value(hf.value.str, hf.value.len, &grpcReq->metadata[md_idx]);
+            found_metadata = found_metadata || grpcReq->metadata[md_idx].len > 0;
+        }
     }
 
+    if (!found_traceparent && grpcReq->enduser.len == 0 && !found_metadata && !found_authority)
+    {
+        return 0;
+    }
+
+    // Get stream id
+    void *headers_frame = NULL;
+    bpf_probe_read(&headers_frame, sizeof(headers_frame), frame_ptr);
+    u32 stream_id = 0;
+    bpf_probe_read(&stream_id, sizeof(stream_id), (void *)(headers_frame + frame_stream_id_pod));
+    bpf_map_update_elem(&streamid_to_grpc_events, &stream_id, grpcReq, 0);
+
     return 0;
 }
 
 // func (ht *http2Server) WriteStatus(s *Stream, st *status.Status)
 // https://github.com/grpc/grpc-go/blob/bcf9171a20e44ed81a6eb152e3ca9e35b2c02c5d/internal/transport/http2_server.go#L1049
+// func (ht *serverHandlerTransport) WriteStatus(s *Stream, st *status.Status) error
 //
 // This is only compatible with versions > 1.40 and < 1.69.0 of the Server.
 SEC("uprobe/http2Server_WriteStatus")
@@ -351,6 +668,7 @@ int uprobe_http2Server_WriteStatus(struct pt_regs *ctx) {
 
 // func (ht *http2Server) writeStatus(s *Stream, st *status.Status)
 // https://github.com/grpc/grpc-go/blob/317271b232677b7869576a49855b01b9f4775d67/internal/transport/http2_server.go#L1045
+// func (ht *serverHandlerTransport) writeStatus(s *ServerStream, st *status.Status) error
 //
 // This is only compatible with versions > 1.69.0 of the Server.
 SEC("uprobe/http2Server_WriteStatus2")
@@ -372,3 +690,111 @@ int uprobe_http2Server_WriteStatus2(struct pt_regs *ctx) {
     void *status_ptr = get_argument(ctx, 3);
     return writeStatus(ctx, status_ptr);
 }
+
+// Returns the event of the request of the grpc.serverStream server_stream,
+// NULL if it is not tracked.
+static __always_inline struct grpc_request_t *server_stream_event(void *server_stream) {
+    void *s = NULL;
+    if (bpf_probe_read_user(&s, sizeof(s), server_stream + server_stream_s_pos) != 0 || s == NULL) {
+        return NULL;
+    }
+    // The stream is a *transport.ServerStream from v1.69.0.
+    void *stream_ptr = s;
+    if (is_server_stream && bpf_probe_read_user(&stream_ptr, sizeof(stream_ptr), s + server_stream_stream_pos) != 0) {
+        return NULL;
+    }
+
+    u64 stream = (u64)stream_ptr;
+    void **key = bpf_map_lookup_elem(&stream_goroutines, &stream);
+    if (key == NULL) {
+        return NULL;
+    }
+    void *goroutine = *key;
+    return bpf_map_lookup_elem(&grpc_events, &goroutine);
+}
+
+// Counts a message of req, and outputs the messages counted since the last
+// flush in a partial event if it was more than message_flush_interval ago.
+static __always_inline void count_message(struct pt_regs *ctx, struct grpc_request_t *req, bool sent) {
+    if (sent) {
+        __sync_fetch_and_add(&req->sent_messages, 1);
+    } else {
+        __sync_fetch_and_add(&req->received_messages, 1);
+    }
+
+    u64 now = get_time_ns();
+    u64 last = req->flush_time != 0 ? req->flush_time : req->start_time;
+    if (message_flush_interval == 0 || now - last < message_flush_interval) {
+        return;
+    }
+
+    u32 zero = 0;
+    struct grpc_request_t *partial = bpf_map_lookup_elem(&grpc_storage_map, &zero);
+    if (partial == NULL) {
+        return;
+    }
+    bpf_probe_read_kernel(partial, sizeof(*partial), req);
+    partial->start_time = last;
+    partial->end_time = now;
+    partial->psc = req->sc;
+    generate_random_bytes(partial->sc.SpanID, SPAN_ID_SIZE);
+    partial->received_messages = req->received_messages - req->flushed_received_messages;
+    partial->sent_messages = req->sent_messages - req->flushed_sent_messages;
+    partial->partial = 1;
+    output_span_event(ctx, partial, enduser_event_size(sizeof(*partial), offsetof(struct grpc_request_t, enduser)), &partial->sc);
+
+    req->flush_time = now;
+    req->flushed_received_messages += partial->received_messages;
+    req->flushed_sent_messages += partial->sent_messages;
+}
+
+static __always_inline int message_call(struct pt_regs *ctx, u8 sent) {
+    struct message_call_t call = {
+        .server_stream = get_argument(ctx, 1),
+        .sent = sent,
+    };
+    if (call.server_stream == NULL) {
+        return 0;
+    }
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&message_calls, &key, &call, 0);
+    return 0;
+}
+
+// func (ss *serverStream) SendMsg(m any) (err error)
+SEC("uprobe/serverStream_SendMsg")
+int uprobe_serverStream_SendMsg(struct pt_regs *ctx) {
+    return message_call(ctx, 1);
+}
+
+// func (ss *serverStream) RecvMsg(m any) (err error)
+SEC("uprobe/serverStream_RecvMsg")
+int uprobe_serverStream_RecvMsg(struct pt_regs *ctx) {
+    return message_call(ctx, 0);
+}
+
+// Counts the messages sent and received successfully by the SendMsg and
+// RecvMsg methods.
+SEC("uprobe/serverStream_SendMsg")
+int uprobe_serverStream_Msg_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct message_call_t *call = bpf_map_lookup_elem(&message_calls, &key);
+    if (call == NULL) {
+        return 0;
+    }
+    void *server_stream = call->server_stream;
+    bool sent = call->sent;
+    bpf_map_delete_elem(&message_calls, &key);
+
+    // The type of the returned error is nil on success.
+    if (get_argument(ctx, 1) != NULL) {
+        return 0;
+    }
+
+    struct grpc_request_t *req = server_stream_event(server_stream);
+    if (req == NULL) {
+        return 0;
+    }
+    count_message(ctx, req, sent);
+    return 0;
+}
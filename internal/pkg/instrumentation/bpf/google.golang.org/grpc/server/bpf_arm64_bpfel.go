@@ -13,21 +13,68 @@ import (
 	"github.com/cilium/ebpf"
 )
 
+type bpfEnduser struct {
+	_     structs.HostLayout
+	Len   uint64
+	Value [1024]int8
+}
+
 type bpfGrpcRequestT struct {
-	_          structs.HostLayout
-	StartTime  uint64
-	EndTime    uint64
-	Sc         bpfSpanContext
-	Psc        bpfSpanContext
-	Method     [100]int8
-	StatusCode uint32
-	LocalAddr  struct {
-		_    structs.HostLayout
-		Ip   [16]uint8
-		Port uint32
+	_             structs.HostLayout
+	StartTime     uint64
+	EndTime       uint64
+	Sc            bpfSpanContext
+	Psc           bpfSpanContext
+	Method        [256]int8
+	StatusCode    uint32
+	StatusMessage [128]int8
+	LocalAddr     struct {
+		_     structs.HostLayout
+		Ip    [16]uint8
+		Port  uint32
+		IpLen uint8
+		Zone  [16]int8
+		_     [3]byte
 	}
-	HasStatus uint8
-	_         [3]byte
+	RemoteAddr struct {
+		_     structs.HostLayout
+		Ip    [16]uint8
+		Port  uint32
+		IpLen uint8
+		Zone  [16]int8
+		_     [3]byte
+	}
+	RemoteUnixPath          [108]int8
+	LocalUnixPath           [108]int8
+	Authority               [128]int8
+	HasStatus               uint8
+	StatusMessageTruncated  uint8
+	MethodTruncated         uint8
+	_                       [1]byte
+	Tracestate              bpfTracestate
+	Metadata                [4]bpfMetadataValue
+	ReceivedMessages        uint64
+	SentMessages            uint64
+	FlushedReceivedMessages uint64
+	FlushedSentMessages     uint64
+	FlushTime               uint64
+	Stream                  uint64
+	Partial                 uint8
+	_                       [7]byte
+	Enduser                 bpfEnduser
+}
+
+type bpfMessageCallT struct {
+	_            structs.HostLayout
+	ServerStream uint64
+	Sent         uint8
+	_            [7]byte
+}
+
+type bpfMetadataValue struct {
+	_     structs.HostLayout
+	Len   uint32
+	Value [64]int8
 }
 
 type bpfSliceArrayBuff struct {
@@ -43,6 +90,17 @@ type bpfSpanContext struct {
 	Padding    [7]uint8
 }
 
+type bpfTraceIdKey struct {
+	_       structs.HostLayout
+	TraceID [16]uint8
+}
+
+type bpfTracestate struct {
+	_     structs.HostLayout
+	Len   uint64
+	Value [512]int8
+}
+
 // loadBpf returns the embedded CollectionSpec for bpf.
 func loadBpf() (*ebpf.CollectionSpec, error) {
 	reader := bytes.NewReader(_BpfBytes)
@@ -88,6 +146,9 @@ type bpfProgramSpecs struct {
 	UprobeHttp2ServerWriteStatus     *ebpf.ProgramSpec `ebpf:"uprobe_http2Server_WriteStatus"`
 	UprobeHttp2ServerWriteStatus2    *ebpf.ProgramSpec `ebpf:"uprobe_http2Server_WriteStatus2"`
 	UprobeHttp2ServerOperateHeader   *ebpf.ProgramSpec `ebpf:"uprobe_http2Server_operateHeader"`
+	UprobeServerStreamMsgReturns     *ebpf.ProgramSpec `ebpf:"uprobe_serverStream_Msg_Returns"`
+	UprobeServerStreamRecvMsg        *ebpf.ProgramSpec `ebpf:"uprobe_serverStream_RecvMsg"`
+	UprobeServerStreamSendMsg        *ebpf.ProgramSpec `ebpf:"uprobe_serverStream_SendMsg"`
 	UprobeServerHandleStream         *ebpf.ProgramSpec `ebpf:"uprobe_server_handleStream"`
 	UprobeServerHandleStream2        *ebpf.ProgramSpec `ebpf:"uprobe_server_handleStream2"`
 	UprobeServerHandleStream2Returns *ebpf.ProgramSpec `ebpf:"uprobe_server_handleStream2_Returns"`
@@ -98,16 +159,23 @@ type bpfProgramSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfMapSpecs struct {
-	AllocMap              *ebpf.MapSpec `ebpf:"alloc_map"`
-	Events                *ebpf.MapSpec `ebpf:"events"`
-	GoContextToSc         *ebpf.MapSpec `ebpf:"go_context_to_sc"`
-	GrpcEvents            *ebpf.MapSpec `ebpf:"grpc_events"`
-	GrpcStorageMap        *ebpf.MapSpec `ebpf:"grpc_storage_map"`
-	ProbeActiveSamplerMap *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
-	SamplersConfigMap     *ebpf.MapSpec `ebpf:"samplers_config_map"`
-	SliceArrayBuffMap     *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
-	StreamidToGrpcEvents  *ebpf.MapSpec `ebpf:"streamid_to_grpc_events"`
-	TrackedSpansBySc      *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
+	AllocMap               *ebpf.MapSpec `ebpf:"alloc_map"`
+	EnduserStorageMap      *ebpf.MapSpec `ebpf:"enduser_storage_map"`
+	Events                 *ebpf.MapSpec `ebpf:"events"`
+	GoContextToSc          *ebpf.MapSpec `ebpf:"go_context_to_sc"`
+	GoroutineSpans         *ebpf.MapSpec `ebpf:"goroutine_spans"`
+	GrpcDurationStorageMap *ebpf.MapSpec `ebpf:"grpc_duration_storage_map"`
+	GrpcEvents             *ebpf.MapSpec `ebpf:"grpc_events"`
+	GrpcStorageMap         *ebpf.MapSpec `ebpf:"grpc_storage_map"`
+	MessageCalls           *ebpf.MapSpec `ebpf:"message_calls"`
+	ProbeActiveSamplerMap  *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
+	SamplersConfigMap      *ebpf.MapSpec `ebpf:"samplers_config_map"`
+	SliceArrayBuffMap      *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
+	StreamGoroutines       *ebpf.MapSpec `ebpf:"stream_goroutines"`
+	StreamidToGrpcEvents   *ebpf.MapSpec `ebpf:"streamid_to_grpc_events"`
+	TracestateByTraceId    *ebpf.MapSpec `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap   *ebpf.MapSpec `ebpf:"tracestate_storage_map"`
+	TrackedSpansBySc       *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
 }
 
 // bpfVariableSpecs contains global variables before they are loaded into the kernel.
@@ -116,22 +184,37 @@ type bpfMapSpecs struct {
 type bpfVariableSpecs struct {
 	TCPAddrIP_offset      *ebpf.VariableSpec `ebpf:"TCPAddr_IP_offset"`
 	TCPAddrPortOffset     *ebpf.VariableSpec `ebpf:"TCPAddr_Port_offset"`
+	UnixAddrNameOffset    *ebpf.VariableSpec `ebpf:"UnixAddr_Name_offset"`
+	BootClockSupported    *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
 	EndAddr               *ebpf.VariableSpec `ebpf:"end_addr"`
+	EnduserEnabled        *ebpf.VariableSpec `ebpf:"enduser_enabled"`
+	EnduserHeader         *ebpf.VariableSpec `ebpf:"enduser_header"`
+	EnduserHeaderLen      *ebpf.VariableSpec `ebpf:"enduser_header_len"`
 	FrameFieldsPos        *ebpf.VariableSpec `ebpf:"frame_fields_pos"`
 	FrameStreamIdPod      *ebpf.VariableSpec `ebpf:"frame_stream_id_pod"`
 	Hex                   *ebpf.VariableSpec `ebpf:"hex"`
 	Http2serverPeerPos    *ebpf.VariableSpec `ebpf:"http2server_peer_pos"`
 	IsNewFramePos         *ebpf.VariableSpec `ebpf:"is_new_frame_pos"`
+	IsServerStream        *ebpf.VariableSpec `ebpf:"is_server_stream"`
+	MessageFlushInterval  *ebpf.VariableSpec `ebpf:"message_flush_interval"`
+	MetadataKeyLens       *ebpf.VariableSpec `ebpf:"metadata_key_lens"`
+	MetadataKeys          *ebpf.VariableSpec `ebpf:"metadata_keys"`
+	PeerAddrPos           *ebpf.VariableSpec `ebpf:"peer_addr_pos"`
 	PeerLocalAddrPos      *ebpf.VariableSpec `ebpf:"peer_local_addr_pos"`
+	RecordDurations       *ebpf.VariableSpec `ebpf:"record_durations"`
 	ServerAddrSupported   *ebpf.VariableSpec `ebpf:"server_addr_supported"`
+	ServerStreamS_pos     *ebpf.VariableSpec `ebpf:"server_stream_s_pos"`
 	ServerStreamStreamPos *ebpf.VariableSpec `ebpf:"server_stream_stream_pos"`
 	StartAddr             *ebpf.VariableSpec `ebpf:"start_addr"`
 	StatusCodePos         *ebpf.VariableSpec `ebpf:"status_code_pos"`
+	StatusMessagePos      *ebpf.VariableSpec `ebpf:"status_message_pos"`
 	StatusS_pos           *ebpf.VariableSpec `ebpf:"status_s_pos"`
 	StreamCtxPos          *ebpf.VariableSpec `ebpf:"stream_ctx_pos"`
 	StreamIdPos           *ebpf.VariableSpec `ebpf:"stream_id_pos"`
 	StreamMethodPtrPos    *ebpf.VariableSpec `ebpf:"stream_method_ptr_pos"`
+	TcpAddrItabAddr       *ebpf.VariableSpec `ebpf:"tcp_addr_itab_addr"`
 	TotalCpus             *ebpf.VariableSpec `ebpf:"total_cpus"`
+	UnixAddrItabAddr      *ebpf.VariableSpec `ebpf:"unix_addr_itab_addr"`
 }
 
 // bpfObjects contains all objects after they have been loaded into the kernel.
@@ -154,29 +237,43 @@ func (o *bpfObjects) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfMaps struct {
-	AllocMap              *ebpf.Map `ebpf:"alloc_map"`
-	Events                *ebpf.Map `ebpf:"events"`
-	GoContextToSc         *ebpf.Map `ebpf:"go_context_to_sc"`
-	GrpcEvents            *ebpf.Map `ebpf:"grpc_events"`
-	GrpcStorageMap        *ebpf.Map `ebpf:"grpc_storage_map"`
-	ProbeActiveSamplerMap *ebpf.Map `ebpf:"probe_active_sampler_map"`
-	SamplersConfigMap     *ebpf.Map `ebpf:"samplers_config_map"`
-	SliceArrayBuffMap     *ebpf.Map `ebpf:"slice_array_buff_map"`
-	StreamidToGrpcEvents  *ebpf.Map `ebpf:"streamid_to_grpc_events"`
-	TrackedSpansBySc      *ebpf.Map `ebpf:"tracked_spans_by_sc"`
+	AllocMap               *ebpf.Map `ebpf:"alloc_map"`
+	EnduserStorageMap      *ebpf.Map `ebpf:"enduser_storage_map"`
+	Events                 *ebpf.Map `ebpf:"events"`
+	GoContextToSc          *ebpf.Map `ebpf:"go_context_to_sc"`
+	GoroutineSpans         *ebpf.Map `ebpf:"goroutine_spans"`
+	GrpcDurationStorageMap *ebpf.Map `ebpf:"grpc_duration_storage_map"`
+	GrpcEvents             *ebpf.Map `ebpf:"grpc_events"`
+	GrpcStorageMap         *ebpf.Map `ebpf:"grpc_storage_map"`
+	MessageCalls           *ebpf.Map `ebpf:"message_calls"`
+	ProbeActiveSamplerMap  *ebpf.Map `ebpf:"probe_active_sampler_map"`
+	SamplersConfigMap      *ebpf.Map `ebpf:"samplers_config_map"`
+	SliceArrayBuffMap      *ebpf.Map `ebpf:"slice_array_buff_map"`
+	StreamGoroutines       *ebpf.Map `ebpf:"stream_goroutines"`
+	StreamidToGrpcEvents   *ebpf.Map `ebpf:"streamid_to_grpc_events"`
+	TracestateByTraceId    *ebpf.Map `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap   *ebpf.Map `ebpf:"tracestate_storage_map"`
+	TrackedSpansBySc       *ebpf.Map `ebpf:"tracked_spans_by_sc"`
 }
 
 func (m *bpfMaps) Close() error {
 	return _BpfClose(
 		m.AllocMap,
+		m.EnduserStorageMap,
 		m.Events,
 		m.GoContextToSc,
+		m.GoroutineSpans,
+		m.GrpcDurationStorageMap,
 		m.GrpcEvents,
 		m.GrpcStorageMap,
+		m.MessageCalls,
 		m.ProbeActiveSamplerMap,
 		m.SamplersConfigMap,
 		m.SliceArrayBuffMap,
+		m.StreamGoroutines,
 		m.StreamidToGrpcEvents,
+		m.TracestateByTraceId,
+		m.TracestateStorageMap,
 		m.TrackedSpansBySc,
 	)
 }
@@ -187,22 +284,37 @@ func (m *bpfMaps) Close() error {
 type bpfVariables struct {
 	TCPAddrIP_offset      *ebpf.Variable `ebpf:"TCPAddr_IP_offset"`
 	TCPAddrPortOffset     *ebpf.Variable `ebpf:"TCPAddr_Port_offset"`
+	UnixAddrNameOffset    *ebpf.Variable `ebpf:"UnixAddr_Name_offset"`
+	BootClockSupported    *ebpf.Variable `ebpf:"boot_clock_supported"`
 	EndAddr               *ebpf.Variable `ebpf:"end_addr"`
+	EnduserEnabled        *ebpf.Variable `ebpf:"enduser_enabled"`
+	EnduserHeader         *ebpf.Variable `ebpf:"enduser_header"`
+	EnduserHeaderLen      *ebpf.Variable `ebpf:"enduser_header_len"`
 	FrameFieldsPos        *ebpf.Variable `ebpf:"frame_fields_pos"`
 	FrameStreamIdPod      *ebpf.Variable `ebpf:"frame_stream_id_pod"`
 	Hex                   *ebpf.Variable `ebpf:"hex"`
 	Http2serverPeerPos    *ebpf.Variable `ebpf:"http2server_peer_pos"`
 	IsNewFramePos         *ebpf.Variable `ebpf:"is_new_frame_pos"`
+	IsServerStream        *ebpf.Variable `ebpf:"is_server_stream"`
+	MessageFlushInterval  *ebpf.Variable `ebpf:"message_flush_interval"`
+	MetadataKeyLens       *ebpf.Variable `ebpf:"metadata_key_lens"`
+	MetadataKeys          *ebpf.Variable `ebpf:"metadata_keys"`
+	PeerAddrPos           *ebpf.Variable `ebpf:"peer_addr_pos"`
 	PeerLocalAddrPos      *ebpf.Variable `ebpf:"peer_local_addr_pos"`
+	RecordDurations       *ebpf.Variable `ebpf:"record_durations"`
 	ServerAddrSupported   *ebpf.Variable `ebpf:"server_addr_supported"`
+	ServerStreamS_pos     *ebpf.Variable `ebpf:"server_stream_s_pos"`
 	ServerStreamStreamPos *ebpf.Variable `ebpf:"server_stream_stream_pos"`
 	StartAddr             *ebpf.Variable `ebpf:"start_addr"`
 	StatusCodePos         *ebpf.Variable `ebpf:"status_code_pos"`
+	StatusMessagePos      *ebpf.Variable `ebpf:"status_message_pos"`
 	StatusS_pos           *ebpf.Variable `ebpf:"status_s_pos"`
 	StreamCtxPos          *ebpf.Variable `ebpf:"stream_ctx_pos"`
 	StreamIdPos           *ebpf.Variable `ebpf:"stream_id_pos"`
 	StreamMethodPtrPos    *ebpf.Variable `ebpf:"stream_method_ptr_pos"`
+	TcpAddrItabAddr       *ebpf.Variable `ebpf:"tcp_addr_itab_addr"`
 	TotalCpus             *ebpf.Variable `ebpf:"total_cpus"`
+	UnixAddrItabAddr      *ebpf.Variable `ebpf:"unix_addr_itab_addr"`
 }
 
 // bpfPrograms contains all programs after they have been loaded into the kernel.
@@ -212,6 +324,9 @@ type bpfPrograms struct {
 	UprobeHttp2ServerWriteStatus     *ebpf.Program `ebpf:"uprobe_http2Server_WriteStatus"`
 	UprobeHttp2ServerWriteStatus2    *ebpf.Program `ebpf:"uprobe_http2Server_WriteStatus2"`
 	UprobeHttp2ServerOperateHeader   *ebpf.Program `ebpf:"uprobe_http2Server_operateHeader"`
+	UprobeServerStreamMsgReturns     *ebpf.Program `ebpf:"uprobe_serverStream_Msg_Returns"`
+	UprobeServerStreamRecvMsg        *ebpf.Program `ebpf:"uprobe_serverStream_RecvMsg"`
+	UprobeServerStreamSendMsg        *ebpf.Program `ebpf:"uprobe_serverStream_SendMsg"`
 	UprobeServerHandleStream         *ebpf.Program `ebpf:"uprobe_server_handleStream"`
 	UprobeServerHandleStream2        *ebpf.Program `ebpf:"uprobe_server_handleStream2"`
 	UprobeServerHandleStream2Returns *ebpf.Program `ebpf:"uprobe_server_handleStream2_Returns"`
@@ -223,6 +338,9 @@ func (p *bpfPrograms) Close() error {
 		p.UprobeHttp2ServerWriteStatus,
 		p.UprobeHttp2ServerWriteStatus2,
 		p.UprobeHttp2ServerOperateHeader,
+		p.UprobeServerStreamMsgReturns,
+		p.UprobeServerStreamRecvMsg,
+		p.UprobeServerStreamSendMsg,
 		p.UprobeServerHandleStream,
 		p.UprobeServerHandleStream2,
 		p.UprobeServerHandleStream2Returns,
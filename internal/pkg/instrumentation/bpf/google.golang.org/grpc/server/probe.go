@@ -6,12 +6,19 @@
 package server
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"log/slog"
-	"net"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf/perf"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
@@ -21,7 +28,9 @@ import (
 
 	"go.opentelemetry.io/auto/internal/pkg/inject"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/enduser"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
 	"go.opentelemetry.io/auto/internal/pkg/process"
@@ -33,6 +42,31 @@ import (
 // pkg is the package being instrumented.
 const pkg = "google.golang.org/grpc"
 
+const (
+	// metadataKeyPrefix is the prefix of the attribute keys of the captured
+	// request metadata.
+	metadataKeyPrefix = "rpc.grpc.request.metadata."
+
+	// The attribute keys of the number of messages received and sent by the
+	// server.
+	requestMessagesKey  = attribute.Key("rpc.grpc.request.messages_per_rpc")
+	responseMessagesKey = attribute.Key("rpc.grpc.response.messages_per_rpc")
+
+	// methodTruncatedKey is the attribute key set to true if the method name
+	// was truncated to the 256 bytes of the event.
+	methodTruncatedKey = attribute.Key("rpc.grpc.method.truncated")
+
+	// messageFlushInterval is the interval the message counts of long-lived
+	// streams are flushed at.
+	messageFlushInterval = 30 * time.Second
+
+	// The limits of the metadata capture. They need to be kept in sync with
+	// the eBPF program.
+	maxMetadataKeys     = 4
+	maxMetadataKeyLen   = 32
+	maxMetadataValueLen = 64
+)
+
 var (
 	// writeStatusMinVersion is the minimum version of grpc that supports
 	// status parsing.
@@ -41,22 +75,98 @@ var (
 	// handleStream methods changed to accept a *transport.ServerStream instead
 	// of a *transport.Stream.
 	serverStreamVersion = semver.New(1, 69, 0, "", "")
+
+	// streamVersions are the versions passing a *transport.Stream to the
+	// handleStream and WriteStatus methods.
+	streamVersions = probe.PackageConstraints{
+		Package: pkg,
+		Constraints: must(
+			semver.NewConstraint("< " + serverStreamVersion.String()),
+		),
+		FailureMode: probe.FailureModeIgnore,
+	}
+	// writeStatusVersions are the versions of streamVersions where the status
+	// is read from the WriteStatus methods of the transports.
+	writeStatusVersions = probe.PackageConstraints{
+		Package: pkg,
+		Constraints: must(semver.NewConstraint(
+			fmt.Sprintf(
+				"> %s, < %s",
+				writeStatusMinVersion,
+				serverStreamVersion,
+			),
+		)),
+		FailureMode: probe.FailureModeIgnore,
+	}
+	// serverStreamVersions are the versions passing a
+	// *transport.ServerStream to the handleStream and writeStatus methods.
+	serverStreamVersions = probe.PackageConstraints{
+		Package: pkg,
+		Constraints: must(
+			semver.NewConstraint(">= " + serverStreamVersion.String()),
+		),
+		FailureMode: probe.FailureModeIgnore,
+	}
 )
 
 // New returns a new [probe.Probe].
-func New(logger *slog.Logger, ver string) probe.Probe {
+//
+// The number of messages received and sent by the server are recorded on the
+// spans. The messages of the streams longer than 30 seconds are also reported
+// every 30 seconds in INTERNAL "<service>/<method> messages" child spans
+// covering the time since the previous report.
+//
+// The values of the request metadata keys are recorded as
+// rpc.grpc.request.metadata.<key> attributes, truncated to 64 bytes. Up to 4
+// keys of 32 bytes are supported, the others are ignored.
+//
+// The requests served with the ServeHTTP method of the Server, e.g. by a
+// net/http server also serving other requests, have the same spans. Their
+// metadata and addresses are not recorded, and the span of the net/http
+// server probe is replaced by the gRPC one.
+//
+// The durations of the requests are passed to the metric handler of the
+// pipeline as rpc.server.duration histogram data points, one per request.
+// The unsampled requests are only recorded if the pipeline has a metric
+// handler, they have no span.
+func New(logger *slog.Logger, ver string, metadata []string) probe.Probe {
 	id := probe.ID{
 		SpanKind:        trace.SpanKindServer,
 		InstrumentedPkg: pkg,
 	}
-	p := &processor{Logger: logger}
+	endUser, err := enduser.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid end user configuration, capture disabled", "error", err)
+	}
+	mdKeys := metadataKeys(logger, metadata)
+	p := &processor{
+		Logger:       logger,
+		endUser:      endUser,
+		metadataKeys: mdKeys,
+	}
 	return &probe.SpanProducer[bpfObjects, event]{
 		Base: probe.Base[bpfObjects, event]{
 			ID:     id,
 			Logger: logger,
-			Consts: []probe.Const{
+			Consts: append([]probe.Const{
 				probe.AllocationConst{},
-				serverAddrConst{},
+				probe.BootClockConst{},
+				serverAddrConst{p: p},
+				recordDurationsConst{p: p},
+				serverStreamConst{},
+				probe.KeyValConst{
+					Key: "message_flush_interval",
+					Val: uint64(messageFlushInterval.Nanoseconds()),
+				},
+				probe.StructFieldConst{
+					Key: "server_stream_s_pos",
+					ID: structfield.NewID(
+						"google.golang.org/grpc",
+						"google.golang.org/grpc",
+						"serverStream",
+						"s",
+					),
+				},
 				probe.StructFieldConst{
 					Key: "stream_method_ptr_pos",
 					ID: structfield.NewID(
@@ -138,6 +248,18 @@ func New(logger *slog.Logger, ver string) probe.Probe {
 					},
 					MinVersion: writeStatusMinVersion,
 				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "status_message_pos",
+						ID: structfield.NewID(
+							"google.golang.org/grpc",
+							"google.golang.org/genproto/googleapis/rpc/status",
+							"Status",
+							"Message",
+						),
+					},
+					MinVersion: writeStatusMinVersion,
+				},
 				probe.StructFieldConstMinVersion{
 					StructField: probe.StructFieldConst{
 						Key: "http2server_peer_pos",
@@ -162,6 +284,30 @@ func New(logger *slog.Logger, ver string) probe.Probe {
 					},
 					MinVersion: serverAddrMinVersion,
 				},
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "peer_addr_pos",
+						ID: structfield.NewID(
+							"google.golang.org/grpc",
+							"google.golang.org/grpc/peer",
+							"Peer",
+							"Addr",
+						),
+					},
+					MinVersion: serverAddrMinVersion,
+				},
+				probe.StructFieldConst{
+					Key: "UnixAddr_Name_offset",
+					ID:  structfield.NewID("std", "net", "UnixAddr", "Name"),
+				},
+				probe.SymbolAddrConst{
+					Key:    "tcp_addr_itab_addr",
+					Symbol: "go:itab.*net.TCPAddr,net.Addr",
+				},
+				probe.SymbolAddrConst{
+					Key:    "unix_addr_itab_addr",
+					Symbol: "go:itab.*net.UnixAddr,net.Addr",
+				},
 				probe.StructFieldConst{
 					Key: "TCPAddr_IP_offset",
 					ID:  structfield.NewID("std", "net", "TCPAddr", "IP"),
@@ -170,77 +316,79 @@ func New(logger *slog.Logger, ver string) probe.Probe {
 					Key: "TCPAddr_Port_offset",
 					ID:  structfield.NewID("std", "net", "TCPAddr", "Port"),
 				},
+				probe.StructFieldConst{
+					Key: "TCPAddr_Zone_offset",
+					ID:  structfield.NewID("std", "net", "TCPAddr", "Zone"),
+				},
 				framePosConst{},
-			},
+				metadataKeysConst(mdKeys),
+				metadataKeyLensConst(mdKeys),
+			}, endUser.Consts()...),
 			Uprobes: []*probe.Uprobe{
 				{
-					Sym:         "google.golang.org/grpc.(*Server).handleStream",
-					EntryProbe:  "uprobe_server_handleStream",
-					ReturnProbe: "uprobe_server_handleStream_Returns",
-					PackageConstraints: []probe.PackageConstraints{
-						{
-							Package: "google.golang.org/grpc",
-							Constraints: must(
-								semver.NewConstraint("< " + serverStreamVersion.String()),
-							),
-							FailureMode: probe.FailureModeIgnore,
-						},
-					},
+					Sym:                "google.golang.org/grpc.(*Server).handleStream",
+					EntryProbe:         "uprobe_server_handleStream",
+					ReturnProbe:        "uprobe_server_handleStream_Returns",
+					PackageConstraints: []probe.PackageConstraints{streamVersions},
 				},
 				{
-					Sym:         "google.golang.org/grpc.(*Server).handleStream",
-					EntryProbe:  "uprobe_server_handleStream2",
-					ReturnProbe: "uprobe_server_handleStream2_Returns",
-					PackageConstraints: []probe.PackageConstraints{
-						{
-							Package: "google.golang.org/grpc",
-							Constraints: must(
-								semver.NewConstraint(">= " + serverStreamVersion.String()),
-							),
-							FailureMode: probe.FailureModeIgnore,
-						},
-					},
+					Sym:                "google.golang.org/grpc.(*Server).handleStream",
+					EntryProbe:         "uprobe_server_handleStream2",
+					ReturnProbe:        "uprobe_server_handleStream2_Returns",
+					PackageConstraints: []probe.PackageConstraints{serverStreamVersions},
 				},
 				{
 					Sym:        "google.golang.org/grpc/internal/transport.(*http2Server).operateHeaders",
 					EntryProbe: "uprobe_http2Server_operateHeader",
 				},
 				{
-					Sym:        "google.golang.org/grpc/internal/transport.(*http2Server).WriteStatus",
-					EntryProbe: "uprobe_http2Server_WriteStatus",
-					PackageConstraints: []probe.PackageConstraints{
-						{
-							Package: "google.golang.org/grpc",
-							Constraints: must(semver.NewConstraint(
-								fmt.Sprintf(
-									"> %s, < %s",
-									writeStatusMinVersion,
-									serverStreamVersion,
-								),
-							)),
-							FailureMode: probe.FailureModeIgnore,
-						},
-					},
+					Sym:                "google.golang.org/grpc/internal/transport.(*http2Server).WriteStatus",
+					EntryProbe:         "uprobe_http2Server_WriteStatus",
+					PackageConstraints: []probe.PackageConstraints{writeStatusVersions},
 				},
 				{
-					Sym:        "google.golang.org/grpc/internal/transport.(*http2Server).writeStatus",
-					EntryProbe: "uprobe_http2Server_WriteStatus2",
-					PackageConstraints: []probe.PackageConstraints{
-						{
-							Package: "google.golang.org/grpc",
-							Constraints: must(
-								semver.NewConstraint(">= " + serverStreamVersion.String()),
-							),
-							FailureMode: probe.FailureModeIgnore,
-						},
-					},
+					Sym:                "google.golang.org/grpc/internal/transport.(*http2Server).writeStatus",
+					EntryProbe:         "uprobe_http2Server_WriteStatus2",
+					PackageConstraints: []probe.PackageConstraints{serverStreamVersions},
+				},
+				{
+					// The transport of the requests served with the
+					// ServeHTTP method of the Server, only linked if it is
+					// used.
+					Sym:                "google.golang.org/grpc/internal/transport.(*serverHandlerTransport).WriteStatus",
+					EntryProbe:         "uprobe_http2Server_WriteStatus",
+					PackageConstraints: []probe.PackageConstraints{writeStatusVersions},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					Sym:                "google.golang.org/grpc/internal/transport.(*serverHandlerTransport).writeStatus",
+					EntryProbe:         "uprobe_http2Server_WriteStatus2",
+					PackageConstraints: []probe.PackageConstraints{serverStreamVersions},
+					FailureMode:        probe.FailureModeIgnore,
+				},
+				{
+					Sym:         "google.golang.org/grpc.(*serverStream).SendMsg",
+					EntryProbe:  "uprobe_serverStream_SendMsg",
+					ReturnProbe: "uprobe_serverStream_Msg_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         "google.golang.org/grpc.(*serverStream).RecvMsg",
+					EntryProbe:  "uprobe_serverStream_RecvMsg",
+					ReturnProbe: "uprobe_serverStream_Msg_Returns",
+					FailureMode: probe.FailureModeIgnore,
 				},
 			},
-			SpecFn: loadBpf,
+			SpecFn:        loadBpf,
+			ProcessRecord: processRecord,
 		},
 		Version:   ver,
 		SchemaURL: semconv.SchemaURL,
 		ProcessFn: p.processFn,
+		MetricFn:  p.metricFn,
+		HandleMetricsFn: func(handled bool) {
+			p.recordDurations = handled
+		},
 	}
 }
 
@@ -270,49 +418,214 @@ func (c framePosConst) InjectOption(info *process.Info) (inject.Option, error) {
 	return inject.WithKeyValue("is_new_frame_pos", ver.GreaterThanEqual(paramChangeVer)), nil
 }
 
-type serverAddrConst struct{}
+// serverStreamConst is a Probe Const defining if the grpc.serverStream holds
+// a *transport.ServerStream instead of a *transport.Stream.
+type serverStreamConst struct{}
 
-var (
-	serverAddrMinVersion = semver.New(1, 60, 0, "", "")
-	serverAddr           = false
-)
+func (c serverStreamConst) InjectOption(info *process.Info) (inject.Option, error) {
+	ver, ok := info.Modules[pkg]
+	if !ok {
+		return nil, fmt.Errorf("unknown module version: %s", pkg)
+	}
+
+	return inject.WithKeyValue("is_server_stream", ver.GreaterThanEqual(serverStreamVersion)), nil
+}
+
+// metadataKeys returns the lowercase metadata keys to capture, ignoring and
+// logging the ones not supported.
+func metadataKeys(logger *slog.Logger, keys []string) []string {
+	var out []string
+	for _, k := range keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		switch {
+		case k == "" || slices.Contains(out, k):
+			continue
+		case len(k) > maxMetadataKeyLen:
+			logger.Warn("gRPC metadata key too long, ignoring", "key", k, "max", maxMetadataKeyLen)
+			continue
+		case len(out) == maxMetadataKeys:
+			logger.Warn("too many gRPC metadata keys, ignoring", "key", k, "max", maxMetadataKeys)
+			continue
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// metadataKeysConst returns the Const of the metadata keys to capture.
+func metadataKeysConst(keys []string) probe.KeyValConst {
+	var val [maxMetadataKeys][maxMetadataKeyLen]byte
+	for i, k := range keys {
+		copy(val[i][:], k)
+	}
+	return probe.KeyValConst{Key: "metadata_keys", Val: val}
+}
+
+// metadataKeyLensConst returns the Const of the lengths of the metadata keys
+// to capture.
+func metadataKeyLensConst(keys []string) probe.KeyValConst {
+	var val [maxMetadataKeys]uint64
+	for i, k := range keys {
+		val[i] = uint64(len(k))
+	}
+	return probe.KeyValConst{Key: "metadata_key_lens", Val: val}
+}
+
+// serverAddrConst is a Probe Const defining if the addresses of the
+// connections are read. It is recorded in the processor of the probe, so the
+// events of the processes of other versions are not affected.
+type serverAddrConst struct {
+	p *processor
+}
+
+var serverAddrMinVersion = semver.New(1, 60, 0, "", "")
 
 func (w serverAddrConst) InjectOption(info *process.Info) (inject.Option, error) {
 	ver, ok := info.Modules[pkg]
 	if !ok {
 		return nil, fmt.Errorf("unknown module version: %s", pkg)
 	}
-	if ver.GreaterThanEqual(serverAddrMinVersion) {
-		serverAddr = true
-	}
-	return inject.WithKeyValue("server_addr_supported", serverAddr), nil
+	w.p.serverAddr = ver.GreaterThanEqual(serverAddrMinVersion)
+	return inject.WithKeyValue("server_addr_supported", w.p.serverAddr), nil
+}
+
+// recordDurationsConst is a Probe Const defining if the durations of the
+// unsampled requests are output, i.e. if the metrics of the probe are handled.
+type recordDurationsConst struct {
+	p *processor
+}
+
+func (w recordDurationsConst) InjectOption(*process.Info) (inject.Option, error) {
+	return inject.WithKeyValue("record_durations", w.p.recordDurations), nil
 }
 
 // event represents an event in the gRPC server during a gRPC request.
 type event struct {
 	context.BaseSpanProperties
-	Method     [100]byte
+	// Method is the full method name, truncated to 256 bytes.
+	Method     [256]byte
 	StatusCode int32
-	LocalAddr  NetAddr
-	HasStatus  uint8
+	// StatusMessage is the message of the status written, truncated to 128
+	// bytes.
+	StatusMessage [128]byte
+	// LocalAddr and RemoteAddr are the addresses of a TCP connection, and
+	// LocalUnixPath and RemoteUnixPath the paths of a Unix domain socket one.
+	LocalAddr      netattr.NetAddr
+	RemoteAddr     netattr.NetAddr
+	RemoteUnixPath [108]byte
+	LocalUnixPath  [108]byte
+	// Authority is the :authority pseudo-header of the request, only read for
+	// the versions where the addresses of the connection are not.
+	Authority              [128]byte
+	HasStatus              uint8
+	StatusMessageTruncated uint8
+	MethodTruncated        uint8
+	_                      [1]byte // padding
+	TraceState             context.TraceState
+	// Metadata are the values of the captured metadata keys, in the order of
+	// the keys.
+	Metadata [maxMetadataKeys]metadataValue
+	// ReceivedMessages and SentMessages are the number of messages of the
+	// request, or of a partial event.
+	ReceivedMessages uint64
+	SentMessages     uint64
+	// The flushed counts, flush time, and stream are only used by the eBPF
+	// program.
+	_ [4]uint64
+	// Partial is not zero for the partial events, reporting the messages of
+	// a long-lived stream since the previous one.
+	Partial uint8
+	_       [7]byte // padding
+	// EndUser is only output by the eBPF program if the capture of the end
+	// user identity is enabled.
+	EndUser enduser.Value
 }
 
-type NetAddr struct {
-	IP   [16]uint8
-	Port int32
+// duration is the record output instead of the event of an unsampled request
+// when the durations are recorded.
+type duration struct {
+	StartTime       uint64
+	EndTime         uint64
+	StatusCode      int32
+	MethodTruncated uint8
+	Method          [256]byte
+	_               [3]byte // padding
+}
+
+// processRecord decodes the event or the duration output in record. The event
+// of a duration only holds the fields read by metricFn, it is not sampled.
+func processRecord(record perf.Record) (*event, error) {
+	if len(record.RawSample) >= binary.Size(event{})-binary.Size(enduser.Value{}) {
+		return enduser.Decode[event](record)
+	}
+
+	var d duration
+	err := binary.Read(bytes.NewReader(record.RawSample), binary.LittleEndian, &d)
+	if err != nil {
+		return nil, err
+	}
+	e := &event{
+		Method:          d.Method,
+		StatusCode:      d.StatusCode,
+		MethodTruncated: d.MethodTruncated,
+	}
+	e.StartTime = d.StartTime
+	e.EndTime = d.EndTime
+	return e, nil
+}
+
+// metadataValue is the value of a captured metadata key.
+type metadataValue struct {
+	Len   uint32
+	Value [maxMetadataValueLen]byte
 }
 
 type processor struct {
-	Logger *slog.Logger
+	Logger  *slog.Logger
+	endUser enduser.Config
+	// metadataKeys are the captured metadata keys.
+	metadataKeys []string
+	// serverAddr is true if the addresses of the connections are read for
+	// the version of the target.
+	serverAddr bool
+	// recordDurations is true if the metrics of the probe are handled, the
+	// durations of the unsampled requests are then output.
+	recordDurations bool
+}
+
+// rpcAttributes returns the span name of the request of e, and its
+// rpc.service and rpc.method attributes.
+func rpcAttributes(e *event) (string, []attribute.KeyValue) {
+	fullMethod := unix.ByteSliceToString(e.Method[:])
+
+	// Malformed methods are recorded as they are received.
+	name := fullMethod
+	rpcAttrs := []attribute.KeyValue{semconv.RPCServiceKey.String(fullMethod)}
+	if service, method, ok := parseMethod(fullMethod); ok {
+		name = service + "/" + method
+		rpcAttrs = []attribute.KeyValue{semconv.RPCService(service), semconv.RPCMethod(method)}
+	}
+	if e.MethodTruncated != 0 {
+		rpcAttrs = append(rpcAttrs, methodTruncatedKey.Bool(true))
+	}
+	return name, rpcAttrs
 }
 
 func (p *processor) processFn(e *event) ptrace.SpanSlice {
 	p.Logger.Debug("processing event", "event", e)
-	method := unix.ByteSliceToString(e.Method[:])
+	// The unsampled requests are only read for their duration.
+	if !e.SpanContext.TraceFlags.IsSampled() {
+		return ptrace.NewSpanSlice()
+	}
+
+	name, rpcAttrs := rpcAttributes(e)
+	if e.Partial != 0 {
+		return partialSpans(e, name, rpcAttrs)
+	}
 
 	spans := ptrace.NewSpanSlice()
 	span := spans.AppendEmpty()
-	span.SetName(method)
+	span.SetName(name)
 	span.SetKind(ptrace.SpanKindServer)
 	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
 	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
@@ -323,12 +636,11 @@ func (p *processor) processFn(e *event) ptrace.SpanSlice {
 	if e.ParentSpanContext.SpanID.IsValid() {
 		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
 	}
+	span.TraceState().FromRaw(e.TraceState.String())
 
-	attrs := []attribute.KeyValue{
-		semconv.RPCSystemKey.String("grpc"),
-		semconv.RPCServiceKey.String(method),
-		semconv.RPCGRPCStatusCodeKey.Int(int(e.StatusCode)),
-	}
+	attrs := []attribute.KeyValue{semconv.RPCSystemKey.String("grpc")}
+	attrs = append(attrs, rpcAttrs...)
+	attrs = append(attrs, semconv.RPCGRPCStatusCodeKey.Int(int(e.StatusCode)))
 
 	if e.HasStatus != 0 {
 		attrs = append(attrs, semconv.RPCGRPCStatusCodeKey.Int(int(e.StatusCode)))
@@ -340,15 +652,163 @@ func (p *processor) processFn(e *event) ptrace.SpanSlice {
 			int32(codes.Unimplemented), int32(codes.Internal),
 			int32(codes.Unavailable), int32(codes.DataLoss):
 			span.Status().SetCode(ptrace.StatusCodeError)
+			span.Status().SetMessage(statusMessage(e))
 		}
 	}
 
-	if serverAddr {
-		attrs = append(attrs, semconv.ServerAddress(net.IP(e.LocalAddr.IP[:]).String()))
-		attrs = append(attrs, semconv.ServerPort(int(e.LocalAddr.Port)))
+	var local, remote netattr.Addr
+	if p.serverAddr {
+		local = netAddr(&e.LocalAddr, e.LocalUnixPath[:])
+		remote = netAddr(&e.RemoteAddr, e.RemoteUnixPath[:])
+	}
+	if local.Host == "" {
+		// The server address requested by the client is used when the
+		// address of the connection is not known.
+		local = netattr.ParseHostPort(unix.ByteSliceToString(e.Authority[:]))
+	}
+	if remote.Host != "" {
+		attrs = append(attrs, semconv.ClientAddress(remote.Host))
+		if remote.Port > 0 {
+			attrs = append(attrs, semconv.ClientPort(remote.Port))
+		}
 	}
+	attrs = append(attrs, netattr.Attributes(local, remote)...)
+	if kv := local.NetworkType(); kv.Valid() {
+		attrs = append(attrs, kv)
+	} else if kv := remote.NetworkType(); kv.Valid() {
+		attrs = append(attrs, kv)
+	}
+	attrs = append(attrs, p.endUser.Attributes(&e.EndUser)...)
+	attrs = append(attrs, p.metadataAttributes(e)...)
+	attrs = append(attrs, messageAttributes(e)...)
 
 	pdataconv.Attributes(span.Attributes(), attrs...)
 
 	return spans
 }
+
+// metricFn returns the rpc.server.duration histogram data point of the
+// request of e, in milliseconds. Partial events are not requests, no metric
+// is returned for them.
+func (p *processor) metricFn(e *event) pmetric.MetricSlice {
+	metrics := pmetric.NewMetricSlice()
+	if e.Partial != 0 || e.EndTime < e.StartTime {
+		return metrics
+	}
+
+	_, rpcAttrs := rpcAttributes(e)
+	attrs := make([]attribute.KeyValue, 0, len(rpcAttrs)+2)
+	attrs = append(attrs, semconv.RPCSystemGRPC)
+	attrs = append(attrs, rpcAttrs...)
+	attrs = append(attrs, semconv.RPCGRPCStatusCodeKey.Int(int(e.StatusCode)))
+
+	m := metrics.AppendEmpty()
+	m.SetName(semconv.RPCServerDurationName)
+	m.SetUnit(semconv.RPCServerDurationUnit)
+	m.SetDescription(semconv.RPCServerDurationDescription)
+	hist := m.SetEmptyHistogram()
+	hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+
+	ms := float64(e.EndTime-e.StartTime) / float64(time.Millisecond)
+	dp := hist.DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	dp.SetTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	dp.SetCount(1)
+	dp.SetSum(ms)
+	dp.SetMin(ms)
+	dp.SetMax(ms)
+	pdataconv.Attributes(dp.Attributes(), attrs...)
+	return metrics
+}
+
+// partialSpans returns the span of the partial event e, reporting the messages
+// of the stream of the request span name since the previous partial event.
+func partialSpans(e *event, name string, rpcAttrs []attribute.KeyValue) ptrace.SpanSlice {
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name + " messages")
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	attrs := []attribute.KeyValue{semconv.RPCSystemKey.String("grpc")}
+	attrs = append(attrs, rpcAttrs...)
+	attrs = append(attrs, messageAttributes(e)...)
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// messageAttributes returns the attributes of the number of messages of e, if
+// any are counted.
+func messageAttributes(e *event) []attribute.KeyValue {
+	if e.ReceivedMessages == 0 && e.SentMessages == 0 {
+		return nil
+	}
+	return []attribute.KeyValue{
+		requestMessagesKey.Int64(int64(min(e.ReceivedMessages, math.MaxInt64))), // nolint: gosec  // Bounded.
+		responseMessagesKey.Int64(int64(min(e.SentMessages, math.MaxInt64))),    // nolint: gosec  // Bounded.
+	}
+}
+
+// netAddr returns the address of an end of a connection read as the TCP
+// address addr or the Unix domain socket path unixPath, the zero Addr if it is
+// not known.
+func netAddr(addr *netattr.NetAddr, unixPath []byte) netattr.Addr {
+	if path := unix.ByteSliceToString(unixPath); path != "" {
+		return netattr.Addr{Transport: netattr.TransportUnix, Host: path}
+	}
+	return addr.Addr()
+}
+
+// metadataAttributes returns the rpc.grpc.request.metadata.<key> attributes
+// of the metadata captured in e.
+func (p *processor) metadataAttributes(e *event) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for i, k := range p.metadataKeys {
+		if i >= len(e.Metadata) {
+			break
+		}
+		n := e.Metadata[i].Len
+		if n == 0 || n > maxMetadataValueLen {
+			continue
+		}
+		// The value is copied into a new heap string: a plain conversion
+		// can be stack allocated as the attribute hides the reference.
+		var val strings.Builder
+		val.Write(e.Metadata[i].Value[:n])
+		key := attribute.Key(metadataKeyPrefix + k)
+		attrs = append(attrs, key.StringSlice([]string{val.String()}))
+	}
+	return attrs
+}
+
+// statusMessage returns the status message of e. A truncated message is
+// suffixed with "...".
+func statusMessage(e *event) string {
+	msg := unix.ByteSliceToString(e.StatusMessage[:])
+	if e.StatusMessageTruncated != 0 {
+		msg += "..."
+	}
+	return msg
+}
+
+// parseMethod returns the service and method of the full method name of a
+// gRPC call, e.g. "package.Service" and "Method" for
+// "/package.Service/Method". The ok result is false if fullMethod is not of
+// this form.
+func parseMethod(fullMethod string) (service, method string, ok bool) {
+	name, ok := strings.CutPrefix(fullMethod, "/")
+	if !ok {
+		return "", "", false
+	}
+	service, method, ok = strings.Cut(name, "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", false
+	}
+	return service, method, true
+}
@@ -0,0 +1,600 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf/perf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+)
+
+func TestProcessFnMethod(t *testing.T) {
+	// Names longer than the 256 bytes of the event are truncated by the
+	// eBPF program.
+	long := "/" + strings.Repeat("a", 200) + ".Service/" + strings.Repeat("M", 100)
+	truncated := long[:len(event{}.Method)]
+	noMethod := "/" + strings.Repeat("a", len(event{}.Method)-1)
+
+	tests := []struct {
+		name        string
+		method      string
+		wantName    string
+		truncated   bool
+		wantService string
+		wantMethod  string
+	}{
+		{
+			name:        "Standard",
+			method:      "/helloworld.Greeter/SayHello",
+			wantName:    "helloworld.Greeter/SayHello",
+			wantService: "helloworld.Greeter",
+			wantMethod:  "SayHello",
+		},
+		{
+			name:        "HealthCheck",
+			method:      "/grpc.health.v1.Health/Check",
+			wantName:    "grpc.health.v1.Health/Check",
+			wantService: "grpc.health.v1.Health",
+			wantMethod:  "Check",
+		},
+		{
+			name:        "Truncated",
+			method:      truncated,
+			truncated:   true,
+			wantName:    truncated[1:],
+			wantService: strings.Repeat("a", 200) + ".Service",
+			wantMethod:  strings.Repeat("M", 46),
+		},
+		{
+			name:        "TruncatedService",
+			method:      noMethod,
+			truncated:   true,
+			wantName:    noMethod,
+			wantService: noMethod,
+		},
+		{
+			name:        "NoLeadingSlash",
+			method:      "helloworld.Greeter/SayHello",
+			wantName:    "helloworld.Greeter/SayHello",
+			wantService: "helloworld.Greeter/SayHello",
+		},
+		{
+			name:        "EmptyMethod",
+			method:      "/helloworld.Greeter/",
+			wantName:    "/helloworld.Greeter/",
+			wantService: "/helloworld.Greeter/",
+		},
+	}
+
+	p := &processor{Logger: slog.Default()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := sampled(&event{})
+			copy(e.Method[:], tt.method)
+			if tt.truncated {
+				e.MethodTruncated = 1
+			}
+
+			spans := p.processFn(e)
+			require.Equal(t, 1, spans.Len())
+			span := spans.At(0)
+			assert.Equal(t, tt.wantName, span.Name())
+
+			attrs := span.Attributes().AsRaw()
+			assert.Equal(t, tt.wantService, attrs[string(semconv.RPCServiceKey)])
+			if tt.wantMethod == "" {
+				assert.NotContains(t, attrs, string(semconv.RPCMethodKey))
+			} else {
+				assert.Equal(t, tt.wantMethod, attrs[string(semconv.RPCMethodKey)])
+			}
+			if tt.truncated {
+				assert.Equal(t, true, attrs[string(methodTruncatedKey)])
+			} else {
+				assert.NotContains(t, attrs, string(methodTruncatedKey))
+			}
+		})
+	}
+}
+
+func TestProcessFnStatus(t *testing.T) {
+	long := strings.Repeat("x", len(event{}.StatusMessage))
+
+	tests := []struct {
+		name      string
+		code      codes.Code
+		msg       string
+		truncated bool
+		wantCode  ptrace.StatusCode
+		wantMsg   string
+	}{
+		{
+			name:     "OK",
+			code:     codes.OK,
+			wantCode: ptrace.StatusCodeUnset,
+		},
+		{
+			name:     "ClientError",
+			code:     codes.NotFound,
+			msg:      "user not found",
+			wantCode: ptrace.StatusCodeUnset,
+		},
+		{
+			name:     "ServerError",
+			code:     codes.Unimplemented,
+			msg:      "unimplemented",
+			wantCode: ptrace.StatusCodeError,
+			wantMsg:  "unimplemented",
+		},
+		{
+			name:      "Truncated",
+			code:      codes.Internal,
+			msg:       long,
+			truncated: true,
+			wantCode:  ptrace.StatusCodeError,
+			wantMsg:   long + "...",
+		},
+	}
+
+	p := &processor{Logger: slog.Default()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := sampled(&event{StatusCode: int32(tt.code), HasStatus: 1})
+			copy(e.Method[:], "/helloworld.Greeter/SayHello")
+			copy(e.StatusMessage[:], tt.msg)
+			if tt.truncated {
+				e.StatusMessageTruncated = 1
+			}
+
+			spans := p.processFn(e)
+			require.Equal(t, 1, spans.Len())
+			status := spans.At(0).Status()
+			assert.Equal(t, tt.wantCode, status.Code())
+			assert.Equal(t, tt.wantMsg, status.Message())
+		})
+	}
+}
+
+func TestProcessFnMetadata(t *testing.T) {
+	p := &processor{
+		Logger:       slog.Default(),
+		metadataKeys: []string{"x-tenant-id", "x-request-id", "x-missing"},
+	}
+
+	e := sampled(&event{})
+	copy(e.Method[:], "/helloworld.Greeter/SayHello")
+	setMetadata := func(i int, v string) {
+		e.Metadata[i].Len = uint32(copy(e.Metadata[i].Value[:], v)) // nolint: gosec  // Bounded.
+	}
+	setMetadata(0, "tenant")
+	setMetadata(1, strings.Repeat("r", 100))
+
+	spans := p.processFn(e)
+	require.Equal(t, 1, spans.Len())
+	attrs := spans.At(0).Attributes().AsRaw()
+	assert.Equal(t, []any{"tenant"}, attrs["rpc.grpc.request.metadata.x-tenant-id"])
+	assert.Equal(t, []any{strings.Repeat("r", 64)}, attrs["rpc.grpc.request.metadata.x-request-id"])
+	assert.NotContains(t, attrs, "rpc.grpc.request.metadata.x-missing")
+}
+
+func TestMetadataKeys(t *testing.T) {
+	keys := metadataKeys(slog.Default(), []string{
+		"X-Tenant-ID",
+		"x-tenant-id",
+		"",
+		strings.Repeat("k", maxMetadataKeyLen+1),
+		"b",
+		"c",
+		"d",
+		"e",
+	})
+	assert.Equal(t, []string{"x-tenant-id", "b", "c", "d"}, keys)
+
+	assert.Equal(t, uint64(len("x-tenant-id")), metadataKeyLensConst(keys).Val.([maxMetadataKeys]uint64)[0])
+}
+
+func TestProcessFnClientAddress(t *testing.T) {
+	local := netattr.NetAddr{IP: [16]uint8{127, 0, 0, 1}, Port: 1701, IPLen: 4}
+
+	tests := []struct {
+		name          string
+		remote        netattr.NetAddr
+		unixPath      string
+		wantAddr      any
+		wantPort      any
+		wantPeer      any
+		wantTransport string
+	}{
+		{
+			name:          "TCP",
+			remote:        netattr.NetAddr{IP: [16]uint8{10, 0, 0, 2}, Port: 52044, IPLen: 4},
+			wantAddr:      "10.0.0.2",
+			wantPort:      int64(52044),
+			wantPeer:      "10.0.0.2",
+			wantTransport: "tcp",
+		},
+		{
+			name:          "Unix",
+			unixPath:      "/run/grpc.sock",
+			wantAddr:      "/run/grpc.sock",
+			wantPeer:      "/run/grpc.sock",
+			wantTransport: "unix",
+		},
+		{
+			name:          "Unknown",
+			wantTransport: "tcp",
+		},
+	}
+
+	p := &processor{Logger: slog.Default(), serverAddr: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := sampled(&event{LocalAddr: local, RemoteAddr: tt.remote})
+			copy(e.Method[:], "/helloworld.Greeter/SayHello")
+			copy(e.RemoteUnixPath[:], tt.unixPath)
+
+			spans := p.processFn(e)
+			require.Equal(t, 1, spans.Len())
+			attrs := spans.At(0).Attributes().AsRaw()
+			assert.Equal(t, tt.wantAddr, attrs[string(semconv.ClientAddressKey)])
+			assert.Equal(t, tt.wantPort, attrs[string(semconv.ClientPortKey)])
+			assert.Equal(t, tt.wantPeer, attrs[string(semconv.NetworkPeerAddressKey)])
+			assert.Equal(t, tt.wantTransport, attrs[string(semconv.NetworkTransportKey)])
+			assert.Equal(t, "127.0.0.1", attrs[string(semconv.ServerAddressKey)])
+			assert.Equal(t, int64(1701), attrs[string(semconv.ServerPortKey)])
+		})
+	}
+}
+
+func TestProcessFnMessages(t *testing.T) {
+	p := &processor{Logger: slog.Default()}
+
+	e := sampled(&event{ReceivedMessages: 3, SentMessages: 1})
+	copy(e.Method[:], "/helloworld.Greeter/SayHello")
+	spans := p.processFn(e)
+	require.Equal(t, 1, spans.Len())
+	attrs := spans.At(0).Attributes().AsRaw()
+	assert.Equal(t, int64(3), attrs[string(requestMessagesKey)])
+	assert.Equal(t, int64(1), attrs[string(responseMessagesKey)])
+
+	e = sampled(&event{})
+	copy(e.Method[:], "/helloworld.Greeter/SayHello")
+	spans = p.processFn(e)
+	require.Equal(t, 1, spans.Len())
+	attrs = spans.At(0).Attributes().AsRaw()
+	assert.NotContains(t, attrs, string(requestMessagesKey))
+	assert.NotContains(t, attrs, string(responseMessagesKey))
+}
+
+func TestProcessFnPartial(t *testing.T) {
+	p := &processor{Logger: slog.Default()}
+
+	e := sampled(&event{SentMessages: 42, Partial: 1})
+	e.SpanContext.SpanID = trace.SpanID{2}
+	e.ParentSpanContext.SpanID = trace.SpanID{1}
+	copy(e.Method[:], "/helloworld.Greeter/StreamHello")
+	e.StatusCode = int32(codes.Internal)
+
+	spans := p.processFn(e)
+	require.Equal(t, 1, spans.Len())
+	span := spans.At(0)
+	assert.Equal(t, "helloworld.Greeter/StreamHello messages", span.Name())
+	assert.Equal(t, ptrace.SpanKindInternal, span.Kind())
+	assert.Equal(t, pcommon.SpanID{2}, span.SpanID())
+	assert.Equal(t, pcommon.SpanID{1}, span.ParentSpanID())
+	assert.Equal(t, ptrace.StatusCodeUnset, span.Status().Code())
+
+	attrs := span.Attributes().AsRaw()
+	assert.Equal(t, "helloworld.Greeter", attrs[string(semconv.RPCServiceKey)])
+	assert.Equal(t, "StreamHello", attrs[string(semconv.RPCMethodKey)])
+	assert.Equal(t, int64(0), attrs[string(requestMessagesKey)])
+	assert.Equal(t, int64(42), attrs[string(responseMessagesKey)])
+	assert.NotContains(t, attrs, string(semconv.RPCGRPCStatusCodeKey))
+}
+
+func TestProcessFnServerAddress(t *testing.T) {
+	tests := []struct {
+		name          string
+		local         netattr.NetAddr
+		unixPath      string
+		wantAddr      any
+		wantPort      any
+		wantTransport any
+		wantType      any
+	}{
+		{
+			name:          "IPv4",
+			local:         netattr.NetAddr{IP: [16]uint8{127, 0, 0, 1}, Port: 1701, IPLen: 4},
+			wantAddr:      "127.0.0.1",
+			wantPort:      int64(1701),
+			wantTransport: "tcp",
+			wantType:      "ipv4",
+		},
+		{
+			name:          "IPv4Mapped",
+			local:         netattr.NetAddr{IP: [16]uint8{10: 0xff, 11: 0xff, 12: 10, 15: 5}, Port: 1701, IPLen: 16},
+			wantAddr:      "10.0.0.5",
+			wantPort:      int64(1701),
+			wantTransport: "tcp",
+			wantType:      "ipv4",
+		},
+		{
+			name:  "Unspecified",
+			local: netattr.NetAddr{Port: 1701, IPLen: 16},
+		},
+		{
+			name:          "IPv6",
+			local:         netattr.NetAddr{IP: [16]uint8{15: 1}, Port: 1701, IPLen: 16},
+			wantAddr:      "::1",
+			wantPort:      int64(1701),
+			wantTransport: "tcp",
+			wantType:      "ipv6",
+		},
+		{
+			name:          "Unix",
+			unixPath:      "/run/grpc.sock",
+			wantAddr:      "/run/grpc.sock",
+			wantTransport: "unix",
+		},
+		{
+			name: "Unknown",
+		},
+	}
+
+	p := &processor{Logger: slog.Default(), serverAddr: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := sampled(&event{LocalAddr: tt.local})
+			copy(e.Method[:], "/helloworld.Greeter/SayHello")
+			copy(e.LocalUnixPath[:], tt.unixPath)
+
+			spans := p.processFn(e)
+			require.Equal(t, 1, spans.Len())
+			attrs := spans.At(0).Attributes().AsRaw()
+			assert.Equal(t, tt.wantAddr, attrs[string(semconv.ServerAddressKey)])
+			assert.Equal(t, tt.wantPort, attrs[string(semconv.ServerPortKey)])
+			assert.Equal(t, tt.wantTransport, attrs[string(semconv.NetworkTransportKey)])
+			assert.Equal(t, tt.wantType, attrs[string(semconv.NetworkTypeKey)])
+		})
+	}
+}
+
+func TestServerAddrConstPerProbe(t *testing.T) {
+	newInfo := func(ver string) *process.Info {
+		return &process.Info{
+			Modules: map[string]*semver.Version{pkg: semver.MustParse(ver)},
+		}
+	}
+
+	// The probes of two processes, the first one supporting the addresses.
+	newer := &processor{Logger: slog.Default()}
+	older := &processor{Logger: slog.Default()}
+	_, err := serverAddrConst{p: newer}.InjectOption(newInfo("1.66.0"))
+	require.NoError(t, err)
+	_, err = serverAddrConst{p: older}.InjectOption(newInfo("1.59.0"))
+	require.NoError(t, err)
+
+	assert.True(t, newer.serverAddr)
+	assert.False(t, older.serverAddr)
+
+	newEvent := func() *event {
+		e := sampled(&event{LocalAddr: netattr.NetAddr{IP: [16]uint8{127, 0, 0, 1}, Port: 1701, IPLen: 4}})
+		copy(e.Method[:], "/helloworld.Greeter/SayHello")
+		return e
+	}
+
+	attrs := newer.processFn(newEvent()).At(0).Attributes().AsRaw()
+	assert.Equal(t, "127.0.0.1", attrs[string(semconv.ServerAddressKey)])
+
+	attrs = older.processFn(newEvent()).At(0).Attributes().AsRaw()
+	assert.NotContains(t, attrs, string(semconv.ServerAddressKey))
+}
+
+func TestProcessFnAuthority(t *testing.T) {
+	local := netattr.NetAddr{IP: [16]uint8{127, 0, 0, 1}, Port: 1701, IPLen: 4}
+
+	tests := []struct {
+		name       string
+		serverAddr bool
+		local      netattr.NetAddr
+		authority  string
+		wantAddr   any
+		wantPort   any
+	}{
+		{
+			name:      "Host",
+			authority: "example.com:50051",
+			wantAddr:  "example.com",
+			wantPort:  int64(50051),
+		},
+		{
+			name:      "IPv6",
+			authority: "[2001:db8::1]:50051",
+			wantAddr:  "2001:db8::1",
+			wantPort:  int64(50051),
+		},
+		{
+			name:      "NoPort",
+			authority: "[::1]",
+			wantAddr:  "::1",
+		},
+		{
+			name:       "PeerPreferred",
+			serverAddr: true,
+			local:      local,
+			authority:  "example.com:50051",
+			wantAddr:   "127.0.0.1",
+			wantPort:   int64(1701),
+		},
+		{
+			name:       "PeerUnknown",
+			serverAddr: true,
+			authority:  "example.com:50051",
+			wantAddr:   "example.com",
+			wantPort:   int64(50051),
+		},
+		{
+			name: "None",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &processor{Logger: slog.Default(), serverAddr: tt.serverAddr}
+			e := sampled(&event{LocalAddr: tt.local})
+			copy(e.Method[:], "/helloworld.Greeter/SayHello")
+			copy(e.Authority[:], tt.authority)
+
+			spans := p.processFn(e)
+			require.Equal(t, 1, spans.Len())
+			attrs := spans.At(0).Attributes().AsRaw()
+			assert.Equal(t, tt.wantAddr, attrs[string(semconv.ServerAddressKey)])
+			assert.Equal(t, tt.wantPort, attrs[string(semconv.ServerPortKey)])
+		})
+	}
+}
+
+func TestProcessFnUnsampled(t *testing.T) {
+	p := &processor{Logger: slog.Default()}
+
+	e := &event{}
+	copy(e.Method[:], "/helloworld.Greeter/SayHello")
+	assert.Equal(t, 0, p.processFn(e).Len())
+	assert.Equal(t, 1, p.metricFn(e).Len(), "unsampled request duration")
+}
+
+func TestMetricFn(t *testing.T) {
+	p := &processor{Logger: slog.Default()}
+
+	e := &event{StatusCode: int32(codes.NotFound)}
+	e.StartTime = 1_000_000
+	e.EndTime = 3_500_000
+	copy(e.Method[:], "/helloworld.Greeter/SayHello")
+
+	metrics := p.metricFn(e)
+	require.Equal(t, 1, metrics.Len())
+	m := metrics.At(0)
+	assert.Equal(t, semconv.RPCServerDurationName, m.Name())
+	assert.Equal(t, semconv.RPCServerDurationUnit, m.Unit())
+	require.Equal(t, pmetric.MetricTypeHistogram, m.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, m.Histogram().AggregationTemporality())
+
+	require.Equal(t, 1, m.Histogram().DataPoints().Len())
+	dp := m.Histogram().DataPoints().At(0)
+	assert.Equal(t, uint64(1), dp.Count())
+	assert.InDelta(t, 2.5, dp.Sum(), 1e-9)
+	assert.InDelta(t, 2.5, dp.Min(), 1e-9)
+	assert.InDelta(t, 2.5, dp.Max(), 1e-9)
+	assert.Equal(t, map[string]any{
+		string(semconv.RPCSystemKey):         "grpc",
+		string(semconv.RPCServiceKey):        "helloworld.Greeter",
+		string(semconv.RPCMethodKey):         "SayHello",
+		string(semconv.RPCGRPCStatusCodeKey): int64(codes.NotFound),
+	}, dp.Attributes().AsRaw())
+
+	// Partial events are not requests.
+	e = &event{Partial: 1}
+	copy(e.Method[:], "/helloworld.Greeter/SayHello")
+	assert.Equal(t, 0, p.metricFn(e).Len())
+}
+
+func TestProcessRecordDuration(t *testing.T) {
+	d := duration{
+		StartTime:       1_000_000,
+		EndTime:         3_500_000,
+		StatusCode:      int32(codes.NotFound),
+		MethodTruncated: 1,
+	}
+	copy(d.Method[:], "/helloworld.Greeter/SayHello")
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, d))
+
+	e, err := processRecord(perf.Record{RawSample: buf.Bytes()})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1_000_000), e.StartTime)
+	assert.Equal(t, uint64(3_500_000), e.EndTime)
+	assert.False(t, e.SpanContext.TraceFlags.IsSampled())
+
+	p := &processor{Logger: slog.Default()}
+	assert.Equal(t, 0, p.processFn(e).Len())
+	metrics := p.metricFn(e)
+	require.Equal(t, 1, metrics.Len())
+	dp := metrics.At(0).Histogram().DataPoints().At(0)
+	assert.InDelta(t, 2.5, dp.Sum(), 1e-9)
+	assert.Equal(t, map[string]any{
+		string(semconv.RPCSystemKey):         "grpc",
+		string(semconv.RPCServiceKey):        "helloworld.Greeter",
+		string(semconv.RPCMethodKey):         "SayHello",
+		string(methodTruncatedKey):           true,
+		string(semconv.RPCGRPCStatusCodeKey): int64(codes.NotFound),
+	}, dp.Attributes().AsRaw())
+
+	_, err = processRecord(perf.Record{RawSample: buf.Bytes()[:16]})
+	assert.Error(t, err, "truncated duration")
+}
+
+func TestRecordDurationsConst(t *testing.T) {
+	sp, ok := New(slog.Default(), "", nil).(*probe.SpanProducer[bpfObjects, event])
+	require.True(t, ok)
+
+	var c recordDurationsConst
+	for _, cnst := range sp.Consts {
+		if rc, ok := cnst.(recordDurationsConst); ok {
+			c = rc
+		}
+	}
+	require.NotNil(t, c.p, "record_durations const")
+
+	assert.False(t, c.p.recordDurations)
+	sp.HandleMetrics(true)
+	assert.True(t, c.p.recordDurations)
+	sp.HandleMetrics(false)
+	assert.False(t, c.p.recordDurations)
+}
+
+// sampled returns e with a sampled span context.
+func sampled(e *event) *event {
+	e.SpanContext.TraceFlags = trace.FlagsSampled
+	return e
+}
+
+func TestVersionConstraints(t *testing.T) {
+	tests := []struct {
+		version      string
+		stream       bool
+		writeStatus  bool
+		serverStream bool
+	}{
+		{version: "1.40.0", stream: true},
+		{version: "1.40.1", stream: true, writeStatus: true},
+		{version: "1.68.2", stream: true, writeStatus: true},
+		{version: "1.69.0", serverStream: true},
+		{version: "1.72.0", serverStream: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v := semver.MustParse(tt.version)
+			assert.Equal(t, tt.stream, streamVersions.Constraints.Check(v), "stream")
+			assert.Equal(t, tt.writeStatus, writeStatusVersions.Constraints.Check(v), "writeStatus")
+			assert.Equal(t, tt.serverStream, serverStreamVersions.Constraints.Check(v), "serverStream")
+		})
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+// The number of watches, and of the goroutines receiving their events,
+// tracked. The least recently used ones are evicted once it is reached.
+#define MAX_WATCHES 1024
+#define MAX_VERB_SIZE 8
+// https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-label-names
+#define MAX_NAMESPACE_SIZE 64
+#define MAX_RESOURCE_SIZE 64
+#define MAX_SUBRESOURCE_SIZE 32
+#define MAX_EVENT_TYPE_SIZE 16
+
+struct k8s_request_t {
+    BASE_SPAN_PROPERTIES
+    // The HTTP method of the request.
+    char verb[MAX_VERB_SIZE];
+    char namespace[MAX_NAMESPACE_SIZE];
+    char resource[MAX_RESOURCE_SIZE];
+    char subresource[MAX_SUBRESOURCE_SIZE];
+    // The type of the event received by a watch, if is_watch is set.
+    char event_type[MAX_EVENT_TYPE_SIZE];
+    u8 has_name;
+    u8 is_watch;
+    u8 has_error;
+    u8 padding[5];
+};
+
+// Requests being sent, keyed by the goroutine sending them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct k8s_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} k8s_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct k8s_request_t));
+    __uint(max_entries, 1);
+} k8s_storage_map SEC(".maps");
+
+// Watches being started, keyed by the goroutine starting them. The psc is the
+// span context of the caller, if any.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct k8s_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} k8s_watch_starts SEC(".maps");
+
+// Watches started, keyed by the decoder of their events.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct k8s_request_t);
+    __uint(max_entries, MAX_WATCHES);
+} k8s_watches SEC(".maps");
+
+// The decoder of the events being decoded, keyed by the goroutine decoding
+// them.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_WATCHES);
+} k8s_decodes SEC(".maps");
+
+// The last event decoded, keyed by the goroutine decoding it. Its span ends
+// when the goroutine decodes the next one, once the event is delivered.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct k8s_request_t);
+    __uint(max_entries, MAX_WATCHES);
+} k8s_watch_events SEC(".maps");
+
+// Injected in init
+volatile const u64 request_verb_pos;
+volatile const u64 request_namespace_pos;
+volatile const u64 request_resource_pos;
+volatile const u64 request_resource_name_pos;
+volatile const u64 request_subresource_pos;
+volatile const u64 stream_watcher_source_pos;
+
+static __always_inline struct k8s_request_t *new_request(void *request) {
+    u32 zero = 0;
+    struct k8s_request_t *k8s_request = bpf_map_lookup_elem(&k8s_storage_map, &zero);
+    if (k8s_request == NULL) {
+        return NULL;
+    }
+    __builtin_memset(k8s_request, 0, sizeof(struct k8s_request_t));
+
+    get_go_string_from_user_ptr((void *)(request + request_verb_pos), k8s_request->verb, sizeof(k8s_request->verb));
+    get_go_string_from_user_ptr((void *)(request + request_namespace_pos), k8s_request->namespace, sizeof(k8s_request->namespace));
+    get_go_string_from_user_ptr((void *)(request + request_resource_pos), k8s_request->resource, sizeof(k8s_request->resource));
+    get_go_string_from_user_ptr((void *)(request + request_subresource_pos), k8s_request->subresource, sizeof(k8s_request->subresource));
+
+    struct go_string name = {0};
+    bpf_probe_read_user(&name, sizeof(name), (void *)(request + request_resource_name_pos));
+    if (name.len > 0) {
+        k8s_request->has_name = 1;
+    }
+    return k8s_request;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Request) request(ctx context.Context, fn func(*http.Request, *http.Response)) error
+SEC("uprobe/Request_request")
+int uprobe_Request_request(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&k8s_events, &key) != NULL) {
+        return 0;
+    }
+
+    struct k8s_request_t *k8s_request = new_request(get_argument(ctx, 1));
+    if (k8s_request == NULL) {
+        bpf_printk("uprobe/Request_request: k8s_request is NULL");
+        return 0;
+    }
+    k8s_request->start_time = get_time_ns();
+
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &k8s_request->psc,
+        .sc = &k8s_request->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    // The HTTP requests sent, including the retries, are children of this
+    // span.
+    start_tracking_span(go_context.data, &k8s_request->sc);
+
+    bpf_map_update_elem(&k8s_events, &key, k8s_request, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Request) request(ctx context.Context, fn func(*http.Request, *http.Response)) error
+SEC("uprobe/Request_request")
+int uprobe_Request_request_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct k8s_request_t *k8s_request = bpf_map_lookup_elem(&k8s_events, &key);
+    if (k8s_request == NULL) {
+        return 0;
+    }
+    k8s_request->end_time = end_time;
+
+    // The returned error is a non-nil interface on failure.
+    if (get_argument(ctx, 1) != NULL) {
+        k8s_request->has_error = 1;
+    }
+
+    output_span_event(ctx, k8s_request, sizeof(*k8s_request), &k8s_request->sc);
+    stop_tracking_span(&k8s_request->sc, &k8s_request->psc);
+    bpf_map_delete_elem(&k8s_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Request) Watch(ctx context.Context) (watch.Interface, error)
+SEC("uprobe/Request_Watch")
+int uprobe_Request_Watch(struct pt_regs *ctx) {
+    struct k8s_request_t *k8s_request = new_request(get_argument(ctx, 1));
+    if (k8s_request == NULL) {
+        bpf_printk("uprobe/Request_Watch: k8s_request is NULL");
+        return 0;
+    }
+    k8s_request->is_watch = 1;
+
+    // The spans of the events are children of the span of the caller.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    struct span_context *psc = get_parent_span_context(&go_context);
+    if (psc != NULL) {
+        k8s_request->psc = *psc;
+    }
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&k8s_watch_starts, &key, k8s_request, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Request) Watch(ctx context.Context) (watch.Interface, error)
+SEC("uprobe/Request_Watch")
+int uprobe_Request_Watch_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct k8s_request_t *k8s_request = bpf_map_lookup_elem(&k8s_watch_starts, &key);
+    if (k8s_request == NULL) {
+        return 0;
+    }
+
+    // The watcher returned is a *watch.StreamWatcher, its events are decoded
+    // by its source in the goroutine it starts.
+    void *watcher = get_argument(ctx, 2);
+    if (watcher != NULL && get_argument(ctx, 3) == NULL) {
+        struct go_iface source = {0};
+        bpf_probe_read_user(&source, sizeof(source), (void *)(watcher + stream_watcher_source_pos));
+        if (source.data != NULL) {
+            bpf_map_update_elem(&k8s_watches, &source.data, k8s_request, 0);
+        }
+    }
+
+    bpf_map_delete_elem(&k8s_watch_starts, &key);
+    return 0;
+}
+
+static __always_inline long get_watch_parent_span_context(void *watch, struct span_context *psc) {
+    struct k8s_request_t *k8s_watch = watch;
+    if (!is_span_context_valid(&k8s_watch->psc)) {
+        return -1;
+    }
+    *psc = k8s_watch->psc;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (d *Decoder) Decode() (watch.EventType, runtime.Object, error)
+SEC("uprobe/Decoder_Decode")
+int uprobe_Decoder_Decode(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+
+    // The previous event is delivered, the goroutine decodes the next one.
+    struct k8s_request_t *event = bpf_map_lookup_elem(&k8s_watch_events, &key);
+    if (event != NULL) {
+        event->end_time = end_time;
+        output_span_event(ctx, event, sizeof(*event), &event->sc);
+        bpf_map_delete_elem(&k8s_watch_events, &key);
+    }
+
+    void *decoder = get_argument(ctx, 1);
+    if (bpf_map_lookup_elem(&k8s_watches, &decoder) == NULL) {
+        return 0;
+    }
+    bpf_map_update_elem(&k8s_decodes, &key, &decoder, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (d *Decoder) Decode() (watch.EventType, runtime.Object, error)
+SEC("uprobe/Decoder_Decode")
+int uprobe_Decoder_Decode_Returns(struct pt_regs *ctx) {
+    u64 start_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    void **decoder_ptr = bpf_map_lookup_elem(&k8s_decodes, &key);
+    if (decoder_ptr == NULL) {
+        return 0;
+    }
+    void *decoder = *decoder_ptr;
+    bpf_map_delete_elem(&k8s_decodes, &key);
+
+    // The watch ends when the decoder returns an error.
+    if (get_argument(ctx, 5) != NULL) {
+        bpf_map_delete_elem(&k8s_watches, &decoder);
+        return 0;
+    }
+
+    struct k8s_request_t *k8s_watch = bpf_map_lookup_elem(&k8s_watches, &decoder);
+    if (k8s_watch == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct k8s_request_t *event = bpf_map_lookup_elem(&k8s_storage_map, &zero);
+    if (event == NULL) {
+        bpf_printk("uprobe/Decoder_Decode: event is NULL");
+        return 0;
+    }
+    *event = *k8s_watch;
+    event->start_time = start_time;
+
+    // The event type is a string.
+    void *event_type_ptr = get_argument(ctx, 1);
+    u64 event_type_len = (u64)get_argument(ctx, 2);
+    u64 event_type_size = MAX_EVENT_TYPE_SIZE < event_type_len ? MAX_EVENT_TYPE_SIZE : event_type_len;
+    bpf_probe_read_user(event->event_type, event_type_size, event_type_ptr);
+
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &event->psc,
+        .sc = &event->sc,
+        .get_parent_span_context_fn = get_watch_parent_span_context,
+        .get_parent_span_context_arg = k8s_watch,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&k8s_watch_events, &key, event, 0);
+    return 0;
+}
@@ -0,0 +1,273 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rest provides an instrumentation probe for Kubernetes API clients
+// using the [k8s.io/client-go/rest] package.
+package rest
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+const (
+	// mod is the module of the package being instrumented.
+	mod = "k8s.io/client-go"
+	// pkg is the package being instrumented.
+	pkg = mod + "/rest"
+	// apimachineryMod is the module of the watcher of the events of a watch.
+	apimachineryMod = "k8s.io/apimachinery"
+)
+
+const (
+	// verbKey is the attribute key of the Kubernetes API verb of a request
+	// (e.g. "get", "list", or "watch").
+	verbKey = attribute.Key("k8s.verb")
+	// resourceKey is the attribute key of the resource of a request (e.g.
+	// "pods").
+	resourceKey = attribute.Key("k8s.resource")
+	// subresourceKey is the attribute key of the subresource of a request
+	// (e.g. "status").
+	subresourceKey = attribute.Key("k8s.subresource")
+	// namespaceKey is the attribute key of the namespace of a request.
+	namespaceKey = attribute.Key("k8s.namespace")
+	// watchKey is the attribute key of whether a span is the one of an event
+	// received by a watch.
+	watchKey = attribute.Key("k8s.watch")
+	// watchEventTypeKey is the attribute key of the type of an event received
+	// by a watch (e.g. "ADDED").
+	watchEventTypeKey = attribute.Key("k8s.watch.event.type")
+)
+
+var (
+	// minVersion is the first version of client-go supported by the probe.
+	minVersion = semver.New(0, 20, 0, "", "")
+	// minApimachineryVersion is the version of apimachinery released with
+	// minVersion.
+	minApimachineryVersion = semver.New(0, 20, 0, "", "")
+)
+
+// New returns a new [probe.Probe].
+//
+// The span of a request sent with a Request is the parent of the spans of
+// the net/http client sending it to the API server, and retrying it.
+//
+// Watches are long-lived, they are not traced unless watchEvents is true. A
+// span is then produced for each event received by a watch, covering its
+// delivery to the receiver of the watch.
+func New(logger *slog.Logger, version string, watchEvents bool) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	constraint := func(module string, minVer *semver.Version) probe.PackageConstraints {
+		return probe.PackageConstraints{
+			Package: module,
+			Constraints: func() *semver.Constraints {
+				c, err := semver.NewConstraint(">= " + minVer.String())
+				if err != nil {
+					panic(err)
+				}
+				return c
+			}(),
+			FailureMode: probe.FailureModeIgnore,
+		}
+	}
+	supported := constraint(mod, minVersion)
+
+	fieldConst := func(key, strct, field string) probe.Const {
+		return probe.StructFieldConstMinVersion{
+			StructField: probe.StructFieldConst{
+				Key: key,
+				ID:  structfield.NewID(mod, pkg, strct, field),
+			},
+			MinVersion: minVersion,
+		}
+	}
+
+	uprobes := []*probe.Uprobe{
+		{
+			Sym:                pkg + ".(*Request).request",
+			EntryProbe:         "uprobe_Request_request",
+			ReturnProbe:        "uprobe_Request_request_Returns",
+			PackageConstraints: []probe.PackageConstraints{supported},
+			FailureMode:        probe.FailureModeIgnore,
+		},
+	}
+	if watchEvents {
+		const watch = pkg + ".(*Request).Watch"
+		uprobes = append(uprobes,
+			&probe.Uprobe{
+				Sym:         watch,
+				EntryProbe:  "uprobe_Request_Watch",
+				ReturnProbe: "uprobe_Request_Watch_Returns",
+				PackageConstraints: []probe.PackageConstraints{
+					supported,
+					constraint(apimachineryMod, minApimachineryVersion),
+				},
+				FailureMode: probe.FailureModeIgnore,
+			},
+			&probe.Uprobe{
+				Sym:                pkg + "/watch.(*Decoder).Decode",
+				EntryProbe:         "uprobe_Decoder_Decode",
+				ReturnProbe:        "uprobe_Decoder_Decode_Returns",
+				PackageConstraints: []probe.PackageConstraints{supported},
+				FailureMode:        probe.FailureModeIgnore,
+				DependsOn:          []string{watch},
+			},
+		)
+	}
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				fieldConst("request_verb_pos", "Request", "verb"),
+				fieldConst("request_namespace_pos", "Request", "namespace"),
+				fieldConst("request_resource_pos", "Request", "resource"),
+				fieldConst("request_resource_name_pos", "Request", "resourceName"),
+				fieldConst("request_subresource_pos", "Request", "subresource"),
+				probe.StructFieldConstMinVersion{
+					StructField: probe.StructFieldConst{
+						Key: "stream_watcher_source_pos",
+						ID: structfield.NewID(
+							apimachineryMod,
+							apimachineryMod+"/pkg/watch",
+							"StreamWatcher",
+							"source",
+						),
+					},
+					MinVersion: minApimachineryVersion,
+				},
+			},
+			Uprobes: uprobes,
+			SpecFn:  loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a request sent to the Kubernetes API server, or an event
+// received by a watch if IsWatch is set.
+type event struct {
+	context.BaseSpanProperties
+	// Verb is the HTTP method of the request.
+	Verb        [8]byte
+	Namespace   [64]byte
+	Resource    [64]byte
+	Subresource [32]byte
+	EventType   [16]byte
+	HasName     uint8
+	IsWatch     uint8
+	HasError    uint8
+	_           [5]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	verb := apiVerb(unix.ByteSliceToString(e.Verb[:]), e.HasName != 0, e.IsWatch != 0)
+	resource := unix.ByteSliceToString(e.Resource[:])
+	subresource := unix.ByteSliceToString(e.Subresource[:])
+
+	attrs := []attribute.KeyValue{
+		verbKey.String(verb),
+		watchKey.Bool(e.IsWatch != 0),
+	}
+	if resource != "" {
+		attrs = append(attrs, resourceKey.String(resource))
+	}
+	if subresource != "" {
+		attrs = append(attrs, subresourceKey.String(subresource))
+	}
+	if ns := unix.ByteSliceToString(e.Namespace[:]); ns != "" {
+		attrs = append(attrs, namespaceKey.String(ns))
+	}
+	if t := unix.ByteSliceToString(e.EventType[:]); e.IsWatch != 0 && t != "" {
+		attrs = append(attrs, watchEventTypeKey.String(t))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(verb, resource, subresource))
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// apiVerb returns the Kubernetes API verb of a request sent with the HTTP
+// method, for a single named resource if hasName.
+//
+// https://kubernetes.io/docs/reference/access-authn-authz/authorization/#determine-the-request-verb
+func apiVerb(method string, hasName, watch bool) string {
+	if watch {
+		return "watch"
+	}
+	switch method {
+	case "GET", "HEAD":
+		if hasName {
+			return "get"
+		}
+		return "list"
+	case "POST":
+		return "create"
+	case "PUT":
+		return "update"
+	case "PATCH":
+		return "patch"
+	case "DELETE":
+		if hasName {
+			return "delete"
+		}
+		return "deletecollection"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// spanName returns the name of the span of a request with the verb to the
+// resource and subresource.
+func spanName(verb, resource, subresource string) string {
+	if resource == "" {
+		return verb
+	}
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	return verb + " " + resource
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	f.ParentSpanID = trace.SpanID{2}
+
+	type request struct {
+		verb, namespace, resource, subresource, eventType string
+		hasName, isWatch, hasError                        bool
+	}
+	newEvent := func(r request) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.Verb[:], r.verb)
+		copy(e.Namespace[:], r.namespace)
+		copy(e.Resource[:], r.resource)
+		copy(e.Subresource[:], r.subresource)
+		copy(e.EventType[:], r.eventType)
+		if r.hasName {
+			e.HasName = 1
+		}
+		if r.isWatch {
+			e.IsWatch = 1
+		}
+		if r.hasError {
+			e.HasError = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "get",
+			event: newEvent(request{verb: "GET", namespace: "default", resource: "deployments", hasName: true}),
+			want: f.Spans(
+				"get deployments",
+				ptrace.StatusCodeUnset,
+				verbKey.String("get"),
+				watchKey.Bool(false),
+				resourceKey.String("deployments"),
+				namespaceKey.String("default"),
+			),
+		},
+		{
+			name:  "list",
+			event: newEvent(request{verb: "GET", resource: "nodes"}),
+			want: f.Spans(
+				"list nodes",
+				ptrace.StatusCodeUnset,
+				verbKey.String("list"),
+				watchKey.Bool(false),
+				resourceKey.String("nodes"),
+			),
+		},
+		{
+			name:  "subresource",
+			event: newEvent(request{verb: "PATCH", namespace: "default", resource: "pods", subresource: "status", hasName: true}),
+			want: f.Spans(
+				"patch pods/status",
+				ptrace.StatusCodeUnset,
+				verbKey.String("patch"),
+				watchKey.Bool(false),
+				resourceKey.String("pods"),
+				subresourceKey.String("status"),
+				namespaceKey.String("default"),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent(request{verb: "DELETE", namespace: "default", resource: "pods", hasError: true}),
+			want: f.Spans(
+				"deletecollection pods",
+				ptrace.StatusCodeError,
+				verbKey.String("deletecollection"),
+				watchKey.Bool(false),
+				resourceKey.String("pods"),
+				namespaceKey.String("default"),
+			),
+		},
+		{
+			name:  "watch event",
+			event: newEvent(request{verb: "GET", namespace: "default", resource: "pods", eventType: "ADDED", isWatch: true}),
+			want: f.Spans(
+				"watch pods",
+				ptrace.StatusCodeUnset,
+				verbKey.String("watch"),
+				watchKey.Bool(true),
+				resourceKey.String("pods"),
+				namespaceKey.String("default"),
+				watchEventTypeKey.String("ADDED"),
+			),
+		},
+		{
+			name:  "no resource",
+			event: newEvent(request{verb: "GET"}),
+			want: f.Spans(
+				"list",
+				ptrace.StatusCodeUnset,
+				verbKey.String("list"),
+				watchKey.Bool(false),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
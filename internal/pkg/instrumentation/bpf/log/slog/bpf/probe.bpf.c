@@ -0,0 +1,282 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_iface.h"
+#include "go_types.h"
+#include "uprobe.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_MESSAGE_SIZE 256
+#define MAX_ATTRS 8
+#define MAX_ATTR_KEY_SIZE 64
+#define MAX_ATTR_VALUE_SIZE 256
+// nAttrsInline, the number of attributes stored inline in a Record.
+#define RECORD_FRONT_SIZE 5
+
+// The slog.Kind of the attribute values read.
+#define KIND_BOOL 1
+#define KIND_DURATION 2
+#define KIND_FLOAT64 3
+#define KIND_INT64 4
+#define KIND_STRING 5
+#define KIND_UINT64 7
+
+// The layout of a slog.Value: the kind of the value, or the length of a
+// string, is stored in num for the values not boxed in any.
+struct go_slog_value {
+    u64 num;
+    struct go_iface any;
+};
+
+// The layout of a slog.Attr.
+struct go_slog_attr {
+    struct go_string key;
+    struct go_slog_value value;
+};
+
+struct log_attr_t {
+    char key[MAX_ATTR_KEY_SIZE];
+    // The slog.Kind of the value.
+    u8 kind;
+    // Set if the string value is longer than MAX_ATTR_VALUE_SIZE.
+    u8 truncated;
+    u8 padding[6];
+    // The bits of the numeric values.
+    u64 num;
+    // The string values.
+    char value[MAX_ATTR_VALUE_SIZE];
+};
+
+struct log_record_t {
+    u64 time;
+    // The span context active in the context the record is logged with.
+    struct span_context sc;
+    s64 level;
+    char message[MAX_MESSAGE_SIZE];
+    struct log_attr_t attrs[MAX_ATTRS];
+    u8 attrs_len;
+    // Set if the message is longer than MAX_MESSAGE_SIZE.
+    u8 message_truncated;
+    u8 padding[2];
+    // The number of attributes past the first MAX_ATTRS ones.
+    u32 attrs_dropped;
+};
+
+// The state of a record being logged, keyed by the goroutine logging it.
+struct log_state_t {
+    struct go_iface go_context;
+    // The Record the attributes are added to.
+    void *record;
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct log_state_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} slog_states SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct log_record_t));
+    __uint(max_entries, 1);
+} slog_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 record_message_pos;
+volatile const u64 record_level_pos;
+volatile const u64 record_front_pos;
+volatile const u64 record_nfront_pos;
+volatile const u64 record_back_pos;
+
+// read_attr reads the attribute at attr_ptr. Only the string and numeric
+// values are read, the kind of the other values is left unset.
+static __always_inline void read_attr(void *attr_ptr, struct log_attr_t *attr) {
+    struct go_slog_attr go_attr = {0};
+    if (bpf_probe_read_user(&go_attr, sizeof(go_attr), attr_ptr) != 0) {
+        return;
+    }
+
+    u64 size = (u64)go_attr.key.len < MAX_ATTR_KEY_SIZE ? go_attr.key.len : MAX_ATTR_KEY_SIZE;
+    bpf_probe_read_user(attr->key, size, go_attr.key.str);
+
+    u8 kind = go_type_kind(go_attr.value.any.type);
+    if (kind == GO_KIND_PTR) {
+        // A string is stored as a *byte to its data, with its length in num.
+        if (go_type_kind(go_ptr_type_elem(go_attr.value.any.type)) != GO_KIND_UINT8) {
+            return;
+        }
+        attr->kind = KIND_STRING;
+        attr->truncated = go_attr.value.num > MAX_ATTR_VALUE_SIZE;
+        size = go_attr.value.num < MAX_ATTR_VALUE_SIZE ? go_attr.value.num : MAX_ATTR_VALUE_SIZE;
+        bpf_probe_read_user(attr->value, size, go_attr.value.any.data);
+    } else if (kind == GO_KIND_INT) {
+        // The numeric values hold their slog.Kind in any, and their bits in
+        // num.
+        s64 value_kind = 0;
+        bpf_probe_read_user(&value_kind, sizeof(value_kind), go_attr.value.any.data);
+        switch (value_kind) {
+        case KIND_BOOL:
+        case KIND_DURATION:
+        case KIND_FLOAT64:
+        case KIND_INT64:
+        case KIND_UINT64:
+            attr->kind = value_kind;
+            attr->num = go_attr.value.num;
+            break;
+        }
+    }
+}
+
+static __always_inline int log_entry(struct pt_regs *ctx) {
+    struct log_state_t state = {0};
+    get_Go_context(ctx, 2, 0, true, &state.go_context);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&slog_states, &key, &state, 0);
+    return 0;
+}
+
+static __always_inline int log_return(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_delete_elem(&slog_states, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (l *Logger) log(ctx context.Context, level Level, msg string, args ...any)
+SEC("uprobe/Logger_log")
+int uprobe_Logger_log(struct pt_regs *ctx) {
+    return log_entry(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (l *Logger) log(ctx context.Context, level Level, msg string, args ...any)
+SEC("uprobe/Logger_log")
+int uprobe_Logger_log_Returns(struct pt_regs *ctx) {
+    return log_return(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (l *Logger) logAttrs(ctx context.Context, level Level, msg string, attrs ...Attr)
+SEC("uprobe/Logger_logAttrs")
+int uprobe_Logger_logAttrs(struct pt_regs *ctx) {
+    return log_entry(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (l *Logger) logAttrs(ctx context.Context, level Level, msg string, attrs ...Attr)
+SEC("uprobe/Logger_logAttrs")
+int uprobe_Logger_logAttrs_Returns(struct pt_regs *ctx) {
+    return log_return(ctx);
+}
+
+// record_add_entry saves the Record the attributes are added to. The Record is
+// only created, and its attributes added, once the Handler of the Logger is
+// enabled for its level.
+static __always_inline int record_add_entry(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct log_state_t *state = bpf_map_lookup_elem(&slog_states, &key);
+    if (state == NULL) {
+        // Not added by Logger.log or Logger.logAttrs.
+        return 0;
+    }
+    state->record = get_argument(ctx, 1);
+    return 0;
+}
+
+// record_add_return outputs the Record once its attributes are added, before
+// it is passed to the Handler.
+static __always_inline int record_add_return(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct log_state_t *state = bpf_map_lookup_elem(&slog_states, &key);
+    if (state == NULL || state->record == NULL) {
+        return 0;
+    }
+    void *record = state->record;
+
+    u32 zero = 0;
+    struct log_record_t *rec = bpf_map_lookup_elem(&slog_storage_map, &zero);
+    if (rec == NULL) {
+        bpf_printk("uprobe/Record_Add: rec is NULL");
+        return 0;
+    }
+    __builtin_memset(rec, 0, sizeof(struct log_record_t));
+    rec->time = get_time_ns();
+
+    struct span_context *sc = get_parent_span_context(&state->go_context);
+    if (sc != NULL) {
+        rec->sc = *sc;
+    }
+
+    bpf_probe_read_user(&rec->level, sizeof(rec->level), record + record_level_pos);
+
+    struct go_string msg = {0};
+    bpf_probe_read_user(&msg, sizeof(msg), record + record_message_pos);
+    rec->message_truncated = (u64)msg.len > MAX_MESSAGE_SIZE;
+    u64 size = (u64)msg.len < MAX_MESSAGE_SIZE ? msg.len : MAX_MESSAGE_SIZE;
+    bpf_probe_read_user(rec->message, size, msg.str);
+
+    s64 nfront = 0;
+    bpf_probe_read_user(&nfront, sizeof(nfront), record + record_nfront_pos);
+    struct go_slice back = {0};
+    bpf_probe_read_user(&back, sizeof(back), record + record_back_pos);
+
+    s64 total = nfront + back.len;
+    if (total > MAX_ATTRS) {
+        rec->attrs_dropped = total - MAX_ATTRS;
+    }
+    for (u32 i = 0; i < MAX_ATTRS; i++) {
+        if (i >= total) {
+            break;
+        }
+        void *attr_ptr;
+        if (i < nfront && i < RECORD_FRONT_SIZE) {
+            attr_ptr = record + record_front_pos + i * sizeof(struct go_slog_attr);
+        } else {
+            attr_ptr = back.array + (i - nfront) * sizeof(struct go_slog_attr);
+        }
+        read_attr(attr_ptr, &rec->attrs[i]);
+        rec->attrs_len++;
+    }
+
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, rec, sizeof(*rec));
+
+    // Attributes added by the Handler are not part of the record logged.
+    bpf_map_delete_elem(&slog_states, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Record) Add(args ...any)
+SEC("uprobe/Record_Add")
+int uprobe_Record_Add(struct pt_regs *ctx) {
+    return record_add_entry(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Record) Add(args ...any)
+SEC("uprobe/Record_Add")
+int uprobe_Record_Add_Returns(struct pt_regs *ctx) {
+    return record_add_return(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Record) AddAttrs(attrs ...Attr)
+SEC("uprobe/Record_AddAttrs")
+int uprobe_Record_AddAttrs(struct pt_regs *ctx) {
+    return record_add_entry(ctx);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Record) AddAttrs(attrs ...Attr)
+SEC("uprobe/Record_AddAttrs")
+int uprobe_Record_AddAttrs_Returns(struct pt_regs *ctx) {
+    return record_add_return(ctx);
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package slog provides an instrumentation probe producing log records from
+// the records logged with the [log/slog] package.
+package slog
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "log/slog"
+
+// truncatedKey is the attribute key set on the log records with a message or
+// attribute values truncated.
+const truncatedKey = attribute.Key("slog.truncated")
+
+// New returns a new [probe.Probe].
+//
+// A log record is produced for each record logged by a Logger with a Handler
+// enabled for its level, with the trace context of the span active in the
+// context it is logged with, if any. The message and the string attribute
+// values are truncated to 256 bytes. Of the first 8 attributes, the ones with a
+// string, boolean, numeric or duration value are recorded, the others are
+// counted as dropped.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	const (
+		log      = pkg + ".(*Logger).log"
+		logAttrs = pkg + ".(*Logger).logAttrs"
+	)
+
+	return &probe.LogProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "record_message_pos",
+					ID:  structfield.NewID("std", pkg, "Record", "Message"),
+				},
+				probe.StructFieldConst{
+					Key: "record_level_pos",
+					ID:  structfield.NewID("std", pkg, "Record", "Level"),
+				},
+				probe.StructFieldConst{
+					Key: "record_front_pos",
+					ID:  structfield.NewID("std", pkg, "Record", "front"),
+				},
+				probe.StructFieldConst{
+					Key: "record_nfront_pos",
+					ID:  structfield.NewID("std", pkg, "Record", "nFront"),
+				},
+				probe.StructFieldConst{
+					Key: "record_back_pos",
+					ID:  structfield.NewID("std", pkg, "Record", "back"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         log,
+					EntryProbe:  "uprobe_Logger_log",
+					ReturnProbe: "uprobe_Logger_log_Returns",
+				},
+				{
+					Sym:         logAttrs,
+					EntryProbe:  "uprobe_Logger_logAttrs",
+					ReturnProbe: "uprobe_Logger_logAttrs_Returns",
+				},
+				{
+					Sym:         pkg + ".(*Record).Add",
+					EntryProbe:  "uprobe_Record_Add",
+					ReturnProbe: "uprobe_Record_Add_Returns",
+					DependsOn:   []string{log},
+				},
+				{
+					Sym:         pkg + ".(*Record).AddAttrs",
+					EntryProbe:  "uprobe_Record_AddAttrs",
+					ReturnProbe: "uprobe_Record_AddAttrs_Returns",
+					DependsOn:   []string{logAttrs},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+const (
+	// maxAttrs is the maximum number of attributes of a record read.
+	maxAttrs = 8
+	// maxAttrKeySize is the maximum size of the attribute keys read.
+	maxAttrKeySize = 64
+	// maxValueSize is the maximum size of the message and of the string
+	// attribute values read.
+	maxValueSize = 256
+)
+
+// The slog.Kind of the attribute values read.
+const (
+	kindBool     = 1
+	kindDuration = 2
+	kindFloat64  = 3
+	kindInt64    = 4
+	kindString   = 5
+	kindUint64   = 7
+)
+
+// attr is an attribute of a logged record.
+type attr struct {
+	Key [maxAttrKeySize]byte
+	// Kind is the slog.Kind of the value, zero if it was not read.
+	Kind      uint8
+	Truncated uint8
+	_         [6]byte // padding
+	// Num holds the bits of the numeric values.
+	Num uint64
+	// Value holds the string values.
+	Value [maxValueSize]byte
+}
+
+// event represents a record logged by a Logger.
+type event struct {
+	Time        uint64
+	SpanContext context.EBPFSpanContext
+	Level       int64
+	Message     [maxValueSize]byte
+	Attrs       [maxAttrs]attr
+	AttrsLen    uint8
+	// MessageTruncated is set if the message is longer than maxValueSize.
+	MessageTruncated uint8
+	_                [2]byte // padding
+	// AttrsDropped is the number of attributes past the first maxAttrs ones.
+	AttrsDropped uint32
+}
+
+func processFn(e *event) plog.LogRecordSlice {
+	logs := plog.NewLogRecordSlice()
+	lr := logs.AppendEmpty()
+
+	ts := kernel.BootOffsetToTimestamp(e.Time)
+	lr.SetTimestamp(ts)
+	lr.SetObservedTimestamp(ts)
+
+	level := slog.Level(e.Level)
+	lr.SetSeverityNumber(severity(level))
+	lr.SetSeverityText(level.String())
+	lr.Body().SetStr(unix.ByteSliceToString(e.Message[:]))
+
+	if e.SpanContext.SpanID.IsValid() {
+		lr.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+		lr.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+		lr.SetFlags(plog.LogRecordFlags(e.SpanContext.TraceFlags))
+	}
+
+	truncated := e.MessageTruncated != 0
+	dropped := e.AttrsDropped
+	attrs := make([]attribute.KeyValue, 0, e.AttrsLen+1)
+	for _, a := range e.Attrs[:min(int(e.AttrsLen), maxAttrs)] {
+		key := attribute.Key(unix.ByteSliceToString(a.Key[:]))
+		switch a.Kind {
+		case kindBool:
+			attrs = append(attrs, key.Bool(a.Num != 0))
+		case kindDuration:
+			attrs = append(attrs, key.Int64(int64(a.Num))) // nolint: gosec  // Bits of an int64 of nanoseconds.
+		case kindFloat64:
+			attrs = append(attrs, key.Float64(math.Float64frombits(a.Num)))
+		case kindInt64:
+			attrs = append(attrs, key.Int64(int64(a.Num))) // nolint: gosec  // Bits of an int64.
+		case kindUint64:
+			if a.Num > math.MaxInt64 {
+				attrs = append(attrs, key.String(strconv.FormatUint(a.Num, 10)))
+			} else {
+				attrs = append(attrs, key.Int64(int64(a.Num)))
+			}
+		case kindString:
+			attrs = append(attrs, key.String(unix.ByteSliceToString(a.Value[:])))
+			truncated = truncated || a.Truncated != 0
+		default:
+			// The value of other kinds is not read.
+			dropped++
+		}
+	}
+	if truncated {
+		attrs = append(attrs, truncatedKey.Bool(true))
+	}
+	pdataconv.Attributes(lr.Attributes(), attrs...)
+	lr.SetDroppedAttributesCount(dropped)
+
+	return logs
+}
+
+// severity returns the OpenTelemetry severity of level. The slog levels are
+// mapped the same way as by the OpenTelemetry slog bridge: Debug, Info, Warn
+// and Error are mapped to the DEBUG, INFO, WARN and ERROR severity ranges.
+func severity(level slog.Level) plog.SeverityNumber {
+	const offset = int(plog.SeverityNumberInfo) - int(slog.LevelInfo)
+	n := int(level) + offset
+	n = max(n, int(plog.SeverityNumberTrace))
+	n = min(n, int(plog.SeverityNumberFatal4))
+	return plog.SeverityNumber(n) // nolint: gosec  // Bounded.
+}
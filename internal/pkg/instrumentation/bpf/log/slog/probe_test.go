@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package slog
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+)
+
+func TestProcessFn(t *testing.T) {
+	now := time.Unix(0, time.Now().UnixNano()) // No wall clock.
+	offset := kernel.TimeToBootOffset(now)
+
+	traceID := trace.TraceID{1}
+	spanID := trace.SpanID{1}
+
+	newAttr := func(key string, kind uint8, num uint64, value string) attr {
+		a := attr{Kind: kind, Num: num}
+		copy(a.Key[:], key)
+		a.Truncated = boolToUint8(copy(a.Value[:], value) < len(value))
+		return a
+	}
+
+	e := &event{
+		Time: offset,
+		SpanContext: context.EBPFSpanContext{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		},
+		Level:    int64(slog.LevelWarn),
+		AttrsLen: 8,
+		Attrs: [maxAttrs]attr{
+			newAttr("str", kindString, 5, "value"),
+			newAttr("bool", kindBool, 1, ""),
+			newAttr("dur", kindDuration, uint64(time.Second), ""),
+			newAttr("float", kindFloat64, math.Float64bits(1.5), ""),
+			newAttr("int", kindInt64, math.MaxUint64, ""),
+			newAttr("uint", kindUint64, math.MaxUint64, ""),
+			newAttr("long", kindString, 300, strings.Repeat("a", 300)),
+			newAttr("group", 0, 0, ""),
+		},
+		AttrsDropped: 2,
+	}
+	copy(e.Message[:], "hello")
+
+	want := plog.NewLogRecordSlice()
+	lr := want.AppendEmpty()
+	lr.SetTimestamp(kernel.BootOffsetToTimestamp(offset))
+	lr.SetObservedTimestamp(kernel.BootOffsetToTimestamp(offset))
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.SetSeverityText("WARN")
+	lr.Body().SetStr("hello")
+	lr.SetTraceID(pcommon.TraceID(traceID))
+	lr.SetSpanID(pcommon.SpanID(spanID))
+	lr.SetFlags(plog.LogRecordFlags(trace.FlagsSampled))
+	lr.SetDroppedAttributesCount(3)
+	pdataconv.Attributes(
+		lr.Attributes(),
+		attribute.String("str", "value"),
+		attribute.Bool("bool", true),
+		attribute.Int64("dur", int64(time.Second)),
+		attribute.Float64("float", 1.5),
+		attribute.Int64("int", -1),
+		attribute.String("uint", "18446744073709551615"),
+		attribute.String("long", strings.Repeat("a", maxValueSize)),
+		truncatedKey.Bool(true),
+	)
+
+	assert.Equal(t, want, processFn(e))
+}
+
+func TestProcessFnNoSpan(t *testing.T) {
+	e := &event{Level: int64(slog.LevelInfo)}
+	copy(e.Message[:], "hello")
+
+	got := processFn(e)
+	assert.Equal(t, 1, got.Len())
+	lr := got.At(0)
+	assert.Equal(t, "hello", lr.Body().Str())
+	assert.True(t, lr.TraceID().IsEmpty())
+	assert.True(t, lr.SpanID().IsEmpty())
+	assert.Equal(t, 0, lr.Attributes().Len())
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  plog.SeverityNumber
+	}{
+		{slog.LevelDebug - 10, plog.SeverityNumberTrace},
+		{slog.LevelDebug, plog.SeverityNumberDebug},
+		{slog.LevelInfo, plog.SeverityNumberInfo},
+		{slog.LevelInfo + 1, plog.SeverityNumberInfo2},
+		{slog.LevelWarn, plog.SeverityNumberWarn},
+		{slog.LevelError, plog.SeverityNumberError},
+		{slog.LevelError + 100, plog.SeverityNumberFatal4},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, severity(tt.level), tt.level)
+	}
+}
+
+func boolToUint8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_net.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_NETWORK_SIZE 16
+#define MAX_ADDRESS_SIZE 256
+
+struct connect_t {
+    BASE_SPAN_PROPERTIES
+    // The network dialed (e.g. "tcp").
+    char network[MAX_NETWORK_SIZE];
+    // The address dialed (e.g. "example.com:443").
+    char address[MAX_ADDRESS_SIZE];
+    // The remote address of the connection, if it is a TCP or UDP one.
+    net_addr_t peer;
+    u8 has_error;
+    u8 padding[7];
+};
+
+// Dials in progress, keyed by the goroutine dialing.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct connect_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} connects SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct connect_t));
+    __uint(max_entries, 1);
+} connect_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 conn_fd_pos;
+volatile const u64 net_fd_raddr_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (d *Dialer) DialContext(ctx context.Context, network, address string) (Conn, error)
+SEC("uprobe/Dialer_DialContext")
+int uprobe_Dialer_DialContext(struct pt_regs *ctx) {
+    // Only the dials of a traced operation, e.g. of the connection of an HTTP
+    // client request, are traced.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    if (get_parent_span_context(&go_context) == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct connect_t *connect = bpf_map_lookup_elem(&connect_storage_map, &zero);
+    if (connect == NULL) {
+        bpf_printk("uprobe/Dialer_DialContext: connect is NULL");
+        return 0;
+    }
+    __builtin_memset(connect, 0, sizeof(struct connect_t));
+    connect->start_time = get_time_ns();
+
+    void *network_ptr = get_argument(ctx, 4);
+    u64 network_len = (u64)get_argument(ctx, 5);
+    u64 size = MAX_NETWORK_SIZE - 1 < network_len ? MAX_NETWORK_SIZE - 1 : network_len;
+    bpf_probe_read_user(connect->network, size, network_ptr);
+
+    void *address_ptr = get_argument(ctx, 6);
+    u64 address_len = (u64)get_argument(ctx, 7);
+    size = MAX_ADDRESS_SIZE - 1 < address_len ? MAX_ADDRESS_SIZE - 1 : address_len;
+    bpf_probe_read_user(connect->address, size, address_ptr);
+
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &connect->psc,
+        .sc = &connect->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&connects, &key, connect, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (d *Dialer) DialContext(ctx context.Context, network, address string) (Conn, error)
+SEC("uprobe/Dialer_DialContext")
+int uprobe_Dialer_DialContext_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct connect_t *connect = bpf_map_lookup_elem(&connects, &key);
+    if (connect == NULL) {
+        return 0;
+    }
+    connect->end_time = end_time;
+
+    if (get_argument(ctx, 3) != NULL) {
+        connect->has_error = 1;
+    } else if (connect->network[0] == 't' || connect->network[0] == 'u') {
+        // The Conn of the "tcp" and "udp" networks is a *TCPConn, or a
+        // *UDPConn, embedding a conn holding the *netFD of the connection.
+        // The remote address of both is a *TCPAddr, or a *UDPAddr, with the
+        // same layout.
+        void *conn_ptr = get_argument(ctx, 2);
+        void *fd_ptr = NULL;
+        bpf_probe_read_user(&fd_ptr, sizeof(fd_ptr), (void *)(conn_ptr + conn_fd_pos));
+        void *raddr_ptr = NULL;
+        if (fd_ptr != NULL) {
+            bpf_probe_read_user(&raddr_ptr, sizeof(raddr_ptr), get_go_interface_instance(fd_ptr + net_fd_raddr_pos));
+        }
+        if (raddr_ptr != NULL) {
+            get_tcp_net_addr_from_tcp_addr(ctx, &connect->peer, raddr_ptr);
+        }
+    }
+
+    output_span_event(ctx, connect, sizeof(*connect), &connect->sc);
+
+    bpf_map_delete_elem(&connects, &key);
+    return 0;
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dialer provides an instrumentation probe for the connections dialed
+// with a [net.Dialer].
+package dialer
+
+import (
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "net"
+
+// New returns a new [probe.Probe]. It is only loaded if enabled is true.
+//
+// Spans are produced for the connections dialed with [net.Dialer.DialContext],
+// e.g. the ones of the [net/http.Transport], as the children of the span of
+// the context they are dialed with. The connections dialed without a span,
+// e.g. not for an HTTP client request, are not traced. The reused
+// connections are not dialed: most requests have no connect span.
+func New(logger *slog.Logger, version string, enabled bool) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	return &dialerProbe{
+		SpanProducer: &probe.SpanProducer[bpfObjects, event]{
+			Base: probe.Base[bpfObjects, event]{
+				ID:     id,
+				Logger: logger,
+				Consts: []probe.Const{
+					probe.AllocationConst{},
+					probe.BootClockConst{},
+					probe.StructFieldConst{
+						Key: "conn_fd_pos",
+						ID:  structfield.NewID("std", pkg, "conn", "fd"),
+					},
+					probe.StructFieldConst{
+						Key: "net_fd_raddr_pos",
+						ID:  structfield.NewID("std", pkg, "netFD", "raddr"),
+					},
+					probe.StructFieldConst{
+						Key: "TCPAddr_IP_offset",
+						ID:  structfield.NewID("std", pkg, "TCPAddr", "IP"),
+					},
+					probe.StructFieldConst{
+						Key: "TCPAddr_Port_offset",
+						ID:  structfield.NewID("std", pkg, "TCPAddr", "Port"),
+					},
+					probe.StructFieldConst{
+						Key: "TCPAddr_Zone_offset",
+						ID:  structfield.NewID("std", pkg, "TCPAddr", "Zone"),
+					},
+				},
+				Uprobes: []*probe.Uprobe{
+					{
+						Sym:         pkg + ".(*Dialer).DialContext",
+						EntryProbe:  "uprobe_Dialer_DialContext",
+						ReturnProbe: "uprobe_Dialer_DialContext_Returns",
+					},
+				},
+				SpecFn: loadBpf,
+			},
+			Version:   version,
+			SchemaURL: semconv.SchemaURL,
+			ProcessFn: processFn,
+		},
+		enabled: enabled,
+	}
+}
+
+// dialerProbe is the probe of the dials, disabled by default.
+type dialerProbe struct {
+	*probe.SpanProducer[bpfObjects, event]
+
+	enabled bool
+}
+
+var _ probe.Optional = (*dialerProbe)(nil)
+
+// Enabled returns whether the dials are traced.
+func (p *dialerProbe) Enabled() bool { return p.enabled }
+
+// event represents a connection dialed.
+type event struct {
+	context.BaseSpanProperties
+	Network [16]byte
+	Address [256]byte
+	// Peer is the remote address of the connection of the "tcp" and "udp"
+	// networks.
+	Peer     netattr.NetAddr
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	network := unix.ByteSliceToString(e.Network[:])
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Address[:]))
+	if t := transport(network); t != netattr.TransportUnknown {
+		server.Transport = t
+	}
+
+	peer := e.Peer.Addr()
+	if peer.Host != "" {
+		peer.Transport = server.Transport
+	}
+	attrs := netattr.Attributes(server, peer)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("connect")
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// transport returns the transport of the network dialed (e.g. "tcp4").
+func transport(network string) netattr.Transport {
+	switch {
+	case strings.HasPrefix(network, "tcp"):
+		return netattr.TransportTCP
+	case strings.HasPrefix(network, "udp"):
+		return netattr.TransportUDP
+	case strings.HasPrefix(network, "unix"):
+		return netattr.TransportUnix
+	default:
+		return netattr.TransportUnknown
+	}
+}
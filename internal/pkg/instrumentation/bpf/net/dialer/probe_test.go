@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dialer
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(network, address string, peer []byte) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.Network[:], network)
+		copy(e.Address[:], address)
+		if peer != nil {
+			e.Peer.IPLen = uint8(copy(e.Peer.IP[:], peer))
+			e.Peer.Port = 443
+		}
+		return e
+	}
+
+	errEvent := newEvent("tcp", "example.com:443", nil)
+	errEvent.HasError = 1
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "tcp",
+			event: newEvent("tcp", "example.com:443", []byte{192, 0, 2, 1}),
+			want: f.Spans(
+				"connect",
+				ptrace.StatusCodeUnset,
+				semconv.NetworkPeerAddress("192.0.2.1"),
+				semconv.NetworkPeerPort(443),
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(443),
+				semconv.NetworkTransportTCP,
+			),
+		},
+		{
+			name:  "udp",
+			event: newEvent("udp6", "[2001:db8::1]:443", []byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}),
+			want: f.Spans(
+				"connect",
+				ptrace.StatusCodeUnset,
+				semconv.NetworkPeerAddress("2001:db8::1"),
+				semconv.NetworkPeerPort(443),
+				semconv.ServerAddress("2001:db8::1"),
+				semconv.ServerPort(443),
+				semconv.NetworkTransportUDP,
+			),
+		},
+		{
+			name:  "unix",
+			event: newEvent("unix", "/tmp/server.sock", nil),
+			want: f.Spans(
+				"connect",
+				ptrace.StatusCodeUnset,
+				semconv.ServerAddress("/tmp/server.sock"),
+				semconv.NetworkTransportUnix,
+			),
+		},
+		{
+			name:  "error",
+			event: errEvent,
+			want: f.Spans(
+				"connect",
+				ptrace.StatusCodeError,
+				semconv.ServerAddress("example.com"),
+				semconv.ServerPort(443),
+				semconv.NetworkTransportTCP,
+				semconv.ErrorTypeOther,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tests := map[string]netattr.Transport{
+		"tcp":        netattr.TransportTCP,
+		"tcp4":       netattr.TransportTCP,
+		"udp6":       netattr.TransportUDP,
+		"unix":       netattr.TransportUnix,
+		"unixpacket": netattr.TransportUnix,
+		"ip4:icmp":   netattr.TransportUnknown,
+		"":           netattr.TransportUnknown,
+	}
+	for network, want := range tests {
+		assert.Equal(t, want, transport(network), network)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		p, ok := New(slog.Default(), "", enabled).(probe.Optional)
+		if assert.True(t, ok) {
+			assert.Equal(t, enabled, p.Enabled())
+		}
+	}
+}
@@ -5,9 +5,11 @@
 #include "trace/span_context.h"
 #include "go_context.h"
 #include "go_types.h"
+#include "http_client.h"
 #include "uprobe.h"
 #include "trace/span_output.h"
 #include "trace/start_span.h"
+#include "trace/tracestate.h"
 
 char __license[] SEC("license") = "Dual MIT/GPL";
 
@@ -40,6 +42,9 @@ struct http_request_t {
     char raw_fragment[MAX_RAWFRAGMENT_SIZE];
     u8 force_query;
     u8 omit_host;
+    // Set if the path was truncated to MAX_PATH_SIZE bytes.
+    u8 path_truncated;
+    struct tracestate tracestate;
 };
 
 struct {
@@ -114,17 +119,28 @@ int uprobe_Transport_roundTrip(struct pt_regs *ctx) {
     }
 
     __builtin_memset(httpReq, 0, sizeof(struct http_request_t));
-    httpReq->start_time = bpf_ktime_get_ns();
-
-    start_span_params_t start_span_params = {
-        .ctx = ctx,
-        .go_context = &go_context,
-        .psc = &httpReq->psc,
-        .sc = &httpReq->sc,
-        .get_parent_span_context_fn = NULL,
-        .get_parent_span_context_arg = NULL,
-    };
-    start_span(&start_span_params);
+    httpReq->start_time = get_time_ns();
+
+    struct span_context *owner_sc = get_http_client_owner(key);
+    if (owner_sc != NULL) {
+        // The request is sent for the span of another client probe, its span
+        // context is propagated and no span is produced for the request.
+        httpReq->sc = *owner_sc;
+    } else {
+        start_span_params_t start_span_params = {
+            .ctx = ctx,
+            .go_context = &go_context,
+            .psc = &httpReq->psc,
+            .sc = &httpReq->sc,
+            .get_parent_span_context_fn = NULL,
+            .get_parent_span_context_arg = NULL,
+        };
+        start_span(&start_span_params);
+        // Track the span so the ones of the lookups of the host dialed for
+        // the request are its children.
+        start_tracking_span(go_context.data, &httpReq->sc);
+    }
+    copy_remote_tracestate(&httpReq->sc, &httpReq->tracestate);
 
     if (!get_go_string_from_user_ptr((void *)(req_ptr+method_ptr_pos), httpReq->method, sizeof(httpReq->method))) {
         bpf_printk("uprobe_Transport_roundTrip: Failed to get method from request");
@@ -134,9 +150,11 @@ int uprobe_Transport_roundTrip(struct pt_regs *ctx) {
     // get path from Request.URL
     void *url_ptr = 0;
     bpf_probe_read(&url_ptr, sizeof(url_ptr), (void *)(req_ptr+url_ptr_pos));
-    if (!get_go_string_from_user_ptr((void *)(url_ptr+path_ptr_pos), httpReq->path, sizeof(httpReq->path))) {
+    u64 path_len = get_go_string_len_from_user_ptr((void *)(url_ptr+path_ptr_pos), httpReq->path, sizeof(httpReq->path));
+    if (path_len == 0) {
         bpf_printk("uprobe_Transport_roundTrip: Failed to get path from Request.URL");
     }
+    httpReq->path_truncated = path_len > sizeof(httpReq->path);
 
     // get scheme from Request.URL
     if (!get_go_string_from_user_ptr((void *)(url_ptr+scheme_pos), httpReq->scheme, sizeof(httpReq->scheme))) {
@@ -210,7 +228,7 @@ int uprobe_Transport_roundTrip(struct pt_regs *ctx) {
 // func net/http/transport.roundTrip(req *Request) (*Response, error)
 SEC("uprobe/Transport_roundTrip")
 int uprobe_Transport_roundTrip_Returns(struct pt_regs *ctx) {
-    u64 end_time = bpf_ktime_get_ns();
+    u64 end_time = get_time_ns();
     void *key = (void *)GOROUTINE(ctx);
 
     struct http_request_t *http_req_span = bpf_map_lookup_elem(&http_events, &key);
@@ -226,7 +244,10 @@ int uprobe_Transport_roundTrip_Returns(struct pt_regs *ctx) {
 
     http_req_span->end_time = end_time;
 
-    output_span_event(ctx, http_req_span, sizeof(*http_req_span), &http_req_span->sc);
+    if (get_http_client_owner(key) == NULL) {
+        output_span_event(ctx, http_req_span, sizeof(*http_req_span), &http_req_span->sc);
+        stop_tracking_span(&http_req_span->sc, &http_req_span->psc);
+    }
 
     bpf_map_delete_elem(&http_events, &key);
     return 0;
@@ -281,6 +302,26 @@ int uprobe_writeSubset(struct pt_regs *ctx) {
                     goto done;
                 }
                 len += W3C_KEY_LENGTH + 2 + W3C_VAL_LENGTH + 2;
+
+                u64 ts_len = http_req_span->tracestate.len;
+                if (ts_len > 0 && ts_len <= TRACESTATE_MAX_LEN && len < (size - (s64)ts_len - TRACESTATE_KEY_LENGTH - 4)) {
+                    char ts_key[TRACESTATE_KEY_LENGTH + 2] = "Tracestate: ";
+                    if (bpf_probe_write_user(buf_ptr + (len & 0x0ffff), ts_key, sizeof(ts_key))) {
+                        bpf_printk("uprobe_writeSubset: Failed to write tracestate key in buffer");
+                        goto write_len;
+                    }
+                    if (bpf_probe_write_user(buf_ptr + ((len + sizeof(ts_key)) & 0x0ffff), http_req_span->tracestate.value, ts_len)) {
+                        bpf_printk("uprobe_writeSubset: Failed to write tracestate value in buffer");
+                        goto write_len;
+                    }
+                    if (bpf_probe_write_user(buf_ptr + ((len + sizeof(ts_key) + ts_len) & 0x0ffff), end, sizeof(end))) {
+                        bpf_printk("uprobe_writeSubset: Failed to write tracestate end in buffer");
+                        goto write_len;
+                    }
+                    len += sizeof(ts_key) + ts_len + sizeof(end);
+                }
+
+write_len:
                 if (bpf_probe_write_user((void *)(io_writer_ptr + io_writer_n_pos), &len, sizeof(len))) {
                     bpf_printk("uprobe_writeSubset: Failed to change io writer n");
                     goto done;
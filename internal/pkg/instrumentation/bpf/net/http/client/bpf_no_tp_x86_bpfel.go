@@ -14,26 +14,28 @@ import (
 )
 
 type bpf_no_tpHttpRequestT struct {
-	_           structs.HostLayout
-	StartTime   uint64
-	EndTime     uint64
-	Sc          bpf_no_tpSpanContext
-	Psc         bpf_no_tpSpanContext
-	Host        [128]int8
-	Proto       [8]int8
-	StatusCode  uint64
-	Method      [16]int8
-	Path        [128]int8
-	Scheme      [8]int8
-	Opaque      [8]int8
-	RawPath     [8]int8
-	Username    [8]int8
-	RawQuery    [128]int8
-	Fragment    [56]int8
-	RawFragment [56]int8
-	ForceQuery  uint8
-	OmitHost    uint8
-	_           [6]byte
+	_             structs.HostLayout
+	StartTime     uint64
+	EndTime       uint64
+	Sc            bpf_no_tpSpanContext
+	Psc           bpf_no_tpSpanContext
+	Host          [128]int8
+	Proto         [8]int8
+	StatusCode    uint64
+	Method        [16]int8
+	Path          [128]int8
+	Scheme        [8]int8
+	Opaque        [8]int8
+	RawPath       [8]int8
+	Username      [8]int8
+	RawQuery      [128]int8
+	Fragment      [56]int8
+	RawFragment   [56]int8
+	ForceQuery    uint8
+	OmitHost      uint8
+	PathTruncated uint8
+	_             [5]byte
+	Tracestate    bpf_no_tpTracestate
 }
 
 type bpf_no_tpSliceArrayBuff struct {
@@ -49,6 +51,17 @@ type bpf_no_tpSpanContext struct {
 	Padding    [7]uint8
 }
 
+type bpf_no_tpTraceIdKey struct {
+	_       structs.HostLayout
+	TraceID [16]uint8
+}
+
+type bpf_no_tpTracestate struct {
+	_     structs.HostLayout
+	Len   uint64
+	Value [512]int8
+}
+
 // loadBpf_no_tp returns the embedded CollectionSpec for bpf_no_tp.
 func loadBpf_no_tp() (*ebpf.CollectionSpec, error) {
 	reader := bytes.NewReader(_Bpf_no_tpBytes)
@@ -103,12 +116,15 @@ type bpf_no_tpMapSpecs struct {
 	AllocMap                   *ebpf.MapSpec `ebpf:"alloc_map"`
 	Events                     *ebpf.MapSpec `ebpf:"events"`
 	GoContextToSc              *ebpf.MapSpec `ebpf:"go_context_to_sc"`
+	HttpClientOwners           *ebpf.MapSpec `ebpf:"http_client_owners"`
 	HttpClientUprobeStorageMap *ebpf.MapSpec `ebpf:"http_client_uprobe_storage_map"`
 	HttpEvents                 *ebpf.MapSpec `ebpf:"http_events"`
 	HttpHeaders                *ebpf.MapSpec `ebpf:"http_headers"`
 	ProbeActiveSamplerMap      *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
 	SamplersConfigMap          *ebpf.MapSpec `ebpf:"samplers_config_map"`
 	SliceArrayBuffMap          *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
+	TracestateByTraceId        *ebpf.MapSpec `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap       *ebpf.MapSpec `ebpf:"tracestate_storage_map"`
 	TrackedSpansBySc           *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
 }
 
@@ -116,31 +132,32 @@ type bpf_no_tpMapSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpf_no_tpVariableSpecs struct {
-	CtxPtrPos         *ebpf.VariableSpec `ebpf:"ctx_ptr_pos"`
-	EndAddr           *ebpf.VariableSpec `ebpf:"end_addr"`
-	ForceQueryPos     *ebpf.VariableSpec `ebpf:"force_query_pos"`
-	FragmentPos       *ebpf.VariableSpec `ebpf:"fragment_pos"`
-	HeadersPtrPos     *ebpf.VariableSpec `ebpf:"headers_ptr_pos"`
-	Hex               *ebpf.VariableSpec `ebpf:"hex"`
-	IoWriterBufPtrPos *ebpf.VariableSpec `ebpf:"io_writer_buf_ptr_pos"`
-	IoWriterN_pos     *ebpf.VariableSpec `ebpf:"io_writer_n_pos"`
-	MethodPtrPos      *ebpf.VariableSpec `ebpf:"method_ptr_pos"`
-	OmitHostPos       *ebpf.VariableSpec `ebpf:"omit_host_pos"`
-	OpaquePos         *ebpf.VariableSpec `ebpf:"opaque_pos"`
-	PathPtrPos        *ebpf.VariableSpec `ebpf:"path_ptr_pos"`
-	RawFragmentPos    *ebpf.VariableSpec `ebpf:"raw_fragment_pos"`
-	RawPathPos        *ebpf.VariableSpec `ebpf:"raw_path_pos"`
-	RawQueryPos       *ebpf.VariableSpec `ebpf:"raw_query_pos"`
-	RequestHostPos    *ebpf.VariableSpec `ebpf:"request_host_pos"`
-	RequestProtoPos   *ebpf.VariableSpec `ebpf:"request_proto_pos"`
-	SchemePos         *ebpf.VariableSpec `ebpf:"scheme_pos"`
-	StartAddr         *ebpf.VariableSpec `ebpf:"start_addr"`
-	StatusCodePos     *ebpf.VariableSpec `ebpf:"status_code_pos"`
-	TotalCpus         *ebpf.VariableSpec `ebpf:"total_cpus"`
-	UrlHostPos        *ebpf.VariableSpec `ebpf:"url_host_pos"`
-	UrlPtrPos         *ebpf.VariableSpec `ebpf:"url_ptr_pos"`
-	UserPtrPos        *ebpf.VariableSpec `ebpf:"user_ptr_pos"`
-	UsernamePos       *ebpf.VariableSpec `ebpf:"username_pos"`
+	BootClockSupported *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
+	CtxPtrPos          *ebpf.VariableSpec `ebpf:"ctx_ptr_pos"`
+	EndAddr            *ebpf.VariableSpec `ebpf:"end_addr"`
+	ForceQueryPos      *ebpf.VariableSpec `ebpf:"force_query_pos"`
+	FragmentPos        *ebpf.VariableSpec `ebpf:"fragment_pos"`
+	HeadersPtrPos      *ebpf.VariableSpec `ebpf:"headers_ptr_pos"`
+	Hex                *ebpf.VariableSpec `ebpf:"hex"`
+	IoWriterBufPtrPos  *ebpf.VariableSpec `ebpf:"io_writer_buf_ptr_pos"`
+	IoWriterN_pos      *ebpf.VariableSpec `ebpf:"io_writer_n_pos"`
+	MethodPtrPos       *ebpf.VariableSpec `ebpf:"method_ptr_pos"`
+	OmitHostPos        *ebpf.VariableSpec `ebpf:"omit_host_pos"`
+	OpaquePos          *ebpf.VariableSpec `ebpf:"opaque_pos"`
+	PathPtrPos         *ebpf.VariableSpec `ebpf:"path_ptr_pos"`
+	RawFragmentPos     *ebpf.VariableSpec `ebpf:"raw_fragment_pos"`
+	RawPathPos         *ebpf.VariableSpec `ebpf:"raw_path_pos"`
+	RawQueryPos        *ebpf.VariableSpec `ebpf:"raw_query_pos"`
+	RequestHostPos     *ebpf.VariableSpec `ebpf:"request_host_pos"`
+	RequestProtoPos    *ebpf.VariableSpec `ebpf:"request_proto_pos"`
+	SchemePos          *ebpf.VariableSpec `ebpf:"scheme_pos"`
+	StartAddr          *ebpf.VariableSpec `ebpf:"start_addr"`
+	StatusCodePos      *ebpf.VariableSpec `ebpf:"status_code_pos"`
+	TotalCpus          *ebpf.VariableSpec `ebpf:"total_cpus"`
+	UrlHostPos         *ebpf.VariableSpec `ebpf:"url_host_pos"`
+	UrlPtrPos          *ebpf.VariableSpec `ebpf:"url_ptr_pos"`
+	UserPtrPos         *ebpf.VariableSpec `ebpf:"user_ptr_pos"`
+	UsernamePos        *ebpf.VariableSpec `ebpf:"username_pos"`
 }
 
 // bpf_no_tpObjects contains all objects after they have been loaded into the kernel.
@@ -166,12 +183,15 @@ type bpf_no_tpMaps struct {
 	AllocMap                   *ebpf.Map `ebpf:"alloc_map"`
 	Events                     *ebpf.Map `ebpf:"events"`
 	GoContextToSc              *ebpf.Map `ebpf:"go_context_to_sc"`
+	HttpClientOwners           *ebpf.Map `ebpf:"http_client_owners"`
 	HttpClientUprobeStorageMap *ebpf.Map `ebpf:"http_client_uprobe_storage_map"`
 	HttpEvents                 *ebpf.Map `ebpf:"http_events"`
 	HttpHeaders                *ebpf.Map `ebpf:"http_headers"`
 	ProbeActiveSamplerMap      *ebpf.Map `ebpf:"probe_active_sampler_map"`
 	SamplersConfigMap          *ebpf.Map `ebpf:"samplers_config_map"`
 	SliceArrayBuffMap          *ebpf.Map `ebpf:"slice_array_buff_map"`
+	TracestateByTraceId        *ebpf.Map `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap       *ebpf.Map `ebpf:"tracestate_storage_map"`
 	TrackedSpansBySc           *ebpf.Map `ebpf:"tracked_spans_by_sc"`
 }
 
@@ -180,12 +200,15 @@ func (m *bpf_no_tpMaps) Close() error {
 		m.AllocMap,
 		m.Events,
 		m.GoContextToSc,
+		m.HttpClientOwners,
 		m.HttpClientUprobeStorageMap,
 		m.HttpEvents,
 		m.HttpHeaders,
 		m.ProbeActiveSamplerMap,
 		m.SamplersConfigMap,
 		m.SliceArrayBuffMap,
+		m.TracestateByTraceId,
+		m.TracestateStorageMap,
 		m.TrackedSpansBySc,
 	)
 }
@@ -194,31 +217,32 @@ func (m *bpf_no_tpMaps) Close() error {
 //
 // It can be passed to loadBpf_no_tpObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpf_no_tpVariables struct {
-	CtxPtrPos         *ebpf.Variable `ebpf:"ctx_ptr_pos"`
-	EndAddr           *ebpf.Variable `ebpf:"end_addr"`
-	ForceQueryPos     *ebpf.Variable `ebpf:"force_query_pos"`
-	FragmentPos       *ebpf.Variable `ebpf:"fragment_pos"`
-	HeadersPtrPos     *ebpf.Variable `ebpf:"headers_ptr_pos"`
-	Hex               *ebpf.Variable `ebpf:"hex"`
-	IoWriterBufPtrPos *ebpf.Variable `ebpf:"io_writer_buf_ptr_pos"`
-	IoWriterN_pos     *ebpf.Variable `ebpf:"io_writer_n_pos"`
-	MethodPtrPos      *ebpf.Variable `ebpf:"method_ptr_pos"`
-	OmitHostPos       *ebpf.Variable `ebpf:"omit_host_pos"`
-	OpaquePos         *ebpf.Variable `ebpf:"opaque_pos"`
-	PathPtrPos        *ebpf.Variable `ebpf:"path_ptr_pos"`
-	RawFragmentPos    *ebpf.Variable `ebpf:"raw_fragment_pos"`
-	RawPathPos        *ebpf.Variable `ebpf:"raw_path_pos"`
-	RawQueryPos       *ebpf.Variable `ebpf:"raw_query_pos"`
-	RequestHostPos    *ebpf.Variable `ebpf:"request_host_pos"`
-	RequestProtoPos   *ebpf.Variable `ebpf:"request_proto_pos"`
-	SchemePos         *ebpf.Variable `ebpf:"scheme_pos"`
-	StartAddr         *ebpf.Variable `ebpf:"start_addr"`
-	StatusCodePos     *ebpf.Variable `ebpf:"status_code_pos"`
-	TotalCpus         *ebpf.Variable `ebpf:"total_cpus"`
-	UrlHostPos        *ebpf.Variable `ebpf:"url_host_pos"`
-	UrlPtrPos         *ebpf.Variable `ebpf:"url_ptr_pos"`
-	UserPtrPos        *ebpf.Variable `ebpf:"user_ptr_pos"`
-	UsernamePos       *ebpf.Variable `ebpf:"username_pos"`
+	BootClockSupported *ebpf.Variable `ebpf:"boot_clock_supported"`
+	CtxPtrPos          *ebpf.Variable `ebpf:"ctx_ptr_pos"`
+	EndAddr            *ebpf.Variable `ebpf:"end_addr"`
+	ForceQueryPos      *ebpf.Variable `ebpf:"force_query_pos"`
+	FragmentPos        *ebpf.Variable `ebpf:"fragment_pos"`
+	HeadersPtrPos      *ebpf.Variable `ebpf:"headers_ptr_pos"`
+	Hex                *ebpf.Variable `ebpf:"hex"`
+	IoWriterBufPtrPos  *ebpf.Variable `ebpf:"io_writer_buf_ptr_pos"`
+	IoWriterN_pos      *ebpf.Variable `ebpf:"io_writer_n_pos"`
+	MethodPtrPos       *ebpf.Variable `ebpf:"method_ptr_pos"`
+	OmitHostPos        *ebpf.Variable `ebpf:"omit_host_pos"`
+	OpaquePos          *ebpf.Variable `ebpf:"opaque_pos"`
+	PathPtrPos         *ebpf.Variable `ebpf:"path_ptr_pos"`
+	RawFragmentPos     *ebpf.Variable `ebpf:"raw_fragment_pos"`
+	RawPathPos         *ebpf.Variable `ebpf:"raw_path_pos"`
+	RawQueryPos        *ebpf.Variable `ebpf:"raw_query_pos"`
+	RequestHostPos     *ebpf.Variable `ebpf:"request_host_pos"`
+	RequestProtoPos    *ebpf.Variable `ebpf:"request_proto_pos"`
+	SchemePos          *ebpf.Variable `ebpf:"scheme_pos"`
+	StartAddr          *ebpf.Variable `ebpf:"start_addr"`
+	StatusCodePos      *ebpf.Variable `ebpf:"status_code_pos"`
+	TotalCpus          *ebpf.Variable `ebpf:"total_cpus"`
+	UrlHostPos         *ebpf.Variable `ebpf:"url_host_pos"`
+	UrlPtrPos          *ebpf.Variable `ebpf:"url_ptr_pos"`
+	UserPtrPos         *ebpf.Variable `ebpf:"user_ptr_pos"`
+	UsernamePos        *ebpf.Variable `ebpf:"username_pos"`
 }
 
 // bpf_no_tpPrograms contains all programs after they have been loaded into the kernel.
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/cilium/ebpf"
@@ -19,9 +20,10 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sys/unix"
 
-	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/errmap"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
 	"go.opentelemetry.io/auto/internal/pkg/structfield"
@@ -33,8 +35,22 @@ import (
 const (
 	// pkg is the package being instrumented.
 	pkg = "net/http"
+
+	// ErrorStatusCodesEnvVar is the environment variable used to configure
+	// which response status codes are recorded as errors. See [errmap] for
+	// the supported syntax.
+	ErrorStatusCodesEnvVar = "OTEL_GO_AUTO_HTTP_CLIENT_ERROR_STATUS_CODES"
+
+	// pathTruncatedKey is the attribute key set to true if the path was
+	// truncated to the 128 bytes of the event.
+	pathTruncatedKey = attribute.Key("url.path.truncated")
 )
 
+// defaultErrorStatusCodes are the response status codes recorded as errors by
+// default. See
+// https://opentelemetry.io/docs/specs/semconv/http/http-spans/#status
+var defaultErrorStatusCodes = errmap.Range(400, 599)
+
 // New returns a new [probe.Probe].
 func New(logger *slog.Logger, version string) probe.Probe {
 	id := probe.ID{
@@ -65,12 +81,18 @@ func New(logger *slog.Logger, version string) probe.Probe {
 		)
 	}
 
+	errorStatusCodes, err := errmap.Parse(os.Getenv(ErrorStatusCodesEnvVar), defaultErrorStatusCodes)
+	if err != nil {
+		logger.Error("invalid error status codes, using default", "error", err, "default", errorStatusCodes)
+	}
+	p := &processor{errorStatusCodes: errorStatusCodes}
 	return &probe.SpanProducer[bpfObjects, event]{
 		Base: probe.Base[bpfObjects, event]{
 			ID:     id,
 			Logger: logger,
 			Consts: []probe.Const{
 				probe.AllocationConst{},
+				probe.BootClockConst{},
 				probe.StructFieldConst{
 					Key: "method_ptr_pos",
 					ID:  structfield.NewID("std", "net/http", "Request", "Method"),
@@ -161,7 +183,7 @@ func New(logger *slog.Logger, version string) probe.Probe {
 		},
 		Version:   version,
 		SchemaURL: semconv.SchemaURL,
-		ProcessFn: processFn,
+		ProcessFn: p.processFn,
 	}
 }
 
@@ -193,9 +215,17 @@ type event struct {
 	RawFragment [56]byte
 	ForceQuery  uint8
 	OmitHost    uint8
+	// PathTruncated is not zero if Path was truncated to its 128 bytes.
+	PathTruncated uint8
+	_             [5]byte // padding
+	TraceState    context.TraceState
 }
 
-func processFn(e *event) ptrace.SpanSlice {
+type processor struct {
+	errorStatusCodes errmap.Set
+}
+
+func (p *processor) processFn(e *event) ptrace.SpanSlice {
 	method := unix.ByteSliceToString(e.Method[:])
 	path := unix.ByteSliceToString(e.Path[:])
 	scheme := unix.ByteSliceToString(e.Scheme[:])
@@ -231,6 +261,9 @@ func processFn(e *event) ptrace.SpanSlice {
 	if path != "" {
 		attrs = append(attrs, semconv.URLPath(path))
 	}
+	if e.PathTruncated != 0 {
+		attrs = append(attrs, pathTruncatedKey.Bool(true))
+	}
 
 	urlObj := &url.URL{
 		Path:        path,
@@ -249,14 +282,10 @@ func processFn(e *event) ptrace.SpanSlice {
 	fullURL := urlObj.String()
 	attrs = append(attrs, semconv.URLFull(fullURL))
 
-	// Server address and port
-	serverAddr, serverPort := http.ServerAddressPortAttributes(e.Host[:])
-	if serverAddr.Valid() {
-		attrs = append(attrs, serverAddr)
-	}
-	if serverPort.Valid() {
-		attrs = append(attrs, serverPort)
-	}
+	attrs = append(attrs, netattr.Attributes(
+		netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:])),
+		netattr.Addr{},
+	)...)
 
 	proto := unix.ByteSliceToString(e.Proto[:])
 	if proto != "" {
@@ -283,12 +312,14 @@ func processFn(e *event) ptrace.SpanSlice {
 	if e.ParentSpanContext.SpanID.IsValid() {
 		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
 	}
+	span.TraceState().FromRaw(e.TraceState.String())
 
-	pdataconv.Attributes(span.Attributes(), attrs...)
-
-	if e.StatusCode >= 400 && e.StatusCode < 600 {
+	if e.StatusCode != 0 && p.errorStatusCodes.Contains(int64(e.StatusCode)) { // nolint: gosec  // Bound checked.
+		attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.FormatUint(e.StatusCode, 10)))
 		span.Status().SetCode(ptrace.StatusCodeError)
 	}
 
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
 	return spans
 }
@@ -4,16 +4,20 @@
 package client
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/errmap"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
 )
@@ -109,6 +113,7 @@ func TestConvertEvent(t *testing.T) {
 					semconv.URLPath(pathString),
 					semconv.URLFull("http://google.com/home"),
 					semconv.ServerAddress(hostString),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
 				)
 
@@ -150,7 +155,9 @@ func TestConvertEvent(t *testing.T) {
 					semconv.URLPath(pathString),
 					semconv.URLFull("http://google.com/home"),
 					semconv.ServerAddress(hostString),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
+					semconv.ErrorTypeKey.String("400"),
 				)
 
 				return spans
@@ -191,7 +198,9 @@ func TestConvertEvent(t *testing.T) {
 					semconv.URLPath(pathString),
 					semconv.URLFull("http://google.com/home"),
 					semconv.ServerAddress(hostString),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
+					semconv.ErrorTypeKey.String("500"),
 				)
 
 				return spans
@@ -231,6 +240,7 @@ func TestConvertEvent(t *testing.T) {
 					semconv.URLPath(pathString),
 					semconv.URLFull("foo://google.com/home"),
 					semconv.ServerAddress(hostString),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolName("foo"),
 					semconv.NetworkProtocolVersion("2.2"),
 				)
@@ -275,6 +285,7 @@ func TestConvertEvent(t *testing.T) {
 					semconv.URLPath(pathString),
 					semconv.URLFull("http://user@google.com/home?query=true#fragment"),
 					semconv.ServerAddress(hostString),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
 				)
 
@@ -328,8 +339,93 @@ func TestConvertEvent(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			out := processFn(tt.event)
+			out := (&processor{errorStatusCodes: defaultErrorStatusCodes}).processFn(tt.event)
 			assert.Equal(t, tt.expected, out)
 		})
 	}
 }
+
+func TestConvertEventTraceState(t *testing.T) {
+	e := &event{
+		BaseSpanProperties: context.BaseSpanProperties{
+			SpanContext: context.EBPFSpanContext{
+				TraceID: trace.TraceID{1},
+				SpanID:  trace.SpanID{1},
+			},
+		},
+		// "GET"
+		Method: [16]byte{0x47, 0x45, 0x54},
+	}
+
+	p := &processor{errorStatusCodes: defaultErrorStatusCodes}
+	spans := p.processFn(e)
+	assert.Empty(t, spans.At(0).TraceState().AsRaw(), "no remote tracestate")
+
+	e.TraceState.Len = uint64(copy(e.TraceState.Value[:], "vendor=value"))
+	spans = p.processFn(e)
+	assert.Equal(t, "vendor=value", spans.At(0).TraceState().AsRaw())
+}
+
+func TestConvertEventPathTruncated(t *testing.T) {
+	e := &event{
+		BaseSpanProperties: context.BaseSpanProperties{
+			SpanContext: context.EBPFSpanContext{
+				TraceID: trace.TraceID{1},
+				SpanID:  trace.SpanID{1},
+			},
+		},
+		// "GET"
+		Method: [16]byte{0x47, 0x45, 0x54},
+	}
+	path := "/" + strings.Repeat("a", len(e.Path)-1)
+	copy(e.Path[:], path)
+
+	p := &processor{errorStatusCodes: defaultErrorStatusCodes}
+	attrs := p.processFn(e).At(0).Attributes().AsRaw()
+	assert.NotContains(t, attrs, string(pathTruncatedKey))
+
+	e.PathTruncated = 1
+	attrs = p.processFn(e).At(0).Attributes().AsRaw()
+	assert.Equal(t, path, attrs[string(semconv.URLPathKey)])
+	assert.Equal(t, true, attrs[string(pathTruncatedKey)])
+}
+
+func TestConvertEventErrorStatusCodes(t *testing.T) {
+	codes, err := errmap.Parse("!404", defaultErrorStatusCodes)
+	require.NoError(t, err)
+	p := &processor{errorStatusCodes: codes}
+
+	e := &event{
+		BaseSpanProperties: context.BaseSpanProperties{
+			SpanContext: context.EBPFSpanContext{
+				TraceID: trace.TraceID{1},
+				SpanID:  trace.SpanID{1},
+			},
+		},
+		// "GET"
+		Method: [16]byte{0x47, 0x45, 0x54},
+	}
+
+	tests := []struct {
+		code    uint64
+		wantErr bool
+	}{
+		{code: 0},
+		{code: 200},
+		{code: 404},
+		{code: 429, wantErr: true},
+		{code: 503, wantErr: true},
+	}
+	for _, test := range tests {
+		e.StatusCode = test.code
+		span := p.processFn(e).At(0)
+		errType, ok := span.Attributes().Get(string(semconv.ErrorTypeKey))
+		if !test.wantErr {
+			assert.Equal(t, ptrace.StatusCodeUnset, span.Status().Code(), "status %d", test.code)
+			assert.False(t, ok, "error.type set for %d", test.code)
+			continue
+		}
+		assert.Equal(t, ptrace.StatusCodeError, span.Status().Code(), "status %d", test.code)
+		assert.Equal(t, strconv.FormatUint(test.code, 10), errType.Str())
+	}
+}
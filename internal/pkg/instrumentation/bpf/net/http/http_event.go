@@ -10,53 +10,9 @@ package http
 
 import (
 	"errors"
-	"net"
-	"strconv"
 	"strings"
-
-	"go.opentelemetry.io/otel/attribute"
-	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
-	"golang.org/x/sys/unix"
 )
 
-func ServerAddressPortAttributes(host []byte) (addr attribute.KeyValue, port attribute.KeyValue) {
-	var portString string
-	var e error
-	hostString := unix.ByteSliceToString(host)
-
-	if strings.Contains(hostString, ":") {
-		if hostString, portString, e = net.SplitHostPort(hostString); e == nil {
-			if portI, err := strconv.Atoi(portString); err == nil {
-				port = semconv.ServerPort(portI)
-			}
-		}
-	}
-
-	if hostString != "" {
-		addr = semconv.ServerAddress(hostString)
-	}
-	return
-}
-
-func NetPeerAddressPortAttributes(host []byte) (addr attribute.KeyValue, port attribute.KeyValue) {
-	var portString string
-	var e error
-	hostString := unix.ByteSliceToString(host)
-
-	if strings.Contains(hostString, ":") {
-		if hostString, portString, e = net.SplitHostPort(hostString); e == nil {
-			if portI, err := strconv.Atoi(portString); err == nil {
-				port = semconv.NetworkPeerPort(portI)
-			}
-		}
-	}
-
-	if hostString != "" {
-		addr = semconv.NetworkPeerAddress(hostString)
-	}
-	return
-}
-
 var (
 	// ErrEmptyPattern is returned when the input pattern is empty.
 	ErrEmptyPattern = errors.New("empty pattern")
@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_SCHEME_SIZE 8
+#define MAX_HOSTNAME_SIZE 128
+#define MAX_PATH_SIZE 128
+#define MAX_RAWQUERY_SIZE 128
+
+struct reverse_proxy_t {
+    BASE_SPAN_PROPERTIES
+    // The URL of the outbound request, once rewritten.
+    char scheme[MAX_SCHEME_SIZE];
+    char host[MAX_HOSTNAME_SIZE];
+    char path[MAX_PATH_SIZE];
+    char raw_query[MAX_RAWQUERY_SIZE];
+    u8 has_response;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// Requests being proxied, keyed by the goroutine serving them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct reverse_proxy_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} reverse_proxy_events SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct reverse_proxy_t));
+    __uint(max_entries, 1);
+} reverse_proxy_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 ctx_ptr_pos;
+volatile const u64 url_ptr_pos;
+volatile const u64 scheme_pos;
+volatile const u64 url_host_pos;
+volatile const u64 path_ptr_pos;
+volatile const u64 raw_query_pos;
+
+static __always_inline void read_url(struct reverse_proxy_t *proxy, void *req_ptr) {
+    void *url_ptr = NULL;
+    bpf_probe_read_user(&url_ptr, sizeof(url_ptr), (void *)(req_ptr + url_ptr_pos));
+    if (url_ptr == NULL) {
+        return;
+    }
+    // The URL of an inbound request has no host, unless the request is sent
+    // to a forward proxy.
+    if (!get_go_string_from_user_ptr((void *)(url_ptr + url_host_pos), proxy->host, sizeof(proxy->host))) {
+        return;
+    }
+    get_go_string_from_user_ptr((void *)(url_ptr + scheme_pos), proxy->scheme, sizeof(proxy->scheme));
+    get_go_string_from_user_ptr((void *)(url_ptr + path_ptr_pos), proxy->path, sizeof(proxy->path));
+    get_go_string_from_user_ptr((void *)(url_ptr + raw_query_pos), proxy->raw_query, sizeof(proxy->raw_query));
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request)
+SEC("uprobe/ReverseProxy_ServeHTTP")
+int uprobe_ReverseProxy_ServeHTTP(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&reverse_proxy_events, &key) != NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct reverse_proxy_t *proxy = bpf_map_lookup_elem(&reverse_proxy_storage_map, &zero);
+    if (proxy == NULL) {
+        bpf_printk("uprobe/ReverseProxy_ServeHTTP: proxy is NULL");
+        return 0;
+    }
+    __builtin_memset(proxy, 0, sizeof(struct reverse_proxy_t));
+    proxy->start_time = get_time_ns();
+
+    // The span of the HTTP server serving the request, if any, is tracked
+    // with the context of the request. The outbound request is a clone of the
+    // request with a context derived from it, the span of the HTTP client
+    // sending it is a child of the span tracked here.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 4, ctx_ptr_pos, false, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &proxy->psc,
+        .sc = &proxy->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&reverse_proxy_events, &key, proxy, 0);
+    start_tracking_span(go_context.data, &proxy->sc);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request)
+SEC("uprobe/ReverseProxy_ServeHTTP")
+int uprobe_ReverseProxy_ServeHTTP_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct reverse_proxy_t *proxy = bpf_map_lookup_elem(&reverse_proxy_events, &key);
+    if (proxy == NULL) {
+        return 0;
+    }
+    proxy->end_time = end_time;
+
+    // The ReverseProxy only returns before the response of the upstream is
+    // modified after calling its ErrorHandler.
+    if (!proxy->has_response) {
+        proxy->has_error = 1;
+    }
+
+    output_span_event(ctx, proxy, sizeof(*proxy), &proxy->sc);
+
+    stop_tracking_span(&proxy->sc, &proxy->psc);
+    bpf_map_delete_elem(&reverse_proxy_events, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *ReverseProxy) modifyResponse(rw http.ResponseWriter, res *http.Response, req *http.Request) bool
+SEC("uprobe/ReverseProxy_modifyResponse")
+int uprobe_ReverseProxy_modifyResponse(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct reverse_proxy_t *proxy = bpf_map_lookup_elem(&reverse_proxy_events, &key);
+    if (proxy == NULL) {
+        return 0;
+    }
+    proxy->has_response = 1;
+    read_url(proxy, get_argument(ctx, 5));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *ReverseProxy) modifyResponse(rw http.ResponseWriter, res *http.Response, req *http.Request) bool
+SEC("uprobe/ReverseProxy_modifyResponse")
+int uprobe_ReverseProxy_modifyResponse_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct reverse_proxy_t *proxy = bpf_map_lookup_elem(&reverse_proxy_events, &key);
+    if (proxy == NULL) {
+        return 0;
+    }
+    // The ErrorHandler is called if ModifyResponse returns an error.
+    u64 proceed = (u64)get_argument(ctx, 1) & 0xff;
+    if (!proceed) {
+        proxy->has_error = 1;
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (p *ReverseProxy) defaultErrorHandler(rw http.ResponseWriter, req *http.Request, err error)
+//
+// It is the ErrorHandler of the ReverseProxy if none is set. It is also called
+// for the errors of protocol switches, which happen after the response is
+// modified.
+SEC("uprobe/ReverseProxy_defaultErrorHandler")
+int uprobe_ReverseProxy_defaultErrorHandler(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct reverse_proxy_t *proxy = bpf_map_lookup_elem(&reverse_proxy_events, &key);
+    if (proxy == NULL) {
+        return 0;
+    }
+    proxy->has_error = 1;
+    // The request is the outbound one if the round trip to the upstream
+    // failed, its URL is not known otherwise.
+    if (proxy->host[0] == 0) {
+        read_url(proxy, get_argument(ctx, 4));
+    }
+    return 0;
+}
@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httputil provides an instrumentation probe for reverse proxies using
+// the [net/http/httputil] package.
+package httputil
+
+import (
+	"log/slog"
+	"net/url"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "net/http/httputil"
+
+// New returns a new [probe.Probe].
+//
+// The span of a request proxied by a ReverseProxy is a child of the span of
+// the net/http server serving it, and the parent of the span of the net/http
+// client sending it upstream.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	const serveHTTP = pkg + ".(*ReverseProxy).ServeHTTP"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "ctx_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "ctx"),
+				},
+				probe.StructFieldConst{
+					Key: "url_ptr_pos",
+					ID:  structfield.NewID("std", "net/http", "Request", "URL"),
+				},
+				probe.StructFieldConst{
+					Key: "scheme_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Scheme"),
+				},
+				probe.StructFieldConst{
+					Key: "url_host_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Host"),
+				},
+				probe.StructFieldConst{
+					Key: "path_ptr_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "Path"),
+				},
+				probe.StructFieldConst{
+					Key: "raw_query_pos",
+					ID:  structfield.NewID("std", "net/url", "URL", "RawQuery"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         serveHTTP,
+					EntryProbe:  "uprobe_ReverseProxy_ServeHTTP",
+					ReturnProbe: "uprobe_ReverseProxy_ServeHTTP_Returns",
+				},
+				{
+					Sym:         pkg + ".(*ReverseProxy).modifyResponse",
+					EntryProbe:  "uprobe_ReverseProxy_modifyResponse",
+					ReturnProbe: "uprobe_ReverseProxy_modifyResponse_Returns",
+					DependsOn:   []string{serveHTTP},
+				},
+				{
+					// Not linked if every ReverseProxy has an ErrorHandler.
+					Sym:         pkg + ".(*ReverseProxy).defaultErrorHandler",
+					EntryProbe:  "uprobe_ReverseProxy_defaultErrorHandler",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{serveHTTP},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a request proxied by a ReverseProxy.
+type event struct {
+	context.BaseSpanProperties
+	// Scheme, Host, Path, and RawQuery are the ones of the URL of the
+	// outbound request. They are empty if the request is not sent upstream.
+	Scheme      [8]byte
+	Host        [128]byte
+	Path        [128]byte
+	RawQuery    [128]byte
+	HasResponse uint8
+	HasError    uint8
+	_           [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	var attrs []attribute.KeyValue
+
+	host := unix.ByteSliceToString(e.Host[:])
+	addr := netattr.ParseHostPort(host)
+	if host != "" {
+		u := &url.URL{
+			Scheme:   unix.ByteSliceToString(e.Scheme[:]),
+			Host:     host,
+			Path:     unix.ByteSliceToString(e.Path[:]),
+			RawQuery: unix.ByteSliceToString(e.RawQuery[:]),
+		}
+		attrs = append(attrs, semconv.URLFull(u.String()))
+		if addr.Host != "" {
+			attrs = append(attrs, semconv.ServerAddress(addr.Host))
+			if addr.Port > 0 {
+				attrs = append(attrs, semconv.ServerPort(addr.Port))
+			}
+		}
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(spanName(addr.Host))
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
+
+// spanName returns the name of the span of a request proxied to the upstream
+// host, e.g. "proxy backend.internal".
+func spanName(host string) string {
+	if host == "" {
+		return "proxy"
+	}
+	return "proxy " + host
+}
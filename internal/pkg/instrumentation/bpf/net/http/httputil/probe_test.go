@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindInternal)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(scheme, host, path, rawQuery string, hasError bool) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.Scheme[:], scheme)
+		copy(e.Host[:], host)
+		copy(e.Path[:], path)
+		copy(e.RawQuery[:], rawQuery)
+		if hasError {
+			e.HasError = 1
+		} else {
+			e.HasResponse = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "proxied",
+			event: newEvent("http", "backend:8080", "/users/1", "fields=name", false),
+			want: f.Spans(
+				"proxy backend",
+				ptrace.StatusCodeUnset,
+				semconv.URLFull("http://backend:8080/users/1?fields=name"),
+				semconv.ServerAddress("backend"),
+				semconv.ServerPort(8080),
+			),
+		},
+		{
+			name:  "upstream error",
+			event: newEvent("https", "backend", "/", "", true),
+			want: f.Spans(
+				"proxy backend",
+				ptrace.StatusCodeError,
+				semconv.URLFull("https://backend/"),
+				semconv.ServerAddress("backend"),
+			),
+		},
+		{
+			name:  "not sent",
+			event: newEvent("", "", "", "", true),
+			want:  f.Spans("proxy", ptrace.StatusCodeError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
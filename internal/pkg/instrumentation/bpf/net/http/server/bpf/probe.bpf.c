@@ -2,12 +2,15 @@
 // SPDX-License-Identifier: Apache-2.0
 
 #include "arguments.h"
+#include "enduser.h"
 #include "trace/span_context.h"
 #include "go_context.h"
 #include "go_types.h"
+#include "goroutine_spans.h"
 #include "uprobe.h"
 #include "trace/span_output.h"
 #include "trace/start_span.h"
+#include "trace/tracestate.h"
 
 char __license[] SEC("license") = "Dual MIT/GPL";
 
@@ -18,6 +21,9 @@ char __license[] SEC("license") = "Dual MIT/GPL";
 #define REMOTE_ADDR_MAX_LEN 256
 #define HOST_MAX_LEN 256
 #define PROTO_MAX_LEN 8
+#define MAX_HEADER_NAME_LEN ENDUSER_HEADER_MAX_LEN
+#define MAX_CHI_ROUTE_PATTERNS 8
+#define TWIRP_ERROR_CODE_MAX_LEN 32
 
 struct http_server_span_t
 {
@@ -26,9 +32,25 @@ struct http_server_span_t
     char method[METHOD_MAX_LEN];
     char path[PATH_MAX_LEN];
     char path_pattern[PATH_MAX_LEN];
+    // The route template of the router handler matching the request, if any.
+    char route[PATH_MAX_LEN];
+    // The path template and the full gRPC method (e.g. "/pkg.Service/Method")
+    // of the grpc-gateway handler proxying the request, if any.
+    char grpc_gateway_route[PATH_MAX_LEN];
+    char grpc_gateway_method[PATH_MAX_LEN];
     char remote_addr[REMOTE_ADDR_MAX_LEN];
     char host[HOST_MAX_LEN];
     char proto[PROTO_MAX_LEN];
+    // The code of the error returned by the Twirp service handling the
+    // request, if twirp is set.
+    char twirp_error_code[TWIRP_ERROR_CODE_MAX_LEN];
+    u8 twirp;
+    // Set if the path was truncated to PATH_MAX_LEN bytes.
+    u8 path_truncated;
+    u8 padding[6];
+    struct tracestate tracestate;
+    // Must be the last field, it is only output if enabled.
+    struct enduser enduser;
 };
 
 struct uprobe_data_t
@@ -38,6 +60,18 @@ struct uprobe_data_t
     // saving the response pointer in the entry probe
     // and using it in the return probe
     u64 resp_ptr;
+    // The context of the request being routed by a router (gin.Context,
+    // echo.context, mux.RouteMatch or chi.Context), saved in the entry probe
+    // of the router and read in its return probe once the route is known.
+    u64 router_ctx_ptr;
+    // The last Twirp error code mapped to an HTTP status, only the one of the
+    // error written in the response is kept in the span.
+    char twirp_error_code[TWIRP_ERROR_CODE_MAX_LEN];
+    // Set if the request is served by a gRPC server, its span is then not
+    // output. The parent of the span is tracked in the context of the request
+    // instead if grpc_parent_tracked is set.
+    u8 grpc;
+    u8 grpc_parent_tracked;
 };
 
 MAP_BUCKET_DEFINITION(go_string_t, go_slice_t)
@@ -58,6 +92,22 @@ struct
     __uint(max_entries, MAX_CONCURRENT);
 } http_server_context_headers SEC(".maps");
 
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct tracestate);
+    __uint(max_entries, MAX_CONCURRENT);
+} http_server_tracestate_headers SEC(".maps");
+
+struct
+{
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct enduser);
+    __uint(max_entries, MAX_CONCURRENT);
+} http_server_enduser_headers SEC(".maps");
+
 struct
 {
     __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
@@ -97,12 +147,27 @@ volatile const u64 req_pat_pos;
 volatile const u64 pat_str_pos;
 // A flag indicating whether the Go version is using swiss maps
 volatile const bool swiss_maps_used;
-
-// Extracts the span context from the request headers by looking for the 'traceparent' header.
-// Fills the parent_span_context with the extracted span context.
-// Returns 0 on success, negative value on error.
-static __always_inline long extract_context_from_req_headers_go_map(void *headers_ptr_ptr, struct span_context *parent_span_context)
+// In case github.com/gin-gonic/gin is used the following offset will be used:
+volatile const u64 gin_context_full_path_pos;
+volatile const u64 echo_context_path_pos;
+volatile const u64 mux_route_match_route_pos;
+volatile const u64 mux_route_match_match_err_pos;
+volatile const u64 mux_route_route_conf_pos;
+volatile const u64 mux_route_conf_regexp_pos;
+volatile const u64 mux_route_regexp_group_path_pos;
+volatile const u64 mux_route_regexp_template_pos;
+volatile const u64 chi_context_route_patterns_pos;
+
+// Finds the first value of the header with the name_len long name in the Go
+// map of request headers. The name is compared case-insensitively and must be
+// lowercase. Fills value with the found value.
+// Returns 0 on success, negative value on error or if the header is not found.
+static __always_inline long get_req_header_go_map(void *headers_ptr_ptr, const char *name, u64 name_len, struct go_string *value)
 {
+    if (name_len > MAX_HEADER_NAME_LEN)
+    {
+        return -1;
+    }
     void *headers_ptr;
     long res;
     res = bpf_probe_read(&headers_ptr, sizeof(headers_ptr), headers_ptr_ptr);
@@ -157,40 +222,80 @@ static __always_inline long extract_context_from_req_headers_go_map(void *header
             {
                 continue;
             }
-            if (map_value->keys[i].len != W3C_KEY_LENGTH)
+            if (map_value->keys[i].len != name_len)
             {
                 continue;
             }
-            char current_header_key[W3C_KEY_LENGTH];
-            bpf_probe_read(current_header_key, sizeof(current_header_key), map_value->keys[i].str);
-            if (!bpf_memcmp(current_header_key, "traceparent", W3C_KEY_LENGTH) && !bpf_memcmp(current_header_key, "Traceparent", W3C_KEY_LENGTH))
+            char current_header_key[MAX_HEADER_NAME_LEN];
+            bpf_probe_read(current_header_key, name_len, map_value->keys[i].str);
+            if (bpf_memicmp(current_header_key, name, name_len))
             {
                 continue;
             }
-            void *traceparent_header_value_ptr = map_value->values[i].array;
-            struct go_string traceparent_header_value_go_str;
-            res = bpf_probe_read(&traceparent_header_value_go_str, sizeof(traceparent_header_value_go_str), traceparent_header_value_ptr);
+            void *header_value_ptr = map_value->values[i].array;
+            res = bpf_probe_read(value, sizeof(*value), header_value_ptr);
             if (res < 0)
             {
                 return -1;
             }
-            if (traceparent_header_value_go_str.len != W3C_VAL_LENGTH)
-            {
-                continue;
-            }
-            char traceparent_header_value[W3C_VAL_LENGTH];
-            res = bpf_probe_read(&traceparent_header_value, sizeof(traceparent_header_value), traceparent_header_value_go_str.str);
-            if (res < 0)
-            {
-                return res;
-            }
-            w3c_string_to_span_context(traceparent_header_value, parent_span_context);
             return 0;
         }
     }
     return -1;
 }
 
+// Extracts the span context from the request headers by looking for the 'traceparent' header.
+// Fills the parent_span_context with the extracted span context.
+// Returns 0 on success, negative value on error.
+static __always_inline long extract_context_from_req_headers_go_map(void *headers_ptr_ptr, struct span_context *parent_span_context)
+{
+    struct go_string traceparent_header_value_go_str;
+    long res = get_req_header_go_map(headers_ptr_ptr, "traceparent", W3C_KEY_LENGTH, &traceparent_header_value_go_str);
+    if (res < 0)
+    {
+        return res;
+    }
+    if (traceparent_header_value_go_str.len != W3C_VAL_LENGTH)
+    {
+        return -1;
+    }
+    char traceparent_header_value[W3C_VAL_LENGTH];
+    res = bpf_probe_read(&traceparent_header_value, sizeof(traceparent_header_value), traceparent_header_value_go_str.str);
+    if (res < 0)
+    {
+        return res;
+    }
+    w3c_string_to_span_context(traceparent_header_value, parent_span_context);
+    return 0;
+}
+
+// Extracts the tracestate from the request headers by looking for the 'tracestate' header.
+// Returns 0 on success, negative value on error or if the tracestate is malformed.
+static __always_inline long extract_tracestate_from_req_headers_go_map(void *headers_ptr_ptr, struct tracestate *ts)
+{
+    struct go_string tracestate_header_value_go_str;
+    long res = get_req_header_go_map(headers_ptr_ptr, "tracestate", TRACESTATE_KEY_LENGTH, &tracestate_header_value_go_str);
+    if (res < 0)
+    {
+        return res;
+    }
+    return read_tracestate(tracestate_header_value_go_str.str, tracestate_header_value_go_str.len, ts);
+}
+
+// Extracts the end user identity from the request headers by looking for the
+// configured end user header.
+// Returns 0 on success, negative value on error or if the value is too long.
+static __always_inline long extract_enduser_from_req_headers_go_map(void *headers_ptr_ptr, struct enduser *eu)
+{
+    struct go_string enduser_header_value_go_str;
+    long res = get_req_header_go_map(headers_ptr_ptr, (const char *)enduser_header, enduser_header_len, &enduser_header_value_go_str);
+    if (res < 0)
+    {
+        return res;
+    }
+    return read_enduser(enduser_header_value_go_str.str, enduser_header_value_go_str.len, eu);
+}
+
 static __always_inline long extract_context_from_req_headers_pre_parsed(void *key, struct span_context *parent_span_context) {
     struct span_context *parsed_header_context = bpf_map_lookup_elem(&http_server_context_headers, &key);
     if (!parsed_header_context) {
@@ -208,6 +313,40 @@ static __always_inline long extract_context_from_req_headers(void *key, struct s
     return extract_context_from_req_headers_go_map(key, parent_span_context);
 }
 
+static __always_inline long extract_tracestate_from_req_headers_pre_parsed(void *key, struct tracestate *ts) {
+    struct tracestate *parsed_tracestate = bpf_map_lookup_elem(&http_server_tracestate_headers, &key);
+    if (!parsed_tracestate) {
+        return -1;
+    }
+
+    __builtin_memcpy(ts, parsed_tracestate, sizeof(struct tracestate));
+    return 0;
+}
+
+static __always_inline long extract_tracestate_from_req_headers(void *key, struct tracestate *ts) {
+    if (swiss_maps_used) {
+        return extract_tracestate_from_req_headers_pre_parsed(key, ts);
+    }
+    return extract_tracestate_from_req_headers_go_map(key, ts);
+}
+
+static __always_inline long extract_enduser_from_req_headers_pre_parsed(void *key, struct enduser *eu) {
+    struct enduser *parsed_enduser = bpf_map_lookup_elem(&http_server_enduser_headers, &key);
+    if (!parsed_enduser) {
+        return -1;
+    }
+
+    __builtin_memcpy(eu, parsed_enduser, sizeof(struct enduser));
+    return 0;
+}
+
+static __always_inline long extract_enduser_from_req_headers(void *key, struct enduser *eu) {
+    if (swiss_maps_used) {
+        return extract_enduser_from_req_headers_pre_parsed(key, eu);
+    }
+    return extract_enduser_from_req_headers_go_map(key, eu);
+}
+
 static __always_inline void read_go_string(void *base, int offset, char *output, int maxLen, const char *errorMsg) {
     void *ptr = (void *)(base + offset);
     if (!get_go_string_from_user_ptr(ptr, output, maxLen)) {
@@ -245,7 +384,7 @@ int uprobe_serverHandler_ServeHTTP(struct pt_regs *ctx)
     uprobe_data->resp_ptr = (u64)resp_impl;
 
     struct http_server_span_t *http_server_span = &uprobe_data->span;
-    http_server_span->start_time = bpf_ktime_get_ns();
+    http_server_span->start_time = get_time_ns();
 
     // Propagate context
     void *req_ptr = get_argument(ctx, 4);
@@ -268,8 +407,19 @@ int uprobe_serverHandler_ServeHTTP(struct pt_regs *ctx)
 
     start_span(&start_span_params);
 
+    // The tracestate is only propagated along with a remote parent.
+    if (is_span_context_valid(&http_server_span->psc)) {
+        extract_tracestate_from_req_headers(start_span_params.get_parent_span_context_arg, &http_server_span->tracestate);
+        set_remote_tracestate(&http_server_span->sc, &http_server_span->tracestate);
+    }
+
+    if (enduser_enabled) {
+        extract_enduser_from_req_headers(start_span_params.get_parent_span_context_arg, &http_server_span->enduser);
+    }
+
     bpf_map_update_elem(&http_server_uprobes, &key, uprobe_data, 0);
     start_tracking_span(go_context.data, &http_server_span->sc);
+    set_goroutine_span(key, &http_server_span->sc);
     return 0;
 }
 
@@ -277,17 +427,26 @@ int uprobe_serverHandler_ServeHTTP(struct pt_regs *ctx)
 // func (sh serverHandler) ServeHTTP(rw ResponseWriter, req *Request)
 SEC("uprobe/serverHandler_ServeHTTP")
 int uprobe_serverHandler_ServeHTTP_Returns(struct pt_regs *ctx) {
-    u64 end_time = bpf_ktime_get_ns();
+    u64 end_time = get_time_ns();
     void *key = (void *)GOROUTINE(ctx);
 
     struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
     if (uprobe_data == NULL) {
         bpf_printk("uprobe/HandlerFunc_ServeHTTP_Returns: entry_state is NULL");
         bpf_map_delete_elem(&http_server_context_headers, &key);
+        bpf_map_delete_elem(&http_server_tracestate_headers, &key);
+        bpf_map_delete_elem(&http_server_enduser_headers, &key);
         return 0;
     }
 
     struct http_server_span_t *http_server_span = &uprobe_data->span;
+    if (uprobe_data->grpc) {
+        // The request is reported by the span of the gRPC server probe.
+        if (uprobe_data->grpc_parent_tracked) {
+            stop_tracking_span(&http_server_span->psc, NULL);
+        }
+        goto done;
+    }
 
     void *resp_ptr = (void *)uprobe_data->resp_ptr;
     void *req_ptr = NULL;
@@ -310,7 +469,11 @@ int uprobe_serverHandler_ServeHTTP_Returns(struct pt_regs *ctx) {
             }
         }
     }
-    read_go_string(url_ptr, path_ptr_pos, http_server_span->path, sizeof(http_server_span->path), "path from Request.URL");
+    u64 path_len = get_go_string_len_from_user_ptr((void *)(url_ptr + path_ptr_pos), http_server_span->path, sizeof(http_server_span->path));
+    if (path_len == 0) {
+        bpf_printk("Failed to get path from Request.URL");
+    }
+    http_server_span->path_truncated = path_len > sizeof(http_server_span->path);
     read_go_string(req_ptr, remote_addr_pos, http_server_span->remote_addr, sizeof(http_server_span->remote_addr), "remote addr from Request.RemoteAddr");
     read_go_string(req_ptr, host_pos, http_server_span->host, sizeof(http_server_span->host), "host from Request.Host");
     read_go_string(req_ptr, proto_pos, http_server_span->proto, sizeof(http_server_span->proto), "proto from Request.Proto");
@@ -318,11 +481,15 @@ int uprobe_serverHandler_ServeHTTP_Returns(struct pt_regs *ctx) {
     // status code
     bpf_probe_read(&http_server_span->status_code, sizeof(http_server_span->status_code), (void *)(resp_ptr + status_code_pos));
 
-    output_span_event(ctx, http_server_span, sizeof(*http_server_span), &http_server_span->sc);
+    output_span_event(ctx, http_server_span, enduser_event_size(sizeof(*http_server_span), offsetof(struct http_server_span_t, enduser)), &http_server_span->sc);
 
     stop_tracking_span(&http_server_span->sc, &http_server_span->psc);
+done:
+    delete_goroutine_span(key);
     bpf_map_delete_elem(&http_server_uprobes, &key);
     bpf_map_delete_elem(&http_server_context_headers, &key);
+    bpf_map_delete_elem(&http_server_tracestate_headers, &key);
+    bpf_map_delete_elem(&http_server_enduser_headers, &key);
     return 0;
 }
 
@@ -343,8 +510,397 @@ int uprobe_textproto_Reader_readContinuedLineSlice_Returns(struct pt_regs *ctx)
             struct span_context parent_span_context = {};
             w3c_string_to_span_context((char *)(temp + W3C_KEY_LENGTH + 2), &parent_span_context);            
             bpf_map_update_elem(&http_server_context_headers, &key, &parent_span_context, BPF_ANY);
+            return 0;
         }
     }
 
+    if (len > TRACESTATE_KEY_LENGTH + 2) {
+        char prefix[TRACESTATE_KEY_LENGTH + 2];
+        bpf_probe_read(prefix, sizeof(prefix), buf);
+
+        if (!bpf_memicmp((const char *)prefix, "tracestate: ", TRACESTATE_KEY_LENGTH + 2)) {
+            struct tracestate *ts = tracestate_buffer();
+            if (ts == NULL) {
+                return 0;
+            }
+            if (read_tracestate(buf + TRACESTATE_KEY_LENGTH + 2, len - TRACESTATE_KEY_LENGTH - 2, ts) == 0) {
+                bpf_map_update_elem(&http_server_tracestate_headers, &key, ts, BPF_ANY);
+            }
+            return 0;
+        }
+    }
+
+    if (enduser_enabled && len > enduser_header_len + 1 && is_enduser_header(buf, enduser_header_len)) {
+        char sep[2] = {};
+        bpf_probe_read(sep, sizeof(sep), buf + enduser_header_len);
+        if (sep[0] != ':') {
+            return 0;
+        }
+        u64 offset = enduser_header_len + 1;
+        if (sep[1] == ' ') {
+            offset++;
+        }
+        struct enduser *eu = enduser_buffer();
+        if (eu == NULL) {
+            return 0;
+        }
+        if (read_enduser(buf + offset, len - offset, eu) == 0) {
+            bpf_map_update_elem(&http_server_enduser_headers, &key, eu, BPF_ANY);
+        }
+    }
+
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (engine *Engine) handleHTTPRequest(c *Context)
+SEC("uprobe/Engine_handleHTTPRequest")
+int uprobe_Engine_handleHTTPRequest(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    uprobe_data->router_ctx_ptr = (u64)get_argument(ctx, 2);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (engine *Engine) handleHTTPRequest(c *Context)
+SEC("uprobe/Engine_handleHTTPRequest")
+int uprobe_Engine_handleHTTPRequest_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL || uprobe_data->router_ctx_ptr == 0) {
+        return 0;
+    }
+
+    // The full path is the route template of the handler, it is empty if no
+    // route matches the request.
+    void *gin_ctx_ptr = (void *)uprobe_data->router_ctx_ptr;
+    read_go_string(gin_ctx_ptr, gin_context_full_path_pos, uprobe_data->span.route, sizeof(uprobe_data->span.route), "full path from gin.Context");
+    uprobe_data->router_ctx_ptr = 0;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Router) Find(method, path string, c Context)
+SEC("uprobe/Router_Find")
+int uprobe_Router_Find(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    // c is an interface, its data is always an *echo.context.
+    uprobe_data->router_ctx_ptr = (u64)get_argument(ctx, 7);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Router) Find(method, path string, c Context)
+SEC("uprobe/Router_Find")
+int uprobe_Router_Find_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL || uprobe_data->router_ctx_ptr == 0) {
+        return 0;
+    }
+
+    // The path is the route template of the handler, it is empty if no route
+    // matches the request.
+    void *echo_ctx_ptr = (void *)uprobe_data->router_ctx_ptr;
+    read_go_string(echo_ctx_ptr, echo_context_path_pos, uprobe_data->span.route, sizeof(uprobe_data->span.route), "path from echo.context");
+    uprobe_data->router_ctx_ptr = 0;
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Router) Match(req *http.Request, match *RouteMatch) bool
+SEC("uprobe/Router_Match")
+int uprobe_Router_Match(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    // Subrouters are matched by nested calls sharing the match.
+    uprobe_data->router_ctx_ptr = (u64)get_argument(ctx, 3);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Router) Match(req *http.Request, match *RouteMatch) bool
+SEC("uprobe/Router_Match")
+int uprobe_Router_Match_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL || uprobe_data->router_ctx_ptr == 0) {
+        return 0;
+    }
+
+    // The match is not cleared, the parent router of a subrouter returns
+    // after it.
+    u8 matched = (u8)(u64)get_argument(ctx, 1);
+    if (!matched) {
+        return 0;
+    }
+
+    // The NotFoundHandler and MethodNotAllowedHandler of a router match
+    // requests with an error, the matched route is then not the handler's.
+    void *match_ptr = (void *)uprobe_data->router_ctx_ptr;
+    void *match_err_type = NULL;
+    bpf_probe_read_user(&match_err_type, sizeof(match_err_type), (void *)(match_ptr + mux_route_match_match_err_pos));
+    if (match_err_type != NULL) {
+        return 0;
+    }
+
+    void *route_ptr = NULL;
+    bpf_probe_read_user(&route_ptr, sizeof(route_ptr), (void *)(match_ptr + mux_route_match_route_pos));
+    if (route_ptr == NULL) {
+        return 0;
+    }
+
+    // The path template of a subrouter route includes the prefixes of its
+    // parent routes. Routes not matching a path have no template.
+    void *path_regexp_ptr = NULL;
+    void *path_pos = route_ptr + mux_route_route_conf_pos + mux_route_conf_regexp_pos + mux_route_regexp_group_path_pos;
+    bpf_probe_read_user(&path_regexp_ptr, sizeof(path_regexp_ptr), path_pos);
+    if (path_regexp_ptr == NULL) {
+        return 0;
+    }
+
+    read_go_string(path_regexp_ptr, mux_route_regexp_template_pos, uprobe_data->span.route, sizeof(uprobe_data->span.route), "path template from mux.Route");
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (n *node) FindRoute(rctx *Context, method methodTyp, path string) (*node, endpoints, http.Handler)
+SEC("uprobe/node_FindRoute")
+int uprobe_node_FindRoute(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    // The routers mounted in a router find their routes with the same context.
+    uprobe_data->router_ctx_ptr = (u64)get_argument(ctx, 2);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (n *node) FindRoute(rctx *Context, method methodTyp, path string) (*node, endpoints, http.Handler)
+SEC("uprobe/node_FindRoute")
+int uprobe_node_FindRoute_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    // No handler is found, the request is handled by the NotFound or
+    // MethodNotAllowed handler of the router and has no route. This is also
+    // the case if a router mounted in a router finding a route does not find
+    // one.
+    void *handler_type = get_argument(ctx, 3);
+    if (handler_type == NULL) {
+        uprobe_data->router_ctx_ptr = 0;
+    }
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (mx *Mux) routeHTTP(w http.ResponseWriter, r *http.Request)
+SEC("uprobe/Mux_routeHTTP")
+int uprobe_Mux_routeHTTP_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL || uprobe_data->router_ctx_ptr == 0) {
+        return 0;
+    }
+
+    // The handler was invoked, the route patterns of all the routers the
+    // request went through are in the context. They are joined as
+    // chi.Context.RoutePattern does.
+    void *rctx_ptr = (void *)uprobe_data->router_ctx_ptr;
+    uprobe_data->router_ctx_ptr = 0;
+
+    struct go_slice patterns = {0};
+    if (bpf_probe_read_user(&patterns, sizeof(patterns), (void *)(rctx_ptr + chi_context_route_patterns_pos)) != 0) {
+        bpf_printk("Failed to get route patterns from chi.Context");
+        return 0;
+    }
+
+    char *route = uprobe_data->span.route;
+    u64 len = 0;
+    for (s64 i = 0; i < MAX_CHI_ROUTE_PATTERNS; i++) {
+        if (i >= patterns.len) {
+            break;
+        }
+        struct go_string pattern = {0};
+        if (bpf_probe_read_user(&pattern, sizeof(pattern), (void *)(patterns.array + (i * sizeof(pattern)))) != 0) {
+            break;
+        }
+
+        // The pattern of a mounted router ends with a "/*" wildcard, which
+        // is replaced by the pattern of the mounted router's route.
+        s64 pattern_len = pattern.len;
+        if (i + 1 < patterns.len && pattern_len >= 2) {
+            char suffix[2] = {0};
+            bpf_probe_read_user(suffix, sizeof(suffix), pattern.str + pattern_len - 2);
+            if (suffix[0] == '/' && suffix[1] == '*') {
+                pattern_len -= 2;
+            }
+        }
+        if (pattern_len <= 0) {
+            continue;
+        }
+
+        if (len >= PATH_MAX_LEN - 1) {
+            break;
+        }
+        u64 n = pattern_len;
+        if (n > PATH_MAX_LEN - 1 - len) {
+            n = PATH_MAX_LEN - 1 - len;
+        }
+        bpf_probe_read_user(&route[len & (PATH_MAX_LEN - 1)], n & (PATH_MAX_LEN - 1), pattern.str);
+        len += n;
+    }
+
+    // Like chi, the trailing slashes of the index route of a mounted router
+    // are trimmed (e.g. "/users/" is "/users").
+    if (len > 2 && route[(len - 1) & (PATH_MAX_LEN - 1)] == '/' && route[(len - 2) & (PATH_MAX_LEN - 1)] == '/') {
+        len -= 2;
+    }
+    if (len > 1 && route[(len - 1) & (PATH_MAX_LEN - 1)] == '/') {
+        len--;
+    }
+    route[len & (PATH_MAX_LEN - 1)] = '\0';
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func ServerHTTPStatusFromErrorCode(code ErrorCode) int
+SEC("uprobe/ServerHTTPStatusFromErrorCode")
+int uprobe_ServerHTTPStatusFromErrorCode(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    // The code is also mapped when validated, e.g. by a Twirp client called
+    // by the handler. It is only used once the status of the response is set.
+    __builtin_memset(uprobe_data->twirp_error_code, 0, sizeof(uprobe_data->twirp_error_code));
+    void *code_ptr = get_argument(ctx, 1);
+    u64 code_len = (u64)get_argument(ctx, 2);
+    u64 code_size = TWIRP_ERROR_CODE_MAX_LEN < code_len ? TWIRP_ERROR_CODE_MAX_LEN : code_len;
+    bpf_probe_read_user(uprobe_data->twirp_error_code, code_size, code_ptr);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func WithStatusCode(ctx context.Context, code int) context.Context
+SEC("uprobe/WithStatusCode")
+int uprobe_WithStatusCode(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    // The generated Twirp servers set the status of every response they
+    // write. The status of an error is the one its code is mapped to.
+    struct http_server_span_t *http_server_span = &uprobe_data->span;
+    http_server_span->twirp = 1;
+    u64 status = (u64)get_argument(ctx, 3);
+    if (status == 200) {
+        __builtin_memset(http_server_span->twirp_error_code, 0, sizeof(http_server_span->twirp_error_code));
+        return 0;
+    }
+    __builtin_memcpy(http_server_span->twirp_error_code, uprobe_data->twirp_error_code, sizeof(http_server_span->twirp_error_code));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func AnnotateContext(ctx context.Context, mux *ServeMux, req *http.Request, rpcMethodName string, options ...AnnotateContextOption) (context.Context, error)
+// func AnnotateIncomingContext(ctx context.Context, mux *ServeMux, req *http.Request, rpcMethodName string, options ...AnnotateContextOption) (context.Context, error)
+SEC("uprobe/AnnotateContext")
+int uprobe_AnnotateContext(struct pt_regs *ctx) {
+    // The handlers of streaming endpoints wrapped by a WebSocket proxy run in
+    // their own goroutine, they have no server span to annotate.
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL) {
+        return 0;
+    }
+
+    struct http_server_span_t *http_server_span = &uprobe_data->span;
+    __builtin_memset(http_server_span->grpc_gateway_method, 0, sizeof(http_server_span->grpc_gateway_method));
+    __builtin_memset(http_server_span->grpc_gateway_route, 0, sizeof(http_server_span->grpc_gateway_route));
+
+    void *method_ptr = get_argument(ctx, 5);
+    u64 method_len = (u64)get_argument(ctx, 6);
+    u64 method_size = PATH_MAX_LEN - 1 < method_len ? PATH_MAX_LEN - 1 : method_len;
+    bpf_probe_read_user(http_server_span->grpc_gateway_method, method_size, method_ptr);
+
+    // The generated handlers pass the path template of their binding with
+    // WithHTTPPathPattern, its only option. The option is a closure
+    // capturing the template after its function pointer.
+    void *options_ptr = get_argument(ctx, 7);
+    u64 options_len = (u64)get_argument(ctx, 8);
+    if (options_ptr == NULL || options_len == 0) {
+        return 0;
+    }
+    void *option_ptr = NULL;
+    bpf_probe_read_user(&option_ptr, sizeof(option_ptr), options_ptr);
+    if (option_ptr == NULL) {
+        return 0;
+    }
+    struct go_string pattern = {0};
+    bpf_probe_read_user(&pattern, sizeof(pattern), option_ptr + sizeof(void *));
+    if (pattern.len <= 0) {
+        return 0;
+    }
+    u64 pattern_size = PATH_MAX_LEN - 1 < pattern.len ? PATH_MAX_LEN - 1 : pattern.len;
+    bpf_probe_read_user(http_server_span->grpc_gateway_route, pattern_size, pattern.str);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request)
+//
+// The gRPC requests served by a google.golang.org/grpc Server through its
+// http.Handler implementation get a span from the gRPC server probe. The span
+// of the HTTP request is not output, and its parent is tracked in the context
+// of the request so it is the parent of the gRPC span.
+SEC("uprobe/Server_ServeHTTP")
+int uprobe_Server_ServeHTTP(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct uprobe_data_t *uprobe_data = bpf_map_lookup_elem(&http_server_uprobes, &key);
+    if (uprobe_data == NULL || uprobe_data->grpc) {
+        return 0;
+    }
+    uprobe_data->grpc = 1;
+
+    struct http_server_span_t *http_server_span = &uprobe_data->span;
+    void *go_context = NULL;
+    void **tracked_ctx = bpf_map_lookup_elem(&tracked_spans_by_sc, &http_server_span->sc);
+    if (tracked_ctx != NULL) {
+        go_context = *tracked_ctx;
+    }
+    stop_tracking_span(&http_server_span->sc, &http_server_span->psc);
+    delete_goroutine_span(key);
+
+    // A local parent is still tracked in the context.
+    if (go_context != NULL && is_span_context_valid(&http_server_span->psc) &&
+        bpf_map_lookup_elem(&tracked_spans_by_sc, &http_server_span->psc) == NULL) {
+        start_tracking_span(go_context, &http_server_span->psc);
+        uprobe_data->grpc_parent_tracked = 1;
+    }
     return 0;
 }
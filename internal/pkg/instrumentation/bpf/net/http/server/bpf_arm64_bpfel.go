@@ -13,6 +13,12 @@ import (
 	"github.com/cilium/ebpf"
 )
 
+type bpfEnduser struct {
+	_     structs.HostLayout
+	Len   uint64
+	Value [1024]int8
+}
+
 type bpfSliceArrayBuff struct {
 	_    structs.HostLayout
 	Buff [1024]uint8
@@ -26,23 +32,48 @@ type bpfSpanContext struct {
 	Padding    [7]uint8
 }
 
+type bpfTraceIdKey struct {
+	_       structs.HostLayout
+	TraceID [16]uint8
+}
+
+type bpfTracestate struct {
+	_     structs.HostLayout
+	Len   uint64
+	Value [512]int8
+}
+
 type bpfUprobeDataT struct {
 	_    structs.HostLayout
 	Span struct {
-		_           structs.HostLayout
-		StartTime   uint64
-		EndTime     uint64
-		Sc          bpfSpanContext
-		Psc         bpfSpanContext
-		StatusCode  uint64
-		Method      [8]int8
-		Path        [128]int8
-		PathPattern [128]int8
-		RemoteAddr  [256]int8
-		Host        [256]int8
-		Proto       [8]int8
+		_                 structs.HostLayout
+		StartTime         uint64
+		EndTime           uint64
+		Sc                bpfSpanContext
+		Psc               bpfSpanContext
+		StatusCode        uint64
+		Method            [8]int8
+		Path              [128]int8
+		PathPattern       [128]int8
+		Route             [128]int8
+		GrpcGatewayRoute  [128]int8
+		GrpcGatewayMethod [128]int8
+		RemoteAddr        [256]int8
+		Host              [256]int8
+		Proto             [8]int8
+		TwirpErrorCode    [32]int8
+		Twirp             uint8
+		PathTruncated     uint8
+		Padding           [6]uint8
+		Tracestate        bpfTracestate
+		Enduser           bpfEnduser
 	}
-	RespPtr uint64
+	RespPtr           uint64
+	RouterCtxPtr      uint64
+	TwirpErrorCode    [32]int8
+	Grpc              uint8
+	GrpcParentTracked uint8
+	_                 [6]byte
 }
 
 // loadBpf returns the embedded CollectionSpec for bpf.
@@ -87,6 +118,19 @@ type bpfSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfProgramSpecs struct {
+	UprobeAnnotateContext                              *ebpf.ProgramSpec `ebpf:"uprobe_AnnotateContext"`
+	UprobeEngineHandleHTTPRequest                      *ebpf.ProgramSpec `ebpf:"uprobe_Engine_handleHTTPRequest"`
+	UprobeEngineHandleHTTPRequestReturns               *ebpf.ProgramSpec `ebpf:"uprobe_Engine_handleHTTPRequest_Returns"`
+	UprobeMuxRouteHTTP_Returns                         *ebpf.ProgramSpec `ebpf:"uprobe_Mux_routeHTTP_Returns"`
+	UprobeRouterFind                                   *ebpf.ProgramSpec `ebpf:"uprobe_Router_Find"`
+	UprobeRouterFindReturns                            *ebpf.ProgramSpec `ebpf:"uprobe_Router_Find_Returns"`
+	UprobeRouterMatch                                  *ebpf.ProgramSpec `ebpf:"uprobe_Router_Match"`
+	UprobeRouterMatchReturns                           *ebpf.ProgramSpec `ebpf:"uprobe_Router_Match_Returns"`
+	UprobeServerHTTPStatusFromErrorCode                *ebpf.ProgramSpec `ebpf:"uprobe_ServerHTTPStatusFromErrorCode"`
+	UprobeServerServeHTTP                              *ebpf.ProgramSpec `ebpf:"uprobe_Server_ServeHTTP"`
+	UprobeWithStatusCode                               *ebpf.ProgramSpec `ebpf:"uprobe_WithStatusCode"`
+	UprobeNodeFindRoute                                *ebpf.ProgramSpec `ebpf:"uprobe_node_FindRoute"`
+	UprobeNodeFindRouteReturns                         *ebpf.ProgramSpec `ebpf:"uprobe_node_FindRoute_Returns"`
 	UprobeServerHandlerServeHTTP                       *ebpf.ProgramSpec `ebpf:"uprobe_serverHandler_ServeHTTP"`
 	UprobeServerHandlerServeHTTP_Returns               *ebpf.ProgramSpec `ebpf:"uprobe_serverHandler_ServeHTTP_Returns"`
 	UprobeTextprotoReaderReadContinuedLineSliceReturns *ebpf.ProgramSpec `ebpf:"uprobe_textproto_Reader_readContinuedLineSlice_Returns"`
@@ -96,30 +140,49 @@ type bpfProgramSpecs struct {
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfMapSpecs struct {
-	AllocMap                   *ebpf.MapSpec `ebpf:"alloc_map"`
-	Events                     *ebpf.MapSpec `ebpf:"events"`
-	GoContextToSc              *ebpf.MapSpec `ebpf:"go_context_to_sc"`
-	GolangMapbucketStorageMap  *ebpf.MapSpec `ebpf:"golang_mapbucket_storage_map"`
-	HttpServerContextHeaders   *ebpf.MapSpec `ebpf:"http_server_context_headers"`
-	HttpServerUprobeStorageMap *ebpf.MapSpec `ebpf:"http_server_uprobe_storage_map"`
-	HttpServerUprobes          *ebpf.MapSpec `ebpf:"http_server_uprobes"`
-	ProbeActiveSamplerMap      *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
-	SamplersConfigMap          *ebpf.MapSpec `ebpf:"samplers_config_map"`
-	SliceArrayBuffMap          *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
-	TrackedSpansBySc           *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
+	AllocMap                    *ebpf.MapSpec `ebpf:"alloc_map"`
+	EnduserStorageMap           *ebpf.MapSpec `ebpf:"enduser_storage_map"`
+	Events                      *ebpf.MapSpec `ebpf:"events"`
+	GoContextToSc               *ebpf.MapSpec `ebpf:"go_context_to_sc"`
+	GolangMapbucketStorageMap   *ebpf.MapSpec `ebpf:"golang_mapbucket_storage_map"`
+	GoroutineSpans              *ebpf.MapSpec `ebpf:"goroutine_spans"`
+	HttpServerContextHeaders    *ebpf.MapSpec `ebpf:"http_server_context_headers"`
+	HttpServerEnduserHeaders    *ebpf.MapSpec `ebpf:"http_server_enduser_headers"`
+	HttpServerTracestateHeaders *ebpf.MapSpec `ebpf:"http_server_tracestate_headers"`
+	HttpServerUprobeStorageMap  *ebpf.MapSpec `ebpf:"http_server_uprobe_storage_map"`
+	HttpServerUprobes           *ebpf.MapSpec `ebpf:"http_server_uprobes"`
+	ProbeActiveSamplerMap       *ebpf.MapSpec `ebpf:"probe_active_sampler_map"`
+	SamplersConfigMap           *ebpf.MapSpec `ebpf:"samplers_config_map"`
+	SliceArrayBuffMap           *ebpf.MapSpec `ebpf:"slice_array_buff_map"`
+	TracestateByTraceId         *ebpf.MapSpec `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap        *ebpf.MapSpec `ebpf:"tracestate_storage_map"`
+	TrackedSpansBySc            *ebpf.MapSpec `ebpf:"tracked_spans_by_sc"`
 }
 
 // bpfVariableSpecs contains global variables before they are loaded into the kernel.
 //
 // It can be passed ebpf.CollectionSpec.Assign.
 type bpfVariableSpecs struct {
+	BootClockSupported         *ebpf.VariableSpec `ebpf:"boot_clock_supported"`
 	BucketsPtrPos              *ebpf.VariableSpec `ebpf:"buckets_ptr_pos"`
+	ChiContextRoutePatternsPos *ebpf.VariableSpec `ebpf:"chi_context_route_patterns_pos"`
 	CtxPtrPos                  *ebpf.VariableSpec `ebpf:"ctx_ptr_pos"`
+	EchoContextPathPos         *ebpf.VariableSpec `ebpf:"echo_context_path_pos"`
 	EndAddr                    *ebpf.VariableSpec `ebpf:"end_addr"`
+	EnduserEnabled             *ebpf.VariableSpec `ebpf:"enduser_enabled"`
+	EnduserHeader              *ebpf.VariableSpec `ebpf:"enduser_header"`
+	EnduserHeaderLen           *ebpf.VariableSpec `ebpf:"enduser_header_len"`
+	GinContextFullPathPos      *ebpf.VariableSpec `ebpf:"gin_context_full_path_pos"`
 	HeadersPtrPos              *ebpf.VariableSpec `ebpf:"headers_ptr_pos"`
 	Hex                        *ebpf.VariableSpec `ebpf:"hex"`
 	HostPos                    *ebpf.VariableSpec `ebpf:"host_pos"`
 	MethodPtrPos               *ebpf.VariableSpec `ebpf:"method_ptr_pos"`
+	MuxRouteConfRegexpPos      *ebpf.VariableSpec `ebpf:"mux_route_conf_regexp_pos"`
+	MuxRouteMatchMatchErrPos   *ebpf.VariableSpec `ebpf:"mux_route_match_match_err_pos"`
+	MuxRouteMatchRoutePos      *ebpf.VariableSpec `ebpf:"mux_route_match_route_pos"`
+	MuxRouteRegexpGroupPathPos *ebpf.VariableSpec `ebpf:"mux_route_regexp_group_path_pos"`
+	MuxRouteRegexpTemplatePos  *ebpf.VariableSpec `ebpf:"mux_route_regexp_template_pos"`
+	MuxRouteRouteConfPos       *ebpf.VariableSpec `ebpf:"mux_route_route_conf_pos"`
 	PatStrPos                  *ebpf.VariableSpec `ebpf:"pat_str_pos"`
 	PathPtrPos                 *ebpf.VariableSpec `ebpf:"path_ptr_pos"`
 	PatternPathPublicSupported *ebpf.VariableSpec `ebpf:"pattern_path_public_supported"`
@@ -156,31 +219,43 @@ func (o *bpfObjects) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfMaps struct {
-	AllocMap                   *ebpf.Map `ebpf:"alloc_map"`
-	Events                     *ebpf.Map `ebpf:"events"`
-	GoContextToSc              *ebpf.Map `ebpf:"go_context_to_sc"`
-	GolangMapbucketStorageMap  *ebpf.Map `ebpf:"golang_mapbucket_storage_map"`
-	HttpServerContextHeaders   *ebpf.Map `ebpf:"http_server_context_headers"`
-	HttpServerUprobeStorageMap *ebpf.Map `ebpf:"http_server_uprobe_storage_map"`
-	HttpServerUprobes          *ebpf.Map `ebpf:"http_server_uprobes"`
-	ProbeActiveSamplerMap      *ebpf.Map `ebpf:"probe_active_sampler_map"`
-	SamplersConfigMap          *ebpf.Map `ebpf:"samplers_config_map"`
-	SliceArrayBuffMap          *ebpf.Map `ebpf:"slice_array_buff_map"`
-	TrackedSpansBySc           *ebpf.Map `ebpf:"tracked_spans_by_sc"`
+	AllocMap                    *ebpf.Map `ebpf:"alloc_map"`
+	EnduserStorageMap           *ebpf.Map `ebpf:"enduser_storage_map"`
+	Events                      *ebpf.Map `ebpf:"events"`
+	GoContextToSc               *ebpf.Map `ebpf:"go_context_to_sc"`
+	GolangMapbucketStorageMap   *ebpf.Map `ebpf:"golang_mapbucket_storage_map"`
+	GoroutineSpans              *ebpf.Map `ebpf:"goroutine_spans"`
+	HttpServerContextHeaders    *ebpf.Map `ebpf:"http_server_context_headers"`
+	HttpServerEnduserHeaders    *ebpf.Map `ebpf:"http_server_enduser_headers"`
+	HttpServerTracestateHeaders *ebpf.Map `ebpf:"http_server_tracestate_headers"`
+	HttpServerUprobeStorageMap  *ebpf.Map `ebpf:"http_server_uprobe_storage_map"`
+	HttpServerUprobes           *ebpf.Map `ebpf:"http_server_uprobes"`
+	ProbeActiveSamplerMap       *ebpf.Map `ebpf:"probe_active_sampler_map"`
+	SamplersConfigMap           *ebpf.Map `ebpf:"samplers_config_map"`
+	SliceArrayBuffMap           *ebpf.Map `ebpf:"slice_array_buff_map"`
+	TracestateByTraceId         *ebpf.Map `ebpf:"tracestate_by_trace_id"`
+	TracestateStorageMap        *ebpf.Map `ebpf:"tracestate_storage_map"`
+	TrackedSpansBySc            *ebpf.Map `ebpf:"tracked_spans_by_sc"`
 }
 
 func (m *bpfMaps) Close() error {
 	return _BpfClose(
 		m.AllocMap,
+		m.EnduserStorageMap,
 		m.Events,
 		m.GoContextToSc,
 		m.GolangMapbucketStorageMap,
+		m.GoroutineSpans,
 		m.HttpServerContextHeaders,
+		m.HttpServerEnduserHeaders,
+		m.HttpServerTracestateHeaders,
 		m.HttpServerUprobeStorageMap,
 		m.HttpServerUprobes,
 		m.ProbeActiveSamplerMap,
 		m.SamplersConfigMap,
 		m.SliceArrayBuffMap,
+		m.TracestateByTraceId,
+		m.TracestateStorageMap,
 		m.TrackedSpansBySc,
 	)
 }
@@ -189,13 +264,26 @@ func (m *bpfMaps) Close() error {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfVariables struct {
+	BootClockSupported         *ebpf.Variable `ebpf:"boot_clock_supported"`
 	BucketsPtrPos              *ebpf.Variable `ebpf:"buckets_ptr_pos"`
+	ChiContextRoutePatternsPos *ebpf.Variable `ebpf:"chi_context_route_patterns_pos"`
 	CtxPtrPos                  *ebpf.Variable `ebpf:"ctx_ptr_pos"`
+	EchoContextPathPos         *ebpf.Variable `ebpf:"echo_context_path_pos"`
 	EndAddr                    *ebpf.Variable `ebpf:"end_addr"`
+	EnduserEnabled             *ebpf.Variable `ebpf:"enduser_enabled"`
+	EnduserHeader              *ebpf.Variable `ebpf:"enduser_header"`
+	EnduserHeaderLen           *ebpf.Variable `ebpf:"enduser_header_len"`
+	GinContextFullPathPos      *ebpf.Variable `ebpf:"gin_context_full_path_pos"`
 	HeadersPtrPos              *ebpf.Variable `ebpf:"headers_ptr_pos"`
 	Hex                        *ebpf.Variable `ebpf:"hex"`
 	HostPos                    *ebpf.Variable `ebpf:"host_pos"`
 	MethodPtrPos               *ebpf.Variable `ebpf:"method_ptr_pos"`
+	MuxRouteConfRegexpPos      *ebpf.Variable `ebpf:"mux_route_conf_regexp_pos"`
+	MuxRouteMatchMatchErrPos   *ebpf.Variable `ebpf:"mux_route_match_match_err_pos"`
+	MuxRouteMatchRoutePos      *ebpf.Variable `ebpf:"mux_route_match_route_pos"`
+	MuxRouteRegexpGroupPathPos *ebpf.Variable `ebpf:"mux_route_regexp_group_path_pos"`
+	MuxRouteRegexpTemplatePos  *ebpf.Variable `ebpf:"mux_route_regexp_template_pos"`
+	MuxRouteRouteConfPos       *ebpf.Variable `ebpf:"mux_route_route_conf_pos"`
 	PatStrPos                  *ebpf.Variable `ebpf:"pat_str_pos"`
 	PathPtrPos                 *ebpf.Variable `ebpf:"path_ptr_pos"`
 	PatternPathPublicSupported *ebpf.Variable `ebpf:"pattern_path_public_supported"`
@@ -216,6 +304,19 @@ type bpfVariables struct {
 //
 // It can be passed to loadBpfObjects or ebpf.CollectionSpec.LoadAndAssign.
 type bpfPrograms struct {
+	UprobeAnnotateContext                              *ebpf.Program `ebpf:"uprobe_AnnotateContext"`
+	UprobeEngineHandleHTTPRequest                      *ebpf.Program `ebpf:"uprobe_Engine_handleHTTPRequest"`
+	UprobeEngineHandleHTTPRequestReturns               *ebpf.Program `ebpf:"uprobe_Engine_handleHTTPRequest_Returns"`
+	UprobeMuxRouteHTTP_Returns                         *ebpf.Program `ebpf:"uprobe_Mux_routeHTTP_Returns"`
+	UprobeRouterFind                                   *ebpf.Program `ebpf:"uprobe_Router_Find"`
+	UprobeRouterFindReturns                            *ebpf.Program `ebpf:"uprobe_Router_Find_Returns"`
+	UprobeRouterMatch                                  *ebpf.Program `ebpf:"uprobe_Router_Match"`
+	UprobeRouterMatchReturns                           *ebpf.Program `ebpf:"uprobe_Router_Match_Returns"`
+	UprobeServerHTTPStatusFromErrorCode                *ebpf.Program `ebpf:"uprobe_ServerHTTPStatusFromErrorCode"`
+	UprobeServerServeHTTP                              *ebpf.Program `ebpf:"uprobe_Server_ServeHTTP"`
+	UprobeWithStatusCode                               *ebpf.Program `ebpf:"uprobe_WithStatusCode"`
+	UprobeNodeFindRoute                                *ebpf.Program `ebpf:"uprobe_node_FindRoute"`
+	UprobeNodeFindRouteReturns                         *ebpf.Program `ebpf:"uprobe_node_FindRoute_Returns"`
 	UprobeServerHandlerServeHTTP                       *ebpf.Program `ebpf:"uprobe_serverHandler_ServeHTTP"`
 	UprobeServerHandlerServeHTTP_Returns               *ebpf.Program `ebpf:"uprobe_serverHandler_ServeHTTP_Returns"`
 	UprobeTextprotoReaderReadContinuedLineSliceReturns *ebpf.Program `ebpf:"uprobe_textproto_Reader_readContinuedLineSlice_Returns"`
@@ -223,6 +324,19 @@ type bpfPrograms struct {
 
 func (p *bpfPrograms) Close() error {
 	return _BpfClose(
+		p.UprobeAnnotateContext,
+		p.UprobeEngineHandleHTTPRequest,
+		p.UprobeEngineHandleHTTPRequestReturns,
+		p.UprobeMuxRouteHTTP_Returns,
+		p.UprobeRouterFind,
+		p.UprobeRouterFindReturns,
+		p.UprobeRouterMatch,
+		p.UprobeRouterMatchReturns,
+		p.UprobeServerHTTPStatusFromErrorCode,
+		p.UprobeServerServeHTTP,
+		p.UprobeWithStatusCode,
+		p.UprobeNodeFindRoute,
+		p.UprobeNodeFindRouteReturns,
 		p.UprobeServerHandlerServeHTTP,
 		p.UprobeServerHandlerServeHTTP_Returns,
 		p.UprobeTextprotoReaderReadContinuedLineSliceReturns,
@@ -19,7 +19,9 @@ import (
 	"go.opentelemetry.io/auto/internal/pkg/inject"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/enduser"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
 	"go.opentelemetry.io/auto/internal/pkg/process"
@@ -28,8 +30,43 @@ import (
 
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
 
-// pkg is the package being instrumented.
-const pkg = "net/http"
+const (
+	// pkg is the package being instrumented.
+	pkg = "net/http"
+	// ginPkg is the package of the Gin router. The route template of the
+	// handler matching a request it routes is read from it.
+	ginPkg = "github.com/gin-gonic/gin"
+	// echoPkg is the package of the Echo router. The route template of the
+	// handler matching a request it routes is read from it.
+	echoPkg = "github.com/labstack/echo/v4"
+	// muxPkg is the package of the gorilla/mux router. The path template of
+	// the route matching a request it routes is read from it.
+	muxPkg = "github.com/gorilla/mux"
+	// chiPkg is the package of the chi router. The route patterns of the
+	// routers a request it routes goes through are read from it.
+	chiPkg = "github.com/go-chi/chi/v5"
+	// twirpPkg is the package of the Twirp runtime. The requests handled by
+	// the generated Twirp servers and their errors are read from it.
+	twirpPkg = "github.com/twitchtv/twirp"
+	// grpcGatewayPkg is the package of the grpc-gateway runtime. The path
+	// template and the gRPC method of the handlers of the requests it proxies
+	// are read from it.
+	grpcGatewayPkg = "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	// grpcPkg is the package of the gRPC servers. The requests served by
+	// their http.Handler implementation have a span of the gRPC server probe
+	// instead.
+	grpcPkg = "google.golang.org/grpc"
+)
+
+const (
+	// twirpErrorCodeKey is the attribute key of the code of the error
+	// returned by a Twirp service.
+	twirpErrorCodeKey = attribute.Key("rpc.twirp.error_code")
+
+	// pathTruncatedKey is the attribute key set to true if the path was
+	// truncated to the 128 bytes of the event.
+	pathTruncatedKey = attribute.Key("url.path.truncated")
+)
 
 var (
 	goMapsVersion = semver.New(1, 24, 0, "", "")
@@ -46,6 +83,111 @@ var (
 		// Don't warn, we have a backup path.
 		FailureMode: probe.FailureModeIgnore,
 	}
+
+	// ginFullPathMinVersion is the first version of Gin with the route
+	// template of the matched handler stored in its Context.
+	ginFullPathMinVersion = semver.New(1, 5, 0, "", "")
+
+	ginWithFullPath = probe.PackageConstraints{
+		Package: ginPkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + ginFullPathMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using Gin is expected, the route is read from net/http then.
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	// echoPathMinVersion is the first version of Echo only storing the route
+	// template of the matched handler in its context. Prior versions store
+	// the request path when no route matches.
+	echoPathMinVersion = semver.New(4, 10, 1, "", "")
+
+	echoWithPath = probe.PackageConstraints{
+		Package: echoPkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + echoPathMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using Echo is expected, the route is read from net/http then.
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	// muxRouteConfMinVersion is the first version of gorilla/mux with the
+	// path template of a route stored in its embedded routeConf.
+	muxRouteConfMinVersion = semver.New(1, 7, 0, "", "")
+
+	muxWithRouteConf = probe.PackageConstraints{
+		Package: muxPkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + muxRouteConfMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using gorilla/mux is expected, the route is read from net/http
+		// then.
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	// chiRoutePatternsMinVersion is the first version of chi v5. Prior major
+	// versions are different modules, they are not instrumented.
+	chiRoutePatternsMinVersion = semver.New(5, 0, 0, "", "")
+
+	chiWithRoutePatterns = probe.PackageConstraints{
+		Package: chiPkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + chiRoutePatternsMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using chi is expected, the route is read from net/http then.
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	// twirpStatusCodeMinVersion is the first version of Twirp known to set
+	// the status of the responses of its generated servers in their context.
+	twirpStatusCodeMinVersion = semver.New(5, 12, 1, "", "")
+
+	twirpWithStatusCode = probe.PackageConstraints{
+		Package: twirpPkg,
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + twirpStatusCodeMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using Twirp is expected, the request is an HTTP one then.
+		FailureMode: probe.FailureModeIgnore,
+	}
+
+	// grpcGatewayMinVersion is the first version of grpc-gateway v2. Prior
+	// major versions are different modules, they are not instrumented.
+	grpcGatewayMinVersion = semver.New(2, 0, 0, "", "")
+
+	grpcGatewayV2 = probe.PackageConstraints{
+		Package: "github.com/grpc-ecosystem/grpc-gateway/v2",
+		Constraints: func() *semver.Constraints {
+			c, err := semver.NewConstraint(">= " + grpcGatewayMinVersion.String())
+			if err != nil {
+				panic(err)
+			}
+			return c
+		}(),
+		// Not using grpc-gateway is expected, the request is an HTTP one
+		// then.
+		FailureMode: probe.FailureModeIgnore,
+	}
 )
 
 // New returns a new [probe.Probe].
@@ -54,11 +196,17 @@ func New(logger *slog.Logger, version string) probe.Probe {
 		SpanKind:        trace.SpanKindServer,
 		InstrumentedPkg: pkg,
 	}
+	endUser, err := enduser.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid end user configuration, capture disabled", "error", err)
+	}
+	p := &processor{endUser: endUser}
 	return &probe.SpanProducer[bpfObjects, event]{
 		Base: probe.Base[bpfObjects, event]{
 			ID:     id,
 			Logger: logger,
-			Consts: []probe.Const{
+			Consts: append([]probe.Const{
+				probe.BootClockConst{},
 				probe.StructFieldConst{
 					Key: "method_ptr_pos",
 					ID:  structfield.NewID("std", "net/http", "Request", "Method"),
@@ -127,10 +275,73 @@ func New(logger *slog.Logger, version string) probe.Probe {
 					},
 					MinVersion: patternPathMinVersion,
 				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "gin_context_full_path_pos",
+						ID:  structfield.NewID(ginPkg, ginPkg, "Context", "fullPath"),
+					},
+					MinVersion: ginFullPathMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "echo_context_path_pos",
+						ID:  structfield.NewID(echoPkg, echoPkg, "context", "path"),
+					},
+					MinVersion: echoPathMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "mux_route_match_route_pos",
+						ID:  structfield.NewID(muxPkg, muxPkg, "RouteMatch", "Route"),
+					},
+					MinVersion: muxRouteConfMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "mux_route_match_match_err_pos",
+						ID:  structfield.NewID(muxPkg, muxPkg, "RouteMatch", "MatchErr"),
+					},
+					MinVersion: muxRouteConfMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "mux_route_route_conf_pos",
+						ID:  structfield.NewID(muxPkg, muxPkg, "Route", "routeConf"),
+					},
+					MinVersion: muxRouteConfMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "mux_route_conf_regexp_pos",
+						ID:  structfield.NewID(muxPkg, muxPkg, "routeConf", "regexp"),
+					},
+					MinVersion: muxRouteConfMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "mux_route_regexp_group_path_pos",
+						ID:  structfield.NewID(muxPkg, muxPkg, "routeRegexpGroup", "path"),
+					},
+					MinVersion: muxRouteConfMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "mux_route_regexp_template_pos",
+						ID:  structfield.NewID(muxPkg, muxPkg, "routeRegexp", "template"),
+					},
+					MinVersion: muxRouteConfMinVersion,
+				},
+				probe.StructFieldConstOptional{
+					StructField: probe.StructFieldConst{
+						Key: "chi_context_route_patterns_pos",
+						ID:  structfield.NewID(chiPkg, chiPkg, "Context", "RoutePatterns"),
+					},
+					MinVersion: chiRoutePatternsMinVersion,
+				},
 				patternPathPublicSupportedConst{},
-				patternPathSupportedConst{},
+				patternPathSupportedConst{p: p},
 				swissMapsUsedConst{},
-			},
+			}, endUser.Consts()...),
 			Uprobes: []*probe.Uprobe{
 				{
 					Sym:         "net/http.serverHandler.ServeHTTP",
@@ -145,37 +356,139 @@ func New(logger *slog.Logger, version string) probe.Probe {
 					},
 					DependsOn: []string{"net/http.serverHandler.ServeHTTP"},
 				},
+				{
+					Sym:         ginPkg + ".(*Engine).handleHTTPRequest",
+					EntryProbe:  "uprobe_Engine_handleHTTPRequest",
+					ReturnProbe: "uprobe_Engine_handleHTTPRequest_Returns",
+					PackageConstraints: []probe.PackageConstraints{
+						ginWithFullPath,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         echoPkg + ".(*Router).Find",
+					EntryProbe:  "uprobe_Router_Find",
+					ReturnProbe: "uprobe_Router_Find_Returns",
+					PackageConstraints: []probe.PackageConstraints{
+						echoWithPath,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         muxPkg + ".(*Router).Match",
+					EntryProbe:  "uprobe_Router_Match",
+					ReturnProbe: "uprobe_Router_Match_Returns",
+					PackageConstraints: []probe.PackageConstraints{
+						muxWithRouteConf,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         chiPkg + ".(*node).FindRoute",
+					EntryProbe:  "uprobe_node_FindRoute",
+					ReturnProbe: "uprobe_node_FindRoute_Returns",
+					PackageConstraints: []probe.PackageConstraints{
+						chiWithRoutePatterns,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// The route is read once the handler found is invoked,
+					// after all the mounted routers found their route.
+					Sym:         chiPkg + ".(*Mux).routeHTTP",
+					ReturnProbe: "uprobe_Mux_routeHTTP_Returns",
+					PackageConstraints: []probe.PackageConstraints{
+						chiWithRoutePatterns,
+					},
+					DependsOn:   []string{chiPkg + ".(*node).FindRoute"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// The generated servers call the runtime to set the
+					// status of each response they write, their own symbols
+					// depend on the services.
+					Sym:        twirpPkg + "/ctxsetters.WithStatusCode",
+					EntryProbe: "uprobe_WithStatusCode",
+					PackageConstraints: []probe.PackageConstraints{
+						twirpWithStatusCode,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:        twirpPkg + ".ServerHTTPStatusFromErrorCode",
+					EntryProbe: "uprobe_ServerHTTPStatusFromErrorCode",
+					PackageConstraints: []probe.PackageConstraints{
+						twirpWithStatusCode,
+					},
+					DependsOn:   []string{twirpPkg + "/ctxsetters.WithStatusCode"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// Called by the generated handlers of the requests
+					// proxied to a gRPC server.
+					Sym:        grpcGatewayPkg + ".AnnotateContext",
+					EntryProbe: "uprobe_AnnotateContext",
+					PackageConstraints: []probe.PackageConstraints{
+						grpcGatewayV2,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// Called instead by the generated handlers of the
+					// requests handled by a gRPC service in process.
+					Sym:        grpcGatewayPkg + ".AnnotateIncomingContext",
+					EntryProbe: "uprobe_AnnotateContext",
+					PackageConstraints: []probe.PackageConstraints{
+						grpcGatewayV2,
+					},
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// Not using gRPC, or only with its own transport, is
+					// expected.
+					Sym:         grpcPkg + ".(*Server).ServeHTTP",
+					EntryProbe:  "uprobe_Server_ServeHTTP",
+					DependsOn:   []string{"net/http.serverHandler.ServeHTTP"},
+					FailureMode: probe.FailureModeIgnore,
+				},
 			},
-			SpecFn: loadBpf,
+			SpecFn:        loadBpf,
+			ProcessRecord: enduser.Decode[event],
 		},
 		Version:   version,
 		SchemaURL: semconv.SchemaURL,
-		ProcessFn: processFn,
+		ProcessFn: p.processFn,
 	}
 }
 
 type patternPathPublicSupportedConst struct{}
 
-var (
-	patternPathPublicMinVersion  = semver.New(1, 23, 0, "", "")
-	isPatternPathPublicSupported = false
-)
+var patternPathPublicMinVersion = semver.New(1, 23, 0, "", "")
 
 func (c patternPathPublicSupportedConst) InjectOption(info *process.Info) (inject.Option, error) {
-	isPatternPathPublicSupported = info.GoVersion.GreaterThanEqual(patternPathPublicMinVersion)
-	return inject.WithKeyValue("pattern_path_public_supported", isPatternPathPublicSupported), nil
+	supported := info.GoVersion.GreaterThanEqual(patternPathPublicMinVersion)
+	return inject.WithKeyValue("pattern_path_public_supported", supported), nil
 }
 
-type patternPathSupportedConst struct{}
+// patternPathSupportedConst is a Probe Const defining if the patterns of the
+// requests are read. It is recorded in the processor of the probe, so the
+// events of the processes of other Go versions are not affected.
+type patternPathSupportedConst struct {
+	p *processor
+}
 
-var (
-	patternPathMinVersion  = semver.New(1, 22, 0, "", "")
-	isPatternPathSupported = false
-)
+var patternPathMinVersion = semver.New(1, 22, 0, "", "")
 
 func (c patternPathSupportedConst) InjectOption(info *process.Info) (inject.Option, error) {
-	isPatternPathSupported = info.GoVersion.GreaterThanEqual(patternPathMinVersion)
-	return inject.WithKeyValue("pattern_path_supported", isPatternPathSupported), nil
+	c.p.patternPath = info.GoVersion.GreaterThanEqual(patternPathMinVersion)
+	return inject.WithKeyValue("pattern_path_supported", c.p.patternPath), nil
 }
 
 type swissMapsUsedConst struct{}
@@ -193,15 +506,45 @@ type event struct {
 	Method      [8]byte
 	Path        [128]byte
 	PathPattern [128]byte
-	RemoteAddr  [256]byte
-	Host        [256]byte
-	Proto       [8]byte
+	// Route is the route template of the handler of the router matching the
+	// request (e.g. "/users/:id" for Gin or Echo, "/users/{id}" for
+	// gorilla/mux or chi), if any.
+	Route [128]byte
+	// GRPCGatewayRoute is the path template of the grpc-gateway handler
+	// proxying the request (e.g. "/v1/users/{id}"), if any. It is only known
+	// for grpc-gateway v2.5.0 and later.
+	GRPCGatewayRoute [128]byte
+	// GRPCGatewayMethod is the full gRPC method the request is proxied to by
+	// grpc-gateway (e.g. "/example.UserService/GetUser"), if any.
+	GRPCGatewayMethod [128]byte
+	RemoteAddr        [256]byte
+	Host              [256]byte
+	Proto             [8]byte
+	// TwirpErrorCode is the code of the error returned by the Twirp service
+	// handling the request, if Twirp is set.
+	TwirpErrorCode [32]byte
+	Twirp          uint8
+	// PathTruncated is not zero if Path was truncated to its 128 bytes.
+	PathTruncated uint8
+	_             [6]byte // padding
+	TraceState    context.TraceState
+	// EndUser is only output by the eBPF program if the capture of the end
+	// user identity is enabled.
+	EndUser enduser.Value
 }
 
-func processFn(e *event) ptrace.SpanSlice {
+type processor struct {
+	endUser enduser.Config
+	// patternPath is true if the patterns of the requests are read for the
+	// Go version of the target.
+	patternPath bool
+}
+
+func (p *processor) processFn(e *event) ptrace.SpanSlice {
 	path := unix.ByteSliceToString(e.Path[:])
 	method := unix.ByteSliceToString(e.Method[:])
 	patternPath := unix.ByteSliceToString(e.PathPattern[:])
+	route := unix.ByteSliceToString(e.Route[:])
 
 	isValidPatternPath := true
 	patternPath, err := http.ParsePattern(patternPath)
@@ -223,24 +566,19 @@ func processFn(e *event) ptrace.SpanSlice {
 			int(e.StatusCode),
 		), // nolint: gosec  // Bound checked.
 	}
-
-	// Client address and port
-	peerAddr, peerPort := http.NetPeerAddressPortAttributes(e.RemoteAddr[:])
-	if peerAddr.Valid() {
-		attrs = append(attrs, peerAddr)
-	}
-	if peerPort.Valid() {
-		attrs = append(attrs, peerPort)
+	if e.PathTruncated != 0 {
+		attrs = append(attrs, pathTruncatedKey.Bool(true))
 	}
 
-	// Server address and port
-	serverAddr, serverPort := http.ServerAddressPortAttributes(e.Host[:])
-	if serverAddr.Valid() {
-		attrs = append(attrs, serverAddr)
-	}
-	if serverPort.Valid() {
-		attrs = append(attrs, serverPort)
+	server := netattr.ParseHostPort(unix.ByteSliceToString(e.Host[:]))
+	peer := netattr.ParseHostPort(unix.ByteSliceToString(e.RemoteAddr[:]))
+	attrs = append(attrs, netattr.Attributes(server, peer)...)
+	if kv := peer.NetworkType(); kv.Valid() {
+		attrs = append(attrs, kv)
+	} else if kv := server.NetworkType(); kv.Valid() {
+		attrs = append(attrs, kv)
 	}
+	attrs = append(attrs, p.endUser.Attributes(&e.EndUser)...)
 
 	if proto != "" {
 		parts := strings.Split(proto, "/")
@@ -252,8 +590,55 @@ func processFn(e *event) ptrace.SpanSlice {
 		}
 	}
 
+	twirpErrorCode := unix.ByteSliceToString(e.TwirpErrorCode[:])
+	service, rpcMethod, isTwirp := "", "", false
+	if e.Twirp != 0 {
+		service, rpcMethod, isTwirp = parseTwirpPath(path)
+	}
+	if isTwirp {
+		attrs = append(attrs,
+			semconv.RPCSystemKey.String("twirp"),
+			semconv.RPCService(service),
+			semconv.RPCMethod(rpcMethod),
+		)
+		if twirpErrorCode != "" {
+			attrs = append(attrs, twirpErrorCodeKey.String(twirpErrorCode))
+		}
+	}
+
+	// The route of the grpc-gateway handler is set when its runtime proxies
+	// the request, the generated handlers always set a template starting with
+	// a slash.
+	gatewayRoute := unix.ByteSliceToString(e.GRPCGatewayRoute[:])
+	if !strings.HasPrefix(gatewayRoute, "/") {
+		gatewayRoute = ""
+	}
+	gatewayMethod := unix.ByteSliceToString(e.GRPCGatewayMethod[:])
+	if service, rpcMethod, ok := parseGRPCMethod(gatewayMethod); ok && !isTwirp {
+		attrs = append(attrs,
+			semconv.RPCSystemGRPC,
+			semconv.RPCService(service),
+			semconv.RPCMethod(rpcMethod),
+		)
+	}
+
 	spanName := method
-	if isPatternPathSupported && isValidPatternPath {
+	switch {
+	case isTwirp:
+		// The route of a Twirp service is its method, e.g.
+		// "example.Haberdasher/MakeHat".
+		spanName = service + "/" + rpcMethod
+	case gatewayRoute != "":
+		// The grpc-gateway runtime is mounted in net/http or a router, its
+		// handler is the one serving the request.
+		spanName = spanName + " " + gatewayRoute
+		attrs = append(attrs, semconv.HTTPRouteKey.String(gatewayRoute))
+	case route != "":
+		// A router (e.g. Gin, Echo, gorilla/mux or chi) matches the request
+		// after the net/http pattern does, its route is the most specific.
+		spanName = spanName + " " + route
+		attrs = append(attrs, semconv.HTTPRouteKey.String(route))
+	case p.patternPath && isValidPatternPath:
 		spanName = spanName + " " + patternPath
 		attrs = append(attrs, semconv.HTTPRouteKey.String(patternPath))
 	}
@@ -271,12 +656,53 @@ func processFn(e *event) ptrace.SpanSlice {
 	if e.ParentSpanContext.SpanID.IsValid() {
 		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
 	}
+	span.TraceState().FromRaw(e.TraceState.String())
 
 	pdataconv.Attributes(span.Attributes(), attrs...)
 
 	if e.StatusCode >= 500 && e.StatusCode < 600 {
 		span.Status().SetCode(ptrace.StatusCodeError)
 	}
+	if isTwirp {
+		// Same as the gRPC status codes of server errors.
+		switch twirpErrorCode {
+		case "unknown", "deadline_exceeded", "unimplemented", "internal",
+			"unavailable", "data_loss":
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+	}
 
 	return spans
 }
+
+// parseTwirpPath returns the fully qualified service and the method of the
+// path of a request to a Twirp server, e.g. "example.Haberdasher" and
+// "MakeHat" for "/twirp/example.Haberdasher/MakeHat". The prefix of the path
+// is configurable, only its last two segments are used.
+func parseTwirpPath(path string) (service, method string, ok bool) {
+	i := strings.LastIndexByte(path, '/')
+	if i < 0 {
+		return "", "", false
+	}
+	path, method = path[:i], path[i+1:]
+	service = path[strings.LastIndexByte(path, '/')+1:]
+	if service == "" || method == "" {
+		return "", "", false
+	}
+	return service, method, true
+}
+
+// parseGRPCMethod returns the fully qualified service and the method of a full
+// gRPC method, e.g. "example.UserService" and "GetUser" for
+// "/example.UserService/GetUser".
+func parseGRPCMethod(fullMethod string) (service, method string, ok bool) {
+	fullMethod, ok = strings.CutPrefix(fullMethod, "/")
+	if !ok {
+		return "", "", false
+	}
+	service, method, ok = strings.Cut(fullMethod, "/")
+	if !ok || service == "" || method == "" {
+		return "", "", false
+	}
+	return service, method, true
+}
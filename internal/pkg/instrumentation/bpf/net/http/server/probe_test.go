@@ -4,19 +4,26 @@
 package server
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 	"go.opentelemetry.io/otel/trace"
 
+	"go.opentelemetry.io/auto/internal/pkg/inject"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/enduser"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
 )
 
 func TestProbeConvertEvent(t *testing.T) {
@@ -79,12 +86,61 @@ func TestProbeConvertEvent(t *testing.T) {
 					semconv.NetworkPeerPort(8080),
 					semconv.ServerAddress("localhost"),
 					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
 				)
 
 				return spans
 			}(),
 		},
+		{
+			name: "router route",
+			event: &event{
+				BaseSpanProperties: context.BaseSpanProperties{
+					StartTime:   startOffset,
+					EndTime:     endOffset,
+					SpanContext: context.EBPFSpanContext{TraceID: traceID, SpanID: spanID},
+				},
+				StatusCode: 200,
+				// "GET"
+				Method: [8]byte{0x47, 0x45, 0x54},
+				// "/users/42"
+				Path: [128]byte{0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x34, 0x32},
+				// "/users/:id"
+				Route: [128]byte{0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f, 0x3a, 0x69, 0x64},
+				// "localhost:8080"
+				Host: [256]byte{
+					0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x68, 0x6f, 0x73, 0x74, 0x3a,
+					0x38, 0x30, 0x38, 0x30, 0x0,
+				},
+				// "HTTP/1.1"
+				Proto: [8]byte{0x48, 0x54, 0x54, 0x50, 0x2f, 0x31, 0x2e, 0x31},
+			},
+			expected: func() ptrace.SpanSlice {
+				spans := ptrace.NewSpanSlice()
+				span := spans.AppendEmpty()
+				span.SetName("GET /users/:id")
+				span.SetKind(ptrace.SpanKindServer)
+				span.SetStartTimestamp(kernel.BootOffsetToTimestamp(startOffset))
+				span.SetEndTimestamp(kernel.BootOffsetToTimestamp(endOffset))
+				span.SetTraceID(pcommon.TraceID(traceID))
+				span.SetSpanID(pcommon.SpanID(spanID))
+				span.SetFlags(uint32(trace.FlagsSampled))
+				pdataconv.Attributes(
+					span.Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLPath("/users/42"),
+					semconv.HTTPResponseStatusCodeKey.Int(200),
+					semconv.ServerAddress("localhost"),
+					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
+					semconv.NetworkProtocolVersion("1.1"),
+					semconv.HTTPRouteKey.String("/users/:id"),
+				)
+
+				return spans
+			}(),
+		},
 		{
 			name: "proto name added when not HTTP",
 			event: &event{
@@ -130,6 +186,7 @@ func TestProbeConvertEvent(t *testing.T) {
 					semconv.NetworkPeerPort(8080),
 					semconv.ServerAddress("localhost"),
 					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolName("FOO"),
 					semconv.NetworkProtocolVersion("2.2"),
 				)
@@ -182,6 +239,7 @@ func TestProbeConvertEvent(t *testing.T) {
 					semconv.NetworkPeerPort(8080),
 					semconv.ServerAddress("localhost"),
 					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
 				)
 
@@ -234,9 +292,85 @@ func TestProbeConvertEvent(t *testing.T) {
 					semconv.NetworkPeerPort(8080),
 					semconv.ServerAddress("localhost"),
 					semconv.ServerPort(8080),
+					semconv.NetworkTransportTCP,
 					semconv.NetworkProtocolVersion("1.1"),
 				)
 
+				return spans
+			}(),
+		},
+		{
+			name: "tracestate from remote parent",
+			event: &event{
+				BaseSpanProperties: context.BaseSpanProperties{
+					StartTime:         startOffset,
+					EndTime:           endOffset,
+					SpanContext:       context.EBPFSpanContext{TraceID: traceID, SpanID: spanID},
+					ParentSpanContext: context.EBPFSpanContext{TraceID: traceID, SpanID: trace.SpanID{2}},
+				},
+				StatusCode: 200,
+				// "GET"
+				Method: [8]byte{0x47, 0x45, 0x54},
+				// "/foo/bar"
+				Path:       [128]byte{0x2f, 0x66, 0x6f, 0x6f, 0x2f, 0x62, 0x61, 0x72},
+				TraceState: newTraceState("vendor=value,other=1"),
+			},
+			expected: func() ptrace.SpanSlice {
+				spans := ptrace.NewSpanSlice()
+				span := spans.AppendEmpty()
+				span.SetName("GET")
+				span.SetKind(ptrace.SpanKindServer)
+				span.SetStartTimestamp(kernel.BootOffsetToTimestamp(startOffset))
+				span.SetEndTimestamp(kernel.BootOffsetToTimestamp(endOffset))
+				span.SetTraceID(pcommon.TraceID(traceID))
+				span.SetSpanID(pcommon.SpanID(spanID))
+				span.SetParentSpanID(pcommon.SpanID{2})
+				span.SetFlags(uint32(trace.FlagsSampled))
+				span.TraceState().FromRaw("vendor=value,other=1")
+				pdataconv.Attributes(
+					span.Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLPath("/foo/bar"),
+					semconv.HTTPResponseStatusCodeKey.Int(200),
+				)
+
+				return spans
+			}(),
+		},
+		{
+			name: "malformed tracestate dropped",
+			event: &event{
+				BaseSpanProperties: context.BaseSpanProperties{
+					StartTime:         startOffset,
+					EndTime:           endOffset,
+					SpanContext:       context.EBPFSpanContext{TraceID: traceID, SpanID: spanID},
+					ParentSpanContext: context.EBPFSpanContext{TraceID: traceID, SpanID: trace.SpanID{2}},
+				},
+				StatusCode: 200,
+				// "GET"
+				Method: [8]byte{0x47, 0x45, 0x54},
+				// "/foo/bar"
+				Path:       [128]byte{0x2f, 0x66, 0x6f, 0x6f, 0x2f, 0x62, 0x61, 0x72},
+				TraceState: newTraceState("Vendor=value"),
+			},
+			expected: func() ptrace.SpanSlice {
+				spans := ptrace.NewSpanSlice()
+				span := spans.AppendEmpty()
+				span.SetName("GET")
+				span.SetKind(ptrace.SpanKindServer)
+				span.SetStartTimestamp(kernel.BootOffsetToTimestamp(startOffset))
+				span.SetEndTimestamp(kernel.BootOffsetToTimestamp(endOffset))
+				span.SetTraceID(pcommon.TraceID(traceID))
+				span.SetSpanID(pcommon.SpanID(spanID))
+				span.SetParentSpanID(pcommon.SpanID{2})
+				span.SetFlags(uint32(trace.FlagsSampled))
+				pdataconv.Attributes(
+					span.Attributes(),
+					semconv.HTTPRequestMethodKey.String("GET"),
+					semconv.URLPath("/foo/bar"),
+					semconv.HTTPResponseStatusCodeKey.Int(200),
+				)
+
 				return spans
 			}(),
 		},
@@ -244,8 +378,346 @@ func TestProbeConvertEvent(t *testing.T) {
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			out := processFn(tt.event)
+			out := (&processor{}).processFn(tt.event)
 			assert.Equal(t, tt.expected, out)
 		})
 	}
 }
+
+func newTraceState(s string) context.TraceState {
+	var ts context.TraceState
+	ts.Len = uint64(copy(ts.Value[:], s))
+	return ts
+}
+
+func TestProbeConvertEventEndUser(t *testing.T) {
+	newEvent := func(id string) *event {
+		e := &event{
+			BaseSpanProperties: context.BaseSpanProperties{
+				SpanContext: context.EBPFSpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}},
+			},
+			Method: [8]byte{'G', 'E', 'T'},
+		}
+		e.EndUser.Len = uint64(copy(e.EndUser.Value[:], id))
+		return e
+	}
+
+	getID := func(t *testing.T, p *processor, e *event) (string, bool) {
+		t.Helper()
+		spans := p.processFn(e)
+		assert.Equal(t, 1, spans.Len())
+		v, ok := spans.At(0).Attributes().Get(string(enduser.IDKey))
+		return v.Str(), ok
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		_, ok := getID(t, &processor{}, newEvent("alice"))
+		assert.False(t, ok, "enduser.id set when disabled")
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		p := &processor{endUser: enduser.Config{Header: "x-user-id"}}
+		id, ok := getID(t, p, newEvent("alice"))
+		assert.True(t, ok, "enduser.id not set")
+		assert.Equal(t, "alice", id)
+
+		_, ok = getID(t, p, newEvent(""))
+		assert.False(t, ok, "enduser.id set without captured header")
+	})
+}
+
+func TestProbeConvertEventNetworkType(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		remoteAddr string
+		wantPeer   any
+		wantType   any
+	}{
+		{
+			name:       "IPv4",
+			host:       "example.com",
+			remoteAddr: "10.0.0.5:43512",
+			wantPeer:   "10.0.0.5",
+			wantType:   "ipv4",
+		},
+		{
+			name:       "IPv4Mapped",
+			host:       "example.com",
+			remoteAddr: "[::ffff:10.0.0.5]:43512",
+			wantPeer:   "10.0.0.5",
+			wantType:   "ipv4",
+		},
+		{
+			name:       "IPv6",
+			host:       "example.com",
+			remoteAddr: "[2001:db8::1]:43512",
+			wantPeer:   "2001:db8::1",
+			wantType:   "ipv6",
+		},
+		{
+			name:     "ServerIP",
+			host:     "[::1]:8080",
+			wantType: "ipv6",
+		},
+		{
+			name: "DomainName",
+			host: "example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &event{
+				BaseSpanProperties: context.BaseSpanProperties{
+					SpanContext: context.EBPFSpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}},
+				},
+				Method: [8]byte{'G', 'E', 'T'},
+			}
+			copy(e.Host[:], tt.host)
+			copy(e.RemoteAddr[:], tt.remoteAddr)
+
+			spans := (&processor{}).processFn(e)
+			require.Equal(t, 1, spans.Len())
+			attrs := spans.At(0).Attributes().AsRaw()
+			assert.Equal(t, tt.wantPeer, attrs[string(semconv.NetworkPeerAddressKey)])
+			assert.Equal(t, tt.wantType, attrs[string(semconv.NetworkTypeKey)])
+		})
+	}
+}
+
+func TestProbeConvertEventPathTruncated(t *testing.T) {
+	e := &event{Method: [8]byte{'G', 'E', 'T'}}
+	copy(e.Path[:], "/"+strings.Repeat("a", len(e.Path)-1))
+
+	attrs := (&processor{}).processFn(e).At(0).Attributes().AsRaw()
+	assert.NotContains(t, attrs, string(pathTruncatedKey))
+
+	e.PathTruncated = 1
+	attrs = (&processor{}).processFn(e).At(0).Attributes().AsRaw()
+	assert.Equal(t, "/"+strings.Repeat("a", len(e.Path)-1), attrs[string(semconv.URLPathKey)])
+	assert.Equal(t, true, attrs[string(pathTruncatedKey)])
+}
+
+func TestProbeConvertEventTwirp(t *testing.T) {
+	newEvent := func(path, code string, status uint64) *event {
+		e := &event{
+			BaseSpanProperties: context.BaseSpanProperties{
+				SpanContext: context.EBPFSpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}},
+			},
+			StatusCode: status,
+			Method:     [8]byte{'P', 'O', 'S', 'T'},
+			Twirp:      1,
+		}
+		copy(e.Path[:], path)
+		copy(e.TwirpErrorCode[:], code)
+		return e
+	}
+
+	p := &processor{}
+
+	t.Run("Success", func(t *testing.T) {
+		spans := p.processFn(newEvent("/twirp/example.Haberdasher/MakeHat", "", 200))
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, "example.Haberdasher/MakeHat", span.Name())
+		assert.Equal(t, ptrace.StatusCodeUnset, span.Status().Code())
+
+		attrs := span.Attributes().AsRaw()
+		assert.Equal(t, "twirp", attrs[string(semconv.RPCSystemKey)])
+		assert.Equal(t, "example.Haberdasher", attrs[string(semconv.RPCServiceKey)])
+		assert.Equal(t, "MakeHat", attrs[string(semconv.RPCMethodKey)])
+		assert.NotContains(t, attrs, string(twirpErrorCodeKey))
+	})
+
+	t.Run("ClientError", func(t *testing.T) {
+		spans := p.processFn(newEvent("/twirp/example.Haberdasher/MakeHat", "invalid_argument", 400))
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, ptrace.StatusCodeUnset, span.Status().Code())
+		assert.Equal(t, "invalid_argument", span.Attributes().AsRaw()[string(twirpErrorCodeKey)])
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		// Deadlines exceeded are not HTTP server errors.
+		spans := p.processFn(newEvent("/twirp/example.Haberdasher/MakeHat", "deadline_exceeded", 408))
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+		assert.Equal(t, "deadline_exceeded", span.Attributes().AsRaw()[string(twirpErrorCodeKey)])
+	})
+
+	t.Run("NotTwirp", func(t *testing.T) {
+		e := newEvent("/twirp/example.Haberdasher/MakeHat", "", 200)
+		e.Twirp = 0
+		spans := p.processFn(e)
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, "POST", span.Name())
+		assert.NotContains(t, span.Attributes().AsRaw(), string(semconv.RPCSystemKey))
+	})
+}
+
+func TestParseTwirpPath(t *testing.T) {
+	service, method, ok := parseTwirpPath("/twirp/example.Haberdasher/MakeHat")
+	assert.True(t, ok)
+	assert.Equal(t, "example.Haberdasher", service)
+	assert.Equal(t, "MakeHat", method)
+
+	// Custom prefix.
+	service, method, ok = parseTwirpPath("/api/v1/example.Haberdasher/MakeHat")
+	assert.True(t, ok)
+	assert.Equal(t, "example.Haberdasher", service)
+	assert.Equal(t, "MakeHat", method)
+
+	_, _, ok = parseTwirpPath("/twirp/example.Haberdasher/")
+	assert.False(t, ok)
+
+	_, _, ok = parseTwirpPath("MakeHat")
+	assert.False(t, ok)
+}
+
+func TestProbeConvertEventGRPCGateway(t *testing.T) {
+	newEvent := func(route, gatewayRoute, gatewayMethod string) *event {
+		e := &event{StatusCode: 200}
+		copy(e.Method[:], "GET")
+		copy(e.Path[:], "/v1/users/42")
+		copy(e.Route[:], route)
+		copy(e.GRPCGatewayRoute[:], gatewayRoute)
+		copy(e.GRPCGatewayMethod[:], gatewayMethod)
+		return e
+	}
+
+	p := &processor{}
+
+	t.Run("Success", func(t *testing.T) {
+		// The route of a router the gateway is mounted in is less specific.
+		spans := p.processFn(newEvent("/v1/*", "/v1/users/{id}", "/example.UserService/GetUser"))
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, "GET /v1/users/{id}", span.Name())
+
+		attrs := span.Attributes().AsRaw()
+		assert.Equal(t, "/v1/users/{id}", attrs[string(semconv.HTTPRouteKey)])
+		assert.Equal(t, "grpc", attrs[string(semconv.RPCSystemKey)])
+		assert.Equal(t, "example.UserService", attrs[string(semconv.RPCServiceKey)])
+		assert.Equal(t, "GetUser", attrs[string(semconv.RPCMethodKey)])
+	})
+
+	t.Run("NoPathPattern", func(t *testing.T) {
+		// Versions prior to v2.5.0 do not pass the path template.
+		spans := p.processFn(newEvent("", "", "/example.UserService/GetUser"))
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, "GET", span.Name())
+
+		attrs := span.Attributes().AsRaw()
+		assert.NotContains(t, attrs, string(semconv.HTTPRouteKey))
+		assert.Equal(t, "example.UserService", attrs[string(semconv.RPCServiceKey)])
+		assert.Equal(t, "GetUser", attrs[string(semconv.RPCMethodKey)])
+	})
+
+	t.Run("InvalidPathPattern", func(t *testing.T) {
+		spans := p.processFn(newEvent("/v1/*", "v1/users", "/example.UserService/GetUser"))
+		require.Equal(t, 1, spans.Len())
+		span := spans.At(0)
+		assert.Equal(t, "GET /v1/*", span.Name())
+		assert.Equal(t, "/v1/*", span.Attributes().AsRaw()[string(semconv.HTTPRouteKey)])
+	})
+
+	t.Run("NotGRPCGateway", func(t *testing.T) {
+		spans := p.processFn(newEvent("", "", ""))
+		require.Equal(t, 1, spans.Len())
+		assert.NotContains(t, spans.At(0).Attributes().AsRaw(), string(semconv.RPCSystemKey))
+	})
+}
+
+func TestParseGRPCMethod(t *testing.T) {
+	service, method, ok := parseGRPCMethod("/example.UserService/GetUser")
+	assert.True(t, ok)
+	assert.Equal(t, "example.UserService", service)
+	assert.Equal(t, "GetUser", method)
+
+	_, _, ok = parseGRPCMethod("example.UserService/GetUser")
+	assert.False(t, ok)
+
+	_, _, ok = parseGRPCMethod("/example.UserService/")
+	assert.False(t, ok)
+
+	_, _, ok = parseGRPCMethod("")
+	assert.False(t, ok)
+}
+
+func TestTwirpConstraints(t *testing.T) {
+	for _, v := range []string{"5.12.1+incompatible", "7.2.0+incompatible", "8.1.3+incompatible"} {
+		assert.True(t, twirpWithStatusCode.Constraints.Check(semver.MustParse(v)), "%s not instrumented", v)
+	}
+	assert.False(t, twirpWithStatusCode.Constraints.Check(semver.MustParse("5.10.0")), "5.10.0 instrumented")
+}
+
+func TestRouterOffsets(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []structfield.ID
+		pc       probe.PackageConstraints
+		versions []string
+		excluded []string
+	}{
+		{
+			name:     "Gin",
+			ids:      []structfield.ID{structfield.NewID(ginPkg, ginPkg, "Context", "fullPath")},
+			pc:       ginWithFullPath,
+			versions: []string{"1.5.0", "1.9.1", "1.10.1"},
+			excluded: []string{"1.4.0"},
+		},
+		{
+			// The fields of the context were reordered in 4.12.
+			name:     "Echo",
+			ids:      []structfield.ID{structfield.NewID(echoPkg, echoPkg, "context", "path")},
+			pc:       echoWithPath,
+			versions: []string{"4.10.1", "4.11.0", "4.11.4", "4.12.0"},
+			excluded: []string{"4.9.1", "4.10.0"},
+		},
+		{
+			name: "gorilla/mux",
+			ids: []structfield.ID{
+				structfield.NewID(muxPkg, muxPkg, "RouteMatch", "Route"),
+				structfield.NewID(muxPkg, muxPkg, "RouteMatch", "MatchErr"),
+				structfield.NewID(muxPkg, muxPkg, "Route", "routeConf"),
+				structfield.NewID(muxPkg, muxPkg, "routeConf", "regexp"),
+				structfield.NewID(muxPkg, muxPkg, "routeRegexpGroup", "path"),
+				structfield.NewID(muxPkg, muxPkg, "routeRegexp", "template"),
+			},
+			pc:       muxWithRouteConf,
+			versions: []string{"1.7.0", "1.7.4", "1.8.1"},
+			excluded: []string{"1.6.2"},
+		},
+		{
+			name:     "chi",
+			ids:      []structfield.ID{structfield.NewID(chiPkg, chiPkg, "Context", "RoutePatterns")},
+			pc:       chiWithRoutePatterns,
+			versions: []string{"5.0.0", "5.0.12", "5.2.3"},
+			excluded: []string{"4.1.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range tt.versions {
+				ver := semver.MustParse(v)
+				assert.True(t, tt.pc.Constraints.Check(ver), "%s not instrumented", v)
+
+				for _, id := range tt.ids {
+					off, ok := inject.GetOffset(id, ver)
+					if assert.True(t, ok, "offset of %s not known for %s", id, v) {
+						assert.True(t, off.Valid, "invalid offset of %s for %s", id, v)
+					}
+				}
+			}
+			for _, v := range tt.excluded {
+				ver := semver.MustParse(v)
+				assert.False(t, tt.pc.Constraints.Check(ver), "%s instrumented", v)
+			}
+		})
+	}
+}
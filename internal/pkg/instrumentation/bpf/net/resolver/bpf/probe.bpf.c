@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_HOST_SIZE 128
+#define MAX_ERROR_SIZE 128
+
+#define RESOLVER_UNKNOWN 0
+#define RESOLVER_GO 1
+#define RESOLVER_CGO 2
+
+struct dns_lookup_t {
+    BASE_SPAN_PROPERTIES
+    // The host looked up.
+    char host[MAX_HOST_SIZE];
+    // The message of the DNSError of the lookup, if has_error is set and the
+    // error is known to be a DNSError.
+    char error[MAX_ERROR_SIZE];
+    // The number of addresses the host resolved to.
+    u64 addr_count;
+    // The resolver that looked up the host: RESOLVER_GO or RESOLVER_CGO.
+    u8 resolver;
+    u8 has_error;
+    u8 is_dns_error;
+    u8 is_not_found;
+    u8 is_timeout;
+    u8 padding[3];
+};
+
+struct dns_host_t {
+    char host[MAX_HOST_SIZE];
+};
+
+// Lookups in progress, keyed by the goroutine looking them up.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct dns_lookup_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} dns_lookups SEC(".maps");
+
+// The resolver of the last lookup of a host. Lookups are done in a goroutine
+// shared by all the concurrent lookups of the same host, not by the goroutine
+// of the lookups.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, struct dns_host_t);
+    __type(value, u8);
+    __uint(max_entries, MAX_CONCURRENT);
+} dns_resolvers SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct dns_lookup_t));
+    __uint(max_entries, 1);
+} dns_lookup_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 dns_error_err_pos;
+volatile const u64 dns_error_is_timeout_pos;
+volatile const u64 dns_error_is_not_found_pos;
+// Whether all the errors of lookups are a *DNSError. Before Go 1.23, lookups
+// canceled by their context return the error of the context.
+volatile const bool dns_error_wrapped;
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IPAddr, error)
+SEC("uprobe/Resolver_lookupIPAddr")
+int uprobe_Resolver_lookupIPAddr(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+
+    u32 zero = 0;
+    struct dns_lookup_t *lookup = bpf_map_lookup_elem(&dns_lookup_storage_map, &zero);
+    if (lookup == NULL) {
+        bpf_printk("uprobe/Resolver_lookupIPAddr: lookup is NULL");
+        return 0;
+    }
+    __builtin_memset(lookup, 0, sizeof(struct dns_lookup_t));
+    lookup->start_time = get_time_ns();
+
+    void *host_ptr = get_argument(ctx, 6);
+    u64 host_len = (u64)get_argument(ctx, 7);
+    u64 size = MAX_HOST_SIZE - 1 < host_len ? MAX_HOST_SIZE - 1 : host_len;
+    bpf_probe_read_user(lookup->host, size, host_ptr);
+
+    // The span of the lookup is a child of the span of the context it is made
+    // with: the one of the client request dialing the host.
+    struct go_iface go_context = {0};
+    get_Go_context(ctx, 2, 0, true, &go_context);
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &lookup->psc,
+        .sc = &lookup->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&dns_lookups, &key, lookup, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (r *Resolver) lookupIPAddr(ctx context.Context, network, host string) ([]IPAddr, error)
+SEC("uprobe/Resolver_lookupIPAddr")
+int uprobe_Resolver_lookupIPAddr_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct dns_lookup_t *lookup = bpf_map_lookup_elem(&dns_lookups, &key);
+    if (lookup == NULL) {
+        return 0;
+    }
+    lookup->end_time = end_time;
+    lookup->addr_count = (u64)get_argument(ctx, 2);
+
+    struct dns_host_t host = {0};
+    __builtin_memcpy(host.host, lookup->host, sizeof(host.host));
+    u8 *resolver = bpf_map_lookup_elem(&dns_resolvers, &host);
+    if (resolver != NULL) {
+        lookup->resolver = *resolver;
+    }
+
+    void *err_type = get_argument(ctx, 4);
+    void *err_ptr = get_argument(ctx, 5);
+    if (err_type != NULL) {
+        lookup->has_error = 1;
+        if (dns_error_wrapped && err_ptr != NULL) {
+            lookup->is_dns_error = 1;
+            get_go_string_from_user_ptr((void *)(err_ptr + dns_error_err_pos), lookup->error, sizeof(lookup->error));
+            bpf_probe_read_user(&lookup->is_timeout, sizeof(lookup->is_timeout), (void *)(err_ptr + dns_error_is_timeout_pos));
+            bpf_probe_read_user(&lookup->is_not_found, sizeof(lookup->is_not_found), (void *)(err_ptr + dns_error_is_not_found_pos));
+        }
+    }
+
+    // Addresses are returned without being looked up if the host is an IP
+    // address: there is no lookup to report.
+    if (lookup->resolver != RESOLVER_UNKNOWN || lookup->has_error) {
+        output_span_event(ctx, lookup, sizeof(*lookup), &lookup->sc);
+    }
+
+    bpf_map_delete_elem(&dns_lookups, &key);
+    return 0;
+}
+
+static __always_inline void record_resolver(void *host_ptr, u64 host_len, u8 resolver) {
+    struct dns_host_t host = {0};
+    u64 size = MAX_HOST_SIZE - 1 < host_len ? MAX_HOST_SIZE - 1 : host_len;
+    bpf_probe_read_user(host.host, size, host_ptr);
+    bpf_map_update_elem(&dns_resolvers, &host, &resolver, 0);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (r *Resolver) goLookupIPCNAMEOrder(ctx context.Context, network, name string, order hostLookupOrder, conf *dnsConfig) (addrs []IPAddr, cname dnsmessage.Name, err error)
+SEC("uprobe/Resolver_goLookupIPCNAMEOrder")
+int uprobe_Resolver_goLookupIPCNAMEOrder(struct pt_regs *ctx) {
+    record_resolver(get_argument(ctx, 6), (u64)get_argument(ctx, 7), RESOLVER_GO);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func cgoLookupIP(ctx context.Context, network, name string) (addrs []IPAddr, err error)
+SEC("uprobe/cgoLookupIP")
+int uprobe_cgoLookupIP(struct pt_regs *ctx) {
+    record_resolver(get_argument(ctx, 5), (u64)get_argument(ctx, 6), RESOLVER_CGO);
+    return 0;
+}
@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package resolver provides an instrumentation probe for the DNS lookups of
+// [net.Resolver].
+package resolver
+
+import (
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "net"
+
+const (
+	// addressCountKey is the attribute key of the number of addresses a host
+	// resolved to.
+	addressCountKey = attribute.Key("dns.address.count")
+	// resolverKey is the attribute key of the resolver that looked up a
+	// host: "go" for the pure Go resolver, or "cgo" for the one of the C
+	// library.
+	resolverKey = attribute.Key("dns.resolver")
+)
+
+// Resolvers of the lookups, as reported by the eBPF program.
+const (
+	resolverUnknown uint8 = iota
+	resolverGo
+	resolverCgo
+)
+
+// Values of the error.type attribute of failed lookups, following the ones
+// of the dns.lookup.duration metric.
+const (
+	errorTypeNotFound = "host_not_found"
+	errorTypeTimeout  = "timeout"
+)
+
+// New returns a new [probe.Probe].
+//
+// Spans are produced for the lookups of the IP addresses of hosts, the ones
+// of [net.Dialer] and [net.Resolver.LookupIPAddr]. They are the children of
+// the span of the context of the lookup, the one of the client request
+// dialing the host.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	const lookupIPAddr = pkg + ".(*Resolver).lookupIPAddr"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "dns_error_err_pos",
+					ID:  structfield.NewID("std", pkg, "DNSError", "Err"),
+				},
+				probe.StructFieldConst{
+					Key: "dns_error_is_timeout_pos",
+					ID:  structfield.NewID("std", pkg, "DNSError", "IsTimeout"),
+				},
+				probe.StructFieldConst{
+					Key: "dns_error_is_not_found_pos",
+					ID:  structfield.NewID("std", pkg, "DNSError", "IsNotFound"),
+				},
+				dnsErrorWrappedConst{},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         lookupIPAddr,
+					EntryProbe:  "uprobe_Resolver_lookupIPAddr",
+					ReturnProbe: "uprobe_Resolver_lookupIPAddr_Returns",
+				},
+				{
+					Sym:        pkg + ".(*Resolver).goLookupIPCNAMEOrder",
+					EntryProbe: "uprobe_Resolver_goLookupIPCNAMEOrder",
+					DependsOn:  []string{lookupIPAddr},
+				},
+				{
+					// Only linked in the binaries built with cgo.
+					Sym:         pkg + ".cgoLookupIP",
+					EntryProbe:  "uprobe_cgoLookupIP",
+					DependsOn:   []string{lookupIPAddr},
+					FailureMode: probe.FailureModeIgnore,
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// dnsErrorWrappedMinVersion is the first version of Go returning a
+// [net.DNSError] for lookups canceled by their context.
+var dnsErrorWrappedMinVersion = semver.New(1, 23, 0, "", "")
+
+type dnsErrorWrappedConst struct{}
+
+func (c dnsErrorWrappedConst) InjectOption(info *process.Info) (inject.Option, error) {
+	wrapped := info.GoVersion.GreaterThanEqual(dnsErrorWrappedMinVersion)
+	return inject.WithKeyValue("dns_error_wrapped", wrapped), nil
+}
+
+// event represents the lookup of the IP addresses of a host.
+type event struct {
+	context.BaseSpanProperties
+	Host [128]byte
+	// Error is the message of the DNSError of the lookup, only valid if
+	// IsDNSError is set.
+	Error        [128]byte
+	AddressCount uint64
+	Resolver     uint8
+	HasError     uint8
+	IsDNSError   uint8
+	IsNotFound   uint8
+	IsTimeout    uint8
+	_            [3]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := []attribute.KeyValue{
+		semconv.DNSQuestionName(unix.ByteSliceToString(e.Host[:])),
+	}
+	if e.HasError == 0 {
+		attrs = append(attrs, addressCountKey.Int64(int64(e.AddressCount))) // nolint: gosec  // Bound by memory.
+	}
+	switch e.Resolver {
+	case resolverGo:
+		attrs = append(attrs, resolverKey.String("go"))
+	case resolverCgo:
+		attrs = append(attrs, resolverKey.String("cgo"))
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("DNS lookup")
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		// Before Go 1.23, the error of a lookup canceled by its context is
+		// not a DNSError, and is not read.
+		if e.IsDNSError != 0 {
+			switch {
+			case e.IsNotFound != 0:
+				attrs = append(attrs, semconv.ErrorTypeKey.String(errorTypeNotFound))
+			case e.IsTimeout != 0:
+				attrs = append(attrs, semconv.ErrorTypeKey.String(errorTypeTimeout))
+			default:
+				attrs = append(attrs, semconv.ErrorTypeOther)
+			}
+			if msg := unix.ByteSliceToString(e.Error[:]); utf8.ValidString(msg) {
+				span.Status().SetMessage(msg)
+			}
+		} else {
+			attrs = append(attrs, semconv.ErrorTypeOther)
+		}
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(resolver uint8, count uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			AddressCount:       count,
+			Resolver:           resolver,
+		}
+		copy(e.Host[:], "example.com")
+		return e
+	}
+
+	newDNSErrorEvent := func(msg string, notFound, timeout bool) *event {
+		e := newEvent(resolverGo, 0)
+		e.HasError = 1
+		e.IsDNSError = 1
+		copy(e.Error[:], msg)
+		if notFound {
+			e.IsNotFound = 1
+		}
+		if timeout {
+			e.IsTimeout = 1
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "go resolver",
+			event: newEvent(resolverGo, 2),
+			want: f.Spans(
+				"DNS lookup",
+				ptrace.StatusCodeUnset,
+				semconv.DNSQuestionName("example.com"),
+				addressCountKey.Int64(2),
+				resolverKey.String("go"),
+			),
+		},
+		{
+			name:  "cgo resolver",
+			event: newEvent(resolverCgo, 1),
+			want: f.Spans(
+				"DNS lookup",
+				ptrace.StatusCodeUnset,
+				semconv.DNSQuestionName("example.com"),
+				addressCountKey.Int64(1),
+				resolverKey.String("cgo"),
+			),
+		},
+		{
+			name:  "unknown resolver",
+			event: newEvent(resolverUnknown, 1),
+			want: f.Spans(
+				"DNS lookup",
+				ptrace.StatusCodeUnset,
+				semconv.DNSQuestionName("example.com"),
+				addressCountKey.Int64(1),
+			),
+		},
+		{
+			name:  "not found",
+			event: newDNSErrorEvent("no such host", true, false),
+			want: f.ErrorSpans(
+				"DNS lookup",
+				"no such host",
+				semconv.DNSQuestionName("example.com"),
+				resolverKey.String("go"),
+				semconv.ErrorTypeKey.String(errorTypeNotFound),
+			),
+		},
+		{
+			name:  "timeout",
+			event: newDNSErrorEvent("i/o timeout", false, true),
+			want: f.ErrorSpans(
+				"DNS lookup",
+				"i/o timeout",
+				semconv.DNSQuestionName("example.com"),
+				resolverKey.String("go"),
+				semconv.ErrorTypeKey.String(errorTypeTimeout),
+			),
+		},
+		{
+			name:  "other DNS error",
+			event: newDNSErrorEvent("server misbehaving", false, false),
+			want: f.ErrorSpans(
+				"DNS lookup",
+				"server misbehaving",
+				semconv.DNSQuestionName("example.com"),
+				resolverKey.String("go"),
+				semconv.ErrorTypeOther,
+			),
+		},
+		{
+			name: "context error",
+			event: func() *event {
+				e := newEvent(resolverUnknown, 0)
+				e.HasError = 1
+				return e
+			}(),
+			want: f.Spans(
+				"DNS lookup",
+				ptrace.StatusCodeError,
+				semconv.DNSQuestionName("example.com"),
+				semconv.ErrorTypeOther,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_SERVICE_METHOD_SIZE 128
+#define MAX_ERROR_SIZE 128
+
+struct rpc_client_call_t {
+    BASE_SPAN_PROPERTIES
+    // The "Service.Method" of the call.
+    char service_method[MAX_SERVICE_METHOD_SIZE];
+    // The message of the error of the call, if has_error is set.
+    char error[MAX_ERROR_SIZE];
+    u8 has_error;
+    u8 padding[7];
+};
+
+// Calls in progress, keyed by their Call. The calls are sent by the goroutine
+// calling them, and done by the goroutine reading the responses of the client.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct rpc_client_call_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} rpc_client_calls SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct rpc_client_call_t));
+    __uint(max_entries, 1);
+} rpc_client_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 call_service_method_pos;
+volatile const u64 call_error_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (client *Client) send(call *Call)
+SEC("uprobe/Client_send")
+int uprobe_Client_send(struct pt_regs *ctx) {
+    void *call_ptr = get_argument(ctx, 2);
+    if (call_ptr == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct rpc_client_call_t *call = bpf_map_lookup_elem(&rpc_client_storage_map, &zero);
+    if (call == NULL) {
+        bpf_printk("uprobe/Client_send: call is NULL");
+        return 0;
+    }
+    __builtin_memset(call, 0, sizeof(struct rpc_client_call_t));
+    call->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr((void *)(call_ptr + call_service_method_pos), call->service_method, sizeof(call->service_method));
+
+    // Calls are not made with a context, and the protocol has no headers: the
+    // span of a call is the root of its trace.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &call->psc,
+        .sc = &call->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&rpc_client_calls, &call_ptr, call, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (call *Call) done()
+SEC("uprobe/Call_done")
+int uprobe_Call_done(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *call_ptr = get_argument(ctx, 1);
+    struct rpc_client_call_t *call = bpf_map_lookup_elem(&rpc_client_calls, &call_ptr);
+    if (call == NULL) {
+        return 0;
+    }
+    call->end_time = end_time;
+
+    // The errors of calls are a ServerError with the error string of the
+    // response, or an *errors.errorString. The data of both starts with the
+    // message of the error.
+    struct go_iface err = {0};
+    bpf_probe_read_user(&err, sizeof(err), (void *)(call_ptr + call_error_pos));
+    if (err.type != NULL) {
+        call->has_error = 1;
+        get_go_string_from_user_ptr(err.data, call->error, sizeof(call->error));
+    }
+
+    output_span_event(ctx, call, sizeof(*call), &call->sc);
+    bpf_map_delete_elem(&rpc_client_calls, &call_ptr);
+    return 0;
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package client provides an instrumentation probe for [net/rpc] clients.
+package client
+
+import (
+	"log/slog"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "net/rpc"
+
+// New returns a new [probe.Probe].
+//
+// Calls are not made with a context, and the protocol of net/rpc has no
+// headers to propagate one with: the span of a call is the root of its trace.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	const send = pkg + ".(*Client).send"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "call_service_method_pos",
+					ID:  structfield.NewID("std", pkg, "Call", "ServiceMethod"),
+				},
+				probe.StructFieldConst{
+					Key: "call_error_pos",
+					ID:  structfield.NewID("std", pkg, "Call", "Error"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					// Both Call and Go send their call with it.
+					Sym:        send,
+					EntryProbe: "uprobe_Client_send",
+				},
+				{
+					Sym:        pkg + ".(*Call).done",
+					EntryProbe: "uprobe_Call_done",
+					DependsOn:  []string{send},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a call made by a Client, from the time it is sent to the
+// time it is done.
+type event struct {
+	context.BaseSpanProperties
+	ServiceMethod [128]byte
+	// Error is the message of the error of the call, only valid if HasError
+	// is set.
+	Error    [128]byte
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	serviceMethod := unix.ByteSliceToString(e.ServiceMethod[:])
+	attrs := rpc.Attributes(serviceMethod)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(rpc.SpanName(serviceMethod))
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		// The message is only known for the errors of the responses, and the
+		// ones of the package. Other errors are not read as a string.
+		if msg := unix.ByteSliceToString(e.Error[:]); utf8.ValidString(msg) {
+			span.Status().SetMessage(msg)
+		}
+	}
+
+	return spans
+}
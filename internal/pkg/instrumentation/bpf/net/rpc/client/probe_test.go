@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+
+	newEvent := func(serviceMethod, errMsg string) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.ServiceMethod[:], serviceMethod)
+		if errMsg != "" {
+			e.HasError = 1
+			copy(e.Error[:], errMsg)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "success",
+			event: newEvent("Arith.Multiply", ""),
+			want: f.Spans(
+				"Arith/Multiply",
+				ptrace.StatusCodeUnset,
+				rpc.SystemNetRPC,
+				semconv.RPCService("Arith"),
+				semconv.RPCMethod("Multiply"),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("Arith.Divide", "division by zero"),
+			want: f.ErrorSpans(
+				"Arith/Divide",
+				"division by zero",
+				rpc.SystemNetRPC,
+				semconv.RPCService("Arith"),
+				semconv.RPCMethod("Divide"),
+			),
+		},
+		{
+			name:  "unreadable error",
+			event: newEvent("Arith.Divide", "\xff\xfe"),
+			want: f.Spans(
+				"Arith/Divide",
+				ptrace.StatusCodeError,
+				rpc.SystemNetRPC,
+				semconv.RPCService("Arith"),
+				semconv.RPCMethod("Divide"),
+			),
+		},
+		{
+			name:  "invalid service method",
+			event: newEvent("Multiply", ""),
+			want:  f.Spans("Multiply", ptrace.StatusCodeUnset, rpc.SystemNetRPC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
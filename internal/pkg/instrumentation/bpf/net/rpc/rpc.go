@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package rpc provides common functionality for [net/rpc] probe
+// instrumentation.
+package rpc
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+// SystemNetRPC is the rpc.system attribute of net/rpc. It is not defined by
+// the semantic conventions.
+var SystemNetRPC = semconv.RPCSystemKey.String("go_net_rpc")
+
+// SplitServiceMethod returns the service and the method of the ServiceMethod
+// of a call, e.g. "Arith" and "Multiply" for "Arith.Multiply". Like the
+// Server, it splits it at its last dot.
+func SplitServiceMethod(serviceMethod string) (service, method string, ok bool) {
+	i := strings.LastIndexByte(serviceMethod, '.')
+	if i < 0 {
+		return "", "", false
+	}
+	service, method = serviceMethod[:i], serviceMethod[i+1:]
+	if service == "" || method == "" {
+		return "", "", false
+	}
+	return service, method, true
+}
+
+// Attributes returns the attributes of the spans of a call of serviceMethod.
+func Attributes(serviceMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{SystemNetRPC}
+	if service, method, ok := SplitServiceMethod(serviceMethod); ok {
+		attrs = append(attrs, semconv.RPCService(service), semconv.RPCMethod(method))
+	}
+	return attrs
+}
+
+// SpanName returns the name of the spans of a call of serviceMethod, e.g.
+// "Arith/Multiply" for "Arith.Multiply". It is the ServiceMethod if it is
+// not valid.
+func SpanName(serviceMethod string) string {
+	service, method, ok := SplitServiceMethod(serviceMethod)
+	if !ok {
+		if serviceMethod == "" {
+			return "net/rpc"
+		}
+		return serviceMethod
+	}
+	return service + "/" + method
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+func TestSplitServiceMethod(t *testing.T) {
+	tests := []struct {
+		serviceMethod string
+		service       string
+		method        string
+		ok            bool
+	}{
+		{serviceMethod: "Arith.Multiply", service: "Arith", method: "Multiply", ok: true},
+		{serviceMethod: "v1.Arith.Multiply", service: "v1.Arith", method: "Multiply", ok: true},
+		{serviceMethod: "Multiply"},
+		{serviceMethod: "Arith."},
+		{serviceMethod: ".Multiply"},
+		{serviceMethod: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serviceMethod, func(t *testing.T) {
+			service, method, ok := SplitServiceMethod(tt.serviceMethod)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.service, service)
+			assert.Equal(t, tt.method, method)
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	assert.Equal(t, []attribute.KeyValue{
+		SystemNetRPC,
+		semconv.RPCService("Arith"),
+		semconv.RPCMethod("Multiply"),
+	}, Attributes("Arith.Multiply"))
+	assert.Equal(t, []attribute.KeyValue{SystemNetRPC}, Attributes("Multiply"))
+}
+
+func TestSpanName(t *testing.T) {
+	assert.Equal(t, "Arith/Multiply", SpanName("Arith.Multiply"))
+	assert.Equal(t, "Multiply", SpanName("Multiply"))
+	assert.Equal(t, "net/rpc", SpanName(""))
+}
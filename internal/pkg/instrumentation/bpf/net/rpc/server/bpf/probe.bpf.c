@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_SERVICE_METHOD_SIZE 128
+#define MAX_ERROR_SIZE 128
+
+struct rpc_server_request_t {
+    BASE_SPAN_PROPERTIES
+    // The "Service.Method" of the request.
+    char service_method[MAX_SERVICE_METHOD_SIZE];
+    // The error of the response, if has_error is set.
+    char error[MAX_ERROR_SIZE];
+    u8 has_error;
+    u8 padding[7];
+};
+
+// Requests being served, keyed by their Request. The requests are read by the
+// goroutine serving the codec, and their responses written by the goroutine
+// calling the method.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct rpc_server_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} rpc_server_requests SEC(".maps");
+
+// Responses being written, keyed by the goroutine writing them.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct rpc_server_request_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} rpc_server_responses SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct rpc_server_request_t));
+    __uint(max_entries, 1);
+} rpc_server_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 request_service_method_pos;
+
+// This instrumentation attaches uprobe to the following function:
+// func (server *Server) readRequestHeader(codec ServerCodec) (svc *service, mtype *methodType, req *Request, keepReading bool, err error)
+SEC("uprobe/Server_readRequestHeader")
+int uprobe_Server_readRequestHeader_Returns(struct pt_regs *ctx) {
+    // The request is nil if its header could not be read.
+    void *req_ptr = get_argument(ctx, 3);
+    if (req_ptr == NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct rpc_server_request_t *req = bpf_map_lookup_elem(&rpc_server_storage_map, &zero);
+    if (req == NULL) {
+        bpf_printk("uprobe/Server_readRequestHeader: req is NULL");
+        return 0;
+    }
+    __builtin_memset(req, 0, sizeof(struct rpc_server_request_t));
+    // Reading the header waits for the request, the request is being served
+    // once it is read.
+    req->start_time = get_time_ns();
+
+    get_go_string_from_user_ptr((void *)(req_ptr + request_service_method_pos), req->service_method, sizeof(req->service_method));
+
+    // The protocol has no headers, the span of a request is the root of its
+    // trace.
+    struct go_iface go_context = {0};
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = &go_context,
+        .psc = &req->psc,
+        .sc = &req->sc,
+        .get_parent_span_context_fn = NULL,
+        .get_parent_span_context_arg = NULL,
+    };
+    start_span(&start_span_params);
+
+    bpf_map_update_elem(&rpc_server_requests, &req_ptr, req, 0);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (server *Server) sendResponse(sending *sync.Mutex, req *Request, reply any, codec ServerCodec, errmsg string)
+SEC("uprobe/Server_sendResponse")
+int uprobe_Server_sendResponse(struct pt_regs *ctx) {
+    void *req_ptr = get_argument(ctx, 3);
+    struct rpc_server_request_t *req = bpf_map_lookup_elem(&rpc_server_requests, &req_ptr);
+    if (req == NULL) {
+        return 0;
+    }
+
+    // The error returned by the method, or the one of a request that could
+    // not be dispatched.
+    void *errmsg_ptr = get_argument(ctx, 8);
+    u64 errmsg_len = (u64)get_argument(ctx, 9);
+    if (errmsg_len > 0) {
+        req->has_error = 1;
+        u64 size = MAX_ERROR_SIZE - 1 < errmsg_len ? MAX_ERROR_SIZE - 1 : errmsg_len;
+        bpf_probe_read_user(req->error, size, errmsg_ptr);
+    }
+
+    // The request is freed, and reused, once its response is written.
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&rpc_server_responses, &key, req, 0);
+    bpf_map_delete_elem(&rpc_server_requests, &req_ptr);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (server *Server) sendResponse(sending *sync.Mutex, req *Request, reply any, codec ServerCodec, errmsg string)
+SEC("uprobe/Server_sendResponse")
+int uprobe_Server_sendResponse_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct rpc_server_request_t *req = bpf_map_lookup_elem(&rpc_server_responses, &key);
+    if (req == NULL) {
+        return 0;
+    }
+    req->end_time = end_time;
+    output_span_event(ctx, req, sizeof(*req), &req->sc);
+    bpf_map_delete_elem(&rpc_server_responses, &key);
+    return 0;
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package server provides an instrumentation probe for [net/rpc] servers.
+package server
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "net/rpc"
+
+// New returns a new [probe.Probe].
+//
+// The protocol of net/rpc has no headers to propagate a trace context with:
+// the span of a request is the root of its trace.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindServer,
+		InstrumentedPkg: pkg,
+	}
+
+	const readRequestHeader = pkg + ".(*Server).readRequestHeader"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "request_service_method_pos",
+					ID:  structfield.NewID("std", pkg, "Request", "ServiceMethod"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:         readRequestHeader,
+					ReturnProbe: "uprobe_Server_readRequestHeader_Returns",
+				},
+				{
+					Sym:         pkg + ".(*Server).sendResponse",
+					EntryProbe:  "uprobe_Server_sendResponse",
+					ReturnProbe: "uprobe_Server_sendResponse_Returns",
+					DependsOn:   []string{readRequestHeader},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a request served by a Server, from the time its header
+// is read to the time its response is written.
+type event struct {
+	context.BaseSpanProperties
+	ServiceMethod [128]byte
+	// Error is the error string of the response, only valid if HasError is
+	// set.
+	Error    [128]byte
+	HasError uint8
+	_        [7]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	serviceMethod := unix.ByteSliceToString(e.ServiceMethod[:])
+	attrs := rpc.Attributes(serviceMethod)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(rpc.SpanName(serviceMethod))
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		span.Status().SetMessage(unix.ByteSliceToString(e.Error[:]))
+	}
+
+	return spans
+}
@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindServer)
+
+	newEvent := func(serviceMethod, errMsg string) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+		}
+		copy(e.ServiceMethod[:], serviceMethod)
+		if errMsg != "" {
+			e.HasError = 1
+			copy(e.Error[:], errMsg)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "success",
+			event: newEvent("Arith.Multiply", ""),
+			want: f.Spans(
+				"Arith/Multiply",
+				ptrace.StatusCodeUnset,
+				rpc.SystemNetRPC,
+				semconv.RPCService("Arith"),
+				semconv.RPCMethod("Multiply"),
+			),
+		},
+		{
+			name:  "error",
+			event: newEvent("Arith.Divide", "rpc: can't find method Arith.Divide"),
+			want: f.ErrorSpans(
+				"Arith/Divide",
+				"rpc: can't find method Arith.Divide",
+				rpc.SystemNetRPC,
+				semconv.RPCService("Arith"),
+				semconv.RPCMethod("Divide"),
+			),
+		},
+		{
+			name:  "invalid service method",
+			event: newEvent("Multiply", ""),
+			want:  f.Spans("Multiply", ptrace.StatusCodeUnset, rpc.SystemNetRPC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
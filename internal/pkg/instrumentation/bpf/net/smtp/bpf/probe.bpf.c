@@ -0,0 +1,394 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_context.h"
+#include "go_types.h"
+#include "goroutine_spans.h"
+#include "uprobe.h"
+#include "trace/span_output.h"
+#include "trace/start_span.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_CONCURRENT 50
+#define MAX_CLIENTS 1024
+#define MAX_ADDR_SIZE 256
+
+struct smtp_send_t {
+    BASE_SPAN_PROPERTIES
+    // The address of the server, as passed to SendMail or Dial, or the host
+    // name of the server of a Client created with NewClient.
+    char addr[MAX_ADDR_SIZE];
+    u64 rcpt_count;
+    // The size of the message, only known for SendMail.
+    u64 msg_size;
+    u8 msg_size_known;
+    u8 has_error;
+    u8 padding[6];
+};
+
+// The calls to SendMail in progress, keyed by their goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, struct smtp_send_t);
+    __uint(max_entries, MAX_CONCURRENT);
+} smtp_send_mails SEC(".maps");
+
+// The conversations of the clients driven manually, from Client.Mail to the
+// close of the writer of Client.Data, keyed by their *Client. Conversations
+// never ended are evicted.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, struct smtp_send_t);
+    __uint(max_entries, MAX_CLIENTS);
+} smtp_conversations SEC(".maps");
+
+// The address dialed by the calls to Dial in progress, keyed by their
+// goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, char[MAX_ADDR_SIZE]);
+    __uint(max_entries, MAX_CONCURRENT);
+} smtp_dials SEC(".maps");
+
+// The address dialed by the clients returned by Dial, keyed by their *Client.
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __type(key, void *);
+    __type(value, char[MAX_ADDR_SIZE]);
+    __uint(max_entries, MAX_CLIENTS);
+} smtp_client_addrs SEC(".maps");
+
+// The client of the calls to Client.Mail, Client.Rcpt, Client.Data, and the
+// Close of the writer of Client.Data in progress, keyed by their goroutine.
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __type(key, void *);
+    __type(value, void *);
+    __uint(max_entries, MAX_CONCURRENT);
+} smtp_client_calls SEC(".maps");
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct smtp_send_t));
+    __uint(max_entries, 1);
+} smtp_storage_map SEC(".maps");
+
+// Injected in init
+volatile const u64 client_server_name_pos;
+
+// SendMail and the Client do not accept a context.Context, the spans are the
+// children of the span active in the goroutine sending the message, if any
+// (e.g. the one of the net/http server request handled).
+static __always_inline long get_goroutine_parent_span_context(void *goroutine, struct span_context *psc) {
+    struct span_context *sc = get_goroutine_span(goroutine);
+    if (sc == NULL) {
+        return -1;
+    }
+    *psc = *sc;
+    return 0;
+}
+
+static __always_inline void start_send_span(struct pt_regs *ctx, struct smtp_send_t *send) {
+    start_span_params_t start_span_params = {
+        .ctx = ctx,
+        .go_context = NULL,
+        .psc = &send->psc,
+        .sc = &send->sc,
+        .get_parent_span_context_fn = get_goroutine_parent_span_context,
+        .get_parent_span_context_arg = (void *)GOROUTINE(ctx),
+    };
+    start_span(&start_span_params);
+}
+
+// Reads the length of the msg argument of SendMail. The arguments preceding
+// it fill the 9 integer argument registers of amd64, it is passed on the
+// stack. It is passed in registers on arm64, which has 16 of them.
+static __always_inline u64 get_msg_len(struct pt_regs *ctx) {
+#if defined(bpf_target_x86)
+    struct go_slice msg = {0};
+    bpf_probe_read_user(&msg, sizeof(msg), get_stack_arguments(ctx));
+    return msg.len;
+#elif defined(bpf_target_arm64)
+    return (u64)__PT_REGS_CAST(ctx)->regs[10];
+#endif
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func SendMail(addr string, a Auth, from string, to []string, msg []byte) error
+SEC("uprobe/SendMail")
+int uprobe_SendMail(struct pt_regs *ctx) {
+    u32 zero = 0;
+    struct smtp_send_t *send = bpf_map_lookup_elem(&smtp_storage_map, &zero);
+    if (send == NULL) {
+        bpf_printk("uprobe/SendMail: send is NULL");
+        return 0;
+    }
+    __builtin_memset(send, 0, sizeof(struct smtp_send_t));
+    send->start_time = get_time_ns();
+
+    void *addr_ptr = get_argument(ctx, 1);
+    u64 addr_len = (u64)get_argument(ctx, 2);
+    u64 size = MAX_ADDR_SIZE - 1 < addr_len ? MAX_ADDR_SIZE - 1 : addr_len;
+    bpf_probe_read_user(send->addr, size, addr_ptr);
+
+    send->rcpt_count = (u64)get_argument(ctx, 8);
+    send->msg_size = get_msg_len(ctx);
+    send->msg_size_known = 1;
+
+    start_send_span(ctx, send);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&smtp_send_mails, &key, send, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func SendMail(addr string, a Auth, from string, to []string, msg []byte) error
+SEC("uprobe/SendMail")
+int uprobe_SendMail_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *key = (void *)GOROUTINE(ctx);
+    struct smtp_send_t *send = bpf_map_lookup_elem(&smtp_send_mails, &key);
+    if (send == NULL) {
+        return 0;
+    }
+    send->end_time = end_time;
+
+    // The errors of the server rejecting the message are *textproto.Error,
+    // the others are of various types. Their message is not read.
+    if (get_argument(ctx, 1) != NULL) {
+        send->has_error = 1;
+    }
+
+    output_span_event(ctx, send, sizeof(*send), &send->sc);
+    bpf_map_delete_elem(&smtp_send_mails, &key);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func Dial(addr string) (*Client, error)
+SEC("uprobe/Dial")
+int uprobe_Dial(struct pt_regs *ctx) {
+    u32 zero = 0;
+    struct smtp_send_t *storage = bpf_map_lookup_elem(&smtp_storage_map, &zero);
+    if (storage == NULL) {
+        bpf_printk("uprobe/Dial: storage is NULL");
+        return 0;
+    }
+    __builtin_memset(storage->addr, 0, sizeof(storage->addr));
+
+    void *addr_ptr = get_argument(ctx, 1);
+    u64 addr_len = (u64)get_argument(ctx, 2);
+    u64 size = MAX_ADDR_SIZE - 1 < addr_len ? MAX_ADDR_SIZE - 1 : addr_len;
+    bpf_probe_read_user(storage->addr, size, addr_ptr);
+
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&smtp_dials, &key, storage->addr, 0);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func Dial(addr string) (*Client, error)
+SEC("uprobe/Dial")
+int uprobe_Dial_Returns(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    char *addr = bpf_map_lookup_elem(&smtp_dials, &key);
+    if (addr == NULL) {
+        return 0;
+    }
+
+    void *client = get_argument(ctx, 1);
+    if (client != NULL) {
+        bpf_map_update_elem(&smtp_client_addrs, &client, addr, 0);
+    }
+
+    bpf_map_delete_elem(&smtp_dials, &key);
+    return 0;
+}
+
+// Sets client as the client of the call of the goroutine of ctx.
+static __always_inline void set_client_call(struct pt_regs *ctx, void *client) {
+    void *key = (void *)GOROUTINE(ctx);
+    bpf_map_update_elem(&smtp_client_calls, &key, &client, 0);
+}
+
+// Returns the conversation of the client of the call of the goroutine of ctx
+// returning, and sets its client to client. Returns NULL if the client is
+// not in a conversation.
+static __always_inline struct smtp_send_t *end_client_call(struct pt_regs *ctx, void **client) {
+    void *key = (void *)GOROUTINE(ctx);
+    void **client_ptr = bpf_map_lookup_elem(&smtp_client_calls, &key);
+    if (client_ptr == NULL) {
+        return NULL;
+    }
+    *client = *client_ptr;
+    bpf_map_delete_elem(&smtp_client_calls, &key);
+    return bpf_map_lookup_elem(&smtp_conversations, client);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Mail(from string) error
+SEC("uprobe/Client_Mail")
+int uprobe_Client_Mail(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    if (client == NULL) {
+        return 0;
+    }
+
+    // The conversations of SendMail are traced by its span.
+    void *key = (void *)GOROUTINE(ctx);
+    if (bpf_map_lookup_elem(&smtp_send_mails, &key) != NULL) {
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct smtp_send_t *send = bpf_map_lookup_elem(&smtp_storage_map, &zero);
+    if (send == NULL) {
+        bpf_printk("uprobe/Client_Mail: send is NULL");
+        return 0;
+    }
+    __builtin_memset(send, 0, sizeof(struct smtp_send_t));
+    send->start_time = get_time_ns();
+
+    char *addr = bpf_map_lookup_elem(&smtp_client_addrs, &client);
+    if (addr != NULL) {
+        __builtin_memcpy(send->addr, addr, sizeof(send->addr));
+    } else {
+        // Created by NewClient, only the host name of the server is known.
+        get_go_string_from_user_ptr(client + client_server_name_pos, send->addr, MAX_ADDR_SIZE - 1);
+    }
+
+    start_send_span(ctx, send);
+
+    // A new conversation replaces the one of the client never ended, if any.
+    bpf_map_update_elem(&smtp_conversations, &client, send, 0);
+    set_client_call(ctx, client);
+    return 0;
+}
+
+// Marks the conversation of the client of the call returning as failed if
+// err is not NULL.
+static __always_inline void end_command(struct pt_regs *ctx, void *err) {
+    void *client = NULL;
+    struct smtp_send_t *send = end_client_call(ctx, &client);
+    if (send != NULL && err != NULL) {
+        send->has_error = 1;
+    }
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (c *Client) Mail(from string) error
+SEC("uprobe/Client_Mail")
+int uprobe_Client_Mail_Returns(struct pt_regs *ctx) {
+    end_command(ctx, get_argument(ctx, 1));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Rcpt(to string) error
+SEC("uprobe/Client_Rcpt")
+int uprobe_Client_Rcpt(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    struct smtp_send_t *send = bpf_map_lookup_elem(&smtp_conversations, &client);
+    if (send == NULL) {
+        return 0;
+    }
+    send->rcpt_count++;
+    set_client_call(ctx, client);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (c *Client) Rcpt(to string) error
+SEC("uprobe/Client_Rcpt")
+int uprobe_Client_Rcpt_Returns(struct pt_regs *ctx) {
+    end_command(ctx, get_argument(ctx, 1));
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (c *Client) Data() (io.WriteCloser, error)
+SEC("uprobe/Client_Data")
+int uprobe_Client_Data(struct pt_regs *ctx) {
+    void *client = get_argument(ctx, 1);
+    if (bpf_map_lookup_elem(&smtp_conversations, &client) == NULL) {
+        return 0;
+    }
+    set_client_call(ctx, client);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (c *Client) Data() (io.WriteCloser, error)
+SEC("uprobe/Client_Data")
+int uprobe_Client_Data_Returns(struct pt_regs *ctx) {
+    end_command(ctx, get_argument(ctx, 3));
+    return 0;
+}
+
+// Ends the conversation of client, if any.
+static __always_inline void end_conversation(struct pt_regs *ctx, void *client, u64 end_time) {
+    struct smtp_send_t *send = bpf_map_lookup_elem(&smtp_conversations, &client);
+    if (send == NULL) {
+        return;
+    }
+    send->end_time = end_time;
+    output_span_event(ctx, send, sizeof(*send), &send->sc);
+    bpf_map_delete_elem(&smtp_conversations, &client);
+}
+
+// This instrumentation attaches uprobe to the following function:
+// func (d *dataCloser) Close() error
+SEC("uprobe/dataCloser_Close")
+int uprobe_dataCloser_Close(struct pt_regs *ctx) {
+    void *closer = get_argument(ctx, 1);
+    if (closer == NULL) {
+        return 0;
+    }
+    // The client is the first field of a dataCloser.
+    void *client = NULL;
+    bpf_probe_read_user(&client, sizeof(client), closer);
+    if (client == NULL || bpf_map_lookup_elem(&smtp_conversations, &client) == NULL) {
+        return 0;
+    }
+    set_client_call(ctx, client);
+    return 0;
+}
+
+// This instrumentation attaches uretprobe to the following function:
+// func (d *dataCloser) Close() error
+SEC("uprobe/dataCloser_Close")
+int uprobe_dataCloser_Close_Returns(struct pt_regs *ctx) {
+    u64 end_time = get_time_ns();
+    void *client = NULL;
+    struct smtp_send_t *send = end_client_call(ctx, &client);
+    if (send == NULL) {
+        return 0;
+    }
+    // The server rejects the message with the reply to its end.
+    if (get_argument(ctx, 1) != NULL) {
+        send->has_error = 1;
+    }
+    end_conversation(ctx, client, end_time);
+    return 0;
+}
+
+// This instrumentation attaches uprobe to the following functions:
+// func (c *Client) Reset() error
+// func (c *Client) Quit() error
+// func (c *Client) Close() error
+//
+// The conversations aborted, e.g. after a recipient is rejected, end when the
+// client is reset or closed.
+SEC("uprobe/Client_end")
+int uprobe_Client_end(struct pt_regs *ctx) {
+    end_conversation(ctx, get_argument(ctx, 1), get_time_ns());
+    return 0;
+}
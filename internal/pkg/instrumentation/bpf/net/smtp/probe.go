@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package smtp provides an instrumentation probe for the clients of the
+// [net/smtp] package.
+package smtp
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/netattr"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "net/smtp"
+
+const (
+	// rcptCountKey is the attribute key of the number of recipients of the
+	// message. The addresses of the recipients are not recorded.
+	rcptCountKey = attribute.Key("smtp.recipient.count")
+	// msgSizeKey is the attribute key of the size of the message, in bytes.
+	msgSizeKey = attribute.Key("smtp.message.size")
+)
+
+// New returns a new [probe.Probe].
+//
+// A span is produced for each message sent, covering the full SMTP
+// conversation: from the call to SendMail to its return, or from the call to
+// Client.Mail to the close of the writer returned by Client.Data for the
+// clients driven manually. The conversations aborted, e.g. after a recipient
+// is rejected, end when the client is reset, quit, or closed. SendMail and the
+// Client do not accept a context, the spans are the children of the span
+// active in the goroutine sending the message, e.g. the one of the net/http
+// or gRPC server request handled.
+func New(logger *slog.Logger, version string) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindClient,
+		InstrumentedPkg: pkg,
+	}
+
+	const clientMail = pkg + ".(*Client).Mail"
+
+	return &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.AllocationConst{},
+				probe.BootClockConst{},
+				probe.StructFieldConst{
+					Key: "client_server_name_pos",
+					ID:  structfield.NewID("std", pkg, "Client", "serverName"),
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					// Called by SendMail, and to send the messages of the
+					// clients driven manually.
+					Sym:         clientMail,
+					EntryProbe:  "uprobe_Client_Mail",
+					ReturnProbe: "uprobe_Client_Mail_Returns",
+				},
+				{
+					Sym:         pkg + ".SendMail",
+					EntryProbe:  "uprobe_SendMail",
+					ReturnProbe: "uprobe_SendMail_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					// Not linked if clients are only created with NewClient.
+					Sym:         pkg + ".Dial",
+					EntryProbe:  "uprobe_Dial",
+					ReturnProbe: "uprobe_Dial_Returns",
+					FailureMode: probe.FailureModeIgnore,
+				},
+				{
+					Sym:         pkg + ".(*Client).Rcpt",
+					EntryProbe:  "uprobe_Client_Rcpt",
+					ReturnProbe: "uprobe_Client_Rcpt_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{clientMail},
+				},
+				{
+					Sym:         pkg + ".(*Client).Data",
+					EntryProbe:  "uprobe_Client_Data",
+					ReturnProbe: "uprobe_Client_Data_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{clientMail},
+				},
+				{
+					Sym:         pkg + ".(*dataCloser).Close",
+					EntryProbe:  "uprobe_dataCloser_Close",
+					ReturnProbe: "uprobe_dataCloser_Close_Returns",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{clientMail},
+				},
+				{
+					Sym:         pkg + ".(*Client).Reset",
+					EntryProbe:  "uprobe_Client_end",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{clientMail},
+				},
+				{
+					Sym:         pkg + ".(*Client).Quit",
+					EntryProbe:  "uprobe_Client_end",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{clientMail},
+				},
+				{
+					Sym:         pkg + ".(*Client).Close",
+					EntryProbe:  "uprobe_Client_end",
+					FailureMode: probe.FailureModeIgnore,
+					DependsOn:   []string{clientMail},
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: processFn,
+	}
+}
+
+// event represents a message sent.
+type event struct {
+	context.BaseSpanProperties
+	// Addr is the address of the server, as passed to SendMail or Dial, or
+	// the host name of the server of a Client created with NewClient.
+	Addr      [256]byte
+	RcptCount uint64
+	// MsgSize is the size of the message, only known if MsgSizeKnown is set:
+	// for the messages sent with SendMail.
+	MsgSize      uint64
+	MsgSizeKnown uint8
+	HasError     uint8
+	_            [6]byte // padding
+}
+
+func processFn(e *event) ptrace.SpanSlice {
+	attrs := netattr.Attributes(
+		netattr.ParseHostPort(unix.ByteSliceToString(e.Addr[:])),
+		netattr.Addr{},
+	)
+	attrs = append(attrs, rcptCountKey.Int64(int64(e.RcptCount))) // nolint: gosec  // Bounded by the recipients.
+	if e.MsgSizeKnown != 0 {
+		attrs = append(attrs, msgSizeKey.Int64(int64(e.MsgSize))) // nolint: gosec  // Bounded by the message.
+	}
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("SMTP send")
+	span.SetKind(ptrace.SpanKindClient)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(e.StartTime))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(e.EndTime))
+	span.SetTraceID(pcommon.TraceID(e.SpanContext.TraceID))
+	span.SetSpanID(pcommon.SpanID(e.SpanContext.SpanID))
+	span.SetFlags(uint32(trace.FlagsSampled))
+
+	if e.ParentSpanContext.SpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(e.ParentSpanContext.SpanID))
+	}
+
+	if e.HasError != 0 {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		attrs = append(attrs, semconv.ErrorTypeOther)
+	}
+
+	pdataconv.Attributes(span.Attributes(), attrs...)
+
+	return spans
+}
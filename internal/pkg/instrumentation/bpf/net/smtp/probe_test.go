@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package smtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/probetest"
+)
+
+func TestProcessFn(t *testing.T) {
+	f := probetest.NewFixture(ptrace.SpanKindClient)
+	f.ParentSpanID = trace.SpanID{2}
+
+	newEvent := func(addr string, rcpts uint64) *event {
+		e := &event{
+			BaseSpanProperties: f.BaseSpanProperties(),
+			RcptCount:          rcpts,
+		}
+		copy(e.Addr[:], addr)
+		return e
+	}
+
+	sendMail := newEvent("mail.example.com:587", 3)
+	sendMail.MsgSize = 1024
+	sendMail.MsgSizeKnown = 1
+
+	rejected := newEvent("mail.example.com:25", 1)
+	rejected.HasError = 1
+
+	tests := []struct {
+		name  string
+		event *event
+		want  ptrace.SpanSlice
+	}{
+		{
+			name:  "SendMail",
+			event: sendMail,
+			want: f.Spans(
+				"SMTP send",
+				ptrace.StatusCodeUnset,
+				semconv.ServerAddress("mail.example.com"),
+				semconv.ServerPort(587),
+				semconv.NetworkTransportTCP,
+				rcptCountKey.Int64(3),
+				msgSizeKey.Int64(1024),
+			),
+		},
+		{
+			name:  "NewClient",
+			event: newEvent("mail.example.com", 2),
+			want: f.Spans(
+				"SMTP send",
+				ptrace.StatusCodeUnset,
+				semconv.ServerAddress("mail.example.com"),
+				semconv.NetworkTransportTCP,
+				rcptCountKey.Int64(2),
+			),
+		},
+		{
+			name:  "rejected",
+			event: rejected,
+			want: f.Spans(
+				"SMTP send",
+				ptrace.StatusCodeError,
+				semconv.ServerAddress("mail.example.com"),
+				semconv.ServerPort(25),
+				semconv.NetworkTransportTCP,
+				rcptCountKey.Int64(1),
+				semconv.ErrorTypeOther,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, processFn(tt.event))
+		})
+	}
+}
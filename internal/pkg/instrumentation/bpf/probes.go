@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bpf is the registry of the probes instrumenting Go packages with
+// eBPF programs. The probes are implemented in its sub-packages, named after
+// the package they instrument.
+package bpf
+
+import (
+	"log/slog"
+
+	pubsubConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/pubsub/consumer"
+	pubsubProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/pubsub/producer"
+	spannerClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/spanner"
+	cryptoTLS "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/crypto/tls"
+	dbSql "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	gqlgen "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/99designs/gqlgen"
+	clickhouseClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/ClickHouse/clickhouse-go"
+	saramaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/IBM/sarama/consumer"
+	saramaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/IBM/sarama/producer"
+	awsClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/aws/aws-sdk-go-v2"
+	memcacheClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/bradfitz/gomemcache"
+	confluentConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/confluentinc/confluent-kafka-go/consumer"
+	confluentProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/confluentinc/confluent-kafka-go/producer"
+	badgerDB "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/dgraph-io/badger"
+	pahoV5Producer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.golang/paho"
+	pahoConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.mqtt.golang/consumer"
+	pahoProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.mqtt.golang/producer"
+	esClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/elastic/go-elasticsearch"
+	gocqlClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/gocql/gocql"
+	gorillaWebsocket "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/gorilla/websocket"
+	vaultClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hashicorp/vault/api"
+	asynqConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hibiken/asynq/consumer"
+	asynqProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hibiken/asynq/producer"
+	influxdbClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/influxdata/influxdb-client-go"
+	pgxClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/jackc/pgx"
+	natsConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/consumer"
+	natsJetstream "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/jetstream"
+	natsProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/producer"
+	rabbitmqConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/rabbitmq/amqp091-go/consumer"
+	rabbitmqProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/rabbitmq/amqp091-go/producer"
+	redisClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/redis/go-redis"
+	rueidisClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/redis/rueidis"
+	kafkaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/consumer"
+	kafkaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/producer"
+	fasthttpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/valyala/fasthttp/client"
+	fasthttpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/valyala/fasthttp/server"
+	bboltTx "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.etcd.io/bbolt"
+	etcdClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.etcd.io/etcd/client"
+	mongoClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.mongodb.org/mongo-driver"
+	autosdk "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/auto/sdk"
+	otelTrace "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/otel/trace"
+	otelTraceGlobal "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/otel/traceglobal"
+	temporalClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.temporal.io/sdk"
+	logZap "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.uber.org/zap"
+	sshClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/golang.org/x/crypto/ssh"
+	http2Server "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/golang.org/x/net/http2"
+	grpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/client"
+	grpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/server"
+	k8sRest "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/k8s.io/client-go/rest"
+	logSlog "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/log/slog"
+	netDialer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/dialer"
+	httpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/client"
+	httpReverseProxy "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/httputil"
+	httpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/server"
+	netResolver "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/resolver"
+	rpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc/client"
+	rpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc/server"
+	netSMTP "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/smtp"
+	goRuntime "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/runtime"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/exception"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+// Config is the configuration of the probes.
+type Config struct {
+	// KubernetesWatchEvents is true if a span is produced for each event
+	// received by the watches of the k8s.io/client-go probe. Watches are not
+	// traced otherwise.
+	KubernetesWatchEvents bool
+	// BboltReadTransactions is true if the read-only transactions of the
+	// go.etcd.io/bbolt probe are traced. Only writable ones are otherwise.
+	BboltReadTransactions bool
+	// HTTPClientConnectionSpans is true if the connections dialed, and their
+	// TLS handshakes, are traced as children of the net/http client spans.
+	HTTPClientConnectionSpans bool
+	// GRPCServerMetadata are the request metadata keys whose values are
+	// recorded on the google.golang.org/grpc server spans.
+	GRPCServerMetadata []string
+	// Exceptions buffers the panics read by the runtime probe until the
+	// spans they are raised in are handled. The spans need to be passed
+	// through the handler it wraps to have the panics recorded. A new buffer
+	// is used if nil.
+	Exceptions *exception.Buffer
+}
+
+// Probes returns new instances of all the probes configured by c, logging
+// with l. The version is the version of the auto-instrumentation set as the
+// instrumentation scope version of the spans the probes produce.
+func Probes(l *slog.Logger, version string, c Config) []probe.Probe {
+	return []probe.Probe{
+		grpcClient.New(l, version),
+		grpcServer.New(l, version, c.GRPCServerMetadata),
+		httpServer.New(l, version),
+		httpClient.New(l, version),
+		httpReverseProxy.New(l, version),
+		netDialer.New(l, version, c.HTTPClientConnectionSpans),
+		cryptoTLS.New(l, version, c.HTTPClientConnectionSpans),
+		fasthttpServer.New(l, version),
+		fasthttpClient.New(l, version),
+		gqlgen.New(l, version),
+		dbSql.New(l, version),
+		redisClient.New(l, version),
+		mongoClient.New(l, version),
+		mongoClient.NewV2(l, version),
+		pgxClient.New(l, version),
+		esClient.NewV7(l, version),
+		esClient.NewV8(l, version),
+		memcacheClient.New(l, version),
+		gocqlClient.New(l, version),
+		etcdClient.New(l, version),
+		awsClient.New(l, version),
+		kafkaProducer.New(l, version),
+		kafkaConsumer.New(l, version),
+		saramaProducer.New(l, version),
+		saramaProducer.NewShopify(l, version),
+		saramaConsumer.New(l, version),
+		saramaConsumer.NewShopify(l, version),
+		natsProducer.New(l, version),
+		natsConsumer.New(l, version),
+		rabbitmqProducer.New(l, version),
+		rabbitmqConsumer.New(l, version),
+		pubsubProducer.New(l, version),
+		pubsubConsumer.New(l, version),
+		spannerClient.New(l, version),
+		confluentProducer.New(l, version),
+		confluentConsumer.New(l, version),
+		gorillaWebsocket.New(l, version),
+		k8sRest.New(l, version, c.KubernetesWatchEvents),
+		rueidisClient.New(l, version),
+		rueidisClient.NewValkey(l, version),
+		clickhouseClient.New(l, version),
+		natsJetstream.New(l, version),
+		asynqProducer.New(l, version),
+		asynqConsumer.New(l, version),
+		temporalClient.New(l, version),
+		influxdbClient.New(l, version),
+		vaultClient.New(l, version),
+		bboltTx.New(l, version, c.BboltReadTransactions),
+		badgerDB.New(l, version),
+		rpcServer.New(l, version),
+		rpcClient.New(l, version),
+		netResolver.New(l, version),
+		netSMTP.New(l, version),
+		sshClient.New(l, version),
+		http2Server.New(l, version),
+		pahoProducer.New(l, version),
+		pahoConsumer.New(l, version),
+		pahoV5Producer.New(l, version),
+		logSlog.New(l, version),
+		logZap.New(l, version),
+		goRuntime.New(l, version, c.Exceptions),
+		autosdk.New(l),
+		otelTrace.New(l),
+		otelTraceGlobal.New(l),
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+#include "arguments.h"
+#include "trace/span_context.h"
+#include "go_iface.h"
+#include "go_types.h"
+#include "goroutine_spans.h"
+#include "uprobe.h"
+
+char __license[] SEC("license") = "Dual MIT/GPL";
+
+#define MAX_TYPE_SIZE 128
+#define MAX_MESSAGE_SIZE 256
+
+struct panic_event_t {
+    u64 time;
+    // The span context of the span active in the panicking goroutine.
+    struct span_context sc;
+    char type[MAX_TYPE_SIZE];
+    char message[MAX_MESSAGE_SIZE];
+};
+
+struct {
+    __uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+    __uint(key_size, sizeof(u32));
+    __uint(value_size, sizeof(struct panic_event_t));
+    __uint(max_entries, 1);
+} panic_storage_map SEC(".maps");
+
+// Injected in init
+// The address of the runtime.types symbol, 0 if it is not known.
+volatile const u64 runtime_types_addr;
+
+// This instrumentation attaches uprobe to the following function:
+// func gopanic(e any)
+SEC("uprobe/gopanic")
+int uprobe_gopanic(struct pt_regs *ctx) {
+    void *key = (void *)GOROUTINE(ctx);
+    struct span_context *sc = get_goroutine_span(key);
+    if (sc == NULL) {
+        // No span active in the goroutine.
+        return 0;
+    }
+
+    u32 zero = 0;
+    struct panic_event_t *event = bpf_map_lookup_elem(&panic_storage_map, &zero);
+    if (event == NULL) {
+        bpf_printk("uprobe/gopanic: event is NULL");
+        return 0;
+    }
+    __builtin_memset(event, 0, sizeof(struct panic_event_t));
+    event->time = get_time_ns();
+    event->sc = *sc;
+
+    // The value panicked with.
+    void *type = get_argument(ctx, 1);
+    void *data = get_argument(ctx, 2);
+    go_type_name(type, (void *)runtime_types_addr, event->type, sizeof(event->type));
+    go_iface_string(type, data, event->message, sizeof(event->message));
+
+    bpf_perf_event_output(ctx, &events, BPF_F_CURRENT_CPU, event, sizeof(*event));
+    return 0;
+}
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package runtime provides an instrumentation probe recording the panics
+// raised in the spans produced by other probes as exception span events.
+package runtime
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sys/unix"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/exception"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 bpf ./bpf/probe.bpf.c
+
+// pkg is the package being instrumented.
+const pkg = "runtime"
+
+// New returns a new [probe.Probe].
+//
+// The panics raised in the goroutines handling a net/http or gRPC server
+// request are added to buf, and then recorded as exception span events of the
+// span of the request, with its status set to error, when it ends. The spans
+// of the requests that do not end, e.g. when the process dies, have their
+// exceptions recorded by INTERNAL "panic" child spans when the probe stops. A
+// new [exception.Buffer] is used if buf is nil.
+//
+// The exception.type is the type of the value panicked with, only known for
+// executables that are not position independent nor stripped. The
+// exception.message is the value panicked with if it is a string, or the
+// string first field of a struct, e.g. the message of an error returned by
+// [errors.New] or [fmt.Errorf]. It is truncated to 256 bytes.
+func New(logger *slog.Logger, version string, buf *exception.Buffer) probe.Probe {
+	id := probe.ID{
+		SpanKind:        trace.SpanKindInternal,
+		InstrumentedPkg: pkg,
+	}
+
+	if buf == nil {
+		buf = exception.NewBuffer()
+	}
+	p := &panicProbe{buf: buf}
+	p.SpanProducer = &probe.SpanProducer[bpfObjects, event]{
+		Base: probe.Base[bpfObjects, event]{
+			ID:     id,
+			Logger: logger,
+			Consts: []probe.Const{
+				probe.BootClockConst{},
+				probe.SymbolAddrConst{
+					Key:    "runtime_types_addr",
+					Symbol: "runtime.types",
+				},
+			},
+			Uprobes: []*probe.Uprobe{
+				{
+					Sym:        pkg + ".gopanic",
+					EntryProbe: "uprobe_gopanic",
+				},
+			},
+			SpecFn: loadBpf,
+		},
+		Version:   version,
+		SchemaURL: semconv.SchemaURL,
+		ProcessFn: p.processFn,
+	}
+	return p
+}
+
+// panicProbe is a [probe.SpanProducer] buffering the panics it reads. It
+// only produces spans for the panics still buffered when it stops.
+type panicProbe struct {
+	*probe.SpanProducer[bpfObjects, event]
+
+	buf *exception.Buffer
+}
+
+// Run runs the events processing loop. The panics still buffered when it
+// stops are flushed to h.
+func (p *panicProbe) Run(h *pipeline.Handler) {
+	p.SpanProducer.Run(h)
+	p.flush(h)
+}
+
+// Replay decodes raw, an event read from the eBPF program as written to an
+// event dump, and passes the "panic" span it produces to h.
+func (p *panicProbe) Replay(raw []byte, h *pipeline.Handler) error {
+	if err := p.SpanProducer.Replay(raw, h); err != nil {
+		return err
+	}
+	p.flush(h)
+	return nil
+}
+
+// flush passes the spans of the panics buffered to h.
+func (p *panicProbe) flush(h *pipeline.Handler) {
+	if h.TraceHandler == nil {
+		return
+	}
+	spans := p.buf.Flush()
+	if spans.Len() == 0 {
+		return
+	}
+	h.WithScope(p.Scope(), p.SchemaURL).Trace(spans)
+}
+
+const (
+	// maxTypeSize is the maximum size of the type name read.
+	maxTypeSize = 128
+	// maxMessageSize is the maximum size of the message read.
+	maxMessageSize = 256
+)
+
+// event represents a panic raised in a span.
+type event struct {
+	Time        uint64
+	SpanContext context.EBPFSpanContext
+	Type        [maxTypeSize]byte
+	Message     [maxMessageSize]byte
+}
+
+func (p *panicProbe) processFn(e *event) ptrace.SpanSlice {
+	ok := p.buf.Add(exception.Exception{
+		Time:    kernel.BootOffsetToTimestamp(e.Time),
+		TraceID: pcommon.TraceID(e.SpanContext.TraceID),
+		SpanID:  pcommon.SpanID(e.SpanContext.SpanID),
+		Flags:   uint32(e.SpanContext.TraceFlags),
+		Type:    unix.ByteSliceToString(e.Type[:]),
+		Message: unix.ByteSliceToString(e.Message[:]),
+	})
+	if !ok {
+		p.Logger.Debug("dropping panic, too many spans with panics pending", "span", e.SpanContext.SpanID)
+	}
+	return ptrace.NewSpanSlice()
+}
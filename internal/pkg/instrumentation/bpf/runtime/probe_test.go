@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/exception"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+)
+
+func TestProcessFn(t *testing.T) {
+	now := time.Unix(0, time.Now().UnixNano()) // No wall clock.
+	offset := kernel.TimeToBootOffset(now)
+
+	traceID := trace.TraceID{1}
+	spanID := trace.SpanID{1}
+
+	e := &event{
+		Time: offset,
+		SpanContext: context.EBPFSpanContext{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		},
+	}
+	copy(e.Type[:], "*errors.errorString")
+	copy(e.Message[:], "boom")
+
+	buf := exception.NewBuffer()
+	p, ok := New(slog.Default(), "", buf).(*panicProbe)
+	require.True(t, ok)
+
+	// The panics are buffered until their span ends.
+	assert.Equal(t, 0, p.processFn(e).Len())
+
+	spans := buf.Flush()
+	require.Equal(t, 1, spans.Len())
+	span := spans.At(0)
+	assert.Equal(t, pcommon.TraceID(traceID), span.TraceID())
+	assert.Equal(t, pcommon.SpanID(spanID), span.ParentSpanID())
+	assert.Equal(t, uint32(trace.FlagsSampled), span.Flags())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	require.Equal(t, 1, span.Events().Len())
+
+	event := span.Events().At(0)
+	assert.Equal(t, kernel.BootOffsetToTimestamp(offset), event.Timestamp())
+	assert.Equal(t, map[string]any{
+		"exception.type":    "*errors.errorString",
+		"exception.message": "boom",
+	}, event.Attributes().AsRaw())
+}
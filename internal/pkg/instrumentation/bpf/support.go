@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bpf
+
+// Support is the range of versions of an instrumented module supported by a
+// probe.
+type Support struct {
+	// Probe is the ID of the probe.
+	Probe string
+	// Module is the path of the instrumented module, "std" for the Go
+	// standard library.
+	Module string
+	// Min and Max are the minimum and maximum supported versions, inclusive.
+	// Versions of the standard library are Go versions (e.g. go1.19).
+	Min, Max string
+}
+
+// Supported are the versions of the instrumented modules supported by the
+// probes, in the order of [Probes]. Probes instrumenting several modules have
+// an entry per module. The probe of the go.opentelemetry.io/auto/sdk module is
+// not listed: the module is versioned with the auto-instrumentation.
+//
+// Keep in sync with COMPATIBILITY.md, and with the offsets of the struct
+// fields of the probes when they are updated.
+var Supported = []Support{
+	{Probe: "google.golang.org/grpc/client", Module: "google.golang.org/grpc", Min: "v1.14.0", Max: "v1.74.0"},
+	{Probe: "google.golang.org/grpc/server", Module: "google.golang.org/grpc", Min: "v1.14.0", Max: "v1.74.0"},
+	{Probe: "net/http/server", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "net/http/server", Module: "github.com/gin-gonic/gin", Min: "v1.5.0", Max: "v1.12.0"},
+	{Probe: "net/http/server", Module: "github.com/labstack/echo/v4", Min: "v4.10.1", Max: "v4.15.4"},
+	{Probe: "net/http/server", Module: "github.com/gorilla/mux", Min: "v1.7.0", Max: "v1.8.1"},
+	{Probe: "net/http/server", Module: "github.com/go-chi/chi/v5", Min: "v5.0.0", Max: "v5.3.2"},
+	{Probe: "net/http/server", Module: "github.com/twitchtv/twirp", Min: "v5.12.1", Max: "v8.1.3"},
+	{Probe: "net/http/server", Module: "github.com/grpc-ecosystem/grpc-gateway/v2", Min: "v2.0.0", Max: "v2.31.0"},
+	{Probe: "net/http/client", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "net/http/httputil/internal", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "net/internal", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "crypto/tls/internal", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "github.com/valyala/fasthttp/server", Module: "github.com/valyala/fasthttp", Min: "v1.20.0", Max: "v1.74.0"},
+	{Probe: "github.com/valyala/fasthttp/client", Module: "github.com/valyala/fasthttp", Min: "v1.20.0", Max: "v1.74.0"},
+	{Probe: "github.com/99designs/gqlgen/graphql/handler/internal", Module: "github.com/99designs/gqlgen", Min: "v0.17.0", Max: "v0.17.95"},
+	{Probe: "github.com/99designs/gqlgen/graphql/handler/internal", Module: "github.com/vektah/gqlparser/v2", Min: "v2.4.0", Max: "v2.5.58"},
+	{Probe: "database/sql/client", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "database/sql/client", Module: "gorm.io/gorm", Min: "v1.25.0", Max: "v1.31.2"},
+	{Probe: "github.com/redis/go-redis/v9/client", Module: "github.com/redis/go-redis/v9", Min: "v9.0.0", Max: "v9.22.0"},
+	{Probe: "go.mongodb.org/mongo-driver/client", Module: "go.mongodb.org/mongo-driver", Min: "v1.11.0", Max: "v1.17.10"},
+	{Probe: "go.mongodb.org/mongo-driver/v2/client", Module: "go.mongodb.org/mongo-driver/v2", Min: "v2.0.0", Max: "v2.9.1"},
+	{Probe: "github.com/jackc/pgx/client", Module: "github.com/jackc/pgx/v5", Min: "v5.0.0", Max: "v5.11.0"},
+	{Probe: "github.com/jackc/pgx/client", Module: "github.com/jackc/pgx/v4", Min: "v4.0.0", Max: "v4.18.3"},
+	// The pgconn package used by v4 of pgx is published as its own module.
+	{Probe: "github.com/jackc/pgx/client", Module: "github.com/jackc/pgconn", Min: "v1.0.0", Max: "v1.14.3"},
+	{Probe: "github.com/elastic/go-elasticsearch/v7/client", Module: "github.com/elastic/go-elasticsearch/v7", Min: "v7.0.0", Max: "v7.17.10"},
+	{Probe: "github.com/elastic/go-elasticsearch/v8/client", Module: "github.com/elastic/go-elasticsearch/v8", Min: "v8.0.0", Max: "v8.19.7"},
+	// The module is not tagged, only the offsets of its latest pseudo-version
+	// are known.
+	{Probe: "github.com/bradfitz/gomemcache/memcache/client", Module: "github.com/bradfitz/gomemcache", Min: "v0.0.0-20260422231931-4d751bb6e37c", Max: "v0.0.0-20260422231931-4d751bb6e37c"},
+	{Probe: "github.com/gocql/gocql/client", Module: "github.com/gocql/gocql", Min: "v1.0.0", Max: "v1.7.0"},
+	{Probe: "go.etcd.io/etcd/client/v3/client", Module: "go.etcd.io/etcd/client/v3", Min: "v3.5.0", Max: "v3.7.2"},
+	{Probe: "go.etcd.io/etcd/client/v3/client", Module: "go.etcd.io/etcd/api/v3", Min: "v3.5.0", Max: "v3.7.2"},
+	{Probe: "github.com/aws/aws-sdk-go-v2/client", Module: "github.com/aws/aws-sdk-go-v2", Min: "v1.0.0", Max: "v1.47.1"},
+	{Probe: "github.com/aws/aws-sdk-go-v2/client", Module: "github.com/aws/smithy-go", Min: "v1.0.0", Max: "v1.28.2"},
+	{Probe: "github.com/segmentio/kafka-go/producer", Module: "github.com/segmentio/kafka-go", Min: "v0.4.1", Max: "v0.4.48"},
+	{Probe: "github.com/segmentio/kafka-go/consumer", Module: "github.com/segmentio/kafka-go", Min: "v0.4.1", Max: "v0.4.48"},
+	{Probe: "github.com/IBM/sarama/producer", Module: "github.com/IBM/sarama", Min: "v1.40.0", Max: "v1.61.0"},
+	{Probe: "github.com/Shopify/sarama/producer", Module: "github.com/Shopify/sarama", Min: "v1.21.0", Max: "v1.38.1"},
+	{Probe: "github.com/IBM/sarama/consumer", Module: "github.com/IBM/sarama", Min: "v1.40.0", Max: "v1.61.0"},
+	{Probe: "github.com/Shopify/sarama/consumer", Module: "github.com/Shopify/sarama", Min: "v1.21.0", Max: "v1.38.1"},
+	{Probe: "github.com/nats-io/nats.go/producer", Module: "github.com/nats-io/nats.go", Min: "v1.11.0", Max: "v1.54.0"},
+	{Probe: "github.com/nats-io/nats.go/consumer", Module: "github.com/nats-io/nats.go", Min: "v1.11.0", Max: "v1.54.0"},
+	{Probe: "github.com/rabbitmq/amqp091-go/producer", Module: "github.com/rabbitmq/amqp091-go", Min: "v1.4.0", Max: "v1.15.0"},
+	{Probe: "github.com/rabbitmq/amqp091-go/consumer", Module: "github.com/rabbitmq/amqp091-go", Min: "v1.4.0", Max: "v1.15.0"},
+	{Probe: "cloud.google.com/go/pubsub/producer", Module: "cloud.google.com/go/pubsub", Min: "v1.9.1", Max: "v1.51.1"},
+	{Probe: "cloud.google.com/go/pubsub/producer", Module: "cloud.google.com/go", Min: "v0.73.0", Max: "v0.123.0"},
+	{Probe: "cloud.google.com/go/pubsub/consumer", Module: "cloud.google.com/go/pubsub", Min: "v1.9.1", Max: "v1.51.1"},
+	{Probe: "cloud.google.com/go/pubsub/consumer", Module: "cloud.google.com/go", Min: "v0.73.0", Max: "v0.123.0"},
+	{Probe: "cloud.google.com/go/spanner/client", Module: "cloud.google.com/go/spanner", Min: "v1.25.0", Max: "v1.82.0"},
+	{Probe: "github.com/confluentinc/confluent-kafka-go/v2/kafka/producer", Module: "github.com/confluentinc/confluent-kafka-go/v2", Min: "v2.0.2", Max: "v2.15.1"},
+	{Probe: "github.com/confluentinc/confluent-kafka-go/v2/kafka/consumer", Module: "github.com/confluentinc/confluent-kafka-go/v2", Min: "v2.0.2", Max: "v2.15.1"},
+	{Probe: "github.com/gorilla/websocket/internal", Module: "github.com/gorilla/websocket", Min: "v1.0.0", Max: "v1.5.3"},
+	{Probe: "k8s.io/client-go/rest/internal", Module: "k8s.io/client-go", Min: "v0.20.0", Max: "v0.37.1"},
+	{Probe: "k8s.io/client-go/rest/internal", Module: "k8s.io/apimachinery", Min: "v0.20.0", Max: "v0.37.1"},
+	{Probe: "github.com/redis/rueidis/client", Module: "github.com/redis/rueidis", Min: "v1.0.0", Max: "v1.0.78"},
+	{Probe: "github.com/valkey-io/valkey-go/client", Module: "github.com/valkey-io/valkey-go", Min: "v1.0.35", Max: "v1.0.78"},
+	{Probe: "github.com/ClickHouse/clickhouse-go/v2/client", Module: "github.com/ClickHouse/clickhouse-go/v2", Min: "v2.0.1", Max: "v2.48.0"},
+	{Probe: "github.com/nats-io/nats.go/jetstream/consumer", Module: "github.com/nats-io/nats.go", Min: "v1.26.0", Max: "v1.54.0"},
+	{Probe: "github.com/hibiken/asynq/producer", Module: "github.com/hibiken/asynq", Min: "v0.24.0", Max: "v0.26.0"},
+	{Probe: "github.com/hibiken/asynq/consumer", Module: "github.com/hibiken/asynq", Min: "v0.24.0", Max: "v0.26.0"},
+	{Probe: "go.temporal.io/sdk/client", Module: "go.temporal.io/sdk", Min: "v1.20.0", Max: "v1.49.0"},
+	{Probe: "github.com/influxdata/influxdb-client-go/v2/client", Module: "github.com/influxdata/influxdb-client-go/v2", Min: "v2.0.1", Max: "v2.14.0"},
+	{Probe: "github.com/hashicorp/vault/api/client", Module: "github.com/hashicorp/vault/api", Min: "v1.1.0", Max: "v1.20.0"},
+	{Probe: "go.etcd.io/bbolt/internal", Module: "go.etcd.io/bbolt", Min: "v1.3.0", Max: "v1.5.0"},
+	{Probe: "github.com/dgraph-io/badger/v4/internal", Module: "github.com/dgraph-io/badger/v4", Min: "v4.0.1", Max: "v4.9.6"},
+	{Probe: "net/rpc/server", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "net/rpc/client", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "net/client", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "net/smtp/client", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	{Probe: "golang.org/x/crypto/ssh/client", Module: "golang.org/x/crypto", Min: "v0.1.0", Max: "v0.57.0"},
+	{Probe: "golang.org/x/net/http2/server", Module: "golang.org/x/net", Min: "v0.1.0", Max: "v0.42.0"},
+	{Probe: "github.com/eclipse/paho.mqtt.golang/producer", Module: "github.com/eclipse/paho.mqtt.golang", Min: "v1.2.0", Max: "v1.5.0"},
+	{Probe: "github.com/eclipse/paho.mqtt.golang/consumer", Module: "github.com/eclipse/paho.mqtt.golang", Min: "v1.2.0", Max: "v1.5.0"},
+	{Probe: "github.com/eclipse/paho.golang/paho/producer", Module: "github.com/eclipse/paho.golang", Min: "v0.10.0", Max: "v0.22.0"},
+	{Probe: "log/slog/internal", Module: "std", Min: "go1.21", Max: "go1.24.5"},
+	{Probe: "go.uber.org/zap/internal", Module: "go.uber.org/zap", Min: "v1.21.0", Max: "v1.27.0"},
+	{Probe: "runtime/internal", Module: "std", Min: "go1.19", Max: "go1.24.5"},
+	// The minimum version is the one with the auto-instrumentation SDK
+	// implementation of non-recording spans the probe attaches to.
+	{Probe: "go.opentelemetry.io/otel/trace/client", Module: "go.opentelemetry.io/otel", Min: "v1.35.1", Max: "v1.37.0"},
+	{Probe: "go.opentelemetry.io/otel/internal/global/client", Module: "go.opentelemetry.io/otel", Min: "v0.14.0", Max: "v1.37.0"},
+}
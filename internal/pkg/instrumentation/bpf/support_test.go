@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bpf
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/auto/internal/pkg/inject"
+)
+
+func TestSupported(t *testing.T) {
+	var ids []string
+	for _, p := range Probes(nil, "", Config{}) {
+		if id := p.Manifest().ID.String(); id != "go.opentelemetry.io/auto/client" {
+			ids = append(ids, id)
+		}
+	}
+	var supported []string
+	for _, s := range Supported {
+		supported = append(supported, s.Probe)
+	}
+	// Probes instrumenting several modules have a row per module.
+	supported = slices.Compact(supported)
+	assert.Equal(t, ids, supported, "supported versions of the probes not listed")
+}
+
+func TestSupportedOffsets(t *testing.T) {
+	bySupport := make(map[string][]Support, len(Supported))
+	for _, s := range Supported {
+		bySupport[s.Probe] = append(bySupport[s.Probe], s)
+	}
+
+	for _, p := range Probes(nil, "", Config{}) {
+		m := p.Manifest()
+		for _, s := range bySupport[m.ID.String()] {
+			minVer, err := semver.NewVersion(strings.TrimPrefix(s.Min, "go"))
+			require.NoError(t, err, s.Probe)
+			maxVer, err := semver.NewVersion(strings.TrimPrefix(s.Max, "go"))
+			require.NoError(t, err, s.Probe)
+			assert.False(t, maxVer.LessThan(minVer), "%s: maximum version lower than minimum", s.Probe)
+
+			// Offsets must be known up to the maximum version.
+			for _, id := range m.StructFields {
+				if id.ModPath != s.Module {
+					continue
+				}
+				_, latest := inject.GetLatestOffset(id)
+				require.NotNil(t, latest, "%s: no offset of %s", s.Probe, id)
+				assert.False(t, latest.LessThan(maxVer), "%s: offsets of %s only known up to %s", s.Probe, id, latest)
+			}
+		}
+	}
+}
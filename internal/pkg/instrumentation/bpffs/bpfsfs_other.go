@@ -9,14 +9,14 @@ import "go.opentelemetry.io/auto/internal/pkg/process"
 
 // Stubs for non-linux systems
 
-func PathForTargetApplication(target *process.TargetDetails) string {
+func PathForTargetApplication(*process.Info) string {
 	return ""
 }
 
-func Mount(target *process.TargetDetails) error {
+func Mount(*process.Info) error {
 	return nil
 }
 
-func Cleanup(target *process.TargetDetails) error {
+func Cleanup(*process.Info) error {
 	return nil
 }
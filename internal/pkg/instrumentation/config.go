@@ -56,6 +56,18 @@ type ConfigProvider interface {
 	Shutdown(ctx context.Context) error
 }
 
+// isStatic returns true if cp never updates the configuration: it is the
+// noop provider, or a provider with a Static method returning true.
+func isStatic(cp ConfigProvider) bool {
+	switch p := cp.(type) {
+	case *noopProvider:
+		return true
+	case interface{ Static() bool }:
+		return p.Static()
+	}
+	return false
+}
+
 type noopProvider struct {
 	SamplingConfig *sampling.Config
 }
@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import "go.opentelemetry.io/otel/trace"
+
+// maxTraceStateLen is the maximum length of a tracestate captured by a probe.
+const maxTraceStateLen = 512
+
+// TraceState is the raw W3C tracestate of a remote parent captured by a
+// probe.
+type TraceState struct {
+	Len   uint64
+	Value [maxTraceStateLen]byte
+}
+
+// String returns the captured tracestate. An empty string is returned if no
+// tracestate was captured or the captured value is not a valid W3C
+// tracestate.
+func (ts *TraceState) String() string {
+	n := min(ts.Len, maxTraceStateLen)
+	if n == 0 {
+		return ""
+	}
+
+	parsed, err := trace.ParseTraceState(string(ts.Value[:n]))
+	if err != nil {
+		return ""
+	}
+	return parsed.String()
+}
@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTraceState(s string) *TraceState {
+	ts := new(TraceState)
+	ts.Len = uint64(copy(ts.Value[:], s))
+	return ts
+}
+
+func members(n int) string {
+	m := make([]string, n)
+	for i := range m {
+		m[i] = fmt.Sprintf("k%d=v", i)
+	}
+	return strings.Join(m, ",")
+}
+
+func TestTraceStateString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Empty", in: "", want: ""},
+		{name: "Single", in: "vendor=value", want: "vendor=value"},
+		{name: "Multiple", in: "a=1,b@c=2", want: "a=1,b@c=2"},
+		{name: "MaxMembers", in: members(32), want: members(32)},
+		{name: "OWS", in: "a=1 , b=2", want: "a=1,b=2"},
+		{name: "InvalidKey", in: "A=1", want: ""},
+		{name: "InvalidValue", in: "a=b=c", want: ""},
+		{name: "Duplicate", in: "a=1,a=2", want: ""},
+		{name: "TooManyMembers", in: members(33), want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, newTraceState(test.in).String())
+		})
+	}
+
+	t.Run("LenOverflow", func(t *testing.T) {
+		ts := newTraceState("a=1")
+		ts.Len = 1 << 20
+		assert.Empty(t, ts.String(), "trailing NUL bytes are invalid")
+	})
+}
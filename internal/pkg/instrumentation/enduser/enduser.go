@@ -0,0 +1,257 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package enduser provides the opt-in capture of the authenticated principal
+// of requests handled by server probes as the enduser.id attribute.
+//
+// The identity is read from a configured request header, or from the "sub"
+// claim of a JWT bearer token in the Authorization header. The identity can be
+// HMAC-hashed with a configured key so that the same principal is reported
+// consistently across services without the raw identity being exported.
+package enduser
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cilium/ebpf/perf"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+const (
+	// SourceEnvVar is the environment variable used to opt-in to the capture
+	// of the end user identity. The value is either "header:<name>", to use
+	// the value of the <name> request header as the identity, or "jwt", to
+	// use the "sub" claim of the bearer token in the Authorization header.
+	//
+	// The capture is disabled if this is not set.
+	SourceEnvVar = "OTEL_GO_AUTO_ENDUSER_ID_SOURCE"
+	// HMACKeyEnvVar is the environment variable containing the key used to
+	// HMAC-SHA256 hash the end user identity. If set, enduser.id is the hex
+	// encoded hash of the identity instead of the identity itself.
+	HMACKeyEnvVar = "OTEL_GO_AUTO_ENDUSER_ID_HMAC_KEY"
+
+	// MaxHeaderLen is the maximum length of the end user header name.
+	MaxHeaderLen = 64
+	// MaxValueLen is the maximum length of a captured header value. Longer
+	// values are not captured.
+	MaxValueLen = 1024
+
+	authorizationHeader = "authorization"
+	bearerPrefix        = "bearer "
+)
+
+// IDKey is the attribute key for the end user identity.
+const IDKey = attribute.Key("enduser.id")
+
+// Config is the end user capture configuration. The zero value disables the
+// capture.
+type Config struct {
+	// Header is the lowercase name of the request header containing the
+	// identity.
+	Header string
+	// JWT is true if the value of Header is a JWT bearer token and the
+	// identity is its "sub" claim.
+	JWT bool
+	// HMACKey, if not empty, is used to hash the identity.
+	HMACKey []byte
+}
+
+// ConfigFromEnv returns the Config defined by the SourceEnvVar and
+// HMACKeyEnvVar environment variables. The zero Config is returned if
+// SourceEnvVar is not set.
+func ConfigFromEnv() (Config, error) {
+	return parseConfig(os.Getenv(SourceEnvVar), os.Getenv(HMACKeyEnvVar))
+}
+
+func parseConfig(source, key string) (Config, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return Config{}, nil
+	}
+
+	var c Config
+	switch name, ok := strings.CutPrefix(source, "header:"); {
+	case ok:
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !validHeaderName(name) {
+			return Config{}, fmt.Errorf("invalid %s header name: %q", SourceEnvVar, name)
+		}
+		if len(name) > MaxHeaderLen {
+			return Config{}, fmt.Errorf("%s header name longer than %d: %q", SourceEnvVar, MaxHeaderLen, name)
+		}
+		c.Header = name
+	case strings.EqualFold(source, "jwt"):
+		c.Header, c.JWT = authorizationHeader, true
+	default:
+		return Config{}, fmt.Errorf("invalid %s: %q", SourceEnvVar, source)
+	}
+
+	if key != "" {
+		c.HMACKey = []byte(key)
+	}
+	return c, nil
+}
+
+// validHeaderName returns if name is a valid HTTP header field name token.
+//
+// https://www.rfc-editor.org/rfc/rfc9110#name-tokens
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Enabled returns if the capture of the end user identity is enabled.
+func (c Config) Enabled() bool {
+	return c.Header != ""
+}
+
+// Consts returns the [probe.Const] configuring the capture of the end user
+// header in the eBPF programs of a probe.
+func (c Config) Consts() []probe.Const {
+	var header [MaxHeaderLen]byte
+	n := copy(header[:], c.Header)
+	return []probe.Const{
+		probe.KeyValConst{Key: "enduser_enabled", Val: c.Enabled()},
+		probe.KeyValConst{Key: "enduser_header", Val: header},
+		probe.KeyValConst{Key: "enduser_header_len", Val: uint64(n)},
+	}
+}
+
+// Attributes returns the enduser.id attribute for the captured header value
+// v. No attributes are returned if the capture is disabled or an identity
+// cannot be determined from v.
+func (c Config) Attributes(v *Value) []attribute.KeyValue {
+	if !c.Enabled() {
+		return nil
+	}
+	id, ok := c.id(v.Bytes())
+	if !ok {
+		return nil
+	}
+	if len(c.HMACKey) > 0 {
+		id = hash(c.HMACKey, id)
+	}
+	return []attribute.KeyValue{IDKey.String(id)}
+}
+
+func (c Config) id(v []byte) (string, bool) {
+	v = bytes.TrimSpace(v)
+	if !c.JWT {
+		return string(v), len(v) > 0
+	}
+	sub, err := jwtSubject(v)
+	if err != nil {
+		return "", false
+	}
+	return sub, true
+}
+
+func hash(key []byte, id string) string {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(id))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var (
+	errNotBearer   = errors.New("not a bearer token")
+	errMalformed   = errors.New("malformed JWT")
+	errMissingSub  = errors.New("missing sub claim")
+	errTokenLength = fmt.Errorf("token longer than %d", MaxValueLen)
+)
+
+// jwtSubject returns the "sub" claim of the JWT bearer token contained in the
+// Authorization header value v.
+//
+// The token is only decoded. Its signature is not verified.
+func jwtSubject(v []byte) (string, error) {
+	if len(v) > MaxValueLen {
+		return "", errTokenLength
+	}
+	if len(v) < len(bearerPrefix) || !strings.EqualFold(string(v[:len(bearerPrefix)]), bearerPrefix) {
+		return "", errNotBearer
+	}
+	token := bytes.TrimSpace(v[len(bearerPrefix):])
+
+	// header.payload.signature
+	parts := bytes.Split(token, []byte{'.'})
+	if len(parts) != 3 || len(parts[1]) == 0 {
+		return "", errMalformed
+	}
+
+	// Padding is not allowed in a JWT, but is tolerated.
+	payload := bytes.TrimRight(parts[1], "=")
+	buf := make([]byte, base64.RawURLEncoding.DecodedLen(len(payload)))
+	n, err := base64.RawURLEncoding.Decode(buf, payload)
+	if err != nil {
+		return "", errMalformed
+	}
+
+	var claims struct {
+		Sub *string `json:"sub"`
+	}
+	if err := json.Unmarshal(buf[:n], &claims); err != nil {
+		return "", errMalformed
+	}
+	if claims.Sub == nil || *claims.Sub == "" {
+		return "", errMissingSub
+	}
+	return *claims.Sub, nil
+}
+
+// Value is a request header value captured by an eBPF program.
+type Value struct {
+	Len   uint64
+	Value [MaxValueLen]byte
+}
+
+// Bytes returns the captured header value. An empty slice is returned if the
+// length of v is not valid.
+func (v *Value) Bytes() []byte {
+	if v.Len == 0 || v.Len > MaxValueLen {
+		return nil
+	}
+	return v.Value[:v.Len]
+}
+
+// Decode decodes the event E of record. The last field of E must be a Value,
+// which the eBPF programs only output if the capture is enabled. It is left
+// empty otherwise.
+func Decode[E any](record perf.Record) (*E, error) {
+	e := new(E)
+	raw := record.RawSample
+	if n := binary.Size(e); len(raw) < n {
+		// The Value is not output, ignore the padding of the sample.
+		m := n - binary.Size(Value{})
+		if len(raw) < m {
+			return nil, io.ErrUnexpectedEOF
+		}
+		raw = append(raw[:m:m], make([]byte, n-m)...)
+	}
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,250 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package enduser
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cilium/ebpf/perf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		source, key string
+		want        Config
+		wantErr     bool
+	}{
+		{source: "", want: Config{}},
+		{source: "", key: "secret", want: Config{}},
+		{source: "header:x-user-id", want: Config{Header: "x-user-id"}},
+		{source: "header: X-User-ID ", want: Config{Header: "x-user-id"}},
+		{
+			source: "header:x-user-id",
+			key:    "secret",
+			want:   Config{Header: "x-user-id", HMACKey: []byte("secret")},
+		},
+		{source: "jwt", want: Config{Header: "authorization", JWT: true}},
+		{source: "JWT", want: Config{Header: "authorization", JWT: true}},
+		{source: "header:", wantErr: true},
+		{source: "header:x user", wantErr: true},
+		{source: "header:" + strings.Repeat("x", MaxHeaderLen+1), wantErr: true},
+		{source: "cookie:session", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			got, err := parseConfig(test.source, test.key)
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.False(t, got.Enabled(), "enabled on error")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(SourceEnvVar, "header:x-user-id")
+	t.Setenv(HMACKeyEnvVar, "secret")
+
+	c, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Header: "x-user-id", HMACKey: []byte("secret")}, c)
+}
+
+func TestConsts(t *testing.T) {
+	var header [MaxHeaderLen]byte
+	copy(header[:], "x-user-id")
+
+	assert.Equal(t, []probe.Const{
+		probe.KeyValConst{Key: "enduser_enabled", Val: true},
+		probe.KeyValConst{Key: "enduser_header", Val: header},
+		probe.KeyValConst{Key: "enduser_header_len", Val: uint64(9)},
+	}, Config{Header: "x-user-id"}.Consts())
+
+	assert.Equal(t, []probe.Const{
+		probe.KeyValConst{Key: "enduser_enabled", Val: false},
+		probe.KeyValConst{Key: "enduser_header", Val: [MaxHeaderLen]byte{}},
+		probe.KeyValConst{Key: "enduser_header_len", Val: uint64(0)},
+	}, Config{}.Consts())
+}
+
+func value(s string) *Value {
+	v := new(Value)
+	v.Len = uint64(copy(v.Value[:], s))
+	return v
+}
+
+func jwt(payload string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"none"}`)) + "." + enc([]byte(payload)) + ".c2ln"
+}
+
+func TestAttributes(t *testing.T) {
+	hashed := func(key, id string) string {
+		h := hmac.New(sha256.New, []byte(key))
+		h.Write([]byte(id))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	header := Config{Header: "x-user-id"}
+	jwtConf := Config{Header: "authorization", JWT: true}
+
+	tests := []struct {
+		name string
+		conf Config
+		val  *Value
+		want []attribute.KeyValue
+	}{
+		{
+			name: "Disabled",
+			val:  value("alice"),
+		},
+		{
+			name: "Header",
+			conf: header,
+			val:  value(" alice "),
+			want: []attribute.KeyValue{IDKey.String("alice")},
+		},
+		{
+			name: "HeaderEmpty",
+			conf: header,
+			val:  value("  "),
+		},
+		{
+			name: "HeaderHMAC",
+			conf: Config{Header: "x-user-id", HMACKey: []byte("secret")},
+			val:  value("alice"),
+			want: []attribute.KeyValue{IDKey.String(hashed("secret", "alice"))},
+		},
+		{
+			name: "InvalidLen",
+			conf: header,
+			val:  &Value{Len: MaxValueLen + 1},
+		},
+		{
+			name: "JWT",
+			conf: jwtConf,
+			val:  value("Bearer " + jwt(`{"sub":"alice","iss":"example"}`)),
+			want: []attribute.KeyValue{IDKey.String("alice")},
+		},
+		{
+			name: "JWTCaseInsensitiveScheme",
+			conf: jwtConf,
+			val:  value("bearer " + jwt(`{"sub":"alice"}`)),
+			want: []attribute.KeyValue{IDKey.String("alice")},
+		},
+		{
+			name: "JWTPadded",
+			conf: jwtConf,
+			val: value("Bearer e30." +
+				base64.URLEncoding.EncodeToString([]byte(`{"sub":"al"}`)) + ".c2ln"),
+			want: []attribute.KeyValue{IDKey.String("al")},
+		},
+		{
+			name: "JWTHMAC",
+			conf: Config{Header: "authorization", JWT: true, HMACKey: []byte("secret")},
+			val:  value("Bearer " + jwt(`{"sub":"alice"}`)),
+			want: []attribute.KeyValue{IDKey.String(hashed("secret", "alice"))},
+		},
+		{
+			name: "JWTBasicAuth",
+			conf: jwtConf,
+			val:  value("Basic YWxpY2U6cGFzc3dvcmQ="),
+		},
+		{
+			name: "JWTMissingSub",
+			conf: jwtConf,
+			val:  value("Bearer " + jwt(`{"iss":"example"}`)),
+		},
+		{
+			name: "JWTNonStringSub",
+			conf: jwtConf,
+			val:  value("Bearer " + jwt(`{"sub":42}`)),
+		},
+		{
+			name: "JWTEmptySub",
+			conf: jwtConf,
+			val:  value("Bearer " + jwt(`{"sub":""}`)),
+		},
+		{
+			name: "JWTNotJSON",
+			conf: jwtConf,
+			val:  value("Bearer " + jwt(`alice`)),
+		},
+		{
+			name: "JWTInvalidBase64",
+			conf: jwtConf,
+			val:  value("Bearer e30.!!!.c2ln"),
+		},
+		{
+			name: "JWTTwoParts",
+			conf: jwtConf,
+			val:  value("Bearer e30.e30"),
+		},
+		{
+			name: "JWTOpaqueToken",
+			conf: jwtConf,
+			val:  value("Bearer 2YotnFZFEjr1zCsicMWpAA"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.conf.Attributes(test.val))
+		})
+	}
+}
+
+func TestJWTSubjectBounded(t *testing.T) {
+	token := "Bearer " + jwt(`{"sub":"`+strings.Repeat("a", MaxValueLen)+`"}`)
+	_, err := jwtSubject([]byte(token))
+	assert.ErrorIs(t, err, errTokenLength)
+}
+
+func TestDecode(t *testing.T) {
+	type event struct {
+		ID      uint64
+		EndUser Value
+	}
+
+	want := event{ID: 42, EndUser: Value{Len: 5}}
+	copy(want.EndUser.Value[:], "alice")
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, want))
+	raw := buf.Bytes()
+
+	t.Run("Enabled", func(t *testing.T) {
+		got, err := Decode[event](perf.Record{RawSample: raw})
+		require.NoError(t, err)
+		assert.Equal(t, want, *got)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		// The perf sample is padded after the ID.
+		got, err := Decode[event](perf.Record{RawSample: raw[:12]})
+		require.NoError(t, err)
+		assert.Equal(t, event{ID: 42}, *got)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		_, err := Decode[event](perf.Record{RawSample: raw[:4]})
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
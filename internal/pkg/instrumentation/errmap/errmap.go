@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package errmap provides the configuration of which status codes returned to
+// instrumented operations are recorded as errors.
+//
+// A set of codes is configured with a comma-separated list of codes (e.g.
+// "404") and inclusive ranges of codes (e.g. "400-499"). Codes and ranges
+// prefixed with "!" are excluded from the set. If a list only contains
+// exclusions, they are excluded from the default set of the operation. For
+// example, "!404,!429" configures all the default codes except 404 and 429 to
+// be errors, and "500-599" configures only 5xx codes to be errors.
+package errmap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type codeRange struct {
+	low, high int64
+}
+
+func (r codeRange) contains(code int64) bool {
+	return r.low <= code && code <= r.high
+}
+
+func (r codeRange) String() string {
+	if r.low == r.high {
+		return strconv.FormatInt(r.low, 10)
+	}
+	return strconv.FormatInt(r.low, 10) + "-" + strconv.FormatInt(r.high, 10)
+}
+
+// Set is a set of status codes. The zero value is an empty set.
+type Set struct {
+	include []codeRange
+	exclude []codeRange
+}
+
+// Range returns a Set containing the codes from low to high inclusive.
+func Range(low, high int64) Set {
+	return Set{include: []codeRange{{low: low, high: high}}}
+}
+
+// Parse parses spec as a set of status codes. If spec is empty or only
+// contains exclusions, the returned set is def without the excluded codes.
+func Parse(spec string, def Set) (Set, error) {
+	var s Set
+	var err error
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		exclude := strings.HasPrefix(item, "!")
+		r, e := parseRange(strings.TrimPrefix(item, "!"))
+		if e != nil {
+			err = errors.Join(err, e)
+			continue
+		}
+		if exclude {
+			s.exclude = append(s.exclude, r)
+		} else {
+			s.include = append(s.include, r)
+		}
+	}
+	if err != nil {
+		return def, err
+	}
+
+	if len(s.include) == 0 {
+		s.include = def.include
+		s.exclude = append(s.exclude, def.exclude...)
+	}
+	return s, nil
+}
+
+func parseRange(item string) (codeRange, error) {
+	lowStr, highStr, isRange := strings.Cut(item, "-")
+	low, err := strconv.ParseInt(strings.TrimSpace(lowStr), 10, 64)
+	if err != nil || low < 0 {
+		return codeRange{}, fmt.Errorf("invalid status code: %q", item)
+	}
+	if !isRange {
+		return codeRange{low: low, high: low}, nil
+	}
+
+	high, err := strconv.ParseInt(strings.TrimSpace(highStr), 10, 64)
+	if err != nil || high < low {
+		return codeRange{}, fmt.Errorf("invalid status code range: %q", item)
+	}
+	return codeRange{low: low, high: high}, nil
+}
+
+// Contains returns if code is in s.
+func (s Set) Contains(code int64) bool {
+	for _, r := range s.exclude {
+		if r.contains(code) {
+			return false
+		}
+	}
+	for _, r := range s.include {
+		if r.contains(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns s in the format accepted by [Parse].
+func (s Set) String() string {
+	items := make([]string, 0, len(s.include)+len(s.exclude))
+	for _, r := range s.include {
+		items = append(items, r.String())
+	}
+	for _, r := range s.exclude {
+		items = append(items, "!"+r.String())
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package errmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	def := Range(400, 599)
+
+	tests := []struct {
+		spec     string
+		str      string
+		contains []int64
+		excludes []int64
+	}{
+		{
+			spec:     "",
+			str:      "400-599",
+			contains: []int64{400, 404, 429, 500, 599},
+			excludes: []int64{0, 200, 399, 600},
+		},
+		{
+			spec:     "!404",
+			str:      "400-599,!404",
+			contains: []int64{400, 429, 500},
+			excludes: []int64{200, 404},
+		},
+		{
+			spec:     " !404 , !429 ",
+			str:      "400-599,!404,!429",
+			contains: []int64{400, 500},
+			excludes: []int64{404, 429},
+		},
+		{
+			spec:     "500-599",
+			str:      "500-599",
+			contains: []int64{500, 503},
+			excludes: []int64{400, 404},
+		},
+		{
+			spec:     "400-499,!404,503",
+			str:      "400-499,503,!404",
+			contains: []int64{400, 429, 503},
+			excludes: []int64{404, 500},
+		},
+		{
+			spec:     "400-599,!400-499,429",
+			str:      "400-599,429,!400-499",
+			contains: []int64{500},
+			excludes: []int64{404, 429},
+		},
+		{
+			spec:     "0",
+			str:      "0",
+			contains: []int64{0},
+			excludes: []int64{500},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			s, err := Parse(test.spec, def)
+			require.NoError(t, err)
+			assert.Equal(t, test.str, s.String())
+			for _, c := range test.contains {
+				assert.True(t, s.Contains(c), "does not contain %d", c)
+			}
+			for _, c := range test.excludes {
+				assert.False(t, s.Contains(c), "contains %d", c)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	def := Range(400, 599)
+	for _, spec := range []string{"4xx", "500-", "-1", "599-500", "!", "400,abc"} {
+		t.Run(spec, func(t *testing.T) {
+			s, err := Parse(spec, def)
+			assert.Error(t, err)
+			assert.Equal(t, def, s, "default not returned")
+		})
+	}
+}
+
+func TestZeroSet(t *testing.T) {
+	var s Set
+	assert.False(t, s.Contains(500))
+	assert.Empty(t, s.String())
+}
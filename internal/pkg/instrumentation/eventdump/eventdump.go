@@ -0,0 +1,204 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package eventdump writes and reads the raw events read from the eBPF
+// programs of the probes, for offline analysis of probe issues.
+//
+// A dump file contains a JSON encoded [Record] per line. The payload of a
+// record is the event as sent by the eBPF program, before it is decoded and
+// processed by the probe.
+package eventdump
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the default maximum size of a dump file.
+	DefaultMaxSize = 16 << 20 // 16 MiB
+	// DefaultMaxFiles is the default maximum number of dump files kept,
+	// including the one written to.
+	DefaultMaxFiles = 4
+)
+
+// Record is an event read from the eBPF program of a probe.
+type Record struct {
+	// Probe is the ID of the probe that read the event.
+	Probe string `json:"probe"`
+	// Time is when the event was read.
+	Time time.Time `json:"time"`
+	// Payload is the raw event.
+	Payload []byte `json:"payload"`
+}
+
+// Config configures a [Writer].
+type Config struct {
+	// MaxSize is the maximum size, in bytes, of a dump file. Once a file
+	// would exceed this size it is rotated. If zero, DefaultMaxSize is used.
+	MaxSize int64
+	// MaxFiles is the maximum number of dump files kept. The oldest file is
+	// deleted when a file is rotated and this number is reached. The total
+	// size of the dump files is capped at MaxSize times MaxFiles. If zero,
+	// DefaultMaxFiles is used.
+	MaxFiles int
+}
+
+// Writer writes records to a dump file.
+//
+// Rotated files are renamed with a numbered suffix, path.1 being the most
+// recent one.
+//
+// A Writer is safe for concurrent use.
+type Writer struct {
+	path string
+	conf Config
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// NewWriter returns a new [Writer] writing to the file at path. The file is
+// truncated if it exists.
+func NewWriter(path string, c Config) (*Writer, error) {
+	if path == "" {
+		return nil, errors.New("event dump: empty path")
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = DefaultMaxSize
+	}
+	if c.MaxFiles <= 0 {
+		c.MaxFiles = DefaultMaxFiles
+	}
+
+	w := &Writer{path: path, conf: c, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes a record of the payload read by the probe identified by
+// probe. An error is returned if w is closed.
+func (w *Writer) Write(probe string, payload []byte) error {
+	line, err := json.Marshal(Record{Probe: probe, Time: w.now(), Payload: payload})
+	if err != nil {
+		return fmt.Errorf("event dump: %w", err)
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.file != nil && w.size > 0 && w.size+int64(len(line)) > w.conf.MaxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		// A previous rotation failed to open a new file.
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("event dump: %w", err)
+	}
+	return nil
+}
+
+// open opens the file at the path of w, truncating it.
+//
+// The mu lock needs to be held by the caller, if w is shared.
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("event dump: %w", err)
+	}
+	w.file, w.size = f, 0
+	return nil
+}
+
+// rotate closes the current file, renames it with the suffix .1, shifting the
+// suffix of the previous files, and opens a new file.
+//
+// The mu lock needs to be held by the caller.
+func (w *Writer) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+
+	// The oldest file is overwritten by the rename, or deleted if no file
+	// is kept but the one written to.
+	last := w.conf.MaxFiles - 1
+	if last == 0 {
+		err = errors.Join(err, os.Remove(w.path))
+	}
+	for i := last; i > 0; i-- {
+		src := w.path
+		if i > 1 {
+			src = w.path + "." + strconv.Itoa(i-1)
+		}
+		e := os.Rename(src, w.path+"."+strconv.Itoa(i))
+		if e != nil && !errors.Is(e, os.ErrNotExist) {
+			err = errors.Join(err, e)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("event dump: %w", err)
+	}
+	return errors.Join(err, w.open())
+}
+
+// Close closes the file written to. Records written after w is closed are
+// dropped.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return fmt.Errorf("event dump: %w", err)
+	}
+	return nil
+}
+
+// Reader reads records from a dump file.
+type Reader struct {
+	dec *json.Decoder
+}
+
+// NewReader returns a new [Reader] reading records from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{dec: json.NewDecoder(r)}
+}
+
+// Next returns the next record. It returns [io.EOF] when there are no more
+// records.
+func (r *Reader) Next() (Record, error) {
+	var rec Record
+	err := r.dec.Decode(&rec)
+	return rec, err
+}
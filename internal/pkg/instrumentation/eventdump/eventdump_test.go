@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package eventdump
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var out []Record
+	r := NewReader(f)
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		require.NoError(t, err)
+		out = append(out, rec)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("stale\n"), 0o600))
+
+	w, err := NewWriter(path, Config{})
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	require.NoError(t, w.Write("net/http/server", []byte{1, 2, 3}))
+	require.NoError(t, w.Write("net/http/client", nil))
+	require.NoError(t, w.Close())
+	assert.ErrorIs(t, w.Write("net/http/server", []byte{4}), os.ErrClosed)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, []Record{
+		{Probe: "net/http/server", Time: now, Payload: []byte{1, 2, 3}},
+		{Probe: "net/http/client", Time: now},
+	}, readRecords(t, path))
+}
+
+func TestWriterRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+
+	payload := make([]byte, 64)
+	w, err := NewWriter(path, Config{MaxSize: 150, MaxFiles: 3})
+	require.NoError(t, err)
+	// Each record is larger than half the maximum size, one record is written
+	// per file.
+	for i := range 5 {
+		payload[0] = byte(i)
+		require.NoError(t, w.Write("probe", payload))
+	}
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"events.jsonl", "events.jsonl.1", "events.jsonl.2"}, names)
+
+	for name, want := range map[string]byte{"events.jsonl": 4, "events.jsonl.1": 3, "events.jsonl.2": 2} {
+		recs := readRecords(t, filepath.Join(dir, name))
+		require.Len(t, recs, 1, name)
+		assert.Equal(t, want, recs[0].Payload[0], name)
+	}
+}
+
+func TestWriterRotateSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+
+	w, err := NewWriter(path, Config{MaxSize: 1, MaxFiles: 1})
+	require.NoError(t, err)
+	require.NoError(t, w.Write("probe", []byte{1}))
+	require.NoError(t, w.Write("probe", []byte{2}))
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	recs := readRecords(t, path)
+	require.Len(t, recs, 1)
+	assert.Equal(t, []byte{2}, recs[0].Payload)
+}
+
+func TestWriterConcurrentSafe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := NewWriter(path, Config{MaxSize: 512})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				assert.NoError(t, w.Write("probe", []byte("event")))
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, w.Close())
+}
+
+func TestNewWriterEmptyPath(t *testing.T) {
+	_, err := NewWriter("", Config{})
+	assert.Error(t, err)
+}
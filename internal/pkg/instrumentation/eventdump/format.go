@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package eventdump
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format returns a human-readable representation of the decoded event v, one
+// field per line.
+//
+// Byte arrays holding a NUL-terminated printable ASCII string, as the eBPF
+// programs write strings, are shown as quoted strings. Other byte arrays are
+// shown in hexadecimal.
+func Format(v any) string {
+	var b strings.Builder
+	format(&b, reflect.ValueOf(v), "")
+	return b.String()
+}
+
+// format writes v to b. The values of structs and arrays are written on the
+// following lines, indented, and other values on the current line.
+func format(b *strings.Builder, v reflect.Value, indent string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b.WriteString(" nil\n")
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		b.WriteByte('\n')
+		t := v.Type()
+		for i := range v.NumField() {
+			b.WriteString(indent + "  " + t.Field(i).Name + ":")
+			format(b, v.Field(i), indent+"  ")
+		}
+	case reflect.Array, reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b.WriteString(" " + formatBytes(bytesOf(v)) + "\n")
+			return
+		}
+		if v.Len() == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteByte('\n')
+		for i := range v.Len() {
+			b.WriteString(indent + "  [" + strconv.Itoa(i) + "]:")
+			format(b, v.Index(i), indent+"  ")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(" " + strconv.FormatInt(v.Int(), 10) + "\n")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(" " + strconv.FormatUint(v.Uint(), 10) + "\n")
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(" " + strconv.FormatFloat(v.Float(), 'g', -1, 64) + "\n")
+	case reflect.Bool:
+		b.WriteString(" " + strconv.FormatBool(v.Bool()) + "\n")
+	case reflect.String:
+		b.WriteString(" " + strconv.Quote(v.String()) + "\n")
+	case reflect.Invalid:
+		b.WriteString(" nil\n")
+	default:
+		// Values of unexported fields cannot be used with Interface.
+		fmt.Fprintf(b, " <%s>\n", v.Type())
+	}
+}
+
+func bytesOf(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	out := make([]byte, v.Len())
+	for i := range out {
+		out[i] = byte(v.Index(i).Uint())
+	}
+	return out
+}
+
+// formatBytes returns b as a quoted string if it holds a NUL-terminated
+// printable ASCII string, and as hexadecimal otherwise.
+func formatBytes(b []byte) string {
+	s := b
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		// The remaining bytes need to be NUL padding.
+		if bytes.Count(b[i:], []byte{0}) != len(b)-i {
+			return hex.EncodeToString(b)
+		}
+		s = b[:i]
+	}
+	if len(s) == 0 {
+		return `""`
+	}
+	for _, c := range s {
+		if c < ' ' || c > '~' {
+			return hex.EncodeToString(b)
+		}
+	}
+	return strconv.Quote(string(s))
+}
@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package eventdump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	type span struct {
+		TraceID [4]byte
+	}
+	type event struct {
+		span
+		StatusCode uint64
+		Method     [8]byte
+		Empty      [4]byte
+		Offset     int64
+		Ratio      float64
+		Ok         bool
+		Keys       [2][2]byte
+		Next       *event
+	}
+
+	got := Format(&event{
+		span:       span{TraceID: [4]byte{0xde, 0xad, 0xbe, 0xef}},
+		StatusCode: 200,
+		Method:     [8]byte{'G', 'E', 'T'},
+		Offset:     -1,
+		Ratio:      0.5,
+		Ok:         true,
+		Keys:       [2][2]byte{{'a'}, {0, 'b'}},
+	})
+	assert.Equal(t, `
+  span:
+    TraceID: deadbeef
+  StatusCode: 200
+  Method: "GET"
+  Empty: ""
+  Offset: -1
+  Ratio: 0.5
+  Ok: true
+  Keys:
+    [0]: "a"
+    [1]: 0062
+  Next: nil
+`, got)
+}
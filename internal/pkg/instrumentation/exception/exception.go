@@ -0,0 +1,184 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package exception provides the recording of the exceptions, such as the
+// panics, raised during spans produced by other probes.
+//
+// The exceptions are read when they are raised, before the span they are
+// raised in ends and is produced by its probe. They are buffered until the
+// span is handled, and then added to it as exception events.
+package exception
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+// MaxPending is the maximum number of spans with exceptions buffered. The
+// exceptions raised in other spans are dropped.
+const MaxPending = 1024
+
+// eventName is the name of the span events of exceptions.
+const eventName = "exception"
+
+// Exception is an exception raised during a span.
+type Exception struct {
+	// Time is the time the exception is raised at.
+	Time pcommon.Timestamp
+	// TraceID and SpanID identify the span the exception is raised in.
+	TraceID pcommon.TraceID
+	SpanID  pcommon.SpanID
+	// Flags are the trace flags of the span.
+	Flags uint32
+	// Type is the type of the exception (e.g. the type of a panic value).
+	Type string
+	// Message is the message of the exception, if known.
+	Message string
+}
+
+// Buffer buffers the exceptions raised in spans until the spans are handled.
+type Buffer struct {
+	mu      sync.Mutex
+	pending map[pcommon.SpanID][]Exception
+	// order are the span IDs of pending in the order they are first added.
+	order []pcommon.SpanID
+}
+
+// NewBuffer returns a new empty [Buffer].
+func NewBuffer() *Buffer {
+	return &Buffer{pending: make(map[pcommon.SpanID][]Exception)}
+}
+
+// Add buffers e until its span is handled. It returns false if e is dropped
+// because the exceptions of [MaxPending] other spans are already buffered.
+func (b *Buffer) Add(e Exception) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.pending[e.SpanID]; !ok {
+		if len(b.pending) >= MaxPending {
+			return false
+		}
+		b.order = append(b.order, e.SpanID)
+	}
+	b.pending[e.SpanID] = append(b.pending[e.SpanID], e)
+	return true
+}
+
+// take removes and returns the exceptions buffered for the span identified
+// by id.
+func (b *Buffer) take(id pcommon.SpanID) []Exception {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.pending) == 0 {
+		return nil
+	}
+	exc, ok := b.pending[id]
+	if ok {
+		delete(b.pending, id)
+		b.order = slices.DeleteFunc(b.order, func(o pcommon.SpanID) bool { return o == id })
+	}
+	return exc
+}
+
+// Flush removes the exceptions buffered for spans that have not been handled.
+// They are returned as INTERNAL "panic" spans, children of the span they are
+// raised in, with their exception events. Their span may never be handled,
+// e.g. when the process dies, or the panic is not recovered in the function
+// producing it.
+func (b *Buffer) Flush() ptrace.SpanSlice {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	spans := ptrace.NewSpanSlice()
+	for _, id := range b.order {
+		exc := b.pending[id]
+		if len(exc) == 0 {
+			continue
+		}
+
+		span := spans.AppendEmpty()
+		span.SetName("panic")
+		span.SetKind(ptrace.SpanKindInternal)
+		span.SetStartTimestamp(exc[0].Time)
+		span.SetEndTimestamp(exc[len(exc)-1].Time)
+		span.SetTraceID(exc[0].TraceID)
+		span.SetSpanID(newSpanID())
+		span.SetParentSpanID(id)
+		span.SetFlags(exc[0].Flags)
+		record(span, exc)
+	}
+	clear(b.pending)
+	b.order = b.order[:0]
+	return spans
+}
+
+// newSpanID returns a new random span ID.
+func newSpanID() pcommon.SpanID {
+	var id pcommon.SpanID
+	for id.IsEmpty() {
+		binary.NativeEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
+
+// record adds the exception events of exc to span and sets its status to
+// error.
+func record(span ptrace.Span, exc []Exception) {
+	for _, e := range exc {
+		event := span.Events().AppendEmpty()
+		event.SetName(eventName)
+		event.SetTimestamp(e.Time)
+		if e.Type != "" {
+			event.Attributes().PutStr(string(semconv.ExceptionTypeKey), e.Type)
+		}
+		if e.Message != "" {
+			event.Attributes().PutStr(string(semconv.ExceptionMessageKey), e.Message)
+		}
+	}
+	span.Status().SetCode(ptrace.StatusCodeError)
+	if msg := exc[len(exc)-1].Message; msg != "" {
+		span.Status().SetMessage(msg)
+	}
+}
+
+// Wrap returns a [pipeline.Handler] that adds the exceptions buffered in b to
+// the spans passed to it, before they are passed to h.
+func (b *Buffer) Wrap(h *pipeline.Handler) *pipeline.Handler {
+	if h == nil || h.TraceHandler == nil {
+		return h
+	}
+	return &pipeline.Handler{
+		TraceHandler:  &handler{next: h.TraceHandler, buf: b},
+		MetricHandler: h.MetricHandler,
+		LogHandler:    h.LogHandler,
+	}
+}
+
+// handler is a [pipeline.TraceHandler] that adds the buffered exceptions to
+// the spans they are raised in.
+type handler struct {
+	next pipeline.TraceHandler
+	buf  *Buffer
+}
+
+var _ pipeline.TraceHandler = (*handler)(nil)
+
+func (h *handler) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	for i := range spans.Len() {
+		span := spans.At(i)
+		if exc := h.buf.take(span.SpanID()); len(exc) > 0 {
+			record(span, exc)
+		}
+	}
+	h.next.HandleTrace(scope, url, spans)
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package exception
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+type recorder struct {
+	spans []ptrace.SpanSlice
+}
+
+func (r *recorder) HandleTrace(_ pcommon.InstrumentationScope, _ string, spans ptrace.SpanSlice) {
+	r.spans = append(r.spans, spans)
+}
+
+var (
+	traceID = pcommon.TraceID{1}
+	spanID  = pcommon.SpanID{1}
+)
+
+func newSpans(ids ...pcommon.SpanID) ptrace.SpanSlice {
+	spans := ptrace.NewSpanSlice()
+	for _, id := range ids {
+		span := spans.AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(id)
+	}
+	return spans
+}
+
+func TestBufferWrap(t *testing.T) {
+	b := NewBuffer()
+	rec := &recorder{}
+	h := b.Wrap(&pipeline.Handler{TraceHandler: rec})
+
+	require.True(t, b.Add(Exception{
+		Time:    1,
+		TraceID: traceID,
+		SpanID:  spanID,
+		Type:    "*errors.errorString",
+		Message: "boom",
+	}))
+	require.True(t, b.Add(Exception{Time: 2, TraceID: traceID, SpanID: spanID, Type: "runtime.boundsError"}))
+
+	h.TraceHandler.HandleTrace(pcommon.NewInstrumentationScope(), "", newSpans(pcommon.SpanID{2}, spanID))
+	require.Len(t, rec.spans, 1)
+	spans := rec.spans[0]
+
+	other := spans.At(0)
+	assert.Equal(t, ptrace.StatusCodeUnset, other.Status().Code())
+	assert.Equal(t, 0, other.Events().Len())
+
+	span := spans.At(1)
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, 2, span.Events().Len())
+
+	event := span.Events().At(0)
+	assert.Equal(t, "exception", event.Name())
+	assert.Equal(t, pcommon.Timestamp(1), event.Timestamp())
+	assert.Equal(t, map[string]any{
+		"exception.type":    "*errors.errorString",
+		"exception.message": "boom",
+	}, event.Attributes().AsRaw())
+	assert.Equal(t, map[string]any{
+		"exception.type": "runtime.boundsError",
+	}, span.Events().At(1).Attributes().AsRaw())
+
+	// Exceptions are only added once.
+	assert.Equal(t, 0, b.Flush().Len())
+}
+
+func TestBufferFlush(t *testing.T) {
+	b := NewBuffer()
+	b.Add(Exception{Time: 1, TraceID: traceID, SpanID: spanID, Flags: 1, Type: "string", Message: "boom"})
+
+	spans := b.Flush()
+	require.Equal(t, 1, spans.Len())
+	span := spans.At(0)
+	assert.Equal(t, "panic", span.Name())
+	assert.Equal(t, ptrace.SpanKindInternal, span.Kind())
+	assert.Equal(t, traceID, span.TraceID())
+	assert.Equal(t, spanID, span.ParentSpanID())
+	assert.False(t, span.SpanID().IsEmpty())
+	assert.NotEqual(t, spanID, span.SpanID())
+	assert.Equal(t, uint32(1), span.Flags())
+	assert.Equal(t, pcommon.Timestamp(1), span.StartTimestamp())
+	assert.Equal(t, pcommon.Timestamp(1), span.EndTimestamp())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "boom", span.Status().Message())
+	assert.Equal(t, 1, span.Events().Len())
+
+	assert.Equal(t, 0, b.Flush().Len(), "flushed twice")
+}
+
+func TestBufferMaxPending(t *testing.T) {
+	b := NewBuffer()
+	for i := range MaxPending {
+		id := pcommon.SpanID{byte(i), byte(i >> 8), 1}
+		require.True(t, b.Add(Exception{TraceID: traceID, SpanID: id}))
+	}
+	assert.False(t, b.Add(Exception{TraceID: traceID, SpanID: pcommon.SpanID{2}}), "other span")
+	assert.True(t, b.Add(Exception{TraceID: traceID, SpanID: pcommon.SpanID{0, 0, 1}}), "pending span")
+	assert.Equal(t, MaxPending, b.Flush().Len())
+}
+
+func TestBufferWrapNoTraceHandler(t *testing.T) {
+	b := NewBuffer()
+	h := &pipeline.Handler{}
+	assert.Same(t, h, b.Wrap(h))
+	assert.Nil(t, b.Wrap(nil))
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kernel
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Clock is a kernel clock eBPF programs read span timestamps from.
+type Clock uint8
+
+const (
+	// ClockBoottime is CLOCK_BOOTTIME, read in eBPF programs using
+	// bpf_ktime_get_boot_ns. This clock includes any time the system was
+	// suspended.
+	ClockBoottime Clock = iota
+	// ClockMonotonic is CLOCK_MONOTONIC, read in eBPF programs using
+	// bpf_ktime_get_ns. This clock does not advance while the system is
+	// suspended.
+	ClockMonotonic
+)
+
+// String returns the name of the clock.
+func (c Clock) String() string {
+	switch c {
+	case ClockBoottime:
+		return "CLOCK_BOOTTIME"
+	case ClockMonotonic:
+		return "CLOCK_MONOTONIC"
+	default:
+		return "CLOCK_UNKNOWN"
+	}
+}
+
+// clock is the clock currently assumed to be used by eBPF programs.
+var clock atomic.Uint32
+
+var detectClock = sync.OnceValue(func() Clock {
+	c := ClockMonotonic
+	if bootClockSupported() {
+		c = ClockBoottime
+	}
+	// Ignore errors. The offset estimated for the prior clock is kept.
+	_ = setClock(c)
+	return c
+})
+
+// BootClockSupported returns if the kernel supports the
+// bpf_ktime_get_boot_ns eBPF helper.
+//
+// The result of the first call determines the clock span timestamps are
+// converted from. If supported, eBPF programs are expected to timestamp spans
+// using bpf_ktime_get_boot_ns. Otherwise, they are expected to use
+// bpf_ktime_get_ns.
+func BootClockSupported() bool {
+	return detectClock() == ClockBoottime
+}
+
+// currentClock returns the clock span timestamps are converted from.
+func currentClock() Clock {
+	return Clock(clock.Load()) // nolint: gosec  // Only ever set from a Clock.
+}
+
+// setClock sets the clock span timestamps are converted from and
+// re-estimates the offset of that clock.
+func setClock(c Clock) error {
+	if Clock(clock.Swap(uint32(c))) == c { // nolint: gosec  // Only ever set from a Clock.
+		return nil
+	}
+	_, err := SyncBootOffset()
+	return err
+}
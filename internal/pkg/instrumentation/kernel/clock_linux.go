@@ -0,0 +1,28 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package kernel
+
+import (
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/features"
+	"golang.org/x/sys/unix"
+)
+
+// haveProgramHelper allows testing with a mock for features.HaveProgramHelper.
+var haveProgramHelper = features.HaveProgramHelper
+
+func bootClockSupported() bool {
+	return haveProgramHelper(ebpf.Kprobe, asm.FnKtimeGetBootNs) == nil
+}
+
+// clockID returns the POSIX clock ID of c.
+func clockID(c Clock) int32 {
+	if c == ClockMonotonic {
+		return unix.CLOCK_MONOTONIC
+	}
+	return unix.CLOCK_BOOTTIME
+}
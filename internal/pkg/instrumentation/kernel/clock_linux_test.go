@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package kernel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestBootClockSupported(t *testing.T) {
+	orig := haveProgramHelper
+	t.Cleanup(func() { haveProgramHelper = orig })
+
+	haveProgramHelper = func(ebpf.ProgramType, asm.BuiltinFunc) error { return nil }
+	assert.True(t, bootClockSupported())
+
+	haveProgramHelper = func(ebpf.ProgramType, asm.BuiltinFunc) error {
+		return errors.New("not supported")
+	}
+	assert.False(t, bootClockSupported())
+}
+
+func TestClockConversion(t *testing.T) {
+	origOffsets, origClock := bootOffsets, currentClock()
+	t.Cleanup(func() {
+		bootOffsets = origOffsets
+		clock.Store(uint32(origClock))
+	})
+
+	for _, c := range []Clock{ClockBoottime, ClockMonotonic} {
+		t.Run(c.String(), func(t *testing.T) {
+			// Simulate a mismatch: start from the offset of the other clock.
+			bootOffsets = newOffsetTable(0)
+			clock.Store(uint32(ClockMonotonic - c))
+
+			// The offset is estimated from times read after before, each
+			// ahead of the clock reading it is paired with. A later clock
+			// reading is then converted to a time between before and after.
+			before := time.Now()
+			require.NoError(t, setClock(c))
+			assert.Equal(t, c, currentClock())
+
+			var ts unix.Timespec
+			require.NoError(t, unix.ClockGettime(clockID(c), &ts))
+			after := time.Now()
+
+			got := bootOffsetToTime(uint64(ts.Nano())) // nolint: gosec  // Positive.
+			assert.False(t, got.Before(before), "%v before %v", got, before)
+			assert.False(t, got.After(after), "%v after %v", got, after)
+		})
+	}
+}
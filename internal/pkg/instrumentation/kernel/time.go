@@ -4,20 +4,134 @@
 package kernel
 
 import (
+	"context"
+	"log/slog"
 	"math"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 )
 
-var bootTimeOffset = func() int64 {
-	o, err := estimateBootTimeOffset()
+const (
+	// DefaultBootOffsetSyncInterval is the default interval the boot time
+	// offset is re-estimated at by [RunBootOffsetSync].
+	DefaultBootOffsetSyncInterval = time.Minute
+
+	// stepCheckInterval is the interval the realtime clock is checked for a
+	// step change.
+	stepCheckInterval = time.Second
+	// stepThreshold is the difference between the elapsed realtime and
+	// elapsed monotonic time over stepCheckInterval that is considered a step
+	// of the realtime clock.
+	stepThreshold = 50 * time.Millisecond
+
+	// maxOffsetSegments is the maximum number of offset segments retained.
+	// Conversions of boot offsets from before the oldest segment use that
+	// oldest segment.
+	maxOffsetSegments = 32
+)
+
+var bootOffsets = func() *offsetTable {
+	o, err := estimateBootTimeOffset(currentClock())
 	if err != nil {
 		panic(err)
 	}
-	return o
+	return newOffsetTable(o)
 }()
 
+// offsetSegment is the boot time offset applied to boot offsets starting at a
+// point in time.
+type offsetSegment struct {
+	// from is the boot offset, in nanoseconds, the segment applies from.
+	from uint64
+	// offset is the estimated number of nanoseconds between the Unix epoch and
+	// the zero value of the kernel clock.
+	offset int64
+	// floor is the minimum Unix time, in nanoseconds, the segment produces.
+	// It ensures conversions never move backwards in time when the offset is
+	// reduced.
+	floor int64
+}
+
+// offsetTable converts boot offsets to Unix time using the boot time offset
+// that was in effect at the time of the boot offset.
+//
+// Conversions are monotonic: if a <= b, then convert(a) <= convert(b). This
+// guarantees that span end times are not before their start times and child
+// spans remain within their parent even when the offset is updated while the
+// spans are active.
+type offsetTable struct {
+	mu   sync.RWMutex
+	segs []offsetSegment
+}
+
+func newOffsetTable(offset int64) *offsetTable {
+	return &offsetTable{
+		segs: []offsetSegment{{offset: offset, floor: math.MinInt64}},
+	}
+}
+
+// current returns the most recent offset.
+func (t *offsetTable) current() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.segs[len(t.segs)-1].offset
+}
+
+// update sets offset as the boot time offset for all boot offsets greater
+// than or equal to from. It returns the difference between the new and the
+// previous offset.
+func (t *offsetTable) update(from uint64, offset int64) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last := t.segs[len(t.segs)-1]
+	if from < last.from {
+		// Never rewrite history.
+		from = last.from
+	}
+
+	floor := max(last.floor, last.unix(from))
+	seg := offsetSegment{from: from, offset: offset, floor: floor}
+	if from == last.from {
+		t.segs[len(t.segs)-1] = seg
+	} else {
+		t.segs = append(t.segs, seg)
+	}
+
+	if n := len(t.segs); n > maxOffsetSegments {
+		t.segs = append(t.segs[:0], t.segs[n-maxOffsetSegments:]...)
+	}
+	return offset - last.offset
+}
+
+// unix returns the Unix time, in nanoseconds, of nsec.
+func (t *offsetTable) unix(nsec uint64) int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	i := len(t.segs) - 1
+	for i > 0 && t.segs[i].from > nsec {
+		i--
+	}
+	return t.segs[i].unix(nsec)
+}
+
+func (s offsetSegment) unix(nsec uint64) int64 {
+	if nsec > math.MaxInt64 {
+		nsec = math.MaxInt64
+	}
+	n := int64(nsec) // nolint: gosec  // Bound checked.
+	var unix int64
+	if s.offset > 0 && n > math.MaxInt64-s.offset {
+		unix = math.MaxInt64
+	} else {
+		unix = s.offset + n
+	}
+	return max(unix, s.floor)
+}
+
 // BootOffsetToTimestamp returns the [pcommon.Timestamp] that is nsec number of
 // nanoseconds after the estimated boot time of the system.
 func BootOffsetToTimestamp(nsec uint64) pcommon.Timestamp {
@@ -27,18 +141,84 @@ func BootOffsetToTimestamp(nsec uint64) pcommon.Timestamp {
 // bootOffsetToTime returns the timestamp that is nsec number of nanoseconds
 // after the estimated boot time of the system.
 func bootOffsetToTime(nsec uint64) time.Time {
-	if nsec > math.MaxInt64 {
-		nsec = math.MaxInt64
-	}
-	return time.Unix(0, bootTimeOffset+int64(nsec)) // nolint: gosec  // Bound checked.
+	return time.Unix(0, bootOffsets.unix(nsec))
 }
 
 // TimeToBootOffset returns the number of nanoseconds after the estimated boot
 // time of the process that the timestamp represent.
 func TimeToBootOffset(timestamp time.Time) uint64 {
-	nsec := timestamp.UnixNano() - bootTimeOffset
+	nsec := timestamp.UnixNano() - bootOffsets.current()
 	if nsec < 0 {
 		return 0
 	}
 	return uint64(nsec)
 }
+
+// SyncBootOffset re-estimates the boot time offset and applies it to all
+// subsequent conversions. The difference between the new and the previous
+// offset is returned.
+func SyncBootOffset() (time.Duration, error) {
+	// The offset applies from before its estimation, not to convert the
+	// boot offsets read right after it with the previous offset.
+	start := time.Now()
+	o, err := estimateBootTimeOffset(currentClock())
+	if err != nil {
+		return 0, err
+	}
+
+	now := start.UnixNano() - o
+	if now < 0 {
+		now = 0
+	}
+	delta := bootOffsets.update(uint64(now), o) // nolint: gosec  // Bound checked.
+	return time.Duration(delta), nil
+}
+
+// RunBootOffsetSync re-estimates the boot time offset every interval, and
+// whenever a step of the realtime clock is detected, until ctx is done. The
+// change of the offset is passed to synced after each re-estimation.
+func RunBootOffsetSync(ctx context.Context, l *slog.Logger, interval time.Duration, synced func(delta time.Duration)) {
+	if interval <= 0 {
+		interval = DefaultBootOffsetSyncInterval
+	}
+
+	resync := time.NewTicker(interval)
+	defer resync.Stop()
+	stepCheck := time.NewTicker(stepCheckInterval)
+	defer stepCheck.Stop()
+
+	doSync := func(reason string) {
+		delta, err := SyncBootOffset()
+		if err != nil {
+			l.Error("failed to resynchronize boot time offset", "error", err)
+			return
+		}
+		l.Debug("resynchronized boot time offset", "reason", reason, "delta", delta)
+		synced(delta)
+	}
+
+	// Values returned from time.Now contain a monotonic clock reading. The
+	// difference between the wall clock and monotonic elapsed time identifies
+	// a step of the realtime clock.
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-resync.C:
+			doSync("periodic")
+		case <-stepCheck.C:
+			now := time.Now()
+			mono := now.Sub(last)
+			wall := now.Round(0).Sub(last.Round(0))
+			last = now
+
+			if step := wall - mono; step > stepThreshold || step < -stepThreshold {
+				l.Info("realtime clock step detected", "step", step)
+				doSync("clock step")
+				// Do not let the resync time affect the next step check.
+				last = time.Now()
+			}
+		}
+	}
+}
@@ -12,10 +12,13 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-func estimateBootTimeOffset() (bootTimeOffset int64, err error) {
-	// The datapath is currently using ktime_get_boot_ns for the pcap timestamp,
-	// which corresponds to CLOCK_BOOTTIME. To be able to convert the the
-	// CLOCK_BOOTTIME to CLOCK_REALTIME (i.e. a unix timestamp).
+// estimateBootTimeOffset returns the estimated number of nanoseconds between
+// the Unix epoch and the zero value of clock c.
+func estimateBootTimeOffset(c Clock) (bootTimeOffset int64, err error) {
+	// eBPF programs timestamp spans using either bpf_ktime_get_boot_ns
+	// (CLOCK_BOOTTIME) or bpf_ktime_get_ns (CLOCK_MONOTONIC). To convert
+	// these timestamps to CLOCK_REALTIME (i.e. a unix timestamp), the offset
+	// between the same clock and CLOCK_REALTIME needs to be known.
 
 	// There can be an arbitrary amount of time between the execution of
 	// time.Now() and unix.ClockGettime() below, especially under scheduler
@@ -34,10 +37,10 @@ func estimateBootTimeOffset() (bootTimeOffset int64, err error) {
 		// Ideally we would use __vdso_clock_gettime for both clocks here,
 		// to have as little overhead as possible.
 		// time.Now() will actually use VDSO on Go 1.9+, but calling
-		// unix.ClockGettime to obtain CLOCK_BOOTTIME is a regular system call
-		// for now.
+		// unix.ClockGettime to obtain the kernel clock is a regular system
+		// call for now.
 		unixTime := time.Now()
-		err = unix.ClockGettime(unix.CLOCK_BOOTTIME, &bootTimespec)
+		err = unix.ClockGettime(clockID(c), &bootTimespec)
 		if err != nil {
 			return 0, err
 		}
@@ -5,4 +5,4 @@
 
 package kernel
 
-func estimateBootTimeOffset() (int64, error) { return 0, nil }
+func estimateBootTimeOffset(Clock) (int64, error) { return 0, nil }
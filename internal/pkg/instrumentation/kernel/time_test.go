@@ -13,6 +13,7 @@ import (
 
 func TestBootOffsetConversion(t *testing.T) {
 	const sec = 1e3
+	bootTimeOffset := bootOffsets.current()
 	nsec := 9328646329 + bootTimeOffset
 
 	timestamp := time.Unix(sec, nsec)
@@ -26,3 +27,77 @@ func TestBootOffsetConversion(t *testing.T) {
 	assert.Equal(t, offset, TimeToBootOffset(timestamp), "TimeToBootOffset")
 	assert.Equal(t, timestamp, bootOffsetToTime(offset), "BootOffsetToTime")
 }
+
+func TestOffsetTableUpdate(t *testing.T) {
+	const (
+		initial = int64(1_000_000)
+		from    = uint64(500)
+	)
+
+	t.Run("Increase", func(t *testing.T) {
+		tbl := newOffsetTable(initial)
+		assert.Equal(t, int64(100), tbl.update(from, initial+100))
+
+		assert.Equal(t, initial+int64(from-1), tbl.unix(from-1), "before update")
+		assert.Equal(t, initial+100+int64(from), tbl.unix(from), "after update")
+		assert.Equal(t, initial+100, tbl.current())
+	})
+
+	t.Run("Decrease", func(t *testing.T) {
+		tbl := newOffsetTable(initial)
+		assert.Equal(t, int64(-100), tbl.update(from, initial-100))
+
+		// Conversions hold at the last value until the new offset catches up.
+		before := tbl.unix(from - 1)
+		assert.Equal(t, initial+int64(from-1), before)
+		assert.Equal(t, initial+int64(from), tbl.unix(from))
+		assert.Equal(t, initial+int64(from), tbl.unix(from+50))
+		assert.Equal(t, initial-100+int64(from+200), tbl.unix(from+200))
+	})
+
+	t.Run("Monotonic", func(t *testing.T) {
+		tbl := newOffsetTable(initial)
+		tbl.update(100, initial+5000)
+		tbl.update(200, initial-5000)
+		tbl.update(300, initial+10)
+
+		prev := tbl.unix(0)
+		for n := uint64(1); n < 20000; n++ {
+			got := tbl.unix(n)
+			require.GreaterOrEqualf(t, got, prev, "conversion of %d went backwards", n)
+			prev = got
+		}
+	})
+
+	t.Run("History", func(t *testing.T) {
+		tbl := newOffsetTable(initial)
+		tbl.update(from, initial+100)
+		tbl.update(from/2, initial+200)
+
+		// An update from before the last one must not rewrite history.
+		assert.Equal(t, initial+int64(from-1), tbl.unix(from-1))
+		assert.Equal(t, initial+200+int64(from), tbl.unix(from))
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		tbl := newOffsetTable(initial)
+		for i := range 2 * maxOffsetSegments {
+			tbl.update(uint64(i+1)*10, initial+int64(i)) // nolint: gosec  // Test value.
+		}
+		assert.Len(t, tbl.segs, maxOffsetSegments)
+	})
+}
+
+func TestSyncBootOffset(t *testing.T) {
+	orig := bootOffsets
+	t.Cleanup(func() { bootOffsets = orig })
+	bootOffsets = newOffsetTable(orig.current())
+
+	start := BootOffsetToTimestamp(TimeToBootOffset(time.Now()))
+
+	_, err := SyncBootOffset()
+	require.NoError(t, err)
+
+	end := BootOffsetToTimestamp(TimeToBootOffset(time.Now()))
+	assert.GreaterOrEqual(t, end, start)
+}
@@ -0,0 +1,930 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+// DefaultLintLogInterval is the default minimum interval between two logs of
+// the same semantic convention violation.
+const DefaultLintLogInterval = time.Minute
+
+// LintConfig configures the semantic convention linting of spans.
+type LintConfig struct {
+	// LogInterval is the minimum interval between two logs of the same
+	// violation. If zero, DefaultLintLogInterval is used.
+	LogInterval time.Duration
+}
+
+// semconvAttr is the semantic convention of a span attribute.
+type semconvAttr struct {
+	key      string
+	typ      pcommon.ValueType
+	required bool
+	// values are the valid values of an enum attribute. Any value is valid
+	// if empty.
+	values []string
+}
+
+// semconvClass is the semantic conventions of a class of spans.
+type semconvClass struct {
+	name string
+	// scope is the instrumentation scope name of the spans of the class.
+	scope string
+	kind  ptrace.SpanKind
+	attrs []semconvAttr
+}
+
+var httpMethods = []string{
+	"CONNECT", "DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT", "TRACE", "_OTHER",
+}
+
+var (
+	rpcSystems             = []string{"grpc", "aws-api", "twirp", "go_net_rpc"}
+	dbSystems              = []string{"redis", "mongodb", "postgresql", "elasticsearch", "memcached", "cassandra", "etcd", "clickhouse", "influxdb", "bbolt", "badger"}
+	messagingSystems       = []string{"kafka", "nats", "rabbitmq", "gcp_pubsub", "redis", "mqtt"}
+	messagingOperationType = []string{"create", "send", "receive", "process", "settle"}
+	graphqlOperationType   = []string{"query", "mutation", "subscription"}
+)
+
+// semconvClasses are the semantic conventions of the spans produced by the
+// probes. Spans of other scopes are not linted. A scope has one class per kind
+// of span.
+//
+// Keep the table up to date with the semantic conventions version the probes
+// use when they change.
+var semconvClasses = []semconvClass{
+	{
+		name:  "http.server",
+		scope: "go.opentelemetry.io/auto/net/http/server",
+		kind:  ptrace.SpanKindServer,
+		attrs: []semconvAttr{
+			{key: "http.request.method", typ: pcommon.ValueTypeStr, required: true, values: httpMethods},
+			{key: "url.path", typ: pcommon.ValueTypeStr, required: true},
+			{key: "http.response.status_code", typ: pcommon.ValueTypeInt},
+			{key: "http.route", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "client.address", typ: pcommon.ValueTypeStr},
+			{key: "client.port", typ: pcommon.ValueTypeInt},
+			{key: "network.protocol.name", typ: pcommon.ValueTypeStr},
+			{key: "network.protocol.version", typ: pcommon.ValueTypeStr},
+			// Requests handled by Twirp services, or proxied to gRPC
+			// services by grpc-gateway.
+			{key: "rpc.system", typ: pcommon.ValueTypeStr, values: rpcSystems},
+			{key: "rpc.service", typ: pcommon.ValueTypeStr},
+			{key: "rpc.method", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "http.server",
+		scope: "go.opentelemetry.io/auto/github.com/valyala/fasthttp/server",
+		kind:  ptrace.SpanKindServer,
+		attrs: []semconvAttr{
+			{key: "http.request.method", typ: pcommon.ValueTypeStr, required: true, values: httpMethods},
+			{key: "url.path", typ: pcommon.ValueTypeStr, required: true},
+			{key: "http.response.status_code", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "client.address", typ: pcommon.ValueTypeStr},
+			{key: "client.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "graphql.server",
+		scope: "go.opentelemetry.io/auto/github.com/99designs/gqlgen/graphql/handler/internal",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "graphql.operation.type", typ: pcommon.ValueTypeStr, values: graphqlOperationType},
+			{key: "graphql.operation.name", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "http.client",
+		scope: "go.opentelemetry.io/auto/net/http/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "http.request.method", typ: pcommon.ValueTypeStr, required: true, values: httpMethods},
+			{key: "url.full", typ: pcommon.ValueTypeStr, required: true},
+			{key: "http.response.status_code", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "error.type", typ: pcommon.ValueTypeStr},
+			{key: "network.protocol.name", typ: pcommon.ValueTypeStr},
+			{key: "network.protocol.version", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "http.client",
+		scope: "go.opentelemetry.io/auto/github.com/valyala/fasthttp/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "http.request.method", typ: pcommon.ValueTypeStr, required: true, values: httpMethods},
+			{key: "url.full", typ: pcommon.ValueTypeStr, required: true},
+			{key: "http.response.status_code", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "error.type", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "rpc.server",
+		scope: "go.opentelemetry.io/auto/google.golang.org/grpc/server",
+		kind:  ptrace.SpanKindServer,
+		attrs: []semconvAttr{
+			{key: "rpc.system", typ: pcommon.ValueTypeStr, required: true, values: rpcSystems},
+			{key: "rpc.service", typ: pcommon.ValueTypeStr},
+			{key: "rpc.grpc.status_code", typ: pcommon.ValueTypeInt, required: true},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "rpc.client",
+		scope: "go.opentelemetry.io/auto/google.golang.org/grpc/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "rpc.system", typ: pcommon.ValueTypeStr, required: true, values: rpcSystems},
+			{key: "rpc.service", typ: pcommon.ValueTypeStr},
+			{key: "rpc.grpc.status_code", typ: pcommon.ValueTypeInt, required: true},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "rpc.server",
+		scope: "go.opentelemetry.io/auto/net/rpc/server",
+		kind:  ptrace.SpanKindServer,
+		attrs: []semconvAttr{
+			{key: "rpc.system", typ: pcommon.ValueTypeStr, required: true, values: rpcSystems},
+			{key: "rpc.service", typ: pcommon.ValueTypeStr},
+			{key: "rpc.method", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "rpc.client",
+		scope: "go.opentelemetry.io/auto/net/rpc/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "rpc.system", typ: pcommon.ValueTypeStr, required: true, values: rpcSystems},
+			{key: "rpc.service", typ: pcommon.ValueTypeStr},
+			{key: "rpc.method", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "dns.lookup",
+		scope: "go.opentelemetry.io/auto/net/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "dns.question.name", typ: pcommon.ValueTypeStr, required: true},
+			{key: "dns.address.count", typ: pcommon.ValueTypeInt},
+			{key: "dns.resolver", typ: pcommon.ValueTypeStr, values: []string{"go", "cgo"}},
+			{key: "error.type", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "ssh.client",
+		scope: "go.opentelemetry.io/auto/golang.org/x/crypto/ssh/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "ssh.user", typ: pcommon.ValueTypeStr},
+			{key: "ssh.command", typ: pcommon.ValueTypeStr},
+			{key: "error.type", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/database/sql/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.query.text", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "gorm.rows_affected", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.transaction",
+		scope: "go.opentelemetry.io/auto/database/sql/client",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: []string{"other_sql"}},
+			{key: "db.transaction.isolation_level", typ: pcommon.ValueTypeStr},
+			{key: "db.transaction.outcome", typ: pcommon.ValueTypeStr, values: []string{"commit", "rollback"}},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/redis/go-redis/v9/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.batch.size", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/go.mongodb.org/mongo-driver/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "db.namespace", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/go.mongodb.org/mongo-driver/v2/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "db.namespace", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/jackc/pgx/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.query.text", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/elastic/go-elasticsearch/v7/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "db.response.status_code", typ: pcommon.ValueTypeStr},
+			{key: "http.request.method", typ: pcommon.ValueTypeStr, values: httpMethods},
+			{key: "url.path", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/elastic/go-elasticsearch/v8/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "db.response.status_code", typ: pcommon.ValueTypeStr},
+			{key: "http.request.method", typ: pcommon.ValueTypeStr, values: httpMethods},
+			{key: "url.path", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/bradfitz/gomemcache/memcache/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.batch.size", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/gocql/gocql/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.namespace", typ: pcommon.ValueTypeStr},
+			{key: "db.query.text", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.batch.size", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/go.etcd.io/etcd/client/v3/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr, values: []string{"Range", "Put", "DeleteRange", "Txn"}},
+		},
+	},
+	{
+		name:  "rpc.client",
+		scope: "go.opentelemetry.io/auto/github.com/aws/aws-sdk-go-v2/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "rpc.system", typ: pcommon.ValueTypeStr, required: true, values: rpcSystems},
+			{key: "rpc.service", typ: pcommon.ValueTypeStr},
+			{key: "rpc.method", typ: pcommon.ValueTypeStr},
+			{key: "aws.region", typ: pcommon.ValueTypeStr},
+			{key: "http.response.status_code", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/segmentio/kafka-go/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+			{key: "messaging.batch.message_count", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/segmentio/kafka-go/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+			{key: "messaging.consumer.group.name", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/IBM/sarama/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/IBM/sarama/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/Shopify/sarama/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/Shopify/sarama/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/nats-io/nats.go/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/nats-io/nats.go/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/eclipse/paho.mqtt.golang/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.mqtt.qos", typ: pcommon.ValueTypeInt},
+			{key: "messaging.mqtt.retained", typ: pcommon.ValueTypeBool},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/eclipse/paho.mqtt.golang/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.mqtt.qos", typ: pcommon.ValueTypeInt},
+			{key: "messaging.mqtt.retained", typ: pcommon.ValueTypeBool},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/eclipse/paho.golang/paho/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.mqtt.qos", typ: pcommon.ValueTypeInt},
+			{key: "messaging.mqtt.retained", typ: pcommon.ValueTypeBool},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/rabbitmq/amqp091-go/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.rabbitmq.destination.routing_key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/rabbitmq/amqp091-go/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.rabbitmq.destination.routing_key", typ: pcommon.ValueTypeStr},
+			{key: "messaging.rabbitmq.message.delivery_tag", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/cloud.google.com/go/pubsub/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.gcp_pubsub.message.ordering_key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/cloud.google.com/go/pubsub/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.gcp_pubsub.message.ordering_key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/confluentinc/confluent-kafka-go/v2/kafka/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/confluentinc/confluent-kafka-go/v2/kafka/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.partition.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.kafka.offset", typ: pcommon.ValueTypeInt},
+			{key: "messaging.kafka.message.key", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "http.reverse_proxy",
+		scope: "go.opentelemetry.io/auto/net/http/httputil/internal",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "url.full", typ: pcommon.ValueTypeStr},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "websocket.message",
+		scope: "go.opentelemetry.io/auto/github.com/gorilla/websocket/internal",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "network.io.direction", typ: pcommon.ValueTypeStr, required: true, values: []string{"transmit", "receive"}},
+			{key: "websocket.message.size", typ: pcommon.ValueTypeInt, required: true},
+			{key: "websocket.message.type", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "k8s.client",
+		scope: "go.opentelemetry.io/auto/k8s.io/client-go/rest/internal",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "k8s.verb", typ: pcommon.ValueTypeStr, required: true},
+			{key: "k8s.watch", typ: pcommon.ValueTypeBool, required: true},
+			{key: "k8s.resource", typ: pcommon.ValueTypeStr},
+			{key: "k8s.subresource", typ: pcommon.ValueTypeStr},
+			{key: "k8s.namespace", typ: pcommon.ValueTypeStr},
+			{key: "k8s.watch.event.type", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/redis/rueidis/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.batch.size", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "network.peer.address", typ: pcommon.ValueTypeStr},
+			{key: "network.peer.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/valkey-io/valkey-go/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.batch.size", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "network.peer.address", typ: pcommon.ValueTypeStr},
+			{key: "network.peer.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/ClickHouse/clickhouse-go/v2/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.query.text", typ: pcommon.ValueTypeStr},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.collection.name", typ: pcommon.ValueTypeStr},
+			{key: "db.namespace", typ: pcommon.ValueTypeStr},
+			{key: "clickhouse.batch.rows", typ: pcommon.ValueTypeInt},
+			{key: "server.address", typ: pcommon.ValueTypeStr},
+			{key: "server.port", typ: pcommon.ValueTypeInt},
+			{key: "network.peer.address", typ: pcommon.ValueTypeStr},
+			{key: "network.peer.port", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.client",
+		scope: "go.opentelemetry.io/auto/github.com/influxdata/influxdb-client-go/v2/client",
+		kind:  ptrace.SpanKindClient,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "db.query.text", typ: pcommon.ValueTypeStr},
+			{key: "influxdb.org", typ: pcommon.ValueTypeStr},
+			{key: "influxdb.bucket", typ: pcommon.ValueTypeStr},
+			{key: "influxdb.points", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "db.transaction",
+		scope: "go.opentelemetry.io/auto/go.etcd.io/bbolt/internal",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr, required: true, values: []string{"commit", "rollback"}},
+			{key: "bbolt.tx.writable", typ: pcommon.ValueTypeBool, required: true},
+			{key: "bbolt.path", typ: pcommon.ValueTypeStr},
+			{key: "bbolt.commit.write.duration", typ: pcommon.ValueTypeDouble},
+			{key: "bbolt.commit.sync.duration", typ: pcommon.ValueTypeDouble},
+		},
+	},
+	{
+		name:  "db.transaction",
+		scope: "go.opentelemetry.io/auto/github.com/dgraph-io/badger/v4/internal",
+		kind:  ptrace.SpanKindInternal,
+		attrs: []semconvAttr{
+			{key: "db.system.name", typ: pcommon.ValueTypeStr, required: true, values: dbSystems},
+			{key: "db.operation.name", typ: pcommon.ValueTypeStr, required: true, values: []string{"commit", "gc"}},
+			{key: "badger.txn.entries", typ: pcommon.ValueTypeInt},
+			{key: "badger.txn.bytes", typ: pcommon.ValueTypeInt},
+			{key: "badger.gc.files_rewritten", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/nats-io/nats.go/jetstream/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.consumer.group.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.nats.stream.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.nats.stream.sequence", typ: pcommon.ValueTypeInt},
+			{key: "messaging.nats.consumer.sequence", typ: pcommon.ValueTypeInt},
+			{key: "messaging.nats.delivery.count", typ: pcommon.ValueTypeInt},
+			{key: "messaging.nats.ack.outcome", typ: pcommon.ValueTypeStr},
+		},
+	},
+	{
+		name:  "messaging.producer",
+		scope: "go.opentelemetry.io/auto/github.com/hibiken/asynq/producer",
+		kind:  ptrace.SpanKindProducer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+		},
+	},
+	{
+		name:  "messaging.consumer",
+		scope: "go.opentelemetry.io/auto/github.com/hibiken/asynq/consumer",
+		kind:  ptrace.SpanKindConsumer,
+		attrs: []semconvAttr{
+			{key: "messaging.system", typ: pcommon.ValueTypeStr, required: true, values: messagingSystems},
+			{key: "messaging.operation.type", typ: pcommon.ValueTypeStr, required: true, values: messagingOperationType},
+			{key: "messaging.operation.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.destination.name", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.id", typ: pcommon.ValueTypeStr},
+			{key: "messaging.message.body.size", typ: pcommon.ValueTypeInt},
+			{key: "messaging.asynq.task.retry_count", typ: pcommon.ValueTypeInt},
+			{key: "messaging.asynq.task.max_retry", typ: pcommon.ValueTypeInt},
+		},
+	},
+}
+
+// semconvViolation is a kind of semantic convention violation.
+type semconvViolation uint8
+
+const (
+	// semconvMissing is a missing required attribute.
+	semconvMissing semconvViolation = iota
+	// semconvType is an attribute with an invalid type.
+	semconvType
+	// semconvValue is an enum attribute with an invalid value.
+	semconvValue
+	// semconvKind is a span with an invalid span kind.
+	semconvKind
+)
+
+func (v semconvViolation) String() string {
+	switch v {
+	case semconvMissing:
+		return "missing_attribute"
+	case semconvType:
+		return "invalid_type"
+	case semconvValue:
+		return "invalid_value"
+	case semconvKind:
+		return "invalid_kind"
+	default:
+		return "unknown"
+	}
+}
+
+// lintIssue is a semantic convention violation found in a span.
+type lintIssue struct {
+	class     string
+	violation semconvViolation
+	// key is the attribute key of the violation, empty for semconvKind.
+	key string
+}
+
+var (
+	spanClassKey = attribute.Key("span.class")
+	attrKeyKey   = attribute.Key("attribute")
+)
+
+// WithSemconvLint returns a copy of h that checks spans against the semantic
+// conventions of the probe that produced them before passing them to the
+// TraceHandler of h. Spans are passed unchanged, each violation is counted
+// and logged at most once per log interval of c.
+//
+// If h does not have a TraceHandler, h is returned.
+func WithSemconvLint(l *slog.Logger, h *pipeline.Handler, c LintConfig) *pipeline.Handler {
+	if h == nil || h.TraceHandler == nil {
+		return h
+	}
+
+	if c.LogInterval <= 0 {
+		c.LogInterval = DefaultLintLogInterval
+	}
+
+	classes := make(map[string][]*semconvClass, len(semconvClasses))
+	for i := range semconvClasses {
+		c := &semconvClasses[i]
+		classes[c.scope] = append(classes[c.scope], c)
+	}
+
+	return &pipeline.Handler{
+		TraceHandler: &linter{
+			next:    h.TraceHandler,
+			logger:  l,
+			classes: classes,
+			violations: newCounter(
+				h,
+				"otel.auto.span.semconv_violations",
+				"{violation}",
+				"Number of semantic convention violations found in spans before export.",
+			),
+			logInterval: c.LogInterval,
+			now:         time.Now,
+			logged:      make(map[lintIssue]time.Time),
+		},
+		MetricHandler: h.MetricHandler,
+		LogHandler:    h.LogHandler,
+	}
+}
+
+// linter is a [pipeline.TraceHandler] that checks spans against their
+// semantic conventions before they are passed to the next handler.
+type linter struct {
+	next   pipeline.TraceHandler
+	logger *slog.Logger
+
+	// classes are the semantic convention classes of the spans of a scope.
+	classes    map[string][]*semconvClass
+	violations *counter
+
+	logInterval time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	logged map[lintIssue]time.Time
+}
+
+var _ pipeline.TraceHandler = (*linter)(nil)
+
+func (l *linter) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	if classes, ok := l.classes[scope.Name()]; ok {
+		var issues []lintIssue
+		for i := range spans.Len() {
+			s := spans.At(i)
+			issues = spanClass(classes, s).lint(issues[:0], s)
+			for _, issue := range issues {
+				l.record(scope, s, issue)
+			}
+		}
+	}
+	l.next.HandleTrace(scope, url, spans)
+}
+
+// spanClass returns the class of the span s among the classes of its scope:
+// the one of its kind, or the first one if there is none.
+func spanClass(classes []*semconvClass, s ptrace.Span) *semconvClass {
+	for _, c := range classes {
+		if c.kind == s.Kind() {
+			return c
+		}
+	}
+	return classes[0]
+}
+
+// lint appends the violations of the semantic conventions of c found in s to
+// dest and returns it.
+func (c *semconvClass) lint(dest []lintIssue, s ptrace.Span) []lintIssue {
+	if s.Kind() != c.kind {
+		dest = append(dest, lintIssue{class: c.name, violation: semconvKind})
+	}
+
+	attrs := s.Attributes()
+	for _, a := range c.attrs {
+		v, ok := attrs.Get(a.key)
+		switch {
+		case !ok:
+			if a.required {
+				dest = append(dest, lintIssue{class: c.name, violation: semconvMissing, key: a.key})
+			}
+		case v.Type() != a.typ:
+			dest = append(dest, lintIssue{class: c.name, violation: semconvType, key: a.key})
+		case len(a.values) > 0 && !slices.Contains(a.values, v.Str()):
+			dest = append(dest, lintIssue{class: c.name, violation: semconvValue, key: a.key})
+		}
+	}
+	return dest
+}
+
+func (l *linter) record(scope pcommon.InstrumentationScope, s ptrace.Span, issue lintIssue) {
+	l.violations.Add(
+		1,
+		scopeNameKey.String(scope.Name()),
+		spanClassKey.String(issue.class),
+		violationKey.String(issue.violation.String()),
+		attrKeyKey.String(issue.key),
+	)
+
+	if !l.shouldLog(issue) {
+		return
+	}
+	l.logger.Warn(
+		"span semantic convention violation",
+		"scope", scope.Name(),
+		"name", s.Name(),
+		"class", issue.class,
+		"violation", issue.violation,
+		"attribute", issue.key,
+	)
+}
+
+// shouldLog returns true if issue has not been logged in the last log
+// interval.
+func (l *linter) shouldLog(issue lintIssue) bool {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.logged[issue]; ok && now.Sub(last) < l.logInterval {
+		return false
+	}
+	l.logged[issue] = now
+	return true
+}
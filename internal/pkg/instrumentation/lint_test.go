@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+func semconvClassByName(t *testing.T, name string) *semconvClass {
+	t.Helper()
+	for i := range semconvClasses {
+		if semconvClasses[i].name == name {
+			return &semconvClasses[i]
+		}
+	}
+	require.FailNow(t, "unknown class", name)
+	return nil
+}
+
+func TestSemconvClassLint(t *testing.T) {
+	class := semconvClassByName(t, "http.server")
+
+	tests := []struct {
+		name  string
+		kind  ptrace.SpanKind
+		attrs map[string]any
+		want  []lintIssue
+	}{
+		{
+			name: "Valid",
+			kind: ptrace.SpanKindServer,
+			attrs: map[string]any{
+				"http.request.method":       "GET",
+				"url.path":                  "/",
+				"http.response.status_code": 200,
+			},
+		},
+		{
+			name:  "Missing",
+			kind:  ptrace.SpanKindServer,
+			attrs: map[string]any{"url.path": "/"},
+			want: []lintIssue{
+				{class: "http.server", violation: semconvMissing, key: "http.request.method"},
+			},
+		},
+		{
+			name: "Type",
+			kind: ptrace.SpanKindServer,
+			attrs: map[string]any{
+				"http.request.method":       "GET",
+				"url.path":                  "/",
+				"http.response.status_code": "200",
+			},
+			want: []lintIssue{
+				{class: "http.server", violation: semconvType, key: "http.response.status_code"},
+			},
+		},
+		{
+			name: "Value",
+			kind: ptrace.SpanKindServer,
+			attrs: map[string]any{
+				"http.request.method": "get",
+				"url.path":            "/",
+			},
+			want: []lintIssue{
+				{class: "http.server", violation: semconvValue, key: "http.request.method"},
+			},
+		},
+		{
+			name: "Kind",
+			kind: ptrace.SpanKindClient,
+			attrs: map[string]any{
+				"http.request.method": "GET",
+				"url.path":            "/",
+			},
+			want: []lintIssue{
+				{class: "http.server", violation: semconvKind},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ptrace.NewSpan()
+			s.SetKind(tt.kind)
+			require.NoError(t, s.Attributes().FromRaw(tt.attrs))
+			assert.Equal(t, tt.want, class.lint(nil, s))
+		})
+	}
+}
+
+func TestSemconvClassesUnique(t *testing.T) {
+	type classID struct {
+		scope string
+		kind  ptrace.SpanKind
+	}
+	ids := make(map[classID]bool)
+	for _, c := range semconvClasses {
+		id := classID{scope: c.scope, kind: c.kind}
+		assert.False(t, ids[id], "duplicate scope %s for kind %s", c.scope, c.kind)
+		ids[id] = true
+
+		keys := make(map[string]bool)
+		for _, a := range c.attrs {
+			assert.False(t, keys[a.key], "%s: duplicate attribute %s", c.name, a.key)
+			keys[a.key] = true
+			if len(a.values) > 0 {
+				assert.Equal(t, pcommon.ValueTypeStr, a.typ, "%s: enum attribute %s is not a string", c.name, a.key)
+			}
+		}
+	}
+}
+
+func TestSpanClass(t *testing.T) {
+	client := &semconvClass{name: "client", kind: ptrace.SpanKindClient}
+	internal := &semconvClass{name: "internal", kind: ptrace.SpanKindInternal}
+	classes := []*semconvClass{client, internal}
+
+	s := ptrace.NewSpan()
+	s.SetKind(ptrace.SpanKindInternal)
+	assert.Same(t, internal, spanClass(classes, s))
+
+	// Spans of another kind are linted with the first class.
+	s.SetKind(ptrace.SpanKindServer)
+	assert.Same(t, client, spanClass(classes, s))
+}
+
+func TestWithSemconvLint(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	rec := new(recordingTraceHandler)
+	h := WithSemconvLint(logger, &pipeline.Handler{TraceHandler: rec}, LintConfig{})
+	l := h.TraceHandler.(*linter)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	handle := func(scopeName string) {
+		scope := pcommon.NewInstrumentationScope()
+		scope.SetName(scopeName)
+		spans := ptrace.NewSpanSlice()
+		newValidSpan(spans, "GET").SetKind(ptrace.SpanKindClient)
+		h.TraceHandler.HandleTrace(scope, "", spans)
+	}
+	logs := func() int { return strings.Count(buf.String(), "span semantic convention violation") }
+
+	// Spans of scopes without semantic conventions are not linted.
+	handle("go.opentelemetry.io/auto/go.opentelemetry.io/auto")
+	assert.Equal(t, 0, logs())
+
+	// Both required attributes are missing.
+	const scope = "go.opentelemetry.io/auto/net/http/client"
+	handle(scope)
+	assert.Equal(t, 2, logs())
+	assert.Contains(t, buf.String(), "attribute=http.request.method")
+	assert.Contains(t, buf.String(), "attribute=url.full")
+
+	// Violations are logged at most once per interval.
+	handle(scope)
+	assert.Equal(t, 2, logs())
+	now = now.Add(DefaultLintLogInterval)
+	handle(scope)
+	assert.Equal(t, 4, logs())
+
+	// Spans are passed unchanged.
+	require.Len(t, rec.spans, 4)
+	for _, spans := range rec.spans {
+		assert.Equal(t, []string{"GET"}, spanNames(spans))
+	}
+}
@@ -1,8 +1,8 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-// Package instrumentation provides functionality to manage instrumentation
-// using eBPF for Go programs.
+//go:build linux
+
 package instrumentation
 
 import (
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/rlimit"
@@ -18,6 +19,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpffs"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/privilege"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
 	"go.opentelemetry.io/auto/internal/pkg/process"
 	"go.opentelemetry.io/auto/pipeline"
@@ -29,6 +33,7 @@ var (
 	rlimitRemoveMemlock = rlimit.RemoveMemlock
 	bpffsMount          = bpffs.Mount
 	bpffsCleanup        = bpffs.Cleanup
+	privilegeDrop       = privilege.Drop
 )
 
 type managerState int
@@ -54,6 +59,19 @@ type Manager struct {
 	probeMu         sync.Mutex
 	state           managerState
 	stateMu         sync.RWMutex
+
+	// validateOffsets is true if the struct field offsets of the probes are
+	// validated before they are loaded.
+	validateOffsets bool
+	// eventDump is the writer the raw events of the probes are dumped to, if
+	// not nil.
+	eventDump *eventdump.Writer
+	// flushes are called once the probes are stopped.
+	flushes []func()
+
+	// statusMu guards probeStatus and updates of currentConfig.
+	statusMu    sync.Mutex
+	probeStatus map[probe.ID]probeStatus
 }
 
 // NewManager returns a new [Manager].
@@ -128,19 +146,36 @@ func (m *Manager) registerProbe(p probe.Probe) error {
 		return err
 	}
 
+	if s, ok := p.(probe.MetricSource); ok {
+		s.HandleMetrics(m.handler != nil && m.handler.MetricHandler != nil)
+	}
+
 	m.probes[id] = p
 	return nil
 }
 
 // filterUnusedProbes filterers probes whose functions are already instrumented
-// out of the Manager.
+// out of the Manager, the probes producing log records if the handler of the
+// Manager does not handle them, and the optional probes not enabled.
 func (m *Manager) filterUnusedProbes() {
 	existingFuncMap := make(map[string]struct{}, len(m.proc.Functions))
 	for _, f := range m.proc.Functions {
 		existingFuncMap[f.Name] = struct{}{}
 	}
+	handlesLogs := m.handler != nil && m.handler.LogHandler != nil
 
 	for name, inst := range m.probes {
+		if _, ok := inst.(probe.LogSource); ok && !handlesLogs {
+			m.logger.Debug("logs not handled, removing probe", "name", name)
+			delete(m.probes, name)
+			continue
+		}
+		if o, ok := inst.(probe.Optional); ok && !o.Enabled() {
+			m.logger.Debug("probe not enabled, removing", "name", name)
+			delete(m.probes, name)
+			continue
+		}
+
 		funcsFound := false
 		for _, s := range inst.Manifest().Symbols {
 			if len(s.DependsOn) == 0 {
@@ -204,20 +239,31 @@ func (m *Manager) applyConfig(c Config) error {
 	}
 
 	for id, p := range m.probes {
+		if m.probeSkipped(id) {
+			continue
+		}
 		currentlyEnabled := isProbeEnabled(id, m.currentConfig)
 		newEnabled := isProbeEnabled(id, c)
 
 		if currentlyEnabled && !newEnabled {
 			m.logger.Info("Disabling probe", "id", id)
 			err = errors.Join(err, p.Close())
+			m.setProbeState(id, ProbeStateDisabled, nil)
 			continue
 		}
 
 		if !currentlyEnabled && newEnabled {
+			if !m.validProbe(id, p) {
+				continue
+			}
 			m.logger.Info("Enabling probe", "id", id)
-			err = errors.Join(err, p.Load(m.exe, m.proc, c.SamplingConfig))
+			loadErr := p.Load(m.exe, m.proc, c.SamplingConfig)
+			err = errors.Join(err, loadErr)
+			if loadErr != nil {
+				m.setProbeState(id, ProbeStateFailed, loadErr)
+			}
 			if err == nil {
-				m.runProbe(p)
+				m.runProbe(id, p)
 			}
 			continue
 		}
@@ -226,7 +272,11 @@ func (m *Manager) applyConfig(c Config) error {
 	return nil
 }
 
-func (m *Manager) runProbe(p probe.Probe) {
+func (m *Manager) runProbe(id probe.ID, p probe.Probe) {
+	if d, ok := p.(eventDumper); ok && m.eventDump != nil {
+		d.SetEventDump(m.eventDump)
+	}
+	m.setProbeState(id, ProbeStateRunning, nil)
 	m.runningProbesWG.Add(1)
 	go func(ap probe.Probe) {
 		defer m.runningProbesWG.Done()
@@ -251,7 +301,7 @@ func (m *Manager) ConfigLoop(ctx context.Context) {
 				m.logger.Error("Failed to apply config", "error", err)
 				continue
 			}
-			m.currentConfig = c
+			m.setConfig(c)
 		}
 	}
 }
@@ -275,8 +325,8 @@ func (m *Manager) Load(ctx context.Context) error {
 		return errors.New("manager is already running, load is not allowed")
 	}
 
-	m.currentConfig = m.cp.InitialConfig(ctx)
-	err := m.loadProbes()
+	m.setConfig(m.cp.InitialConfig(ctx))
+	err := m.loadProbes(ctx)
 	if err != nil {
 		return err
 	}
@@ -286,6 +336,36 @@ func (m *Manager) Load(ctx context.Context) error {
 	return nil
 }
 
+// Privileges returns the capabilities the Manager needs once it is loaded:
+// the capabilities needed to load probes if configuration updates can enable
+// them, none otherwise. Loaded probes are run and closed without privileges.
+func (m *Manager) Privileges() privilege.Set {
+	if isStatic(m.cp) {
+		return 0
+	}
+	return privilege.Reattach
+}
+
+// DropPrivileges drops the capabilities of the process not needed by the
+// Manager, as returned by [Manager.Privileges], or in retain. It must be
+// called after [Manager.Load], all the privileged operations needed to run
+// the probes are done by then.
+func (m *Manager) DropPrivileges(retain privilege.Set) error {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+
+	if m.state != managerStateLoaded {
+		return errors.New("manager is not loaded, call Load before dropping privileges")
+	}
+
+	keep := m.Privileges() | retain
+	if err := privilegeDrop(keep); err != nil {
+		return fmt.Errorf("failed to drop privileges: %w", err)
+	}
+	m.logger.Info("dropped privileges", "retained", keep.String())
+	return nil
+}
+
 func (m *Manager) runProbes(ctx context.Context) (context.Context, error) {
 	m.stateMu.Lock()
 	defer m.stateMu.Unlock()
@@ -295,8 +375,8 @@ func (m *Manager) runProbes(ctx context.Context) (context.Context, error) {
 	}
 
 	for id, p := range m.probes {
-		if isProbeEnabled(id, m.currentConfig) {
-			m.runProbe(p)
+		if isProbeEnabled(id, m.currentConfig) && !m.probeSkipped(id) {
+			m.runProbe(id, p)
 		}
 	}
 
@@ -307,13 +387,31 @@ func (m *Manager) runProbes(ctx context.Context) (context.Context, error) {
 }
 
 // Run runs the event processing loop for all managed probes.
+//
+// The probes are stopped and cleaned up once ctx is done, the cause of ctx is
+// then returned. If ctx is already done, the probes are cleaned up without
+// being run.
 func (m *Manager) Run(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Join(context.Cause(ctx), m.Stop())
+	}
+
 	ctx, err := m.runProbes(ctx)
 	if err != nil {
 		return err
 	}
 
 	go m.ConfigLoop(ctx)
+	// Keep the span timestamp conversion aligned with the realtime clock.
+	offsetDelta := newGauge(
+		m.handler,
+		"otel.auto.boot_time_offset.delta",
+		"ns",
+		"Change of the estimated boot time offset at the last resynchronization.",
+	)
+	go kernel.RunBootOffsetSync(ctx, m.logger, kernel.DefaultBootOffsetSyncInterval, func(d time.Duration) {
+		offsetDelta.Record(int64(d))
+	})
 
 	done := make(chan error, 1)
 	go func() {
@@ -354,12 +452,18 @@ func (m *Manager) Stop() error {
 
 	// Wait for all probes to stop.
 	m.runningProbesWG.Wait()
+	for _, flush := range m.flushes {
+		flush()
+	}
 
 	m.state = managerStateStopped
 	return err
 }
 
-func (m *Manager) loadProbes() error {
+// loadProbes loads the enabled probes. If it fails, or ctx is done before all
+// the probes are loaded, the loaded probes are closed and the BPF
+// file-system of the target is removed.
+func (m *Manager) loadProbes(ctx context.Context) error {
 	// Remove resource limits for kernels <5.11.
 	if err := rlimitRemoveMemlock(); err != nil {
 		return err
@@ -371,6 +475,10 @@ func (m *Manager) loadProbes() error {
 	}
 	m.exe = exe
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	m.logger.Debug("Mounting bpffs")
 	if err := bpffsMount(m.proc); err != nil {
 		return err
@@ -378,10 +486,18 @@ func (m *Manager) loadProbes() error {
 
 	// Load probes
 	for name, i := range m.probes {
+		if err := ctx.Err(); err != nil {
+			m.logger.Info("loading canceled, cleaning up")
+			return errors.Join(err, m.cleanup())
+		}
 		if isProbeEnabled(name, m.currentConfig) {
+			if !m.validProbe(name, i) {
+				continue
+			}
 			m.logger.Info("loading probe", "name", name)
 			err := i.Load(exe, m.proc, m.currentConfig.SamplingConfig)
 			if err != nil {
+				m.setProbeState(name, ProbeStateFailed, err)
 				m.logger.Error(
 					"error while loading probes, cleaning up",
 					"error",
@@ -391,17 +507,86 @@ func (m *Manager) loadProbes() error {
 				)
 				return errors.Join(err, m.cleanup())
 			}
+			m.setProbeState(name, ProbeStateLoaded, nil)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		m.logger.Info("loading canceled, cleaning up")
+		return errors.Join(err, m.cleanup())
+	}
+
 	m.logger.Debug("loaded probes to memory", "total_probes", len(m.probes))
 	return nil
 }
 
+// EnableOffsetValidation enables the validation of the struct field offsets
+// of the probes against the target process before they are loaded. Probes
+// with invalid offsets are skipped instead of reading corrupt data. It must
+// be called before [Manager.Load].
+func (m *Manager) EnableOffsetValidation() {
+	m.validateOffsets = true
+}
+
+// offsetValidator is a [probe.Probe] whose struct field offsets can be
+// validated against the target process.
+type offsetValidator interface {
+	ValidateOffsets(*process.Info) error
+}
+
+// validProbe returns false if offset validation is enabled and the offsets of
+// p, identified by id, are not valid for the target process. The probe is
+// then recorded as skipped.
+//
+// The offsets of targets without DWARF data cannot be validated, their probes
+// are loaded.
+func (m *Manager) validProbe(id probe.ID, p probe.Probe) bool {
+	v, ok := p.(offsetValidator)
+	if !m.validateOffsets || !ok {
+		return true
+	}
+
+	err := v.ValidateOffsets(m.proc)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, probe.ErrNoDWARF):
+		m.logger.Warn("offsets not validated, no DWARF data", "name", id)
+		return true
+	}
+	m.logger.Warn("invalid offsets, skipping probe", "name", id, "error", err)
+	m.setProbeState(id, ProbeStateSkipped, err)
+	return false
+}
+
+// EnableEventDump enables the dump of the raw events read by the probes to
+// w, for offline analysis. The Manager closes w when the probes are closed.
+// It must be called before [Manager.Run].
+func (m *Manager) EnableEventDump(w *eventdump.Writer) {
+	m.eventDump = w
+}
+
+// FlushOnStop registers flush to be called once the probes are stopped, and
+// no more telemetry is handled, to pass the telemetry held by a wrapper of the
+// handler of the Manager (e.g. [WithProxyMode]) to the handler it wraps. It
+// must be called before [Manager.Run].
+func (m *Manager) FlushOnStop(flush func()) {
+	m.flushes = append(m.flushes, flush)
+}
+
+// eventDumper is a [probe.Probe] whose raw events can be dumped.
+type eventDumper interface {
+	SetEventDump(*eventdump.Writer)
+}
+
 func (m *Manager) cleanup() error {
 	err := m.cp.Shutdown(context.Background())
-	for _, i := range m.probes {
+	for id, i := range m.probes {
 		err = errors.Join(err, i.Close())
+		m.closeProbeState(id)
+	}
+	if m.eventDump != nil {
+		err = errors.Join(err, m.eventDump.Close())
 	}
 
 	m.logger.Debug("Cleaning bpffs")
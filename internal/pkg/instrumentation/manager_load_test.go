@@ -1,6 +1,8 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
+//go:build linux
+
 package instrumentation
 
 import (
@@ -18,15 +20,64 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/auto/internal/pkg/inject"
+	pubsubConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/pubsub/consumer"
+	pubsubProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/pubsub/producer"
+	spannerClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/spanner"
+	cryptoTLS "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/crypto/tls"
 	dbSql "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	gqlgen "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/99designs/gqlgen"
+	clickhouseClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/ClickHouse/clickhouse-go"
+	saramaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/IBM/sarama/consumer"
+	saramaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/IBM/sarama/producer"
+	awsClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/aws/aws-sdk-go-v2"
+	memcacheClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/bradfitz/gomemcache"
+	confluentConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/confluentinc/confluent-kafka-go/consumer"
+	confluentProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/confluentinc/confluent-kafka-go/producer"
+	badgerDB "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/dgraph-io/badger"
+	pahoV5Producer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.golang/paho"
+	pahoConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.mqtt.golang/consumer"
+	pahoProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.mqtt.golang/producer"
+	esClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/elastic/go-elasticsearch"
+	gocqlClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/gocql/gocql"
+	gorillaWebsocket "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/gorilla/websocket"
+	vaultClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hashicorp/vault/api"
+	asynqConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hibiken/asynq/consumer"
+	asynqProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hibiken/asynq/producer"
+	influxdbClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/influxdata/influxdb-client-go"
+	pgxClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/jackc/pgx"
+	natsConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/consumer"
+	natsJetstream "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/jetstream"
+	natsProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/producer"
+	rabbitmqConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/rabbitmq/amqp091-go/consumer"
+	rabbitmqProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/rabbitmq/amqp091-go/producer"
+	redisClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/redis/go-redis"
+	rueidisClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/redis/rueidis"
 	kafkaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/consumer"
 	kafkaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/producer"
+	fasthttpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/valyala/fasthttp/client"
+	fasthttpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/valyala/fasthttp/server"
+	bboltTx "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.etcd.io/bbolt"
+	etcdClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.etcd.io/etcd/client"
+	mongoClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.mongodb.org/mongo-driver"
 	autosdk "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/auto/sdk"
 	otelTraceGlobal "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/otel/traceglobal"
+	temporalClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.temporal.io/sdk"
+	logZap "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.uber.org/zap"
+	sshClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/golang.org/x/crypto/ssh"
+	http2Server "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/golang.org/x/net/http2"
 	grpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/client"
 	grpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/server"
+	k8sRest "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/k8s.io/client-go/rest"
+	logSlog "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/log/slog"
+	netDialer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/dialer"
 	httpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/client"
+	httpReverseProxy "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/httputil"
 	httpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/server"
+	netResolver "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/resolver"
+	rpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc/client"
+	rpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc/server"
+	netSMTP "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/smtp"
+	goRuntime "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/runtime"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpffs"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/debug"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
@@ -56,12 +107,66 @@ func TestLoadProbes(t *testing.T) {
 	logger := slog.Default()
 	probes := []probe.Probe{
 		grpcClient.New(logger, ""),
-		grpcServer.New(logger, ""),
+		grpcServer.New(logger, "", nil),
 		httpServer.New(logger, ""),
 		httpClient.New(logger, ""),
+		httpReverseProxy.New(logger, ""),
+		netDialer.New(logger, "", true),
+		cryptoTLS.New(logger, "", true),
+		fasthttpServer.New(logger, ""),
+		fasthttpClient.New(logger, ""),
+		gqlgen.New(logger, ""),
 		dbSql.New(logger, ""),
+		redisClient.New(logger, ""),
+		mongoClient.New(logger, ""),
+		mongoClient.NewV2(logger, ""),
+		pgxClient.New(logger, ""),
+		esClient.NewV7(logger, ""),
+		esClient.NewV8(logger, ""),
+		memcacheClient.New(logger, ""),
+		gocqlClient.New(logger, ""),
+		etcdClient.New(logger, ""),
+		awsClient.New(logger, ""),
 		kafkaProducer.New(logger, ""),
 		kafkaConsumer.New(logger, ""),
+		saramaProducer.New(logger, ""),
+		saramaProducer.NewShopify(logger, ""),
+		saramaConsumer.New(logger, ""),
+		saramaConsumer.NewShopify(logger, ""),
+		natsProducer.New(logger, ""),
+		natsConsumer.New(logger, ""),
+		rabbitmqProducer.New(logger, ""),
+		rabbitmqConsumer.New(logger, ""),
+		pubsubProducer.New(logger, ""),
+		pubsubConsumer.New(logger, ""),
+		spannerClient.New(logger, ""),
+		confluentProducer.New(logger, ""),
+		confluentConsumer.New(logger, ""),
+		gorillaWebsocket.New(logger, ""),
+		k8sRest.New(logger, "", true),
+		rueidisClient.New(logger, ""),
+		rueidisClient.NewValkey(logger, ""),
+		clickhouseClient.New(logger, ""),
+		natsJetstream.New(logger, ""),
+		asynqProducer.New(logger, ""),
+		asynqConsumer.New(logger, ""),
+		temporalClient.New(logger, ""),
+		influxdbClient.New(logger, ""),
+		vaultClient.New(logger, ""),
+		bboltTx.New(logger, "", false),
+		badgerDB.New(logger, ""),
+		rpcServer.New(logger, ""),
+		rpcClient.New(logger, ""),
+		netResolver.New(logger, ""),
+		netSMTP.New(logger, ""),
+		sshClient.New(logger, ""),
+		http2Server.New(logger, ""),
+		pahoProducer.New(logger, ""),
+		pahoConsumer.New(logger, ""),
+		pahoV5Producer.New(logger, ""),
+		logSlog.New(logger, ""),
+		logZap.New(logger, ""),
+		goRuntime.New(logger, "", nil),
 		autosdk.New(logger),
 		otelTraceGlobal.New(logger),
 	}
@@ -82,9 +187,18 @@ func TestLoadProbes(t *testing.T) {
 		})
 	}
 
-	// The grpcClient, grpcServer, httpClient, dbSql, kafkaProducer,
-	// kafkaConsumer, autosdk, and otelTraceGlobal all allocate. Ensure it has
-	// been called.
+	// The grpcClient, grpcServer, httpClient, httpReverseProxy, gqlgen, dbSql,
+	// redisClient, mongoClient, pgxClient, esClient, memcacheClient,
+	// gocqlClient, etcdClient, awsClient, kafkaProducer, kafkaConsumer,
+	// saramaProducer, saramaConsumer, natsProducer, natsConsumer,
+	// rabbitmqProducer, rabbitmqConsumer, pubsubProducer, pubsubConsumer,
+	// spannerClient, confluentProducer, confluentConsumer, gorillaWebsocket,
+	// k8sRest, rueidisClient, clickhouseClient, natsJetstream, asynqProducer,
+	// asynqConsumer, temporalClient, influxdbClient, vaultClient, bboltTx,
+	// badgerDB, rpcServer, rpcClient, netResolver, netSMTP, netDialer,
+	// cryptoTLS, sshClient, http2Server, pahoProducer, pahoConsumer,
+	// pahoV5Producer, autosdk, and otelTraceGlobal all allocate.
+	// Ensure it has been called.
 	a, err := info.Alloc(logger)
 	require.NoError(t, err)
 	assert.NotEmpty(t, a.StartAddr, "memory not allocated")
@@ -1,4 +1,4 @@
-//go:build !ebpf_test
+//go:build linux && !ebpf_test
 
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
@@ -9,6 +9,8 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -19,18 +21,70 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/otel/trace"
 
+	pubsubConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/pubsub/consumer"
+	pubsubProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/pubsub/producer"
+	spannerClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/cloud.google.com/go/spanner"
+	cryptoTLS "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/crypto/tls"
 	dbSql "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/database/sql"
+	gqlgen "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/99designs/gqlgen"
+	clickhouseClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/ClickHouse/clickhouse-go"
+	saramaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/IBM/sarama/consumer"
+	saramaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/IBM/sarama/producer"
+	awsClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/aws/aws-sdk-go-v2"
+	memcacheClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/bradfitz/gomemcache"
+	confluentConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/confluentinc/confluent-kafka-go/consumer"
+	confluentProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/confluentinc/confluent-kafka-go/producer"
+	badgerDB "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/dgraph-io/badger"
+	pahoV5Producer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.golang/paho"
+	pahoConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.mqtt.golang/consumer"
+	pahoProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/eclipse/paho.mqtt.golang/producer"
+	esClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/elastic/go-elasticsearch"
+	gocqlClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/gocql/gocql"
+	gorillaWebsocket "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/gorilla/websocket"
+	vaultClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hashicorp/vault/api"
+	asynqConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hibiken/asynq/consumer"
+	asynqProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/hibiken/asynq/producer"
+	influxdbClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/influxdata/influxdb-client-go"
+	pgxClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/jackc/pgx"
+	natsConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/consumer"
+	natsJetstream "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/jetstream"
+	natsProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/nats-io/nats.go/producer"
+	rabbitmqConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/rabbitmq/amqp091-go/consumer"
+	rabbitmqProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/rabbitmq/amqp091-go/producer"
+	redisClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/redis/go-redis"
+	rueidisClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/redis/rueidis"
 	kafkaConsumer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/consumer"
 	kafkaProducer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/segmentio/kafka-go/producer"
+	fasthttpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/valyala/fasthttp/client"
+	fasthttpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/github.com/valyala/fasthttp/server"
+	bboltTx "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.etcd.io/bbolt"
+	etcdClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.etcd.io/etcd/client"
+	mongoClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.mongodb.org/mongo-driver"
 	autosdk "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/auto/sdk"
 	otelTraceGlobal "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.opentelemetry.io/otel/traceglobal"
+	temporalClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.temporal.io/sdk"
+	logZap "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/go.uber.org/zap"
+	sshClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/golang.org/x/crypto/ssh"
+	http2Server "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/golang.org/x/net/http2"
 	grpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/client"
 	grpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/google.golang.org/grpc/server"
+	k8sRest "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/k8s.io/client-go/rest"
+	logSlog "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/log/slog"
+	netDialer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/dialer"
 	httpClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/client"
+	httpReverseProxy "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/httputil"
 	httpServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/http/server"
+	netResolver "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/resolver"
+	rpcClient "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc/client"
+	rpcServer "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/rpc/server"
+	netSMTP "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/net/smtp"
+	goRuntime "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf/runtime"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/privilege"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/sampling"
 	"go.opentelemetry.io/auto/internal/pkg/process"
@@ -65,6 +119,19 @@ func TestProbeFiltering(t *testing.T) {
 		)
 		assert.Len(t, m.probes, 1)
 	})
+
+	t.Run("optional probe not enabled", func(t *testing.T) {
+		m := fakeManager("net.(*Dialer).DialContext")
+		assert.Empty(t, m.probes)
+	})
+
+	t.Run("optional probe enabled", func(t *testing.T) {
+		m := fakeManager("net.(*Dialer).DialContext")
+		p := netDialer.New(slog.Default(), "", true)
+		m.probes[p.Manifest().ID] = p
+		m.filterUnusedProbes()
+		assert.Len(t, m.probes, 1)
+	})
 }
 
 func TestDependencyChecks(t *testing.T) {
@@ -150,16 +217,109 @@ func TestDependencyChecks(t *testing.T) {
 	})
 }
 
+// metricProbe is a noopProbe recording whether its metrics are handled.
+type metricProbe struct {
+	noopProbe
+	handled *bool
+}
+
+func (p *metricProbe) HandleMetrics(handled bool) { p.handled = &handled }
+
+type noopMetricHandler struct{}
+
+func (noopMetricHandler) HandleMetric(pcommon.InstrumentationScope, string, pmetric.MetricSlice) {}
+
+func TestRegisterProbeHandleMetrics(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		handler *pipeline.Handler
+		want    bool
+	}{
+		{name: "no handler"},
+		{name: "trace handler", handler: newNoopHandler()},
+		{
+			name: "metric handler",
+			handler: &pipeline.Handler{
+				TraceHandler:  noopTraceHandler{},
+				MetricHandler: noopMetricHandler{},
+			},
+			want: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manager{handler: tt.handler, probes: make(map[probe.ID]probe.Probe)}
+			p := &metricProbe{}
+			require.NoError(t, m.registerProbe(p))
+			require.NotNil(t, p.handled, "HandleMetrics not called")
+			assert.Equal(t, tt.want, *p.handled)
+		})
+	}
+}
+
 func fakeManager(fnNames ...string) *Manager {
 	logger := slog.Default()
 	probes := []probe.Probe{
 		grpcClient.New(logger, ""),
-		grpcServer.New(logger, ""),
+		grpcServer.New(logger, "", nil),
 		httpServer.New(logger, ""),
 		httpClient.New(logger, ""),
+		httpReverseProxy.New(logger, ""),
+		netDialer.New(logger, "", false),
+		cryptoTLS.New(logger, "", false),
+		fasthttpServer.New(logger, ""),
+		fasthttpClient.New(logger, ""),
+		gqlgen.New(logger, ""),
 		dbSql.New(logger, ""),
+		redisClient.New(logger, ""),
+		mongoClient.New(logger, ""),
+		mongoClient.NewV2(logger, ""),
+		pgxClient.New(logger, ""),
+		esClient.NewV7(logger, ""),
+		esClient.NewV8(logger, ""),
+		memcacheClient.New(logger, ""),
+		gocqlClient.New(logger, ""),
+		etcdClient.New(logger, ""),
+		awsClient.New(logger, ""),
 		kafkaProducer.New(logger, ""),
 		kafkaConsumer.New(logger, ""),
+		saramaProducer.New(logger, ""),
+		saramaProducer.NewShopify(logger, ""),
+		saramaConsumer.New(logger, ""),
+		saramaConsumer.NewShopify(logger, ""),
+		natsProducer.New(logger, ""),
+		natsConsumer.New(logger, ""),
+		rabbitmqProducer.New(logger, ""),
+		rabbitmqConsumer.New(logger, ""),
+		pubsubProducer.New(logger, ""),
+		pubsubConsumer.New(logger, ""),
+		spannerClient.New(logger, ""),
+		confluentProducer.New(logger, ""),
+		confluentConsumer.New(logger, ""),
+		gorillaWebsocket.New(logger, ""),
+		k8sRest.New(logger, "", true),
+		rueidisClient.New(logger, ""),
+		rueidisClient.NewValkey(logger, ""),
+		clickhouseClient.New(logger, ""),
+		natsJetstream.New(logger, ""),
+		asynqProducer.New(logger, ""),
+		asynqConsumer.New(logger, ""),
+		temporalClient.New(logger, ""),
+		influxdbClient.New(logger, ""),
+		vaultClient.New(logger, ""),
+		bboltTx.New(logger, "", false),
+		badgerDB.New(logger, ""),
+		rpcServer.New(logger, ""),
+		rpcClient.New(logger, ""),
+		netResolver.New(logger, ""),
+		netSMTP.New(logger, ""),
+		sshClient.New(logger, ""),
+		http2Server.New(logger, ""),
+		pahoProducer.New(logger, ""),
+		pahoConsumer.New(logger, ""),
+		pahoV5Producer.New(logger, ""),
+		logSlog.New(logger, ""),
+		logZap.New(logger, ""),
+		goRuntime.New(logger, "", nil),
 		autosdk.New(logger),
 		otelTraceGlobal.New(logger),
 	}
@@ -444,6 +604,15 @@ func TestConfigProvider(t *testing.T) {
 		return p.closed.Load()
 	}
 
+	probeState := func(id probe.ID) ProbeState {
+		for _, s := range m.ProbeStatus() {
+			if s.ID == id {
+				return s.State
+			}
+		}
+		return ""
+	}
+
 	assert.True(t, probePending(netHTTPClientProbeID))
 	assert.Eventually(t, func() bool {
 		return probeRunning(netHTTPServerProbeID)
@@ -451,6 +620,9 @@ func TestConfigProvider(t *testing.T) {
 	assert.Eventually(t, func() bool {
 		return probeRunning(somePackageProducerProbeID)
 	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ProbeStateDisabled, probeState(netHTTPClientProbeID))
+	assert.Equal(t, ProbeStateRunning, probeState(netHTTPServerProbeID))
+	assert.Equal(t, ProbeStateRunning, probeState(somePackageProducerProbeID))
 
 	// Send a new config that enables the net/http client probe by removing the explicit disable
 	m.cp.(*dummyProvider).sendConfig(Config{})
@@ -459,6 +631,10 @@ func TestConfigProvider(t *testing.T) {
 	}, time.Second, 10*time.Millisecond)
 	assert.True(t, probeRunning(netHTTPServerProbeID))
 	assert.True(t, probeRunning(somePackageProducerProbeID))
+	assert.Equal(t, ProbeStateRunning, probeState(netHTTPClientProbeID))
+	assert.Eventually(t, func() bool {
+		return m.Config().InstrumentationLibraryConfigs == nil
+	}, time.Second, 10*time.Millisecond)
 
 	// Send a new config that disables the net/http client and server probes
 	m.cp.(*dummyProvider).sendConfig(Config{
@@ -504,6 +680,9 @@ func TestConfigProvider(t *testing.T) {
 			probeClosed(netHTTPServerProbeID) && !probeRunning(netHTTPServerProbeID) &&
 			probeClosed(somePackageProducerProbeID) && !probeRunning(somePackageProducerProbeID)
 	}, time.Second, 10*time.Millisecond)
+	for _, s := range m.ProbeStatus() {
+		assert.Equal(t, ProbeStateClosed, s.State, s.ID)
+	}
 
 	// Send a config to enable all probes, but the manager is stopped - this should panic
 	assert.Panics(t, func() {
@@ -618,3 +797,278 @@ func TestStopBeforeRun(t *testing.T) {
 	require.True(t, p.closed.Load())
 	require.False(t, p.running.Load())
 }
+
+type staticProvider struct{ ConfigProvider }
+
+func (staticProvider) Static() bool { return true }
+
+func TestDropPrivileges(t *testing.T) {
+	var dropped []privilege.Set
+	orig := privilegeDrop
+	t.Cleanup(func() { privilegeDrop = orig })
+	privilegeDrop = func(keep privilege.Set) error {
+		dropped = append(dropped, keep)
+		return nil
+	}
+
+	newManager := func(cp ConfigProvider) *Manager {
+		return &Manager{
+			handler: newNoopHandler(),
+			logger:  slog.Default(),
+			probes:  map[probe.ID]probe.Probe{{}: &noopProbe{}},
+			cp:      cp,
+			proc:    new(process.Info),
+		}
+	}
+	mockExeAndBpffs(t)
+
+	m := newManager(NewNoopConfigProvider(nil))
+	assert.Equal(t, privilege.Set(0), m.Privileges())
+	require.Error(t, m.DropPrivileges(0), "dropped before Load")
+	assert.Empty(t, dropped)
+
+	require.NoError(t, m.Load(context.Background()))
+	require.NoError(t, m.DropPrivileges(0))
+	require.NoError(t, m.DropPrivileges(privilege.Discovery))
+	assert.Equal(t, []privilege.Set{0, privilege.Discovery}, dropped)
+	require.NoError(t, m.Stop())
+
+	// Updated configurations can enable probes.
+	dropped = nil
+	m = newManager(newDummyProvider(Config{}))
+	assert.Equal(t, privilege.Reattach, m.Privileges())
+	require.NoError(t, m.Load(context.Background()))
+	require.NoError(t, m.DropPrivileges(0))
+	assert.Equal(t, []privilege.Set{privilege.Reattach}, dropped)
+	require.NoError(t, m.Stop())
+
+	m = newManager(staticProvider{newDummyProvider(Config{})})
+	assert.Equal(t, privilege.Set(0), m.Privileges())
+
+	privilegeDrop = func(privilege.Set) error { return errors.New("not permitted") }
+	m = newManager(NewNoopConfigProvider(nil))
+	require.NoError(t, m.Load(context.Background()))
+	assert.ErrorContains(t, m.DropPrivileges(0), "failed to drop privileges: not permitted")
+	require.NoError(t, m.Stop())
+}
+
+// hookProbe is a noopProbe calling onLoad when loaded.
+type hookProbe struct {
+	noopProbe
+	onLoad func()
+}
+
+func (p *hookProbe) Load(exe *link.Executable, info *process.Info, c *sampling.Config) error {
+	p.onLoad()
+	return p.noopProbe.Load(exe, info, c)
+}
+
+func TestCancelPhases(t *testing.T) {
+	mockExeAndBpffs(t)
+	var mounted, cleaned bool
+	bpffsMount = func(*process.Info) error {
+		mounted = true
+		return nil
+	}
+	bpffsCleanup = func(*process.Info) error {
+		cleaned = true
+		return nil
+	}
+
+	for _, phase := range []string{"before load", "bpffs mount", "probe load", "before run", "running"} {
+		t.Run(phase, func(t *testing.T) {
+			mounted, cleaned = false, false
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cancelAt := func(p string) func() {
+				return func() {
+					if p == phase {
+						cancel()
+					}
+				}
+			}
+			origMount := bpffsMount
+			t.Cleanup(func() { bpffsMount = origMount })
+			bpffsMount = func(info *process.Info) error {
+				cancelAt("bpffs mount")()
+				return origMount(info)
+			}
+
+			probes := []*hookProbe{{onLoad: cancelAt("probe load")}, {onLoad: cancelAt("probe load")}}
+			m := &Manager{
+				handler: newNoopHandler(),
+				logger:  slog.Default(),
+				probes: map[probe.ID]probe.Probe{
+					{InstrumentedPkg: "a"}: probes[0],
+					{InstrumentedPkg: "b"}: probes[1],
+				},
+				cp:   NewNoopConfigProvider(nil),
+				proc: new(process.Info),
+			}
+
+			cancelAt("before load")()
+			start := time.Now()
+			err := m.Load(ctx)
+			if err == nil {
+				cancelAt("before run")()
+				if phase == "running" {
+					time.AfterFunc(10*time.Millisecond, cancel)
+				}
+				err = m.Run(ctx)
+			}
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), time.Second, "cancellation not prompt")
+
+			// Partially loaded probes are cleaned up.
+			for _, p := range probes {
+				assert.False(t, p.loaded.Load(), "probe left loaded")
+				assert.False(t, p.running.Load(), "probe left running")
+			}
+			assert.Equal(t, mounted, cleaned, "bpffs not cleaned up")
+			require.NoError(t, m.Stop())
+		})
+	}
+}
+
+// validatedProbe is a noopProbe with offsets validated by validate.
+type validatedProbe struct {
+	noopProbe
+	validate func() error
+}
+
+func (p *validatedProbe) ValidateOffsets(*process.Info) error { return p.validate() }
+
+func TestOffsetValidation(t *testing.T) {
+	mockExeAndBpffs(t)
+
+	validID := probe.ID{InstrumentedPkg: "valid"}
+	invalidID := probe.ID{InstrumentedPkg: "invalid"}
+	strippedID := probe.ID{InstrumentedPkg: "stripped"}
+	valid := &validatedProbe{validate: func() error { return nil }}
+	invalid := &validatedProbe{validate: func() error { return errors.New("offset 8, found 0") }}
+	stripped := &validatedProbe{validate: func() error { return probe.ErrNoDWARF }}
+
+	newManager := func() *Manager {
+		valid.noopProbe, invalid.noopProbe, stripped.noopProbe = noopProbe{}, noopProbe{}, noopProbe{}
+		return &Manager{
+			handler: newNoopHandler(),
+			logger:  slog.Default(),
+			probes: map[probe.ID]probe.Probe{
+				validID:    valid,
+				invalidID:  invalid,
+				strippedID: stripped,
+			},
+			cp:   NewNoopConfigProvider(nil),
+			proc: new(process.Info),
+		}
+	}
+
+	// Offsets are not validated by default.
+	m := newManager()
+	require.NoError(t, m.Load(context.Background()))
+	assert.True(t, invalid.loaded.Load())
+	require.NoError(t, m.Stop())
+
+	m = newManager()
+	m.EnableOffsetValidation()
+	require.NoError(t, m.Load(context.Background()))
+	assert.True(t, valid.loaded.Load())
+	assert.True(t, stripped.loaded.Load(), "probe without DWARF data skipped")
+	assert.False(t, invalid.loaded.Load(), "probe with invalid offsets loaded")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	assert.Eventually(t, valid.running.Load, time.Second, 10*time.Millisecond)
+	assert.False(t, invalid.running.Load(), "skipped probe run")
+
+	status := make(map[probe.ID]ProbeStatus)
+	for _, s := range m.ProbeStatus() {
+		status[s.ID] = s
+	}
+	assert.Equal(t, ProbeStateRunning, status[validID].State)
+	assert.Equal(t, ProbeStateSkipped, status[invalidID].State)
+	assert.ErrorContains(t, status[invalidID].Err, "offset 8, found 0")
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	for _, s := range m.ProbeStatus() {
+		if s.ID == invalidID {
+			assert.Equal(t, ProbeStateSkipped, s.State, "skip reason lost on close")
+		}
+	}
+}
+
+// dumpedProbe is a noopProbe writing a raw event to its event dump when run.
+type dumpedProbe struct {
+	noopProbe
+	dump *eventdump.Writer
+}
+
+func (p *dumpedProbe) SetEventDump(w *eventdump.Writer) { p.dump = w }
+
+func (p *dumpedProbe) Run(h *pipeline.Handler) {
+	if p.dump != nil {
+		_ = p.dump.Write("dumped", []byte{1})
+	}
+	p.noopProbe.Run(h)
+}
+
+func TestEventDump(t *testing.T) {
+	mockExeAndBpffs(t)
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	w, err := eventdump.NewWriter(path, eventdump.Config{})
+	require.NoError(t, err)
+
+	p := new(dumpedProbe)
+	m := &Manager{
+		handler: newNoopHandler(),
+		logger:  slog.Default(),
+		probes:  map[probe.ID]probe.Probe{{InstrumentedPkg: "dumped"}: p},
+		cp:      NewNoopConfigProvider(nil),
+		proc:    new(process.Info),
+	}
+	m.EnableEventDump(w)
+	require.NoError(t, m.Load(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	assert.Eventually(t, p.running.Load, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// The dump is closed with the probes.
+	assert.ErrorIs(t, w.Write("dumped", nil), os.ErrClosed)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"probe":"dumped"`)
+}
+
+func TestFlushOnStop(t *testing.T) {
+	mockExeAndBpffs(t)
+
+	p := new(noopProbe)
+	m := &Manager{
+		handler: newNoopHandler(),
+		logger:  slog.Default(),
+		probes:  map[probe.ID]probe.Probe{{InstrumentedPkg: "flushed"}: p},
+		cp:      NewNoopConfigProvider(nil),
+		proc:    new(process.Info),
+	}
+	var closedOnFlush atomic.Bool
+	m.FlushOnStop(func() { closedOnFlush.Store(p.closed.Load()) })
+	require.NoError(t, m.Load(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+	assert.Eventually(t, p.running.Load, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	assert.True(t, closedOnFlush.Load(), "flushed before the probes are closed")
+}
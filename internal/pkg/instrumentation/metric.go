@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+// metricScopeName is the instrumentation scope name of the metrics produced
+// by this package.
+const metricScopeName = "go.opentelemetry.io/auto/internal/pkg/instrumentation"
+
+// metricHandler returns the handler of the metrics produced by this package
+// passing them to the MetricHandler of h. The returned handler drops the
+// metrics if h is nil or does not have a MetricHandler.
+func metricHandler(h *pipeline.Handler) pipeline.Handler {
+	if h == nil || h.MetricHandler == nil {
+		return pipeline.Handler{}
+	}
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName(metricScopeName)
+	scope.SetVersion(BuildVersion())
+	return pipeline.Handler{MetricHandler: h.MetricHandler}.WithScope(scope, "")
+}
+
+// counter is a monotonic counter whose increments are passed to a metric
+// handler as delta sum data points, to be aggregated by the handler.
+type counter struct {
+	h pipeline.Handler
+
+	name, unit, description string
+}
+
+// newCounter returns a counter passing its increments to the MetricHandler
+// of h.
+func newCounter(h *pipeline.Handler, name, unit, description string) *counter {
+	return &counter{
+		h:           metricHandler(h),
+		name:        name,
+		unit:        unit,
+		description: description,
+	}
+}
+
+// Add adds n to the count of attrs.
+func (c *counter) Add(n int64, attrs ...attribute.KeyValue) {
+	if c.h.MetricHandler == nil {
+		return
+	}
+
+	metrics := pmetric.NewMetricSlice()
+	m := metrics.AppendEmpty()
+	m.SetName(c.name)
+	m.SetUnit(c.unit)
+	m.SetDescription(c.description)
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	dp.SetIntValue(n)
+	pdataconv.Attributes(dp.Attributes(), attrs...)
+
+	c.h.Metric(metrics)
+}
+
+// gauge is a gauge whose values are passed to a metric handler as gauge data
+// points.
+type gauge struct {
+	h pipeline.Handler
+
+	name, unit, description string
+}
+
+// newGauge returns a gauge passing its values to the MetricHandler of h.
+func newGauge(h *pipeline.Handler, name, unit, description string) *gauge {
+	return &gauge{
+		h:           metricHandler(h),
+		name:        name,
+		unit:        unit,
+		description: description,
+	}
+}
+
+// Record records v as the current value of attrs.
+func (g *gauge) Record(v int64, attrs ...attribute.KeyValue) {
+	if g.h.MetricHandler == nil {
+		return
+	}
+
+	metrics := pmetric.NewMetricSlice()
+	m := metrics.AppendEmpty()
+	m.SetName(g.name)
+	m.SetUnit(g.unit)
+	m.SetDescription(g.description)
+
+	dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	dp.SetIntValue(v)
+	pdataconv.Attributes(dp.Attributes(), attrs...)
+
+	g.h.Metric(metrics)
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+type recordingMetricHandler struct {
+	scopes  []pcommon.InstrumentationScope
+	metrics []pmetric.MetricSlice
+}
+
+func (h *recordingMetricHandler) HandleMetric(scope pcommon.InstrumentationScope, _ string, m pmetric.MetricSlice) {
+	h.scopes = append(h.scopes, scope)
+	h.metrics = append(h.metrics, m)
+}
+
+func TestCounter(t *testing.T) {
+	rec := new(recordingMetricHandler)
+	c := newCounter(&pipeline.Handler{MetricHandler: rec}, "requests", "{request}", "Requests.")
+	c.Add(2, attribute.String("key", "value"))
+
+	require.Len(t, rec.metrics, 1)
+	assert.Equal(t, metricScopeName, rec.scopes[0].Name())
+	assert.Equal(t, BuildVersion(), rec.scopes[0].Version())
+
+	require.Equal(t, 1, rec.metrics[0].Len())
+	m := rec.metrics[0].At(0)
+	assert.Equal(t, "requests", m.Name())
+	assert.Equal(t, "{request}", m.Unit())
+	assert.Equal(t, "Requests.", m.Description())
+	require.Equal(t, pmetric.MetricTypeSum, m.Type())
+	assert.True(t, m.Sum().IsMonotonic())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, m.Sum().AggregationTemporality())
+	require.Equal(t, 1, m.Sum().DataPoints().Len())
+	dp := m.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(2), dp.IntValue())
+	assert.Equal(t, map[string]any{"key": "value"}, dp.Attributes().AsRaw())
+}
+
+func TestGauge(t *testing.T) {
+	rec := new(recordingMetricHandler)
+	g := newGauge(&pipeline.Handler{MetricHandler: rec}, "offset", "ns", "Offset.")
+	g.Record(-5)
+
+	require.Len(t, rec.metrics, 1)
+	require.Equal(t, 1, rec.metrics[0].Len())
+	m := rec.metrics[0].At(0)
+	assert.Equal(t, "offset", m.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, m.Type())
+	require.Equal(t, 1, m.Gauge().DataPoints().Len())
+	assert.Equal(t, int64(-5), m.Gauge().DataPoints().At(0).IntValue())
+}
+
+func TestMetricsWithoutMetricHandler(t *testing.T) {
+	assert.NotPanics(t, func() {
+		newCounter(nil, "requests", "", "").Add(1)
+		newGauge(&pipeline.Handler{}, "offset", "", "").Record(1)
+	})
+}
@@ -0,0 +1,253 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package netattr provides the semantic convention attributes describing the
+// network connection of instrumented operations.
+package netattr
+
+import (
+	"bytes"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+// Transport is the OSI transport layer or inter-process communication method
+// of a connection.
+type Transport int
+
+const (
+	// TransportUnknown is used when the transport of a connection is not known.
+	TransportUnknown Transport = iota
+	// TransportTCP is a TCP connection.
+	TransportTCP
+	// TransportUDP is a UDP connection.
+	TransportUDP
+	// TransportUnix is a Unix domain socket connection.
+	TransportUnix
+)
+
+// Attribute returns the network.transport attribute for t. The returned
+// attribute is invalid if t is TransportUnknown.
+func (t Transport) Attribute() attribute.KeyValue {
+	switch t {
+	case TransportTCP:
+		return semconv.NetworkTransportTCP
+	case TransportUDP:
+		return semconv.NetworkTransportUDP
+	case TransportUnix:
+		return semconv.NetworkTransportUnix
+	default:
+		return attribute.KeyValue{}
+	}
+}
+
+// Addr is the address of one end of a network connection.
+//
+// The zero value is an unknown address and produces no attributes.
+type Addr struct {
+	// Transport is the transport of the connection.
+	Transport Transport
+	// Host is an IP address, a domain name, or a Unix domain socket path.
+	// Scoped IPv6 addresses include their zone (e.g. "fe80::1%eth0").
+	Host string
+	// Port is the port number. Zero if unknown or not applicable.
+	Port int
+}
+
+// FromIP returns the TCP address of ip, zone, and port as captured from a
+// [net.TCPAddr]. The ip is either a 4 byte IPv4 or a 16 byte IPv6 address.
+// IPv4-mapped IPv6 addresses are converted to IPv4 and zone is ignored for
+// IPv4 addresses. The zero Addr is returned if ip is not valid, or if it is
+// the unspecified address (i.e. it was not read).
+func FromIP(ip []byte, zone string, port int) Addr {
+	a, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return Addr{}
+	}
+	// Unmap before the check, ::ffff:0.0.0.0 is the unspecified IPv4 address.
+	a = a.Unmap()
+	if a.IsUnspecified() {
+		return Addr{}
+	}
+	if a.Is6() && zone != "" {
+		a = a.WithZone(zone)
+	}
+	return Addr{Transport: TransportTCP, Host: a.String(), Port: validPort(port)}
+}
+
+// NetAddr is a TCP address as read from a [net.TCPAddr] by the eBPF
+// programs. It has the layout of the net_addr_t C struct.
+type NetAddr struct {
+	IP    [16]uint8
+	Port  int32
+	IPLen uint8
+	Zone  [16]byte
+	_     [3]byte // padding
+}
+
+// Addr returns the address of a. The zero Addr is returned if a was not read.
+func (a *NetAddr) Addr() Addr {
+	ipLen := int(a.IPLen)
+	if ipLen != net.IPv4len && ipLen != net.IPv6len {
+		return Addr{}
+	}
+	zone := a.Zone[:]
+	if i := bytes.IndexByte(zone, 0); i >= 0 {
+		zone = zone[:i]
+	}
+	return FromIP(a.IP[:ipLen], string(zone), int(a.Port))
+}
+
+// NetworkType returns the network.type attribute of the IP address of a. The
+// returned attribute is invalid if a is not an IP address, e.g. a domain name
+// or a Unix domain socket path.
+func (a Addr) NetworkType() attribute.KeyValue {
+	if a.Transport != TransportTCP && a.Transport != TransportUDP {
+		return attribute.KeyValue{}
+	}
+	ip, err := netip.ParseAddr(a.Host)
+	switch {
+	case err != nil:
+		return attribute.KeyValue{}
+	case ip.Unmap().Is4():
+		return semconv.NetworkTypeIpv4
+	default:
+		return semconv.NetworkTypeIpv6
+	}
+}
+
+// ParseHostPort parses a "host:port" or "host" address as formatted by
+// [net.Addr.String] or found in an HTTP Host header. IPv6 hosts are expected
+// to be enclosed in brackets when a port is included (e.g.
+// "[fe80::1%eth0]:8080"). Addresses starting with "/" or "@" are Unix domain
+// socket paths. The zero Addr is returned if s is empty.
+func ParseHostPort(s string) Addr {
+	if s == "" {
+		return Addr{}
+	}
+	if isUnixPath(s) {
+		return Addr{Transport: TransportUnix, Host: s}
+	}
+
+	host, port := s, 0
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		host = h
+		if n, err := strconv.Atoi(p); err == nil {
+			port = validPort(n)
+		}
+	} else {
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	if host == "" {
+		return Addr{}
+	}
+	return Addr{Transport: TransportTCP, Host: normalizeHost(host), Port: port}
+}
+
+// ParseTarget parses a gRPC dial target (e.g. "dns:///localhost:50051",
+// "unix:///tmp/grpc.sock", or "localhost:50051").
+//
+// https://github.com/grpc/grpc/blob/master/doc/naming.md
+func ParseTarget(target string) Addr {
+	if target == "" {
+		return Addr{}
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return ParseHostPort(target)
+	}
+
+	// The endpoint of a URL target is its opaque data (e.g. "unix:path") or
+	// path without the leading slash (e.g. "dns:///host:port").
+	endpoint := u.Opaque
+	if endpoint == "" {
+		endpoint = strings.TrimPrefix(u.Path, "/")
+	}
+
+	switch u.Scheme {
+	case "unix":
+		// "unix:path" is relative, "unix:///path" and "unix://path" are absolute.
+		path := u.Opaque
+		if path == "" {
+			path = u.Host + u.Path
+			if path == "" {
+				return Addr{}
+			}
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+		}
+		return Addr{Transport: TransportUnix, Host: path}
+	case "unix-abstract":
+		if endpoint == "" {
+			return Addr{}
+		}
+		return Addr{Transport: TransportUnix, Host: "@" + endpoint}
+	case "dns", "passthrough":
+		return ParseHostPort(endpoint)
+	default:
+		// Targets without a registered scheme are passed through as-is.
+		return ParseHostPort(target)
+	}
+}
+
+// Attributes returns the semantic convention attributes of a connection to
+// the server address from the peer address. The server address is the
+// logical server name (i.e. server.address and server.port), and the peer is
+// the remote end of the connection (i.e. network.peer.address and
+// network.peer.port). Either address may be the zero Addr if unknown.
+//
+// The network.transport attribute is set from the peer transport, or the
+// server transport if the peer transport is unknown.
+func Attributes(server, peer Addr) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if peer.Host != "" {
+		attrs = append(attrs, semconv.NetworkPeerAddress(peer.Host))
+		if peer.Port > 0 {
+			attrs = append(attrs, semconv.NetworkPeerPort(peer.Port))
+		}
+	}
+	if server.Host != "" {
+		attrs = append(attrs, semconv.ServerAddress(server.Host))
+		if server.Port > 0 {
+			attrs = append(attrs, semconv.ServerPort(server.Port))
+		}
+	}
+
+	transport := peer.Transport
+	if transport == TransportUnknown {
+		transport = server.Transport
+	}
+	if kv := transport.Attribute(); kv.Valid() {
+		attrs = append(attrs, kv)
+	}
+	return attrs
+}
+
+func isUnixPath(s string) bool {
+	return strings.HasPrefix(s, "/") || strings.HasPrefix(s, "@")
+}
+
+// normalizeHost returns the canonical form of host if it is an IP address.
+// Otherwise, host is returned unchanged.
+func normalizeHost(host string) string {
+	a, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	return a.Unmap().String()
+}
+
+func validPort(port int) int {
+	if port <= 0 || port > 65535 {
+		return 0
+	}
+	return port
+}
@@ -0,0 +1,329 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package netattr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+func TestFromIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   []byte
+		zone string
+		port int
+		want Addr
+	}{
+		{
+			name: "IPv4",
+			ip:   []byte{127, 0, 0, 1},
+			port: 8080,
+			want: Addr{Transport: TransportTCP, Host: "127.0.0.1", Port: 8080},
+		},
+		{
+			name: "IPv4Mapped",
+			ip:   []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 0, 0, 1},
+			port: 443,
+			want: Addr{Transport: TransportTCP, Host: "10.0.0.1", Port: 443},
+		},
+		{
+			name: "IPv4ZoneIgnored",
+			ip:   []byte{10, 0, 0, 1},
+			zone: "eth0",
+			port: 443,
+			want: Addr{Transport: TransportTCP, Host: "10.0.0.1", Port: 443},
+		},
+		{
+			name: "IPv6",
+			ip:   []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			port: 50051,
+			want: Addr{Transport: TransportTCP, Host: "::1", Port: 50051},
+		},
+		{
+			name: "IPv6LinkLocalZone",
+			ip:   []byte{0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			zone: "eth0",
+			port: 80,
+			want: Addr{Transport: TransportTCP, Host: "fe80::1%eth0", Port: 80},
+		},
+		{
+			name: "InvalidPort",
+			ip:   []byte{127, 0, 0, 1},
+			port: 70000,
+			want: Addr{Transport: TransportTCP, Host: "127.0.0.1"},
+		},
+		{
+			name: "IPv4Unspecified",
+			ip:   []byte{0, 0, 0, 0},
+			port: 80,
+		},
+		{
+			name: "IPv4MappedUnspecified",
+			ip:   []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0, 0, 0, 0},
+			port: 80,
+		},
+		{
+			name: "IPv6Unspecified",
+			ip:   make([]byte, 16),
+			port: 80,
+		},
+		{
+			name: "InvalidIP",
+			ip:   []byte{127, 0, 1},
+			port: 80,
+		},
+		{
+			name: "Empty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, FromIP(test.ip, test.zone, test.port))
+		})
+	}
+}
+
+func TestNetAddr(t *testing.T) {
+	tests := []struct {
+		name string
+		addr NetAddr
+		want Addr
+	}{
+		{
+			name: "IPv4",
+			addr: NetAddr{IP: [16]uint8{10, 0, 0, 2}, Port: 52044, IPLen: 4},
+			want: Addr{Transport: TransportTCP, Host: "10.0.0.2", Port: 52044},
+		},
+		{
+			name: "IPv6Zone",
+			addr: NetAddr{IP: [16]uint8{0xfe, 0x80, 15: 1}, Port: 80, IPLen: 16, Zone: [16]byte{'e', 't', 'h', '0'}},
+			want: Addr{Transport: TransportTCP, Host: "fe80::1%eth0", Port: 80},
+		},
+		{
+			name: "ZoneNotTerminated",
+			addr: NetAddr{IP: [16]uint8{0xfe, 0x80, 15: 1}, IPLen: 16, Zone: [16]byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p'}},
+			want: Addr{Transport: TransportTCP, Host: "fe80::1%abcdefghijklmnop"},
+		},
+		{
+			name: "NotRead",
+			addr: NetAddr{Port: 1701},
+		},
+		{
+			name: "InvalidLength",
+			addr: NetAddr{IP: [16]uint8{10, 0, 0, 2}, Port: 1701, IPLen: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.addr.Addr())
+		})
+	}
+}
+
+func TestNetworkType(t *testing.T) {
+	tests := []struct {
+		name string
+		addr Addr
+		want attribute.KeyValue
+	}{
+		{
+			name: "IPv4",
+			addr: FromIP([]byte{10, 0, 0, 5}, "", 50051),
+			want: semconv.NetworkTypeIpv4,
+		},
+		{
+			name: "IPv4Mapped",
+			addr: FromIP([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 0, 0, 5}, "", 50051),
+			want: semconv.NetworkTypeIpv4,
+		},
+		{
+			name: "IPv4MappedHost",
+			addr: Addr{Transport: TransportTCP, Host: "::ffff:10.0.0.5"},
+			want: semconv.NetworkTypeIpv4,
+		},
+		{
+			name: "IPv6",
+			addr: FromIP([]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}, "", 50051),
+			want: semconv.NetworkTypeIpv6,
+		},
+		{
+			name: "IPv6Zone",
+			addr: ParseHostPort("[fe80::1%eth0]:8080"),
+			want: semconv.NetworkTypeIpv6,
+		},
+		{
+			name: "UDP",
+			addr: Addr{Transport: TransportUDP, Host: "10.0.0.5"},
+			want: semconv.NetworkTypeIpv4,
+		},
+		{
+			name: "Unspecified",
+			addr: FromIP(make([]byte, 16), "", 50051),
+		},
+		{
+			name: "DomainName",
+			addr: ParseHostPort("example.com:443"),
+		},
+		{
+			name: "Unix",
+			addr: ParseHostPort("/run/grpc.sock"),
+		},
+		{
+			name: "Empty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.addr.NetworkType())
+		})
+	}
+}
+
+func TestParseHostPort(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Addr
+	}{
+		{in: ""},
+		{in: ":8080"},
+		{in: "localhost", want: Addr{Transport: TransportTCP, Host: "localhost"}},
+		{in: "localhost:8080", want: Addr{Transport: TransportTCP, Host: "localhost", Port: 8080}},
+		{in: "localhost:http", want: Addr{Transport: TransportTCP, Host: "localhost"}},
+		{in: "localhost:0", want: Addr{Transport: TransportTCP, Host: "localhost"}},
+		{in: "127.0.0.1:80", want: Addr{Transport: TransportTCP, Host: "127.0.0.1", Port: 80}},
+		{in: "::1", want: Addr{Transport: TransportTCP, Host: "::1"}},
+		{in: "[::1]", want: Addr{Transport: TransportTCP, Host: "::1"}},
+		{in: "[::1]:8080", want: Addr{Transport: TransportTCP, Host: "::1", Port: 8080}},
+		{in: "[::ffff:10.0.0.1]:80", want: Addr{Transport: TransportTCP, Host: "10.0.0.1", Port: 80}},
+		{in: "[2001:DB8::1]:80", want: Addr{Transport: TransportTCP, Host: "2001:db8::1", Port: 80}},
+		{in: "[fe80::1%eth0]:8080", want: Addr{Transport: TransportTCP, Host: "fe80::1%eth0", Port: 8080}},
+		{in: "fe80::1%eth0", want: Addr{Transport: TransportTCP, Host: "fe80::1%eth0"}},
+		{in: "/run/app.sock", want: Addr{Transport: TransportUnix, Host: "/run/app.sock"}},
+		{in: "@abstract", want: Addr{Transport: TransportUnix, Host: "@abstract"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			assert.Equal(t, test.want, ParseHostPort(test.in))
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Addr
+	}{
+		{in: ""},
+		{in: "localhost:50051", want: Addr{Transport: TransportTCP, Host: "localhost", Port: 50051}},
+		{in: "127.0.0.1:1701", want: Addr{Transport: TransportTCP, Host: "127.0.0.1", Port: 1701}},
+		{in: "[::1]:1701", want: Addr{Transport: TransportTCP, Host: "::1", Port: 1701}},
+		{in: "[fe80::1%eth0]:1701", want: Addr{Transport: TransportTCP, Host: "fe80::1%eth0", Port: 1701}},
+		{in: "dns:///example.com:443", want: Addr{Transport: TransportTCP, Host: "example.com", Port: 443}},
+		{in: "dns://8.8.8.8/example.com:443", want: Addr{Transport: TransportTCP, Host: "example.com", Port: 443}},
+		{in: "dns:example.com", want: Addr{Transport: TransportTCP, Host: "example.com"}},
+		{in: "passthrough:///[::1]:50051", want: Addr{Transport: TransportTCP, Host: "::1", Port: 50051}},
+		{in: "unix:///tmp/grpc.sock", want: Addr{Transport: TransportUnix, Host: "/tmp/grpc.sock"}},
+		{in: "unix://tmp/grpc.sock", want: Addr{Transport: TransportUnix, Host: "/tmp/grpc.sock"}},
+		{in: "unix:/tmp/grpc.sock", want: Addr{Transport: TransportUnix, Host: "/tmp/grpc.sock"}},
+		{in: "unix:grpc.sock", want: Addr{Transport: TransportUnix, Host: "grpc.sock"}},
+		{in: "unix:", want: Addr{}},
+		{in: "unix-abstract:grpc", want: Addr{Transport: TransportUnix, Host: "@grpc"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			assert.Equal(t, test.want, ParseTarget(test.in))
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	tests := []struct {
+		name         string
+		server, peer Addr
+		want         []attribute.KeyValue
+	}{
+		{
+			name: "Empty",
+		},
+		{
+			name:   "ServerOnly",
+			server: Addr{Transport: TransportTCP, Host: "localhost", Port: 8080},
+			want: []attribute.KeyValue{
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(8080),
+				semconv.NetworkTransportTCP,
+			},
+		},
+		{
+			name: "PeerOnly",
+			peer: Addr{Transport: TransportTCP, Host: "fe80::1%eth0", Port: 51234},
+			want: []attribute.KeyValue{
+				semconv.NetworkPeerAddress("fe80::1%eth0"),
+				semconv.NetworkPeerPort(51234),
+				semconv.NetworkTransportTCP,
+			},
+		},
+		{
+			name:   "ServerAndPeer",
+			server: Addr{Transport: TransportTCP, Host: "localhost", Port: 8080},
+			peer:   Addr{Transport: TransportTCP, Host: "::1", Port: 51234},
+			want: []attribute.KeyValue{
+				semconv.NetworkPeerAddress("::1"),
+				semconv.NetworkPeerPort(51234),
+				semconv.ServerAddress("localhost"),
+				semconv.ServerPort(8080),
+				semconv.NetworkTransportTCP,
+			},
+		},
+		{
+			name:   "PeerTransportPreferred",
+			server: Addr{Transport: TransportTCP, Host: "localhost"},
+			peer:   Addr{Transport: TransportUnix, Host: "@"},
+			want: []attribute.KeyValue{
+				semconv.NetworkPeerAddress("@"),
+				semconv.ServerAddress("localhost"),
+				semconv.NetworkTransportUnix,
+			},
+		},
+		{
+			name:   "Unix",
+			server: Addr{Transport: TransportUnix, Host: "/tmp/grpc.sock"},
+			want: []attribute.KeyValue{
+				semconv.ServerAddress("/tmp/grpc.sock"),
+				semconv.NetworkTransportUnix,
+			},
+		},
+		{
+			name:   "UDP",
+			server: Addr{Transport: TransportUDP, Host: "10.0.0.1", Port: 53},
+			want: []attribute.KeyValue{
+				semconv.ServerAddress("10.0.0.1"),
+				semconv.ServerPort(53),
+				semconv.NetworkTransportUDP,
+			},
+		},
+		{
+			name:   "UnknownTransport",
+			server: Addr{Host: "localhost"},
+			want: []attribute.KeyValue{
+				semconv.ServerAddress("localhost"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Attributes(test.server, test.peer))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package privilege provides the Linux capabilities needed by the
+// instrumentation and drops the ones no longer needed.
+package privilege
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Cap is a Linux capability.
+type Cap uint
+
+// Capabilities used by the instrumentation.
+const (
+	CapDACReadSearch Cap = 2
+	CapSysPtrace     Cap = 19
+	CapSysAdmin      Cap = 21
+	CapSysResource   Cap = 24
+	CapPerfmon       Cap = 38
+	CapBPF           Cap = 39
+)
+
+var capNames = map[Cap]string{
+	CapDACReadSearch: "CAP_DAC_READ_SEARCH",
+	CapSysPtrace:     "CAP_SYS_PTRACE",
+	CapSysAdmin:      "CAP_SYS_ADMIN",
+	CapSysResource:   "CAP_SYS_RESOURCE",
+	CapPerfmon:       "CAP_PERFMON",
+	CapBPF:           "CAP_BPF",
+}
+
+func (c Cap) String() string {
+	if n, ok := capNames[c]; ok {
+		return n
+	}
+	return "CAP_" + strconv.Itoa(int(c))
+}
+
+// Set is a set of Linux capabilities.
+type Set uint64
+
+// Of returns the set of caps.
+func Of(caps ...Cap) Set {
+	var s Set
+	for _, c := range caps {
+		s |= 1 << c
+	}
+	return s
+}
+
+// Has returns true if s contains c.
+func (s Set) Has(c Cap) bool { return s&(1<<c) != 0 }
+
+// Caps returns the capabilities of s in ascending order.
+func (s Set) Caps() []Cap {
+	caps := make([]Cap, 0, bits.OnesCount64(uint64(s)))
+	for v := uint64(s); v != 0; v &= v - 1 {
+		caps = append(caps, Cap(bits.TrailingZeros64(v)))
+	}
+	return caps
+}
+
+func (s Set) String() string {
+	names := make([]string, 0, bits.OnesCount64(uint64(s)))
+	for _, c := range s.Caps() {
+		names = append(names, c.String())
+	}
+	return strings.Join(names, ",")
+}
+
+var (
+	// Load are the capabilities needed to load and attach probes: loading
+	// eBPF programs and maps (CAP_BPF, CAP_PERFMON, or CAP_SYS_ADMIN on
+	// kernels <5.8), mounting the BPF file-system (CAP_SYS_ADMIN), removing
+	// the memlock limit on kernels <5.11 (CAP_SYS_RESOURCE), and reading the
+	// executable and memory of the target process (CAP_SYS_PTRACE,
+	// CAP_DAC_READ_SEARCH).
+	Load = Of(CapSysAdmin, CapBPF, CapPerfmon, CapSysResource, CapSysPtrace, CapDACReadSearch)
+
+	// Reattach are the capabilities needed to load probes after the
+	// instrumentation is loaded, when configuration updates enable them.
+	Reattach = Load
+
+	// Discovery are the capabilities needed to discover and instrument other
+	// processes: reading their /proc entries and loading probes.
+	Discovery = Load
+)
+
+// Current returns the effective capabilities of the process.
+func Current() (Set, error) { return current() }
+
+// Drop removes the capabilities of the process not in keep. They are removed
+// from the effective, permitted, and inheritable sets of all the threads of
+// the process, which also removes them from the ambient set. They are removed
+// from the bounding set as well if the process has CAP_SETPCAP, so they
+// cannot be regained by executing a program.
+//
+// The capabilities dropped cannot be regained by the process.
+func Drop(keep Set) error { return drop(keep) }
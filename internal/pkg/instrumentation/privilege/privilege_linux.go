@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package privilege
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const capSetPCap Cap = 8
+
+// capabilities returns the capability sets of the calling thread.
+func capabilities() (unix.CapUserHeader, [2]unix.CapUserData, error) {
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	err := unix.Capget(&hdr, &data[0])
+	return hdr, data, err
+}
+
+func current() (Set, error) {
+	_, data, err := capabilities()
+	if err != nil {
+		return 0, err
+	}
+	return Set(data[0].Effective) | Set(data[1].Effective)<<32, nil
+}
+
+func drop(keep Set) error {
+	hdr, data, err := capabilities()
+	if err != nil {
+		return err
+	}
+
+	// Capabilities are per-thread: all the threads of the process need to
+	// drop them. This is not supported by the runtime if cgo is used.
+	if Set(data[0].Effective).Has(capSetPCap) {
+		for c := Cap(0); c < 64; c++ {
+			if keep.Has(c) {
+				continue
+			}
+			_, _, errno := syscall.AllThreadsSyscall(unix.SYS_PRCTL, unix.PR_CAPBSET_DROP, uintptr(c), 0)
+			if errno == unix.EINVAL {
+				// Past the last capability of the kernel.
+				break
+			}
+			if errno != 0 {
+				return dropErr(fmt.Sprintf("drop %s from the bounding set", c), errno)
+			}
+		}
+	}
+
+	for i := range data {
+		k := uint32(keep >> (32 * i))
+		data[i].Effective &= k
+		data[i].Permitted &= k
+		data[i].Inheritable &= k
+	}
+	_, _, errno := syscall.AllThreadsSyscall(
+		unix.SYS_CAPSET,
+		uintptr(unsafe.Pointer(&hdr)),
+		uintptr(unsafe.Pointer(&data[0])),
+		0,
+	)
+	if errno != 0 {
+		return dropErr("set capabilities", errno)
+	}
+	return nil
+}
+
+func dropErr(op string, errno syscall.Errno) error {
+	if errors.Is(errno, syscall.ENOTSUP) {
+		return fmt.Errorf("%s: not supported in programs using cgo: %w", op, errno)
+	}
+	return fmt.Errorf("%s: %w", op, errno)
+}
@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package privilege
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// envDropKeep is the environment variable of the capabilities kept by the
+// test process running testDrop.
+const envDropKeep = "PRIVILEGE_TEST_DROP_KEEP"
+
+func TestMain(m *testing.M) {
+	if v, ok := os.LookupEnv(envDropKeep); ok {
+		keep, _ := strconv.ParseUint(v, 10, 64)
+		if err := testDrop(Set(keep), os.Args[len(os.Args)-1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testDrop drops the capabilities not in keep and checks the privileged
+// operation of mounting a file-system on dir succeeds only if CAP_SYS_ADMIN
+// is kept.
+func testDrop(keep Set, dir string) error {
+	mount := func() error {
+		if err := unix.Mount("none", dir, "tmpfs", 0, ""); err != nil {
+			return err
+		}
+		return unix.Unmount(dir, 0)
+	}
+	if err := mount(); err != nil {
+		return fmt.Errorf("mount before drop: %w", err)
+	}
+
+	if err := Drop(keep); err != nil {
+		return fmt.Errorf("drop: %w", err)
+	}
+	got, err := Current()
+	if err != nil {
+		return err
+	}
+	if got&^keep != 0 {
+		return fmt.Errorf("capabilities not dropped: %s", got&^keep)
+	}
+
+	err = mount()
+	switch {
+	case keep.Has(CapSysAdmin) && err != nil:
+		return fmt.Errorf("mount with CAP_SYS_ADMIN kept: %w", err)
+	case !keep.Has(CapSysAdmin) && !errors.Is(err, unix.EPERM):
+		return fmt.Errorf("mount after drop: got %v, want EPERM", err)
+	}
+	return nil
+}
+
+func TestDrop(t *testing.T) {
+	caps, err := Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.Has(CapSysAdmin) {
+		t.Skip("CAP_SYS_ADMIN is needed")
+	}
+
+	for _, tc := range []struct {
+		name string
+		keep Set
+	}{
+		{name: "all", keep: 0},
+		{name: "keep load", keep: Load},
+		{name: "keep ptrace", keep: Of(CapSysPtrace)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Capabilities cannot be regained once dropped, drop them in a
+			// new process.
+			cmd := exec.Command(os.Args[0], "-test.run=^$", t.TempDir())
+			cmd.Env = append(os.Environ(), envDropKeep+"="+strconv.FormatUint(uint64(tc.keep), 10))
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+		})
+	}
+}
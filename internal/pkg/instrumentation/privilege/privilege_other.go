@@ -0,0 +1,12 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+
+package privilege
+
+import "errors"
+
+func current() (Set, error) { return 0, errors.ErrUnsupported }
+
+func drop(Set) error { return errors.ErrUnsupported }
@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package privilege
+
+import (
+	"slices"
+	"testing"
+)
+
+// The tests of this package do not use testify: its dependency on the net
+// package links the cgo resolver, and capabilities cannot be dropped by
+// programs using cgo.
+
+func TestSet(t *testing.T) {
+	s := Of(CapBPF, CapSysAdmin, CapDACReadSearch)
+	if !s.Has(CapBPF) || !s.Has(CapSysAdmin) || !s.Has(CapDACReadSearch) {
+		t.Errorf("%s: missing capability", s)
+	}
+	if s.Has(CapSysPtrace) {
+		t.Errorf("%s: unexpected %s", s, CapSysPtrace)
+	}
+	if got, want := s.Caps(), []Cap{CapDACReadSearch, CapSysAdmin, CapBPF}; !slices.Equal(got, want) {
+		t.Errorf("Caps() = %v, want %v", got, want)
+	}
+	if got, want := (s | Of(7)).String(), "CAP_DAC_READ_SEARCH,CAP_7,CAP_SYS_ADMIN,CAP_BPF"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := Set(0).String(); got != "" {
+		t.Errorf("String() = %q, want empty", got)
+	}
+}
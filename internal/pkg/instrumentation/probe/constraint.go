@@ -22,6 +22,10 @@ const (
 )
 
 // PackageConstraints is a versioning requirement for a package.
+//
+// A probe whose offsets are only known for some versions of a package uses
+// a constraint matching these versions with FailureModeIgnore: its uprobes
+// are skipped for the other versions, for which no offset is injected.
 type PackageConstraints struct {
 	// Package is the package import path that this constraint applies to.
 	Package string
@@ -7,12 +7,15 @@ package probe
 import (
 	"bytes"
 	"context"
+	"debug/elf"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"sync/atomic"
 
 	"github.com/Masterminds/semver/v3"
@@ -21,11 +24,15 @@ import (
 	"github.com/cilium/ebpf/perf"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"go.opentelemetry.io/auto/internal/pkg/inject"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/bpffs"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/debug"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/eventdump"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
 	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe/sampling"
 	"go.opentelemetry.io/auto/internal/pkg/process"
 	"go.opentelemetry.io/auto/internal/pkg/structfield"
@@ -82,6 +89,19 @@ type Base[BPFObj any, BPFEvent any] struct {
 	collection      *ebpf.Collection
 	closers         []io.Closer
 	samplingManager *sampling.Manager
+	libVersion      *semver.Version
+	dump            *eventdump.Writer
+
+	events atomic.Uint64
+	lost   atomic.Uint64
+}
+
+// Stats are the event counters of a [Probe].
+type Stats struct {
+	// Events is the number of events read from the eBPF program.
+	Events uint64
+	// Lost is the number of events dropped because the perf buffer was full.
+	Lost uint64
 }
 
 const (
@@ -103,6 +123,9 @@ func (i *Base[BPFObj, BPFEvent]) Manifest() Manifest {
 		if sfc, ok := cnst.(StructFieldConstMinVersion); ok {
 			structFieldIDs = append(structFieldIDs, sfc.StructField.ID)
 		}
+		if sfc, ok := cnst.(StructFieldConstOptional); ok {
+			structFieldIDs = append(structFieldIDs, sfc.StructField.ID)
+		}
 	}
 
 	symbols := make([]FunctionSymbol, 0, len(i.Uprobes))
@@ -154,10 +177,36 @@ func (i *Base[BPFObj, BPFEvent]) Load(
 	}
 
 	i.closers = append(i.closers, i.reader)
+	i.libVersion = moduleVersion(info, i.ID.InstrumentedPkg)
 
 	return nil
 }
 
+// moduleVersion returns the version of the module providing pkg in the
+// process described by info. The Go version is returned for standard library
+// packages. If the version is unknown, nil is returned.
+func moduleVersion(info *process.Info, pkg string) *semver.Version {
+	if info == nil {
+		return nil
+	}
+
+	var mod string
+	for m := range info.Modules {
+		if len(m) > len(mod) && (pkg == m || strings.HasPrefix(pkg, m+"/")) {
+			mod = m
+		}
+	}
+	if mod != "" {
+		return info.Modules[mod]
+	}
+
+	// Standard library import paths do not contain a domain name.
+	if elem, _, _ := strings.Cut(pkg, "/"); !strings.Contains(elem, ".") {
+		return info.GoVersion
+	}
+	return nil
+}
+
 func (i *Base[BPFObj, BPFEvent]) InjectConsts(info *process.Info, spec *ebpf.CollectionSpec) error {
 	var err error
 	var opts []inject.Option
@@ -183,7 +232,9 @@ func (i *Base[BPFObj, BPFEvent]) loadUprobes(exec *link.Executable, info *proces
 	for _, up := range i.Uprobes {
 		var skip bool
 		for _, pc := range up.PackageConstraints {
-			if pc.Constraints.Check(info.Modules[pc.Package]) {
+			// The constraint is not met if the package is not used by the
+			// target process (e.g. another major version of its module is).
+			if ver := info.Modules[pc.Package]; ver != nil && pc.Constraints.Check(ver) {
 				continue
 			}
 
@@ -295,25 +346,97 @@ func (i *Base[BPFObj, BPFEvent]) read() (*BPFEvent, error) {
 	}
 
 	if record.LostSamples != 0 {
+		i.lost.Add(record.LostSamples)
 		i.Logger.Debug("perf event ring buffer full", "dropped", record.LostSamples)
 		return nil, err
 	}
+	i.events.Add(1)
 
-	var event *BPFEvent
-	if i.ProcessRecord != nil {
-		event, err = i.ProcessRecord(record)
-	} else {
-		event = new(BPFEvent)
-		buf := bytes.NewReader(record.RawSample)
-		err = binary.Read(buf, binary.LittleEndian, event)
+	if i.dump != nil {
+		if err := i.dump.Write(i.ID.String(), record.RawSample); err != nil {
+			i.Logger.Debug("failed to dump event", "error", err)
+		}
 	}
+	return i.decode(record)
+}
 
-	if err != nil {
+// decode decodes the BPFEvent of record.
+func (i *Base[BPFObj, BPFEvent]) decode(record perf.Record) (*BPFEvent, error) {
+	if i.ProcessRecord != nil {
+		return i.ProcessRecord(record)
+	}
+	event := new(BPFEvent)
+	buf := bytes.NewReader(record.RawSample)
+	if err := binary.Read(buf, binary.LittleEndian, event); err != nil {
 		return nil, err
 	}
 	return event, nil
 }
 
+// SetEventDump sets the Writer the raw events read from the eBPF program are
+// written to, before they are decoded. It must be called before the Probe is
+// run.
+func (i *Base[BPFObj, BPFEvent]) SetEventDump(w *eventdump.Writer) {
+	i.dump = w
+}
+
+// DecodeEvent decodes a raw event read from the eBPF program, as written to
+// an event dump, into the event type of the Probe.
+func (i *Base[BPFObj, BPFEvent]) DecodeEvent(raw []byte) (any, error) {
+	return i.decode(perf.Record{RawSample: raw})
+}
+
+// Stats returns the event counters of the Probe.
+func (i *Base[BPFObj, BPFEvent]) Stats() Stats {
+	return Stats{Events: i.events.Load(), Lost: i.lost.Load()}
+}
+
+// MapUsage is the fill level of an eBPF hash map of a [Probe].
+type MapUsage struct {
+	// Name is the name of the map.
+	Name string
+	// Entries is the number of entries in the map.
+	Entries uint32
+	// MaxEntries is the maximum number of entries of the map.
+	MaxEntries uint32
+}
+
+// MapUsage returns the fill level of the eBPF hash maps of the Probe, sorted
+// by name. Entries are counted by iterating the keys of each map, this is not
+// intended to be called often.
+func (i *Base[BPFObj, BPFEvent]) MapUsage() []MapUsage {
+	if i.collection == nil {
+		return nil
+	}
+
+	var out []MapUsage
+	for name, m := range i.collection.Maps {
+		switch m.Type() {
+		case ebpf.Hash, ebpf.LRUHash, ebpf.PerCPUHash, ebpf.LRUCPUHash:
+		default:
+			continue
+		}
+
+		u := MapUsage{Name: name, MaxEntries: m.MaxEntries()}
+		key, next := make([]byte, m.KeySize()), make([]byte, m.KeySize())
+		var prev any
+		// Bound the iteration as entries can be updated concurrently.
+		for u.Entries < u.MaxEntries {
+			if err := m.NextKey(prev, next); err != nil {
+				break
+			}
+			u.Entries++
+			copy(key, next)
+			prev = key
+		}
+		out = append(out, u)
+	}
+	slices.SortFunc(out, func(a, b MapUsage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
 // Close stops the Probe.
 func (i *Base[BPFObj, BPFEvent]) Close() error {
 	if i.collection != nil {
@@ -329,26 +452,153 @@ func (i *Base[BPFObj, BPFEvent]) Close() error {
 	return err
 }
 
+// LibraryVersionKey is the instrumentation scope attribute key for the version
+// of the module instrumented by a [SpanProducer].
+const LibraryVersionKey = "otel.auto.instrumented_library.version"
+
+// ScopeName returns the instrumentation scope name of the telemetry produced
+// by the probe identified by id.
+func ScopeName(id ID) string {
+	return "go.opentelemetry.io/auto/" + id.String()
+}
+
 type SpanProducer[BPFObj any, BPFEvent any] struct {
 	Base[BPFObj, BPFEvent]
 
 	Version   string
 	SchemaURL string
 	ProcessFn func(*BPFEvent) ptrace.SpanSlice
+	// MetricFn is an optional function returning the metrics of an event.
+	// It is called for every event, including the ones ProcessFn returns no
+	// span for (e.g. unsampled requests).
+	MetricFn func(*BPFEvent) pmetric.MetricSlice
+	// HandleMetricsFn is an optional function called with whether the
+	// metrics returned by MetricFn are handled, before the probe is loaded
+	// (e.g. to only output the events read for their metrics if so).
+	HandleMetricsFn func(handled bool)
+}
+
+// HandleMetrics calls the HandleMetricsFn of i, if any, with handled.
+func (i *SpanProducer[BPFObj, BPFEvent]) HandleMetrics(handled bool) {
+	if i.HandleMetricsFn != nil {
+		i.HandleMetricsFn(handled)
+	}
+}
+
+// Scope returns the instrumentation scope of spans produced by i. It is only
+// complete after i has been loaded.
+func (i *SpanProducer[BPFObj, BPFEvent]) Scope() pcommon.InstrumentationScope {
+	return i.scope(i.Version)
+}
+
+// scope returns the instrumentation scope of the telemetry produced by i with
+// the auto-instrumentation version.
+func (i *Base[BPFObj, BPFEvent]) scope(version string) pcommon.InstrumentationScope {
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName(ScopeName(i.ID))
+	scope.SetVersion(version)
+	if i.libVersion != nil {
+		scope.Attributes().PutStr(LibraryVersionKey, i.libVersion.String())
+	}
+	return scope
 }
 
 // Run runs the events processing loop.
 func (i *SpanProducer[BPFObj, BPFEvent]) Run(h *pipeline.Handler) {
-	if h.TraceHandler == nil {
+	if h.TraceHandler == nil && (i.MetricFn == nil || h.MetricHandler == nil) {
 		i.Logger.Info("tracing not supported by handler, dropping traces", "handler", h)
 		return
 	}
 
 	// Bind the single scope to the handler.
-	scope := pcommon.NewInstrumentationScope()
-	scope.SetName("go.opentelemetry.io/auto/" + i.ID.InstrumentedPkg)
-	scope.SetVersion(i.Version)
-	handler := h.WithScope(scope, i.SchemaURL)
+	handler := h.WithScope(i.Scope(), i.SchemaURL)
+
+	for {
+		event, err := i.read()
+		if err != nil {
+			if errors.Is(err, perf.ErrClosed) {
+				return
+			}
+			continue
+		}
+		if event == nil {
+			continue
+		}
+
+		i.handle(handler, event)
+	}
+}
+
+// handle passes the spans and metrics produced from event to h.
+func (i *SpanProducer[BPFObj, BPFEvent]) handle(h pipeline.Handler, event *BPFEvent) {
+	if spans := i.ProcessFn(event); spans.Len() > 0 {
+		h.Trace(spans)
+	}
+	if i.MetricFn == nil {
+		return
+	}
+	if metrics := i.MetricFn(event); metrics.Len() > 0 {
+		h.Metric(metrics)
+	}
+}
+
+// Replay decodes raw, an event read from the eBPF program as written to an
+// event dump, and passes the spans it produces to h as if they were read by
+// the running probe.
+func (i *SpanProducer[BPFObj, BPFEvent]) Replay(raw []byte, h *pipeline.Handler) error {
+	event, err := i.decode(perf.Record{RawSample: raw})
+	if err != nil || event == nil {
+		return err
+	}
+	i.handle(h.WithScope(i.Scope(), i.SchemaURL), event)
+	return nil
+}
+
+// LogProducer is a [Probe] producing log records from the events read from
+// its eBPF program.
+type LogProducer[BPFObj any, BPFEvent any] struct {
+	Base[BPFObj, BPFEvent]
+
+	Version   string
+	SchemaURL string
+	ProcessFn func(*BPFEvent) plog.LogRecordSlice
+}
+
+// LogSource is implemented by the probes producing log records. They are only
+// loaded if the log records are handled.
+type LogSource interface {
+	logSource()
+}
+
+func (*LogProducer[BPFObj, BPFEvent]) logSource() {}
+
+// MetricSource is implemented by the probes producing metrics. HandleMetrics
+// is called with whether their metrics are handled before they are loaded.
+type MetricSource interface {
+	HandleMetrics(handled bool)
+}
+
+// Optional is implemented by the probes that are disabled by default. They
+// are only loaded if Enabled returns true.
+type Optional interface {
+	Enabled() bool
+}
+
+// Scope returns the instrumentation scope of log records produced by i. It is
+// only complete after i has been loaded.
+func (i *LogProducer[BPFObj, BPFEvent]) Scope() pcommon.InstrumentationScope {
+	return i.scope(i.Version)
+}
+
+// Run runs the events processing loop.
+func (i *LogProducer[BPFObj, BPFEvent]) Run(h *pipeline.Handler) {
+	if h.LogHandler == nil {
+		i.Logger.Info("logs not supported by handler, dropping logs", "handler", h)
+		return
+	}
+
+	// Bind the single scope to the handler.
+	handler := h.WithScope(i.Scope(), i.SchemaURL)
 
 	for {
 		event, err := i.read()
@@ -362,8 +612,20 @@ func (i *SpanProducer[BPFObj, BPFEvent]) Run(h *pipeline.Handler) {
 			continue
 		}
 
-		handler.Trace(i.ProcessFn(event))
+		handler.Log(i.ProcessFn(event))
+	}
+}
+
+// Replay decodes raw, an event read from the eBPF program as written to an
+// event dump, and passes the log records it produces to h as if they were read
+// by the running probe.
+func (i *LogProducer[BPFObj, BPFEvent]) Replay(raw []byte, h *pipeline.Handler) error {
+	event, err := i.decode(perf.Record{RawSample: raw})
+	if err != nil || event == nil {
+		return err
 	}
+	h.WithScope(i.Scope(), i.SchemaURL).Log(i.ProcessFn(event))
+	return nil
 }
 
 type TraceProducer[BPFObj any, BPFEvent any] struct {
@@ -397,6 +659,19 @@ func (i *TraceProducer[BPFObj, BPFEvent]) Run(h *pipeline.Handler) {
 	}
 }
 
+// Replay decodes raw, an event read from the eBPF program as written to an
+// event dump, and passes the spans it produces to h as if they were read by
+// the running probe.
+func (i *TraceProducer[BPFObj, BPFEvent]) Replay(raw []byte, h *pipeline.Handler) error {
+	event, err := i.decode(perf.Record{RawSample: raw})
+	if err != nil || event == nil || h.TraceHandler == nil {
+		return err
+	}
+	scope, url, spans := i.ProcessFn(event)
+	h.TraceHandler.HandleTrace(scope, url, spans)
+	return nil
+}
+
 // Uprobe is an eBPF program that is attached in the entry point and/or the return of a function.
 type Uprobe struct {
 	// Sym is the symbol name of the function to attach the eBPF program to.
@@ -517,9 +792,20 @@ func (c StructFieldConst) SetLogger(l *slog.Logger) Const {
 // version of the struct field module is known. If it is not, an error is
 // returned.
 func (c StructFieldConst) InjectOption(info *process.Info) (inject.Option, error) {
+	off, err := c.offset(info)
+	if err != nil {
+		return nil, err
+	}
+	return inject.WithKeyValue(c.Key, off), nil
+}
+
+// offset returns the offset of the struct field in the target process
+// described by info: the known offset for the version of the struct field
+// module, or the offset found in the DWARF data of the executable.
+func (c StructFieldConst) offset(info *process.Info) (uint64, error) {
 	ver, ok := info.Modules[c.ID.ModPath]
 	if !ok {
-		return nil, fmt.Errorf("unknown module: %s", c.ID.ModPath)
+		return 0, fmt.Errorf("unknown module: %s", c.ID.ModPath)
 	}
 
 	off, ok := inject.GetOffset(c.ID, ver)
@@ -535,48 +821,60 @@ func (c StructFieldConst) InjectOption(info *process.Info) (inject.Option, error
 		var err error
 		off, err = inject.FindOffset(c.ID, info)
 		if err != nil {
-			return nil, fmt.Errorf("failed to find offset for %q: %w", c.ID, err)
+			return 0, fmt.Errorf("failed to find offset for %q: %w", c.ID, err)
 		}
 		if !off.Valid {
-			return nil, fmt.Errorf("failed to find valid offset for %q", c.ID)
+			return 0, fmt.Errorf("failed to find valid offset for %q", c.ID)
 		}
 	}
 
 	if c.logger != nil {
 		c.logger.Debug("Offset found", "key", c.Key, "id", c.ID, "offset", off.Offset)
 	}
-	return inject.WithKeyValue(c.Key, off.Offset), nil
+	return off.Offset, nil
 }
 
 // StructFieldConstMaxVersion is a [Const] for a struct field offset. These
 // struct field ID needs to be known offsets in the [inject] package. The
-// offset is only injected if the module version is less than the MaxVersion.
+// offset is only injected if the module version is less than the MaxVersion,
+// and greater than or equal to the MinVersion, if set.
 type StructFieldConstMaxVersion struct {
 	StructField StructFieldConst
 	// MaxVersion is the exclusive maximum version (it will only match versions
 	// less than this).
 	MaxVersion *semver.Version
+	// MinVersion is the optional inclusive minimum version of the module.
+	MinVersion *semver.Version
 }
 
 // InjectOption returns the appropriately configured [inject.WithOffset] if the
 // version of the struct field module is known and is less than the MaxVersion.
 // If the module version is not known, an error is returned. If the module
-// version is known but is greater than or equal to the MaxVersion, no offset
-// is injected.
+// version is known but is greater than or equal to the MaxVersion, or less
+// than the MinVersion, no offset is injected.
 func (c StructFieldConstMaxVersion) InjectOption(info *process.Info) (inject.Option, error) {
 	sf := c.StructField
-	ver, ok := info.Modules[sf.ID.ModPath]
-	if !ok {
+	if _, ok := info.Modules[sf.ID.ModPath]; !ok {
 		return nil, fmt.Errorf("unknown module version: %s", sf.ID.ModPath)
 	}
 
-	if !ver.LessThan(c.MaxVersion) {
+	if !c.applies(info) {
 		return nil, nil
 	}
 
 	return sf.InjectOption(info)
 }
 
+// applies returns true if the offset is injected for the target process
+// described by info.
+func (c StructFieldConstMaxVersion) applies(info *process.Info) bool {
+	ver, ok := info.Modules[c.StructField.ID.ModPath]
+	if !ok || !ver.LessThan(c.MaxVersion) {
+		return false
+	}
+	return c.MinVersion == nil || ver.GreaterThanEqual(c.MinVersion)
+}
+
 // StructFieldConstMinVersion is a [Const] for a struct field offset. These struct field
 // ID needs to be known offsets in the [inject] package. The offset is only
 // injected if the module version is greater than or equal to the MinVersion.
@@ -604,6 +902,74 @@ func (c StructFieldConstMinVersion) InjectOption(info *process.Info) (inject.Opt
 	return sf.InjectOption(info)
 }
 
+// applies returns true if the offset is injected for the target process
+// described by info.
+func (c StructFieldConstMinVersion) applies(info *process.Info) bool {
+	ver, ok := info.Modules[c.StructField.ID.ModPath]
+	return ok && ver.GreaterThanEqual(c.MinVersion)
+}
+
+// StructFieldConstOptional is a [Const] for a struct field offset. These
+// struct field ID needs to be known offsets in the [inject] package. The
+// offset is only injected if the struct field module is used by the target
+// process, e.g. for probes instrumenting several major versions of a module,
+// and its version is greater than or equal to the MinVersion, if set.
+type StructFieldConstOptional struct {
+	StructField StructFieldConst
+	// MinVersion is the optional inclusive minimum version of the module.
+	MinVersion *semver.Version
+	// FailureMode defines the behavior that is performed when the offset is
+	// not known, nor found in the executable of the target process. If the
+	// failure is not an error, no offset is injected.
+	FailureMode FailureMode
+}
+
+var _ setLogger = StructFieldConstOptional{}
+
+// SetLogger sets the Logger for StructFieldConstOptional operations.
+func (c StructFieldConstOptional) SetLogger(l *slog.Logger) Const {
+	c.StructField.logger = l
+	return c
+}
+
+// InjectOption returns the appropriately configured [inject.WithOffset] if the
+// struct field module is used by the target process and its version is
+// greater than or equal to the MinVersion, if set. Otherwise, no offset is
+// injected.
+func (c StructFieldConstOptional) InjectOption(info *process.Info) (inject.Option, error) {
+	if !c.applies(info) {
+		return nil, nil
+	}
+	opt, err := c.StructField.InjectOption(info)
+	if err == nil {
+		return opt, nil
+	}
+
+	logFn := func(string, ...any) {}
+	switch c.FailureMode {
+	case FailureModeIgnore:
+		if l := c.StructField.logger; l != nil {
+			logFn = l.Debug
+		}
+	case FailureModeWarn:
+		if l := c.StructField.logger; l != nil {
+			logFn = l.Warn
+		}
+	default:
+		// Unknown and FailureModeError.
+		return nil, err
+	}
+	logFn("offset not injected", "key", c.StructField.Key, "id", c.StructField.ID, "error", err)
+	return nil, nil
+}
+
+// applies returns true if the offset is injected for the target process
+// described by info.
+func (c StructFieldConstOptional) applies(info *process.Info) bool {
+	ver, ok := info.Modules[c.StructField.ID.ModPath]
+	return ok && (c.MinVersion == nil || (ver != nil && ver.GreaterThanEqual(c.MinVersion)))
+}
+
 // AllocationConst is a [Const] for all the allocation details that need to be
 // injected into an eBPF program.
 type AllocationConst struct {
@@ -648,6 +1014,17 @@ func (c AllocationConst) InjectOption(info *process.Info) (inject.Option, error)
 	return inject.WithAllocation(*alloc), nil
 }
 
+// BootClockConst is a [Const] that sets the kernel clock the eBPF program
+// reads span timestamps from. All probes with eBPF programs that include
+// "uprobe.h" need to include this [Const].
+type BootClockConst struct{}
+
+// InjectOption returns the appropriately configured [inject.WithKeyValue]
+// for the "boot_clock_supported" constant.
+func (c BootClockConst) InjectOption(*process.Info) (inject.Option, error) {
+	return inject.WithKeyValue("boot_clock_supported", kernel.BootClockSupported()), nil
+}
+
 // KeyValConst is a [Const] for a generic key-value pair.
 //
 // This should not be used as a replacement for any of the other provided
@@ -662,3 +1039,44 @@ type KeyValConst struct {
 func (c KeyValConst) InjectOption(*process.Info) (inject.Option, error) {
 	return inject.WithKeyValue(c.Key, c.Val), nil
 }
+
+// SymbolAddrConst is a [Const] for the address of a symbol of the executable
+// of the target process.
+//
+// The address is only known for executables not position independent and not
+// stripped of their symbols, 0 is injected for the others.
+type SymbolAddrConst struct {
+	Key    string
+	Symbol string
+}
+
+// InjectOption returns the appropriately configured [inject.WithKeyValue]
+// for the address of the symbol.
+func (c SymbolAddrConst) InjectOption(info *process.Info) (inject.Option, error) {
+	f, err := elf.Open(info.ID.ExePath())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return inject.WithKeyValue(c.Key, symbolAddr(f, c.Symbol)), nil
+}
+
+// symbolAddr returns the address of the symbol sym of f, 0 if it is not
+// known.
+func symbolAddr(f *elf.File, sym string) uint64 {
+	// The symbols of position independent executables are relocated.
+	if f.Type != elf.ET_EXEC {
+		return 0
+	}
+	syms, err := f.Symbols()
+	if err != nil {
+		return 0
+	}
+	for _, s := range syms {
+		if s.Name == sym {
+			return s.Value
+		}
+	}
+	return 0
+}
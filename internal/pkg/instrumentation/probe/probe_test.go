@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	"debug/elf"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/cilium/ebpf/perf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+func TestModuleVersion(t *testing.T) {
+	info := &process.Info{
+		GoVersion: semver.MustParse("1.24.5"),
+		Modules: map[string]*semver.Version{
+			"std":                           semver.MustParse("1.24.5"),
+			"google.golang.org/grpc":        semver.MustParse("1.69.0"),
+			"google.golang.org/grpc/stats":  semver.MustParse("0.1.0"),
+			"github.com/segmentio/kafka-go": semver.MustParse("v0.4.47"),
+		},
+	}
+
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{pkg: "net/http", want: "1.24.5"},
+		{pkg: "database/sql", want: "1.24.5"},
+		{pkg: "google.golang.org/grpc", want: "1.69.0"},
+		{pkg: "google.golang.org/grpc/internal/transport", want: "1.69.0"},
+		{pkg: "google.golang.org/grpc/stats/opentelemetry", want: "0.1.0"},
+		{pkg: "github.com/segmentio/kafka-go", want: "0.4.47"},
+		{pkg: "google.golang.org/grpcx"},
+		{pkg: "go.opentelemetry.io/otel"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pkg, func(t *testing.T) {
+			got := moduleVersion(info, test.pkg)
+			if test.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, test.want, got.String())
+		})
+	}
+
+	assert.Nil(t, moduleVersion(nil, "net/http"))
+}
+
+func TestSpanProducerScope(t *testing.T) {
+	p := &SpanProducer[struct{}, struct{}]{
+		Base: Base[struct{}, struct{}]{
+			ID: ID{SpanKind: trace.SpanKindServer, InstrumentedPkg: "google.golang.org/grpc"},
+		},
+		Version: "v0.23.0",
+	}
+
+	scope := p.Scope()
+	assert.Equal(t, "go.opentelemetry.io/auto/google.golang.org/grpc/server", scope.Name())
+	assert.Equal(t, "v0.23.0", scope.Version())
+	assert.Equal(t, 0, scope.Attributes().Len())
+
+	p.libVersion = semver.MustParse("1.69.0")
+	scope = p.Scope()
+	v, ok := scope.Attributes().Get(LibraryVersionKey)
+	assert.True(t, ok)
+	assert.Equal(t, "1.69.0", v.Str())
+}
+
+type logRecorder struct {
+	scope pcommon.InstrumentationScope
+	logs  plog.LogRecordSlice
+}
+
+func (r *logRecorder) HandleLog(scope pcommon.InstrumentationScope, _ string, logs plog.LogRecordSlice) {
+	r.scope, r.logs = scope, logs
+}
+
+func TestLogProducerReplay(t *testing.T) {
+	type event struct {
+		Level int32
+	}
+	p := &LogProducer[struct{}, event]{
+		Base: Base[struct{}, event]{
+			ID: ID{SpanKind: trace.SpanKindInternal, InstrumentedPkg: "log/slog"},
+		},
+		Version: "v0.23.0",
+		ProcessFn: func(e *event) plog.LogRecordSlice {
+			logs := plog.NewLogRecordSlice()
+			logs.AppendEmpty().SetSeverityNumber(plog.SeverityNumber(e.Level))
+			return logs
+		},
+	}
+
+	r := &logRecorder{}
+	require.NoError(t, p.Replay([]byte{9, 0, 0, 0}, &pipeline.Handler{LogHandler: r}))
+	assert.Equal(t, "go.opentelemetry.io/auto/log/slog/internal", r.scope.Name())
+	assert.Equal(t, "v0.23.0", r.scope.Version())
+	require.Equal(t, 1, r.logs.Len())
+	assert.Equal(t, plog.SeverityNumberInfo, r.logs.At(0).SeverityNumber())
+}
+
+type telemetryRecorder struct {
+	spans   []ptrace.SpanSlice
+	metrics []pmetric.MetricSlice
+}
+
+func (r *telemetryRecorder) HandleTrace(_ pcommon.InstrumentationScope, _ string, spans ptrace.SpanSlice) {
+	r.spans = append(r.spans, spans)
+}
+
+func (r *telemetryRecorder) HandleMetric(_ pcommon.InstrumentationScope, _ string, metrics pmetric.MetricSlice) {
+	r.metrics = append(r.metrics, metrics)
+}
+
+func TestSpanProducerReplayMetrics(t *testing.T) {
+	type event struct {
+		Sampled uint32
+	}
+	p := &SpanProducer[struct{}, event]{
+		Base: Base[struct{}, event]{
+			ID: ID{SpanKind: trace.SpanKindServer, InstrumentedPkg: "google.golang.org/grpc"},
+		},
+		ProcessFn: func(e *event) ptrace.SpanSlice {
+			spans := ptrace.NewSpanSlice()
+			if e.Sampled != 0 {
+				spans.AppendEmpty().SetName("span")
+			}
+			return spans
+		},
+		MetricFn: func(*event) pmetric.MetricSlice {
+			metrics := pmetric.NewMetricSlice()
+			metrics.AppendEmpty().SetName("metric")
+			return metrics
+		},
+	}
+
+	r := &telemetryRecorder{}
+	h := &pipeline.Handler{TraceHandler: r, MetricHandler: r}
+	require.NoError(t, p.Replay([]byte{1, 0, 0, 0}, h))
+	require.NoError(t, p.Replay([]byte{0, 0, 0, 0}, h))
+
+	// Empty span slices are not handled, the metrics of every event are.
+	assert.Len(t, r.spans, 1)
+	assert.Len(t, r.metrics, 2)
+}
+
+func TestSpanProducerHandleMetrics(t *testing.T) {
+	var p SpanProducer[struct{}, struct{}]
+	var _ MetricSource = &p
+	p.HandleMetrics(true) // No HandleMetricsFn.
+
+	var got []bool
+	p.HandleMetricsFn = func(handled bool) { got = append(got, handled) }
+	p.HandleMetrics(true)
+	p.HandleMetrics(false)
+	assert.Equal(t, []bool{true, false}, got)
+}
+
+func TestBaseDecodeEvent(t *testing.T) {
+	type event struct {
+		A uint32
+		B [4]byte
+	}
+	raw := []byte{1, 0, 0, 0, 'a', 'b', 0, 0}
+	want := &event{A: 1, B: [4]byte{'a', 'b'}}
+
+	p := &Base[struct{}, event]{}
+	got, err := p.DecodeEvent(raw)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = p.DecodeEvent(raw[:2])
+	assert.Error(t, err)
+
+	p.ProcessRecord = func(r perf.Record) (*event, error) {
+		return &event{A: uint32(len(r.RawSample))}, nil
+	}
+	got, err = p.DecodeEvent(raw)
+	require.NoError(t, err)
+	assert.Equal(t, &event{A: 8}, got)
+}
+
+func TestStructFieldConstOptionalFailureMode(t *testing.T) {
+	// The offset is neither known nor found, the process does not exist.
+	info := &process.Info{
+		Modules: map[string]*semver.Version{
+			"example.com/mod": semver.MustParse("1.0.0"),
+		},
+	}
+	c := StructFieldConstOptional{
+		StructField: StructFieldConst{
+			Key: "t_f_pos",
+			ID:  structfield.NewID("example.com/mod", "example.com/mod", "T", "F"),
+		},
+	}
+
+	_, err := c.InjectOption(info)
+	assert.Error(t, err, "FailureModeError")
+
+	c.FailureMode = FailureModeIgnore
+	opt, err := c.InjectOption(info)
+	assert.NoError(t, err, "FailureModeIgnore")
+	assert.Nil(t, opt, "FailureModeIgnore")
+}
+
+func TestStructFieldConstMaxVersion(t *testing.T) {
+	c := StructFieldConstMaxVersion{
+		StructField: StructFieldConst{
+			Key: "t_f_pos",
+			ID:  structfield.NewID("example.com/mod", "example.com/mod", "T", "F"),
+		},
+		MaxVersion: semver.MustParse("1.2.0"),
+		MinVersion: semver.MustParse("1.1.0"),
+	}
+
+	info := func(v string) *process.Info {
+		return &process.Info{
+			Modules: map[string]*semver.Version{
+				"example.com/mod": semver.MustParse(v),
+			},
+		}
+	}
+
+	assert.False(t, c.applies(info("1.0.0")), "less than MinVersion")
+	assert.True(t, c.applies(info("1.1.0")), "MinVersion")
+	assert.True(t, c.applies(info("1.1.1")), "in range")
+	assert.False(t, c.applies(info("1.2.0")), "MaxVersion")
+
+	// No offset is injected, nor error returned, for versions out of range.
+	opt, err := c.InjectOption(info("1.0.0"))
+	assert.NoError(t, err)
+	assert.Nil(t, opt)
+
+	c.MinVersion = nil
+	assert.True(t, c.applies(info("1.0.0")), "no MinVersion")
+
+	_, err = c.InjectOption(&process.Info{})
+	assert.Error(t, err, "unknown module version")
+}
+
+func TestSymbolAddr(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	f, err := elf.Open(exe)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	assert.Equal(t, uint64(0), symbolAddr(f, "not.a.symbol"))
+	if _, err := f.Symbols(); errors.Is(err, elf.ErrNoSymbols) {
+		t.Skip("test binary built without a symbol table")
+	}
+	if f.Type != elf.ET_EXEC {
+		assert.Equal(t, uint64(0), symbolAddr(f, "runtime.types"), "position independent")
+		return
+	}
+	assert.NotEqual(t, uint64(0), symbolAddr(f, "runtime.types"))
+}
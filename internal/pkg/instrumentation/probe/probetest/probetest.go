@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package probetest provides utilities to test the conversion of probe events
+// to spans.
+package probetest
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/context"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/kernel"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+)
+
+// Fixture is the timing and span context of an event, and of the span
+// expected from it.
+type Fixture struct {
+	// Kind is the kind of the expected span.
+	Kind ptrace.SpanKind
+
+	StartOffset, EndOffset uint64
+
+	TraceID trace.TraceID
+	SpanID  trace.SpanID
+	// ParentSpanID is the span ID of the parent span. The span has no parent
+	// if it is not valid.
+	ParentSpanID trace.SpanID
+}
+
+// NewFixture returns a Fixture of a one second long span of kind started now,
+// without a parent.
+func NewFixture(kind ptrace.SpanKind) Fixture {
+	start := time.Unix(0, time.Now().UnixNano()) // No wall clock.
+	end := start.Add(1 * time.Second)
+
+	return Fixture{
+		Kind:        kind,
+		StartOffset: kernel.TimeToBootOffset(start),
+		EndOffset:   kernel.TimeToBootOffset(end),
+		TraceID:     trace.TraceID{1},
+		SpanID:      trace.SpanID{1},
+	}
+}
+
+// BaseSpanProperties returns the base properties of an event of f.
+func (f Fixture) BaseSpanProperties() context.BaseSpanProperties {
+	p := context.BaseSpanProperties{
+		StartTime:   f.StartOffset,
+		EndTime:     f.EndOffset,
+		SpanContext: context.EBPFSpanContext{TraceID: f.TraceID, SpanID: f.SpanID},
+	}
+	if f.ParentSpanID.IsValid() {
+		p.ParentSpanContext = context.EBPFSpanContext{TraceID: f.TraceID, SpanID: f.ParentSpanID}
+	}
+	return p
+}
+
+// Spans returns the spans holding the sampled span of f named name, with the
+// status code and attrs.
+func (f Fixture) Spans(name string, code ptrace.StatusCode, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName(name)
+	span.SetKind(f.Kind)
+	span.SetStartTimestamp(kernel.BootOffsetToTimestamp(f.StartOffset))
+	span.SetEndTimestamp(kernel.BootOffsetToTimestamp(f.EndOffset))
+	span.SetTraceID(pcommon.TraceID(f.TraceID))
+	span.SetSpanID(pcommon.SpanID(f.SpanID))
+	if f.ParentSpanID.IsValid() {
+		span.SetParentSpanID(pcommon.SpanID(f.ParentSpanID))
+	}
+	span.SetFlags(uint32(trace.FlagsSampled))
+	span.Status().SetCode(code)
+	pdataconv.Attributes(span.Attributes(), attrs...)
+	return spans
+}
+
+// ErrorSpans returns the spans holding the sampled span of f named name, with
+// an error status of msg and attrs.
+func (f Fixture) ErrorSpans(name, msg string, attrs ...attribute.KeyValue) ptrace.SpanSlice {
+	spans := f.Spans(name, ptrace.StatusCodeError, attrs...)
+	spans.At(0).Status().SetMessage(msg)
+	return spans
+}
@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+// ErrNoDWARF is returned by [Base.ValidateOffsets] if the executable of the
+// target process has no DWARF debugging data to validate the offsets with.
+var ErrNoDWARF = errors.New("no DWARF data")
+
+// stringSize is the size of a Go string header on the supported 64-bit
+// architectures: a data pointer and a length.
+const stringSize = 16
+
+// ValidateOffsets validates the struct field offsets injected into the eBPF
+// programs of the probe for the target process described by info against the
+// DWARF debugging data of its executable.
+//
+// An offset is invalid if it differs from the offset of the field in the
+// DWARF data, or the field does not fit in its struct. The offsets of string
+// fields, which the eBPF programs read a data pointer and a length from, must
+// also be pointer aligned. An error describing the invalid offsets is
+// returned, or one wrapping [ErrNoDWARF] if the executable has no DWARF data.
+func (i *Base[BPFObj, BPFEvent]) ValidateOffsets(info *process.Info) error {
+	fields := i.structFields(info)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	f, err := elf.Open(info.ID.ExePath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := f.DWARF()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNoDWARF, err)
+	}
+	return validateOffsets(data, info, fields)
+}
+
+// structFields returns the struct field constants of the probe injected for
+// the target process described by info.
+func (i *Base[BPFObj, BPFEvent]) structFields(info *process.Info) []StructFieldConst {
+	var out []StructFieldConst
+	for _, cnst := range i.Consts {
+		switch c := cnst.(type) {
+		case StructFieldConst:
+			out = append(out, c)
+		case StructFieldConstMinVersion:
+			if c.applies(info) {
+				out = append(out, c.StructField)
+			}
+		case StructFieldConstMaxVersion:
+			if c.applies(info) {
+				out = append(out, c.StructField)
+			}
+		case StructFieldConstOptional:
+			if c.applies(info) {
+				out = append(out, c.StructField)
+			}
+		}
+	}
+	return out
+}
+
+func validateOffsets(data *dwarf.Data, info *process.Info, fields []StructFieldConst) error {
+	types, err := structTypes(data, fields)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, c := range fields {
+		off, err := c.offset(info)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		st, ok := types[structName(c.ID)]
+		if !ok {
+			errs = errors.Join(errs, fmt.Errorf("%s: struct not found", c.ID))
+			continue
+		}
+		errs = errors.Join(errs, validateOffset(st, c.ID, off))
+	}
+	return errs
+}
+
+// validateOffset returns an error if off is not a plausible offset of the
+// field identified by id in st.
+func validateOffset(st *dwarf.StructType, id structfield.ID, off uint64) error {
+	for _, f := range st.Field {
+		if f.Name != id.Field {
+			continue
+		}
+		if f.ByteOffset < 0 || uint64(f.ByteOffset) != off {
+			return fmt.Errorf("%s: offset %d, found %d", id, off, f.ByteOffset)
+		}
+		size := f.Type.Size()
+		if size < 0 || st.ByteSize < 0 || off+uint64(size) > uint64(st.ByteSize) {
+			return fmt.Errorf("%s: field of size %d at offset %d exceeds struct of size %d", id, size, off, st.ByteSize)
+		}
+		if f.Type.Common().Name == "string" && (size != stringSize || off%8 != 0) {
+			return fmt.Errorf("%s: invalid string field of size %d at offset %d", id, size, off)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s: field not found", id)
+}
+
+func structName(id structfield.ID) string {
+	return process.LinkerPkgPath(id.PkgPath) + "." + id.Struct
+}
+
+// structTypes returns the types of the structs of fields found in data, keyed
+// by their name.
+func structTypes(data *dwarf.Data, fields []StructFieldConst) (map[string]*dwarf.StructType, error) {
+	want := make(map[string]bool, len(fields))
+	for _, c := range fields {
+		want[structName(c.ID)] = true
+	}
+
+	out := make(map[string]*dwarf.StructType, len(want))
+	r := data.Reader()
+	for len(out) < len(want) {
+		e, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag != dwarf.TagStructType {
+			continue
+		}
+		name, _ := e.Val(dwarf.AttrName).(string)
+		if !want[name] || out[name] != nil {
+			continue
+		}
+		t, err := data.Type(e.Offset)
+		if err != nil {
+			return nil, err
+		}
+		if st, ok := t.(*dwarf.StructType); ok {
+			out[name] = st
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	"debug/dwarf"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/auto/internal/pkg/process"
+	"go.opentelemetry.io/auto/internal/pkg/structfield"
+)
+
+func TestValidateOffset(t *testing.T) {
+	str := &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: 16, Name: "string"},
+		StructName: "string",
+		Kind:       "struct",
+	}
+	i64 := &dwarf.IntType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 8, Name: "int64"}}}
+	st := &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: 32, Name: "net/http.Request"},
+		StructName: "net/http.Request",
+		Kind:       "struct",
+		Field: []*dwarf.StructField{
+			{Name: "Method", Type: str, ByteOffset: 0},
+			{Name: "ContentLength", Type: i64, ByteOffset: 16},
+			{Name: "Host", Type: str, ByteOffset: 20},
+		},
+	}
+	id := func(field string) structfield.ID {
+		return structfield.NewID("std", "net/http", "Request", field)
+	}
+
+	assert.NoError(t, validateOffset(st, id("Method"), 0))
+	assert.NoError(t, validateOffset(st, id("ContentLength"), 16))
+
+	assert.ErrorContains(t, validateOffset(st, id("Method"), 8), "offset 8, found 0")
+	assert.ErrorContains(t, validateOffset(st, id("Host"), 20), "exceeds struct")
+	assert.ErrorContains(t, validateOffset(st, id("Proto"), 0), "field not found")
+
+	st.ByteSize = 40
+	assert.ErrorContains(t, validateOffset(st, id("Host"), 20), "invalid string field")
+}
+
+func TestStructFields(t *testing.T) {
+	sf := func(field string) StructFieldConst {
+		return StructFieldConst{ID: structfield.NewID("std", "net/http", "Request", field)}
+	}
+	b := &Base[struct{}, struct{}]{
+		Consts: []Const{
+			sf("Method"),
+			StructFieldConstMinVersion{StructField: sf("Pattern"), MinVersion: semver.MustParse("1.23.0")},
+			StructFieldConstMaxVersion{StructField: sf("Old"), MaxVersion: semver.MustParse("1.23.0")},
+			StructFieldConstOptional{StructField: sf("Proto")},
+			StructFieldConstOptional{StructField: sf("Host"), MinVersion: semver.MustParse("1.25.0")},
+			StructFieldConstOptional{StructField: StructFieldConst{ID: structfield.NewID("example.com/mod", "example.com/mod", "T", "F")}},
+			KeyValConst{Key: "key", Val: true},
+		},
+	}
+	info := &process.Info{Modules: map[string]*semver.Version{"std": semver.MustParse("1.24.0")}}
+	assert.Equal(t, []StructFieldConst{sf("Method"), sf("Pattern"), sf("Proto")}, b.structFields(info))
+}
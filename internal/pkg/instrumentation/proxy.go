@@ -0,0 +1,246 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+const (
+	// DefaultProxyHoldTimeout is the default maximum duration a CLIENT span
+	// is held waiting for its local SERVER parent span in proxy mode.
+	DefaultProxyHoldTimeout = 5 * time.Second
+
+	// DefaultProxyMaxHeld is the default maximum number of CLIENT spans held
+	// in proxy mode.
+	DefaultProxyMaxHeld = 4096
+)
+
+// ProxyConfig configures the suppression of CLIENT spans in proxy mode.
+type ProxyConfig struct {
+	// HoldTimeout is the maximum duration a CLIENT span is held waiting for
+	// its parent. If zero, DefaultProxyHoldTimeout is used.
+	HoldTimeout time.Duration
+	// MaxHeld is the maximum number of CLIENT spans held at once. CLIENT
+	// spans received when this limit is reached are not suppressed. If zero,
+	// DefaultProxyMaxHeld is used.
+	MaxHeld int
+}
+
+// WithProxyMode returns a copy of h that suppresses CLIENT spans made by a
+// proxy on behalf of the request it is serving.
+//
+// A CLIENT span is suppressed if it is the only CLIENT span child of a SERVER
+// span handled by h and it does not have an error status. CLIENT spans are
+// held until their parent is handled, or the hold timeout of c is reached, to
+// determine this. All CLIENT spans of a SERVER span with more than one CLIENT
+// span child are passed to the TraceHandler of h so processes where outgoing
+// requests are not 1:1 with incoming requests are not affected.
+//
+// The returned flush function passes the CLIENT spans still held to the
+// TraceHandler of h. It needs to be called once no more spans are handled,
+// before the TraceHandler of h is shut down.
+//
+// If h does not have a TraceHandler, h and a no-op flush function are
+// returned.
+func WithProxyMode(l *slog.Logger, h *pipeline.Handler, c ProxyConfig) (*pipeline.Handler, func()) {
+	if h == nil || h.TraceHandler == nil {
+		return h, func() {}
+	}
+
+	if c.HoldTimeout <= 0 {
+		c.HoldTimeout = DefaultProxyHoldTimeout
+	}
+	if c.MaxHeld <= 0 {
+		c.MaxHeld = DefaultProxyMaxHeld
+	}
+
+	f := &proxyFilter{
+		next:    h.TraceHandler,
+		logger:  l,
+		timeout: c.HoldTimeout,
+		maxHeld: c.MaxHeld,
+		now:     time.Now,
+		held:    make(map[pcommon.SpanID]*heldClients),
+	}
+	return &pipeline.Handler{
+		TraceHandler:  f,
+		MetricHandler: h.MetricHandler,
+		LogHandler:    h.LogHandler,
+	}, f.flush
+}
+
+// heldSpan is a CLIENT span held by a proxyFilter.
+type heldSpan struct {
+	scope pcommon.InstrumentationScope
+	url   string
+	span  ptrace.Span
+}
+
+// heldClients are the CLIENT spans held for a parent span.
+type heldClients struct {
+	traceID pcommon.TraceID
+	expires time.Time
+	spans   []heldSpan
+}
+
+// proxyFilter is a [pipeline.TraceHandler] that suppresses CLIENT spans that
+// are 1:1 with their local SERVER parent span.
+type proxyFilter struct {
+	next   pipeline.TraceHandler
+	logger *slog.Logger
+
+	timeout time.Duration
+	maxHeld int
+	now     func() time.Time
+
+	mu    sync.Mutex
+	held  map[pcommon.SpanID]*heldClients
+	nHeld int
+	timer *time.Timer
+}
+
+var _ pipeline.TraceHandler = (*proxyFilter)(nil)
+
+func (f *proxyFilter) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	var release []heldSpan
+
+	f.mu.Lock()
+	spans.RemoveIf(func(s ptrace.Span) bool {
+		switch s.Kind() {
+		case ptrace.SpanKindClient:
+			return f.hold(scope, url, s)
+		case ptrace.SpanKindServer:
+			release = append(release, f.resolve(s)...)
+		}
+		return false
+	})
+	f.mu.Unlock()
+
+	if spans.Len() > 0 {
+		f.next.HandleTrace(scope, url, spans)
+	}
+	f.emit(release)
+}
+
+// hold holds s until its parent is handled. It returns false if s is not
+// held.
+//
+// The mu lock needs to be held by the caller.
+func (f *proxyFilter) hold(scope pcommon.InstrumentationScope, url string, s ptrace.Span) bool {
+	parent := s.ParentSpanID()
+	if parent.IsEmpty() || f.nHeld >= f.maxHeld {
+		return false
+	}
+
+	h, ok := f.held[parent]
+	if !ok {
+		h = &heldClients{
+			traceID: s.TraceID(),
+			expires: f.now().Add(f.timeout),
+		}
+		f.held[parent] = h
+	} else if h.traceID != s.TraceID() {
+		return false
+	}
+
+	span := ptrace.NewSpan()
+	s.CopyTo(span)
+	h.spans = append(h.spans, heldSpan{scope: scope, url: url, span: span})
+	f.nHeld++
+
+	if f.timer == nil {
+		f.timer = time.AfterFunc(f.timeout, f.expire)
+	}
+	return true
+}
+
+// resolve releases the CLIENT spans held for the SERVER span s. If s has a
+// single CLIENT span child without an error status, it is suppressed and
+// nothing is returned.
+//
+// The mu lock needs to be held by the caller.
+func (f *proxyFilter) resolve(s ptrace.Span) []heldSpan {
+	h, ok := f.held[s.SpanID()]
+	if !ok || h.traceID != s.TraceID() {
+		return nil
+	}
+	delete(f.held, s.SpanID())
+	f.nHeld -= len(h.spans)
+
+	if len(h.spans) == 1 && h.spans[0].span.Status().Code() != ptrace.StatusCodeError {
+		f.logger.Debug(
+			"suppressed proxy client span",
+			"scope", h.spans[0].scope.Name(),
+			"name", h.spans[0].span.Name(),
+			"parent", s.Name(),
+		)
+		return nil
+	}
+	return h.spans
+}
+
+// expire releases all CLIENT spans held longer than the hold timeout.
+func (f *proxyFilter) expire() {
+	var release []heldSpan
+
+	f.mu.Lock()
+	now := f.now()
+	var next time.Time
+	for id, h := range f.held {
+		if !now.Before(h.expires) {
+			release = append(release, h.spans...)
+			f.nHeld -= len(h.spans)
+			delete(f.held, id)
+			continue
+		}
+		if next.IsZero() || h.expires.Before(next) {
+			next = h.expires
+		}
+	}
+	if len(f.held) > 0 {
+		f.timer.Reset(next.Sub(now))
+	} else if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	f.mu.Unlock()
+
+	f.emit(release)
+}
+
+// flush releases all the held CLIENT spans, regardless of their hold timeout.
+func (f *proxyFilter) flush() {
+	var release []heldSpan
+
+	f.mu.Lock()
+	for _, h := range f.held {
+		release = append(release, h.spans...)
+	}
+	clear(f.held)
+	f.nHeld = 0
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	f.mu.Unlock()
+
+	f.emit(release)
+}
+
+// emit passes spans to the next handler.
+func (f *proxyFilter) emit(spans []heldSpan) {
+	for _, h := range spans {
+		out := ptrace.NewSpanSlice()
+		h.span.MoveTo(out.AppendEmpty())
+		f.next.HandleTrace(h.scope, h.url, out)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+type syncRecordingTraceHandler struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (h *syncRecordingTraceHandler) HandleTrace(_ pcommon.InstrumentationScope, _ string, s ptrace.SpanSlice) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.names = append(h.names, spanNames(s)...)
+}
+
+func (h *syncRecordingTraceHandler) spanNames() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.names
+}
+
+type proxySpan struct {
+	name   string
+	kind   ptrace.SpanKind
+	id     byte
+	parent byte
+	err    bool
+}
+
+func handleProxySpans(h *pipeline.Handler, spans ...proxySpan) {
+	for _, ps := range spans {
+		batch := ptrace.NewSpanSlice()
+		s := newValidSpan(batch, ps.name)
+		s.SetKind(ps.kind)
+		s.SetSpanID(pcommon.SpanID{ps.id})
+		if ps.parent != 0 {
+			s.SetParentSpanID(pcommon.SpanID{ps.parent})
+		}
+		if ps.err {
+			s.Status().SetCode(ptrace.StatusCodeError)
+		}
+		h.TraceHandler.HandleTrace(pcommon.NewInstrumentationScope(), "", batch)
+	}
+}
+
+func newProxyFilter(t *testing.T, c ProxyConfig) (*pipeline.Handler, *proxyFilter, *syncRecordingTraceHandler) {
+	t.Helper()
+
+	rec := new(syncRecordingTraceHandler)
+	h, _ := WithProxyMode(slog.Default(), &pipeline.Handler{TraceHandler: rec}, c)
+	f, ok := h.TraceHandler.(*proxyFilter)
+	require.True(t, ok, "not a proxyFilter")
+	return h, f, rec
+}
+
+func TestWithProxyMode(t *testing.T) {
+	t.Run("Suppressed", func(t *testing.T) {
+		h, f, rec := newProxyFilter(t, ProxyConfig{})
+		handleProxySpans(h,
+			proxySpan{name: "client", kind: ptrace.SpanKindClient, id: 2, parent: 1},
+			proxySpan{name: "server", kind: ptrace.SpanKindServer, id: 1},
+		)
+		assert.Equal(t, []string{"server"}, rec.spanNames())
+		assert.Empty(t, f.held)
+		assert.Zero(t, f.nHeld)
+	})
+
+	t.Run("MultipleClients", func(t *testing.T) {
+		h, _, rec := newProxyFilter(t, ProxyConfig{})
+		handleProxySpans(h,
+			proxySpan{name: "client0", kind: ptrace.SpanKindClient, id: 2, parent: 1},
+			proxySpan{name: "client1", kind: ptrace.SpanKindClient, id: 3, parent: 1},
+			proxySpan{name: "server", kind: ptrace.SpanKindServer, id: 1},
+		)
+		assert.Equal(t, []string{"server", "client0", "client1"}, rec.spanNames())
+	})
+
+	t.Run("ClientError", func(t *testing.T) {
+		h, _, rec := newProxyFilter(t, ProxyConfig{})
+		handleProxySpans(h,
+			proxySpan{name: "client", kind: ptrace.SpanKindClient, id: 2, parent: 1, err: true},
+			proxySpan{name: "server", kind: ptrace.SpanKindServer, id: 1},
+		)
+		assert.Equal(t, []string{"server", "client"}, rec.spanNames())
+	})
+
+	t.Run("NotHeld", func(t *testing.T) {
+		h, _, rec := newProxyFilter(t, ProxyConfig{})
+		handleProxySpans(h,
+			proxySpan{name: "root client", kind: ptrace.SpanKindClient, id: 2},
+			proxySpan{name: "internal", kind: ptrace.SpanKindInternal, id: 3, parent: 1},
+			proxySpan{name: "server", kind: ptrace.SpanKindServer, id: 1},
+			proxySpan{name: "late client", kind: ptrace.SpanKindClient, id: 4, parent: 5},
+		)
+		assert.Equal(t, []string{"root client", "internal", "server"}, rec.spanNames())
+	})
+
+	t.Run("MaxHeld", func(t *testing.T) {
+		h, _, rec := newProxyFilter(t, ProxyConfig{MaxHeld: 1})
+		handleProxySpans(h,
+			proxySpan{name: "client0", kind: ptrace.SpanKindClient, id: 2, parent: 1},
+			proxySpan{name: "client1", kind: ptrace.SpanKindClient, id: 4, parent: 3},
+		)
+		assert.Equal(t, []string{"client1"}, rec.spanNames())
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		h, f, rec := newProxyFilter(t, ProxyConfig{HoldTimeout: time.Minute})
+		now := time.Now()
+		f.now = func() time.Time { return now }
+
+		handleProxySpans(h, proxySpan{name: "client0", kind: ptrace.SpanKindClient, id: 2, parent: 1})
+		now = now.Add(30 * time.Second)
+		handleProxySpans(h, proxySpan{name: "client1", kind: ptrace.SpanKindClient, id: 4, parent: 3})
+
+		now = now.Add(30 * time.Second)
+		f.expire()
+		assert.Equal(t, []string{"client0"}, rec.spanNames())
+
+		now = now.Add(30 * time.Second)
+		f.expire()
+		assert.Equal(t, []string{"client0", "client1"}, rec.spanNames())
+		assert.Nil(t, f.timer, "timer not stopped")
+
+		handleProxySpans(h, proxySpan{name: "server", kind: ptrace.SpanKindServer, id: 1})
+		assert.Equal(t, []string{"client0", "client1", "server"}, rec.spanNames())
+	})
+
+	t.Run("Timer", func(t *testing.T) {
+		h, _, rec := newProxyFilter(t, ProxyConfig{HoldTimeout: time.Millisecond})
+		handleProxySpans(h, proxySpan{name: "client", kind: ptrace.SpanKindClient, id: 2, parent: 1})
+		assert.Eventually(t, func() bool {
+			return len(rec.spanNames()) == 1
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		rec := new(syncRecordingTraceHandler)
+		h, flush := WithProxyMode(slog.Default(), &pipeline.Handler{TraceHandler: rec}, ProxyConfig{})
+		handleProxySpans(h, proxySpan{name: "client", kind: ptrace.SpanKindClient, id: 2, parent: 1})
+		assert.Empty(t, rec.spanNames())
+
+		flush()
+		assert.Equal(t, []string{"client"}, rec.spanNames())
+		f := h.TraceHandler.(*proxyFilter)
+		assert.Empty(t, f.held)
+		assert.Zero(t, f.nHeld)
+		assert.Nil(t, f.timer, "timer not stopped")
+	})
+
+	t.Run("NoTraceHandler", func(t *testing.T) {
+		h := &pipeline.Handler{}
+		got, flush := WithProxyMode(slog.Default(), h, ProxyConfig{})
+		assert.Same(t, h, got)
+		assert.NotPanics(t, flush)
+	})
+}
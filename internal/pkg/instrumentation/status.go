@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package instrumentation
+
+import (
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+// ProbeState is the state of a probe managed by a [Manager].
+type ProbeState string
+
+const (
+	// ProbeStateDisabled is the state of a probe that is not enabled by the
+	// current configuration.
+	ProbeStateDisabled ProbeState = "disabled"
+	// ProbeStateLoaded is the state of a probe that is attached to the target
+	// process, but does not process events yet.
+	ProbeStateLoaded ProbeState = "loaded"
+	// ProbeStateRunning is the state of a probe that is attached to the
+	// target process and processes events.
+	ProbeStateRunning ProbeState = "running"
+	// ProbeStateFailed is the state of a probe that failed to load.
+	ProbeStateFailed ProbeState = "failed"
+	// ProbeStateSkipped is the state of a probe that is not loaded because
+	// its struct field offsets are not valid for the target process.
+	ProbeStateSkipped ProbeState = "skipped"
+	// ProbeStateClosed is the state of a probe that was stopped.
+	ProbeStateClosed ProbeState = "closed"
+)
+
+// ProbeStatus is the status of a probe managed by a [Manager].
+type ProbeStatus struct {
+	// ID is the identifier of the probe.
+	ID probe.ID
+	// State is the state of the probe.
+	State ProbeState
+	// Err is the error that caused the probe to fail or be skipped, if any.
+	Err error
+	// Stats are the event counters of the probe.
+	Stats probe.Stats
+}
+
+type probeStatus struct {
+	state ProbeState
+	err   error
+}
+
+// statsProbe is a [probe.Probe] that counts its events.
+type statsProbe interface {
+	Stats() probe.Stats
+}
+
+// setProbeState records the state of the probe identified by id.
+func (m *Manager) setProbeState(id probe.ID, state ProbeState, err error) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	if m.probeStatus == nil {
+		m.probeStatus = make(map[probe.ID]probeStatus)
+	}
+	m.probeStatus[id] = probeStatus{state: state, err: err}
+}
+
+// closeProbeState records the probe identified by id as closed, unless it
+// failed to load or was skipped so its error is kept.
+func (m *Manager) closeProbeState(id probe.ID) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	if s, ok := m.probeStatus[id]; ok && (s.state == ProbeStateFailed || s.state == ProbeStateSkipped) {
+		return
+	}
+	if m.probeStatus == nil {
+		m.probeStatus = make(map[probe.ID]probeStatus)
+	}
+	m.probeStatus[id] = probeStatus{state: ProbeStateClosed}
+}
+
+// probeSkipped returns true if the probe identified by id was skipped.
+func (m *Manager) probeSkipped(id probe.ID) bool {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	return m.probeStatus[id].state == ProbeStateSkipped
+}
+
+// ProbeStatus returns the status of all probes managed by m, sorted by ID.
+func (m *Manager) ProbeStatus() []ProbeStatus {
+	m.statusMu.Lock()
+	out := make([]ProbeStatus, 0, len(m.probes))
+	for id, p := range m.probes {
+		ps := ProbeStatus{ID: id, State: ProbeStateDisabled}
+		if s, ok := m.probeStatus[id]; ok {
+			ps.State, ps.Err = s.state, s.err
+		}
+		if sp, ok := p.(statsProbe); ok {
+			ps.Stats = sp.Stats()
+		}
+		out = append(out, ps)
+	}
+	m.statusMu.Unlock()
+
+	slices.SortFunc(out, func(a, b ProbeStatus) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return out
+}
+
+// Config returns the configuration currently applied by m.
+func (m *Manager) Config() Config {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	return m.currentConfig
+}
+
+// setConfig sets the configuration currently applied by m.
+func (m *Manager) setConfig(c Config) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	m.currentConfig = c
+}
+
+// mapsProbe is a [probe.Probe] that reports the fill level of its eBPF maps.
+type mapsProbe interface {
+	MapUsage() []probe.MapUsage
+}
+
+// ProbeMapUsage returns the fill level of the eBPF maps of the loaded and
+// running probes managed by m.
+func (m *Manager) ProbeMapUsage() map[probe.ID][]probe.MapUsage {
+	m.statusMu.Lock()
+	var ids []probe.ID
+	for id, s := range m.probeStatus {
+		if s.state == ProbeStateLoaded || s.state == ProbeStateRunning {
+			ids = append(ids, id)
+		}
+	}
+	m.statusMu.Unlock()
+
+	out := make(map[probe.ID][]probe.MapUsage, len(ids))
+	for _, id := range ids {
+		if mp, ok := m.probes[id].(mapsProbe); ok {
+			out[id] = mp.MapUsage()
+		}
+	}
+	return out
+}
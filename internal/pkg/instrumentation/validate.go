@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+// DefaultMaxSpanDuration is the default maximum duration of a valid span.
+const DefaultMaxSpanDuration = time.Hour
+
+// ValidationPolicy defines how spans that violate a structural invariant are
+// handled.
+type ValidationPolicy uint8
+
+const (
+	// ValidationPolicyDrop drops spans that violate any invariant.
+	ValidationPolicyDrop ValidationPolicy = iota
+	// ValidationPolicyRepair repairs spans that violate invariants where
+	// possible. Spans with a violation that cannot be repaired (i.e. an
+	// invalid trace or span ID, or a duration greater than the maximum) are
+	// dropped.
+	ValidationPolicyRepair
+)
+
+// ValidationConfig configures the validation of spans before they are
+// handled.
+type ValidationConfig struct {
+	// MaxSpanDuration is the maximum duration of a valid span. Spans with a
+	// longer duration are dropped. If zero, DefaultMaxSpanDuration is used.
+	MaxSpanDuration time.Duration
+	// Policy is the policy applied to spans violating an invariant.
+	Policy ValidationPolicy
+}
+
+// violation is a set of structural invariants violated by a span.
+type violation uint8
+
+const (
+	violationTraceID violation = 1 << iota
+	violationSpanID
+	violationName
+	violationNegativeDuration
+	violationDurationExceeded
+	violationString
+
+	// unrepairable are the violations that cannot be repaired.
+	unrepairable = violationTraceID | violationSpanID | violationDurationExceeded
+)
+
+// violations are all violations in the order they are reported.
+var violations = []violation{
+	violationTraceID,
+	violationSpanID,
+	violationName,
+	violationNegativeDuration,
+	violationDurationExceeded,
+	violationString,
+}
+
+func (v violation) String() string {
+	switch v {
+	case violationTraceID:
+		return "invalid_trace_id"
+	case violationSpanID:
+		return "invalid_span_id"
+	case violationName:
+		return "empty_name"
+	case violationNegativeDuration:
+		return "negative_duration"
+	case violationDurationExceeded:
+		return "duration_exceeded"
+	case violationString:
+		return "invalid_string"
+	default:
+		return "unknown"
+	}
+}
+
+// repairedName is the name given to repaired spans with an empty name.
+const repairedName = "unknown"
+
+var (
+	scopeNameKey = attribute.Key("otel.scope.name")
+	violationKey = attribute.Key("violation")
+	actionKey    = attribute.Key("action")
+
+	actionDrop   = actionKey.String("drop")
+	actionRepair = actionKey.String("repair")
+)
+
+// WithValidation returns a copy of h that validates spans before passing them
+// to the TraceHandler of h. Spans violating a structural invariant are dropped
+// or repaired according to the policy of c, and each violation is counted.
+//
+// If h does not have a TraceHandler, h is returned.
+func WithValidation(l *slog.Logger, h *pipeline.Handler, c ValidationConfig) *pipeline.Handler {
+	if h == nil || h.TraceHandler == nil {
+		return h
+	}
+
+	if c.MaxSpanDuration <= 0 {
+		c.MaxSpanDuration = DefaultMaxSpanDuration
+	}
+
+	return &pipeline.Handler{
+		TraceHandler: &validator{
+			next:        h.TraceHandler,
+			logger:      l,
+			maxDuration: c.MaxSpanDuration,
+			policy:      c.Policy,
+			violations: newCounter(
+				h,
+				"otel.auto.span.violations",
+				"{violation}",
+				"Number of span invariant violations found by validation before export.",
+			),
+		},
+		MetricHandler: h.MetricHandler,
+		LogHandler:    h.LogHandler,
+	}
+}
+
+// validator is a [pipeline.TraceHandler] that drops or repairs invalid spans
+// before they are passed to the next handler.
+type validator struct {
+	next   pipeline.TraceHandler
+	logger *slog.Logger
+
+	maxDuration time.Duration
+	policy      ValidationPolicy
+	violations  *counter
+}
+
+var _ pipeline.TraceHandler = (*validator)(nil)
+
+func (v *validator) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	spans.RemoveIf(func(s ptrace.Span) bool {
+		found := v.check(s)
+		if found == 0 {
+			return false
+		}
+
+		drop := v.policy == ValidationPolicyDrop || found&unrepairable != 0
+		v.record(scope, s, found, drop)
+		if drop {
+			return true
+		}
+		repair(s, found)
+		return false
+	})
+
+	if spans.Len() == 0 {
+		return
+	}
+	v.next.HandleTrace(scope, url, spans)
+}
+
+// check returns the invariants violated by s.
+func (v *validator) check(s ptrace.Span) violation {
+	var found violation
+	if s.TraceID().IsEmpty() {
+		found |= violationTraceID
+	}
+	if s.SpanID().IsEmpty() {
+		found |= violationSpanID
+	}
+	if s.Name() == "" {
+		found |= violationName
+	} else if !validString(s.Name()) {
+		found |= violationString
+	}
+
+	start, end := s.StartTimestamp(), s.EndTimestamp()
+	if end < start {
+		found |= violationNegativeDuration
+	} else if time.Duration(end-start) > v.maxDuration { // nolint: gosec  // end >= start.
+		found |= violationDurationExceeded
+	}
+
+	if found&violationString == 0 {
+		s.Attributes().Range(func(_ string, val pcommon.Value) bool {
+			if val.Type() == pcommon.ValueTypeStr && !validString(val.Str()) {
+				found |= violationString
+				return false
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func (v *validator) record(scope pcommon.InstrumentationScope, s ptrace.Span, found violation, drop bool) {
+	action := actionRepair
+	if drop {
+		action = actionDrop
+	}
+
+	for _, viol := range violations {
+		if found&viol == 0 {
+			continue
+		}
+		v.logger.Debug(
+			"invalid span",
+			"scope", scope.Name(),
+			"name", s.Name(),
+			"violation", viol,
+			"action", action.Value.AsString(),
+		)
+		v.violations.Add(
+			1,
+			scopeNameKey.String(scope.Name()),
+			violationKey.String(viol.String()),
+			action,
+		)
+	}
+}
+
+// repair fixes all repairable violations found in s.
+func repair(s ptrace.Span, found violation) {
+	if found&violationNegativeDuration != 0 {
+		s.SetEndTimestamp(s.StartTimestamp())
+	}
+	if found&violationString != 0 {
+		s.SetName(cleanString(s.Name()))
+		s.Attributes().Range(func(_ string, val pcommon.Value) bool {
+			if val.Type() == pcommon.ValueTypeStr {
+				val.SetStr(cleanString(val.Str()))
+			}
+			return true
+		})
+	}
+	// The name is checked after being cleaned: it is empty if it only
+	// contained NUL bytes.
+	if s.Name() == "" {
+		s.SetName(repairedName)
+	}
+}
+
+// validString returns true if str is valid UTF-8 and does not contain NUL
+// bytes.
+func validString(str string) bool {
+	return utf8.ValidString(str) && strings.IndexByte(str, 0) < 0
+}
+
+// cleanString returns str with all NUL bytes removed and invalid UTF-8 byte
+// sequences replaced with the Unicode replacement character.
+func cleanString(str string) string {
+	if validString(str) {
+		return str
+	}
+	str = strings.ReplaceAll(str, "\x00", "")
+	return strings.ToValidUTF8(str, string(utf8.RuneError))
+}
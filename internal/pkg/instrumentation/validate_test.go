@@ -0,0 +1,208 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+type recordingTraceHandler struct {
+	spans []ptrace.SpanSlice
+}
+
+func (h *recordingTraceHandler) HandleTrace(_ pcommon.InstrumentationScope, _ string, s ptrace.SpanSlice) {
+	h.spans = append(h.spans, s)
+}
+
+var (
+	validStart   = time.Unix(1000, 0)
+	validTraceID = pcommon.TraceID{0x1}
+	validSpanID  = pcommon.SpanID{0x1}
+)
+
+func newValidSpan(spans ptrace.SpanSlice, name string) ptrace.Span {
+	s := spans.AppendEmpty()
+	s.SetName(name)
+	s.SetTraceID(validTraceID)
+	s.SetSpanID(validSpanID)
+	s.SetStartTimestamp(pcommon.NewTimestampFromTime(validStart))
+	s.SetEndTimestamp(pcommon.NewTimestampFromTime(validStart.Add(time.Second)))
+	return s
+}
+
+func spanNames(spans ptrace.SpanSlice) []string {
+	var out []string
+	for i := 0; i < spans.Len(); i++ {
+		out = append(out, spans.At(i).Name())
+	}
+	return out
+}
+
+func validate(t *testing.T, c ValidationConfig, spans ptrace.SpanSlice) ptrace.SpanSlice {
+	t.Helper()
+
+	rec := new(recordingTraceHandler)
+	h := WithValidation(slog.Default(), &pipeline.Handler{TraceHandler: rec}, c)
+	h.TraceHandler.HandleTrace(pcommon.NewInstrumentationScope(), "", spans)
+
+	if len(rec.spans) == 0 {
+		return ptrace.NewSpanSlice()
+	}
+	require.Len(t, rec.spans, 1)
+	return rec.spans[0]
+}
+
+func TestWithValidationDuration(t *testing.T) {
+	newSpans := func(durations ...time.Duration) ptrace.SpanSlice {
+		spans := ptrace.NewSpanSlice()
+		for _, d := range durations {
+			s := newValidSpan(spans, d.String())
+			s.SetEndTimestamp(pcommon.NewTimestampFromTime(validStart.Add(d)))
+		}
+		return spans
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		got := validate(t, ValidationConfig{}, newSpans(
+			0, time.Second, -time.Second, DefaultMaxSpanDuration, DefaultMaxSpanDuration+1,
+		))
+		assert.Equal(t, []string{"0s", "1s", "1h0m0s"}, spanNames(got))
+	})
+
+	t.Run("MaxSpanDuration", func(t *testing.T) {
+		got := validate(t, ValidationConfig{MaxSpanDuration: time.Minute}, newSpans(
+			time.Second, 2*time.Minute,
+		))
+		assert.Equal(t, []string{"1s"}, spanNames(got))
+	})
+
+	t.Run("AllDropped", func(t *testing.T) {
+		rec := new(recordingTraceHandler)
+		h := WithValidation(slog.Default(), &pipeline.Handler{TraceHandler: rec}, ValidationConfig{})
+
+		h.TraceHandler.HandleTrace(pcommon.NewInstrumentationScope(), "", newSpans(-time.Nanosecond))
+		assert.Empty(t, rec.spans, "empty span slice handled")
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		got := validate(t, ValidationConfig{Policy: ValidationPolicyRepair}, newSpans(
+			-time.Second, DefaultMaxSpanDuration+1,
+		))
+		require.Equal(t, 1, got.Len())
+		assert.Equal(t, got.At(0).StartTimestamp(), got.At(0).EndTimestamp())
+	})
+
+	t.Run("NoTraceHandler", func(t *testing.T) {
+		h := &pipeline.Handler{}
+		assert.Same(t, h, WithValidation(slog.Default(), h, ValidationConfig{}))
+	})
+}
+
+func TestWithValidationInvariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(ptrace.Span)
+		// repaired is the name of the repaired span. If empty, the span is
+		// expected to be dropped by ValidationPolicyRepair.
+		repaired string
+		check    func(*testing.T, ptrace.Span)
+	}{
+		{
+			name:   "TraceID",
+			modify: func(s ptrace.Span) { s.SetTraceID(pcommon.NewTraceIDEmpty()) },
+		},
+		{
+			name:   "SpanID",
+			modify: func(s ptrace.Span) { s.SetSpanID(pcommon.NewSpanIDEmpty()) },
+		},
+		{
+			name:     "EmptyName",
+			modify:   func(s ptrace.Span) { s.SetName("") },
+			repaired: repairedName,
+		},
+		{
+			name:     "NameNUL",
+			modify:   func(s ptrace.Span) { s.SetName("GET\x00\x00") },
+			repaired: "GET",
+		},
+		{
+			name:     "NameOnlyNUL",
+			modify:   func(s ptrace.Span) { s.SetName("\x00\x00") },
+			repaired: repairedName,
+		},
+		{
+			name: "AttributeNUL",
+			modify: func(s ptrace.Span) {
+				s.Attributes().PutStr("db.query.text", "SELECT 1\x00\x00\x00")
+			},
+			repaired: "AttributeNUL",
+			check: func(t *testing.T, s ptrace.Span) {
+				v, ok := s.Attributes().Get("db.query.text")
+				require.True(t, ok)
+				assert.Equal(t, "SELECT 1", v.Str())
+			},
+		},
+		{
+			name: "AttributeUTF8",
+			modify: func(s ptrace.Span) {
+				s.Attributes().PutStr("url.path", "/a\xffb")
+				s.Attributes().PutInt("http.response.status_code", 200)
+			},
+			repaired: "AttributeUTF8",
+			check: func(t *testing.T, s ptrace.Span) {
+				v, ok := s.Attributes().Get("url.path")
+				require.True(t, ok)
+				assert.Equal(t, "/a�b", v.Str())
+
+				v, ok = s.Attributes().Get("http.response.status_code")
+				require.True(t, ok)
+				assert.Equal(t, int64(200), v.Int())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newSpans := func() ptrace.SpanSlice {
+				spans := ptrace.NewSpanSlice()
+				newValidSpan(spans, "valid")
+				test.modify(newValidSpan(spans, test.name))
+				return spans
+			}
+
+			t.Run("Drop", func(t *testing.T) {
+				got := validate(t, ValidationConfig{Policy: ValidationPolicyDrop}, newSpans())
+				assert.Equal(t, []string{"valid"}, spanNames(got))
+			})
+
+			t.Run("Repair", func(t *testing.T) {
+				got := validate(t, ValidationConfig{Policy: ValidationPolicyRepair}, newSpans())
+				if test.repaired == "" {
+					assert.Equal(t, []string{"valid"}, spanNames(got))
+					return
+				}
+
+				assert.Equal(t, []string{"valid", test.repaired}, spanNames(got))
+				if test.check != nil {
+					test.check(t, got.At(1))
+				}
+			})
+		})
+	}
+}
+
+func TestCleanString(t *testing.T) {
+	assert.Equal(t, "valid", cleanString("valid"))
+	assert.Equal(t, "nul", cleanString("n\x00u\x00l\x00"))
+	assert.Equal(t, "a�b", cleanString("a\xff\xfeb"))
+}
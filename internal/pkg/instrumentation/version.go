@@ -5,9 +5,61 @@
 // using eBPF for Go programs.
 package instrumentation
 
+import (
+	"runtime/debug"
+	"sync"
+)
+
 const (
 	// Name is used for `telemetry.distro.name` resource attribute.
 	Name = "opentelemetry-go-instrumentation"
 	// Version is the current release version of OpenTelemetry Go auto-instrumentation in use.
 	Version = "v0.22.1"
+
+	// modulePath is the path of the module of the auto-instrumentation.
+	modulePath = "go.opentelemetry.io/auto"
 )
+
+// buildVersion is the version of the auto-instrumentation set at build time
+// with the linker, e.g.:
+//
+//	go build -ldflags "-X go.opentelemetry.io/auto/internal/pkg/instrumentation.buildVersion=v0.22.1-acme.1" ./cli
+var buildVersion string
+
+// BuildVersion returns the version of the auto-instrumentation in use: the
+// version set at build time if any, otherwise the version of the
+// go.opentelemetry.io/auto module recorded in the build information of the
+// binary, otherwise Version.
+var BuildVersion = sync.OnceValue(func() string {
+	bi, _ := debug.ReadBuildInfo()
+	return resolveVersion(buildVersion, bi)
+})
+
+func resolveVersion(linked string, bi *debug.BuildInfo) string {
+	if linked != "" {
+		return linked
+	}
+	if bi == nil {
+		return Version
+	}
+
+	mod := &bi.Main
+	if mod.Path != modulePath {
+		mod = nil
+		for _, dep := range bi.Deps {
+			if dep.Path == modulePath {
+				mod = dep
+				break
+			}
+		}
+	}
+	if mod != nil && mod.Replace != nil {
+		mod = mod.Replace
+	}
+	// Modules built from a local directory, e.g. tests or replaced modules,
+	// have no version.
+	if mod == nil || mod.Version == "" || mod.Version == "(devel)" {
+		return Version
+	}
+	return mod.Version
+}
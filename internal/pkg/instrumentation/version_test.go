@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package instrumentation
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVersion(t *testing.T) {
+	main := func(v string) *debug.BuildInfo {
+		return &debug.BuildInfo{Main: debug.Module{Path: modulePath, Version: v}}
+	}
+	dep := func(mod *debug.Module) *debug.BuildInfo {
+		return &debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app", Version: "v1.0.0"},
+			Deps: []*debug.Module{{Path: "example.com/lib", Version: "v2.0.0"}, mod},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		linked string
+		bi     *debug.BuildInfo
+		want   string
+	}{
+		{name: "Linked", linked: "v0.22.1-acme.1", bi: main("v0.23.0"), want: "v0.22.1-acme.1"},
+		{name: "NoBuildInfo", want: Version},
+		{name: "Main", bi: main("v0.23.0"), want: "v0.23.0"},
+		{name: "MainPseudo", bi: main("v0.22.2-0.20251015090000-0123456789ab+dirty"), want: "v0.22.2-0.20251015090000-0123456789ab+dirty"},
+		{name: "MainDevel", bi: main("(devel)"), want: Version},
+		{name: "Dependency", bi: dep(&debug.Module{Path: modulePath, Version: "v0.23.0"}), want: "v0.23.0"},
+		{
+			name: "Replaced",
+			bi: dep(&debug.Module{
+				Path:    modulePath,
+				Version: "v0.23.0",
+				Replace: &debug.Module{Path: "example.com/fork/auto", Version: "v0.23.0-fork.1"},
+			}),
+			want: "v0.23.0-fork.1",
+		},
+		{
+			name: "ReplacedLocal",
+			bi: dep(&debug.Module{
+				Path:    modulePath,
+				Version: "v0.23.0",
+				Replace: &debug.Module{Path: "../auto"},
+			}),
+			want: Version,
+		},
+		{name: "NotFound", bi: dep(&debug.Module{Path: "example.com/other", Version: "v1.0.0"}), want: Version},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveVersion(tt.linked, tt.bi))
+		})
+	}
+}
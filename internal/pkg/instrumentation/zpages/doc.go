@@ -0,0 +1,6 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package zpages provides a local HTTP endpoint with pages showing the
+// recent spans produced by the instrumentation and the state of its probes.
+package zpages
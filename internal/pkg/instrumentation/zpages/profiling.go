@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package zpages
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+// VarsSource provides the probe internals reported by the profiling handler.
+type VarsSource interface {
+	// ProbeStatus returns the status of all probes.
+	ProbeStatus() []instrumentation.ProbeStatus
+	// ProbeMapUsage returns the fill level of the eBPF maps of the loaded
+	// probes.
+	ProbeMapUsage() map[probe.ID][]probe.MapUsage
+}
+
+// maxProfileDuration is the maximum duration of a CPU profile or execution
+// trace.
+const maxProfileDuration = 5 * time.Minute
+
+// NewProfilingHandler returns an [http.Handler] serving the runtime profiles
+// of the agent in the format expected by "go tool pprof", and its internals as
+// JSON:
+//
+//   - /debug/pprof/: index of the profiles
+//   - /debug/pprof/profile: CPU profile (seconds query parameter, default 30)
+//   - /debug/pprof/trace: execution trace (seconds query parameter, default 1)
+//   - /debug/pprof/{name}: runtime profiles (e.g. heap, goroutine, allocs)
+//   - /debug/vars: goroutine count, memory statistics, probe event counters
+//     and eBPF map fill levels, the spans recorded by rec, and the spans
+//     rejected by the collector as reported by exp
+//
+// The rec and exp parameters may be nil.
+//
+// The net/http/pprof package is not used as it registers its handlers with
+// [http.DefaultServeMux] when imported.
+func NewProfilingHandler(src VarsSource, rec *Recorder, exp ExporterSource) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/{$}", pprofIndex)
+	mux.HandleFunc("/debug/pprof/profile", pprofCPU)
+	mux.HandleFunc("/debug/pprof/trace", pprofTrace)
+	mux.HandleFunc("/debug/pprof/{name}", pprofLookup)
+	mux.Handle("/debug/vars", &vars{src: src, rec: rec, exp: exp})
+	return mux
+}
+
+func pprofIndex(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "profile")
+	fmt.Fprintln(w, "trace")
+	for _, p := range pprof.Profiles() {
+		fmt.Fprintf(w, "%s (%d)\n", p.Name(), p.Count())
+	}
+}
+
+// seconds returns the value of the seconds query parameter of r, or def if
+// not set.
+func seconds(r *http.Request, def time.Duration) (time.Duration, error) {
+	v := r.URL.Query().Get("seconds")
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid seconds: %q", v)
+	}
+	d := time.Duration(n) * time.Second
+	if d > maxProfileDuration {
+		return 0, fmt.Errorf("seconds exceeds maximum of %s", maxProfileDuration)
+	}
+	return d, nil
+}
+
+// sleep waits for d, or until the request r is canceled.
+func sleep(r *http.Request, d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-r.Context().Done():
+	}
+}
+
+func pprofCPU(w http.ResponseWriter, r *http.Request) {
+	d, err := seconds(r, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		http.Error(w, "start CPU profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sleep(r, d)
+	pprof.StopCPUProfile()
+}
+
+func pprofTrace(w http.ResponseWriter, r *http.Request) {
+	d, err := seconds(r, time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		http.Error(w, "start trace: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sleep(r, d)
+	trace.Stop()
+}
+
+func pprofLookup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.Error(w, "unknown profile: "+name, http.StatusNotFound)
+		return
+	}
+
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if name == "heap" && r.URL.Query().Get("gc") != "" {
+		runtime.GC()
+	}
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	_ = p.WriteTo(w, debug)
+}
+
+type vars struct {
+	src VarsSource
+	rec *Recorder
+	exp ExporterSource
+}
+
+type varsMemory struct {
+	HeapAlloc    uint64 `json:"heap_alloc"`
+	HeapInuse    uint64 `json:"heap_inuse"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	StackInuse   uint64 `json:"stack_inuse"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"gc_pause_total_ns"`
+}
+
+type varsMap struct {
+	Name       string `json:"name"`
+	Entries    uint32 `json:"entries"`
+	MaxEntries uint32 `json:"max_entries"`
+}
+
+type varsProbe struct {
+	ID     string    `json:"id"`
+	State  string    `json:"state"`
+	Error  string    `json:"error,omitempty"`
+	Events uint64    `json:"events"`
+	Lost   uint64    `json:"lost_events"`
+	Maps   []varsMap `json:"maps,omitempty"`
+}
+
+type varsExporter struct {
+	RejectedSpans     int64      `json:"rejected_spans"`
+	LastRejection     string     `json:"last_rejection,omitempty"`
+	LastRejectionTime *time.Time `json:"last_rejection_time,omitempty"`
+}
+
+type varsData struct {
+	Goroutines    int               `json:"goroutines"`
+	Memory        varsMemory        `json:"memory"`
+	Probes        []varsProbe       `json:"probes"`
+	RecordedSpans map[string]uint64 `json:"recorded_spans,omitempty"`
+	Exporter      *varsExporter     `json:"exporter,omitempty"`
+}
+
+func (v *vars) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	data := varsData{
+		Goroutines: runtime.NumGoroutine(),
+		Memory: varsMemory{
+			HeapAlloc:    ms.HeapAlloc,
+			HeapInuse:    ms.HeapInuse,
+			HeapObjects:  ms.HeapObjects,
+			StackInuse:   ms.StackInuse,
+			Sys:          ms.Sys,
+			NumGC:        ms.NumGC,
+			PauseTotalNs: ms.PauseTotalNs,
+		},
+	}
+
+	usage := v.src.ProbeMapUsage()
+	for _, s := range v.src.ProbeStatus() {
+		p := varsProbe{
+			ID:     s.ID.String(),
+			State:  string(s.State),
+			Events: s.Stats.Events,
+			Lost:   s.Stats.Lost,
+		}
+		if s.Err != nil {
+			p.Error = s.Err.Error()
+		}
+		for _, m := range usage[s.ID] {
+			p.Maps = append(p.Maps, varsMap(m))
+		}
+		data.Probes = append(data.Probes, p)
+	}
+	if v.rec != nil {
+		data.RecordedSpans = v.rec.Counts()
+	}
+	if v.exp != nil {
+		es := v.exp.ExportStatus()
+		data.Exporter = &varsExporter{RejectedSpans: es.RejectedSpans, LastRejection: es.LastRejection}
+		if !es.LastRejectionTime.IsZero() {
+			data.Exporter.LastRejectionTime = &es.LastRejectionTime
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(data)
+}
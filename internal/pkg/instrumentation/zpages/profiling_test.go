@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package zpages
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+)
+
+func TestProfilingHandlerPprof(t *testing.T) {
+	h := NewProfilingHandler(source{}, nil, nil)
+
+	assert.Contains(t, get(t, h, "/debug/pprof/"), "heap")
+	assert.NotEmpty(t, get(t, h, "/debug/pprof/heap"))
+	assert.Contains(t, get(t, h, "/debug/pprof/goroutine?debug=1"), "goroutine profile")
+
+	for path, code := range map[string]int{
+		"/debug/pprof/unknown":            http.StatusNotFound,
+		"/debug/pprof/profile?seconds=0":  http.StatusBadRequest,
+		"/debug/pprof/trace?seconds=1000": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		assert.Equal(t, code, rec.Code, path)
+	}
+}
+
+func TestProfilingHandlerVars(t *testing.T) {
+	id := probe.ID{InstrumentedPkg: "net/http", SpanKind: trace.SpanKindServer}
+	src := source{
+		status: []instrumentation.ProbeStatus{{
+			ID:    id,
+			State: instrumentation.ProbeStateRunning,
+			Stats: probe.Stats{Events: 10, Lost: 3},
+		}},
+		maps: map[probe.ID][]probe.MapUsage{
+			id: {{Name: "http_server_uprobes", Entries: 2, MaxEntries: 1000}},
+		},
+	}
+	rec := NewRecorder(new(countingHandler), 1)
+	rec.HandleTrace(scope("scope"), "", spans("span"))
+
+	exp := exporter{RejectedSpans: 2, LastRejection: "invalid span"}
+
+	var got varsData
+	require.NoError(t, json.Unmarshal([]byte(get(t, NewProfilingHandler(src, rec, exp), "/debug/vars")), &got))
+
+	assert.Positive(t, got.Goroutines)
+	assert.Positive(t, got.Memory.Sys)
+	assert.Equal(t, []varsProbe{{
+		ID:     id.String(),
+		State:  "running",
+		Events: 10,
+		Lost:   3,
+		Maps:   []varsMap{{Name: "http_server_uprobes", Entries: 2, MaxEntries: 1000}},
+	}}, got.Probes)
+	assert.Equal(t, map[string]uint64{"scope": 1}, got.RecordedSpans)
+	assert.Equal(t, &varsExporter{RejectedSpans: 2, LastRejection: "invalid span"}, got.Exporter)
+}
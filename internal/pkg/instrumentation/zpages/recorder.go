@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package zpages
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"go.opentelemetry.io/auto/pipeline"
+)
+
+const (
+	// DefaultSpansPerScope is the default number of recent spans recorded for
+	// each instrumentation scope.
+	DefaultSpansPerScope = 32
+
+	// maxScopes is the maximum number of instrumentation scopes spans are
+	// recorded for. Spans of the auto/sdk and OpenTelemetry API probes use the
+	// scopes of the instrumented application which are not bounded.
+	maxScopes = 64
+	// maxAttrs is the maximum number of attributes recorded for a span.
+	maxAttrs = 8
+	// maxValueLen is the maximum length of a recorded attribute value.
+	maxValueLen = 128
+)
+
+// SpanSummary is a summary of a span recorded by a [Recorder].
+type SpanSummary struct {
+	Name     string
+	Kind     ptrace.SpanKind
+	TraceID  pcommon.TraceID
+	SpanID   pcommon.SpanID
+	Start    time.Time
+	Duration time.Duration
+	Status   ptrace.StatusCode
+	// Attrs are the first attributes of the span, with their values
+	// truncated.
+	Attrs []Attr
+}
+
+// Attr is an attribute of a [SpanSummary].
+type Attr struct {
+	Key   string
+	Value string
+}
+
+func newSpanSummary(s ptrace.Span) SpanSummary {
+	out := SpanSummary{
+		Name:     s.Name(),
+		Kind:     s.Kind(),
+		TraceID:  s.TraceID(),
+		SpanID:   s.SpanID(),
+		Start:    s.StartTimestamp().AsTime(),
+		Duration: s.EndTimestamp().AsTime().Sub(s.StartTimestamp().AsTime()),
+		Status:   s.Status().Code(),
+	}
+	s.Attributes().Range(func(k string, v pcommon.Value) bool {
+		val := v.AsString()
+		if len(val) > maxValueLen {
+			val = val[:maxValueLen] + "…"
+		}
+		out.Attrs = append(out.Attrs, Attr{Key: k, Value: val})
+		return len(out.Attrs) < maxAttrs
+	})
+	return out
+}
+
+// ring is a fixed size ring buffer of span summaries.
+type ring struct {
+	spans []SpanSummary
+	next  int
+	total uint64
+}
+
+func (r *ring) add(s SpanSummary) {
+	if len(r.spans) < cap(r.spans) {
+		r.spans = append(r.spans, s)
+	} else {
+		r.spans[r.next] = s
+	}
+	r.next = (r.next + 1) % cap(r.spans)
+	r.total++
+}
+
+// recent returns the spans of r, newest first.
+func (r *ring) recent() []SpanSummary {
+	out := make([]SpanSummary, 0, len(r.spans))
+	for i := 1; i <= len(r.spans); i++ {
+		out = append(out, r.spans[(r.next-i+len(r.spans))%len(r.spans)])
+	}
+	return out
+}
+
+// Recorder is a [pipeline.TraceHandler] that records a summary of the most
+// recent spans it handles for each instrumentation scope before passing them
+// to the next handler.
+//
+// The memory used is bounded by the number of spans recorded per scope, the
+// number of scopes, and the number and size of the attributes recorded for
+// each span.
+type Recorder struct {
+	next pipeline.TraceHandler
+	size int
+
+	mu    sync.Mutex
+	rings map[string]*ring
+}
+
+var _ pipeline.TraceHandler = (*Recorder)(nil)
+
+// NewRecorder returns a new [Recorder] that records the size most recent
+// spans of each instrumentation scope and passes all spans to next. If size is
+// not positive, DefaultSpansPerScope is used.
+func NewRecorder(next pipeline.TraceHandler, size int) *Recorder {
+	if size <= 0 {
+		size = DefaultSpansPerScope
+	}
+	return &Recorder{next: next, size: size, rings: make(map[string]*ring)}
+}
+
+// WithRecorder returns a copy of h with its TraceHandler wrapped by a new
+// [Recorder], and that Recorder. If h does not have a TraceHandler, h and nil
+// are returned.
+func WithRecorder(h *pipeline.Handler, size int) (*pipeline.Handler, *Recorder) {
+	if h == nil || h.TraceHandler == nil {
+		return h, nil
+	}
+	r := NewRecorder(h.TraceHandler, size)
+	return &pipeline.Handler{
+		TraceHandler:  r,
+		MetricHandler: h.MetricHandler,
+		LogHandler:    h.LogHandler,
+	}, r
+}
+
+func (r *Recorder) HandleTrace(scope pcommon.InstrumentationScope, url string, spans ptrace.SpanSlice) {
+	r.mu.Lock()
+	rg, ok := r.rings[scope.Name()]
+	if !ok && len(r.rings) < maxScopes {
+		rg = &ring{spans: make([]SpanSummary, 0, r.size)}
+		r.rings[scope.Name()] = rg
+	}
+	if rg != nil {
+		for i := 0; i < spans.Len(); i++ {
+			rg.add(newSpanSummary(spans.At(i)))
+		}
+	}
+	r.mu.Unlock()
+
+	r.next.HandleTrace(scope, url, spans)
+}
+
+// Spans returns the recent spans recorded for each instrumentation scope name,
+// newest first.
+func (r *Recorder) Spans() map[string][]SpanSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string][]SpanSummary, len(r.rings))
+	for name, rg := range r.rings {
+		out[name] = rg.recent()
+	}
+	return out
+}
+
+// Counts returns the number of spans handled for each instrumentation scope
+// name.
+func (r *Recorder) Counts() map[string]uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string]uint64, len(r.rings))
+	for name, rg := range r.rings {
+		out[name] = rg.total
+	}
+	return out
+}
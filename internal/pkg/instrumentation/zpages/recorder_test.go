@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package zpages
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type countingHandler struct {
+	n int
+}
+
+func (h *countingHandler) HandleTrace(_ pcommon.InstrumentationScope, _ string, s ptrace.SpanSlice) {
+	h.n += s.Len()
+}
+
+func scope(name string) pcommon.InstrumentationScope {
+	s := pcommon.NewInstrumentationScope()
+	s.SetName(name)
+	return s
+}
+
+func spans(names ...string) ptrace.SpanSlice {
+	ss := ptrace.NewSpanSlice()
+	start := time.Unix(0, 0)
+	for _, name := range names {
+		s := ss.AppendEmpty()
+		s.SetName(name)
+		s.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		s.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Second)))
+	}
+	return ss
+}
+
+func TestRecorder(t *testing.T) {
+	next := new(countingHandler)
+	r := NewRecorder(next, 2)
+
+	r.HandleTrace(scope("a"), "", spans("a0", "a1", "a2"))
+	r.HandleTrace(scope("b"), "", spans("b0"))
+	r.HandleTrace(scope("a"), "", spans("a3"))
+
+	assert.Equal(t, 5, next.n, "spans not passed to next handler")
+	assert.Equal(t, map[string]uint64{"a": 4, "b": 1}, r.Counts())
+
+	got := r.Spans()
+	require.Len(t, got, 2)
+	require.Len(t, got["a"], 2)
+	assert.Equal(t, "a3", got["a"][0].Name)
+	assert.Equal(t, "a2", got["a"][1].Name)
+	assert.Equal(t, time.Second, got["a"][0].Duration)
+	require.Len(t, got["b"], 1)
+	assert.Equal(t, "b0", got["b"][0].Name)
+}
+
+func TestRecorderBounds(t *testing.T) {
+	r := NewRecorder(new(countingHandler), 1)
+
+	for i := 0; i < maxScopes+1; i++ {
+		r.HandleTrace(scope(strconv.Itoa(i)), "", spans("span"))
+	}
+	assert.Len(t, r.Spans(), maxScopes)
+
+	ss := spans("attrs")
+	for i := 0; i < maxAttrs+1; i++ {
+		ss.At(0).Attributes().PutStr(strconv.Itoa(i), strings.Repeat("x", maxValueLen+1))
+	}
+	r.HandleTrace(scope("0"), "", ss)
+
+	got := r.Spans()["0"]
+	require.Len(t, got, 1)
+	assert.Len(t, got[0].Attrs, maxAttrs)
+	assert.Equal(t, strings.Repeat("x", maxValueLen)+"…", got[0].Attrs[0].Value)
+}
+
+func TestNewRecorderDefaultSize(t *testing.T) {
+	r := NewRecorder(new(countingHandler), 0)
+	assert.Equal(t, DefaultSpansPerScope, r.size)
+}
@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package zpages
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.sock")
+	ln, err := Listen("unix:" + path)
+	require.NoError(t, err)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	// A stale socket is replaced.
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+	ln, err = Listen("unix:" + path)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+
+	_, err = Listen("unix:")
+	assert.Error(t, err)
+}
+
+func TestListen(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:0", "[::1]:0", "localhost:0"} {
+		ln, err := Listen(addr)
+		if err != nil {
+			// IPv6 may not be available.
+			assert.NotEqual(t, "127.0.0.1:0", addr, err)
+			continue
+		}
+		require.NoError(t, ln.Close())
+	}
+
+	ln, err := Listen(":0")
+	require.NoError(t, err)
+	assert.True(t, ln.Addr().(*net.TCPAddr).IP.IsLoopback(), "empty host not loopback")
+	require.NoError(t, ln.Close())
+
+	for _, addr := range []string{"0.0.0.0:0", "192.0.2.1:0", "example.com:0", "invalid"} {
+		_, err := Listen(addr)
+		assert.Error(t, err, addr)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package zpages
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/pipeline/otelsdk"
+)
+
+// Source provides the probe state shown by the pages.
+type Source interface {
+	// ProbeStatus returns the status of all probes.
+	ProbeStatus() []instrumentation.ProbeStatus
+	// Config returns the currently applied instrumentation configuration.
+	Config() instrumentation.Config
+}
+
+// ExporterSource provides the export status shown by the pages.
+type ExporterSource interface {
+	// ExportStatus returns the status of the export of spans.
+	ExportStatus() otelsdk.ExportStatus
+}
+
+// Setting is a configuration setting shown by the pages.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// NewHandler returns an [http.Handler] serving the pages:
+//
+//   - /: index of the pages
+//   - /spans: recent spans recorded by rec for each instrumentation scope
+//   - /probes: probe status and event counters, and the spans rejected by
+//     the collector as reported by exp
+//   - /config: active configuration
+//
+// The rec and exp parameters may be nil.
+func NewHandler(src Source, rec *Recorder, exp ExporterSource, settings []Setting) http.Handler {
+	p := &pages{src: src, rec: rec, exp: exp, settings: settings}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", p.index)
+	mux.HandleFunc("/spans", p.spans)
+	mux.HandleFunc("/probes", p.probes)
+	mux.HandleFunc("/config", p.config)
+	return mux
+}
+
+type pages struct {
+	src      Source
+	rec      *Recorder
+	exp      ExporterSource
+	settings []Setting
+}
+
+func (p *pages) index(w http.ResponseWriter, _ *http.Request) {
+	p.render(w, "index", nil)
+}
+
+type scopeSpans struct {
+	Scope string
+	Spans []SpanSummary
+}
+
+func (p *pages) spans(w http.ResponseWriter, r *http.Request) {
+	var data []scopeSpans
+	if p.rec != nil {
+		filter := r.URL.Query().Get("scope")
+		for scope, spans := range p.rec.Spans() {
+			if filter != "" && scope != filter {
+				continue
+			}
+			data = append(data, scopeSpans{Scope: scope, Spans: spans})
+		}
+	}
+	slices.SortFunc(data, func(a, b scopeSpans) int {
+		return strings.Compare(a.Scope, b.Scope)
+	})
+	p.render(w, "spans", data)
+}
+
+type probeRow struct {
+	instrumentation.ProbeStatus
+	Spans uint64
+}
+
+type probesData struct {
+	Probes   []probeRow
+	Exporter *otelsdk.ExportStatus
+}
+
+func (p *pages) probes(w http.ResponseWriter, _ *http.Request) {
+	var counts map[string]uint64
+	if p.rec != nil {
+		counts = p.rec.Counts()
+	}
+
+	status := p.src.ProbeStatus()
+	rows := make([]probeRow, len(status))
+	for i, s := range status {
+		rows[i] = probeRow{ProbeStatus: s, Spans: counts[probe.ScopeName(s.ID)]}
+	}
+	data := probesData{Probes: rows}
+	if p.exp != nil {
+		es := p.exp.ExportStatus()
+		data.Exporter = &es
+	}
+	p.render(w, "probes", data)
+}
+
+func (p *pages) config(w http.ResponseWriter, _ *http.Request) {
+	c := p.src.Config()
+
+	settings := slices.Clone(p.settings)
+	settings = append(settings, Setting{
+		Name:  "default traces disabled",
+		Value: fmt.Sprint(c.DefaultTracesDisabled),
+	})
+	var libs []Setting
+	for id, lib := range c.InstrumentationLibraryConfigs {
+		v := "default"
+		if lib.TracesEnabled != nil {
+			v = fmt.Sprint(*lib.TracesEnabled)
+		}
+		name := "traces enabled: " + id.InstrumentedPkg
+		if id.SpanKind != 0 {
+			name += " (" + id.SpanKind.String() + ")"
+		}
+		libs = append(libs, Setting{Name: name, Value: v})
+	}
+	slices.SortFunc(libs, func(a, b Setting) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	settings = append(settings, libs...)
+	if c.SamplingConfig != nil {
+		settings = append(settings, Setting{
+			Name:  "sampling",
+			Value: fmt.Sprintf("%+v", *c.SamplingConfig),
+		})
+	}
+	p.render(w, "config", settings)
+}
+
+func (p *pages) render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var tmpl = template.Must(template.New("").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html><head><title>OpenTelemetry Go Auto-Instrumentation</title></head>
+<body>
+<p><a href="/spans">spans</a> | <a href="/probes">probes</a> | <a href="/config">config</a></p>
+{{end}}
+{{define "footer"}}</body></html>
+{{end}}
+
+{{define "index"}}{{template "header"}}
+<h1>OpenTelemetry Go Auto-Instrumentation</h1>
+<ul>
+<li><a href="/spans">Recent spans</a> produced for each instrumentation scope</li>
+<li><a href="/probes">Probes</a> status and event counters, and spans rejected by the collector</li>
+<li><a href="/config">Active configuration</a></li>
+</ul>
+{{template "footer"}}{{end}}
+
+{{define "spans"}}{{template "header"}}
+<h1>Recent spans</h1>
+{{range .}}
+<h2><a href="/spans?scope={{.Scope}}">{{.Scope}}</a></h2>
+<table border="1">
+<tr><th>Start</th><th>Duration</th><th>Name</th><th>Kind</th><th>Status</th><th>Trace ID</th><th>Span ID</th><th>Attributes</th></tr>
+{{range .Spans}}<tr>
+<td>{{.Start.Format "2006-01-02T15:04:05.000000Z07:00"}}</td>
+<td>{{.Duration}}</td>
+<td>{{.Name}}</td>
+<td>{{.Kind}}</td>
+<td>{{.Status}}</td>
+<td><code>{{.TraceID}}</code></td>
+<td><code>{{.SpanID}}</code></td>
+<td>{{range .Attrs}}{{.Key}}={{.Value}}<br>{{end}}</td>
+</tr>{{end}}
+</table>
+{{else}}
+<p>No spans recorded.</p>
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "probes"}}{{template "header"}}
+<h1>Probes</h1>
+<table border="1">
+<tr><th>Probe</th><th>State</th><th>Error</th><th>Events</th><th>Lost events</th><th>Spans</th></tr>
+{{range .Probes}}<tr>
+<td>{{.ID}}</td>
+<td>{{.State}}</td>
+<td>{{with .Err}}{{.}}{{end}}</td>
+<td>{{.Stats.Events}}</td>
+<td>{{.Stats.Lost}}</td>
+<td>{{.Spans}}</td>
+</tr>{{end}}
+</table>
+{{with .Exporter}}
+<h2>Exporter</h2>
+<table border="1">
+<tr><th>Rejected spans</th><th>Last rejection</th><th>Last rejection time</th></tr>
+<tr>
+<td>{{.RejectedSpans}}</td>
+<td>{{.LastRejection}}</td>
+<td>{{if not .LastRejectionTime.IsZero}}{{.LastRejectionTime.Format "2006-01-02T15:04:05Z07:00"}}{{end}}</td>
+</tr>
+</table>
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "config"}}{{template "header"}}
+<h1>Active configuration</h1>
+<table border="1">
+<tr><th>Setting</th><th>Value</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}
+</table>
+{{template "footer"}}{{end}}
+`))
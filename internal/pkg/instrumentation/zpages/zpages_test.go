@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package zpages
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/probe"
+	"go.opentelemetry.io/auto/pipeline/otelsdk"
+)
+
+type source struct {
+	status []instrumentation.ProbeStatus
+	config instrumentation.Config
+	maps   map[probe.ID][]probe.MapUsage
+}
+
+func (s source) ProbeStatus() []instrumentation.ProbeStatus { return s.status }
+
+func (s source) Config() instrumentation.Config { return s.config }
+
+func (s source) ProbeMapUsage() map[probe.ID][]probe.MapUsage { return s.maps }
+
+type exporter otelsdk.ExportStatus
+
+func (e exporter) ExportStatus() otelsdk.ExportStatus { return otelsdk.ExportStatus(e) }
+
+func get(t *testing.T, h http.Handler, path string) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code, path)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestHandler(t *testing.T) {
+	serverID := probe.ID{InstrumentedPkg: "net/http", SpanKind: trace.SpanKindServer}
+	clientID := probe.ID{InstrumentedPkg: "net/http", SpanKind: trace.SpanKindClient}
+	src := source{
+		status: []instrumentation.ProbeStatus{
+			{
+				ID:    serverID,
+				State: instrumentation.ProbeStateRunning,
+				Stats: probe.Stats{Events: 10, Lost: 3},
+			},
+			{
+				ID:    clientID,
+				State: instrumentation.ProbeStateFailed,
+				Err:   errors.New("attach failed"),
+			},
+		},
+		config: instrumentation.Config{DefaultTracesDisabled: true},
+	}
+
+	rec := NewRecorder(new(countingHandler), 2)
+	ss := spans("GET /hello")
+	ss.At(0).Attributes().PutStr("http.route", "/hello")
+	rec.HandleTrace(scope(probe.ScopeName(serverID)), "", ss)
+
+	exp := exporter{RejectedSpans: 7, LastRejection: "attribute limit exceeded"}
+	h := NewHandler(src, rec, exp, []Setting{{Name: "proxy mode", Value: "true"}})
+
+	assert.Contains(t, get(t, h, "/"), `href="/spans"`)
+
+	body := get(t, h, "/spans")
+	assert.Contains(t, body, "go.opentelemetry.io/auto/net/http/server")
+	assert.Contains(t, body, "GET /hello")
+	assert.Contains(t, body, "http.route=/hello")
+
+	body = get(t, h, "/spans?scope=unknown")
+	assert.Contains(t, body, "No spans recorded.")
+
+	body = get(t, h, "/probes")
+	assert.Contains(t, body, "<td>running</td>")
+	assert.Contains(t, body, "<td>10</td>")
+	assert.Contains(t, body, "<td>3</td>")
+	assert.Contains(t, body, "<td>1</td>", "recorded span count")
+	assert.Contains(t, body, "attach failed")
+	assert.Contains(t, body, "<td>7</td>", "rejected spans")
+	assert.Contains(t, body, "attribute limit exceeded")
+
+	body = get(t, h, "/config")
+	assert.Contains(t, body, "<td>proxy mode</td><td>true</td>")
+	assert.Contains(t, body, "<td>default traces disabled</td><td>true</td>")
+}
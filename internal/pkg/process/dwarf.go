@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"go.opentelemetry.io/auto/internal/pkg/structfield"
 )
@@ -23,7 +24,7 @@ type DWARF struct {
 // GoStructField returns the offset value of a Go struct field. If the struct
 // field cannot be found -1 and a non-nil error will be returned.
 func (d DWARF) GoStructField(id structfield.ID) (int64, error) {
-	strct := fmt.Sprintf("%s.%s", id.PkgPath, id.Struct)
+	strct := fmt.Sprintf("%s.%s", LinkerPkgPath(id.PkgPath), id.Struct)
 	if !d.GoToEntry(dwarf.TagStructType, strct) {
 		return -1, fmt.Errorf("struct %q not found", strct)
 	}
@@ -45,6 +46,14 @@ func (d DWARF) GoStructField(id structfield.ID) (int64, error) {
 	return v, nil
 }
 
+// LinkerPkgPath returns pkgPath as it is named in the DWARF data, and symbols,
+// of a Go binary. The linker escapes the dots of the last element of the path
+// (e.g. "github.com/nats-io/nats%2ego" for "github.com/nats-io/nats.go").
+func LinkerPkgPath(pkgPath string) string {
+	i := strings.LastIndexByte(pkgPath, '/') + 1
+	return pkgPath[:i] + strings.ReplaceAll(pkgPath[i:], ".", "%2e")
+}
+
 // GoToEntry reads until the entry with a tag equal to name is found. True is
 // returned if the entry is found, otherwise false is returned.
 func (d DWARF) GoToEntry(tag dwarf.Tag, name string) bool {
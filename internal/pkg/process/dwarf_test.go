@@ -0,0 +1,29 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkerPkgPath(t *testing.T) {
+	tests := []struct {
+		pkgPath string
+		want    string
+	}{
+		{pkgPath: "net/http", want: "net/http"},
+		{pkgPath: "github.com/nats-io/nats.go", want: "github.com/nats-io/nats%2ego"},
+		{pkgPath: "gopkg.in/yaml.v3", want: "gopkg.in/yaml%2ev3"},
+		{pkgPath: "go.opentelemetry.io/otel/trace", want: "go.opentelemetry.io/otel/trace"},
+		{pkgPath: "main", want: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkgPath, func(t *testing.T) {
+			assert.Equal(t, tt.want, LinkerPkgPath(tt.pkgPath))
+		})
+	}
+}
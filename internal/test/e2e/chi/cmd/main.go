@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package chi is a testing application for the [github.com/go-chi/chi/v5]
+// package.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/go-chi/chi/v5"
+
+	"go.opentelemetry.io/auto/internal/test/trigger"
+)
+
+func get(ctx context.Context, url string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Body: %s\n", string(body))
+	_ = resp.Body.Close()
+}
+
+func main() {
+	var trig trigger.Flag
+	flag.Var(&trig, "trigger", trig.Docs())
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(api chi.Router) {
+		users := chi.NewRouter()
+		users.Get("/{userID}", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("hello " + chi.URLParam(req, "userID") + "\n"))
+		})
+		api.Mount("/users", users)
+	})
+	go func() {
+		_ = http.ListenAndServe(":8080", r)
+	}()
+
+	// Wait for auto-instrumentation.
+	err := trig.Wait(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	get(ctx, "http://localhost:8080/api/v1/users/chi")
+	// The mounted router finds no route, the request is handled by the
+	// NotFound handler.
+	get(ctx, "http://localhost:8080/api/v1/missing")
+}
@@ -17,7 +17,7 @@ import (
 )
 
 // scopeName defines the instrumentation scope name used in the trace.
-const scopeName = "go.opentelemetry.io/auto/database/sql"
+const scopeName = "go.opentelemetry.io/auto/database/sql/client"
 
 // queryTextKey defines the attribute key for the SQL query text.
 const queryTextKey = string(semconv.DBQueryTextKey)
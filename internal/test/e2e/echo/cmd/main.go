@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package echo is a testing application for the
+// [github.com/labstack/echo/v4] package.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/labstack/echo/v4"
+
+	"go.opentelemetry.io/auto/internal/test/trigger"
+)
+
+func main() {
+	var trig trigger.Flag
+	flag.Var(&trig, "trigger", trig.Docs())
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	e := echo.New()
+	e.HideBanner = true
+	e.GET("/hello/:name", func(c echo.Context) error {
+		return c.String(http.StatusOK, "hello "+c.Param("name")+"\n")
+	})
+	go func() {
+		_ = e.Start(":8080")
+	}()
+
+	// Wait for auto-instrumentation.
+	err := trig.Wait(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	url := "http://localhost:8080/hello/echo"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Body: %s\n", string(body))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fasthttp is a testing application for the
+// [github.com/valyala/fasthttp] package.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/valyala/fasthttp"
+
+	"go.opentelemetry.io/auto/internal/test/trigger"
+)
+
+func main() {
+	var trig trigger.Flag
+	flag.Var(&trig, "trigger", trig.Docs())
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		_ = fasthttp.ListenAndServe(":8080", func(c *fasthttp.RequestCtx) {
+			c.SetStatusCode(fasthttp.StatusOK)
+			c.SetBodyString("hello " + string(c.QueryArgs().Peek("name")) + "\n")
+		})
+	}()
+
+	// Wait for auto-instrumentation.
+	err := trig.Wait(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("http://localhost:8080/hello?name=fasthttp")
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	var client fasthttp.Client
+	if err := client.Do(req, resp); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Body: %s\n", string(resp.Body()))
+}
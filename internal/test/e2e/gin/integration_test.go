@@ -16,8 +16,11 @@ import (
 	"go.opentelemetry.io/auto/internal/test/e2e"
 )
 
-// scopeName defines the instrumentation scope name used in the trace.
-const scopeName = "go.opentelemetry.io/auto/net/http"
+// scopeNames defines the instrumentation scope names used in the trace.
+var scopeNames = []string{
+	"go.opentelemetry.io/auto/net/http/server",
+	"go.opentelemetry.io/auto/net/http/client",
+}
 
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
@@ -27,7 +30,7 @@ func TestIntegration(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	traces := e2e.RunInstrumentation(t, "./cmd")
-	scopes := e2e.ScopeSpansByName(traces, scopeName)
+	scopes := e2e.ScopeSpansByName(traces, scopeNames...)
 	require.NotEmpty(t, scopes)
 
 	t.Run("ResourceAttribute/ServiceName", func(t *testing.T) {
@@ -37,11 +40,11 @@ func TestIntegration(t *testing.T) {
 	})
 
 	t.Run("Scope", func(t *testing.T) {
-		assert.Equal(t, scopeName, scopes[0].Scope().Name(), "scope name")
+		assert.Contains(t, scopeNames, scopes[0].Scope().Name(), "scope name")
 	})
 
 	serverS, err := e2e.SelectSpan(scopes, func(s ptrace.Span) bool {
-		return s.Name() == "GET" && s.Kind() == ptrace.SpanKindServer
+		return s.Name() == "GET /hello-gin" && s.Kind() == ptrace.SpanKindServer
 	})
 	require.NoError(t, err)
 	t.Run("ServerSpan", func(t *testing.T) {
@@ -52,6 +55,7 @@ func TestIntegration(t *testing.T) {
 		attrs := e2e.AttributesMap(serverS.Attributes())
 		assert.Equal(t, "GET", attrs["http.request.method"], "http.request.method")
 		assert.Equal(t, "/hello-gin", attrs["url.path"], "http.url")
+		assert.Equal(t, "/hello-gin", attrs["http.route"], "http.route")
 		assert.Equal(
 			t,
 			int64(200),
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gorillamux is a testing application for the
+// [github.com/gorilla/mux] package.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/gorilla/mux"
+
+	"go.opentelemetry.io/auto/internal/test/trigger"
+)
+
+func get(ctx context.Context, url string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Body: %s\n", string(body))
+	_ = resp.Body.Close()
+}
+
+func main() {
+	var trig trigger.Flag
+	flag.Var(&trig, "trigger", trig.Docs())
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/hello/{name}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("hello " + mux.Vars(req)["name"] + "\n"))
+	}).Methods(http.MethodGet)
+	go func() {
+		_ = http.ListenAndServe(":8080", r)
+	}()
+
+	// Wait for auto-instrumentation.
+	err := trig.Wait(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	get(ctx, "http://localhost:8080/api/hello/mux")
+	// No route matches, the request is handled by the NotFoundHandler.
+	get(ctx, "http://localhost:8080/missing")
+}
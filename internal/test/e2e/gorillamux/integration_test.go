@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gorillamux provides an integration test for the routes of
+// gorilla/mux handlers.
+package gorillamux
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/goleak"
+
+	"go.opentelemetry.io/auto/internal/test/e2e"
+)
+
+// scopeNames defines the instrumentation scope names used in the trace.
+var scopeNames = []string{
+	"go.opentelemetry.io/auto/net/http/server",
+	"go.opentelemetry.io/auto/net/http/client",
+}
+
+func TestIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping long-running integration test in short mode.")
+	}
+
+	defer goleak.VerifyNone(t)
+
+	traces := e2e.RunInstrumentation(t, "./cmd")
+	scopes := e2e.ScopeSpansByName(traces, scopeNames...)
+	require.NotEmpty(t, scopes)
+
+	t.Run("ResourceAttribute/ServiceName", func(t *testing.T) {
+		val, err := e2e.ResourceAttribute(traces, "service.name")
+		require.NoError(t, err)
+		assert.Equal(t, "sample-app", val.AsString())
+	})
+
+	serverS, err := e2e.SelectSpan(scopes, func(s ptrace.Span) bool {
+		return s.Name() == "GET /api/hello/{name}" && s.Kind() == ptrace.SpanKindServer
+	})
+	require.NoError(t, err)
+	t.Run("ServerSpan", func(t *testing.T) {
+		e2e.AssertTraceID(t, serverS.TraceID(), "trace ID")
+
+		e2e.AssertSpanID(t, serverS.SpanID(), "span ID")
+
+		attrs := e2e.AttributesMap(serverS.Attributes())
+		assert.Equal(t, "GET", attrs["http.request.method"], "http.request.method")
+		assert.Equal(t, "/api/hello/mux", attrs["url.path"], "url.path")
+		// The template of a subrouter route includes its prefix.
+		assert.Equal(t, "/api/hello/{name}", attrs["http.route"], "http.route")
+		assert.Equal(
+			t,
+			int64(200),
+			attrs["http.response.status_code"],
+			"http.response.status_code",
+		)
+	})
+
+	notFoundS, err := e2e.SelectSpan(scopes, func(s ptrace.Span) bool {
+		if s.Kind() != ptrace.SpanKindServer {
+			return false
+		}
+		attrs := e2e.AttributesMap(s.Attributes())
+		return attrs["url.path"] == "/missing"
+	})
+	require.NoError(t, err)
+	t.Run("NotFoundSpan", func(t *testing.T) {
+		assert.Equal(t, "GET", notFoundS.Name(), "span name")
+
+		attrs := e2e.AttributesMap(notFoundS.Attributes())
+		assert.NotContains(t, attrs, "http.route", "http.route")
+		assert.Equal(
+			t,
+			int64(404),
+			attrs["http.response.status_code"],
+			"http.response.status_code",
+		)
+	})
+
+	clientS, err := e2e.SelectSpan(scopes, func(s ptrace.Span) bool {
+		if s.Kind() != ptrace.SpanKindClient {
+			return false
+		}
+		attrs := e2e.AttributesMap(s.Attributes())
+		return attrs["url.path"] == "/api/hello/mux"
+	})
+	require.NoError(t, err)
+
+	var clientSpanID [8]byte = clientS.SpanID()
+	var serverParentSpanID [8]byte = serverS.ParentSpanID()
+	assert.Equal(
+		t,
+		hex.EncodeToString(clientSpanID[:]),
+		hex.EncodeToString(serverParentSpanID[:]),
+		"client is parent of server",
+	)
+}
@@ -17,8 +17,11 @@ import (
 	"go.opentelemetry.io/auto/internal/test/e2e"
 )
 
-// scopeName defines the instrumentation scope name used in the trace.
-const scopeName = "go.opentelemetry.io/auto/google.golang.org/grpc"
+// scopeNames defines the instrumentation scope names used in the trace.
+var scopeNames = []string{
+	"go.opentelemetry.io/auto/google.golang.org/grpc/server",
+	"go.opentelemetry.io/auto/google.golang.org/grpc/client",
+}
 
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
@@ -28,7 +31,7 @@ func TestIntegration(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	traces := e2e.RunInstrumentation(t, "./cmd")
-	scopes := e2e.ScopeSpansByName(traces, scopeName)
+	scopes := e2e.ScopeSpansByName(traces, scopeNames...)
 	require.NotEmpty(t, scopes)
 
 	t.Run("ResourceAttribute/ServiceName", func(t *testing.T) {
@@ -38,7 +41,10 @@ func TestIntegration(t *testing.T) {
 	})
 
 	t.Run("Scope", func(t *testing.T) {
-		assert.Equal(t, scopeName, scopes[0].Scope().Name(), "scope name")
+		assert.Contains(t, scopeNames, scopes[0].Scope().Name(), "scope name")
+		v, ok := scopes[0].Scope().Attributes().Get("otel.auto.instrumented_library.version")
+		assert.True(t, ok, "instrumented library version")
+		assert.Regexp(t, `^1\.\d+\.\d+`, v.AsString(), "instrumented library version")
 	})
 
 	var count int
@@ -51,7 +57,7 @@ func TestIntegration(t *testing.T) {
 			}
 			count++
 			t.Run("ServerSpan/"+strconv.Itoa(count), func(t *testing.T) {
-				assert.Equal(t, "/helloworld.Greeter/SayHello", span.Name(), "span name")
+				assert.Equal(t, "helloworld.Greeter/SayHello", span.Name(), "span name")
 
 				e2e.AssertTraceID(t, span.TraceID(), "trace ID")
 				e2e.AssertSpanID(t, span.SpanID(), "span ID")
@@ -59,19 +65,19 @@ func TestIntegration(t *testing.T) {
 
 				attrs := e2e.AttributesMap(span.Attributes())
 				assert.Equal(t, "grpc", attrs["rpc.system"], "rpc.system")
-				assert.Equal(
-					t,
-					"/helloworld.Greeter/SayHello",
-					attrs["rpc.service"],
-					"rpc.service",
-				)
+				assert.Equal(t, "helloworld.Greeter", attrs["rpc.service"], "rpc.service")
+				assert.Equal(t, "SayHello", attrs["rpc.method"], "rpc.method")
 				assert.Equal(t, "127.0.0.1", attrs["server.address"], "server.address")
 				assert.Equal(t, int64(1701), attrs["server.port"], "server.port")
+				assert.Equal(t, "tcp", attrs["network.transport"], "network.transport")
+				assert.Equal(t, "127.0.0.1", attrs["client.address"], "client.address")
+				assert.Positive(t, attrs["client.port"], "client.port")
 
 				code, ok := attrs["rpc.grpc.status_code"]
 				assert.True(t, ok, "has rpc.grpc.status_code attribute")
 				if v, ok := code.(int64); ok && v != 0 {
 					assert.Equal(t, int64(12), v, "error code value")
+					assert.Equal(t, "unimplmented", span.Status().Message(), "status message")
 				}
 			})
 		}
@@ -144,6 +150,7 @@ func TestIntegration(t *testing.T) {
 					"rpc.service",
 				)
 				assert.Equal(t, int64(1701), attrs["server.port"], "server.port")
+				assert.Equal(t, "tcp", attrs["network.transport"], "network.transport")
 
 				code, ok := attrs["rpc.grpc.status_code"]
 				assert.True(t, ok, "has rpc.grpc.status_code attribute")
@@ -18,8 +18,11 @@ import (
 	"go.opentelemetry.io/auto/internal/test/e2e"
 )
 
-// scopeName defines the instrumentation scope name used in the trace.
-const scopeName = "go.opentelemetry.io/auto/github.com/segmentio/kafka-go"
+// scopeNames defines the instrumentation scope names used in the trace.
+var scopeNames = []string{
+	"go.opentelemetry.io/auto/github.com/segmentio/kafka-go/producer",
+	"go.opentelemetry.io/auto/github.com/segmentio/kafka-go/consumer",
+}
 
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
@@ -29,7 +32,7 @@ func TestIntegration(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	traces := e2e.RunInstrumentation(t, "./cmd")
-	scopes := e2e.ScopeSpansByName(traces, scopeName)
+	scopes := e2e.ScopeSpansByName(traces, scopeNames...)
 	require.NotEmpty(t, scopes)
 
 	t.Run("ResourceAttribute/ServiceName", func(t *testing.T) {
@@ -44,9 +47,9 @@ func TestIntegration(t *testing.T) {
 
 	var producerSpanIDs []string
 	s, err := e2e.SelectSpan(scopes, func(span ptrace.Span) bool {
-		return span.Kind() == ptrace.SpanKindProducer && span.Name() == "topic1 publish"
+		return span.Kind() == ptrace.SpanKindProducer && span.Name() == "topic1 create"
 	})
-	require.NoError(t, err, "producer span 'topic1 publish' not found")
+	require.NoError(t, err, "producer span 'topic1 create' not found")
 
 	b := [8]byte(s.SpanID())
 	sID := hex.EncodeToString(b[:])
@@ -54,15 +57,21 @@ func TestIntegration(t *testing.T) {
 	t.Run("ProducerSpan/topic1", pSpan(1, tID, s))
 
 	s, err = e2e.SelectSpan(scopes, func(span ptrace.Span) bool {
-		return span.Kind() == ptrace.SpanKindProducer && span.Name() == "topic2 publish"
+		return span.Kind() == ptrace.SpanKindProducer && span.Name() == "topic2 create"
 	})
-	require.NoError(t, err, "producer span 'topic2 publish' not found")
+	require.NoError(t, err, "producer span 'topic2 create' not found")
 
 	b = [8]byte(s.SpanID())
 	sID = hex.EncodeToString(b[:])
 	producerSpanIDs = append(producerSpanIDs, sID)
 	t.Run("ProducerSpan/topic2", pSpan(2, tID, s))
 
+	s, err = e2e.SelectSpan(scopes, func(span ptrace.Span) bool {
+		return span.Kind() == ptrace.SpanKindProducer && span.Name() == "publish"
+	})
+	require.NoError(t, err, "producer span 'publish' not found")
+	t.Run("ProducerSpan/batch", batchSpan(tID, producerSpanIDs, s))
+
 	s, err = e2e.SelectSpan(scopes, func(span ptrace.Span) bool {
 		return span.Kind() == ptrace.SpanKindConsumer && span.Name() == "topic1 receive"
 	})
@@ -108,12 +117,54 @@ func pSpan(n int, tID string, span ptrace.Span) func(t *testing.T) {
 			attrs[string(semconv.MessagingKafkaMessageKeyKey)],
 			"messaging.kafka.message.key",
 		)
+		assert.Equal(
+			t,
+			"0",
+			attrs[string(semconv.MessagingDestinationPartitionIDKey)],
+			"messaging.destination.partition.id",
+		)
+		assert.Equal(
+			t,
+			"create",
+			attrs[string(semconv.MessagingOperationTypeKey)],
+			"messaging.operation.type",
+		)
+	}
+}
+
+func batchSpan(tID string, msgSpanIDs []string, span ptrace.Span) func(t *testing.T) {
+	return func(t *testing.T) {
+		b := [16]byte(span.TraceID())
+		assert.Equalf(t, tID, hex.EncodeToString(b[:]), "trace ID")
+		e2e.AssertSpanID(t, span.SpanID(), "span ID")
+
+		attrs := e2e.AttributesMap(span.Attributes())
+		assert.Equal(
+			t,
+			"kafka",
+			attrs[string(semconv.MessagingSystemKey)],
+			"messaging.system",
+		)
+		assert.Equal(
+			t,
+			"send",
+			attrs[string(semconv.MessagingOperationTypeKey)],
+			"messaging.operation.type",
+		)
 		assert.Equal(
 			t,
 			int64(2),
 			attrs[string(semconv.MessagingBatchMessageCountKey)],
 			"messaging.batch.message.count",
 		)
+
+		// The batch is linked to the spans of its messages.
+		var linked []string
+		for i := 0; i < span.Links().Len(); i++ {
+			b := [8]byte(span.Links().At(i).SpanID())
+			linked = append(linked, hex.EncodeToString(b[:]))
+		}
+		assert.ElementsMatch(t, msgSpanIDs, linked, "links")
 	}
 }
 
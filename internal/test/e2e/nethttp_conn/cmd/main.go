@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nethttp_conn is a testing application for the connections of the
+// [net/http] client.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+
+	"go.opentelemetry.io/auto/internal/test/trigger"
+)
+
+func hello(w http.ResponseWriter, _ *http.Request) {
+	fmt.Fprintf(w, "hello\n")
+}
+
+func get(ctx context.Context, client *http.Client, url string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Body: %s\n", string(body))
+	_ = resp.Body.Close()
+}
+
+func main() {
+	var trig trigger.Flag
+	flag.Var(&trig, "trigger", trig.Docs())
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(hello))
+	defer srv.Close()
+
+	// Wait for auto-instrumentation.
+	err := trig.Wait(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The connection dialed by the first request is reused by the second.
+	client := srv.Client()
+	get(ctx, client, srv.URL+"/first")
+	get(ctx, client, srv.URL+"/second")
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nethttp_conn provides an integration test for the connection probes
+// of the net/http client.
+package nethttp_conn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/goleak"
+
+	"go.opentelemetry.io/auto/internal/test/e2e"
+)
+
+// scopeNames defines the instrumentation scope names used in the trace.
+var scopeNames = []string{
+	"go.opentelemetry.io/auto/net/http/client",
+	"go.opentelemetry.io/auto/net/internal",
+	"go.opentelemetry.io/auto/crypto/tls/internal",
+}
+
+// children returns the spans of scopes that are children of parent.
+func children(scopes []ptrace.ScopeSpans, parent ptrace.Span) []ptrace.Span {
+	var out []ptrace.Span
+	for _, scope := range scopes {
+		spans := scope.Spans()
+		for i := range spans.Len() {
+			if s := spans.At(i); s.ParentSpanID() == parent.SpanID() {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func TestIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping long-running integration test in short mode.")
+	}
+
+	defer goleak.VerifyNone(t)
+
+	t.Setenv("OTEL_GO_AUTO_HTTP_CLIENT_CONNECTION_SPANS", "true")
+	traces := e2e.RunInstrumentation(t, "./cmd")
+	scopes := e2e.ScopeSpansByName(traces, scopeNames...)
+	require.NotEmpty(t, scopes)
+
+	var clientSpans []ptrace.Span
+	for _, scope := range e2e.ScopeSpansByName(traces, scopeNames[0]) {
+		spans := scope.Spans()
+		for i := range spans.Len() {
+			clientSpans = append(clientSpans, spans.At(i))
+		}
+	}
+	require.Len(t, clientSpans, 2)
+	first, second := clientSpans[0], clientSpans[1]
+	if first.StartTimestamp() > second.StartTimestamp() {
+		first, second = second, first
+	}
+
+	t.Run("NewConnection", func(t *testing.T) {
+		got := children(scopes, first)
+		require.Len(t, got, 2)
+		names := []string{got[0].Name(), got[1].Name()}
+		assert.ElementsMatch(t, []string{"connect", "TLS handshake"}, names)
+
+		for _, span := range got {
+			assert.Equal(t, ptrace.SpanKindInternal, span.Kind(), "span kind")
+			assert.Equal(t, first.TraceID(), span.TraceID(), "trace ID")
+			assert.Equal(t, ptrace.StatusCodeUnset, span.Status().Code(), "status")
+
+			attrs := e2e.AttributesMap(span.Attributes())
+			assert.Equal(t, "127.0.0.1", attrs["server.address"], "server address")
+			assert.Equal(t, "127.0.0.1", attrs["network.peer.address"], "network peer address")
+			assert.Regexp(t, e2e.PortRE, attrs["network.peer.port"], "network peer port")
+			assert.Equal(t, "tcp", attrs["network.transport"], "network transport")
+			if span.Name() == "TLS handshake" {
+				assert.Equal(t, "tls", attrs["tls.protocol.name"], "TLS protocol name")
+				assert.Equal(t, "1.3", attrs["tls.protocol.version"], "TLS protocol version")
+				assert.NotEmpty(t, attrs["tls.cipher"], "TLS cipher")
+			}
+		}
+	})
+
+	t.Run("ReusedConnection", func(t *testing.T) {
+		// The connection of the first request is reused, it is not dialed.
+		assert.Empty(t, children(scopes, second))
+	})
+}
@@ -18,8 +18,11 @@ import (
 	"go.opentelemetry.io/auto/internal/test/e2e"
 )
 
-// scopeName defines the instrumentation scope name used in the trace.
-const scopeName = "go.opentelemetry.io/auto/net/http"
+// scopeNames defines the instrumentation scope names used in the trace.
+var scopeNames = []string{
+	"go.opentelemetry.io/auto/net/http/server",
+	"go.opentelemetry.io/auto/net/http/client",
+}
 
 func TestIntegration(t *testing.T) {
 	if testing.Short() {
@@ -29,7 +32,7 @@ func TestIntegration(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
 	traces := e2e.RunInstrumentation(t, "./cmd")
-	scopes := e2e.ScopeSpansByName(traces, scopeName)
+	scopes := e2e.ScopeSpansByName(traces, scopeNames...)
 	require.NotEmpty(t, scopes)
 
 	t.Run("ResourceAttribute/ServiceName", func(t *testing.T) {
@@ -40,7 +43,7 @@ func TestIntegration(t *testing.T) {
 
 	for i, scope := range scopes {
 		t.Run("Scope/"+strconv.Itoa(i), func(t *testing.T) {
-			assert.Equal(t, scopeName, scope.Scope().Name(), "scope name")
+			assert.Contains(t, scopeNames, scope.Scope().Name(), "scope name")
 			assert.Equal(t, semconv.SchemaURL, scope.SchemaUrl(), "schema URL")
 		})
 	}
@@ -92,6 +95,12 @@ func TestIntegration(t *testing.T) {
 			attrs[string(semconv.NetworkPeerAddressKey)],
 			"network peer address",
 		)
+		assert.Equal(
+			t,
+			"tcp",
+			attrs[string(semconv.NetworkTransportKey)],
+			"network transport",
+		)
 	})
 
 	clientSpan, err := e2e.SelectSpan(scopes, func(span ptrace.Span) bool {
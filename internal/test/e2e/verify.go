@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"testing" // nolint:depguard  // This is a testing utility package.
 
 	"github.com/stretchr/testify/assert" // nolint:depguard  // This is a testing utility package.
@@ -40,15 +41,15 @@ func ResourceAttribute(td ptrace.Traces, key string) (pcommon.Value, error) {
 	return pcommon.NewValueEmpty(), fmt.Errorf("resource attribute %q not found", key)
 }
 
-// ScopeSpansByName filters scope spans matching the provided scope
-// name.
-func ScopeSpansByName(td ptrace.Traces, name string) []ptrace.ScopeSpans {
+// ScopeSpansByName filters scope spans matching any of the provided scope
+// names.
+func ScopeSpansByName(td ptrace.Traces, names ...string) []ptrace.ScopeSpans {
 	var result []ptrace.ScopeSpans
 	for _, rs := range ResourceSpans(td) {
 		scopes := rs.ScopeSpans()
 		for i := range scopes.Len() {
 			ss := scopes.At(i)
-			if ss.Scope().Name() == name {
+			if slices.Contains(names, ss.Scope().Name()) {
 				result = append(result, ss)
 			}
 		}
@@ -14,6 +14,9 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
 
 	"go.opentelemetry.io/auto/internal/pkg/structfield"
 	"go.opentelemetry.io/auto/internal/tools/inspect"
@@ -23,6 +26,125 @@ const (
 	defaultOutputFile = "offset_results.json"
 
 	minGoVersion = "1.19"
+	// minSlogGoVersion is the minimum Go version of the log/slog package
+	// instrumented, its first release.
+	minSlogGoVersion = "1.21"
+
+	// minMongoDriverVersion is the minimum version of the v1
+	// go.mongodb.org/mongo-driver module instrumented.
+	minMongoDriverVersion = "1.11.0"
+
+	// minShopifySaramaVersion is the minimum version of the
+	// github.com/Shopify/sarama module instrumented.
+	minShopifySaramaVersion = "1.21.0"
+	// ibmSaramaVersion is the first version of sarama published as the
+	// github.com/IBM/sarama module.
+	ibmSaramaVersion = "1.40.0"
+
+	// minGinVersion is the minimum version of the github.com/gin-gonic/gin
+	// module instrumented. It is the first version recording the route of a
+	// Context.
+	minGinVersion = "1.5.0"
+	// minEchoVersion is the minimum version of the
+	// github.com/labstack/echo/v4 module instrumented. It is the first
+	// version only recording the matched route in a context.
+	minEchoVersion = "4.10.1"
+	// minMuxVersion is the minimum version of the github.com/gorilla/mux
+	// module instrumented. It is the first version storing the path template
+	// of a route in its routeConf.
+	minMuxVersion = "1.7.0"
+	// minChiVersion is the minimum version of the github.com/go-chi/chi/v5
+	// module instrumented. Prior major versions are not instrumented.
+	minChiVersion = "5.0.0"
+	// minFasthttpVersion is the minimum version of the
+	// github.com/valyala/fasthttp module instrumented. It is the first
+	// version able to stream request bodies.
+	minFasthttpVersion = "1.20.0"
+	// minAWSSDKVersion and minSmithyVersion are the minimum versions of the
+	// github.com/aws/aws-sdk-go-v2 and github.com/aws/smithy-go modules
+	// instrumented. Prior major versions are not instrumented.
+	minAWSSDKVersion = "1.0.0"
+	minSmithyVersion = "1.0.0"
+	// gomemcacheVersion is the version of the
+	// github.com/bradfitz/gomemcache module the offsets are generated for.
+	// The module is not tagged, its latest pseudo-version is used.
+	gomemcacheVersion = "v0.0.0-20260422231931-4d751bb6e37c"
+	// minNATSVersion is the minimum version of the github.com/nats-io/nats.go
+	// module instrumented. It is the first version supporting headers.
+	minNATSVersion = "1.11.0"
+	// minAMQPVersion is the minimum version of the
+	// github.com/rabbitmq/amqp091-go module instrumented. It is the first
+	// version with the publishing methods accepting a context.Context.
+	minAMQPVersion = "1.4.0"
+	// minGqlgenVersion and minGqlparserVersion are the minimum versions of the
+	// github.com/99designs/gqlgen and github.com/vektah/gqlparser/v2 modules
+	// instrumented. The latter is the version required by the former.
+	minGqlgenVersion    = "0.17.0"
+	minGqlparserVersion = "2.4.0"
+	// minEtcdVersion is the minimum version of the go.etcd.io/etcd/api/v3
+	// module instrumented. It is released with the go.etcd.io/etcd/client/v3
+	// module of the same version.
+	minEtcdVersion = "3.5.0"
+	// minPubsubVersion is the minimum version of the
+	// cloud.google.com/go/pubsub module instrumented. It is the first version
+	// with messages defined in the cloud.google.com/go/internal/pubsub
+	// package. minGoogleCloudVersion is the version of the
+	// cloud.google.com/go module it requires.
+	minPubsubVersion      = "1.9.1"
+	minGoogleCloudVersion = "0.73.0"
+	// minConfluentKafkaVersion is the minimum version of the
+	// github.com/confluentinc/confluent-kafka-go/v2 module instrumented, its
+	// first release.
+	minConfluentKafkaVersion = "2.0.2"
+	// minGormVersion is the minimum version of the gorm.io/gorm module
+	// instrumented.
+	minGormVersion = "1.25.0"
+	// minClientGoVersion and minApimachineryVersion are the minimum versions
+	// of the k8s.io/client-go and k8s.io/apimachinery modules instrumented.
+	// They are released together.
+	minClientGoVersion     = "0.20.0"
+	minApimachineryVersion = "0.20.0"
+	// minRueidisVersion is the minimum version of the
+	// github.com/redis/rueidis module instrumented.
+	minRueidisVersion = "1.0.0"
+	// minValkeyVersion is the minimum version of the
+	// github.com/valkey-io/valkey-go module instrumented, its first release.
+	minValkeyVersion = "1.0.35"
+	// minClickHouseVersion is the minimum version of the
+	// github.com/ClickHouse/clickhouse-go/v2 module instrumented, its first
+	// release.
+	minClickHouseVersion = "2.0.1"
+	// minNATSJetStreamVersion is the minimum version of the
+	// github.com/nats-io/nats.go module whose jetstream package is
+	// instrumented.
+	minNATSJetStreamVersion = "1.26.0"
+	// minAsynqVersion is the minimum version of the github.com/hibiken/asynq
+	// module instrumented.
+	minAsynqVersion = "0.24.0"
+	// minTemporalVersion is the minimum version of the go.temporal.io/sdk
+	// module instrumented.
+	minTemporalVersion = "1.20.0"
+	// minBboltVersion is the minimum version of the go.etcd.io/bbolt module
+	// instrumented, its first release.
+	minBboltVersion = "1.3.0"
+	// minPahoMQTTVersion is the minimum version of the
+	// github.com/eclipse/paho.mqtt.golang module instrumented. It is the first
+	// version with tokens completed by setError.
+	minPahoMQTTVersion = "1.2.0"
+	// minPahoVersion is the minimum version of the github.com/eclipse/paho.golang
+	// module instrumented. It is the first version with user properties
+	// defined as a slice.
+	minPahoVersion = "0.10.0"
+	// minZapVersion is the minimum version of the go.uber.org/zap module
+	// instrumented.
+	minZapVersion = "1.21.0"
+	// minVaultVersion is the minimum version of the
+	// github.com/hashicorp/vault/api module instrumented. It is the first
+	// version with the LifetimeWatcher.
+	minVaultVersion = "1.1.0"
+	// minSpannerVersion is the minimum version of the
+	// cloud.google.com/go/spanner module instrumented.
+	minSpannerVersion = "1.25.0"
 )
 
 var (
@@ -55,6 +177,11 @@ func manifests() ([]inspect.Manifest, error) {
 		return nil, fmt.Errorf("failed to get Go versions: %w", err)
 	}
 
+	slogGoVers, err := GoVersions(">= " + minSlogGoVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log/slog Go versions: %w", err)
+	}
+
 	grpcVers, err := PkgVersions("google.golang.org/grpc")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get \"google.golang.org/grpc\" versions: %w", err)
@@ -65,6 +192,11 @@ func manifests() ([]inspect.Manifest, error) {
 		return nil, fmt.Errorf("failed to get \"golang.org/x/net\" versions: %w", err)
 	}
 
+	xCryptoVers, err := PkgVersions("golang.org/x/crypto")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"golang.org/x/crypto\" versions: %w", err)
+	}
+
 	goOtelVers, err := PkgVersions("go.opentelemetry.io/otel")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get \"go.opentelemetry.io/otel\" versions: %w", err)
@@ -75,6 +207,341 @@ func manifests() ([]inspect.Manifest, error) {
 		return nil, fmt.Errorf("failed to get \"github.com/segmentio/kafka-go\" versions: %w", err)
 	}
 
+	goRedisVers, err := PkgVersions("github.com/redis/go-redis/v9")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/redis/go-redis/v9\" versions: %w", err)
+	}
+
+	mongoMin := semver.MustParse(minMongoDriverVersion)
+	mongoVers, err := PkgVersions("go.mongodb.org/mongo-driver")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"go.mongodb.org/mongo-driver\" versions: %w", err)
+	}
+	mongoVers = slices.DeleteFunc(mongoVers, func(v *semver.Version) bool {
+		return v.LessThan(mongoMin)
+	})
+
+	mongoV2Vers, err := PkgVersions("go.mongodb.org/mongo-driver/v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"go.mongodb.org/mongo-driver/v2\" versions: %w", err)
+	}
+
+	pgxV5Vers, err := PkgVersions("github.com/jackc/pgx/v5")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/jackc/pgx/v5\" versions: %w", err)
+	}
+
+	pgxV4Vers, err := PkgVersions("github.com/jackc/pgx/v4")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/jackc/pgx/v4\" versions: %w", err)
+	}
+
+	pgconnVers, err := PkgVersions("github.com/jackc/pgconn")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/jackc/pgconn\" versions: %w", err)
+	}
+
+	shopifySaramaMin := semver.MustParse(minShopifySaramaVersion)
+	ibmSarama := semver.MustParse(ibmSaramaVersion)
+	shopifySaramaVers, err := PkgVersions("github.com/Shopify/sarama")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/Shopify/sarama\" versions: %w", err)
+	}
+	shopifySaramaVers = slices.DeleteFunc(shopifySaramaVers, func(v *semver.Version) bool {
+		return v.LessThan(shopifySaramaMin) || !v.LessThan(ibmSarama)
+	})
+
+	ibmSaramaVers, err := PkgVersions("github.com/IBM/sarama")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/IBM/sarama\" versions: %w", err)
+	}
+	ibmSaramaVers = slices.DeleteFunc(ibmSaramaVers, func(v *semver.Version) bool {
+		return v.LessThan(ibmSarama)
+	})
+
+	ginMin := semver.MustParse(minGinVersion)
+	ginVers, err := PkgVersions("github.com/gin-gonic/gin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/gin-gonic/gin\" versions: %w", err)
+	}
+	ginVers = slices.DeleteFunc(ginVers, func(v *semver.Version) bool {
+		return v.LessThan(ginMin)
+	})
+
+	echoMin := semver.MustParse(minEchoVersion)
+	echoVers, err := PkgVersions("github.com/labstack/echo/v4")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/labstack/echo/v4\" versions: %w", err)
+	}
+	echoVers = slices.DeleteFunc(echoVers, func(v *semver.Version) bool {
+		return v.LessThan(echoMin)
+	})
+
+	muxMin := semver.MustParse(minMuxVersion)
+	muxVers, err := PkgVersions("github.com/gorilla/mux")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/gorilla/mux\" versions: %w", err)
+	}
+	muxVers = slices.DeleteFunc(muxVers, func(v *semver.Version) bool {
+		return v.LessThan(muxMin)
+	})
+
+	chiMin := semver.MustParse(minChiVersion)
+	chiVers, err := PkgVersions("github.com/go-chi/chi/v5")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/go-chi/chi/v5\" versions: %w", err)
+	}
+	chiVers = slices.DeleteFunc(chiVers, func(v *semver.Version) bool {
+		return v.LessThan(chiMin)
+	})
+
+	fasthttpMin := semver.MustParse(minFasthttpVersion)
+	fasthttpVers, err := PkgVersions("github.com/valyala/fasthttp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/valyala/fasthttp\" versions: %w", err)
+	}
+	fasthttpVers = slices.DeleteFunc(fasthttpVers, func(v *semver.Version) bool {
+		return v.LessThan(fasthttpMin)
+	})
+
+	awsSDKMin := semver.MustParse(minAWSSDKVersion)
+	awsSDKVers, err := PkgVersions("github.com/aws/aws-sdk-go-v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/aws/aws-sdk-go-v2\" versions: %w", err)
+	}
+	awsSDKVers = slices.DeleteFunc(awsSDKVers, func(v *semver.Version) bool {
+		return v.LessThan(awsSDKMin)
+	})
+
+	smithyMin := semver.MustParse(minSmithyVersion)
+	smithyVers, err := PkgVersions("github.com/aws/smithy-go")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/aws/smithy-go\" versions: %w", err)
+	}
+	smithyVers = slices.DeleteFunc(smithyVers, func(v *semver.Version) bool {
+		return v.LessThan(smithyMin)
+	})
+
+	gomemcacheVers := []*semver.Version{semver.MustParse(gomemcacheVersion)}
+
+	natsMin := semver.MustParse(minNATSVersion)
+	natsVers, err := PkgVersions("github.com/nats-io/nats.go")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/nats-io/nats.go\" versions: %w", err)
+	}
+	natsVers = slices.DeleteFunc(natsVers, func(v *semver.Version) bool {
+		return v.LessThan(natsMin)
+	})
+
+	amqpMin := semver.MustParse(minAMQPVersion)
+	amqpVers, err := PkgVersions("github.com/rabbitmq/amqp091-go")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/rabbitmq/amqp091-go\" versions: %w", err)
+	}
+	amqpVers = slices.DeleteFunc(amqpVers, func(v *semver.Version) bool {
+		return v.LessThan(amqpMin)
+	})
+
+	gqlgenMin := semver.MustParse(minGqlgenVersion)
+	gqlgenVers, err := PkgVersions("github.com/99designs/gqlgen")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/99designs/gqlgen\" versions: %w", err)
+	}
+	gqlgenVers = slices.DeleteFunc(gqlgenVers, func(v *semver.Version) bool {
+		return v.LessThan(gqlgenMin)
+	})
+
+	gqlparserMin := semver.MustParse(minGqlparserVersion)
+	gqlparserVers, err := PkgVersions("github.com/vektah/gqlparser/v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/vektah/gqlparser/v2\" versions: %w", err)
+	}
+	gqlparserVers = slices.DeleteFunc(gqlparserVers, func(v *semver.Version) bool {
+		return v.LessThan(gqlparserMin)
+	})
+
+	gocqlVers, err := PkgVersions("github.com/gocql/gocql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/gocql/gocql\" versions: %w", err)
+	}
+
+	etcdMin := semver.MustParse(minEtcdVersion)
+	etcdVers, err := PkgVersions("go.etcd.io/etcd/api/v3")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"go.etcd.io/etcd/api/v3\" versions: %w", err)
+	}
+	etcdVers = slices.DeleteFunc(etcdVers, func(v *semver.Version) bool {
+		return v.LessThan(etcdMin)
+	})
+
+	pubsubMin := semver.MustParse(minPubsubVersion)
+	pubsubVers, err := PkgVersions("cloud.google.com/go/pubsub")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"cloud.google.com/go/pubsub\" versions: %w", err)
+	}
+	pubsubVers = slices.DeleteFunc(pubsubVers, func(v *semver.Version) bool {
+		return v.LessThan(pubsubMin)
+	})
+
+	googleCloudMin := semver.MustParse(minGoogleCloudVersion)
+	googleCloudVers, err := PkgVersions("cloud.google.com/go")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"cloud.google.com/go\" versions: %w", err)
+	}
+	googleCloudVers = slices.DeleteFunc(googleCloudVers, func(v *semver.Version) bool {
+		return v.LessThan(googleCloudMin)
+	})
+
+	confluentKafkaMin := semver.MustParse(minConfluentKafkaVersion)
+	confluentKafkaVers, err := PkgVersions("github.com/confluentinc/confluent-kafka-go/v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/confluentinc/confluent-kafka-go/v2\" versions: %w", err)
+	}
+	confluentKafkaVers = slices.DeleteFunc(confluentKafkaVers, func(v *semver.Version) bool {
+		return v.LessThan(confluentKafkaMin)
+	})
+
+	gormMin := semver.MustParse(minGormVersion)
+	gormVers, err := PkgVersions("gorm.io/gorm")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"gorm.io/gorm\" versions: %w", err)
+	}
+	gormVers = slices.DeleteFunc(gormVers, func(v *semver.Version) bool {
+		return v.LessThan(gormMin)
+	})
+
+	clientGoMin := semver.MustParse(minClientGoVersion)
+	clientGoVers, err := PkgVersions("k8s.io/client-go")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"k8s.io/client-go\" versions: %w", err)
+	}
+	clientGoVers = slices.DeleteFunc(clientGoVers, func(v *semver.Version) bool {
+		return v.LessThan(clientGoMin)
+	})
+
+	apimachineryMin := semver.MustParse(minApimachineryVersion)
+	apimachineryVers, err := PkgVersions("k8s.io/apimachinery")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"k8s.io/apimachinery\" versions: %w", err)
+	}
+	apimachineryVers = slices.DeleteFunc(apimachineryVers, func(v *semver.Version) bool {
+		return v.LessThan(apimachineryMin)
+	})
+
+	rueidisMin := semver.MustParse(minRueidisVersion)
+	rueidisVers, err := PkgVersions("github.com/redis/rueidis")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/redis/rueidis\" versions: %w", err)
+	}
+	rueidisVers = slices.DeleteFunc(rueidisVers, func(v *semver.Version) bool {
+		return v.LessThan(rueidisMin)
+	})
+
+	valkeyMin := semver.MustParse(minValkeyVersion)
+	valkeyVers, err := PkgVersions("github.com/valkey-io/valkey-go")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/valkey-io/valkey-go\" versions: %w", err)
+	}
+	valkeyVers = slices.DeleteFunc(valkeyVers, func(v *semver.Version) bool {
+		return v.LessThan(valkeyMin)
+	})
+
+	clickHouseMin := semver.MustParse(minClickHouseVersion)
+	clickHouseVers, err := PkgVersions("github.com/ClickHouse/clickhouse-go/v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/ClickHouse/clickhouse-go/v2\" versions: %w", err)
+	}
+	clickHouseVers = slices.DeleteFunc(clickHouseVers, func(v *semver.Version) bool {
+		return v.LessThan(clickHouseMin)
+	})
+
+	jetStreamMin := semver.MustParse(minNATSJetStreamVersion)
+	jetStreamVers := slices.DeleteFunc(slices.Clone(natsVers), func(v *semver.Version) bool {
+		return v.LessThan(jetStreamMin)
+	})
+
+	asynqMin := semver.MustParse(minAsynqVersion)
+	asynqVers, err := PkgVersions("github.com/hibiken/asynq")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/hibiken/asynq\" versions: %w", err)
+	}
+	asynqVers = slices.DeleteFunc(asynqVers, func(v *semver.Version) bool {
+		return v.LessThan(asynqMin)
+	})
+
+	temporalMin := semver.MustParse(minTemporalVersion)
+	temporalVers, err := PkgVersions("go.temporal.io/sdk")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"go.temporal.io/sdk\" versions: %w", err)
+	}
+	temporalVers = slices.DeleteFunc(temporalVers, func(v *semver.Version) bool {
+		return v.LessThan(temporalMin)
+	})
+
+	influxDBVers, err := PkgVersions("github.com/influxdata/influxdb-client-go/v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/influxdata/influxdb-client-go/v2\" versions: %w", err)
+	}
+
+	bboltMin := semver.MustParse(minBboltVersion)
+	bboltVers, err := PkgVersions("go.etcd.io/bbolt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"go.etcd.io/bbolt\" versions: %w", err)
+	}
+	bboltVers = slices.DeleteFunc(bboltVers, func(v *semver.Version) bool {
+		return v.LessThan(bboltMin)
+	})
+
+	badgerVers, err := PkgVersions("github.com/dgraph-io/badger/v4")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/dgraph-io/badger/v4\" versions: %w", err)
+	}
+
+	pahoMQTTMin := semver.MustParse(minPahoMQTTVersion)
+	pahoMQTTVers, err := PkgVersions("github.com/eclipse/paho.mqtt.golang")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/eclipse/paho.mqtt.golang\" versions: %w", err)
+	}
+	pahoMQTTVers = slices.DeleteFunc(pahoMQTTVers, func(v *semver.Version) bool {
+		return v.LessThan(pahoMQTTMin)
+	})
+
+	pahoMin := semver.MustParse(minPahoVersion)
+	pahoVers, err := PkgVersions("github.com/eclipse/paho.golang")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/eclipse/paho.golang\" versions: %w", err)
+	}
+	pahoVers = slices.DeleteFunc(pahoVers, func(v *semver.Version) bool {
+		return v.LessThan(pahoMin)
+	})
+
+	zapMin := semver.MustParse(minZapVersion)
+	zapVers, err := PkgVersions("go.uber.org/zap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"go.uber.org/zap\" versions: %w", err)
+	}
+	zapVers = slices.DeleteFunc(zapVers, func(v *semver.Version) bool {
+		return v.LessThan(zapMin)
+	})
+
+	vaultMin := semver.MustParse(minVaultVersion)
+	vaultVers, err := PkgVersions("github.com/hashicorp/vault/api")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"github.com/hashicorp/vault/api\" versions: %w", err)
+	}
+	vaultVers = slices.DeleteFunc(vaultVers, func(v *semver.Version) bool {
+		return v.LessThan(vaultMin)
+	})
+
+	spannerMin := semver.MustParse(minSpannerVersion)
+	spannerVers, err := PkgVersions("cloud.google.com/go/spanner")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get \"cloud.google.com/go/spanner\" versions: %w", err)
+	}
+	spannerVers = slices.DeleteFunc(spannerVers, func(v *semver.Version) bool {
+		return v.LessThan(spannerMin)
+	})
+
 	ren := func(src string) inspect.Renderer {
 		return inspect.NewRenderer(logger, src, inspect.DefaultFS)
 	}
@@ -124,8 +591,63 @@ func manifests() ([]inspect.Manifest, error) {
 				structfield.NewID("std", "net/url", "Userinfo", "username"),
 				structfield.NewID("std", "bufio", "Writer", "buf"),
 				structfield.NewID("std", "bufio", "Writer", "n"),
+				structfield.NewID("std", "net", "DNSError", "Err"),
+				structfield.NewID("std", "net", "DNSError", "IsTimeout"),
+				structfield.NewID("std", "net", "DNSError", "IsNotFound"),
 				structfield.NewID("std", "net", "TCPAddr", "IP"),
 				structfield.NewID("std", "net", "TCPAddr", "Port"),
+				structfield.NewID("std", "net", "TCPAddr", "Zone"),
+				structfield.NewID("std", "net", "UnixAddr", "Name"),
+				structfield.NewID("std", "net", "conn", "fd"),
+				structfield.NewID("std", "net", "netFD", "raddr"),
+				structfield.NewID("std", "crypto/tls", "Conn", "conn"),
+				structfield.NewID("std", "crypto/tls", "Conn", "vers"),
+				structfield.NewID("std", "crypto/tls", "Conn", "config"),
+				structfield.NewID("std", "crypto/tls", "Conn", "cipherSuite"),
+				structfield.NewID("std", "crypto/tls", "Config", "ServerName"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer:  ren("templates/net/rpc/*.tmpl"),
+				GoVerions: goVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("std", "net/rpc", "Request", "ServiceMethod"),
+				structfield.NewID("std", "net/rpc", "Call", "ServiceMethod"),
+				structfield.NewID("std", "net/rpc", "Call", "Error"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer:  ren("templates/net/smtp/*.tmpl"),
+				GoVerions: goVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("std", "net/smtp", "Client", "serverName"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer:  ren("templates/database/sql/*.tmpl"),
+				GoVerions: goVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("std", "database/sql", "Tx", "dc"),
+				structfield.NewID("std", "database/sql", "TxOptions", "Isolation"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer:  ren("templates/log/slog/*.tmpl"),
+				GoVerions: slogGoVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("std", "log/slog", "Record", "Message"),
+				structfield.NewID("std", "log/slog", "Record", "Level"),
+				structfield.NewID("std", "log/slog", "Record", "front"),
+				structfield.NewID("std", "log/slog", "Record", "nFront"),
+				structfield.NewID("std", "log/slog", "Record", "back"),
 			},
 		},
 		{
@@ -164,6 +686,12 @@ func manifests() ([]inspect.Manifest, error) {
 					"ClientConn",
 					"target",
 				),
+				structfield.NewID(
+					"google.golang.org/grpc",
+					"google.golang.org/grpc",
+					"serverStream",
+					"s",
+				),
 				structfield.NewID(
 					"google.golang.org/grpc",
 					"google.golang.org/grpc/internal/transport",
@@ -212,6 +740,12 @@ func manifests() ([]inspect.Manifest, error) {
 					"http2Server",
 					"peer",
 				),
+				structfield.NewID(
+					"google.golang.org/grpc",
+					"google.golang.org/grpc/peer",
+					"Peer",
+					"Addr",
+				),
 				structfield.NewID(
 					"google.golang.org/grpc",
 					"google.golang.org/grpc/peer",
@@ -240,6 +774,20 @@ func manifests() ([]inspect.Manifest, error) {
 				),
 			},
 		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/golang.org/x/crypto/*.tmpl"),
+				Versions: xCryptoVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"golang.org/x/crypto",
+					"golang.org/x/crypto/ssh",
+					"ClientConfig",
+					"User",
+				),
+			},
+		},
 		{
 			Application: inspect.Application{
 				Renderer: ren("templates/go.opentelemetry.io/otel/traceglobal/*.tmpl"),
@@ -356,6 +904,1079 @@ func manifests() ([]inspect.Manifest, error) {
 					"Conn",
 					"clientID",
 				),
+				structfield.NewID(
+					"github.com/segmentio/kafka-go",
+					"github.com/segmentio/kafka-go",
+					"partitionWriter",
+					"meta",
+				),
+				structfield.NewID(
+					"github.com/segmentio/kafka-go",
+					"github.com/segmentio/kafka-go",
+					"topicPartition",
+					"partition",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/IBM/sarama/*.tmpl"),
+				Versions: ibmSaramaVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ProducerMessage",
+					"Topic",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ProducerMessage",
+					"Key",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ProducerMessage",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ProducerMessage",
+					"Partition",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ConsumerMessage",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ConsumerMessage",
+					"Key",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ConsumerMessage",
+					"Topic",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ConsumerMessage",
+					"Partition",
+				),
+				structfield.NewID(
+					"github.com/IBM/sarama",
+					"github.com/IBM/sarama",
+					"ConsumerMessage",
+					"Offset",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/Shopify/sarama/*.tmpl"),
+				Versions: shopifySaramaVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ProducerMessage",
+					"Topic",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ProducerMessage",
+					"Key",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ProducerMessage",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ProducerMessage",
+					"Partition",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ConsumerMessage",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ConsumerMessage",
+					"Key",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ConsumerMessage",
+					"Topic",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ConsumerMessage",
+					"Partition",
+				),
+				structfield.NewID(
+					"github.com/Shopify/sarama",
+					"github.com/Shopify/sarama",
+					"ConsumerMessage",
+					"Offset",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/redis/go-redis/*.tmpl"),
+				Versions: goRedisVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/redis/go-redis/v9",
+					"github.com/redis/go-redis/v9",
+					"baseClient",
+					"opt",
+				),
+				structfield.NewID(
+					"github.com/redis/go-redis/v9",
+					"github.com/redis/go-redis/v9",
+					"Options",
+					"Addr",
+				),
+				structfield.NewID(
+					"github.com/redis/go-redis/v9",
+					"github.com/redis/go-redis/v9",
+					"baseCmd",
+					"args",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/go.mongodb.org/mongo-driver/*.tmpl"),
+				Versions: mongoVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"go.mongodb.org/mongo-driver",
+					"go.mongodb.org/mongo-driver/x/mongo/driver",
+					"Operation",
+					"Database",
+				),
+				structfield.NewID(
+					"go.mongodb.org/mongo-driver",
+					"go.mongodb.org/mongo-driver/x/mongo/driver",
+					"Operation",
+					"Name",
+				),
+				structfield.NewID(
+					"go.mongodb.org/mongo-driver",
+					"go.mongodb.org/mongo-driver/x/mongo/driver/topology",
+					"connection",
+					"addr",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/go.mongodb.org/mongo-driver/v2/*.tmpl"),
+				Versions: mongoV2Vers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"go.mongodb.org/mongo-driver/v2",
+					"go.mongodb.org/mongo-driver/v2/x/mongo/driver",
+					"Operation",
+					"Database",
+				),
+				structfield.NewID(
+					"go.mongodb.org/mongo-driver/v2",
+					"go.mongodb.org/mongo-driver/v2/x/mongo/driver",
+					"Operation",
+					"Name",
+				),
+				structfield.NewID(
+					"go.mongodb.org/mongo-driver/v2",
+					"go.mongodb.org/mongo-driver/v2/x/mongo/driver/topology",
+					"connection",
+					"addr",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/jackc/pgx/v5/*.tmpl"),
+				Versions: pgxV5Vers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/jackc/pgx/v5",
+					"github.com/jackc/pgx/v5",
+					"Conn",
+					"pgConn",
+				),
+				structfield.NewID(
+					"github.com/jackc/pgx/v5",
+					"github.com/jackc/pgx/v5/pgconn",
+					"PgConn",
+					"config",
+				),
+				structfield.NewID(
+					"github.com/jackc/pgx/v5",
+					"github.com/jackc/pgx/v5/pgconn",
+					"Config",
+					"Host",
+				),
+				structfield.NewID(
+					"github.com/jackc/pgx/v5",
+					"github.com/jackc/pgx/v5/pgconn",
+					"Config",
+					"Port",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/jackc/pgx/v4/*.tmpl"),
+				Versions: pgxV4Vers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/jackc/pgx/v4",
+					"github.com/jackc/pgx/v4",
+					"Conn",
+					"pgConn",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/jackc/pgconn/*.tmpl"),
+				Versions: pgconnVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/jackc/pgconn",
+					"github.com/jackc/pgconn",
+					"PgConn",
+					"config",
+				),
+				structfield.NewID(
+					"github.com/jackc/pgconn",
+					"github.com/jackc/pgconn",
+					"Config",
+					"Host",
+				),
+				structfield.NewID(
+					"github.com/jackc/pgconn",
+					"github.com/jackc/pgconn",
+					"Config",
+					"Port",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/gin-gonic/gin/*.tmpl"),
+				Versions: ginVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/gin-gonic/gin",
+					"github.com/gin-gonic/gin",
+					"Context",
+					"fullPath",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/labstack/echo/v4/*.tmpl"),
+				Versions: echoVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/labstack/echo/v4",
+					"github.com/labstack/echo/v4",
+					"context",
+					"path",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/gorilla/mux/*.tmpl"),
+				Versions: muxVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/gorilla/mux",
+					"github.com/gorilla/mux",
+					"RouteMatch",
+					"Route",
+				),
+				structfield.NewID(
+					"github.com/gorilla/mux",
+					"github.com/gorilla/mux",
+					"RouteMatch",
+					"MatchErr",
+				),
+				structfield.NewID(
+					"github.com/gorilla/mux",
+					"github.com/gorilla/mux",
+					"Route",
+					"routeConf",
+				),
+				structfield.NewID(
+					"github.com/gorilla/mux",
+					"github.com/gorilla/mux",
+					"routeConf",
+					"regexp",
+				),
+				structfield.NewID(
+					"github.com/gorilla/mux",
+					"github.com/gorilla/mux",
+					"routeRegexpGroup",
+					"path",
+				),
+				structfield.NewID(
+					"github.com/gorilla/mux",
+					"github.com/gorilla/mux",
+					"routeRegexp",
+					"template",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/go-chi/chi/v5/*.tmpl"),
+				Versions: chiVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/go-chi/chi/v5",
+					"github.com/go-chi/chi/v5",
+					"Context",
+					"RoutePatterns",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/valyala/fasthttp/*.tmpl"),
+				Versions: fasthttpVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestCtx",
+					"Request",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestCtx",
+					"Response",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestCtx",
+					"c",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"Request",
+					"Header",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"Response",
+					"Header",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestHeader",
+					"method",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestHeader",
+					"requestURI",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestHeader",
+					"host",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestHeader",
+					"h",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"RequestHeader",
+					"header",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"header",
+					"h",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"ResponseHeader",
+					"statusCode",
+				),
+				structfield.NewID(
+					"github.com/valyala/fasthttp",
+					"github.com/valyala/fasthttp",
+					"HostClient",
+					"IsTLS",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/aws/aws-sdk-go-v2/*.tmpl"),
+				Versions: awsSDKVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/aws/aws-sdk-go-v2",
+					"github.com/aws/aws-sdk-go-v2/aws/middleware",
+					"RegisterServiceMetadata",
+					"ServiceID",
+				),
+				structfield.NewID(
+					"github.com/aws/aws-sdk-go-v2",
+					"github.com/aws/aws-sdk-go-v2/aws/middleware",
+					"RegisterServiceMetadata",
+					"Region",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/aws/smithy-go/*.tmpl"),
+				Versions: smithyVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/aws/smithy-go",
+					"github.com/aws/smithy-go/middleware",
+					"Stack",
+					"id",
+				),
+				structfield.NewID(
+					"github.com/aws/smithy-go",
+					"github.com/aws/smithy-go/transport/http",
+					"Response",
+					"Response",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/bradfitz/gomemcache/*.tmpl"),
+				Versions: gomemcacheVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/bradfitz/gomemcache",
+					"github.com/bradfitz/gomemcache/memcache",
+					"staticAddr",
+					"str",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/nats-io/nats.go/*.tmpl"),
+				Versions: natsVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"Conn",
+					"info",
+				),
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"Conn",
+					"ps",
+				),
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"parseState",
+					"ma",
+				),
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"msgArg",
+					"subject",
+				),
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"msgArg",
+					"hdr",
+				),
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"serverInfo",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/nats-io/nats.go",
+					"github.com/nats-io/nats.go",
+					"ServerInfo",
+					"Headers",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/rabbitmq/amqp091-go/*.tmpl"),
+				Versions: amqpVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/rabbitmq/amqp091-go",
+					"github.com/rabbitmq/amqp091-go",
+					"headerFrame",
+					"Size",
+				),
+				structfield.NewID(
+					"github.com/rabbitmq/amqp091-go",
+					"github.com/rabbitmq/amqp091-go",
+					"Delivery",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/rabbitmq/amqp091-go",
+					"github.com/rabbitmq/amqp091-go",
+					"Delivery",
+					"DeliveryTag",
+				),
+				structfield.NewID(
+					"github.com/rabbitmq/amqp091-go",
+					"github.com/rabbitmq/amqp091-go",
+					"Delivery",
+					"Exchange",
+				),
+				structfield.NewID(
+					"github.com/rabbitmq/amqp091-go",
+					"github.com/rabbitmq/amqp091-go",
+					"Delivery",
+					"RoutingKey",
+				),
+				structfield.NewID(
+					"github.com/rabbitmq/amqp091-go",
+					"github.com/rabbitmq/amqp091-go",
+					"Delivery",
+					"Body",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/99designs/gqlgen/*.tmpl"),
+				Versions: gqlgenVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/99designs/gqlgen",
+					"github.com/99designs/gqlgen/graphql",
+					"OperationContext",
+					"RawQuery",
+				),
+				structfield.NewID(
+					"github.com/99designs/gqlgen",
+					"github.com/99designs/gqlgen/graphql",
+					"OperationContext",
+					"OperationName",
+				),
+				structfield.NewID(
+					"github.com/99designs/gqlgen",
+					"github.com/99designs/gqlgen/graphql",
+					"OperationContext",
+					"Operation",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/vektah/gqlparser/v2/*.tmpl"),
+				Versions: gqlparserVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/vektah/gqlparser/v2",
+					"github.com/vektah/gqlparser/v2/ast",
+					"OperationDefinition",
+					"Operation",
+				),
+				structfield.NewID(
+					"github.com/vektah/gqlparser/v2",
+					"github.com/vektah/gqlparser/v2/ast",
+					"OperationDefinition",
+					"Name",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/gocql/gocql/*.tmpl"),
+				Versions: gocqlVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Query",
+					"stmt",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Query",
+					"context",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Batch",
+					"Entries",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Batch",
+					"context",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Batch",
+					"keyspace",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Session",
+					"cfg",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"ClusterConfig",
+					"Keyspace",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Iter",
+					"err",
+				),
+				structfield.NewID(
+					"github.com/gocql/gocql",
+					"github.com/gocql/gocql",
+					"Conn",
+					"addr",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/go.etcd.io/etcd/api/v3/*.tmpl"),
+				Versions: etcdVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"go.etcd.io/etcd/api/v3",
+					"go.etcd.io/etcd/api/v3/etcdserverpb",
+					"RangeRequest",
+					"Key",
+				),
+				structfield.NewID(
+					"go.etcd.io/etcd/api/v3",
+					"go.etcd.io/etcd/api/v3/etcdserverpb",
+					"RangeRequest",
+					"RangeEnd",
+				),
+				structfield.NewID(
+					"go.etcd.io/etcd/api/v3",
+					"go.etcd.io/etcd/api/v3/etcdserverpb",
+					"PutRequest",
+					"Key",
+				),
+				structfield.NewID(
+					"go.etcd.io/etcd/api/v3",
+					"go.etcd.io/etcd/api/v3/etcdserverpb",
+					"DeleteRangeRequest",
+					"Key",
+				),
+				structfield.NewID(
+					"go.etcd.io/etcd/api/v3",
+					"go.etcd.io/etcd/api/v3/etcdserverpb",
+					"DeleteRangeRequest",
+					"RangeEnd",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/cloud.google.com/go/pubsub/*.tmpl"),
+				Versions: pubsubVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"cloud.google.com/go/pubsub",
+					"cloud.google.com/go/pubsub",
+					"Topic",
+					"name",
+				),
+				structfield.NewID(
+					"cloud.google.com/go/pubsub",
+					"cloud.google.com/go/pubsub",
+					"bundledMessage",
+					"res",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/cloud.google.com/go/*.tmpl"),
+				Versions: googleCloudVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"cloud.google.com/go",
+					"cloud.google.com/go/internal/pubsub",
+					"Message",
+					"ID",
+				),
+				structfield.NewID(
+					"cloud.google.com/go",
+					"cloud.google.com/go/internal/pubsub",
+					"Message",
+					"Data",
+				),
+				structfield.NewID(
+					"cloud.google.com/go",
+					"cloud.google.com/go/internal/pubsub",
+					"Message",
+					"Attributes",
+				),
+				structfield.NewID(
+					"cloud.google.com/go",
+					"cloud.google.com/go/internal/pubsub",
+					"Message",
+					"OrderingKey",
+				),
+				structfield.NewID(
+					"cloud.google.com/go",
+					"cloud.google.com/go/internal/pubsub",
+					"PublishResult",
+					"err",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/confluentinc/confluent-kafka-go/v2/*.tmpl"),
+				Versions: confluentKafkaVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID(
+					"github.com/confluentinc/confluent-kafka-go/v2",
+					"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+					"Message",
+					"Key",
+				),
+				structfield.NewID(
+					"github.com/confluentinc/confluent-kafka-go/v2",
+					"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+					"Message",
+					"Headers",
+				),
+				structfield.NewID(
+					"github.com/confluentinc/confluent-kafka-go/v2",
+					"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+					"TopicPartition",
+					"Topic",
+				),
+				structfield.NewID(
+					"github.com/confluentinc/confluent-kafka-go/v2",
+					"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+					"TopicPartition",
+					"Partition",
+				),
+				structfield.NewID(
+					"github.com/confluentinc/confluent-kafka-go/v2",
+					"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+					"TopicPartition",
+					"Offset",
+				),
+				structfield.NewID(
+					"github.com/confluentinc/confluent-kafka-go/v2",
+					"github.com/confluentinc/confluent-kafka-go/v2/kafka",
+					"TopicPartition",
+					"Error",
+				),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/gorm.io/gorm/*.tmpl"),
+				Versions: gormVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("gorm.io/gorm", "gorm.io/gorm", "DB", "RowsAffected"),
+				structfield.NewID("gorm.io/gorm", "gorm.io/gorm", "DB", "Statement"),
+				structfield.NewID("gorm.io/gorm", "gorm.io/gorm", "Statement", "Table"),
+				structfield.NewID("gorm.io/gorm", "gorm.io/gorm", "Statement", "SQL"),
+				structfield.NewID("gorm.io/gorm", "gorm.io/gorm", "processor", "Clauses"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/k8s.io/client-go/*.tmpl"),
+				Versions: clientGoVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("k8s.io/client-go", "k8s.io/client-go/rest", "Request", "verb"),
+				structfield.NewID("k8s.io/client-go", "k8s.io/client-go/rest", "Request", "namespace"),
+				structfield.NewID("k8s.io/client-go", "k8s.io/client-go/rest", "Request", "resource"),
+				structfield.NewID("k8s.io/client-go", "k8s.io/client-go/rest", "Request", "resourceName"),
+				structfield.NewID("k8s.io/client-go", "k8s.io/client-go/rest", "Request", "subresource"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/k8s.io/apimachinery/*.tmpl"),
+				Versions: apimachineryVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("k8s.io/apimachinery", "k8s.io/apimachinery/pkg/watch", "StreamWatcher", "source"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/redis/rueidis/*.tmpl"),
+				Versions: rueidisVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/redis/rueidis", "github.com/redis/rueidis", "pipe", "conn"),
+				structfield.NewID("github.com/redis/rueidis", "github.com/redis/rueidis/internal/cmds", "CommandSlice", "s"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/valkey-io/valkey-go/*.tmpl"),
+				Versions: valkeyVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/valkey-io/valkey-go", "github.com/valkey-io/valkey-go", "pipe", "conn"),
+				structfield.NewID("github.com/valkey-io/valkey-go", "github.com/valkey-io/valkey-go/internal/cmds", "CommandSlice", "s"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/ClickHouse/clickhouse-go/v2/*.tmpl"),
+				Versions: clickHouseVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "connect", "opt"),
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "connect", "conn"),
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "Options", "Auth"),
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "Auth", "Database"),
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "batch", "ctx"),
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "batch", "conn"),
+				structfield.NewID("github.com/ClickHouse/clickhouse-go/v2", "github.com/ClickHouse/clickhouse-go/v2", "batch", "query"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/nats-io/nats.go/jetstream/*.tmpl"),
+				Versions: jetStreamVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/nats-io/nats.go", "github.com/nats-io/nats.go", "Msg", "Subject"),
+				structfield.NewID("github.com/nats-io/nats.go", "github.com/nats-io/nats.go", "Msg", "Reply"),
+				structfield.NewID("github.com/nats-io/nats.go", "github.com/nats-io/nats.go", "Msg", "Header"),
+				structfield.NewID("github.com/nats-io/nats.go", "github.com/nats-io/nats.go", "Msg", "Data"),
+				structfield.NewID("github.com/nats-io/nats.go", "github.com/nats-io/nats.go/jetstream", "jetStreamMsg", "msg"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/hibiken/asynq/*.tmpl"),
+				Versions: asynqVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq", "Task", "typename"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq", "Task", "payload"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq", "Task", "w"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq", "ResultWriter", "id"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq", "ResultWriter", "qname"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq/internal/base", "TaskMessage", "ID"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq/internal/base", "TaskMessage", "Retry"),
+				structfield.NewID("github.com/hibiken/asynq", "github.com/hibiken/asynq/internal/base", "TaskMessage", "Retried"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/go.temporal.io/sdk/*.tmpl"),
+				Versions: temporalVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientExecuteWorkflowInput", "Options"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientExecuteWorkflowInput", "WorkflowType"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientSignalWorkflowInput", "WorkflowID"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientSignalWorkflowInput", "RunID"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientSignalWorkflowInput", "SignalName"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientSignalWithStartWorkflowInput", "SignalName"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientSignalWithStartWorkflowInput", "Options"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "ClientSignalWithStartWorkflowInput", "WorkflowType"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "StartWorkflowOptions", "ID"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "StartWorkflowOptions", "TaskQueue"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "workflowRunImpl", "workflowID"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "workflowRunImpl", "firstRunID"),
+				structfield.NewID("go.temporal.io/sdk", "go.temporal.io/sdk/internal", "workflowRunImpl", "currentRunID"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/influxdata/influxdb-client-go/v2/*.tmpl"),
+				Versions: influxDBVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/influxdata/influxdb-client-go/v2", "github.com/influxdata/influxdb-client-go/v2/internal/write", "Service", "org"),
+				structfield.NewID("github.com/influxdata/influxdb-client-go/v2", "github.com/influxdata/influxdb-client-go/v2/internal/write", "Service", "bucket"),
+				structfield.NewID("github.com/influxdata/influxdb-client-go/v2", "github.com/influxdata/influxdb-client-go/v2/api", "WriteAPIImpl", "writeBuffer"),
+				structfield.NewID("github.com/influxdata/influxdb-client-go/v2", "github.com/influxdata/influxdb-client-go/v2/api", "queryAPI", "org"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/go.etcd.io/bbolt/*.tmpl"),
+				Versions: bboltVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("go.etcd.io/bbolt", "go.etcd.io/bbolt", "DB", "path"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/dgraph-io/badger/v4/*.tmpl"),
+				Versions: badgerVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/dgraph-io/badger/v4", "github.com/dgraph-io/badger/v4", "Txn", "size"),
+				structfield.NewID("github.com/dgraph-io/badger/v4", "github.com/dgraph-io/badger/v4", "Txn", "count"),
+				structfield.NewID("github.com/dgraph-io/badger/v4", "github.com/dgraph-io/badger/v4", "Entry", "Key"),
+				structfield.NewID("github.com/dgraph-io/badger/v4", "github.com/dgraph-io/badger/v4", "Entry", "Value"),
+				structfield.NewID("github.com/dgraph-io/badger/v4", "github.com/dgraph-io/badger/v4", "Entry", "meta"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/eclipse/paho.mqtt.golang/*.tmpl"),
+				Versions: pahoMQTTVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang", "baseToken", "err"),
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang", "message", "payload"),
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang/packets", "PublishPacket", "FixedHeader"),
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang/packets", "PublishPacket", "TopicName"),
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang/packets", "PublishPacket", "Payload"),
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang/packets", "FixedHeader", "Qos"),
+				structfield.NewID("github.com/eclipse/paho.mqtt.golang", "github.com/eclipse/paho.mqtt.golang/packets", "FixedHeader", "Retain"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/eclipse/paho.golang/*.tmpl"),
+				Versions: pahoVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/eclipse/paho.golang", "github.com/eclipse/paho.golang/paho", "Publish", "QoS"),
+				structfield.NewID("github.com/eclipse/paho.golang", "github.com/eclipse/paho.golang/paho", "Publish", "Retain"),
+				structfield.NewID("github.com/eclipse/paho.golang", "github.com/eclipse/paho.golang/paho", "Publish", "Topic"),
+				structfield.NewID("github.com/eclipse/paho.golang", "github.com/eclipse/paho.golang/paho", "Publish", "Properties"),
+				structfield.NewID("github.com/eclipse/paho.golang", "github.com/eclipse/paho.golang/paho", "Publish", "Payload"),
+				structfield.NewID("github.com/eclipse/paho.golang", "github.com/eclipse/paho.golang/paho", "PublishProperties", "User"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/go.uber.org/zap/*.tmpl"),
+				Versions: zapVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "Entry", "Level"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "Entry", "LoggerName"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "Entry", "Message"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "Entry", "Caller"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "EntryCaller", "Defined"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "EntryCaller", "File"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "EntryCaller", "Line"),
+				structfield.NewID("go.uber.org/zap", "go.uber.org/zap/zapcore", "EntryCaller", "Function"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/github.com/hashicorp/vault/api/*.tmpl"),
+				Versions: vaultVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("github.com/hashicorp/vault/api", "github.com/hashicorp/vault/api", "Request", "Method"),
+				structfield.NewID("github.com/hashicorp/vault/api", "github.com/hashicorp/vault/api", "Request", "URL"),
+				structfield.NewID("github.com/hashicorp/vault/api", "github.com/hashicorp/vault/api", "Response", "Response"),
+			},
+		},
+		{
+			Application: inspect.Application{
+				Renderer: ren("templates/cloud.google.com/go/spanner/*.tmpl"),
+				Versions: spannerVers,
+			},
+			StructFields: []structfield.ID{
+				structfield.NewID("cloud.google.com/go/spanner", "cloud.google.com/go/spanner", "ReadOnlyTransaction", "txReadOnly"),
 			},
 		},
 	}, nil
@@ -17,11 +17,55 @@ import (
 // embedded.
 //
 //go:embed templates/golang.org/x/net/*.tmpl
+//go:embed templates/golang.org/x/crypto/*.tmpl
 //go:embed templates/google.golang.org/grpc/*.tmpl
 //go:embed templates/net/http/*.tmpl
+//go:embed templates/net/rpc/*.tmpl
+//go:embed templates/database/sql/*.tmpl
+//go:embed templates/log/slog/*.tmpl
 //go:embed templates/runtime/*.tmpl
 //go:embed templates/go.opentelemetry.io/otel/traceglobal/*.tmpl
 //go:embed templates/github.com/segmentio/kafka-go/*.tmpl
+//go:embed templates/github.com/IBM/sarama/*.tmpl
+//go:embed templates/github.com/Shopify/sarama/*.tmpl
+//go:embed templates/github.com/redis/go-redis/*.tmpl
+//go:embed templates/go.mongodb.org/mongo-driver/*.tmpl
+//go:embed templates/go.mongodb.org/mongo-driver/v2/*.tmpl
+//go:embed templates/github.com/jackc/pgx/v5/*.tmpl
+//go:embed templates/github.com/jackc/pgx/v4/*.tmpl
+//go:embed templates/github.com/jackc/pgconn/*.tmpl
+//go:embed templates/github.com/gin-gonic/gin/*.tmpl
+//go:embed templates/github.com/labstack/echo/v4/*.tmpl
+//go:embed templates/github.com/gorilla/mux/*.tmpl
+//go:embed templates/github.com/go-chi/chi/v5/*.tmpl
+//go:embed templates/github.com/valyala/fasthttp/*.tmpl
+//go:embed templates/github.com/aws/aws-sdk-go-v2/*.tmpl
+//go:embed templates/github.com/aws/smithy-go/*.tmpl
+//go:embed templates/github.com/bradfitz/gomemcache/*.tmpl
+//go:embed templates/github.com/nats-io/nats.go/*.tmpl
+//go:embed templates/github.com/nats-io/nats.go/jetstream/*.tmpl
+//go:embed templates/github.com/rabbitmq/amqp091-go/*.tmpl
+//go:embed templates/github.com/99designs/gqlgen/*.tmpl
+//go:embed templates/github.com/vektah/gqlparser/v2/*.tmpl
+//go:embed templates/github.com/gocql/gocql/*.tmpl
+//go:embed templates/go.etcd.io/etcd/api/v3/*.tmpl
+//go:embed templates/cloud.google.com/go/pubsub/*.tmpl
+//go:embed templates/cloud.google.com/go/*.tmpl
+//go:embed templates/github.com/confluentinc/confluent-kafka-go/v2/*.tmpl
+//go:embed templates/gorm.io/gorm/*.tmpl
+//go:embed templates/k8s.io/client-go/*.tmpl
+//go:embed templates/k8s.io/apimachinery/*.tmpl
+//go:embed templates/github.com/redis/rueidis/*.tmpl
+//go:embed templates/github.com/valkey-io/valkey-go/*.tmpl
+//go:embed templates/github.com/ClickHouse/clickhouse-go/v2/*.tmpl
+//go:embed templates/github.com/hibiken/asynq/*.tmpl
+//go:embed templates/go.temporal.io/sdk/*.tmpl
+//go:embed templates/github.com/influxdata/influxdb-client-go/v2/*.tmpl
+//go:embed templates/go.etcd.io/bbolt/*.tmpl
+//go:embed templates/github.com/dgraph-io/badger/v4/*.tmpl
+//go:embed templates/github.com/eclipse/paho.mqtt.golang/*.tmpl
+//go:embed templates/github.com/eclipse/paho.golang/*.tmpl
+//go:embed templates/go.uber.org/zap/*.tmpl
 var DefaultFS embed.FS
 
 // Renderer renders templates from an fs.FS.
@@ -0,0 +1,499 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// ErrExporterAuth is returned, wrapped, by the trace exporter when it fails to
+// authenticate with the collector. Either a token could not be obtained, or
+// the collector rejected it.
+var ErrExporterAuth = errors.New("exporter authentication failed")
+
+const (
+	// envAuthTokenKey is the key for the environment variable value
+	// containing the static bearer token of the OTLP trace exporter.
+	envAuthTokenKey = "OTEL_GO_AUTO_EXPORTER_AUTH_TOKEN"
+	// envAuthTokenFileKey is the key for the environment variable value
+	// containing the path of the file holding the bearer token of the OTLP
+	// trace exporter.
+	envAuthTokenFileKey = "OTEL_GO_AUTO_EXPORTER_AUTH_TOKEN_FILE"
+	// envOAuth2TokenURLKey is the key for the environment variable value
+	// containing the OAuth2 token endpoint.
+	envOAuth2TokenURLKey = "OTEL_GO_AUTO_EXPORTER_OAUTH2_TOKEN_URL"
+	// envOAuth2ClientIDKey is the key for the environment variable value
+	// containing the OAuth2 client ID.
+	envOAuth2ClientIDKey = "OTEL_GO_AUTO_EXPORTER_OAUTH2_CLIENT_ID"
+	// envOAuth2ClientIDFileKey is the key for the environment variable value
+	// containing the path of the file holding the OAuth2 client ID.
+	envOAuth2ClientIDFileKey = "OTEL_GO_AUTO_EXPORTER_OAUTH2_CLIENT_ID_FILE"
+	// envOAuth2ClientSecretKey is the key for the environment variable value
+	// containing the OAuth2 client secret.
+	envOAuth2ClientSecretKey = "OTEL_GO_AUTO_EXPORTER_OAUTH2_CLIENT_SECRET"
+	// envOAuth2ClientSecretFileKey is the key for the environment variable
+	// value containing the path of the file holding the OAuth2 client secret.
+	envOAuth2ClientSecretFileKey = "OTEL_GO_AUTO_EXPORTER_OAUTH2_CLIENT_SECRET_FILE"
+	// envOAuth2ScopesKey is the key for the environment variable value
+	// containing the comma-separated OAuth2 scopes requested.
+	envOAuth2ScopesKey = "OTEL_GO_AUTO_EXPORTER_OAUTH2_SCOPES"
+
+	// authMaxRefreshWindow is the maximum time before its expiry an OAuth2
+	// token is refreshed.
+	authMaxRefreshWindow = 5 * time.Minute
+	// authMaxRetry is the maximum delay between retries of an export that
+	// failed to authenticate.
+	authMaxRetry = 10 * time.Second
+)
+
+// authMinRetry is the delay before the first retry of an export that failed
+// to authenticate. Variable for testing.
+var authMinRetry = 500 * time.Millisecond
+
+// AuthConfig configures the authentication of the OTLP trace exporter with
+// the collector using a bearer token in the Authorization header of the
+// export requests.
+//
+// Only one of Token, TokenFile, or OAuth2 should be set. If multiple are,
+// OAuth2 takes precedence over TokenFile, which takes precedence over Token.
+type AuthConfig struct {
+	// Token is a static bearer token.
+	Token string
+	// TokenFile is the path of a file holding the bearer token. The file is
+	// read again when it changes, so the token can be rotated without
+	// restarting the agent.
+	TokenFile string
+	// OAuth2 configures bearer tokens to be obtained with the OAuth2 client
+	// credentials flow.
+	OAuth2 *OAuth2Config
+}
+
+// OAuth2Config configures the OAuth2 client credentials flow used to obtain
+// bearer tokens. Tokens are refreshed before they expire, and when the
+// collector rejects them.
+//
+// The client ID and secret can be read from files so they can be rotated
+// without restarting the agent. The files are read each time a token is
+// requested. A file takes precedence over its value counterpart.
+type OAuth2Config struct {
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string
+	// ClientID is the client ID.
+	ClientID string
+	// ClientIDFile is the path of a file holding the client ID.
+	ClientIDFile string
+	// ClientSecret is the client secret.
+	ClientSecret string
+	// ClientSecretFile is the path of a file holding the client secret.
+	ClientSecretFile string
+	// Scopes are the scopes requested.
+	Scopes []string
+}
+
+// authConfigFromEnv returns the exporter authentication configuration
+// defined by environment variables. If authentication is not enabled, false
+// is returned.
+func authConfigFromEnv() (AuthConfig, bool) {
+	var ac AuthConfig
+	if u := getEnv(envOAuth2TokenURLKey); u != "" {
+		ac.OAuth2 = &OAuth2Config{
+			TokenURL:         u,
+			ClientID:         getEnv(envOAuth2ClientIDKey),
+			ClientIDFile:     getEnv(envOAuth2ClientIDFileKey),
+			ClientSecret:     getEnv(envOAuth2ClientSecretKey),
+			ClientSecretFile: getEnv(envOAuth2ClientSecretFileKey),
+		}
+		for _, s := range strings.Split(getEnv(envOAuth2ScopesKey), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ac.OAuth2.Scopes = append(ac.OAuth2.Scopes, s)
+			}
+		}
+		return ac, true
+	}
+	ac.Token = getEnv(envAuthTokenKey)
+	ac.TokenFile = getEnv(envAuthTokenFileKey)
+	return ac, ac.Token != "" || ac.TokenFile != ""
+}
+
+// tokenSource provides the bearer tokens used to authenticate export
+// requests.
+type tokenSource interface {
+	// token returns the current token.
+	token(context.Context) (string, error)
+	// invalidate discards the current token after it was rejected.
+	invalidate()
+}
+
+func newTokenSource(c AuthConfig) (tokenSource, error) {
+	switch {
+	case c.OAuth2 != nil:
+		o := *c.OAuth2
+		if _, err := url.Parse(o.TokenURL); err != nil || o.TokenURL == "" {
+			return nil, fmt.Errorf("invalid OAuth2 token URL %q", o.TokenURL)
+		}
+		if o.ClientID == "" && o.ClientIDFile == "" {
+			return nil, errors.New("missing OAuth2 client ID")
+		}
+		return &oauth2Source{
+			conf:   o,
+			client: &http.Client{Timeout: 30 * time.Second},
+			now:    time.Now,
+		}, nil
+	case c.TokenFile != "":
+		return &fileTokenSource{path: c.TokenFile}, nil
+	case c.Token != "":
+		return staticTokenSource(c.Token), nil
+	default:
+		return nil, errors.New("no exporter authentication configured")
+	}
+}
+
+// staticTokenSource is a tokenSource of a fixed token.
+type staticTokenSource string
+
+func (s staticTokenSource) token(context.Context) (string, error) { return string(s), nil }
+
+func (staticTokenSource) invalidate() {}
+
+// fileTokenSource is a tokenSource reading the token from a file. The file is
+// read again when its modification time or size changes.
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	tok     string
+}
+
+func (s *fileTokenSource) token(context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", err
+	}
+	if s.tok != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.tok, nil
+	}
+
+	tok, err := readSecretFile(s.path)
+	if err != nil {
+		return "", err
+	}
+	s.tok, s.modTime, s.size = tok, info.ModTime(), info.Size()
+	return tok, nil
+}
+
+func (s *fileTokenSource) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Read the file again in case it was replaced within the modification
+	// time granularity.
+	s.tok = ""
+}
+
+// readSecretFile returns the trimmed content of the file at path. The
+// content is never included in the returned error.
+func readSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return "", fmt.Errorf("empty file %s", path)
+	}
+	return s, nil
+}
+
+// oauth2Source is a tokenSource obtaining tokens with the OAuth2 client
+// credentials flow.
+type oauth2Source struct {
+	conf   OAuth2Config
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	tok     string
+	refresh time.Time // Zero if the token does not expire.
+	// inParams is true if the client credentials are sent in the request
+	// body because the authorization server rejected HTTP Basic
+	// authentication.
+	inParams bool
+}
+
+func (s *oauth2Source) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok != "" && (s.refresh.IsZero() || s.now().Before(s.refresh)) {
+		return s.tok, nil
+	}
+
+	tok, expiresIn, err := s.fetch(ctx)
+	if err != nil {
+		if s.tok != "" && s.now().Before(s.refresh.Add(authMaxRefreshWindow)) {
+			// Proactive refresh failed, keep using the current token until
+			// the next attempt.
+			return s.tok, nil
+		}
+		return "", err
+	}
+
+	s.tok, s.refresh = tok, time.Time{}
+	if expiresIn > 0 {
+		// Refresh before the token expires, leaving time for the export
+		// request to complete.
+		window := min(expiresIn/5, authMaxRefreshWindow)
+		s.refresh = s.now().Add(expiresIn - window)
+	}
+	return tok, nil
+}
+
+func (s *oauth2Source) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tok, s.refresh = "", time.Time{}
+}
+
+type oauth2Response struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch requests a new token. The mu lock needs to be held by the caller.
+func (s *oauth2Source) fetch(ctx context.Context) (string, time.Duration, error) {
+	id, secret := s.conf.ClientID, s.conf.ClientSecret
+	var err error
+	if s.conf.ClientIDFile != "" {
+		if id, err = readSecretFile(s.conf.ClientIDFile); err != nil {
+			return "", 0, fmt.Errorf("OAuth2 client ID: %w", err)
+		}
+	}
+	if s.conf.ClientSecretFile != "" {
+		if secret, err = readSecretFile(s.conf.ClientSecretFile); err != nil {
+			return "", 0, fmt.Errorf("OAuth2 client secret: %w", err)
+		}
+	}
+
+	resp, code, err := s.request(ctx, id, secret, s.inParams)
+	if err == nil && !s.inParams && (code == http.StatusBadRequest || code == http.StatusUnauthorized) {
+		// Some authorization servers only support the client credentials in
+		// the request body.
+		if r, c, e := s.request(ctx, id, secret, true); e == nil && c == http.StatusOK {
+			resp, code, s.inParams = r, c, true
+		}
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("OAuth2 token request: %w", err)
+	}
+	if code != http.StatusOK {
+		msg := resp.Error
+		if resp.ErrorDescription != "" {
+			msg += ": " + resp.ErrorDescription
+		}
+		return "", 0, fmt.Errorf("OAuth2 token request: %s: %s", http.StatusText(code), msg)
+	}
+	if resp.AccessToken == "" {
+		return "", 0, errors.New("OAuth2 token response without access token")
+	}
+	if resp.TokenType != "" && !strings.EqualFold(resp.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported OAuth2 token type %q", resp.TokenType)
+	}
+	return resp.AccessToken, time.Duration(resp.ExpiresIn) * time.Second, nil
+}
+
+// request performs a token request authenticated with id and secret, and
+// returns the decoded response and its status code.
+func (s *oauth2Source) request(ctx context.Context, id, secret string, inParams bool) (oauth2Response, int, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.conf.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.conf.Scopes, " "))
+	}
+	if inParams {
+		form.Set("client_id", id)
+		form.Set("client_secret", secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauth2Response{}, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !inParams {
+		req.SetBasicAuth(url.QueryEscape(id), url.QueryEscape(secret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return oauth2Response{}, 0, err
+	}
+	defer resp.Body.Close()
+
+	var out oauth2Response
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return oauth2Response{}, 0, err
+	}
+	if err := json.Unmarshal(body, &out); err != nil && resp.StatusCode == http.StatusOK {
+		return oauth2Response{}, 0, fmt.Errorf("invalid token response: %w", err)
+	}
+	return out, resp.StatusCode, nil
+}
+
+// authError is the error of an export that failed to authenticate.
+type authError struct {
+	// reason is "token" if a token could not be obtained, or "rejected" if
+	// the collector rejected the token.
+	reason string
+	err    error
+}
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrExporterAuth, e.err)
+}
+
+func (e *authError) Is(target error) bool { return target == ErrExporterAuth }
+
+func (e *authError) Unwrap() error { return e.err }
+
+// authTransport is an [http.RoundTripper] adding the bearer token of src to
+// requests.
+type authTransport struct {
+	base http.RoundTripper
+	src  tokenSource
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	tok, err := t.src.token(r.Context())
+	if err != nil {
+		return nil, &authError{reason: "token", err: err}
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+tok)
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		_ = resp.Body.Close()
+		return nil, &authError{reason: "rejected", err: errors.New(resp.Status)}
+	}
+	return resp, nil
+}
+
+// perRPCCredentials are gRPC [credentials.PerRPCCredentials] adding the
+// bearer token of src to calls.
+type perRPCCredentials struct {
+	src    tokenSource
+	secure bool
+}
+
+var _ credentials.PerRPCCredentials = perRPCCredentials{}
+
+func (c perRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	tok, err := c.src.token(ctx)
+	if err != nil {
+		return nil, grpcstatus.Error(codes.Unauthenticated, (&authError{reason: "token", err: err}).Error())
+	}
+	return map[string]string{"authorization": "Bearer " + tok}, nil
+}
+
+func (c perRPCCredentials) RequireTransportSecurity() bool { return c.secure }
+
+// authExporter is an [sdk.SpanExporter] that retries exports failing to
+// authenticate after discarding the token, until the export context is done.
+type authExporter struct {
+	sdk.SpanExporter
+
+	src      tokenSource
+	failures metric.Int64Counter
+}
+
+func newAuthExporter(exp sdk.SpanExporter, src tokenSource, meter metric.Meter) *authExporter {
+	counter, err := meter.Int64Counter(
+		"otel.auto.exporter.auth.failures",
+		metric.WithUnit("{failure}"),
+		metric.WithDescription("Number of trace exports that failed to authenticate with the collector."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return &authExporter{SpanExporter: exp, src: src, failures: counter}
+}
+
+func (e *authExporter) ExportSpans(ctx context.Context, spans []sdk.ReadOnlySpan) error {
+	delay := authMinRetry
+	for {
+		err := e.SpanExporter.ExportSpans(ctx, spans)
+		reason, ok := authFailure(err)
+		if !ok {
+			return err
+		}
+		e.failures.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+		if reason == "rejected" {
+			e.src.invalidate()
+		}
+
+		select {
+		case <-ctx.Done():
+			if !errors.Is(err, ErrExporterAuth) {
+				err = fmt.Errorf("%w: %w", ErrExporterAuth, err)
+			}
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, authMaxRetry)
+	}
+}
+
+// authFailure returns the reason err is an authentication failure. If err is
+// not an authentication failure, false is returned.
+func authFailure(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	if ae := (*authError)(nil); errors.As(err, &ae) {
+		return ae.reason, true
+	}
+	if s, ok := grpcstatus.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unauthenticated:
+			if strings.HasPrefix(s.Message(), ErrExporterAuth.Error()) {
+				return "token", true
+			}
+			return "rejected", true
+		case codes.PermissionDenied:
+			return "rejected", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,341 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+func TestAuthConfigFromEnv(t *testing.T) {
+	_, ok := authConfigFromEnv()
+	assert.False(t, ok, "enabled without configuration")
+
+	t.Setenv(envAuthTokenFileKey, "/run/secrets/token")
+	ac, ok := authConfigFromEnv()
+	assert.True(t, ok)
+	assert.Equal(t, AuthConfig{TokenFile: "/run/secrets/token"}, ac)
+
+	t.Setenv(envOAuth2TokenURLKey, "https://auth.example.com/token")
+	t.Setenv(envOAuth2ClientIDKey, "agent")
+	t.Setenv(envOAuth2ClientSecretFileKey, "/run/secrets/secret")
+	t.Setenv(envOAuth2ScopesKey, "traces.write, metrics.write")
+	ac, ok = authConfigFromEnv()
+	assert.True(t, ok)
+	assert.Equal(t, AuthConfig{OAuth2: &OAuth2Config{
+		TokenURL:         "https://auth.example.com/token",
+		ClientID:         "agent",
+		ClientSecretFile: "/run/secrets/secret",
+		Scopes:           []string{"traces.write", "metrics.write"},
+	}}, ac)
+}
+
+func TestNewTokenSource(t *testing.T) {
+	_, err := newTokenSource(AuthConfig{})
+	assert.Error(t, err)
+	_, err = newTokenSource(AuthConfig{OAuth2: &OAuth2Config{TokenURL: "https://auth.example.com/token"}})
+	assert.ErrorContains(t, err, "client ID")
+
+	src, err := newTokenSource(AuthConfig{Token: "static"})
+	require.NoError(t, err)
+	tok, err := src.token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "static", tok)
+}
+
+func TestFileTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	src := &fileTokenSource{path: path}
+	ctx := context.Background()
+
+	_, err := src.token(ctx)
+	assert.Error(t, err, "missing file")
+
+	require.NoError(t, os.WriteFile(path, []byte("token0\n"), 0o600))
+	tok, err := src.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token0", tok)
+
+	// Rotated.
+	require.NoError(t, os.WriteFile(path, []byte("token-1\n"), 0o600))
+	tok, err = src.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", tok)
+
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0o600))
+	_, err = src.token(ctx)
+	assert.ErrorContains(t, err, "empty")
+}
+
+// tokenServer is an OAuth2 authorization server issuing tokens with the
+// client credentials flow.
+type tokenServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	issued    int
+	expiresIn int
+	// basic is false if the server only supports client credentials in the
+	// request body.
+	basic bool
+	fail  bool
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	s := &tokenServer{expiresIn: 3600, basic: true}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		id, secret, ok := r.BasicAuth()
+		if !s.basic {
+			ok = false
+		}
+		if !ok {
+			id, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if s.fail || r.PostFormValue("grant_type") != "client_credentials" || id != "agent" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		s.issued++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "token" + string(rune('0'+s.issued)),
+			"token_type":   "Bearer",
+			"expires_in":   s.expiresIn,
+			"scope":        r.PostFormValue("scope"),
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) set(f func(*tokenServer)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s)
+}
+
+func TestOAuth2Source(t *testing.T) {
+	srv := newTokenServer(t)
+	secret := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("s3cret\n"), 0o600))
+
+	src, err := newTokenSource(AuthConfig{OAuth2: &OAuth2Config{
+		TokenURL:         srv.URL,
+		ClientID:         "agent",
+		ClientSecretFile: secret,
+		Scopes:           []string{"traces.write"},
+	}})
+	require.NoError(t, err)
+	o := src.(*oauth2Source)
+	now := time.Now()
+	o.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	tok, err := o.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token1", tok)
+
+	// Cached until shortly before expiry.
+	now = now.Add(54 * time.Minute)
+	tok, err = o.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token1", tok)
+
+	now = now.Add(2 * time.Minute)
+	tok, err = o.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token2", tok, "not refreshed before expiry")
+
+	// A failed proactive refresh keeps the current token until it expires.
+	srv.set(func(s *tokenServer) { s.fail = true })
+	now = now.Add(56 * time.Minute)
+	tok, err = o.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token2", tok)
+	now = now.Add(5 * time.Minute)
+	_, err = o.token(ctx)
+	assert.ErrorContains(t, err, "invalid_client")
+	assert.NotContains(t, err.Error(), "s3cret")
+
+	srv.set(func(s *tokenServer) { s.fail = false })
+	tok, err = o.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token3", tok)
+
+	o.invalidate()
+	tok, err = o.token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token4", tok)
+}
+
+func TestOAuth2SourceCredentialsInBody(t *testing.T) {
+	srv := newTokenServer(t)
+	srv.basic = false
+
+	src, err := newTokenSource(AuthConfig{OAuth2: &OAuth2Config{
+		TokenURL:     srv.URL,
+		ClientID:     "agent",
+		ClientSecret: "s3cret",
+	}})
+	require.NoError(t, err)
+
+	tok, err := src.token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token1", tok)
+	assert.True(t, src.(*oauth2Source).inParams)
+}
+
+// collector is an OTLP HTTP collector accepting requests authenticated with
+// the valid token.
+type collector struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	valid string
+	auths []string
+}
+
+func newCollector(t *testing.T, valid string) *collector {
+	c := &collector{valid: valid}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		auth := r.Header.Get("Authorization")
+		c.auths = append(c.auths, auth)
+		if auth != "Bearer "+c.valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *collector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.auths...)
+}
+
+func TestWithExporterAuth(t *testing.T) {
+	minRetry := authMinRetry
+	authMinRetry = time.Millisecond
+	t.Cleanup(func() { authMinRetry = minRetry })
+
+	col := newCollector(t, "token2")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", col.URL)
+
+	tokens := newTokenServer(t)
+	c, err := newConfig(context.Background(), []Option{
+		WithExporterAuth(AuthConfig{OAuth2: &OAuth2Config{
+			TokenURL:     tokens.URL,
+			ClientID:     "agent",
+			ClientSecret: "s3cret",
+		}}),
+	})
+	require.NoError(t, err)
+	require.IsType(t, &authExporter{}, c.exporter)
+
+	// The first token is rejected, the batch is exported with a new one.
+	ctx := context.Background()
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span")))
+	assert.Equal(t, []string{"Bearer token1", "Bearer token2"}, col.received())
+	require.NoError(t, c.exporter.Shutdown(ctx))
+}
+
+func TestWithExporterAuthIgnored(t *testing.T) {
+	c, err := newConfig(context.Background(), []Option{
+		WithTraceExporter(&flakyExporter{}),
+		WithExporterAuth(AuthConfig{Token: "token"}),
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &flakyExporter{}, c.exporter)
+	require.Len(t, c.warnings, 1)
+	assert.Contains(t, c.warnings[0], "authentication is ignored")
+}
+
+// errTokenSource is a tokenSource failing to obtain tokens.
+type errTokenSource struct{}
+
+func (errTokenSource) token(context.Context) (string, error) {
+	return "", errors.New("authorization server unavailable")
+}
+
+func (errTokenSource) invalidate() {}
+
+func TestAuthExporterTimeout(t *testing.T) {
+	minRetry := authMinRetry
+	authMinRetry = time.Millisecond
+	t.Cleanup(func() { authMinRetry = minRetry })
+
+	col := newCollector(t, "token")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", col.URL)
+	u, err := otlpEndpoint()
+	require.NoError(t, err)
+
+	exp, err := newOTLPExporter(context.Background(), u, false, otlpHooks{auth: errTokenSource{}})
+	require.NoError(t, err)
+	auth := newAuthExporter(exp, errTokenSource{}, noop.Meter{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = auth.ExportSpans(ctx, testSpans("span"))
+	assert.ErrorIs(t, err, ErrExporterAuth)
+	assert.Empty(t, col.received(), "request sent without token")
+	require.NoError(t, auth.Shutdown(context.Background()))
+}
+
+func TestPerRPCCredentials(t *testing.T) {
+	creds := perRPCCredentials{src: staticTokenSource("token"), secure: true}
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token"}, md)
+	assert.True(t, creds.RequireTransportSecurity())
+
+	creds = perRPCCredentials{src: errTokenSource{}}
+	_, err = creds.GetRequestMetadata(context.Background())
+	reason, ok := authFailure(err)
+	assert.True(t, ok)
+	assert.Equal(t, "token", reason)
+}
+
+func TestAuthFailure(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		reason string
+		ok     bool
+	}{
+		{err: nil},
+		{err: errors.New("connection refused")},
+		{err: grpcstatus.Error(codes.Unavailable, "unavailable")},
+		{err: grpcstatus.Error(codes.Unauthenticated, "invalid token"), reason: "rejected", ok: true},
+		{err: grpcstatus.Error(codes.PermissionDenied, "forbidden"), reason: "rejected", ok: true},
+		{err: &authError{reason: "rejected", err: errors.New("401 Unauthorized")}, reason: "rejected", ok: true},
+		{err: &authError{reason: "token", err: errors.New("timeout")}, reason: "token", ok: true},
+	} {
+		reason, ok := authFailure(tc.err)
+		assert.Equal(t, tc.ok, ok, tc.err)
+		assert.Equal(t, tc.reason, reason, tc.err)
+	}
+}
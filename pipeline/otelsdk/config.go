@@ -8,13 +8,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"go.opentelemetry.io/contrib/exporters/autoexport"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelsdk "go.opentelemetry.io/otel/sdk"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdk "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
@@ -32,8 +37,49 @@ const (
 	// envLogLevelKey is the key for the environment variable value containing
 	// the log level.
 	envLogLevelKey = "OTEL_LOG_LEVEL"
+	// envTracesExporterKey is the key for the environment variable value
+	// containing the trace exporters.
+	envTracesExporterKey = "OTEL_TRACES_EXPORTER"
+
+	// envFileDirKey is the key for the environment variable value containing
+	// the directory the file exporter writes to.
+	envFileDirKey = "OTEL_GO_AUTO_TRACES_FILE_DIR"
+	// envFileMaxSizeKey is the key for the environment variable value
+	// containing the maximum size of a file written by the file exporter.
+	envFileMaxSizeKey = "OTEL_GO_AUTO_TRACES_FILE_MAX_SIZE"
+	// envFileMaxTotalSizeKey is the key for the environment variable value
+	// containing the maximum total size of the files written by the file
+	// exporter.
+	envFileMaxTotalSizeKey = "OTEL_GO_AUTO_TRACES_FILE_MAX_TOTAL_SIZE"
+	// envFileSyncKey is the key for the environment variable value containing
+	// the sync policy of the file exporter.
+	envFileSyncKey = "OTEL_GO_AUTO_TRACES_FILE_SYNC"
+
+	// envQueueDirKey is the key for the environment variable value containing
+	// the directory of the persistent queue.
+	envQueueDirKey = "OTEL_GO_AUTO_TRACES_QUEUE_DIR"
+	// envQueueMaxSizeKey is the key for the environment variable value
+	// containing the maximum size of the persistent queue.
+	envQueueMaxSizeKey = "OTEL_GO_AUTO_TRACES_QUEUE_MAX_SIZE"
+	// envQueueSyncKey is the key for the environment variable value
+	// containing the sync policy of the persistent queue.
+	envQueueSyncKey = "OTEL_GO_AUTO_TRACES_QUEUE_SYNC"
 )
 
+// otlpEnvSuffixes are the suffixes of the OTLP exporter environment variables
+// that are defined generically and per-signal.
+var otlpEnvSuffixes = []string{
+	"ENDPOINT",
+	"HEADERS",
+	"TIMEOUT",
+	"PROTOCOL",
+	"COMPRESSION",
+	"INSECURE",
+	"CERTIFICATE",
+	"CLIENT_CERTIFICATE",
+	"CLIENT_KEY",
+}
+
 // Option configures a [traceHandler] via [NewHandler].
 type Option interface {
 	apply(context.Context, config) (config, error)
@@ -89,7 +135,56 @@ func WithResourceAttributes(attrs ...attribute.KeyValue) Option {
 // [WithEnv]. If both are used, the last one provided will be used.
 func WithTraceExporter(exp sdk.SpanExporter) Option {
 	return fnOpt(func(_ context.Context, c config) (config, error) {
-		c.exporter = exp
+		c.exporter, c.otlpEnv = exp, false
+		return c, nil
+	})
+}
+
+// WithFileExporter returns an [Option] that will configure spans to also be
+// written to files as OTLP JSON. See [FileConfig] for details.
+//
+// Spans are written to files in addition to being exported by the trace
+// exporter. To only write spans to files, set OTEL_TRACES_EXPORTER to "none".
+//
+// If OTEL_GO_AUTO_TRACES_FILE_DIR is defined, this option will conflict with
+// [WithEnv]. If both are used, the last one provided will be used.
+func WithFileExporter(fc FileConfig) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		c.file = &fc
+		return c, nil
+	})
+}
+
+// WithExporterAuth returns an [Option] that will configure the OTLP trace
+// exporter to authenticate with the collector using a bearer token. See
+// [AuthConfig] for details.
+//
+// Exports failing to authenticate are retried until they time out, and
+// return an error wrapping [ErrExporterAuth]. Their number is counted by the
+// otel.auto.exporter.auth.failures metric. Tokens are never logged.
+//
+// This option only applies to the OTLP exporter configured with environment
+// variables. If the OTEL_GO_AUTO_EXPORTER_AUTH_TOKEN,
+// OTEL_GO_AUTO_EXPORTER_AUTH_TOKEN_FILE, or
+// OTEL_GO_AUTO_EXPORTER_OAUTH2_TOKEN_URL environment variables are defined,
+// this option will conflict with [WithEnv]. If both are used, the last one
+// provided will be used.
+func WithExporterAuth(ac AuthConfig) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		c.auth = &ac
+		return c, nil
+	})
+}
+
+// WithPersistentQueue returns an [Option] that will configure spans the trace
+// exporter fails to export to be stored on disk and exported once it
+// recovers. See [QueueConfig] for details.
+//
+// If OTEL_GO_AUTO_TRACES_QUEUE_DIR is defined, this option will conflict
+// with [WithEnv]. If both are used, the last one provided will be used.
+func WithPersistentQueue(qc QueueConfig) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		c.queue = &qc
 		return c, nil
 	})
 }
@@ -105,6 +200,53 @@ var (
 //   - OTEL_SERVICE_NAME (or OTEL_RESOURCE_ATTRIBUTES): sets the service name
 //   - OTEL_TRACES_EXPORTER: sets the trace exporter
 //   - OTEL_LOG_LEVEL: sets the default logger's minimum logging level
+//   - OTEL_METRICS_EXPORTER: sets the exporters of the metrics produced by
+//     the agent and the instrumentation ("otlp", "prometheus", or "none",
+//     comma-separated). Metrics are not exported if undefined
+//   - OTEL_LOGS_EXPORTER: sets the exporters of the log records produced by
+//     auto-instrumentation ("otlp" or "none", comma-separated). Logs are not
+//     exported if undefined
+//   - OTEL_EXPORTER_PROMETHEUS_HOST: sets the host the Prometheus exporter
+//     listens on (default "localhost")
+//   - OTEL_EXPORTER_PROMETHEUS_PORT: sets the port the Prometheus exporter
+//     listens on (default 9464)
+//   - OTEL_GO_AUTO_TRACES_FILE_DIR: enables the file exporter writing to
+//     the directory (see [WithFileExporter])
+//   - OTEL_GO_AUTO_TRACES_FILE_MAX_SIZE: sets the maximum size in bytes of
+//     a file written by the file exporter
+//   - OTEL_GO_AUTO_TRACES_FILE_MAX_TOTAL_SIZE: sets the maximum total size in
+//     bytes of the files written by the file exporter
+//   - OTEL_GO_AUTO_TRACES_FILE_SYNC: sets the file exporter sync policy
+//     ("rotate", "always", or "never")
+//   - OTEL_GO_AUTO_EXPORTER_AUTH_TOKEN: sets the bearer token of the OTLP
+//     exporter (see [WithExporterAuth])
+//   - OTEL_GO_AUTO_EXPORTER_AUTH_TOKEN_FILE: sets the file holding the
+//     bearer token of the OTLP exporter
+//   - OTEL_GO_AUTO_EXPORTER_OAUTH2_TOKEN_URL: enables the OAuth2 client
+//     credentials flow to obtain the bearer tokens of the OTLP exporter from
+//     the token endpoint
+//   - OTEL_GO_AUTO_EXPORTER_OAUTH2_CLIENT_ID (or _CLIENT_ID_FILE): sets the
+//     OAuth2 client ID
+//   - OTEL_GO_AUTO_EXPORTER_OAUTH2_CLIENT_SECRET (or _CLIENT_SECRET_FILE):
+//     sets the OAuth2 client secret
+//   - OTEL_GO_AUTO_EXPORTER_OAUTH2_SCOPES: sets the comma-separated OAuth2
+//     scopes requested
+//   - OTEL_GO_AUTO_TRACES_QUEUE_DIR: enables the persistent queue stored in
+//     the directory (see [WithPersistentQueue])
+//   - OTEL_GO_AUTO_TRACES_QUEUE_MAX_SIZE: sets the maximum size in bytes of
+//     the persistent queue
+//   - OTEL_GO_AUTO_TRACES_QUEUE_SYNC: sets the persistent queue sync policy
+//     ("rotate", "always", or "never")
+//
+// The OTLP trace exporter is configured with the OTEL_EXPORTER_OTLP_TRACES_*
+// environment variables, which take precedence over their generic
+// OTEL_EXPORTER_OTLP_* equivalent. The OTLP metric and log exporters are
+// configured the same way with the OTEL_EXPORTER_OTLP_METRICS_* and
+// OTEL_EXPORTER_OTLP_LOGS_* environment variables. A warning is logged if OTLP
+// exporter environment variables are defined for the logs signal when
+// OTEL_LOGS_EXPORTER does not include "otlp", for the metrics signal when
+// OTEL_METRICS_EXPORTER does not include "otlp", or for the traces signal when
+// OTEL_TRACES_EXPORTER does not include "otlp".
 //
 // This option will conflict with [WithTraceExporter] and [WithServiceName].
 // The last [Option] provided will be used.
@@ -120,12 +262,38 @@ var (
 // supported values and registration of custom exporters.
 func WithEnv() Option {
 	return fnOpt(func(ctx context.Context, c config) (config, error) {
-		var err error
 		// NewSpanExporter will use an OTLP (HTTP/protobuf) exporter as the
 		// default. This is the OTel recommended default.
-		c.exporter, err = autoexport.NewSpanExporter(ctx)
+		exp, err := autoexport.NewSpanExporter(ctx)
+		if err == nil {
+			c.exporter = exp
+		}
+		c.otlpEnv = useOTLPFromEnv()
 
 		c.resAttrs = append(c.resAttrs, lookupResourceData()...)
+		c.warnings = append(c.warnings, otlpEnvWarnings()...)
+
+		var e error
+		c, e = metricConfigFromEnv(ctx, c)
+		err = errors.Join(err, e)
+		c, e = logConfigFromEnv(ctx, c)
+		err = errors.Join(err, e)
+
+		if fc, ok, e := fileConfigFromEnv(); e != nil {
+			err = errors.Join(err, e)
+		} else if ok {
+			c.file = &fc
+		}
+
+		if ac, ok := authConfigFromEnv(); ok {
+			c.auth = &ac
+		}
+
+		if qc, ok, e := queueConfigFromEnv(); e != nil {
+			err = errors.Join(err, e)
+		} else if ok {
+			c.queue = &qc
+		}
 
 		if val, ok := lookupEnv(envLogLevelKey); c.logger == nil && ok {
 			var level slog.Level
@@ -140,6 +308,130 @@ func WithEnv() Option {
 	})
 }
 
+// otlpEnvWarnings returns warnings for the OTLP exporter environment
+// variables that are defined but not used.
+func otlpEnvWarnings() []string {
+	var warnings []string
+	if exporters, ok := lookupEnv(envMetricsExporterKey); !ok {
+		for _, key := range otlpEnvKeys("METRICS") {
+			if _, ok := lookupEnv(key); ok {
+				warnings = append(warnings, key+" is ignored: metrics are not exported")
+			}
+		}
+	} else if !includesOTLP(exporters) {
+		for _, key := range otlpEnvKeys("METRICS") {
+			if _, ok := lookupEnv(key); ok {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s is ignored: %s=%q does not include otlp",
+					key, envMetricsExporterKey, exporters,
+				))
+			}
+		}
+	}
+
+	if exporters, ok := lookupEnv(envLogsExporterKey); !ok {
+		for _, key := range otlpEnvKeys("LOGS") {
+			if _, ok := lookupEnv(key); ok {
+				warnings = append(warnings, key+" is ignored: logs are not exported")
+			}
+		}
+	} else if !includesOTLP(exporters) {
+		for _, key := range otlpEnvKeys("LOGS") {
+			if _, ok := lookupEnv(key); ok {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s is ignored: %s=%q does not include otlp",
+					key, envLogsExporterKey, exporters,
+				))
+			}
+		}
+	}
+
+	if useOTLPFromEnv() {
+		return warnings
+	}
+	exporters := getEnv(envTracesExporterKey)
+	for _, key := range append(otlpEnvKeys(""), otlpEnvKeys("TRACES")...) {
+		if _, ok := lookupEnv(key); ok {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s is ignored: %s=%q does not include otlp",
+				key, envTracesExporterKey, exporters,
+			))
+		}
+	}
+	return warnings
+}
+
+// otlpEnvKeys returns the OTLP exporter environment variable keys for signal.
+// If signal is empty, the generic keys are returned.
+func otlpEnvKeys(signal string) []string {
+	prefix := "OTEL_EXPORTER_OTLP_"
+	if signal != "" {
+		prefix += signal + "_"
+	}
+	keys := make([]string, len(otlpEnvSuffixes))
+	for i, suffix := range otlpEnvSuffixes {
+		keys[i] = prefix + suffix
+	}
+	return keys
+}
+
+// fileConfigFromEnv returns the file exporter configuration defined by
+// environment variables. If the file exporter is not enabled, false is
+// returned.
+func fileConfigFromEnv() (FileConfig, bool, error) {
+	dir, ok := lookupEnv(envFileDirKey)
+	if !ok || dir == "" {
+		return FileConfig{}, false, nil
+	}
+
+	fc := FileConfig{Dir: dir}
+	var err error
+	parseSize := func(key string) int64 {
+		v, ok := lookupEnv(key)
+		if !ok {
+			return 0
+		}
+		n, e := strconv.ParseInt(v, 10, 64)
+		if e != nil || n < 0 {
+			err = errors.Join(err, fmt.Errorf("invalid %s: %q", key, v))
+			return 0
+		}
+		return n
+	}
+	fc.MaxSize = parseSize(envFileMaxSizeKey)
+	fc.MaxTotalSize = parseSize(envFileMaxTotalSizeKey)
+
+	var e error
+	fc.Sync, e = parseFileSyncPolicy(getEnv(envFileSyncKey))
+	err = errors.Join(err, e)
+	return fc, err == nil, err
+}
+
+// queueConfigFromEnv returns the persistent queue configuration defined by
+// environment variables. If the persistent queue is not enabled, false is
+// returned.
+func queueConfigFromEnv() (QueueConfig, bool, error) {
+	dir, ok := lookupEnv(envQueueDirKey)
+	if !ok || dir == "" {
+		return QueueConfig{}, false, nil
+	}
+
+	qc := QueueConfig{Dir: dir}
+	var err error
+	if v, ok := lookupEnv(envQueueMaxSizeKey); ok {
+		n, e := strconv.ParseInt(v, 10, 64)
+		if e != nil || n < 0 {
+			err = fmt.Errorf("invalid %s: %q", envQueueMaxSizeKey, v)
+		}
+		qc.MaxSize = n
+	}
+
+	var e error
+	qc.Sync, e = parseFileSyncPolicy(getEnv(envQueueSyncKey))
+	err = errors.Join(err, e)
+	return qc, err == nil, err
+}
+
 func lookupResourceData() []attribute.KeyValue {
 	rawVal := getEnv(envResourceAttrKey)
 	pairs := strings.Split(strings.TrimSpace(rawVal), ",")
@@ -177,6 +469,43 @@ type config struct {
 
 	spanProcessor sdk.SpanProcessor
 	idGenerator   *idGenerator
+
+	// otlpEnv is true if exporter is an OTLP exporter configured with
+	// environment variables.
+	otlpEnv bool
+	// proxy is the proxy used by exporter, nil if it connects directly.
+	proxy *url.URL
+	// endpoint is the endpoint exporter connects to if otlpEnv is true.
+	endpoint *url.URL
+
+	// file is the file exporter configuration, nil if disabled.
+	file *FileConfig
+	// queue is the persistent queue configuration, nil if disabled.
+	queue *QueueConfig
+	// auth is the exporter authentication configuration, nil if disabled.
+	auth *AuthConfig
+	// partial records the partial success responses of the exporter if
+	// otlpEnv is true.
+	partial *partialSuccess
+
+	// prometheusAddr is the address the Prometheus exporter listens on,
+	// empty if disabled.
+	prometheusAddr string
+	// metricReaders are the readers of the metrics produced by the agent.
+	metricReaders []metric.Reader
+	// metricViews are the views applied to the metrics produced by the
+	// agent.
+	metricViews []metric.View
+	// meterProvider is the provider of the metrics produced by the agent,
+	// nil if metrics are not exported.
+	meterProvider *meterProvider
+
+	// logProcessors are the processors of the log records produced by
+	// auto-instrumentation.
+	logProcessors []sdklog.Processor
+
+	// warnings are logged once the configuration is complete.
+	warnings []string
 }
 
 func newConfig(ctx context.Context, options []Option) (config, error) {
@@ -193,12 +522,40 @@ func newConfig(ctx context.Context, options []Option) (config, error) {
 		c, e = opt.apply(ctx, c)
 		err = errors.Join(err, e)
 	}
+	if err == nil {
+		// Created first, the exporters have instruments.
+		c.meterProvider, err = c.newMeterProvider()
+	}
 
 	if c.exporter == nil {
-		var e error
-		c.exporter, e = otlptracehttp.New(ctx)
+		// Not assigned on error, a nil *otlptrace.Exporter is not a nil
+		// exporter.
+		if exp, e := otlptracehttp.New(ctx); e != nil {
+			err = errors.Join(err, e)
+		} else {
+			c.exporter = exp
+		}
+		c.otlpEnv = true
+	}
+	if c.otlpEnv && c.exporter != nil {
+		err = errors.Join(err, c.configureEndpoint(ctx))
+	} else if c.auth != nil {
+		c.warnings = append(c.warnings, "exporter authentication is ignored: the trace exporter is not the OTLP exporter configured with environment variables")
+	}
+	if c.queue != nil && c.exporter != nil {
+		qe, e := newQueueExporter(c.exporter, *c.queue, c.Logger())
 		if e != nil {
 			err = errors.Join(err, e)
+		} else {
+			c.exporter = qe
+		}
+	}
+	if c.file != nil {
+		fe, e := newFileExporter(*c.file)
+		if e != nil {
+			err = errors.Join(err, e)
+		} else {
+			c.exporter = fanoutExporter{c.exporter, fe}
 		}
 	}
 	c.spanProcessor = sdk.NewBatchSpanProcessor(c.exporter)
@@ -206,6 +563,74 @@ func newConfig(ctx context.Context, options []Option) (config, error) {
 	return c, err
 }
 
+// shutdown releases the exporters and servers of an unused c. Nothing is
+// exported, they are shut down without delay.
+func (c config) shutdown() error {
+	var err error
+	if c.spanProcessor != nil {
+		// Shuts down the exporter.
+		err = c.spanProcessor.Shutdown(context.Background())
+	} else if c.exporter != nil {
+		err = c.exporter.Shutdown(context.Background())
+	}
+	return errors.Join(
+		err,
+		c.meterProvider.Shutdown(context.Background()),
+		c.shutdownLogProcessors(context.Background()),
+	)
+}
+
+// configureEndpoint configures the connection of the OTLP exporter of c to
+// the endpoint defined by environment variables.
+func (c *config) configureEndpoint(ctx context.Context) error {
+	var err error
+	c.endpoint, err = otlpEndpoint()
+	if err != nil {
+		return err
+	}
+
+	h := otlpHooks{partial: newPartialSuccess(c.Logger(), c.meterProvider.meter())}
+	if c.auth != nil {
+		if h.auth, err = newTokenSource(*c.auth); err != nil {
+			return fmt.Errorf("exporter authentication: %w", err)
+		}
+	}
+	c.partial = h.partial
+	grpc := otlpEnv("PROTOCOL") == "grpc"
+
+	// The exporter configured with environment variables does not support
+	// unix domain sockets, nor hooks on its requests. Replace it.
+	_ = c.exporter.Shutdown(ctx)
+	c.exporter = nil
+	path, unix := unixSocketPath(c.endpoint)
+	var exp sdk.SpanExporter
+	if unix {
+		exp, err = newUnixExporter(ctx, path, grpc, h)
+	} else {
+		exp, err = newOTLPExporter(ctx, c.endpoint, grpc, h)
+	}
+	if err != nil {
+		return err
+	}
+	c.exporter = exp
+	if h.auth != nil {
+		c.exporter = newAuthExporter(c.exporter, h.auth, c.meterProvider.meter())
+	}
+	if unix {
+		// Proxies are not used for unix domain sockets.
+		return nil
+	}
+
+	c.proxy, err = otlpProxy(c.endpoint)
+	if err != nil {
+		return err
+	}
+	if c.proxy != nil {
+		c.exporter = newProxyExporter(c.exporter, c.proxy)
+	}
+	return nil
+}
+
 func defaultServiceName() string {
 	executable, err := os.Executable()
 	if err != nil {
@@ -238,7 +663,9 @@ func (c config) resource() *resource.Resource {
 		append(
 			[]attribute.KeyValue{
 				semconv.TelemetrySDKLanguageGo,
-				semconv.TelemetryDistroVersion(instrumentation.Version),
+				semconv.TelemetrySDKName("opentelemetry"),
+				semconv.TelemetrySDKVersion(otelsdk.Version()),
+				semconv.TelemetryDistroVersion(instrumentation.BuildVersion()),
 				semconv.TelemetryDistroName(instrumentation.Name),
 			},
 			c.resAttrs...,
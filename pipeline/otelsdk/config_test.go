@@ -12,7 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
+	otelsdk "go.opentelemetry.io/otel/sdk"
 	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation"
 )
 
 func TestWithServiceName(t *testing.T) {
@@ -29,6 +32,18 @@ func TestWithServiceName(t *testing.T) {
 	assert.NotContains(t, res, semconv.ServiceName(defaultServiceName()))
 }
 
+func TestResourceTelemetryAttributes(t *testing.T) {
+	c, err := newConfig(context.Background(), nil)
+	require.NoError(t, err)
+
+	res := c.resource().Attributes()
+	assert.Contains(t, res, semconv.TelemetrySDKLanguageGo)
+	assert.Contains(t, res, semconv.TelemetrySDKName("opentelemetry"))
+	assert.Contains(t, res, semconv.TelemetrySDKVersion(otelsdk.Version()))
+	assert.Contains(t, res, semconv.TelemetryDistroName("opentelemetry-go-instrumentation"))
+	assert.Contains(t, res, semconv.TelemetryDistroVersion(instrumentation.BuildVersion()))
+}
+
 func TestWithEnv(t *testing.T) {
 	t.Run("OTEL_SERVICE_NAME", func(t *testing.T) {
 		const name = "test_service"
@@ -72,6 +87,71 @@ func TestWithEnv(t *testing.T) {
 		_, err = newConfig(ctx, opts)
 		require.ErrorContains(t, err, `parse log level "invalid"`)
 	})
+
+	t.Run("OTLPWarnings", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318")
+		t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "http://metrics:4318")
+		t.Setenv("OTEL_EXPORTER_OTLP_LOGS_HEADERS", "key=value")
+
+		c, err := newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT is ignored: metrics are not exported",
+			"OTEL_EXPORTER_OTLP_LOGS_HEADERS is ignored: logs are not exported",
+		}, c.warnings)
+	})
+
+	t.Run("OTLPWarningsExporter", func(t *testing.T) {
+		t.Setenv(envTracesExporterKey, "console")
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_TIMEOUT", "1000")
+
+		c, err := newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`OTEL_EXPORTER_OTLP_ENDPOINT is ignored: OTEL_TRACES_EXPORTER="console" does not include otlp`,
+			`OTEL_EXPORTER_OTLP_TRACES_TIMEOUT is ignored: OTEL_TRACES_EXPORTER="console" does not include otlp`,
+		}, c.warnings)
+
+		t.Setenv(envTracesExporterKey, "otlp")
+		c, err = newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Empty(t, c.warnings)
+	})
+
+	t.Run("OTLPWarningsMetricsExporter", func(t *testing.T) {
+		t.Setenv(envMetricsExporterKey, "none")
+		t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "http://metrics:4318")
+
+		c, err := newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`OTEL_EXPORTER_OTLP_METRICS_ENDPOINT is ignored: OTEL_METRICS_EXPORTER="none" does not include otlp`,
+		}, c.warnings)
+
+		t.Setenv(envMetricsExporterKey, "otlp")
+		c, err = newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Empty(t, c.warnings)
+		_ = c.meterProvider.Shutdown(context.Background())
+	})
+
+	t.Run("OTLPWarningsLogsExporter", func(t *testing.T) {
+		t.Setenv(envLogsExporterKey, "none")
+		t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "http://logs:4318")
+
+		c, err := newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`OTEL_EXPORTER_OTLP_LOGS_ENDPOINT is ignored: OTEL_LOGS_EXPORTER="none" does not include otlp`,
+		}, c.warnings)
+
+		t.Setenv(envLogsExporterKey, "otlp")
+		c, err = newConfig(context.Background(), []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Empty(t, c.warnings)
+		_ = c.shutdownLogProcessors(context.Background())
+	})
 }
 
 func TestWithResourceAttributes(t *testing.T) {
@@ -5,9 +5,11 @@ package otelsdk_test
 
 import (
 	"context"
+	"fmt"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"go.opentelemetry.io/auto"
 	"go.opentelemetry.io/auto/pipeline/otelsdk"
@@ -59,3 +61,33 @@ func Example_multiplex() {
 	// Shut down the multiplexer, cleaning up any remaining resources.
 	_ = m.Shutdown(ctx)
 }
+
+func ExampleRecordingExporter() {
+	ctx := context.Background()
+
+	// Record the spans produced for the service under test in memory.
+	exp := otelsdk.NewRecordingExporter()
+	h, err := otelsdk.NewHandler(ctx, otelsdk.WithTraceExporter(exp))
+	if err != nil {
+		panic(err)
+	}
+
+	// Instrument the service under test, a process started by the test.
+	//
+	// NOTE: Error handling is omitted here for brevity. In test code, always
+	// check and handle errors.
+	const pid = 1297
+	inst, _ := auto.NewInstrumentation(ctx, auto.WithPID(pid), auto.WithHandler(h))
+	_ = inst.Load(ctx)
+	go func() { _ = inst.Run(ctx) }()
+
+	// Send requests to the service, then wait for the spans they produce.
+	spans, err := exp.WaitForSpans(1, 30*time.Second)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(spans[0].Name)
+
+	// Use exp.AssertSpan in a test to check a span with a name and
+	// attributes was exported.
+}
@@ -0,0 +1,375 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+
+	"go.opentelemetry.io/auto/internal/pkg/instrumentation/pdataconv"
+)
+
+const (
+	// DefaultFileMaxSize is the default maximum size of a file written by the
+	// file exporter.
+	DefaultFileMaxSize = 100 << 20 // 100 MiB
+	// DefaultFileMaxTotalSize is the default maximum total size of the files
+	// written by the file exporter.
+	DefaultFileMaxTotalSize = 1 << 30 // 1 GiB
+
+	filePrefix = "traces-"
+	fileSuffix = ".jsonl"
+)
+
+// FileSyncPolicy defines when files written by the file exporter are synced
+// to stable storage.
+type FileSyncPolicy uint8
+
+const (
+	// FileSyncRotate syncs a file when it is rotated or the exporter is shut
+	// down. This is the default policy.
+	FileSyncRotate FileSyncPolicy = iota
+	// FileSyncAlways syncs a file after each export.
+	FileSyncAlways
+	// FileSyncNever never syncs files. Syncing is left to the operating
+	// system.
+	FileSyncNever
+)
+
+// parseFileSyncPolicy parses s as a FileSyncPolicy.
+func parseFileSyncPolicy(s string) (FileSyncPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "rotate":
+		return FileSyncRotate, nil
+	case "always":
+		return FileSyncAlways, nil
+	case "never":
+		return FileSyncNever, nil
+	default:
+		return FileSyncRotate, fmt.Errorf("unknown file sync policy: %q", s)
+	}
+}
+
+// FileConfig configures the file exporter.
+//
+// The file exporter writes spans as OTLP JSON to files in Dir. Each line of a
+// file is a JSON encoded ExportTraceServiceRequest. These files can be read by
+// the OpenTelemetry Collector otlpjsonfile receiver.
+type FileConfig struct {
+	// Dir is the directory files are written to. It is created if it does not
+	// exist.
+	Dir string
+	// MaxSize is the maximum size, in bytes, of a file. Once a file would
+	// exceed this size a new one is created. If zero, DefaultFileMaxSize is
+	// used.
+	MaxSize int64
+	// MaxTotalSize is the maximum total size, in bytes, of the files in Dir.
+	// The oldest files are deleted when a file is rotated and this size is
+	// exceeded. If zero, DefaultFileMaxTotalSize is used.
+	MaxTotalSize int64
+	// Sync is the policy used to sync files to stable storage.
+	Sync FileSyncPolicy
+}
+
+// fileExporter is an [sdk.SpanExporter] that writes spans as OTLP JSON to
+// size-rotated files.
+type fileExporter struct {
+	conf FileConfig
+	now  func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	seq     uint64
+	stopped bool
+
+	marshaler ptrace.JSONMarshaler
+}
+
+var _ sdk.SpanExporter = (*fileExporter)(nil)
+
+func newFileExporter(c FileConfig) (*fileExporter, error) {
+	if c.Dir == "" {
+		return nil, errors.New("file exporter: empty directory")
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = DefaultFileMaxSize
+	}
+	if c.MaxTotalSize <= 0 {
+		c.MaxTotalSize = DefaultFileMaxTotalSize
+	}
+	if err := os.MkdirAll(c.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("file exporter: %w", err)
+	}
+	e := &fileExporter{conf: c, now: time.Now}
+	// Files written before a restart count towards the total size.
+	return e, e.prune()
+}
+
+// ExportSpans writes spans as a single line of OTLP JSON.
+func (e *fileExporter) ExportSpans(_ context.Context, spans []sdk.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	line, err := e.marshaler.MarshalTraces(convertSpans(spans))
+	if err != nil {
+		return fmt.Errorf("file exporter: %w", err)
+	}
+	line = append(line, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return nil
+	}
+
+	if e.file != nil && e.size > 0 && e.size+int64(len(line)) > e.conf.MaxSize {
+		if err := e.rotate(); err != nil {
+			return err
+		}
+	}
+	if e.file == nil {
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := e.file.Write(line)
+	e.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("file exporter: %w", err)
+	}
+	if e.conf.Sync == FileSyncAlways {
+		if err := e.file.Sync(); err != nil {
+			return fmt.Errorf("file exporter: %w", err)
+		}
+	}
+	return nil
+}
+
+// open opens a new file to write to.
+//
+// The mu lock needs to be held by the caller.
+func (e *fileExporter) open() error {
+	// Names sort in the order files are created.
+	name := fmt.Sprintf(
+		"%s%s-%06d%s",
+		filePrefix,
+		e.now().UTC().Format("20060102T150405.000000000"),
+		e.seq,
+		fileSuffix,
+	)
+	e.seq++
+
+	f, err := os.OpenFile(filepath.Join(e.conf.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("file exporter: %w", err)
+	}
+	e.file, e.size = f, 0
+	return nil
+}
+
+// rotate closes the current file and deletes the oldest files if the total
+// size limit is exceeded.
+//
+// The mu lock needs to be held by the caller.
+func (e *fileExporter) rotate() error {
+	err := e.close()
+	return errors.Join(err, e.prune())
+}
+
+// close closes the current file, syncing it based on the sync policy.
+//
+// The mu lock needs to be held by the caller.
+func (e *fileExporter) close() error {
+	if e.file == nil {
+		return nil
+	}
+
+	var err error
+	if e.conf.Sync == FileSyncRotate {
+		err = e.file.Sync()
+	}
+	err = errors.Join(err, e.file.Close())
+	e.file, e.size = nil, 0
+	if err != nil {
+		return fmt.Errorf("file exporter: %w", err)
+	}
+	return nil
+}
+
+// prune deletes the oldest closed files until their total size no longer
+// exceeds the maximum total size.
+//
+// The mu lock needs to be held by the caller.
+func (e *fileExporter) prune() error {
+	entries, err := os.ReadDir(e.conf.Dir)
+	if err != nil {
+		return fmt.Errorf("file exporter: %w", err)
+	}
+
+	type file struct {
+		name string
+		size int64
+	}
+	var (
+		files []file
+		total int64
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{name: name, size: info.Size()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	for _, f := range files {
+		if total <= e.conf.MaxTotalSize {
+			break
+		}
+		if rmErr := os.Remove(filepath.Join(e.conf.Dir, f.name)); rmErr != nil {
+			err = errors.Join(err, rmErr)
+			continue
+		}
+		total -= f.size
+	}
+	if err != nil {
+		return fmt.Errorf("file exporter: %w", err)
+	}
+	return nil
+}
+
+// Shutdown closes the current file.
+func (e *fileExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopped = true
+	return e.close()
+}
+
+// fanoutExporter is an [sdk.SpanExporter] that exports spans with multiple
+// exporters.
+type fanoutExporter []sdk.SpanExporter
+
+func (e fanoutExporter) ExportSpans(ctx context.Context, spans []sdk.ReadOnlySpan) error {
+	var err error
+	for _, exp := range e {
+		err = errors.Join(err, exp.ExportSpans(ctx, spans))
+	}
+	return err
+}
+
+func (e fanoutExporter) Shutdown(ctx context.Context) error {
+	var err error
+	for _, exp := range e {
+		err = errors.Join(err, exp.Shutdown(ctx))
+	}
+	return err
+}
+
+// convertSpans returns spans converted to pdata.
+func convertSpans(spans []sdk.ReadOnlySpan) ptrace.Traces {
+	td := ptrace.NewTraces()
+
+	resources := make(map[*resource.Resource]ptrace.ResourceSpans)
+	scopes := make(map[*resource.Resource]map[instrumentation.Scope]ptrace.ScopeSpans)
+	for _, s := range spans {
+		res := s.Resource()
+		rs, ok := resources[res]
+		if !ok {
+			rs = td.ResourceSpans().AppendEmpty()
+			if res != nil {
+				rs.SetSchemaUrl(res.SchemaURL())
+				pdataconv.Attributes(rs.Resource().Attributes(), res.Attributes()...)
+			}
+			resources[res] = rs
+			scopes[res] = make(map[instrumentation.Scope]ptrace.ScopeSpans)
+		}
+
+		is := s.InstrumentationScope()
+		ss, ok := scopes[res][is]
+		if !ok {
+			ss = rs.ScopeSpans().AppendEmpty()
+			ss.SetSchemaUrl(is.SchemaURL)
+			ss.Scope().SetName(is.Name)
+			ss.Scope().SetVersion(is.Version)
+			pdataconv.Attributes(ss.Scope().Attributes(), is.Attributes.ToSlice()...)
+			scopes[res][is] = ss
+		}
+
+		convertSpan(ss.Spans().AppendEmpty(), s)
+	}
+	return td
+}
+
+func convertSpan(dest ptrace.Span, s sdk.ReadOnlySpan) {
+	sc := s.SpanContext()
+	dest.SetTraceID(pcommon.TraceID(sc.TraceID()))
+	dest.SetSpanID(pcommon.SpanID(sc.SpanID()))
+	dest.TraceState().FromRaw(sc.TraceState().String())
+	dest.SetFlags(uint32(sc.TraceFlags()))
+	if p := s.Parent(); p.SpanID().IsValid() {
+		dest.SetParentSpanID(pcommon.SpanID(p.SpanID()))
+	}
+
+	dest.SetName(s.Name())
+	dest.SetKind(ptrace.SpanKind(s.SpanKind())) // nolint: gosec  // Same values.
+	dest.SetStartTimestamp(pcommon.NewTimestampFromTime(s.StartTime()))
+	dest.SetEndTimestamp(pcommon.NewTimestampFromTime(s.EndTime()))
+	pdataconv.Attributes(dest.Attributes(), s.Attributes()...)
+	dest.SetDroppedAttributesCount(uint32(s.DroppedAttributes())) // nolint: gosec  // Non-negative.
+
+	for _, e := range s.Events() {
+		event := dest.Events().AppendEmpty()
+		event.SetName(e.Name)
+		event.SetTimestamp(pcommon.NewTimestampFromTime(e.Time))
+		pdataconv.Attributes(event.Attributes(), e.Attributes...)
+		event.SetDroppedAttributesCount(uint32(e.DroppedAttributeCount)) // nolint: gosec  // Non-negative.
+	}
+	dest.SetDroppedEventsCount(uint32(s.DroppedEvents())) // nolint: gosec  // Non-negative.
+
+	for _, l := range s.Links() {
+		link := dest.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID(l.SpanContext.TraceID()))
+		link.SetSpanID(pcommon.SpanID(l.SpanContext.SpanID()))
+		link.TraceState().FromRaw(l.SpanContext.TraceState().String())
+		link.SetFlags(uint32(l.SpanContext.TraceFlags()))
+		pdataconv.Attributes(link.Attributes(), l.Attributes...)
+		link.SetDroppedAttributesCount(uint32(l.DroppedAttributeCount)) // nolint: gosec  // Non-negative.
+	}
+	dest.SetDroppedLinksCount(uint32(s.DroppedLinks())) // nolint: gosec  // Non-negative.
+
+	status := s.Status()
+	switch status.Code {
+	case codes.Ok:
+		dest.Status().SetCode(ptrace.StatusCodeOk)
+	case codes.Error:
+		dest.Status().SetCode(ptrace.StatusCodeError)
+		dest.Status().SetMessage(status.Description)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testSpans(names ...string) []sdk.ReadOnlySpan {
+	res := resource.NewSchemaless(attribute.String("service.name", "test"))
+	scope := instrumentation.Scope{Name: "go.opentelemetry.io/auto/net/http/server", Version: "v0.23.0"}
+
+	stubs := make(tracetest.SpanStubs, len(names))
+	for i, name := range names {
+		stubs[i] = tracetest.SpanStub{
+			Name: name,
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{0x1},
+				SpanID:     trace.SpanID{byte(i + 1)},
+				TraceFlags: trace.FlagsSampled,
+			}),
+			Parent: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: trace.TraceID{0x1},
+				SpanID:  trace.SpanID{0xff},
+			}),
+			SpanKind:             trace.SpanKindServer,
+			StartTime:            time.Unix(1000, 0),
+			EndTime:              time.Unix(1001, 0),
+			Attributes:           []attribute.KeyValue{attribute.Int("http.response.status_code", 500)},
+			Events:               []sdk.Event{{Name: "event", Time: time.Unix(1000, 5)}},
+			Status:               sdk.Status{Code: codes.Error, Description: "failed"},
+			Resource:             res,
+			InstrumentationScope: scope,
+		}
+	}
+	return stubs.Snapshots()
+}
+
+// readFiles returns the traces in the files written to dir, in order.
+func readFiles(t *testing.T, dir string) (files []string, traces []ptrace.Traces) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileSuffix))
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	// The otlpjsonfile receiver of the OpenTelemetry Collector decodes each
+	// line with this unmarshaler.
+	var u ptrace.JSONUnmarshaler
+	for _, name := range files {
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			td, err := u.UnmarshalTraces(scanner.Bytes())
+			require.NoError(t, err)
+			traces = append(traces, td)
+		}
+		require.NoError(t, scanner.Err())
+		require.NoError(t, f.Close())
+	}
+	return files, traces
+}
+
+func TestFileExporter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "traces")
+	exp, err := newFileExporter(FileConfig{Dir: dir})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, exp.ExportSpans(ctx, testSpans("span0", "span1")))
+	require.NoError(t, exp.ExportSpans(ctx, nil))
+	require.NoError(t, exp.ExportSpans(ctx, testSpans("span2")))
+	require.NoError(t, exp.Shutdown(ctx))
+	require.NoError(t, exp.ExportSpans(ctx, testSpans("dropped")))
+
+	files, traces := readFiles(t, dir)
+	require.Len(t, files, 1)
+	require.Len(t, traces, 2)
+
+	td := traces[0]
+	require.Equal(t, 1, td.ResourceSpans().Len())
+	rs := td.ResourceSpans().At(0)
+	svc, ok := rs.Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "test", svc.Str())
+
+	require.Equal(t, 1, rs.ScopeSpans().Len())
+	ss := rs.ScopeSpans().At(0)
+	assert.Equal(t, "go.opentelemetry.io/auto/net/http/server", ss.Scope().Name())
+	assert.Equal(t, "v0.23.0", ss.Scope().Version())
+
+	require.Equal(t, 2, ss.Spans().Len())
+	span := ss.Spans().At(1)
+	assert.Equal(t, "span1", span.Name())
+	assert.Equal(t, ptrace.SpanKindServer, span.Kind())
+	assert.Equal(t, [16]byte{0x1}, [16]byte(span.TraceID()))
+	assert.Equal(t, [8]byte{0x2}, [8]byte(span.SpanID()))
+	assert.Equal(t, [8]byte{0xff}, [8]byte(span.ParentSpanID()))
+	assert.Equal(t, time.Unix(1000, 0).UTC(), span.StartTimestamp().AsTime())
+	assert.Equal(t, time.Unix(1001, 0).UTC(), span.EndTimestamp().AsTime())
+	code, ok := span.Attributes().Get("http.response.status_code")
+	require.True(t, ok)
+	assert.Equal(t, int64(500), code.Int())
+	require.Equal(t, 1, span.Events().Len())
+	assert.Equal(t, "event", span.Events().At(0).Name())
+	assert.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	assert.Equal(t, "failed", span.Status().Message())
+
+	assert.Equal(t, "span2", traces[1].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	// Determine the size of a line to size the limits.
+	var m ptrace.JSONMarshaler
+	line, err := m.MarshalTraces(convertSpans(testSpans("span")))
+	require.NoError(t, err)
+	lineSize := int64(len(line) + 1)
+
+	exp, err := newFileExporter(FileConfig{
+		Dir:          dir,
+		MaxSize:      2 * lineSize,
+		MaxTotalSize: 5 * lineSize,
+		Sync:         FileSyncAlways,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for range 7 {
+		require.NoError(t, exp.ExportSpans(ctx, testSpans("span")))
+	}
+	// Files hold 2, 2, 2, and 1 lines. The oldest file is deleted when the
+	// third is rotated to keep the total below 5 lines.
+	files, traces := readFiles(t, dir)
+	assert.Len(t, files, 3)
+	assert.Len(t, traces, 5)
+
+	for range 2 {
+		require.NoError(t, exp.ExportSpans(ctx, testSpans("span")))
+	}
+	files, traces = readFiles(t, dir)
+	assert.Len(t, files, 3)
+	assert.Len(t, traces, 5)
+	require.NoError(t, exp.Shutdown(ctx))
+
+	// Files from a previous run count towards the total size.
+	exp, err = newFileExporter(FileConfig{Dir: dir, MaxTotalSize: 2 * lineSize})
+	require.NoError(t, err)
+	files, traces = readFiles(t, dir)
+	assert.Len(t, files, 1)
+	assert.Len(t, traces, 1)
+	require.NoError(t, exp.Shutdown(ctx))
+}
+
+func TestNewFileExporterEmptyDir(t *testing.T) {
+	_, err := newFileExporter(FileConfig{})
+	assert.Error(t, err)
+}
+
+func TestParseFileSyncPolicy(t *testing.T) {
+	for in, want := range map[string]FileSyncPolicy{
+		"":       FileSyncRotate,
+		"rotate": FileSyncRotate,
+		"Always": FileSyncAlways,
+		"never":  FileSyncNever,
+	} {
+		got, err := parseFileSyncPolicy(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := parseFileSyncPolicy("sometimes")
+	assert.Error(t, err)
+}
+
+func TestFileConfigFromEnv(t *testing.T) {
+	_, ok, err := fileConfigFromEnv()
+	require.NoError(t, err)
+	assert.False(t, ok, "enabled without directory")
+
+	t.Setenv(envFileDirKey, "/tmp/traces")
+	t.Setenv(envFileMaxSizeKey, "1024")
+	t.Setenv(envFileMaxTotalSizeKey, "4096")
+	t.Setenv(envFileSyncKey, "never")
+	fc, ok, err := fileConfigFromEnv()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, FileConfig{
+		Dir:          "/tmp/traces",
+		MaxSize:      1024,
+		MaxTotalSize: 4096,
+		Sync:         FileSyncNever,
+	}, fc)
+
+	t.Setenv(envFileMaxSizeKey, "1KiB")
+	t.Setenv(envFileSyncKey, "sometimes")
+	_, ok, err = fileConfigFromEnv()
+	assert.ErrorContains(t, err, envFileMaxSizeKey)
+	assert.ErrorContains(t, err, "sometimes")
+	assert.False(t, ok)
+}
+
+func TestWithFileExporter(t *testing.T) {
+	dir := t.TempDir()
+	mem := tracetest.NewInMemoryExporter()
+	c, err := newConfig(context.Background(), []Option{
+		WithTraceExporter(mem),
+		WithFileExporter(FileConfig{Dir: dir}),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span")))
+	assert.Len(t, mem.GetSpans(), 1)
+	require.NoError(t, c.exporter.Shutdown(ctx))
+
+	_, traces := readFiles(t, dir)
+	assert.Len(t, traces, 1)
+}
@@ -8,6 +8,7 @@ package otelsdk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync/atomic"
@@ -16,6 +17,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdk "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 
@@ -30,7 +32,7 @@ func NewHandler(ctx context.Context, options ...Option) (*pipeline.Handler, erro
 	if err != nil {
 		return nil, err
 	}
-	return &pipeline.Handler{TraceHandler: th}, nil
+	return th.PipelineHandler(), nil
 }
 
 // TraceHandler handles telemetry produced by auto-instrumentation by processing
@@ -38,26 +40,78 @@ func NewHandler(ctx context.Context, options ...Option) (*pipeline.Handler, erro
 type TraceHandler struct {
 	logger         *slog.Logger
 	tracerProvider *sdk.TracerProvider
+	meterProvider  *meterProvider
+	loggerProvider *sdklog.LoggerProvider
+	partial        *partialSuccess
 
 	stopped atomic.Bool
 }
 
-var _ pipeline.TraceHandler = (*TraceHandler)(nil)
+var (
+	_ pipeline.TraceHandler  = (*TraceHandler)(nil)
+	_ pipeline.MetricHandler = (*TraceHandler)(nil)
+	_ pipeline.LogHandler    = (*TraceHandler)(nil)
+)
 
 // NewTraceHandler returns a new configured TraceHandler that uses the
 // OpenTelemetry SDK (go.opentelemetry.io/otel/sdk) to process and export
 // trace telemetry generated by auto-instrumentation.
+//
+// If metric exporters are configured (see [WithPrometheusExporter] and
+// [WithMetricReader]), the metrics produced by the agent, and the metrics
+// passed to HandleMetric, are exported by a MeterProvider of the returned
+// TraceHandler. The global MeterProvider is not changed. The provider is shut
+// down with the returned TraceHandler.
+//
+// If log exporters are configured (see [WithLogProcessor]), the log records
+// passed to HandleLog are exported. Otherwise, they are dropped.
+//
+// If ctx is done before the TraceHandler is created, the exporters are shut
+// down and ctx.Err() is returned.
 func NewTraceHandler(ctx context.Context, options ...Option) (*TraceHandler, error) {
 	c, err := newConfig(ctx, options)
+	if err == nil {
+		err = ctx.Err()
+	}
 	if err != nil {
-		return nil, err
+		// Release the exporters connecting, and the servers listening.
+		return nil, errors.Join(err, c.shutdown())
 	}
 
-	return newTraceHandler(c), nil
+	l := c.Logger()
+	for _, w := range c.warnings {
+		l.Warn("invalid exporter configuration", "warning", w)
+	}
+	if c.proxy != nil {
+		l.Info("exporting through proxy", "endpoint", c.endpoint.Redacted(), "proxy", c.proxy.Redacted())
+	} else if c.endpoint != nil {
+		l.Info("exporting without proxy", "endpoint", c.endpoint.Redacted())
+	}
+	h := newTraceHandler(c)
+	h.partial = c.partial
+	return h, nil
 }
 
 func newTraceHandler(c config) *TraceHandler {
-	return &TraceHandler{logger: c.Logger(), tracerProvider: c.TracerProvider()}
+	return &TraceHandler{
+		logger:         c.Logger(),
+		tracerProvider: c.TracerProvider(),
+		meterProvider:  c.meterProvider,
+		loggerProvider: c.newLoggerProvider(),
+	}
+}
+
+// PipelineHandler returns a [pipeline.Handler] handling the telemetry with h.
+// Metrics and logs are only handled if they are exported.
+func (h *TraceHandler) PipelineHandler() *pipeline.Handler {
+	ph := &pipeline.Handler{TraceHandler: h}
+	if h.meterProvider != nil {
+		ph.MetricHandler = h
+	}
+	if h.loggerProvider != nil {
+		ph.LogHandler = h
+	}
+	return ph
 }
 
 // HandleTrace the passed telemetry using the default OpenTelemetry Go SDK.
@@ -128,6 +182,14 @@ func (h *TraceHandler) HandleTrace(
 	}
 }
 
+// ExportStatus returns the status of the export of the spans handled by h.
+//
+// Rejected spans and export requests are only reported for the OTLP exporter
+// configured with environment variables (see [WithEnv]).
+func (h *TraceHandler) ExportStatus() ExportStatus {
+	return h.partial.Status()
+}
+
 // Shutdown shuts down the Handler.
 //
 // Once shut down, calls to Handle will be dropped.
@@ -136,7 +198,14 @@ func (h *TraceHandler) Shutdown(ctx context.Context) error {
 		return nil
 	}
 
-	return h.tracerProvider.Shutdown(ctx)
+	err := errors.Join(
+		h.tracerProvider.Shutdown(ctx),
+		h.meterProvider.Shutdown(ctx),
+	)
+	if h.loggerProvider != nil {
+		err = errors.Join(err, h.loggerProvider.Shutdown(ctx))
+	}
+	return err
 }
 
 func attrs(m pcommon.Map) []attribute.KeyValue {
@@ -286,3 +286,13 @@ func TestControllerTraceConcurrentSafe(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestNewTraceHandlerCanceled(t *testing.T) {
+	exp := new(shutdownExporter)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewTraceHandler(ctx, WithTraceExporter(exp))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, exp.called, "Exporter not shutdown")
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// envLogsExporterKey is the key for the environment variable value containing
+// the log exporters.
+const envLogsExporterKey = "OTEL_LOGS_EXPORTER"
+
+// WithLogProcessor returns an [Option] that will configure p to process the
+// log records produced by auto-instrumentation. Multiple processors can be
+// configured by using this option multiple times.
+func WithLogProcessor(p sdklog.Processor) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		c.logProcessors = append(c.logProcessors, p)
+		return c, nil
+	})
+}
+
+// logConfigFromEnv returns c configured with the log exporters defined by
+// environment variables.
+//
+// Logs are not exported if OTEL_LOGS_EXPORTER is not defined.
+func logConfigFromEnv(ctx context.Context, c config) (config, error) {
+	v, ok := lookupEnv(envLogsExporterKey)
+	if !ok {
+		return c, nil
+	}
+
+	var err error
+	for _, name := range strings.Split(v, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "", "none":
+		case "otlp":
+			exp, e := newOTLPLogExporter(ctx)
+			if e != nil {
+				err = errors.Join(err, e)
+				continue
+			}
+			c.logProcessors = append(c.logProcessors, sdklog.NewBatchProcessor(exp))
+		default:
+			err = errors.Join(err, fmt.Errorf("unsupported %s value: %q", envLogsExporterKey, name))
+		}
+	}
+	return c, err
+}
+
+// newOTLPLogExporter returns an OTLP log exporter configured with the
+// OTEL_EXPORTER_OTLP_LOGS_* and OTEL_EXPORTER_OTLP_* environment variables.
+func newOTLPLogExporter(ctx context.Context) (sdklog.Exporter, error) {
+	proto, ok := lookupEnv("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL")
+	if !ok {
+		proto = getEnv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	switch proto {
+	case "grpc":
+		return otlploggrpc.New(ctx)
+	case "", "http/protobuf":
+		return otlploghttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP log protocol: %q", proto)
+	}
+}
+
+// newLoggerProvider returns a [sdklog.LoggerProvider] processing log records
+// with the processors of c. If no processors are configured, nil is returned.
+func (c config) newLoggerProvider() *sdklog.LoggerProvider {
+	if len(c.logProcessors) == 0 {
+		return nil
+	}
+
+	opts := []sdklog.LoggerProviderOption{sdklog.WithResource(c.resource())}
+	for _, p := range c.logProcessors {
+		opts = append(opts, sdklog.WithProcessor(p))
+	}
+	return sdklog.NewLoggerProvider(opts...)
+}
+
+// shutdownLogProcessors shuts down the log processors of c.
+func (c config) shutdownLogProcessors(ctx context.Context) error {
+	var err error
+	for _, p := range c.logProcessors {
+		err = errors.Join(err, p.Shutdown(ctx))
+	}
+	return err
+}
+
+// HandleLog handles the passed log records using the default OpenTelemetry Go
+// SDK. The log records are dropped if no log exporter is configured.
+func (h *TraceHandler) HandleLog(scope pcommon.InstrumentationScope, url string, logs plog.LogRecordSlice) {
+	if h.loggerProvider == nil || h.stopped.Load() {
+		return
+	}
+
+	logger := h.loggerProvider.Logger(
+		scope.Name(),
+		log.WithInstrumentationVersion(scope.Version()),
+		log.WithInstrumentationAttributes(attrs(scope.Attributes())...),
+		log.WithSchemaURL(url),
+	)
+
+	var rec log.Record
+	for i := range logs.Len() {
+		lr := logs.At(i)
+
+		ctx := context.Background()
+		if !lr.TraceID().IsEmpty() && !lr.SpanID().IsEmpty() {
+			sc := trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID(lr.TraceID()),
+				SpanID:     trace.SpanID(lr.SpanID()),
+				TraceFlags: trace.TraceFlags(lr.Flags()),
+			})
+			ctx = trace.ContextWithSpanContext(ctx, sc)
+		}
+
+		rec = log.Record{}
+		rec.SetTimestamp(lr.Timestamp().AsTime())
+		rec.SetObservedTimestamp(lr.ObservedTimestamp().AsTime())
+		rec.SetSeverity(log.Severity(lr.SeverityNumber())) // nolint: gosec  // Bounded by the severity numbers.
+		rec.SetSeverityText(lr.SeverityText())
+		rec.SetBody(logVal(lr.Body()))
+		lr.Attributes().Range(func(k string, v pcommon.Value) bool {
+			rec.AddAttributes(log.KeyValue{Key: k, Value: logVal(v)})
+			return true
+		})
+
+		logger.Emit(ctx, rec)
+	}
+}
+
+func logVal(v pcommon.Value) log.Value {
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		return log.StringValue(v.Str())
+	case pcommon.ValueTypeInt:
+		return log.Int64Value(v.Int())
+	case pcommon.ValueTypeDouble:
+		return log.Float64Value(v.Double())
+	case pcommon.ValueTypeBool:
+		return log.BoolValue(v.Bool())
+	case pcommon.ValueTypeBytes:
+		return log.BytesValue(v.Bytes().AsRaw())
+	case pcommon.ValueTypeSlice:
+		s := v.Slice()
+		out := make([]log.Value, s.Len())
+		for i := range out {
+			out[i] = logVal(s.At(i))
+		}
+		return log.SliceValue(out...)
+	case pcommon.ValueTypeMap:
+		out := make([]log.KeyValue, 0, v.Map().Len())
+		v.Map().Range(func(k string, v pcommon.Value) bool {
+			out = append(out, log.KeyValue{Key: k, Value: logVal(v)})
+			return true
+		})
+		return log.MapValue(out...)
+	default:
+		return log.Value{}
+	}
+}
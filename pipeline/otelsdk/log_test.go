@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogConfigFromEnv(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Disabled", func(t *testing.T) {
+		c, err := newConfig(ctx, []Option{WithEnv()})
+		require.NoError(t, err)
+		assert.Empty(t, c.logProcessors)
+		assert.Nil(t, c.newLoggerProvider())
+	})
+
+	t.Run("OTLP", func(t *testing.T) {
+		t.Setenv(envLogsExporterKey, "otlp, none")
+
+		c, err := logConfigFromEnv(ctx, config{})
+		require.NoError(t, err)
+		assert.Len(t, c.logProcessors, 1)
+		assert.NoError(t, c.shutdownLogProcessors(ctx))
+	})
+
+	t.Run("UnsupportedProtocol", func(t *testing.T) {
+		t.Setenv(envLogsExporterKey, "otlp")
+		t.Setenv("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL", "http/json")
+
+		_, err := logConfigFromEnv(ctx, config{})
+		assert.ErrorContains(t, err, `unsupported OTLP log protocol: "http/json"`)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Setenv(envLogsExporterKey, "console")
+
+		_, err := logConfigFromEnv(ctx, config{})
+		assert.ErrorContains(t, err, `unsupported OTEL_LOGS_EXPORTER value: "console"`)
+	})
+}
+
+func TestOTLPLogExporterSignalEnv(t *testing.T) {
+	ctx := context.Background()
+	generic, genericReqs := otlpServer(t)
+	signal, signalReqs := otlpServer(t)
+
+	t.Run("Generic", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", generic)
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "key=generic")
+
+		exp, err := newOTLPLogExporter(ctx)
+		require.NoError(t, err)
+		require.NoError(t, exp.Export(ctx, []sdklog.Record{{}}))
+		require.NoError(t, exp.Shutdown(ctx))
+		assert.Equal(t, otlpRequest{path: "/v1/logs", key: "generic"}, <-genericReqs)
+	})
+
+	t.Run("Signal", func(t *testing.T) {
+		setOTLPEnv(t, "LOGS", generic, signal+"/custom/logs")
+
+		exp, err := newOTLPLogExporter(ctx)
+		require.NoError(t, err)
+		require.NoError(t, exp.Export(ctx, []sdklog.Record{{}}))
+		require.NoError(t, exp.Shutdown(ctx))
+		assert.Equal(t, otlpRequest{path: "/custom/logs", key: "signal"}, <-signalReqs)
+		assert.Never(t, func() bool { return len(genericReqs) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+}
+
+// logRecorder is a [sdklog.Processor] recording the log records emitted.
+type logRecorder struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (r *logRecorder) OnEmit(_ context.Context, rec *sdklog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec.Clone())
+	return nil
+}
+
+func (*logRecorder) Shutdown(context.Context) error   { return nil }
+func (*logRecorder) ForceFlush(context.Context) error { return nil }
+
+func TestTraceHandlerHandleLog(t *testing.T) {
+	rec := &logRecorder{}
+	h, err := NewTraceHandler(context.Background(), WithServiceName(service), WithLogProcessor(rec))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = h.Shutdown(context.Background()) })
+
+	ph := h.PipelineHandler()
+	require.Equal(t, h, ph.LogHandler)
+
+	ts := time.Unix(0, 1000)
+	traceID := trace.TraceID{1}
+	spanID := trace.SpanID{2}
+
+	logs := plog.NewLogRecordSlice()
+	lr := logs.AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(ts))
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.SetSeverityText("WARN")
+	lr.Body().SetStr("hello")
+	lr.SetTraceID(pcommon.TraceID(traceID))
+	lr.SetSpanID(pcommon.SpanID(spanID))
+	lr.SetFlags(plog.LogRecordFlags(trace.FlagsSampled))
+	lr.Attributes().PutStr("key", "value")
+	lr.Attributes().PutInt("n", 1)
+	logs.AppendEmpty().Body().SetStr("no span")
+
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("go.opentelemetry.io/auto/log/slog/internal")
+	scope.SetVersion("v0.1.0")
+	ph.WithScope(scope, "").Log(logs)
+
+	require.Len(t, rec.records, 2)
+
+	got := rec.records[0]
+	assert.Equal(t, "go.opentelemetry.io/auto/log/slog/internal", got.InstrumentationScope().Name)
+	assert.Equal(t, "v0.1.0", got.InstrumentationScope().Version)
+	assert.True(t, ts.Equal(got.Timestamp()), "timestamp")
+	assert.Equal(t, log.SeverityWarn, got.Severity())
+	assert.Equal(t, "WARN", got.SeverityText())
+	assert.Equal(t, log.StringValue("hello"), got.Body())
+	assert.Equal(t, traceID, got.TraceID())
+	assert.Equal(t, spanID, got.SpanID())
+	assert.Equal(t, trace.FlagsSampled, got.TraceFlags())
+
+	var kvs []log.KeyValue
+	got.WalkAttributes(func(kv log.KeyValue) bool {
+		kvs = append(kvs, kv)
+		return true
+	})
+	assert.Equal(t, []log.KeyValue{log.String("key", "value"), log.Int64("n", 1)}, kvs)
+
+	got = rec.records[1]
+	assert.Equal(t, log.StringValue("no span"), got.Body())
+	assert.False(t, got.TraceID().IsValid())
+
+	// Log records are dropped once shut down.
+	require.NoError(t, h.Shutdown(context.Background()))
+	h.HandleLog(scope, "", logs)
+	assert.Len(t, rec.records, 2)
+}
+
+func TestPipelineHandlerNoLogs(t *testing.T) {
+	h, err := NewHandler(context.Background(), WithServiceName(service))
+	require.NoError(t, err)
+	assert.Nil(t, h.LogHandler)
+	assert.NoError(t, h.TraceHandler.(*TraceHandler).Shutdown(context.Background()))
+}
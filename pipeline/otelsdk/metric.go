@@ -0,0 +1,390 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	promexporter "go.opentelemetry.io/otel/exporters/prometheus"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	// envMetricsExporterKey is the key for the environment variable value
+	// containing the metric exporters.
+	envMetricsExporterKey = "OTEL_METRICS_EXPORTER"
+	// envPrometheusHostKey is the key for the environment variable value
+	// containing the host the Prometheus exporter listens on.
+	envPrometheusHostKey = "OTEL_EXPORTER_PROMETHEUS_HOST"
+	// envPrometheusPortKey is the key for the environment variable value
+	// containing the port the Prometheus exporter listens on.
+	envPrometheusPortKey = "OTEL_EXPORTER_PROMETHEUS_PORT"
+)
+
+// DefaultPrometheusAddr is the default address the Prometheus exporter
+// listens on.
+const DefaultPrometheusAddr = "localhost:9464"
+
+// WithPrometheusExporter returns an [Option] that will serve the metrics
+// produced by the agent in the Prometheus text format at the /metrics path of
+// an HTTP server listening on addr. If addr is empty, DefaultPrometheusAddr
+// is used.
+//
+// Metric names and units are converted following the OpenTelemetry
+// Prometheus compatibility specification, and the resource is exposed as the
+// target_info metric. The Prometheus exporter can be used in addition to
+// readers configured with [WithMetricReader].
+//
+// If OTEL_METRICS_EXPORTER is defined, this option will conflict with
+// [WithEnv]. If both are used, the last one provided will be used.
+func WithPrometheusExporter(addr string) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		if addr == "" {
+			addr = DefaultPrometheusAddr
+		}
+		c.prometheusAddr = addr
+		return c, nil
+	})
+}
+
+// WithMetricReader returns an [Option] that will configure r to read the
+// metrics produced by the agent. Multiple readers can be configured by using
+// this option multiple times.
+func WithMetricReader(r metric.Reader) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		c.metricReaders = append(c.metricReaders, r)
+		return c, nil
+	})
+}
+
+// WithMetricView returns an [Option] that will apply views to the metrics
+// produced by the agent. Views can be used to change the aggregation of an
+// instrument, e.g. the bucket boundaries of the rpc.server.duration histogram,
+// or to drop it. Multiple views can be configured by using this option
+// multiple times.
+//
+// The temporality of the exported metrics is defined by the reader, see
+// [WithMetricReader].
+func WithMetricView(views ...metric.View) Option {
+	return fnOpt(func(_ context.Context, c config) (config, error) {
+		c.metricViews = append(c.metricViews, views...)
+		return c, nil
+	})
+}
+
+// metricConfigFromEnv returns c configured with the metric exporters defined
+// by environment variables.
+//
+// Metrics are not exported if OTEL_METRICS_EXPORTER is not defined.
+func metricConfigFromEnv(ctx context.Context, c config) (config, error) {
+	v, ok := lookupEnv(envMetricsExporterKey)
+	if !ok {
+		return c, nil
+	}
+
+	var err error
+	c.prometheusAddr = ""
+	for _, name := range strings.Split(v, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "", "none":
+		case "prometheus":
+			host, port := getEnv(envPrometheusHostKey), getEnv(envPrometheusPortKey)
+			if host == "" {
+				host = "localhost"
+			}
+			if port == "" {
+				port = "9464"
+			}
+			c.prometheusAddr = net.JoinHostPort(host, port)
+		case "otlp":
+			exp, e := newOTLPMetricExporter(ctx)
+			if e != nil {
+				err = errors.Join(err, e)
+				continue
+			}
+			c.metricReaders = append(c.metricReaders, metric.NewPeriodicReader(exp))
+		default:
+			err = errors.Join(err, fmt.Errorf("unsupported %s value: %q", envMetricsExporterKey, name))
+		}
+	}
+	return c, err
+}
+
+// newOTLPMetricExporter returns an OTLP metric exporter configured with the
+// OTEL_EXPORTER_OTLP_METRICS_* and OTEL_EXPORTER_OTLP_* environment
+// variables.
+func newOTLPMetricExporter(ctx context.Context) (metric.Exporter, error) {
+	proto, ok := lookupEnv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+	if !ok {
+		proto = getEnv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	switch proto {
+	case "grpc":
+		return otlpmetricgrpc.New(ctx)
+	case "", "http/protobuf":
+		return otlpmetrichttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP metric protocol: %q", proto)
+	}
+}
+
+// meterProvider is the [metric.MeterProvider] used for the metrics produced
+// by the agent, and the Prometheus HTTP server serving them if enabled.
+type meterProvider struct {
+	*metric.MeterProvider
+
+	server *http.Server
+}
+
+// newMeterProvider returns a meterProvider reading metrics with the readers
+// of c. If no readers are configured, nil is returned.
+func (c config) newMeterProvider() (*meterProvider, error) {
+	readers := c.metricReaders
+	var server *http.Server
+	if c.prometheusAddr != "" {
+		reg := prometheus.NewRegistry()
+		exp, err := promexporter.New(promexporter.WithRegisterer(reg))
+		if err != nil {
+			return nil, err
+		}
+
+		server, err = servePrometheus(c.prometheusAddr, reg, c.Logger())
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers[:len(readers):len(readers)], exp)
+	}
+	if len(readers) == 0 {
+		return nil, nil
+	}
+
+	opts := []metric.Option{metric.WithResource(c.resource())}
+	if len(c.metricViews) > 0 {
+		opts = append(opts, metric.WithView(c.metricViews...))
+	}
+	for _, r := range readers {
+		opts = append(opts, metric.WithReader(r))
+	}
+	return &meterProvider{
+		MeterProvider: metric.NewMeterProvider(opts...),
+		server:        server,
+	}, nil
+}
+
+// servePrometheus serves the metrics registered with reg at the /metrics path
+// of an HTTP server listening on addr.
+func servePrometheus(addr string, reg *prometheus.Registry, l *slog.Logger) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus exporter: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{
+		Addr:              ln.Addr().String(),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.Error("prometheus exporter server failed", "error", err)
+		}
+	}()
+	l.Info("serving Prometheus metrics", "address", server.Addr, "path", "/metrics")
+	return server, nil
+}
+
+// meter returns the [otelmetric.Meter] of the metrics produced by the agent
+// in this package. A no-op meter is returned if p is nil, the metrics are not
+// exported.
+func (p *meterProvider) meter() otelmetric.Meter {
+	if p == nil {
+		return noop.Meter{}
+	}
+	return p.Meter("go.opentelemetry.io/auto/pipeline/otelsdk")
+}
+
+// Shutdown shuts down the meter provider and the Prometheus HTTP server.
+func (p *meterProvider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	err := p.MeterProvider.Shutdown(ctx)
+	if p.server != nil {
+		err = errors.Join(err, p.server.Shutdown(ctx))
+	}
+	return err
+}
+
+// HandleMetric handles the passed metrics using the default OpenTelemetry Go
+// SDK: their measurements are recorded with the instruments of the meter
+// provider of h, and aggregated by it. The metrics are dropped if no metric
+// exporter is configured.
+//
+// The values of the data points of sum metrics are added to counters, or
+// up-down counters if not monotonic, and the ones of gauge metrics are
+// recorded with gauges. The sums of the histogram data points counting a
+// single measurement are recorded with histograms. Other metrics are dropped.
+func (h *TraceHandler) HandleMetric(scope pcommon.InstrumentationScope, url string, metrics pmetric.MetricSlice) {
+	if h.meterProvider == nil || h.stopped.Load() {
+		return
+	}
+
+	meter := h.meterProvider.Meter(
+		scope.Name(),
+		otelmetric.WithInstrumentationVersion(scope.Version()),
+		otelmetric.WithInstrumentationAttributes(attrs(scope.Attributes())...),
+		otelmetric.WithSchemaURL(url),
+	)
+
+	ctx := context.Background()
+	for i := range metrics.Len() {
+		m := metrics.At(i)
+
+		var err error
+		switch m.Type() {
+		case pmetric.MetricTypeSum:
+			err = recordSum(ctx, meter, m)
+		case pmetric.MetricTypeGauge:
+			err = recordGauge(ctx, meter, m)
+		case pmetric.MetricTypeHistogram:
+			err = recordHistogram(ctx, meter, m)
+		default:
+			h.logger.Debug("dropping unsupported metric", "name", m.Name(), "type", m.Type())
+		}
+		if err != nil {
+			h.logger.Error("failed to record metric", "name", m.Name(), "error", err)
+		}
+	}
+}
+
+func recordSum(ctx context.Context, meter otelmetric.Meter, m pmetric.Metric) error {
+	unit, desc := otelmetric.WithUnit(m.Unit()), otelmetric.WithDescription(m.Description())
+	dps := m.Sum().DataPoints()
+	if dps.Len() == 0 {
+		return nil
+	}
+
+	var (
+		addInt   func(context.Context, int64, ...otelmetric.AddOption)
+		addFloat func(context.Context, float64, ...otelmetric.AddOption)
+		err      error
+	)
+	// The type of the instrument is the one of the first data point.
+	isInt := dps.At(0).ValueType() == pmetric.NumberDataPointValueTypeInt
+	switch {
+	case isInt && m.Sum().IsMonotonic():
+		var c otelmetric.Int64Counter
+		c, err = meter.Int64Counter(m.Name(), unit, desc)
+		addInt = c.Add
+	case isInt:
+		var c otelmetric.Int64UpDownCounter
+		c, err = meter.Int64UpDownCounter(m.Name(), unit, desc)
+		addInt = c.Add
+	case m.Sum().IsMonotonic():
+		var c otelmetric.Float64Counter
+		c, err = meter.Float64Counter(m.Name(), unit, desc)
+		addFloat = c.Add
+	default:
+		var c otelmetric.Float64UpDownCounter
+		c, err = meter.Float64UpDownCounter(m.Name(), unit, desc)
+		addFloat = c.Add
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range dps.Len() {
+		dp := dps.At(i)
+		opt := otelmetric.WithAttributes(attrs(dp.Attributes())...)
+		if isInt {
+			addInt(ctx, intValue(dp), opt)
+		} else {
+			addFloat(ctx, floatValue(dp), opt)
+		}
+	}
+	return nil
+}
+
+func recordGauge(ctx context.Context, meter otelmetric.Meter, m pmetric.Metric) error {
+	unit, desc := otelmetric.WithUnit(m.Unit()), otelmetric.WithDescription(m.Description())
+	dps := m.Gauge().DataPoints()
+	if dps.Len() == 0 {
+		return nil
+	}
+
+	// The type of the instrument is the one of the first data point.
+	if dps.At(0).ValueType() == pmetric.NumberDataPointValueTypeInt {
+		g, err := meter.Int64Gauge(m.Name(), unit, desc)
+		if err != nil {
+			return err
+		}
+		for i := range dps.Len() {
+			dp := dps.At(i)
+			g.Record(ctx, intValue(dp), otelmetric.WithAttributes(attrs(dp.Attributes())...))
+		}
+		return nil
+	}
+
+	g, err := meter.Float64Gauge(m.Name(), unit, desc)
+	if err != nil {
+		return err
+	}
+	for i := range dps.Len() {
+		dp := dps.At(i)
+		g.Record(ctx, floatValue(dp), otelmetric.WithAttributes(attrs(dp.Attributes())...))
+	}
+	return nil
+}
+
+func recordHistogram(ctx context.Context, meter otelmetric.Meter, m pmetric.Metric) error {
+	hist, err := meter.Float64Histogram(
+		m.Name(),
+		otelmetric.WithUnit(m.Unit()),
+		otelmetric.WithDescription(m.Description()),
+	)
+	if err != nil {
+		return err
+	}
+	dps := m.Histogram().DataPoints()
+	for i := range dps.Len() {
+		dp := dps.At(i)
+		if dp.Count() != 1 || !dp.HasSum() {
+			// The measurements of aggregated data points are not known.
+			continue
+		}
+		hist.Record(ctx, dp.Sum(), otelmetric.WithAttributes(attrs(dp.Attributes())...))
+	}
+	return nil
+}
+
+func intValue(dp pmetric.NumberDataPoint) int64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeDouble {
+		return int64(dp.DoubleValue())
+	}
+	return dp.IntValue()
+}
+
+func floatValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
@@ -0,0 +1,281 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestWithPrometheusExporter(t *testing.T) {
+	reader := metric.NewManualReader()
+	c, err := newConfig(context.Background(), []Option{
+		WithServiceName("test_service"),
+		WithPrometheusExporter("127.0.0.1:0"),
+		WithMetricReader(reader),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.meterProvider)
+	t.Cleanup(func() { _ = c.meterProvider.Shutdown(context.Background()) })
+
+	counter, err := c.meterProvider.Meter("test").Int64Counter(
+		"test.received",
+		otelmetric.WithUnit("By"),
+	)
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	resp, err := http.Get("http://" + c.meterProvider.server.Addr + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), "test_received_bytes_total{")
+	assert.Contains(t, string(body), `target_info{`)
+	assert.Contains(t, string(body), `service_name="test_service"`)
+
+	// The OTLP reader is read concurrently with the Prometheus exporter.
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	assert.Equal(t, "test.received", rm.ScopeMetrics[0].Metrics[0].Name)
+
+	require.NoError(t, c.meterProvider.Shutdown(context.Background()))
+	_, err = http.Get("http://" + c.meterProvider.server.Addr + "/metrics")
+	assert.Error(t, err, "server not shut down")
+}
+
+func TestWithMetricView(t *testing.T) {
+	reader := metric.NewManualReader()
+	bounds := []float64{1, 10}
+	c, err := newConfig(context.Background(), []Option{
+		WithMetricReader(reader),
+		WithMetricView(metric.NewView(
+			metric.Instrument{Name: "test.duration"},
+			metric.Stream{Aggregation: metric.AggregationExplicitBucketHistogram{Boundaries: bounds}},
+		)),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.meterProvider)
+	t.Cleanup(func() { _ = c.meterProvider.Shutdown(context.Background()) })
+
+	hist, err := c.meterProvider.Meter("test").Float64Histogram("test.duration")
+	require.NoError(t, err)
+	hist.Record(context.Background(), 5)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	data, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "not a histogram")
+	require.Len(t, data.DataPoints, 1)
+	assert.Equal(t, bounds, data.DataPoints[0].Bounds)
+	assert.Equal(t, []uint64{0, 1, 0}, data.DataPoints[0].BucketCounts)
+}
+
+func TestMetricConfigFromEnv(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Disabled", func(t *testing.T) {
+		c, err := metricConfigFromEnv(ctx, config{prometheusAddr: "localhost:0"})
+		require.NoError(t, err)
+		assert.Equal(t, "localhost:0", c.prometheusAddr, "WithPrometheusExporter")
+		assert.Empty(t, c.metricReaders)
+	})
+
+	t.Run("Prometheus", func(t *testing.T) {
+		t.Setenv(envMetricsExporterKey, "prometheus")
+		t.Setenv(envPrometheusHostKey, "127.0.0.1")
+		t.Setenv(envPrometheusPortKey, "0")
+
+		c, err := metricConfigFromEnv(ctx, config{})
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:0", c.prometheusAddr)
+		assert.Empty(t, c.metricReaders)
+
+		t.Setenv(envPrometheusHostKey, "")
+		t.Setenv(envPrometheusPortKey, "")
+		c, err = metricConfigFromEnv(ctx, config{})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPrometheusAddr, c.prometheusAddr)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Setenv(envMetricsExporterKey, "otlp, prometheus")
+
+		c, err := metricConfigFromEnv(ctx, config{})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPrometheusAddr, c.prometheusAddr)
+		assert.Len(t, c.metricReaders, 1)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		t.Setenv(envMetricsExporterKey, "none")
+
+		c, err := metricConfigFromEnv(ctx, config{prometheusAddr: "localhost:0"})
+		require.NoError(t, err)
+		assert.Empty(t, c.prometheusAddr)
+		assert.Empty(t, c.metricReaders)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Setenv(envMetricsExporterKey, "console")
+
+		_, err := metricConfigFromEnv(ctx, config{})
+		assert.ErrorContains(t, err, `unsupported OTEL_METRICS_EXPORTER value: "console"`)
+	})
+}
+
+func TestTraceHandlerHandleMetric(t *testing.T) {
+	reader := metric.NewManualReader()
+	h, err := NewTraceHandler(context.Background(), WithServiceName(service), WithMetricReader(reader))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = h.Shutdown(context.Background()) })
+
+	ph := h.PipelineHandler()
+	require.Equal(t, h, ph.MetricHandler)
+
+	metrics := pmetric.NewMetricSlice()
+	m := metrics.AppendEmpty()
+	m.SetName("rpc.server.duration")
+	m.SetUnit("ms")
+	hist := m.SetEmptyHistogram()
+	hist.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	for _, ms := range []float64{2.5, 5} {
+		dp := hist.DataPoints().AppendEmpty()
+		dp.SetCount(1)
+		dp.SetSum(ms)
+		dp.Attributes().PutStr("rpc.system", "grpc")
+	}
+	// Aggregated data points are dropped.
+	dp := hist.DataPoints().AppendEmpty()
+	dp.SetCount(2)
+	dp.SetSum(100)
+
+	m = metrics.AppendEmpty()
+	m.SetName("requests")
+	sum := m.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.DataPoints().AppendEmpty().SetIntValue(3)
+
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("go.opentelemetry.io/auto/google.golang.org/grpc/server")
+	scope.SetVersion("v0.1.0")
+	ph.WithScope(scope, "").Metric(metrics)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	sm := rm.ScopeMetrics[0]
+	assert.Equal(t, "go.opentelemetry.io/auto/google.golang.org/grpc/server", sm.Scope.Name)
+	assert.Equal(t, "v0.1.0", sm.Scope.Version)
+	require.Len(t, sm.Metrics, 2)
+
+	assert.Equal(t, "ms", sm.Metrics[0].Unit)
+	histData, ok := sm.Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "not a histogram")
+	require.Len(t, histData.DataPoints, 1)
+	assert.Equal(t, uint64(2), histData.DataPoints[0].Count)
+	assert.InDelta(t, 7.5, histData.DataPoints[0].Sum, 1e-9)
+	assert.Equal(t, attribute.NewSet(attribute.String("rpc.system", "grpc")), histData.DataPoints[0].Attributes)
+
+	sumData, ok := sm.Metrics[1].Data.(metricdata.Sum[int64])
+	require.True(t, ok, "not an int64 sum")
+	require.Len(t, sumData.DataPoints, 1)
+	assert.Equal(t, int64(3), sumData.DataPoints[0].Value)
+}
+
+func TestTraceHandlerWithoutMetrics(t *testing.T) {
+	h, err := NewTraceHandler(context.Background(), WithServiceName(service))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = h.Shutdown(context.Background()) })
+
+	assert.Nil(t, h.PipelineHandler().MetricHandler)
+}
+
+func TestTraceHandlerKeepsGlobalMeterProvider(t *testing.T) {
+	global := otel.GetMeterProvider()
+
+	h, err := NewTraceHandler(context.Background(), WithServiceName(service), WithMetricReader(metric.NewManualReader()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = h.Shutdown(context.Background()) })
+
+	assert.Equal(t, global, otel.GetMeterProvider())
+}
+
+// otlpRequest is a request received by an OTLP HTTP server.
+type otlpRequest struct {
+	path, key string
+}
+
+// otlpServer returns the URL of an OTLP HTTP server sending the requests it
+// receives, with their "key" header, to the returned channel.
+func otlpServer(t *testing.T) (string, <-chan otlpRequest) {
+	t.Helper()
+
+	reqs := make(chan otlpRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		reqs <- otlpRequest{path: r.URL.Path, key: r.Header.Get("key")}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, reqs
+}
+
+// setOTLPEnv sets the generic OTEL_EXPORTER_OTLP_* environment variables to
+// export to the generic URL with the gRPC protocol, overridden by the ones of
+// signal to export to the signal URL with the HTTP one.
+func setOTLPEnv(t *testing.T, signal, generic, signalURL string) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", generic)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "key=generic")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_"+signal+"_ENDPOINT", signalURL)
+	t.Setenv("OTEL_EXPORTER_OTLP_"+signal+"_HEADERS", "key=signal")
+	t.Setenv("OTEL_EXPORTER_OTLP_"+signal+"_PROTOCOL", "http/protobuf")
+}
+
+func TestOTLPMetricExporterSignalEnv(t *testing.T) {
+	ctx := context.Background()
+	generic, genericReqs := otlpServer(t)
+	signal, signalReqs := otlpServer(t)
+
+	t.Run("Generic", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", generic)
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "key=generic")
+
+		exp, err := newOTLPMetricExporter(ctx)
+		require.NoError(t, err)
+		require.NoError(t, exp.Export(ctx, &metricdata.ResourceMetrics{}))
+		require.NoError(t, exp.Shutdown(ctx))
+		assert.Equal(t, otlpRequest{path: "/v1/metrics", key: "generic"}, <-genericReqs)
+	})
+
+	t.Run("Signal", func(t *testing.T) {
+		setOTLPEnv(t, "METRICS", generic, signal+"/custom/metrics")
+
+		exp, err := newOTLPMetricExporter(ctx)
+		require.NoError(t, err)
+		require.NoError(t, exp.Export(ctx, &metricdata.ResourceMetrics{}))
+		require.NoError(t, exp.Shutdown(ctx))
+		assert.Equal(t, otlpRequest{path: "/custom/metrics", key: "signal"}, <-signalReqs)
+		assert.Never(t, func() bool { return len(genericReqs) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+}
@@ -6,6 +6,7 @@ package otelsdk
 import (
 	"context"
 	"debug/buildinfo"
+	"errors"
 	"strconv"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -40,20 +41,49 @@ func NewMultiplexer(ctx context.Context, options ...Option) (*Multiplexer, error
 // If an error occurs while determining process-specific resource attributes,
 // the error is logged, and a handler without those attributes is returned.
 //
+// The metrics are aggregated by the provider of the metrics produced by the
+// agent, shared by all the handlers. They do not have the resource attributes
+// of the process.
+//
 // If Shutdown has already been called on the Multiplexer, the returned handler
 // will also be in a shut down state and will not export any telemetry.
 func (m Multiplexer) Handler(pid int) *pipeline.Handler {
 	c := m.withProcResAttrs(pid)
-	return &pipeline.Handler{TraceHandler: newTraceHandler(c)}
+	return newTraceHandler(c).PipelineHandler()
+}
+
+// HandlerWithAttributes returns a new [pipeline.Handler] like [Multiplexer.Handler]
+// with the additional resource attributes attrs.
+//
+// The attrs take precedence over the resource attributes of the process and
+// of the options of the Multiplexer, including the service name.
+func (m Multiplexer) HandlerWithAttributes(pid int, attrs ...attribute.KeyValue) *pipeline.Handler {
+	c := m.withProcResAttrs(pid)
+	c.resAttrs = append(c.resAttrs[:len(c.resAttrs):len(c.resAttrs)], attrs...)
+	return newTraceHandler(c).PipelineHandler()
+}
+
+// ExportStatus returns the status of the export of the spans handled by the
+// handlers of m.
+//
+// Rejected spans and export requests are only reported for the OTLP exporter
+// configured with environment variables (see [WithEnv]).
+func (m Multiplexer) ExportStatus() ExportStatus {
+	return m.cfg.partial.Status()
 }
 
-// Shutdown gracefully shuts down the Multiplexer's span processor.
+// Shutdown gracefully shuts down the Multiplexer's span and log processors,
+// and the provider of the metrics produced by the agent.
 //
 // After Shutdown is called, any subsequent calls to Handler will return a
 // handler that is in a shut down state. These handlers will silently drop
 // telemetry and will not perform any processing or exporting.
 func (m Multiplexer) Shutdown(ctx context.Context) error {
-	return m.cfg.spanProcessor.Shutdown(ctx)
+	return errors.Join(
+		m.cfg.spanProcessor.Shutdown(ctx),
+		m.cfg.meterProvider.Shutdown(ctx),
+		m.cfg.shutdownLogProcessors(ctx),
+	)
 }
 
 // withProcResAttrs returns a copy of the Multiplexer's config with additional
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMultiplexerHandlerWithAttributes(t *testing.T) {
+	ctx := context.Background()
+	exp := newExporter()
+	m, err := NewMultiplexer(
+		ctx,
+		WithTraceExporter(exp),
+		WithServiceName("base"),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	require.NoError(t, err)
+
+	spans := ptrace.NewSpanSlice()
+	span := spans.AppendEmpty()
+	span.SetName("span")
+	span.SetTraceID(pcommon.TraceID(trace.TraceID{0x1}))
+	span.SetSpanID(pcommon.SpanID(trace.SpanID{0x1}))
+	scope := pcommon.NewInstrumentationScope()
+
+	// The process does not exist, no process attributes are added.
+	m.HandlerWithAttributes(-1, semconv.ServiceName("svc"), semconv.ProcessPID(7)).TraceHandler.HandleTrace(scope, "", spans)
+	m.Handler(-1).TraceHandler.HandleTrace(scope, "", spans)
+	require.NoError(t, m.Shutdown(ctx))
+
+	got := exp.GetSpans()
+	require.Len(t, got, 2)
+	service := func(i int) string {
+		v, _ := got[i].Resource.Set().Value(semconv.ServiceNameKey)
+		return v.AsString()
+	}
+	assert.Equal(t, "svc", service(0))
+	pid, _ := got[0].Resource.Set().Value(semconv.ProcessPIDKey)
+	assert.Equal(t, attribute.Int64Value(7), pid)
+	assert.Equal(t, "base", service(1))
+}
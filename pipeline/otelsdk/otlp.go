@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+)
+
+// otlpHooks are the hooks of the OTLP trace exporter on its requests and
+// their responses.
+type otlpHooks struct {
+	// auth authenticates requests with its tokens if not nil.
+	auth tokenSource
+	// partial records the partial success responses if not nil.
+	partial *partialSuccess
+}
+
+// roundTripper returns base wrapped by the HTTP hooks of h.
+func (h otlpHooks) roundTripper(base http.RoundTripper) http.RoundTripper {
+	if h.partial != nil {
+		base = &partialTransport{base: base, partial: h.partial}
+	}
+	if h.auth != nil {
+		base = &authTransport{base: base, src: h.auth}
+	}
+	return base
+}
+
+// dialOptions returns the gRPC dial options of the hooks of h. If secure is
+// true, credentials are only sent over connections with transport security.
+func (h otlpHooks) dialOptions(secure bool) []grpc.DialOption {
+	var opts []grpc.DialOption
+	if h.auth != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(perRPCCredentials{src: h.auth, secure: secure}))
+	}
+	if h.partial != nil {
+		opts = append(opts, grpc.WithUnaryInterceptor(h.partial.interceptor))
+	}
+	return opts
+}
+
+// newOTLPExporter returns an OTLP trace exporter configured with environment
+// variables that connects to endpoint with the hooks of h.
+func newOTLPExporter(ctx context.Context, endpoint *url.URL, grpcProto bool, h otlpHooks) (sdk.SpanExporter, error) {
+	if grpcProto {
+		// WithDialOption replaces the previously passed options.
+		return otlptracegrpc.New(ctx, otlptracegrpc.WithDialOption(h.dialOptions(!otlpInsecure(endpoint))...))
+	}
+
+	// The exporter does not apply its TLS and timeout configuration to a
+	// client passed to it, apply them here.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConf, err := otlpTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConf
+
+	timeout := 10 * time.Second
+	if v := otlpEnv("TIMEOUT"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid OTLP timeout: %q", v)
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+	client := &http.Client{Transport: h.roundTripper(transport), Timeout: timeout}
+	return otlptracehttp.New(ctx, otlptracehttp.WithHTTPClient(client))
+}
+
+// otlpInsecure returns true if the OTLP trace exporter configured with
+// environment variables connects to endpoint without TLS.
+func otlpInsecure(endpoint *url.URL) bool {
+	if insecure, _ := strconv.ParseBool(otlpEnv("INSECURE")); insecure {
+		return true
+	}
+	return strings.HasPrefix(otlpEnv("ENDPOINT"), "http://") || endpoint.Scheme == "http"
+}
+
+// otlpTLSConfig returns the TLS configuration of the OTLP trace exporter
+// defined by environment variables.
+func otlpTLSConfig() (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if path := otlpEnv("CERTIFICATE"); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("OTLP certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bytes.TrimSpace(b)) {
+			return nil, fmt.Errorf("OTLP certificate: no certificates in %s", path)
+		}
+		conf.RootCAs = pool
+	}
+	certPath, keyPath := otlpEnv("CLIENT_CERTIFICATE"), otlpEnv("CLIENT_KEY")
+	if certPath != "" && keyPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("OTLP client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// partialLogInterval is the minimum interval between two logs of partial
+// success responses.
+const partialLogInterval = time.Minute
+
+// ExportStatus is the status of the export of the spans of a [TraceHandler].
+type ExportStatus struct {
+	// RejectedSpans is the number of spans the collector rejected in partial
+	// success responses.
+	RejectedSpans int64
+	// LastRejection is the message of the most recent partial success
+	// response, empty if none was received.
+	LastRejection string
+	// LastRejectionTime is the time the most recent partial success response
+	// was received.
+	LastRejectionTime time.Time
+	// LastExportTime is the time the collector last accepted an export
+	// request, zero if it never did.
+	LastExportTime time.Time
+	// LastExportError is the error of the most recent failed export request,
+	// empty if no request failed since the collector last accepted one.
+	LastExportError string
+}
+
+// partialSuccess records the partial success responses of the OTLP trace
+// exporter.
+//
+// Spans rejected by the collector are not retried. They are counted by the
+// otel.auto.exporter.rejected_spans metric, and the message of the collector
+// is logged at most once per partialLogInterval.
+type partialSuccess struct {
+	logger   *slog.Logger
+	rejected metric.Int64Counter
+	now      func() time.Time
+
+	mu     sync.Mutex
+	status ExportStatus
+	// logged is the time of the last log, and suppressed the number of
+	// responses received since.
+	logged     time.Time
+	suppressed int
+}
+
+func newPartialSuccess(l *slog.Logger, meter metric.Meter) *partialSuccess {
+	counter, err := meter.Int64Counter(
+		"otel.auto.exporter.rejected_spans",
+		metric.WithUnit("{span}"),
+		metric.WithDescription("Number of spans rejected by the collector in partial success responses."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return &partialSuccess{logger: l, rejected: counter, now: time.Now}
+}
+
+// record records a partial success response. It returns false if resp is not
+// a partial success.
+func (p *partialSuccess) record(ctx context.Context, resp *coltracepb.ExportTraceServiceResponse) bool {
+	ps := resp.GetPartialSuccess()
+	n, msg := ps.GetRejectedSpans(), ps.GetErrorMessage()
+	if n == 0 && msg == "" {
+		return false
+	}
+	if n > 0 {
+		p.rejected.Add(ctx, n)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.status.RejectedSpans += n
+	p.status.LastRejection, p.status.LastRejectionTime = msg, now
+
+	if !p.logged.IsZero() && now.Sub(p.logged) < partialLogInterval {
+		p.suppressed++
+		return true
+	}
+	p.logger.Warn(
+		"collector rejected spans",
+		"rejected", n,
+		"message", msg,
+		"total_rejected", p.status.RejectedSpans,
+		"suppressed_responses", p.suppressed,
+	)
+	p.logged, p.suppressed = now, 0
+	return true
+}
+
+// exported records the outcome of an export request.
+func (p *partialSuccess) exported(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.status.LastExportError = err.Error()
+		return
+	}
+	p.status.LastExportTime, p.status.LastExportError = p.now(), ""
+}
+
+// Status returns the export status.
+func (p *partialSuccess) Status() ExportStatus {
+	if p == nil {
+		return ExportStatus{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// interceptor is a gRPC unary client interceptor recording the partial
+// success of export responses.
+//
+// The partial success is removed from the response once recorded so the
+// exporter does not report it again to the OpenTelemetry error handler.
+func (p *partialSuccess) interceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	p.exported(err)
+	if resp, ok := reply.(*coltracepb.ExportTraceServiceResponse); ok && err == nil {
+		if p.record(ctx, resp) {
+			resp.PartialSuccess = nil
+		}
+	}
+	return err
+}
+
+// partialTransport is an [http.RoundTripper] recording the partial success of
+// export responses.
+//
+// The partial success is removed from the response once recorded so the
+// exporter does not report it again to the OpenTelemetry error handler.
+type partialTransport struct {
+	base    http.RoundTripper
+	partial *partialSuccess
+}
+
+func (t *partialTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		t.partial.exported(err)
+		return resp, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		t.partial.exported(fmt.Errorf("HTTP status %s", resp.Status))
+		return resp, nil
+	}
+	t.partial.exported(nil)
+	if resp.Header.Get("Content-Type") != "application/x-protobuf" {
+		// The exporter only decodes protobuf responses.
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg coltracepb.ExportTraceServiceResponse
+	if proto.Unmarshal(body, &msg) == nil && t.partial.record(r.Context(), &msg) {
+		msg.PartialSuccess = nil
+		if b, err := proto.Marshal(&msg); err == nil {
+			body = b
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric/noop"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+var partialResp = &coltracepb.ExportTraceServiceResponse{
+	PartialSuccess: &coltracepb.ExportTracePartialSuccess{
+		RejectedSpans: 1,
+		ErrorMessage:  "attribute limit exceeded",
+	},
+}
+
+// errHandler records the errors passed to the OpenTelemetry error handler.
+type errHandler struct{ errs []error }
+
+func (h *errHandler) Handle(err error) { h.errs = append(h.errs, err) }
+
+func withErrHandler(t *testing.T) *errHandler {
+	h := new(errHandler)
+	orig := otel.GetErrorHandler()
+	otel.SetErrorHandler(h)
+	t.Cleanup(func() { otel.SetErrorHandler(orig) })
+	return h
+}
+
+func partialConfig(t *testing.T) (config, *bytes.Buffer) {
+	var logs bytes.Buffer
+	c, err := newConfig(context.Background(), []Option{
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.partial)
+	t.Cleanup(func() { _ = c.exporter.Shutdown(context.Background()) })
+	return c, &logs
+}
+
+func TestPartialSuccessHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		b, _ := proto.Marshal(partialResp)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+	errs := withErrHandler(t)
+
+	c, logs := partialConfig(t)
+	now := time.Now()
+	c.partial.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span0", "span1")))
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span2")))
+	assert.Equal(t, 1, strings.Count(logs.String(), "collector rejected spans"), "not rate-limited")
+
+	now = now.Add(partialLogInterval)
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span3")))
+	assert.Equal(t, 2, strings.Count(logs.String(), "collector rejected spans"))
+	assert.Contains(t, logs.String(), "suppressed_responses=1")
+	assert.Contains(t, logs.String(), "attribute limit exceeded")
+
+	assert.Equal(t, ExportStatus{
+		RejectedSpans:     3,
+		LastRejection:     "attribute limit exceeded",
+		LastRejectionTime: now,
+		LastExportTime:    now,
+	}, c.partial.Status())
+	assert.Empty(t, errs.errs, "partial success reported twice")
+}
+
+type traceServer struct {
+	coltracepb.UnimplementedTraceServiceServer
+}
+
+func (traceServer) Export(context.Context, *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	return proto.Clone(partialResp).(*coltracepb.ExportTraceServiceResponse), nil
+}
+
+func TestPartialSuccessGRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(srv, traceServer{})
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+ln.Addr().String())
+	errs := withErrHandler(t)
+
+	c, logs := partialConfig(t)
+	require.NoError(t, c.exporter.ExportSpans(context.Background(), testSpans("span")))
+
+	status := c.partial.Status()
+	assert.Equal(t, int64(1), status.RejectedSpans)
+	assert.Equal(t, "attribute limit exceeded", status.LastRejection)
+	assert.Contains(t, logs.String(), "collector rejected spans")
+	assert.Empty(t, errs.errs, "partial success reported twice")
+}
+
+func TestExportStatus(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+	_ = withErrHandler(t)
+
+	c, _ := partialConfig(t)
+	now := time.Now()
+	c.partial.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	assert.Error(t, c.exporter.ExportSpans(ctx, testSpans("span")))
+	status := c.partial.Status()
+	assert.True(t, status.LastExportTime.IsZero())
+	assert.Contains(t, status.LastExportError, "400")
+
+	fail.Store(false)
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span")))
+	assert.Equal(t, ExportStatus{LastExportTime: now}, c.partial.Status())
+}
+
+func TestPartialSuccessNone(t *testing.T) {
+	p := newPartialSuccess(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), noop.Meter{})
+	assert.False(t, p.record(context.Background(), &coltracepb.ExportTraceServiceResponse{}))
+	assert.Equal(t, ExportStatus{}, p.Status())
+
+	var nilPartial *partialSuccess
+	assert.Equal(t, ExportStatus{}, nilPartial.Status())
+}
@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// proxyFromEnvironment is used for testing.
+var proxyFromEnvironment = http.ProxyFromEnvironment
+
+// otlpEnv returns the value of the OTLP trace exporter environment variable
+// with suffix. The per-signal variable takes precedence over the generic one.
+func otlpEnv(suffix string) string {
+	if v, ok := lookupEnv("OTEL_EXPORTER_OTLP_TRACES_" + suffix); ok {
+		return v
+	}
+	return getEnv("OTEL_EXPORTER_OTLP_" + suffix)
+}
+
+// otlpEndpoint returns the URL of the endpoint the OTLP trace exporter
+// configured with environment variables connects to. The scheme of the
+// returned URL is the one used to select a proxy, the gRPC exporter always
+// uses the proxy for https. Unix domain socket endpoints are returned with the
+// unix scheme.
+func otlpEndpoint() (*url.URL, error) {
+	grpc := otlpEnv("PROTOCOL") == "grpc"
+
+	endpoint := otlpEnv("ENDPOINT")
+	if endpoint == "" {
+		endpoint = "localhost:4318"
+		if grpc {
+			endpoint = "localhost:4317"
+		}
+	}
+
+	u, err := url.Parse(endpoint)
+	if err == nil && u.Scheme == "unix" {
+		return u, nil
+	}
+	if err != nil || u.Host == "" {
+		// Endpoint without a scheme (e.g. "localhost:4317").
+		u, err = url.Parse("//" + endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+		}
+		u.Scheme = "https"
+		if insecure, _ := strconv.ParseBool(otlpEnv("INSECURE")); insecure {
+			u.Scheme = "http"
+		}
+	}
+	if grpc {
+		u.Scheme = "https"
+	}
+	return u, nil
+}
+
+// otlpProxy returns the proxy the OTLP trace exporter uses to connect to
+// endpoint. If no proxy is used, nil is returned.
+func otlpProxy(endpoint *url.URL) (*url.URL, error) {
+	switch endpoint.Scheme {
+	case "http", "https":
+	default:
+		// Proxies are not used for other transports.
+		return nil, nil
+	}
+
+	proxy, err := proxyFromEnvironment(&http.Request{URL: endpoint})
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy configuration: %w", err)
+	}
+	return proxy, nil
+}
+
+// proxyExporter is an [sdk.SpanExporter] that identifies export errors as
+// occurring through a proxy.
+type proxyExporter struct {
+	sdk.SpanExporter
+
+	proxy string
+}
+
+func newProxyExporter(exp sdk.SpanExporter, proxy *url.URL) *proxyExporter {
+	return &proxyExporter{SpanExporter: exp, proxy: proxy.Redacted()}
+}
+
+func (e *proxyExporter) ExportSpans(ctx context.Context, spans []sdk.ReadOnlySpan) error {
+	if err := e.SpanExporter.ExportSpans(ctx, spans); err != nil {
+		return fmt.Errorf("export through proxy %s: %w", e.proxy, err)
+	}
+	return nil
+}
+
+// useOTLPFromEnv returns true if the trace exporter configured with
+// environment variables is an OTLP exporter.
+func useOTLPFromEnv() bool {
+	v, ok := lookupEnv(envTracesExporterKey)
+	return !ok || includesOTLP(v)
+}
+
+// includesOTLP returns true if the comma-separated exporter names include
+// otlp.
+func includesOTLP(exporters string) bool {
+	for _, name := range strings.Split(exporters, ",") {
+		if strings.TrimSpace(name) == "otlp" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,705 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultQueueMaxSize is the default maximum size of the persistent
+	// queue.
+	DefaultQueueMaxSize = 256 << 20 // 256 MiB
+
+	// queueMaxSegmentSize is the maximum size of a persistent queue segment.
+	queueMaxSegmentSize = 16 << 20 // 16 MiB
+
+	queueSegmentPrefix = "segment-"
+	queueSegmentSuffix = ".otlp"
+	queueCursorName    = "cursor"
+
+	// queueHeaderSize is the size of the header of a record: the length and
+	// the CRC-32 (Castagnoli) checksum of the payload.
+	queueHeaderSize = 8
+
+	queueExportTimeout = 30 * time.Second
+)
+
+var queueCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// queueMinRetry and queueMaxRetry bound the delay between retries of a
+// failed export of the persistent queue. Variables for testing.
+var (
+	queueMinRetry = time.Second
+	queueMaxRetry = 30 * time.Second
+)
+
+// QueueConfig configures the persistent queue.
+//
+// The persistent queue stores the spans the trace exporter fails to export
+// in Dir and exports them again, in order, once the exporter recovers. The
+// spans stored are exported after a restart of the agent.
+//
+// While the queue holds spans, new spans are appended to it instead of being
+// exported so the order is preserved. The in-memory span queue does not fill
+// up while the exporter fails, spans are only dropped from it if writing to
+// the persistent queue fails.
+//
+// Spans are delivered at least once. Spans being exported when the agent
+// stops are exported again after a restart.
+//
+// Spans the collector rejects with a non-retryable error (e.g. an invalid or
+// unauthenticated request) are dropped and logged instead of being stored.
+type QueueConfig struct {
+	// Dir is the directory the queue is stored in. It is created if it does
+	// not exist.
+	Dir string
+	// MaxSize is the maximum size, in bytes, of the queue. Once exceeded, the
+	// oldest spans are dropped. If zero, DefaultQueueMaxSize is used.
+	MaxSize int64
+	// Sync is the policy used to sync the queue to stable storage.
+	// FileSyncRotate syncs a segment of the queue when it is full or the
+	// exporter is shut down. FileSyncAlways syncs after each write, and
+	// FileSyncNever leaves syncing to the operating system. Spans not synced
+	// can be lost if the host crashes.
+	Sync FileSyncPolicy
+}
+
+// queuePos is a position in the persistent queue.
+type queuePos struct {
+	seq uint64
+	off int64
+}
+
+// queueSegment is a file of the persistent queue. It contains a sequence of
+// records each holding an OTLP protobuf encoded ExportTraceServiceRequest.
+type queueSegment struct {
+	seq  uint64
+	size int64
+}
+
+// queueExporter is an [sdk.SpanExporter] that stores the spans the next
+// exporter fails to export in a persistent queue and exports them again once
+// it recovers.
+type queueExporter struct {
+	next   sdk.SpanExporter
+	conf   QueueConfig
+	logger *slog.Logger
+
+	segmentSize int64
+
+	mu       sync.Mutex
+	segments []queueSegment
+	w        *os.File // Write segment, the last of segments if not nil.
+	r        *os.File // Read segment, the one of read.
+	read     queuePos
+	nextSeq  uint64
+	stopped  bool
+
+	wake chan struct{}
+	stop context.CancelFunc
+	done chan struct{}
+
+	marshaler   ptrace.ProtoMarshaler
+	unmarshaler ptrace.ProtoUnmarshaler
+}
+
+var _ sdk.SpanExporter = (*queueExporter)(nil)
+
+func newQueueExporter(next sdk.SpanExporter, c QueueConfig, l *slog.Logger) (*queueExporter, error) {
+	if c.Dir == "" {
+		return nil, errors.New("persistent queue: empty directory")
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = DefaultQueueMaxSize
+	}
+	if err := os.MkdirAll(c.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("persistent queue: %w", err)
+	}
+
+	e := &queueExporter{
+		next:        next,
+		conf:        c,
+		logger:      l,
+		segmentSize: min(c.MaxSize/4, queueMaxSegmentSize),
+		wake:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	if err := e.recover(); err != nil {
+		return nil, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	e.stop = stop
+	go e.run(ctx)
+	return e, nil
+}
+
+func queueSegmentName(seq uint64) string {
+	return fmt.Sprintf("%s%020d%s", queueSegmentPrefix, seq, queueSegmentSuffix)
+}
+
+// recover loads the state of the queue stored in the directory.
+func (e *queueExporter) recover() error {
+	entries, err := os.ReadDir(e.conf.Dir)
+	if err != nil {
+		return fmt.Errorf("persistent queue: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, queueSegmentPrefix) || !strings.HasSuffix(name, queueSegmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, queueSegmentPrefix), queueSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		e.segments = append(e.segments, queueSegment{seq: seq, size: info.Size()})
+	}
+	sort.Slice(e.segments, func(i, j int) bool { return e.segments[i].seq < e.segments[j].seq })
+
+	if len(e.segments) == 0 {
+		e.read = queuePos{seq: e.nextSeq}
+		return nil
+	}
+	e.nextSeq = e.segments[len(e.segments)-1].seq + 1
+	e.read = queuePos{seq: e.segments[0].seq}
+
+	if cur, ok := e.loadCursor(); ok {
+		for len(e.segments) > 0 && e.segments[0].seq < cur.seq {
+			// Already exported.
+			e.removeSegment()
+		}
+		if len(e.segments) > 0 && e.segments[0].seq == cur.seq && cur.off <= e.segments[0].size {
+			e.read = cur
+		} else if len(e.segments) > 0 {
+			e.read = queuePos{seq: e.segments[0].seq}
+		} else {
+			e.read = queuePos{seq: e.nextSeq}
+		}
+	}
+
+	var pending int64
+	for _, s := range e.segments {
+		pending += s.size
+	}
+	pending -= e.read.off
+	if pending > 0 {
+		e.logger.Info("exporting spans stored in persistent queue", "dir", e.conf.Dir, "bytes", pending)
+	}
+	return nil
+}
+
+// loadCursor returns the read position stored in the directory.
+func (e *queueExporter) loadCursor() (queuePos, bool) {
+	b, err := os.ReadFile(filepath.Join(e.conf.Dir, queueCursorName))
+	if err != nil {
+		return queuePos{}, false
+	}
+	var p queuePos
+	if _, err := fmt.Sscanf(string(b), "%d %d", &p.seq, &p.off); err != nil || p.off < 0 {
+		e.logger.Warn("ignoring invalid persistent queue cursor", "cursor", string(b))
+		return queuePos{}, false
+	}
+	return p, true
+}
+
+// storeCursor stores the read position in the directory.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) storeCursor() error {
+	path := filepath.Join(e.conf.Dir, queueCursorName)
+	tmp := path + ".tmp"
+	data := fmt.Sprintf("%d %d", e.read.seq, e.read.off)
+	if err := os.WriteFile(tmp, []byte(data), 0o640); err != nil {
+		return err
+	}
+	if e.conf.Sync == FileSyncAlways {
+		if f, err := os.Open(tmp); err == nil {
+			_ = f.Sync()
+			_ = f.Close()
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// ExportSpans exports spans with the next exporter if the queue is empty. If
+// the queue is not empty or the export fails, spans are appended to the
+// queue. Spans rejected by a non-retryable error are not appended, the error
+// is returned.
+func (e *queueExporter) ExportSpans(ctx context.Context, spans []sdk.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	empty, stopped := e.empty(), e.stopped
+	e.mu.Unlock()
+	if stopped {
+		return nil
+	}
+
+	if empty {
+		err := e.next.ExportSpans(ctx, spans)
+		if err == nil {
+			return nil
+		}
+		if !retryable(err) {
+			return err
+		}
+		e.logger.Debug("export failed, storing spans in persistent queue", "error", err)
+	}
+
+	payload, err := e.marshaler.MarshalTraces(convertSpans(spans))
+	if err != nil {
+		return fmt.Errorf("persistent queue: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		return nil
+	}
+	if err := e.append(payload); err != nil {
+		return fmt.Errorf("persistent queue: %w", err)
+	}
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// empty returns true if all the records of the queue have been read.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) empty() bool {
+	if len(e.segments) == 0 {
+		return true
+	}
+	last := e.segments[len(e.segments)-1]
+	return e.read.seq == last.seq && e.read.off >= last.size
+}
+
+// append appends a record holding payload to the queue.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) append(payload []byte) error {
+	rec := make([]byte, queueHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(rec[0:4], uint32(len(payload))) // nolint: gosec  // Bounded by the batch size.
+	binary.LittleEndian.PutUint32(rec[4:8], crc32.Checksum(payload, queueCRCTable))
+	copy(rec[queueHeaderSize:], payload)
+
+	if e.w != nil {
+		cur := e.segments[len(e.segments)-1]
+		if cur.size > 0 && cur.size+int64(len(rec)) > e.segmentSize {
+			if err := e.closeWrite(); err != nil {
+				return err
+			}
+		}
+	}
+	if e.w == nil {
+		if err := e.openWrite(); err != nil {
+			return err
+		}
+	}
+
+	cur := &e.segments[len(e.segments)-1]
+	n, err := e.w.Write(rec)
+	cur.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if e.conf.Sync == FileSyncAlways {
+		if err := e.w.Sync(); err != nil {
+			return err
+		}
+	}
+	e.prune()
+	return nil
+}
+
+// openWrite creates a new write segment.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) openWrite() error {
+	seq := e.nextSeq
+	path := filepath.Join(e.conf.Dir, queueSegmentName(seq))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	e.nextSeq++
+
+	if len(e.segments) == 0 {
+		e.read = queuePos{seq: seq}
+	}
+	e.segments = append(e.segments, queueSegment{seq: seq})
+	e.w = f
+	return nil
+}
+
+// closeWrite closes the write segment, syncing it based on the sync policy.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) closeWrite() error {
+	if e.w == nil {
+		return nil
+	}
+	var err error
+	if e.conf.Sync == FileSyncRotate {
+		err = e.w.Sync()
+	}
+	err = errors.Join(err, e.w.Close())
+	e.w = nil
+	return err
+}
+
+// prune drops the oldest segments until the size of the queue no longer
+// exceeds the maximum size. The write segment is never dropped.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) prune() {
+	var total int64
+	for _, s := range e.segments {
+		total += s.size
+	}
+
+	var dropped int64
+	for total > e.conf.MaxSize && len(e.segments) > 1 {
+		s := e.segments[0]
+		unread := s.size
+		if e.read.seq == s.seq {
+			unread -= e.read.off
+		}
+		e.removeSegment()
+		total -= s.size
+		dropped += unread
+	}
+	if dropped > 0 {
+		e.logger.Warn("persistent queue full, dropped oldest spans", "bytes", dropped)
+	}
+}
+
+// removeSegment removes the oldest segment. If it is the read segment, the
+// read position is moved to the start of the next segment.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) removeSegment() {
+	s := e.segments[0]
+	e.segments = e.segments[1:]
+	if e.read.seq == s.seq {
+		if e.r != nil {
+			_ = e.r.Close()
+			e.r = nil
+		}
+		e.read = queuePos{seq: e.nextSeq}
+		if len(e.segments) > 0 {
+			e.read.seq = e.segments[0].seq
+		}
+	}
+	path := filepath.Join(e.conf.Dir, queueSegmentName(s.seq))
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		e.logger.Error("failed to remove persistent queue segment", "error", err, "path", path)
+	}
+}
+
+// peek returns the payload of the next record to export, its position, and
+// the position following it. If the queue is empty, false is returned.
+//
+// Exported segments are removed. Corrupted records, and the rest of the
+// segment holding them, are skipped.
+func (e *queueExporter) peek() ([]byte, queuePos, queuePos, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for len(e.segments) > 0 {
+		s := e.segments[0]
+		isWrite := e.w != nil && len(e.segments) == 1
+		if e.read.off >= s.size {
+			if isWrite {
+				return nil, queuePos{}, queuePos{}, false
+			}
+			e.removeSegment()
+			continue
+		}
+
+		payload, err := e.readRecord(s)
+		if err != nil {
+			e.logger.Warn(
+				"skipping corrupted persistent queue segment",
+				"error", err,
+				"segment", queueSegmentName(s.seq),
+				"bytes", s.size-e.read.off,
+			)
+			e.read.off = s.size
+			continue
+		}
+		next := queuePos{seq: s.seq, off: e.read.off + queueHeaderSize + int64(len(payload))}
+		return payload, e.read, next, true
+	}
+	return nil, queuePos{}, queuePos{}, false
+}
+
+// readRecord reads the record at the read position of segment s.
+//
+// The mu lock needs to be held by the caller.
+func (e *queueExporter) readRecord(s queueSegment) ([]byte, error) {
+	if e.r == nil {
+		f, err := os.Open(filepath.Join(e.conf.Dir, queueSegmentName(s.seq)))
+		if err != nil {
+			return nil, err
+		}
+		e.r = f
+	}
+
+	if s.size-e.read.off < queueHeaderSize {
+		return nil, errors.New("truncated record header")
+	}
+	var header [queueHeaderSize]byte
+	if _, err := e.r.ReadAt(header[:], e.read.off); err != nil {
+		return nil, err
+	}
+	n := int64(binary.LittleEndian.Uint32(header[0:4]))
+	if n > s.size-e.read.off-queueHeaderSize {
+		return nil, errors.New("truncated record")
+	}
+	payload := make([]byte, n)
+	if _, err := e.r.ReadAt(payload, e.read.off+queueHeaderSize); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(payload, queueCRCTable) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, errors.New("checksum mismatch")
+	}
+	return payload, nil
+}
+
+// advance moves the read position from from to to, unless the read segment
+// was dropped in the meantime.
+func (e *queueExporter) advance(from, to queuePos) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.read != from {
+		return
+	}
+	e.read = to
+	if err := e.storeCursor(); err != nil {
+		e.logger.Error("failed to store persistent queue cursor", "error", err)
+	}
+}
+
+// run exports the records of the queue, in order, until ctx is done. Failed
+// exports are retried with an exponential backoff.
+func (e *queueExporter) run(ctx context.Context) {
+	defer close(e.done)
+
+	delay := queueMinRetry
+	for {
+		payload, from, to, ok := e.peek()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.wake:
+				continue
+			}
+		}
+		td, err := e.unmarshaler.UnmarshalTraces(payload)
+		if err != nil {
+			e.logger.Warn("skipping invalid persistent queue record", "error", err)
+			e.advance(from, to)
+			continue
+		}
+
+		exportCtx, cancel := context.WithTimeout(ctx, queueExportTimeout)
+		err = e.next.ExportSpans(exportCtx, readOnlySpans(td))
+		cancel()
+		if err != nil && retryable(err) {
+			e.logger.Warn("export from persistent queue failed, retrying", "error", err, "delay", delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, queueMaxRetry)
+			continue
+		}
+		if err != nil {
+			// Exporting the record again would fail the same way.
+			e.logger.Warn("dropping persistent queue record rejected by the collector", "error", err, "spans", td.SpanCount())
+		}
+		delay = queueMinRetry
+		e.advance(from, to)
+	}
+}
+
+// Shutdown stops exporting the queue, closes it, and shuts down the next
+// exporter. Spans remaining in the queue are exported after a restart.
+func (e *queueExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return nil
+	}
+	e.stopped = true
+	e.mu.Unlock()
+
+	e.stop()
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	e.mu.Lock()
+	err := e.closeWrite()
+	if e.r != nil {
+		err = errors.Join(err, e.r.Close())
+		e.r = nil
+	}
+	err = errors.Join(err, e.storeCursor())
+	e.mu.Unlock()
+	if err != nil {
+		err = fmt.Errorf("persistent queue: %w", err)
+	}
+	return errors.Join(err, e.next.Shutdown(ctx))
+}
+
+// httpStatusRE matches the error returned by the OTLP HTTP exporter when the
+// collector responds with a non-retryable status.
+var httpStatusRE = regexp.MustCompile(`failed to send to \S+: ([0-9]{3}) `)
+
+// retryable returns false if err is an export error that would be returned
+// again by exporting the same spans: the collector rejecting the request as
+// invalid (gRPC InvalidArgument, HTTP 4xx other than 408 and 429) or not
+// authenticating it (gRPC Unauthenticated). Failures of the exporter
+// authentication (see [ErrExporterAuth]) are retryable, the token is
+// renewed.
+func retryable(err error) bool {
+	if errors.Is(err, ErrExporterAuth) {
+		return true
+	}
+	if s, ok := grpcstatus.FromError(err); ok {
+		switch s.Code() {
+		case codes.InvalidArgument, codes.Unauthenticated:
+			return false
+		}
+		return true
+	}
+	if m := httpStatusRE.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return code < http.StatusBadRequest || code >= http.StatusInternalServerError ||
+			code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// readOnlySpans returns the spans of td converted to [sdk.ReadOnlySpan].
+func readOnlySpans(td ptrace.Traces) []sdk.ReadOnlySpan {
+	var out []sdk.ReadOnlySpan
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		rs := td.ResourceSpans().At(i)
+		res := resource.NewWithAttributes(rs.SchemaUrl(), attrs(rs.Resource().Attributes())...)
+
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			ss := rs.ScopeSpans().At(j)
+			scope := instrumentation.Scope{
+				Name:       ss.Scope().Name(),
+				Version:    ss.Scope().Version(),
+				SchemaURL:  ss.SchemaUrl(),
+				Attributes: attribute.NewSet(attrs(ss.Scope().Attributes())...),
+			}
+
+			for k := 0; k < ss.Spans().Len(); k++ {
+				stub := spanStub(ss.Spans().At(k))
+				stub.Resource = res
+				stub.InstrumentationScope = scope
+				out = append(out, stub.Snapshot())
+			}
+		}
+	}
+	return out
+}
+
+func spanStub(s ptrace.Span) tracetest.SpanStub {
+	ts, _ := trace.ParseTraceState(s.TraceState().AsRaw())
+	stub := tracetest.SpanStub{
+		Name: s.Name(),
+		SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID(s.TraceID()),
+			SpanID:     trace.SpanID(s.SpanID()),
+			TraceFlags: trace.TraceFlags(s.Flags()), // nolint: gosec  // Flags are a single byte.
+			TraceState: ts,
+		}),
+		SpanKind:          spanKind(s.Kind()),
+		StartTime:         s.StartTimestamp().AsTime(),
+		EndTime:           s.EndTimestamp().AsTime(),
+		Attributes:        attrs(s.Attributes()),
+		DroppedAttributes: int(s.DroppedAttributesCount()),
+		DroppedEvents:     int(s.DroppedEventsCount()),
+		DroppedLinks:      int(s.DroppedLinksCount()),
+	}
+	if !s.ParentSpanID().IsEmpty() {
+		stub.Parent = trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID(s.TraceID()),
+			SpanID:  trace.SpanID(s.ParentSpanID()),
+		})
+	}
+	stub.Status.Code, stub.Status.Description = status(s.Status())
+
+	for i := 0; i < s.Events().Len(); i++ {
+		e := s.Events().At(i)
+		stub.Events = append(stub.Events, sdk.Event{
+			Name:                  e.Name(),
+			Time:                  e.Timestamp().AsTime(),
+			Attributes:            attrs(e.Attributes()),
+			DroppedAttributeCount: int(e.DroppedAttributesCount()),
+		})
+	}
+	for i := 0; i < s.Links().Len(); i++ {
+		l := s.Links().At(i)
+		lts, _ := trace.ParseTraceState(l.TraceState().AsRaw())
+		stub.Links = append(stub.Links, sdk.Link{
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID(l.TraceID()),
+				SpanID:     trace.SpanID(l.SpanID()),
+				TraceFlags: trace.TraceFlags(l.Flags()), // nolint: gosec  // Flags are a single byte.
+				TraceState: lts,
+			}),
+			Attributes:            attrs(l.Attributes()),
+			DroppedAttributeCount: int(l.DroppedAttributesCount()),
+		})
+	}
+	return stub
+}
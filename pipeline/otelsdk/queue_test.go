@@ -0,0 +1,319 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// flakyExporter is an in-memory exporter that fails while down is true.
+type flakyExporter struct {
+	mu    sync.Mutex
+	down  bool
+	names []string
+}
+
+func (e *flakyExporter) setDown(down bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.down = down
+}
+
+func (e *flakyExporter) ExportSpans(_ context.Context, spans []sdk.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		return errors.New("collector unavailable")
+	}
+	for _, s := range spans {
+		e.names = append(e.names, s.Name())
+	}
+	return nil
+}
+
+func (e *flakyExporter) Shutdown(context.Context) error { return nil }
+
+func (e *flakyExporter) exported() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.names...)
+}
+
+func newTestQueue(t *testing.T, next sdk.SpanExporter, c QueueConfig) *queueExporter {
+	t.Helper()
+
+	minRetry, maxRetry := queueMinRetry, queueMaxRetry
+	queueMinRetry, queueMaxRetry = time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { queueMinRetry, queueMaxRetry = minRetry, maxRetry })
+
+	e, err := newQueueExporter(next, c, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	return e
+}
+
+func TestQueueExporterOutage(t *testing.T) {
+	next := &flakyExporter{}
+	e := newTestQueue(t, next, QueueConfig{Dir: t.TempDir()})
+
+	ctx := context.Background()
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span0")))
+	assert.Equal(t, []string{"span0"}, next.exported())
+
+	next.setDown(true)
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span1", "span2")))
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span3")))
+	next.setDown(false)
+	// Exported after the stored spans.
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span4")))
+
+	want := []string{"span0", "span1", "span2", "span3", "span4"}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(want, next.exported())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(ctx))
+}
+
+// rejectingExporter is a flakyExporter failing the exports of spans named
+// "invalid" with err.
+type rejectingExporter struct {
+	flakyExporter
+
+	err error
+}
+
+func (e *rejectingExporter) ExportSpans(ctx context.Context, spans []sdk.ReadOnlySpan) error {
+	for _, s := range spans {
+		if s.Name() == "invalid" {
+			return e.err
+		}
+	}
+	return e.flakyExporter.ExportSpans(ctx, spans)
+}
+
+func TestQueueExporterNonRetryable(t *testing.T) {
+	next := &rejectingExporter{err: grpcstatus.Error(grpccodes.InvalidArgument, "invalid span")}
+	e := newTestQueue(t, next, QueueConfig{Dir: t.TempDir()})
+
+	ctx := context.Background()
+	// Not stored, the error is returned.
+	require.Error(t, e.ExportSpans(ctx, testSpans("invalid")))
+
+	next.setDown(true)
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span0")))
+	require.NoError(t, e.ExportSpans(ctx, testSpans("invalid")))
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span1")))
+	next.setDown(false)
+
+	// The rejected record is dropped, not retried.
+	want := []string{"span0", "span1"}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(want, next.exported())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(ctx))
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Unavailable", grpcstatus.Error(grpccodes.Unavailable, "unavailable"), true},
+		{"InvalidArgument", grpcstatus.Error(grpccodes.InvalidArgument, "invalid"), false},
+		{"Unauthenticated", grpcstatus.Error(grpccodes.Unauthenticated, "unauthenticated"), false},
+		{"ExporterAuth", fmt.Errorf("%w: %w", ErrExporterAuth, grpcstatus.Error(grpccodes.Unauthenticated, "unauthenticated")), true},
+		{"HTTPBadRequest", errors.New("failed to send to http://localhost:4318/v1/traces: 400 Bad Request (body: invalid)"), false},
+		{"HTTPTooManyRequests", errors.New("failed to send to http://localhost:4318/v1/traces: 429 Too Many Requests (body: slow down)"), true},
+		{"HTTPInternalServerError", errors.New("failed to send to http://localhost:4318/v1/traces: 500 Internal Server Error (body: oops)"), true},
+		{"Network", errors.New("connection refused"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryable(tt.err))
+		})
+	}
+}
+
+func TestQueueExporterRestart(t *testing.T) {
+	dir := t.TempDir()
+	next := &flakyExporter{down: true}
+	e := newTestQueue(t, next, QueueConfig{Dir: dir, Sync: FileSyncAlways})
+
+	ctx := context.Background()
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span0")))
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span1")))
+	require.NoError(t, e.Shutdown(ctx))
+	require.NoError(t, e.ExportSpans(ctx, testSpans("dropped")))
+	assert.Empty(t, next.exported())
+
+	next.setDown(false)
+	e = newTestQueue(t, next, QueueConfig{Dir: dir})
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"span0", "span1"}, next.exported())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(ctx))
+
+	// Exported spans are not exported again.
+	e = newTestQueue(t, next, QueueConfig{Dir: dir})
+	require.NoError(t, e.Shutdown(ctx))
+	assert.Equal(t, []string{"span0", "span1"}, next.exported())
+	segments, err := filepath.Glob(filepath.Join(dir, queueSegmentPrefix+"*"))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(segments), 1)
+}
+
+func TestQueueExporterMaxSize(t *testing.T) {
+	var m queueExporter
+	payload, err := m.marshaler.MarshalTraces(convertSpans(testSpans("span0")))
+	require.NoError(t, err)
+	recSize := int64(queueHeaderSize + len(payload))
+
+	next := &flakyExporter{down: true}
+	// Segments hold 2 records, the queue at most 4.
+	e := newTestQueue(t, next, QueueConfig{Dir: t.TempDir(), MaxSize: 4*recSize + 1})
+	e.segmentSize = 2*recSize + 1
+
+	ctx := context.Background()
+	for _, name := range []string{"span0", "span1", "span2", "span3", "span4", "span5"} {
+		require.NoError(t, e.ExportSpans(ctx, testSpans(name)))
+	}
+	next.setDown(false)
+
+	want := []string{"span2", "span3", "span4", "span5"}
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(want, next.exported())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(ctx))
+}
+
+func TestQueueExporterCorruptedSegment(t *testing.T) {
+	dir := t.TempDir()
+	next := &flakyExporter{down: true}
+	e := newTestQueue(t, next, QueueConfig{Dir: dir})
+
+	ctx := context.Background()
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span0")))
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span1")))
+	require.NoError(t, e.Shutdown(ctx))
+
+	e = newTestQueue(t, next, QueueConfig{Dir: dir})
+	require.NoError(t, e.ExportSpans(ctx, testSpans("span2")))
+	require.NoError(t, e.Shutdown(ctx))
+
+	// Corrupt the payload of the first record of the first segment.
+	path := filepath.Join(dir, queueSegmentName(0))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[queueHeaderSize] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	next.setDown(false)
+	e = newTestQueue(t, next, QueueConfig{Dir: dir})
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"span2"}, next.exported())
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, e.Shutdown(ctx))
+}
+
+func TestQueueExporterRoundTrip(t *testing.T) {
+	next := tracetest.NewInMemoryExporter()
+	e := newTestQueue(t, next, QueueConfig{Dir: t.TempDir()})
+
+	want := testSpans("span")
+	e.mu.Lock()
+	payload, err := e.marshaler.MarshalTraces(convertSpans(want))
+	require.NoError(t, err)
+	require.NoError(t, e.append(payload))
+	e.wake <- struct{}{}
+	e.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return len(next.GetSpans()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	got := next.GetSpans()[0]
+	assert.Equal(t, want[0].Name(), got.Name)
+	assert.Equal(t, want[0].SpanContext().TraceID(), got.SpanContext.TraceID())
+	assert.Equal(t, want[0].SpanContext().SpanID(), got.SpanContext.SpanID())
+	assert.Equal(t, trace.FlagsSampled, got.SpanContext.TraceFlags())
+	assert.Equal(t, want[0].Parent().SpanID(), got.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindServer, got.SpanKind)
+	assert.True(t, want[0].StartTime().Equal(got.StartTime))
+	assert.Equal(t, want[0].Attributes(), got.Attributes)
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, "event", got.Events[0].Name)
+	assert.Equal(t, codes.Error, got.Status.Code)
+	assert.Equal(t, "failed", got.Status.Description)
+	assert.Equal(t, want[0].Resource().Attributes(), got.Resource.Attributes())
+	assert.Equal(t, want[0].InstrumentationScope().Name, got.InstrumentationScope.Name)
+	assert.Equal(t, want[0].InstrumentationScope().Version, got.InstrumentationScope.Version)
+	require.NoError(t, e.Shutdown(context.Background()))
+}
+
+func TestNewQueueExporterEmptyDir(t *testing.T) {
+	_, err := newQueueExporter(&flakyExporter{}, QueueConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.Error(t, err)
+}
+
+func TestQueueConfigFromEnv(t *testing.T) {
+	_, ok, err := queueConfigFromEnv()
+	require.NoError(t, err)
+	assert.False(t, ok, "enabled without directory")
+
+	t.Setenv(envQueueDirKey, "/var/lib/otel/queue")
+	t.Setenv(envQueueMaxSizeKey, "1048576")
+	t.Setenv(envQueueSyncKey, "always")
+	qc, ok, err := queueConfigFromEnv()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, QueueConfig{
+		Dir:     "/var/lib/otel/queue",
+		MaxSize: 1 << 20,
+		Sync:    FileSyncAlways,
+	}, qc)
+
+	t.Setenv(envQueueMaxSizeKey, "1MiB")
+	_, ok, err = queueConfigFromEnv()
+	assert.ErrorContains(t, err, envQueueMaxSizeKey)
+	assert.False(t, ok)
+}
+
+func TestWithPersistentQueue(t *testing.T) {
+	minRetry := queueMinRetry
+	queueMinRetry = time.Millisecond
+	t.Cleanup(func() { queueMinRetry = minRetry })
+
+	next := &flakyExporter{down: true}
+	c, err := newConfig(context.Background(), []Option{
+		WithTraceExporter(next),
+		WithPersistentQueue(QueueConfig{Dir: t.TempDir()}),
+	})
+	require.NoError(t, err)
+	require.IsType(t, &queueExporter{}, c.exporter)
+
+	ctx := context.Background()
+	require.NoError(t, c.exporter.ExportSpans(ctx, testSpans("span")))
+	next.setDown(false)
+	assert.Eventually(t, func() bool {
+		return len(next.exported()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.exporter.Shutdown(ctx))
+}
@@ -0,0 +1,141 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// RecordingExporter is a span exporter that stores the exported spans in
+// memory. It is intended for integration tests asserting on the spans an
+// auto-instrumented service produces, without a collector.
+//
+// Use it with [WithTraceExporter] so the spans go through the same pipeline
+// as with the OTLP exporter. Spans are exported in batches, call
+// [RecordingExporter.WaitForSpans] to wait for them.
+//
+// A RecordingExporter is safe for concurrent use. Its spans are kept once it
+// is shut down.
+type RecordingExporter struct {
+	mu    sync.Mutex
+	spans tracetest.SpanStubs
+	// added is closed, and replaced, when spans are added.
+	added chan struct{}
+}
+
+var _ sdk.SpanExporter = (*RecordingExporter)(nil)
+
+// NewRecordingExporter returns a new [RecordingExporter].
+func NewRecordingExporter() *RecordingExporter {
+	return &RecordingExporter{added: make(chan struct{})}
+}
+
+// ExportSpans stores spans.
+func (e *RecordingExporter) ExportSpans(ctx context.Context, spans []sdk.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return ctx.Err()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, tracetest.SpanStubsFromReadOnlySpans(spans)...)
+	close(e.added)
+	e.added = make(chan struct{})
+	return ctx.Err()
+}
+
+// Shutdown does nothing, the stored spans are kept.
+func (e *RecordingExporter) Shutdown(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Spans returns a copy of the spans stored, in the order they were exported.
+func (e *RecordingExporter) Spans() tracetest.SpanStubs {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append(tracetest.SpanStubs(nil), e.spans...)
+}
+
+// Reset removes the stored spans.
+func (e *RecordingExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// WaitForSpans waits until at least n spans are stored and returns them. An
+// error is returned with the spans stored if they are not within timeout.
+func (e *RecordingExporter) WaitForSpans(n int, timeout time.Duration) (tracetest.SpanStubs, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		e.mu.Lock()
+		spans, added := e.spans, e.added
+		e.mu.Unlock()
+
+		if len(spans) >= n {
+			return append(tracetest.SpanStubs(nil), spans...), nil
+		}
+
+		select {
+		case <-added:
+		case <-timer.C:
+			return append(tracetest.SpanStubs(nil), spans...),
+				fmt.Errorf("timed out after %s waiting for %d spans, %d exported", timeout, n, len(spans))
+		}
+	}
+}
+
+// FindSpan returns the first stored span named name with all of attrs, and
+// true. If there is none, false is returned.
+func (e *RecordingExporter) FindSpan(name string, attrs ...attribute.KeyValue) (tracetest.SpanStub, bool) {
+	for _, s := range e.Spans() {
+		if s.Name == name && hasAttributes(s.Attributes, attrs) {
+			return s, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func hasAttributes(set []attribute.KeyValue, attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if !slices.Contains(set, kv) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestingT is the subset of [testing.TB] used by [RecordingExporter.AssertSpan].
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertSpan reports an error to t and returns false if no stored span is
+// named name with all of attrs. The names of the stored spans are included in
+// the error.
+func (e *RecordingExporter) AssertSpan(t TestingT, name string, attrs ...attribute.KeyValue) bool {
+	t.Helper()
+	if _, ok := e.FindSpan(name, attrs...); ok {
+		return true
+	}
+
+	spans := e.Spans()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	t.Errorf("no span named %q with attributes %v, spans: [%s]", name, attrs, strings.Join(names, ", "))
+	return false
+}
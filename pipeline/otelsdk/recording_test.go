@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// fakeT records the errors reported by AssertSpan.
+type fakeT struct{ errs []string }
+
+func (*fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+func TestRecordingExporter(t *testing.T) {
+	exp := NewRecordingExporter()
+	handler, err := NewTraceHandler(context.Background(), WithTraceExporter(exp))
+	require.NoError(t, err)
+
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("go.opentelemetry.io/auto/net/http")
+	spans := ptrace.NewSpanSlice()
+	for i, name := range []string{"GET", "POST"} {
+		span := spans.AppendEmpty()
+		span.SetName(name)
+		span.SetTraceID(pcommon.TraceID{0x1})
+		span.SetSpanID(pcommon.SpanID{byte(i + 1)})
+		span.Attributes().PutStr("http.request.method", name)
+	}
+
+	done := make(chan struct{})
+	var got []string
+	go func() {
+		defer close(done)
+		stubs, err := exp.WaitForSpans(2, 10*time.Second)
+		assert.NoError(t, err)
+		for _, s := range stubs {
+			got = append(got, s.Name)
+		}
+	}()
+	handler.HandleTrace(scope, "", spans)
+	// Flush the batched spans.
+	require.NoError(t, handler.Shutdown(context.Background()))
+	<-done
+	assert.Equal(t, []string{"GET", "POST"}, got)
+
+	s, ok := exp.FindSpan("POST", attribute.String("http.request.method", "POST"))
+	assert.True(t, ok)
+	assert.Equal(t, "go.opentelemetry.io/auto/net/http", s.InstrumentationScope.Name)
+	_, ok = exp.FindSpan("POST", attribute.String("http.request.method", "GET"))
+	assert.False(t, ok)
+
+	ft := &fakeT{}
+	assert.True(t, exp.AssertSpan(ft, "GET", attribute.String("http.request.method", "GET")))
+	assert.Empty(t, ft.errs)
+	assert.False(t, exp.AssertSpan(ft, "PUT"))
+	require.Len(t, ft.errs, 1)
+	assert.Contains(t, ft.errs[0], `no span named "PUT"`)
+	assert.Contains(t, ft.errs[0], "spans: [GET, POST]")
+
+	exp.Reset()
+	assert.Empty(t, exp.Spans())
+	_, err = exp.WaitForSpans(1, 10*time.Millisecond)
+	assert.ErrorContains(t, err, "waiting for 1 spans, 0 exported")
+}
+
+func TestRecordingExporterConcurrentSafe(t *testing.T) {
+	exp := NewRecordingExporter()
+	handler, err := NewTraceHandler(context.Background(), WithTraceExporter(exp))
+	require.NoError(t, err)
+
+	const goroutines, perGoroutine = 5, 20
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				spans := ptrace.NewSpanSlice()
+				span := spans.AppendEmpty()
+				span.SetName("span")
+				span.SetTraceID(pcommon.TraceID{0x1})
+				span.SetSpanID(pcommon.SpanID{0x1})
+				handler.HandleTrace(pcommon.NewInstrumentationScope(), "", spans)
+				_ = exp.Spans()
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, handler.Shutdown(context.Background()))
+
+	spans, err := exp.WaitForSpans(goroutines*perGoroutine, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, spans, goroutines*perGoroutine)
+}
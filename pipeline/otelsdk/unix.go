@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// unixSocketPath returns the path of the unix domain socket endpoint u
+// refers to. If u is not a unix domain socket endpoint, false is returned.
+//
+// Both the URL form (unix:///path/to.sock) and the gRPC target form
+// (unix:/path/to.sock or unix:relative.sock) are supported.
+func unixSocketPath(u *url.URL) (string, bool) {
+	if u == nil || u.Scheme != "unix" {
+		return "", false
+	}
+	if u.Opaque != "" {
+		return u.Opaque, true
+	}
+	return u.Path, u.Path != ""
+}
+
+// newUnixExporter returns an OTLP trace exporter that exports to the
+// collector listening on the unix domain socket at path. The exporter is
+// configured with environment variables, except for its endpoint.
+//
+// Connections are dialed when needed, so the exporter recovers when the
+// collector is restarted and the socket recreated, or when the socket does
+// not exist when the exporter is created.
+func newUnixExporter(ctx context.Context, path string, grpcProto bool, h otlpHooks) (sdk.SpanExporter, error) {
+	if grpcProto {
+		return otlptracegrpc.New(
+			ctx,
+			// The unix:path target form, unlike unix://path, supports
+			// relative paths.
+			otlptracegrpc.WithEndpoint("unix:"+path),
+			otlptracegrpc.WithInsecure(),
+			otlptracegrpc.WithDialOption(h.dialOptions(false)...),
+		)
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		},
+		MaxIdleConns:    100,
+		IdleConnTimeout: 90 * time.Second,
+	}
+	return otlptracehttp.New(
+		ctx,
+		// The host is only used for the Host header of requests.
+		otlptracehttp.WithEndpoint("localhost"),
+		otlptracehttp.WithURLPath("/v1/traces"),
+		otlptracehttp.WithInsecure(),
+		otlptracehttp.WithHTTPClient(&http.Client{Transport: h.roundTripper(transport)}),
+	)
+}
@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelsdk
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	collpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		ok       bool
+	}{
+		{endpoint: "unix:///var/run/otel/collector.sock", want: "/var/run/otel/collector.sock", ok: true},
+		{endpoint: "unix:/var/run/otel/collector.sock", want: "/var/run/otel/collector.sock", ok: true},
+		{endpoint: "unix:collector.sock", want: "collector.sock", ok: true},
+		{endpoint: "unix://"},
+		{endpoint: "http://localhost:4318"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endpoint, func(t *testing.T) {
+			u, err := url.Parse(test.endpoint)
+			require.NoError(t, err)
+			got, ok := unixSocketPath(u)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+	_, ok := unixSocketPath(nil)
+	assert.False(t, ok)
+}
+
+// socketPath returns a path for a unix domain socket that does not exist.
+func socketPath(t *testing.T) string {
+	t.Helper()
+
+	// Avoid t.TempDir, its path can exceed the maximum socket path length.
+	dir, err := os.MkdirTemp("", "otlp")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "collector.sock")
+}
+
+// startCollector starts a fake collector for protocol listening on the unix
+// domain socket at path. Each export received is sent on the returned
+// channel.
+func startCollector(t *testing.T, protocol, path string) <-chan struct{} {
+	t.Helper()
+
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	got := make(chan struct{}, 10)
+	switch protocol {
+	case "grpc":
+		srv := grpc.NewServer()
+		collpb.RegisterTraceServiceServer(srv, &traceService{got: got})
+		go func() { _ = srv.Serve(ln) }()
+		t.Cleanup(srv.Stop)
+	default:
+		srv := &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body)
+				if r.URL.Path != "/v1/traces" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				got <- struct{}{}
+				w.Header().Set("Content-Type", "application/x-protobuf")
+				w.WriteHeader(http.StatusOK)
+			}),
+			ReadHeaderTimeout: time.Second,
+		}
+		go func() { _ = srv.Serve(ln) }()
+		t.Cleanup(func() { _ = srv.Close() })
+	}
+	return got
+}
+
+type traceService struct {
+	collpb.UnimplementedTraceServiceServer
+
+	got chan<- struct{}
+}
+
+func (s *traceService) Export(context.Context, *collpb.ExportTraceServiceRequest) (*collpb.ExportTraceServiceResponse, error) {
+	s.got <- struct{}{}
+	return &collpb.ExportTraceServiceResponse{}, nil
+}
+
+func TestUnixExporter(t *testing.T) {
+	spans := tracetest.SpanStubs{{Name: "span"}}.Snapshots()
+
+	for _, protocol := range []string{"http/protobuf", "grpc"} {
+		t.Run(protocol, func(t *testing.T) {
+			t.Run("Export", func(t *testing.T) {
+				path := socketPath(t)
+				got := startCollector(t, protocol, path)
+
+				t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", protocol)
+				t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "unix://"+path)
+				c, err := newConfig(context.Background(), []Option{WithEnv()})
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = c.exporter.Shutdown(context.Background()) })
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				require.NoError(t, c.exporter.ExportSpans(ctx, spans))
+				assert.Len(t, got, 1)
+			})
+
+			t.Run("RelativePath", func(t *testing.T) {
+				path := socketPath(t)
+				wd, err := os.Getwd()
+				require.NoError(t, err)
+				require.NoError(t, os.Chdir(filepath.Dir(path)))
+				t.Cleanup(func() { _ = os.Chdir(wd) })
+				got := startCollector(t, protocol, filepath.Base(path))
+
+				t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", protocol)
+				t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "unix:"+filepath.Base(path))
+				c, err := newConfig(context.Background(), []Option{WithEnv()})
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = c.exporter.Shutdown(context.Background()) })
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				require.NoError(t, c.exporter.ExportSpans(ctx, spans))
+				assert.Len(t, got, 1)
+			})
+
+			t.Run("SocketMissingAtStartup", func(t *testing.T) {
+				path := socketPath(t)
+
+				t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", protocol)
+				t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "unix:"+path)
+				c, err := newConfig(context.Background(), []Option{WithEnv()})
+				require.NoError(t, err)
+				t.Cleanup(func() { _ = c.exporter.Shutdown(context.Background()) })
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				assert.Error(t, c.exporter.ExportSpans(ctx, spans), "export without collector")
+
+				got := startCollector(t, protocol, path)
+
+				ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				require.NoError(t, c.exporter.ExportSpans(ctx, spans))
+				assert.Len(t, got, 1)
+			})
+		})
+	}
+}
@@ -83,6 +83,34 @@ func (p *noopProvider) Shutdown(_ context.Context) error {
 	return nil
 }
 
+// NewStaticConfigProvider returns a [ConfigProvider] providing c as the
+// initial configuration, and never updating it.
+//
+// The configuration of the returned provider cannot enable probes once the
+// instrumentation is loaded: the capabilities needed to load probes are not
+// retained by [WithPrivilegeDrop].
+func NewStaticConfigProvider(c InstrumentationConfig) ConfigProvider {
+	return &staticProvider{config: c}
+}
+
+type staticProvider struct {
+	config InstrumentationConfig
+}
+
+func (p *staticProvider) InitialConfig(context.Context) InstrumentationConfig {
+	return p.config
+}
+
+func (p *staticProvider) Watch() <-chan InstrumentationConfig {
+	c := make(chan InstrumentationConfig)
+	close(c)
+	return c
+}
+
+func (p *staticProvider) Shutdown(context.Context) error {
+	return nil
+}
+
 func convertConfigProvider(cp ConfigProvider) instrumentation.ConfigProvider {
 	return &converter{ConfigProvider: cp}
 }
@@ -94,6 +122,16 @@ type converter struct {
 	chOnce sync.Once
 }
 
+// Static returns true if the converted provider never updates the
+// configuration.
+func (c *converter) Static() bool {
+	switch c.ConfigProvider.(type) {
+	case *noopProvider, *staticProvider:
+		return true
+	}
+	return false
+}
+
 func (c *converter) InitialConfig(ctx context.Context) instrumentation.Config {
 	return c.instrumentationConfig(c.ConfigProvider.InitialConfig(ctx))
 }
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracetest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Diff returns a report of the differences between the traces want and got,
+// or an empty string if they are equal. Both traces are expected to be
+// normalized with [Normalize].
+//
+// Each line of the report is a field of a resource, scope, span, span event
+// or link that is only in want, prefixed with "-", or only in got, prefixed
+// with "+". The line starts with the path of the field, spans are identified
+// by their name and their index among the spans with the same name in their
+// scope. For example, a changed status code attribute is reported as a
+// removed line followed by an added one, both with the path:
+//
+//	resource[0] scope "go.opentelemetry.io/auto/net/http" span "GET"[0] attributes.http.response.status_code
+func Diff(want, got ptrace.Traces) string {
+	a, b := lines(want), lines(got)
+
+	// Longest common subsequence of the lines.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
+
+// lines returns the fields of td, one per line, prefixed with their path.
+func lines(td ptrace.Traces) []string {
+	var out []string
+	add := func(path, field, value string) {
+		out = append(out, path+" "+field+": "+value)
+	}
+	addMap := func(path, field string, m pcommon.Map) {
+		m.Range(func(k string, v pcommon.Value) bool {
+			add(path, field+"."+k, valueString(v))
+			return true
+		})
+	}
+
+	rss := td.ResourceSpans()
+	for i := range rss.Len() {
+		rs := rss.At(i)
+		rPath := "resource[" + strconv.Itoa(i) + "]"
+		add(rPath, "schema_url", strconv.Quote(rs.SchemaUrl()))
+		addMap(rPath, "attributes", rs.Resource().Attributes())
+
+		sss := rs.ScopeSpans()
+		for j := range sss.Len() {
+			ss := sss.At(j)
+			scope := ss.Scope()
+			sPath := rPath + " scope " + strconv.Quote(scope.Name())
+			add(sPath, "version", strconv.Quote(scope.Version()))
+			add(sPath, "schema_url", strconv.Quote(ss.SchemaUrl()))
+			addMap(sPath, "attributes", scope.Attributes())
+
+			seen := make(map[string]int)
+			spans := ss.Spans()
+			for k := range spans.Len() {
+				span := spans.At(k)
+				n := seen[span.Name()]
+				seen[span.Name()]++
+				path := fmt.Sprintf("%s span %q[%d]", sPath, span.Name(), n)
+
+				add(path, "trace_id", span.TraceID().String())
+				add(path, "span_id", span.SpanID().String())
+				add(path, "parent_span_id", span.ParentSpanID().String())
+				add(path, "trace_state", strconv.Quote(span.TraceState().AsRaw()))
+				add(path, "flags", strconv.FormatUint(uint64(span.Flags()), 10))
+				add(path, "kind", span.Kind().String())
+				add(path, "start", span.StartTimestamp().String())
+				add(path, "end", span.EndTimestamp().String())
+				add(path, "status", span.Status().Code().String()+" "+strconv.Quote(span.Status().Message()))
+				addMap(path, "attributes", span.Attributes())
+
+				events := span.Events()
+				for e := range events.Len() {
+					event := events.At(e)
+					ePath := fmt.Sprintf("%s event[%d]", path, e)
+					add(ePath, "name", strconv.Quote(event.Name()))
+					add(ePath, "time", event.Timestamp().String())
+					addMap(ePath, "attributes", event.Attributes())
+				}
+				links := span.Links()
+				for l := range links.Len() {
+					link := links.At(l)
+					lPath := fmt.Sprintf("%s link[%d]", path, l)
+					add(lPath, "trace_id", link.TraceID().String())
+					add(lPath, "span_id", link.SpanID().String())
+					addMap(lPath, "attributes", link.Attributes())
+				}
+			}
+		}
+	}
+	return out
+}
+
+func valueString(v pcommon.Value) string {
+	if v.Type() == pcommon.ValueTypeStr {
+		return strconv.Quote(v.Str())
+	}
+	return v.AsString()
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracetest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	want := Normalize(request(1, now, false))
+	assert.Empty(t, Diff(want, want))
+
+	got := request(2, now, false)
+	span := got.ResourceSpans().At(2).ScopeSpans().At(0).Spans().At(0)
+	span.Attributes().PutStr("url.path", "/v2/api")
+	span.Events().AppendEmpty().SetName("retry")
+	got = Normalize(got)
+
+	const path = `resource[0] scope "go.opentelemetry.io/auto/net/http" span "GET /api"[0]`
+	assert.Equal(t, strings.Join([]string{
+		`- ` + path + ` attributes.url.path: "/api"`,
+		`+ ` + path + ` attributes.url.path: "/v2/api"`,
+		`+ ` + path + ` event[0] name: "retry"`,
+		`+ ` + path + ` event[0] time: 1970-01-01 00:00:00 +0000 UTC`,
+	}, "\n")+"\n", Diff(want, got))
+}
+
+func TestDiffMissingSpan(t *testing.T) {
+	want := Normalize(request(1, time.Now(), false))
+	got := ptrace.NewTraces()
+	want.ResourceSpans().At(0).CopyTo(got.ResourceSpans().AppendEmpty())
+	got = Normalize(got)
+
+	d := Diff(want, got)
+	assert.Contains(t, d, `- resource[2] scope "go.opentelemetry.io/auto/net/http" span "GET"[0] kind: Client`)
+	assert.Contains(t, d, `- resource[1] scope "go.opentelemetry.io/auto/net/http" span "GET /"[0] kind: Server`)
+	assert.NotContains(t, d, "kind: Server\n+")
+}
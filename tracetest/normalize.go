@@ -0,0 +1,317 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracetest provides helpers to compare the traces produced by an
+// auto-instrumented service with trace fixtures.
+//
+// Traces are nondeterministic: their IDs are random, their timestamps depend
+// on when they were recorded, and their spans and attributes are exported in
+// no particular order. [Normalize] removes these differences so two traces
+// of the same operations can be compared with [Diff].
+package tracetest
+
+import (
+	"encoding/binary"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Option configures [Normalize].
+type Option interface {
+	apply(config) config
+}
+
+type config struct {
+	precision time.Duration
+}
+
+type fnOpt func(config) config
+
+func (f fnOpt) apply(c config) config { return f(c) }
+
+// WithTimestampPrecision returns an [Option] that rounds the timestamps down
+// to a multiple of d instead of removing them.
+//
+// If d is not positive, timestamps are removed. This is the default.
+func WithTimestampPrecision(d time.Duration) Option {
+	return fnOpt(func(c config) config {
+		c.precision = d
+		return c
+	})
+}
+
+// Normalize returns a normalized copy of td:
+//
+//   - Timestamps are removed, or rounded with [WithTimestampPrecision].
+//   - Attributes are sorted by key.
+//   - Resources, scopes and spans are sorted by their content, excluding
+//     IDs.
+//   - Trace and span IDs are replaced by sequential IDs assigned in that
+//     order. The same ID is replaced by the same value everywhere, so the
+//     parent/child relationships and links between spans are preserved.
+//
+// The order of span events and links is kept.
+func Normalize(td ptrace.Traces, opts ...Option) ptrace.Traces {
+	var c config
+	for _, o := range opts {
+		c = o.apply(c)
+	}
+
+	out := ptrace.NewTraces()
+	td.CopyTo(out)
+
+	rss := out.ResourceSpans()
+	for i := range rss.Len() {
+		rs := rss.At(i)
+		sortMap(rs.Resource().Attributes())
+		sss := rs.ScopeSpans()
+		for j := range sss.Len() {
+			ss := sss.At(j)
+			sortMap(ss.Scope().Attributes())
+			spans := ss.Spans()
+			for k := range spans.Len() {
+				normalizeSpan(spans.At(k), c)
+			}
+		}
+	}
+
+	keys := spanKeys(out)
+	for i := range rss.Len() {
+		sss := rss.At(i).ScopeSpans()
+		for j := range sss.Len() {
+			sss.At(j).Spans().Sort(func(a, b ptrace.Span) bool {
+				return keys[a.SpanID()] < keys[b.SpanID()]
+			})
+		}
+		sss.Sort(func(a, b ptrace.ScopeSpans) bool { return scopeKey(a, keys) < scopeKey(b, keys) })
+	}
+	rss.Sort(func(a, b ptrace.ResourceSpans) bool { return resourceKey(a, keys) < resourceKey(b, keys) })
+
+	newIDs().replace(out)
+	return out
+}
+
+func normalizeSpan(span ptrace.Span, c config) {
+	span.SetStartTimestamp(round(span.StartTimestamp(), c.precision))
+	span.SetEndTimestamp(round(span.EndTimestamp(), c.precision))
+	sortMap(span.Attributes())
+
+	events := span.Events()
+	for i := range events.Len() {
+		e := events.At(i)
+		e.SetTimestamp(round(e.Timestamp(), c.precision))
+		sortMap(e.Attributes())
+	}
+	links := span.Links()
+	for i := range links.Len() {
+		sortMap(links.At(i).Attributes())
+	}
+}
+
+func round(ts pcommon.Timestamp, precision time.Duration) pcommon.Timestamp {
+	if precision <= 0 {
+		return 0
+	}
+	return ts - ts%pcommon.Timestamp(precision)
+}
+
+// sortMap sorts the entries of m, and of the maps it contains, by key.
+func sortMap(m pcommon.Map) {
+	keys := make([]string, 0, m.Len())
+	m.Range(func(k string, v pcommon.Value) bool {
+		keys = append(keys, k)
+		sortValue(v)
+		return true
+	})
+	if slices.IsSorted(keys) {
+		return
+	}
+	slices.Sort(keys)
+
+	sorted := pcommon.NewMap()
+	sorted.EnsureCapacity(len(keys))
+	for _, k := range keys {
+		v, _ := m.Get(k)
+		v.CopyTo(sorted.PutEmpty(k))
+	}
+	sorted.MoveTo(m)
+}
+
+func sortValue(v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		sortMap(v.Map())
+	case pcommon.ValueTypeSlice:
+		s := v.Slice()
+		for i := range s.Len() {
+			sortValue(s.At(i))
+		}
+	}
+}
+
+// mapKey returns a string representation of the sorted map m.
+func mapKey(m pcommon.Map) string {
+	var b strings.Builder
+	m.Range(func(k string, v pcommon.Value) bool {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(v.AsString())
+		b.WriteByte(';')
+		return true
+	})
+	return b.String()
+}
+
+// resourceKey returns the sort key of rs. It includes the keys of its sorted
+// scopes so resources with the same attributes are ordered by their spans.
+func resourceKey(rs ptrace.ResourceSpans, spans map[pcommon.SpanID]string) string {
+	var b strings.Builder
+	b.WriteString(mapKey(rs.Resource().Attributes()))
+	sss := rs.ScopeSpans()
+	for i := range sss.Len() {
+		b.WriteByte(2)
+		b.WriteString(scopeKey(sss.At(i), spans))
+	}
+	return b.String()
+}
+
+// scopeKey returns the sort key of ss. It includes the keys of its sorted
+// spans.
+func scopeKey(ss ptrace.ScopeSpans, spans map[pcommon.SpanID]string) string {
+	var b strings.Builder
+	s := ss.Scope()
+	b.WriteString(s.Name() + "\x00" + s.Version() + "\x00" + mapKey(s.Attributes()))
+	ps := ss.Spans()
+	for i := range ps.Len() {
+		b.WriteByte(3)
+		b.WriteString(spans[ps.At(i).SpanID()])
+	}
+	return b.String()
+}
+
+// spanKeys returns the sort keys of the spans of td by span ID. The key of a
+// span is prefixed with the key of its parent, so the order of spans with the
+// same content depends on the content of their ancestors, not their IDs.
+func spanKeys(td ptrace.Traces) map[pcommon.SpanID]string {
+	spans := make(map[pcommon.SpanID]ptrace.Span)
+	rss := td.ResourceSpans()
+	for i := range rss.Len() {
+		sss := rss.At(i).ScopeSpans()
+		for j := range sss.Len() {
+			ss := sss.At(j).Spans()
+			for k := range ss.Len() {
+				spans[ss.At(k).SpanID()] = ss.At(k)
+			}
+		}
+	}
+
+	keys := make(map[pcommon.SpanID]string, len(spans))
+	var key func(id pcommon.SpanID, depth int) string
+	key = func(id pcommon.SpanID, depth int) string {
+		if k, ok := keys[id]; ok {
+			return k
+		}
+		span := spans[id]
+		k := spanKey(span)
+		// Bound the depth in case of a cycle of parents.
+		if _, ok := spans[span.ParentSpanID()]; ok && depth < len(spans) {
+			k = key(span.ParentSpanID(), depth+1) + "\x01" + k
+		}
+		keys[id] = k
+		return k
+	}
+	for id := range spans {
+		key(id, 0)
+	}
+	return keys
+}
+
+// spanKey returns the sort key of span. It does not include its IDs.
+func spanKey(span ptrace.Span) string {
+	var b strings.Builder
+	b.WriteString(span.Name())
+	b.WriteByte(0)
+	b.WriteString(span.Kind().String())
+	b.WriteByte(0)
+	b.WriteString(span.StartTimestamp().String())
+	b.WriteByte(0)
+	b.WriteString(span.EndTimestamp().String())
+	b.WriteByte(0)
+	b.WriteString(span.Status().Code().String())
+	b.WriteByte(0)
+	b.WriteString(mapKey(span.Attributes()))
+	return b.String()
+}
+
+// ids replaces trace and span IDs with sequential IDs.
+type ids struct {
+	traces map[pcommon.TraceID]pcommon.TraceID
+	spans  map[pcommon.SpanID]pcommon.SpanID
+}
+
+func newIDs() *ids {
+	return &ids{
+		traces: make(map[pcommon.TraceID]pcommon.TraceID),
+		spans:  make(map[pcommon.SpanID]pcommon.SpanID),
+	}
+}
+
+func (r *ids) traceID(id pcommon.TraceID) pcommon.TraceID {
+	if id.IsEmpty() {
+		return id
+	}
+	if n, ok := r.traces[id]; ok {
+		return n
+	}
+	var n pcommon.TraceID
+	binary.BigEndian.PutUint64(n[8:], uint64(len(r.traces)+1))
+	r.traces[id] = n
+	return n
+}
+
+func (r *ids) spanID(id pcommon.SpanID) pcommon.SpanID {
+	if id.IsEmpty() {
+		return id
+	}
+	if n, ok := r.spans[id]; ok {
+		return n
+	}
+	var n pcommon.SpanID
+	binary.BigEndian.PutUint64(n[:], uint64(len(r.spans)+1))
+	r.spans[id] = n
+	return n
+}
+
+// replace replaces the IDs of td. The IDs of the spans are assigned first, in
+// order, so they do not depend on the parents and links referring to them.
+func (r *ids) replace(td ptrace.Traces) {
+	var spans []ptrace.Span
+	rss := td.ResourceSpans()
+	for i := range rss.Len() {
+		sss := rss.At(i).ScopeSpans()
+		for j := range sss.Len() {
+			ss := sss.At(j).Spans()
+			for k := range ss.Len() {
+				spans = append(spans, ss.At(k))
+			}
+		}
+	}
+
+	for _, span := range spans {
+		span.SetTraceID(r.traceID(span.TraceID()))
+		span.SetSpanID(r.spanID(span.SpanID()))
+	}
+	for _, span := range spans {
+		span.SetParentSpanID(r.spanID(span.ParentSpanID()))
+		links := span.Links()
+		for i := range links.Len() {
+			l := links.At(i)
+			l.SetTraceID(r.traceID(l.TraceID()))
+			l.SetSpanID(r.spanID(l.SpanID()))
+		}
+	}
+}
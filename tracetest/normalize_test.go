@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// request returns the traces of an HTTP request served by "frontend", calling
+// "backend". The IDs are derived from seed, and the spans are added in
+// reverse order if reverse is true.
+func request(seed byte, start time.Time, reverse bool) ptrace.Traces {
+	tid := pcommon.TraceID{seed, 0xaa}
+	serverID, clientID, backendID := pcommon.SpanID{seed, 1}, pcommon.SpanID{seed, 2}, pcommon.SpanID{seed, 3}
+
+	td := ptrace.NewTraces()
+	addSpan := func(service string, name string, kind ptrace.SpanKind, id, parent pcommon.SpanID, attrs map[string]any) {
+		rs := td.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("service.name", service)
+		ss := rs.ScopeSpans().AppendEmpty()
+		ss.Scope().SetName("go.opentelemetry.io/auto/net/http")
+		span := ss.Spans().AppendEmpty()
+		span.SetName(name)
+		span.SetKind(kind)
+		span.SetTraceID(tid)
+		span.SetSpanID(id)
+		span.SetParentSpanID(parent)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Millisecond)))
+		_ = span.Attributes().FromRaw(attrs)
+	}
+
+	spans := []func(){
+		func() {
+			addSpan("frontend", "GET /", ptrace.SpanKindServer, serverID, pcommon.SpanID{}, map[string]any{
+				"http.request.method": "GET",
+				"url.path":            "/",
+			})
+		},
+		func() {
+			addSpan("frontend", "GET", ptrace.SpanKindClient, clientID, serverID, map[string]any{
+				"http.request.method": "GET",
+				"server.address":      "backend",
+			})
+		},
+		func() {
+			addSpan("backend", "GET /api", ptrace.SpanKindServer, backendID, clientID, map[string]any{
+				"url.path":            "/api",
+				"http.request.method": "GET",
+			})
+		},
+	}
+	if reverse {
+		for i := len(spans) - 1; i >= 0; i-- {
+			spans[i]()
+		}
+	} else {
+		for _, f := range spans {
+			f()
+		}
+	}
+	return td
+}
+
+func TestNormalize(t *testing.T) {
+	now := time.Now()
+	a := Normalize(request(1, now, false))
+	b := Normalize(request(2, now.Add(time.Hour), true))
+	assert.Empty(t, Diff(a, b))
+
+	// The input is not modified.
+	orig := request(1, now, false)
+	Normalize(orig)
+	assert.Equal(t, pcommon.TraceID{1, 0xaa}, orig.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID())
+
+	require.Equal(t, 3, a.ResourceSpans().Len())
+	spans := make(map[string]ptrace.Span)
+	for i := range a.ResourceSpans().Len() {
+		rs := a.ResourceSpans().At(i)
+		span := rs.ScopeSpans().At(0).Spans().At(0)
+		spans[span.Name()] = span
+		assert.Zero(t, span.StartTimestamp())
+		assert.Zero(t, span.EndTimestamp())
+	}
+
+	tid := pcommon.TraceID{15: 1}
+	for _, span := range spans {
+		assert.Equal(t, tid, span.TraceID())
+	}
+	// Parent/child relationships are preserved.
+	assert.True(t, spans["GET /"].ParentSpanID().IsEmpty())
+	assert.Equal(t, spans["GET /"].SpanID(), spans["GET"].ParentSpanID())
+	assert.Equal(t, spans["GET"].SpanID(), spans["GET /api"].ParentSpanID())
+
+	// Attributes are sorted.
+	var keys []string
+	spans["GET /api"].Attributes().Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	assert.Equal(t, []string{"http.request.method", "url.path"}, keys)
+}
+
+func TestNormalizeTimestampPrecision(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	td := Normalize(request(1, start, false), WithTimestampPrecision(time.Second))
+	span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, start.Truncate(time.Second), span.StartTimestamp().AsTime())
+	assert.Equal(t, start.Truncate(time.Second), span.EndTimestamp().AsTime())
+}
+
+func TestNormalizeSiblings(t *testing.T) {
+	// Spans with the same content are ordered by the content of their
+	// parent, not their IDs.
+	build := func(ids [4]byte) ptrace.Traces {
+		td := ptrace.NewTraces()
+		spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+		add := func(name string, id, parent byte) {
+			span := spans.AppendEmpty()
+			span.SetName(name)
+			span.SetTraceID(pcommon.TraceID{1})
+			span.SetSpanID(pcommon.SpanID{id})
+			if parent != 0 {
+				span.SetParentSpanID(pcommon.SpanID{parent})
+			}
+		}
+		add("query", ids[2], ids[1])
+		add("query", ids[3], ids[0])
+		add("a", ids[0], 0)
+		add("b", ids[1], 0)
+		return td
+	}
+
+	a := Normalize(build([4]byte{1, 2, 3, 4}))
+	b := Normalize(build([4]byte{9, 8, 7, 6}))
+	assert.Empty(t, Diff(a, b))
+}
@@ -6,6 +6,49 @@ package auto
 import "go.opentelemetry.io/auto/internal/pkg/instrumentation"
 
 // Version is the current release version of OpenTelemetry Go auto-instrumentation in use.
+//
+// The version can be set at build time with the linker, e.g.:
+//
+//	go build -ldflags "-X go.opentelemetry.io/auto/internal/pkg/instrumentation.buildVersion=v0.22.1-acme.1"
+//
+// Otherwise, it is the version of the go.opentelemetry.io/auto module
+// recorded in the build information of the binary, or the release version of
+// this package if the module is built from a local directory.
+//
+// The version is exported as the telemetry.distro.version resource attribute
+// and as the instrumentation scope version of the spans.
 func Version() string {
-	return instrumentation.Version
+	return instrumentation.BuildVersion()
+}
+
+// VersionInfo is the version of OpenTelemetry Go auto-instrumentation in use
+// and the versions of the instrumented libraries it supports.
+type VersionInfo struct {
+	// Version is the version returned by [Version].
+	Version string
+	// Libraries are the versions of the instrumented libraries supported by
+	// each probe.
+	Libraries []LibrarySupport
+}
+
+// LibrarySupport is the range of versions of an instrumented library
+// supported by a probe.
+type LibrarySupport struct {
+	// Probe is the ID of the probe: the instrumented package and the kind of
+	// its spans (e.g. "net/http/server").
+	Probe string
+	// Module is the module path of the library, "std" for the Go standard
+	// library.
+	Module string
+	// Min and Max are the minimum and maximum supported versions, inclusive.
+	// Versions of the Go standard library are Go versions (e.g. "go1.19").
+	Min, Max string
+}
+
+// ReadVersionInfo returns the version of OpenTelemetry Go auto-instrumentation
+// in use and the versions of the instrumented libraries it supports.
+//
+// No library is supported on platforms other than Linux.
+func ReadVersionInfo() VersionInfo {
+	return VersionInfo{Version: Version(), Libraries: supportedLibraries()}
 }
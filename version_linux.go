@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package auto
+
+import "go.opentelemetry.io/auto/internal/pkg/instrumentation/bpf"
+
+// supportedLibraries returns the versions of the instrumented libraries
+// supported by each probe.
+func supportedLibraries() []LibrarySupport {
+	libs := make([]LibrarySupport, len(bpf.Supported))
+	for i, s := range bpf.Supported {
+		libs[i] = LibrarySupport(s)
+	}
+	return libs
+}
@@ -0,0 +1,28 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package auto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadVersionInfo(t *testing.T) {
+	info := ReadVersionInfo()
+	assert.Equal(t, Version(), info.Version)
+	assert.Contains(t, info.Libraries, LibrarySupport{
+		Probe:  "net/http/server",
+		Module: "std",
+		Min:    "go1.19",
+		Max:    "go1.24.5",
+	})
+	for _, l := range info.Libraries {
+		assert.NotEmpty(t, l.Module, l.Probe)
+		assert.NotEmpty(t, l.Min, l.Probe)
+		assert.NotEmpty(t, l.Max, l.Probe)
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+
+package auto
+
+// supportedLibraries returns nil, the probes are only supported on Linux.
+func supportedLibraries() []LibrarySupport { return nil }